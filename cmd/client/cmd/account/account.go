@@ -0,0 +1,74 @@
+// cmd/client/cmd/account/account.go
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var quotaOutput string
+
+// AccountCmd - родительская команда учетной записи на сервере
+var AccountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Учетная запись на сервере",
+}
+
+var QuotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Использование хранилища",
+	Long: `Показывает, сколько места занимают ваши зашифрованные записи на сервере
+и какой лимит действует. Записи в корзине не учитываются.
+
+Если запись не помещается в квоту, сервер отклоняет ее. Освободите место,
+удалив ненужные записи и очистив корзину, или обратитесь к администратору.`,
+	Example: `  gophkeeper account quota
+  gophkeeper account quota -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		usage, err := app.Quota(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения квоты: %w", err)
+		}
+
+		if quotaOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(usage)
+		}
+
+		percent := 0.0
+		if usage.Limit > 0 {
+			percent = float64(usage.Used) / float64(usage.Limit) * 100
+		}
+
+		fmt.Println("💾 Хранилище на сервере")
+		fmt.Printf("   Занято:   %s (%.1f%%)\n", client.FormatBytes(usage.Used), percent)
+		fmt.Printf("   Свободно: %s\n", client.FormatBytes(usage.Remaining))
+		if usage.Custom {
+			fmt.Printf("   Лимит:    %s (задан администратором)\n", client.FormatBytes(usage.Limit))
+		} else {
+			fmt.Printf("   Лимит:    %s\n", client.FormatBytes(usage.Limit))
+		}
+
+		if percent >= 90 {
+			fmt.Println("⚠️  Хранилище почти заполнено: удалите ненужные записи и очистите корзину")
+		}
+		return nil
+	},
+}
+
+func init() {
+	QuotaCmd.Flags().StringVarP(&quotaOutput, "output", "o", "text", "формат вывода (text, json)")
+}
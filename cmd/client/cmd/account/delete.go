@@ -0,0 +1,94 @@
+// cmd/client/cmd/account/delete.go
+package account
+
+import (
+	"errors"
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteYes    bool
+	deleteCancel bool
+	deleteStatus bool
+)
+
+var DeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Удалить учетную запись на сервере",
+	Long: `Планирует удаление учетной записи вместе со всеми записями, версиями,
+вложениями, конфликтами, устройствами и сессиями.
+
+Сервер удаляет данные не сразу, а по истечении льготного периода (по умолчанию
+30 дней). До этого вход работает: можно выгрузить данные командой
+gophkeeper account export или отменить удаление флагом --cancel.
+После удаления восстановить данные невозможно.`,
+	Example: `  gophkeeper account delete
+  gophkeeper account delete --status
+  gophkeeper account delete --cancel`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		switch {
+		case deleteStatus:
+			deletion, err := app.AccountDeletion(cmd.Context())
+			if errors.Is(err, client.ErrDeletionNotScheduled) {
+				fmt.Println("✅ Удаление учетной записи не запланировано")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			printDeletion(deletion)
+			return nil
+		case deleteCancel:
+			if err := app.CancelAccountDeletion(cmd.Context()); err != nil {
+				return err
+			}
+			fmt.Println("✅ Удаление учетной записи отменено")
+			return nil
+		}
+
+		if !deleteYes {
+			fmt.Println("⚠️  Учетная запись и все данные на сервере будут удалены без возможности восстановления.")
+			ok, err := prompt.Confirm("Запланировать удаление учетной записи?")
+			if err != nil {
+				return fmt.Errorf("%w; подтвердите удаление флагом --yes", err)
+			}
+			if !ok {
+				fmt.Println("Отменено")
+				return nil
+			}
+		}
+
+		deletion, err := app.ScheduleAccountDeletion(cmd.Context())
+		if err != nil {
+			return err
+		}
+		printDeletion(deletion)
+		fmt.Println("   Выгрузить данные: gophkeeper account export")
+		fmt.Println("   Отменить удаление: gophkeeper account delete --cancel")
+		return nil
+	},
+}
+
+func printDeletion(deletion *client.AccountDeletion) {
+	fmt.Printf("🗑️  Учетная запись будет удалена после %s\n",
+		deletion.DeleteAfter.Local().Format("2006-01-02 15:04"))
+}
+
+func init() {
+	DeleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "не запрашивать подтверждение")
+	DeleteCmd.Flags().BoolVar(&deleteCancel, "cancel", false, "отменить запланированное удаление")
+	DeleteCmd.Flags().BoolVar(&deleteStatus, "status", false, "показать, запланировано ли удаление")
+	DeleteCmd.MarkFlagsMutuallyExclusive("cancel", "status")
+}
@@ -0,0 +1,69 @@
+// cmd/client/cmd/account/export.go
+package account
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportYes    bool
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Выгрузить все данные учетной записи",
+	Long: `Сохраняет zip-архив со всеми данными учетной записи на сервере: записи
+(включая корзину), историю версий, вложения, файлы, папки, настройки,
+устройства, конфликты синхронизации и файл мастер-ключа.
+
+Сервер не знает мастер-ключа, поэтому записи, вложения и файлы в архиве
+остаются зашифрованными. Храните архив вместе с мастер-паролем: без него
+данные не расшифровать.`,
+	Example: `  gophkeeper account export
+  gophkeeper account export -o backup/gophkeeper.zip`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		path := exportOutput
+		if path == "" {
+			path = fmt.Sprintf("gophkeeper-export-%s.zip", time.Now().Format("20060102"))
+		}
+
+		if _, err := os.Stat(path); err == nil && !exportYes {
+			ok, err := prompt.Confirm(fmt.Sprintf("Файл %s уже существует. Перезаписать?", path))
+			if err != nil {
+				return fmt.Errorf("%w; подтвердите перезапись флагом --yes", err)
+			}
+			if !ok {
+				fmt.Println("Отменено")
+				return nil
+			}
+		}
+
+		size, err := app.ExportAccount(cmd.Context(), path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("📦 Данные учетной записи сохранены в %s (%s)\n", path, client.FormatBytes(size))
+		return nil
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "путь к архиву (по умолчанию gophkeeper-export-ГГГГММДД.zip)")
+	ExportCmd.Flags().BoolVarP(&exportYes, "yes", "y", false, "перезаписать файл без подтверждения")
+}
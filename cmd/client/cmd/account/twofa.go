@@ -0,0 +1,153 @@
+// cmd/client/cmd/account/twofa.go
+package account
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// TwoFACmd - двухфакторная аутентификация учетной записи
+var TwoFACmd = &cobra.Command{
+	Use:   "2fa",
+	Short: "Двухфакторная аутентификация учетной записи",
+	Long: `Второй фактор (TOTP) для входа в учетную запись GophKeeper.
+
+После включения gophkeeper auth login после пароля запрашивает код из
+приложения-аутентификатора. Если приложение недоступно, вместо кода
+подойдет одноразовый код восстановления. Ранее выданные токены
+перестают действовать, на остальных устройствах нужно войти заново.`,
+}
+
+var TwoFAStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Состояние двухфакторной аутентификации",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		status, err := app.MFAStatus(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения состояния: %w", err)
+		}
+
+		switch {
+		case status.Enabled:
+			fmt.Println("🔐 Двухфакторная аутентификация включена")
+			fmt.Printf("   Осталось кодов восстановления: %d\n", status.RecoveryCodesLeft)
+			if status.RecoveryCodesLeft <= 2 {
+				fmt.Println("⚠️  Кодов восстановления почти не осталось: gophkeeper account 2fa recovery-codes")
+			}
+		case status.Pending:
+			fmt.Println("⏳ Подключение начато, но не подтверждено кодом: gophkeeper account 2fa enable")
+		default:
+			fmt.Println("🔓 Двухфакторная аутентификация выключена")
+		}
+		return nil
+	},
+}
+
+var TwoFAEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Включить двухфакторную аутентификацию",
+	Long: `Выдает секрет TOTP, который нужно добавить в приложение-аутентификатор
+(по otpauth:// URI или вручную), и включает второй фактор после ввода
+первого кода. Коды восстановления показываются один раз: сохраните их.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		enrollment, err := app.EnrollMFA(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка подключения: %w", err)
+		}
+
+		fmt.Println("Добавьте учетную запись в приложение-аутентификатор:")
+		fmt.Printf("   URI:    %s\n", enrollment.URI)
+		fmt.Printf("   Секрет: %s\n", enrollment.Secret)
+		fmt.Println()
+
+		code, err := readCode("Код из приложения: ")
+		if err != nil {
+			return err
+		}
+		codes, err := app.ConfirmMFA(cmd.Context(), code)
+		if err != nil {
+			return fmt.Errorf("ошибка включения: %w", err)
+		}
+
+		fmt.Println("✅ Двухфакторная аутентификация включена")
+		printRecoveryCodes(codes)
+		return nil
+	},
+}
+
+var TwoFADisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Выключить двухфакторную аутентификацию",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		code, err := readCode("Код из приложения (или код восстановления): ")
+		if err != nil {
+			return err
+		}
+		if err := app.DisableMFA(cmd.Context(), code); err != nil {
+			return fmt.Errorf("ошибка выключения: %w", err)
+		}
+
+		fmt.Println("✅ Двухфакторная аутентификация выключена")
+		return nil
+	},
+}
+
+var TwoFARecoveryCodesCmd = &cobra.Command{
+	Use:   "recovery-codes",
+	Short: "Выпустить новые коды восстановления",
+	Long:  `Выдает новые коды восстановления. Прежние коды перестают действовать.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		code, err := readCode("Код из приложения (или код восстановления): ")
+		if err != nil {
+			return err
+		}
+		codes, err := app.RegenerateRecoveryCodes(cmd.Context(), code)
+		if err != nil {
+			return fmt.Errorf("ошибка выпуска кодов: %w", err)
+		}
+
+		printRecoveryCodes(codes)
+		return nil
+	},
+}
+
+func readCode(label string) (string, error) {
+	return prompt.Line(label)
+}
+
+func printRecoveryCodes(codes []string) {
+	fmt.Println()
+	fmt.Println("🔑 Коды восстановления (каждый действует один раз, сохраните их в надежном месте):")
+	for _, code := range codes {
+		fmt.Printf("   %s\n", code)
+	}
+}
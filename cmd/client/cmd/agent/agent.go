@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/agent"
+
+	"github.com/spf13/cobra"
+)
+
+// AgentCmd - родительская команда фонового агента синхронизации
+var AgentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Фоновый агент синхронизации",
+	Long: `Агент периодически синхронизирует данные с сервером в фоне.
+
+Агент держит мастер-ключ разблокированным в памяти до автоблокировки
+(AUTO_LOCK) и отвечает другим командам и интеграциям (расширение браузера,
+SSH askpass) через локальный IPC: unix-сокет agent.sock в директории
+конфигурации или именованный канал в Windows.
+
+Команда start запускает агент в фоне до перезагрузки, install регистрирует
+его как пользовательский сервис (systemd в Linux, launchd в macOS), чтобы
+синхронизация продолжалась после перезагрузки без ручного запуска.`,
+}
+
+var RunCmd = &cobra.Command{
+	Use:     "run",
+	Aliases: []string{"serve"},
+	Short:   "Запустить агент в текущем терминале",
+	Long: `Запускает автоматическую синхронизацию с интервалом SYNC_INTERVAL_SECONDS
+и работает до получения сигнала завершения. Эту команду вызывает сервис,
+установленный через gophkeeper agent install.
+
+Если задан AGENT_HTTP_ADDR (gophkeeper config set agent-http-addr 127.0.0.1:8200),
+агент также отдает расшифрованные поля записей по HTTP в формате Vault KV:
+GET /v1/secret/<запись> с токеном из gophkeeper agent token в заголовке
+X-Vault-Token. Пока мастер-ключ заблокирован, API отвечает 503.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+		if !app.IsAuthenticated() {
+			// Не завершаемся: сервис перезапускал бы агент в цикле до входа в систему
+			fmt.Println("⚠️  Вход не выполнен: синхронизация начнется после gophkeeper auth login")
+		}
+
+		return app.Run(cmd.Context())
+	},
+}
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Показать состояние запущенного агента",
+	Long: `Подключается к агенту через локальный IPC (unix-сокет в директории
+конфигурации или именованный канал в Windows) и выводит его состояние.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
+		defer cancel()
+
+		status, err := app.GetAgentStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("=== Агент GophKeeper ===")
+		fmt.Printf("PID: %d\n", status.PID)
+		fmt.Printf("Адрес IPC: %s\n", status.Address)
+		fmt.Printf("Запущен: %s\n", status.StartedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Сервер: %s\n", status.ServerAddress)
+		fmt.Printf("Интервал синхронизации: %d сек\n", status.SyncInterval)
+		fmt.Printf("Вход выполнен: %s\n", yesNo(status.Authenticated))
+		fmt.Printf("Мастер-ключ разблокирован: %s\n", yesNo(status.MasterKeyUnlocked))
+		if status.AutoLock > 0 {
+			fmt.Printf("Автоблокировка: через %s простоя\n", time.Duration(status.AutoLock)*time.Second)
+		} else {
+			fmt.Println("Автоблокировка: выключена")
+		}
+		fmt.Printf("Подтверждение выдачи секретов: %s\n", yesNo(status.Confirm))
+		if status.HTTPAddress != "" {
+			fmt.Printf("HTTP-API секретов: http://%s/v1/secret/<запись>\n", status.HTTPAddress)
+		}
+		if status.LastSync.IsZero() {
+			fmt.Println("Последняя синхронизация: никогда")
+		} else {
+			fmt.Printf("Последняя синхронизация: %s\n", status.LastSync.Format("2006-01-02 15:04:05"))
+		}
+
+		return nil
+	},
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "да"
+	}
+	return "нет"
+}
+
+var rotateToken bool
+
+var TokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Показать токен HTTP-API секретов агента",
+	Long: `Выводит токен, который локальные инструменты передают HTTP-API секретов
+агента в заголовке X-Vault-Token (или Authorization: Bearer). Токен
+создается при первом вызове и хранится в файле agent_token директории
+конфигурации с правами 0600.
+
+С --rotate создается новый токен; запущенный агент примет его после
+перезапуска.`,
+	Example: `  export VAULT_ADDR=http://127.0.0.1:8200
+  export VAULT_TOKEN=$(gophkeeper agent token)
+  curl -H "X-Vault-Token: $VAULT_TOKEN" $VAULT_ADDR/v1/secret/Postgres`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		var token string
+		var err error
+		if rotateToken {
+			token, err = app.RotateAgentHTTPToken()
+		} else {
+			token, err = app.AgentHTTPToken()
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(token)
+		if app.Config().AgentHTTPAddr == "" {
+			fmt.Fprintln(cmd.ErrOrStderr(), "⚠️  HTTP-API агента выключен: gophkeeper config set agent-http-addr 127.0.0.1:8200")
+		}
+		return nil
+	},
+}
+
+func init() {
+	TokenCmd.Flags().BoolVar(&rotateToken, "rotate", false, "создать новый токен")
+}
+
+var InstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Установить агент как сервис пользователя",
+	Long: `Записывает пользовательский юнит systemd (~/.config/systemd/user/gophkeeper-agent.service)
+или агент launchd (~/Library/LaunchAgents/com.gophkeeper.agent.plist), включает
+автозапуск и запускает агент.
+
+Текущие настройки клиента (адрес сервера, директория конфигурации, интервал
+синхронизации) сохраняются в файле сервиса. После их изменения повторите install.
+
+Агент профиля (--profile work) устанавливается отдельным сервисом
+gophkeeper-agent-work (com.gophkeeper.agent.work) и работает одновременно
+с агентами других профилей.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+
+		installer, err := agent.NewInstaller(app.Config().Profile)
+		if err != nil {
+			return err
+		}
+
+		spec, err := agentSpec(cmd, app)
+		if err != nil {
+			return err
+		}
+
+		if err := installer.Install(spec); err != nil {
+			return fmt.Errorf("ошибка установки агента: %w", err)
+		}
+
+		fmt.Println("✅ Агент установлен и запущен")
+		fmt.Printf("Файл сервиса: %s\n", installer.Path())
+		if !app.IsAuthenticated() {
+			fmt.Println("⚠️  Вход не выполнен: агент начнет синхронизацию после gophkeeper auth login")
+		}
+
+		return nil
+	},
+}
+
+var UninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Удалить сервис агента",
+	Long:  `Останавливает агент, отключает автозапуск и удаляет файл сервиса.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		installer, err := agent.NewInstaller(app.Config().Profile)
+		if err != nil {
+			return err
+		}
+
+		if err := installer.Uninstall(); err != nil {
+			return fmt.Errorf("ошибка удаления агента: %w", err)
+		}
+
+		fmt.Println("✅ Агент остановлен и удален")
+		fmt.Printf("Удален файл: %s\n", installer.Path())
+
+		return nil
+	},
+}
+
+// agentSpec описывает запуск агента текущим бинарником с конфигурацией из --config
+func agentSpec(cmd *cobra.Command, app *client.App) (*agent.Spec, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить путь к gophkeeper: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	configFile, _ := cmd.Flags().GetString("config")
+	if configFile != "" {
+		if configFile, err = filepath.Abs(configFile); err != nil {
+			return nil, fmt.Errorf("неверный путь к конфигурации: %w", err)
+		}
+	}
+
+	return agent.NewSpec(app.Config(), executable, configFile), nil
+}
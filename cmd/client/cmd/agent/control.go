@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/agent"
+	"gophkeeper/internal/app/client/ipc"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// agentStartTimeout - сколько ждать, пока запущенный агент откроет IPC
+	agentStartTimeout = 10 * time.Second
+	// agentStopTimeout - сколько ждать завершения агента после stop
+	agentStopTimeout  = 5 * time.Second
+	agentPollInterval = 200 * time.Millisecond
+)
+
+var startUnlock bool
+
+var StartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Запустить агент в фоне",
+	Long: `Запускает gophkeeper agent run фоновым процессом, отвязанным от терминала,
+и ждет, пока агент начнет принимать запросы. Журнал агента пишется в
+agent.log в директории конфигурации.
+
+С --unlock после запуска запрашивается мастер-пароль: агент держит ключ
+разблокированным в памяти до автоблокировки (AUTO_LOCK) или gophkeeper agent lock.`,
+	Example: `  gophkeeper agent start --unlock
+  gophkeeper agent get gk://Postgres/password`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+
+		if status, err := app.GetAgentStatus(cmd.Context()); err == nil {
+			fmt.Printf("ℹ️  Агент уже запущен (PID %d)\n", status.PID)
+		} else {
+			spec, err := agentSpec(cmd, app)
+			if err != nil {
+				return err
+			}
+
+			if _, err := agent.StartDetached(spec); err != nil {
+				return err
+			}
+
+			status, err := waitAgent(cmd.Context(), app)
+			if err != nil {
+				return fmt.Errorf("агент не ответил за %s, подробности в %s: %w", agentStartTimeout, spec.LogPath, err)
+			}
+			fmt.Printf("✅ Агент запущен (PID %d)\n", status.PID)
+		}
+
+		if startUnlock {
+			return unlockAgent(cmd.Context(), app)
+		}
+		return nil
+	},
+}
+
+// waitAgent ждет, пока только что запущенный агент начнет отвечать по IPC
+func waitAgent(ctx context.Context, app *client.App) (*client.AgentStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, agentStartTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(agentPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := app.GetAgentStatus(ctx)
+		if err == nil {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-ticker.C:
+		}
+	}
+}
+
+var StopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Остановить запущенный агент",
+	Long: `Просит агент завершиться: мастер-ключ стирается из памяти агента,
+фоновая синхронизация останавливается. Агент, установленный как сервис,
+может быть перезапущен системой; чтобы отключить его, выполните
+gophkeeper agent uninstall.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if err := app.AgentStop(cmd.Context()); err != nil {
+			if errors.Is(err, ipc.ErrAgentNotRunning) {
+				fmt.Println("ℹ️  Агент не запущен")
+				return nil
+			}
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), agentStopTimeout)
+		defer cancel()
+		for {
+			if _, err := app.GetAgentStatus(ctx); err != nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("агент не завершился за %s", agentStopTimeout)
+			case <-time.After(agentPollInterval):
+			}
+		}
+
+		fmt.Println("✅ Агент остановлен")
+		return nil
+	},
+}
+
+var UnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Разблокировать мастер-ключ в агенте",
+	Long: `Запрашивает мастер-пароль и передает его запущенному агенту по IPC.
+Агент держит ключ в памяти до автоблокировки (AUTO_LOCK), выхода системы
+из спящего режима или gophkeeper agent lock.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		return unlockAgent(cmd.Context(), app)
+	},
+}
+
+func unlockAgent(ctx context.Context, app *client.App) error {
+	// Без агента пароль спрашивать незачем
+	status, err := app.GetAgentStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if status.MasterKeyUnlocked {
+		fmt.Println("ℹ️  Мастер-ключ в агенте уже разблокирован")
+		return nil
+	}
+
+	password, err := prompt.Password("Мастер-пароль: ")
+	if err != nil {
+		return fmt.Errorf("ошибка чтения пароля: %w", err)
+	}
+
+	if _, err := app.AgentUnlock(ctx, password); err != nil {
+		return err
+	}
+
+	fmt.Println("🔓 Мастер-ключ разблокирован в агенте")
+	return nil
+}
+
+var LockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Заблокировать мастер-ключ в агенте",
+	Long:  `Стирает мастер-ключ из памяти агента. Синхронизация продолжается, выдача секретов - нет.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if err := app.AgentLock(cmd.Context()); err != nil {
+			return err
+		}
+
+		fmt.Println("🔒 Мастер-ключ в агенте заблокирован")
+		return nil
+	},
+}
+
+var getClient string
+
+var GetCmd = &cobra.Command{
+	Use:   "get <gk://запись/поле>",
+	Short: "Получить поле записи у агента",
+	Long: `Запрашивает у запущенного агента значение поля записи и выводит его
+без перевода строки. Мастер-пароль не нужен: агент должен быть
+разблокирован (gophkeeper agent unlock).
+
+Если включено подтверждение (gophkeeper config set agent-confirm true),
+агент спрашивает пользователя через программу AGENT_CONFIRM_COMMAND;
+в вопросе показывается имя из --client.`,
+	Example: `  export PGPASSWORD=$(gophkeeper agent get gk://Postgres/password)
+  gophkeeper agent get --client ssh gk://Сервер/passphrase`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		value, err := app.AgentSecret(cmd.Context(), args[0], getClient)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(value)
+		return nil
+	},
+}
+
+func init() {
+	StartCmd.Flags().BoolVar(&startUnlock, "unlock", false, "разблокировать мастер-ключ в агенте после запуска")
+	GetCmd.Flags().StringVar(&getClient, "client", "gophkeeper agent get", "имя программы в вопросе подтверждения")
+}
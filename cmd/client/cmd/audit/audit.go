@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFormat string
+	minEntropy   float64
+	maxAgeDays   int
+)
+
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Проверить надежность паролей",
+	Long: `Проверяет пароли в записях логинов:
+- слабые пароли (оценка энтропии ниже порога)
+- один и тот же пароль в нескольких записях
+- старые пароли (запись не изменялась дольше порога)
+
+Записи расшифровываются только локально. В отчет попадают ID, названия
+и оценки записей; пароли и логины не выводятся и на сервер не отправляются.`,
+	Example: `  gophkeeper audit
+  gophkeeper audit --min-entropy 60 --max-age 180
+  gophkeeper audit -o json`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if outputFormat != "text" && outputFormat != "json" {
+			return fmt.Errorf("неизвестный формат вывода: %s (text, json)", outputFormat)
+		}
+		if maxAgeDays <= 0 {
+			return fmt.Errorf("--max-age должен быть положительным")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			return client.ErrMasterKeyLocked
+		}
+
+		report, err := app.AuditPasswords(cmd.Context(), client.AuditOptions{
+			MinEntropy: minEntropy,
+			MaxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка аудита: %w", err)
+		}
+
+		output.UseFlag(outputFormat)
+		return output.Render(output.Result{
+			Value: report,
+			IDs:   report.IssueIDs(),
+			Text: func() error {
+				printReport(report)
+				return nil
+			},
+		})
+	},
+}
+
+func printReport(report *client.AuditReport) {
+	fmt.Println("=== Аудит паролей ===")
+	fmt.Printf("Проверено логинов: %d\n", report.Total)
+
+	if len(report.Failed) > 0 {
+		fmt.Printf("⚠️  Не удалось расшифровать записи: %v\n", report.Failed)
+	}
+
+	if report.Issues() == 0 {
+		fmt.Println()
+		fmt.Println("✅ Проблем не найдено")
+		return
+	}
+
+	if len(report.Weak) > 0 {
+		fmt.Println()
+		fmt.Printf("🔓 Слабые пароли (энтропия ниже %.0f бит): %d\n", report.MinEntropy, len(report.Weak))
+		for _, rec := range report.Weak {
+			fmt.Printf("   %s — %.0f бит, %s\n", recordLine(rec), rec.Entropy, strengthName(rec.Strength))
+		}
+	}
+
+	if len(report.Reused) > 0 {
+		fmt.Println()
+		fmt.Printf("🔁 Повторяющиеся пароли: %d групп\n", len(report.Reused))
+		for i, group := range report.Reused {
+			fmt.Printf("   Группа %d (%d записей):\n", i+1, len(group.Records))
+			for _, rec := range group.Records {
+				fmt.Printf("      %s\n", recordLine(rec))
+			}
+		}
+	}
+
+	if len(report.Old) > 0 {
+		fmt.Println()
+		fmt.Printf("⏳ Старые пароли (не менялись дольше %d дн.): %d\n", report.MaxAgeDays, len(report.Old))
+		for _, rec := range report.Old {
+			fmt.Printf("   %s — %d дн., изменен %s\n", recordLine(rec), rec.AgeDays, rec.LastModified.Format("2006-01-02"))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Записей с проблемами: %d из %d\n", report.Issues(), report.Total)
+}
+
+func recordLine(rec client.AuditRecord) string {
+	line := fmt.Sprintf("[%d] %s", rec.RecordID, rec.Title)
+	if rec.Resource != "" {
+		line += " (" + rec.Resource + ")"
+	}
+	return line
+}
+
+func strengthName(s client.PasswordStrength) string {
+	switch s {
+	case client.StrengthVeryWeak:
+		return "очень слабый"
+	case client.StrengthWeak:
+		return "слабый"
+	case client.StrengthReasonable:
+		return "средний"
+	default:
+		return "надежный"
+	}
+}
+
+func init() {
+	AuditCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "формат вывода (text, json)")
+	AuditCmd.Flags().Float64Var(&minEntropy, "min-entropy", client.DefaultAuditMinEntropy, "минимальная энтропия пароля в битах")
+	AuditCmd.Flags().IntVar(&maxAgeDays, "max-age", int(client.DefaultAuditMaxAge/(24*time.Hour)), "возраст пароля в днях, после которого он считается старым")
+}
@@ -8,5 +8,5 @@ import (
 var AuthCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Управление пользователем",
-	Long:  `Авторизация, регистрация, изменение пароля.`,
+	Long:  `Авторизация, регистрация, изменение пароля, активные сессии.`,
 }
@@ -0,0 +1,67 @@
+// cmd/client/cmd/auth/change_password.go
+package auth
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var ChangePasswordCmd = &cobra.Command{
+	Use:   "change-password",
+	Short: "Сменить пароль входа",
+	Long: `Меняет пароль входа в учетную запись на сервере.
+
+После смены сервер завершает все сессии учетной записи: на других
+устройствах нужно снова выполнить gophkeeper auth login. Это устройство
+получает новый токен и остается в системе.
+
+Пароль входа не защищает данные: мастер-пароль меняется отдельно командой
+gophkeeper key change-password.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsAuthenticated() {
+			return client.ErrAuthRequired
+		}
+
+		oldPassword, err := prompt.Password("Текущий пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		newPassword, err := prompt.Password("Новый пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		passwordConfirm, err := prompt.Password("Повторите новый пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		if newPassword != passwordConfirm {
+			return fmt.Errorf("пароли не совпадают")
+		}
+
+		if len(newPassword) < 8 {
+			return fmt.Errorf("пароль должен содержать минимум 8 символов")
+		}
+
+		if err := app.ChangeAccountPassword(cmd.Context(), oldPassword, newPassword); err != nil {
+			return err
+		}
+
+		fmt.Println("✅ Пароль входа изменен")
+		fmt.Println("🔒 Сессии на других устройствах завершены")
+		return nil
+	},
+}
@@ -3,14 +3,14 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
 	"gophkeeper/internal/app/client"
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 
 	"gophkeeper/internal/domain/user"
 )
@@ -24,7 +24,13 @@ var LoginCmd = &cobra.Command{
 	Short: "Войти в систему GophKeeper",
 	Long: `Аутентификация на сервере GophKeeper.
 	
-После входа токен сохраняется локально для последующих операций.`,
+После входа токен сохраняется локально для последующих операций.
+Если для учетной записи включена двухфакторная аутентификация
+(gophkeeper account 2fa enable), после пароля запрашивается код
+из приложения-аутентификатора или одноразовый код восстановления.
+
+На новом устройстве с существующей учетной записью используйте
+gophkeeper setup: он загрузит мастер-ключ учетной записи с сервера.`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
 		if app == nil {
@@ -35,43 +41,38 @@ var LoginCmd = &cobra.Command{
 		fmt.Println()
 
 		// Запрашиваем email
-		fmt.Print("Email: ")
-		var email string
-		_, _ = fmt.Scanln(&email)
+		email, err := prompt.Line("Email: ")
+		if err != nil {
+			return err
+		}
 
 		// Запрашиваем пароль
-		fmt.Print("Пароль: ")
-		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		password, err := prompt.Password("Пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
 		// Проверяем, инициализирован ли мастер-ключ
 		if !app.IsInitialized() {
 			// Первый вход - инициализируем мастер-ключ
-			fmt.Print("Мастер-пароль (для шифрования данных): ")
-			masterPassword, err := term.ReadPassword(int(os.Stdin.Fd()))
+			masterPassword, err := prompt.Password("Мастер-пароль (для шифрования данных): ")
 			if err != nil {
 				return fmt.Errorf("ошибка чтения мастер-пароля: %w", err)
 			}
-			fmt.Println()
 
-			if err := app.InitMasterKey(string(masterPassword)); err != nil {
+			if err := app.InitMasterKey(masterPassword); err != nil {
 				return fmt.Errorf("ошибка инициализации мастер-ключа: %w", err)
 			}
 			fmt.Println("✓ Мастер-ключ инициализирован")
 		} else {
 			// Разблокируем существующий мастер-ключ
-			fmt.Print("Мастер-пароль (для расшифровки данных): ")
-			masterPassword, err := term.ReadPassword(int(os.Stdin.Fd()))
+			masterPassword, err := prompt.Password("Мастер-пароль (для расшифровки данных): ")
 			if err != nil {
 				return fmt.Errorf("ошибка чтения мастер-пароля: %w", err)
 			}
-			fmt.Println()
 
-			if err := app.UnlockMasterKey(string(masterPassword)); err != nil {
-				return fmt.Errorf("неверный мастер-пароль: %w", err)
+			if err := app.UnlockMasterKey(masterPassword); err != nil {
+				return fmt.Errorf("ошибка разблокировки: %w", err)
 			}
 		}
 
@@ -82,8 +83,19 @@ var LoginCmd = &cobra.Command{
 
 		token, err := app.Login(ctx, user.BaseRequest{
 			Login:    email,
-			Password: string(password),
+			Password: password,
 		})
+		var mfaErr *client.MFARequiredError
+		if errors.As(err, &mfaErr) {
+			// Второй шаг: код из приложения-аутентификатора или код восстановления
+			var code string
+			code, err = prompt.Line("Код двухфакторной аутентификации (или код восстановления): ")
+			if err != nil {
+				return err
+			}
+
+			token, err = app.LoginMFA(ctx, email, mfaErr.Challenge, code)
+		}
 		if err != nil {
 			return fmt.Errorf("ошибка аутентификации: %w", err)
 		}
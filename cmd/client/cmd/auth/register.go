@@ -4,11 +4,10 @@ package auth
 import (
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
 	"gophkeeper/internal/app/client"
-	"os"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 
 	"gophkeeper/internal/domain/user"
 )
@@ -30,26 +29,23 @@ var RegisterCmd = &cobra.Command{
 		fmt.Println()
 
 		// Запрашиваем email
-		fmt.Print("Login: ")
-		var login string
-		_, _ = fmt.Scanln(&login)
+		login, err := prompt.Line("Login: ")
+		if err != nil {
+			return err
+		}
 
 		// Запрашиваем пароль
-		fmt.Print("Пароль: ")
-		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		password, err := prompt.Password("Пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
-		fmt.Print("Повторите пароль: ")
-		passwordConfirm, err := term.ReadPassword(int(os.Stdin.Fd()))
+		passwordConfirm, err := prompt.Password("Повторите пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
-		if string(password) != string(passwordConfirm) {
+		if password != passwordConfirm {
 			return fmt.Errorf("пароли не совпадают")
 		}
 
@@ -61,7 +57,7 @@ var RegisterCmd = &cobra.Command{
 		fmt.Println("Регистрация...")
 		err = app.Register(cmd.Context(), user.BaseRequest{
 			Login:    login,
-			Password: string(password),
+			Password: password,
 		})
 		if err != nil {
 			return fmt.Errorf("ошибка регистрации: %w", err)
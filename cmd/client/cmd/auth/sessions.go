@@ -0,0 +1,166 @@
+// cmd/client/cmd/auth/sessions.go
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/session"
+
+	"github.com/spf13/cobra"
+)
+
+var revokeOthers bool
+
+var SessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Активные сессии учетной записи",
+	Long: `Показывает сессии входа в учетную запись: устройство, IP-адрес,
+User-Agent клиента, время входа и последнего использования. Сессия этого
+клиента отмечена звездочкой.
+
+Незнакомую сессию завершите командой gophkeeper auth revoke <id> и
+смените пароль входа: gophkeeper auth change-password.`,
+	Example: `  gophkeeper auth sessions
+  gophkeeper auth sessions --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		sessions, err := app.Sessions(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, len(sessions))
+		for i, s := range sessions {
+			ids[i] = s.ID
+		}
+
+		return output.Render(output.Result{
+			Value: sessions,
+			IDs:   ids,
+			Text: func() error {
+				printSessions(sessions)
+				return nil
+			},
+		})
+	},
+}
+
+var RevokeCmd = &cobra.Command{
+	Use:   "revoke [session-id]",
+	Short: "Завершить сессию",
+	Long: `Завершает сессию по ID из списка gophkeeper auth sessions: ее токен
+больше не принимается сервером, и на том устройстве нужно снова выполнить
+gophkeeper auth login. Завершение сессии этого клиента равносильно выходу.
+
+С флагом --others завершаются все сессии, кроме сессии этого клиента.`,
+	Example: `  gophkeeper auth revoke 12
+  gophkeeper auth revoke --others`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if revokeOthers {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if revokeOthers {
+			revoked, err := app.RevokeOtherSessions(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ Завершено сессий: %d\n", revoked)
+			return nil
+		}
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id <= 0 {
+			return fmt.Errorf("неверный ID сессии: %s", args[0])
+		}
+
+		current, err := app.RevokeSession(cmd.Context(), id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Сессия %d завершена\n", id)
+		if current {
+			fmt.Println("🔒 Это была сессия этого клиента: войдите снова командой gophkeeper auth login")
+		}
+		return nil
+	},
+}
+
+func init() {
+	RevokeCmd.Flags().BoolVar(&revokeOthers, "others", false, "завершить все сессии, кроме сессии этого клиента")
+}
+
+func printSessions(sessions []session.Session) {
+	if len(sessions) == 0 {
+		fmt.Println("Активных сессий нет")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, " \tID\tУСТРОЙСТВО\tIP\tКЛИЕНТ\tВХОД\tИСПОЛЬЗОВАНА")
+	for _, s := range sessions {
+		marker := " "
+		if s.Current {
+			marker = "*"
+		}
+		lastUsed := "-"
+		if s.LastUsedAt != nil {
+			lastUsed = formatTime(*s.LastUsedAt)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", marker, s.ID, sessionDevice(s),
+			orDash(s.IPAddress), shorten(orDash(s.UserAgent), 40), formatTime(s.CreatedAt), lastUsed)
+	}
+	_ = w.Flush()
+}
+
+// sessionDevice возвращает имя устройства, а если у устройства нет имени -
+// отпечаток его ключа
+func sessionDevice(s session.Session) string {
+	switch {
+	case s.DeviceName != "":
+		return s.DeviceName
+	case s.DeviceFingerprint != "":
+		return s.DeviceFingerprint
+	default:
+		return "-"
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// shorten обрезает строку до n символов
+func shorten(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
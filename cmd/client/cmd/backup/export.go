@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/crypto"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputPath          string
+	separatePassphrase  bool
+	overwriteBackupFile bool
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Создать зашифрованную резервную копию хранилища",
+	Long: `Сохраняет все локальные записи, мастер-ключ (в зашифрованном виде) и метаданные
+синхронизации в один файл, зашифрованный AES-256-GCM.
+
+По умолчанию ключ копии выводится из мастер-пароля. С флагом --passphrase
+можно задать отдельную парольную фразу для резервной копии.
+
+Восстановление: gophkeeper import-backup <файл>`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+
+		if !overwriteBackupFile {
+			if _, err := os.Stat(outputPath); err == nil {
+				return fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", outputPath)
+			}
+		}
+
+		var password, keySource string
+		if separatePassphrase {
+			pass, err := readPassword("Парольная фраза резервной копии: ")
+			if err != nil {
+				return err
+			}
+			confirm, err := readPassword("Повторите парольную фразу: ")
+			if err != nil {
+				return err
+			}
+			if pass != confirm {
+				return fmt.Errorf("парольные фразы не совпадают")
+			}
+			if len(pass) < 8 {
+				return fmt.Errorf("парольная фраза должна содержать минимум 8 символов")
+			}
+			password, keySource = pass, crypto.BackupKeySourcePassphrase
+		} else {
+			pass, err := readPassword("Мастер-пароль: ")
+			if err != nil {
+				return err
+			}
+			if err := app.VerifyMasterPassword(pass); err != nil {
+				return fmt.Errorf("неверный мастер-пароль")
+			}
+			password, keySource = pass, crypto.BackupKeySourceMasterPassword
+		}
+
+		// Пишем во временный файл, чтобы не оставить обрезанную копию при ошибке
+		tmpPath := outputPath + ".tmp"
+		file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("ошибка создания файла: %w", err)
+		}
+
+		result, err := app.ExportBackup(cmd.Context(), file, password, keySource)
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка записи файла: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("ошибка создания резервной копии: %w", err)
+		}
+
+		if err := os.Rename(tmpPath, outputPath); err != nil {
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("ошибка сохранения файла: %w", err)
+		}
+
+		fmt.Printf("✅ Резервная копия сохранена: %s\n", outputPath)
+		fmt.Printf("   Записей: %d\n", result.Records)
+
+		return nil
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringVarP(&outputPath, "output", "o", "vault.gkbackup", "путь к файлу резервной копии")
+	ExportCmd.Flags().BoolVar(&separatePassphrase, "passphrase", false, "зашифровать отдельной парольной фразой вместо мастер-пароля")
+	ExportCmd.Flags().BoolVarP(&overwriteBackupFile, "force", "f", false, "перезаписать существующий файл")
+}
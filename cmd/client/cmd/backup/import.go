@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/crypto"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importRulesPath string
+	onDuplicate     string
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import-backup [file]",
+	Short: "Восстановить записи из резервной копии",
+	Long: `Восстанавливает записи из файла, созданного командой gophkeeper export.
+
+На новом устройстве вместе с записями восстанавливается мастер-ключ и метаданные
+синхронизации, поэтому после входа синхронизация продолжится с момента создания копии.
+Локальные записи заменяются только более новыми версиями из копии.
+
+С флагом --rules добавленные и обновленные записи раскладываются по тегам и
+категориям: правила из JSON-файла сопоставляют регулярные выражения с названием
+и ресурсом записи.
+
+Новые записи сравниваются с локальными: точные копии пропускаются, а логин с тем
+же ресурсом и именем пользователя обрабатывается по политике --on-duplicate:
+  skip      - оставить локальную запись (по умолчанию)
+  overwrite - заменить локальную запись записью из копии
+  merge     - дополнить пустые поля локальной записи и объединить теги
+Логины сравниваются только при разблокированном мастер-ключе.`,
+	Example: `  gophkeeper import-backup vault.gkbackup
+  gophkeeper import-backup vault.gkbackup --rules import-rules.json
+  gophkeeper import-backup vault.gkbackup --on-duplicate merge`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		policy, err := client.ParseDuplicatePolicy(onDuplicate)
+		if err != nil {
+			return err
+		}
+		opts := client.ImportOptions{OnDuplicate: policy}
+		if importRulesPath != "" {
+			if opts.Rules, err = client.LoadImportRules(importRulesPath); err != nil {
+				return err
+			}
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("ошибка открытия файла: %w", err)
+		}
+		defer file.Close()
+
+		reader, err := client.ReadBackupHeader(file)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения резервной копии: %w", err)
+		}
+
+		header := reader.Header()
+		fmt.Printf("Резервная копия от %s\n", header.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+
+		prompt := "Мастер-пароль: "
+		if header.KeySource == crypto.BackupKeySourcePassphrase {
+			prompt = "Парольная фраза резервной копии: "
+		}
+		password, err := readPassword(prompt)
+		if err != nil {
+			return err
+		}
+
+		result, err := app.ImportBackup(cmd.Context(), reader, password, opts)
+		if err != nil {
+			if errors.Is(err, crypto.ErrBackupPassword) {
+				return fmt.Errorf("неверный пароль или файл поврежден")
+			}
+			return fmt.Errorf("ошибка восстановления: %w", err)
+		}
+
+		fmt.Println("✅ Резервная копия восстановлена")
+		if result.MasterKeyRestored {
+			fmt.Println("   Мастер-ключ восстановлен. Разблокируйте его: gophkeeper unlock")
+		}
+		fmt.Printf("   Записей в копии: %d\n", result.Records)
+		fmt.Printf("   Добавлено:       %d\n", result.Imported)
+		fmt.Printf("   Обновлено:       %d\n", result.Updated)
+		fmt.Printf("   Объединено:      %d\n", result.Merged)
+		fmt.Printf("   Пропущено:       %d\n", result.Skipped)
+		if result.Duplicates > 0 {
+			fmt.Printf("   Совпало с локальными: %d (политика %s)\n", result.Duplicates, policy)
+		}
+		if opts.Rules != nil {
+			fmt.Printf("   Разложено по правилам: %d\n", result.Organized)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	ImportCmd.Flags().StringVar(&importRulesPath, "rules", "", "JSON-файл правил: регулярные выражения по названию и ресурсу -> теги и категория")
+	ImportCmd.Flags().StringVar(&onDuplicate, "on-duplicate", string(client.DuplicateSkip), "политика для совпавших логинов: skip, overwrite или merge")
+}
+
+func readPassword(label string) (string, error) {
+	password, err := prompt.Password(label)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения пароля: %w", err)
+	}
+	return password, nil
+}
@@ -0,0 +1,346 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/infrastructure/objectstore"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	targetType     string
+	targetPrefix   string
+	targetInterval time.Duration
+	targetKeep     int
+
+	s3Endpoint  string
+	s3Region    string
+	s3Bucket    string
+	s3AccessKey string
+	s3NoSSL     bool
+
+	webdavURL  string
+	webdavUser string
+
+	driveClientID string
+	driveFolderID string
+
+	pullOutput string
+	pullForce  bool
+)
+
+// BackupCmd - родительская команда удаленных резервных копий
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Резервные копии в S3, WebDAV и Google Drive",
+	Long: `Выгружает зашифрованные копии хранилища в собственные хранилища пользователя -
+второй путь восстановления, не зависящий от сервера GophKeeper.
+
+Копия шифруется парольной фразой цели, поэтому хранилище видит только шифротекст.
+Параметры доступа и парольная фраза сохраняются на этом устройстве в служебном
+файле, зашифрованном ключом машины. Если у цели задан --interval, агент
+(gophkeeper agent run) выгружает копии по расписанию.
+
+Восстановление: gophkeeper backup pull <цель> -o vault.gkbackup,
+затем gophkeeper import-backup vault.gkbackup.`,
+}
+
+// TargetCmd - родительская команда целей резервного копирования
+var TargetCmd = &cobra.Command{
+	Use:   "target",
+	Short: "Цели резервного копирования",
+}
+
+var TargetAddCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Добавить цель резервного копирования",
+	Long: `Добавляет хранилище для резервных копий. Секреты (ключ S3, пароль WebDAV,
+client secret и refresh token Google Drive) и парольная фраза копий
+запрашиваются интерактивно, чтобы не попасть в историю оболочки.
+
+Google Drive: создайте OAuth-клиент, получите refresh token со scope
+https://www.googleapis.com/auth/drive.file и укажите ID папки из ее URL.`,
+	Example: `  gophkeeper backup target add minio --type s3 --endpoint minio.local:9000 --bucket vault --access-key gk --no-ssl --interval 24h
+  gophkeeper backup target add nas --type webdav --url https://nas.local/remote.php/dav/files/alice --username alice --keep 14
+  gophkeeper backup target add drive --type gdrive --client-id 123.apps.googleusercontent.com --folder-id 1AbC --interval 168h`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+
+		target := client.BackupTarget{
+			Name:            args[0],
+			Type:            targetType,
+			Prefix:          targetPrefix,
+			IntervalSeconds: int64(targetInterval / time.Second),
+			KeepLast:        targetKeep,
+		}
+
+		switch targetType {
+		case client.BackupTargetS3:
+			secret, err := readPassword("Secret key S3: ")
+			if err != nil {
+				return err
+			}
+			target.S3 = &objectstore.S3Config{
+				Endpoint:  s3Endpoint,
+				Region:    s3Region,
+				Bucket:    s3Bucket,
+				AccessKey: s3AccessKey,
+				SecretKey: secret,
+				UseSSL:    !s3NoSSL,
+			}
+		case client.BackupTargetWebDAV:
+			target.WebDAV = &objectstore.WebDAVConfig{URL: webdavURL, Username: webdavUser}
+			if webdavUser != "" {
+				if target.WebDAV.Password, err = readPassword("Пароль WebDAV: "); err != nil {
+					return err
+				}
+			}
+		case client.BackupTargetDrive:
+			secret, err := readPassword("Client secret: ")
+			if err != nil {
+				return err
+			}
+			refresh, err := readPassword("Refresh token: ")
+			if err != nil {
+				return err
+			}
+			target.Drive = &objectstore.DriveConfig{
+				ClientID:     driveClientID,
+				ClientSecret: secret,
+				RefreshToken: refresh,
+				FolderID:     driveFolderID,
+			}
+		default:
+			return fmt.Errorf("неизвестный тип хранилища %q (s3, webdav или gdrive)", targetType)
+		}
+
+		pass, err := readPassword("Парольная фраза копий: ")
+		if err != nil {
+			return err
+		}
+		confirm, err := readPassword("Повторите парольную фразу: ")
+		if err != nil {
+			return err
+		}
+		if pass != confirm {
+			return fmt.Errorf("парольные фразы не совпадают")
+		}
+		target.Passphrase = pass
+
+		if err := app.AddBackupTarget(target); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Цель %s добавлена\n", target.Name)
+		fmt.Println("⚠️  Сохраните парольную фразу отдельно: без нее копии не расшифровать")
+		fmt.Printf("   Проверить доступ: gophkeeper backup push %s\n", target.Name)
+		return nil
+	},
+}
+
+var TargetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список целей резервного копирования",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		targets, err := app.BackupTargets()
+		if err != nil {
+			return err
+		}
+		if len(targets) == 0 {
+			fmt.Println("Целей нет. Добавьте: gophkeeper backup target add <имя> --type s3|webdav|gdrive")
+			return nil
+		}
+
+		for _, t := range targets {
+			fmt.Printf("☁️  %s (%s) %s\n", t.Name, t.Type, t.Location())
+			schedule := "вручную"
+			if t.IntervalSeconds > 0 {
+				schedule = "каждые " + t.Interval().String()
+			}
+			fmt.Printf("   Расписание: %s, хранить копий: %d\n", schedule, t.KeepLast)
+			if !t.LastBackup.IsZero() {
+				fmt.Printf("   Последняя копия: %s\n", t.LastBackup.Local().Format("2006-01-02 15:04:05"))
+			}
+			if t.LastError != "" {
+				fmt.Printf("   ❌ Последняя ошибка: %s\n", t.LastError)
+			}
+		}
+		return nil
+	},
+}
+
+var TargetRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Удалить цель резервного копирования",
+	Long:  `Удаляет цель с этого устройства. Выгруженные копии остаются в хранилище.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.RemoveBackupTarget(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️  Цель %s удалена\n", args[0])
+		return nil
+	},
+}
+
+var PushCmd = &cobra.Command{
+	Use:   "push [name]",
+	Short: "Выгрузить резервную копию",
+	Long:  `Выгружает копию хранилища в цель, а без имени - во все цели.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		names := args
+		if len(names) == 0 {
+			targets, err := app.BackupTargets()
+			if err != nil {
+				return err
+			}
+			for _, t := range targets {
+				names = append(names, t.Name)
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("целей нет. Добавьте: gophkeeper backup target add <имя> --type s3|webdav|gdrive")
+			}
+		}
+
+		failed := 0
+		for _, name := range names {
+			backup, err := app.PushBackup(cmd.Context(), name)
+			if err != nil {
+				fmt.Printf("❌ %s: %v\n", name, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ %s: %s (записей: %d, %d байт)\n", name, backup.Key, backup.Records, backup.Size)
+			if backup.Pruned > 0 {
+				fmt.Printf("   Удалено старых копий: %d\n", backup.Pruned)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("не удалось выгрузить копий: %d из %d", failed, len(names))
+		}
+		return nil
+	},
+}
+
+var ListCmd = &cobra.Command{
+	Use:   "list [name]",
+	Short: "Копии в удаленном хранилище",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		keys, err := app.ListRemoteBackups(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			fmt.Printf("В %s нет резервных копий\n", args[0])
+			return nil
+		}
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+		return nil
+	},
+}
+
+var PullCmd = &cobra.Command{
+	Use:   "pull [name] [key]",
+	Short: "Загрузить резервную копию из удаленного хранилища",
+	Long: `Загружает копию (по умолчанию последнюю) в файл. Восстановить записи из
+файла: gophkeeper import-backup <файл> с парольной фразой цели.`,
+	Example: `  gophkeeper backup pull nas -o vault.gkbackup
+  gophkeeper backup pull nas gophkeeper/vault-20261016T120000Z.gkbackup`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !pullForce {
+			if _, err := os.Stat(pullOutput); err == nil {
+				return fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", pullOutput)
+			}
+		}
+
+		key := ""
+		if len(args) == 2 {
+			key = args[1]
+		}
+		data, key, err := app.PullBackup(cmd.Context(), args[0], key)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(pullOutput, data, 0600); err != nil {
+			return fmt.Errorf("ошибка сохранения файла: %w", err)
+		}
+
+		fmt.Printf("✅ Копия %s сохранена: %s\n", key, pullOutput)
+		fmt.Printf("   Восстановить: gophkeeper import-backup %s\n", pullOutput)
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	return app, nil
+}
+
+func init() {
+	f := TargetAddCmd.Flags()
+	f.StringVar(&targetType, "type", "", "тип хранилища: s3, webdav или gdrive")
+	f.StringVar(&targetPrefix, "prefix", "", "каталог копий в хранилище (по умолчанию gophkeeper/, для gdrive - без префикса)")
+	f.DurationVar(&targetInterval, "interval", 0, "период выгрузки агентом, например 24h (0 - только вручную)")
+	f.IntVar(&targetKeep, "keep", 7, "сколько последних копий хранить")
+	f.StringVar(&s3Endpoint, "endpoint", "", "S3: host[:port] или URL")
+	f.StringVar(&s3Region, "region", "us-east-1", "S3: регион")
+	f.StringVar(&s3Bucket, "bucket", "", "S3: бакет")
+	f.StringVar(&s3AccessKey, "access-key", "", "S3: access key")
+	f.BoolVar(&s3NoSSL, "no-ssl", false, "S3: подключаться по HTTP")
+	f.StringVar(&webdavURL, "url", "", "WebDAV: URL каталога")
+	f.StringVar(&webdavUser, "username", "", "WebDAV: имя пользователя")
+	f.StringVar(&driveClientID, "client-id", "", "Google Drive: client id OAuth-клиента")
+	f.StringVar(&driveFolderID, "folder-id", "", "Google Drive: ID папки")
+	_ = TargetAddCmd.MarkFlagRequired("type")
+
+	PullCmd.Flags().StringVarP(&pullOutput, "output", "o", "vault.gkbackup", "путь к файлу резервной копии")
+	PullCmd.Flags().BoolVarP(&pullForce, "force", "f", false, "перезаписать существующий файл")
+}
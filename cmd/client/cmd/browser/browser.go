@@ -0,0 +1,243 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/ipc"
+	"gophkeeper/internal/app/client/nativemsg"
+	"gophkeeper/internal/utils/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// BrowserCmd - родительская команда интеграции с браузерным расширением
+var BrowserCmd = &cobra.Command{
+	Use:   "browser",
+	Short: "Интеграция с браузерным расширением",
+	Long: `Браузерное расширение GophKeeper получает логины для автозаполнения и
+сохраняет новые через хост native messaging (Chrome, Chromium, Edge, Firefox).
+
+Хост передает запросы агенту, который держит мастер-ключ разблокированным:
+gophkeeper agent start --unlock. Пароль выдается только для логина, ресурс
+которого совпадает с адресом страницы, и только после подтверждения
+пользователем программой AGENT_CONFIRM_COMMAND.`,
+}
+
+var (
+	installBrowser string
+	extensionID    string
+)
+
+var InstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Зарегистрировать хост native messaging в браузере",
+	Long: `Записывает манифест хоста com.gophkeeper.native в директорию браузера
+(в Windows - в директорию конфигурации и в реестр HKCU) и скрипт запуска
+native-host.sh (native-host.bat) в директории конфигурации. Манифест
+разрешает подключение только расширению с указанным ID.
+
+Текущие настройки клиента (директория конфигурации, --config) сохраняются
+в скрипте запуска; после их изменения повторите install.`,
+	Example: `  gophkeeper browser install --browser chrome --extension-id abcdefghijklmnopabcdefghijklmnop
+  gophkeeper browser install --browser firefox --extension-id gophkeeper@example.com`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		browser, err := nativemsg.ParseBrowser(installBrowser)
+		if err != nil {
+			return err
+		}
+
+		installer, err := newInstaller(app)
+		if err != nil {
+			return err
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("не удалось определить путь к gophkeeper: %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+
+		var args []string
+		if configFile, _ := cmd.Flags().GetString("config"); configFile != "" {
+			if configFile, err = filepath.Abs(configFile); err != nil {
+				return fmt.Errorf("неверный путь к конфигурации: %w", err)
+			}
+			args = append(args, "--config", configFile)
+		}
+		if profile := app.Config().Profile; profile != "" {
+			args = append(args, "--profile", profile)
+		}
+		args = append(args, "browser", "host")
+
+		// Браузер запускает хост без окружения интерактивной оболочки;
+		// каталог профиля хост выводит из общего каталога по --profile
+		env := map[string]string{"CONFIG_DIR": app.Config().RootDir}
+
+		manifestPath, err := installer.Install(browser, extensionID, executable, args, env)
+		if err != nil {
+			return fmt.Errorf("ошибка регистрации хоста: %w", err)
+		}
+
+		fmt.Printf("✅ Хост native messaging зарегистрирован для %s\n", browser)
+		fmt.Printf("Манифест: %s\n", manifestPath)
+		fmt.Printf("Скрипт запуска: %s\n", installer.LauncherPath())
+		if app.Config().AgentConfirmCommand == "" {
+			fmt.Println("⚠️  Программа подтверждения не задана: агент будет отклонять автозаполнение.")
+			fmt.Println("   Задайте ее: gophkeeper config set agent-confirm-command \"zenity --question --text\"")
+		}
+		return nil
+	},
+}
+
+var UninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Удалить регистрацию хоста в браузере",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		browser, err := nativemsg.ParseBrowser(installBrowser)
+		if err != nil {
+			return err
+		}
+
+		installer, err := newInstaller(app)
+		if err != nil {
+			return err
+		}
+
+		manifestPath, err := installer.Uninstall(browser)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Хост native messaging удален для %s\n", browser)
+		fmt.Printf("Удален файл: %s\n", manifestPath)
+		return nil
+	},
+}
+
+func newInstaller(app *client.App) (*nativemsg.Installer, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить домашнюю директорию: %w", err)
+	}
+
+	configDir, err := filepath.Abs(app.Config().ConfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("неверная директория конфигурации: %w", err)
+	}
+	return nativemsg.NewInstaller(home, runtime.GOOS, configDir), nil
+}
+
+var HostCmd = &cobra.Command{
+	Use:    "host",
+	Short:  "Хост native messaging (запускается браузером)",
+	Hidden: true,
+	Long: `Обменивается сообщениями с браузерным расширением через stdin/stdout.
+Команду запускает браузер через скрипт, записанный gophkeeper browser install.`,
+	// Chrome передает адрес расширения (и --parent-window в Windows),
+	// Firefox - путь к манифесту и ID расширения
+	Args:               cobra.ArbitraryArgs,
+	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		backend := &agentBackend{app: app, client: callerName(args)}
+		// Браузер сохраняет stderr хоста в своем журнале
+		host := nativemsg.NewHost(backend, logger.NewTo(app.Config().Env, os.Stderr))
+		return host.Serve(cmd.Context(), os.Stdin, os.Stdout)
+	},
+}
+
+// callerName возвращает имя расширения из аргументов, переданных браузером
+func callerName(args []string) string {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "chrome-extension://") {
+			return "расширение " + strings.Trim(strings.TrimPrefix(arg, "chrome-extension://"), "/")
+		}
+	}
+	// Firefox: путь к манифесту и ID расширения
+	if len(args) >= 2 {
+		return "расширение " + args[1]
+	}
+	return ""
+}
+
+// agentBackend выполняет запросы расширения через агент
+type agentBackend struct {
+	app    *client.App
+	client string
+}
+
+func (b *agentBackend) Status(ctx context.Context) (*nativemsg.Status, error) {
+	status, err := b.app.GetAgentStatus(ctx)
+	if errors.Is(err, ipc.ErrAgentNotRunning) {
+		return &nativemsg.Status{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &nativemsg.Status{AgentRunning: true, Unlocked: status.MasterKeyUnlocked}, nil
+}
+
+func (b *agentBackend) Logins(ctx context.Context, pageURL string) ([]nativemsg.Login, error) {
+	logins, err := b.app.AgentBrowserLogins(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]nativemsg.Login, 0, len(logins))
+	for _, l := range logins {
+		out = append(out, nativemsg.Login{ID: l.ID, Title: l.Title, Resource: l.Resource, Username: l.Username})
+	}
+	return out, nil
+}
+
+func (b *agentBackend) Credentials(ctx context.Context, pageURL string, recordID int) (*nativemsg.Credentials, error) {
+	creds, err := b.app.AgentBrowserCredentials(ctx, pageURL, recordID, b.client)
+	if err != nil {
+		return nil, err
+	}
+	return &nativemsg.Credentials{ID: creds.ID, Username: creds.Username, Password: creds.Password}, nil
+}
+
+func (b *agentBackend) Save(ctx context.Context, req nativemsg.SaveRequest) (int, error) {
+	return b.app.AgentBrowserSave(ctx, client.BrowserSaveRequest{
+		URL:      req.URL,
+		Title:    req.Title,
+		Username: req.Username,
+		Password: req.Password,
+		Client:   b.client,
+	})
+}
+
+func init() {
+	for _, c := range []*cobra.Command{InstallCmd, UninstallCmd} {
+		c.Flags().StringVar(&installBrowser, "browser", string(nativemsg.Chrome), "браузер: chrome, chromium, edge, firefox")
+	}
+	InstallCmd.Flags().StringVar(&extensionID, "extension-id", "", "ID расширения GophKeeper (обязателен)")
+	_ = InstallCmd.MarkFlagRequired("extension-id")
+}
@@ -0,0 +1,58 @@
+// Package complete - динамическое автодополнение аргументов команд в оболочке.
+// Варианты берутся из локального хранилища без обращения к серверу.
+package complete
+
+import (
+	"strconv"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// RecordID дополняет первый аргумент ID записи; название показывается
+// оболочкой как описание варианта
+func RecordID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return records(cmd, args, toComplete, false)
+}
+
+// TrashedRecordID дополняет первый аргумент ID записи из корзины
+func TrashedRecordID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return records(cmd, args, toComplete, true)
+}
+
+// RecordIDThenFile дополняет ID записи, а следующие аргументы - путями к файлам
+func RecordIDThenFile(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return records(cmd, args, toComplete, false)
+}
+
+func records(cmd *cobra.Command, args []string, toComplete string, trash bool) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Без инициализированного клиента подсказок нет, но и ошибки в оболочке тоже
+	app, ok := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if !ok || app == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	found, err := app.CompleteRecords(toComplete, trash)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := make([]string, 0, len(found))
+	for _, rec := range found {
+		id := strconv.Itoa(rec.ID)
+		if rec.Title != "" {
+			id += "\t" + rec.Title + " (" + string(rec.Type) + ")"
+		}
+		completions = append(completions, id)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
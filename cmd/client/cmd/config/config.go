@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+	clientconfig "gophkeeper/internal/app/client/config"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd - родительская команда локальной конфигурации клиента
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Локальная конфигурация клиента",
+	Long: `Просмотр и изменение локальной конфигурации клиента (config.yaml,
+config.yml или config.toml в каталоге конфигурации или флаг --config).
+
+Значения собираются из слоев, каждый следующий важнее предыдущих:
+значения по умолчанию < файл < раздел профиля в файле < переменные
+окружения < флаги командной строки. Профиль выбирает флаг --profile,
+переменная GOPHKEEPER_PROFILE или ключ profile в файле:
+
+  server_address: localhost:8080
+  profiles:
+    work:
+      server_address: keeper.work.example:443
+      enable_tls: true
+
+В отличие от gophkeeper settings, эти параметры относятся только
+к этому устройству и не синхронизируются.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return printConfig(cmd)
+	},
+}
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Показать все параметры, их значения и источники",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return printConfig(cmd)
+	},
+}
+
+var GetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Показать значение параметра и его источник",
+	Example: `  gophkeeper config get auto-lock
+  gophkeeper --profile work config get server-address`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return printConfig(cmd)
+		}
+
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		value, err := app.Config().Lookup(args[0])
+		if err != nil {
+			return err
+		}
+
+		return output.Render(output.Result{
+			Value: value,
+			Text: func() error {
+				fmt.Println(value.Value)
+				fmt.Fprintf(os.Stderr, "(%s)\n", sourceLabel(app.Config(), value))
+				return nil
+			},
+		})
+	},
+}
+
+var SetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Изменить параметр в файле конфигурации",
+	Long: `Проверяет значение и записывает его в файл конфигурации. Если выбран
+профиль, значение записывается в его раздел (новый профиль создается),
+с флагом --global - на верхний уровень файла.`,
+	Example: `  gophkeeper config set auto-lock 15m
+  gophkeeper config set auto-lock off
+  gophkeeper --profile work config set server-address keeper.work.example:443`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		cfg := app.Config()
+		path := cfg.FilePath()
+		profile := targetProfile(cmd, cfg)
+
+		value, err := clientconfig.SetFileValue(path, profile, args[0], args[1])
+		if err != nil {
+			return err
+		}
+
+		where := path
+		if profile != "" {
+			where = fmt.Sprintf("%s, профиль %s", path, profile)
+		}
+		fmt.Printf("✅ %s = %s (%s)\n", args[0], value, where)
+
+		// Значение из файла не действует, если его переопределяет старший слой
+		if current, err := cfg.Lookup(args[0]); err == nil {
+			switch {
+			case current.Source == clientconfig.SourceEnv || current.Source == clientconfig.SourceFlag:
+				fmt.Printf("⚠️  Сейчас действует %s = %s (%s)\n", current.Key, current.Value, sourceLabel(cfg, current))
+			case current.Source == clientconfig.SourceProfile && profile == "":
+				fmt.Printf("⚠️  Профиль %s переопределяет это значение: %s\n", cfg.Profile, current.Value)
+			}
+		}
+		fmt.Println("Изменение вступит в силу при следующем запуске клиента.")
+		return nil
+	},
+}
+
+func init() {
+	SetCmd.Flags().Bool("global", false, "записать на верхний уровень файла, а не в раздел профиля")
+}
+
+func printConfig(cmd *cobra.Command) error {
+	app, err := appFrom(cmd)
+	if err != nil {
+		return err
+	}
+	cfg := app.Config()
+
+	return output.Render(output.Result{
+		Value: output.Config{File: cfg.FilePath(), Profile: cfg.Profile, Values: cfg.Values},
+		Text: func() error {
+			title := cfg.FilePath()
+			if cfg.File == "" {
+				title += ", файла нет"
+			}
+			if cfg.Profile != "" {
+				title += ", профиль " + cfg.Profile
+			}
+			fmt.Printf("=== Конфигурация клиента (%s) ===\n", title)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, v := range cfg.Values {
+				value := v.Value
+				if value == "" {
+					value = "-"
+				}
+				fmt.Fprintf(w, "  %s:\t%s\t[%s]\t%s\n", v.Key, value, sourceLabel(cfg, v), v.Description)
+			}
+			return w.Flush()
+		},
+	})
+}
+
+// sourceLabel называет слой, из которого взято значение
+func sourceLabel(cfg *clientconfig.Config, v clientconfig.Value) string {
+	switch v.Source {
+	case clientconfig.SourceFlag:
+		return "флаг"
+	case clientconfig.SourceEnv:
+		return "окружение"
+	case clientconfig.SourceProfile:
+		return "профиль " + cfg.Profile
+	case clientconfig.SourceFile:
+		return "файл"
+	default:
+		return "по умолчанию"
+	}
+}
+
+// targetProfile возвращает профиль, в раздел которого пишет config set:
+// пусто - верхний уровень файла
+func targetProfile(cmd *cobra.Command, cfg *clientconfig.Config) string {
+	if global, _ := cmd.Flags().GetBool("global"); global {
+		return ""
+	}
+	// Профиля может еще не быть в файле: config set его создает
+	if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+		return profile
+	}
+	if profile := os.Getenv(clientconfig.ProfileEnv); profile != "" {
+		return profile
+	}
+	return cfg.Profile
+}
+
+func appFrom(cmd *cobra.Command) (*client.App, error) {
+	app, _ := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	return app, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	clientconfig "gophkeeper/internal/app/client/config"
+
+	"github.com/spf13/cobra"
+)
+
+var EncryptDBCmd = &cobra.Command{
+	Use:   "encrypt-db",
+	Short: "Зашифровать локальную базу",
+	Long: `Шифрует файл локальной базы SQLCipher ключом, производным от
+мастер-ключа, и включает encrypt_local_db в файле конфигурации.
+
+После этого база открывается только после разблокировки мастер-ключа.
+Ротация ключа данных не меняет ключ базы. Клиент должен быть собран
+с SQLCipher (-tags libsqlite3 и библиотека libsqlcipher).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return convertDB(cmd, true)
+	},
+}
+
+var DecryptDBCmd = &cobra.Command{
+	Use:   "decrypt-db",
+	Short: "Расшифровать локальную базу",
+	Long: `Переводит зашифрованную локальную базу в открытый формат SQLite
+и выключает encrypt_local_db в файле конфигурации. Записи в базе
+по-прежнему зашифрованы мастер-ключом.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return convertDB(cmd, false)
+	},
+}
+
+func convertDB(cmd *cobra.Command, encrypt bool) error {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsMasterKeyUnlocked() {
+		fmt.Println("❌ Мастер-ключ заблокирован")
+		fmt.Println("Выполните команду: gophkeeper unlock")
+		return client.ErrMasterKeyLocked
+	}
+
+	path := app.Config().FilePath()
+
+	var err error
+	if encrypt {
+		err = app.EncryptLocalDB()
+	} else {
+		err = app.DecryptLocalDB()
+	}
+	if err != nil {
+		if errors.Is(err, client.ErrCipherUnsupported) {
+			fmt.Println("❌ Этот клиент собран без SQLCipher")
+		}
+		return err
+	}
+
+	if _, err := clientconfig.SetFileValue(path, app.Config().Profile, "encrypt-local-db", strconv.FormatBool(encrypt)); err != nil {
+		return fmt.Errorf("база преобразована, но конфигурация не сохранена: %w", err)
+	}
+
+	if encrypt {
+		fmt.Println("🔐 Локальная база зашифрована")
+	} else {
+		fmt.Println("🔓 Локальная база расшифрована")
+	}
+	fmt.Printf("✅ encrypt-local-db = %t (%s)\n", encrypt, path)
+	return nil
+}
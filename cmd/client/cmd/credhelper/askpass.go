@@ -0,0 +1,44 @@
+// cmd/client/cmd/credhelper/askpass.go
+package credhelper
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var AskpassCmd = &cobra.Command{
+	Use:   "askpass [вопрос]",
+	Short: "Ответить на вопрос ssh или git (SSH_ASKPASS, GIT_ASKPASS)",
+	Long: `Печатает секрет в ответ на вопрос ssh или git:
+
+  парольная фраза ключа  SSH-ключ с тем же отпечатком
+  пароль ssh user@host   логин user с ресурсом host
+  логин и пароль git     логин, ресурс которого совпадает с адресом
+
+На другие вопросы (например, подтверждение ключа хоста) ответа нет, и ssh
+считает это отказом. Мастер-ключ должен быть разблокирован (gophkeeper unlock).
+SSH_ASKPASS и GIT_ASKPASS принимают путь к программе без аргументов, поэтому
+команду вызывает небольшой скрипт.`,
+	Example: `  printf '#!/bin/sh\nexec gophkeeper askpass "$@"\n' > ~/.local/bin/gk-askpass
+  chmod +x ~/.local/bin/gk-askpass
+  export SSH_ASKPASS=~/.local/bin/gk-askpass SSH_ASKPASS_REQUIRE=prefer
+  export GIT_ASKPASS=~/.local/bin/gk-askpass`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		answer, err := app.Askpass(cmd.Context(), strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), answer)
+		return nil
+	},
+}
@@ -0,0 +1,54 @@
+// cmd/client/cmd/credhelper/git.go
+package credhelper
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var GitCredentialCmd = &cobra.Command{
+	Use:   "git-credential <get|store|erase>",
+	Short: "Помощник учетных данных git",
+	Long: `Реализует протокол git credential helper: git получает имя пользователя
+и пароль (токен) из логина, ресурс которого совпадает с протоколом и хостом
+репозитория.
+
+  get    выдать логин; без подходящего логина git спросит данные сам
+  store  сохранить принятые данные: создать логин или обновить пароль
+  erase  убрать в корзину логин с отклоненным паролем
+
+Мастер-ключ должен быть разблокирован (gophkeeper unlock).`,
+	Example: `  git config --global credential.helper '!gophkeeper git-credential'
+  git config --global credential.https://github.com.helper '!gophkeeper git-credential'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		req, err := client.ReadGitCredential(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+
+		switch args[0] {
+		case "get":
+			found, err := app.GitCredentialGet(cmd.Context(), req)
+			if err != nil || found == nil {
+				return err
+			}
+			return found.Write(os.Stdout)
+		case "store":
+			return app.GitCredentialStore(cmd.Context(), req)
+		case "erase":
+			return app.GitCredentialErase(cmd.Context(), req)
+		}
+		// Протокол требует молча пропускать незнакомые операции
+		return nil
+	},
+}
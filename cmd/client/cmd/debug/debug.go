@@ -0,0 +1,69 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var bundleOutput string
+
+// DebugCmd - родительская команда отладочных инструментов
+var DebugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Отладочные инструменты",
+}
+
+var BundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Собрать архив для сообщения об ошибке",
+	Long: `Собирает tar.gz-архив для приложения к сообщению об ошибке:
+- конфигурация клиента и состояние (секреты и логин затерты)
+- метаданные, настройки и статистика синхронизации
+- схема локальной базы и число записей по типам
+- последний 1 МБ журнала фонового агента (секреты затерты)
+
+Данные и метаданные записей, мастер-ключ и токены в архив не попадают.
+Имя архива по умолчанию содержит время создания.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		path := bundleOutput
+		if path == "" {
+			path = fmt.Sprintf("gophkeeper-debug-%s.tar.gz", time.Now().Format("20060102-150405"))
+		}
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("ошибка создания файла: %w", err)
+		}
+
+		files, err := app.WriteDebugBundle(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(path)
+			return fmt.Errorf("ошибка создания архива: %w", err)
+		}
+
+		fmt.Printf("✅ Отладочный архив создан: %s\n", path)
+		for _, name := range files {
+			fmt.Printf("   %s\n", name)
+		}
+		fmt.Println("Проверьте содержимое архива перед отправкой.")
+		return nil
+	},
+}
+
+func init() {
+	BundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "", "путь к архиву")
+}
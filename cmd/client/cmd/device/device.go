@@ -0,0 +1,291 @@
+package device
+
+import (
+	"fmt"
+	"strconv"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/spf13/cobra"
+)
+
+// DeviceCmd - родительская команда управления устройствами
+var DeviceCmd = &cobra.Command{
+	Use:     "device",
+	Aliases: []string{"devices"},
+	Short:   "Устройства синхронизации",
+	Long: `Управление устройствами, с которых выполняется синхронизация.
+
+Каждая установка клиента имеет постоянный UUID. Он хранится в device.json
+и в хранилище секретов ОС, поэтому после переустановки клиент остается
+тем же устройством на сервере.
+
+Новое устройство регистрируется со своим открытым ключом и остается
+неподтвержденным, пока его не подтвердят с доверенного устройства
+командой gophkeeper device approve. До этого сервер отказывает ему
+в синхронизации.`,
+}
+
+var (
+	showUsage          bool
+	approveFingerprint string
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список устройств",
+	Long: `Список устройств, с которых выполняется синхронизация.
+
+С флагом --usage для каждого устройства выводятся трафик и число операций
+синхронизации за все время. Устройство, которое отправляет или получает
+заметно больше остальных, может синхронизироваться в цикле или быть
+скомпрометировано: удалите его и смените мастер-пароль.`,
+	Example: `  gophkeeper devices list
+  gophkeeper devices list --usage`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		identity, err := app.DeviceIdentity()
+		if err != nil {
+			return err
+		}
+
+		devices, err := app.GetDevices(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения устройств: %w", err)
+		}
+
+		ids := make([]int, len(devices))
+		for i, d := range devices {
+			ids[i] = d.ID
+		}
+
+		return output.Render(output.Result{
+			Value: output.Devices(devices, identity.UUID),
+			IDs:   ids,
+			Text: func() error {
+				if len(devices) == 0 {
+					fmt.Println("Устройств нет. Устройство регистрируется при первой синхронизации.")
+					return nil
+				}
+
+				if showUsage {
+					printUsage(devices, identity)
+					return nil
+				}
+
+				fmt.Printf("%-6s %-25s %-10s %-20s %-21s %s\n", "ID", "Имя", "Тип", "Синхронизация", "Отпечаток", "")
+				for _, d := range devices {
+					fmt.Printf("%-6d %-25s %-10s %-20s %-21s %s\n", d.ID, d.Name, d.Type, lastSync(d),
+						fingerprint(d), marker(d, identity))
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var RegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Зарегистрировать это устройство",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		response, err := app.RegisterDevice(cmd.Context(), 0)
+		if err != nil {
+			return err
+		}
+
+		printRegistered(response)
+		return nil
+	},
+}
+
+var ClaimCmd = &cobra.Command{
+	Use:   "claim [id]",
+	Short: "Восстановить прежнюю запись устройства после переустановки",
+	Long: `Привязывает эту установку клиента к существующей записи устройства
+на сервере. Используйте, если после переустановки в списке появилось
+новое устройство вместо прежнего (например, хранилище ОС было недоступно).
+
+Запись, созданная этой установкой, и записи того же устройства от старых
+версий клиента удаляются, их конфликты переносятся на восстановленное устройство.`,
+	Example: `  gophkeeper device list
+  gophkeeper device claim 3`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		deviceID, err := strconv.Atoi(args[0])
+		if err != nil || deviceID <= 0 {
+			return fmt.Errorf("неверный ID устройства: %s", args[0])
+		}
+
+		response, err := app.RegisterDevice(cmd.Context(), deviceID)
+		if err != nil {
+			return err
+		}
+
+		printRegistered(response)
+		return nil
+	},
+}
+
+var ApproveCmd = &cobra.Command{
+	Use:   "approve [id]",
+	Short: "Подтвердить новое устройство",
+	Long: `Разрешает синхронизацию устройству, которое ожидает подтверждения.
+Команду выполняют на уже подтвержденном устройстве.
+
+Перед подтверждением сверьте отпечаток ключа: новое устройство выводит его
+при регистрации и в gophkeeper device list. Без --fingerprint команда
+показывает отпечаток и запрашивает подтверждение.`,
+	Example: `  gophkeeper device list
+  gophkeeper device approve 4
+  gophkeeper device approve 4 --fingerprint 1a2b-3c4d-5e6f-7a8b`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		deviceID, err := strconv.Atoi(args[0])
+		if err != nil || deviceID <= 0 {
+			return fmt.Errorf("неверный ID устройства: %s", args[0])
+		}
+
+		devices, err := app.GetDevices(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения устройств: %w", err)
+		}
+		var target *sync.DeviceInfo
+		for i := range devices {
+			if devices[i].ID == deviceID {
+				target = &devices[i]
+				break
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("устройство %d не найдено", deviceID)
+		}
+		if target.Status != sync.DevicePending {
+			fmt.Printf("✅ Устройство %d (%s) уже подтверждено\n", target.ID, target.Name)
+			return nil
+		}
+
+		if approveFingerprint == "" {
+			fmt.Printf("🔑 Устройство %d (%s), отпечаток ключа: %s\n", target.ID, target.Name, fingerprint(*target))
+			ok, err := prompt.Confirm("Отпечаток совпадает с выведенным на новом устройстве?")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("❌ Подтверждение отменено")
+				return nil
+			}
+			approveFingerprint = sync.DeviceFingerprint(target.PublicKey)
+		}
+
+		approved, err := app.ApproveDevice(cmd.Context(), deviceID, approveFingerprint)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Устройство %d (%s) подтверждено\n", approved.ID, approved.Name)
+		return nil
+	},
+}
+
+func appFrom(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	if !app.IsAuthenticated() {
+		return nil, client.ErrAuthRequired
+	}
+	return app, nil
+}
+
+func init() {
+	ListCmd.Flags().BoolVar(&showUsage, "usage", false, "показать трафик и операции синхронизации устройств")
+	ApproveCmd.Flags().StringVar(&approveFingerprint, "fingerprint", "", "ожидаемый отпечаток ключа устройства (без запроса подтверждения)")
+}
+
+// printUsage выводит таблицу трафика устройств: отправлено и получено
+// устройством, число запросов и записей
+func printUsage(devices []sync.DeviceInfo, identity *client.DeviceIdentity) {
+	fmt.Printf("%-6s %-25s %-12s %-12s %-10s %-14s %s\n",
+		"ID", "Имя", "Отправлено", "Получено", "Запросы", "Записи ↑/↓", "")
+	var total sync.DeviceUsage
+	for _, d := range devices {
+		u := d.Usage
+		total = total.Add(u)
+		fmt.Printf("%-6d %-25s %-12s %-12s %-10d %-14s %s\n", d.ID, d.Name,
+			client.FormatBytes(u.BytesUploaded), client.FormatBytes(u.BytesDownloaded), u.Requests,
+			fmt.Sprintf("%d/%d", u.RecordsUploaded, u.RecordsDownloaded), marker(d, identity))
+	}
+	fmt.Printf("\n📊 Всего: отправлено %s, получено %s, запросов %d\n",
+		client.FormatBytes(total.BytesUploaded), client.FormatBytes(total.BytesDownloaded), total.Requests)
+}
+
+func printRegistered(response *sync.RegisterDeviceResponse) {
+	if response.Data.Status == sync.DevicePending {
+		fmt.Printf("⏳ Устройство %d (%s) ожидает подтверждения\n", response.Data.ID, response.Data.Name)
+		fmt.Printf("🔑 Отпечаток ключа: %s\n", sync.DeviceFingerprint(response.Data.PublicKey))
+		fmt.Printf("Подтвердите его на доверенном устройстве: gophkeeper device approve %d\n", response.Data.ID)
+		return
+	}
+
+	if response.Claimed {
+		fmt.Printf("✅ Устройство %d (%s) восстановлено\n", response.Data.ID, response.Data.Name)
+	} else {
+		fmt.Printf("✅ Устройство %d (%s) зарегистрировано\n", response.Data.ID, response.Data.Name)
+	}
+	if response.Merged > 0 {
+		fmt.Printf("🧹 Удалено дубликатов: %d\n", response.Merged)
+	}
+}
+
+func lastSync(d sync.DeviceInfo) string {
+	if d.LastSyncTime.IsZero() {
+		return "никогда"
+	}
+	return d.LastSyncTime.Local().Format("2006-01-02 15:04")
+}
+
+// fingerprint возвращает отпечаток ключа устройства; "-" у устройств
+// старых версий клиента, зарегистрированных без ключа
+func fingerprint(d sync.DeviceInfo) string {
+	if fp := sync.DeviceFingerprint(d.PublicKey); fp != "" {
+		return fp
+	}
+	return "-"
+}
+
+func marker(d sync.DeviceInfo, identity *client.DeviceIdentity) string {
+	current := d.UUID != "" && d.UUID == identity.UUID
+	switch {
+	case d.Status == sync.DevicePending && current:
+		return "← это устройство, ⏳ ожидает подтверждения"
+	case d.Status == sync.DevicePending:
+		return "⏳ ожидает подтверждения"
+	case current:
+		return "← это устройство"
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package doctor
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var fix bool
+
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Проверить установку клиента",
+	Long: `Проверяет права доступа к каталогу конфигурации и файлам с секретами:
+мастер-ключу, токену, локальной базе и служебным файлам.
+
+Каталоги должны быть доступны только владельцу (0700), файлы - 0600.
+На Windows проверяется ACL: доступ допускается только для текущего
+пользователя, SYSTEM и администраторов.
+
+С флагом --fix права исправляются.`,
+	Example: `  gophkeeper doctor
+  gophkeeper doctor --fix`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		var issues []client.PermissionIssue
+		if fix {
+			issues = app.FixPermissions()
+		} else {
+			issues = app.CheckPermissions()
+		}
+
+		fmt.Println("=== Права доступа ===")
+		if len(issues) == 0 {
+			fmt.Println("✅ Права на каталог конфигурации и файлы с секретами в порядке")
+			return nil
+		}
+
+		failed := 0
+		for _, issue := range issues {
+			icon := "⚠️ "
+			if issue.Exposed {
+				icon = "🚨"
+			}
+			switch {
+			case issue.Fixed:
+				fmt.Printf("✅ %s: исправлено (%s)\n", issue.Path, issue.Problem)
+			case issue.FixError != "":
+				failed++
+				fmt.Printf("❌ %s: %s — не удалось исправить: %s\n", issue.Path, issue.Problem, issue.FixError)
+			default:
+				failed++
+				fmt.Printf("%s %s: %s\n", icon, issue.Path, issue.Problem)
+			}
+		}
+
+		if failed == 0 {
+			return nil
+		}
+		if !fix {
+			fmt.Println()
+			fmt.Println("Исправить: gophkeeper doctor --fix")
+		}
+		return fmt.Errorf("найдено проблем: %d", failed)
+	},
+}
+
+func init() {
+	DoctorCmd.Flags().BoolVar(&fix, "fix", false, "исправить права доступа")
+}
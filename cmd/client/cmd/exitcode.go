@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Коды завершения CLI, по которым скрипты различают причины ошибки.
+// Таблица кодов приведена в docs/CLIENT_USAGE.md, раздел «Коды завершения».
+const (
+	exitError        = 1
+	exitUsage        = 2
+	exitAuthRequired = 3
+	exitLocked       = 4
+	exitNotFound     = 5
+	exitConflict     = 6
+	exitNetwork      = 7
+	exitForbidden    = 8
+	exitQuota        = 9
+	exitUpgrade      = 10
+	exitInterrupted  = 130
+)
+
+// usageError - неверный вызов команды: неизвестная команда, флаг или аргументы
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// exitCode выбирает код завершения по виду ошибки
+func exitCode(err error) int {
+	var uerr *usageError
+	var merr *client.MaintenanceError
+	var upgradeErr *client.UpgradeRequiredError
+	switch {
+	case errors.As(err, &uerr), strings.HasPrefix(err.Error(), "unknown command"),
+		errors.Is(err, prompt.ErrNonInteractive):
+		return exitUsage
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, client.ErrMasterKeyLocked):
+		return exitLocked
+	case errors.As(err, &upgradeErr):
+		return exitUpgrade
+	case errors.Is(err, client.ErrServerUnavailable),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &merr):
+		return exitNetwork
+	}
+
+	switch apperr.KindOf(err) {
+	case apperr.Unauthorized:
+		return exitAuthRequired
+	case apperr.NotFound, apperr.Gone:
+		return exitNotFound
+	case apperr.Conflict:
+		return exitConflict
+	case apperr.Forbidden:
+		return exitForbidden
+	case apperr.QuotaExceeded:
+		return exitQuota
+	}
+	return exitError
+}
+
+// childExitCode возвращает код завершения команды, запущенной gophkeeper run.
+// Команда, завершенная сигналом, дает код 128+номер сигнала, как в оболочке.
+func childExitCode(err error) (int, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 0, false
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), true
+	}
+	return exitErr.ExitCode(), true
+}
+
+// markUsageErrors помечает ошибки разбора флагов и проверки аргументов
+// всех команд как usageError, чтобы они завершались с кодом exitUsage
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(c *cobra.Command, a []string) error {
+			if err := args(c, a); err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/apperr"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"generic", errors.New("что-то пошло не так"), exitError},
+		{"usage", &usageError{err: errors.New("unknown flag: --foo")}, exitUsage},
+		{"unknown command", errors.New(`unknown command "lsit" for "gophkeeper"`), exitUsage},
+		{"non-interactive", fmt.Errorf("ввод пароля: %w", prompt.ErrNonInteractive), exitUsage},
+		{"interrupted", fmt.Errorf("синхронизация: %w", context.Canceled), exitInterrupted},
+		{"locked", fmt.Errorf("получение записи: %w", client.ErrMasterKeyLocked), exitLocked},
+		{"upgrade", fmt.Errorf("вход: %w", &client.UpgradeRequiredError{ClientVersion: "1.0.0", MinVersion: "2.0.0"}), exitUpgrade},
+		{"server unavailable", fmt.Errorf("%w: connection refused", client.ErrServerUnavailable), exitNetwork},
+		{"timeout", fmt.Errorf("запрос: %w", context.DeadlineExceeded), exitNetwork},
+		{"maintenance", &client.MaintenanceError{Message: "обновление"}, exitNetwork},
+		{"auth required", client.ErrAuthRequired, exitAuthRequired},
+		{"not found", fmt.Errorf("record get 42: %w", client.ErrRecordNotFound), exitNotFound},
+		{"gone", apperr.New(apperr.Gone, "ссылка истекла"), exitNotFound},
+		{"conflict", client.ErrRecordLocked, exitConflict},
+		{"forbidden", apperr.New(apperr.Forbidden, "нет доступа к папке"), exitForbidden},
+		{"quota", apperr.New(apperr.QuotaExceeded, "превышена квота"), exitQuota},
+		{"invalid", apperr.New(apperr.Invalid, "неверные данные"), exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestMarkUsageErrors(t *testing.T) {
+	root := &cobra.Command{Use: "gophkeeper", SilenceErrors: true, SilenceUsage: true}
+	get := &cobra.Command{
+		Use:  "get <ID>",
+		Args: cobra.ExactArgs(1),
+		RunE: func(*cobra.Command, []string) error { return client.ErrRecordNotFound },
+	}
+	get.Flags().Bool("reveal", false, "")
+	root.AddCommand(get)
+	markUsageErrors(root)
+
+	run := func(args ...string) error {
+		root.SetArgs(args)
+		return root.Execute()
+	}
+
+	assert.Equal(t, exitUsage, exitCode(run("get")), "неверное число аргументов")
+	assert.Equal(t, exitUsage, exitCode(run("get", "1", "--bogus")), "неизвестный флаг")
+	assert.Equal(t, exitUsage, exitCode(run("lsit")), "неизвестная команда")
+	// Ошибка самой команды сохраняет свой код
+	assert.Equal(t, exitNotFound, exitCode(run("get", "1", "--reveal")))
+}
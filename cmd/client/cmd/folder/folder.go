@@ -0,0 +1,143 @@
+package folder
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// FolderCmd - родительская команда папок
+var FolderCmd = &cobra.Command{
+	Use:   "folder",
+	Short: "Папки для записей",
+	Long: `Папки упорядочивают записи в дерево. Путь папки записывается через /:
+Work/Cloud/AWS. Вложенность - до 10 уровней.
+
+Папки хранятся на сервере и доступны на всех устройствах; без связи с
+сервером используется сохраненный список. Переместить запись в папку:
+gophkeeper record move <ID> <путь>. Записи папки и вложенных в нее:
+gophkeeper record list --folder <путь>.`,
+}
+
+var CreateCmd = &cobra.Command{
+	Use:     "create [path]",
+	Short:   "Создать папку",
+	Long:    `Создает папку по пути вместе с недостающими родительскими папками.`,
+	Example: `  gophkeeper folder create Work/Cloud/AWS`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		created, err := app.CreateFolder(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("📁 Папка %s создана\n", created.Path)
+		return nil
+	},
+}
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список папок",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		folders, err := app.ListFolders(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if len(folders) == 0 {
+			fmt.Println("Папок нет. Создайте: gophkeeper folder create <путь>")
+			return nil
+		}
+
+		for _, f := range folders {
+			fmt.Printf("📁 %s (%d)\n", f.Path, f.Records)
+		}
+		return nil
+	},
+}
+
+var MoveCmd = &cobra.Command{
+	Use:   "move [path] [parent]",
+	Short: "Переместить папку",
+	Long: `Переносит папку вместе с содержимым в другую папку.
+Путь / переносит папку на верхний уровень.`,
+	Example: `  gophkeeper folder move Cloud/AWS Work
+  gophkeeper folder move Work/Cloud /`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.MoveFolder(cmd.Context(), args[0], args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Папка %s перемещена в %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var RenameCmd = &cobra.Command{
+	Use:     "rename [path] [name]",
+	Short:   "Переименовать папку",
+	Example: `  gophkeeper folder rename Work/Cloud Infra`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.RenameFolder(cmd.Context(), args[0], args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Папка %s переименована в %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var DeleteCmd = &cobra.Command{
+	Use:   "delete [path]",
+	Short: "Удалить пустую папку",
+	Long: `Удаляет папку без вложенных папок и записей. Записи можно
+предварительно перенести: gophkeeper record move <ID> <путь>.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.DeleteFolder(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️ Папка %s удалена\n", args[0])
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	return app, nil
+}
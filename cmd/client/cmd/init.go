@@ -1,15 +1,44 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"strings"
 
+	"gophkeeper/cmd/client/cmd/account"
+	"gophkeeper/cmd/client/cmd/agent"
+	"gophkeeper/cmd/client/cmd/audit"
 	"gophkeeper/cmd/client/cmd/auth"
+	"gophkeeper/cmd/client/cmd/backup"
+	"gophkeeper/cmd/client/cmd/browser"
+	configcmd "gophkeeper/cmd/client/cmd/config"
+	"gophkeeper/cmd/client/cmd/credhelper"
+	debugcmd "gophkeeper/cmd/client/cmd/debug"
+	"gophkeeper/cmd/client/cmd/device"
+	"gophkeeper/cmd/client/cmd/doctor"
+	"gophkeeper/cmd/client/cmd/folder"
+	"gophkeeper/cmd/client/cmd/inject"
+	"gophkeeper/cmd/client/cmd/k8s"
+	"gophkeeper/cmd/client/cmd/key"
+	"gophkeeper/cmd/client/cmd/keychain"
+	"gophkeeper/cmd/client/cmd/org"
+	"gophkeeper/cmd/client/cmd/otp"
+	"gophkeeper/cmd/client/cmd/pin"
+	"gophkeeper/cmd/client/cmd/profile"
+	"gophkeeper/cmd/client/cmd/prompt"
 	"gophkeeper/cmd/client/cmd/record"
+	"gophkeeper/cmd/client/cmd/run"
+	"gophkeeper/cmd/client/cmd/settings"
+	"gophkeeper/cmd/client/cmd/setup"
+	"gophkeeper/cmd/client/cmd/share"
+	"gophkeeper/cmd/client/cmd/stats"
+	"gophkeeper/cmd/client/cmd/status"
 	"gophkeeper/cmd/client/cmd/sync"
+	"gophkeeper/cmd/client/cmd/undo"
+	"gophkeeper/cmd/client/cmd/use"
+	"gophkeeper/internal/app/client/crypto"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/term"
 )
 
 var initCmd = &cobra.Command{
@@ -21,33 +50,39 @@ var initCmd = &cobra.Command{
 	3. Проверяет соединение с сервером
 	
 Мастер-ключ защищает все ваши данные. Убедитесь, что выбрали надежный пароль
-и сохранили его в безопасном месте. Без мастер-ключа восстановить данные невозможно.`,
-	RunE: func(_ *cobra.Command, _ []string) error {
+и сохранили его в безопасном месте. Без мастер-ключа восстановить данные невозможно.
+
+Флаги --kdf и --cipher выбирают алгоритм получения ключа из пароля
+и шифр записей. Шифр нельзя изменить после создания ключа.`,
+	Example: `  gophkeeper init
+  gophkeeper init --kdf Argon2id --cipher XChaCha20-Poly1305`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
 		// Проверяем, не инициализирован ли уже клиент
 		if app.IsInitialized() {
 			fmt.Println("Клиент уже инициализирован.")
 			return nil
 		}
 
+		if err := app.SetKeyAlgorithms(initKDF, initCipher); err != nil {
+			return fmt.Errorf("%w. Доступные KDF: %s; шифры: %s", err,
+				strings.Join(crypto.KDFs(), ", "), strings.Join(crypto.AEADs(), ", "))
+		}
+
 		fmt.Println("=== Инициализация GophKeeper ===")
 		fmt.Println()
 
 		// Запрашиваем мастер-пароль
-		fmt.Print("Введите мастер-пароль: ")
-		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		password, err := prompt.Password("Введите мастер-пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
-		fmt.Print("Повторите мастер-пароль: ")
-		passwordConfirm, err := term.ReadPassword(int(os.Stdin.Fd()))
+		passwordConfirm, err := prompt.Password("Повторите мастер-пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
-		if string(password) != string(passwordConfirm) {
+		if password != passwordConfirm {
 			return fmt.Errorf("пароли не совпадают")
 		}
 
@@ -57,13 +92,13 @@ var initCmd = &cobra.Command{
 
 		// Инициализируем мастер-ключ
 		fmt.Println("Создание мастер-ключа...")
-		if err := app.InitMasterKey(string(password)); err != nil {
+		if err := app.InitMasterKey(password); err != nil {
 			return fmt.Errorf("ошибка создания мастер-ключа: %w", err)
 		}
 
 		// Проверяем соединение с сервером
 		fmt.Println("Проверка соединения с сервером...")
-		if err := app.CheckConnection(); err != nil {
+		if err := app.CheckConnection(cmd.Context()); err != nil {
 			fmt.Printf("⚠️  Предупреждение: не удалось подключиться к серверу: %v\n", err)
 			fmt.Println("Вы можете работать в офлайн-режиме, но синхронизация будет недоступна.")
 		} else {
@@ -96,7 +131,12 @@ var unlockCmd = &cobra.Command{
 Мастер-ключ необходим для:
 - Создания новых записей
 - Просмотра зашифрованных данных
-- Синхронизации с сервером`,
+- Синхронизации с сервером
+
+Если включена разблокировка через хранилище ОС (gophkeeper keychain enable),
+пароль не запрашивается. Если включен PIN (gophkeeper pin enable), запрашивается
+PIN; после нескольких неверных PIN подряд PIN отключается. Если хранилище
+недоступно, запрашивается мастер-пароль.`,
 	RunE: func(_ *cobra.Command, _ []string) error {
 		// Проверяем, инициализирован ли клиент
 		if !app.IsInitialized() {
@@ -109,19 +149,47 @@ var unlockCmd = &cobra.Command{
 			return nil
 		}
 
+		if app.KeychainEnabled() && !unlockWithPassword {
+			err := app.UnlockWithKeychain()
+			if err == nil {
+				fmt.Printf("✅ Мастер-ключ разблокирован через %s\n", app.KeychainName())
+				return nil
+			}
+			fmt.Printf("⚠️  Не удалось разблокировать через %s: %v\n", app.KeychainName(), err)
+			fmt.Println("Используйте мастер-пароль.")
+			fmt.Println()
+		}
+
+		if app.PINEnabled() && !unlockWithPassword {
+			pinCode, err := prompt.Password("Введите PIN: ")
+			if err != nil {
+				return fmt.Errorf("ошибка чтения PIN: %w", err)
+			}
+
+			err = app.UnlockWithPIN(pinCode)
+			switch {
+			case err == nil:
+				fmt.Println("✅ Мастер-ключ разблокирован по PIN")
+				return nil
+			case errors.As(err, new(*crypto.WrongPINError)):
+				return err
+			default:
+				fmt.Printf("⚠️  %v\n", err)
+				fmt.Println()
+			}
+		}
+
 		fmt.Println("=== Разблокировка мастер-ключа ===")
 		fmt.Println()
 
 		// Запрашиваем мастер-пароль
-		fmt.Print("Введите мастер-пароль: ")
-		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		password, err := prompt.Password("Введите мастер-пароль: ")
 		if err != nil {
 			return fmt.Errorf("ошибка чтения пароля: %w", err)
 		}
-		fmt.Println()
 
 		// Разблокируем мастер-ключ
-		if err := app.UnlockMasterKey(string(password)); err != nil {
+		if err := app.UnlockMasterKey(password); err != nil {
 			return fmt.Errorf("ошибка разблокировки: %w", err)
 		}
 
@@ -130,6 +198,13 @@ var unlockCmd = &cobra.Command{
 	},
 }
 
+var unlockWithPassword bool
+
+var (
+	initKDF    string
+	initCipher string
+)
+
 var lockCmd = &cobra.Command{
 	Use:   "lock",
 	Short: "Заблокировать мастер-ключ",
@@ -150,21 +225,196 @@ var lockCmd = &cobra.Command{
 }
 
 func init() {
+	initCmd.Flags().StringVar(&initKDF, "kdf", crypto.DefaultKDF, "алгоритм получения ключа из пароля")
+	initCmd.Flags().StringVar(&initCipher, "cipher", crypto.DefaultAEAD, "шифр мастер-ключа и записей")
+
 	// Добавляем команды инициализации
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(setup.SetupCmd)
 	rootCmd.AddCommand(unlockCmd)
 	rootCmd.AddCommand(lockCmd)
+	unlockCmd.Flags().BoolVar(&unlockWithPassword, "password", false, "разблокировать мастер-паролем, минуя хранилище ОС и PIN")
+
+	// Добавляем команды разблокировки через хранилище ОС
+	rootCmd.AddCommand(keychain.KeychainCmd)
+	keychain.KeychainCmd.AddCommand(keychain.EnableCmd)
+	keychain.KeychainCmd.AddCommand(keychain.DisableCmd)
+	keychain.KeychainCmd.AddCommand(keychain.StatusCmd)
+
+	// Добавляем команды управления ключом данных
+	rootCmd.AddCommand(key.KeyCmd)
+	key.KeyCmd.AddCommand(key.RotateCmd)
+	key.KeyCmd.AddCommand(key.StatusCmd)
+	key.KeyCmd.AddCommand(key.ChangePasswordCmd)
 
 	// Добавляем команды аутентификации
 	rootCmd.AddCommand(auth.AuthCmd)
 	auth.AuthCmd.AddCommand(auth.RegisterCmd)
 	auth.AuthCmd.AddCommand(auth.LoginCmd)
+	auth.AuthCmd.AddCommand(auth.ChangePasswordCmd)
+	auth.AuthCmd.AddCommand(auth.SessionsCmd)
+	auth.AuthCmd.AddCommand(auth.RevokeCmd)
 
 	// Добавляем команды работы с записями
 	rootCmd.AddCommand(record.RecordCmd)
 	record.RecordCmd.AddCommand(record.CreateCmd)
 	record.RecordCmd.AddCommand(record.GetCmd)
 	record.RecordCmd.AddCommand(record.ListCmd)
+	record.RecordCmd.AddCommand(record.ExportCmd)
+	record.RecordCmd.AddCommand(record.IconCmd)
+	record.RecordCmd.AddCommand(record.HistoryCmd)
+	record.RecordCmd.AddCommand(record.RestoreCmd)
+	record.RecordCmd.AddCommand(record.DeleteCmd)
+	record.RecordCmd.AddCommand(record.LockCmd)
+	record.RecordCmd.AddCommand(record.UnlockCmd)
+	record.RecordCmd.AddCommand(record.AnnotateCmd)
+	record.RecordCmd.AddCommand(record.ExpireCmd)
+	record.RecordCmd.AddCommand(record.AttachCmd)
+	record.RecordCmd.AddCommand(record.DetachCmd)
+	record.RecordCmd.AddCommand(record.DownloadAttachmentCmd)
+	record.RecordCmd.AddCommand(record.TrashCmd)
+	record.RecordCmd.AddCommand(record.MoveCmd)
+	record.RecordCmd.AddCommand(record.EncryptMetaCmd)
+
+	// Добавляем команды папок
+	rootCmd.AddCommand(folder.FolderCmd)
+	folder.FolderCmd.AddCommand(folder.CreateCmd)
+	folder.FolderCmd.AddCommand(folder.ListCmd)
+	folder.FolderCmd.AddCommand(folder.MoveCmd)
+	folder.FolderCmd.AddCommand(folder.RenameCmd)
+	folder.FolderCmd.AddCommand(folder.DeleteCmd)
+
+	// Добавляем токены просмотра для аудиторов и одноразовые ссылки на секреты
+	rootCmd.AddCommand(share.ShareCmd)
+	share.ShareCmd.AddCommand(share.TokenCmd)
+	share.TokenCmd.AddCommand(share.TokenCreateCmd)
+	share.TokenCmd.AddCommand(share.TokenListCmd)
+	share.TokenCmd.AddCommand(share.TokenRevokeCmd)
+	share.ShareCmd.AddCommand(share.LinkCmd)
+	share.ShareCmd.AddCommand(share.LinksCmd)
+	share.ShareCmd.AddCommand(share.RevokeLinkCmd)
+	share.ShareCmd.AddCommand(share.OpenCmd)
 
 	rootCmd.AddCommand(sync.SyncCmd)
+	rootCmd.AddCommand(undo.UndoCmd)
+	rootCmd.AddCommand(use.UseCmd)
+
+	// Добавляем запуск команд с секретами в окружении
+	rootCmd.AddCommand(run.RunCmd)
+	rootCmd.AddCommand(inject.InjectCmd)
+	rootCmd.AddCommand(k8s.K8sCmd)
+
+	// Добавляем помощники учетных данных для git и ssh
+	rootCmd.AddCommand(credhelper.GitCredentialCmd)
+	rootCmd.AddCommand(credhelper.AskpassCmd)
+
+	// Добавляем разблокировку по PIN
+	rootCmd.AddCommand(pin.PINCmd)
+	pin.PINCmd.AddCommand(pin.EnableCmd)
+	pin.PINCmd.AddCommand(pin.DisableCmd)
+	pin.PINCmd.AddCommand(pin.StatusCmd)
+
+	// Добавляем команды учетной записи
+	rootCmd.AddCommand(account.AccountCmd)
+	account.AccountCmd.AddCommand(account.QuotaCmd)
+	account.AccountCmd.AddCommand(account.DeleteCmd)
+	account.AccountCmd.AddCommand(account.ExportCmd)
+	account.AccountCmd.AddCommand(account.TwoFACmd)
+	account.TwoFACmd.AddCommand(account.TwoFAStatusCmd)
+	account.TwoFACmd.AddCommand(account.TwoFAEnableCmd)
+	account.TwoFACmd.AddCommand(account.TwoFADisableCmd)
+	account.TwoFACmd.AddCommand(account.TwoFARecoveryCodesCmd)
+
+	// Добавляем команды устройств синхронизации
+	rootCmd.AddCommand(device.DeviceCmd)
+	device.DeviceCmd.AddCommand(device.ListCmd)
+	device.DeviceCmd.AddCommand(device.RegisterCmd)
+	device.DeviceCmd.AddCommand(device.ClaimCmd)
+	device.DeviceCmd.AddCommand(device.ApproveCmd)
+
+	// Добавляем аудит паролей
+	rootCmd.AddCommand(audit.AuditCmd)
+
+	// Добавляем проверку установки
+	rootCmd.AddCommand(doctor.DoctorCmd)
+
+	// Добавляем сводку состояния клиента
+	rootCmd.AddCommand(status.StatusCmd)
+
+	// Добавляем статистику хранилища
+	rootCmd.AddCommand(stats.StatsCmd)
+
+	// Добавляем команды одноразовых паролей
+	rootCmd.AddCommand(otp.OTPCmd)
+	otp.OTPCmd.AddCommand(otp.ShowCmd)
+	otp.OTPCmd.AddCommand(otp.ImportCmd)
+	otp.OTPCmd.AddCommand(otp.ExportCmd)
+
+	// Добавляем команды резервного копирования
+	rootCmd.AddCommand(backup.ExportCmd)
+	rootCmd.AddCommand(backup.ImportCmd)
+	rootCmd.AddCommand(backup.BackupCmd)
+	backup.BackupCmd.AddCommand(backup.TargetCmd)
+	backup.TargetCmd.AddCommand(backup.TargetAddCmd)
+	backup.TargetCmd.AddCommand(backup.TargetListCmd)
+	backup.TargetCmd.AddCommand(backup.TargetRemoveCmd)
+	backup.BackupCmd.AddCommand(backup.PushCmd)
+	backup.BackupCmd.AddCommand(backup.ListCmd)
+	backup.BackupCmd.AddCommand(backup.PullCmd)
+
+	// Добавляем команды фонового агента
+	rootCmd.AddCommand(agent.AgentCmd)
+	agent.AgentCmd.AddCommand(agent.RunCmd)
+	agent.AgentCmd.AddCommand(agent.StatusCmd)
+	agent.AgentCmd.AddCommand(agent.InstallCmd)
+	agent.AgentCmd.AddCommand(agent.UninstallCmd)
+	agent.AgentCmd.AddCommand(agent.TokenCmd)
+	agent.AgentCmd.AddCommand(agent.StartCmd)
+	agent.AgentCmd.AddCommand(agent.StopCmd)
+	agent.AgentCmd.AddCommand(agent.UnlockCmd)
+	agent.AgentCmd.AddCommand(agent.LockCmd)
+	agent.AgentCmd.AddCommand(agent.GetCmd)
+
+	// Добавляем интеграцию с браузерным расширением
+	rootCmd.AddCommand(browser.BrowserCmd)
+	browser.BrowserCmd.AddCommand(browser.InstallCmd)
+	browser.BrowserCmd.AddCommand(browser.UninstallCmd)
+	browser.BrowserCmd.AddCommand(browser.HostCmd)
+
+	// Добавляем команды локальной конфигурации
+	rootCmd.AddCommand(configcmd.ConfigCmd)
+	configcmd.ConfigCmd.AddCommand(configcmd.ListCmd)
+	configcmd.ConfigCmd.AddCommand(configcmd.GetCmd)
+	configcmd.ConfigCmd.AddCommand(configcmd.SetCmd)
+	configcmd.ConfigCmd.AddCommand(configcmd.EncryptDBCmd)
+	configcmd.ConfigCmd.AddCommand(configcmd.DecryptDBCmd)
+
+	rootCmd.AddCommand(profile.ProfileCmd)
+	profile.ProfileCmd.AddCommand(profile.ListCmd)
+	profile.ProfileCmd.AddCommand(profile.AddCmd)
+	profile.ProfileCmd.AddCommand(profile.UseCmd)
+	profile.ProfileCmd.AddCommand(profile.RemoveCmd)
+
+	// Добавляем отладочные команды
+	rootCmd.AddCommand(debugcmd.DebugCmd)
+	debugcmd.DebugCmd.AddCommand(debugcmd.BundleCmd)
+
+	// Добавляем команды организаций и общих хранилищ
+	rootCmd.AddCommand(org.OrgCmd)
+	org.OrgCmd.AddCommand(org.CreateCmd)
+	org.OrgCmd.AddCommand(org.ListCmd)
+	org.OrgCmd.AddCommand(org.DeleteCmd)
+	org.OrgCmd.AddCommand(org.InviteCmd)
+	org.OrgCmd.AddCommand(org.AcceptCmd)
+	org.OrgCmd.AddCommand(org.MembersCmd)
+	org.OrgCmd.AddCommand(org.RoleCmd)
+	org.OrgCmd.AddCommand(org.RemoveCmd)
+	org.OrgCmd.AddCommand(org.RecordsCmd)
+	org.OrgCmd.AddCommand(org.ShareCmd)
+	org.OrgCmd.AddCommand(org.GetCmd)
+
+	// Добавляем команды настроек пользователя
+	rootCmd.AddCommand(settings.SettingsCmd)
+	settings.SettingsCmd.AddCommand(settings.SetCmd)
+	settings.SettingsCmd.AddCommand(settings.ResetCmd)
 }
@@ -0,0 +1,148 @@
+package inject
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	inputPath  string
+	outputPath string
+	fileMode   string
+	force      bool
+)
+
+var InjectCmd = &cobra.Command{
+	Use:   "inject -i <шаблон> [-o <файл>]",
+	Short: "Подставить секреты в шаблон конфигурации",
+	Long: `Выполняет шаблон Go (text/template) и записывает результат в файл.
+
+Секреты подставляются функцией secret: ссылкой gk://<запись>/<поле> или
+записью и полем отдельными аргументами. Запись задается ID или точным
+названием, поле - ключом данных записи; для TOTP поле code дает текущий код.
+
+  password: {{ secret "gk://Postgres/password" }}
+  user: {{ secret "Postgres" "username" }}
+
+Файл создается с правами --mode (по умолчанию 0600) и появляется целиком:
+если хотя бы одна ссылка не разрешилась, он не создается и не меняется.
+Без -o результат выводится в stdout, "-" в -i читает шаблон из stdin.
+Записи читаются из локального хранилища, мастер-ключ должен быть разблокирован.`,
+	Example: `  gophkeeper inject -i config.tmpl -o config.yaml
+  gophkeeper inject -i app.env.tmpl -o /etc/app/app.env --mode 0640 --force
+  cat config.tmpl | gophkeeper inject -i - > config.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		perm, err := parseMode(fileMode)
+		if err != nil {
+			return err
+		}
+
+		if outputPath != "-" && !force {
+			if _, err := os.Stat(outputPath); err == nil {
+				return fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", outputPath)
+			}
+		}
+
+		text, err := readInput(cmd.InOrStdin(), inputPath)
+		if err != nil {
+			return err
+		}
+
+		rendered, err := app.RenderTemplate(cmd.Context(), filepath.Base(inputPath), string(text))
+		if err != nil {
+			return err
+		}
+
+		if outputPath == "-" {
+			_, err := cmd.OutOrStdout().Write(rendered)
+			return err
+		}
+
+		if err := writeFile(outputPath, rendered, perm); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "✅ Файл сохранен: %s (права %04o)\n", outputPath, perm)
+		return nil
+	},
+}
+
+func init() {
+	InjectCmd.Flags().StringVarP(&inputPath, "in", "i", "", "файл шаблона, \"-\" - stdin")
+	InjectCmd.Flags().StringVarP(&outputPath, "out", "o", "-", "файл результата, \"-\" - stdout")
+	InjectCmd.Flags().StringVar(&fileMode, "mode", "0600", "права файла результата (восьмеричные)")
+	InjectCmd.Flags().BoolVarP(&force, "force", "f", false, "перезаписать существующий файл")
+	_ = InjectCmd.MarkFlagRequired("in")
+}
+
+// parseMode разбирает восьмеричные права файла
+func parseMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("неверные права %q, ожидается восьмеричное число, например 0600", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+func readInput(stdin io.Reader, path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения шаблона: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения шаблона: %w", err)
+	}
+	return data, nil
+}
+
+// writeFile записывает файл через временный в том же каталоге: права
+// выставляются до того, как в файле появятся секреты, а прежний файл
+// заменяется целиком
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// CreateTemp создает файл с 0600; Chmod не зависит от umask
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка установки прав: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/k8s"
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	listenAddr   string
+	tokenFile    string
+	passwordFile string
+	tlsCert      string
+	tlsKey       string
+)
+
+// K8sCmd - родительская команда интеграции с Kubernetes
+var K8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Интеграция с Kubernetes",
+	Long: `Команды для передачи секретов хранилища в кластер Kubernetes.
+
+Команда serve запускает клиент как провайдер секретов в поде (sidecar или
+отдельный Deployment): он синхронизируется с сервером GophKeeper и отдает
+секреты по HTTP webhook-провайдеру External Secrets Operator.`,
+}
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve --token-file <файл> [--password-file <файл>]",
+	Short: "Запустить провайдер секретов для External Secrets Operator",
+	Long: `Запускает HTTP-провайдер секретов и периодическую синхронизацию.
+
+  GET /healthz                      - готовность (503, пока ключ заблокирован)
+  GET /v1/secrets/{запись}          - все поля записи объектом JSON
+  GET /v1/secrets/{запись}/{поле}   - одно поле: {"value": "..."}
+
+Запись задается ID или точным названием (с "/" экранированным как %2F),
+поле - ключом данных записи; для TOTP поле code дает текущий код.
+Запросы секретов требуют заголовка Authorization: Bearer <токен> с токеном
+из --token-file. Мастер-пароль читается из --password-file (например,
+смонтированного Secret); без него ключ должен быть разблокирован заранее.
+Мастер-ключ не блокируется по простою, пока провайдер работает.`,
+	Example: `  gophkeeper k8s serve --token-file /var/run/gophkeeper/token \
+    --password-file /var/run/gophkeeper/master-password`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsInitialized() {
+			return fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+		}
+
+		token, err := readSecretFile(tokenFile)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения токена провайдера: %w", err)
+		}
+		if len(token) < 16 {
+			return fmt.Errorf("токен провайдера должен содержать минимум 16 символов")
+		}
+
+		if passwordFile != "" {
+			password, err := readSecretFile(passwordFile)
+			if err != nil {
+				return fmt.Errorf("ошибка чтения мастер-пароля: %w", err)
+			}
+			if err := app.UnlockMasterKey(password); err != nil {
+				return err
+			}
+		}
+		if !app.IsMasterKeyUnlocked() {
+			return client.ErrMasterKeyLocked
+		}
+
+		log := slog.New(sl.NewRedactHandler(slog.NewJSONHandler(os.Stderr, nil)))
+		srv := &http.Server{
+			Addr:              listenAddr,
+			Handler:           k8s.NewHandler(app, token, log),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		if tlsCert != "" || tlsKey != "" {
+			cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+			if err != nil {
+				return fmt.Errorf("ошибка загрузки сертификата TLS: %w", err)
+			}
+			srv.TLSConfig = &tls.Config{
+				Certificates: []tls.Certificate{cert},
+				MinVersion:   tls.VersionTLS12,
+			}
+		}
+
+		return app.RunSecretsProvider(cmd.Context(), srv)
+	},
+}
+
+func init() {
+	ServeCmd.Flags().StringVar(&listenAddr, "listen", ":8280", "адрес HTTP-сервера провайдера")
+	ServeCmd.Flags().StringVar(&tokenFile, "token-file", "", "файл с токеном, который должны предъявлять клиенты в кластере")
+	ServeCmd.Flags().StringVar(&passwordFile, "password-file", "", "файл с мастер-паролем для разблокировки при запуске")
+	ServeCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "сертификат TLS (PEM)")
+	ServeCmd.Flags().StringVar(&tlsKey, "tls-key", "", "закрытый ключ TLS (PEM)")
+	_ = ServeCmd.MarkFlagRequired("token-file")
+	ServeCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+
+	K8sCmd.AddCommand(ServeCmd)
+}
+
+// readSecretFile читает секрет из файла без завершающего перевода строки,
+// который добавляют echo и kubectl create secret --from-file
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
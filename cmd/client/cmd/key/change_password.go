@@ -0,0 +1,79 @@
+package key
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var ChangePasswordCmd = &cobra.Command{
+	Use:   "change-password",
+	Short: "Сменить мастер-пароль",
+	Long: `Меняет мастер-пароль, которым защищен ключ данных. Ключ данных не
+меняется, поэтому записи не перешифровываются.
+
+Новый файл ключа сначала записывается во временный файл и проверяется:
+он должен открываться новым паролем и расшифровывать запись из хранилища.
+Только после этого он заменяет прежний файл. Затем файл сохраняется на
+сервере; если сервер недоступен, он отправится при синхронизации.
+
+Разблокировка через хранилище ОС и PIN продолжают работать. Новые
+устройства получают файл с новым паролем. На устройствах, где хранилище уже
+настроено, действует прежний мастер-пароль: выполните на них эту же команду
+с теми же паролями.
+
+Пароль входа в учетную запись меняется отдельно: gophkeeper auth change-password.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		oldPassword, err := prompt.Password("Текущий мастер-пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		newPassword, err := prompt.Password("Новый мастер-пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		passwordConfirm, err := prompt.Password("Повторите новый мастер-пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		if newPassword != passwordConfirm {
+			return fmt.Errorf("пароли не совпадают")
+		}
+
+		result, err := app.ChangeMasterPassword(cmd.Context(), oldPassword, newPassword)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("✅ Мастер-пароль изменен")
+		if result.SampleRecordID > 0 {
+			fmt.Printf("🔐 Проверка: запись %d расшифрована новым файлом ключа\n", result.SampleRecordID)
+		}
+		switch {
+		case result.Published:
+			fmt.Println("☁️  Файл ключа сохранен на сервере")
+		case result.PublishError != nil:
+			fmt.Printf("⚠️  Файл ключа не сохранен на сервере: %v\n", result.PublishError)
+			fmt.Println("Он отправится при следующей синхронизации: gophkeeper sync")
+		}
+		return nil
+	},
+}
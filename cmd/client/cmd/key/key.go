@@ -0,0 +1,148 @@
+package key
+
+import (
+	"fmt"
+	"sort"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// KeyCmd - родительская команда управления ключом данных
+var KeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Управление ключом шифрования данных",
+	Long: `Записи шифруются ключом данных, который защищен мастер-паролем. Ротация
+заменяет ключ данных новым случайным ключом, не меняя мастер-пароль, и
+перешифровывает им все записи, в том числе на сервере.
+
+Прежние ключи сохраняются в файле ключа, поэтому записи, еще не
+перешифрованные новым ключом, продолжают читаться на всех устройствах.
+
+Смена мастер-пароля (change-password) перезаписывает только файл ключа:
+ключ данных и записи не меняются.`,
+}
+
+var rotateResume bool
+
+var RotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Заменить ключ данных и перешифровать записи",
+	Long: `Создает новый случайный ключ данных, защищает его мастер-паролем,
+сохраняет файл ключа на сервере, перешифровывает все записи и отправляет их
+на сервер с новой версией.
+
+Другие устройства получают новый файл ключа при синхронизации и просят
+разблокировать его мастер-паролем. Разблокировка через хранилище ОС на этом
+устройстве обновляется автоматически, PIN нужно включить заново.
+
+Если перешифрование прервано, продолжите его флагом --resume: новый ключ
+при этом не создается.`,
+	Example: `  gophkeeper key rotate
+  gophkeeper key rotate --resume`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		var result *client.KeyRotationResult
+		if rotateResume {
+			result, err = app.ReencryptRecords(cmd.Context())
+		} else {
+			password, perr := prompt.Password("Мастер-пароль: ")
+			if perr != nil {
+				return fmt.Errorf("ошибка чтения пароля: %w", perr)
+			}
+			result, err = app.RotateKey(cmd.Context(), password)
+		}
+
+		if result != nil {
+			if !rotateResume {
+				fmt.Printf("🔑 Новый ключ данных, версия %d\n", result.KeyVersion)
+			}
+			fmt.Printf("🔐 Перешифровано и отправлено записей: %d\n", result.Updated)
+			if result.Pending > 0 {
+				fmt.Printf("⏳ Не синхронизированы: %d (отправятся при синхронизации)\n", result.Pending)
+			}
+			if result.Skipped > 0 {
+				fmt.Printf("🗑️  В корзине: %d (остаются зашифрованы прежним ключом)\n", result.Skipped)
+			}
+			for _, reason := range result.Failed {
+				fmt.Printf("⚠️  Ошибка: %s\n", reason)
+			}
+			if result.PINDisabled {
+				fmt.Println("📌 PIN отключен, включите заново: gophkeeper pin enable")
+			}
+		}
+		if err != nil {
+			if result != nil {
+				fmt.Println("Продолжите перешифрование: gophkeeper key rotate --resume")
+			}
+			return err
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("не удалось обновить записей: %d, выполните: gophkeeper key rotate --resume", len(result.Failed))
+		}
+		return nil
+	},
+}
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Версия ключа данных и записи по версиям",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		usage, err := app.KeyUsage()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Версия ключа: %d\n", usage.KeyVersion)
+		versions := make([]int, 0, len(usage.Records))
+		for version := range usage.Records {
+			versions = append(versions, version)
+		}
+		sort.Ints(versions)
+		for _, version := range versions {
+			marker := "✅"
+			if version < usage.KeyVersion {
+				marker = "⏳"
+			}
+			fmt.Printf("%s Записей с ключом версии %d: %d\n", marker, version, usage.Records[version])
+		}
+		return nil
+	},
+}
+
+func init() {
+	RotateCmd.Flags().BoolVar(&rotateResume, "resume", false, "продолжить перешифрование без создания нового ключа")
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsInitialized() {
+		return nil, fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+	}
+
+	return app, nil
+}
@@ -0,0 +1,102 @@
+package keychain
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// KeychainCmd - родительская команда разблокировки через хранилище ОС
+var KeychainCmd = &cobra.Command{
+	Use:   "keychain",
+	Short: "Разблокировка через хранилище секретов ОС",
+	Long: `Разблокировка мастер-ключа без ввода пароля через хранилище секретов ОС:
+- macOS   - связка ключей (Keychain), доступ подтверждается паролем или Touch ID
+- Windows - DPAPI, ключ доступен после входа пользователя в систему
+- Linux   - Secret Service (GNOME Keyring, KWallet), нужна утилита secret-tool
+
+В хранилище ОС сохраняется ключ, которым зашифрована копия мастер-ключа
+(файл <master_key_path>.os). Мастер-пароль продолжает работать и нужен,
+если хранилище ОС недоступно.`,
+}
+
+var EnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Включить разблокировку через хранилище ОС",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !app.KeychainAvailable() {
+			return fmt.Errorf("хранилище %s недоступно на этой системе", app.KeychainName())
+		}
+
+		if err := app.EnableKeychainUnlock(); err != nil {
+			return fmt.Errorf("ошибка включения разблокировки: %w", err)
+		}
+
+		fmt.Printf("✅ Разблокировка через %s включена\n", app.KeychainName())
+		fmt.Println("Теперь gophkeeper unlock не запрашивает мастер-пароль.")
+		return nil
+	},
+}
+
+var DisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Отключить разблокировку через хранилище ОС",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.DisableKeychainUnlock(); err != nil {
+			return fmt.Errorf("ошибка отключения разблокировки: %w", err)
+		}
+
+		fmt.Printf("✅ Ключ удален из %s\n", app.KeychainName())
+		return nil
+	},
+}
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Состояние разблокировки через хранилище ОС",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Хранилище:  %s\n", app.KeychainName())
+		if app.KeychainAvailable() {
+			fmt.Println("Доступно:   ✅ да")
+		} else {
+			fmt.Println("Доступно:   ❌ нет")
+		}
+		if app.KeychainEnabled() {
+			fmt.Println("Включено:   ✅ да")
+		} else {
+			fmt.Println("Включено:   ❌ нет (gophkeeper keychain enable)")
+		}
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsInitialized() {
+		return nil, fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+	}
+
+	return app, nil
+}
@@ -0,0 +1,174 @@
+package org
+
+import (
+	"fmt"
+	"strings"
+
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/domain/org"
+
+	"github.com/spf13/cobra"
+)
+
+var inviteRole string
+
+var InviteCmd = &cobra.Command{
+	Use:   "invite [org-id] [login]",
+	Short: "Пригласить участника",
+	Long: `Приглашает пользователя в организацию и выводит код приглашения.
+
+Код расшифровывает ключ хранилища, поэтому передайте его приглашенному
+лично или через другой защищенный канал - не через сервер GophKeeper.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+
+		code, err := app.InviteOrgMember(cmd.Context(), orgID, args[1], org.Role(inviteRole))
+		if err != nil {
+			return fmt.Errorf("ошибка приглашения: %w", err)
+		}
+
+		fmt.Printf("✅ Пользователь '%s' приглашен с ролью %s\n", args[1], inviteRole)
+		fmt.Println()
+		fmt.Printf("🔑 Код приглашения: %s\n", code)
+		fmt.Println()
+		fmt.Println("Передайте код приглашенному лично. Принять приглашение:")
+		fmt.Printf("  gophkeeper org accept %d\n", orgID)
+		return nil
+	},
+}
+
+var AcceptCmd = &cobra.Command{
+	Use:   "accept [org-id]",
+	Short: "Принять приглашение в организацию",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+
+		code, err := prompt.Password("Введите код приглашения: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения кода: %w", err)
+		}
+
+		if err := app.AcceptOrgInvite(cmd.Context(), orgID, strings.TrimSpace(code)); err != nil {
+			return fmt.Errorf("ошибка принятия приглашения: %w", err)
+		}
+
+		fmt.Printf("✅ Вы присоединились к организации %d\n", orgID)
+		fmt.Printf("Записи хранилища: gophkeeper org records %d\n", orgID)
+		return nil
+	},
+}
+
+var MembersCmd = &cobra.Command{
+	Use:   "members [org-id]",
+	Short: "Список участников",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+
+		members, err := app.ListOrgMembers(cmd.Context(), orgID)
+		if err != nil {
+			return fmt.Errorf("ошибка получения участников: %w", err)
+		}
+
+		fmt.Printf("%-8s %-30s %-10s %s\n", "User ID", "Логин", "Роль", "Статус")
+		fmt.Println(strings.Repeat("-", 60))
+		for _, m := range members {
+			status := "активен"
+			if m.Status == org.StatusInvited {
+				status = "приглашен"
+			}
+			fmt.Printf("%-8d %-30s %-10s %s\n", m.UserID, m.Login, m.Role, status)
+		}
+		return nil
+	},
+}
+
+var RoleCmd = &cobra.Command{
+	Use:   "role [org-id] [user-id] [role]",
+	Short: "Изменить роль участника",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+		userID, err := parseID(args[1], "участника")
+		if err != nil {
+			return err
+		}
+
+		if err := app.ChangeOrgMemberRole(cmd.Context(), orgID, userID, org.Role(args[2])); err != nil {
+			return fmt.Errorf("ошибка изменения роли: %w", err)
+		}
+
+		fmt.Printf("✅ Роль участника %d изменена на %s\n", userID, args[2])
+		return nil
+	},
+}
+
+var RemoveCmd = &cobra.Command{
+	Use:   "remove [org-id] [user-id]",
+	Short: "Исключить участника или отозвать приглашение",
+	Long: `Исключает участника из организации. Укажите свой ID, чтобы выйти из организации.
+
+Исключенный участник теряет доступ к хранилищу на сервере, но мог сохранить
+ранее прочитанные данные. Смените секреты, к которым у него был доступ.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+		userID, err := parseID(args[1], "участника")
+		if err != nil {
+			return err
+		}
+
+		if err := app.RemoveOrgMember(cmd.Context(), orgID, userID); err != nil {
+			return fmt.Errorf("ошибка исключения участника: %w", err)
+		}
+
+		fmt.Printf("✅ Участник %d исключен из организации %d\n", userID, orgID)
+		return nil
+	},
+}
+
+func init() {
+	InviteCmd.Flags().StringVar(&inviteRole, "role", string(org.RoleMember), "Роль: owner, admin, member, read-only")
+}
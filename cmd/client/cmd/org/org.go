@@ -0,0 +1,128 @@
+package org
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/org"
+
+	"github.com/spf13/cobra"
+)
+
+// OrgCmd - родительская команда организаций и общих хранилищ
+var OrgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Организации и общие хранилища",
+	Long: `Организация - общее хранилище записей для команды.
+
+Записи хранилища шифруются отдельным ключом организации. Сервер хранит
+этот ключ только в зашифрованном виде: для каждого участника - его
+мастер-ключом, для приглашенного - кодом приглашения.
+
+Роли участников:
+- owner     - полный доступ, управление участниками и удаление организации
+- admin     - запись и приглашение участников с ролями member и read-only
+- member    - чтение и изменение записей
+- read-only - только чтение записей`,
+}
+
+var CreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Создать организацию",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		created, err := app.CreateOrg(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("ошибка создания организации: %w", err)
+		}
+
+		fmt.Printf("✅ Организация '%s' создана (ID: %d)\n", created.Name, created.ID)
+		fmt.Printf("Пригласите участников: gophkeeper org invite %d <login> --role member\n", created.ID)
+		return nil
+	},
+}
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список организаций и приглашений",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgs, err := app.ListOrgs(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения организаций: %w", err)
+		}
+
+		if len(orgs) == 0 {
+			fmt.Println("Вы не состоите ни в одной организации")
+			return nil
+		}
+
+		fmt.Printf("%-6s %-30s %-10s %-9s %s\n", "ID", "Название", "Роль", "Участники", "Статус")
+		fmt.Println(strings.Repeat("-", 70))
+		for _, o := range orgs {
+			status := "✅ участник"
+			if o.Status == org.StatusInvited {
+				status = fmt.Sprintf("📨 приглашение (gophkeeper org accept %d)", o.ID)
+			}
+			fmt.Printf("%-6d %-30s %-10s %-9d %s\n", o.ID, o.Name, o.Role, o.Members, status)
+		}
+		return nil
+	},
+}
+
+var DeleteCmd = &cobra.Command{
+	Use:   "delete [org-id]",
+	Short: "Удалить организацию вместе с хранилищем",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+
+		if err := app.DeleteOrg(cmd.Context(), orgID); err != nil {
+			return fmt.Errorf("ошибка удаления организации: %w", err)
+		}
+
+		fmt.Printf("🗑️  Организация %d удалена\n", orgID)
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsAuthenticated() {
+		return nil, client.ErrAuthRequired
+	}
+
+	return app, nil
+}
+
+func parseID(value, what string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("неверный ID %s: %s", what, value)
+	}
+	return id, nil
+}
@@ -0,0 +1,123 @@
+package org
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var RecordsCmd = &cobra.Command{
+	Use:   "records [org-id]",
+	Short: "Записи хранилища организации",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+
+		records, err := app.ListOrgRecords(cmd.Context(), orgID)
+		if err != nil {
+			return fmt.Errorf("ошибка получения записей: %w", err)
+		}
+
+		if records.Total == 0 {
+			fmt.Println("В хранилище организации нет записей")
+			fmt.Printf("Поделиться записью: gophkeeper org share %d <record-id>\n", orgID)
+			return nil
+		}
+
+		fmt.Printf("%-6s %-10s %-35s %s\n", "ID", "Тип", "Название", "Изменена")
+		fmt.Println(strings.Repeat("-", 75))
+		for _, item := range records.Records {
+			title := ""
+			var meta map[string]interface{}
+			if json.Unmarshal(item.Meta, &meta) == nil {
+				title, _ = meta["title"].(string)
+			}
+			fmt.Printf("%-6d %-10s %-35s %s\n", item.ID, item.Type, title,
+				item.LastModified.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("\nВсего: %d\n", records.Total)
+		return nil
+	},
+}
+
+var ShareCmd = &cobra.Command{
+	Use:   "share [org-id] [record-id]",
+	Short: "Поделиться записью с организацией",
+	Long: `Копирует личную запись в хранилище организации.
+
+Данные перешифровываются ключом организации и становятся доступны всем
+участникам. Личная запись остается без изменений.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+		recordID, err := parseID(args[1], "записи")
+		if err != nil {
+			return err
+		}
+
+		sharedID, err := app.ShareRecord(cmd.Context(), orgID, recordID)
+		if err != nil {
+			return fmt.Errorf("ошибка публикации записи: %w", err)
+		}
+
+		fmt.Printf("✅ Запись %d добавлена в хранилище организации (ID: %d)\n", recordID, sharedID)
+		return nil
+	},
+}
+
+var GetCmd = &cobra.Command{
+	Use:   "get [org-id] [record-id]",
+	Short: "Показать запись хранилища организации",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		orgID, err := parseID(args[0], "организации")
+		if err != nil {
+			return err
+		}
+		recordID, err := parseID(args[1], "записи")
+		if err != nil {
+			return err
+		}
+
+		rec, data, err := app.GetOrgRecord(cmd.Context(), orgID, recordID)
+		if err != nil {
+			return fmt.Errorf("ошибка получения записи: %w", err)
+		}
+
+		fmt.Printf("ID:          %d\n", rec.ID)
+		fmt.Printf("Тип:         %s\n", rec.Type)
+		fmt.Printf("Версия:      %d\n", rec.Version)
+		fmt.Printf("Изменена:    %s\n", rec.LastModified.Format("2006-01-02 15:04:05"))
+
+		pretty, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("ошибка форматирования данных: %w", err)
+		}
+		fmt.Println("Данные:")
+		fmt.Println(string(pretty))
+		return nil
+	},
+}
@@ -0,0 +1,165 @@
+// cmd/client/cmd/otp/migration.go
+package otp
+
+import (
+	"bufio"
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFile   string
+	exportOutput string
+	exportForce  bool
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import [uri...]",
+	Short: "Импортировать секреты из Google Authenticator",
+	Long: `Создает записи TOTP из адресов otpauth-migration://offline?data=...
+
+Такие адреса содержат QR-коды «Перенести аккаунты» Google Authenticator:
+отсканируйте коды любым сканером QR и передайте адреса аргументами, файлом
+(--file, по адресу в строке) или через stdin. Секреты, которые уже есть в
+хранилище, пропускаются; счетчики HOTP не поддерживаются.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		uris := args
+		if len(uris) == 0 {
+			var r io.Reader = os.Stdin
+			if importFile != "" {
+				file, err := os.Open(importFile)
+				if err != nil {
+					return fmt.Errorf("ошибка чтения файла: %w", err)
+				}
+				defer file.Close()
+				r = file
+			}
+			var err error
+			if uris, err = readURIs(r); err != nil {
+				return err
+			}
+		}
+		if len(uris) == 0 {
+			return fmt.Errorf("не указаны адреса otpauth-migration")
+		}
+
+		result, err := app.ImportOTPMigration(cmd.Context(), uris)
+		if result != nil {
+			fmt.Printf("✅ Импортировано: %d\n", result.Imported)
+			if result.Duplicates > 0 {
+				fmt.Printf("⏭️  Уже в хранилище: %d\n", result.Duplicates)
+			}
+			for _, reason := range result.Skipped {
+				fmt.Printf("⚠️  Пропущено: %s\n", reason)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка импорта: %w", err)
+		}
+
+		return nil
+	},
+}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export [id...]",
+	Short: "Экспортировать секреты для Google Authenticator",
+	Long: `Собирает записи TOTP (все или с указанными ID) в адреса
+otpauth-migration://offline?data=..., по 10 секретов в адресе.
+
+Адреса содержат секреты в открытом виде. Превратите их в QR-коды
+(например, qrencode -t ansiutf8) и отсканируйте в Google Authenticator,
+затем удалите вывод. Записи с периодом, отличным от 30 секунд, формат
+не поддерживает, они пропускаются.`,
+	ValidArgsFunction: complete.RecordID,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		ids := make([]int, 0, len(args))
+		for _, arg := range args {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("неверный ID записи: %w", err)
+			}
+			ids = append(ids, id)
+		}
+
+		if exportOutput != "" && !exportForce {
+			if _, err := os.Stat(exportOutput); err == nil {
+				return fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", exportOutput)
+			}
+		}
+
+		result, err := app.ExportOTPMigration(cmd.Context(), ids)
+		if err != nil {
+			return fmt.Errorf("ошибка экспорта: %w", err)
+		}
+		for _, reason := range result.Skipped {
+			fmt.Fprintf(os.Stderr, "⚠️  Пропущено: %s\n", reason)
+		}
+		if result.Exported == 0 {
+			return fmt.Errorf("нет записей TOTP для экспорта")
+		}
+
+		output := strings.Join(result.URIs, "\n") + "\n"
+		if exportOutput == "" {
+			fmt.Print(output)
+			return nil
+		}
+		if err := os.WriteFile(exportOutput, []byte(output), 0600); err != nil {
+			return fmt.Errorf("ошибка записи файла: %w", err)
+		}
+		fmt.Printf("✅ Экспортировано секретов: %d (адресов: %d) в %s\n", result.Exported, len(result.URIs), exportOutput)
+		return nil
+	},
+}
+
+// readURIs читает адреса по одному в строке, пропуская пустые строки
+func readURIs(r io.Reader) ([]string, error) {
+	var uris []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			uris = append(uris, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения адресов: %w", err)
+	}
+	return uris, nil
+}
+
+func init() {
+	ImportCmd.Flags().StringVarP(&importFile, "file", "f", "", "файл с адресами otpauth-migration, по одному в строке")
+	ExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "сохранить адреса в файл с правами 0600 вместо вывода")
+	ExportCmd.Flags().BoolVar(&exportForce, "force", false, "перезаписать существующий файл")
+}
@@ -0,0 +1,12 @@
+package otp
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// OTPCmd - родительская команда для работы с одноразовыми паролями
+var OTPCmd = &cobra.Command{
+	Use:   "otp",
+	Short: "Одноразовые пароли (TOTP)",
+	Long:  `Генерация кодов двухфакторной аутентификации из сохраненных секретов TOTP.`,
+}
@@ -0,0 +1,48 @@
+package otp
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var ShowCmd = &cobra.Command{
+	Use:   "show [id]",
+	Short: "Показать текущий код TOTP",
+	Long: `Генерирует текущий код двухфакторной аутентификации для записи TOTP.
+
+Код вычисляется локально из расшифрованного секрета, секрет не покидает клиент.`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		code, remaining, err := app.GetOTPCode(cmd.Context(), recordID)
+		if err != nil {
+			return fmt.Errorf("ошибка получения кода: %w", err)
+		}
+
+		fmt.Printf("🔑 Код: %s\n", code)
+		fmt.Printf("Действителен еще: %d сек\n", int(remaining.Seconds()))
+
+		return nil
+	},
+}
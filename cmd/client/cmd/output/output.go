@@ -0,0 +1,113 @@
+// Package output - общий вывод результатов команд CLI.
+//
+// Глобальный флаг --json печатает результат в JSON по схеме из schema.go:
+// поля не переименовываются и не удаляются, новые только добавляются, поэтому
+// вывод можно разбирать jq и скриптами. Флаг --quiet печатает только ID
+// объектов, по одному в строке, для передачи в xargs. Ошибки в режиме --json
+// выводятся в stderr тоже в JSON, вместе с кодом завершения.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Mode - формат вывода
+type Mode int
+
+const (
+	// Text - вывод для человека
+	Text Mode = iota
+	// JSON - JSON со стабильной схемой
+	JSON
+	// Quiet - только ID объектов
+	Quiet
+)
+
+// ErrConflictingModes - одновременно заданы --json и --quiet
+var ErrConflictingModes = errors.New("флаги --json и --quiet несовместимы")
+
+var (
+	mode Mode
+
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// SetMode задает формат вывода по глобальным флагам
+func SetMode(jsonOut, quiet bool) error {
+	switch {
+	case jsonOut && quiet:
+		return ErrConflictingModes
+	case jsonOut:
+		mode = JSON
+	case quiet:
+		mode = Quiet
+	default:
+		mode = Text
+	}
+	return nil
+}
+
+// Current возвращает текущий формат вывода
+func Current() Mode {
+	return mode
+}
+
+// UseFlag учитывает флаг команды -o: значение json включает вывод в JSON.
+// Флаг оставлен у команд, где он был до появления глобального --json.
+func UseFlag(format string) {
+	if format == "json" {
+		mode = JSON
+	}
+}
+
+// Result - результат команды в трех представлениях
+type Result struct {
+	// Value выводится в JSON, обычно значение из schema.go
+	Value interface{}
+	// IDs выводятся с --quiet
+	IDs []int
+	// Text выводит результат для человека
+	Text func() error
+}
+
+// Render выводит результат в текущем формате
+func Render(r Result) error {
+	switch mode {
+	case JSON:
+		return Print(r.Value)
+	case Quiet:
+		for _, id := range r.IDs {
+			if _, err := fmt.Fprintln(stdout, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if r.Text == nil {
+			return nil
+		}
+		return r.Text()
+	}
+}
+
+// Print выводит значение в JSON с отступами
+func Print(v interface{}) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// PrintError выводит ошибку команды в stderr: в режиме --json - объектом Error
+func PrintError(err error, code int) {
+	if mode != JSON {
+		_, _ = fmt.Fprintf(stderr, "Ошибка: %v\n", err)
+		return
+	}
+	encoder := json.NewEncoder(stderr)
+	_ = encoder.Encode(Error{Error: err.Error(), ExitCode: code})
+}
@@ -0,0 +1,377 @@
+package output
+
+import (
+	"encoding/json"
+	"time"
+
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/config"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/stats"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Error - ошибка команды в режиме --json (stderr)
+type Error struct {
+	Error    string `json:"error"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Record - запись в списках record list и record trash list.
+// Секреты и шифротекст в вывод не попадают.
+type Record struct {
+	ID        int        `json:"id"`
+	ServerID  int        `json:"server_id,omitempty"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Details   string     `json:"details,omitempty"`
+	Locked    bool       `json:"locked"`
+	Synced    bool       `json:"synced"`
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Device - устройство синхронизации
+type Device struct {
+	ID       int        `json:"id"`
+	UUID     string     `json:"uuid,omitempty"`
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Current  bool       `json:"current"`
+	LastSync *time.Time `json:"last_sync,omitempty"`
+	// Status - approved или pending (ожидает подтверждения)
+	Status      string `json:"status"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Usage - трафик и операции синхронизации устройства за все время
+	Usage sync.DeviceUsage `json:"usage"`
+}
+
+// Conflict - неразрешенный конфликт синхронизации
+type Conflict struct {
+	ID             int       `json:"id"`
+	RecordID       int       `json:"record_id"`
+	RecordType     string    `json:"record_type,omitempty"`
+	Title          string    `json:"title,omitempty"`
+	ConflictType   string    `json:"conflict_type"`
+	LocalVersion   int       `json:"local_version"`
+	ServerVersion  int       `json:"server_version"`
+	LocalModified  time.Time `json:"local_modified"`
+	ServerModified time.Time `json:"server_modified"`
+}
+
+// SyncResult - итог gophkeeper sync. Выводится и тогда, когда синхронизация
+// завершилась ошибкой: success равен false, а причины перечислены в errors.
+type SyncResult struct {
+	Uploaded   int   `json:"uploaded"`
+	Downloaded int   `json:"downloaded"`
+	Conflicts  int   `json:"conflicts"`
+	Resolved   int   `json:"resolved"`
+	Failed     int   `json:"failed"`
+	DurationMS int64 `json:"duration_ms"`
+	// PausedUntil - сервер на обслуживании, синхронизация отложена до этого времени
+	PausedUntil *time.Time  `json:"paused_until,omitempty"`
+	Success     bool        `json:"success"`
+	StartedAt   time.Time   `json:"started_at"`
+	FinishedAt  time.Time   `json:"finished_at"`
+	Errors      []SyncError `json:"errors"`
+	// Expiring - записи, срок действия которых истекает в окно expiry_warning
+	Expiring []record.Expiration `json:"expiring,omitempty"`
+}
+
+// SyncError - ошибка одного шага синхронизации или одной записи
+type SyncError struct {
+	// Operation - шаг: pre_sync_check, get_server_changes, upload, download_update и т.д.
+	Operation string `json:"operation"`
+	// RecordID - локальный ID записи, если ошибка относится к записи
+	RecordID int       `json:"record_id,omitempty"`
+	Error    string    `json:"error"`
+	Time     time.Time `json:"time"`
+}
+
+// SyncPlan - план gophkeeper sync --dry-run: что было бы отправлено на
+// сервер, загружено с него и как разрешены конфликты
+type SyncPlan struct {
+	Upload    []client.PlannedChange `json:"upload"`
+	Download  []client.PlannedChange `json:"download"`
+	Conflicts []PlannedConflict      `json:"conflicts"`
+	Strategy  string                 `json:"strategy"`
+	Filter    *Filter                `json:"filter,omitempty"`
+	// Errors - шаги, которые не удалось выполнить: план по ним неполный
+	Errors []SyncError `json:"errors"`
+}
+
+// PlannedConflict - конфликт, который возник бы при синхронизации
+type PlannedConflict struct {
+	RecordID       int       `json:"record_id"`
+	RecordType     string    `json:"record_type,omitempty"`
+	Title          string    `json:"title,omitempty"`
+	ConflictType   string    `json:"conflict_type"`
+	LocalVersion   int       `json:"local_version"`
+	ServerVersion  int       `json:"server_version"`
+	LocalModified  time.Time `json:"local_modified"`
+	ServerModified time.Time `json:"server_modified"`
+	// Resolution - client, server, merged или manual (потребуется ручное разрешение)
+	Resolution string `json:"resolution"`
+}
+
+// SyncStatus - статистика и состояние синхронизации (gophkeeper sync --status)
+type SyncStatus struct {
+	TotalSyncs      int        `json:"total_syncs"`
+	Successful      int        `json:"successful"`
+	Failed          int        `json:"failed"`
+	Uploaded        int        `json:"uploaded"`
+	Downloaded      int        `json:"downloaded"`
+	Conflicts       int        `json:"conflicts"`
+	Resolved        int        `json:"resolved"`
+	AvgDurationSecs float64    `json:"avg_duration_seconds"`
+	LastSync        *time.Time `json:"last_sync,omitempty"`
+	Filter          *Filter    `json:"filter,omitempty"`
+	Server          Server     `json:"server"`
+	Authenticated   bool       `json:"authenticated"`
+}
+
+// Status - сводка состояния клиента (gophkeeper status). Раздел, который
+// не удалось получить, пуст, а причина указана в поле *_error.
+type Status struct {
+	// Profile - выбранный профиль; пусто - без профиля
+	Profile      string             `json:"profile,omitempty"`
+	Initialized  bool               `json:"initialized"`
+	Auth         Auth               `json:"auth"`
+	MasterKey    MasterKey          `json:"master_key"`
+	Records      *client.LocalStats `json:"records,omitempty"`
+	RecordsError string             `json:"records_error,omitempty"`
+	Sync         LastSync           `json:"sync"`
+	Server       Server             `json:"server"`
+	Quota        *client.QuotaUsage `json:"quota,omitempty"`
+	QuotaError   string             `json:"quota_error,omitempty"`
+}
+
+// Stats - статистика хранилища (gophkeeper stats): записи, рост и
+// устройства с сервера и локальная статистика синхронизации. Если
+// серверную часть получить не удалось, причина указана в server_error.
+type Stats struct {
+	Profile     string            `json:"profile,omitempty"`
+	Server      *stats.Stats      `json:"server,omitempty"`
+	ServerError string            `json:"server_error,omitempty"`
+	Sync        *client.SyncStats `json:"sync"`
+}
+
+// Auth - вход на сервер
+type Auth struct {
+	Authenticated bool       `json:"authenticated"`
+	Login         string     `json:"login,omitempty"`
+	LoggedInAt    *time.Time `json:"logged_in_at,omitempty"`
+	// ExpiresAt - окончание сессии на сервере, после него нужен повторный вход
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Expired   bool       `json:"expired"`
+}
+
+// MasterKey - состояние мастер-ключа
+type MasterKey struct {
+	Unlocked bool `json:"unlocked"`
+}
+
+// LastSync - итог последнего запуска синхронизации
+type LastSync struct {
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	Success     bool       `json:"success"`
+	Error       string     `json:"error,omitempty"`
+	TotalSyncs  int        `json:"total_syncs"`
+	// PausedUntil - сервер на обслуживании, синхронизация отложена до этого времени
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
+// Filter - фильтр выборочной синхронизации
+type Filter struct {
+	Types       []string `json:"types,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ExcludeTags []string `json:"exclude_tags,omitempty"`
+}
+
+// Server - доступность сервера
+type Server struct {
+	OK               bool       `json:"ok"`
+	LatencyMS        int64      `json:"latency_ms,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	MaintenanceUntil *time.Time `json:"maintenance_until,omitempty"`
+}
+
+// Config - действующая конфигурация клиента (config list)
+type Config struct {
+	File    string         `json:"file"`
+	Profile string         `json:"profile,omitempty"`
+	Values  []config.Value `json:"values"`
+}
+
+// Profile - профиль из файла конфигурации (profile list)
+type Profile struct {
+	Name          string `json:"name"`
+	Active        bool   `json:"active"`
+	Default       bool   `json:"default"`
+	ServerAddress string `json:"server_address,omitempty"`
+	Dir           string `json:"dir,omitempty"`
+	LoggedIn      bool   `json:"logged_in"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Records преобразует локальные записи в схему вывода
+func Records(records []*client.LocalRecord) []Record {
+	out := make([]Record, 0, len(records))
+	for _, rec := range records {
+		r := Record{
+			ID:        rec.ID,
+			ServerID:  rec.ServerID,
+			Type:      string(rec.Type),
+			Title:     RecordTitle(rec),
+			Locked:    record.IsLocked(rec.Meta),
+			Synced:    rec.Synced,
+			Version:   rec.Version,
+			CreatedAt: rec.CreatedAt,
+			UpdatedAt: rec.LastModified,
+			DeletedAt: rec.DeletedAt,
+		}
+		if rec.Preview != nil {
+			r.Details = rec.Preview.Summary()
+		}
+		if expiresAt, ok := record.ExpiresAt(rec.Meta); ok {
+			r.ExpiresAt = &expiresAt
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// RecordTitle возвращает название записи из превью, а без него - из метаданных
+func RecordTitle(rec *client.LocalRecord) string {
+	if rec.Preview != nil && rec.Preview.Title != "" {
+		return rec.Preview.Title
+	}
+	var meta struct {
+		Title string `json:"title"`
+	}
+	if len(rec.Meta) > 0 && json.Unmarshal(rec.Meta, &meta) == nil {
+		return meta.Title
+	}
+	return ""
+}
+
+// RecordIDs возвращает локальные ID записей для --quiet
+func RecordIDs(records []*client.LocalRecord) []int {
+	ids := make([]int, len(records))
+	for i, rec := range records {
+		ids[i] = rec.ID
+	}
+	return ids
+}
+
+// Devices преобразует устройства в схему вывода; current - UUID этой установки
+func Devices(devices []sync.DeviceInfo, current string) []Device {
+	out := make([]Device, 0, len(devices))
+	for _, d := range devices {
+		dev := Device{
+			ID:          d.ID,
+			UUID:        d.UUID,
+			Name:        d.Name,
+			Type:        d.Type,
+			Current:     d.UUID != "" && d.UUID == current,
+			Status:      string(d.Status),
+			Fingerprint: sync.DeviceFingerprint(d.PublicKey),
+			Usage:       d.Usage,
+		}
+		if !d.LastSyncTime.IsZero() {
+			last := d.LastSyncTime
+			dev.LastSync = &last
+		}
+		out = append(out, dev)
+	}
+	return out
+}
+
+// SyncResultOf преобразует итог синхронизации в схему вывода; duration -
+// полное время команды вместе с проверкой соединения
+func SyncResultOf(result *client.SyncResult, duration time.Duration) SyncResult {
+	out := SyncResult{
+		Uploaded:   result.Uploaded,
+		Downloaded: result.Downloaded,
+		Conflicts:  result.Conflicts,
+		Resolved:   result.Resolved,
+		Failed:     len(result.Errors),
+		DurationMS: duration.Milliseconds(),
+		Success:    result.Success,
+		StartedAt:  result.StartTime,
+		FinishedAt: result.EndTime,
+	}
+	if result.Paused != nil {
+		until := result.Paused.Until
+		out.PausedUntil = &until
+	}
+	out.Errors = syncErrors(result.Errors)
+	return out
+}
+
+// SyncPlanOf преобразует план синхронизации в схему вывода
+func SyncPlanOf(plan *client.SyncPlan) SyncPlan {
+	out := SyncPlan{
+		Upload:    plan.Upload,
+		Download:  plan.Download,
+		Conflicts: make([]PlannedConflict, 0, len(plan.Conflicts)),
+		Strategy:  plan.Strategy,
+		Errors:    syncErrors(plan.Errors),
+	}
+	if !plan.Filter.IsEmpty() {
+		out.Filter = &Filter{Types: plan.Filter.Types, Tags: plan.Filter.Tags, ExcludeTags: plan.Filter.ExcludeTags}
+	}
+	for _, c := range plan.Conflicts {
+		resolution := c.Resolution
+		if resolution == "" {
+			resolution = "manual"
+		}
+		out.Conflicts = append(out.Conflicts, PlannedConflict{
+			RecordID:       c.RecordID,
+			RecordType:     c.RecordType,
+			Title:          c.Title,
+			ConflictType:   c.ConflictType,
+			LocalVersion:   c.LocalVersion,
+			ServerVersion:  c.ServerVersion,
+			LocalModified:  c.LocalModified,
+			ServerModified: c.ServerModified,
+			Resolution:     resolution,
+		})
+	}
+	return out
+}
+
+func syncErrors(errs []client.SyncError) []SyncError {
+	out := make([]SyncError, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, SyncError{
+			Operation: e.Operation,
+			RecordID:  e.RecordID,
+			Error:     e.Error,
+			Time:      e.Timestamp,
+		})
+	}
+	return out
+}
+
+// Conflicts преобразует конфликты в схему вывода
+func Conflicts(conflicts []sync.Conflict) []Conflict {
+	out := make([]Conflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		out = append(out, Conflict{
+			ID:             c.ID,
+			RecordID:       c.RecordID,
+			RecordType:     c.RecordType,
+			Title:          c.Title,
+			ConflictType:   c.ConflictType,
+			LocalVersion:   c.LocalVersion,
+			ServerVersion:  c.ServerVersion,
+			LocalModified:  c.LocalModified,
+			ServerModified: c.ServerModified,
+		})
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client"
+)
+
+// renderJSON выводит значение так же, как команда с --json, и разбирает вывод
+func renderJSON(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	var buf bytes.Buffer
+	prevOut, prevMode := stdout, mode
+	stdout, mode = &buf, JSON
+	t.Cleanup(func() { stdout, mode = prevOut, prevMode })
+
+	require.NoError(t, Render(Result{Value: v}))
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	return out
+}
+
+func TestSyncResultOf(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &client.SyncResult{
+		Uploaded:   3,
+		Downloaded: 1,
+		Conflicts:  1,
+		Resolved:   1,
+		StartTime:  start,
+		EndTime:    start.Add(2 * time.Second),
+		Errors: []client.SyncError{
+			{Operation: "upload", RecordID: 42, Error: "превышена квота", Timestamp: start.Add(time.Second)},
+			{Operation: "get_server_changes", Error: "сервер недоступен", Timestamp: start.Add(2 * time.Second)},
+		},
+	}
+
+	out := renderJSON(t, SyncResultOf(result, 2500*time.Millisecond))
+	assert.Equal(t, false, out["success"])
+	assert.EqualValues(t, 3, out["uploaded"])
+	assert.EqualValues(t, 2, out["failed"])
+	assert.EqualValues(t, 2500, out["duration_ms"])
+	assert.Equal(t, "2025-06-01T12:00:00Z", out["started_at"])
+	assert.Equal(t, "2025-06-01T12:00:02Z", out["finished_at"])
+	assert.NotContains(t, out, "paused_until")
+
+	errs, ok := out["errors"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Equal(t, map[string]interface{}{
+		"operation": "upload",
+		"record_id": float64(42),
+		"error":     "превышена квота",
+		"time":      "2025-06-01T12:00:01Z",
+	}, errs[0])
+	// Ошибка шага синхронизации не относится к записи
+	assert.NotContains(t, errs[1], "record_id")
+}
+
+func TestSyncResultOf_Paused(t *testing.T) {
+	until := time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC)
+	result := &client.SyncResult{Paused: &client.MaintenanceError{Until: until}}
+
+	out := renderJSON(t, SyncResultOf(result, 0))
+	assert.Equal(t, false, out["success"])
+	assert.Equal(t, "2025-06-01T13:00:00Z", out["paused_until"])
+	// Пустой список ошибок выводится массивом, а не null, чтобы jq '.errors[]' не падал
+	assert.Equal(t, []interface{}{}, out["errors"])
+}
@@ -0,0 +1,111 @@
+package pin
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// PINCmd - родительская команда разблокировки по PIN
+var PINCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Быстрая разблокировка по PIN",
+	Long: `Разблокировка мастер-ключа коротким PIN вместо мастер-пароля.
+
+Копия мастер-ключа (файл <master_key_path>.pin) шифруется ключом, который
+получается из PIN и случайного секрета в хранилище секретов ОС. Без хранилища
+ОС этой учетной записи PIN бесполезен, поэтому короткого PIN достаточно.
+
+После нескольких неверных PIN подряд (pin_attempts, по умолчанию 3) копия
+удаляется, и разблокировать ключ можно только мастер-паролем. PIN можно
+включить заново командой gophkeeper pin enable.`,
+}
+
+var EnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Включить или сменить PIN",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		pin, err := prompt.Password("Введите PIN: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения PIN: %w", err)
+		}
+
+		pinConfirm, err := prompt.Password("Повторите PIN: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения PIN: %w", err)
+		}
+
+		if pin != pinConfirm {
+			return fmt.Errorf("PIN не совпадают")
+		}
+
+		if err := app.EnablePIN(pin); err != nil {
+			return fmt.Errorf("ошибка включения PIN: %w", err)
+		}
+
+		fmt.Printf("✅ Разблокировка по PIN включена (ключ защищен %s)\n", app.KeychainName())
+		fmt.Println("Теперь gophkeeper unlock запрашивает PIN вместо мастер-пароля.")
+		return nil
+	},
+}
+
+var DisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Отключить разблокировку по PIN",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.DisablePIN(); err != nil {
+			return fmt.Errorf("ошибка отключения PIN: %w", err)
+		}
+
+		fmt.Println("✅ Разблокировка по PIN отключена")
+		return nil
+	},
+}
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Состояние разблокировки по PIN",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		if !app.PINEnabled() {
+			fmt.Println("Включено:   ❌ нет (gophkeeper pin enable)")
+			return nil
+		}
+
+		fmt.Println("Включено:   ✅ да")
+		fmt.Printf("Хранилище:  %s\n", app.KeychainName())
+		fmt.Printf("Попыток:    %d\n", app.PINAttemptsLeft())
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsInitialized() {
+		return nil, fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+	}
+
+	return app, nil
+}
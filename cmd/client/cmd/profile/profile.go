@@ -0,0 +1,246 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/app/client/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	clearProfile bool
+	addServer    string
+	addTLS       bool
+)
+
+// ProfileCmd - родительская команда профилей
+var ProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Профили: несколько серверов и учетных записей на одной машине",
+	Long: `Профиль - именованный раздел profiles файла конфигурации со своим
+адресом сервера и другими параметрами. У каждого профиля свой каталог
+данных (<config_dir>/profiles/<профиль>): токен входа, мастер-ключ,
+локальная база и состояние синхронизации, поэтому профили не мешают
+друг другу и синхронизируются независимо.
+
+Профиль выбирает флаг --profile, переменная GOPHKEEPER_PROFILE или
+профиль по умолчанию, заданный командой gophkeeper profile use.
+
+Без подкоманды выводит список профилей.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return listProfiles(cmd)
+	},
+}
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Показать профили",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return listProfiles(cmd)
+	},
+}
+
+var AddCmd = &cobra.Command{
+	Use:   "add [имя]",
+	Short: "Создать профиль",
+	Example: `  gophkeeper profile add work --server keeper.work.example:443 --tls
+  gophkeeper --profile work auth login`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		cfg := app.Config()
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			return err
+		}
+
+		path := cfg.FilePath()
+		names, _, err := config.FileProfiles(path)
+		if err != nil {
+			return err
+		}
+		for _, existing := range names {
+			if existing == name {
+				return fmt.Errorf("профиль %s уже существует. Изменить его: gophkeeper --profile %s config set <ключ> <значение>", name, name)
+			}
+		}
+
+		if _, err := config.SetFileValue(path, name, "server-address", addServer); err != nil {
+			return err
+		}
+		if addTLS {
+			if _, err := config.SetFileValue(path, name, "enable-tls", "true"); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("✅ Профиль %s создан (%s)\n", name, path)
+		fmt.Printf("Данные профиля: %s\n", config.ProfileDir(cfg.RootDir, name))
+		fmt.Println("Дальше:")
+		fmt.Printf("  gophkeeper --profile %s init\n", name)
+		fmt.Printf("  gophkeeper --profile %s auth login\n", name)
+		fmt.Printf("  gophkeeper profile use %s   # сделать профилем по умолчанию\n", name)
+		return nil
+	},
+}
+
+var UseCmd = &cobra.Command{
+	Use:   "use [имя]",
+	Short: "Выбрать профиль по умолчанию",
+	Long: `Записывает профиль по умолчанию в файл конфигурации: последующие
+команды без --profile работают с ним. Без аргументов показывает текущий
+профиль, --clear возвращает работу без профиля.`,
+	Example: `  gophkeeper profile use work
+  gophkeeper profile use
+  gophkeeper profile use --clear`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		cfg := app.Config()
+		path := cfg.FilePath()
+
+		switch {
+		case clearProfile:
+			if len(args) > 0 {
+				return fmt.Errorf("флаг --clear не используется вместе с именем профиля")
+			}
+			if err := config.SetFileProfile(path, ""); err != nil {
+				return err
+			}
+			fmt.Println("✅ Профиль по умолчанию сброшен")
+		case len(args) == 0:
+			if cfg.Profile == "" {
+				fmt.Println("Профиль не выбран")
+			} else {
+				fmt.Printf("Текущий профиль: %s (%s)\n", cfg.Profile, cfg.ConfigDir)
+			}
+			return nil
+		default:
+			if err := config.SetFileProfile(path, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Профиль по умолчанию: %s\n", args[0])
+		}
+
+		if env := os.Getenv(config.ProfileEnv); env != "" {
+			fmt.Printf("⚠️  Переменная %s=%s важнее профиля по умолчанию\n", config.ProfileEnv, env)
+		}
+		return nil
+	},
+}
+
+var RemoveCmd = &cobra.Command{
+	Use:   "remove [имя]",
+	Short: "Удалить профиль из файла конфигурации",
+	Long: `Удаляет раздел профиля из файла конфигурации. Каталог данных профиля
+(мастер-ключ, база) не удаляется: удалите его вручную, если данные
+больше не нужны. Если это был профиль по умолчанию, команды работают
+без профиля.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		cfg := app.Config()
+
+		if err := config.RemoveFileProfile(cfg.FilePath(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Профиль %s удален\n", args[0])
+		fmt.Printf("Данные профиля остались в %s\n", config.ProfileDir(cfg.RootDir, args[0]))
+		return nil
+	},
+}
+
+func init() {
+	AddCmd.Flags().StringVar(&addServer, "server", "", "адрес сервера профиля")
+	AddCmd.Flags().BoolVar(&addTLS, "tls", false, "подключаться к серверу по HTTPS")
+	_ = AddCmd.MarkFlagRequired("server")
+
+	UseCmd.Flags().BoolVar(&clearProfile, "clear", false, "работать без профиля")
+}
+
+func listProfiles(cmd *cobra.Command) error {
+	app, err := appFrom(cmd)
+	if err != nil {
+		return err
+	}
+	cfg := app.Config()
+
+	names, def, err := config.FileProfiles(cfg.FilePath())
+	if err != nil {
+		return err
+	}
+
+	profiles := make([]output.Profile, 0, len(names))
+	for _, name := range names {
+		p := output.Profile{Name: name, Active: name == cfg.Profile, Default: name == def}
+		// Параметры профиля собираются так же, как при запуске с --profile
+		pcfg, err := config.Load(config.Options{File: cfg.File, Profile: name})
+		if err != nil {
+			p.Error = err.Error()
+		} else {
+			p.ServerAddress = pcfg.ServerAddress
+			p.Dir = pcfg.ConfigDir
+			_, serr := os.Stat(pcfg.TokenPath)
+			p.LoggedIn = serr == nil
+		}
+		profiles = append(profiles, p)
+	}
+
+	return output.Render(output.Result{
+		Value: profiles,
+		Text: func() error {
+			if len(profiles) == 0 {
+				fmt.Println("Профилей нет. Создайте: gophkeeper profile add <имя> --server <адрес>")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  \tПРОФИЛЬ\tСЕРВЕР\tВХОД\tДАННЫЕ")
+			for _, p := range profiles {
+				marker := " "
+				if p.Active {
+					marker = "*"
+				}
+				name := p.Name
+				if p.Default {
+					name += " (по умолчанию)"
+				}
+				if p.Error != "" {
+					fmt.Fprintf(w, "%s\t%s\t❌ %s\t\t\n", marker, name, p.Error)
+					continue
+				}
+				login := "нет"
+				if p.LoggedIn {
+					login = "да"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, name, p.ServerAddress, login, p.Dir)
+			}
+			return w.Flush()
+		},
+	})
+}
+
+func appFrom(cmd *cobra.Command) (*client.App, error) {
+	app, _ := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	return app, nil
+}
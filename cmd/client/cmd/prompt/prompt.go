@@ -0,0 +1,129 @@
+// Package prompt - интерактивный ввод команд CLI. С глобальным флагом
+// --non-interactive обязательный ввод не запрашивается: команда завершается
+// ошибкой ErrNonInteractive, поэтому скрипт или CI не зависает в ожидании
+// ввода. Необязательные значения в этом режиме остаются пустыми.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	gosync "sync"
+
+	"golang.org/x/term"
+)
+
+// ErrNonInteractive - команде нужен ввод, а включен неинтерактивный режим
+var ErrNonInteractive = errors.New("неинтерактивный режим (--non-interactive)")
+
+var (
+	nonInteractive bool
+
+	stdinOnce gosync.Once
+	stdin     *bufio.Reader
+)
+
+// SetNonInteractive включает неинтерактивный режим
+func SetNonInteractive(v bool) {
+	nonInteractive = v
+}
+
+// NonInteractive сообщает, включен ли неинтерактивный режим
+func NonInteractive() bool {
+	return nonInteractive
+}
+
+// Password запрашивает секрет без эха в терминале
+func Password(label string) (string, error) {
+	if err := check(label); err != nil {
+		return "", err
+	}
+
+	fmt.Print(label)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// Line запрашивает обязательную строку. Пробелы по краям отбрасываются.
+func Line(label string) (string, error) {
+	if err := check(label); err != nil {
+		return "", err
+	}
+
+	fmt.Print(label)
+	return readLine()
+}
+
+// Optional запрашивает необязательную строку. В неинтерактивном режиме
+// возвращает пустую строку без запроса.
+func Optional(label string) (string, error) {
+	if nonInteractive {
+		return "", nil
+	}
+
+	fmt.Print(label)
+	return readLine()
+}
+
+// Text читает многострочный текст до конца ввода (Ctrl+D)
+func Text(label string) (string, error) {
+	if err := check(label); err != nil {
+		return "", err
+	}
+
+	fmt.Println(label)
+	data, err := io.ReadAll(reader())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// Confirm запрашивает подтверждение [y/N]. В неинтерактивном режиме
+// возвращает ErrNonInteractive: подтверждение передается флагом команды.
+func Confirm(label string) (bool, error) {
+	if err := check(label); err != nil {
+		return false, err
+	}
+
+	fmt.Print(label + " [y/N]: ")
+	answer, err := readLine()
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"), nil
+}
+
+// check возвращает ErrNonInteractive с названием запрошенного значения
+func check(label string) error {
+	if !nonInteractive {
+		return nil
+	}
+	name := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(label), ":?"))
+	return fmt.Errorf("%w: команде нужен ввод «%s»", ErrNonInteractive, name)
+}
+
+// reader - общий буфер стандартного ввода, чтобы строки, прочитанные
+// с запасом одним запросом, не терялись для следующего
+func reader() *bufio.Reader {
+	stdinOnce.Do(func() {
+		stdin = bufio.NewReader(os.Stdin)
+	})
+	return stdin
+}
+
+// readLine читает строку; конец ввода без перевода строки - не ошибка
+func readLine() (string, error) {
+	line, err := reader().ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
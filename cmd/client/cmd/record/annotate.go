@@ -0,0 +1,45 @@
+// cmd/client/cmd/record/annotate.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var AnnotateCmd = &cobra.Command{
+	Use:   "annotate [id] [текст]",
+	Short: "Добавить пометку к записи",
+	Long: `Добавляет к записи датированную пометку, например о смене пароля после инцидента.
+
+Пометки шифруются отдельно от данных записи и только дополняются: они
+сохраняются при восстановлении старой версии и синхронизируются на все
+устройства. Пометку можно добавить и к защищенной записи.
+Просмотр: gophkeeper record get <id> --notes`,
+	Example:           `  gophkeeper record annotate 12 "пароль сменен после инцидента INC-42"`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		if err := app.AnnotateRecord(cmd.Context(), recordID, strings.Join(args[1:], " ")); err != nil {
+			return err
+		}
+
+		fmt.Printf("📝 Пометка добавлена к записи %d\n", recordID)
+		return nil
+	},
+}
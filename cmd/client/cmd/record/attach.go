@@ -0,0 +1,138 @@
+// cmd/client/cmd/record/attach.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var downloadForce bool
+
+var AttachCmd = &cobra.Command{
+	Use:   "attach [id] [file...]",
+	Short: "Прикрепить файлы к записи",
+	Long: `Шифрует файлы мастер-ключом и прикрепляет их к записи любого типа:
+PDF с кодами восстановления к логину, скан к карте и т.п.
+
+Вложения хранятся на сервере отдельно от записи и не передаются при
+синхронизации: их список и содержимое загружаются по запросу. Размер
+файла - до 25 МБ, вложений у записи - до 20. Список вложений показывает
+gophkeeper record get <id> --decrypt.`,
+	Example: `  gophkeeper record attach 12 ~/Documents/github-recovery-codes.pdf
+  gophkeeper record attach 7 card-front.jpg card-back.jpg`,
+	ValidArgsFunction: complete.RecordIDThenFile,
+	Args:              cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		for _, path := range args[1:] {
+			info, err := app.AttachFile(cmd.Context(), recordID, path)
+			if err != nil {
+				return fmt.Errorf("ошибка прикрепления %s: %w", path, err)
+			}
+			fmt.Printf("📎 %s прикреплен к записи %d (вложение %d, %d байт)\n", info.Filename, recordID, info.ID, info.Size)
+		}
+		return nil
+	},
+}
+
+var DetachCmd = &cobra.Command{
+	Use:               "detach [id] [attachment-id]",
+	Short:             "Открепить файл от записи",
+	Long:              `Удаляет вложение записи с сервера. ID вложения показывает gophkeeper record get <id> --decrypt.`,
+	Example:           `  gophkeeper record detach 12 3`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, attachmentID, err := parseAttachmentArgs(args)
+		if err != nil {
+			return err
+		}
+
+		if err := app.DetachFile(cmd.Context(), recordID, attachmentID); err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️ Вложение %d откреплено от записи %d\n", attachmentID, recordID)
+		return nil
+	},
+}
+
+var DownloadAttachmentCmd = &cobra.Command{
+	Use:   "download-attachment [id] [attachment-id] [path]",
+	Short: "Скачать вложение записи",
+	Long: `Загружает вложение, расшифровывает его и сохраняет с правами 0600.
+Если путь - каталог или не указан, файл сохраняется в каталоге под исходным именем.`,
+	Example: `  gophkeeper record download-attachment 12 3
+  gophkeeper record download-attachment 12 3 ~/Downloads/codes.pdf`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, attachmentID, err := parseAttachmentArgs(args)
+		if err != nil {
+			return err
+		}
+
+		path := "."
+		if len(args) == 3 {
+			path = args[2]
+		}
+
+		written, err := app.DownloadAttachment(cmd.Context(), recordID, attachmentID, path, downloadForce)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Вложение сохранено: %s\n", written)
+		return nil
+	},
+}
+
+func parseAttachmentArgs(args []string) (recordID, attachmentID int, err error) {
+	if recordID, err = strconv.Atoi(args[0]); err != nil {
+		return 0, 0, fmt.Errorf("неверный ID записи: %w", err)
+	}
+	if attachmentID, err = strconv.Atoi(args[1]); err != nil {
+		return 0, 0, fmt.Errorf("неверный ID вложения: %w", err)
+	}
+	return recordID, attachmentID, nil
+}
+
+// printAttachments выводит вложения записи; без вложений ничего не выводит
+func printAttachments(attachments []client.AttachmentInfo) {
+	if len(attachments) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("=== Вложения ===")
+	for _, att := range attachments {
+		fmt.Printf("  [%d] %s (%d байт, %s)\n", att.ID, att.Filename, att.Size, att.CreatedAt.Format("2006-01-02 15:04"))
+	}
+}
+
+func init() {
+	DownloadAttachmentCmd.Flags().BoolVarP(&downloadForce, "force", "f", false, "перезаписать существующий файл")
+}
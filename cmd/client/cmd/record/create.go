@@ -2,10 +2,10 @@
 package record
 
 import (
-	"bufio"
 	"crypto/rand"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
 	"gophkeeper/internal/app/client"
 	"math/big"
 	"os"
@@ -28,6 +28,14 @@ var (
 	expiryDate  string
 	cvv         string
 	filePath    string
+	otpSecret   string
+	otpIssuer   string
+	otpAccount  string
+	otpDigits   int
+	otpPeriod   int
+	otpAlgo     string
+	sshPass     string
+	sshComment  string
 )
 
 var CreateCmd = &cobra.Command{
@@ -39,7 +47,9 @@ var CreateCmd = &cobra.Command{
 - password - логин и пароль
 - note     - текстовая заметка
 - card     - данные банковской карты
-- file     - бинарный файл`,
+- file     - бинарный файл
+- otp      - секрет двухфакторной аутентификации (TOTP)
+- ssh-key  - SSH-ключ (и сертификат, если рядом лежит *-cert.pub)`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
 		if app == nil {
@@ -51,7 +61,7 @@ var CreateCmd = &cobra.Command{
 			fmt.Println()
 			fmt.Println("Для создания записей необходимо разблокировать мастер-ключ.")
 			fmt.Println("Выполните команду: gophkeeper unlock")
-			return fmt.Errorf("мастер-ключ заблокирован")
+			return client.ErrMasterKeyLocked
 		}
 
 		if recordType == "" {
@@ -60,10 +70,13 @@ var CreateCmd = &cobra.Command{
 			fmt.Println("2. Текстовая заметка")
 			fmt.Println("3. Банковская карта")
 			fmt.Println("4. Файл")
-			fmt.Print("Ваш выбор [1-4]: ")
+			fmt.Println("5. Секрет TOTP (2FA)")
+			fmt.Println("6. SSH-ключ")
 
-			var choice string
-			_, _ = fmt.Scanln(&choice)
+			choice, err := prompt.Line("Ваш выбор [1-6]: ")
+			if err != nil {
+				return err
+			}
 
 			switch choice {
 			case "1":
@@ -74,6 +87,10 @@ var CreateCmd = &cobra.Command{
 				recordType = "card"
 			case "4":
 				recordType = "file"
+			case "5":
+				recordType = "otp"
+			case "6":
+				recordType = "ssh-key"
 			default:
 				return fmt.Errorf("неверный выбор")
 			}
@@ -81,11 +98,11 @@ var CreateCmd = &cobra.Command{
 
 		// Запрашиваем имя записи
 		if recordName == "" {
-			fmt.Print("Название записи: ")
-			scanner := bufio.NewScanner(os.Stdin)
-			if scanner.Scan() {
-				recordName = scanner.Text()
+			name, err := prompt.Line("Название записи: ")
+			if err != nil {
+				return err
 			}
+			recordName = name
 			if recordName == "" {
 				return fmt.Errorf("название записи обязательно")
 			}
@@ -104,6 +121,10 @@ var CreateCmd = &cobra.Command{
 			recordID, err = createCardRecord(cmd, app)
 		case "file":
 			recordID, err = createFileRecord(cmd, app)
+		case "otp":
+			recordID, err = createOTPRecord(cmd, app)
+		case "ssh-key":
+			recordID, err = createSSHKeyRecord(cmd, app)
 		default:
 			return fmt.Errorf("неподдерживаемый тип записи: %s", recordType)
 		}
@@ -121,17 +142,14 @@ var CreateCmd = &cobra.Command{
 
 func createPasswordRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	if username == "" {
-		fmt.Print("Логин/Email: ")
-		_, err := fmt.Scanln(&username)
-		if err != nil {
+		var err error
+		if username, err = prompt.Line("Логин/Email: "); err != nil {
 			return 0, err
 		}
 	}
 
 	if password == "" {
-		fmt.Print("Пароль (оставьте пустым для генерации): ")
-		var pass string
-		_, err := fmt.Scanln(&pass)
+		pass, err := prompt.Optional("Пароль (оставьте пустым для генерации): ")
 		if err != nil {
 			return 0, err
 		}
@@ -146,9 +164,8 @@ func createPasswordRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	}
 
 	if url == "" {
-		fmt.Print("URL (необязательно): ")
-		_, err := fmt.Scanln(&url)
-		if err != nil {
+		var err error
+		if url, err = prompt.Optional("URL (необязательно): "); err != nil {
 			return 0, err
 		}
 	}
@@ -167,13 +184,10 @@ func createPasswordRecord(cmd *cobra.Command, app *client.App) (int, error) {
 
 func createNoteRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	if noteContent == "" {
-		fmt.Println("Введите текст заметки (Ctrl+D для завершения):")
-		scanner := bufio.NewScanner(os.Stdin)
-		var lines []string
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
+		var err error
+		if noteContent, err = prompt.Text("Введите текст заметки (Ctrl+D для завершения):"); err != nil {
+			return 0, err
 		}
-		noteContent = strings.Join(lines, "\n")
 	}
 
 	req := client.CreateTextRequest{
@@ -187,42 +201,51 @@ func createNoteRecord(cmd *cobra.Command, app *client.App) (int, error) {
 
 func createCardRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	if cardNumber == "" {
-		fmt.Print("Номер карты: ")
-		_, err := fmt.Scanln(&cardNumber)
-		if err != nil {
+		var err error
+		if cardNumber, err = prompt.Line("Номер карты: "); err != nil {
 			return 0, err
 		}
 	}
 
 	if cardHolder == "" {
-		fmt.Print("Держатель карты: ")
-		scanner := bufio.NewScanner(os.Stdin)
-		if scanner.Scan() {
-			cardHolder = scanner.Text()
+		var err error
+		if cardHolder, err = prompt.Line("Держатель карты: "); err != nil {
+			return 0, err
 		}
 	}
 
 	if expiryDate == "" {
-		fmt.Print("Срок действия (MM/YY): ")
-		_, _ = fmt.Scanln(&expiryDate)
+		var err error
+		if expiryDate, err = prompt.Line("Срок действия (MM/YY): "); err != nil {
+			return 0, err
+		}
 	}
 
 	if cvv == "" {
-		fmt.Print("CVV: ")
-		_, _ = fmt.Scanln(&cvv)
+		var err error
+		if cvv, err = prompt.Line("CVV: "); err != nil {
+			return 0, err
+		}
 	}
 
-	// Разбираем срок действия
-	parts := strings.Split(expiryDate, "/")
+	// Разбираем срок действия: MM/YY или MM/YYYY
+	parts := strings.Split(strings.TrimSpace(expiryDate), "/")
 	if len(parts) != 2 {
 		return 0, fmt.Errorf("неверный формат срока действия. Используйте MM/YY")
 	}
+	month, year := parts[0], parts[1]
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if len(year) == 2 {
+		year = "20" + year
+	}
 
 	req := client.CreateCardRequest{
 		CardNumber:  cardNumber,
 		CardHolder:  cardHolder,
-		ExpiryMonth: parts[0],
-		ExpiryYear:  "20" + parts[1], // Преобразуем YY в 20YY
+		ExpiryMonth: month,
+		ExpiryYear:  year,
 		CVV:         cvv,
 		Title:       recordName,
 		Notes:       description,
@@ -234,8 +257,10 @@ func createCardRecord(cmd *cobra.Command, app *client.App) (int, error) {
 
 func createFileRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	if filePath == "" {
-		fmt.Print("Путь к файлу: ")
-		_, _ = fmt.Scanln(&filePath)
+		var err error
+		if filePath, err = prompt.Line("Путь к файлу: "); err != nil {
+			return 0, err
+		}
 	}
 
 	// Читаем файл
@@ -263,6 +288,73 @@ func createFileRecord(cmd *cobra.Command, app *client.App) (int, error) {
 	return app.CreateBinaryRecord(cmd.Context(), req)
 }
 
+func createOTPRecord(cmd *cobra.Command, app *client.App) (int, error) {
+	if otpSecret == "" {
+		var err error
+		if otpSecret, err = prompt.Line("Секрет (base32): "); err != nil {
+			return 0, err
+		}
+	}
+
+	if otpIssuer == "" {
+		var err error
+		if otpIssuer, err = prompt.Optional("Сервис (необязательно): "); err != nil {
+			return 0, err
+		}
+	}
+
+	req := client.CreateOTPRequest{
+		Secret:      otpSecret,
+		Algorithm:   otpAlgo,
+		Digits:      otpDigits,
+		Period:      otpPeriod,
+		Title:       recordName,
+		Issuer:      otpIssuer,
+		AccountName: otpAccount,
+	}
+
+	fmt.Println("Создание записи...")
+	return app.CreateOTPRecord(cmd.Context(), req)
+}
+
+func createSSHKeyRecord(cmd *cobra.Command, app *client.App) (int, error) {
+	if filePath == "" {
+		var err error
+		if filePath, err = prompt.Line("Путь к ключу: "); err != nil {
+			return 0, err
+		}
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	req := client.CreateSSHKeyRequest{
+		Passphrase: sshPass,
+		Title:      recordName,
+		Comment:    sshComment,
+	}
+
+	// Передан открытый ключ - сохраняем только его
+	basePath := strings.TrimSuffix(filePath, ".pub")
+	if strings.HasSuffix(filePath, ".pub") {
+		req.PublicKey = string(data)
+	} else {
+		req.PrivateKey = string(data)
+		if pub, err := os.ReadFile(filePath + ".pub"); err == nil {
+			req.PublicKey = string(pub)
+		}
+	}
+
+	if cert, err := os.ReadFile(basePath + "-cert.pub"); err == nil {
+		req.Certificate = string(cert)
+	}
+
+	fmt.Println("Создание записи...")
+	return app.CreateSSHKeyRecord(cmd.Context(), req)
+}
+
 func generatePassword(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
 	result := make([]byte, length)
@@ -274,7 +366,9 @@ func generatePassword(length int) string {
 }
 
 func init() {
-	CreateCmd.Flags().StringVarP(&recordType, "type", "t", "", "тип записи (password, note, card, file)")
+	CreateCmd.Flags().StringVarP(&recordType, "type", "t", "", "тип записи (password, note, card, file, otp, ssh-key)")
+	_ = CreateCmd.RegisterFlagCompletionFunc("type",
+		cobra.FixedCompletions([]string{"password", "note", "card", "file", "otp", "ssh-key"}, cobra.ShellCompDirectiveNoFileComp))
 	CreateCmd.Flags().StringVarP(&recordName, "name", "n", "", "название записи")
 	CreateCmd.Flags().StringVar(&description, "desc", "", "описание записи")
 
@@ -293,5 +387,17 @@ func init() {
 	CreateCmd.Flags().StringVar(&cvv, "cvv", "", "CVV код")
 
 	// Флаги для файлов
-	CreateCmd.Flags().StringVar(&filePath, "file", "", "путь к файлу")
+	CreateCmd.Flags().StringVar(&filePath, "file", "", "путь к файлу (или к SSH-ключу)")
+
+	// Флаги для TOTP
+	CreateCmd.Flags().StringVar(&otpSecret, "secret", "", "секрет TOTP в base32")
+	CreateCmd.Flags().StringVar(&otpIssuer, "issuer", "", "сервис, выдавший секрет")
+	CreateCmd.Flags().StringVar(&otpAccount, "account", "", "имя учетной записи")
+	CreateCmd.Flags().IntVar(&otpDigits, "digits", 6, "количество цифр кода (6 или 8)")
+	CreateCmd.Flags().IntVar(&otpPeriod, "period", 30, "период смены кода в секундах")
+	CreateCmd.Flags().StringVar(&otpAlgo, "algorithm", "SHA1", "алгоритм (SHA1, SHA256, SHA512)")
+
+	// Флаги для SSH-ключей
+	CreateCmd.Flags().StringVar(&sshPass, "passphrase", "", "парольная фраза SSH-ключа")
+	CreateCmd.Flags().StringVar(&sshComment, "comment", "", "комментарий SSH-ключа")
 }
@@ -0,0 +1,60 @@
+// cmd/client/cmd/record/encrypt_meta.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var EncryptMetaCmd = &cobra.Command{
+	Use:   "encrypt-meta",
+	Short: "Зашифровать метаданные записей на сервере",
+	Long: `Повторно отправляет на сервер все синхронизированные записи, чтобы заменить
+их открытые метаданные (названия, адреса, теги) зашифрованными мастер-ключом.
+
+Сначала включите режим: gophkeeper config set encrypt-meta true. Новые и
+измененные записи шифруются автоматически, команда нужна один раз для записей,
+созданных раньше. После этого сервер не ищет записи по названию и не
+фильтрует синхронизацию по тегам: поиск выполняется по локальному хранилищу.
+Включите режим на всех устройствах, иначе они будут отправлять метаданные
+открытыми.`,
+	Example: `  gophkeeper config set encrypt-meta true
+  gophkeeper record encrypt-meta`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		result, err := app.EncryptRecordsMeta(cmd.Context())
+		if result != nil {
+			fmt.Printf("🔐 Зашифрованы метаданные записей: %d\n", result.Updated)
+			if result.Pending > 0 {
+				fmt.Printf("⏳ Не синхронизированы: %d (зашифруются при синхронизации)\n", result.Pending)
+			}
+			if result.Skipped > 0 {
+				fmt.Printf("🗑️  В корзине: %d (пропущены)\n", result.Skipped)
+			}
+			for _, reason := range result.Failed {
+				fmt.Printf("⚠️  Ошибка: %s\n", reason)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("не удалось обновить записей: %d, повторите команду", len(result.Failed))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,63 @@
+// cmd/client/cmd/record/expire.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/record"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var ExpireCmd = &cobra.Command{
+	Use:   "expire [id] [date|never]",
+	Short: "Задать срок действия записи",
+	Long: `Задает срок действия записи: окончание срока сертификата, дату плановой
+смены пароля и т.д. У карты срок заполняется при создании из срока действия карты.
+
+Срок указывается датой (2026-03-31), месяцем (2026-03 или 03/26 - действует
+до конца месяца) или временем RFC 3339. Значение never снимает срок.
+
+Записи с истекающим сроком выводит gophkeeper record list --expiring 30d,
+синхронизация предупреждает о них заранее (EXPIRY_WARNING, по умолчанию 30 дней).
+Срок хранится в открытых метаданных и синхронизируется на все устройства.`,
+	Example: `  gophkeeper record expire 12 2026-03-31
+  gophkeeper record expire 12 never`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		var expiresAt *time.Time
+		if args[1] != "never" {
+			at, err := record.ParseExpiry(args[1])
+			if err != nil {
+				return fmt.Errorf("неверный срок действия %q: ожидается дата 2026-03-31, месяц 2026-03 или 03/26", args[1])
+			}
+			expiresAt = &at
+		}
+
+		if err := app.SetRecordExpiry(cmd.Context(), recordID, expiresAt); err != nil {
+			return err
+		}
+
+		if expiresAt == nil {
+			fmt.Printf("✅ Срок действия записи %d снят\n", recordID)
+			return nil
+		}
+		fmt.Printf("✅ Запись %d: %s\n", recordID, expiryLabel(*expiresAt, time.Now()))
+		return nil
+	},
+}
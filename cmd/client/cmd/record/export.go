@@ -0,0 +1,75 @@
+// cmd/client/cmd/record/export.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportForce bool
+
+var ExportCmd = &cobra.Command{
+	Use:   "export [id] [path]",
+	Short: "Сохранить запись в файл",
+	Long: `Расшифровка записи и сохранение ее содержимого в файл с правами 0600.
+
+Поддерживаемые типы записей:
+- ssh-key - закрытый ключ, открытый ключ (<path>.pub) и сертификат (<path>-cert.pub)
+- file    - исходный файл`,
+	ValidArgsFunction: complete.RecordIDThenFile,
+	Args:              cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			return client.ErrMasterKeyLocked
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		path := args[1]
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("ошибка определения домашней директории: %w", err)
+			}
+			path = filepath.Join(home, path[2:])
+		}
+
+		if !exportForce {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", path)
+			}
+		}
+
+		files, err := app.ExportRecord(cmd.Context(), recordID, path)
+		if err != nil {
+			return fmt.Errorf("ошибка экспорта записи: %w", err)
+		}
+
+		fmt.Println("✅ Запись сохранена:")
+		for _, file := range files {
+			fmt.Printf("   %s\n", file)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	ExportCmd.Flags().BoolVarP(&exportForce, "force", "f", false, "перезаписать существующий файл")
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
 	"gophkeeper/internal/app/client"
 	"gophkeeper/internal/domain/record"
 	"os"
@@ -18,6 +19,8 @@ var (
 	outputFormat string
 	showPassword bool
 	decrypt      bool
+	refresh      bool
+	showNotes    bool
 )
 
 var GetCmd = &cobra.Command{
@@ -25,8 +28,10 @@ var GetCmd = &cobra.Command{
 	Short: "Просмотреть запись",
 	Long: `Просмотр содержимого записи по ID.
 	
-Вы можете указать формат вывода и решить, показывать ли чувствительные данные.`,
-	Args: cobra.ExactArgs(1),
+Вы можете указать формат вывода и решить, показывать ли чувствительные данные.
+С --notes выводятся пометки к записи (gophkeeper record annotate).`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
 		if app == nil {
@@ -39,7 +44,16 @@ var GetCmd = &cobra.Command{
 		}
 
 		// Получаем запись
-		rec, err := app.GetRecord(cmd.Context(), recordID)
+		var rec *client.LocalRecord
+		if refresh {
+			var updated bool
+			rec, updated, err = app.RefreshRecord(cmd.Context(), recordID)
+			if err == nil && updated {
+				fmt.Fprintln(os.Stderr, "🔄 Локальная копия обновлена с сервера")
+			}
+		} else {
+			rec, err = app.GetRecord(cmd.Context(), recordID)
+		}
 		if err != nil {
 			return fmt.Errorf("ошибка получения записи: %w", err)
 		}
@@ -48,26 +62,67 @@ var GetCmd = &cobra.Command{
 		var decryptedData interface{}
 		if decrypt {
 			if !app.IsMasterKeyUnlocked() {
-				return fmt.Errorf("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+				return client.ErrMasterKeyLocked
+			}
+			if outputFormat == "json" {
+				// JSON выводится как есть из защищенной памяти, которая
+				// затирается после вывода
+				payload, err := app.GetDecryptedPayload(cmd.Context(), recordID)
+				if err != nil {
+					return fmt.Errorf("ошибка расшифровки записи: %w", err)
+				}
+				defer payload.Close()
+				decryptedData = json.RawMessage(payload.Bytes())
+			} else {
+				decryptedData, err = app.GetDecryptedRecord(cmd.Context(), recordID)
+				if err != nil {
+					return fmt.Errorf("ошибка расшифровки записи: %w", err)
+				}
+			}
+			// Номер карты, CVV и PIN попадают в JSON и YAML только с --show-password
+			if rec.Type == record.RecTypeCard && !showPassword && outputFormat != "text" {
+				if decryptedData, err = maskCardData(decryptedData); err != nil {
+					return fmt.Errorf("ошибка расшифровки записи: %w", err)
+				}
 			}
-			decryptedData, err = app.GetDecryptedRecord(cmd.Context(), recordID)
+		}
+
+		// Пометки зашифрованы отдельно от данных записи
+		var annotations []client.RecordAnnotation
+		if showNotes {
+			if !app.IsMasterKeyUnlocked() {
+				return client.ErrMasterKeyLocked
+			}
+			annotations, err = app.RecordAnnotations(rec)
 			if err != nil {
-				return fmt.Errorf("ошибка расшифровки записи: %w", err)
+				return err
 			}
 		}
 
 		switch outputFormat {
 		case "json":
-			return printRecordJSON(rec, decryptedData, showPassword)
+			return printRecordJSON(rec, decryptedData, annotations)
 		case "yaml":
-			return printRecordYAML(rec, decryptedData, showPassword)
-		default:
-			return printRecordHuman(rec, decryptedData, showPassword)
+			return printRecordYAML(rec, decryptedData, annotations)
+		}
+
+		if err := printRecordHuman(rec, app.RecordFolderPath(rec), decryptedData, showPassword); err != nil {
+			return err
+		}
+		printAnnotations(rec, annotations)
+		// Сведения о вложениях зашифрованы, их список выводится вместе с данными
+		if decrypt && rec.ServerID > 0 {
+			attachments, err := app.ListAttachments(cmd.Context(), recordID)
+			if err != nil {
+				return fmt.Errorf("ошибка получения вложений: %w", err)
+			}
+			printAttachments(attachments)
 		}
+		return nil
 	},
 }
 
-func printRecordHuman(rec *client.LocalRecord, decryptedData interface{}, showPassword bool) error {
+func printRecordHuman(rec *client.LocalRecord, folderPath string, decryptedData interface{}, showPassword bool) error {
 	fmt.Printf("ID:          %d\n", rec.ID)
 	fmt.Printf("Server ID:   %d\n", rec.ServerID)
 	fmt.Printf("Тип:         %s\n", rec.Type)
@@ -80,10 +135,16 @@ func printRecordHuman(rec *client.LocalRecord, decryptedData interface{}, showPa
 			}
 		}
 	}
+	if folderPath != "" {
+		fmt.Printf("Папка:       📁 %s\n", folderPath)
+	}
 
 	fmt.Printf("Обновлено:   %s\n", rec.LastModified.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Версия:      %d\n", rec.Version)
 	fmt.Printf("Синхронизирована: %v\n", rec.Synced)
+	if record.IsLocked(rec.Meta) {
+		fmt.Printf("Защищена:    🔒 да (снять защиту: gophkeeper record unlock %d)\n", rec.ID)
+	}
 	fmt.Println()
 
 	// Если данные расшифрованы, показываем их
@@ -117,28 +178,73 @@ func printRecordHuman(rec *client.LocalRecord, decryptedData interface{}, showPa
 
 		case record.RecTypeCard:
 			fmt.Println("=== Данные карты ===")
+			if paymentSystem, ok := meta["payment_system"].(string); ok && paymentSystem != "" {
+				fmt.Printf("Система:     %s\n", paymentSystem)
+			}
 			if dataMap, ok := decryptedData.(map[string]interface{}); ok {
 				if cardNumber, ok := dataMap["card_number"].(string); ok {
 					if showPassword {
 						fmt.Printf("Номер карты: %s\n", cardNumber)
+					} else if masked := record.MaskCardNumber(cardNumber); masked != "" {
+						fmt.Printf("Номер карты: %s\n", masked)
 					} else {
-						fmt.Printf("Номер карты: ****%s\n", cardNumber[len(cardNumber)-4:])
+						fmt.Println("Номер карты: ••••")
 					}
 				}
 				if cardHolder, ok := dataMap["card_holder"].(string); ok {
 					fmt.Printf("Держатель:   %s\n", cardHolder)
 				}
+				month, _ := dataMap["expiry_month"].(string)
+				year, _ := dataMap["expiry_year"].(string)
+				if month != "" && year != "" {
+					fmt.Printf("Срок:        %s/%s\n", month, year)
+				}
 				if showPassword {
 					if cvv, ok := dataMap["cvv"].(string); ok {
 						fmt.Printf("CVV:         %s\n", cvv)
 					}
+					if pin, ok := dataMap["pin"].(string); ok && pin != "" {
+						fmt.Printf("PIN:         %s\n", pin)
+					}
+				} else {
+					fmt.Println("CVV:         *** (используйте --show-password)")
 				}
 			}
 
 		case record.RecTypeBinary:
 			fmt.Println("=== Файл ===")
 			fmt.Printf("Размер:      %d байт\n", len(rec.EncryptedData))
-			fmt.Println("Используйте команду 'export' для сохранения файла")
+			fmt.Println("Используйте команду 'record export' для сохранения файла")
+
+		case record.RecTypeOTP:
+			fmt.Println("=== Секрет TOTP ===")
+			if showPassword {
+				if dataMap, ok := decryptedData.(map[string]interface{}); ok {
+					if secret, ok := dataMap["secret"].(string); ok {
+						fmt.Printf("Секрет:      %s\n", secret)
+					}
+				}
+			} else {
+				fmt.Println("Секрет:      ******** (используйте --show-password)")
+			}
+			fmt.Printf("Используйте 'gophkeeper otp show %d' для получения кода\n", rec.ID)
+
+		case record.RecTypeSSHKey:
+			fmt.Println("=== SSH-ключ ===")
+			printSSHKeyMeta(meta)
+			if dataMap, ok := decryptedData.(map[string]interface{}); ok {
+				if publicKey, ok := dataMap["public_key"].(string); ok && publicKey != "" {
+					fmt.Printf("Открытый ключ: %s\n", publicKey)
+				}
+				if showPassword {
+					if privateKey, ok := dataMap["private_key"].(string); ok && privateKey != "" {
+						fmt.Println(privateKey)
+					}
+				} else {
+					fmt.Println("Закрытый ключ: ******** (используйте --show-password)")
+				}
+			}
+			fmt.Printf("Используйте 'gophkeeper record export %d <путь>' для сохранения ключа\n", rec.ID)
 		}
 	} else {
 		// Данные не расшифрованы
@@ -161,24 +267,73 @@ func printRecordHuman(rec *client.LocalRecord, decryptedData interface{}, showPa
 		case record.RecTypeBinary:
 			fmt.Println("=== Файл ===")
 			fmt.Printf("Размер:      %d байт\n", len(rec.EncryptedData))
-			fmt.Println("Используйте команду 'export' для сохранения файла")
+			fmt.Println("Используйте команду 'record export' для сохранения файла")
+
+		case record.RecTypeOTP:
+			fmt.Println("=== Секрет TOTP ===")
+			fmt.Println("(Данные зашифрованы)")
+			fmt.Printf("Используйте 'gophkeeper otp show %d' для получения кода\n", rec.ID)
+
+		case record.RecTypeSSHKey:
+			fmt.Println("=== SSH-ключ ===")
+			printSSHKeyMeta(meta)
+			fmt.Println("(Данные зашифрованы)")
+			fmt.Printf("Используйте 'gophkeeper record export %d <путь>' для сохранения ключа\n", rec.ID)
 		}
 	}
 
 	return nil
 }
 
-func printRecordJSON(rec *client.LocalRecord, decryptedData interface{}, _ bool) error {
+// printSSHKeyMeta выводит открытые сведения о ключе из метаданных
+func printSSHKeyMeta(meta map[string]interface{}) {
+	if keyType, ok := meta["key_type"].(string); ok && keyType != "" {
+		if bits, ok := meta["bits"].(float64); ok && bits > 0 {
+			fmt.Printf("Тип ключа:   %s (%d бит)\n", keyType, int(bits))
+		} else {
+			fmt.Printf("Тип ключа:   %s\n", keyType)
+		}
+	}
+	if fingerprint, ok := meta["fingerprint"].(string); ok && fingerprint != "" {
+		fmt.Printf("Отпечаток:   %s\n", fingerprint)
+	}
+	if hasCert, ok := meta["has_certificate"].(bool); ok && hasCert {
+		fmt.Println("Сертификат:  есть")
+	}
+}
+
+// printAnnotations выводит пометки к записи, а без --notes - только их число
+func printAnnotations(rec *client.LocalRecord, annotations []client.RecordAnnotation) {
+	if !showNotes {
+		if n := len(record.Annotations(rec.Meta)); n > 0 {
+			fmt.Printf("\n📝 Пометок: %d (используйте --notes)\n", n)
+		}
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("=== Пометки ===")
+	if len(annotations) == 0 {
+		fmt.Printf("Пометок нет. Добавить: gophkeeper record annotate %d \"текст\"\n", rec.ID)
+		return
+	}
+	for _, a := range annotations {
+		fmt.Printf("%s  %s\n", a.At.Local().Format("2006-01-02 15:04"), a.Text)
+	}
+}
+
+func printRecordJSON(rec *client.LocalRecord, decryptedData interface{}, annotations []client.RecordAnnotation) error {
 	output := struct {
-		ID            int             `json:"id"`
-		ServerID      int             `json:"server_id"`
-		Type          string          `json:"type"`
-		Meta          json.RawMessage `json:"meta"`
-		Version       int             `json:"version"`
-		LastModified  string          `json:"last_modified"`
-		CreatedAt     string          `json:"created_at"`
-		Synced        bool            `json:"synced"`
-		DecryptedData interface{}     `json:"decrypted_data,omitempty"`
+		ID            int                       `json:"id"`
+		ServerID      int                       `json:"server_id"`
+		Type          string                    `json:"type"`
+		Meta          json.RawMessage           `json:"meta"`
+		Version       int                       `json:"version"`
+		LastModified  string                    `json:"last_modified"`
+		CreatedAt     string                    `json:"created_at"`
+		Synced        bool                      `json:"synced"`
+		DecryptedData interface{}               `json:"decrypted_data,omitempty"`
+		Annotations   []client.RecordAnnotation `json:"annotations,omitempty"`
 	}{
 		ID:            rec.ID,
 		ServerID:      rec.ServerID,
@@ -189,6 +344,7 @@ func printRecordJSON(rec *client.LocalRecord, decryptedData interface{}, _ bool)
 		CreatedAt:     rec.CreatedAt.Format(time.RFC3339),
 		Synced:        rec.Synced,
 		DecryptedData: decryptedData,
+		Annotations:   annotations,
 	}
 
 	encoder := json.NewEncoder(os.Stdout)
@@ -196,12 +352,42 @@ func printRecordJSON(rec *client.LocalRecord, decryptedData interface{}, _ bool)
 	return encoder.Encode(output)
 }
 
-func printRecordYAML(rec *client.LocalRecord, decryptedData interface{}, showPassword bool) error {
-	return printRecordJSON(rec, decryptedData, showPassword)
+func printRecordYAML(rec *client.LocalRecord, decryptedData interface{}, annotations []client.RecordAnnotation) error {
+	return printRecordJSON(rec, decryptedData, annotations)
 }
 
 func init() {
 	GetCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "формат вывода (text, json, yaml)")
 	GetCmd.Flags().BoolVar(&showPassword, "show-password", false, "показывать пароли и чувствительные данные")
 	GetCmd.Flags().BoolVar(&decrypt, "decrypt", false, "расшифровать данные записи")
+	GetCmd.Flags().BoolVar(&showNotes, "notes", false, "показать пометки к записи")
+	GetCmd.Flags().BoolVar(&refresh, "refresh", false, "сверить версию с сервером и загрузить запись, если локальная копия устарела")
+}
+
+// maskCardData скрывает в расшифрованных данных карты номер (кроме последних
+// 4 цифр), CVV и PIN так же, как record.CardData.Masked
+func maskCardData(data interface{}) (interface{}, error) {
+	var fields map[string]interface{}
+	switch d := data.(type) {
+	case json.RawMessage:
+		if err := json.Unmarshal(d, &fields); err != nil {
+			return nil, err
+		}
+	case map[string]interface{}:
+		fields = d
+	default:
+		return data, nil
+	}
+
+	var card record.CardData
+	card.CardNumber, _ = fields["card_number"].(string)
+	card.CVV, _ = fields["cvv"].(string)
+	card.PIN, _ = fields["pin"].(string)
+	masked := card.Masked()
+	for key, value := range map[string]string{"card_number": masked.CardNumber, "cvv": masked.CVV, "pin": masked.PIN} {
+		if _, ok := fields[key]; ok {
+			fields[key] = value
+		}
+	}
+	return fields, nil
 }
@@ -0,0 +1,113 @@
+// cmd/client/cmd/record/history.go
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyOutput  string
+	restoreVersion int
+)
+
+var HistoryCmd = &cobra.Command{
+	Use:   "history [id]",
+	Short: "История версий записи",
+	Long: `Показывает версии записи, сохраненные на сервере.
+
+Прежняя версия сохраняется при каждом изменении записи. Вернуть ее
+можно командой gophkeeper record restore.`,
+	Example: `  gophkeeper record history 12
+  gophkeeper record history 12 -o json`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		history, err := app.RecordHistory(cmd.Context(), recordID)
+		if err != nil {
+			return fmt.Errorf("ошибка получения истории: %w", err)
+		}
+
+		if historyOutput == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(history)
+		}
+
+		fmt.Printf("%-8s %-20s %s\n", "Версия", "Дата", "Название")
+		for _, v := range history {
+			line := fmt.Sprintf("%-8d %-20s %s", v.Version, v.CreatedAt.Local().Format("2006-01-02 15:04"), v.Title)
+			if v.Current {
+				line += "  ← текущая"
+			}
+			fmt.Println(line)
+		}
+		if len(history) == 1 {
+			fmt.Println()
+			fmt.Println("Прежних версий нет")
+		}
+		return nil
+	},
+}
+
+var RestoreCmd = &cobra.Command{
+	Use:   "restore [id]",
+	Short: "Восстановить версию записи",
+	Long: `Восстанавливает содержимое прежней версии записи.
+
+История не откатывается: содержимое выбранной версии сохраняется как
+новая версия, а текущая попадает в историю и тоже может быть восстановлена.`,
+	Example: `  gophkeeper record history 12
+  gophkeeper record restore 12 --version 3`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+		if restoreVersion <= 0 {
+			return fmt.Errorf("укажите версию: --version N")
+		}
+
+		if !app.IsMasterKeyUnlocked() {
+			return client.ErrMasterKeyLocked
+		}
+
+		newVersion, err := app.RestoreRecordVersion(cmd.Context(), recordID, restoreVersion)
+		if err != nil {
+			return fmt.Errorf("ошибка восстановления: %w", err)
+		}
+
+		fmt.Printf("✅ Версия %d восстановлена как версия %d\n", restoreVersion, newVersion)
+		return nil
+	},
+}
+
+func init() {
+	HistoryCmd.Flags().StringVarP(&historyOutput, "output", "o", "text", "формат вывода (text, json)")
+	RestoreCmd.Flags().IntVar(&restoreVersion, "version", 0, "номер восстанавливаемой версии")
+	_ = RestoreCmd.MarkFlagRequired("version")
+}
@@ -0,0 +1,86 @@
+// cmd/client/cmd/record/icon.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	iconOutput  string
+	iconRefresh bool
+	iconAll     bool
+)
+
+var IconCmd = &cobra.Command{
+	Use:   "icon [id]",
+	Short: "Значок сайта логина",
+	Long: `Загрузка значка сайта (favicon) для записи-логина в локальный кэш.
+
+Загрузка выключена по умолчанию: значок запрашивается напрямую с сайта,
+и сайт узнает, что у вас есть учетная запись. Включается переменной
+окружения FETCH_ICONS=true. Кэш хранится зашифрованным в каталоге конфигурации.
+
+Флаг --all загружает значки для всех логинов, которых еще нет в кэше.`,
+	ValidArgsFunction: complete.RecordID,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if iconAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if iconAll {
+			fetched, err := app.PrefetchIcons(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("ошибка загрузки значков: %w", err)
+			}
+			fmt.Printf("✅ Загружено значков: %d\n", fetched)
+			return nil
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		icon, err := app.RecordIcon(cmd.Context(), recordID, iconRefresh)
+		if err != nil {
+			return fmt.Errorf("ошибка получения значка: %w", err)
+		}
+
+		if len(icon.Data) == 0 {
+			fmt.Printf("ℹ️  У сайта %s нет значка\n", icon.Host)
+			return nil
+		}
+
+		if iconOutput != "" {
+			if err := os.WriteFile(iconOutput, icon.Data, 0600); err != nil {
+				return fmt.Errorf("ошибка записи файла: %w", err)
+			}
+			fmt.Printf("✅ Значок %s сохранен в %s\n", icon.Host, iconOutput)
+			return nil
+		}
+
+		fmt.Printf("🖼  %s: %s, %d байт, загружен %s\n",
+			icon.Host, icon.ContentType, len(icon.Data), icon.FetchedAt.Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+func init() {
+	IconCmd.Flags().StringVarP(&iconOutput, "output", "o", "", "сохранить значок в файл")
+	IconCmd.Flags().BoolVar(&iconRefresh, "refresh", false, "загрузить заново, минуя кэш")
+	IconCmd.Flags().BoolVar(&iconAll, "all", false, "загрузить значки всех логинов")
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
 	"gophkeeper/internal/app/client"
 	"gophkeeper/internal/domain/record"
 	"os"
+	"sort"
 	"text/tabwriter"
 	"time"
 
@@ -20,14 +22,38 @@ var (
 	showDeleted bool
 	limit       int
 	offset      int
+
+	listSearch   string
+	listTags     []string
+	listCategory string
+	listResource string
+	listFolder   string
+	listExpiring string
+	listAll      bool
 )
 
 var ListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Список записей",
-	Long: `Просмотр списка всех записей с возможностью фильтрации по типу.
-	
-Поддерживается пагинация через флаги --limit и --offset.`,
+	Long: `Просмотр списка всех записей с возможностью фильтрации по типу и метаданным.
+
+Флаг --search ищет подстроку в названии и ресурсе записи без учета регистра,
+--tag оставляет записи со всеми указанными тегами (флаг можно повторять),
+--category и --resource фильтруют по категории и ресурсу логина,
+--folder выводит записи папки и вложенных в нее (gophkeeper folder list),
+--expiring - записи, срок действия которых истекает в указанный срок (30d,
+72h), включая уже истекшие, начиная с ближайшего срока.
+
+Если задан контекст (gophkeeper use work/aws), выводятся только записи его
+категории и вложенных в нее; флаг --all выводит записи без учета контекста.
+
+Поддерживается пагинация через флаги --limit и --offset.
+
+Примеры:
+  gophkeeper record list --tag work --search github
+  gophkeeper record list --type login --resource example.com
+  gophkeeper record list --folder Work/Cloud
+  gophkeeper record list --expiring 30d`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
 		if app == nil {
@@ -39,24 +65,50 @@ var ListCmd = &cobra.Command{
 			ShowDeleted: showDeleted,
 			Limit:       limit,
 			Offset:      offset,
+			Search:      listSearch,
+			Tags:        listTags,
+			Category:    listCategory,
+			Resource:    listResource,
+			Folder:      listFolder,
+		}
+		if !listAll {
+			filter.Workspace = app.Workspace()
+		}
+		if listExpiring != "" {
+			within, err := parseAge(listExpiring)
+			if err != nil {
+				return fmt.Errorf("неверный срок --expiring: %w", err)
+			}
+			filter.ExpiresBefore = time.Now().Add(within)
 		}
 
 		records, err := app.ListRecords(cmd.Context(), filter)
 		if err != nil {
 			return fmt.Errorf("ошибка получения списка записей: %w", err)
 		}
-
-		// Выводим результат
-		switch listFormat {
-		case "json":
-			return printRecordsJSON(records)
-		case "table":
-			return printRecordsTable(records)
-		case "csv":
-			return printRecordsCSV(records)
-		default:
-			return printRecordsSimple(records)
+		if listExpiring != "" {
+			sortByExpiry(records)
 		}
+
+		output.UseFlag(listFormat)
+		return output.Render(output.Result{
+			Value: output.Records(records),
+			IDs:   output.RecordIDs(records),
+			Text: func() error {
+				if filter.Workspace != "" && (listFormat == "simple" || listFormat == "table") {
+					fmt.Printf("📁 Контекст: %s (все записи: --all)\n\n", filter.Workspace)
+				}
+
+				switch listFormat {
+				case "table":
+					return printRecordsTable(records)
+				case "csv":
+					return printRecordsCSV(records)
+				default:
+					return printRecordsSimple(records)
+				}
+			},
+		})
 	},
 }
 
@@ -74,17 +126,18 @@ func printRecordsSimple(records []*client.LocalRecord) error {
 			status = "✗"
 		}
 
-		var meta map[string]interface{}
-		title := "Без названия"
-		if len(rec.Meta) > 0 {
-			if err := json.Unmarshal(rec.Meta, &meta); err == nil {
-				if t, ok := meta["title"].(string); ok {
-					title = t
-				}
-			}
+		title, details := recordTitle(rec)
+		if record.IsLocked(rec.Meta) {
+			title += " 🔒"
 		}
 
 		fmt.Printf("%d. [%s] %s (%s)\n", i+1, status, title, rec.Type)
+		if details != "" {
+			fmt.Printf("   %s\n", details)
+		}
+		if expiresAt, ok := record.ExpiresAt(rec.Meta); ok {
+			fmt.Printf("   %s\n", expiryLabel(expiresAt, time.Now()))
+		}
 		fmt.Printf("   ID: %d | Server ID: %d | Создано: %s\n",
 			rec.ID,
 			rec.ServerID,
@@ -102,8 +155,8 @@ func printRecordsTable(records []*client.LocalRecord) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintf(w, "ID\tServer ID\tТип\tНазвание\tСтатус\tСоздано\tОбновлено\t\n")
-	_, _ = fmt.Fprintf(w, "---\t---\t---\t---\t---\t---\t---\t\n")
+	_, _ = fmt.Fprintf(w, "ID\tServer ID\tТип\tНазвание\tДетали\tСтатус\tСоздано\tОбновлено\t\n")
+	_, _ = fmt.Fprintf(w, "---\t---\t---\t---\t---\t---\t---\t---\t\n")
 
 	for _, rec := range records {
 		status := "Активна"
@@ -111,21 +164,14 @@ func printRecordsTable(records []*client.LocalRecord) error {
 			status = "Удалена"
 		}
 
-		var meta map[string]interface{}
-		title := "Без названия"
-		if len(rec.Meta) > 0 {
-			if err := json.Unmarshal(rec.Meta, &meta); err == nil {
-				if t, ok := meta["title"].(string); ok {
-					title = t
-				}
-			}
-		}
+		title, details := recordTitle(rec)
 
-		_, _ = fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t\n",
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
 			rec.ID,
 			rec.ServerID,
 			string(rec.Type),
 			truncate(title, 30),
+			truncate(details, 30),
 			status,
 			rec.CreatedAt.Format("2006-01-02"),
 			rec.LastModified.Format("2006-01-02"),
@@ -137,12 +183,6 @@ func printRecordsTable(records []*client.LocalRecord) error {
 	return nil
 }
 
-func printRecordsJSON(records []*client.LocalRecord) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(records)
-}
-
 func printRecordsCSV(records []*client.LocalRecord) error {
 	fmt.Println("ID,ServerID,Type,Title,Status,CreatedAt,UpdatedAt")
 
@@ -152,15 +192,7 @@ func printRecordsCSV(records []*client.LocalRecord) error {
 			status = "deleted"
 		}
 
-		var meta map[string]interface{}
-		title := "Без названия"
-		if len(rec.Meta) > 0 {
-			if err := json.Unmarshal(rec.Meta, &meta); err == nil {
-				if t, ok := meta["title"].(string); ok {
-					title = t
-				}
-			}
-		}
+		title, _ := recordTitle(rec)
 
 		fmt.Printf("%d,%d,%s,%q,%s,%s,%s\n",
 			rec.ID,
@@ -176,11 +208,53 @@ func printRecordsCSV(records []*client.LocalRecord) error {
 	return nil
 }
 
+// recordTitle возвращает название и детали записи из превью, сохраненного
+// в локальной базе. Метаданные разбираются, только если превью нет.
+func recordTitle(rec *client.LocalRecord) (string, string) {
+	if rec.Preview != nil {
+		return rec.Preview.Title, rec.Preview.Summary()
+	}
+
+	title := "Без названия"
+	var meta map[string]interface{}
+	if len(rec.Meta) > 0 {
+		if err := json.Unmarshal(rec.Meta, &meta); err == nil {
+			if t, ok := meta["title"].(string); ok && t != "" {
+				title = t
+			}
+		}
+	}
+	return title, ""
+}
+
+// sortByExpiry упорядочивает записи по сроку действия, начиная с ближайшего
+func sortByExpiry(records []*client.LocalRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, _ := record.ExpiresAt(records[i].Meta)
+		b, _ := record.ExpiresAt(records[j].Meta)
+		return a.Before(b)
+	})
+}
+
+// expiryLabel описывает срок действия записи относительно now
+func expiryLabel(expiresAt, now time.Time) string {
+	date := expiresAt.Local().Format("2006-01-02")
+	if !expiresAt.After(now) {
+		return "⛔ Срок истек " + date
+	}
+	days := int(expiresAt.Sub(now).Hours() / 24)
+	if days < 30 {
+		return fmt.Sprintf("⏳ Истекает %s (через %d дн.)", date, days)
+	}
+	return "Действует до " + date
+}
+
 func truncate(s string, length int) string {
-	if len(s) <= length {
+	r := []rune(s)
+	if len(r) <= length {
 		return s
 	}
-	return s[:length-3] + "..."
+	return string(r[:length-3]) + "..."
 }
 
 func init() {
@@ -189,4 +263,14 @@ func init() {
 	ListCmd.Flags().BoolVar(&showDeleted, "deleted", false, "показывать удаленные записи")
 	ListCmd.Flags().IntVar(&limit, "limit", 50, "ограничение количества записей")
 	ListCmd.Flags().IntVar(&offset, "offset", 0, "смещение для пагинации")
+	ListCmd.Flags().StringVarP(&listSearch, "search", "s", "", "поиск по названию и ресурсу")
+	ListCmd.Flags().StringSliceVar(&listTags, "tag", nil, "фильтр по тегу (можно указать несколько)")
+	ListCmd.Flags().StringVar(&listCategory, "category", "", "фильтр по категории")
+	ListCmd.Flags().StringVar(&listResource, "resource", "", "фильтр по ресурсу логина")
+	ListCmd.Flags().StringVar(&listFolder, "folder", "", "фильтр по папке, включая вложенные (Work/Cloud)")
+	ListCmd.Flags().StringVar(&listExpiring, "expiring", "", "записи, срок действия которых истекает в указанный срок (30d, 72h)")
+	ListCmd.Flags().BoolVar(&listAll, "all", false, "не учитывать текущий контекст (gophkeeper use)")
+
+	_ = ListCmd.RegisterFlagCompletionFunc("format",
+		cobra.FixedCompletions([]string{"simple", "table", "json", "csv"}, cobra.ShellCompDirectiveNoFileComp))
 }
@@ -0,0 +1,79 @@
+// cmd/client/cmd/record/lock.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var LockCmd = &cobra.Command{
+	Use:   "lock [id]",
+	Short: "Защитить запись от изменений",
+	Long: `Защищает запись от случайного изменения и удаления.
+
+Подходит для критичных записей: кодов восстановления, root-учетных данных.
+Защищенную запись нельзя изменить, восстановить из истории или удалить,
+пока защиту не снимут командой gophkeeper record unlock с вводом мастер-пароля.
+Защита синхронизируется на все устройства.`,
+	Example:           `  gophkeeper record lock 12`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		if err := app.LockRecord(cmd.Context(), recordID); err != nil {
+			return err
+		}
+
+		fmt.Printf("🔒 Запись %d защищена от изменений\n", recordID)
+		fmt.Printf("   Снять защиту: gophkeeper record unlock %d\n", recordID)
+		return nil
+	},
+}
+
+var UnlockCmd = &cobra.Command{
+	Use:   "unlock [id]",
+	Short: "Снять защиту записи",
+	Long: `Снимает защиту от изменений с записи. Требует повторного ввода мастер-пароля,
+даже если мастер-ключ уже разблокирован.`,
+	Example:           `  gophkeeper record unlock 12`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		password, err := prompt.Password("Мастер-пароль: ")
+		if err != nil {
+			return fmt.Errorf("ошибка чтения пароля: %w", err)
+		}
+
+		if err := app.UnlockRecord(cmd.Context(), recordID, password); err != nil {
+			return err
+		}
+
+		fmt.Printf("🔓 Защита с записи %d снята\n", recordID)
+		return nil
+	},
+}
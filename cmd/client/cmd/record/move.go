@@ -0,0 +1,42 @@
+// cmd/client/cmd/record/move.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/internal/app/client"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var MoveCmd = &cobra.Command{
+	Use:   "move [id] [folder]",
+	Short: "Переместить запись в папку",
+	Long: `Переносит запись в папку по ее пути (gophkeeper folder list).
+Путь / убирает запись из папок. Папка записи синхронизируется
+на все устройства вместе с остальными метаданными.`,
+	Example: `  gophkeeper record move 12 Work/Cloud/AWS
+  gophkeeper record move 12 /`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		if err := app.MoveRecord(cmd.Context(), recordID, args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("📁 Запись %d перемещена в %s\n", recordID, args[1])
+		return nil
+	},
+}
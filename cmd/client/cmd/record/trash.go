@@ -0,0 +1,230 @@
+// cmd/client/cmd/record/trash.go
+package record
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/complete"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	trashOutput     string
+	purgeOlderThan  string
+	purgeYes        bool
+	deletePermanent bool
+)
+
+var DeleteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Short: "Удалить запись",
+	Long: `Перемещает запись в корзину.
+
+Запись из корзины можно вернуть командой gophkeeper record trash restore.
+С флагом --permanent запись удаляется окончательно, минуя корзину.`,
+	Example: `  gophkeeper record delete 12
+  gophkeeper record delete 12 --permanent`,
+	ValidArgsFunction: complete.RecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		if err := app.DeleteRecord(cmd.Context(), recordID, deletePermanent); err != nil {
+			return err
+		}
+
+		if deletePermanent {
+			fmt.Printf("🗑️  Запись %d удалена окончательно\n", recordID)
+		} else {
+			fmt.Printf("🗑️  Запись %d перемещена в корзину\n", recordID)
+			fmt.Printf("   Восстановить: gophkeeper record trash restore %d\n", recordID)
+		}
+		return nil
+	},
+}
+
+var TrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Корзина удаленных записей",
+	Long: `Просмотр, восстановление и очистка удаленных записей.
+
+Удаленные записи хранятся в корзине, пока их не удалят окончательно.
+Сервер сам очищает корзину от записей старше срока хранения (по умолчанию 30 дней).`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Записи в корзине",
+	Example: `  gophkeeper record trash list
+  gophkeeper record trash list -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		records, err := app.ListTrash(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения корзины: %w", err)
+		}
+
+		output.UseFlag(trashOutput)
+		return output.Render(output.Result{
+			Value: output.Records(records),
+			IDs:   output.RecordIDs(records),
+			Text:  func() error { return printTrash(records) },
+		})
+	},
+}
+
+func printTrash(records []*client.LocalRecord) error {
+	if len(records) == 0 {
+		fmt.Println("Корзина пуста")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "ID\tТип\tНазвание\tУдалена\t\n")
+	for _, rec := range records {
+		title, _ := recordTitle(rec)
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t\n",
+			rec.ID,
+			string(rec.Type),
+			truncate(title, 40),
+			rec.DeletedAt.Local().Format("2006-01-02 15:04"),
+		)
+	}
+	return w.Flush()
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:               "restore [id]",
+	Short:             "Восстановить запись из корзины",
+	Example:           `  gophkeeper record trash restore 12`,
+	ValidArgsFunction: complete.TrashedRecordID,
+	Args:              cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		recordID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("неверный ID записи: %w", err)
+		}
+
+		rec, err := app.RestoreFromTrash(cmd.Context(), recordID)
+		if err != nil {
+			return fmt.Errorf("ошибка восстановления: %w", err)
+		}
+
+		title, _ := recordTitle(rec)
+		fmt.Printf("♻️  Запись %d восстановлена: %s\n", rec.ID, title)
+		if !rec.Synced {
+			fmt.Println("   Изменения будут отправлены на сервер при следующей синхронизации")
+		}
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Очистить корзину",
+	Long: `Окончательно удаляет записи из корзины локально и на сервере.
+
+Без --older-than корзина очищается полностью. Срок задается в днях (30d)
+или в формате Go duration (12h, 90m).`,
+	Example: `  gophkeeper record trash purge
+  gophkeeper record trash purge --older-than 30d`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		olderThan, err := parseAge(purgeOlderThan)
+		if err != nil {
+			return fmt.Errorf("неверное значение --older-than: %w", err)
+		}
+
+		if !purgeYes {
+			question := "Удалить окончательно все записи из корзины?"
+			if olderThan != 0 {
+				question = fmt.Sprintf("Удалить окончательно записи, удаленные более %s назад?", purgeOlderThan)
+			}
+			ok, err := prompt.Confirm(question)
+			if err != nil {
+				return fmt.Errorf("%w; подтвердите удаление флагом --yes", err)
+			}
+			if !ok {
+				fmt.Println("Отменено")
+				return nil
+			}
+		}
+
+		result, err := app.PurgeTrash(cmd.Context(), olderThan)
+		if err != nil {
+			return fmt.Errorf("ошибка очистки корзины: %w", err)
+		}
+
+		fmt.Printf("🧹 Удалено записей: локально %d, на сервере %d\n", result.Local, result.Server)
+		return nil
+	},
+}
+
+// parseAge разбирает срок вида 30d или Go duration
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("ожидается число дней, например 30d")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("срок не может быть отрицательным")
+	}
+	return d, nil
+}
+
+func init() {
+	DeleteCmd.Flags().BoolVar(&deletePermanent, "permanent", false, "удалить окончательно, минуя корзину")
+
+	trashListCmd.Flags().StringVarP(&trashOutput, "output", "o", "text", "формат вывода (text, json)")
+	trashPurgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "", "удалять записи старше срока (30d, 12h)")
+	trashPurgeCmd.Flags().BoolVarP(&purgeYes, "yes", "y", false, "не запрашивать подтверждение")
+
+	TrashCmd.AddCommand(trashListCmd)
+	TrashCmd.AddCommand(trashRestoreCmd)
+	TrashCmd.AddCommand(trashPurgeCmd)
+}
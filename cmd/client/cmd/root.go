@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"io"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"golang.org/x/exp/slog"
 
@@ -14,17 +20,19 @@ import (
 	"gophkeeper/internal/utils/logger"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile    string
-	cfg        *config.Config
-	log        *slog.Logger
-	app        *client.App
-	debug      bool
-	jsonOutput bool
-	serverURL  string
+	cfgFile        string
+	profileName    string
+	cfg            *config.Config
+	log            *slog.Logger
+	app            *client.App
+	debug          bool
+	jsonOutput     bool
+	serverURL      string
+	nonInteractive bool
+	quiet          bool
 )
 
 var rootCmd = &cobra.Command{
@@ -35,32 +43,106 @@ var rootCmd = &cobra.Command{
 	
 Все данные шифруются на стороне клиента с использованием мастер-ключа
 и синхронизируются с сервером.`,
+	Version:           client.Version,
 	PersistentPreRunE: setupApp,
 	SilenceUsage:      true,
 	SilenceErrors:     true,
 }
 
+// backgroundWait - сколько после завершения команды ждать фоновые задачи
+// клиента, например синхронизацию, запущенную командой record list
+const backgroundWait = 10 * time.Second
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
-		os.Exit(1)
+	// Ctrl-C отменяет контекст команды: запросы к серверу, синхронизация и
+	// резервное копирование останавливаются, не оставляя данные наполовину записанными
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// Повторный Ctrl-C завершает процесс сразу, в том числе во время ввода пароля
+		signal.Stop(signals)
+		cancel()
+		fmt.Fprintln(os.Stderr, "\n⏳ Прерывание... нажмите Ctrl-C еще раз для немедленного выхода")
+	}()
+
+	markUsageErrors(rootCmd)
+	err := rootCmd.ExecuteContext(ctx)
+	if app != nil {
+		closeCtx, cancel := context.WithTimeout(ctx, backgroundWait)
+		app.Close(closeCtx)
+		cancel()
 	}
+
+	if err != nil {
+		// Команда gophkeeper run уже вывела свои ошибки сама
+		if code, ok := childExitCode(err); ok {
+			os.Exit(code)
+		}
+		if output.Current() != output.JSON {
+			if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+				fmt.Fprintln(os.Stderr, "⛔ Операция прервана")
+				os.Exit(exitInterrupted)
+			}
+			var upgradeErr *client.UpgradeRequiredError
+			if errors.As(err, &upgradeErr) {
+				printUpgradePrompt(upgradeErr)
+				os.Exit(exitUpgrade)
+			}
+		}
+		code := exitCode(err)
+		output.PrintError(err, code)
+		os.Exit(code)
+	}
+}
+
+// printUpgradePrompt предлагает обновить клиент, который сервер больше не принимает
+func printUpgradePrompt(err *client.UpgradeRequiredError) {
+	fmt.Fprintf(os.Stderr, "⬆️  Требуется обновление gophkeeper: установлена версия %s", err.ClientVersion)
+	if err.MinVersion != "" {
+		fmt.Fprintf(os.Stderr, ", сервер принимает %s и новее", err.MinVersion)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "   Старые версии отключены, чтобы не повредить данные при синхронизации.")
+	fmt.Fprintln(os.Stderr, "   Установите новую версию клиента и повторите команду.")
 }
 
 func setupApp(cmd *cobra.Command, _ []string) error {
+	prompt.SetNonInteractive(nonInteractive)
+	if err := output.SetMode(jsonOutput, quiet); err != nil {
+		return &usageError{err: err}
+	}
+
+	// Скрипт автодополнения генерируется без конфигурации и локального хранилища
+	if isCompletionCmd(cmd) {
+		return nil
+	}
+
 	var err error
-	cfg, err = loadConfig()
+	cfg, err = loadConfig(cmd)
 	if err != nil {
 		return fmt.Errorf("ошибка загрузки конфигурации: %w", err)
 	}
 
-	// Переопределяем настройки из флагов командной строки
-	if serverURL != "" {
-		cfg.ServerAddress = serverURL
+	// Настраиваем логгер. При автодополнении stdout читает оболочка,
+	// поэтому журнал отключается
+	completing := isCompletionRequest(cmd)
+	if !completing {
+		for _, warning := range cfg.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  %s\n", warning)
+		}
+	}
+	if completing {
+		log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	} else if output.Current() != output.Text || isProtocolCmd(cmd) {
+		// stdout занят JSON, списком ID для скриптов или ответом другой программе
+		log = logger.NewTo(cfg.Env, os.Stderr)
+	} else {
+		log = logger.New(cfg.Env)
 	}
-
-	// Настраиваем логгер
-	log = logger.New(cfg.Env)
 
 	// Создаем приложение
 	app, err = client.New(cfg, log)
@@ -72,39 +154,116 @@ func setupApp(cmd *cobra.Command, _ []string) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+
+	// Уведомления об обновлении покажет следующая команда пользователя,
+	// если stdout этой читает другая программа
+	if !completing && !isProtocolCmd(cmd) {
+		showUpgradeNotices(ctx, app)
+	}
+
+	// doctor сам выводит найденные проблемы
+	if cmd.Name() != "doctor" && !completing {
+		warnExposedFiles(app)
+	}
 	cmd.SetContext(context.WithValue(ctx, clientctx.ClientAppKey, app))
 
 	return nil
 }
 
-func loadConfig() (*config.Config, error) {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Ищем конфиг в стандартных местах
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
+// isCompletionCmd сообщает, что команда печатает скрипт автодополнения
+func isCompletionCmd(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Name() == "completion" {
+			return true
 		}
+	}
+	return false
+}
+
+// isProtocolCmd сообщает, что stdout команды читает другая программа: браузер
+// (native messaging), git или ssh
+func isProtocolCmd(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "git-credential", "askpass":
+		return true
+	}
+	return cmd.Name() == "host" && cmd.Parent() != nil && cmd.Parent().Name() == "browser"
+}
+
+// isCompletionRequest сообщает, что оболочка запрашивает варианты автодополнения
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
 
-		configDir := filepath.Join(home, ".gophkeeper")
-		viper.AddConfigPath(configDir)
-		viper.AddConfigPath(".")
-		viper.SetConfigName("config")
-		viper.SetConfigType("yaml")
+// showUpgradeNotices выполняет задачи обновления клиента и выводит уведомления о нем
+func showUpgradeNotices(ctx context.Context, app *client.App) {
+	report, err := app.CheckUpgrade(ctx)
+	if err != nil {
+		log.Warn("Не удалось выполнить задачи обновления", "error", err)
+	}
+	if report == nil || len(report.Notices) == 0 {
+		return
 	}
 
-	viper.AutomaticEnv()
+	if report.From != "" {
+		fmt.Fprintf(os.Stderr, "⬆️  gophkeeper обновлен: %s → %s\n", report.From, report.To)
+	} else {
+		fmt.Fprintf(os.Stderr, "⬆️  gophkeeper обновлен до %s\n", report.To)
+	}
+	for _, notice := range report.Notices {
+		if notice.Error != "" {
+			fmt.Fprintf(os.Stderr, "   ⚠️  %s: %s (повторится при следующем запуске)\n", notice.Message, notice.Error)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "   • %s\n", notice.Message)
+	}
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, err
+// warnExposedFiles предупреждает о файлах с секретами, доступных другим пользователям
+func warnExposedFiles(app *client.App) {
+	exposed := 0
+	for _, issue := range app.CheckPermissions() {
+		if !issue.Exposed {
+			continue
 		}
-		// Конфиг не найден, используем значения по умолчанию
+		exposed++
+		fmt.Fprintf(os.Stderr, "🚨 Небезопасные права доступа: %s (%s)\n", issue.Path, issue.Problem)
+	}
+	if exposed > 0 {
+		fmt.Fprintln(os.Stderr, "   Исправьте командой: gophkeeper doctor --fix")
+	}
+}
+
+// loadConfig собирает конфигурацию из слоев: флаги --config, --profile
+// и --server важнее файла и переменных окружения
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	opts := config.Options{File: cfgFile, Profile: profileName}
+	if serverURL != "" {
+		opts.Flags = map[string]string{"server_address": serverURL}
+	}
+
+	c, err := config.Load(opts)
+	if errors.Is(err, config.ErrProfileNotFound) && managesProfiles(cmd) {
+		opts.IgnoreProfile = true
+		c, err = config.Load(opts)
 	}
+	return c, err
+}
 
-	// Загружаем конфигурацию через стандартный метод
-	return config.MustLoad(), nil
+// managesProfiles сообщает, что команда работает без выбранного профиля:
+// config set --profile создает новый профиль, команды profile исправляют
+// ссылку на удаленный
+func managesProfiles(cmd *cobra.Command) bool {
+	if cmd.Parent() == nil {
+		return false
+	}
+	switch cmd.Parent().Name() {
+	case "profile":
+		return true
+	case "config":
+		return cmd.Name() == "set"
+	}
+	return cmd.Name() == "profile"
 }
 
 func init() {
@@ -113,8 +272,13 @@ func init() {
 	// Глобальные флаги
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "конфигурационный файл")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "включить отладочный режим")
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "вывод в формате JSON")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "вывод в формате JSON со стабильной схемой")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "выводить только ID объектов, по одному в строке")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "",
+		"профиль из раздела profiles файла конфигурации (по умолчанию GOPHKEEPER_PROFILE или ключ profile)")
 	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "URL сервера GophKeeper")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false,
+		"не запрашивать ввод: команда завершается ошибкой, если значение не передано флагом")
 
 	// Команды будут добавлены в init() соответствующих файлов
 }
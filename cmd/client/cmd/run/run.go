@@ -0,0 +1,78 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var envRefs []string
+
+var RunCmd = &cobra.Command{
+	Use:   "run [--env KEY=gk://запись/поле]... -- <команда> [аргументы]",
+	Short: "Запустить команду с секретами в переменных окружения",
+	Long: `Запускает команду, подставляя в ее окружение расшифрованные секреты.
+
+Значение переменной вида gk://<запись>/<поле> заменяется полем записи
+в момент запуска. Запись задается ID или точным названием, поле - ключом
+данных записи: username, password, content, card_number, private_key и т.д.
+Для записей TOTP поле code дает текущий код. Ссылки берутся из окружения
+gophkeeper и из флагов --env, поэтому в профиле оболочки хранятся только
+ссылки, а сами секреты видит лишь запущенный процесс.
+
+Записи читаются из локального хранилища, мастер-ключ должен быть разблокирован.
+Если хотя бы одна ссылка не разрешилась, команда не запускается.
+Код завершения gophkeeper run совпадает с кодом завершения команды.`,
+	Example: `  export DB_PASSWORD=gk://Postgres/password
+  gophkeeper run -- psql -U app
+
+  gophkeeper run -e AWS_SECRET_ACCESS_KEY=gk://42/password -- terraform apply
+  gophkeeper run -e GITHUB_TOKEN="gk://GitHub%20CI/content" -- ./deploy.sh`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		env := os.Environ()
+		for _, kv := range envRefs {
+			if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+				return fmt.Errorf("неверный формат --env %q, ожидается KEY=VALUE", kv)
+			}
+			env = append(env, kv)
+		}
+
+		env, _, err := app.ResolveEnv(cmd.Context(), env)
+		if err != nil {
+			return fmt.Errorf("ошибка подстановки секретов: %w", err)
+		}
+
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			return fmt.Errorf("команда не найдена: %w", err)
+		}
+
+		// Команда не привязана к контексту: Ctrl-C терминал доставляет ей
+		// самой, и она завершается так, как умеет, а не принудительно
+		child := exec.Command(path, args[1:]...)
+		child.Env = env
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+
+		return child.Run()
+	},
+}
+
+func init() {
+	// Флаги после имени команды относятся к ней, а не к gophkeeper
+	RunCmd.Flags().SetInterspersed(false)
+	RunCmd.Flags().StringArrayVarP(&envRefs, "env", "e", nil, "переменная окружения KEY=VALUE для команды, значение может быть ссылкой gk://")
+}
@@ -0,0 +1,111 @@
+package settings
+
+import (
+	"fmt"
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var SettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Настройки пользователя",
+	Long: `Просмотр и изменение настроек пользователя, хранящихся на сервере.
+
+Поддерживаемые ключи:
+- conflict_strategy - стратегия разрешения конфликтов (client, server, newer, merge, manual)
+- notifications     - уведомления (true, false)
+- retention_days    - срок хранения удаленных записей в днях
+
+Настройки применяются на всех устройствах при следующей синхронизации.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		values, err := app.GetSettings(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("ошибка получения настроек: %w", err)
+		}
+
+		printSettings(values)
+		return nil
+	},
+}
+
+var SetCmd = &cobra.Command{
+	Use:   "set [key] [value]",
+	Short: "Изменить настройку",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		value := args[1]
+		values, err := app.UpdateSettings(cmd.Context(), map[string]*string{args[0]: &value})
+		if err != nil {
+			return fmt.Errorf("ошибка изменения настройки: %w", err)
+		}
+
+		fmt.Printf("✅ Настройка '%s' сохранена\n", args[0])
+		printSettings(values)
+		return nil
+	},
+}
+
+var ResetCmd = &cobra.Command{
+	Use:   "reset [key]",
+	Short: "Сбросить настройку к значению по умолчанию",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFromCmd(cmd)
+		if err != nil {
+			return err
+		}
+
+		values, err := app.UpdateSettings(cmd.Context(), map[string]*string{args[0]: nil})
+		if err != nil {
+			return fmt.Errorf("ошибка сброса настройки: %w", err)
+		}
+
+		fmt.Printf("✅ Настройка '%s' сброшена\n", args[0])
+		printSettings(values)
+		return nil
+	},
+}
+
+func appFromCmd(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+
+	if !app.IsAuthenticated() {
+		return nil, client.ErrAuthRequired
+	}
+
+	return app, nil
+}
+
+func printSettings(values map[string]string) {
+	fmt.Println("=== Настройки пользователя ===")
+	if len(values) == 0 {
+		fmt.Println("Используются значения по умолчанию")
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("  %-18s %s\n", key+":", values[key])
+	}
+}
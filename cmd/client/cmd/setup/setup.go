@@ -0,0 +1,213 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/prompt"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/spf13/cobra"
+)
+
+// unlockAttempts - сколько раз мастер-пароль запрашивается до выхода из мастера
+const unlockAttempts = 3
+
+// SetupCmd - мастер первого запуска на новом устройстве
+var SetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Настроить новое устройство и восстановить хранилище с сервера",
+	Long: `Мастер первого запуска: выполняет по шагам все, что нужно новому устройству.
+	1. Проверяет соединение с сервером
+	2. Выполняет вход (с кодом 2FA, если он включен)
+	3. Загружает файл мастер-ключа учетной записи и разблокирует его мастер-паролем
+	4. Загружает все записи с сервера
+	5. Проверяет, что каждая запись расшифровывается
+	6. Регистрирует устройство
+
+Файл мастер-ключа сохраняется на сервере при синхронизации с любого устройства.
+Ключ в нем зашифрован мастер-паролем, поэтому сервер не может расшифровать записи.
+Для новой учетной записи мастер создает мастер-ключ.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		if app.IsInitialized() {
+			fmt.Println("Клиент уже настроен на этом устройстве.")
+			fmt.Println("Синхронизировать записи: gophkeeper sync")
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Minute)
+		defer cancel()
+
+		fmt.Println("=== Настройка GophKeeper ===")
+		fmt.Println()
+
+		fmt.Println("[1/6] Проверка соединения с сервером...")
+		if err := app.CheckConnection(ctx); err != nil {
+			return fmt.Errorf("сервер недоступен: %w", err)
+		}
+		fmt.Println("✓ Соединение с сервером установлено")
+
+		fmt.Println("[2/6] Вход в систему")
+		if err := login(ctx, app); err != nil {
+			return err
+		}
+		fmt.Println("✓ Вход выполнен")
+
+		fmt.Println("[3/6] Мастер-ключ")
+		if err := masterKey(ctx, app); err != nil {
+			return err
+		}
+
+		fmt.Println("[4/6] Загрузка записей...")
+		if err := app.InitStorage(); err != nil {
+			return fmt.Errorf("ошибка инициализации хранилища: %w", err)
+		}
+		result, err := app.Sync(ctx)
+		if err != nil {
+			return fmt.Errorf("ошибка загрузки записей: %w. Повторите: gophkeeper sync", err)
+		}
+		if !result.Success {
+			return fmt.Errorf("загрузка завершена с ошибками (%d). Повторите: gophkeeper sync", len(result.Errors))
+		}
+		fmt.Printf("✓ Загружено записей: %d\n", result.Downloaded)
+
+		fmt.Println("[5/6] Проверка целостности...")
+		check, err := app.VerifyVault()
+		if err != nil {
+			return err
+		}
+		if len(check.Corrupt) > 0 {
+			fmt.Printf("⚠️  Не расшифровываются записи: %v\n", check.Corrupt)
+			fmt.Println("   Они зашифрованы другим мастер-ключом или повреждены. Восстановите их из резервной копии.")
+		} else {
+			fmt.Printf("✓ Все записи расшифрованы (%d)\n", check.Valid)
+		}
+
+		fmt.Println("[6/6] Регистрация устройства...")
+		response, err := app.RegisterDevice(ctx, 0)
+		if err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			fmt.Println("   Повторите позже: gophkeeper device register")
+		} else {
+			fmt.Printf("✓ Устройство %d (%s) зарегистрировано\n", response.Data.ID, response.Data.Name)
+		}
+
+		fmt.Println()
+		fmt.Println("✅ Устройство настроено!")
+		fmt.Println("Список записей: gophkeeper record list")
+		return nil
+	},
+}
+
+// login выполняет вход; токен сохраняется, чтобы следующие команды работали без входа
+func login(ctx context.Context, app *client.App) error {
+	email, err := prompt.Line("Email: ")
+	if err != nil {
+		return err
+	}
+
+	password, err := readPassword("Пароль: ")
+	if err != nil {
+		return err
+	}
+
+	_, err = app.Login(ctx, user.BaseRequest{Login: email, Password: password})
+	var mfaErr *client.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		var code string
+		code, err = prompt.Line("Код двухфакторной аутентификации (или код восстановления): ")
+		if err != nil {
+			return err
+		}
+
+		_, err = app.LoginMFA(ctx, email, mfaErr.Challenge, code)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка аутентификации: %w", err)
+	}
+	return nil
+}
+
+// masterKey восстанавливает мастер-ключ учетной записи с сервера, а для новой
+// учетной записи создает его
+func masterKey(ctx context.Context, app *client.App) error {
+	err := app.RestoreMasterKeyFromServer(ctx)
+	switch {
+	case err == nil:
+		fmt.Println("✓ Файл мастер-ключа загружен с сервера")
+		return unlock(app)
+	case !errors.Is(err, client.ErrKeyFileNotFound):
+		return err
+	}
+
+	// Ключа на сервере нет. Если данные уже есть, новый ключ их не расшифрует.
+	usage, err := app.Quota(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки данных учетной записи: %w", err)
+	}
+	if usage.Used > 0 {
+		return client.ErrKeyFileNotFound
+	}
+
+	fmt.Println("Новая учетная запись: создайте мастер-пароль для шифрования данных")
+	password, err := readPassword("Мастер-пароль: ")
+	if err != nil {
+		return err
+	}
+	confirm, err := readPassword("Повторите мастер-пароль: ")
+	if err != nil {
+		return err
+	}
+	if password != confirm {
+		return fmt.Errorf("пароли не совпадают")
+	}
+	if len(password) < 8 {
+		return fmt.Errorf("пароль должен содержать минимум 8 символов")
+	}
+
+	if err := app.InitMasterKey(password); err != nil {
+		return fmt.Errorf("ошибка создания мастер-ключа: %w", err)
+	}
+	if err := app.PublishKeyFile(ctx); err != nil {
+		fmt.Printf("⚠️  Файл мастер-ключа не сохранен на сервере: %v\n", err)
+		fmt.Println("   Он будет отправлен при следующей синхронизации.")
+	}
+	fmt.Println("✓ Мастер-ключ создан")
+	return nil
+}
+
+func unlock(app *client.App) error {
+	for attempt := 1; ; attempt++ {
+		password, err := readPassword("Мастер-пароль: ")
+		if err != nil {
+			return err
+		}
+
+		err = app.UnlockMasterKey(password)
+		if err == nil {
+			fmt.Println("✓ Мастер-ключ разблокирован")
+			return nil
+		}
+		if attempt == unlockAttempts {
+			return fmt.Errorf("ошибка разблокировки: %w. Продолжите: gophkeeper unlock && gophkeeper sync", err)
+		}
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+func readPassword(label string) (string, error) {
+	password, err := prompt.Password(label)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения пароля: %w", err)
+	}
+	return password, nil
+}
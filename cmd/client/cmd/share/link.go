@@ -0,0 +1,150 @@
+package share
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	linkField    string
+	linkExpires  time.Duration
+	linkMaxViews int
+)
+
+var LinkCmd = &cobra.Command{
+	Use:   "link [id|название]",
+	Short: "Одноразовая ссылка на секрет",
+	Long: `Шифрует поле записи (--field) или все ее поля случайным ключом, загружает
+шифротекст на сервер и выводит ссылку. Ключ записан во фрагменте ссылки
+(после #) и на сервер не передается: сервер не может прочитать секрет.
+
+Сервер отдает секрет не больше --max-views раз до истечения --expires и
+удаляет его после последнего просмотра. Получатель открывает ссылку в
+браузере или командой gophkeeper share open; учетная запись ему не нужна.`,
+	Example: `  gophkeeper share link 12 --field password
+  gophkeeper share link GitHub --expires 1h --max-views 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		if !app.IsMasterKeyUnlocked() {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+			return client.ErrMasterKeyLocked
+		}
+
+		link, err := app.CreateSecretLink(cmd.Context(), args[0], linkField, linkExpires, linkMaxViews)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("✅ Ссылка на секрет создана")
+		fmt.Printf("👁️  Просмотров: %d\n", link.MaxViews)
+		fmt.Printf("⏰ Действует до: %s\n", link.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		fmt.Println()
+		fmt.Println(link.URL)
+		fmt.Println()
+		fmt.Println("⚠️  Ссылка содержит ключ и больше не будет показана. Удалить: gophkeeper share revoke-link", link.ID)
+		return nil
+	},
+}
+
+var LinksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "Список ссылок на секреты",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		links, err := app.ListSecretLinks(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			fmt.Println("Ссылок на секреты нет. Создать: gophkeeper share link <id>")
+			return nil
+		}
+
+		now := time.Now()
+		fmt.Printf("%-24s %-18s %-18s %s\n", "ID", "Создана", "Действует до", "Просмотры")
+		for _, l := range links {
+			expires := l.ExpiresAt.Local().Format("2006-01-02 15:04")
+			if l.Expired(now) {
+				expires = "истекла"
+			}
+			fmt.Printf("%-24s %-18s %-18s %d/%d\n", l.ID, l.CreatedAt.Local().Format("2006-01-02 15:04"),
+				expires, l.Views, l.MaxViews)
+		}
+		return nil
+	},
+}
+
+var RevokeLinkCmd = &cobra.Command{
+	Use:     "revoke-link [id]",
+	Short:   "Удалить ссылку на секрет",
+	Example: `  gophkeeper share revoke-link Hk2x9dQ1b0w7Zt4mLr8c3A`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := app.RevokeSecretLink(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️  Ссылка %s удалена\n", args[0])
+		return nil
+	},
+}
+
+var OpenCmd = &cobra.Command{
+	Use:   "open [ссылка]",
+	Short: "Открыть ссылку на секрет",
+	Long: `Получает секрет по ссылке и расшифровывает его ключом из ссылки.
+Вход и мастер-ключ не нужны. Открытие расходует один просмотр.`,
+	Example: `  gophkeeper share open 'https://vault.example.com/s/Hk2x9dQ1b0w7Zt4mLr8c3A#...'`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		secret, err := app.OpenSecretLink(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		if secret.Title != "" {
+			fmt.Printf("🔐 %s\n", secret.Title)
+		}
+		names := make([]string, 0, len(secret.Fields))
+		for name := range secret.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: %s\n", name, secret.Fields[name])
+		}
+		return nil
+	},
+}
+
+func init() {
+	LinkCmd.Flags().StringVar(&linkField, "field", "", "передать только это поле записи (password, card_number...)")
+	LinkCmd.Flags().DurationVar(&linkExpires, "expires", time.Hour, "срок действия ссылки (не больше 168h)")
+	LinkCmd.Flags().IntVar(&linkMaxViews, "max-views", 1, "число просмотров (не больше 100)")
+}
@@ -0,0 +1,153 @@
+package share
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// ShareCmd - родительская команда доступа к записям для других
+var ShareCmd = &cobra.Command{
+	Use:   "share",
+	Short: "Доступ к записям для аудиторов и ссылки на секреты",
+	Long: `Токены просмотра открывают записи выбранных папок только для чтения:
+для аудита или экстренного доступа. Токен действует ограниченный срок,
+не позволяет ничего изменить и не видит записи вне своих папок.
+
+Записи остаются зашифрованными мастер-ключом: по токену видны список
+записей, их открытые метаданные (название, тип, теги, даты) и шифротекст.
+
+Одноразовые ссылки передают один секрет человеку без учетной записи:
+секрет шифруется ключом из ссылки, и сервер удаляет его после просмотра.`,
+}
+
+// TokenCmd - команды токенов просмотра
+var TokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Токены просмотра",
+}
+
+var (
+	createName    string
+	createFolders []string
+	createTTL     time.Duration
+)
+
+var TokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Выпустить токен просмотра",
+	Long: `Выпускает токен только для чтения записей указанных папок вместе
+с вложенными папками. Токен выводится один раз: передайте его получателю
+по защищенному каналу. Получатель читает записи запросами
+
+  curl -H "Authorization: Bearer <токен>" https://<сервер>/api/shared/records
+  curl -H "Authorization: Bearer <токен>" https://<сервер>/api/shared/records/<ID>`,
+	Example: `  gophkeeper share token create --name "аудит 2026" --folder Work/Cloud --ttl 72h
+  gophkeeper share token create --name break-glass --folder Infra --folder Work/DB`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+		if len(createFolders) == 0 {
+			return fmt.Errorf("укажите папки токена: --folder <путь>")
+		}
+
+		issued, err := app.CreateViewerToken(cmd.Context(), createName, createFolders, createTTL)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Токен просмотра %d (%s) выпущен\n", issued.ID, issued.Name)
+		fmt.Printf("📁 Папки: %s\n", strings.Join(createFolders, ", "))
+		fmt.Printf("⏰ Действует до: %s\n", issued.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		fmt.Println()
+		fmt.Println(issued.Secret)
+		fmt.Println()
+		fmt.Println("⚠️  Токен больше не будет показан. Отозвать: gophkeeper share token revoke", issued.ID)
+		return nil
+	},
+}
+
+var TokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Список токенов просмотра",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		tokens, err := app.ListViewerTokens(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Println("Токенов просмотра нет. Выпустить: gophkeeper share token create")
+			return nil
+		}
+
+		now := time.Now()
+		fmt.Printf("%-6s %-20s %-18s %-18s %s\n", "ID", "Название", "Действует до", "Использован", "Папки")
+		for _, t := range tokens {
+			expires := t.ExpiresAt.Local().Format("2006-01-02 15:04")
+			if t.Expired(now) {
+				expires = "истек"
+			}
+			used := "никогда"
+			if t.LastUsedAt != nil {
+				used = t.LastUsedAt.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%-6d %-20s %-18s %-18s %s\n", t.ID, t.Name, expires, used, strings.Join(t.Folders, ", "))
+		}
+		return nil
+	},
+}
+
+var TokenRevokeCmd = &cobra.Command{
+	Use:     "revoke [id]",
+	Short:   "Отозвать токен просмотра",
+	Example: `  gophkeeper share token revoke 3`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := appFrom(cmd)
+		if err != nil {
+			return err
+		}
+
+		id, err := strconv.Atoi(args[0])
+		if err != nil || id <= 0 {
+			return fmt.Errorf("неверный ID токена: %s", args[0])
+		}
+		if err := app.RevokeViewerToken(cmd.Context(), id); err != nil {
+			return err
+		}
+
+		fmt.Printf("🗑️  Токен просмотра %d отозван\n", id)
+		return nil
+	},
+}
+
+func appFrom(cmd *cobra.Command) (*client.App, error) {
+	app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+	if app == nil {
+		return nil, fmt.Errorf("приложение не инициализировано")
+	}
+	if !app.IsAuthenticated() {
+		return nil, client.ErrAuthRequired
+	}
+	return app, nil
+}
+
+func init() {
+	TokenCreateCmd.Flags().StringVar(&createName, "name", "", "название токена: кому и зачем выдан")
+	TokenCreateCmd.Flags().StringArrayVarP(&createFolders, "folder", "f", nil, "папка, записи которой открывает токен (можно несколько)")
+	TokenCreateCmd.Flags().DurationVar(&createTTL, "ttl", 24*time.Hour, "срок действия токена (не больше 720h)")
+	_ = TokenCreateCmd.MarkFlagRequired("name")
+}
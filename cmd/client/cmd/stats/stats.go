@@ -0,0 +1,177 @@
+// cmd/client/cmd/stats/stats.go
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/stats"
+
+	"github.com/spf13/cobra"
+)
+
+var statsDays int
+
+var StatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Статистика хранилища: записи, рост, устройства, синхронизация",
+	Long: `Показывает статистику хранилища:
+  • число и объем записей на сервере по типам;
+  • рост хранилища по дням за период (--days, по умолчанию 30):
+    созданные, измененные и удаленные записи, объем на конец дня;
+  • активность устройств: последняя синхронизация и трафик;
+  • статистику синхронизации этого устройства.
+
+Содержимое записей зашифровано, поэтому сервер считает только количество
+и объемы. Записи в корзине и хранилищах организаций не учитываются.
+С флагом --json вывод подходит для дашбордов: в growth перечислены все дни
+периода, в том числе без изменений.`,
+	Example: `  gophkeeper stats
+  gophkeeper stats --days 7
+  gophkeeper stats --json | jq '.server.growth[] | [.day, .records]'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+		if statsDays < 1 || statsDays > stats.MaxDays {
+			return fmt.Errorf("--days: период должен быть от 1 до %d дней", stats.MaxDays)
+		}
+
+		result := output.Stats{
+			Profile: app.Config().Profile,
+			Sync:    app.GetSyncService().GetStats(),
+		}
+		// Без сервера выводится хотя бы локальная статистика синхронизации
+		if server, err := app.AccountStats(cmd.Context(), statsDays); err != nil {
+			result.ServerError = err.Error()
+		} else {
+			result.Server = server
+		}
+
+		return output.Render(output.Result{
+			Value: result,
+			Text: func() error {
+				printStats(result)
+				return nil
+			},
+		})
+	},
+}
+
+func init() {
+	StatsCmd.Flags().IntVar(&statsDays, "days", stats.DefaultDays, fmt.Sprintf("период роста хранилища в днях (1-%d)", stats.MaxDays))
+}
+
+func printStats(s output.Stats) {
+	fmt.Println("=== Статистика GophKeeper ===")
+	if s.Profile != "" {
+		fmt.Printf("👤 Профиль: %s\n", s.Profile)
+	}
+
+	if s.Server == nil {
+		fmt.Printf("\n❌ Статистика сервера недоступна: %s\n", s.ServerError)
+	} else {
+		printRecords(s.Server)
+		printGrowth(s.Server)
+		printDevices(s.Server.Devices)
+	}
+
+	printSync(s.Sync)
+}
+
+func printRecords(s *stats.Stats) {
+	fmt.Printf("\n📦 Записи на сервере: %d (%s)\n", s.Records.TotalRecords, client.FormatBytes(s.Records.TotalSize))
+
+	types := make([]string, 0, len(s.Records.ByType))
+	for t := range s.Records.ByType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		ts := s.Records.ByType[t]
+		fmt.Printf("    %-8s %5d  %s\n", t, ts.Count, client.FormatBytes(ts.Size))
+	}
+}
+
+func printGrowth(s *stats.Stats) {
+	if len(s.Growth) == 0 {
+		return
+	}
+
+	// Число и объем записей до начала периода
+	first, last := s.Growth[0], s.Growth[len(s.Growth)-1]
+	startRecords := first.Records - first.Created + first.Deleted
+	startSize := first.Size - first.SizeDelta
+
+	fmt.Printf("\n📈 Рост за %d дн.: %+d записей, %s; дней с изменениями: %d\n",
+		s.Days, last.Records-startRecords, signedBytes(last.Size-startSize), s.Records.ActiveDays)
+	if s.Records.ActiveDays == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ДЕНЬ\tСОЗДАНО\tИЗМЕНЕНО\tУДАЛЕНО\tОБЪЕМ\tЗАПИСЕЙ")
+	for _, d := range s.Growth {
+		if !d.Active() {
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\t%s\t%d\n", d.Day, d.Created, d.Updated, d.Deleted, signedBytes(d.SizeDelta), d.Records)
+	}
+	_ = w.Flush()
+}
+
+func printDevices(devices []stats.Device) {
+	fmt.Println("\n💻 Устройства:")
+	if len(devices) == 0 {
+		fmt.Println("  нет зарегистрированных устройств")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  УСТРОЙСТВО\tТИП\tСТАТУС\tСИНХРОНИЗАЦИЯ\tЗАПРОСОВ\tОТПРАВЛЕНО\tПОЛУЧЕНО")
+	for _, d := range devices {
+		last := "никогда"
+		if !d.LastSyncTime.IsZero() {
+			last = formatTime(d.LastSyncTime)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%d\t%s\t%s\n", d.Name, d.Type, d.Status, last, d.Usage.Requests,
+			client.FormatBytes(d.Usage.BytesUploaded), client.FormatBytes(d.Usage.BytesDownloaded))
+	}
+	_ = w.Flush()
+}
+
+func printSync(s *client.SyncStats) {
+	fmt.Println("\n🔄 Синхронизация этого устройства:")
+	if s.TotalSyncs == 0 {
+		fmt.Println("  не выполнялась")
+		return
+	}
+
+	fmt.Printf("  Синхронизаций: %d (с ошибками: %d)\n", s.TotalSyncs, s.TotalErrors)
+	fmt.Printf("  Отправлено записей: %d, получено: %d\n", s.TotalUploads, s.TotalDownloads)
+	fmt.Printf("  Конфликтов: %d (разрешено: %d)\n", s.TotalConflicts, s.TotalResolved)
+	fmt.Printf("  Среднее время: %.2f сек\n", s.AvgSyncDuration)
+	if !s.LastSync.IsZero() {
+		fmt.Printf("  Последняя синхронизация: %s\n", formatTime(s.LastSync))
+	}
+}
+
+// signedBytes выводит изменение объема со знаком
+func signedBytes(n int64) string {
+	if n < 0 {
+		return "-" + client.FormatBytes(-n)
+	}
+	return "+" + client.FormatBytes(n)
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
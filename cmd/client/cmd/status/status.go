@@ -0,0 +1,216 @@
+// cmd/client/cmd/status/status.go
+package status
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+// expiryWarning - за сколько до окончания сессии предупреждать о повторном входе
+const expiryWarning = 2 * time.Hour
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Состояние клиента: вход, ключ, записи, синхронизация, сервер",
+	Long: `Показывает в одном месте:
+  • вход на сервер и срок действия сессии;
+  • заблокирован ли мастер-ключ;
+  • число локальных записей по типам, записи в корзине;
+  • изменения, еще не отправленные на сервер;
+  • время и итог последней синхронизации;
+  • доступность сервера и время ответа;
+  • использование хранилища на сервере.
+
+Сервер проверяется заново, без кэша. Если часть сведений получить не удалось
+(сервер недоступен, локальная база зашифрована и ключ заблокирован), команда
+выводит остальное и указывает причину.`,
+	Example: `  gophkeeper status
+  gophkeeper status --json | jq .records.pending`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		status := collect(cmd.Context(), app)
+		return output.Render(output.Result{
+			Value: status,
+			Text: func() error {
+				printStatus(status)
+				return nil
+			},
+		})
+	},
+}
+
+// collect собирает сведения; ошибки отдельных разделов не прерывают сбор
+func collect(ctx context.Context, app *client.App) output.Status {
+	status := output.Status{
+		Profile:     app.Config().Profile,
+		Initialized: app.IsInitialized(),
+		MasterKey:   output.MasterKey{Unlocked: app.IsMasterKeyUnlocked()},
+	}
+
+	session := app.Session()
+	status.Auth = output.Auth{
+		Authenticated: session.Authenticated,
+		Login:         session.Login,
+		Expired:       session.Expired(time.Now()),
+	}
+	if !session.LoggedInAt.IsZero() {
+		status.Auth.LoggedInAt = &session.LoggedInAt
+		status.Auth.ExpiresAt = &session.ExpiresAt
+	}
+
+	if stats, err := app.LocalStats(); err != nil {
+		status.RecordsError = err.Error()
+	} else {
+		status.Records = stats
+	}
+
+	syncService := app.GetSyncService()
+	stats := syncService.GetStats()
+	status.Sync = output.LastSync{
+		Success:    stats.LastSuccess,
+		Error:      stats.LastError,
+		TotalSyncs: stats.TotalSyncs,
+	}
+	if !stats.LastAttempt.IsZero() {
+		status.Sync.LastAttempt = &stats.LastAttempt
+	}
+	if paused := syncService.Paused(); paused != nil {
+		status.Sync.PausedUntil = &paused.Until
+	}
+
+	health := app.ConnectionStatus(ctx, true)
+	status.Server = output.Server{OK: health.OK, Error: health.Error}
+	if health.OK {
+		status.Server.LatencyMS = health.Latency.Milliseconds()
+		if merr := health.MaintenanceErr(); merr != nil {
+			status.Server.MaintenanceUntil = &merr.Until
+		}
+	}
+
+	switch {
+	case !session.Authenticated:
+		status.QuotaError = "требуется вход"
+	case !health.OK:
+		status.QuotaError = "сервер недоступен"
+	default:
+		if quota, err := app.Quota(ctx); err != nil {
+			status.QuotaError = err.Error()
+		} else {
+			status.Quota = quota
+		}
+	}
+
+	return status
+}
+
+func printStatus(s output.Status) {
+	fmt.Println("=== Состояние GophKeeper ===")
+	if s.Profile != "" {
+		fmt.Printf("👤 Профиль: %s\n", s.Profile)
+	}
+	if !s.Initialized {
+		fmt.Println("⚠️  Клиент не инициализирован. Выполните: gophkeeper init")
+	}
+
+	fmt.Print("\n🔐 Вход: ")
+	switch {
+	case !s.Auth.Authenticated:
+		fmt.Println("❌ не выполнен (gophkeeper auth login)")
+	case s.Auth.Expired:
+		fmt.Printf("❌ сессия %s истекла %s, выполните вход заново\n", s.Auth.Login, formatTime(*s.Auth.ExpiresAt))
+	case s.Auth.ExpiresAt != nil:
+		left := time.Until(*s.Auth.ExpiresAt)
+		icon := "✅"
+		if left < expiryWarning {
+			icon = "⚠️ "
+		}
+		fmt.Printf("%s %s, сессия до %s (осталось %s)\n", icon, s.Auth.Login,
+			formatTime(*s.Auth.ExpiresAt), left.Round(time.Minute))
+	default:
+		fmt.Printf("✅ %s\n", s.Auth.Login)
+	}
+
+	fmt.Print("🔑 Мастер-ключ: ")
+	if s.MasterKey.Unlocked {
+		fmt.Println("🔓 разблокирован")
+	} else {
+		fmt.Println("🔒 заблокирован (gophkeeper unlock)")
+	}
+
+	fmt.Println("\n📦 Локальные записи:")
+	if s.Records == nil {
+		fmt.Printf("  ❌ %s\n", s.RecordsError)
+	} else {
+		fmt.Printf("  Всего: %d\n", s.Records.Total)
+		types := make([]string, 0, len(s.Records.ByType))
+		for t := range s.Records.ByType {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			fmt.Printf("    %-8s %d\n", t, s.Records.ByType[t])
+		}
+		if s.Records.Trashed > 0 {
+			fmt.Printf("  В корзине: %d\n", s.Records.Trashed)
+		}
+		if s.Records.Pending > 0 {
+			fmt.Printf("  ⏳ Не отправлено на сервер: %d\n", s.Records.Pending)
+		} else {
+			fmt.Println("  ✅ Все изменения отправлены на сервер")
+		}
+	}
+
+	fmt.Print("\n🔄 Последняя синхронизация: ")
+	switch {
+	case s.Sync.LastAttempt == nil:
+		fmt.Println("не выполнялась")
+	case s.Sync.Success:
+		fmt.Printf("✅ %s\n", formatTime(*s.Sync.LastAttempt))
+	default:
+		fmt.Printf("❌ %s\n", formatTime(*s.Sync.LastAttempt))
+		if s.Sync.Error != "" {
+			fmt.Printf("  %s\n", s.Sync.Error)
+		}
+	}
+	if s.Sync.PausedUntil != nil {
+		fmt.Printf("  🛠  Приостановлена до %s: сервер на обслуживании\n", s.Sync.PausedUntil.Format("15:04:05"))
+	}
+
+	fmt.Print("🌐 Сервер: ")
+	switch {
+	case !s.Server.OK:
+		fmt.Printf("❌ недоступен: %s\n", s.Server.Error)
+	case s.Server.MaintenanceUntil != nil:
+		fmt.Printf("🛠  на обслуживании до %s (%d мс)\n", s.Server.MaintenanceUntil.Format("15:04:05"), s.Server.LatencyMS)
+	default:
+		fmt.Printf("✅ доступен (%d мс)\n", s.Server.LatencyMS)
+	}
+
+	fmt.Print("💾 Хранилище: ")
+	if s.Quota == nil {
+		fmt.Printf("нет данных (%s)\n", s.QuotaError)
+		return
+	}
+	percent := 0.0
+	if s.Quota.Limit > 0 {
+		percent = float64(s.Quota.Used) / float64(s.Quota.Limit) * 100
+	}
+	fmt.Printf("%s из %s (%.1f%%)\n", client.FormatBytes(s.Quota.Used), client.FormatBytes(s.Quota.Limit), percent)
+}
+
+func formatTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05")
+}
@@ -2,9 +2,15 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/cmd/client/cmd/output"
 	"gophkeeper/internal/app/client"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -15,6 +21,10 @@ var (
 	syncStatus    bool
 	resetStats    bool
 	showConflicts bool
+	dryRun        bool
+	syncTypes     []string
+	syncTags      []string
+	excludeTags   []string
 )
 
 var SyncCmd = &cobra.Command{
@@ -23,7 +33,23 @@ var SyncCmd = &cobra.Command{
 	Long: `Синхронизация данных между клиентом и сервером.
 	
 Команда позволяет управлять процессом синхронизации, просматривать статус
-и разрешать конфликты.`,
+и разрешать конфликты.
+
+Флаги --types, --tag и --exclude-tag включают выборочную синхронизацию на
+этот запуск: отправляются и загружаются только записи перечисленных типов
+и тегов. Постоянный фильтр задается полем filter в sync_config.json, флаги
+заменяют соответствующие его части. После смены фильтра клиент заново
+сверяет индекс записей с сервером; уже загруженные записи вне фильтра
+остаются на устройстве.
+
+Флаг --dry-run находит изменения и конфликты, но ничего не применяет:
+выводит, какие записи были бы отправлены на сервер и загружены с него
+и как текущая стратегия разрешила бы конфликты. Локальная база, курсор
+синхронизации и статистика не меняются.`,
+	Example: `  gophkeeper sync
+  gophkeeper sync --dry-run
+  gophkeeper sync --types login,card --exclude-tag archive
+  gophkeeper sync --tag work`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
 		if app == nil {
@@ -42,42 +68,221 @@ var SyncCmd = &cobra.Command{
 			return showSyncConflicts(cmd.Context(), app)
 		}
 
+		if err := applyFilterFlags(cmd, app.GetSyncService()); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return runDryRun(cmd.Context(), app)
+		}
+
 		// Выполняем синхронизацию
 		return runSync(cmd.Context(), app, forceSync)
 	},
 }
 
 func runSync(ctx context.Context, app *client.App, _ bool) error {
-	fmt.Println("=== Синхронизация данных ===")
+	text := output.Current() == output.Text
+	if text {
+		fmt.Println("=== Синхронизация данных ===")
+	}
 
 	if !app.IsAuthenticated() {
-		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+		return client.ErrAuthRequired
 	}
 
 	if !app.IsMasterKeyUnlocked() {
-		fmt.Println("❌ Мастер-ключ заблокирован")
-		fmt.Println()
-		fmt.Println("Для синхронизации необходимо разблокировать мастер-ключ.")
-		fmt.Println("Выполните команду: gophkeeper unlock")
-		return fmt.Errorf("мастер-ключ заблокирован")
+		if text {
+			fmt.Println("❌ Мастер-ключ заблокирован")
+			fmt.Println()
+			fmt.Println("Для синхронизации необходимо разблокировать мастер-ключ.")
+			fmt.Println("Выполните команду: gophkeeper unlock")
+		}
+		return client.ErrMasterKeyLocked
 	}
 
 	syncService := app.GetSyncService()
 
-	fmt.Println("Проверка соединения с сервером...")
-	if err := app.CheckConnection(); err != nil {
+	if text {
+		fmt.Println("Проверка соединения с сервером...")
+	}
+	if err := app.CheckConnection(ctx); err != nil {
 		return fmt.Errorf("сервер недоступен: %v", err)
 	}
 
-	fmt.Println("Начало синхронизации...")
+	if text {
+		fmt.Println("Начало синхронизации...")
+	}
 	start := time.Now()
 
 	result, err := app.Sync(ctx)
+	var merr *client.MaintenanceError
+	if errors.As(err, &merr) {
+		now := time.Now()
+		result, err = &client.SyncResult{Paused: merr, StartTime: start, EndTime: now}, nil
+	}
 	if err != nil {
+		// Скрипты получают итог и при ошибке: что успело выполниться и где сбой
+		if result != nil && !text {
+			if rerr := output.Render(output.Result{Value: output.SyncResultOf(result, time.Since(start))}); rerr != nil {
+				return rerr
+			}
+		}
+		if errors.Is(err, client.ErrDevicePending) && text {
+			printDevicePending(app)
+		}
 		return fmt.Errorf("ошибка синхронизации: %w", err)
 	}
 
 	duration := time.Since(start)
+	summary := output.SyncResultOf(result, duration)
+	if window := app.Config().ExpiryWarning; window > 0 {
+		// Синхронизация уже выполнена: ошибка проверки сроков ее не отменяет
+		expiring, err := app.ExpiringRecords(window)
+		if err != nil && text {
+			fmt.Fprintf(os.Stderr, "⚠️  Не удалось проверить сроки действия записей: %v\n", err)
+		}
+		summary.Expiring = expiring
+	}
+
+	if err := output.Render(output.Result{
+		Value: summary,
+		Text: func() error {
+			printSyncResult(result, duration, syncService.GetStats())
+			printExpiring(summary.Expiring)
+			return nil
+		},
+	}); err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("синхронизация завершена с ошибками: %d", len(result.Errors))
+	}
+	return nil
+}
+
+// runDryRun выводит план синхронизации, ничего не изменяя
+func runDryRun(ctx context.Context, app *client.App) error {
+	if !app.IsAuthenticated() {
+		return client.ErrAuthRequired
+	}
+	if !app.IsMasterKeyUnlocked() {
+		return client.ErrMasterKeyLocked
+	}
+
+	plan, err := app.PlanSync(ctx)
+	var merr *client.MaintenanceError
+	if errors.As(err, &merr) {
+		if output.Current() == output.Text {
+			printMaintenance(merr)
+		}
+		return fmt.Errorf("план синхронизации недоступен: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка построения плана синхронизации: %w", err)
+	}
+
+	if err := output.Render(output.Result{
+		Value: output.SyncPlanOf(plan),
+		Text: func() error {
+			printSyncPlan(plan)
+			return nil
+		},
+	}); err != nil {
+		return err
+	}
+
+	if len(plan.Errors) > 0 {
+		return fmt.Errorf("план синхронизации неполный: ошибок %d", len(plan.Errors))
+	}
+	return nil
+}
+
+func printSyncPlan(plan *client.SyncPlan) {
+	fmt.Println("=== План синхронизации (пробный запуск, ничего не изменено) ===")
+	if !plan.Filter.IsEmpty() {
+		fmt.Printf("Выборочная синхронизация: %s\n", describeFilter(plan.Filter))
+	}
+
+	fmt.Printf("\n📤 Будет отправлено на сервер: %d\n", len(plan.Upload))
+	printPlannedChanges(plan.Upload)
+	fmt.Printf("\n📥 Будет загружено с сервера: %d\n", len(plan.Download))
+	printPlannedChanges(plan.Download)
+
+	if len(plan.Conflicts) > 0 {
+		fmt.Printf("\n⚠️  Конфликтов: %d (стратегия %s)\n", len(plan.Conflicts), plan.Strategy)
+		for _, c := range plan.Conflicts {
+			title := c.Title
+			if title == "" {
+				title = "(без названия)"
+			}
+			resolution := "потребуется ручное разрешение"
+			switch c.Resolution {
+			case "client":
+				resolution = "останется локальная версия"
+			case "server":
+				resolution = "будет принята версия сервера"
+			case "merged":
+				resolution = "версии будут объединены"
+			}
+			fmt.Printf("  • %s (запись %d, %s): %s, v%d локально / v%d на сервере — %s\n",
+				title, c.RecordID, c.RecordType, c.ConflictType, c.LocalVersion, c.ServerVersion, resolution)
+		}
+	} else {
+		fmt.Println("\n✅ Конфликтов нет")
+	}
+
+	if len(plan.Errors) > 0 {
+		fmt.Printf("\n❌ Не удалось проверить, план неполный: %d\n", len(plan.Errors))
+		for _, e := range plan.Errors {
+			fmt.Printf("  • %s: %s\n", e.Operation, e.Error)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Для выполнения синхронизации: gophkeeper sync")
+}
+
+func printPlannedChanges(changes []client.PlannedChange) {
+	for _, c := range changes {
+		title := c.Title
+		if title == "" {
+			title = "(без названия)"
+		}
+		icon, action := "✏️ ", "изменена"
+		switch c.Action {
+		case client.PlanCreate:
+			icon, action = "➕", "новая"
+		case client.PlanDelete:
+			icon, action = "🗑 ", "удалена"
+		}
+		fmt.Printf("  %s %s (%s, %s", icon, title, c.Type, action)
+		if c.RecordID > 0 {
+			fmt.Printf(", ID %d", c.RecordID)
+		}
+		fmt.Println(")")
+	}
+}
+
+// printDevicePending выводит ID и отпечаток ключа устройства, которое
+// нужно подтвердить на доверенном устройстве
+func printDevicePending(app *client.App) {
+	identity, err := app.DeviceIdentity()
+	if err != nil {
+		return
+	}
+	fmt.Printf("⏳ Устройство ожидает подтверждения. Отпечаток ключа: %s\n", identity.Fingerprint())
+	fmt.Printf("Сверьте отпечаток и выполните на доверенном устройстве: gophkeeper device approve %d\n", identity.ServerID)
+}
+
+func printSyncResult(result *client.SyncResult, duration time.Duration, stats *client.SyncStats) {
+	if result.Paused != nil {
+		printMaintenance(result.Paused)
+		if result.Uploaded == 0 && result.Downloaded == 0 && len(result.Errors) == 0 {
+			return
+		}
+	}
 
 	fmt.Println()
 	fmt.Println("✅ Синхронизация завершена!")
@@ -99,7 +304,11 @@ func runSync(ctx context.Context, app *client.App, _ bool) error {
 		fmt.Printf("Ошибок при синхронизации: %d\n", len(result.Errors))
 		for i, err := range result.Errors {
 			if i < 3 { // Показываем только первые 3 ошибки
-				fmt.Printf("  • %s: %s\n", err.Operation, err.Error)
+				if err.RecordID > 0 {
+					fmt.Printf("  • %s (запись %d): %s\n", err.Operation, err.RecordID, err.Error)
+				} else {
+					fmt.Printf("  • %s: %s\n", err.Operation, err.Error)
+				}
 			}
 		}
 		if len(result.Errors) > 3 {
@@ -107,21 +316,81 @@ func runSync(ctx context.Context, app *client.App, _ bool) error {
 		}
 	}
 
-	stats := syncService.GetStats()
 	fmt.Printf("Всего синхронизаций: %d\n", stats.TotalSyncs)
 	if !stats.LastSync.IsZero() {
 		fmt.Printf("Последняя синхронизация: %s\n",
 			stats.LastSync.Format("2006-01-02 15:04:05"))
 	}
-
-	return nil
 }
 
-func showSyncStatus(_ context.Context, app *client.App) error {
-	fmt.Println("=== Статус синхронизации ===")
+// printExpiring предупреждает о записях с истекающим или истекшим сроком действия
+func printExpiring(expiring []record.Expiration) {
+	if len(expiring) == 0 {
+		return
+	}
 
+	now := time.Now()
+	fmt.Println()
+	fmt.Printf("⏳ Срок действия истекает у записей: %d\n", len(expiring))
+	for _, e := range expiring {
+		title := e.Title
+		if title == "" {
+			title = "Без названия"
+		}
+		date := e.ExpiresAt.Local().Format("2006-01-02")
+		if e.Expired(now) {
+			fmt.Printf("  ⛔ %s (ID %d, %s): истек %s\n", title, e.RecordID, e.Type, date)
+		} else {
+			fmt.Printf("  • %s (ID %d, %s): до %s\n", title, e.RecordID, e.Type, date)
+		}
+	}
+	fmt.Println("   Список: gophkeeper record list --expiring 30d")
+}
+
+func showSyncStatus(ctx context.Context, app *client.App) error {
 	syncService := app.GetSyncService()
 	stats := syncService.GetStats()
+	filter := syncService.Filter()
+	health := app.ConnectionStatus(ctx, false)
+
+	status := output.SyncStatus{
+		TotalSyncs:      stats.TotalSyncs,
+		Successful:      stats.TotalSyncs - stats.TotalErrors,
+		Failed:          stats.TotalErrors,
+		Uploaded:        stats.TotalUploads,
+		Downloaded:      stats.TotalDownloads,
+		Conflicts:       stats.TotalConflicts,
+		Resolved:        stats.TotalResolved,
+		AvgDurationSecs: stats.AvgSyncDuration,
+		Server:          output.Server{OK: health.OK, Error: health.Error},
+		Authenticated:   app.IsAuthenticated(),
+	}
+	if !stats.LastSync.IsZero() {
+		last := stats.LastSync
+		status.LastSync = &last
+	}
+	if !filter.IsEmpty() {
+		status.Filter = &output.Filter{Types: filter.Types, Tags: filter.Tags, ExcludeTags: filter.ExcludeTags}
+	}
+	if health.OK {
+		status.Server.LatencyMS = health.Latency.Milliseconds()
+		if merr := health.MaintenanceErr(); merr != nil {
+			until := merr.Until
+			status.Server.MaintenanceUntil = &until
+		}
+	}
+
+	return output.Render(output.Result{
+		Value: status,
+		Text: func() error {
+			printSyncStatus(app, stats, filter, health)
+			return nil
+		},
+	})
+}
+
+func printSyncStatus(app *client.App, stats *client.SyncStats, filter sync.Filter, health *client.HealthStatus) {
+	fmt.Println("=== Статус синхронизации ===")
 
 	fmt.Println("📊 Статистика:")
 	fmt.Printf("  Всего синхронизаций: %d\n", stats.TotalSyncs)
@@ -140,12 +409,19 @@ func showSyncStatus(_ context.Context, app *client.App) error {
 	}
 
 	fmt.Printf("\n⚙️  Конфигурация: (используйте файл sync_config.json для настройки)\n")
+	if !filter.IsEmpty() {
+		fmt.Printf("  Выборочная синхронизация: %s\n", describeFilter(filter))
+	}
 
 	fmt.Printf("\n🌐 Соединение с сервером: ")
-	if err := app.CheckConnection(); err != nil {
-		fmt.Printf("❌ Ошибка: %v\n", err)
+	if !health.OK {
+		fmt.Printf("❌ Ошибка: %s\n", health.Error)
+	} else if merr := health.MaintenanceErr(); merr != nil {
+		fmt.Printf("🛠  сервер на обслуживании\n")
+		printMaintenance(merr)
 	} else {
-		fmt.Printf("✅ OK\n")
+		fmt.Printf("✅ OK (%d мс, проверено %s назад)\n",
+			health.Latency.Milliseconds(), time.Since(health.CheckedAt).Round(time.Second))
 	}
 
 	fmt.Printf("🔐 Аутентификация: ")
@@ -154,8 +430,6 @@ func showSyncStatus(_ context.Context, app *client.App) error {
 	} else {
 		fmt.Printf("❌ Требуется вход\n")
 	}
-
-	return nil
 }
 
 func resetSyncStats(app *client.App) error {
@@ -165,14 +439,127 @@ func resetSyncStats(app *client.App) error {
 	return nil
 }
 
-func showSyncConflicts(_ context.Context, _ *client.App) error {
-	fmt.Println("Просмотр конфликтов будет реализован в будущей версии")
+func showSyncConflicts(ctx context.Context, app *client.App) error {
+	if !app.IsAuthenticated() {
+		return client.ErrAuthRequired
+	}
+
+	conflicts, err := app.GetSyncConflicts(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка получения конфликтов: %w", err)
+	}
+
+	ids := make([]int, len(conflicts))
+	for i, c := range conflicts {
+		ids[i] = c.ID
+	}
+
+	return output.Render(output.Result{
+		Value: output.Conflicts(conflicts),
+		IDs:   ids,
+		Text: func() error {
+			printSyncConflicts(conflicts)
+			return nil
+		},
+	})
+}
+
+func printSyncConflicts(conflicts []sync.Conflict) {
+	fmt.Println("=== Конфликты синхронизации ===")
+
+	if len(conflicts) == 0 {
+		fmt.Println("✅ Неразрешенных конфликтов нет")
+		return
+	}
+
+	fmt.Printf("⚠️  Неразрешенных конфликтов: %d\n", len(conflicts))
+	for _, c := range conflicts {
+		title := c.Title
+		if title == "" {
+			title = "(без названия)"
+		}
+
+		fmt.Println()
+		fmt.Printf("Конфликт #%d: %s\n", c.ID, title)
+		fmt.Printf("  Запись: %d", c.RecordID)
+		if c.RecordType != "" {
+			fmt.Printf(" (%s)", c.RecordType)
+		}
+		fmt.Println()
+		fmt.Printf("  Тип конфликта: %s\n", c.ConflictType)
+		fmt.Printf("  Локальная версия: v%d, изменена %s\n", c.LocalVersion, formatConflictTime(c.LocalModified))
+		fmt.Printf("  Серверная версия: v%d, изменена %s\n", c.ServerVersion, formatConflictTime(c.ServerModified))
+	}
+}
+
+// applyFilterFlags заменяет части фильтра из sync_config.json заданными флагами
+func applyFilterFlags(cmd *cobra.Command, syncService *client.SyncService) error {
+	flags := cmd.Flags()
+	if !flags.Changed("types") && !flags.Changed("tag") && !flags.Changed("exclude-tag") {
+		return nil
+	}
+
+	filter := syncService.Filter()
+	if flags.Changed("types") {
+		for _, t := range syncTypes {
+			if err := record.RecType(t).Validate(); err != nil {
+				return err
+			}
+		}
+		filter.Types = syncTypes
+	}
+	if flags.Changed("tag") {
+		filter.Tags = syncTags
+	}
+	if flags.Changed("exclude-tag") {
+		filter.ExcludeTags = excludeTags
+	}
+
+	if err := syncService.SetFilter(filter); err != nil {
+		return fmt.Errorf("неверный фильтр синхронизации: %w", err)
+	}
 	return nil
 }
 
+func describeFilter(filter sync.Filter) string {
+	var parts []string
+	if len(filter.Types) > 0 {
+		parts = append(parts, "типы "+strings.Join(filter.Types, ", "))
+	}
+	if len(filter.Tags) > 0 {
+		parts = append(parts, "теги "+strings.Join(filter.Tags, ", "))
+	}
+	if len(filter.ExcludeTags) > 0 {
+		parts = append(parts, "кроме тегов "+strings.Join(filter.ExcludeTags, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func formatConflictTime(t time.Time) string {
+	if t.IsZero() {
+		return "неизвестно"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
 func init() {
 	SyncCmd.Flags().BoolVarP(&forceSync, "force", "f", false, "принудительная синхронизация")
 	SyncCmd.Flags().BoolVar(&syncStatus, "status", false, "показать статус синхронизации")
 	SyncCmd.Flags().BoolVar(&resetStats, "reset", false, "сбросить статистику синхронизации")
 	SyncCmd.Flags().BoolVar(&showConflicts, "conflicts", false, "показать неразрешенные конфликты")
+	SyncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "показать, что будет отправлено и загружено, ничего не изменяя")
+	SyncCmd.Flags().StringSliceVar(&syncTypes, "types", nil, "синхронизировать только записи этих типов (login, card, text, binary, otp, ssh_key)")
+	SyncCmd.Flags().StringSliceVar(&syncTags, "tag", nil, "синхронизировать только записи хотя бы с одним из тегов")
+	SyncCmd.Flags().StringSliceVar(&excludeTags, "exclude-tag", nil, "не синхронизировать записи с этими тегами")
+}
+
+func printMaintenance(merr *client.MaintenanceError) {
+	fmt.Println()
+	fmt.Println("🛠  Сервер на плановом обслуживании и временно не принимает изменения.")
+	if merr.Message != "" {
+		fmt.Printf("   Сообщение сервера: %s\n", merr.Message)
+	}
+	fmt.Printf("   Синхронизация приостановлена до %s. Локальные изменения сохранены\n",
+		merr.Until.Format("15:04:05"))
+	fmt.Println("   и будут отправлены автоматически.")
 }
@@ -0,0 +1,46 @@
+package undo
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var UndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Отменить последнее изменение записи",
+	Long: `Отменяет последнее локальное изменение записи: создание, обновление
+или удаление. Клиент хранит небольшой зашифрованный журнал из последних
+изменений с прежним состоянием записи.
+
+Отменить можно только изменение, которое еще не отправлено на сервер
+(например, сделанное без связи с ним). Если изменение уже синхронизировано,
+прошлые версии записи доступны через gophkeeper record history, а удаленные
+записи - через gophkeeper record trash restore.`,
+	Example: `  gophkeeper undo`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		entry, err := app.Undo()
+		if err != nil {
+			return err
+		}
+
+		switch entry.Action {
+		case client.UndoCreate:
+			fmt.Printf("↩️  Создание записи %d отменено, запись удалена\n", entry.RecordID)
+		case client.UndoUpdate:
+			fmt.Printf("↩️  Изменение записи %d отменено\n", entry.RecordID)
+		case client.UndoDelete:
+			fmt.Printf("↩️  Удаление отменено, запись восстановлена (ID %d)\n", entry.RecordID)
+		}
+		return nil
+	},
+}
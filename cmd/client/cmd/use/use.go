@@ -0,0 +1,69 @@
+package use
+
+import (
+	"fmt"
+
+	"gophkeeper/cmd/client/cmd/clientctx"
+	"gophkeeper/internal/app/client"
+
+	"github.com/spf13/cobra"
+)
+
+var clearWorkspace bool
+
+var UseCmd = &cobra.Command{
+	Use:   "use [контекст]",
+	Short: "Задать контекст для работы с записями",
+	Long: `Задает текущий контекст - категорию записей, которой ограничиваются
+последующие команды: gophkeeper record list (в том числе с --search)
+выводит только записи этой категории и вложенных в нее, без повторения
+фильтров в каждой команде.
+
+Категории с "/" образуют каталоги: контекст work включает записи
+категорий work, work/aws и work/aws/prod. Контекст хранится в состоянии
+клиента до смены или сброса.
+
+Без аргументов команда показывает текущий контекст, --clear сбрасывает его.
+Флаг --all у record list выводит записи без учета контекста.`,
+	Example: `  gophkeeper use work/aws
+  gophkeeper use
+  gophkeeper use --clear`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app := cmd.Context().Value(clientctx.ClientAppKey).(*client.App)
+		if app == nil {
+			return fmt.Errorf("приложение не инициализировано")
+		}
+
+		switch {
+		case clearWorkspace:
+			if len(args) > 0 {
+				return fmt.Errorf("--clear не принимает контекст")
+			}
+			if err := app.SetWorkspace(""); err != nil {
+				return err
+			}
+			fmt.Println("✅ Контекст сброшен, команды работают со всеми записями")
+		case len(args) == 1:
+			if err := app.SetWorkspace(args[0]); err != nil {
+				return err
+			}
+			if workspace := app.Workspace(); workspace != "" {
+				fmt.Printf("📁 Текущий контекст: %s\n", workspace)
+			} else {
+				fmt.Println("✅ Контекст сброшен, команды работают со всеми записями")
+			}
+		default:
+			if workspace := app.Workspace(); workspace != "" {
+				fmt.Printf("📁 Текущий контекст: %s\n", workspace)
+			} else {
+				fmt.Println("Контекст не задан, команды работают со всеми записями")
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	UseCmd.Flags().BoolVar(&clearWorkspace, "clear", false, "сбросить контекст")
+}
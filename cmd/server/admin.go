@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"gophkeeper/internal/app/server"
+	"gophkeeper/internal/app/server/admin"
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/infrastructure/migration"
+
+	"golang.org/x/exp/slog"
+)
+
+const adminUsage = "usage: gophkeeper admin users | user ID | disable ID | enable ID | " +
+	"reset-password ID | role ID user|admin | purge [-older-than DURATION] [-user ID]"
+
+// runAdmin выполняет команду `gophkeeper admin ...` напрямую в базе сервера,
+// без admin API и токена: доступ к ней дает доступ к конфигурации сервера
+func runAdmin(cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(adminUsage)
+	}
+
+	if err := migration.NewMigration(cfg, migration.DefaultEngine).Up(); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	ctx := context.Background()
+	repos, err := server.OpenStorage(ctx, cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer repos.Close()
+
+	service := admin.NewService(admin.Repositories{
+		Users:    repos.Users,
+		Sessions: repos.Sessions,
+		Usage:    repos.Quotas,
+		Devices:  repos.Sync,
+		Records:  repos.Records,
+	}, log)
+
+	return adminCommand(ctx, service, os.Stdout, args)
+}
+
+func adminCommand(ctx context.Context, service admin.Servicer, out io.Writer, args []string) error {
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "users":
+		if len(args) != 0 {
+			return errors.New(adminUsage)
+		}
+		users, err := service.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+		printUsers(out, users...)
+		return nil
+	case "user", "disable", "enable", "reset-password":
+		if len(args) != 1 {
+			return errors.New(adminUsage)
+		}
+		id, err := parseUserID(args[0])
+		if err != nil {
+			return err
+		}
+		return adminUserCommand(ctx, service, out, command, id)
+	case "role":
+		if len(args) != 2 {
+			return errors.New(adminUsage)
+		}
+		id, err := parseUserID(args[0])
+		if err != nil {
+			return err
+		}
+		info, err := service.SetRole(ctx, id, args[1])
+		if err != nil {
+			return err
+		}
+		printUsers(out, *info)
+		return nil
+	case "purge":
+		return adminPurge(ctx, service, out, args)
+	default:
+		return errors.New(adminUsage)
+	}
+}
+
+func adminUserCommand(ctx context.Context, service admin.Servicer, out io.Writer, command string, id int) error {
+	var (
+		info *admin.UserInfo
+		err  error
+	)
+
+	switch command {
+	case "disable":
+		info, err = service.Disable(ctx, id)
+	case "enable":
+		info, err = service.Enable(ctx, id)
+	case "reset-password":
+		reset, err := service.ResetPassword(ctx, id)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "temporary password for user %d: %s\n", reset.UserID, reset.TemporaryPassword)
+		fmt.Fprintf(out, "sessions revoked: %d\n", reset.SessionsRevoked)
+		return nil
+	default:
+		info, err = service.GetUser(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	printUsers(out, *info)
+	return nil
+}
+
+func adminPurge(ctx context.Context, service admin.Servicer, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	olderThan := flags.Duration("older-than", 0, "purge records deleted earlier than this")
+	userID := flags.Int("user", 0, "purge only this user's trash")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return errors.New(adminUsage)
+	}
+
+	result, err := service.Purge(ctx, *userID, *olderThan)
+	if err != nil {
+		return err
+	}
+
+	scope := "all users"
+	if result.UserID != 0 {
+		scope = "user " + strconv.Itoa(result.UserID)
+	}
+	fmt.Fprintf(out, "purged %d records of %s deleted before %s\n",
+		result.Purged, scope, result.Before.Format(time.RFC3339))
+	return nil
+}
+
+func printUsers(out io.Writer, users ...admin.UserInfo) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLOGIN\tROLE\tSTATUS\tSTORAGE\tDEVICES\tCREATED")
+	for _, u := range users {
+		status := "active"
+		if u.Disabled {
+			status = "disabled"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			u.ID, u.Login, u.Role, status, u.StorageUsed, u.Devices, u.CreatedAt.Format(time.DateOnly))
+	}
+	w.Flush()
+}
+
+func parseUserID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid user id %q: %s", s, adminUsage)
+	}
+	return id, nil
+}
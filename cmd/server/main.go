@@ -2,67 +2,54 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"gophkeeper/internal/app/server/api"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gophkeeper/internal/app/server"
 	"gophkeeper/internal/app/server/config"
-	"gophkeeper/internal/infrastructure/migration"
 	"gophkeeper/internal/utils/logger"
 	"gophkeeper/internal/utils/logger/sl"
-	"net/http"
-	"os"
-	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/exp/slog"
-
-	"github.com/danielgtaylor/huma/v2/humacli"
 )
 
 func main() {
 	cfg := config.MustLoad()
 	log := logger.New(cfg.Env)
-	pool, err := pgxpool.New(context.Background(), cfg.DB.DatabaseURI)
-	if err != nil {
-		log.Error("failed to init storage", sl.Err(err))
-		os.Exit(1)
-	}
-	defer pool.Close()
 
-	mg := migration.NewMigration(cfg, migration.DefaultEngine)
-	err = mg.Up()
-	if err != nil {
-		log.Error("failed to run migrations", sl.Err(err))
-		os.Exit(1)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, log, os.Args[2:]); err != nil {
+			log.Error("migration failed", sl.Err(err))
+			os.Exit(1)
+		}
+		return
 	}
 
-	log.Info("starting gophkeeper", slog.String("env", cfg.Env), slog.String("version", "1.0"))
-
-	router := api.New(pool, log)
-
-	cli := humacli.New(func(hooks humacli.Hooks, _ *struct{}) {
-		server := &http.Server{
-			Addr:    fmt.Sprintf(":%d", cfg.Server.RunPort),
-			Handler: router,
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		if err := runAdmin(cfg, log, os.Args[2:]); err != nil {
+			log.Error("admin command failed", sl.Err(err))
+			os.Exit(1)
 		}
+		return
+	}
 
-		hooks.OnStart(func() {
-			log.Info("server starting", slog.Int("port", cfg.Server.RunPort))
-			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				log.Error("server failed", sl.Err(err))
-			}
-		})
+	// SIGTERM (остановка контейнера, systemd) и Ctrl-C запускают плавную остановку
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		hooks.OnStop(func() {
-			log.Info("shutting down server gracefully")
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+	log.Info("starting gophkeeper", slog.String("env", cfg.Env), slog.String("version", "1.0"))
 
-			if err := server.Shutdown(ctx); err != nil {
-				log.Error("server forced shutdown", sl.Err(err))
-			}
-			log.Info("server stopped")
-		})
-	})
+	app, err := server.New(ctx, cfg, log)
+	if err != nil {
+		log.Error("failed to start server", sl.Err(err))
+		os.Exit(1)
+	}
+	defer app.Close()
 
-	cli.Run()
+	if err := app.Run(ctx); err != nil {
+		log.Error("server stopped with error", sl.Err(err))
+		app.Close()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/infrastructure/migration"
+
+	"golang.org/x/exp/slog"
+)
+
+const migrateUsage = "usage: gophkeeper migrate up | down [N] | version"
+
+// runMigrate выполняет команду `gophkeeper migrate ...`. Сервер применяет
+// миграции и сам при запуске; команда нужна, чтобы обновить схему до
+// выкладки новой версии или откатить ее.
+func runMigrate(cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	mg := migration.NewMigration(cfg, migration.DefaultEngine)
+	log = log.With(slog.String("source", mg.SourceURL()))
+
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+		if err := mg.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of steps %q: %s", args[1], migrateUsage)
+			}
+			steps = n
+		}
+		if err := mg.Down(steps); err != nil {
+			return err
+		}
+	case "version":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, dirty, err := mg.Version()
+	if err != nil {
+		return err
+	}
+	log.Info("schema version", slog.Uint64("version", uint64(version)), slog.Bool("dirty", dirty))
+	return nil
+}
@@ -0,0 +1,103 @@
+// internal/app/client/account.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// ErrDeletionNotScheduled - удаление учетной записи не запланировано
+var ErrDeletionNotScheduled = errors.New("удаление учетной записи не запланировано")
+
+// AccountDeletion - запланированное удаление учетной записи
+type AccountDeletion struct {
+	// DeleteAfter - после этого времени сервер удалит учетную запись и все данные
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// ScheduleAccountDeletion запрашивает удаление учетной записи. Сервер удаляет
+// ее после льготного периода; до этого вход работает и удаление можно отменить.
+func (a *App) ScheduleAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	deletion, err := a.httpClient.ScheduleAccountDeletion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса удаления: %w", err)
+	}
+
+	a.log.Info("Удаление учетной записи запланировано", "delete_after", deletion.DeleteAfter)
+	return deletion, nil
+}
+
+// AccountDeletion возвращает запланированное удаление или ErrDeletionNotScheduled
+func (a *App) AccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	deletion, err := a.httpClient.GetAccountDeletion(ctx)
+	if errors.Is(err, apperr.NotFound) {
+		return nil, ErrDeletionNotScheduled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения состояния удаления: %w", err)
+	}
+	return deletion, nil
+}
+
+// CancelAccountDeletion отменяет запланированное удаление учетной записи
+func (a *App) CancelAccountDeletion(ctx context.Context) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	err := a.httpClient.CancelAccountDeletion(ctx)
+	if errors.Is(err, apperr.NotFound) {
+		return ErrDeletionNotScheduled
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка отмены удаления: %w", err)
+	}
+
+	a.log.Info("Удаление учетной записи отменено")
+	return nil
+}
+
+// ExportAccount сохраняет в path zip-архив со всеми данными учетной записи.
+// Записи, вложения и файлы в архиве остаются зашифрованными мастер-ключом.
+// Архив пишется во временный файл и переименовывается только после полной
+// загрузки, чтобы оборванный экспорт не затер прежний.
+func (a *App) ExportAccount(ctx context.Context, path string) (int64, error) {
+	if !a.IsAuthenticated() {
+		return 0, ErrAuthRequired
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gophkeeper-export-*")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := a.httpClient.ExportAccount(ctx, tmp)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("ошибка записи файла: %w", closeErr)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка экспорта: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения архива: %w", err)
+	}
+
+	a.log.Info("Данные учетной записи выгружены", "path", path, "size", size)
+	return size, nil
+}
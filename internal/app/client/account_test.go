@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountServer хранит срок удаления учетной записи и отдает архив экспорта
+type accountServer struct {
+	deleteAfter *time.Time
+	archive     []byte
+}
+
+func (s *accountServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	notScheduled := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"detail": "account deletion is not scheduled"})
+	}
+
+	switch {
+	case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/account":
+		if s.deleteAfter == nil {
+			at := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+			s.deleteAfter = &at
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(AccountDeletion{DeleteAfter: *s.deleteAfter})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/account/deletion":
+		if s.deleteAfter == nil {
+			notScheduled()
+			return
+		}
+		_ = json.NewEncoder(w).Encode(AccountDeletion{DeleteAfter: *s.deleteAfter})
+	case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/account/deletion":
+		if s.deleteAfter == nil {
+			notScheduled()
+			return
+		}
+		s.deleteAfter = nil
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v1/account/export":
+		if s.archive == nil {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": "device is not trusted"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write(s.archive)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestApp_AccountDeletion(t *testing.T) {
+	ctx := context.Background()
+	srv := &accountServer{}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	app := newKeyFileTestApp(t, ts.URL)
+
+	_, err := app.AccountDeletion(ctx)
+	assert.ErrorIs(t, err, ErrDeletionNotScheduled)
+
+	deletion, err := app.ScheduleAccountDeletion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, *srv.deleteAfter, deletion.DeleteAfter)
+
+	status, err := app.AccountDeletion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, deletion.DeleteAfter, status.DeleteAfter)
+
+	require.NoError(t, app.CancelAccountDeletion(ctx))
+	assert.Nil(t, srv.deleteAfter)
+	assert.ErrorIs(t, app.CancelAccountDeletion(ctx), ErrDeletionNotScheduled)
+}
+
+func TestApp_ExportAccount(t *testing.T) {
+	ctx := context.Background()
+	srv := &accountServer{archive: []byte("PK\x03\x04 archive")}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	app := newKeyFileTestApp(t, ts.URL)
+
+	path := filepath.Join(t.TempDir(), "export.zip")
+	size, err := app.ExportAccount(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(srv.archive)), size)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, srv.archive, data)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Неудачный экспорт не затирает прежний архив
+	srv.archive = nil
+	_, err = app.ExportAccount(ctx, path)
+	assert.Error(t, err)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("PK\x03\x04 archive"), data)
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
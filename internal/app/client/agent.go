@@ -0,0 +1,285 @@
+// internal/app/client/agent.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/internal/app/client/ipc"
+)
+
+// Методы IPC агента
+const (
+	AgentMethodStatus = "status"
+	AgentMethodSync   = "sync"
+	AgentMethodUnlock = "unlock"
+	AgentMethodLock   = "lock"
+	AgentMethodSecret = "secret"
+	AgentMethodStop   = "stop"
+
+	AgentMethodBrowserLogins      = "browser_logins"
+	AgentMethodBrowserCredentials = "browser_credentials"
+	AgentMethodBrowserSave        = "browser_save"
+)
+
+// AgentBrowserParams - запрос логинов страницы от браузерного расширения
+type AgentBrowserParams struct {
+	URL      string `json:"url"`
+	RecordID int    `json:"record_id,omitempty"`
+	// Client - имя расширения для вопроса подтверждения
+	Client string `json:"client,omitempty"`
+}
+
+// AgentUnlockParams - параметры разблокировки мастер-ключа в агенте
+type AgentUnlockParams struct {
+	Password string `json:"password"`
+}
+
+// AgentSecretParams - запрос поля записи у агента
+type AgentSecretParams struct {
+	// Ref - ссылка gk://<запись>/<поле>
+	Ref string `json:"ref"`
+	// Client - имя запрашивающей программы для вопроса подтверждения
+	Client string `json:"client,omitempty"`
+}
+
+// AgentSecret - значение поля записи
+type AgentSecret struct {
+	Value string `json:"value"`
+}
+
+// AgentStatus состояние запущенного агента
+type AgentStatus struct {
+	PID               int       `json:"pid"`
+	Address           string    `json:"address"`
+	StartedAt         time.Time `json:"started_at"`
+	ServerAddress     string    `json:"server_address"`
+	Authenticated     bool      `json:"authenticated"`
+	MasterKeyUnlocked bool      `json:"master_key_unlocked"`
+	LastSync          time.Time `json:"last_sync,omitempty"`
+	SyncInterval      int       `json:"sync_interval_seconds"`
+	// HTTPAddress - адрес HTTP-API секретов, если он включен
+	HTTPAddress string `json:"http_address,omitempty"`
+	// AutoLock - блокировка мастер-ключа после простоя в секундах (0 - выключена)
+	AutoLock int `json:"auto_lock_seconds"`
+	// Confirm - каждая выдача секрета подтверждается пользователем
+	Confirm bool `json:"confirm"`
+}
+
+// AgentAddress возвращает адрес IPC агента: unix-сокет или именованный канал Windows
+func (a *App) AgentAddress() string {
+	return ipc.DefaultAddress(a.config.ConfigDir)
+}
+
+// serveAgent принимает запросы локальных инструментов до отмены контекста
+func (a *App) serveAgent(ctx context.Context) {
+	startedAt := time.Now()
+	server := ipc.NewServer(a.AgentAddress(), a.log)
+
+	server.Handle(AgentMethodStatus, func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		return a.agentStatus(startedAt), nil
+	})
+
+	server.Handle(AgentMethodSync, func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+		token, err := a.GetToken()
+		if err != nil {
+			return nil, err
+		}
+		a.httpClient.SetToken(token)
+
+		return a.syncService.Sync(ctx)
+	})
+
+	server.Handle(AgentMethodUnlock, func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var p AgentUnlockParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("неверные параметры: %w", err)
+		}
+		if err := a.UnlockMasterKey(p.Password); err != nil {
+			return nil, err
+		}
+		a.log.Info("Мастер-ключ разблокирован через агент")
+		return a.agentStatus(startedAt), nil
+	})
+
+	server.Handle(AgentMethodLock, func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		a.LockMasterKey()
+		a.log.Info("Мастер-ключ заблокирован через агент")
+		return a.agentStatus(startedAt), nil
+	})
+
+	server.Handle(AgentMethodSecret, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p AgentSecretParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("неверные параметры: %w", err)
+		}
+		return a.agentSecret(ctx, p)
+	})
+
+	server.Handle(AgentMethodBrowserLogins, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p AgentBrowserParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("неверные параметры: %w", err)
+		}
+		return a.BrowserLogins(ctx, p.URL)
+	})
+
+	server.Handle(AgentMethodBrowserCredentials, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p AgentBrowserParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("неверные параметры: %w", err)
+		}
+		return a.BrowserCredentials(ctx, p.URL, p.RecordID, p.Client)
+	})
+
+	server.Handle(AgentMethodBrowserSave, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var req BrowserSaveRequest
+		if err := json.Unmarshal(params, &req); err != nil {
+			return nil, fmt.Errorf("неверные параметры: %w", err)
+		}
+		id, err := a.SaveBrowserLogin(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"id": id}, nil
+	})
+
+	server.Handle(AgentMethodStop, func(_ context.Context, _ json.RawMessage) (interface{}, error) {
+		a.log.Info("Получена команда остановки агента")
+		// Ответ должен уйти клиенту до закрытия IPC
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			if a.cancel != nil {
+				a.cancel()
+			}
+		}()
+		return struct{}{}, nil
+	})
+
+	// Без IPC агент продолжает синхронизацию, недоступны только локальные запросы
+	if err := server.Serve(ctx); err != nil {
+		a.log.Error("IPC агента недоступен", "error", err)
+	}
+}
+
+func (a *App) agentStatus(startedAt time.Time) *AgentStatus {
+	status := &AgentStatus{
+		PID:               os.Getpid(),
+		Address:           a.AgentAddress(),
+		StartedAt:         startedAt,
+		ServerAddress:     a.config.ServerAddress,
+		Authenticated:     a.IsAuthenticated(),
+		MasterKeyUnlocked: a.IsMasterKeyUnlocked(),
+		SyncInterval:      a.config.SyncInterval,
+		HTTPAddress:       a.config.AgentHTTPAddr,
+		AutoLock:          int(a.crypto.IdleTimeout().Seconds()),
+		Confirm:           a.config.AgentConfirm,
+	}
+
+	if meta, err := a.syncService.loadSyncMetadata(); err == nil {
+		status.LastSync = meta.LastSyncTime
+	}
+
+	return status
+}
+
+// agentSecret выдает поле записи по ссылке после подтверждения пользователем
+func (a *App) agentSecret(ctx context.Context, p AgentSecretParams) (*AgentSecret, error) {
+	ref, err := ParseSecretRef(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if err := a.confirmSecretRequest(ctx, p.Client, ref.Record); err != nil {
+		return nil, err
+	}
+
+	value, err := a.ResolveSecretRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentSecret{Value: value}, nil
+}
+
+// GetAgentStatus запрашивает состояние запущенного агента
+func (a *App) GetAgentStatus(ctx context.Context) (*AgentStatus, error) {
+	var status AgentStatus
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodStatus, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// AgentSync просит запущенный агент выполнить синхронизацию
+func (a *App) AgentSync(ctx context.Context) (*SyncResult, error) {
+	var result SyncResult
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodSync, nil, &result); err != nil {
+		return nil, fmt.Errorf("ошибка синхронизации через агент: %w", err)
+	}
+	return &result, nil
+}
+
+// AgentUnlock разблокирует мастер-ключ в запущенном агенте
+func (a *App) AgentUnlock(ctx context.Context, password string) (*AgentStatus, error) {
+	var status AgentStatus
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodUnlock, AgentUnlockParams{Password: password}, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// AgentLock блокирует мастер-ключ в запущенном агенте
+func (a *App) AgentLock(ctx context.Context) error {
+	return ipc.Call(ctx, a.AgentAddress(), AgentMethodLock, nil, nil)
+}
+
+// AgentSecret запрашивает у агента поле записи по ссылке gk://<запись>/<поле>;
+// clientName показывается в вопросе подтверждения
+func (a *App) AgentSecret(ctx context.Context, ref, clientName string) (string, error) {
+	var secret AgentSecret
+	params := AgentSecretParams{Ref: ref, Client: clientName}
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodSecret, params, &secret); err != nil {
+		return "", err
+	}
+	return secret.Value, nil
+}
+
+// AgentStop просит запущенный агент завершиться
+func (a *App) AgentStop(ctx context.Context) error {
+	return ipc.Call(ctx, a.AgentAddress(), AgentMethodStop, nil, nil)
+}
+
+// AgentBrowserLogins запрашивает у агента логины, подходящие странице
+func (a *App) AgentBrowserLogins(ctx context.Context, pageURL string) ([]BrowserLogin, error) {
+	var logins []BrowserLogin
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodBrowserLogins, AgentBrowserParams{URL: pageURL}, &logins); err != nil {
+		return nil, err
+	}
+	return logins, nil
+}
+
+// AgentBrowserCredentials запрашивает у агента пароль логина для автозаполнения
+func (a *App) AgentBrowserCredentials(ctx context.Context, pageURL string, id int, clientName string) (*BrowserCredentials, error) {
+	var creds BrowserCredentials
+	params := AgentBrowserParams{URL: pageURL, RecordID: id, Client: clientName}
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodBrowserCredentials, params, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// AgentBrowserSave просит агент сохранить логин со страницы
+func (a *App) AgentBrowserSave(ctx context.Context, req BrowserSaveRequest) (int, error) {
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := ipc.Call(ctx, a.AgentAddress(), AgentMethodBrowserSave, req, &result); err != nil {
+		return 0, err
+	}
+	return result.ID, nil
+}
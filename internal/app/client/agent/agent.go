@@ -0,0 +1,200 @@
+// internal/app/client/agent/agent.go
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gophkeeper/internal/app/client/config"
+)
+
+const (
+	// ServiceName имя пользовательского сервиса systemd; у агента профиля
+	// добавляется суффикс -<профиль>
+	ServiceName = "gophkeeper-agent"
+	// LaunchdLabel метка агента launchd; у агента профиля - .<профиль>
+	LaunchdLabel = "com.gophkeeper.agent"
+
+	logFileName = "agent.log"
+)
+
+// ErrUnsupported установка сервиса не поддерживается на этой ОС
+var ErrUnsupported = errors.New("установка агента поддерживается только в Linux (systemd) и macOS (launchd)")
+
+// Spec описание запускаемого агента
+type Spec struct {
+	Executable string            // абсолютный путь к бинарнику gophkeeper
+	Args       []string          // аргументы, например agent run
+	WorkingDir string            // рабочая директория (директория конфигурации)
+	Env        map[string]string // окружение, с которым запускается агент
+	LogPath    string            // файл журнала (используется launchd)
+}
+
+// NewSpec создает описание агента из текущей конфигурации клиента.
+// Настройки передаются через окружение, потому что у сервиса нет
+// доступа к переменным и .env-файлу интерактивной оболочки.
+func NewSpec(cfg *config.Config, executable, configFile string) *Spec {
+	args := []string{"agent", "run"}
+	if configFile != "" {
+		args = append(args, "--config", configFile)
+	}
+	// Каталог профиля агент выводит из общего каталога сам
+	if cfg.Profile != "" {
+		args = append(args, "--profile", cfg.Profile)
+	}
+
+	env := map[string]string{
+		"APP_ENV":               cfg.Env,
+		"SERVER_ADDRESS":        cfg.ServerAddress,
+		"LOG_LEVEL":             cfg.LogLevel,
+		"CONFIG_DIR":            cfg.RootDir,
+		"MASTER_KEY_PATH":       cfg.MasterKeyPath,
+		"SYNC_INTERVAL_SECONDS": strconv.Itoa(cfg.SyncInterval),
+		"ENABLE_TLS":            strconv.FormatBool(cfg.EnableTLS),
+	}
+	if cfg.CACertPath != "" {
+		env["CA_CERT_PATH"] = cfg.CACertPath
+	}
+	if len(cfg.TLSPins) > 0 {
+		env["TLS_PINS"] = strings.Join(cfg.TLSPins, ",")
+	}
+	if cfg.TLSClientCert != "" {
+		env["TLS_CLIENT_CERT"] = cfg.TLSClientCert
+		env["TLS_CLIENT_KEY"] = cfg.TLSClientKey
+	}
+	if cfg.AgentHTTPAddr != "" {
+		env["AGENT_HTTP_ADDR"] = cfg.AgentHTTPAddr
+	}
+	if cfg.AgentConfirm {
+		env["AGENT_CONFIRM"] = "true"
+		env["AGENT_CONFIRM_COMMAND"] = cfg.AgentConfirmCommand
+	}
+	env["AUTO_LOCK"] = cfg.AutoLock.String()
+
+	return &Spec{
+		Executable: executable,
+		Args:       args,
+		WorkingDir: cfg.ConfigDir,
+		Env:        env,
+		LogPath:    filepath.Join(cfg.ConfigDir, logFileName),
+	}
+}
+
+// envKeys возвращает ключи окружения в стабильном порядке
+func (s *Spec) envKeys() []string {
+	keys := make([]string, 0, len(s.Env))
+	for k := range s.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Installer устанавливает агент как пользовательский сервис ОС
+type Installer interface {
+	// Install записывает файл сервиса, включает автозапуск и запускает агент
+	Install(spec *Spec) error
+	// Uninstall останавливает агент и удаляет файл сервиса
+	Uninstall() error
+	// Path возвращает путь к файлу сервиса
+	Path() string
+}
+
+// NewInstaller возвращает установщик для текущей ОС. Агенты профилей
+// устанавливаются отдельными сервисами и работают одновременно.
+func NewInstaller(profile string) (Installer, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось определить домашнюю директорию: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		return newSystemdInstaller(home, profile, runCommand), nil
+	case "darwin":
+		return newLaunchdInstaller(home, profile, os.Getuid(), runCommand), nil
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// serviceName возвращает имя сервиса systemd агента профиля
+func serviceName(profile string) string {
+	if profile == "" {
+		return ServiceName
+	}
+	return ServiceName + "-" + profile
+}
+
+// launchdLabel возвращает метку launchd агента профиля
+func launchdLabel(profile string) string {
+	if profile == "" {
+		return LaunchdLabel
+	}
+	return LaunchdLabel + "." + profile
+}
+
+// commandRunner выполняет системную команду (systemctl, launchctl)
+type commandRunner func(name string, args ...string) error
+
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// writeServiceFile атомарно записывает файл сервиса
+func writeServiceFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории %s: %w", filepath.Dir(path), err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("ошибка записи %s: %w", path, err)
+	}
+	return nil
+}
+
+// StartDetached запускает агент фоновым процессом, отвязанным от терминала.
+// Вывод агента дописывается в spec.LogPath. Окружение текущего процесса
+// сохраняется, значения из spec.Env его дополняют.
+func StartDetached(spec *Spec) (int, error) {
+	logFile, err := os.OpenFile(spec.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка открытия журнала агента: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(spec.Executable, spec.Args...)
+	cmd.Dir = spec.WorkingDir
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = os.Environ()
+	for _, k := range spec.envKeys() {
+		cmd.Env = append(cmd.Env, k+"="+spec.Env[k])
+	}
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("ошибка запуска агента: %w", err)
+	}
+	pid := cmd.Process.Pid
+	// Агент живет дольше команды start, ждать его не нужно
+	_ = cmd.Process.Release()
+
+	return pid, nil
+}
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/config"
+)
+
+// recorder запоминает системные команды вместо их выполнения
+type recorder struct {
+	commands []string
+	fail     map[string]error
+}
+
+func (r *recorder) run(name string, args ...string) error {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	r.commands = append(r.commands, cmd)
+	return r.fail[cmd]
+}
+
+func testSpec() *Spec {
+	return &Spec{
+		Executable: "/opt/Goph Keeper/bin/gophkeeper",
+		Args:       []string{"agent", "run", "--profile", "work"},
+		WorkingDir: "/home/alice/.gophkeeper",
+		Env: map[string]string{
+			"SERVER_ADDRESS":        "https://keeper.example.com",
+			"AGENT_CONFIRM_COMMAND": `zenity --question --text "100% $USER"`,
+		},
+		LogPath: "/home/alice/.gophkeeper/agent.log",
+	}
+}
+
+func TestNewSpec(t *testing.T) {
+	cfg := &config.Config{
+		ServerAddress: "https://keeper.example.com",
+		RootDir:       "/home/alice/.gophkeeper",
+		ConfigDir:     "/home/alice/.gophkeeper/profiles/work",
+		MasterKeyPath: "/home/alice/.gophkeeper/profiles/work/master.key",
+		SyncInterval:  300,
+		Profile:       "work",
+		AutoLock:      15 * time.Minute,
+	}
+
+	spec := NewSpec(cfg, "/usr/local/bin/gophkeeper", "/etc/gophkeeper.yaml")
+	assert.Equal(t, []string{"agent", "run", "--config", "/etc/gophkeeper.yaml", "--profile", "work"}, spec.Args)
+	assert.Equal(t, cfg.ConfigDir, spec.WorkingDir)
+	assert.Equal(t, filepath.Join(cfg.ConfigDir, "agent.log"), spec.LogPath)
+	// Каталог профиля агент выводит из общего каталога
+	assert.Equal(t, cfg.RootDir, spec.Env["CONFIG_DIR"])
+	assert.Equal(t, "300", spec.Env["SYNC_INTERVAL_SECONDS"])
+	assert.Equal(t, "15m0s", spec.Env["AUTO_LOCK"])
+	assert.NotContains(t, spec.Env, "AGENT_HTTP_ADDR")
+	assert.NotContains(t, spec.Env, "TLS_CLIENT_KEY")
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	want := `[Unit]
+Description=GophKeeper background sync agent
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart="/opt/Goph Keeper/bin/gophkeeper" "agent" "run" "--profile" "work"
+WorkingDirectory=/home/alice/.gophkeeper
+Environment="AGENT_CONFIRM_COMMAND=zenity --question --text \"100%% $USER\""
+Environment="SERVER_ADDRESS=https://keeper.example.com"
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`
+	assert.Equal(t, want, renderSystemdUnit(testSpec()))
+}
+
+func TestSystemdQuote(t *testing.T) {
+	assert.Equal(t, `"a b"`, systemdQuote("a b", true))
+	assert.Equal(t, `"\\ \" %% $$HOME"`, systemdQuote(`\ " % $HOME`, true))
+	// В Environment= переменные не подставляются, $ остается как есть
+	assert.Equal(t, `"$HOME"`, systemdQuote("$HOME", false))
+}
+
+// decodePlist разбирает словарь plist: строки, логические значения, массивы и словари
+func decodePlist(t *testing.T, data []byte) map[string]interface{} {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var parse func(start xml.StartElement) interface{}
+	parse = func(start xml.StartElement) interface{} {
+		switch start.Name.Local {
+		case "true", "false":
+			require.NoError(t, dec.Skip())
+			return start.Name.Local == "true"
+		case "string":
+			var s string
+			require.NoError(t, dec.DecodeElement(&s, &start))
+			return s
+		}
+
+		var list []interface{}
+		dict := make(map[string]interface{})
+		key := ""
+		for {
+			tok, err := dec.Token()
+			require.NoError(t, err)
+			switch tok := tok.(type) {
+			case xml.StartElement:
+				if tok.Name.Local == "key" {
+					require.NoError(t, dec.DecodeElement(&key, &tok))
+					continue
+				}
+				value := parse(tok)
+				if start.Name.Local == "array" {
+					list = append(list, value)
+				} else {
+					dict[key] = value
+				}
+			case xml.EndElement:
+				if start.Name.Local == "array" {
+					return list
+				}
+				return dict
+			}
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			t.Fatal("в plist нет словаря")
+		}
+		require.NoError(t, err)
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "dict" {
+			return parse(start).(map[string]interface{})
+		}
+	}
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	spec := testSpec()
+	spec.Env["TLS_PINS"] = "sha256/<pin>&more"
+
+	data, err := renderLaunchdPlist("com.gophkeeper.agent.work", spec)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte(xml.Header+`<!DOCTYPE plist`)))
+
+	plist := decodePlist(t, data)
+	assert.Equal(t, map[string]interface{}{
+		"Label":            "com.gophkeeper.agent.work",
+		"ProgramArguments": []interface{}{"/opt/Goph Keeper/bin/gophkeeper", "agent", "run", "--profile", "work"},
+		"WorkingDirectory": "/home/alice/.gophkeeper",
+		"EnvironmentVariables": map[string]interface{}{
+			"AGENT_CONFIRM_COMMAND": `zenity --question --text "100% $USER"`,
+			"SERVER_ADDRESS":        "https://keeper.example.com",
+			"TLS_PINS":              "sha256/<pin>&more",
+		},
+		"RunAtLoad":         true,
+		"KeepAlive":         map[string]interface{}{"SuccessfulExit": false},
+		"StandardOutPath":   "/home/alice/.gophkeeper/agent.log",
+		"StandardErrorPath": "/home/alice/.gophkeeper/agent.log",
+	}, plist)
+}
+
+func TestSystemdInstaller(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	r := &recorder{}
+
+	i := newSystemdInstaller(home, "work", r.run)
+	assert.Equal(t, filepath.Join(home, ".config", "systemd", "user", "gophkeeper-agent-work.service"), i.Path())
+
+	require.NoError(t, i.Install(testSpec()))
+	unit, err := os.ReadFile(i.Path())
+	require.NoError(t, err)
+	assert.Equal(t, renderSystemdUnit(testSpec()), string(unit))
+	assert.Equal(t, []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable gophkeeper-agent-work.service",
+		"systemctl --user restart gophkeeper-agent-work.service",
+	}, r.commands)
+
+	r.commands = nil
+	r.fail = map[string]error{"systemctl --user disable --now gophkeeper-agent-work.service": errors.New("not loaded")}
+	require.NoError(t, i.Uninstall())
+	assert.NoFileExists(t, i.Path())
+	assert.Equal(t, []string{
+		"systemctl --user disable --now gophkeeper-agent-work.service",
+		"systemctl --user daemon-reload",
+	}, r.commands)
+
+	assert.ErrorContains(t, i.Uninstall(), "агент не установлен")
+
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	assert.Equal(t, filepath.Join(home, "xdg", "systemd", "user", "gophkeeper-agent.service"), newSystemdInstaller(home, "", r.run).Path())
+}
+
+func TestLaunchdInstaller(t *testing.T) {
+	home := t.TempDir()
+	r := &recorder{}
+
+	i := newLaunchdInstaller(home, "", 501, r.run)
+	assert.Equal(t, filepath.Join(home, "Library", "LaunchAgents", "com.gophkeeper.agent.plist"), i.Path())
+
+	require.NoError(t, i.Install(testSpec()))
+	plist, err := os.ReadFile(i.Path())
+	require.NoError(t, err)
+	want, err := renderLaunchdPlist("com.gophkeeper.agent", testSpec())
+	require.NoError(t, err)
+	assert.Equal(t, want, plist)
+	assert.Equal(t, []string{
+		"launchctl bootout gui/501/com.gophkeeper.agent",
+		"launchctl bootstrap gui/501 " + i.Path(),
+	}, r.commands)
+
+	r.commands = nil
+	require.NoError(t, i.Uninstall())
+	assert.NoFileExists(t, i.Path())
+	assert.Equal(t, []string{"launchctl bootout gui/501/com.gophkeeper.agent"}, r.commands)
+}
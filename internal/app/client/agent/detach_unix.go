@@ -0,0 +1,14 @@
+// internal/app/client/agent/detach_unix.go
+//go:build !windows
+
+package agent
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach запускает процесс в новой сессии, чтобы закрытие терминала его не завершало
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,20 @@
+// internal/app/client/agent/detach_windows.go
+//go:build windows
+
+package agent
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const (
+	detachedProcess       = 0x00000008
+	createNewProcessGroup = 0x00000200
+)
+
+// detach запускает процесс без консоли и в отдельной группе, чтобы Ctrl-C
+// в терминале его не завершал
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess | createNewProcessGroup}
+}
@@ -0,0 +1,129 @@
+// internal/app/client/agent/launchd.go
+package agent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// launchdInstaller управляет агентом launchd в домене пользователя (gui/<uid>)
+type launchdInstaller struct {
+	path   string
+	label  string
+	domain string
+	run    commandRunner
+}
+
+func newLaunchdInstaller(home, profile string, uid int, run commandRunner) *launchdInstaller {
+	label := launchdLabel(profile)
+	return &launchdInstaller{
+		path:   filepath.Join(home, "Library", "LaunchAgents", label+".plist"),
+		label:  label,
+		domain: "gui/" + strconv.Itoa(uid),
+		run:    run,
+	}
+}
+
+func (i *launchdInstaller) Path() string {
+	return i.path
+}
+
+func (i *launchdInstaller) Install(spec *Spec) error {
+	plist, err := renderLaunchdPlist(i.label, spec)
+	if err != nil {
+		return err
+	}
+
+	// При переустановке агент нужно выгрузить, иначе bootstrap вернет ошибку
+	_ = i.run("launchctl", "bootout", i.domain+"/"+i.label)
+
+	if err := writeServiceFile(i.path, plist); err != nil {
+		return err
+	}
+
+	return i.run("launchctl", "bootstrap", i.domain, i.path)
+}
+
+func (i *launchdInstaller) Uninstall() error {
+	if _, err := os.Stat(i.path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("агент не установлен: %s не найден", i.path)
+	}
+
+	_ = i.run("launchctl", "bootout", i.domain+"/"+i.label)
+
+	if err := os.Remove(i.path); err != nil {
+		return fmt.Errorf("ошибка удаления %s: %w", i.path, err)
+	}
+	return nil
+}
+
+func renderLaunchdPlist(label string, spec *Spec) ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n<dict>\n")
+
+	writeKey := func(key string) {
+		b.WriteString("\t<key>")
+		_ = xml.EscapeText(&b, []byte(key))
+		b.WriteString("</key>\n")
+	}
+	writeString := func(indent, value string) {
+		b.WriteString(indent + "<string>")
+		_ = xml.EscapeText(&b, []byte(value))
+		b.WriteString("</string>\n")
+	}
+
+	writeKey("Label")
+	writeString("\t", label)
+
+	writeKey("ProgramArguments")
+	b.WriteString("\t<array>\n")
+	writeString("\t\t", spec.Executable)
+	for _, arg := range spec.Args {
+		writeString("\t\t", arg)
+	}
+	b.WriteString("\t</array>\n")
+
+	if spec.WorkingDir != "" {
+		writeKey("WorkingDirectory")
+		writeString("\t", spec.WorkingDir)
+	}
+
+	if len(spec.Env) > 0 {
+		writeKey("EnvironmentVariables")
+		b.WriteString("\t<dict>\n")
+		for _, key := range spec.envKeys() {
+			b.WriteString("\t\t<key>")
+			if err := xml.EscapeText(&b, []byte(key)); err != nil {
+				return nil, err
+			}
+			b.WriteString("</key>\n")
+			writeString("\t\t", spec.Env[key])
+		}
+		b.WriteString("\t</dict>\n")
+	}
+
+	writeKey("RunAtLoad")
+	b.WriteString("\t<true/>\n")
+
+	// Перезапуск только при аварийном завершении, не после штатной остановки
+	writeKey("KeepAlive")
+	b.WriteString("\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+
+	if spec.LogPath != "" {
+		writeKey("StandardOutPath")
+		writeString("\t", spec.LogPath)
+		writeKey("StandardErrorPath")
+		writeString("\t", spec.LogPath)
+	}
+
+	b.WriteString("</dict>\n</plist>\n")
+	return b.Bytes(), nil
+}
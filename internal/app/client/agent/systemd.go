@@ -0,0 +1,108 @@
+// internal/app/client/agent/systemd.go
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemdInstaller управляет пользовательским юнитом systemd (systemctl --user)
+type systemdInstaller struct {
+	path string
+	unit string
+	run  commandRunner
+}
+
+func newSystemdInstaller(home, profile string, run commandRunner) *systemdInstaller {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	unit := serviceName(profile) + ".service"
+	return &systemdInstaller{
+		path: filepath.Join(configHome, "systemd", "user", unit),
+		unit: unit,
+		run:  run,
+	}
+}
+
+func (i *systemdInstaller) Path() string {
+	return i.path
+}
+
+func (i *systemdInstaller) Install(spec *Spec) error {
+	if err := writeServiceFile(i.path, []byte(renderSystemdUnit(spec))); err != nil {
+		return err
+	}
+
+	if err := i.run("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	if err := i.run("systemctl", "--user", "enable", i.unit); err != nil {
+		return err
+	}
+	// restart, а не start: при переустановке агент подхватит новый юнит
+	return i.run("systemctl", "--user", "restart", i.unit)
+}
+
+func (i *systemdInstaller) Uninstall() error {
+	if _, err := os.Stat(i.path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("агент не установлен: %s не найден", i.path)
+	}
+
+	// Юнит мог быть уже остановлен вручную - это не ошибка
+	_ = i.run("systemctl", "--user", "disable", "--now", i.unit)
+
+	if err := os.Remove(i.path); err != nil {
+		return fmt.Errorf("ошибка удаления %s: %w", i.path, err)
+	}
+
+	return i.run("systemctl", "--user", "daemon-reload")
+}
+
+func renderSystemdUnit(spec *Spec) string {
+	var b strings.Builder
+
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=GophKeeper background sync agent\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=simple\n")
+
+	exec := []string{systemdQuote(spec.Executable, true)}
+	for _, arg := range spec.Args {
+		exec = append(exec, systemdQuote(arg, true))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(exec, " "))
+
+	if spec.WorkingDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", spec.WorkingDir)
+	}
+	for _, key := range spec.envKeys() {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(key+"="+spec.Env[key], false))
+	}
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=10\n\n")
+
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+
+	return b.String()
+}
+
+// systemdQuote заключает значение в кавычки по правилам systemd.unit,
+// экранируя обратный слеш, кавычки и спецификаторы %. В ExecStart
+// дополнительно экранируется $, иначе systemd подставит переменную.
+func systemdQuote(s string, exec bool) string {
+	pairs := []string{`\`, `\\`, `"`, `\"`, "%", "%%"}
+	if exec {
+		pairs = append(pairs, "$", "$$")
+	}
+	return `"` + strings.NewReplacer(pairs...).Replace(s) + `"`
+}
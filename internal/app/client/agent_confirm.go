@@ -0,0 +1,81 @@
+// internal/app/client/agent_confirm.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// agentConfirmTimeout - сколько ждать ответа пользователя на запрос секрета
+const agentConfirmTimeout = time.Minute
+
+// ErrAgentRequestDenied - пользователь не подтвердил запрос к агенту
+var ErrAgentRequestDenied = apperr.New(apperr.Forbidden, "запрос отклонен")
+
+// confirmSecretRequest спрашивает пользователя, выдать ли секрет записи
+// клиенту агента. Без AGENT_CONFIRM запросы разрешены.
+func (a *App) confirmSecretRequest(ctx context.Context, client, record string) error {
+	if !a.config.AgentConfirm {
+		return nil
+	}
+
+	if client == "" {
+		client = "неизвестный клиент"
+	}
+	return a.askApproval(ctx, fmt.Sprintf("GophKeeper: выдать %s секрет %s?", client, record))
+}
+
+// askApproval задает вопрос пользователю программой AGENT_CONFIRM_COMMAND.
+// Программа получает вопрос последним аргументом; код выхода 0 разрешает
+// действие. Если программа не задана, спросить некого и действие отклоняется.
+// Одновременные вопросы задаются по очереди.
+func (a *App) askApproval(ctx context.Context, question string) error {
+	fields := strings.Fields(a.config.AgentConfirmCommand)
+	if len(fields) == 0 {
+		a.log.Warn("Запрос отклонен: программа подтверждения не задана", "question", question)
+		return fmt.Errorf("%w: не задана программа подтверждения (gophkeeper config set agent-confirm-command ...)", ErrAgentRequestDenied)
+	}
+
+	a.confirmMu.Lock()
+	defer a.confirmMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, agentConfirmTimeout)
+	defer cancel()
+
+	args := append(fields[1:], question)
+	err := exec.CommandContext(ctx, fields[0], args...).Run()
+	if err == nil {
+		a.log.Info("Запрос подтвержден", "question", question)
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		a.log.Error("Программа подтверждения не запустилась", "command", fields[0], "error", err)
+	}
+	a.log.Warn("Запрос отклонен", "question", question)
+	return ErrAgentRequestDenied
+}
+
+// confirmingVault запрашивает подтверждение перед выдачей полей записи
+// через HTTP-API секретов агента
+type confirmingVault struct {
+	app *App
+}
+
+func (v confirmingVault) IsMasterKeyUnlocked() bool {
+	return v.app.IsMasterKeyUnlocked()
+}
+
+func (v confirmingVault) SecretFields(ctx context.Context, record string) (map[string]string, error) {
+	if err := v.app.confirmSecretRequest(ctx, "HTTP-API", record); err != nil {
+		return nil, err
+	}
+	return v.app.SecretFields(ctx, record)
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+func TestApp_ConfirmSecretRequest(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("нет утилит true/false")
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		confirm bool
+		command string
+		allowed bool
+	}{
+		{"confirmation off", false, "false", true},
+		{"no command denies", true, "", false},
+		{"command allows", true, "true", true},
+		{"command denies", true, "false", false},
+		{"missing command denies", true, "gophkeeper-no-such-confirm", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			app.config.AgentConfirm = tt.confirm
+			app.config.AgentConfirmCommand = tt.command
+
+			err := app.confirmSecretRequest(ctx, "test", "Postgres")
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrAgentRequestDenied)
+			assert.Equal(t, apperr.Forbidden, apperr.KindOf(err))
+		})
+	}
+}
+
+func TestApp_AgentSecret_Locked(t *testing.T) {
+	app := newTestApp(t)
+
+	_, err := app.agentSecret(context.Background(), AgentSecretParams{Ref: "gk://Postgres/password"})
+	assert.ErrorIs(t, err, ErrMasterKeyLocked)
+
+	_, err = app.agentSecret(context.Background(), AgentSecretParams{Ref: "Postgres"})
+	assert.ErrorIs(t, err, ErrInvalidSecretRef)
+}
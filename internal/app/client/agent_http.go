@@ -0,0 +1,95 @@
+// internal/app/client/agent_http.go
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/client/secretapi"
+)
+
+// agentTokenFile - токен HTTP-API секретов агента в директории конфигурации.
+// Файл не шифруется: его читают сторонние инструменты, доступ ограничен правами 0600.
+const agentTokenFile = "agent_token"
+
+// agentHTTPShutdownTimeout - сколько ждать завершения начатых запросов при остановке агента
+const agentHTTPShutdownTimeout = 5 * time.Second
+
+// AgentHTTPToken возвращает токен HTTP-API секретов агента, создавая его при первом вызове
+func (a *App) AgentHTTPToken() (string, error) {
+	path := filepath.Join(a.config.ConfigDir, agentTokenFile)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("ошибка чтения токена агента: %w", err)
+	}
+
+	return a.RotateAgentHTTPToken()
+}
+
+// RotateAgentHTTPToken создает новый токен HTTP-API агента. Запущенный агент
+// продолжает принимать прежний токен до перезапуска.
+func (a *App) RotateAgentHTTPToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("ошибка генерации токена агента: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	path := filepath.Join(a.config.ConfigDir, agentTokenFile)
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("ошибка сохранения токена агента: %w", err)
+	}
+	return token, nil
+}
+
+// serveAgentHTTP обслуживает HTTP-API секретов на AGENT_HTTP_ADDR до отмены
+// контекста. Без адреса в конфигурации API выключен.
+func (a *App) serveAgentHTTP(ctx context.Context) {
+	addr := a.config.AgentHTTPAddr
+	if addr == "" {
+		return
+	}
+
+	token, err := a.AgentHTTPToken()
+	if err != nil {
+		a.log.Error("HTTP-API секретов агента недоступен", "error", err)
+		return
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		a.log.Error("HTTP-API секретов агента недоступен", "addr", addr, "error", err)
+		return
+	}
+
+	srv := &http.Server{
+		Handler:           secretapi.NewHandler(confirmingVault{app: a}, token, a.log),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), agentHTTPShutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	a.log.Info("HTTP-API секретов агента запущен", "addr", listener.Addr().String())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		a.log.Error("HTTP-API секретов агента остановлен с ошибкой", "error", err)
+	}
+}
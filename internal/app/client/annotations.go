@@ -0,0 +1,100 @@
+// internal/app/client/annotations.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+)
+
+// Пометки к записи («пароль сменен после инцидента X») хранятся в метаданных
+// отдельно от секрета: текст каждой пометки зашифрован мастер-ключом, а список
+// только дополняется. Пометки сохраняются при восстановлении старой версии
+// и объединяются при слиянии конфликтов синхронизации.
+
+// maxAnnotationLength - предельная длина текста пометки
+const maxAnnotationLength = 2000
+
+// ErrEmptyAnnotation - пустой текст пометки
+var ErrEmptyAnnotation = apperr.New(apperr.Invalid, "текст пометки пуст")
+
+// RecordAnnotation - расшифрованная пометка к записи
+type RecordAnnotation struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+// AnnotateRecord добавляет к записи пометку с текущим временем. Пометку
+// можно добавить и к защищенной записи: данные и метаданные записи не меняются.
+func (a *App) AnnotateRecord(ctx context.Context, id int, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrEmptyAnnotation
+	}
+	if len(text) > maxAnnotationLength {
+		return apperr.New(apperr.Invalid, fmt.Sprintf("пометка длиннее %d байт", maxAnnotationLength))
+	}
+
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return err
+	}
+	if rec.DeletedAt != nil {
+		return fmt.Errorf("%w: запись в корзине", ErrRecordNotFound)
+	}
+
+	encrypted, err := a.encryptRecordData(text)
+	if err != nil {
+		return err
+	}
+
+	meta, err := record.AddAnnotation(rec.Meta, record.Annotation{At: time.Now().UTC(), Data: encrypted})
+	if err != nil {
+		return fmt.Errorf("ошибка изменения метаданных записи: %w", err)
+	}
+
+	return a.updateRecord(ctx, rec, GenericRecordRequest{
+		Type: rec.Type,
+		Data: rec.EncryptedData,
+		Meta: meta,
+	})
+}
+
+// RecordAnnotations расшифровывает пометки записи в порядке добавления
+func (a *App) RecordAnnotations(rec *LocalRecord) ([]RecordAnnotation, error) {
+	annotations := record.Annotations(rec.Meta)
+	out := make([]RecordAnnotation, 0, len(annotations))
+	for _, an := range annotations {
+		var text string
+		if err := a.decryptRecordData(an.Data, &text); err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки пометки от %s: %w", an.At.Format(time.RFC3339), err)
+		}
+		out = append(out, RecordAnnotation{At: an.At, Text: text})
+	}
+	return out, nil
+}
+
+// keepAnnotations переносит в метаданные meta пометки текущей версии current,
+// чтобы восстановление старой версии не теряло пометки, добавленные позже
+func keepAnnotations(current, meta json.RawMessage) (json.RawMessage, error) {
+	kept := record.MergeAnnotations(record.Annotations(meta), record.Annotations(current))
+	if len(kept) == 0 {
+		return meta, nil
+	}
+	return record.WithAnnotations(meta, kept)
+}
+
+// withoutAnnotations возвращает метаданные без пометок: при слиянии конфликта
+// пометки объединяются отдельно и не считаются изменением одного поля
+func withoutAnnotations(meta json.RawMessage) json.RawMessage {
+	stripped, err := record.WithAnnotations(meta, nil)
+	if err != nil {
+		return meta
+	}
+	return stripped
+}
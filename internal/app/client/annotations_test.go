@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_AnnotateRecord(t *testing.T) {
+	ctx := context.Background()
+	app := newUndoTestApp(t)
+
+	id, err := app.saveLocalRecord(ctx, record.RecTypeText, CreateTextRequest{Title: "root", Content: "secret"})
+	require.NoError(t, err)
+	require.NoError(t, app.LockRecord(ctx, id))
+	before, err := app.storage.GetRecord(id)
+	require.NoError(t, err)
+
+	// Пометка добавляется и к защищенной записи, данные не меняются
+	require.NoError(t, app.AnnotateRecord(ctx, id, "  rotated after incident X  "))
+	require.NoError(t, app.AnnotateRecord(ctx, id, "checked"))
+	assert.ErrorIs(t, app.AnnotateRecord(ctx, id, " "), ErrEmptyAnnotation)
+
+	rec, err := app.storage.GetRecord(id)
+	require.NoError(t, err)
+	assert.Equal(t, before.EncryptedData, rec.EncryptedData)
+	assert.True(t, record.IsLocked(rec.Meta))
+	assert.NotContains(t, string(rec.Meta), "incident", "текст пометки зашифрован")
+
+	annotations, err := app.RecordAnnotations(rec)
+	require.NoError(t, err)
+	require.Len(t, annotations, 2)
+	assert.Equal(t, "rotated after incident X", annotations[0].Text)
+	assert.Equal(t, "checked", annotations[1].Text)
+	assert.WithinDuration(t, time.Now(), annotations[0].At, time.Minute)
+}
+
+func TestKeepAnnotations(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	old, err := record.AddAnnotation(json.RawMessage(`{"title":"v1"}`), record.Annotation{At: at, Data: "a"})
+	require.NoError(t, err)
+	current, err := record.AddAnnotation(json.RawMessage(`{"title":"v2"}`), record.Annotation{At: at, Data: "a"})
+	require.NoError(t, err)
+	current, err = record.AddAnnotation(current, record.Annotation{At: at.Add(time.Hour), Data: "b"})
+	require.NoError(t, err)
+
+	// Восстановленная версия получает пометки, добавленные после нее
+	restored, err := keepAnnotations(current, old)
+	require.NoError(t, err)
+	assert.Equal(t, record.Annotations(current), record.Annotations(restored))
+	assert.Contains(t, string(restored), `"title":"v1"`)
+
+	plain := json.RawMessage(`{"title":"v1"}`)
+	kept, err := keepAnnotations(json.RawMessage(`{}`), plain)
+	require.NoError(t, err)
+	assert.Equal(t, plain, kept)
+	assert.JSONEq(t, `{"title":"v2"}`, string(withoutAnnotations(current)))
+}
@@ -0,0 +1,232 @@
+// internal/app/client/askpass.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// askpass: ssh (SSH_ASKPASS) и git (GIT_ASKPASS) запускают программу с текстом
+// вопроса и читают ответ из stdout. Понятные вопросы - парольная фраза ключа,
+// пароль ssh и имя пользователя или пароль git по https. На остальные вопросы
+// (например, подтверждение ключа хоста) ответа нет: ssh считает это отказом.
+
+// AskpassKind - о чем спрашивает программа
+type AskpassKind string
+
+const (
+	AskpassPassphrase AskpassKind = "passphrase"
+	AskpassPassword   AskpassKind = "password"
+	AskpassUsername   AskpassKind = "username"
+)
+
+// ErrUnsupportedPrompt - вопрос askpass, на который GophKeeper не отвечает
+var ErrUnsupportedPrompt = apperr.New(apperr.Invalid, "вопрос askpass не поддерживается")
+
+// AskpassPrompt - разобранный вопрос askpass
+type AskpassPrompt struct {
+	Kind AskpassKind
+	// KeyPath - файл ключа, парольную фразу которого спрашивает ssh
+	KeyPath string
+	// URL - адрес для логина: https://host для git, ssh://host для ssh
+	URL      string
+	Username string
+}
+
+var (
+	askpassPassphraseRe  = regexp.MustCompile(`^Enter passphrase for (?:key )?'([^']+)'`)
+	askpassSSHPasswordRe = regexp.MustCompile(`^([^@\s']+)@([^\s']+?)'s password:`)
+	askpassGitRe         = regexp.MustCompile(`^(Username|Password) for '([^']+)':`)
+)
+
+// ParseAskpassPrompt разбирает вопрос ssh или git
+func ParseAskpassPrompt(prompt string) (AskpassPrompt, error) {
+	prompt = strings.TrimSpace(prompt)
+
+	if m := askpassPassphraseRe.FindStringSubmatch(prompt); m != nil {
+		return AskpassPrompt{Kind: AskpassPassphrase, KeyPath: m[1]}, nil
+	}
+	if m := askpassSSHPasswordRe.FindStringSubmatch(prompt); m != nil {
+		return AskpassPrompt{Kind: AskpassPassword, URL: "ssh://" + m[2], Username: m[1]}, nil
+	}
+	if m := askpassGitRe.FindStringSubmatch(prompt); m != nil {
+		u, err := url.Parse(m[2])
+		if err != nil || u.Host == "" {
+			return AskpassPrompt{}, fmt.Errorf("%w: %q", ErrUnsupportedPrompt, prompt)
+		}
+		p := AskpassPrompt{Kind: AskpassPassword, URL: u.Scheme + "://" + u.Host}
+		if m[1] == "Username" {
+			p.Kind = AskpassUsername
+		}
+		if u.User != nil {
+			p.Username = u.User.Username()
+		}
+		return p, nil
+	}
+
+	return AskpassPrompt{}, fmt.Errorf("%w: %q", ErrUnsupportedPrompt, prompt)
+}
+
+// Askpass отвечает на вопрос ssh или git секретом из хранилища
+func (a *App) Askpass(ctx context.Context, question string) (string, error) {
+	prompt, err := ParseAskpassPrompt(question)
+	if err != nil {
+		return "", err
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return "", ErrMasterKeyLocked
+	}
+
+	switch prompt.Kind {
+	case AskpassPassphrase:
+		return a.keyPassphrase(ctx, prompt.KeyPath)
+	case AskpassUsername:
+		login, err := a.askpassLogin(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+		return login.Username, nil
+	default:
+		login, err := a.askpassLogin(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+		return login.Password, nil
+	}
+}
+
+// askpassLogin ищет логин для адреса из вопроса. Для git по https годится
+// тот же логин, что и для браузера; для ssh хост ресурса должен совпадать
+// с хостом сервера, а имя пользователя - с именем из вопроса.
+func (a *App) askpassLogin(ctx context.Context, prompt AskpassPrompt) (*record.LoginData, error) {
+	scheme, host, _ := strings.Cut(prompt.URL, "://")
+	if scheme != "ssh" {
+		logins, err := a.gitLogins(ctx, &GitCredential{Protocol: scheme, Host: host, Username: prompt.Username})
+		if err != nil {
+			return nil, err
+		}
+		if len(logins) == 0 {
+			return nil, fmt.Errorf("%w: нет логина для %s", ErrRecordNotFound, prompt.URL)
+		}
+		return &logins[0].data, nil
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeLogin})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, rec := range records {
+		var meta record.LoginMeta
+		if len(rec.Meta) == 0 || json.Unmarshal(rec.Meta, &meta) != nil || !matchHost(meta.Resource, host) {
+			continue
+		}
+		var data record.LoginData
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			a.log.Warn("Не удалось расшифровать логин", "record_id", rec.ID, "error", err)
+			continue
+		}
+		if data.Username == prompt.Username {
+			return &data, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: нет логина %s для %s", ErrRecordNotFound, prompt.Username, host)
+}
+
+// keyPassphrase находит SSH-ключ по отпечатку ключа из файла path и
+// возвращает его парольную фразу
+func (a *App) keyPassphrase(ctx context.Context, path string) (string, error) {
+	fingerprint, err := keyFileFingerprint(path)
+	if err != nil {
+		return "", err
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeSSHKey})
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	for _, rec := range records {
+		var meta record.SSHKeyMeta
+		if len(rec.Meta) == 0 || json.Unmarshal(rec.Meta, &meta) != nil || meta.Fingerprint != fingerprint {
+			continue
+		}
+		var data record.SSHKeyData
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			return "", fmt.Errorf("ошибка расшифровки записи %d: %w", rec.ID, err)
+		}
+		if data.Passphrase == "" {
+			return "", fmt.Errorf("%w: у ключа %s в записи %d нет парольной фразы", ErrRecordNotFound, fingerprint, rec.ID)
+		}
+		return data.Passphrase, nil
+	}
+	return "", fmt.Errorf("%w: нет SSH-ключа с отпечатком %s", ErrRecordNotFound, fingerprint)
+}
+
+// keyFileFingerprint возвращает отпечаток ключа из файла закрытого ключа.
+// Открытая часть зашифрованного ключа OpenSSH читается без парольной фразы,
+// для ключей PEM используется соседний файл .pub.
+func keyFileFingerprint(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения ключа: %w", err)
+	}
+
+	_, err = ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && missing.PublicKey != nil {
+		return ssh.FingerprintSHA256(missing.PublicKey), nil
+	}
+
+	pubData, err := os.ReadFile(path + ".pub")
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить открытый ключ %s: %w", path, err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(pubData)
+	if err != nil {
+		return "", fmt.Errorf("неверный открытый ключ %s.pub: %w", path, err)
+	}
+	return ssh.FingerprintSHA256(pub), nil
+}
+
+// matchHost сообщает, что ресурс логина указывает на хост host. Схема ресурса
+// не учитывается, www и регистр тоже; порт ресурса, если указан, должен совпадать.
+func matchHost(resource, host string) bool {
+	raw := strings.TrimSpace(resource)
+	if raw == "" {
+		return false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "ssh://" + raw
+	}
+	res, err := url.Parse(raw)
+	if err != nil || res.Hostname() == "" {
+		return false
+	}
+
+	target, err := url.Parse("ssh://" + host)
+	if err != nil {
+		return false
+	}
+	if res.Port() != "" && res.Port() != target.Port() {
+		return false
+	}
+	return strings.EqualFold(strings.TrimPrefix(res.Hostname(), "www."), strings.TrimPrefix(target.Hostname(), "www."))
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestParseAskpassPrompt(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   AskpassPrompt
+	}{
+		{"Enter passphrase for key '/home/alice/.ssh/id_ed25519': ", AskpassPrompt{Kind: AskpassPassphrase, KeyPath: "/home/alice/.ssh/id_ed25519"}},
+		{"Enter passphrase for '/tmp/key': ", AskpassPrompt{Kind: AskpassPassphrase, KeyPath: "/tmp/key"}},
+		{"deploy@db.example.com's password: ", AskpassPrompt{Kind: AskpassPassword, URL: "ssh://db.example.com", Username: "deploy"}},
+		{"Username for 'https://github.com': ", AskpassPrompt{Kind: AskpassUsername, URL: "https://github.com"}},
+		{"Password for 'https://alice@github.com': ", AskpassPrompt{Kind: AskpassPassword, URL: "https://github.com", Username: "alice"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseAskpassPrompt(tt.prompt)
+		require.NoError(t, err, tt.prompt)
+		assert.Equal(t, tt.want, got, tt.prompt)
+	}
+
+	_, err := ParseAskpassPrompt("Are you sure you want to continue connecting (yes/no/[fingerprint])? ")
+	assert.ErrorIs(t, err, ErrUnsupportedPrompt)
+}
+
+func TestApp_Askpass(t *testing.T) {
+	ctx := context.Background()
+	app := newUndoTestApp(t)
+
+	saveTestRecord(t, app, record.RecTypeLogin,
+		record.LoginMeta{Title: "DB", Resource: "db.example.com"},
+		record.LoginData{Username: "deploy", Password: "ssh-pass"})
+	saveTestRecord(t, app, record.RecTypeLogin,
+		record.LoginMeta{Title: "GitHub", Resource: "github.com"},
+		record.LoginData{Username: "alice", Password: "gh-token"})
+
+	// Зашифрованный ключ OpenSSH: отпечаток читается без парольной фразы
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "alice", []byte("key-pass"))
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	saveTestRecord(t, app, record.RecTypeSSHKey,
+		record.SSHKeyMeta{Title: "laptop", Fingerprint: ssh.FingerprintSHA256(sshPub)},
+		record.SSHKeyData{PublicKey: string(ssh.MarshalAuthorizedKey(sshPub)), Passphrase: "key-pass"})
+
+	answers := map[string]string{
+		"Enter passphrase for key '" + keyPath + "': ": "key-pass",
+		"deploy@db.example.com's password: ":           "ssh-pass",
+		"Username for 'https://github.com': ":          "alice",
+		"Password for 'https://alice@github.com': ":    "gh-token",
+	}
+	for prompt, want := range answers {
+		got, err := app.Askpass(ctx, prompt)
+		require.NoError(t, err, prompt)
+		assert.Equal(t, want, got, prompt)
+	}
+
+	_, err = app.Askpass(ctx, "root@db.example.com's password: ")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	_, err = app.Askpass(ctx, "Password for 'https://alice@github.com.evil.io': ")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestMatchHost(t *testing.T) {
+	assert.True(t, matchHost("db.example.com", "db.example.com"))
+	assert.True(t, matchHost("ssh://DB.example.com", "db.example.com"))
+	assert.True(t, matchHost("https://www.example.com/login", "example.com"))
+	assert.False(t, matchHost("example.com", "db.example.com"))
+	assert.False(t, matchHost("db.example.com:2222", "db.example.com"))
+	assert.False(t, matchHost("", "db.example.com"))
+}
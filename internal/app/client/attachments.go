@@ -0,0 +1,235 @@
+// internal/app/client/attachments.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Вложения - файлы, прикрепленные к записи любого типа: PDF с кодами
+// восстановления к логину, скан к карте. Имя файла и содержимое шифруются
+// мастер-ключом; сервер хранит вложения отдельно от записи, поэтому они не
+// передаются при синхронизации. Локально кэшируется только список вложений,
+// содержимое загружается при скачивании.
+
+// maxAttachmentFileSize - наибольший размер прикрепляемого файла
+const maxAttachmentFileSize = 25 * 1024 * 1024
+
+// LocalAttachment - вложение записи в локальном кэше
+type LocalAttachment struct {
+	ID            int // ID вложения на сервере
+	RecordID      int // локальный ID записи
+	EncryptedMeta string
+	Size          int64
+	CreatedAt     time.Time
+}
+
+// attachmentMeta - сведения о файле, хранятся в зашифрованном виде
+type attachmentMeta struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// AttachmentInfo - расшифрованные сведения о вложении
+type AttachmentInfo struct {
+	ID          int
+	Filename    string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+}
+
+// AttachFile шифрует файл и прикрепляет его к записи
+func (a *App) AttachFile(ctx context.Context, id int, path string) (*AttachmentInfo, error) {
+	rec, err := a.attachmentRecord(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("файл %s пуст", path)
+	}
+	if len(content) > maxAttachmentFileSize {
+		return nil, fmt.Errorf("файл слишком большой: %d байт (максимум %d МБ)", len(content), maxAttachmentFileSize/1024/1024)
+	}
+
+	meta := attachmentMeta{
+		Filename:    filepath.Base(path),
+		ContentType: mime.TypeByExtension(strings.ToLower(filepath.Ext(path))),
+		Size:        int64(len(content)),
+	}
+	encryptedMeta, err := a.encryptRecordData(meta)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования сведений о файле: %w", err)
+	}
+	data, err := a.encryptor.EncryptRecord(content)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования файла: %w", err)
+	}
+
+	attachment, err := a.httpClient.AddAttachment(ctx, rec.ServerRef(), encryptedMeta, data)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки вложения: %w", err)
+	}
+	a.refreshAttachments(ctx, rec)
+
+	return &AttachmentInfo{
+		ID:          attachment.ID,
+		Filename:    meta.Filename,
+		ContentType: meta.ContentType,
+		Size:        meta.Size,
+		CreatedAt:   attachment.CreatedAt,
+	}, nil
+}
+
+// ListAttachments возвращает вложения записи. Список запрашивается с сервера,
+// без связи с ним - из локального кэша.
+func (a *App) ListAttachments(ctx context.Context, id int) ([]AttachmentInfo, error) {
+	rec, err := a.attachmentRecord(id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := a.refreshAttachments(ctx, rec)
+	infos := make([]AttachmentInfo, 0, len(cached))
+	for _, att := range cached {
+		var meta attachmentMeta
+		if err := a.decryptRecordData(att.EncryptedMeta, &meta); err != nil {
+			a.log.Warn("Не удалось расшифровать сведения о вложении", "attachment_id", att.ID, "error", err)
+			meta.Filename = fmt.Sprintf("attachment-%d", att.ID)
+		}
+		infos = append(infos, AttachmentInfo{
+			ID:          att.ID,
+			Filename:    meta.Filename,
+			ContentType: meta.ContentType,
+			Size:        meta.Size,
+			CreatedAt:   att.CreatedAt,
+		})
+	}
+	return infos, nil
+}
+
+// DetachFile удаляет вложение записи
+func (a *App) DetachFile(ctx context.Context, id, attachmentID int) error {
+	rec, err := a.attachmentRecord(id, true)
+	if err != nil {
+		return err
+	}
+
+	if err := a.httpClient.DeleteAttachment(ctx, rec.ServerRef(), attachmentID); err != nil {
+		return fmt.Errorf("ошибка удаления вложения: %w", err)
+	}
+	a.refreshAttachments(ctx, rec)
+	return nil
+}
+
+// DownloadAttachment загружает вложение и сохраняет его расшифрованным в path
+// с правами 0600. Если path - каталог, файл сохраняется в нем под исходным именем.
+// Возвращает путь сохраненного файла.
+func (a *App) DownloadAttachment(ctx context.Context, id, attachmentID int, path string, overwrite bool) (string, error) {
+	rec, err := a.attachmentRecord(id, false)
+	if err != nil {
+		return "", err
+	}
+
+	attachment, data, err := a.httpClient.GetAttachment(ctx, rec.ServerRef(), attachmentID)
+	if err != nil {
+		return "", fmt.Errorf("ошибка загрузки вложения: %w", err)
+	}
+
+	var meta attachmentMeta
+	if err := a.decryptRecordData(attachment.EncryptedMeta, &meta); err != nil {
+		return "", fmt.Errorf("ошибка расшифровки сведений о файле: %w", err)
+	}
+	content, err := a.encryptor.DecryptRecordSecure(data)
+	if err != nil {
+		return "", fmt.Errorf("ошибка расшифровки файла: %w", err)
+	}
+	defer content.Close()
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		// Имя из вложения не должно выводить за пределы каталога
+		path = filepath.Join(path, filepath.Base(meta.Filename))
+	}
+	if !overwrite {
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("файл %s уже существует (используйте --force для перезаписи)", path)
+		}
+	}
+
+	if err := os.WriteFile(path, content.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("ошибка записи файла %s: %w", path, err)
+	}
+	// WriteFile не меняет права уже существующего файла
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", fmt.Errorf("ошибка установки прав на файл %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// attachmentRecord возвращает запись, с вложениями которой можно работать:
+// вложения хранятся на сервере, поэтому запись должна быть на нем
+func (a *App) attachmentRecord(id int, write bool) (*LocalRecord, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: запись в корзине", ErrRecordNotFound)
+	}
+	if rec.ServerID == 0 {
+		return nil, fmt.Errorf("запись %d еще не сохранена на сервере. Выполните: gophkeeper sync", id)
+	}
+	if write && record.IsLocked(rec.Meta) {
+		return nil, ErrRecordLocked
+	}
+	return rec, nil
+}
+
+// refreshAttachments обновляет кэш списка вложений записи с сервера и
+// возвращает актуальный список; без связи с сервером - сохраненный
+func (a *App) refreshAttachments(ctx context.Context, rec *LocalRecord) []*LocalAttachment {
+	attachments, err := a.httpClient.ListAttachments(ctx, rec.ServerRef())
+	if err != nil {
+		a.log.Warn("Не удалось получить вложения с сервера, используется кэш", "record_id", rec.ID, "error", err)
+		cached, err := a.storage.ListAttachments(rec.ID)
+		if err != nil {
+			a.log.Warn("Не удалось прочитать кэш вложений", "record_id", rec.ID, "error", err)
+		}
+		return cached
+	}
+
+	local := make([]*LocalAttachment, 0, len(attachments))
+	for _, att := range attachments {
+		local = append(local, &LocalAttachment{
+			ID:            att.ID,
+			RecordID:      rec.ID,
+			EncryptedMeta: att.EncryptedMeta,
+			Size:          att.Size,
+			CreatedAt:     att.CreatedAt,
+		})
+	}
+	if err := a.storage.SaveAttachments(rec.ID, local); err != nil {
+		a.log.Warn("Не удалось сохранить вложения в кэш", "record_id", rec.ID, "error", err)
+	}
+	return local
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+// attachmentServer - вложения одной записи на сервере для тестов
+type attachmentServer struct {
+	mu          gosync.Mutex
+	nextID      int
+	attachments map[int]record.Attachment
+	data        map[int][]byte
+}
+
+func (s *attachmentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/records/7/attachments")
+	switch {
+	case r.Method == http.MethodGet && rest == "":
+		list := []record.Attachment{}
+		for id := 1; id <= s.nextID; id++ {
+			if a, ok := s.attachments[id]; ok {
+				list = append(list, a)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "Ok", "attachments": list})
+	case r.Method == http.MethodPost && rest == "":
+		var body struct {
+			EncryptedMeta string `json:"encrypted_meta"`
+			Data          []byte `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.nextID++
+		a := record.Attachment{ID: s.nextID, RecordID: 7, EncryptedMeta: body.EncryptedMeta, Size: int64(len(body.Data)), CreatedAt: time.Now()}
+		s.attachments[a.ID] = a
+		s.data[a.ID] = body.Data
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "Ok", "attachment": a})
+	default:
+		var id int
+		if _, err := fmt.Sscanf(rest, "/%d", &id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		a, ok := s.attachments[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": "attachment not found"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.attachments, id)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "Ok"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "Ok", "attachment": a, "data": s.data[id]})
+	}
+}
+
+func TestApp_Attachments(t *testing.T) {
+	server := &attachmentServer{attachments: map[int]record.Attachment{}, data: map[int][]byte{}}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.httpClient.baseURL = srv.URL
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.loggedIn("alice", "token"))
+
+	ctx := context.Background()
+	rec := &LocalRecord{ServerID: 7, Type: record.RecTypeCard, Meta: json.RawMessage(`{"title":"visa"}`), Synced: true}
+	require.NoError(t, app.storage.SaveRecord(rec))
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "card-scan.png")
+	require.NoError(t, os.WriteFile(src, []byte("scan of the card"), 0600))
+
+	info, err := app.AttachFile(ctx, rec.ID, src)
+	require.NoError(t, err)
+	assert.Equal(t, "card-scan.png", info.Filename)
+	assert.Equal(t, "image/png", info.ContentType)
+
+	// Сервер получает только зашифрованные имя и содержимое
+	stored := server.attachments[info.ID]
+	assert.NotContains(t, stored.EncryptedMeta, "card-scan")
+	assert.NotContains(t, string(server.data[info.ID]), "scan of the card")
+
+	list, err := app.ListAttachments(ctx, rec.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "card-scan.png", list[0].Filename)
+	assert.Equal(t, int64(16), list[0].Size)
+
+	out := filepath.Join(dir, "out")
+	require.NoError(t, os.Mkdir(out, 0700))
+	written, err := app.DownloadAttachment(ctx, rec.ID, info.ID, out, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(out, "card-scan.png"), written)
+	content, err := os.ReadFile(written)
+	require.NoError(t, err)
+	assert.Equal(t, "scan of the card", string(content))
+
+	_, err = app.DownloadAttachment(ctx, rec.ID, info.ID, out, false)
+	assert.Error(t, err, "существующий файл не перезаписывается без overwrite")
+
+	// Без связи с сервером список берется из кэша
+	srv.Close()
+	offline, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	list, err = app.ListAttachments(offline, rec.ID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestApp_AttachFile_Rejected(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.loggedIn("alice", "token"))
+
+	src := filepath.Join(t.TempDir(), "codes.txt")
+	require.NoError(t, os.WriteFile(src, []byte("codes"), 0600))
+
+	local := &LocalRecord{Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"codes"}`)}
+	require.NoError(t, app.storage.SaveRecord(local))
+	_, err := app.AttachFile(context.Background(), local.ID, src)
+	assert.ErrorContains(t, err, "gophkeeper sync")
+
+	locked := &LocalRecord{ServerID: 3, Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"codes","locked":true}`)}
+	require.NoError(t, app.storage.SaveRecord(locked))
+	_, err = app.AttachFile(context.Background(), locked.ID, src)
+	assert.ErrorIs(t, err, ErrRecordLocked)
+}
@@ -0,0 +1,277 @@
+// internal/app/client/audit.go
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Аудит паролей выполняется целиком на клиенте: записи логинов расшифровываются
+// в памяти, в отчет попадают только ID, названия и оценки. Сами пароли,
+// их хэши и логины в отчет не включаются и на сервер не отправляются.
+
+const (
+	// DefaultAuditMinEntropy - пароли с меньшей оценкой энтропии считаются слабыми
+	DefaultAuditMinEntropy = 50.0
+	// DefaultAuditMaxAge - пароли старше считаются устаревшими
+	DefaultAuditMaxAge = 365 * 24 * time.Hour
+)
+
+// commonPasswords - распространенные пароли и их основы. Пароль, совпадающий
+// с одной из них или составленный из нее и цифр, получает минимальную оценку.
+var commonPasswords = []string{
+	"password", "passw0rd", "qwerty", "qwertyuiop", "123456", "12345678", "123456789",
+	"111111", "000000", "abc123", "letmein", "welcome", "admin", "iloveyou", "monkey",
+	"dragon", "football", "baseball", "master", "sunshine", "princess", "login",
+	"trustno1", "starwars", "whatever", "zaq12wsx", "1q2w3e4r", "asdfgh", "parol",
+}
+
+// AuditOptions - параметры аудита паролей
+type AuditOptions struct {
+	MinEntropy float64
+	MaxAge     time.Duration
+}
+
+// PasswordStrength - оценка пароля
+type PasswordStrength string
+
+const (
+	StrengthVeryWeak   PasswordStrength = "very_weak"
+	StrengthWeak       PasswordStrength = "weak"
+	StrengthReasonable PasswordStrength = "reasonable"
+	StrengthStrong     PasswordStrength = "strong"
+)
+
+// AuditRecord - запись в отчете аудита
+type AuditRecord struct {
+	RecordID     int              `json:"record_id"`
+	Title        string           `json:"title"`
+	Resource     string           `json:"resource,omitempty"`
+	Entropy      float64          `json:"entropy"`
+	Strength     PasswordStrength `json:"strength"`
+	LastModified time.Time        `json:"last_modified"`
+	AgeDays      int              `json:"age_days"`
+}
+
+// AuditReuseGroup - записи с одинаковым паролем
+type AuditReuseGroup struct {
+	Records []AuditRecord `json:"records"`
+}
+
+// AuditReport - результат аудита паролей
+type AuditReport struct {
+	CreatedAt  time.Time         `json:"created_at"`
+	MinEntropy float64           `json:"min_entropy"`
+	MaxAgeDays int               `json:"max_age_days"`
+	Total      int               `json:"total"`
+	Weak       []AuditRecord     `json:"weak"`
+	Reused     []AuditReuseGroup `json:"reused"`
+	Old        []AuditRecord     `json:"old"`
+	Failed     []int             `json:"failed,omitempty"` // записи, которые не удалось расшифровать
+}
+
+// Issues возвращает число проблемных записей
+func (r *AuditReport) Issues() int {
+	return len(r.IssueIDs())
+}
+
+// IssueIDs возвращает ID проблемных записей по возрастанию
+func (r *AuditReport) IssueIDs() []int {
+	seen := make(map[int]bool)
+	var ids []int
+	add := func(rec AuditRecord) {
+		if !seen[rec.RecordID] {
+			seen[rec.RecordID] = true
+			ids = append(ids, rec.RecordID)
+		}
+	}
+	for _, rec := range r.Weak {
+		add(rec)
+	}
+	for _, g := range r.Reused {
+		for _, rec := range g.Records {
+			add(rec)
+		}
+	}
+	for _, rec := range r.Old {
+		add(rec)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// AuditPasswords проверяет пароли записей логинов: слабые, повторяющиеся и старые.
+// Возраст пароля считается по последнему изменению записи.
+func (a *App) AuditPasswords(_ context.Context, opts AuditOptions) (*AuditReport, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if opts.MinEntropy <= 0 {
+		opts.MinEntropy = DefaultAuditMinEntropy
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultAuditMaxAge
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeLogin})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей: %w", err)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	now := time.Now()
+	report := &AuditReport{
+		CreatedAt:  now,
+		MinEntropy: opts.MinEntropy,
+		MaxAgeDays: int(opts.MaxAge / (24 * time.Hour)),
+		Weak:       []AuditRecord{},
+		Reused:     []AuditReuseGroup{},
+		Old:        []AuditRecord{},
+	}
+
+	// Повторы ищутся по SHA-256 пароля; ключи карты живут только в памяти
+	byPassword := make(map[[sha256.Size]byte][]AuditRecord)
+	var order [][sha256.Size]byte
+
+	for _, rec := range records {
+		var data record.LoginData
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			a.log.Debug("Не удалось расшифровать запись для аудита", "record_id", rec.ID, "error", err)
+			report.Failed = append(report.Failed, rec.ID)
+			continue
+		}
+		if data.Password == "" {
+			continue
+		}
+		report.Total++
+
+		entropy := PasswordEntropy(data.Password, data.Username)
+		item := AuditRecord{
+			RecordID:     rec.ID,
+			Title:        buildRecordPreview(rec.Type, rec.Meta).Title,
+			Resource:     auditResource(rec.Meta),
+			Entropy:      math.Round(entropy*10) / 10,
+			Strength:     strengthOf(entropy),
+			LastModified: rec.LastModified,
+			AgeDays:      int(now.Sub(rec.LastModified) / (24 * time.Hour)),
+		}
+
+		if entropy < opts.MinEntropy {
+			report.Weak = append(report.Weak, item)
+		}
+		if now.Sub(rec.LastModified) > opts.MaxAge {
+			report.Old = append(report.Old, item)
+		}
+
+		key := sha256.Sum256([]byte(data.Password))
+		if _, ok := byPassword[key]; !ok {
+			order = append(order, key)
+		}
+		byPassword[key] = append(byPassword[key], item)
+	}
+
+	for _, key := range order {
+		if group := byPassword[key]; len(group) > 1 {
+			report.Reused = append(report.Reused, AuditReuseGroup{Records: group})
+		}
+	}
+
+	return report, nil
+}
+
+// PasswordEntropy оценивает энтропию пароля в битах: длина на log2 размера
+// алфавита по использованным классам символов. Повторы и последовательности
+// символов, распространенные пароли и вхождение логина снижают оценку.
+func PasswordEntropy(password, username string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	lower := strings.ToLower(password)
+	for _, common := range commonPasswords {
+		if strings.Trim(lower, "0123456789!.") == common {
+			return math.Log2(float64(len(commonPasswords)))
+		}
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+	for _, r := range runes {
+		switch {
+		case r > unicode.MaxASCII:
+			hasOther = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{
+		{hasLower, 26}, {hasUpper, 26}, {hasDigit, 10}, {hasSymbol, 33}, {hasOther, 66},
+	} {
+		if class.used {
+			pool += class.size
+		}
+	}
+
+	// Символ, повторяющий предыдущий или продолжающий последовательность
+	// (aaa, abc, 321), почти не добавляет энтропии
+	effective := 1.0
+	for i := 1; i < len(runes); i++ {
+		diff := runes[i] - runes[i-1]
+		if diff >= -1 && diff <= 1 {
+			effective += 0.25
+		} else {
+			effective++
+		}
+	}
+
+	entropy := effective * math.Log2(float64(pool))
+
+	if u := strings.ToLower(strings.TrimSpace(username)); len(u) >= 3 && strings.Contains(lower, u) {
+		entropy -= float64(len([]rune(u))) * math.Log2(float64(pool)) * 0.75
+	}
+
+	return math.Max(entropy, 0)
+}
+
+func strengthOf(entropy float64) PasswordStrength {
+	switch {
+	case entropy < 28:
+		return StrengthVeryWeak
+	case entropy < DefaultAuditMinEntropy:
+		return StrengthWeak
+	case entropy < 80:
+		return StrengthReasonable
+	default:
+		return StrengthStrong
+	}
+}
+
+func auditResource(meta []byte) string {
+	var m struct {
+		Resource string `json:"resource"`
+	}
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil {
+		return ""
+	}
+	return resourceLabel(strings.TrimSpace(m.Resource))
+}
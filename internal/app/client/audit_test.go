@@ -0,0 +1,24 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditReport_IssueIDs(t *testing.T) {
+	report := &AuditReport{
+		Weak: []AuditRecord{{RecordID: 7}, {RecordID: 2}},
+		Reused: []AuditReuseGroup{
+			{Records: []AuditRecord{{RecordID: 2}, {RecordID: 5}}},
+		},
+		Old: []AuditRecord{{RecordID: 7}, {RecordID: 1}},
+	}
+
+	assert.Equal(t, []int{1, 2, 5, 7}, report.IssueIDs())
+	assert.Equal(t, 4, report.Issues())
+
+	empty := &AuditReport{}
+	assert.Empty(t, empty.IssueIDs())
+	assert.Zero(t, empty.Issues())
+}
@@ -0,0 +1,52 @@
+// internal/app/client/autolock.go
+package client
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	autoLockCheckInterval = 15 * time.Second
+	// suspendThreshold - расхождение настенных и монотонных часов, после
+	// которого считается, что система была в спящем режиме. Монотонные
+	// часы не идут во сне (Linux, macOS), а настенные идут.
+	suspendThreshold = time.Minute
+)
+
+// runAutoLock блокирует мастер-ключ после простоя и после выхода системы
+// из спящего режима. Работает в долгоживущем процессе (агент); короткие
+// команды CLI проверяют простой по времени активности в файле сессии.
+func (a *App) runAutoLock(ctx context.Context) {
+	ticker := time.NewTicker(autoLockCheckInterval)
+	defer ticker.Stop()
+
+	prev := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			wall := now.Round(0).Sub(prev.Round(0))
+			slept := wall-now.Sub(prev) > suspendThreshold
+			prev = now
+
+			if slept && !a.crypto.IsLocked() {
+				a.autoLock("system sleep")
+				continue
+			}
+			if a.crypto.LockIfIdle() {
+				a.autoLock("idle timeout")
+			}
+		}
+	}
+}
+
+func (a *App) autoLock(reason string) {
+	a.crypto.Lock()
+
+	a.state.SetMasterKeyReady(false)
+
+	a.log.Info("Мастер-ключ заблокирован автоматически", "reason", reason)
+}
@@ -0,0 +1,320 @@
+// internal/app/client/backup.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gophkeeper/internal/app/client/crypto"
+)
+
+const (
+	backupManifestVersion = 1
+	backupPageSize        = 100
+)
+
+// BackupManifest первая строка расшифрованного потока резервной копии.
+// За ней следуют записи - по одному JSON-объекту LocalRecord на строку.
+type BackupManifest struct {
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	ClientVersion string    `json:"client_version"`
+	UserLogin     string    `json:"user_login,omitempty"`
+	MasterKeyHash string    `json:"master_key_hash,omitempty"`
+	// MasterKey - файл мастер-ключа (ключ в нем зашифрован): base64-строка
+	// двоичного файла или JSON-объект в копиях первых версий клиента
+	MasterKey    json.RawMessage `json:"master_key"`
+	SyncMetadata *SyncMetadata   `json:"sync_metadata,omitempty"`
+}
+
+// BackupResult итог экспорта или импорта резервной копии
+type BackupResult struct {
+	Records           int  `json:"records"`
+	Imported          int  `json:"imported,omitempty"`
+	Updated           int  `json:"updated,omitempty"`
+	Skipped           int  `json:"skipped,omitempty"`
+	Merged            int  `json:"merged,omitempty"`
+	Duplicates        int  `json:"duplicates,omitempty"` // записи копии, совпавшие с локальными (входят в Skipped, Updated или Merged)
+	Organized         int  `json:"organized,omitempty"`  // записи, которым правила импорта назначили теги или категорию
+	MasterKeyRestored bool `json:"master_key_restored,omitempty"`
+}
+
+// VerifyMasterPassword проверяет мастер-пароль без изменения состояния блокировки
+func (a *App) VerifyMasterPassword(password string) error {
+	return a.crypto.VerifyPassword(password)
+}
+
+// ExportBackup записывает все локальные записи (включая удаленные) и метаданные
+// синхронизации в зашифрованный поток. Данные записей остаются зашифрованными
+// мастер-ключом, поэтому разблокировка для экспорта не требуется.
+func (a *App) ExportBackup(ctx context.Context, w io.Writer, password, keySource string) (*BackupResult, error) {
+	if !a.crypto.IsInitialized() {
+		return nil, fmt.Errorf("клиент не инициализирован. Выполните: gophkeeper init")
+	}
+
+	keyFile, err := a.crypto.KeyFile()
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := json.Marshal(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	syncMeta, err := a.syncService.loadSyncMetadata()
+	if err != nil {
+		a.log.Warn("Не удалось загрузить метаданные синхронизации", "error", err)
+		syncMeta = nil
+	}
+
+	state := a.state.Snapshot()
+	manifest := BackupManifest{
+		Version:       backupManifestVersion,
+		CreatedAt:     time.Now().UTC(),
+		ClientVersion: Version,
+		UserLogin:     state.UserLogin,
+		MasterKeyHash: state.MasterKeyHash,
+		MasterKey:     masterKey,
+		SyncMetadata:  syncMeta,
+	}
+
+	bw, err := crypto.NewBackupWriter(w, password, keySource)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания резервной копии: %w", err)
+	}
+
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("ошибка записи манифеста: %w", err)
+	}
+
+	result := &BackupResult{}
+	for offset := 0; ; offset += backupPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		records, err := a.storage.ListRecords(&RecordFilter{
+			ShowDeleted: true,
+			Limit:       backupPageSize,
+			Offset:      offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+		}
+
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				return nil, fmt.Errorf("ошибка записи записи %d: %w", rec.ID, err)
+			}
+			result.Records++
+		}
+
+		if len(records) < backupPageSize {
+			break
+		}
+	}
+
+	if err := bw.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка завершения резервной копии: %w", err)
+	}
+
+	return result, nil
+}
+
+// ReadBackupHeader читает открытый заголовок резервной копии,
+// чтобы понять, каким паролем она зашифрована
+func ReadBackupHeader(r io.Reader) (*crypto.BackupReader, error) {
+	return crypto.NewBackupReader(r)
+}
+
+// ImportBackup восстанавливает записи из резервной копии.
+// На новом устройстве восстанавливается и файл мастер-ключа, а метаданные
+// синхронизации позволяют продолжить синхронизацию с момента создания копии.
+// Записи с сервера заменяются только более новыми версиями из копии.
+// Новые записи, совпавшие с локальными, обрабатываются по политике opts.OnDuplicate,
+// правила opts.Rules раскладывают добавленные и обновленные записи по тегам и категориям.
+func (a *App) ImportBackup(ctx context.Context, br *crypto.BackupReader, password string, opts ImportOptions) (*BackupResult, error) {
+	if err := br.Unlock(password); err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+
+	var manifest BackupManifest
+	if err := dec.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("ошибка чтения манифеста: %w", err)
+	}
+	if manifest.Version != backupManifestVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия манифеста: %d", manifest.Version)
+	}
+
+	result := &BackupResult{}
+
+	if err := a.restoreBackupKey(&manifest, result); err != nil {
+		return nil, err
+	}
+
+	idx, err := a.newImportIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		var rec LocalRecord
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return result, fmt.Errorf("ошибка чтения записи: %w", err)
+		}
+		result.Records++
+
+		if err := a.importBackupRecord(&rec, idx, opts, result); err != nil {
+			return result, err
+		}
+	}
+
+	if manifest.SyncMetadata != nil {
+		local, err := a.syncService.loadSyncMetadata()
+		if err == nil && local.LastSyncTime.Before(manifest.SyncMetadata.LastSyncTime) {
+			if err := a.syncService.saveSyncMetadata(manifest.SyncMetadata); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	count, err := a.storage.CountRecords()
+	if err != nil {
+		return result, fmt.Errorf("ошибка подсчета записей: %w", err)
+	}
+
+	if err := a.state.Update(func(s *AppState) {
+		s.RecordsCount = count
+		if s.UserLogin == "" {
+			s.UserLogin = manifest.UserLogin
+		}
+	}); err != nil {
+		return result, fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+
+	return result, nil
+}
+
+// restoreBackupKey восстанавливает мастер-ключ на новом устройстве или проверяет,
+// что копия создана с тем же мастер-ключом, что и локальные данные
+func (a *App) restoreBackupKey(manifest *BackupManifest, result *BackupResult) error {
+	if !a.crypto.IsInitialized() {
+		keyFile, err := backupKeyFile(manifest.MasterKey)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения мастер-ключа из копии: %w", err)
+		}
+		if err := a.crypto.RestoreKeyFile(keyFile); err != nil {
+			return fmt.Errorf("ошибка восстановления мастер-ключа: %w", err)
+		}
+
+		a.state.Modify(func(s *AppState) {
+			s.Initialized = true
+			s.MasterKeyHash = manifest.MasterKeyHash
+		})
+
+		result.MasterKeyRestored = true
+		return nil
+	}
+
+	localHash := a.state.Snapshot().MasterKeyHash
+
+	if localHash != "" && manifest.MasterKeyHash != "" && localHash != manifest.MasterKeyHash {
+		return fmt.Errorf("резервная копия создана с другим мастер-ключом")
+	}
+
+	return nil
+}
+
+// backupKeyFile возвращает файл мастер-ключа из манифеста
+func backupKeyFile(raw json.RawMessage) ([]byte, error) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var data []byte
+		err := json.Unmarshal(raw, &data)
+		return data, err
+	}
+	return raw, nil
+}
+
+func (a *App) importBackupRecord(rec *LocalRecord, idx *importIndex, opts ImportOptions, result *BackupResult) error {
+	if rec.ServerID > 0 {
+		existing, err := findLocalRecord(a.storage, rec)
+		if err == nil && existing != nil {
+			if existing.Version >= rec.Version {
+				result.Skipped++
+				return nil
+			}
+			if err := applyImportRules(rec, opts.Rules, result); err != nil {
+				return err
+			}
+			rec.ID = existing.ID
+			if err := a.storage.SaveRecord(rec); err != nil {
+				return fmt.Errorf("ошибка обновления записи %d: %w", rec.ServerID, err)
+			}
+			result.Updated++
+			return nil
+		}
+	}
+
+	// Новая запись: повторный импорт не должен создавать дубликаты
+	if existing, exact := idx.find(rec); existing != nil {
+		if exact {
+			result.Duplicates++
+			result.Skipped++
+			return nil
+		}
+		return a.resolveDuplicate(existing, rec, opts, idx, result)
+	}
+
+	if err := applyImportRules(rec, opts.Rules, result); err != nil {
+		return err
+	}
+	// UUID занят локальной записью с другими данными: импортированная копия
+	// сохраняется как новая запись
+	if rec.UUID != "" {
+		if _, err := a.storage.GetRecordByUUID(rec.UUID); err == nil {
+			rec.UUID = ""
+		}
+	}
+	rec.ID = 0
+	if err := a.storage.SaveRecord(rec); err != nil {
+		return fmt.Errorf("ошибка сохранения записи: %w", err)
+	}
+	idx.add(rec)
+	result.Imported++
+	return nil
+}
+
+// applyImportRules назначает записи теги и категорию по правилам импорта.
+// Измененная запись получает новую версию и отправляется на сервер при синхронизации.
+func applyImportRules(rec *LocalRecord, rules *ImportRules, result *BackupResult) error {
+	meta, changed, err := rules.Apply(rec.Meta)
+	if err != nil {
+		return fmt.Errorf("ошибка применения правил импорта: %w", err)
+	}
+	if !changed {
+		return nil
+	}
+
+	rec.Meta = meta
+	rec.LastModified = time.Now()
+	rec.Synced = false
+	if rec.ServerID > 0 {
+		rec.Version++
+	}
+	result.Organized++
+	return nil
+}
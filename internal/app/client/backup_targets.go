@@ -0,0 +1,394 @@
+// internal/app/client/backup_targets.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/infrastructure/objectstore"
+)
+
+// Удаленные цели резервного копирования - второй путь восстановления,
+// не зависящий от сервера GophKeeper. Клиент выгружает в них копии,
+// зашифрованные парольной фразой цели, поэтому хранилище видит только
+// шифротекст. Параметры доступа и парольная фраза хранятся в служебном
+// файле, зашифрованном ключом машины.
+
+// Типы удаленных хранилищ
+const (
+	BackupTargetS3     = "s3"
+	BackupTargetWebDAV = "webdav"
+	BackupTargetDrive  = "gdrive"
+)
+
+const (
+	backupTargetsFile        = "backup_targets.json"
+	backupObjectPrefix       = "vault-"
+	backupObjectSuffix       = ".gkbackup"
+	backupObjectLayout       = "20060102T150405Z"
+	defaultBackupTargetKeep  = 7
+	defaultBackupTargetDir   = "gophkeeper/"
+	backupTargetsCheckPeriod = time.Minute
+)
+
+// BackupTarget - удаленное хранилище резервных копий
+type BackupTarget struct {
+	Name   string                    `json:"name"`
+	Type   string                    `json:"type"` // s3, webdav или gdrive
+	S3     *objectstore.S3Config     `json:"s3,omitempty"`
+	WebDAV *objectstore.WebDAVConfig `json:"webdav,omitempty"`
+	Drive  *objectstore.DriveConfig  `json:"gdrive,omitempty"`
+	// Prefix - каталог копий в хранилище (в Google Drive - префикс имени файла)
+	Prefix string `json:"prefix"`
+	// IntervalSeconds - период выгрузки агентом; 0 - только gophkeeper backup push
+	IntervalSeconds int64 `json:"interval_seconds,omitempty"`
+	KeepLast        int   `json:"keep_last"`
+	// Passphrase - парольная фраза копий: выгрузка по расписанию идет без участия пользователя
+	Passphrase string `json:"passphrase"`
+
+	LastBackup time.Time `json:"last_backup,omitempty"`
+	LastKey    string    `json:"last_key,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// Interval возвращает период выгрузки по расписанию
+func (t *BackupTarget) Interval() time.Duration {
+	return time.Duration(t.IntervalSeconds) * time.Second
+}
+
+// Due сообщает, пора ли выгружать копию по расписанию
+func (t *BackupTarget) Due(now time.Time) bool {
+	return t.IntervalSeconds > 0 && !now.Before(t.LastBackup.Add(t.Interval()))
+}
+
+// Location возвращает адрес хранилища без секретов
+func (t *BackupTarget) Location() string {
+	switch {
+	case t.S3 != nil:
+		return fmt.Sprintf("s3://%s/%s (%s)", t.S3.Bucket, t.Prefix, t.S3.Endpoint)
+	case t.WebDAV != nil:
+		return strings.TrimRight(t.WebDAV.URL, "/") + "/" + t.Prefix
+	case t.Drive != nil:
+		return fmt.Sprintf("gdrive://%s/%s*", t.Drive.FolderID, t.Prefix)
+	}
+	return ""
+}
+
+// store создает клиент хранилища цели
+func (t *BackupTarget) store() (objectstore.Store, error) {
+	switch t.Type {
+	case BackupTargetS3:
+		if t.S3 == nil || t.S3.Endpoint == "" || t.S3.Bucket == "" || t.S3.AccessKey == "" || t.S3.SecretKey == "" {
+			return nil, fmt.Errorf("для S3 нужны endpoint, bucket, access key и secret key")
+		}
+		if t.S3.Region == "" {
+			t.S3.Region = "us-east-1"
+		}
+		return objectstore.NewS3Store(*t.S3, nil)
+	case BackupTargetWebDAV:
+		if t.WebDAV == nil {
+			return nil, fmt.Errorf("для WebDAV нужен URL")
+		}
+		return objectstore.NewWebDAVStore(*t.WebDAV, nil)
+	case BackupTargetDrive:
+		if t.Drive == nil {
+			return nil, fmt.Errorf("для Google Drive нужны client id, refresh token и ID папки")
+		}
+		return objectstore.NewDriveStore(*t.Drive, nil)
+	}
+	return nil, fmt.Errorf("неизвестный тип хранилища %q (s3, webdav или gdrive)", t.Type)
+}
+
+// RemoteBackup - копия, выгруженная в удаленное хранилище
+type RemoteBackup struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"created_at"`
+	Records   int       `json:"records,omitempty"`
+	Size      int       `json:"size,omitempty"`
+	Pruned    int       `json:"pruned,omitempty"` // удалено старых копий сверх KeepLast
+}
+
+// BackupTargets возвращает цели резервного копирования
+func (a *App) BackupTargets() ([]BackupTarget, error) {
+	a.backupTargetsMu.Lock()
+	defer a.backupTargetsMu.Unlock()
+
+	return a.loadBackupTargets()
+}
+
+// AddBackupTarget добавляет цель. Параметры хранилища проверяются, но
+// соединение не устанавливается: проверить доступ можно командой push.
+func (a *App) AddBackupTarget(target BackupTarget) error {
+	if target.Name == "" {
+		return fmt.Errorf("имя цели не задано")
+	}
+	if len(target.Passphrase) < 8 {
+		return fmt.Errorf("парольная фраза должна содержать минимум 8 символов")
+	}
+	if target.IntervalSeconds < 0 {
+		return fmt.Errorf("период выгрузки не может быть отрицательным")
+	}
+	if target.KeepLast <= 0 {
+		target.KeepLast = defaultBackupTargetKeep
+	}
+	if target.Prefix == "" && target.Type != BackupTargetDrive {
+		target.Prefix = defaultBackupTargetDir
+	}
+	if _, err := target.store(); err != nil {
+		return err
+	}
+
+	a.backupTargetsMu.Lock()
+	defer a.backupTargetsMu.Unlock()
+
+	targets, err := a.loadBackupTargets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if t.Name == target.Name {
+			return fmt.Errorf("цель %q уже существует", target.Name)
+		}
+	}
+
+	return a.saveBackupTargets(append(targets, target))
+}
+
+// RemoveBackupTarget удаляет цель. Копии в хранилище остаются.
+func (a *App) RemoveBackupTarget(name string) error {
+	a.backupTargetsMu.Lock()
+	defer a.backupTargetsMu.Unlock()
+
+	targets, err := a.loadBackupTargets()
+	if err != nil {
+		return err
+	}
+	for i, t := range targets {
+		if t.Name == name {
+			return a.saveBackupTargets(append(targets[:i], targets[i+1:]...))
+		}
+	}
+	return ErrBackupTargetNotFound
+}
+
+// PushBackup выгружает копию хранилища в цель и удаляет копии сверх KeepLast.
+// Итог (время или ошибка) сохраняется в цели.
+func (a *App) PushBackup(ctx context.Context, name string) (*RemoteBackup, error) {
+	target, err := a.backupTarget(name)
+	if err != nil {
+		return nil, err
+	}
+
+	backup, pushErr := a.pushBackup(ctx, target)
+
+	a.backupTargetsMu.Lock()
+	defer a.backupTargetsMu.Unlock()
+	targets, err := a.loadBackupTargets()
+	if err != nil {
+		return backup, errors.Join(pushErr, err)
+	}
+	for i := range targets {
+		if targets[i].Name != name {
+			continue
+		}
+		if pushErr != nil {
+			targets[i].LastError = pushErr.Error()
+		} else {
+			targets[i].LastBackup = backup.CreatedAt
+			targets[i].LastKey = backup.Key
+			targets[i].LastError = ""
+		}
+	}
+	if err := a.saveBackupTargets(targets); err != nil {
+		return backup, errors.Join(pushErr, err)
+	}
+
+	return backup, pushErr
+}
+
+func (a *App) pushBackup(ctx context.Context, target *BackupTarget) (*RemoteBackup, error) {
+	store, err := target.store()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	result, err := a.ExportBackup(ctx, &buf, target.Passphrase, crypto.BackupKeySourcePassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	backup := &RemoteBackup{
+		Key:       target.Prefix + backupObjectPrefix + now.Format(backupObjectLayout) + backupObjectSuffix,
+		CreatedAt: now,
+		Records:   result.Records,
+		Size:      buf.Len(),
+	}
+	if err := store.Put(ctx, backup.Key, buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("ошибка выгрузки в %s: %w", target.Name, err)
+	}
+
+	keys, err := a.remoteBackupKeys(ctx, store, target)
+	if err != nil {
+		a.log.Warn("Не удалось получить список копий для очистки", "target", target.Name, "error", err)
+		return backup, nil
+	}
+	for len(keys) > target.KeepLast {
+		if err := store.Delete(ctx, keys[0]); err != nil {
+			a.log.Warn("Не удалось удалить старую копию", "target", target.Name, "key", keys[0], "error", err)
+			break
+		}
+		keys = keys[1:]
+		backup.Pruned++
+	}
+
+	a.log.Info("Резервная копия выгружена", "target", target.Name, "key", backup.Key, "records", backup.Records)
+	return backup, nil
+}
+
+// ListRemoteBackups возвращает ключи копий цели от старых к новым
+func (a *App) ListRemoteBackups(ctx context.Context, name string) ([]string, error) {
+	target, err := a.backupTarget(name)
+	if err != nil {
+		return nil, err
+	}
+	store, err := target.store()
+	if err != nil {
+		return nil, err
+	}
+	return a.remoteBackupKeys(ctx, store, target)
+}
+
+// PullBackup загружает копию из цели; пустой key - последняя копия.
+// Возвращает ключ загруженной копии.
+func (a *App) PullBackup(ctx context.Context, name, key string) ([]byte, string, error) {
+	target, err := a.backupTarget(name)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := target.store()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if key == "" {
+		keys, err := a.remoteBackupKeys(ctx, store, target)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(keys) == 0 {
+			return nil, "", fmt.Errorf("в %s нет резервных копий", target.Name)
+		}
+		key = keys[len(keys)-1]
+	}
+
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка загрузки %s: %w", key, err)
+	}
+	return data, key, nil
+}
+
+// remoteBackupKeys возвращает ключи копий цели; время в имени дает сортировку по строке
+func (a *App) remoteBackupKeys(ctx context.Context, store objectstore.Store, target *BackupTarget) ([]string, error) {
+	keys, err := store.List(ctx, target.Prefix+backupObjectPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка копий %s: %w", target.Name, err)
+	}
+
+	backups := keys[:0]
+	for _, key := range keys {
+		if strings.HasSuffix(key, backupObjectSuffix) {
+			backups = append(backups, key)
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// runBackupTargets выгружает копии в цели по их расписанию, пока работает агент
+func (a *App) runBackupTargets(ctx context.Context) {
+	ticker := time.NewTicker(backupTargetsCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.pushDueBackups(ctx)
+		}
+	}
+}
+
+// pushDueBackups выгружает копии в цели, у которых подошел срок
+func (a *App) pushDueBackups(ctx context.Context) {
+	if !a.IsInitialized() {
+		return
+	}
+
+	targets, err := a.BackupTargets()
+	if err != nil {
+		a.log.Warn("Не удалось загрузить цели резервного копирования", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, t := range targets {
+		if !t.Due(now) {
+			continue
+		}
+		if _, err := a.PushBackup(ctx, t.Name); err != nil {
+			a.log.Error("Ошибка резервного копирования по расписанию", "target", t.Name, "error", err)
+		}
+	}
+}
+
+func (a *App) backupTarget(name string) (*BackupTarget, error) {
+	targets, err := a.BackupTargets()
+	if err != nil {
+		return nil, err
+	}
+	for i := range targets {
+		if targets[i].Name == name {
+			return &targets[i], nil
+		}
+	}
+	return nil, ErrBackupTargetNotFound
+}
+
+func (a *App) loadBackupTargets() ([]BackupTarget, error) {
+	path := filepath.Join(a.config.ConfigDir, backupTargetsFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := a.stateCipher.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения целей резервного копирования: %w", err)
+	}
+
+	var targets []BackupTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("ошибка разбора целей резервного копирования: %w", err)
+	}
+	return targets, nil
+}
+
+func (a *App) saveBackupTargets(targets []BackupTarget) error {
+	data, err := json.MarshalIndent(targets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации целей резервного копирования: %w", err)
+	}
+	return a.stateCipher.WriteFile(filepath.Join(a.config.ConfigDir, backupTargetsFile), data)
+}
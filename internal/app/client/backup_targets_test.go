@@ -0,0 +1,130 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/infrastructure/objectstore"
+)
+
+// davServer - минимальный WebDAV-сервер в памяти
+type davServer struct {
+	mu    gosync.Mutex
+	files map[string][]byte
+}
+
+func (s *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case "MKCOL":
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		s.files[r.URL.Path], _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := s.files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(s.files, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case "PROPFIND":
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = io.WriteString(w, `<d:multistatus xmlns:d="DAV:">`)
+		for name := range s.files {
+			if strings.HasPrefix(name, r.URL.Path) {
+				_, _ = io.WriteString(w, `<d:response><d:href>`+name+`</d:href></d:response>`)
+			}
+		}
+		_, _ = io.WriteString(w, `</d:multistatus>`)
+	}
+}
+
+func TestApp_BackupTargets(t *testing.T) {
+	ctx := context.Background()
+	dav := &davServer{files: map[string][]byte{}}
+	srv := httptest.NewServer(dav)
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.syncService = NewSyncService(app)
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.storage.SaveRecord(&LocalRecord{ID: 1, Type: "text", EncryptedData: "secret", Version: 1}))
+
+	target := BackupTarget{
+		Name:            "nas",
+		Type:            BackupTargetWebDAV,
+		WebDAV:          &objectstore.WebDAVConfig{URL: srv.URL + "/dav"},
+		IntervalSeconds: 3600,
+		KeepLast:        1,
+		Passphrase:      "offsite-passphrase",
+	}
+	require.NoError(t, app.AddBackupTarget(target))
+	assert.Error(t, app.AddBackupTarget(target), "имена целей уникальны")
+	assert.Error(t, app.AddBackupTarget(BackupTarget{Name: "bad", Type: "ftp", Passphrase: "offsite-passphrase"}))
+
+	targets, err := app.BackupTargets()
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "gophkeeper/", targets[0].Prefix)
+	assert.True(t, targets[0].Due(time.Now()), "ни одной копии еще не выгружено")
+
+	first, err := app.PushBackup(ctx, "nas")
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Records)
+	assert.True(t, strings.HasPrefix(first.Key, "gophkeeper/vault-"))
+
+	// Имя копии содержит время с точностью до секунды
+	time.Sleep(1100 * time.Millisecond)
+	second, err := app.PushBackup(ctx, "nas")
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.Pruned, "остается KeepLast копий")
+
+	keys, err := app.ListRemoteBackups(ctx, "nas")
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.Key}, keys)
+
+	targets, err = app.BackupTargets()
+	require.NoError(t, err)
+	assert.Equal(t, second.Key, targets[0].LastKey)
+	assert.False(t, targets[0].Due(time.Now()))
+
+	data, key, err := app.PullBackup(ctx, "nas", "")
+	require.NoError(t, err)
+	assert.Equal(t, second.Key, key)
+	reader, err := ReadBackupHeader(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.BackupKeySourcePassphrase, reader.Header().KeySource)
+
+	// Копия восстанавливается на новом устройстве вместе с мастер-ключом
+	restored := newTestApp(t)
+	restored.storage = NewMemoryStorage()
+	restored.syncService = NewSyncService(restored)
+	result, err := restored.ImportBackup(ctx, reader, "offsite-passphrase", ImportOptions{OnDuplicate: DuplicateSkip})
+	require.NoError(t, err)
+	assert.True(t, result.MasterKeyRestored)
+	assert.Equal(t, 1, result.Imported)
+	require.NoError(t, restored.UnlockMasterKey("password123"))
+
+	require.NoError(t, app.RemoveBackupTarget("nas"))
+	assert.ErrorIs(t, app.RemoveBackupTarget("nas"), ErrBackupTargetNotFound)
+	_, err = app.PushBackup(ctx, "nas")
+	assert.ErrorIs(t, err, ErrBackupTargetNotFound)
+}
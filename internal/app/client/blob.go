@@ -0,0 +1,91 @@
+// internal/app/client/blob.go
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/blob"
+)
+
+// Содержимое бинарных записей хранится на сервере отдельно от записи - в блобе,
+// адресуемом SHA-256 шифротекста. Файл шифруется детерминированно, поэтому
+// одинаковые файлы пользователя дают один блоб: перед загрузкой клиент
+// спрашивает сервер, есть ли блоб, и не передает его повторно. Загруженные
+// блобы кэшируются в каталоге конфигурации в зашифрованном виде.
+
+// blobsDir - каталог кэша блобов в каталоге конфигурации
+const blobsDir = "blobs"
+
+// storeBlob шифрует содержимое файла и загружает его на сервер, если такого
+// блоба там еще нет. file - имя и тип файла для политики вложений сервера.
+// Возвращает контрольную сумму блоба и ключ расшифровки.
+func (a *App) storeBlob(ctx context.Context, content []byte, file blob.FileInfo) (string, []byte, error) {
+	ciphertext, key, err := a.encryptor.EncryptBlob(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка шифрования файла: %w", err)
+	}
+	sum := sha256.Sum256(ciphertext)
+	checksum := hex.EncodeToString(sum[:])
+
+	a.cacheBlob(checksum, ciphertext)
+
+	if _, err := a.httpClient.GetBlobByChecksum(ctx, checksum); err == nil {
+		a.log.Debug("Файл уже хранится на сервере, загрузка пропущена", "checksum", checksum)
+		return checksum, key, nil
+	} else if !errors.Is(err, ErrBlobNotFound) {
+		return "", nil, err
+	}
+
+	if _, err := a.httpClient.PutBlob(ctx, checksum, ciphertext, file); err != nil {
+		return "", nil, fmt.Errorf("ошибка загрузки файла: %w", err)
+	}
+	return checksum, key, nil
+}
+
+// loadBlob возвращает расшифрованное содержимое блоба из кэша или с сервера
+func (a *App) loadBlob(ctx context.Context, checksum string, key []byte) ([]byte, error) {
+	ciphertext, err := os.ReadFile(a.blobPath(checksum))
+	if err != nil || !blobMatches(checksum, ciphertext) {
+		if ciphertext, err = a.httpClient.GetBlob(ctx, checksum); err != nil {
+			return nil, fmt.Errorf("ошибка загрузки файла: %w", err)
+		}
+		if !blobMatches(checksum, ciphertext) {
+			return nil, fmt.Errorf("содержимое файла повреждено: контрольная сумма не совпадает")
+		}
+		a.cacheBlob(checksum, ciphertext)
+	}
+
+	content, err := crypto.DecryptBlob(key, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки файла: %w", err)
+	}
+	return content, nil
+}
+
+func (a *App) blobPath(checksum string) string {
+	return filepath.Join(a.config.ConfigDir, blobsDir, checksum)
+}
+
+// cacheBlob сохраняет шифротекст в кэш; ошибка кэша не мешает операции
+func (a *App) cacheBlob(checksum string, ciphertext []byte) {
+	path := a.blobPath(checksum)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		a.log.Debug("Не удалось создать каталог кэша файлов", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, ciphertext, 0600); err != nil {
+		a.log.Debug("Не удалось сохранить файл в кэш", "checksum", checksum, "error", err)
+	}
+}
+
+func blobMatches(checksum string, ciphertext []byte) bool {
+	sum := sha256.Sum256(ciphertext)
+	return hex.EncodeToString(sum[:]) == checksum
+}
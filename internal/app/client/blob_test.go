@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/blob"
+)
+
+// blobServer - хранилище блобов сервера для тестов
+type blobServer struct {
+	mu    gosync.Mutex
+	blobs map[string][]byte
+	puts  int
+	gets  int
+}
+
+func (s *blobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checksum := strings.TrimPrefix(r.URL.Path, "/api/blobs/")
+	data, ok := s.blobs[checksum]
+	switch r.Method {
+	case http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Blob-Size", strconv.Itoa(len(data)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		s.puts++
+		var body struct {
+			Data []byte `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.blobs[checksum] = body.Data
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"checksum": checksum, "created": !ok})
+	case http.MethodGet:
+		s.gets++
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"checksum": checksum, "data": data})
+	}
+}
+
+func TestApp_StoreBlob_Deduplicates(t *testing.T) {
+	server := &blobServer{blobs: map[string][]byte{}}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.httpClient.baseURL = srv.URL
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	ctx := context.Background()
+	content := []byte("quarterly report")
+
+	checksum, key, err := app.storeBlob(ctx, content, blob.FileInfo{})
+	require.NoError(t, err)
+	assert.Len(t, checksum, 64)
+	assert.Equal(t, 1, server.puts)
+
+	// Тот же файл дает тот же блоб и повторно не загружается
+	again, againKey, err := app.storeBlob(ctx, content, blob.FileInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, checksum, again)
+	assert.Equal(t, key, againKey)
+	assert.Equal(t, 1, server.puts)
+
+	other, _, err := app.storeBlob(ctx, []byte("another file"), blob.FileInfo{Filename: "notes.txt"})
+	require.NoError(t, err)
+	assert.NotEqual(t, checksum, other)
+	assert.Equal(t, 2, server.puts)
+
+	// Из кэша файл читается без обращения к серверу
+	got, err := app.loadBlob(ctx, checksum, key)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	assert.Zero(t, server.gets)
+
+	// Без кэша блоб загружается с сервера
+	require.NoError(t, os.RemoveAll(app.blobPath(checksum)))
+	got, err = app.loadBlob(ctx, checksum, key)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	assert.Equal(t, 1, server.gets)
+
+	// Поврежденный на сервере блоб отклоняется
+	require.NoError(t, os.RemoveAll(app.blobPath(checksum)))
+	server.blobs[checksum] = []byte("tampered")
+	_, err = app.loadBlob(ctx, checksum, key)
+	assert.Error(t, err)
+}
+
+func TestApp_StoreBlob_PolicyRejection(t *testing.T) {
+	var detail string
+	var file blob.FileInfo
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&file)
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": http.StatusForbidden, "detail": detail})
+	}))
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.httpClient.baseURL = srv.URL
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	ctx := context.Background()
+
+	detail = blob.ErrRejected.Error() + `: extension "exe" is blocked`
+	_, _, err := app.storeBlob(ctx, []byte("MZ"), blob.FileInfo{Filename: "setup.exe"})
+	assert.ErrorIs(t, err, ErrUploadRejected)
+	assert.Equal(t, "setup.exe", file.Filename)
+
+	// Другие отказы 403 (например, устройство не подтверждено) - не отказ политики
+	detail = "device is pending approval"
+	_, _, err = app.storeBlob(ctx, []byte("MZ"), blob.FileInfo{Filename: "setup.exe"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUploadRejected)
+}
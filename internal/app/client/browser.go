@@ -0,0 +1,242 @@
+// internal/app/client/browser.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+)
+
+// Запросы браузерного расширения выполняет агент: он держит мастер-ключ
+// и задает вопросы подтверждения. Расширение получает пароль только для
+// логина, ресурс которого совпадает с адресом страницы.
+
+// ErrOriginMismatch - ресурс записи не совпадает с адресом страницы
+var ErrOriginMismatch = apperr.New(apperr.Forbidden, "ресурс записи не совпадает с адресом страницы")
+
+// BrowserLogin - логин, подходящий странице; пароль не передается
+type BrowserLogin struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Resource string `json:"resource"`
+	Username string `json:"username"`
+}
+
+// BrowserCredentials - данные для автозаполнения формы входа
+type BrowserCredentials struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// BrowserSaveRequest - логин, который расширение предлагает сохранить
+type BrowserSaveRequest struct {
+	URL      string `json:"url"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Client - имя расширения для вопроса подтверждения
+	Client string `json:"client,omitempty"`
+}
+
+// MatchOrigin сообщает, подходит ли логин с ресурсом resource странице pageURL.
+//
+// Совпадают хост и поддомены (github.com подходит gist.github.com), www
+// не учитывается. Ресурс со схемой требует той же схемы; ресурс без схемы
+// подходит только страницам https, чтобы пароль не ушел на страницу http.
+// Порт ресурса, если он указан, должен совпадать. Путь не учитывается.
+func MatchOrigin(resource, pageURL string) bool {
+	page, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil || page.Hostname() == "" || (page.Scheme != "https" && page.Scheme != "http") {
+		return false
+	}
+
+	raw := strings.TrimSpace(resource)
+	hasScheme := strings.Contains(raw, "://")
+	if !hasScheme {
+		raw = "https://" + raw
+	}
+	res, err := url.Parse(raw)
+	if err != nil || res.Hostname() == "" {
+		return false
+	}
+
+	if !strings.EqualFold(res.Scheme, page.Scheme) {
+		return false
+	}
+	if res.Port() != "" && res.Port() != page.Port() {
+		return false
+	}
+
+	resHost := strings.TrimPrefix(strings.ToLower(res.Hostname()), "www.")
+	pageHost := strings.TrimPrefix(strings.ToLower(page.Hostname()), "www.")
+	return pageHost == resHost || strings.HasSuffix(pageHost, "."+resHost)
+}
+
+// pageOrigin возвращает схему и хост страницы - ресурс нового логина
+func pageOrigin(pageURL string) (string, error) {
+	page, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil || page.Host == "" || (page.Scheme != "https" && page.Scheme != "http") {
+		return "", apperr.New(apperr.Invalid, fmt.Sprintf("неверный адрес страницы %q", pageURL))
+	}
+	return page.Scheme + "://" + strings.ToLower(page.Host), nil
+}
+
+// BrowserLogins возвращает логины, подходящие странице, с именами пользователей
+func (a *App) BrowserLogins(ctx context.Context, pageURL string) ([]BrowserLogin, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	records, err := a.matchingLogins(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	logins := make([]BrowserLogin, 0, len(records))
+	for _, m := range records {
+		var data record.LoginData
+		if err := a.decryptRecordData(m.rec.EncryptedData, &data); err != nil {
+			a.log.Warn("Не удалось расшифровать логин", "record_id", m.rec.ID, "error", err)
+			continue
+		}
+		logins = append(logins, BrowserLogin{
+			ID:       m.rec.ID,
+			Title:    m.meta.Title,
+			Resource: m.meta.Resource,
+			Username: data.Username,
+		})
+	}
+
+	sort.Slice(logins, func(i, j int) bool {
+		if logins[i].Title != logins[j].Title {
+			return logins[i].Title < logins[j].Title
+		}
+		return logins[i].ID < logins[j].ID
+	})
+	return logins, nil
+}
+
+// BrowserCredentials выдает имя пользователя и пароль логина id для страницы
+// pageURL после подтверждения пользователем. Ресурс записи должен совпадать
+// с адресом страницы: расширение не может получить пароль чужого сайта.
+func (a *App) BrowserCredentials(ctx context.Context, pageURL string, id int, clientName string) (*BrowserCredentials, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	rec, err := a.storage.GetRecord(id)
+	if err != nil || rec.Type != record.RecTypeLogin || rec.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: %d", ErrRecordNotFound, id)
+	}
+
+	var meta record.LoginMeta
+	_ = json.Unmarshal(rec.Meta, &meta)
+	if !MatchOrigin(meta.Resource, pageURL) {
+		a.log.Warn("Запрос пароля для чужого сайта отклонен", "record_id", id, "url", pageURL)
+		return nil, ErrOriginMismatch
+	}
+
+	origin, _ := pageOrigin(pageURL)
+	question := fmt.Sprintf("GophKeeper: заполнить логин %q для %s (%s)?", meta.Title, origin, browserClient(clientName))
+	if err := a.askApproval(ctx, question); err != nil {
+		return nil, err
+	}
+
+	var data record.LoginData
+	if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки записи %d: %w", id, err)
+	}
+
+	return &BrowserCredentials{ID: id, Username: data.Username, Password: data.Password}, nil
+}
+
+// SaveBrowserLogin сохраняет логин со страницы после подтверждения пользователем.
+// Ресурсом записи становятся схема и хост страницы. Если для страницы уже есть
+// логин с тем же именем пользователя, возвращается конфликт.
+func (a *App) SaveBrowserLogin(ctx context.Context, req BrowserSaveRequest) (int, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return 0, ErrMasterKeyLocked
+	}
+
+	origin, err := pageOrigin(req.URL)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(req.Username) == "" || req.Password == "" {
+		return 0, apperr.New(apperr.Invalid, "не указаны имя пользователя и пароль")
+	}
+
+	existing, err := a.BrowserLogins(ctx, req.URL)
+	if err != nil {
+		return 0, err
+	}
+	for _, login := range existing {
+		if login.Username == req.Username {
+			return 0, apperr.New(apperr.Conflict, fmt.Sprintf("логин %s уже сохранен (запись %d)", req.Username, login.ID))
+		}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = resourceLabel(origin)
+	}
+
+	question := fmt.Sprintf("GophKeeper: сохранить логин %s для %s (%s)?", req.Username, origin, browserClient(req.Client))
+	if err := a.askApproval(ctx, question); err != nil {
+		return 0, err
+	}
+
+	return a.CreateLoginRecord(ctx, CreateLoginRequest{
+		Username: req.Username,
+		Password: req.Password,
+		Title:    title,
+		Resource: origin,
+	})
+}
+
+// loginMatch - локальная запись логина и ее метаданные
+type loginMatch struct {
+	rec  *LocalRecord
+	meta record.LoginMeta
+}
+
+// matchingLogins возвращает неудаленные логины, ресурс которых подходит странице
+func (a *App) matchingLogins(ctx context.Context, pageURL string) ([]loginMatch, error) {
+	if _, err := pageOrigin(pageURL); err != nil {
+		return nil, err
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeLogin})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var matches []loginMatch
+	for _, rec := range records {
+		var meta record.LoginMeta
+		if len(rec.Meta) == 0 || json.Unmarshal(rec.Meta, &meta) != nil {
+			continue
+		}
+		if MatchOrigin(meta.Resource, pageURL) {
+			matches = append(matches, loginMatch{rec: rec, meta: meta})
+		}
+	}
+	return matches, nil
+}
+
+func browserClient(name string) string {
+	if name == "" {
+		return "расширение браузера"
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		resource string
+		page     string
+		want     bool
+	}{
+		{"github.com", "https://github.com/login", true},
+		{"https://github.com", "https://github.com/login", true},
+		{"github.com", "https://www.github.com/", true},
+		{"www.github.com", "https://github.com/", true},
+		{"github.com", "https://gist.github.com/", true},
+		{"GitHub.com/login", "https://github.com/session", true},
+		{"github.com", "http://github.com/login", false},
+		{"http://intranet.local", "http://intranet.local/", true},
+		{"http://intranet.local", "https://intranet.local/", false},
+		{"github.com", "https://github.com.evil.io/", false},
+		{"github.com", "https://notgithub.com/", false},
+		{"gist.github.com", "https://github.com/", false},
+		{"localhost:3000", "https://localhost:3000/", true},
+		{"localhost:3000", "https://localhost:4000/", false},
+		{"localhost", "https://localhost:4000/", true},
+		{"github.com", "file:///etc/passwd", false},
+		{"github.com", "javascript:alert(1)", false},
+		{"", "https://github.com/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource+" "+tt.page, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchOrigin(tt.resource, tt.page))
+		})
+	}
+}
+
+func TestApp_Browser(t *testing.T) {
+	if _, err := exec.LookPath("true"); err != nil {
+		t.Skip("нет утилит true/false")
+	}
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	ctx := context.Background()
+
+	_, err := app.BrowserLogins(ctx, "https://github.com/login")
+	require.ErrorIs(t, err, ErrMasterKeyLocked)
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	saveLogin := func(title, resource string, data record.LoginData) int {
+		encrypted, err := app.encryptRecordData(data)
+		require.NoError(t, err)
+		meta, err := json.Marshal(record.LoginMeta{Title: title, Resource: resource})
+		require.NoError(t, err)
+		rec := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: encrypted, Meta: meta, LastModified: time.Now()}
+		require.NoError(t, app.storage.SaveRecord(rec))
+		return rec.ID
+	}
+
+	work := saveLogin("GitHub work", "https://github.com", record.LoginData{Username: "alice-work", Password: "w"})
+	saveLogin("GitHub", "github.com", record.LoginData{Username: "alice", Password: "p"})
+	other := saveLogin("GitLab", "gitlab.com", record.LoginData{Username: "bob", Password: "x"})
+
+	t.Run("logins match page origin", func(t *testing.T) {
+		logins, err := app.BrowserLogins(ctx, "https://github.com/login")
+		require.NoError(t, err)
+		require.Len(t, logins, 2)
+		assert.Equal(t, "GitHub", logins[0].Title)
+		assert.Equal(t, "alice", logins[0].Username)
+		assert.Equal(t, "alice-work", logins[1].Username)
+
+		_, err = app.BrowserLogins(ctx, "chrome://settings")
+		assert.Equal(t, apperr.Invalid, apperr.KindOf(err))
+	})
+
+	t.Run("credentials require matching origin and approval", func(t *testing.T) {
+		app.config.AgentConfirmCommand = "true"
+
+		creds, err := app.BrowserCredentials(ctx, "https://github.com/login", work, "test")
+		require.NoError(t, err)
+		assert.Equal(t, &BrowserCredentials{ID: work, Username: "alice-work", Password: "w"}, creds)
+
+		_, err = app.BrowserCredentials(ctx, "https://github.com/login", other, "test")
+		assert.ErrorIs(t, err, ErrOriginMismatch)
+
+		_, err = app.BrowserCredentials(ctx, "https://github.com/login", 999, "test")
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		app.config.AgentConfirmCommand = "false"
+		_, err = app.BrowserCredentials(ctx, "https://github.com/login", work, "test")
+		assert.ErrorIs(t, err, ErrAgentRequestDenied)
+
+		// Без программы подтверждения пароли не выдаются
+		app.config.AgentConfirmCommand = ""
+		_, err = app.BrowserCredentials(ctx, "https://github.com/login", work, "test")
+		assert.ErrorIs(t, err, ErrAgentRequestDenied)
+	})
+
+	t.Run("save rejects duplicate username", func(t *testing.T) {
+		app.config.AgentConfirmCommand = "true"
+		_, err := app.SaveBrowserLogin(ctx, BrowserSaveRequest{URL: "https://github.com/login", Username: "alice", Password: "new"})
+		assert.Equal(t, apperr.Conflict, apperr.KindOf(err))
+
+		_, err = app.SaveBrowserLogin(ctx, BrowserSaveRequest{URL: "https://github.com/login", Username: "alice"})
+		assert.Equal(t, apperr.Invalid, apperr.KindOf(err))
+	})
+}
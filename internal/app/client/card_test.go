@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_CreateCardRecord(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(RecordResponse{ID: 7})
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, app.InitMasterKey("master-password"))
+	ctx := context.Background()
+
+	req := CreateCardRequest{
+		CardNumber:  "5555 5555 5555 4444",
+		CardHolder:  "IVAN IVANOV",
+		ExpiryMonth: "12",
+		ExpiryYear:  strconv.Itoa(time.Now().Year() + 2),
+		CVV:         "123",
+		Title:       "Зарплатная",
+	}
+
+	bad := req
+	bad.CardNumber = "5555 5555 5555 4445"
+	_, err := app.CreateCardRecord(ctx, bad)
+	assert.ErrorContains(t, err, "неверные данные карты")
+	bad = req
+	bad.PaymentSystem = "visa"
+	_, err = app.CreateCardRecord(ctx, bad)
+	assert.ErrorContains(t, err, "does not match")
+	assert.Zero(t, requests, "неверная карта не отправляется на сервер")
+
+	id, err := app.CreateCardRecord(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	rec, err := app.storage.GetRecord(id)
+	require.NoError(t, err)
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Meta, &meta))
+	assert.Equal(t, "mastercard", meta["payment_system"])
+	require.NotNil(t, rec.Preview)
+	assert.Equal(t, "•••• 4444", rec.Preview.Masked)
+	assert.NotContains(t, string(rec.Meta), "5555")
+}
@@ -2,19 +2,26 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	gosync "sync"
-	"syscall"
 	"time"
 
 	"golang.org/x/exp/slog"
 
 	"gophkeeper/internal/app/client/config"
 	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/blob"
 	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/settings"
 	"gophkeeper/internal/domain/sync"
 	"gophkeeper/internal/domain/user"
 )
@@ -23,16 +30,34 @@ type App struct {
 	config         *config.Config
 	log            *slog.Logger
 	crypto         *crypto.MasterKeyManager
+	keyProvider    crypto.KeyProvider
+	stateCipher    *crypto.StateCipher
+	unlockThrottle *crypto.UnlockThrottle
 	encryptor      *crypto.RecordEncryptor
 	httpClient     *httpClient
 	storage        Storage
 	syncService    *SyncService
-	state          *AppState
-	masterKeyReady bool
-	authenticated  bool
+	hooks          *HookRunner
+	health         healthCache
+	device         *DeviceIdentity
+	state          *appState
 	wg             gosync.WaitGroup
 	cancel         context.CancelFunc
-	mu             gosync.RWMutex
+
+	// ctx живет до Close; в нем выполняются фоновые задачи, которые не должны
+	// отменяться вместе с запросом, начавшим их
+	ctx        context.Context
+	stop       context.CancelFunc
+	background gosync.WaitGroup
+
+	// deviceMu защищает device; остальное состояние - в state
+	deviceMu gosync.Mutex
+	// undoMu защищает журнал отмены
+	undoMu gosync.Mutex
+	// backupTargetsMu защищает файл целей резервного копирования
+	backupTargetsMu gosync.Mutex
+	// confirmMu выстраивает запросы подтверждения агента в очередь
+	confirmMu gosync.Mutex
 }
 
 // AppState хранит состояние приложения
@@ -42,11 +67,25 @@ type AppState struct {
 	LastSync      time.Time `json:"last_sync"`
 	RecordsCount  int       `json:"records_count"`
 	MasterKeyHash string    `json:"master_key_hash"`
+	// KeyFileChecksum - SHA-256 файла мастер-ключа, последним сохраненного на сервере
+	KeyFileChecksum string `json:"key_file_checksum,omitempty"`
+	// PublishedKeyHash - хэш из заголовка файла мастер-ключа на сервере;
+	// после смены мастер-пароля по нему сервер заменяет файл
+	PublishedKeyHash string `json:"published_key_hash,omitempty"`
+	// Workspace - текущий контекст команд (gophkeeper use), путь категории
+	Workspace string `json:"workspace,omitempty"`
+	// ClientVersion - версия клиента при прошлом запуске, для задач обновления
+	ClientVersion string `json:"client_version,omitempty"`
+	// LoggedInAt - время входа на сервер, от него считается срок сессии
+	LoggedInAt time.Time `json:"logged_in_at,omitempty"`
 }
 
 func New(cfg *config.Config, log *slog.Logger) (*App, error) {
+	keyProvider := crypto.NewOSKeyProvider(cfg.ConfigDir)
+	stateCipher := crypto.NewStateCipher(cfg.ConfigDir, keyProvider)
+
 	// Инициализируем менеджер мастер-ключа
-	state, err := loadAppState(cfg)
+	state, err := loadAppState(cfg, stateCipher)
 	if err != nil {
 		log.Warn("Не удалось загрузить состояние приложения", "error", err)
 		state = &AppState{}
@@ -57,6 +96,7 @@ func New(cfg *config.Config, log *slog.Logger) (*App, error) {
 		return nil, fmt.Errorf("ошибка инициализации мастер-ключа: %w", err)
 	}
 
+	masterKey.SetIdleTimeout(cfg.AutoLock)
 	encryptor := crypto.NewRecordEncryptor(masterKey)
 
 	// Инициализируем HTTP клиент
@@ -65,49 +105,57 @@ func New(cfg *config.Config, log *slog.Logger) (*App, error) {
 		return nil, fmt.Errorf("ошибка инициализации HTTP клиента: %w", err)
 	}
 
-	// Инициализируем локальное хранилище (используем SQLite)
-	var storage Storage
-	sqliteStorage, err := NewSQLiteStorage(cfg.DataPath)
+	// Инициализируем локальное хранилище (используем SQLite). Зашифрованная
+	// база откроется при первом обращении после разблокировки.
+	storage, err := openLocalStorage(cfg.DataPath, cfg.EncryptLocalDB, localDBKey(masterKey))
 	if err != nil {
 		log.Warn("Не удалось инициализировать SQLite, используем память", "error", err)
 		storage = NewMemoryStorage()
-	} else {
-		storage = sqliteStorage
 	}
 
 	app := &App{
-		config:     cfg,
-		log:        log,
-		crypto:     masterKey,
-		encryptor:  encryptor,
-		httpClient: httpCl,
-		storage:    storage,
-		state:      state,
+		config:      cfg,
+		log:         log,
+		crypto:      masterKey,
+		keyProvider: keyProvider,
+		stateCipher: stateCipher,
+		encryptor:   encryptor,
+		httpClient:  httpCl,
+		storage:     storage,
+		hooks:       NewHookRunner(cfg.ConfigDir, log),
+
+		unlockThrottle: crypto.NewUnlockThrottle(filepath.Join(cfg.ConfigDir, unlockAttemptsFile), stateCipher),
 	}
 
+	httpCl.deviceSource = app.deviceUUID
+	httpCl.deviceKey = app.deviceSigningKey
+	httpCl.meta = app
+	app.state = newAppState(*state, app.saveAppState)
+	app.ctx, app.stop = context.WithCancel(context.Background())
+
 	// Инициализируем сервис синхронизации
 	app.syncService = NewSyncService(app)
 
 	// Загружаем токен если он есть
 	if token, err := app.GetToken(); err == nil && token != "" {
 		httpCl.SetToken(token)
-		app.mu.Lock()
-		app.authenticated = true
-		app.mu.Unlock()
+		app.state.SetAuthenticated(true)
 		log.Debug("Токен загружен из файла")
 	}
 
 	return app, nil
 }
 
-func loadAppState(cfg *config.Config) (*AppState, error) {
+// loadAppState читает state.json. Файл зашифрован ключом, привязанным к машине:
+// он содержит логин и хэш мастер-ключа, а нужен до разблокировки.
+func loadAppState(cfg *config.Config, cipher *crypto.StateCipher) (*AppState, error) {
 	statePath := cfg.ConfigDir + "/state.json"
 
 	if _, err := os.Stat(statePath); os.IsNotExist(err) {
 		return &AppState{}, nil
 	}
 
-	data, err := os.ReadFile(statePath)
+	data, err := cipher.ReadFile(statePath)
 	if err != nil {
 		return nil, err
 	}
@@ -120,27 +168,48 @@ func loadAppState(cfg *config.Config) (*AppState, error) {
 	return &state, nil
 }
 
-func (a *App) saveAppState() error {
+func (a *App) saveAppState(state AppState) error {
 	statePath := a.config.ConfigDir + "/state.json"
-	data, err := json.MarshalIndent(a.state, "", "  ")
+	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(statePath, data, 0600)
+	return a.stateCipher.WriteFile(statePath, data)
 }
 
-func (a *App) Run() error {
-	ctx, cancel := context.WithCancel(context.Background())
+// Run запускает агент и работает, пока не отменен ctx (Ctrl-C, сигнал
+// завершения от сервиса) или не вызван Shutdown
+func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
 	a.cancel = cancel
+	defer cancel()
 
-	go a.handleSignals()
-
-	a.wg.Add(1)
+	a.wg.Add(6)
 	go func() {
 		defer a.wg.Done()
 		a.startSync(ctx)
 	}()
+	go func() {
+		defer a.wg.Done()
+		a.serveAgent(ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		a.serveAgentHTTP(ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		a.runAutoLock(ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		a.runHealthPinger(ctx)
+	}()
+	go func() {
+		defer a.wg.Done()
+		a.runBackupTargets(ctx)
+	}()
 
 	a.log.Info("Клиент запущен",
 		"server", a.config.ServerAddress,
@@ -148,14 +217,52 @@ func (a *App) Run() error {
 	)
 
 	a.wg.Wait()
+	a.log.Info("Агент остановлен")
 	return nil
 }
 
+// goBackground запускает задачу, которая переживает вызвавший ее запрос.
+// Задача получает контекст приложения: он отменяется в Close.
+func (a *App) goBackground(fn func(ctx context.Context)) {
+	a.background.Add(1)
+	go func() {
+		defer a.background.Done()
+		fn(a.ctx)
+	}()
+}
+
+// Close дожидается фоновых задач. Если ctx отменяется раньше (таймаут,
+// повторный Ctrl-C), задачи прерываются.
+func (a *App) Close(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		a.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		a.log.Debug("Прерываем фоновые задачи")
+		a.stop()
+		<-done
+	}
+	a.stop()
+}
+
 // IsInitialized проверяет, инициализирован ли клиент
 func (a *App) IsInitialized() bool {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
-	return a.state.Initialized
+	return a.state.Snapshot().Initialized
+}
+
+// SetKeyAlgorithms выбирает KDF и шифр для мастер-ключа, создаваемого InitMasterKey
+func (a *App) SetKeyAlgorithms(kdf, cipher string) error {
+	return a.crypto.SetAlgorithms(kdf, cipher)
+}
+
+// KeyAlgorithms возвращает KDF и шифр текущего мастер-ключа
+func (a *App) KeyAlgorithms() (kdf, cipher string) {
+	return a.crypto.Algorithms()
 }
 
 // InitMasterKey инициализирует мастер-ключ
@@ -170,28 +277,17 @@ func (a *App) InitMasterKey(password string) error {
 		return fmt.Errorf("ошибка получения хэша ключа: %w", err)
 	}
 
-	a.mu.Lock()
-	a.state.MasterKeyHash = keyHash
-	a.masterKeyReady = true
-	a.state.Initialized = true
-
-	if err := a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	a.state.SetMasterKeyReady(true)
+	if err := a.state.Update(func(s *AppState) {
+		s.MasterKeyHash = keyHash
+		s.Initialized = true
+	}); err != nil {
 		return fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
 	return nil
 }
 
-// CheckConnection проверяет соединение с сервером
-func (a *App) CheckConnection() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	return a.httpClient.HealthCheck(ctx)
-}
-
 // InitStorage инициализирует хранилище
 func (a *App) InitStorage() error {
 	// Проверяем, что таблицы созданы
@@ -199,20 +295,7 @@ func (a *App) InitStorage() error {
 	if err != nil {
 		return fmt.Errorf("ошибка инициализации хранилища: %w", err)
 	}
-	a.state.RecordsCount = count
-
-	return nil
-}
-
-// UnlockMasterKey разблокирует мастер-ключ
-func (a *App) UnlockMasterKey(password string) error {
-	if err := a.crypto.UnlockMasterKey(password); err != nil {
-		return fmt.Errorf("неверный мастер-пароль: %w", err)
-	}
-
-	a.mu.Lock()
-	a.masterKeyReady = true
-	a.mu.Unlock()
+	a.state.Modify(func(s *AppState) { s.RecordsCount = count })
 
 	return nil
 }
@@ -224,12 +307,8 @@ func (a *App) IsMasterKeyUnlocked() bool {
 
 // LockMasterKey блокирует мастер-ключ
 func (a *App) LockMasterKey() {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
 	a.crypto.Lock()
-	a.mu.Lock()
-	a.masterKeyReady = false
+	a.state.SetMasterKeyReady(false)
 }
 
 // HasLocalData проверяет наличие локальных данных
@@ -251,25 +330,26 @@ func (a *App) startSync(ctx context.Context) {
 			a.log.Info("Синхронизация остановлена")
 			return
 		case <-ticker.C:
+			// Агент работает долго: токен мог измениться после повторного входа
+			token, err := a.GetToken()
+			if err != nil {
+				a.log.Debug("Синхронизация пропущена: вход не выполнен")
+				continue
+			}
+			a.httpClient.SetToken(token)
+
 			if _, err := a.syncService.Sync(ctx); err != nil {
+				var merr *MaintenanceError
+				if errors.As(err, &merr) {
+					// Пауза уже записана в журнал при переходе в режим обслуживания
+					continue
+				}
 				a.log.Error("Ошибка синхронизации", "error", err)
 			}
 		}
 	}
 }
 
-func (a *App) handleSignals() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-
-	sig := <-sigChan
-	a.log.Info("Получен сигнал завершения", "signal", sig.String())
-
-	if a.cancel != nil {
-		a.cancel()
-	}
-}
-
 func (a *App) Shutdown() {
 	a.log.Info("Завершение работы клиента...")
 
@@ -283,17 +363,16 @@ func (a *App) Shutdown() {
 
 // IsAuthenticated проверяет, аутентифицирован ли пользователь
 func (a *App) IsAuthenticated() bool {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	if !a.authenticated {
-		token, err := a.GetToken()
-		if err == nil && token != "" {
-			a.authenticated = true
-		}
+	if a.state.Authenticated() {
+		return true
 	}
 
-	return a.authenticated
+	token, err := a.GetToken()
+	if err != nil || token == "" {
+		return false
+	}
+	a.state.SetAuthenticated(true)
+	return true
 }
 
 // GetToken возвращает сохраненный токен
@@ -301,7 +380,7 @@ func (a *App) GetToken() (string, error) {
 	tokenBytes, err := os.ReadFile(a.config.TokenPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("токен не найден. Выполните вход: gophkeeper auth login")
+			return "", ErrNotLoggedIn
 		}
 		return "", fmt.Errorf("ошибка чтения токена: %w", err)
 	}
@@ -321,19 +400,18 @@ func (a *App) SaveToken(token string) error {
 
 // ClearToken удаляет токен
 func (a *App) ClearToken() error {
-	a.mu.Lock()
-	a.authenticated = false
-	a.state.UserLogin = ""
+	a.state.SetAuthenticated(false)
 
 	if err := os.Remove(a.config.TokenPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("ошибка удаления токена: %w", err)
 	}
 
-	if err := a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err := a.state.Update(func(s *AppState) {
+		s.UserLogin = ""
+		s.LoggedInAt = time.Time{}
+	}); err != nil {
 		return fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
 	return nil
 }
@@ -348,29 +426,33 @@ func (a *App) Register(ctx context.Context, req user.BaseRequest) error {
 	return nil
 }
 
-// Login выполняет вход пользователя
+// Login выполняет вход пользователя. Если у пользователя включена двухфакторная
+// аутентификация, возвращает *MFARequiredError, и вход завершается через LoginMFA.
 func (a *App) Login(ctx context.Context, req user.BaseRequest) (string, error) {
 	token, err := a.httpClient.Login(ctx, req.Login, req.Password)
 	if err != nil {
 		return "", err
 	}
 
-	if err = a.SaveToken(token); err != nil {
-		return "", fmt.Errorf("ошибка сохранения токена: %w", err)
-	}
+	return token, a.loggedIn(req.Login, token)
+}
 
-	a.mu.Lock()
-	a.authenticated = true
-	a.state.UserLogin = req.Login
+// loggedIn сохраняет токен и логин после успешного входа
+func (a *App) loggedIn(login, token string) error {
+	if err := a.SaveToken(token); err != nil {
+		return fmt.Errorf("ошибка сохранения токена: %w", err)
+	}
 
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	a.state.SetAuthenticated(true)
+	if err := a.state.Update(func(s *AppState) {
+		s.UserLogin = login
+		s.LoggedInAt = time.Now()
+	}); err != nil {
 		a.log.Warn("Не удалось сохранить состояние", "error", err)
 	}
-	a.mu.Unlock()
 
-	a.log.Info("Вход выполнен успешно", "login", req.Login)
-	return token, nil
+	a.log.Info("Вход выполнен успешно", "login", login)
+	return nil
 }
 
 // ==================== Record Operations ====================
@@ -382,7 +464,7 @@ func (a *App) CreateLoginRecord(ctx context.Context, req CreateLoginRequest) (in
 	}
 
 	if !a.IsMasterKeyUnlocked() {
-		return 0, fmt.Errorf("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+		return 0, ErrMasterKeyLocked
 	}
 
 	// Подготавливаем метаданные (не шифруем для поиска)
@@ -395,21 +477,22 @@ func (a *App) CreateLoginRecord(ctx context.Context, req CreateLoginRequest) (in
 	metaJSON, _ := json.Marshal(meta)
 
 	// Подготавливаем зашифрованную запись
-	encryptedReq, err := a.prepareEncryptedRecord(record.RecTypeLogin, req, metaJSON)
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeLogin, req, metaJSON)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
 	}
 
 	// Отправляем на сервер через generic API
-	serverID, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
 	if err != nil {
 		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
-		return a.saveLocalRecord(record.RecTypeLogin, req)
+		return a.saveLocalRecord(ctx, record.RecTypeLogin, req)
 	}
 
 	// Сохраняем локально
 	localRec := &LocalRecord{
-		ServerID:      serverID,
+		ServerID:      created.ID,
+		UUID:          created.UUID,
 		Type:          record.RecTypeLogin,
 		EncryptedData: encryptedReq.Data,
 		Meta:          metaJSON,
@@ -424,15 +507,14 @@ func (a *App) CreateLoginRecord(ctx context.Context, req CreateLoginRequest) (in
 		a.log.Warn("Не удалось сохранить запись локально", "error", err)
 	}
 
-	a.mu.Lock()
-	a.state.RecordsCount++
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err = a.state.Update(incRecordsCount); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
-	return serverID, nil
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeLogin), req.Title, true)
+
+	return localRec.ID, nil
 }
 
 // CreateTextRecord создает текстовую запись с шифрованием
@@ -442,7 +524,7 @@ func (a *App) CreateTextRecord(ctx context.Context, req CreateTextRequest) (int,
 	}
 
 	if !a.IsMasterKeyUnlocked() {
-		return 0, fmt.Errorf("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+		return 0, ErrMasterKeyLocked
 	}
 
 	// Подготавливаем метаданные
@@ -455,21 +537,22 @@ func (a *App) CreateTextRecord(ctx context.Context, req CreateTextRequest) (int,
 	metaJSON, _ := json.Marshal(meta)
 
 	// Подготавливаем зашифрованную запись
-	encryptedReq, err := a.prepareEncryptedRecord(record.RecTypeText, req, metaJSON)
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeText, req, metaJSON)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
 	}
 
 	// Отправляем на сервер
-	serverID, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
 	if err != nil {
 		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
-		return a.saveLocalRecord(record.RecTypeText, req)
+		return a.saveLocalRecord(ctx, record.RecTypeText, req)
 	}
 
 	// Сохраняем локально
 	localRec := &LocalRecord{
-		ServerID:      serverID,
+		ServerID:      created.ID,
+		UUID:          created.UUID,
 		Type:          record.RecTypeText,
 		EncryptedData: encryptedReq.Data,
 		Meta:          metaJSON,
@@ -484,15 +567,14 @@ func (a *App) CreateTextRecord(ctx context.Context, req CreateTextRequest) (int,
 		a.log.Warn("Не удалось сохранить запись локально", "error", err)
 	}
 
-	a.mu.Lock()
-	a.state.RecordsCount++
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err = a.state.Update(incRecordsCount); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
-	return serverID, nil
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeText), req.Title, true)
+
+	return localRec.ID, nil
 }
 
 // CreateCardRecord создает запись карты с шифрованием
@@ -502,7 +584,24 @@ func (a *App) CreateCardRecord(ctx context.Context, req CreateCardRequest) (int,
 	}
 
 	if !a.IsMasterKeyUnlocked() {
-		return 0, fmt.Errorf("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+		return 0, ErrMasterKeyLocked
+	}
+
+	// Данные карты проверяются до шифрования: сервер их уже не увидит
+	card := record.CardData{
+		CardNumber:  req.CardNumber,
+		CardHolder:  req.CardHolder,
+		ExpiryMonth: req.ExpiryMonth,
+		ExpiryYear:  req.ExpiryYear,
+		CVV:         req.CVV,
+		PIN:         req.PIN,
+	}
+	if err := card.Validate(); err != nil {
+		return 0, fmt.Errorf("неверные данные карты: %w", err)
+	}
+	cardMeta := record.CardMeta{Title: req.Title, PaymentSystem: req.PaymentSystem}
+	if err := cardMeta.ApplyPaymentSystem(&card); err != nil {
+		return 0, fmt.Errorf("неверные данные карты: %w", err)
 	}
 
 	// Подготавливаем метаданные
@@ -512,24 +611,32 @@ func (a *App) CreateCardRecord(ctx context.Context, req CreateCardRequest) (int,
 		"category":  req.Category,
 		"tags":      req.Tags,
 	}
+	if cardMeta.PaymentSystem != "" {
+		meta["payment_system"] = cardMeta.PaymentSystem
+	}
+	// Срок карты виден без расшифровки: о нем предупреждает синхронизация
+	if expiresAt, err := record.CardExpiresAt(req.ExpiryMonth, req.ExpiryYear); err == nil {
+		meta["expires_at"] = expiresAt.Format(time.RFC3339)
+	}
 	metaJSON, _ := json.Marshal(meta)
 
 	// Подготавливаем зашифрованную запись
-	encryptedReq, err := a.prepareEncryptedRecord(record.RecTypeCard, req, metaJSON)
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeCard, req, metaJSON)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
 	}
 
 	// Отправляем на сервер
-	serverID, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
 	if err != nil {
 		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
-		return a.saveLocalRecord(record.RecTypeCard, req)
+		return a.saveLocalRecord(ctx, record.RecTypeCard, req)
 	}
 
 	// Сохраняем локально
 	localRec := &LocalRecord{
-		ServerID:      serverID,
+		ServerID:      created.ID,
+		UUID:          created.UUID,
 		Type:          record.RecTypeCard,
 		EncryptedData: encryptedReq.Data,
 		Meta:          metaJSON,
@@ -538,21 +645,22 @@ func (a *App) CreateCardRecord(ctx context.Context, req CreateCardRequest) (int,
 		CreatedAt:     time.Now(),
 		Synced:        true,
 		DeviceID:      req.DeviceID,
+		// Номер карты доступен только здесь, в открытом виде он не сохраняется
+		Preview: &RecordPreview{Masked: record.MaskCardNumber(req.CardNumber)},
 	}
 
 	if err := a.storage.SaveRecord(localRec); err != nil {
 		a.log.Warn("Не удалось сохранить запись локально", "error", err)
 	}
 
-	a.mu.Lock()
-	a.state.RecordsCount++
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err = a.state.Update(incRecordsCount); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
-	return serverID, nil
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeCard), req.Title, true)
+
+	return localRec.ID, nil
 }
 
 // CreateBinaryRecord создает бинарную запись с шифрованием
@@ -562,7 +670,7 @@ func (a *App) CreateBinaryRecord(ctx context.Context, req CreateBinaryRequest) (
 	}
 
 	if !a.IsMasterKeyUnlocked() {
-		return 0, fmt.Errorf("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+		return 0, ErrMasterKeyLocked
 	}
 
 	// Подготавливаем метаданные
@@ -573,24 +681,57 @@ func (a *App) CreateBinaryRecord(ctx context.Context, req CreateBinaryRequest) (
 		"tags":        req.Tags,
 		"description": req.Description,
 	}
+
+	// Тип определяется по содержимому, если его не указали: по нему сервер
+	// применяет политику вложений
+	content := []byte(req.Data)
+	if req.ContentType == "" {
+		req.ContentType = http.DetectContentType(content)
+	}
+	if err := a.scanUpload(ctx, PreUploadEvent{
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        int64(len(content)),
+		Title:       req.Title,
+	}, content); err != nil {
+		return 0, err
+	}
+
+	// Содержимое загружается отдельным блобом, в записи остается ссылка на него
+	checksum, key, err := a.storeBlob(ctx, content, blob.FileInfo{Filename: req.Filename, ContentType: req.ContentType})
+	if errors.Is(err, ErrUploadRejected) {
+		// Не сохраняем локально файл, который запрещен политикой вложений
+		return 0, err
+	}
+	if err != nil {
+		a.log.Warn("Не удалось загрузить файл на сервер, сохраняем локально", "error", err)
+		return a.saveLocalRecord(ctx, record.RecTypeBinary, req)
+	}
+	meta[blob.MetaKey] = checksum
 	metaJSON, _ := json.Marshal(meta)
 
+	blobReq := req
+	blobReq.Data = ""
+	blobReq.Blob = checksum
+	blobReq.BlobKey = key
+
 	// Подготавливаем зашифрованную запись
-	encryptedReq, err := a.prepareEncryptedRecord(record.RecTypeBinary, req, metaJSON)
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeBinary, blobReq, metaJSON)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
 	}
 
 	// Отправляем на сервер
-	serverID, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
 	if err != nil {
 		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
-		return a.saveLocalRecord(record.RecTypeBinary, req)
+		return a.saveLocalRecord(ctx, record.RecTypeBinary, req)
 	}
 
 	// Сохраняем локально
 	localRec := &LocalRecord{
-		ServerID:      serverID,
+		ServerID:      created.ID,
+		UUID:          created.UUID,
 		Type:          record.RecTypeBinary,
 		EncryptedData: encryptedReq.Data,
 		Meta:          metaJSON,
@@ -605,19 +746,172 @@ func (a *App) CreateBinaryRecord(ctx context.Context, req CreateBinaryRequest) (
 		a.log.Warn("Не удалось сохранить запись локально", "error", err)
 	}
 
-	a.mu.Lock()
-	a.state.RecordsCount++
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err = a.state.Update(incRecordsCount); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeBinary), req.Title, true)
+
+	return localRec.ID, nil
+}
+
+// CreateOTPRecord создает запись TOTP с шифрованием
+func (a *App) CreateOTPRecord(ctx context.Context, req CreateOTPRequest) (int, error) {
+	if !a.IsAuthenticated() {
+		return 0, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	if !a.IsMasterKeyUnlocked() {
+		return 0, ErrMasterKeyLocked
+	}
+
+	// Проверяем секрет до шифрования, чтобы не сохранить непригодную запись
+	otpData := record.OTPData{
+		Secret:    req.Secret,
+		Algorithm: req.Algorithm,
+		Digits:    req.Digits,
+		Period:    req.Period,
+	}
+	if err := otpData.Validate(); err != nil {
+		return 0, fmt.Errorf("некорректный секрет TOTP: %w", err)
+	}
+
+	// Подготавливаем метаданные
+	meta := map[string]interface{}{
+		"title":        req.Title,
+		"issuer":       req.Issuer,
+		"account_name": req.AccountName,
+		"category":     req.Category,
+		"tags":         req.Tags,
+	}
+	metaJSON, _ := json.Marshal(meta)
+
+	// Подготавливаем зашифрованную запись
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeOTP, req, metaJSON)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
+	}
+
+	// Отправляем на сервер
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	if err != nil {
+		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
+		return a.saveLocalRecord(ctx, record.RecTypeOTP, req)
+	}
+
+	// Сохраняем локально
+	localRec := &LocalRecord{
+		ServerID:      created.ID,
+		UUID:          created.UUID,
+		Type:          record.RecTypeOTP,
+		EncryptedData: encryptedReq.Data,
+		Meta:          metaJSON,
+		Version:       1,
+		LastModified:  time.Now(),
+		CreatedAt:     time.Now(),
+		Synced:        true,
+		DeviceID:      req.DeviceID,
+	}
+
+	if err := a.storage.SaveRecord(localRec); err != nil {
+		a.log.Warn("Не удалось сохранить запись локально", "error", err)
+	}
+
+	if err = a.state.Update(incRecordsCount); err != nil {
+		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeOTP), req.Title, true)
+
+	return localRec.ID, nil
+}
+
+// CreateSSHKeyRecord создает запись SSH-ключа с шифрованием.
+// Тип, длина и отпечаток ключа попадают в открытые метаданные.
+func (a *App) CreateSSHKeyRecord(ctx context.Context, req CreateSSHKeyRequest) (int, error) {
+	if !a.IsAuthenticated() {
+		return 0, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	if !a.IsMasterKeyUnlocked() {
+		return 0, ErrMasterKeyLocked
+	}
+
+	keyData := record.SSHKeyData{
+		PrivateKey:  req.PrivateKey,
+		PublicKey:   req.PublicKey,
+		Passphrase:  req.Passphrase,
+		Certificate: req.Certificate,
+	}
+	info, err := keyData.Parse()
+	if err != nil {
+		return 0, fmt.Errorf("некорректный SSH-ключ: %w", err)
+	}
+
+	// Открытый ключ всегда сохраняем, чтобы его можно было выгрузить без закрытого
+	if req.PublicKey == "" {
+		req.PublicKey = info.PublicKey
+	}
+
+	// Подготавливаем метаданные
+	meta := map[string]interface{}{
+		"title":           req.Title,
+		"key_type":        info.KeyType,
+		"bits":            info.Bits,
+		"fingerprint":     info.Fingerprint,
+		"comment":         req.Comment,
+		"hosts":           req.Hosts,
+		"has_certificate": req.Certificate != "",
+		"category":        req.Category,
+		"tags":            req.Tags,
+	}
+	metaJSON, _ := json.Marshal(meta)
+
+	// Подготавливаем зашифрованную запись
+	encryptedReq, err := a.prepareNewRecord(record.RecTypeSSHKey, req, metaJSON)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка подготовки зашифрованной записи: %w", err)
+	}
+
+	// Отправляем на сервер
+	created, err := a.httpClient.CreateRecord(ctx, encryptedReq)
+	if err != nil {
+		a.log.Warn("Не удалось создать запись на сервере, сохраняем локально", "error", err)
+		return a.saveLocalRecord(ctx, record.RecTypeSSHKey, req)
+	}
+
+	// Сохраняем локально
+	localRec := &LocalRecord{
+		ServerID:      created.ID,
+		UUID:          created.UUID,
+		Type:          record.RecTypeSSHKey,
+		EncryptedData: encryptedReq.Data,
+		Meta:          metaJSON,
+		Version:       1,
+		LastModified:  time.Now(),
+		CreatedAt:     time.Now(),
+		Synced:        true,
+		DeviceID:      req.DeviceID,
+	}
+
+	if err := a.storage.SaveRecord(localRec); err != nil {
+		a.log.Warn("Не удалось сохранить запись локально", "error", err)
+	}
+
+	if err = a.state.Update(incRecordsCount); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
 
-	return serverID, nil
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified, Synced: true})
+	a.fireRecordCreated(ctx, localRec.ID, string(record.RecTypeSSHKey), req.Title, true)
+
+	return localRec.ID, nil
 }
 
 // saveLocalRecord сохраняет запись локально без синхронизации
-func (a *App) saveLocalRecord(recType record.RecType, data interface{}) (int, error) {
+func (a *App) saveLocalRecord(ctx context.Context, recType record.RecType, data interface{}) (int, error) {
 	dataJSON, err := json.Marshal(data)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка сериализации данных: %w", err)
@@ -625,7 +919,7 @@ func (a *App) saveLocalRecord(recType record.RecType, data interface{}) (int, er
 
 	// Шифруем данные если мастер-ключ готов
 	var encryptedData string
-	if a.masterKeyReady {
+	if a.state.MasterKeyReady() {
 		encrypted, err := a.encryptor.EncryptRecord(dataJSON)
 		if err != nil {
 			return 0, fmt.Errorf("ошибка шифрования данных: %w", err)
@@ -643,18 +937,24 @@ func (a *App) saveLocalRecord(recType record.RecType, data interface{}) (int, er
 		CreatedAt:     time.Now(),
 		Synced:        false,
 	}
+	if card, ok := data.(CreateCardRequest); ok {
+		localRec.Preview = &RecordPreview{Masked: record.MaskCardNumber(card.CardNumber)}
+	}
 
 	if err := a.storage.SaveRecord(localRec); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения записи: %w", err)
 	}
 
-	a.mu.Lock()
-	a.state.RecordsCount++
-	if err = a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	if err = a.state.Update(incRecordsCount); err != nil {
 		return 0, fmt.Errorf("ошибка сохранения состояния: %w", err)
 	}
-	a.mu.Unlock()
+
+	var titled struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal(dataJSON, &titled)
+	a.rememberUndo(UndoEntry{Action: UndoCreate, RecordID: localRec.ID, Modified: localRec.LastModified})
+	a.fireRecordCreated(ctx, localRec.ID, string(recType), titled.Title, false)
 
 	return localRec.ID, nil
 }
@@ -665,9 +965,9 @@ func (a *App) GetRecord(ctx context.Context, id int) (*LocalRecord, error) {
 	if err != nil {
 		// Если нет локально, пробуем получить с сервера
 		if a.IsAuthenticated() {
-			serverRec, err := a.httpClient.GetRecord(ctx, id)
+			serverRec, err := a.httpClient.GetRecord(ctx, strconv.Itoa(id))
 			if err != nil {
-				return nil, fmt.Errorf("запись не найдена: %w", err)
+				return nil, fmt.Errorf("запись %d не найдена локально, ошибка запроса к серверу: %w", id, err)
 			}
 
 			localRec = FromServerRecord(serverRec)
@@ -677,12 +977,52 @@ func (a *App) GetRecord(ctx context.Context, id int) (*LocalRecord, error) {
 
 			return localRec, nil
 		}
-		return nil, fmt.Errorf("запись не найдена: %w", err)
+		return nil, err
 	}
 
 	return localRec, nil
 }
 
+// RefreshRecord возвращает запись, предварительно сверив ее версию с сервером
+// запросом HEAD. Полная запись загружается, только если локальная копия устарела.
+// Второе значение сообщает, была ли копия обновлена.
+func (a *App) RefreshRecord(ctx context.Context, id int) (*LocalRecord, bool, error) {
+	localRec, err := a.GetRecord(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Несинхронизированные изменения не перезаписываем
+	if !a.IsAuthenticated() || localRec.ServerID == 0 || !localRec.Synced {
+		return localRec, false, nil
+	}
+
+	head, err := a.httpClient.HeadRecord(ctx, localRec.ServerRef())
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка проверки версии записи: %w", err)
+	}
+	if head.Deleted {
+		return nil, false, fmt.Errorf("запись %d удалена на сервере", id)
+	}
+	if head.Version == localRec.Version {
+		return localRec, false, nil
+	}
+
+	serverRec, err := a.httpClient.GetRecord(ctx, localRec.ServerRef())
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка получения записи: %w", err)
+	}
+
+	updated := FromServerRecord(serverRec)
+	updated.ID = localRec.ID
+	updated.CreatedAt = localRec.CreatedAt
+	if err := a.storage.SaveRecord(updated); err != nil {
+		return nil, false, fmt.Errorf("ошибка сохранения записи: %w", err)
+	}
+
+	return updated, true, nil
+}
+
 // GetDecryptedRecord возвращает расшифрованную запись
 func (a *App) GetDecryptedRecord(ctx context.Context, id int) (interface{}, error) {
 	localRec, err := a.GetRecord(ctx, id)
@@ -698,26 +1038,144 @@ func (a *App) GetDecryptedRecord(ctx context.Context, id int) (interface{}, erro
 	return decryptedData, nil
 }
 
+// GetDecryptedPayload возвращает расшифрованные данные записи (JSON) в
+// защищенной памяти. Вызывающий закрывает результат после вывода.
+func (a *App) GetDecryptedPayload(ctx context.Context, id int) (*crypto.SecureBytes, error) {
+	localRec, err := a.GetRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(localRec.EncryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка декодирования base64: %w", err)
+	}
+	payload, err := a.encryptor.DecryptRecordSecure(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки данных: %w", err)
+	}
+	return payload, nil
+}
+
+// GetOTPCode генерирует текущий TOTP-код из расшифрованного секрета записи.
+// Возвращает код и время до его смены.
+func (a *App) GetOTPCode(ctx context.Context, id int) (string, time.Duration, error) {
+	localRec, err := a.GetRecord(ctx, id)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if localRec.Type != record.RecTypeOTP {
+		return "", 0, fmt.Errorf("запись %d не является записью TOTP (тип: %s)", id, localRec.Type)
+	}
+
+	var otpData record.OTPData
+	if err := a.decryptRecordData(localRec.EncryptedData, &otpData); err != nil {
+		return "", 0, fmt.Errorf("ошибка расшифровки данных: %w", err)
+	}
+
+	now := time.Now()
+	code, err := otpData.Code(now)
+	if err != nil {
+		return "", 0, fmt.Errorf("ошибка генерации кода: %w", err)
+	}
+
+	return code, otpData.Remaining(now), nil
+}
+
+// ExportRecord расшифровывает запись и записывает ее содержимое в файл с правами 0600.
+// Для SSH-ключа рядом сохраняется открытый ключ (<path>.pub) и сертификат (<path>-cert.pub).
+// Возвращает список созданных файлов.
+func (a *App) ExportRecord(ctx context.Context, id int, path string) ([]string, error) {
+	localRec, err := a.GetRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+
+	switch localRec.Type {
+	case record.RecTypeSSHKey:
+		var keyData record.SSHKeyData
+		if err := a.decryptRecordData(localRec.EncryptedData, &keyData); err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки данных: %w", err)
+		}
+		if keyData.PrivateKey != "" {
+			files[path] = []byte(keyData.PrivateKey)
+			if keyData.PublicKey != "" {
+				files[path+".pub"] = []byte(strings.TrimSpace(keyData.PublicKey) + "\n")
+			}
+		} else {
+			files[path] = []byte(strings.TrimSpace(keyData.PublicKey) + "\n")
+		}
+		if keyData.Certificate != "" {
+			files[strings.TrimSuffix(path, ".pub")+"-cert.pub"] = []byte(strings.TrimSpace(keyData.Certificate) + "\n")
+		}
+	case record.RecTypeBinary:
+		var binData CreateBinaryRequest
+		if err := a.decryptRecordData(localRec.EncryptedData, &binData); err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки данных: %w", err)
+		}
+		content := []byte(binData.Data)
+		if binData.Blob != "" {
+			var err error
+			if content, err = a.loadBlob(ctx, binData.Blob, binData.BlobKey); err != nil {
+				return nil, err
+			}
+		}
+		files[path] = content
+	default:
+		return nil, fmt.Errorf("экспорт не поддерживается для записей типа %s", localRec.Type)
+	}
+
+	written := make([]string, 0, len(files))
+	for name, content := range files {
+		if err := writeSecretFile(name, content); err != nil {
+			return written, fmt.Errorf("%s: %w", name, err)
+		}
+		written = append(written, name)
+	}
+	sort.Strings(written)
+
+	return written, nil
+}
+
 // ListRecords возвращает список записей
 func (a *App) ListRecords(ctx context.Context, filter *RecordFilter) ([]*LocalRecord, error) {
+	if err := a.resolveFolderFilter(ctx, filter); err != nil {
+		return nil, err
+	}
+
 	records, err := a.storage.ListRecords(filter)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения локальных записей: %w", err)
 	}
 
 	if a.IsAuthenticated() {
-		go func() {
+		// Синхронизация продолжается после возврата списка, поэтому
+		// выполняется в контексте приложения, а не запроса
+		a.goBackground(func(ctx context.Context) {
 			if _, err := a.syncService.Sync(ctx); err != nil {
 				a.log.Warn("Ошибка синхронизации", "error", err)
 			}
-		}()
+		})
 
 		if len(records) == 0 {
-			serverRecords, err := a.httpClient.ListRecords(ctx)
+			serverRecords, err := a.httpClient.ListRecords(ctx, filter)
 			if err == nil && len(serverRecords.Records) > 0 {
 				for _, item := range serverRecords.Records {
+					if err := ctx.Err(); err != nil {
+						return records, err
+					}
 					// Получаем полную запись с сервера
-					serverRec, err := a.httpClient.GetRecord(ctx, item.ID)
+					ref := item.UUID
+					if ref == "" {
+						ref = strconv.Itoa(item.ID)
+					}
+					serverRec, err := a.httpClient.GetRecord(ctx, ref)
 					if err != nil {
 						a.log.Warn("Не удалось получить запись с сервера", "error", err, "record_id", item.ID)
 						continue
@@ -726,6 +1184,11 @@ func (a *App) ListRecords(ctx context.Context, filter *RecordFilter) ([]*LocalRe
 					if err := a.storage.SaveRecord(localRec); err != nil {
 						a.log.Warn("Не удалось сохранить запись локально", "error", err, "record_id", item.ID)
 					}
+					// Сервер не знает о контексте, он проверяется локально
+					// вместе с остальными фильтрами
+					if filter != nil && !filter.matchesMeta(localRec.Meta) {
+						continue
+					}
 					records = append(records, localRec)
 				}
 			}
@@ -740,9 +1203,21 @@ func (a *App) UpdateRecord(ctx context.Context, id int, req GenericRecordRequest
 	// Получаем существующую запись
 	existingRec, err := a.storage.GetRecord(id)
 	if err != nil {
-		return fmt.Errorf("запись не найдена: %w", err)
+		return err
+	}
+
+	if record.IsLocked(existingRec.Meta) {
+		return ErrRecordLocked
 	}
 
+	return a.updateRecord(ctx, existingRec, req)
+}
+
+// updateRecord сохраняет новое состояние записи локально и на сервере
+func (a *App) updateRecord(ctx context.Context, existingRec *LocalRecord, req GenericRecordRequest) error {
+	id := existingRec.ID
+	before := *existingRec
+
 	// Обновляем поля
 	existingRec.Type = req.Type
 	existingRec.Meta = req.Meta
@@ -758,7 +1233,7 @@ func (a *App) UpdateRecord(ctx context.Context, id int, req GenericRecordRequest
 
 	// Синхронизируем с сервером
 	if a.IsAuthenticated() && existingRec.ServerID > 0 {
-		if err := a.httpClient.UpdateRecord(ctx, existingRec.ServerID, req); err != nil {
+		if err := a.httpClient.UpdateRecord(ctx, existingRec.ServerRef(), req); err != nil {
 			a.log.Warn("Не удалось синхронизировать обновление с сервером", "error", err, "record_id", id)
 		} else {
 			existingRec.Synced = true
@@ -768,6 +1243,14 @@ func (a *App) UpdateRecord(ctx context.Context, id int, req GenericRecordRequest
 		}
 	}
 
+	a.rememberUndo(UndoEntry{
+		Action:   UndoUpdate,
+		RecordID: id,
+		Before:   &before,
+		Modified: existingRec.LastModified,
+		Synced:   existingRec.Synced,
+	})
+
 	return nil
 }
 
@@ -776,32 +1259,47 @@ func (a *App) DeleteRecord(ctx context.Context, id int, permanent bool) error {
 	// Получаем запись
 	rec, err := a.storage.GetRecord(id)
 	if err != nil {
-		return fmt.Errorf("запись не найдена: %w", err)
+		return err
+	}
+
+	if record.IsLocked(rec.Meta) {
+		return ErrRecordLocked
 	}
+	before := *rec
 
 	if permanent {
 		if err := a.storage.HardDeleteRecord(id); err != nil {
 			return fmt.Errorf("ошибка удаления записи: %w", err)
 		}
-		a.state.RecordsCount--
 	} else {
 		if err := a.storage.DeleteRecord(id); err != nil {
 			return fmt.Errorf("ошибка удаления записи: %w", err)
 		}
 	}
 
+	synced := false
 	if a.IsAuthenticated() && rec.ServerID > 0 {
-		if err := a.httpClient.DeleteRecord(ctx, rec.ServerID); err != nil {
+		if err := a.httpClient.DeleteRecord(ctx, rec.ServerRef(), permanent); err != nil {
 			a.log.Warn("Не удалось синхронизировать удаление с сервером", "error", err, "record_id", id)
+		} else {
+			synced = true
 		}
 	}
 
-	a.mu.Lock()
-	if err := a.saveAppState(); err != nil {
-		a.mu.Unlock()
+	undo := UndoEntry{Action: UndoDelete, RecordID: id, Before: &before, Synced: synced}
+	if deleted, err := a.storage.GetRecord(id); err == nil {
+		undo.Modified = deleted.LastModified
+	}
+	a.rememberUndo(undo)
+
+	if err := a.state.Update(func(s *AppState) {
+		// Записи в корзине уже не учитываются в RecordsCount
+		if before.DeletedAt == nil {
+			s.RecordsCount--
+		}
+	}); err != nil {
 		a.log.Warn("Не удалось сохранить состояние", "error", err)
 	}
-	a.mu.Unlock()
 
 	return nil
 }
@@ -811,6 +1309,16 @@ func (a *App) Sync(ctx context.Context) (*SyncResult, error) {
 	return a.syncService.Sync(ctx)
 }
 
+// PlanSync возвращает план синхронизации, ничего не изменяя
+func (a *App) PlanSync(ctx context.Context) (*SyncPlan, error) {
+	return a.syncService.Plan(ctx)
+}
+
+// Config возвращает конфигурацию клиента
+func (a *App) Config() *config.Config {
+	return a.config
+}
+
 // GetSyncService возвращает сервис синхронизации
 func (a *App) GetSyncService() *SyncService {
 	return a.syncService
@@ -840,3 +1348,35 @@ func (a *App) GetDevices(ctx context.Context) ([]sync.DeviceInfo, error) {
 func (a *App) RemoveDevice(ctx context.Context, deviceID int) error {
 	return a.httpClient.RemoveDevice(ctx, deviceID)
 }
+
+// GetSettings получает настройки пользователя с сервера
+func (a *App) GetSettings(ctx context.Context) (map[string]string, error) {
+	values, err := a.httpClient.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.syncService.ApplyUserSettings(values)
+	return values, nil
+}
+
+// UpdateSettings обновляет настройки пользователя на сервере.
+// Значение nil сбрасывает настройку к значению по умолчанию.
+func (a *App) UpdateSettings(ctx context.Context, values map[string]*string) (map[string]string, error) {
+	for key, value := range values {
+		if value == nil {
+			continue
+		}
+		if err := settings.Validate(key, *value); err != nil {
+			return nil, fmt.Errorf("некорректная настройка: %w", err)
+		}
+	}
+
+	updated, err := a.httpClient.UpdateSettings(ctx, settings.UpdateRequest{Settings: values})
+	if err != nil {
+		return nil, err
+	}
+
+	a.syncService.ApplyUserSettings(updated)
+	return updated, nil
+}
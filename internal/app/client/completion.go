@@ -0,0 +1,46 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// RecordCompletion - вариант автодополнения ID записи в оболочке
+type RecordCompletion struct {
+	ID    int
+	Title string
+	Type  record.RecType
+}
+
+// CompleteRecords возвращает записи локального хранилища, ID которых
+// начинается с prefix, для автодополнения аргументов команд. В отличие
+// от ListRecords не запускает синхронизацию: оболочка ждет ответа на
+// каждое нажатие Tab. С trash=true возвращаются только записи из корзины.
+func (a *App) CompleteRecords(prefix string, trash bool) ([]RecordCompletion, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: trash})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей: %w", err)
+	}
+
+	var completions []RecordCompletion
+	for _, rec := range records {
+		if trash != (rec.DeletedAt != nil) {
+			continue
+		}
+		if !strings.HasPrefix(strconv.Itoa(rec.ID), prefix) {
+			continue
+		}
+		c := RecordCompletion{ID: rec.ID, Type: rec.Type}
+		if rec.Preview != nil {
+			c.Title = rec.Preview.Title
+		}
+		completions = append(completions, c)
+	}
+	sort.Slice(completions, func(i, j int) bool { return completions[i].ID < completions[j].ID })
+
+	return completions, nil
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_CompleteRecords(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+
+	save := func(title string, deleted bool) int {
+		meta, err := json.Marshal(map[string]string{"title": title})
+		require.NoError(t, err)
+		rec := &LocalRecord{Type: record.RecTypeLogin, Meta: meta, LastModified: time.Now()}
+		if deleted {
+			now := time.Now()
+			rec.DeletedAt = &now
+		}
+		require.NoError(t, app.storage.SaveRecord(rec))
+		return rec.ID
+	}
+
+	for i := 1; i <= 11; i++ {
+		save("record", false)
+	}
+	gone := save("Old bank", true)
+
+	all, err := app.CompleteRecords("", false)
+	require.NoError(t, err)
+	assert.Len(t, all, 11)
+	assert.Equal(t, 1, all[0].ID)
+	assert.Equal(t, "record", all[0].Title)
+
+	byPrefix, err := app.CompleteRecords("1", false)
+	require.NoError(t, err)
+	ids := make([]int, len(byPrefix))
+	for i, c := range byPrefix {
+		ids[i] = c.ID
+	}
+	assert.Equal(t, []int{1, 10, 11}, ids)
+
+	trash, err := app.CompleteRecords("", true)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, RecordCompletion{ID: gone, Title: "Old bank", Type: record.RecTypeLogin}, trash[0])
+}
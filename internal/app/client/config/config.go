@@ -1,12 +1,16 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
 )
 
 const (
@@ -15,6 +19,21 @@ const (
 	defaultEnv           = "local"
 	defaultMasterKeyPath = ".master.key"
 	defaultConfigDir     = ".gophkeeper"
+	defaultAutoLock      = 15 * time.Minute
+	defaultExpiryWarning = 30 * 24 * time.Hour
+
+	// MinUnlockWipeAfter - наименьший допустимый порог удаления данных,
+	// чтобы одна опечатка в пароле не стирала хранилище
+	MinUnlockWipeAfter = 3
+
+	defaultPINAttempts = 3
+	maxPINAttempts     = 10
+
+	defaultConnectTimeout  = 10 * time.Second
+	defaultKeepAlive       = 30 * time.Second
+	defaultHealthTimeout   = 5 * time.Second
+	defaultRequestTimeout  = 30 * time.Second
+	defaultTransferTimeout = 10 * time.Minute
 )
 
 type Config struct {
@@ -27,35 +46,76 @@ type Config struct {
 	DataPath      string `mapstructure:"data_path"`
 	SyncInterval  int    `mapstructure:"sync_interval_seconds"`
 	EnableTLS     bool   `mapstructure:"enable_tls"`
-	CACertPath    string `mapstructure:"ca_cert_path"`
-}
+	CACertPath    string `mapstructure:"ca_cert_path"` // дополнительный набор УЦ (PEM) для проверки сервера
+	// TLSPins - закрепленные ключи: SHA-256 SubjectPublicKeyInfo сертификата
+	// сервера или УЦ в цепочке, в виде sha256/<base64>; пусто - без закрепления
+	TLSPins []string `mapstructure:"tls_pins"`
+	// TLSClientCert и TLSClientKey - сертификат и ключ клиента (PEM) для
+	// взаимной аутентификации TLS
+	TLSClientCert string `mapstructure:"tls_client_cert"`
+	TLSClientKey  string `mapstructure:"tls_client_key"`
+	FetchIcons    bool   `mapstructure:"fetch_icons"` // загружать значки сайтов логинов
+	// EncryptMeta - шифровать метаданные записей (названия, адреса, теги)
+	// мастер-ключом; сервер хранит их как непрозрачные данные
+	EncryptMeta bool `mapstructure:"encrypt_meta"`
+	// EncryptLocalDB - локальная база зашифрована SQLCipher ключом,
+	// производным от мастер-ключа; переключается командой db encrypt/decrypt
+	EncryptLocalDB bool `mapstructure:"encrypt_local_db"`
+	// AutoLock - блокировка мастер-ключа после простоя (0 - выключена)
+	AutoLock time.Duration `mapstructure:"auto_lock"`
+	// ExpiryWarning - за сколько до срока действия записи предупреждать
+	// о нем при синхронизации (0 - не предупреждать)
+	ExpiryWarning time.Duration `mapstructure:"expiry_warning"`
+	// UnlockWipeAfter - удалить локальные данные после стольких неудачных
+	// попыток разблокировки подряд (0 - не удалять)
+	UnlockWipeAfter int `mapstructure:"unlock_wipe_after"`
+	// PINAttempts - число неверных PIN подряд, после которого PIN отключается
+	// и для разблокировки нужен мастер-пароль
+	PINAttempts int `mapstructure:"pin_attempts"`
+	// AgentHTTPAddr - адрес HTTP-API секретов агента на localhost
+	// (например 127.0.0.1:8200); пусто - API выключен
+	AgentHTTPAddr string `mapstructure:"agent_http_addr"`
+	// AgentConfirm - агент спрашивает пользователя перед выдачей каждого секрета
+	AgentConfirm bool `mapstructure:"agent_confirm"`
+	// AgentConfirmCommand - программа подтверждения: получает текст вопроса
+	// последним аргументом, код 0 разрешает выдачу (например zenity --question --text)
+	AgentConfirmCommand string `mapstructure:"agent_confirm_command"`
 
-// MustLoad загружает конфигурацию клиента
-func MustLoad() *Config {
-	// Определяем путь к .env файлу (относительно места запуска)
-	envPath := ".env"
-	if _, err := os.Stat(envPath); os.IsNotExist(err) {
-		// Пробуем найти .env в родительской директории
-		envPath = "../.env"
-	}
+	// Таймауты HTTP-клиента. ConnectTimeout ограничивает установку соединения
+	// и TLS-рукопожатие, остальные - запрос целиком для своего класса операций.
+	ConnectTimeout  time.Duration `mapstructure:"http_connect_timeout"`
+	KeepAlive       time.Duration `mapstructure:"http_keepalive"`
+	HealthTimeout   time.Duration `mapstructure:"http_health_timeout"`   // проверка доступности сервера
+	RequestTimeout  time.Duration `mapstructure:"http_request_timeout"`  // обычные запросы API
+	TransferTimeout time.Duration `mapstructure:"http_transfer_timeout"` // бинарные данные и синхронизация
 
-	// Загружаем .env файл если существует
-	if _, err := os.Stat(envPath); err == nil {
-		if err := godotenv.Load(envPath); err != nil {
-			fmt.Printf("Ошибка загрузки .env файла: %v\n", err)
-		}
-	}
+	// File - файл конфигурации, из которого загружены параметры; пусто - файла нет
+	File string `mapstructure:"-"`
+	// Profile - выбранный профиль из раздела profiles файла; пусто - без профиля
+	Profile string `mapstructure:"-"`
+	// RootDir - общий каталог клиента (config_dir). Данные профиля - токен,
+	// мастер-ключ, база и состояние синхронизации - хранятся в отдельном
+	// каталоге RootDir/profiles/<профиль>; без профиля RootDir совпадает с ConfigDir
+	RootDir string `mapstructure:"-"`
+	// Values - действующие значения всех параметров с их источниками
+	Values []Value `mapstructure:"-"`
+	// Warnings - замечания к файлу конфигурации, например неизвестные ключи
+	Warnings []string `mapstructure:"-"`
+}
 
-	viper.AutomaticEnv()
+// Load собирает конфигурацию клиента из слоев и проверяет ее. Ошибка
+// называет параметр, его значение и слой, из которого оно взято.
+func Load(opts Options) (*Config, error) {
+	loadDotEnv()
 
-	// Устанавливаем значения по умолчанию
-	viper.SetDefault("APP_ENV", defaultEnv)
-	viper.SetDefault("SERVER_ADDRESS", defaultServerAddress)
-	viper.SetDefault("LOG_LEVEL", defaultLogLevel)
-	viper.SetDefault("MASTER_KEY_PATH", defaultMasterKeyPath)
-	viper.SetDefault("CONFIG_DIR", defaultConfigDir)
-	viper.SetDefault("SYNC_INTERVAL_SECONDS", 30)
-	viper.SetDefault("ENABLE_TLS", false)
+	l, err := loadLayers(opts)
+	if err != nil {
+		return nil, err
+	}
+	p, values, err := l.parse()
+	if err != nil {
+		return nil, err
+	}
 
 	// Получаем домашнюю директорию пользователя
 	homeDir, err := os.UserHomeDir()
@@ -64,9 +124,13 @@ func MustLoad() *Config {
 	}
 
 	// Вычисляем пути для хранения данных
-	configDir := viper.GetString("CONFIG_DIR")
-	if configDir == defaultConfigDir {
-		configDir = filepath.Join(homeDir, configDir)
+	rootDir := p.strings["config_dir"]
+	if rootDir == defaultConfigDir {
+		rootDir = filepath.Join(homeDir, rootDir)
+	}
+	configDir := rootDir
+	if l.profile != "" {
+		configDir = ProfileDir(rootDir, l.profile)
 	}
 
 	// Создаем директории если их нет
@@ -74,41 +138,239 @@ func MustLoad() *Config {
 		fmt.Printf("Ошибка создания директории конфигурации: %v\n", err)
 	}
 
-	masterKeyPath := viper.GetString("MASTER_KEY_PATH")
+	masterKeyPath := p.strings["master_key_path"]
 	if masterKeyPath == defaultMasterKeyPath {
 		masterKeyPath = filepath.Join(configDir, masterKeyPath)
 	}
-
-	tokenPath := filepath.Join(configDir, "token")
-	dataPath := filepath.Join(configDir, "data.json")
+	warnings := l.unknownKeys()
+	if l.profile != "" && l.source("master_key_path") == SourceFile && masterKeyPath != "" {
+		warnings = append(warnings, fmt.Sprintf("%s: master_key_path на верхнем уровне файла - общий мастер-ключ "+
+			"для всех профилей; задайте его в разделе профиля или удалите, чтобы ключ хранился в %s", l.file, configDir))
+	}
 
 	config := &Config{
-		Env:           viper.GetString("APP_ENV"),
-		ServerAddress: viper.GetString("SERVER_ADDRESS"),
-		LogLevel:      viper.GetString("LOG_LEVEL"),
+		Env:           p.strings["app_env"],
+		ServerAddress: p.strings["server_address"],
+		LogLevel:      p.strings["log_level"],
 		MasterKeyPath: masterKeyPath,
 		ConfigDir:     configDir,
-		TokenPath:     tokenPath,
-		DataPath:      dataPath,
-		SyncInterval:  viper.GetInt("SYNC_INTERVAL_SECONDS"),
-		EnableTLS:     viper.GetBool("ENABLE_TLS"),
-		CACertPath:    viper.GetString("CA_CERT_PATH"),
+		TokenPath:     filepath.Join(configDir, "token"),
+		DataPath:      filepath.Join(configDir, "data.json"),
+		SyncInterval:  p.ints["sync_interval_seconds"],
+		EnableTLS:     p.bools["enable_tls"],
+		CACertPath:    p.strings["ca_cert_path"],
+		TLSPins:       p.lists["tls_pins"],
+		TLSClientCert: p.strings["tls_client_cert"],
+		TLSClientKey:  p.strings["tls_client_key"],
+		FetchIcons:    p.bools["fetch_icons"],
+		EncryptMeta:   p.bools["encrypt_meta"],
+		AutoLock:      p.durations["auto_lock"],
+		ExpiryWarning: p.durations["expiry_warning"],
+
+		EncryptLocalDB: p.bools["encrypt_local_db"],
+
+		UnlockWipeAfter: p.ints["unlock_wipe_after"],
+		PINAttempts:     p.ints["pin_attempts"],
+		AgentHTTPAddr:   p.strings["agent_http_addr"],
+
+		AgentConfirm:        p.bools["agent_confirm"],
+		AgentConfirmCommand: p.strings["agent_confirm_command"],
+
+		ConnectTimeout:  p.durations["http_connect_timeout"],
+		KeepAlive:       p.durations["http_keepalive"],
+		HealthTimeout:   p.durations["http_health_timeout"],
+		RequestTimeout:  p.durations["http_request_timeout"],
+		TransferTimeout: p.durations["http_transfer_timeout"],
+
+		File:     l.file,
+		Profile:  l.profile,
+		RootDir:  rootDir,
+		Values:   values,
+		Warnings: warnings,
 	}
 
 	// Валидация конфигурации
 	if err := config.validate(); err != nil {
-		panic(fmt.Sprintf("Ошибка конфигурации: %v", err))
+		var kerr *keyError
+		if errors.As(err, &kerr) {
+			if k, lerr := lookupKey(kerr.key); lerr == nil {
+				r := l.lookup(k)
+				err = fmt.Errorf("%w (значение %q: %s)", err, r.value, l.describe(k, r.source))
+				switch r.source {
+				case SourceProfile:
+					err = fmt.Errorf("%w. Исправьте: gophkeeper config set --profile %s %s <значение>", err, l.profile, k.key)
+				case SourceFile, SourceDefault:
+					err = fmt.Errorf("%w. Исправьте: gophkeeper config set %s <значение>", err, k.key)
+				}
+			}
+		}
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// loadDotEnv загружает .env из текущего или родительского каталога, если он
+// есть: его переменные входят в слой окружения
+func loadDotEnv() {
+	envPath := ".env"
+	if _, err := os.Stat(envPath); os.IsNotExist(err) {
+		envPath = "../.env"
 	}
 
-	return config
+	if _, err := os.Stat(envPath); err == nil {
+		if err := godotenv.Load(envPath); err != nil {
+			fmt.Printf("Ошибка загрузки .env файла: %v\n", err)
+		}
+	}
+}
+
+// ProfileDir возвращает каталог данных профиля в общем каталоге клиента
+func ProfileDir(rootDir, profile string) string {
+	return filepath.Join(rootDir, "profiles", profile)
+}
+
+// FilePath возвращает файл, в который пишет config set: загруженный или
+// config.yaml в каталоге конфигурации
+func (c *Config) FilePath() string {
+	if c.File != "" {
+		return c.File
+	}
+	return filepath.Join(c.ConfigDir, ConfigFileName)
+}
+
+// Lookup возвращает действующее значение параметра по имени из команды
+// (auto-lock) или файла (auto_lock)
+func (c *Config) Lookup(key string) (Value, error) {
+	k, err := lookupKey(key)
+	if err != nil {
+		return Value{}, err
+	}
+	for _, v := range c.Values {
+		if v.Key == k.key {
+			return v, nil
+		}
+	}
+	return Value{}, fmt.Errorf("значение '%s' не загружено", k.key)
+}
+
+// keyError - недопустимое значение параметра key
+type keyError struct {
+	key string
+	msg string
+}
+
+func (e *keyError) Error() string {
+	return e.key + ": " + e.msg
+}
+
+func invalid(key, format string, args ...any) error {
+	return &keyError{key: key, msg: fmt.Sprintf(format, args...)}
 }
 
 func (c *Config) validate() error {
 	if c.ServerAddress == "" {
-		return fmt.Errorf("server_address не может быть пустым")
+		return invalid("server_address", "не может быть пустым")
 	}
 	if c.MasterKeyPath == "" {
-		return fmt.Errorf("master_key_path не может быть пустым")
+		return invalid("master_key_path", "не может быть пустым")
+	}
+	if c.SyncInterval <= 0 {
+		return invalid("sync_interval_seconds", "должен быть положительным")
+	}
+	if c.AutoLock < 0 {
+		return invalid("auto_lock", "не может быть отрицательным")
+	}
+	if c.ExpiryWarning < 0 {
+		return invalid("expiry_warning", "не может быть отрицательным")
+	}
+	if c.UnlockWipeAfter < 0 || (c.UnlockWipeAfter > 0 && c.UnlockWipeAfter < MinUnlockWipeAfter) {
+		return invalid("unlock_wipe_after", "должен быть 0 или не меньше %d", MinUnlockWipeAfter)
+	}
+	if c.PINAttempts < 1 || c.PINAttempts > maxPINAttempts {
+		return invalid("pin_attempts", "должен быть от 1 до %d", maxPINAttempts)
+	}
+	for _, pin := range c.TLSPins {
+		if err := ValidatePin(pin); err != nil {
+			return invalid("tls_pins", "%v", err)
+		}
+	}
+	if (c.TLSClientCert == "") != (c.TLSClientKey == "") {
+		return invalid("tls_client_cert", "задается вместе с tls_client_key")
+	}
+	if !c.EnableTLS && (len(c.TLSPins) > 0 || c.TLSClientCert != "") {
+		return invalid("enable_tls", "tls_pins и tls_client_cert действуют только с enable_tls")
+	}
+	if c.AgentHTTPAddr != "" {
+		if err := ValidateLoopbackAddr(c.AgentHTTPAddr); err != nil {
+			return invalid("agent_http_addr", "%v", err)
+		}
+	}
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"http_connect_timeout", c.ConnectTimeout},
+		{"http_health_timeout", c.HealthTimeout},
+		{"http_request_timeout", c.RequestTimeout},
+		{"http_transfer_timeout", c.TransferTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value <= 0 {
+			return invalid(t.name, "должен быть положительным")
+		}
+	}
+	if c.KeepAlive < 0 {
+		return invalid("http_keepalive", "не может быть отрицательным")
+	}
+	return nil
+}
+
+// PinPrefix - префикс закрепленного ключа
+const PinPrefix = "sha256/"
+
+// ValidatePin проверяет закрепленный ключ: sha256/ и SHA-256 в base64
+func ValidatePin(pin string) error {
+	hash, ok := strings.CutPrefix(pin, PinPrefix)
+	if !ok {
+		return fmt.Errorf("ключ %q должен начинаться с %s", pin, PinPrefix)
+	}
+	sum, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil || len(sum) != 32 {
+		return fmt.Errorf("ключ %q: ожидается SHA-256 в base64", pin)
+	}
+	return nil
+}
+
+// stringList разбирает список из файла (YAML-список) или окружения
+// (значения через запятую)
+func stringList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// ValidateLoopbackAddr проверяет, что адрес host:port слушает только
+// локальные соединения: секреты не должны быть доступны по сети
+func ValidateLoopbackAddr(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("ожидается адрес host:port: %w", err)
+	}
+	if port == "" {
+		return fmt.Errorf("не указан порт")
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("допускается только адрес localhost, 127.0.0.1 или ::1, получен %q", host)
 	}
 	return nil
 }
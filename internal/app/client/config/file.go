@@ -0,0 +1,441 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName - файл локальной конфигурации в каталоге конфигурации
+const ConfigFileName = "config.yaml"
+
+// configFileNames - имена, под которыми ищется файл конфигурации
+var configFileNames = []string{"config.yaml", "config.yml", "config.toml"}
+
+// Служебные ключи файла: выбранный профиль и разделы профилей
+const (
+	fileKeyProfile  = "profile"
+	fileKeyProfiles = "profiles"
+)
+
+// kind - тип значения параметра
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindDuration
+	kindList
+)
+
+// keySpec описывает параметр конфигурации
+type keySpec struct {
+	key         string // имя в командах config get/set
+	name        string // ключ в файле; в верхнем регистре - переменная окружения
+	kind        kind
+	def         any // значение по умолчанию
+	description string
+	// normalize проверяет и приводит значение config set; nil - по типу
+	normalize func(string) (string, error)
+}
+
+// env возвращает переменную окружения параметра
+func (k keySpec) env() string {
+	return strings.ToUpper(k.name)
+}
+
+var keySpecs = []keySpec{
+	{key: "server-address", name: "server_address", def: defaultServerAddress,
+		description: "адрес сервера GophKeeper", normalize: normalizeNonEmpty},
+	{key: "app-env", name: "app_env", def: defaultEnv,
+		description: "окружение: local, dev или prod (формат журнала)", normalize: normalizeEnv},
+	{key: "log-level", name: "log_level", def: defaultLogLevel,
+		description: "уровень журнала", normalize: normalizeNonEmpty},
+	{key: "config-dir", name: "config_dir", def: defaultConfigDir,
+		description: "каталог данных клиента (по умолчанию ~/.gophkeeper)", normalize: normalizeNonEmpty},
+	{key: "master-key-path", name: "master_key_path", def: defaultMasterKeyPath,
+		description: "файл мастер-ключа (по умолчанию в каталоге данных)", normalize: normalizeNonEmpty},
+	{key: "sync-interval", name: "sync_interval_seconds", kind: kindInt, def: 30,
+		description: "интервал фоновой синхронизации в секундах", normalize: normalizePositiveInt},
+	{key: "enable-tls", name: "enable_tls", kind: kindBool, def: false,
+		description: "подключаться к серверу по HTTPS (true, false)"},
+	{key: "ca-cert-path", name: "ca_cert_path",
+		description: "дополнительный набор УЦ (PEM) для проверки сервера (off - не задан)", normalize: normalizeOptional},
+	{key: "tls-pins", name: "tls_pins", kind: kindList,
+		description: "закрепленные ключи sha256/<base64> через запятую (off - без закрепления)", normalize: normalizePins},
+	{key: "tls-client-cert", name: "tls_client_cert",
+		description: "сертификат клиента (PEM) для взаимной аутентификации TLS (off - не задан)", normalize: normalizeOptional},
+	{key: "tls-client-key", name: "tls_client_key",
+		description: "ключ сертификата клиента (PEM) (off - не задан)", normalize: normalizeOptional},
+	{key: "fetch-icons", name: "fetch_icons", kind: kindBool, def: false,
+		description: "загружать значки сайтов логинов (true, false)"},
+	{key: "encrypt-meta", name: "encrypt_meta", kind: kindBool, def: false,
+		description: "шифровать метаданные записей мастер-ключом (true, false)"},
+	{key: "encrypt-local-db", name: "encrypt_local_db", kind: kindBool, def: false,
+		description: "локальная база зашифрована SQLCipher (true, false; файл переводят config encrypt-db и decrypt-db)"},
+	{key: "auto-lock", name: "auto_lock", kind: kindDuration, def: defaultAutoLock,
+		description: "блокировка мастер-ключа после простоя (например 15m, 1h; off - выключить)", normalize: normalizeAutoLock},
+	{key: "expiry-warning", name: "expiry_warning", kind: kindDuration, def: defaultExpiryWarning,
+		description: "предупреждать о сроке действия записей за столько до него (например 720h; off - не предупреждать)"},
+	{key: "unlock-wipe-after", name: "unlock_wipe_after", kind: kindInt, def: 0,
+		description: "удалять локальные данные после N неудачных попыток разблокировки (0 - не удалять)", normalize: normalizeWipeAfter},
+	{key: "pin-attempts", name: "pin_attempts", kind: kindInt, def: defaultPINAttempts,
+		description: fmt.Sprintf("отключать PIN после N неверных попыток подряд (1-%d)", maxPINAttempts), normalize: normalizePINAttempts},
+	{key: "agent-http-addr", name: "agent_http_addr",
+		description: "адрес HTTP-API секретов агента на localhost, например 127.0.0.1:8200 (off - выключить)", normalize: normalizeAgentHTTPAddr},
+	{key: "agent-confirm", name: "agent_confirm", kind: kindBool, def: false,
+		description: "спрашивать подтверждение перед выдачей агентом каждого секрета (true, false)"},
+	{key: "agent-confirm-command", name: "agent_confirm_command",
+		description: "программа подтверждения запросов к агенту, например \"zenity --question --text\" (off - не задана)", normalize: normalizeOptional},
+	{key: "http-connect-timeout", name: "http_connect_timeout", kind: kindDuration, def: defaultConnectTimeout,
+		description: "таймаут установки соединения и TLS-рукопожатия", normalize: normalizePositiveDuration},
+	{key: "http-keepalive", name: "http_keepalive", kind: kindDuration, def: defaultKeepAlive,
+		description: "интервал keep-alive соединений (off - выключен)"},
+	{key: "http-health-timeout", name: "http_health_timeout", kind: kindDuration, def: defaultHealthTimeout,
+		description: "таймаут проверки доступности сервера", normalize: normalizePositiveDuration},
+	{key: "http-request-timeout", name: "http_request_timeout", kind: kindDuration, def: defaultRequestTimeout,
+		description: "таймаут обычных запросов API", normalize: normalizePositiveDuration},
+	{key: "http-transfer-timeout", name: "http_transfer_timeout", kind: kindDuration, def: defaultTransferTimeout,
+		description: "таймаут передачи файлов и синхронизации", normalize: normalizePositiveDuration},
+}
+
+// lookupKey находит параметр по имени из команды (auto-lock) или файла (auto_lock)
+func lookupKey(key string) (keySpec, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for _, k := range keySpecs {
+		if k.key == key || k.name == key {
+			return k, nil
+		}
+	}
+
+	msg := fmt.Sprintf("неизвестный ключ '%s'", key)
+	if similar, ok := suggestKey(key); ok {
+		msg += fmt.Sprintf(", возможно, имелся в виду '%s'", similar.key)
+	}
+	return keySpec{}, fmt.Errorf("%s. Список ключей: gophkeeper config list", msg)
+}
+
+// suggestKey возвращает похожий параметр для сообщения об опечатке
+func suggestKey(key string) (keySpec, bool) {
+	var best keySpec
+	found, bestDistance := false, 3
+	for _, k := range keySpecs {
+		for _, candidate := range []string{k.key, k.name} {
+			if d := editDistance(key, candidate); d < bestDistance {
+				best, found, bestDistance = k, true, d
+			}
+		}
+	}
+	return best, found
+}
+
+// editDistance - расстояние Левенштейна
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// SettableKeys возвращает ключи, доступные для config set, с описаниями
+func SettableKeys() map[string]string {
+	keys := make(map[string]string, len(keySpecs))
+	for _, k := range keySpecs {
+		keys[k.key] = k.description
+	}
+	return keys
+}
+
+// SetFileValue проверяет значение и записывает его в файл конфигурации,
+// в раздел профиля, если он задан. Остальные ключи файла сохраняются.
+// Переменные окружения и флаги имеют приоритет над файлом.
+func SetFileValue(path, profile, key, value string) (string, error) {
+	k, err := lookupKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	normalized, err := k.normalizeValue(value)
+	if err != nil {
+		return "", fmt.Errorf("неверное значение для '%s': %w", k.key, err)
+	}
+
+	values, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+	section := values
+	if profile != "" && k.name == "config_dir" {
+		return "", errProfileConfigDir
+	}
+	if profile != "" {
+		section, err = profileSection(values, profile, true)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	section[k.name] = k.fileValue(normalized)
+
+	if err := writeFile(path, values); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// fileValue возвращает значение для записи в файл: логические значения,
+// числа и списки записываются своими типами YAML и TOML
+func (k keySpec) fileValue(normalized string) interface{} {
+	switch k.kind {
+	case kindBool:
+		if b, err := strconv.ParseBool(normalized); err == nil {
+			return b
+		}
+	case kindInt:
+		if n, err := strconv.Atoi(normalized); err == nil {
+			return n
+		}
+	case kindList:
+		return stringList([]string{normalized})
+	}
+	return normalized
+}
+
+// normalizeValue проверяет значение config set
+func (k keySpec) normalizeValue(value string) (string, error) {
+	if k.normalize != nil {
+		return k.normalize(value)
+	}
+	switch k.kind {
+	case kindBool:
+		return normalizeBool(value)
+	case kindInt:
+		return normalizeInt(value)
+	case kindDuration:
+		return normalizeDuration(value)
+	default:
+		return normalizeOptional(value)
+	}
+}
+
+func readFile(path string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return values, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения конфигурации: %w", err)
+	}
+
+	if isTOML(path) {
+		err = toml.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора конфигурации %s: %w", path, err)
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	return values, nil
+}
+
+func writeFile(path string, values map[string]interface{}) error {
+	var data []byte
+	var err error
+	if isTOML(path) {
+		data, err = toml.Marshal(values)
+	} else {
+		data, err = yaml.Marshal(values)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации конфигурации: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("ошибка создания директории конфигурации: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("ошибка записи конфигурации: %w", err)
+	}
+	return nil
+}
+
+// isTOML сообщает, что файл в формате TOML; остальные читаются как YAML
+func isTOML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeDuration(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "off", "0", "never":
+		return "0s", nil
+	}
+
+	d, err := parseDuration(value)
+	if err != nil {
+		return "", err
+	}
+	if d < 0 {
+		return "", fmt.Errorf("значение не может быть отрицательным")
+	}
+	return d.String(), nil
+}
+
+func normalizeAutoLock(value string) (string, error) {
+	normalized, err := normalizeDuration(value)
+	if err != nil || normalized == "0s" {
+		return normalized, err
+	}
+	if d, _ := time.ParseDuration(normalized); d < time.Minute {
+		return "", fmt.Errorf("минимум 1m")
+	}
+	return normalized, nil
+}
+
+func normalizePositiveDuration(value string) (string, error) {
+	d, err := parseDuration(value)
+	if err != nil {
+		return "", err
+	}
+	if d <= 0 {
+		return "", fmt.Errorf("значение должно быть положительным")
+	}
+	return d.String(), nil
+}
+
+func normalizeBool(value string) (string, error) {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("ожидается true или false")
+	}
+	return strconv.FormatBool(b), nil
+}
+
+func normalizeNonEmpty(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("значение не может быть пустым")
+	}
+	return value, nil
+}
+
+func normalizeEnv(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "local", "dev", "prod":
+		return value, nil
+	}
+	return "", fmt.Errorf("ожидается local, dev или prod")
+}
+
+func normalizeInt(value string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("ожидается целое число")
+	}
+	return strconv.Itoa(n), nil
+}
+
+func normalizePositiveInt(value string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("ожидается целое число")
+	}
+	if n <= 0 {
+		return "", fmt.Errorf("значение должно быть положительным")
+	}
+	return strconv.Itoa(n), nil
+}
+
+func normalizeWipeAfter(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "off", "never":
+		return "0", nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("ожидается целое число")
+	}
+	if n < 0 || (n > 0 && n < MinUnlockWipeAfter) {
+		return "", fmt.Errorf("0 или не меньше %d", MinUnlockWipeAfter)
+	}
+	return strconv.Itoa(n), nil
+}
+
+func normalizePINAttempts(value string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return "", fmt.Errorf("ожидается целое число")
+	}
+	if n < 1 || n > maxPINAttempts {
+		return "", fmt.Errorf("от 1 до %d", maxPINAttempts)
+	}
+	return strconv.Itoa(n), nil
+}
+
+// normalizeOptional - необязательная строка; off сбрасывает значение
+func normalizeOptional(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "off") {
+		return "", nil
+	}
+	return value, nil
+}
+
+func normalizePins(value string) (string, error) {
+	value, _ = normalizeOptional(value)
+	pins := stringList([]string{value})
+	for _, pin := range pins {
+		if err := ValidatePin(pin); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(pins, ","), nil
+}
+
+func normalizeAgentHTTPAddr(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "off", "":
+		return "", nil
+	}
+
+	if err := ValidateLoopbackAddr(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Конфигурация собирается из слоев; каждый следующий переопределяет
+// предыдущие: значения по умолчанию < файл (YAML или TOML) < раздел
+// выбранного профиля в файле < переменные окружения < флаги командной строки.
+
+// ProfileEnv - переменная окружения с выбранным профилем
+const ProfileEnv = "GOPHKEEPER_PROFILE"
+
+// Source - слой, из которого взято значение параметра
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceProfile Source = "profile"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Value - действующее значение параметра и его источник
+type Value struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Source      Source `json:"source"`
+	Description string `json:"description"`
+}
+
+// Options - откуда загружать конфигурацию сверх значений по умолчанию
+type Options struct {
+	// File - файл конфигурации (--config); пусто - config.yaml, config.yml
+	// или config.toml в ~/.gophkeeper или текущем каталоге
+	File string
+	// Profile - профиль (--profile); пусто - GOPHKEEPER_PROFILE или ключ
+	// profile файла
+	Profile string
+	// Flags - значения флагов командной строки по ключам файла (server_address)
+	Flags map[string]string
+	// IgnoreProfile - загрузить без профиля: config set создает новый профиль
+	IgnoreProfile bool
+}
+
+// layers - загруженные слои конфигурации
+type layers struct {
+	file    string
+	profile string
+	values  map[string]interface{} // параметры верхнего уровня файла
+	section map[string]interface{} // параметры выбранного профиля
+	flags   map[string]string
+}
+
+// raw - значение параметра в виде строки и его слой
+type raw struct {
+	value  string
+	source Source
+}
+
+// loadLayers находит файл конфигурации и выбирает профиль
+func loadLayers(opts Options) (*layers, error) {
+	l := &layers{flags: opts.Flags, values: map[string]interface{}{}}
+
+	l.file = opts.File
+	if l.file == "" {
+		l.file = findConfigFile()
+	} else if _, err := os.Stat(l.file); err != nil {
+		return nil, fmt.Errorf("файл конфигурации %s недоступен: %w", l.file, err)
+	}
+	if l.file != "" {
+		values, err := readFile(l.file)
+		if err != nil {
+			return nil, err
+		}
+		l.values = values
+	}
+
+	if opts.IgnoreProfile {
+		return l, nil
+	}
+	l.profile = opts.Profile
+	if l.profile == "" {
+		l.profile = os.Getenv(ProfileEnv)
+	}
+	if l.profile == "" {
+		if p, ok := l.values[fileKeyProfile]; ok {
+			l.profile = fmt.Sprint(p)
+		}
+	}
+	if l.profile != "" {
+		if err := ValidateProfileName(l.profile); err != nil {
+			return nil, err
+		}
+		hint := fmt.Sprintf("Создайте профиль: gophkeeper config set --profile %s server-address <адрес>", l.profile)
+		if l.file == "" {
+			return nil, fmt.Errorf("%w: %q, файла конфигурации нет. %s", ErrProfileNotFound, l.profile, hint)
+		}
+		section, err := profileSection(l.values, l.profile, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w. %s", l.file, err, hint)
+		}
+		if _, ok := section["config_dir"]; ok {
+			return nil, fmt.Errorf("%s: профиль %s: %w", l.file, l.profile, errProfileConfigDir)
+		}
+		l.section = section
+	}
+	return l, nil
+}
+
+// source возвращает слой, из которого взят параметр с ключом файла name
+func (l *layers) source(name string) Source {
+	k, err := lookupKey(name)
+	if err != nil {
+		return SourceDefault
+	}
+	return l.lookup(k).source
+}
+
+// findConfigFile ищет файл конфигурации в каталоге по умолчанию, затем в текущем
+func findConfigFile() string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, defaultConfigDir))
+	}
+	dirs = append(dirs, ".")
+
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// lookup возвращает значение параметра из старшего слоя, где оно задано
+func (l *layers) lookup(k keySpec) raw {
+	if v, ok := l.flags[k.name]; ok {
+		return raw{value: v, source: SourceFlag}
+	}
+	if v, ok := os.LookupEnv(k.env()); ok {
+		return raw{value: v, source: SourceEnv}
+	}
+	if v, ok := l.section[k.name]; ok {
+		return raw{value: fileString(v), source: SourceProfile}
+	}
+	if v, ok := l.values[k.name]; ok {
+		return raw{value: fileString(v), source: SourceFile}
+	}
+	return raw{value: fileString(k.def), source: SourceDefault}
+}
+
+// fileString приводит значение из файла к строке, как в переменной окружения
+func fileString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// describe называет слой значения для сообщений об ошибках
+func (l *layers) describe(k keySpec, source Source) string {
+	switch source {
+	case SourceFlag:
+		return "флаг командной строки"
+	case SourceEnv:
+		return "переменная окружения " + k.env()
+	case SourceProfile:
+		return fmt.Sprintf("профиль %s в файле %s", l.profile, l.file)
+	case SourceFile:
+		return "файл " + l.file
+	default:
+		return "значение по умолчанию"
+	}
+}
+
+// unknownKeys возвращает замечания о ключах файла, которые клиент не знает
+func (l *layers) unknownKeys() []string {
+	var warnings []string
+	check := func(values map[string]interface{}, where string) {
+		for _, name := range sortedNames(values) {
+			if name == fileKeyProfile || name == fileKeyProfiles {
+				continue
+			}
+			if _, err := lookupKey(name); err != nil {
+				warning := fmt.Sprintf("%s: неизвестный ключ '%s'", where, name)
+				if similar, ok := suggestKey(name); ok {
+					warning += fmt.Sprintf(", возможно, имелся в виду '%s'", similar.name)
+				}
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	check(l.values, l.file)
+	if l.section != nil {
+		check(l.section, fmt.Sprintf("%s (профиль %s)", l.file, l.profile))
+	}
+	return warnings
+}
+
+// parsed - значения параметров, разобранные по типам
+type parsed struct {
+	strings   map[string]string
+	bools     map[string]bool
+	ints      map[string]int
+	durations map[string]time.Duration
+	lists     map[string][]string
+}
+
+// parse разбирает значения всех параметров; ошибка называет параметр,
+// значение и слой, из которого оно взято
+func (l *layers) parse() (*parsed, []Value, error) {
+	p := &parsed{
+		strings:   map[string]string{},
+		bools:     map[string]bool{},
+		ints:      map[string]int{},
+		durations: map[string]time.Duration{},
+		lists:     map[string][]string{},
+	}
+	values := make([]Value, 0, len(keySpecs))
+
+	for _, k := range keySpecs {
+		r := l.lookup(k)
+		var err error
+		switch k.kind {
+		case kindBool:
+			p.bools[k.name], err = strconv.ParseBool(strings.TrimSpace(r.value))
+			if err != nil {
+				err = fmt.Errorf("ожидается true или false")
+			}
+		case kindInt:
+			p.ints[k.name], err = strconv.Atoi(strings.TrimSpace(r.value))
+			if err != nil {
+				err = fmt.Errorf("ожидается целое число")
+			}
+		case kindDuration:
+			p.durations[k.name], err = parseDuration(r.value)
+		case kindList:
+			p.lists[k.name] = stringList([]string{r.value})
+		default:
+			p.strings[k.name] = r.value
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: неверное значение %q (%s): %w", k.name, r.value, l.describe(k, r.source), err)
+		}
+
+		values = append(values, Value{Key: k.key, Value: r.value, Source: r.source, Description: k.description})
+	}
+	return p, values, nil
+}
+
+// parseDuration разбирает длительность; число без единицы - ошибка, а не
+// наносекунды
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		if _, nerr := strconv.Atoi(value); nerr == nil {
+			return 0, fmt.Errorf("укажите единицу измерения, например %ss или %sm", value, value)
+		}
+		return 0, fmt.Errorf("ожидается длительность, например 30s, 15m, 1h")
+	}
+	return d, nil
+}
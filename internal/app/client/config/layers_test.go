@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig создает файл конфигурации в каталоге теста
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("CONFIG_DIR", filepath.Join(dir, "data"))
+	t.Setenv(ProfileEnv, "")
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+server_address: file:8080
+auto_lock: 15m
+sync_interval_seconds: 60
+enable_tls: true
+`)
+	t.Setenv("AUTO_LOCK", "5m")
+
+	cfg, err := Load(Options{File: path, Flags: map[string]string{"server_address": "flag:9090"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag:9090", cfg.ServerAddress)
+	assert.Equal(t, 5*time.Minute, cfg.AutoLock)
+	assert.Equal(t, 60, cfg.SyncInterval)
+	assert.True(t, cfg.EnableTLS)
+	assert.Equal(t, defaultPINAttempts, cfg.PINAttempts)
+
+	for key, source := range map[string]Source{
+		"server-address": SourceFlag,
+		"auto-lock":      SourceEnv,
+		"sync-interval":  SourceFile,
+		"pin-attempts":   SourceDefault,
+	} {
+		v, err := cfg.Lookup(key)
+		require.NoError(t, err)
+		assert.Equal(t, source, v.Source, key)
+	}
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	path := writeConfig(t, "config.toml", `
+server_address = "personal:8080"
+profile = "work"
+
+[profiles.work]
+server_address = "work:443"
+enable_tls = true
+`)
+
+	cfg, err := Load(Options{File: path})
+	require.NoError(t, err)
+	assert.Equal(t, "work", cfg.Profile)
+	assert.Equal(t, "work:443", cfg.ServerAddress)
+	assert.True(t, cfg.EnableTLS)
+
+	t.Setenv(ProfileEnv, "personal")
+	_, err = Load(Options{File: path})
+	assert.ErrorIs(t, err, ErrProfileNotFound)
+	assert.Contains(t, err.Error(), "Доступные: work")
+
+	cfg, err = Load(Options{File: path, IgnoreProfile: true})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profile)
+	assert.Equal(t, "personal:8080", cfg.ServerAddress)
+}
+
+func TestLoad_HelpfulErrors(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "auto_lock: 15\nsync_intervl: 10\n")
+
+	_, err := Load(Options{File: path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `auto_lock: неверное значение "15" (файл `+path+`)`)
+	assert.Contains(t, err.Error(), "15s или 15m")
+
+	require.NoError(t, os.WriteFile(path, []byte("sync_intervl: 10\n"), 0600))
+	cfg, err := Load(Options{File: path})
+	require.NoError(t, err)
+	require.Len(t, cfg.Warnings, 1)
+	assert.Contains(t, cfg.Warnings[0], "возможно, имелся в виду 'sync_interval_seconds'")
+
+	t.Setenv("SYNC_INTERVAL_SECONDS", "0")
+	_, err = Load(Options{File: path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "переменная окружения SYNC_INTERVAL_SECONDS")
+
+	_, err = Load(Options{File: filepath.Join(t.TempDir(), "missing.yaml")})
+	assert.Error(t, err, "явно заданный файл должен существовать")
+}
+
+func TestSetFileValue(t *testing.T) {
+	for _, name := range []string{"config.yaml", "config.toml"} {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, name, "")
+
+			value, err := SetFileValue(path, "", "auto-lock", "15m")
+			require.NoError(t, err)
+			assert.Equal(t, "15m0s", value)
+			_, err = SetFileValue(path, "work", "server-address", "work:443")
+			require.NoError(t, err)
+			_, err = SetFileValue(path, "work", "enable_tls", "true")
+			require.NoError(t, err)
+			require.NoError(t, SetFileProfile(path, "work"))
+
+			_, err = SetFileValue(path, "", "auto-lok", "5m")
+			assert.ErrorContains(t, err, "возможно, имелся в виду 'auto-lock'")
+			_, err = SetFileValue(path, "", "pin-attempts", "0")
+			assert.Error(t, err)
+			assert.ErrorIs(t, SetFileProfile(path, "home"), ErrProfileNotFound)
+
+			cfg, err := Load(Options{File: path})
+			require.NoError(t, err)
+			assert.Equal(t, "work", cfg.Profile)
+			assert.Equal(t, "work:443", cfg.ServerAddress)
+			assert.True(t, cfg.EnableTLS)
+			assert.Equal(t, 15*time.Minute, cfg.AutoLock)
+			assert.Empty(t, cfg.Warnings)
+		})
+	}
+}
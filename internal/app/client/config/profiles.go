@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Профиль - именованный раздел profiles файла конфигурации, например для
+// рабочего и личного серверов. Параметры раздела переопределяют верхний
+// уровень файла, а данные профиля хранятся в своем каталоге (ProfileDir),
+// поэтому каждый профиль - отдельное устройство со своими входом,
+// мастер-ключом, базой и синхронизацией.
+
+// ErrProfileNotFound - выбранного профиля нет в файле конфигурации
+var ErrProfileNotFound = errors.New("профиль не найден")
+
+// errProfileConfigDir - config_dir в разделе профиля
+var errProfileConfigDir = errors.New("config_dir задается только на верхнем уровне файла: " +
+	"данные профиля хранятся в <config_dir>/profiles/<профиль>")
+
+// profileNamePattern - имя профиля становится именем каталога и сервиса агента
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// ValidateProfileName проверяет имя профиля
+func ValidateProfileName(profile string) error {
+	if !profileNamePattern.MatchString(profile) {
+		return fmt.Errorf("неверное имя профиля %q: допустимы латинские буквы, цифры, '-' и '_', до 32 символов", profile)
+	}
+	return nil
+}
+
+// FileProfiles возвращает профили файла конфигурации по именам и профиль
+// по умолчанию (ключ profile)
+func FileProfiles(path string) ([]string, string, error) {
+	values, err := readFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var names []string
+	if profiles, ok := values[fileKeyProfiles].(map[string]interface{}); ok {
+		names = sortedNames(profiles)
+	}
+	var def string
+	if p, ok := values[fileKeyProfile]; ok {
+		def = fmt.Sprint(p)
+	}
+	return names, def, nil
+}
+
+// SetFileProfile записывает в файл профиль по умолчанию; пустой - без профиля
+func SetFileProfile(path, profile string) error {
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	if profile == "" {
+		delete(values, fileKeyProfile)
+	} else {
+		if _, err := profileSection(values, profile, false); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		values[fileKeyProfile] = profile
+	}
+	return writeFile(path, values)
+}
+
+// RemoveFileProfile удаляет раздел профиля из файла; если профиль был
+// профилем по умолчанию, файл остается без профиля по умолчанию
+func RemoveFileProfile(path, profile string) error {
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := profileSection(values, profile, false); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	profiles := values[fileKeyProfiles].(map[string]interface{})
+	delete(profiles, profile)
+	if len(profiles) == 0 {
+		delete(values, fileKeyProfiles)
+	}
+	if p, ok := values[fileKeyProfile]; ok && fmt.Sprint(p) == profile {
+		delete(values, fileKeyProfile)
+	}
+	return writeFile(path, values)
+}
+
+// profileSection возвращает раздел профиля; create - создать, если его нет
+func profileSection(values map[string]interface{}, profile string, create bool) (map[string]interface{}, error) {
+	if err := ValidateProfileName(profile); err != nil {
+		return nil, err
+	}
+
+	profiles, ok := values[fileKeyProfiles].(map[string]interface{})
+	if !ok {
+		if _, exists := values[fileKeyProfiles]; exists {
+			return nil, fmt.Errorf("раздел %s должен содержать профили по именам", fileKeyProfiles)
+		}
+		if !create {
+			return nil, fmt.Errorf("%w: %q, профилей нет", ErrProfileNotFound, profile)
+		}
+		profiles = make(map[string]interface{})
+		values[fileKeyProfiles] = profiles
+	}
+
+	section, ok := profiles[profile].(map[string]interface{})
+	if !ok {
+		if _, exists := profiles[profile]; exists {
+			return nil, fmt.Errorf("профиль %q должен содержать параметры по ключам", profile)
+		}
+		if !create {
+			return nil, fmt.Errorf("%w: %q. Доступные: %s", ErrProfileNotFound, profile, strings.Join(sortedNames(profiles), ", "))
+		}
+		section = make(map[string]interface{})
+		profiles[profile] = section
+	}
+	return section, nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_ProfilePaths(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+server_address: personal:8080
+profiles:
+  work:
+    server_address: work:443
+  home:
+    server_address: home:8080
+`)
+	root := os.Getenv("CONFIG_DIR")
+
+	base, err := Load(Options{File: path})
+	require.NoError(t, err)
+	assert.Equal(t, root, base.ConfigDir)
+	assert.Equal(t, root, base.RootDir)
+
+	work, err := Load(Options{File: path, Profile: "work"})
+	require.NoError(t, err)
+	home, err := Load(Options{File: path, Profile: "home"})
+	require.NoError(t, err)
+
+	for _, cfg := range []*Config{work, home} {
+		dir := ProfileDir(root, cfg.Profile)
+		assert.Equal(t, root, cfg.RootDir)
+		assert.Equal(t, dir, cfg.ConfigDir)
+		assert.Equal(t, filepath.Join(dir, "token"), cfg.TokenPath)
+		assert.Equal(t, filepath.Join(dir, "data.json"), cfg.DataPath)
+		assert.Equal(t, filepath.Join(dir, defaultMasterKeyPath), cfg.MasterKeyPath)
+		assert.DirExists(t, dir)
+		assert.Empty(t, cfg.Warnings)
+	}
+	assert.Equal(t, "work:443", work.ServerAddress)
+	assert.Equal(t, "home:8080", home.ServerAddress)
+}
+
+func TestLoad_ProfileRestrictions(t *testing.T) {
+	path := writeConfig(t, "config.yaml", `
+master_key_path: /keys/master.key
+profiles:
+  work:
+    server_address: work:443
+  moved:
+    config_dir: /elsewhere
+`)
+
+	cfg, err := Load(Options{File: path, Profile: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, "/keys/master.key", cfg.MasterKeyPath)
+	require.Len(t, cfg.Warnings, 1)
+	assert.Contains(t, cfg.Warnings[0], "общий мастер-ключ для всех профилей")
+
+	_, err = Load(Options{File: path, Profile: "moved"})
+	assert.ErrorIs(t, err, errProfileConfigDir)
+
+	_, err = Load(Options{File: path, Profile: "../work"})
+	assert.ErrorContains(t, err, "неверное имя профиля")
+
+	_, err = SetFileValue(path, "work", "config-dir", "/elsewhere")
+	assert.ErrorIs(t, err, errProfileConfigDir)
+}
+
+func TestFileProfiles(t *testing.T) {
+	path := writeConfig(t, "config.toml", "")
+
+	names, def, err := FileProfiles(path)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Empty(t, def)
+
+	for _, name := range []string{"work", "home"} {
+		_, err := SetFileValue(path, name, "server-address", name+":443")
+		require.NoError(t, err)
+	}
+	require.NoError(t, SetFileProfile(path, "work"))
+
+	names, def, err = FileProfiles(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "work"}, names)
+	assert.Equal(t, "work", def)
+
+	require.NoError(t, RemoveFileProfile(path, "work"))
+	names, def, err = FileProfiles(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home"}, names)
+	assert.Empty(t, def, "удаленный профиль больше не профиль по умолчанию")
+
+	assert.ErrorIs(t, RemoveFileProfile(path, "work"), ErrProfileNotFound)
+	_, err = SetFileValue(path, "a b", "server-address", "x:1")
+	assert.ErrorContains(t, err, "неверное имя профиля")
+}
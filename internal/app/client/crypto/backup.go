@@ -0,0 +1,332 @@
+// internal/app/client/crypto/backup.go
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Формат резервной копии:
+//
+//	magic "GKBACKUP" | uint32 длина заголовка | заголовок (JSON) | чанки...
+//
+// Каждый чанк: флаг (0 - промежуточный, 1 - последний) | uint32 длина | nonce+шифротекст.
+// Заголовок, номер чанка и флаг входят в additional data AES-GCM, поэтому
+// подмена заголовка, перестановка и обрезка чанков обнаруживаются при чтении.
+const (
+	backupMagic         = "GKBACKUP"
+	backupFormatVersion = 1
+	backupChunkSize     = 64 * 1024
+	backupMaxHeaderSize = 4 * 1024
+
+	chunkFlagMore  byte = 0
+	chunkFlagFinal byte = 1
+)
+
+// Источник пароля резервной копии
+const (
+	BackupKeySourceMasterPassword = "master-password"
+	BackupKeySourcePassphrase     = "passphrase"
+)
+
+var (
+	// ErrBackupFormat файл не является резервной копией GophKeeper или поврежден
+	ErrBackupFormat = errors.New("неверный формат резервной копии")
+	// ErrBackupPassword неверный пароль резервной копии или данные изменены
+	ErrBackupPassword = errors.New("неверный пароль резервной копии или данные повреждены")
+)
+
+// BackupHeader открытый (но аутентифицированный) заголовок резервной копии
+type BackupHeader struct {
+	Version    int       `json:"version"`
+	KDF        string    `json:"kdf"`
+	Iterations int       `json:"iterations"`
+	Salt       string    `json:"salt"` // hex
+	ChunkSize  int       `json:"chunk_size"`
+	KeySource  string    `json:"key_source"` // master-password или passphrase
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BackupWriter шифрует поток данных резервной копии по чанкам
+type BackupWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	buf    []byte
+	index  uint64
+	closed bool
+}
+
+// NewBackupWriter записывает заголовок и возвращает writer для данных.
+// Ключ выводится из пароля через PBKDF2-SHA256 со случайной солью.
+// Close обязателен: он записывает последний чанк, без которого копия считается обрезанной.
+func NewBackupWriter(w io.Writer, password, keySource string) (*BackupWriter, error) {
+	salt, err := GenerateSalt(pbkdf2SaltLength)
+	if err != nil {
+		return nil, err
+	}
+
+	header := BackupHeader{
+		Version:    backupFormatVersion,
+		KDF:        "PBKDF2-SHA256",
+		Iterations: pbkdf2Iterations,
+		Salt:       hex.EncodeToString(salt),
+		ChunkSize:  backupChunkSize,
+		KeySource:  keySource,
+		CreatedAt:  clock().UTC(),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации заголовка: %w", err)
+	}
+
+	aead, err := backupAEAD(password, salt, header.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix bytes.Buffer
+	prefix.WriteString(backupMagic)
+	_ = binary.Write(&prefix, binary.BigEndian, uint32(len(headerJSON)))
+	prefix.Write(headerJSON)
+	if _, err := w.Write(prefix.Bytes()); err != nil {
+		return nil, fmt.Errorf("ошибка записи заголовка: %w", err)
+	}
+
+	return &BackupWriter{
+		w:      w,
+		aead:   aead,
+		header: headerJSON,
+		buf:    make([]byte, 0, backupChunkSize),
+	}, nil
+}
+
+// Write буферизует данные и шифрует их полными чанками
+func (bw *BackupWriter) Write(p []byte) (int, error) {
+	if bw.closed {
+		return 0, fmt.Errorf("резервная копия уже закрыта")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// Полный чанк отправляем только когда есть продолжение,
+		// чтобы последний чанк всегда писался в Close с флагом final
+		if len(bw.buf) == cap(bw.buf) {
+			if err := bw.flush(chunkFlagMore); err != nil {
+				return written, err
+			}
+		}
+
+		n := copy(bw.buf[len(bw.buf):cap(bw.buf)], p)
+		bw.buf = bw.buf[:len(bw.buf)+n]
+		p = p[n:]
+		written += n
+	}
+
+	return written, nil
+}
+
+// Close записывает последний чанк
+func (bw *BackupWriter) Close() error {
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+	return bw.flush(chunkFlagFinal)
+}
+
+func (bw *BackupWriter) flush(flag byte) error {
+	nonce := make([]byte, bw.aead.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return fmt.Errorf("ошибка генерации nonce: %w", err)
+	}
+
+	sealed := bw.aead.Seal(nonce, nonce, bw.buf, chunkAD(bw.header, bw.index, flag))
+
+	var prefix [5]byte
+	prefix[0] = flag
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(sealed)))
+
+	if _, err := bw.w.Write(prefix[:]); err != nil {
+		return fmt.Errorf("ошибка записи чанка: %w", err)
+	}
+	if _, err := bw.w.Write(sealed); err != nil {
+		return fmt.Errorf("ошибка записи чанка: %w", err)
+	}
+
+	bw.index++
+	bw.buf = bw.buf[:0]
+	return nil
+}
+
+// BackupReader расшифровывает поток резервной копии
+type BackupReader struct {
+	r         *bufio.Reader
+	header    BackupHeader
+	headerRaw []byte
+	aead      cipher.AEAD
+	buf       []byte
+	index     uint64
+	done      bool
+}
+
+// NewBackupReader читает и разбирает заголовок. Перед чтением данных нужно вызвать Unlock.
+func NewBackupReader(r io.Reader) (*BackupReader, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(backupMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != backupMagic {
+		return nil, ErrBackupFormat
+	}
+
+	var headerLen uint32
+	if err := binary.Read(br, binary.BigEndian, &headerLen); err != nil || headerLen > backupMaxHeaderSize {
+		return nil, ErrBackupFormat
+	}
+
+	headerRaw := make([]byte, headerLen)
+	if _, err := io.ReadFull(br, headerRaw); err != nil {
+		return nil, ErrBackupFormat
+	}
+
+	var header BackupHeader
+	if err := json.Unmarshal(headerRaw, &header); err != nil {
+		return nil, ErrBackupFormat
+	}
+
+	if header.Version != backupFormatVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия резервной копии: %d", header.Version)
+	}
+	if header.KDF != "PBKDF2-SHA256" {
+		return nil, fmt.Errorf("неподдерживаемый алгоритм: %s", header.KDF)
+	}
+
+	return &BackupReader{
+		r:         br,
+		header:    header,
+		headerRaw: headerRaw,
+	}, nil
+}
+
+// Header возвращает заголовок резервной копии
+func (br *BackupReader) Header() BackupHeader {
+	return br.header
+}
+
+// Unlock выводит ключ из пароля и проверяет его на первом чанке
+func (br *BackupReader) Unlock(password string) error {
+	salt, err := hex.DecodeString(br.header.Salt)
+	if err != nil {
+		return ErrBackupFormat
+	}
+
+	aead, err := backupAEAD(password, salt, br.header.Iterations)
+	if err != nil {
+		return err
+	}
+	br.aead = aead
+
+	if err := br.next(); err != nil {
+		br.aead = nil
+		return err
+	}
+
+	return nil
+}
+
+// Read возвращает расшифрованные данные
+func (br *BackupReader) Read(p []byte) (int, error) {
+	if br.aead == nil {
+		return 0, fmt.Errorf("резервная копия не разблокирована")
+	}
+
+	for len(br.buf) == 0 {
+		if br.done {
+			return 0, io.EOF
+		}
+		if err := br.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, br.buf)
+	br.buf = br.buf[n:]
+	return n, nil
+}
+
+func (br *BackupReader) next() error {
+	var prefix [5]byte
+	if _, err := io.ReadFull(br.r, prefix[:]); err != nil {
+		return fmt.Errorf("%w: резервная копия обрезана", ErrBackupFormat)
+	}
+
+	flag := prefix[0]
+	size := binary.BigEndian.Uint32(prefix[1:])
+	maxSize := uint32(br.header.ChunkSize + br.aead.NonceSize() + br.aead.Overhead())
+	if (flag != chunkFlagMore && flag != chunkFlagFinal) || size > maxSize || size < uint32(br.aead.NonceSize()) {
+		return ErrBackupFormat
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(br.r, sealed); err != nil {
+		return fmt.Errorf("%w: резервная копия обрезана", ErrBackupFormat)
+	}
+
+	nonce, ciphertext := sealed[:br.aead.NonceSize()], sealed[br.aead.NonceSize():]
+	plaintext, err := br.aead.Open(nil, nonce, ciphertext, chunkAD(br.headerRaw, br.index, flag))
+	if err != nil {
+		return ErrBackupPassword
+	}
+
+	br.buf = plaintext
+	br.index++
+
+	if flag == chunkFlagFinal {
+		br.done = true
+		if _, err := br.r.ReadByte(); err != io.EOF {
+			return fmt.Errorf("%w: данные после последнего чанка", ErrBackupFormat)
+		}
+	}
+
+	return nil
+}
+
+func backupAEAD(password string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if password == "" {
+		return nil, fmt.Errorf("пароль резервной копии не может быть пустым")
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, iterations, pbkdf2KeyLength, sha256.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания GCM: %w", err)
+	}
+
+	return gcm, nil
+}
+
+func chunkAD(header []byte, index uint64, flag byte) []byte {
+	ad := make([]byte, 0, len(header)+9)
+	ad = append(ad, header...)
+	ad = binary.BigEndian.AppendUint64(ad, index)
+	return append(ad, flag)
+}
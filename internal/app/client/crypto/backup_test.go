@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func writeBackup(t *testing.T, password string, payload []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	bw, err := NewBackupWriter(&buf, password, BackupKeySourcePassphrase)
+	if err != nil {
+		t.Fatalf("Ошибка создания резервной копии: %v", err)
+	}
+	if _, err := bw.Write(payload); err != nil {
+		t.Fatalf("Ошибка записи: %v", err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatalf("Ошибка закрытия: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readBackup(data []byte, password string) ([]byte, error) {
+	br, err := NewBackupReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := br.Unlock(password); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(br)
+}
+
+func TestBackup_RoundTrip(t *testing.T) {
+	sizes := []int{0, 10, backupChunkSize, backupChunkSize + 1, 3*backupChunkSize + 17}
+
+	for _, size := range sizes {
+		payload := bytes.Repeat([]byte("x"), size)
+		data := writeBackup(t, "backup-pass", payload)
+
+		got, err := readBackup(data, "backup-pass")
+		if err != nil {
+			t.Fatalf("size %d: ошибка чтения: %v", size, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Errorf("size %d: данные не совпадают", size)
+		}
+	}
+}
+
+func TestBackup_Header(t *testing.T) {
+	data := writeBackup(t, "backup-pass", []byte("data"))
+
+	br, err := NewBackupReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Ошибка чтения заголовка: %v", err)
+	}
+	if br.Header().KeySource != BackupKeySourcePassphrase {
+		t.Errorf("Неверный источник ключа: %s", br.Header().KeySource)
+	}
+	if br.Header().Version != backupFormatVersion {
+		t.Errorf("Неверная версия: %d", br.Header().Version)
+	}
+}
+
+func TestBackup_WrongPassword(t *testing.T) {
+	data := writeBackup(t, "backup-pass", []byte("secret"))
+
+	if _, err := readBackup(data, "other-pass"); !errors.Is(err, ErrBackupPassword) {
+		t.Errorf("Ожидалась ErrBackupPassword, получено: %v", err)
+	}
+}
+
+func TestBackup_Tampering(t *testing.T) {
+	payload := bytes.Repeat([]byte("y"), 2*backupChunkSize+5)
+	data := writeBackup(t, "backup-pass", payload)
+
+	// Обрезка последнего чанка
+	if _, err := readBackup(data[:len(data)-40], "backup-pass"); err == nil {
+		t.Error("Обрезанная копия должна приводить к ошибке")
+	}
+
+	// Изменение байта шифротекста
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	if _, err := readBackup(corrupted, "backup-pass"); err == nil {
+		t.Error("Измененная копия должна приводить к ошибке")
+	}
+
+	// Лишние данные после последнего чанка
+	if _, err := readBackup(append(append([]byte(nil), data...), 0), "backup-pass"); err == nil {
+		t.Error("Данные после последнего чанка должны приводить к ошибке")
+	}
+
+	// Не резервная копия
+	if _, err := NewBackupReader(bytes.NewReader([]byte("not a backup"))); !errors.Is(err, ErrBackupFormat) {
+		t.Errorf("Ожидалась ErrBackupFormat, получено: %v", err)
+	}
+}
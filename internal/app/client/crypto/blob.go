@@ -0,0 +1,48 @@
+// internal/app/client/crypto/blob.go
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// blobKeyContext отделяет ключи блобов от других HMAC мастер-ключа
+const blobKeyContext = "gophkeeper-blob-v1:"
+
+// EncryptBlob шифрует содержимое файла детерминированно (convergent encryption):
+// ключ выводится из мастер-ключа и SHA-256 содержимого, nonce - из ключа. Один и
+// тот же файл пользователя всегда дает один и тот же шифротекст, поэтому сервер
+// хранит его один раз. Без мастер-ключа по шифротексту нельзя проверить догадку
+// о содержимом. Ключ возвращается для сохранения в зашифрованных данных записи.
+func (e *RecordEncryptor) EncryptBlob(plaintext []byte) (ciphertext, key []byte, err error) {
+	if e.masterKeyManager == nil || e.masterKeyManager.IsLocked() {
+		return nil, nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	digest := sha256.Sum256(plaintext)
+	masterKey := e.masterKeyManager.rawKey()
+	defer masterKey.Close()
+
+	mac := hmac.New(sha256.New, masterKey.Bytes())
+	mac.Write([]byte(blobKeyContext))
+	mac.Write(digest[:])
+	key = mac.Sum(nil)
+
+	gcm, err := aesGCM{}.aead(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Ключ уникален для содержимого, поэтому постоянный для ключа nonce не повторяется
+	// с другим открытым текстом
+	nonceMAC := hmac.New(sha256.New, key)
+	nonceMAC.Write([]byte("nonce"))
+	nonce := nonceMAC.Sum(nil)[:gcm.NonceSize()]
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), key, nil
+}
+
+// DecryptBlob расшифровывает содержимое файла ключом из данных записи
+func DecryptBlob(key, ciphertext []byte) ([]byte, error) {
+	return decryptWithKey(key, ciphertext)
+}
@@ -1,17 +1,17 @@
 package crypto
 
 import (
-	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestMasterKeyManager(t *testing.T) {
 	// Тест 1: Генерация мастер-ключа
-	mgr, err := NewMasterKeyManager("test_master.key")
+	// Сессия пишется рядом с файлом ключа, поэтому оба - во временном каталоге
+	mgr, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "test_master.key"))
 	if err != nil {
 		t.Fatalf("Ошибка создания менеджера: %v", err)
 	}
-	defer os.Remove("test_master.key")
 
 	// Тест 2: Генерация ключа
 	err = mgr.GenerateMasterKey("testpassword123")
@@ -19,24 +19,58 @@ func NewRecordEncryptor(masterKeyManager *MasterKeyManager) *RecordEncryptor {
 	}
 }
 
-// EncryptRecord шифрует данные записи
+// EncryptRecord шифрует данные записи собственным ключом записи
 func (e *RecordEncryptor) EncryptRecord(plaintext []byte) ([]byte, error) {
 	if e.masterKeyManager == nil {
 		return nil, fmt.Errorf("мастер-ключ не инициализирован")
 	}
 
-	return e.masterKeyManager.EncryptData(plaintext)
+	return e.masterKeyManager.SealEnvelope(plaintext)
 }
 
-// DecryptRecord расшифровывает данные записи
+// DecryptRecord расшифровывает данные записи. Записи прежних версий клиента
+// зашифрованы мастер-ключом напрямую и читаются по-прежнему.
 func (e *RecordEncryptor) DecryptRecord(ciphertext []byte) ([]byte, error) {
 	if e.masterKeyManager == nil {
 		return nil, fmt.Errorf("мастер-ключ не инициализирован")
 	}
 
+	if IsEnvelope(ciphertext) {
+		plaintext, err := e.masterKeyManager.OpenEnvelope(ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		// Начало прежнего шифротекста (случайный nonce) может совпасть с
+		// заголовком конверта
+		if legacy, legacyErr := e.masterKeyManager.DecryptData(ciphertext); legacyErr == nil {
+			return legacy, nil
+		}
+		return nil, err
+	}
+
 	return e.masterKeyManager.DecryptData(ciphertext)
 }
 
+// DecryptRecordSecure расшифровывает данные записи в защищенную память.
+// Вызывающий закрывает результат, когда данные больше не нужны.
+func (e *RecordEncryptor) DecryptRecordSecure(ciphertext []byte) (*SecureBytes, error) {
+	plaintext, err := e.DecryptRecord(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return SecureBytesFrom(plaintext), nil
+}
+
+// RewrapRecord шифрует ключ записи текущим мастер-ключом после ротации;
+// запись прежнего формата перешифровывается собственным ключом
+func (e *RecordEncryptor) RewrapRecord(ciphertext []byte) ([]byte, error) {
+	if e.masterKeyManager == nil {
+		return nil, fmt.Errorf("мастер-ключ не инициализирован")
+	}
+
+	return e.masterKeyManager.RewrapEnvelope(ciphertext)
+}
+
 // EncryptField шифрует отдельное поле записи
 func (e *RecordEncryptor) EncryptField(_ string, value string) (string, error) {
 	if e.masterKeyManager == nil {
@@ -76,7 +110,10 @@ func (e *RecordEncryptor) GenerateHMAC(data []byte) (string, error) {
 		return "", fmt.Errorf("мастер-ключ не инициализирован")
 	}
 
-	mac := hmac.New(sha256.New, e.masterKeyManager.getRawKey())
+	key := e.masterKeyManager.rawKey()
+	defer key.Close()
+
+	mac := hmac.New(sha256.New, key.Bytes())
 	mac.Write(data)
 	return hex.EncodeToString(mac.Sum(nil)), nil
 }
@@ -131,12 +168,11 @@ func (e *RecordEncryptor) DecryptMetadata(encryptedMetadata map[string]string) (
 	return metadata, nil
 }
 
-// getRawKey возвращает сырой мастер-ключ (только для внутреннего использования)
-func (m *MasterKeyManager) getRawKey() []byte {
+// rawKey возвращает копию мастер-ключа в защищенной памяти (только для
+// внутреннего использования); вызывающий закрывает ее
+func (m *MasterKeyManager) rawKey() *SecureBytes {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	keyCopy := make([]byte, len(m.masterKey))
-	copy(keyCopy, m.masterKey)
-	return keyCopy
+	return SecureBytesCopy(m.masterKey)
 }
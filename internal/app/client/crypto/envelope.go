@@ -0,0 +1,192 @@
+// internal/app/client/crypto/envelope.go
+package crypto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Конвертное шифрование записей. Каждая запись шифруется своим случайным
+// ключом (DEK), а он - мастер-ключом (KEK). Зашифрованный ключ записи хранится
+// в начале шифротекста, поэтому путешествует вместе с данными через
+// синхронизацию, историю версий и резервные копии. Ротация мастер-ключа
+// перешифровывает только ключи записей, а ключ одной записи можно передать,
+// не раскрывая остальные.
+//
+// Формат: "GKE" | версия формата (1 байт) | длина ключа (uint16, big-endian) |
+// ключ записи, зашифрованный мастер-ключом (с меткой версии мастер-ключа) |
+// данные, зашифрованные ключом записи.
+
+const (
+	envelopeMagic      = "GKE"
+	envelopeVersion    = 1
+	envelopeHeaderSize = len(envelopeMagic) + 1 + 2
+	// envelopeMaxKeySize - предел длины зашифрованного ключа записи
+	envelopeMaxKeySize = 1024
+)
+
+// IsEnvelope проверяет, зашифрованы ли данные ключом записи
+func IsEnvelope(ciphertext []byte) bool {
+	_, _, err := splitEnvelope(ciphertext)
+	return err == nil
+}
+
+// SealEnvelope шифрует данные новым ключом записи
+func (m *MasterKeyManager) SealEnvelope(plaintext []byte) ([]byte, error) {
+	if err := m.touch(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isLoaded || m.isLocked {
+		return nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	dek := make([]byte, aead.KeySize())
+	if _, err := io.ReadFull(randReader, dek); err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа записи: %w", err)
+	}
+	defer wipe(dek)
+
+	data, err := aead.Seal(dek, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := m.seal(aead, dek)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования ключа записи: %w", err)
+	}
+	return joinEnvelope(wrapped, data), nil
+}
+
+// OpenEnvelope расшифровывает данные, зашифрованные ключом записи
+func (m *MasterKeyManager) OpenEnvelope(ciphertext []byte) ([]byte, error) {
+	dek, err := m.RecordKey(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(dek)
+
+	return m.openWithRecordKey(dek, ciphertext)
+}
+
+// RecordKey возвращает ключ записи из шифротекста. Ключ дает доступ только к
+// этой записи: по нему ее можно расшифровать без мастер-ключа.
+func (m *MasterKeyManager) RecordKey(ciphertext []byte) ([]byte, error) {
+	if err := m.touch(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isLoaded || m.isLocked {
+		return nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	wrapped, _, err := splitEnvelope(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := m.open(aead, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки ключа записи: %w", err)
+	}
+	return dek, nil
+}
+
+// DecryptWithRecordKey расшифровывает запись ключом, полученным через RecordKey
+func (m *MasterKeyManager) DecryptWithRecordKey(dek, ciphertext []byte) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.openWithRecordKey(dek, ciphertext)
+}
+
+// RewrapEnvelope шифрует ключ записи текущим мастер-ключом, не трогая данные.
+// Шифротекст без ключа записи перешифровывается целиком в новый формат.
+func (m *MasterKeyManager) RewrapEnvelope(ciphertext []byte) ([]byte, error) {
+	if !IsEnvelope(ciphertext) {
+		plaintext, err := m.DecryptData(ciphertext)
+		if err != nil {
+			return nil, err
+		}
+		return m.SealEnvelope(plaintext)
+	}
+
+	dek, err := m.RecordKey(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(dek)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := m.seal(aead, dek)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования ключа записи: %w", err)
+	}
+	_, data, _ := splitEnvelope(ciphertext)
+	return joinEnvelope(wrapped, data), nil
+}
+
+// openWithRecordKey расшифровывает данные конверта; вызывается под блокировкой
+func (m *MasterKeyManager) openWithRecordKey(dek, ciphertext []byte) ([]byte, error) {
+	_, data, err := splitEnvelope(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(dek, data)
+}
+
+func joinEnvelope(wrapped, data []byte) []byte {
+	envelope := make([]byte, envelopeHeaderSize+len(wrapped)+len(data))
+	copy(envelope, envelopeMagic)
+	envelope[len(envelopeMagic)] = envelopeVersion
+	binary.BigEndian.PutUint16(envelope[len(envelopeMagic)+1:], uint16(len(wrapped)))
+	copy(envelope[envelopeHeaderSize:], wrapped)
+	copy(envelope[envelopeHeaderSize+len(wrapped):], data)
+	return envelope
+}
+
+// splitEnvelope разделяет конверт на зашифрованный ключ записи и данные
+func splitEnvelope(ciphertext []byte) (wrapped, data []byte, err error) {
+	if len(ciphertext) < envelopeHeaderSize || string(ciphertext[:len(envelopeMagic)]) != envelopeMagic {
+		return nil, nil, fmt.Errorf("данные зашифрованы без ключа записи")
+	}
+	if ciphertext[len(envelopeMagic)] != envelopeVersion {
+		return nil, nil, fmt.Errorf("неподдерживаемая версия конверта: %d, обновите клиент", ciphertext[len(envelopeMagic)])
+	}
+	size := int(binary.BigEndian.Uint16(ciphertext[len(envelopeMagic)+1 : envelopeHeaderSize]))
+	if size == 0 || size > envelopeMaxKeySize || envelopeHeaderSize+size > len(ciphertext) {
+		return nil, nil, fmt.Errorf("поврежден конверт записи")
+	}
+	return ciphertext[envelopeHeaderSize : envelopeHeaderSize+size], ciphertext[envelopeHeaderSize+size:], nil
+}
+
+// wipe затирает ключ в памяти
+func wipe(key []byte) {
+	for i := range key {
+		key[i] = 0
+	}
+}
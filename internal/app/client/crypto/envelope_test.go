@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordEncryptor_Envelope(t *testing.T) {
+	m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+	e := NewRecordEncryptor(m)
+
+	first, err := e.EncryptRecord([]byte("secret"))
+	require.NoError(t, err)
+	second, err := e.EncryptRecord([]byte("secret"))
+	require.NoError(t, err)
+	assert.True(t, IsEnvelope(first))
+
+	// У каждой записи свой ключ
+	firstKey, err := m.RecordKey(first)
+	require.NoError(t, err)
+	secondKey, err := m.RecordKey(second)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstKey, secondKey)
+
+	plaintext, err := e.DecryptRecord(first)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	t.Run("record key opens only its record", func(t *testing.T) {
+		plaintext, err := m.DecryptWithRecordKey(firstKey, first)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+
+		_, err = m.DecryptWithRecordKey(firstKey, second)
+		assert.Error(t, err)
+	})
+
+	t.Run("legacy ciphertext", func(t *testing.T) {
+		legacy, err := m.EncryptData([]byte("legacy"))
+		require.NoError(t, err)
+		assert.False(t, IsEnvelope(legacy))
+
+		plaintext, err := e.DecryptRecord(legacy)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy", string(plaintext))
+
+		converted, err := e.RewrapRecord(legacy)
+		require.NoError(t, err)
+		assert.True(t, IsEnvelope(converted))
+		plaintext, err = e.DecryptRecord(converted)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy", string(plaintext))
+	})
+
+	t.Run("tampered envelope", func(t *testing.T) {
+		tampered := append([]byte(nil), first...)
+		tampered[len(tampered)-1] ^= 0xff
+		_, err := e.DecryptRecord(tampered)
+		assert.Error(t, err)
+
+		_, err = e.DecryptRecord(first[:envelopeHeaderSize+1])
+		assert.Error(t, err)
+	})
+
+	t.Run("rotation rewraps only the record key", func(t *testing.T) {
+		_, err := m.RotateKey("password123")
+		require.NoError(t, err)
+		assert.Equal(t, 1, CiphertextKeyVersion(first))
+
+		rewrapped, err := e.RewrapRecord(first)
+		require.NoError(t, err)
+		assert.Equal(t, 2, CiphertextKeyVersion(rewrapped))
+
+		_, oldData, err := splitEnvelope(first)
+		require.NoError(t, err)
+		_, newData, err := splitEnvelope(rewrapped)
+		require.NoError(t, err)
+		assert.Equal(t, oldData, newData, "данные записи не перешифровываются")
+
+		plaintext, err := e.DecryptRecord(rewrapped)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+	})
+}
@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+// SetDeterministic переключает пакет в детерминированный режим: случайные
+// байты берутся из потока, заданного seed, а метки времени равны at.
+// Одинаковые входные данные тогда дают побайтно одинаковые шифротексты,
+// файлы ключей и резервные копии, что позволяет сравнивать их с эталонами.
+// Возвращает функцию, восстанавливающую обычный режим.
+//
+// Режим глобальный, поэтому тесты, которые его используют, нельзя
+// запускать с t.Parallel.
+func SetDeterministic(seed string, at time.Time) (restore func()) {
+	prevReader, prevClock := randReader, clock
+	randReader = NewDeterministicReader(seed)
+	clock = func() time.Time { return at }
+
+	return func() {
+		randReader, clock = prevReader, prevClock
+	}
+}
+
+// NewDeterministicReader возвращает бесконечный поток байт, однозначно
+// определяемый seed: SHA-256(seed || счетчик) блок за блоком.
+// Поток не является криптографически стойким источником ключей.
+func NewDeterministicReader(seed string) io.Reader {
+	return &deterministicReader{seed: []byte(seed)}
+}
+
+type deterministicReader struct {
+	mu      sync.Mutex
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func (r *deterministicReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			block := make([]byte, 0, len(r.seed)+8)
+			block = append(block, r.seed...)
+			block = binary.BigEndian.AppendUint64(block, r.counter)
+			sum := sha256.Sum256(block)
+			r.buf = sum[:]
+			r.counter++
+		}
+		copied := copy(p[n:], r.buf)
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+	return n, nil
+}
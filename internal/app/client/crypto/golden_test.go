@@ -0,0 +1,198 @@
+package crypto
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Эталонные файлы в testdata создаются в детерминированном режиме и
+// фиксируют форматы на диске. Если тест упал после намеренного изменения
+// формата, эталоны обновляются командой
+//
+//	go test ./internal/app/client/crypto -run Golden -update
+//
+// Старые эталоны (например, master_key_v1.golden) при этом не пересоздаются:
+// они проверяют, что новый клиент читает файлы прежних версий.
+
+var updateGolden = flag.Bool("update", false, "перезаписать эталонные файлы в testdata")
+
+const (
+	goldenSeed     = "gophkeeper-golden"
+	goldenPassword = "golden-password"
+	goldenRecord   = "golden record"
+)
+
+var goldenTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// assertGolden сравнивает данные с эталоном или перезаписывает его с -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "нет эталона, запустите тест с -update")
+	assert.True(t, bytes.Equal(want, got), "%s отличается от эталона", name)
+}
+
+func readGolden(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+// goldenManager создает мастер-ключ в детерминированном режиме
+func goldenManager(t *testing.T, aeadID string) *MasterKeyManager {
+	t.Helper()
+
+	restore := SetDeterministic(goldenSeed+aeadID, goldenTime)
+	t.Cleanup(restore)
+
+	m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, m.SetAlgorithms(KDFPBKDF2, aeadID))
+	require.NoError(t, m.GenerateMasterKey(goldenPassword))
+	t.Cleanup(m.Lock)
+	return m
+}
+
+func TestDeterministicReader(t *testing.T) {
+	read := func(seed string, n int) []byte {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(NewDeterministicReader(seed), buf)
+		require.NoError(t, err)
+		return buf
+	}
+
+	assert.Equal(t, read("a", 100), read("a", 100))
+	assert.NotEqual(t, read("a", 100), read("b", 100))
+	// Поток не зависит от размера чтений
+	assert.Equal(t, read("a", 100)[:40], read("a", 40))
+}
+
+func TestSetDeterministic_Restore(t *testing.T) {
+	restore := SetDeterministic("seed", goldenTime)
+	first, err := GenerateRandomBytes(16)
+	require.NoError(t, err)
+	assert.Equal(t, goldenTime, clock())
+	restore()
+
+	restore = SetDeterministic("seed", goldenTime)
+	second, err := GenerateRandomBytes(16)
+	require.NoError(t, err)
+	restore()
+	assert.Equal(t, first, second)
+
+	third, err := GenerateRandomBytes(16)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+	assert.NotEqual(t, goldenTime, clock())
+}
+
+func TestGolden_MasterKey(t *testing.T) {
+	m := goldenManager(t, AEADAESGCM)
+
+	data, err := m.KeyFile()
+	require.NoError(t, err)
+	assertGolden(t, "master_key_v2.golden", data)
+}
+
+func TestGolden_Records(t *testing.T) {
+	for _, tt := range []struct {
+		aead   string
+		golden string
+	}{
+		{AEADAESGCM, "record_aes_gcm.golden"},
+		{AEADXChaCha20, "record_xchacha20.golden"},
+	} {
+		t.Run(tt.aead, func(t *testing.T) {
+			m := goldenManager(t, tt.aead)
+
+			ciphertext, err := m.EncryptData([]byte(goldenRecord))
+			require.NoError(t, err)
+			assertGolden(t, tt.golden, ciphertext)
+
+			plaintext, err := m.DecryptData(readGolden(t, tt.golden))
+			require.NoError(t, err)
+			assert.Equal(t, goldenRecord, string(plaintext))
+		})
+	}
+}
+
+func TestGolden_Envelope(t *testing.T) {
+	m := goldenManager(t, AEADAESGCM)
+	e := NewRecordEncryptor(m)
+
+	ciphertext, err := e.EncryptRecord([]byte(goldenRecord))
+	require.NoError(t, err)
+	assertGolden(t, "record_envelope.golden", ciphertext)
+
+	plaintext, err := e.DecryptRecord(readGolden(t, "record_envelope.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, goldenRecord, string(plaintext))
+
+	// Записи прежнего формата читаются через тот же шифровальщик
+	plaintext, err = e.DecryptRecord(readGolden(t, "record_aes_gcm.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, goldenRecord, string(plaintext))
+}
+
+func TestGolden_Backup(t *testing.T) {
+	restore := SetDeterministic(goldenSeed, goldenTime)
+	defer restore()
+
+	content := bytes.Repeat([]byte("gophkeeper backup "), 32)
+
+	var buf bytes.Buffer
+	bw, err := NewBackupWriter(&buf, goldenPassword, BackupKeySourcePassphrase)
+	require.NoError(t, err)
+	_, err = bw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, bw.Close())
+	assertGolden(t, "backup.golden", buf.Bytes())
+
+	br, err := NewBackupReader(bytes.NewReader(readGolden(t, "backup.golden")))
+	require.NoError(t, err)
+	require.NoError(t, br.Unlock(goldenPassword))
+	restored, err := io.ReadAll(br)
+	require.NoError(t, err)
+	assert.Equal(t, content, restored)
+	assert.Equal(t, goldenTime, br.Header().CreatedAt)
+}
+
+// TestGolden_KeyFileVersions проверяет, что файлы ключей всех версий формата
+// открываются текущим клиентом и расшифровывают записи, созданные тем же ключом
+func TestGolden_KeyFileVersions(t *testing.T) {
+	for _, name := range []string{"master_key_v1.golden", "master_key_v2.golden"} {
+		t.Run(name, func(t *testing.T) {
+			keyPath := filepath.Join(t.TempDir(), "master.key")
+			require.NoError(t, os.WriteFile(keyPath, readGolden(t, name), masterKeyPermissions))
+
+			m, err := NewMasterKeyManager(keyPath)
+			require.NoError(t, err)
+			assert.Equal(t, goldenTime, m.header.CreatedAt.UTC())
+
+			assert.ErrorIs(t, m.UnlockMasterKey("wrong"), ErrWrongPassword)
+			require.NoError(t, m.UnlockMasterKey(goldenPassword))
+			defer m.Lock()
+
+			plaintext, err := m.DecryptData(readGolden(t, "record_aes_gcm.golden"))
+			require.NoError(t, err)
+			assert.Equal(t, goldenRecord, string(plaintext))
+		})
+	}
+}
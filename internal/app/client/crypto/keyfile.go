@@ -0,0 +1,206 @@
+// internal/app/client/crypto/keyfile.go
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Формат файла мастер-ключа (версия 2):
+//
+//	magic "GKMASTER" | uint16 версия | uint32 длина заголовка | заголовок (JSON) |
+//	uint32 длина ключа | зашифрованный мастер-ключ
+//
+// Заголовок описывает KDF, AEAD и их параметры, поэтому файл читается без
+// внешних сведений. Версия 1 - JSON {"header": ..., "data": hex} без magic.
+// Файлы старых версий читаются всегда и переписываются в текущую версию
+// после успешной разблокировки. Файл более новой версии не изменяется:
+// клиент сообщает, что его нужно обновить.
+const (
+	keyFileMagic = "GKMASTER"
+	// keyFileVersion - текущая версия формата, в ней записываются новые файлы
+	keyFileVersion = 2
+
+	keyFileMaxHeaderSize = 16 * 1024
+	keyFileMaxDataSize   = 1024
+)
+
+var (
+	// ErrKeyFileFormat файл не является файлом мастер-ключа или поврежден
+	ErrKeyFileFormat = errors.New("неверный формат файла мастер-ключа")
+	// ErrKeyFileVersion файл записан более новой версией клиента
+	ErrKeyFileVersion = errors.New("файл мастер-ключа создан более новой версией GophKeeper, обновите клиент")
+)
+
+// keyContainer - содержимое файла мастер-ключа независимо от версии формата
+type keyContainer struct {
+	Header MasterKeyHeader
+	// Data - мастер-ключ, зашифрованный ключом из пароля
+	Data []byte
+	// Version - версия формата, в которой файл был прочитан
+	Version int
+}
+
+// keyFileDecoders читают файл каждой поддерживаемой версии
+var keyFileDecoders = map[int]func([]byte) (*keyContainer, error){
+	1: decodeKeyFileV1,
+	2: decodeKeyFileV2,
+}
+
+// keyFileVersionOf определяет версию формата по началу файла
+func keyFileVersionOf(data []byte) (int, error) {
+	if bytes.HasPrefix(data, []byte(keyFileMagic)) {
+		rest := data[len(keyFileMagic):]
+		if len(rest) < 2 {
+			return 0, ErrKeyFileFormat
+		}
+		return int(binary.BigEndian.Uint16(rest)), nil
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return 1, nil
+	}
+	return 0, ErrKeyFileFormat
+}
+
+// decodeKeyFile читает файл мастер-ключа любой поддерживаемой версии
+func decodeKeyFile(data []byte) (*keyContainer, error) {
+	version, err := keyFileVersionOf(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decode, ok := keyFileDecoders[version]
+	if !ok {
+		if version > keyFileVersion {
+			return nil, fmt.Errorf("%w (версия %d)", ErrKeyFileVersion, version)
+		}
+		return nil, fmt.Errorf("%w: версия %d", ErrKeyFileFormat, version)
+	}
+
+	container, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	container.Version = version
+	return container, nil
+}
+
+// encodeKeyFile записывает контейнер в текущей версии формата
+func encodeKeyFile(c *keyContainer) ([]byte, error) {
+	header := c.Header
+	header.Version = keyFileVersion
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации заголовка: %w", err)
+	}
+	if len(headerJSON) > keyFileMaxHeaderSize {
+		return nil, fmt.Errorf("заголовок мастер-ключа слишком большой: %d байт", len(headerJSON))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(keyFileMagic)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(keyFileVersion))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(headerJSON)))
+	buf.Write(headerJSON)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(c.Data)))
+	buf.Write(c.Data)
+
+	return buf.Bytes(), nil
+}
+
+// readKeyFile читает и декодирует файл мастер-ключа
+func readKeyFile(path string) (*keyContainer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла ключа: %w", err)
+	}
+	return decodeKeyFile(data)
+}
+
+// writeKeyFile атомарно записывает файл мастер-ключа в текущей версии формата,
+// чтобы сбой во время записи не оставил поврежденный ключ
+func writeKeyFile(path string, c *keyContainer) error {
+	data, err := encodeKeyFile(c)
+	if err != nil {
+		return err
+	}
+	return writeKeyFileData(path, data)
+}
+
+// writeKeyFileData атомарно записывает уже закодированный файл мастер-ключа:
+// прежний файл заменяется только после полной записи нового
+func writeKeyFileData(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, masterKeyPermissions); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	return nil
+}
+
+// decodeKeyFileV1 читает JSON-формат первых версий клиента
+func decodeKeyFileV1(data []byte) (*keyContainer, error) {
+	var v1 struct {
+		Header MasterKeyHeader `json:"header"`
+		Data   string          `json:"data"` // hex
+	}
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyFileFormat, err)
+	}
+
+	encryptedKey, err := hex.DecodeString(v1.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ошибка декодирования ключа: %v", ErrKeyFileFormat, err)
+	}
+
+	return &keyContainer{Header: v1.Header, Data: encryptedKey}, nil
+}
+
+// decodeKeyFileV2 читает формат с magic и длинами полей
+func decodeKeyFileV2(data []byte) (*keyContainer, error) {
+	r := bytes.NewReader(data[len(keyFileMagic)+2:])
+
+	headerJSON, err := readLengthPrefixed(r, keyFileMaxHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: заголовок: %v", ErrKeyFileFormat, err)
+	}
+	encryptedKey, err := readLengthPrefixed(r, keyFileMaxDataSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ключ: %v", ErrKeyFileFormat, err)
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: лишние данные в конце файла", ErrKeyFileFormat)
+	}
+
+	var header MasterKeyHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyFileFormat, err)
+	}
+
+	return &keyContainer{Header: header, Data: encryptedKey}, nil
+}
+
+func readLengthPrefixed(r *bytes.Reader, maxSize int) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("нет длины поля")
+	}
+	if int(size) > maxSize || int(size) > r.Len() {
+		return nil, fmt.Errorf("неверная длина поля: %d", size)
+	}
+
+	field := make([]byte, size)
+	_, _ = r.Read(field)
+	return field, nil
+}
@@ -0,0 +1,256 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyFileV1 переписывает файл ключа в JSON-формате версии 1,
+// удаляя из заголовка поля, которых в старых файлах еще не было
+func writeKeyFileV1(t *testing.T, keyPath string, dropFields ...string) {
+	t.Helper()
+
+	container, err := readKeyFile(keyPath)
+	require.NoError(t, err)
+	container.Header.Version = 1
+
+	headerJSON, err := json.Marshal(container.Header)
+	require.NoError(t, err)
+	var header map[string]any
+	require.NoError(t, json.Unmarshal(headerJSON, &header))
+	for _, field := range dropFields {
+		delete(header, field)
+	}
+
+	data, err := json.MarshalIndent(map[string]any{
+		"header": header,
+		"data":   hex.EncodeToString(container.Data),
+	}, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, data, masterKeyPermissions))
+}
+
+func TestKeyFile_RoundTrip(t *testing.T) {
+	original := &keyContainer{
+		Header: MasterKeyHeader{
+			KeyAlgorithm: KDFArgon2id,
+			Salt:         "00112233445566778899aabbccddeeff",
+			CreatedAt:    time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC),
+			KeyHash:      "abcdef",
+			Cipher:       AEADXChaCha20,
+			KDFParams:    KDFParams{Iterations: 3, Memory: 65536, Threads: 2},
+		},
+		Data: []byte{1, 2, 3, 4, 5},
+	}
+
+	data, err := encodeKeyFile(original)
+	require.NoError(t, err)
+	assert.Equal(t, keyFileMagic, string(data[:len(keyFileMagic)]))
+	assert.Equal(t, uint16(keyFileVersion), binary.BigEndian.Uint16(data[len(keyFileMagic):]))
+
+	decoded, err := decodeKeyFile(data)
+	require.NoError(t, err)
+	assert.Equal(t, keyFileVersion, decoded.Version)
+	assert.Equal(t, keyFileVersion, decoded.Header.Version)
+	assert.Equal(t, original.Data, decoded.Data)
+
+	decoded.Header.Version = original.Header.Version
+	assert.Equal(t, original.Header, decoded.Header)
+}
+
+func TestKeyFile_Corrupted(t *testing.T) {
+	valid, err := encodeKeyFile(&keyContainer{Header: MasterKeyHeader{KeyHash: "abc"}, Data: []byte("key")})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Empty", nil},
+		{"Garbage", []byte("not a key file")},
+		{"Magic only", []byte(keyFileMagic)},
+		{"Truncated", valid[:len(valid)-1]},
+		{"Trailing data", append(append([]byte{}, valid...), 0)},
+		{"Huge header length", append([]byte(keyFileMagic), 0, 2, 0xff, 0xff, 0xff, 0xff)},
+		{"Broken JSON v1", []byte(`{"header": `)},
+		{"Broken hex v1", []byte(`{"header": {}, "data": "zz"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeKeyFile(tt.data)
+			assert.ErrorIs(t, err, ErrKeyFileFormat)
+		})
+	}
+}
+
+func TestKeyFile_NewerVersion(t *testing.T) {
+	data := append([]byte(keyFileMagic), 0, keyFileVersion+1)
+
+	_, err := decodeKeyFile(data)
+	assert.ErrorIs(t, err, ErrKeyFileVersion)
+
+	// Файл неизвестной версии не трогается и не позволяет создать ключ поверх себя
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+	require.NoError(t, os.WriteFile(keyPath, data, masterKeyPermissions))
+	_, err = NewMasterKeyManager(keyPath)
+	assert.ErrorIs(t, err, ErrKeyFileVersion)
+
+	onDisk, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, onDisk)
+}
+
+func TestKeyFile_MigrationFromV1(t *testing.T) {
+	for _, kdf := range []string{KDFPBKDF2, KDFArgon2id} {
+		t.Run(kdf, func(t *testing.T) {
+			keyPath := filepath.Join(t.TempDir(), "master.key")
+			m, err := NewMasterKeyManager(keyPath)
+			require.NoError(t, err)
+			require.NoError(t, m.SetAlgorithms(kdf, AEADAESGCM))
+			require.NoError(t, m.GenerateMasterKey("old-password"))
+			// После смены пароля мастер-ключ хранится зашифрованным ключом из пароля
+			require.NoError(t, m.ChangeMasterPassword("old-password", "password123"))
+			ciphertext, err := m.EncryptData([]byte("record"))
+			require.NoError(t, err)
+			m.Lock()
+
+			writeKeyFileV1(t, keyPath)
+
+			legacy, err := NewMasterKeyManager(keyPath)
+			require.NoError(t, err)
+			assert.True(t, legacy.IsInitialized())
+
+			// Неверный пароль не переписывает файл
+			assert.ErrorIs(t, legacy.UnlockMasterKey("wrong"), ErrWrongPassword)
+			container, err := readKeyFile(keyPath)
+			require.NoError(t, err)
+			assert.Equal(t, 1, container.Version)
+
+			require.NoError(t, legacy.UnlockMasterKey("password123"))
+			plaintext, err := legacy.DecryptData(ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, "record", string(plaintext))
+			legacy.Lock()
+
+			container, err = readKeyFile(keyPath)
+			require.NoError(t, err)
+			assert.Equal(t, keyFileVersion, container.Version)
+			assert.NoFileExists(t, keyPath+".tmp")
+
+			migrated, err := NewMasterKeyManager(keyPath)
+			require.NoError(t, err)
+			require.NoError(t, migrated.UnlockMasterKey("password123"))
+			plaintext, err = migrated.DecryptData(ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, "record", string(plaintext))
+			migrated.Lock()
+		})
+	}
+}
+
+func TestKeyFile_RestoreV1(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+	m, err := NewMasterKeyManager(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+	m.Lock()
+	writeKeyFileV1(t, keyPath)
+
+	// Резервная копия, сделанная старым клиентом, восстанавливается на новом
+	backup, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+
+	restoredPath := filepath.Join(t.TempDir(), "master.key")
+	restored, err := NewMasterKeyManager(restoredPath)
+	require.NoError(t, err)
+	require.NoError(t, restored.RestoreKeyFile(backup))
+	assert.True(t, restored.IsInitialized())
+	require.NoError(t, restored.UnlockMasterKey("password123"))
+	restored.Lock()
+
+	fresh, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, fresh.RestoreKeyFile([]byte("garbage")), ErrKeyFileFormat)
+
+	// Недописанный файл не подменяет ключ: восстановление идет через
+	// временный файл, и при сбое записи ключ остается не созданным
+	failedPath := filepath.Join(t.TempDir(), "master.key")
+	require.NoError(t, os.MkdirAll(filepath.Join(failedPath+".tmp", "busy"), 0700))
+	failed, err := NewMasterKeyManager(failedPath)
+	require.NoError(t, err)
+	assert.Error(t, failed.RestoreKeyFile(backup))
+	assert.NoFileExists(t, failedPath)
+	assert.False(t, failed.IsInitialized())
+}
+
+func TestChangeMasterPasswordVerified(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "master.key")
+	m, err := NewMasterKeyManager(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("old-password"))
+	ciphertext, err := NewRecordEncryptor(m).EncryptRecord([]byte("record"))
+	require.NoError(t, err)
+	before, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+
+	t.Run("Failed check keeps the old file", func(t *testing.T) {
+		err := m.ChangeMasterPasswordVerified("old-password", "new-password", func(*MasterKeyManager) error {
+			return errors.New("sample record is broken")
+		})
+		assert.ErrorContains(t, err, "sample record is broken")
+
+		onDisk, err := os.ReadFile(keyPath)
+		require.NoError(t, err)
+		assert.Equal(t, before, onDisk)
+		assert.NoError(t, m.VerifyPassword("old-password"))
+	})
+
+	t.Run("Wrong old password", func(t *testing.T) {
+		err := m.ChangeMasterPasswordVerified("wrong", "new-password", nil)
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+
+	t.Run("Check decrypts records with the new file", func(t *testing.T) {
+		err := m.ChangeMasterPasswordVerified("old-password", "new-password", func(check *MasterKeyManager) error {
+			plaintext, err := NewRecordEncryptor(check).DecryptRecord(ciphertext)
+			if err == nil && string(plaintext) != "record" {
+				err = errors.New("unexpected plaintext")
+			}
+			return err
+		})
+		require.NoError(t, err)
+		assert.NoError(t, m.VerifyPassword("new-password"))
+
+		// Временные файлы проверки не остаются рядом с ключом
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, e := range entries {
+			assert.NotContains(t, e.Name(), "password-change")
+		}
+
+		m.Lock()
+		reopened, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.NoError(t, reopened.UnlockMasterKey("new-password"))
+		plaintext, err := NewRecordEncryptor(reopened).DecryptRecord(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "record", string(plaintext))
+		reopened.Lock()
+	})
+
+	t.Run("Locked key", func(t *testing.T) {
+		err := m.ChangeMasterPassword("new-password", "other-password")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,190 @@
+// internal/app/client/crypto/keyprovider.go
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrKeyNotFound возвращается провайдером, если секрет не сохранен
+var ErrKeyNotFound = errors.New("ключ не найден в хранилище ОС")
+
+// KeyProvider хранит секрет в защищенном хранилище операционной системы
+// (macOS Keychain, Windows DPAPI, Linux Secret Service). Доступ к секрету
+// подтверждается средствами ОС: входом в систему, Touch ID, паролем связки ключей.
+type KeyProvider interface {
+	// Name возвращает название хранилища для вывода пользователю
+	Name() string
+	// Available проверяет, доступно ли хранилище на этой машине
+	Available() bool
+	Store(account string, secret []byte) error
+	// Load возвращает ErrKeyNotFound, если секрет не сохранен
+	Load(account string) ([]byte, error)
+	Delete(account string) error
+}
+
+const (
+	keyProviderService = "gophkeeper"
+	wrapKeyLength      = 32
+)
+
+// wrappedKeyFile - копия мастер-ключа, зашифрованная ключом из хранилища ОС.
+// В самом хранилище лежит только ключ обертки, поэтому ни файл, ни запись
+// в хранилище ОС по отдельности не раскрывают мастер-ключ.
+type wrappedKeyFile struct {
+	Provider  string    `json:"provider"`
+	Account   string    `json:"account"`
+	Data      string    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EnableKeyProvider сохраняет копию разблокированного мастер-ключа для
+// разблокировки через хранилище ОС. Файл с паролем продолжает работать.
+func (m *MasterKeyManager) EnableKeyProvider(p KeyProvider) error {
+	if !p.Available() {
+		return fmt.Errorf("хранилище %s недоступно", p.Name())
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isLoaded || m.isLocked {
+		return fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	wrapKey := make([]byte, wrapKeyLength)
+	if _, err := io.ReadFull(randReader, wrapKey); err != nil {
+		return fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+
+	wrapped, err := encryptWithKey(wrapKey, m.masterKey)
+	if err != nil {
+		return fmt.Errorf("ошибка шифрования мастер-ключа: %w", err)
+	}
+
+	account := m.providerAccount()
+	if err := p.Store(account, wrapKey); err != nil {
+		return fmt.Errorf("ошибка сохранения в %s: %w", p.Name(), err)
+	}
+
+	data, err := json.MarshalIndent(wrappedKeyFile{
+		Provider:  p.Name(),
+		Account:   account,
+		Data:      hex.EncodeToString(wrapped),
+		CreatedAt: clock(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	if err := os.WriteFile(m.wrappedKeyPath(), data, masterKeyPermissions); err != nil {
+		_ = p.Delete(account)
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	return nil
+}
+
+// UnlockWithProvider разблокирует мастер-ключ ключом из хранилища ОС
+func (m *MasterKeyManager) UnlockWithProvider(p KeyProvider) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isLoaded && !m.isLocked {
+		return nil
+	}
+
+	file, err := m.readWrappedKey()
+	if err != nil {
+		return err
+	}
+
+	wrapKey, err := p.Load(file.Account)
+	if err != nil {
+		return fmt.Errorf("ошибка получения ключа из %s: %w", p.Name(), err)
+	}
+
+	wrapped, err := hex.DecodeString(file.Data)
+	if err != nil {
+		return fmt.Errorf("ошибка декодирования ключа: %w", err)
+	}
+
+	masterKey, err := decryptWithKey(wrapKey, wrapped)
+	if err != nil {
+		return fmt.Errorf("ключ в %s не подходит: выполните разблокировку паролем и включите заново", p.Name())
+	}
+
+	m.setMasterKey(masterKey)
+	if err := m.unwrapRetired(); err != nil {
+		return err
+	}
+	m.isLoaded = true
+	m.isLocked = false
+	m.lastActivity = time.Now()
+
+	m.mu.Unlock()
+	_ = m.SaveSession()
+	m.mu.Lock()
+
+	return nil
+}
+
+// DisableKeyProvider удаляет копию мастер-ключа из хранилища ОС
+func (m *MasterKeyManager) DisableKeyProvider(p KeyProvider) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account := m.providerAccount()
+	if file, err := m.readWrappedKey(); err == nil {
+		account = file.Account
+	}
+
+	if err := p.Delete(account); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("ошибка удаления из %s: %w", p.Name(), err)
+	}
+
+	if err := os.Remove(m.wrappedKeyPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ошибка удаления файла: %w", err)
+	}
+
+	return nil
+}
+
+// KeyProviderEnabled проверяет, включена ли разблокировка через хранилище ОС
+func (m *MasterKeyManager) KeyProviderEnabled() bool {
+	_, err := os.Stat(m.wrappedKeyPath())
+	return err == nil
+}
+
+func (m *MasterKeyManager) readWrappedKey() (*wrappedKeyFile, error) {
+	data, err := os.ReadFile(m.wrappedKeyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("разблокировка через хранилище ОС не включена")
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var file wrappedKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("ошибка декодирования файла: %w", err)
+	}
+	return &file, nil
+}
+
+func (m *MasterKeyManager) wrappedKeyPath() string {
+	return m.keyPath + ".os"
+}
+
+// providerAccount - имя записи в хранилище ОС. Зависит от пути к мастер-ключу,
+// чтобы несколько установок клиента у одного пользователя не мешали друг другу.
+func (m *MasterKeyManager) providerAccount() string {
+	sum := sha256.Sum256([]byte(m.keyPath))
+	return "master-key-" + hex.EncodeToString(sum[:8])
+}
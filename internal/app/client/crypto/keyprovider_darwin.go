@@ -0,0 +1,76 @@
+// internal/app/client/crypto/keyprovider_darwin.go
+//go:build darwin
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainExitNotFound - код выхода security, если запись не найдена
+const keychainExitNotFound = 44
+
+// keychainProvider хранит секрет в связке ключей macOS через утилиту security.
+// Секрет передается через stdin интерактивного режима, а не аргументами,
+// чтобы не попасть в список процессов.
+type keychainProvider struct{}
+
+// NewOSKeyProvider возвращает хранилище секретов текущей ОС
+func NewOSKeyProvider(_ string) KeyProvider {
+	return keychainProvider{}
+}
+
+func (keychainProvider) Name() string { return "macOS Keychain" }
+
+func (keychainProvider) Available() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+func (keychainProvider) Store(account string, secret []byte) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+		keyProviderService, account, keyProviderService, hex.EncodeToString(secret))
+
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("security: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stderr.Len() > 0 {
+		return fmt.Errorf("security: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (keychainProvider) Load(account string) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password",
+		"-s", keyProviderService, "-a", account, "-w").Output()
+	if err != nil {
+		return nil, keychainError(err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
+func (keychainProvider) Delete(account string) error {
+	err := exec.Command("security", "delete-generic-password",
+		"-s", keyProviderService, "-a", account).Run()
+	if err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+func keychainError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == keychainExitNotFound {
+		return ErrKeyNotFound
+	}
+	return fmt.Errorf("security: %w", err)
+}
@@ -0,0 +1,67 @@
+// internal/app/client/crypto/keyprovider_linux.go
+//go:build linux
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretServiceProvider хранит секрет через Secret Service API
+// (GNOME Keyring, KWallet) с помощью утилиты secret-tool из libsecret.
+type secretServiceProvider struct{}
+
+// NewOSKeyProvider возвращает хранилище секретов текущей ОС
+func NewOSKeyProvider(_ string) KeyProvider {
+	return secretServiceProvider{}
+}
+
+func (secretServiceProvider) Name() string { return "Secret Service" }
+
+// Available требует secret-tool и сессионную шину D-Bus
+func (secretServiceProvider) Available() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (secretServiceProvider) Store(account string, secret []byte) error {
+	cmd := exec.Command("secret-tool", "store", "--label=GophKeeper master key",
+		"service", keyProviderService, "account", account)
+	cmd.Stdin = strings.NewReader(hex.EncodeToString(secret))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("secret-tool: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Load: secret-tool завершается с ошибкой и пустым выводом, если секрета нет
+func (secretServiceProvider) Load(account string) ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup",
+		"service", keyProviderService, "account", account).Output()
+	value := strings.TrimSpace(string(out))
+	if value == "" {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool: %w", err)
+	}
+	return hex.DecodeString(value)
+}
+
+func (secretServiceProvider) Delete(account string) error {
+	if err := exec.Command("secret-tool", "clear",
+		"service", keyProviderService, "account", account).Run(); err != nil {
+		return fmt.Errorf("secret-tool: %w", err)
+	}
+	return nil
+}
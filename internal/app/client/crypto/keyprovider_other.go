@@ -0,0 +1,23 @@
+// internal/app/client/crypto/keyprovider_other.go
+//go:build !darwin && !linux && !windows
+
+package crypto
+
+// unsupportedProvider - заглушка для ОС без поддерживаемого хранилища секретов.
+// Разблокировка выполняется только паролем.
+type unsupportedProvider struct{}
+
+// NewOSKeyProvider возвращает хранилище секретов текущей ОС
+func NewOSKeyProvider(_ string) KeyProvider {
+	return unsupportedProvider{}
+}
+
+func (unsupportedProvider) Name() string { return "OS keychain" }
+
+func (unsupportedProvider) Available() bool { return false }
+
+func (unsupportedProvider) Store(string, []byte) error { return ErrKeyNotFound }
+
+func (unsupportedProvider) Load(string) ([]byte, error) { return nil, ErrKeyNotFound }
+
+func (unsupportedProvider) Delete(string) error { return ErrKeyNotFound }
@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryProvider - хранилище секретов в памяти для тестов
+type memoryProvider struct {
+	secrets map[string][]byte
+}
+
+func (p *memoryProvider) Name() string    { return "memory" }
+func (p *memoryProvider) Available() bool { return true }
+
+func (p *memoryProvider) Store(account string, secret []byte) error {
+	p.secrets[account] = append([]byte(nil), secret...)
+	return nil
+}
+
+func (p *memoryProvider) Load(account string) ([]byte, error) {
+	secret, ok := p.secrets[account]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return secret, nil
+}
+
+func (p *memoryProvider) Delete(account string) error {
+	if _, ok := p.secrets[account]; !ok {
+		return ErrKeyNotFound
+	}
+	delete(p.secrets, account)
+	return nil
+}
+
+func TestMasterKeyManager_KeyProvider(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+
+	m, err := NewMasterKeyManager(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+
+	masterKey := append([]byte(nil), m.masterKey...)
+	provider := &memoryProvider{secrets: make(map[string][]byte)}
+
+	t.Run("Enable requires unlocked key", func(t *testing.T) {
+		m.Lock()
+		assert.Error(t, m.EnableKeyProvider(provider))
+		assert.False(t, m.KeyProviderEnabled())
+		require.NoError(t, m.UnlockMasterKey("password123"))
+	})
+
+	t.Run("Enable stores only the wrap key", func(t *testing.T) {
+		require.NoError(t, m.EnableKeyProvider(provider))
+		assert.True(t, m.KeyProviderEnabled())
+		require.Len(t, provider.secrets, 1)
+		for _, secret := range provider.secrets {
+			assert.NotEqual(t, masterKey, secret)
+		}
+	})
+
+	t.Run("Unlock with provider", func(t *testing.T) {
+		m.Lock()
+		require.NoError(t, m.UnlockWithProvider(provider))
+		assert.False(t, m.IsLocked())
+		assert.Equal(t, masterKey, m.masterKey)
+	})
+
+	t.Run("Unlock fails without stored key", func(t *testing.T) {
+		m.Lock()
+		other := &memoryProvider{secrets: make(map[string][]byte)}
+		err := m.UnlockWithProvider(other)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		assert.True(t, m.IsLocked())
+	})
+
+	t.Run("Disable removes key and file", func(t *testing.T) {
+		require.NoError(t, m.DisableKeyProvider(provider))
+		assert.False(t, m.KeyProviderEnabled())
+		assert.Empty(t, provider.secrets)
+		assert.Error(t, m.UnlockWithProvider(provider))
+	})
+
+	m.Lock()
+}
@@ -0,0 +1,80 @@
+// internal/app/client/crypto/keyprovider_windows.go
+//go:build windows
+
+package crypto
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dpapiProvider шифрует секрет DPAPI текущего пользователя Windows и хранит
+// результат в файле. Расшифровать его может только этот пользователь
+// на этой машине после входа в систему (пароль, PIN или Windows Hello).
+type dpapiProvider struct {
+	dir string
+}
+
+// NewOSKeyProvider возвращает хранилище секретов текущей ОС.
+// dir - каталог для файлов, зашифрованных DPAPI.
+func NewOSKeyProvider(dir string) KeyProvider {
+	return dpapiProvider{dir: dir}
+}
+
+func (dpapiProvider) Name() string { return "Windows DPAPI" }
+
+func (dpapiProvider) Available() bool { return true }
+
+func (p dpapiProvider) Store(account string, secret []byte) error {
+	in := windows.DataBlob{Size: uint32(len(secret)), Data: &secret[0]}
+	var out windows.DataBlob
+	if err := windows.CryptProtectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return fmt.Errorf("CryptProtectData: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	protected := unsafe.Slice(out.Data, out.Size)
+	return os.WriteFile(p.path(account), protected, masterKeyPermissions)
+}
+
+func (p dpapiProvider) Load(account string) ([]byte, error) {
+	protected, err := os.ReadFile(p.path(account))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	if len(protected) == 0 {
+		return nil, ErrKeyNotFound
+	}
+
+	in := windows.DataBlob{Size: uint32(len(protected)), Data: &protected[0]}
+	var out windows.DataBlob
+	if err := windows.CryptUnprotectData(&in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out); err != nil {
+		return nil, fmt.Errorf("CryptUnprotectData: %w", err)
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+
+	secret := make([]byte, out.Size)
+	copy(secret, unsafe.Slice(out.Data, out.Size))
+	return secret, nil
+}
+
+func (p dpapiProvider) Delete(account string) error {
+	if err := os.Remove(p.path(account)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrKeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (p dpapiProvider) path(account string) string {
+	return filepath.Join(p.dir, account+".dpapi")
+}
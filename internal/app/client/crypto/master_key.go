@@ -2,12 +2,10 @@
 package crypto
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -15,7 +13,6 @@ import (
 	"sync"
 	"time"
 
-	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -31,13 +28,13 @@ const (
 	argon2Threads = 4
 	argon2KeyLen  = 32
 
-	// Константы для шифрования
-	keyVersion = 1
-
 	// Константы для файла мастер-ключа
 	masterKeyPermissions = 0600
 )
 
+// ErrWrongPassword возвращается, если пароль не подходит к мастер-ключу
+var ErrWrongPassword = errors.New("неверный пароль")
+
 // MasterKeyHeader содержит метаданные мастер-ключа
 type MasterKeyHeader struct {
 	Version      int       `json:"version"`
@@ -45,18 +42,38 @@ type MasterKeyHeader struct {
 	Salt         string    `json:"salt"` // base64 encoded salt
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
-	KeyHash      string    `json:"key_hash"`   // SHA256 хэш ключа для проверки
-	Iterations   int       `json:"iterations"` // Для PBKDF2
+	KeyHash      string    `json:"key_hash"` // SHA256 хэш ключа для проверки
+	// Cipher - AEAD мастер-ключа и записей; пустое значение - AES-256-GCM
+	Cipher string `json:"cipher,omitempty"`
+	// KeyVersion - версия ключа данных; пустое значение - ключ без ротаций
+	KeyVersion int `json:"key_version,omitempty"`
+	// RetiredKeys - прежние ключи данных после ротаций
+	RetiredKeys []RetiredKey `json:"retired_keys,omitempty"`
+	KDFParams
 }
 
 // MasterKeyManager управляет мастер-ключом
 type MasterKeyManager struct {
-	masterKey []byte          // Загруженный мастер-ключ в памяти
+	masterKey []byte          // Загруженный мастер-ключ в памяти (срез keyMem)
+	retired   map[int][]byte  // Прежние ключи данных по версиям (срезы retiredMem)
 	header    MasterKeyHeader // Заголовок с метаданными
 	keyPath   string          // Путь к файлу мастер-ключа
 	isLoaded  bool            // Загружен ли ключ в память
 	isLocked  bool            // Заблокирован ли ключ (очищен из памяти)
 	mu        sync.RWMutex
+
+	// Защищенная память ключей; ключи попадают в нее через setMasterKey
+	// и unwrapRetired, а clearKey ее затирает
+	keyMem     *SecureBytes
+	retiredMem []*SecureBytes
+
+	// Алгоритмы для новых ключей; KDF также применяется при смене пароля
+	kdfID  string
+	aeadID string
+
+	idleTimeout  time.Duration // автоблокировка после простоя (0 - выключена)
+	lastActivity time.Time     // последнее использование ключа, хранится в файле сессии
+	lastPersist  time.Time     // последняя запись времени активности в файл сессии
 }
 
 // NewMasterKeyManager создает новый менеджер мастер-ключа
@@ -71,6 +88,8 @@ func NewMasterKeyManager(keyPath string) (*MasterKeyManager, error) {
 		keyPath:  absPath,
 		isLoaded: false,
 		isLocked: true,
+		kdfID:    DefaultKDF,
+		aeadID:   DefaultAEAD,
 	}
 
 	// Если файл существует, загружаем заголовок
@@ -86,38 +105,85 @@ func NewMasterKeyManager(keyPath string) (*MasterKeyManager, error) {
 	return manager, nil
 }
 
+// SetAlgorithms задает KDF и AEAD для нового мастер-ключа. KDF также
+// применяется при смене пароля, что позволяет перейти на более стойкий алгоритм.
+// AEAD существующего ключа не меняется: им зашифрованы записи.
+func (m *MasterKeyManager) SetAlgorithms(kdfID, aeadID string) error {
+	if _, err := LookupKDF(kdfID); err != nil {
+		return err
+	}
+	if _, err := LookupAEAD(aeadID); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.kdfID = kdfID
+	m.aeadID = aeadID
+	return nil
+}
+
+// Algorithms возвращает KDF и AEAD текущего мастер-ключа
+func (m *MasterKeyManager) Algorithms() (kdfID, aeadID string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aeadID = m.header.Cipher
+	if aeadID == "" {
+		aeadID = AEADAESGCM
+	}
+	return m.header.KeyAlgorithm, aeadID
+}
+
 // GenerateMasterKey генерирует новый мастер-ключ
 func (m *MasterKeyManager) GenerateMasterKey(password string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	kdf, err := LookupKDF(m.kdfID)
+	if err != nil {
+		return err
+	}
+	aead, err := LookupAEAD(m.aeadID)
+	if err != nil {
+		return err
+	}
+
 	// Генерируем соль
-	salt := make([]byte, pbkdf2SaltLength)
-	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+	salt := make([]byte, kdf.SaltLength())
+	if _, err := io.ReadFull(randReader, salt); err != nil {
 		return fmt.Errorf("ошибка генерации соли: %w", err)
 	}
 
-	// Генерируем ключ из пароля с помощью PBKDF2
-	key := pbkdf2.Key([]byte(password), salt, pbkdf2Iterations, pbkdf2KeyLength, sha256.New)
+	// Генерируем ключ из пароля
+	params := kdf.DefaultParams()
+	key, err := kdf.DeriveKey(password, salt, params, aead.KeySize())
+	if err != nil {
+		return fmt.Errorf("ошибка получения ключа: %w", err)
+	}
 
 	// Вычисляем хэш ключа для будущей проверки
 	keyHash := sha256.Sum256(key)
 
 	// Создаем заголовок
 	m.header = MasterKeyHeader{
-		Version:      keyVersion,
-		KeyAlgorithm: "PBKDF2-SHA256",
+		Version:      keyFileVersion,
+		KeyAlgorithm: kdf.ID(),
 		Salt:         hex.EncodeToString(salt),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		CreatedAt:    clock(),
+		UpdatedAt:    clock(),
 		KeyHash:      hex.EncodeToString(keyHash[:]),
-		Iterations:   pbkdf2Iterations,
+		Cipher:       aead.ID(),
+		KDFParams:    params,
 	}
 
 	// Сохраняем ключ в память
-	m.masterKey = key
+	m.clearRetired()
+	m.setMasterKey(key)
 	m.isLoaded = true
 	m.isLocked = false
+	m.lastActivity = time.Now()
 
 	// Сохраняем ключ в файл (зашифрованный)
 	if err := m.saveMasterKey(); err != nil {
@@ -137,67 +203,53 @@ func (m *MasterKeyManager) UnlockMasterKey(password string) error {
 		return nil
 	}
 
-	encryptedData, err := os.ReadFile(m.keyPath)
+	container, err := readKeyFile(m.keyPath)
 	if err != nil {
-		return fmt.Errorf("ошибка чтения файла ключа: %w", err)
-	}
-
-	var container struct {
-		Header MasterKeyHeader `json:"header"`
-		Data   string          `json:"data"` // base64 encoded encrypted key
-	}
-
-	if err := json.Unmarshal(encryptedData, &container); err != nil {
-		return fmt.Errorf("ошибка декодирования файла ключа: %w", err)
+		return err
 	}
 
 	m.header = container.Header
 
-	// Декодируем соль
-	salt, err := hex.DecodeString(m.header.Salt)
+	aead, err := LookupAEAD(m.header.Cipher)
 	if err != nil {
-		return fmt.Errorf("ошибка декодирования соли: %w", err)
-	}
-
-	// Восстанавливаем ключ из пароля
-	var key []byte
-	switch m.header.KeyAlgorithm {
-	case "PBKDF2-SHA256":
-		key = pbkdf2.Key([]byte(password), salt, m.header.Iterations, pbkdf2KeyLength, sha256.New)
-	case "Argon2id":
-		// Для будущей поддержки Argon2
-		salt = salt[:16] // Argon2 требует 16 байт соли
-		key = argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
-	default:
-		return fmt.Errorf("неподдерживаемый алгоритм: %s", m.header.KeyAlgorithm)
-	}
-
-	// Проверяем хэш ключа
-	keyHash := sha256.Sum256(key)
-	if hex.EncodeToString(keyHash[:]) != m.header.KeyHash {
-		return fmt.Errorf("неверный пароль")
+		return err
 	}
 
-	// Декодируем и расшифровываем мастер-ключ
-	encryptedKey, err := hex.DecodeString(container.Data)
+	// Восстанавливаем ключ из пароля и проверяем его хэш
+	key, err := m.deriveKey(password, aead.KeySize())
 	if err != nil {
-		return fmt.Errorf("ошибка декодирования зашифрованного ключа: %w", err)
+		return err
 	}
+	defer wipe(key)
+
+	encryptedKey := container.Data
 
 	// Для первого раза ключ еще не зашифрован (генерируется из пароля)
 	if len(encryptedKey) == 0 {
-		m.masterKey = key
+		m.setMasterKey(key)
 	} else {
 		// Расшифровываем мастер-ключ
-		decryptedKey, err := decryptWithKey(key, encryptedKey)
+		decryptedKey, err := aead.Open(key, encryptedKey)
 		if err != nil {
 			return fmt.Errorf("ошибка расшифровки мастер-ключа: %w", err)
 		}
-		m.masterKey = decryptedKey
+		m.setMasterKey(decryptedKey)
+	}
+	if err := m.unwrapRetired(); err != nil {
+		return err
 	}
 
 	m.isLoaded = true
 	m.isLocked = false
+	m.lastActivity = time.Now()
+
+	// Файл старой версии переписываем только после проверки пароля. При ошибке
+	// записи остается прежний файл: он по-прежнему читается.
+	if container.Version < keyFileVersion {
+		if err := writeKeyFile(m.keyPath, container); err == nil {
+			m.header.Version = keyFileVersion
+		}
+	}
 
 	m.mu.Unlock()
 	_ = m.SaveSession()
@@ -210,53 +262,31 @@ func (m *MasterKeyManager) UnlockMasterKey(password string) error {
 func (m *MasterKeyManager) saveMasterKey() error {
 	// Для первого сохранения используем ключ, полученный из пароля
 	// (он же и будет мастер-ключом)
-	var encryptedData string
+	var encryptedData []byte
 
 	// Если у нас уже есть мастер-ключ в памяти, шифруем его самим собой
 	if len(m.masterKey) > 0 {
+		aead, err := LookupAEAD(m.header.Cipher)
+		if err != nil {
+			return err
+		}
+
 		// Шифруем мастер-ключ самим собой
-		encryptedKey, err := encryptWithKey(m.masterKey, m.masterKey)
+		encryptedKey, err := aead.Seal(m.masterKey, m.masterKey)
 		if err != nil {
 			return fmt.Errorf("ошибка шифрования мастер-ключа: %w", err)
 		}
-		encryptedData = hex.EncodeToString(encryptedKey)
-	}
-
-	container := struct {
-		Header MasterKeyHeader `json:"header"`
-		Data   string          `json:"data"`
-	}{
-		Header: m.header,
-		Data:   encryptedData,
-	}
-
-	// Сериализуем в JSON
-	data, err := json.MarshalIndent(container, "", "  ")
-	if err != nil {
-		return fmt.Errorf("ошибка сериализации: %w", err)
-	}
-
-	// Сохраняем в файл
-	if err := os.WriteFile(m.keyPath, data, masterKeyPermissions); err != nil {
-		return fmt.Errorf("ошибка записи файла: %w", err)
+		encryptedData = encryptedKey
 	}
 
-	return nil
+	return writeKeyFile(m.keyPath, &keyContainer{Header: m.header, Data: encryptedData})
 }
 
 // loadHeader загружает только заголовок мастер-ключа
 func (m *MasterKeyManager) loadHeader() error {
-	data, err := os.ReadFile(m.keyPath)
+	container, err := readKeyFile(m.keyPath)
 	if err != nil {
-		return fmt.Errorf("ошибка чтения файла ключа: %w", err)
-	}
-
-	var container struct {
-		Header MasterKeyHeader `json:"header"`
-	}
-
-	if err := json.Unmarshal(data, &container); err != nil {
-		return fmt.Errorf("ошибка декодирования файла ключа: %w", err)
+		return err
 	}
 
 	m.header = container.Header
@@ -265,6 +295,10 @@ func (m *MasterKeyManager) loadHeader() error {
 
 // EncryptData шифрует данные с использованием мастер-ключа
 func (m *MasterKeyManager) EncryptData(plaintext []byte) ([]byte, error) {
+	if err := m.touch(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -272,11 +306,19 @@ func (m *MasterKeyManager) EncryptData(plaintext []byte) ([]byte, error) {
 		return nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
 	}
 
-	return encryptWithKey(m.masterKey, plaintext)
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	return m.seal(aead, plaintext)
 }
 
 // DecryptData расшифровывает данные с использованием мастер-ключа
 func (m *MasterKeyManager) DecryptData(ciphertext []byte) ([]byte, error) {
+	if err := m.touch(); err != nil {
+		return nil, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -284,14 +326,18 @@ func (m *MasterKeyManager) DecryptData(ciphertext []byte) ([]byte, error) {
 		return nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
 	}
 
-	return decryptWithKey(m.masterKey, ciphertext)
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	return m.open(aead, ciphertext)
 }
 
 // EncryptDataWithPassword шифрует данные с использованием пароля напрямую
 func (m *MasterKeyManager) EncryptDataWithPassword(plaintext []byte, password string) ([]byte, error) {
 	// Генерируем ключ из пароля для этого шифрования
 	salt := make([]byte, pbkdf2SaltLength)
-	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+	if _, err := io.ReadFull(randReader, salt); err != nil {
 		return nil, fmt.Errorf("ошибка генерации соли: %w", err)
 	}
 
@@ -343,68 +389,118 @@ func (m *MasterKeyManager) GetKeyHash() (string, error) {
 
 // ChangeMasterPassword изменяет мастер-пароль
 func (m *MasterKeyManager) ChangeMasterPassword(oldPassword, newPassword string) error {
+	return m.ChangeMasterPasswordVerified(oldPassword, newPassword, nil)
+}
+
+// ChangeMasterPasswordVerified изменяет мастер-пароль, заменяя файл ключа
+// только после проверки новой копии. Копия пишется во временный каталог
+// рядом с файлом, разблокируется новым паролем и передается в verify
+// (например, для расшифровки образцовой записи). При любой ошибке файл
+// ключа остается прежним.
+func (m *MasterKeyManager) ChangeMasterPasswordVerified(oldPassword, newPassword string, verify func(*MasterKeyManager) error) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if !m.isLoaded || m.isLocked || len(m.masterKey) == 0 {
+		return fmt.Errorf("мастер-ключ не разблокирован")
+	}
+
 	// Проверяем старый пароль
 	if err := m.verifyPassword(oldPassword); err != nil {
 		return fmt.Errorf("неверный старый пароль: %w", err)
 	}
 
+	// Новый пароль защищается KDF, выбранным для новых ключей
+	kdf, err := LookupKDF(m.kdfID)
+	if err != nil {
+		return err
+	}
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return err
+	}
+
 	// Генерируем новую соль
-	newSalt := make([]byte, pbkdf2SaltLength)
-	if _, err := io.ReadFull(rand.Reader, newSalt); err != nil {
+	newSalt := make([]byte, kdf.SaltLength())
+	if _, err := io.ReadFull(randReader, newSalt); err != nil {
 		return fmt.Errorf("ошибка генерации новой соли: %w", err)
 	}
 
 	// Генерируем новый ключ из пароля
-	newKey := pbkdf2.Key([]byte(newPassword), newSalt, pbkdf2Iterations, pbkdf2KeyLength, sha256.New)
+	params := kdf.DefaultParams()
+	newKey, err := kdf.DeriveKey(newPassword, newSalt, params, aead.KeySize())
+	if err != nil {
+		return fmt.Errorf("ошибка получения ключа: %w", err)
+	}
 	newKeyHash := sha256.Sum256(newKey)
 
-	// Обновляем заголовок
-	m.header.Salt = hex.EncodeToString(newSalt)
-	m.header.KeyHash = hex.EncodeToString(newKeyHash[:])
-	m.header.UpdatedAt = time.Now()
+	// Готовим новый заголовок, не трогая текущий до замены файла
+	header := m.header
+	header.KeyAlgorithm = kdf.ID()
+	header.KDFParams = params
+	header.Salt = hex.EncodeToString(newSalt)
+	header.KeyHash = hex.EncodeToString(newKeyHash[:])
+	header.UpdatedAt = clock()
+	header.Version = keyFileVersion
 
 	// Шифруем текущий мастер-ключ новым ключом
-	encryptedMasterKey, err := encryptWithKey(newKey, m.masterKey)
+	encryptedMasterKey, err := aead.Seal(newKey, m.masterKey)
 	if err != nil {
 		return fmt.Errorf("ошибка шифрования нового мастер-ключа: %w", err)
 	}
 
-	// Сохраняем изменения
-	container := struct {
-		Header MasterKeyHeader `json:"header"`
-		Data   string          `json:"data"`
-	}{
-		Header: m.header,
-		Data:   hex.EncodeToString(encryptedMasterKey),
+	// Во временном каталоге остается и файл сессии проверочной копии
+	tmpDir, err := os.MkdirTemp(filepath.Dir(m.keyPath), ".password-change-")
+	if err != nil {
+		return fmt.Errorf("ошибка создания временного каталога: %w", err)
 	}
+	defer os.RemoveAll(tmpDir)
 
-	data, err := json.MarshalIndent(container, "", "  ")
-	if err != nil {
-		return fmt.Errorf("ошибка сериализации: %w", err)
+	tmpPath := filepath.Join(tmpDir, filepath.Base(m.keyPath))
+	if err := writeKeyFile(tmpPath, &keyContainer{Header: header, Data: encryptedMasterKey}); err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(m.keyPath, data, masterKeyPermissions); err != nil {
-		return fmt.Errorf("ошибка записи файла: %w", err)
+	// Новая копия должна открываться новым паролем и давать тот же ключ
+	check := &MasterKeyManager{keyPath: tmpPath, isLocked: true, kdfID: m.kdfID, aeadID: m.aeadID}
+	if err := check.UnlockMasterKey(newPassword); err != nil {
+		return fmt.Errorf("новый файл ключа не прошел проверку: %w", err)
+	}
+	defer check.Lock()
+
+	if subtle.ConstantTimeCompare(check.masterKey, m.masterKey) != 1 {
+		return fmt.Errorf("новый файл ключа не прошел проверку: ключ не совпадает")
+	}
+	if verify != nil {
+		if err := verify(check); err != nil {
+			return fmt.Errorf("новый файл ключа не прошел проверку: %w", err)
+		}
 	}
 
+	// Атомарно заменяем файл ключа
+	if err := os.Rename(tmpPath, m.keyPath); err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	m.header = header
 	return nil
 }
 
 // Lock блокирует мастер-ключ (очищает из памяти)
 func (m *MasterKeyManager) Lock() {
+	m.mu.Lock()
 	m.clearKey()
 	m.isLocked = true
+	m.mu.Unlock()
+
 	_ = m.ClearSession()
 }
 
-// IsLocked проверяет, заблокирован ли ключ
+// IsLocked проверяет, заблокирован ли ключ. Ключ, простаивавший дольше
+// таймаута автоблокировки, считается заблокированным.
 func (m *MasterKeyManager) IsLocked() bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.isLocked
+	return m.isLocked || m.idleExpired(time.Now())
 }
 
 // IsInitialized проверяет, инициализирован ли мастер-ключ
@@ -414,27 +510,101 @@ func (m *MasterKeyManager) IsInitialized() bool {
 	return m.header.CreatedAt != (time.Time{})
 }
 
+// VerifyPassword проверяет мастер-пароль, не меняя состояние блокировки
+func (m *MasterKeyManager) VerifyPassword(password string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.verifyPassword(password)
+}
+
+// HeaderKeyHash возвращает хэш ключа из заголовка файла мастер-ключа.
+// В отличие от GetKeyHash не требует разблокировки.
+func (m *MasterKeyManager) HeaderKeyHash() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.header.KeyHash
+}
+
+// KeyFile возвращает содержимое файла мастер-ключа (ключ в нем зашифрован)
+func (m *MasterKeyManager) KeyFile() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, err := os.ReadFile(m.keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла ключа: %w", err)
+	}
+	return data, nil
+}
+
+// RestoreKeyFile записывает файл мастер-ключа из резервной копии.
+// Допускается только если локальный мастер-ключ еще не создан.
+func (m *MasterKeyManager) RestoreKeyFile(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.header.CreatedAt != (time.Time{}) {
+		return fmt.Errorf("мастер-ключ уже инициализирован")
+	}
+
+	// Файл сохраняется как есть; старая версия формата обновится при разблокировке
+	container, err := decodeKeyFile(data)
+	if err != nil {
+		return err
+	}
+
+	if err := writeKeyFileData(m.keyPath, data); err != nil {
+		return err
+	}
+
+	m.header = container.Header
+	return nil
+}
+
 // verifyPassword проверяет пароль без разблокировки ключа
 func (m *MasterKeyManager) verifyPassword(password string) error {
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.deriveKey(password, aead.KeySize())
+	return err
+}
+
+// deriveKey получает ключ из пароля по KDF и параметрам заголовка
+// и сверяет его хэш с заголовком
+func (m *MasterKeyManager) deriveKey(password string, keyLen int) ([]byte, error) {
+	kdf, err := LookupKDF(m.header.KeyAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
 	salt, err := hex.DecodeString(m.header.Salt)
 	if err != nil {
-		return fmt.Errorf("ошибка декодирования соли: %w", err)
+		return nil, fmt.Errorf("ошибка декодирования соли: %w", err)
 	}
 
-	var key []byte
-	switch m.header.KeyAlgorithm {
-	case "PBKDF2-SHA256":
-		key = pbkdf2.Key([]byte(password), salt, m.header.Iterations, pbkdf2KeyLength, sha256.New)
-	default:
-		return fmt.Errorf("неподдерживаемый алгоритм: %s", m.header.KeyAlgorithm)
+	key, err := kdf.DeriveKey(password, salt, m.header.KDFParams, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ключа: %w", err)
 	}
 
 	keyHash := sha256.Sum256(key)
 	if hex.EncodeToString(keyHash[:]) != m.header.KeyHash {
-		return fmt.Errorf("неверный пароль")
+		return nil, ErrWrongPassword
 	}
 
-	return nil
+	return key, nil
+}
+
+// setMasterKey переносит ключ в защищенную память и затирает key.
+// Вызывается под блокировкой.
+func (m *MasterKeyManager) setMasterKey(key []byte) {
+	locked := SecureBytesFrom(key)
+	_ = m.keyMem.Close()
+	m.keyMem = locked
+	m.masterKey = locked.Bytes()
 }
 
 // clearKey безопасно очищает ключ из памяти
@@ -446,52 +616,18 @@ func (m *MasterKeyManager) clearKey() {
 		}
 		m.masterKey = nil
 	}
+	_ = m.keyMem.Close()
+	m.keyMem = nil
+	m.clearRetired()
 	m.isLoaded = false
 }
 
 // encryptWithKey шифрует данные с использованием AES-GCM
 func encryptWithKey(key, plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка создания GCM: %w", err)
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, fmt.Errorf("ошибка генерации nonce: %w", err)
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	return ciphertext, nil
+	return aesGCM{}.Seal(key, plaintext)
 }
 
 // decryptWithKey расшифровывает данные с использованием AES-GCM
 func decryptWithKey(key, ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка создания GCM: %w", err)
-	}
-
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return nil, fmt.Errorf("шифротекст слишком короткий")
-	}
-
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка расшифровки: %w", err)
-	}
-
-	return plaintext, nil
+	return aesGCM{}.Open(key, ciphertext)
 }
@@ -0,0 +1,17 @@
+// internal/app/client/crypto/memlock_unix.go
+//go:build !windows
+
+package crypto
+
+import "golang.org/x/sys/unix"
+
+// lockMemory закрепляет страницы в памяти (mlock). Ошибка означает, что
+// превышен RLIMIT_MEMLOCK: буфер работает, но может попасть в swap.
+func lockMemory(b []byte) error {
+	return unix.Mlock(b)
+}
+
+// unlockMemory снимает закрепление страниц
+func unlockMemory(b []byte) error {
+	return unix.Munlock(b)
+}
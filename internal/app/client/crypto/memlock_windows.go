@@ -0,0 +1,22 @@
+// internal/app/client/crypto/memlock_windows.go
+//go:build windows
+
+package crypto
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockMemory закрепляет страницы в рабочем наборе процесса (VirtualLock).
+// Ошибка означает, что рабочий набор исчерпан: буфер работает, но может
+// попасть в файл подкачки.
+func lockMemory(b []byte) error {
+	return windows.VirtualLock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
+
+// unlockMemory снимает закрепление страниц
+func unlockMemory(b []byte) error {
+	return windows.VirtualUnlock(uintptr(unsafe.Pointer(&b[0])), uintptr(len(b)))
+}
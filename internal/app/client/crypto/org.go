@@ -0,0 +1,89 @@
+// internal/app/client/crypto/org.go
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// OrgKeyLength длина ключа хранилища организации (AES-256)
+	OrgKeyLength = 32
+
+	inviteCodeBytes = 20
+)
+
+var inviteEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateOrgKey создает случайный ключ хранилища организации.
+// Сервер видит ключ только зашифрованным мастер-ключом участника или кодом приглашения.
+func GenerateOrgKey() ([]byte, error) {
+	key := make([]byte, OrgKeyLength)
+	if _, err := io.ReadFull(randReader, key); err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа организации: %w", err)
+	}
+	return key, nil
+}
+
+// GenerateInviteCode создает одноразовый код приглашения, который
+// передается приглашенному вне сервиса
+func GenerateInviteCode() (string, error) {
+	raw := make([]byte, inviteCodeBytes)
+	if _, err := io.ReadFull(randReader, raw); err != nil {
+		return "", fmt.Errorf("ошибка генерации кода приглашения: %w", err)
+	}
+	return inviteEncoding.EncodeToString(raw), nil
+}
+
+// WrapKeyWithCode шифрует ключ хранилища кодом приглашения: соль PBKDF2 + AES-GCM
+func WrapKeyWithCode(key []byte, code string) ([]byte, error) {
+	salt := make([]byte, pbkdf2SaltLength)
+	if _, err := io.ReadFull(randReader, salt); err != nil {
+		return nil, fmt.Errorf("ошибка генерации соли: %w", err)
+	}
+
+	ciphertext, err := encryptWithKey(inviteKey(code, salt), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(salt, ciphertext...), nil
+}
+
+// UnwrapKeyWithCode расшифровывает ключ хранилища кодом приглашения
+func UnwrapKeyWithCode(wrapped []byte, code string) ([]byte, error) {
+	if len(wrapped) < pbkdf2SaltLength {
+		return nil, fmt.Errorf("неверный формат ключа приглашения")
+	}
+
+	key, err := decryptWithKey(inviteKey(code, wrapped[:pbkdf2SaltLength]), wrapped[pbkdf2SaltLength:])
+	if err != nil {
+		return nil, fmt.Errorf("неверный код приглашения")
+	}
+	if len(key) != OrgKeyLength {
+		return nil, fmt.Errorf("неверная длина ключа организации")
+	}
+
+	return key, nil
+}
+
+// EncryptWithOrgKey шифрует данные записи ключом хранилища организации
+func EncryptWithOrgKey(key, plaintext []byte) ([]byte, error) {
+	return encryptWithKey(key, plaintext)
+}
+
+// DecryptWithOrgKey расшифровывает данные записи ключом хранилища организации
+func DecryptWithOrgKey(key, ciphertext []byte) ([]byte, error) {
+	return decryptWithKey(key, ciphertext)
+}
+
+// inviteKey выводит ключ из кода; регистр и пробелы при вводе кода не важны
+func inviteKey(code string, salt []byte) []byte {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	return pbkdf2.Key([]byte(normalized), salt, pbkdf2Iterations, pbkdf2KeyLength, sha256.New)
+}
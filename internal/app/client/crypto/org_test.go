@@ -0,0 +1,57 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOrgKey_WrapWithCode(t *testing.T) {
+	key, err := GenerateOrgKey()
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа: %v", err)
+	}
+
+	code, err := GenerateInviteCode()
+	if err != nil {
+		t.Fatalf("Ошибка генерации кода: %v", err)
+	}
+
+	wrapped, err := WrapKeyWithCode(key, code)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования ключа: %v", err)
+	}
+
+	// Код, введенный в нижнем регистре и с пробелами, тоже подходит
+	spaced := strings.ToLower(code[:8] + " " + code[8:])
+	got, err := UnwrapKeyWithCode(wrapped, spaced)
+	if err != nil {
+		t.Fatalf("Ошибка расшифровки ключа: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Error("Ключ не совпадает")
+	}
+
+	if _, err := UnwrapKeyWithCode(wrapped, "WRONGCODE"); err == nil {
+		t.Error("Неверный код должен приводить к ошибке")
+	}
+}
+
+func TestOrgKey_EncryptRecord(t *testing.T) {
+	key, _ := GenerateOrgKey()
+	other, _ := GenerateOrgKey()
+
+	ciphertext, err := EncryptWithOrgKey(key, []byte("shared secret"))
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+
+	plaintext, err := DecryptWithOrgKey(key, ciphertext)
+	if err != nil || string(plaintext) != "shared secret" {
+		t.Fatalf("Ошибка расшифровки: %v", err)
+	}
+
+	if _, err := DecryptWithOrgKey(other, ciphertext); err == nil {
+		t.Error("Чужой ключ не должен расшифровывать запись")
+	}
+}
@@ -0,0 +1,273 @@
+// internal/app/client/crypto/pin.go
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Быстрая разблокировка коротким PIN-кодом. Копия мастер-ключа шифруется
+// ключом, который получается из PIN и случайного секрета в хранилище ОС.
+// Файл с копией бесполезен без хранилища ОС, а секрет из хранилища - без PIN,
+// поэтому перебор PIN вне этой учетной записи ОС невозможен. После нескольких
+// неверных PIN копия и секрет удаляются, и остается только мастер-пароль.
+
+const (
+	// MinPINLength - наименьшая длина PIN
+	MinPINLength = 4
+	// DefaultPINAttempts - число неверных PIN, после которого PIN отключается
+	DefaultPINAttempts = 3
+
+	pinSaltLength = 16
+)
+
+var (
+	// ErrPINNotEnabled возвращается, если разблокировка по PIN не включена
+	ErrPINNotEnabled = errors.New("разблокировка по PIN не включена")
+	// ErrPINDisabled возвращается, если PIN отключен после превышения числа попыток
+	ErrPINDisabled = errors.New("превышено число попыток ввода PIN, PIN отключен, используйте мастер-пароль")
+)
+
+// WrongPINError возвращается при неверном PIN, пока попытки не исчерпаны
+type WrongPINError struct {
+	AttemptsLeft int
+}
+
+func (e *WrongPINError) Error() string {
+	return fmt.Sprintf("неверный PIN, осталось попыток: %d", e.AttemptsLeft)
+}
+
+// pinKeyFile - копия мастер-ключа, зашифрованная ключом из PIN и хранилища ОС
+type pinKeyFile struct {
+	Provider  string    `json:"provider"`
+	Account   string    `json:"account"`
+	Salt      string    `json:"salt"`
+	Data      string    `json:"data"`
+	Failures  int       `json:"failures"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidatePIN проверяет длину PIN
+func ValidatePIN(pin string) error {
+	if len([]rune(pin)) < MinPINLength {
+		return fmt.Errorf("PIN должен содержать минимум %d символа", MinPINLength)
+	}
+	return nil
+}
+
+// EnablePIN сохраняет копию разблокированного мастер-ключа для разблокировки по PIN.
+// Повторный вызов заменяет PIN и сбрасывает счетчик ошибок.
+func (m *MasterKeyManager) EnablePIN(p KeyProvider, pin string) error {
+	if err := ValidatePIN(pin); err != nil {
+		return err
+	}
+	if !p.Available() {
+		return fmt.Errorf("хранилище %s недоступно", p.Name())
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isLoaded || m.isLocked {
+		return fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	secret := make([]byte, wrapKeyLength)
+	if _, err := io.ReadFull(randReader, secret); err != nil {
+		return fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+	salt := make([]byte, pinSaltLength)
+	if _, err := io.ReadFull(randReader, salt); err != nil {
+		return fmt.Errorf("ошибка генерации соли: %w", err)
+	}
+
+	wrapped, err := encryptWithKey(pinKey(secret, pin, salt), m.masterKey)
+	if err != nil {
+		return fmt.Errorf("ошибка шифрования мастер-ключа: %w", err)
+	}
+
+	account := m.pinAccount()
+	if err := p.Store(account, secret); err != nil {
+		return fmt.Errorf("ошибка сохранения в %s: %w", p.Name(), err)
+	}
+
+	file := &pinKeyFile{
+		Provider:  p.Name(),
+		Account:   account,
+		Salt:      hex.EncodeToString(salt),
+		Data:      hex.EncodeToString(wrapped),
+		CreatedAt: clock(),
+	}
+	if err := m.writePINFile(file); err != nil {
+		_ = p.Delete(account)
+		return err
+	}
+
+	return nil
+}
+
+// UnlockWithPIN разблокирует мастер-ключ по PIN. После maxAttempts неверных PIN
+// подряд PIN отключается и возвращается ErrPINDisabled.
+func (m *MasterKeyManager) UnlockWithPIN(p KeyProvider, pin string, maxAttempts int) error {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultPINAttempts
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isLoaded && !m.isLocked {
+		return nil
+	}
+
+	file, err := m.readPINFile()
+	if err != nil {
+		return err
+	}
+
+	secret, err := p.Load(file.Account)
+	if err != nil {
+		return fmt.Errorf("ошибка получения ключа из %s: %w", p.Name(), err)
+	}
+
+	salt, err := hex.DecodeString(file.Salt)
+	if err != nil {
+		return fmt.Errorf("ошибка декодирования соли: %w", err)
+	}
+	wrapped, err := hex.DecodeString(file.Data)
+	if err != nil {
+		return fmt.Errorf("ошибка декодирования ключа: %w", err)
+	}
+
+	masterKey, err := decryptWithKey(pinKey(secret, pin, salt), wrapped)
+	if err != nil {
+		file.Failures++
+		if file.Failures >= maxAttempts {
+			m.removePIN(p, file.Account)
+			return ErrPINDisabled
+		}
+		if err := m.writePINFile(file); err != nil {
+			// Без сохраненного счетчика перебор не ограничен: отключаем PIN
+			m.removePIN(p, file.Account)
+			return ErrPINDisabled
+		}
+		return &WrongPINError{AttemptsLeft: maxAttempts - file.Failures}
+	}
+
+	if file.Failures > 0 {
+		file.Failures = 0
+		_ = m.writePINFile(file)
+	}
+
+	m.setMasterKey(masterKey)
+	if err := m.unwrapRetired(); err != nil {
+		return err
+	}
+	m.isLoaded = true
+	m.isLocked = false
+	m.lastActivity = time.Now()
+
+	m.mu.Unlock()
+	_ = m.SaveSession()
+	m.mu.Lock()
+
+	return nil
+}
+
+// DisablePIN удаляет копию мастер-ключа и секрет PIN из хранилища ОС
+func (m *MasterKeyManager) DisablePIN(p KeyProvider) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account := m.pinAccount()
+	if file, err := m.readPINFile(); err == nil {
+		account = file.Account
+	}
+
+	if err := p.Delete(account); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("ошибка удаления из %s: %w", p.Name(), err)
+	}
+
+	if err := os.Remove(m.pinPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ошибка удаления файла: %w", err)
+	}
+
+	return nil
+}
+
+// PINEnabled проверяет, включена ли разблокировка по PIN
+func (m *MasterKeyManager) PINEnabled() bool {
+	_, err := os.Stat(m.pinPath())
+	return err == nil
+}
+
+// PINFailures возвращает число неверных PIN подряд
+func (m *MasterKeyManager) PINFailures() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file, err := m.readPINFile()
+	if err != nil {
+		return 0
+	}
+	return file.Failures
+}
+
+// removePIN удаляет копию мастер-ключа без учета ошибок; вызывается под m.mu
+func (m *MasterKeyManager) removePIN(p KeyProvider, account string) {
+	_ = p.Delete(account)
+	_ = os.Remove(m.pinPath())
+}
+
+func (m *MasterKeyManager) readPINFile() (*pinKeyFile, error) {
+	data, err := os.ReadFile(m.pinPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrPINNotEnabled
+		}
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var file pinKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("ошибка декодирования файла: %w", err)
+	}
+	return &file, nil
+}
+
+func (m *MasterKeyManager) writePINFile(file *pinKeyFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	if err := os.WriteFile(m.pinPath(), data, masterKeyPermissions); err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	return nil
+}
+
+func (m *MasterKeyManager) pinPath() string {
+	return m.keyPath + ".pin"
+}
+
+func (m *MasterKeyManager) pinAccount() string {
+	return m.providerAccount() + "-pin"
+}
+
+// pinKey получает ключ обертки из PIN и секрета из хранилища ОС
+func pinKey(secret []byte, pin string, salt []byte) []byte {
+	stretched := argon2.IDKey([]byte(pin), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(stretched)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterKeyManager_PIN(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+
+	m, err := NewMasterKeyManager(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+
+	masterKey := append([]byte(nil), m.masterKey...)
+	provider := &memoryProvider{secrets: make(map[string][]byte)}
+
+	t.Run("Short PIN is rejected", func(t *testing.T) {
+		assert.Error(t, m.EnablePIN(provider, "123"))
+		assert.False(t, m.PINEnabled())
+	})
+
+	t.Run("Enable requires unlocked key", func(t *testing.T) {
+		m.Lock()
+		assert.Error(t, m.EnablePIN(provider, "1234"))
+		require.NoError(t, m.UnlockMasterKey("password123"))
+	})
+
+	t.Run("Unlock with PIN", func(t *testing.T) {
+		require.NoError(t, m.EnablePIN(provider, "1234"))
+		assert.True(t, m.PINEnabled())
+
+		m.Lock()
+		require.NoError(t, m.UnlockWithPIN(provider, "1234", 3))
+		assert.False(t, m.IsLocked())
+		assert.Equal(t, masterKey, m.masterKey)
+	})
+
+	t.Run("PIN alone is not enough", func(t *testing.T) {
+		m.Lock()
+		other := &memoryProvider{secrets: make(map[string][]byte)}
+		assert.ErrorIs(t, m.UnlockWithPIN(other, "1234", 3), ErrKeyNotFound)
+		assert.True(t, m.IsLocked())
+		assert.Equal(t, 0, m.PINFailures())
+	})
+
+	t.Run("Success resets failures", func(t *testing.T) {
+		err := m.UnlockWithPIN(provider, "0000", 3)
+		var wrong *WrongPINError
+		require.True(t, errors.As(err, &wrong))
+		assert.Equal(t, 2, wrong.AttemptsLeft)
+		assert.Equal(t, 1, m.PINFailures())
+
+		require.NoError(t, m.UnlockWithPIN(provider, "1234", 3))
+		assert.Equal(t, 0, m.PINFailures())
+	})
+
+	t.Run("Too many failures disable PIN", func(t *testing.T) {
+		m.Lock()
+		for i := 0; i < 2; i++ {
+			var wrong *WrongPINError
+			assert.True(t, errors.As(m.UnlockWithPIN(provider, "0000", 3), &wrong))
+		}
+		assert.ErrorIs(t, m.UnlockWithPIN(provider, "0000", 3), ErrPINDisabled)
+		assert.False(t, m.PINEnabled())
+		assert.Empty(t, provider.secrets)
+
+		assert.ErrorIs(t, m.UnlockWithPIN(provider, "1234", 3), ErrPINNotEnabled)
+		require.NoError(t, m.UnlockMasterKey("password123"))
+	})
+
+	t.Run("Disable removes secret and file", func(t *testing.T) {
+		require.NoError(t, m.EnablePIN(provider, "5678"))
+		require.NoError(t, m.DisablePIN(provider))
+		assert.False(t, m.PINEnabled())
+		assert.Empty(t, provider.secrets)
+	})
+
+	m.Lock()
+}
@@ -0,0 +1,16 @@
+// internal/app/client/crypto/random.go
+package crypto
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// randReader - источник случайных байт для ключей, солей и nonce.
+// Все функции пакета читают случайность только через него, поэтому тесты
+// пакета подменяют его детерминированным потоком (см. export_test.go).
+var randReader io.Reader = rand.Reader
+
+// clock - время для меток в заголовках файлов ключа и резервных копий
+var clock = time.Now
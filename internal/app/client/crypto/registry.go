@@ -0,0 +1,280 @@
+// internal/app/client/crypto/registry.go
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Алгоритмы получения ключа из пароля (KDF) и шифрования (AEAD) зарегистрированы
+// по идентификаторам, которые записываются в заголовок файла мастер-ключа.
+// Новый алгоритм добавляется регистрацией реализации; MasterKeyManager выбирает
+// реализацию по заголовку и не зависит от конкретных алгоритмов.
+
+const (
+	// KDFPBKDF2 - PBKDF2-HMAC-SHA256
+	KDFPBKDF2 = "PBKDF2-SHA256"
+	// KDFArgon2id - Argon2id
+	KDFArgon2id = "Argon2id"
+
+	// AEADAESGCM - AES-256-GCM
+	AEADAESGCM = "AES-256-GCM"
+	// AEADXChaCha20 - XChaCha20-Poly1305
+	AEADXChaCha20 = "XChaCha20-Poly1305"
+
+	// DefaultKDF и DefaultAEAD используются для новых мастер-ключей
+	DefaultKDF  = KDFPBKDF2
+	DefaultAEAD = AEADAESGCM
+)
+
+// ErrUnsupportedAlgorithm возвращается для незарегистрированного алгоритма
+var ErrUnsupportedAlgorithm = errors.New("неподдерживаемый алгоритм")
+
+// KDFParams - параметры KDF, сохраняемые в заголовке файла ключа.
+// Нулевые значения заменяются параметрами алгоритма по умолчанию.
+type KDFParams struct {
+	Iterations int    `json:"iterations"`        // число проходов (PBKDF2, Argon2id)
+	Memory     uint32 `json:"memory,omitempty"`  // память в КБ (Argon2id)
+	Threads    uint8  `json:"threads,omitempty"` // параллелизм (Argon2id)
+}
+
+// KDF получает ключ шифрования из пароля
+type KDF interface {
+	// ID - идентификатор алгоритма в заголовке файла ключа
+	ID() string
+	// SaltLength - длина соли для новых ключей
+	SaltLength() int
+	// DefaultParams - параметры для новых ключей
+	DefaultParams() KDFParams
+	DeriveKey(password string, salt []byte, params KDFParams, keyLen int) ([]byte, error)
+}
+
+// AEAD шифрует данные с аутентификацией. Nonce хранится в начале шифротекста.
+type AEAD interface {
+	// ID - идентификатор алгоритма в заголовке файла ключа
+	ID() string
+	KeySize() int
+	Seal(key, plaintext []byte) ([]byte, error)
+	Open(key, ciphertext []byte) ([]byte, error)
+}
+
+var registry = struct {
+	mu    sync.RWMutex
+	kdfs  map[string]KDF
+	aeads map[string]AEAD
+}{
+	kdfs:  make(map[string]KDF),
+	aeads: make(map[string]AEAD),
+}
+
+// RegisterKDF регистрирует KDF. Повторная регистрация идентификатора заменяет реализацию.
+func RegisterKDF(k KDF) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.kdfs[k.ID()] = k
+}
+
+// RegisterAEAD регистрирует AEAD. Повторная регистрация идентификатора заменяет реализацию.
+func RegisterAEAD(a AEAD) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.aeads[a.ID()] = a
+}
+
+// LookupKDF возвращает KDF по идентификатору
+func LookupKDF(id string) (KDF, error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	k, ok := registry.kdfs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, id)
+	}
+	return k, nil
+}
+
+// LookupAEAD возвращает AEAD по идентификатору. Пустой идентификатор означает
+// AES-256-GCM: так зашифрованы ключи, созданные до появления реестра.
+func LookupAEAD(id string) (AEAD, error) {
+	if id == "" {
+		id = AEADAESGCM
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	a, ok := registry.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, id)
+	}
+	return a, nil
+}
+
+// KDFs возвращает идентификаторы зарегистрированных KDF
+func KDFs() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	ids := make([]string, 0, len(registry.kdfs))
+	for id := range registry.kdfs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// AEADs возвращает идентификаторы зарегистрированных AEAD
+func AEADs() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	ids := make([]string, 0, len(registry.aeads))
+	for id := range registry.aeads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func init() {
+	RegisterKDF(pbkdf2KDF{})
+	RegisterKDF(argon2idKDF{})
+	RegisterAEAD(aesGCM{})
+	RegisterAEAD(xchacha20{})
+}
+
+// pbkdf2KDF - PBKDF2-HMAC-SHA256
+type pbkdf2KDF struct{}
+
+func (pbkdf2KDF) ID() string      { return KDFPBKDF2 }
+func (pbkdf2KDF) SaltLength() int { return pbkdf2SaltLength }
+
+func (pbkdf2KDF) DefaultParams() KDFParams {
+	return KDFParams{Iterations: pbkdf2Iterations}
+}
+
+func (k pbkdf2KDF) DeriveKey(password string, salt []byte, params KDFParams, keyLen int) ([]byte, error) {
+	if params.Iterations <= 0 {
+		params.Iterations = pbkdf2Iterations
+	}
+	return pbkdf2.Key([]byte(password), salt, params.Iterations, keyLen, sha256.New), nil
+}
+
+// argon2idKDF - Argon2id
+type argon2idKDF struct{}
+
+func (argon2idKDF) ID() string      { return KDFArgon2id }
+func (argon2idKDF) SaltLength() int { return 16 }
+
+func (argon2idKDF) DefaultParams() KDFParams {
+	return KDFParams{Iterations: argon2Time, Memory: argon2Memory, Threads: argon2Threads}
+}
+
+func (k argon2idKDF) DeriveKey(password string, salt []byte, params KDFParams, keyLen int) ([]byte, error) {
+	defaults := k.DefaultParams()
+	if params.Iterations <= 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Threads == 0 {
+		params.Threads = defaults.Threads
+	}
+	if len(salt) < k.SaltLength() {
+		return nil, fmt.Errorf("соль Argon2id короче %d байт", k.SaltLength())
+	}
+	// Ключи старого формата хранили соль PBKDF2 большей длины
+	salt = salt[:k.SaltLength()]
+
+	return argon2.IDKey([]byte(password), salt, uint32(params.Iterations), params.Memory, params.Threads, uint32(keyLen)), nil
+}
+
+// aesGCM - AES-256-GCM
+type aesGCM struct{}
+
+func (aesGCM) ID() string   { return AEADAESGCM }
+func (aesGCM) KeySize() int { return 32 }
+
+func (aesGCM) aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+func (a aesGCM) Seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := a.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return seal(gcm, plaintext)
+}
+
+func (a aesGCM) Open(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := a.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return open(gcm, ciphertext)
+}
+
+// xchacha20 - XChaCha20-Poly1305 со случайным 24-байтовым nonce
+type xchacha20 struct{}
+
+func (xchacha20) ID() string   { return AEADXChaCha20 }
+func (xchacha20) KeySize() int { return chacha20poly1305.KeySize }
+
+func (xchacha20) Seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
+	}
+	return seal(aead, plaintext)
+}
+
+func (xchacha20) Open(key, ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания cipher: %w", err)
+	}
+	return open(aead, ciphertext)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, fmt.Errorf("ошибка генерации nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("шифротекст слишком короткий")
+	}
+
+	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{KDFArgon2id, KDFPBKDF2}, KDFs())
+	assert.Equal(t, []string{AEADAESGCM, AEADXChaCha20}, AEADs())
+
+	_, err := LookupKDF("scrypt")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	_, err = LookupAEAD("ROT13")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	legacy, err := LookupAEAD("")
+	require.NoError(t, err)
+	assert.Equal(t, AEADAESGCM, legacy.ID())
+
+	for _, id := range AEADs() {
+		aead, err := LookupAEAD(id)
+		require.NoError(t, err)
+
+		key := make([]byte, aead.KeySize())
+		sealed, err := aead.Seal(key, []byte("secret"))
+		require.NoError(t, err, id)
+		opened, err := aead.Open(key, sealed)
+		require.NoError(t, err, id)
+		assert.Equal(t, "secret", string(opened), id)
+
+		sealed[len(sealed)-1] ^= 1
+		_, err = aead.Open(key, sealed)
+		assert.Error(t, err, id)
+	}
+}
+
+func TestMasterKeyManager_Algorithms(t *testing.T) {
+	t.Run("Per-vault algorithms", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "master.key")
+		m, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.NoError(t, m.SetAlgorithms(KDFArgon2id, AEADXChaCha20))
+		require.NoError(t, m.GenerateMasterKey("password123"))
+
+		ciphertext, err := m.EncryptData([]byte("record"))
+		require.NoError(t, err)
+		m.Lock()
+
+		reopened, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		kdf, aead := reopened.Algorithms()
+		assert.Equal(t, KDFArgon2id, kdf)
+		assert.Equal(t, AEADXChaCha20, aead)
+
+		assert.ErrorIs(t, reopened.UnlockMasterKey("wrong-password"), ErrWrongPassword)
+		require.NoError(t, reopened.UnlockMasterKey("password123"))
+		plaintext, err := reopened.DecryptData(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "record", string(plaintext))
+		reopened.Lock()
+	})
+
+	t.Run("Unknown algorithm is rejected", func(t *testing.T) {
+		m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, m.SetAlgorithms("scrypt", AEADAESGCM), ErrUnsupportedAlgorithm)
+		assert.ErrorIs(t, m.SetAlgorithms(KDFPBKDF2, "ROT13"), ErrUnsupportedAlgorithm)
+	})
+
+	t.Run("Legacy header without cipher", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "master.key")
+		m, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.NoError(t, m.GenerateMasterKey("password123"))
+		m.Lock()
+
+		// Файл ключа, созданный до появления реестра, не содержит поля cipher
+		writeKeyFileV1(t, keyPath, "cipher")
+
+		legacy, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.NoError(t, legacy.UnlockMasterKey("password123"))
+		_, aead := legacy.Algorithms()
+		assert.Equal(t, AEADAESGCM, aead)
+		legacy.Lock()
+	})
+
+	t.Run("Password change upgrades KDF", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "master.key")
+		m, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.NoError(t, m.GenerateMasterKey("password123"))
+		ciphertext, err := m.EncryptData([]byte("record"))
+		require.NoError(t, err)
+
+		require.NoError(t, m.SetAlgorithms(KDFArgon2id, AEADAESGCM))
+		require.NoError(t, m.ChangeMasterPassword("password123", "new-password"))
+		m.Lock()
+
+		upgraded, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		kdf, _ := upgraded.Algorithms()
+		assert.Equal(t, KDFArgon2id, kdf)
+
+		require.NoError(t, upgraded.UnlockMasterKey("new-password"))
+		plaintext, err := upgraded.DecryptData(ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, "record", string(plaintext))
+		upgraded.Lock()
+	})
+}
@@ -0,0 +1,294 @@
+// internal/app/client/crypto/rotation.go
+package crypto
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Ротация ключа данных. Мастер-ключ, которым шифруются записи, заменяется
+// новым случайным ключом, защищенным тем же мастер-паролем. Прежние ключи
+// остаются в заголовке файла ключа, зашифрованные текущим: записи, еще не
+// перешифрованные новым ключом, по-прежнему читаются. Шифротексты после первой
+// ротации начинаются с метки версии ключа; шифротексты без метки созданы
+// первым ключом.
+
+const (
+	// keyVersionMagic - начало метки версии ключа в шифротексте
+	keyVersionMagic = "GKV"
+	// keyVersionTagSize - длина метки: магия и версия (uint16, big-endian)
+	keyVersionTagSize = len(keyVersionMagic) + 2
+	// maxKeyVersion - предел версий ключа, помещающихся в метку
+	maxKeyVersion = 1<<16 - 1
+)
+
+// ErrKeyRotated возвращается, если сохраненная копия ключа (сессия, хранилище
+// ОС, PIN) относится к ключу до ротации
+var ErrKeyRotated = errors.New("ключ данных заменен: разблокируйте мастер-паролем")
+
+// RetiredKey - прежний ключ данных, зашифрованный текущим ключом
+type RetiredKey struct {
+	Version int    `json:"version"`
+	Key     string `json:"key"` // hex
+}
+
+// KeyVersion возвращает версию текущего ключа данных; до первой ротации - 1
+func (m *MasterKeyManager) KeyVersion() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.header.keyVersion()
+}
+
+// CiphertextKeyVersion возвращает версию ключа, которым зашифрован шифротекст
+// мастер-ключа (для конверта - ключ записи); шифротекст без метки создан
+// первым ключом
+func CiphertextKeyVersion(ciphertext []byte) int {
+	if wrapped, _, err := splitEnvelope(ciphertext); err == nil {
+		ciphertext = wrapped
+	}
+	if version, _, ok := splitKeyVersion(ciphertext); ok {
+		return version
+	}
+	return 1
+}
+
+// RotateKey заменяет ключ данных новым случайным ключом и возвращает его
+// версию. Пароль подтверждает ротацию и защищает новый ключ. Прежний ключ
+// сохраняется в файле ключа для расшифровки старых записей; копии ключа в
+// хранилище ОС и PIN после ротации нужно сохранить заново.
+func (m *MasterKeyManager) RotateKey(password string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.isLoaded || m.isLocked {
+		return 0, fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return 0, err
+	}
+	passwordKey, err := m.deriveKey(password, aead.KeySize())
+	if err != nil {
+		return 0, err
+	}
+
+	current := m.header.keyVersion()
+	if current >= maxKeyVersion {
+		return 0, fmt.Errorf("достигнуто максимальное число ротаций ключа")
+	}
+
+	newKey := make([]byte, aead.KeySize())
+	if _, err := io.ReadFull(randReader, newKey); err != nil {
+		return 0, fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+
+	retired := make(map[int][]byte, len(m.retired)+1)
+	for version, key := range m.retired {
+		retired[version] = key
+	}
+	retired[current] = m.masterKey
+
+	entries := make([]RetiredKey, 0, len(retired))
+	for _, version := range sortedVersions(retired) {
+		wrapped, err := aead.Seal(newKey, retired[version])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка шифрования прежнего ключа: %w", err)
+		}
+		entries = append(entries, RetiredKey{Version: version, Key: hex.EncodeToString(wrapped)})
+	}
+
+	wrappedKey, err := aead.Seal(passwordKey, newKey)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка шифрования нового ключа: %w", err)
+	}
+
+	header := m.header
+	header.Version = keyFileVersion
+	header.KeyVersion = current + 1
+	header.RetiredKeys = entries
+	header.UpdatedAt = clock()
+	if err := writeKeyFile(m.keyPath, &keyContainer{Header: header, Data: wrappedKey}); err != nil {
+		return 0, err
+	}
+
+	// Текущий ключ становится прежним вместе со своей защищенной памятью
+	m.header = header
+	m.retired = retired
+	if m.keyMem != nil {
+		m.retiredMem = append(m.retiredMem, m.keyMem)
+		m.keyMem = nil
+	}
+	m.setMasterKey(newKey)
+
+	m.mu.Unlock()
+	_ = m.SaveSession()
+	m.mu.Lock()
+
+	return header.KeyVersion, nil
+}
+
+// AdoptKeyFile заменяет локальный файл ключа файлом с сервера, если на другом
+// устройстве выполнена ротация. Возвращает true, если файл заменен: ключ
+// блокируется и разблокируется мастер-паролем уже с новым файлом. Файл с
+// другим мастер-паролем не принимается.
+func (m *MasterKeyManager) AdoptKeyFile(data []byte) (bool, error) {
+	container, err := decodeKeyFile(data)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	if container.Header.keyVersion() <= m.header.keyVersion() {
+		m.mu.Unlock()
+		return false, nil
+	}
+	if container.Header.KeyHash != m.header.KeyHash {
+		m.mu.Unlock()
+		return false, fmt.Errorf("файл ключа на сервере защищен другим мастер-паролем")
+	}
+
+	if err := writeKeyFileData(m.keyPath, data); err != nil {
+		m.mu.Unlock()
+		return false, err
+	}
+	m.header = container.Header
+	m.clearKey()
+	m.isLocked = true
+	m.mu.Unlock()
+
+	_ = m.ClearSession()
+	return true, nil
+}
+
+// unwrapRetired расшифровывает прежние ключи из заголовка загруженным ключом.
+// Если они не расшифровываются, загружена копия ключа до ротации: ключ
+// очищается и возвращается ErrKeyRotated. Вызывается под блокировкой.
+func (m *MasterKeyManager) unwrapRetired() error {
+	m.clearRetired()
+	if len(m.header.RetiredKeys) == 0 {
+		return nil
+	}
+
+	aead, err := LookupAEAD(m.header.Cipher)
+	if err != nil {
+		return err
+	}
+
+	retired := make(map[int][]byte, len(m.header.RetiredKeys))
+	for _, entry := range m.header.RetiredKeys {
+		wrapped, err := hex.DecodeString(entry.Key)
+		if err != nil {
+			return fmt.Errorf("ошибка декодирования прежнего ключа: %w", err)
+		}
+		key, err := aead.Open(m.masterKey, wrapped)
+		if err != nil {
+			m.clearKey()
+			m.isLocked = true
+			return ErrKeyRotated
+		}
+		locked := SecureBytesFrom(key)
+		m.retiredMem = append(m.retiredMem, locked)
+		retired[entry.Version] = locked.Bytes()
+	}
+	m.retired = retired
+	return nil
+}
+
+// clearRetired затирает прежние ключи в памяти
+func (m *MasterKeyManager) clearRetired() {
+	for _, key := range m.retired {
+		wipe(key)
+	}
+	m.retired = nil
+	for _, locked := range m.retiredMem {
+		_ = locked.Close()
+	}
+	m.retiredMem = nil
+}
+
+// seal шифрует данные текущим ключом; после ротации добавляет метку версии
+func (m *MasterKeyManager) seal(aead AEAD, plaintext []byte) ([]byte, error) {
+	ciphertext, err := aead.Seal(m.masterKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	version := m.header.keyVersion()
+	if version <= 1 {
+		return ciphertext, nil
+	}
+
+	tagged := make([]byte, keyVersionTagSize+len(ciphertext))
+	copy(tagged, keyVersionMagic)
+	binary.BigEndian.PutUint16(tagged[len(keyVersionMagic):], uint16(version))
+	copy(tagged[keyVersionTagSize:], ciphertext)
+	return tagged, nil
+}
+
+// open расшифровывает данные ключом из метки версии. Шифротекст без метки
+// (или метка, случайно совпавшая с началом nonce) пробуется текущим и всеми
+// прежними ключами: шифр аутентифицирован, чужой ключ не подходит.
+func (m *MasterKeyManager) open(aead AEAD, ciphertext []byte) ([]byte, error) {
+	if version, body, ok := splitKeyVersion(ciphertext); ok {
+		if key := m.keyForVersion(version); key != nil {
+			if plaintext, err := aead.Open(key, body); err == nil {
+				return plaintext, nil
+			}
+		}
+	}
+
+	plaintext, err := aead.Open(m.masterKey, ciphertext)
+	if err == nil {
+		return plaintext, nil
+	}
+
+	versions := sortedVersions(m.retired)
+	for i := len(versions) - 1; i >= 0; i-- {
+		if plaintext, retiredErr := aead.Open(m.retired[versions[i]], ciphertext); retiredErr == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, err
+}
+
+// keyForVersion возвращает ключ данных указанной версии или nil
+func (m *MasterKeyManager) keyForVersion(version int) []byte {
+	if version == m.header.keyVersion() {
+		return m.masterKey
+	}
+	return m.retired[version]
+}
+
+// keyVersion возвращает версию ключа данных из заголовка
+func (h MasterKeyHeader) keyVersion() int {
+	if h.KeyVersion < 1 {
+		return 1
+	}
+	return h.KeyVersion
+}
+
+// splitKeyVersion отделяет метку версии ключа от шифротекста
+func splitKeyVersion(ciphertext []byte) (int, []byte, bool) {
+	if len(ciphertext) <= keyVersionTagSize || string(ciphertext[:len(keyVersionMagic)]) != keyVersionMagic {
+		return 0, nil, false
+	}
+	version := int(binary.BigEndian.Uint16(ciphertext[len(keyVersionMagic):keyVersionTagSize]))
+	if version < 2 {
+		return 0, nil, false
+	}
+	return version, ciphertext[keyVersionTagSize:], true
+}
+
+func sortedVersions(keys map[int][]byte) []int {
+	versions := make([]int, 0, len(keys))
+	for version := range keys {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
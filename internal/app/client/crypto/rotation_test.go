@@ -0,0 +1,168 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterKeyManager_RotateKey(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "master.key")
+
+	m, err := NewMasterKeyManager(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+	assert.Equal(t, 1, m.KeyVersion())
+
+	first, err := m.EncryptData([]byte("first"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, CiphertextKeyVersion(first))
+	oldKey := append([]byte(nil), m.masterKey...)
+
+	_, err = m.RotateKey("wrong")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	version, err := m.RotateKey("password123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.NotEqual(t, oldKey, m.masterKey)
+
+	second, err := m.EncryptData([]byte("second"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, CiphertextKeyVersion(second))
+
+	version, err = m.RotateKey("password123")
+	require.NoError(t, err)
+	assert.Equal(t, 3, version)
+
+	third, err := m.EncryptData([]byte("third"))
+	require.NoError(t, err)
+
+	t.Run("old ciphertexts stay readable after unlock", func(t *testing.T) {
+		reopened, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		reopened.Lock()
+		require.NoError(t, reopened.UnlockMasterKey("password123"))
+		assert.Equal(t, 3, reopened.KeyVersion())
+
+		for ciphertext, want := range map[string]string{string(first): "first", string(second): "second", string(third): "third"} {
+			plaintext, err := reopened.DecryptData([]byte(ciphertext))
+			require.NoError(t, err)
+			assert.Equal(t, want, string(plaintext))
+		}
+	})
+
+	t.Run("session holds the new key", func(t *testing.T) {
+		restored, err := NewMasterKeyManager(keyPath)
+		require.NoError(t, err)
+		require.False(t, restored.IsLocked())
+		plaintext, err := restored.DecryptData(first)
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(plaintext))
+	})
+
+	t.Run("stale key copy is rejected", func(t *testing.T) {
+		m.mu.Lock()
+		m.masterKey = append([]byte(nil), oldKey...)
+		err := m.unwrapRetired()
+		m.mu.Unlock()
+		assert.ErrorIs(t, err, ErrKeyRotated)
+		assert.True(t, m.IsLocked())
+	})
+}
+
+func TestMasterKeyManager_AdoptKeyFile(t *testing.T) {
+	rotated, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, rotated.GenerateMasterKey("password123"))
+	original, err := rotated.KeyFile()
+	require.NoError(t, err)
+
+	other, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, other.RestoreKeyFile(original))
+	require.NoError(t, other.UnlockMasterKey("password123"))
+
+	adopted, err := other.AdoptKeyFile(original)
+	require.NoError(t, err)
+	assert.False(t, adopted, "та же версия ключа не заменяется")
+
+	_, err = rotated.RotateKey("password123")
+	require.NoError(t, err)
+	ciphertext, err := rotated.EncryptData([]byte("secret"))
+	require.NoError(t, err)
+	data, err := rotated.KeyFile()
+	require.NoError(t, err)
+
+	// Сбой записи оставляет прежний файл ключа нетронутым
+	otherPath := other.keyPath
+	require.NoError(t, os.MkdirAll(filepath.Join(otherPath+".tmp", "busy"), 0700))
+	_, err = other.AdoptKeyFile(data)
+	assert.Error(t, err)
+	current, err := os.ReadFile(otherPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, current)
+	assert.Equal(t, 1, other.KeyVersion())
+	assert.False(t, other.IsLocked())
+	require.NoError(t, os.RemoveAll(otherPath+".tmp"))
+
+	adopted, err = other.AdoptKeyFile(data)
+	require.NoError(t, err)
+	assert.True(t, adopted)
+	assert.True(t, other.IsLocked())
+	assert.Equal(t, 2, other.KeyVersion())
+
+	require.NoError(t, other.UnlockMasterKey("password123"))
+	plaintext, err := other.DecryptData(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	// Файл ключа с другим паролем не принимается
+	foreign, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, foreign.GenerateMasterKey("other-password"))
+	_, err = foreign.RotateKey("other-password")
+	require.NoError(t, err)
+	_, err = foreign.RotateKey("other-password")
+	require.NoError(t, err)
+	foreignData, err := foreign.KeyFile()
+	require.NoError(t, err)
+	_, err = other.AdoptKeyFile(foreignData)
+	assert.Error(t, err)
+}
+
+func TestCiphertextKeyVersion(t *testing.T) {
+	assert.Equal(t, 1, CiphertextKeyVersion(nil))
+	assert.Equal(t, 1, CiphertextKeyVersion([]byte("plain ciphertext")))
+	assert.Equal(t, 7, CiphertextKeyVersion([]byte("GKV\x00\x07body")))
+	// Метка версии 1 не используется: такой шифротекст создан первым ключом
+	assert.Equal(t, 1, CiphertextKeyVersion([]byte("GKV\x00\x01body")))
+}
+
+func TestMasterKeyManager_DeriveSubkey(t *testing.T) {
+	m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+
+	dbKey, err := m.DeriveSubkey("local db")
+	require.NoError(t, err)
+	assert.Len(t, dbKey, SubkeyLength)
+
+	other, err := m.DeriveSubkey("other")
+	require.NoError(t, err)
+	assert.NotEqual(t, dbKey, other)
+
+	_, err = m.RotateKey("password123")
+	require.NoError(t, err)
+	rotated, err := m.DeriveSubkey("local db")
+	require.NoError(t, err)
+	assert.Equal(t, dbKey, rotated, "ротация не меняет производные ключи")
+
+	m.Lock()
+	_, err = m.DeriveSubkey("local db")
+	assert.Error(t, err)
+}
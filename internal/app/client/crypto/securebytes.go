@@ -0,0 +1,121 @@
+// internal/app/client/crypto/securebytes.go
+package crypto
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// SecureBytes хранит секрет (ключ, расшифрованные данные записи) в отдельных
+// страницах памяти, закрепленных от выгрузки в swap (mlock, VirtualLock), где
+// это позволяет система. Close затирает секрет нулями; после Close Bytes
+// возвращает nil. Строковое представление не раскрывает содержимое, поэтому
+// секрет не попадет в журнал через %v.
+//
+// Внутри пакета ключи хранятся только в SecureBytes: проверка в
+// securebytes_lint_test.go не дает присвоить ключ менеджера в обход setMasterKey.
+type SecureBytes struct {
+	mu     sync.Mutex
+	mem    []byte // выделенные страницы
+	data   []byte // секрет: начало mem
+	locked bool
+}
+
+// NewSecureBytes выделяет защищенный буфер из size нулевых байт
+func NewSecureBytes(size int) *SecureBytes {
+	page := os.Getpagesize()
+	n := (size + page - 1) / page * page
+	if n == 0 {
+		n = page
+	}
+
+	// Буфер занимает целые страницы: снятие закрепления в Close не должно
+	// затрагивать соседние данные
+	raw := make([]byte, n+page)
+	off := 0
+	if rem := int(uintptr(unsafe.Pointer(&raw[0])) % uintptr(page)); rem != 0 {
+		off = page - rem
+	}
+	mem := raw[off : off+n : off+n]
+
+	return &SecureBytes{
+		mem:    mem,
+		data:   mem[:size:size],
+		locked: lockMemory(mem) == nil,
+	}
+}
+
+// SecureBytesFrom переносит src в защищенный буфер и затирает src
+func SecureBytesFrom(src []byte) *SecureBytes {
+	s := NewSecureBytes(len(src))
+	copy(s.data, src)
+	wipe(src)
+	return s
+}
+
+// SecureBytesCopy копирует src в защищенный буфер, не изменяя src
+func SecureBytesCopy(src []byte) *SecureBytes {
+	s := NewSecureBytes(len(src))
+	copy(s.data, src)
+	return s
+}
+
+// Bytes возвращает секрет. Срез действителен до Close; копировать его в
+// обычную память без необходимости не следует.
+func (s *SecureBytes) Bytes() []byte {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data
+}
+
+// Len возвращает длину секрета
+func (s *SecureBytes) Len() int {
+	return len(s.Bytes())
+}
+
+// Locked сообщает, закреплена ли память секрета от выгрузки
+func (s *SecureBytes) Locked() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+// Close затирает секрет и снимает закрепление памяти. Повторный вызов
+// ничего не делает.
+func (s *SecureBytes) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.mem == nil {
+		return nil
+	}
+	wipe(s.mem)
+
+	var err error
+	if s.locked {
+		err = unlockMemory(s.mem)
+	}
+	s.mem, s.data, s.locked = nil, nil, false
+	return err
+}
+
+// String не раскрывает содержимое секрета
+func (s *SecureBytes) String() string {
+	return "[скрыто]"
+}
+
+// Format не раскрывает содержимое секрета ни при каком глаголе форматирования
+func (s *SecureBytes) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(s.String()))
+}
@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSecureBytesUsage проверяет код пакета: ключ менеджера попадает в поле
+// masterKey только из защищенной памяти (setMasterKey) и не задается
+// литералом структуры в обход нее.
+func TestSecureBytesUsage(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					sel, ok := lhs.(*ast.SelectorExpr)
+					if !ok || sel.Sel.Name != "masterKey" || i >= len(n.Rhs) {
+						continue
+					}
+					if !secureKeySource(n.Rhs[i]) {
+						t.Errorf("%s: ключ присваивается masterKey в обход SecureBytes, используйте setMasterKey", fset.Position(n.Pos()))
+					}
+				}
+			case *ast.CompositeLit:
+				if ident, ok := n.Type.(*ast.Ident); !ok || ident.Name != "MasterKeyManager" {
+					return true
+				}
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "masterKey" {
+							t.Errorf("%s: masterKey задается литералом в обход SecureBytes", fset.Position(kv.Pos()))
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// secureKeySource сообщает, что значение - nil или срез защищенной памяти
+func secureKeySource(expr ast.Expr) bool {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name == "nil"
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Bytes"
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecureBytes(t *testing.T) {
+	src := []byte("super-secret")
+	s := SecureBytesFrom(src)
+
+	assert.Equal(t, []byte("super-secret"), s.Bytes())
+	assert.Equal(t, 12, s.Len())
+	assert.Equal(t, make([]byte, 12), src, "исходный срез затирается")
+
+	page := uintptr(os.Getpagesize())
+	assert.Zero(t, uintptr(unsafe.Pointer(&s.mem[0]))%page, "буфер начинается с границы страницы")
+	assert.Zero(t, len(s.mem)%int(page))
+
+	assert.Equal(t, "[скрыто]", s.String())
+	assert.NotContains(t, fmt.Sprintf("%v %s %x %+v %#v", s, s, s, s, s), "secret")
+
+	mem := s.mem
+	require.NoError(t, s.Close())
+	assert.Nil(t, s.Bytes())
+	assert.Equal(t, make([]byte, len(mem)), mem, "Close затирает память")
+	assert.NoError(t, s.Close(), "повторный Close ничего не делает")
+
+	copied := []byte("copy")
+	c := SecureBytesCopy(copied)
+	assert.Equal(t, []byte("copy"), copied)
+	assert.Equal(t, copied, c.Bytes())
+	require.NoError(t, c.Close())
+
+	var nilSecret *SecureBytes
+	assert.Nil(t, nilSecret.Bytes())
+	assert.NoError(t, nilSecret.Close())
+}
+
+func TestMasterKeyManager_KeyInSecureMemory(t *testing.T) {
+	m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, m.GenerateMasterKey("password123"))
+
+	require.NotNil(t, m.keyMem)
+	assert.Equal(t, m.keyMem.Bytes(), m.masterKey)
+
+	_, err = m.RotateKey("password123")
+	require.NoError(t, err)
+	require.Len(t, m.retiredMem, 1, "прежний ключ остается в защищенной памяти")
+	assert.Equal(t, m.retiredMem[0].Bytes(), m.retired[1])
+
+	keyMem, retiredMem := m.keyMem, m.retiredMem[0]
+	m.Lock()
+	assert.Nil(t, keyMem.Bytes())
+	assert.Nil(t, retiredMem.Bytes())
+	assert.Nil(t, m.keyMem)
+	assert.Empty(t, m.retiredMem)
+}
@@ -2,9 +2,9 @@
 package crypto
 
 import (
-	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,15 +13,24 @@ import (
 )
 
 const (
-	sessionTimeout     = 15 * time.Minute // Таймаут сессии
+	sessionMaxLifetime = 12 * time.Hour // Предельный срок сессии независимо от активности
 	sessionPermissions = 0600
+
+	// DefaultIdleTimeout - автоблокировка после простоя по умолчанию
+	DefaultIdleTimeout = 15 * time.Minute
+	// activityPersistInterval - как часто время активности записывается в файл сессии
+	activityPersistInterval = 30 * time.Second
 )
 
+// ErrIdleLocked возвращается, если ключ заблокирован автоматически после простоя
+var ErrIdleLocked = errors.New("мастер-ключ заблокирован после простоя. Выполните: gophkeeper unlock")
+
 // Session хранит информацию о разблокированной сессии
 type Session struct {
-	SessionKey []byte    `json:"session_key"` // Зашифрованный мастер-ключ
-	ExpiresAt  time.Time `json:"expires_at"`
-	CreatedAt  time.Time `json:"created_at"`
+	SessionKey   []byte    `json:"session_key"` // Зашифрованный мастер-ключ
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActivity time.Time `json:"last_activity"` // Последнее использование ключа любым процессом клиента
 }
 
 // SaveSession сохраняет сессию с разблокированным ключом
@@ -35,7 +44,7 @@ func (m *MasterKeyManager) SaveSession() error {
 
 	// Генерируем случайный ключ сессии для шифрования
 	sessionKey := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, sessionKey); err != nil {
+	if _, err := io.ReadFull(randReader, sessionKey); err != nil {
 		return fmt.Errorf("ошибка генерации ключа сессии: %w", err)
 	}
 
@@ -45,10 +54,17 @@ func (m *MasterKeyManager) SaveSession() error {
 		return fmt.Errorf("ошибка шифрования мастер-ключа: %w", err)
 	}
 
+	now := time.Now()
+	lastActivity := m.lastActivity
+	if lastActivity.IsZero() {
+		lastActivity = now
+	}
+
 	session := Session{
-		SessionKey: encryptedMasterKey,
-		ExpiresAt:  time.Now().Add(sessionTimeout),
-		CreatedAt:  time.Now(),
+		SessionKey:   encryptedMasterKey,
+		ExpiresAt:    now.Add(sessionMaxLifetime),
+		CreatedAt:    now,
+		LastActivity: lastActivity,
 	}
 
 	// Сериализуем сессию
@@ -151,14 +167,91 @@ func (m *MasterKeyManager) LoadSession() error {
 		return fmt.Errorf("ошибка расшифровки мастер-ключа: %w", err)
 	}
 
-	// Восстанавливаем мастер-ключ в памяти
-	m.masterKey = masterKey
+	// Восстанавливаем мастер-ключ в памяти. Простой проверяется при
+	// использовании ключа: таймаут задается позже через SetIdleTimeout.
+	m.setMasterKey(masterKey)
+	if err := m.unwrapRetired(); err != nil {
+		os.Remove(sessionPath)
+		return err
+	}
 	m.isLoaded = true
 	m.isLocked = false
+	m.lastActivity = session.LastActivity
+	if m.lastActivity.IsZero() {
+		m.lastActivity = session.CreatedAt
+	}
+	m.lastPersist = time.Now()
+
+	return nil
+}
+
+// SetIdleTimeout задает таймаут автоблокировки (0 - выключить). Если ключ
+// уже простаивал дольше нового таймаута, он сразу блокируется.
+func (m *MasterKeyManager) SetIdleTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	m.idleTimeout = timeout
+	expired := !m.isLocked && m.idleExpired(time.Now())
+	m.mu.Unlock()
+
+	if expired {
+		m.Lock()
+	}
+}
+
+// IdleTimeout возвращает таймаут автоблокировки
+func (m *MasterKeyManager) IdleTimeout() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.idleTimeout
+}
 
+// LockIfIdle блокирует ключ, если он простаивал дольше таймаута.
+// Возвращает true, если ключ был заблокирован этим вызовом.
+func (m *MasterKeyManager) LockIfIdle() bool {
+	m.mu.RLock()
+	expired := !m.isLocked && m.idleExpired(time.Now())
+	m.mu.RUnlock()
+
+	if expired {
+		m.Lock()
+	}
+	return expired
+}
+
+// touch отмечает использование ключа. Время активности периодически
+// записывается в файл сессии, чтобы простой учитывался между запусками CLI.
+func (m *MasterKeyManager) touch() error {
+	now := time.Now()
+
+	m.mu.Lock()
+	if m.isLocked || !m.isLoaded {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.idleExpired(now) {
+		m.mu.Unlock()
+		m.Lock()
+		return ErrIdleLocked
+	}
+
+	m.lastActivity = now
+	persist := now.Sub(m.lastPersist) >= activityPersistInterval
+	if persist {
+		m.lastPersist = now
+	}
+	m.mu.Unlock()
+
+	if persist {
+		_ = m.SaveSession()
+	}
 	return nil
 }
 
+// idleExpired вызывается под m.mu
+func (m *MasterKeyManager) idleExpired(now time.Time) bool {
+	return m.idleTimeout > 0 && !m.lastActivity.IsZero() && now.Sub(m.lastActivity) > m.idleTimeout
+}
+
 // ClearSession удаляет файл сессии
 func (m *MasterKeyManager) ClearSession() error {
 	sessionPath := m.getSessionPath()
@@ -3,7 +3,9 @@ package crypto
 import (
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -119,3 +121,106 @@ func TestMasterKeyManager_SaveSession_Locked(t *testing.T) {
 	err := m.SaveSession()
 	assert.EqualError(t, err, "мастер-ключ не разблокирован")
 }
+
+func TestMasterKeyManager_IdleTimeout(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "master.key")
+	masterKey := []byte("0123456789abcdef0123456789abcdef")
+
+	newManager := func(lastActivity time.Time) *MasterKeyManager {
+		return &MasterKeyManager{
+			keyPath:      keyPath,
+			masterKey:    append([]byte(nil), masterKey...),
+			isLoaded:     true,
+			lastActivity: lastActivity,
+		}
+	}
+
+	t.Run("active key stays unlocked", func(t *testing.T) {
+		m := newManager(time.Now())
+		m.SetIdleTimeout(time.Minute)
+
+		_, err := m.EncryptData([]byte("data"))
+		require.NoError(t, err)
+		assert.False(t, m.IsLocked())
+	})
+
+	t.Run("idle key is locked on use", func(t *testing.T) {
+		m := newManager(time.Now().Add(-2 * time.Minute))
+		m.idleTimeout = time.Minute
+
+		assert.True(t, m.IsLocked())
+		_, err := m.DecryptData([]byte("data"))
+		assert.ErrorIs(t, err, ErrIdleLocked)
+		assert.Nil(t, m.masterKey)
+	})
+
+	t.Run("SetIdleTimeout locks expired key", func(t *testing.T) {
+		m := newManager(time.Now().Add(-time.Hour))
+		m.SetIdleTimeout(30 * time.Minute)
+		assert.True(t, m.IsLocked())
+		assert.Nil(t, m.masterKey)
+	})
+
+	t.Run("zero timeout disables auto-lock", func(t *testing.T) {
+		m := newManager(time.Now().Add(-24 * time.Hour))
+		m.SetIdleTimeout(0)
+		assert.False(t, m.LockIfIdle())
+		assert.False(t, m.IsLocked())
+	})
+
+	t.Run("activity is shared through the session file", func(t *testing.T) {
+		lastActivity := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+		m := newManager(lastActivity)
+		require.NoError(t, m.SaveSession())
+
+		other := &MasterKeyManager{keyPath: keyPath}
+		require.NoError(t, other.LoadSession())
+		assert.True(t, other.lastActivity.Equal(lastActivity))
+
+		other.SetIdleTimeout(5 * time.Minute)
+		assert.True(t, other.IsLocked())
+		_, err := os.Stat(other.getSessionPath())
+		assert.True(t, os.IsNotExist(err), "сессия должна быть удалена")
+	})
+}
+
+// TestMasterKeyManager_ConcurrentLock гоняет блокировку, разблокировку и
+// шифрование из разных горутин. Смысл теста - в запуске с -race.
+func TestMasterKeyManager_ConcurrentLock(t *testing.T) {
+	m, err := NewMasterKeyManager(filepath.Join(t.TempDir(), "master.key"))
+	require.NoError(t, err)
+	require.NoError(t, m.SetAlgorithms(KDFPBKDF2, AEADAESGCM))
+	require.NoError(t, m.GenerateMasterKey("password123"))
+	ciphertext, err := m.EncryptData([]byte("record"))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = m.UnlockMasterKey("password123")
+		}()
+		go func() {
+			defer wg.Done()
+			// Ключ может быть заблокирован соседней горутиной - это не ошибка теста
+			if plaintext, err := m.DecryptData(ciphertext); err == nil {
+				assert.Equal(t, "record", string(plaintext))
+			}
+			_ = m.IsLocked()
+			_ = m.LockIfIdle()
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, m.UnlockMasterKey("password123"))
+	plaintext, err := m.DecryptData(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "record", string(plaintext))
+	m.Lock()
+	assert.True(t, m.IsLocked())
+}
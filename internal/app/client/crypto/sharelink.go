@@ -0,0 +1,39 @@
+// internal/app/client/crypto/sharelink.go
+package crypto
+
+import (
+	"fmt"
+	"io"
+)
+
+// ShareKeyLength длина ключа одноразовой ссылки на секрет (AES-256)
+const ShareKeyLength = 32
+
+// SealShared шифрует секрет для одноразовой ссылки новым случайным ключом.
+// Формат - nonce и шифротекст AES-256-GCM: его расшифровывает и WebCrypto в
+// браузере получателя. Ключ передается только в фрагменте ссылки.
+func SealShared(plaintext []byte) (key, ciphertext []byte, err error) {
+	key = make([]byte, ShareKeyLength)
+	if _, err := io.ReadFull(randReader, key); err != nil {
+		return nil, nil, fmt.Errorf("ошибка генерации ключа ссылки: %w", err)
+	}
+
+	ciphertext, err = encryptWithKey(key, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, ciphertext, nil
+}
+
+// OpenShared расшифровывает секрет одноразовой ссылки ключом из ее фрагмента
+func OpenShared(key, ciphertext []byte) ([]byte, error) {
+	if len(key) != ShareKeyLength {
+		return nil, fmt.Errorf("неверная длина ключа ссылки")
+	}
+
+	plaintext, err := decryptWithKey(key, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("неверный ключ ссылки или поврежденные данные")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealShared(t *testing.T) {
+	secret := []byte(`{"fields":{"password":"s3cret"}}`)
+
+	key, ciphertext, err := SealShared(secret)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+	if len(key) != ShareKeyLength {
+		t.Fatalf("Длина ключа %d, ожидалась %d", len(key), ShareKeyLength)
+	}
+	if bytes.Contains(ciphertext, []byte("s3cret")) {
+		t.Fatal("Шифротекст содержит открытый текст")
+	}
+
+	plaintext, err := OpenShared(key, ciphertext)
+	if err != nil {
+		t.Fatalf("Ошибка расшифровки: %v", err)
+	}
+	if !bytes.Equal(plaintext, secret) {
+		t.Fatalf("Получено %q, ожидалось %q", plaintext, secret)
+	}
+
+	other, _, err := SealShared(secret)
+	if err != nil {
+		t.Fatalf("Ошибка шифрования: %v", err)
+	}
+	if _, err := OpenShared(other, ciphertext); err == nil {
+		t.Fatal("Секрет расшифрован чужим ключом")
+	}
+	if _, err := OpenShared(key[:16], ciphertext); err == nil {
+		t.Fatal("Принят ключ неверной длины")
+	}
+}
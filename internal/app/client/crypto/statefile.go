@@ -0,0 +1,189 @@
+// internal/app/client/crypto/statefile.go
+package crypto
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Служебные файлы клиента (state.json, метаданные и статистика синхронизации)
+// раскрывают логин, число записей и хэш мастер-ключа. Они нужны до разблокировки,
+// поэтому шифруются не мастер-ключом, а ключом, привязанным к машине: случайный
+// секрет (в хранилище ОС или в файле state.key) смешивается с идентификатором
+// машины. Скопированный на другую машину каталог конфигурации не расшифровывается.
+
+const (
+	stateKeyFile   = "state.key"
+	stateKeyLength = 32
+	stateKeyInfo   = "gophkeeper state v1"
+)
+
+// stateMagic - заголовок зашифрованного файла. Файлы без заголовка считаются
+// открытыми файлами прежних версий и читаются как есть.
+var stateMagic = []byte("GKSTATE1")
+
+// ErrStateKeyUnavailable возвращается, если ключ служебных файлов недоступен
+var ErrStateKeyUnavailable = errors.New("ключ служебных файлов недоступен")
+
+// StateCipher шифрует служебные файлы клиента ключом, привязанным к машине
+type StateCipher struct {
+	configDir string
+	provider  KeyProvider
+	machineID func() string
+
+	once sync.Once
+	key  []byte
+	err  error
+}
+
+// NewStateCipher создает шифратор служебных файлов каталога конфигурации.
+// provider может быть nil - тогда секрет хранится только в файле.
+func NewStateCipher(configDir string, provider KeyProvider) *StateCipher {
+	return &StateCipher{
+		configDir: configDir,
+		provider:  provider,
+		machineID: machineID,
+	}
+}
+
+// ReadFile читает и расшифровывает служебный файл. Открытые файлы прежних
+// версий возвращаются без изменений и шифруются при следующей записи.
+func (c *StateCipher) ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Open(data)
+}
+
+// WriteFile шифрует и атомарно записывает служебный файл с правами 0600
+func (c *StateCipher) WriteFile(path string, plaintext []byte) error {
+	data, err := c.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, masterKeyPermissions); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Seal шифрует данные ключом служебных файлов
+func (c *StateCipher) Seal(plaintext []byte) ([]byte, error) {
+	key, err := c.stateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := encryptWithKey(key, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования: %w", err)
+	}
+	return append(append([]byte(nil), stateMagic...), sealed...), nil
+}
+
+// Open расшифровывает данные, записанные Seal
+func (c *StateCipher) Open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, stateMagic) {
+		return data, nil
+	}
+
+	key, err := c.stateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decryptWithKey(key, data[len(stateMagic):])
+	if err != nil {
+		return nil, fmt.Errorf("файл зашифрован другим ключом или поврежден: %w", err)
+	}
+	return plaintext, nil
+}
+
+// stateKey выводит ключ из секрета и идентификатора машины
+func (c *StateCipher) stateKey() ([]byte, error) {
+	c.once.Do(func() {
+		secret, err := c.loadSecret()
+		if err != nil {
+			c.err = fmt.Errorf("%w: %v", ErrStateKeyUnavailable, err)
+			return
+		}
+		c.key, c.err = hkdf.Key(sha256.New, secret, []byte(c.machineID()), stateKeyInfo, stateKeyLength)
+	})
+	return c.key, c.err
+}
+
+// loadSecret берет секрет из хранилища ОС или файла state.key, а при первом
+// запуске создает его. Хранилище ОС используется, если оно доступно.
+func (c *StateCipher) loadSecret() ([]byte, error) {
+	path := filepath.Join(c.configDir, stateKeyFile)
+	if data, err := os.ReadFile(path); err == nil {
+		secret, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(secret) != stateKeyLength {
+			return nil, fmt.Errorf("файл %s поврежден", stateKeyFile)
+		}
+		return secret, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	useProvider := c.provider != nil && c.provider.Available()
+	if useProvider {
+		secret, err := c.provider.Load(c.providerAccount())
+		if err == nil && len(secret) == stateKeyLength {
+			return secret, nil
+		}
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return nil, err
+		}
+	}
+
+	secret := make([]byte, stateKeyLength)
+	if _, err := io.ReadFull(randReader, secret); err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа: %w", err)
+	}
+
+	if useProvider {
+		if err := c.provider.Store(c.providerAccount(), secret); err == nil {
+			return secret, nil
+		}
+	}
+
+	if err := os.MkdirAll(c.configDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(secret)), masterKeyPermissions); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (c *StateCipher) providerAccount() string {
+	sum := sha256.Sum256([]byte(c.configDir))
+	return "state-key-" + hex.EncodeToString(sum[:8])
+}
+
+// machineID возвращает идентификатор машины. На Linux это machine-id systemd,
+// на остальных системах - имя хоста.
+func machineID() string {
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return id
+			}
+		}
+	}
+	host, _ := os.Hostname()
+	return host
+}
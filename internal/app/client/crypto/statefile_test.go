@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStateCipher(dir string, provider KeyProvider, machine string) *StateCipher {
+	c := NewStateCipher(dir, provider)
+	c.machineID = func() string { return machine }
+	return c
+}
+
+func TestStateCipher(t *testing.T) {
+	plaintext := []byte(`{"user_login":"alice","master_key_hash":"abc"}`)
+
+	t.Run("Round trip with key file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "state.json")
+		c := newTestStateCipher(dir, nil, "machine-a")
+
+		require.NoError(t, c.WriteFile(path, plaintext))
+
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(raw, stateMagic))
+		assert.NotContains(t, string(raw), "alice")
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		assert.FileExists(t, filepath.Join(dir, stateKeyFile))
+
+		// Новый экземпляр читает тот же секрет
+		got, err := newTestStateCipher(dir, nil, "machine-a").ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	})
+
+	t.Run("Secret in key provider", func(t *testing.T) {
+		dir := t.TempDir()
+		provider := &memoryProvider{secrets: make(map[string][]byte)}
+		c := newTestStateCipher(dir, provider, "machine-a")
+
+		sealed, err := c.Seal(plaintext)
+		require.NoError(t, err)
+		assert.Len(t, provider.secrets, 1)
+		assert.NoFileExists(t, filepath.Join(dir, stateKeyFile))
+
+		got, err := newTestStateCipher(dir, provider, "machine-a").Open(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	})
+
+	t.Run("Other machine cannot decrypt", func(t *testing.T) {
+		dir := t.TempDir()
+		sealed, err := newTestStateCipher(dir, nil, "machine-a").Seal(plaintext)
+		require.NoError(t, err)
+
+		_, err = newTestStateCipher(dir, nil, "machine-b").Open(sealed)
+		assert.Error(t, err)
+	})
+
+	t.Run("Tampered data", func(t *testing.T) {
+		c := newTestStateCipher(t.TempDir(), nil, "machine-a")
+		sealed, err := c.Seal(plaintext)
+		require.NoError(t, err)
+
+		sealed[len(sealed)-1] ^= 0xFF
+		_, err = c.Open(sealed)
+		assert.Error(t, err)
+	})
+
+	t.Run("Legacy plaintext is returned as is", func(t *testing.T) {
+		c := newTestStateCipher(t.TempDir(), nil, "machine-a")
+
+		got, err := c.Open(plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	})
+
+	t.Run("Corrupted key file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, stateKeyFile), []byte("garbage"), 0600))
+
+		_, err := newTestStateCipher(dir, nil, "machine-a").Seal(plaintext)
+		assert.ErrorIs(t, err, ErrStateKeyUnavailable)
+	})
+}
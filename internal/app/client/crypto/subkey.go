@@ -0,0 +1,34 @@
+// internal/app/client/crypto/subkey.go
+package crypto
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"fmt"
+)
+
+// SubkeyLength - длина производных ключей, байт
+const SubkeyLength = 32
+
+// DeriveSubkey выводит из ключа данных отдельный ключ для назначения info
+// (HKDF-SHA256). Ключ выводится из первого ключа данных: он остается в файле
+// ключа после ротаций, поэтому ротация не меняет производные ключи и не
+// требует перешифровать то, что ими защищено.
+func (m *MasterKeyManager) DeriveSubkey(info string) ([]byte, error) {
+	if err := m.touch(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.isLoaded || m.isLocked {
+		return nil, fmt.Errorf("мастер-ключ не загружен или заблокирован")
+	}
+
+	base := m.keyForVersion(1)
+	if base == nil {
+		return nil, fmt.Errorf("первый ключ данных недоступен")
+	}
+	return hkdf.Key(sha256.New, base, nil, info, SubkeyLength)
+}
@@ -0,0 +1,148 @@
+// internal/app/client/crypto/throttle.go
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Счетчик неудачных попыток разблокировки хранится в файле, зашифрованном
+// ключом состояния, и поэтому переживает перезапуск клиента. После нескольких
+// ошибок каждая следующая попытка возможна только после паузы, которая
+// удваивается с каждой ошибкой. Пауза проверяется до вычисления ключа из пароля.
+
+const (
+	// unlockFreeAttempts - число ошибок без задержки
+	unlockFreeAttempts = 3
+	unlockBaseDelay    = time.Second
+	unlockMaxDelay     = 15 * time.Minute
+)
+
+// ThrottledError возвращается, если попытка разблокировки сделана раньше окончания паузы
+type ThrottledError struct {
+	Failures   int
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("слишком много неудачных попыток разблокировки (%d), повторите через %s",
+		e.Failures, e.RetryAfter.Round(time.Second))
+}
+
+// UnlockAttempts - состояние счетчика неудачных попыток
+type UnlockAttempts struct {
+	Failures    int       `json:"failures"`
+	LastFailure time.Time `json:"last_failure"`
+}
+
+// UnlockDelay возвращает паузу после failures неудачных попыток подряд
+func UnlockDelay(failures int) time.Duration {
+	if failures < unlockFreeAttempts {
+		return 0
+	}
+
+	delay := unlockBaseDelay
+	for i := unlockFreeAttempts; i < failures; i++ {
+		delay *= 2
+		if delay >= unlockMaxDelay {
+			return unlockMaxDelay
+		}
+	}
+	return delay
+}
+
+// UnlockThrottle ограничивает частоту попыток разблокировки мастер-ключа
+type UnlockThrottle struct {
+	path   string
+	cipher *StateCipher
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewUnlockThrottle создает счетчик попыток, хранящийся в path
+func NewUnlockThrottle(path string, cipher *StateCipher) *UnlockThrottle {
+	return &UnlockThrottle{
+		path:   path,
+		cipher: cipher,
+		now:    time.Now,
+	}
+}
+
+// Check возвращает *ThrottledError, если пауза после прошлых ошибок еще не истекла
+func (t *UnlockThrottle) Check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	wait := attempts.LastFailure.Add(UnlockDelay(attempts.Failures)).Sub(t.now())
+	if wait > 0 {
+		return &ThrottledError{Failures: attempts.Failures, RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordFailure учитывает неудачную попытку и возвращает число ошибок подряд
+func (t *UnlockThrottle) RecordFailure() (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts, err := t.load()
+	if err != nil {
+		// Поврежденный счетчик не должен обнулять задержку
+		attempts = UnlockAttempts{Failures: unlockFreeAttempts}
+	}
+	attempts.Failures++
+	attempts.LastFailure = t.now()
+
+	data, err := json.Marshal(attempts)
+	if err != nil {
+		return attempts.Failures, fmt.Errorf("ошибка сериализации счетчика попыток: %w", err)
+	}
+	if err := t.cipher.WriteFile(t.path, data); err != nil {
+		return attempts.Failures, err
+	}
+	return attempts.Failures, nil
+}
+
+// Reset обнуляет счетчик после успешной разблокировки
+func (t *UnlockThrottle) Reset() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.Remove(t.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ошибка сброса счетчика попыток: %w", err)
+	}
+	return nil
+}
+
+// Attempts возвращает текущее состояние счетчика
+func (t *UnlockThrottle) Attempts() (UnlockAttempts, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.load()
+}
+
+func (t *UnlockThrottle) load() (UnlockAttempts, error) {
+	var attempts UnlockAttempts
+
+	data, err := t.cipher.ReadFile(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return attempts, nil
+		}
+		return attempts, fmt.Errorf("ошибка чтения счетчика попыток: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return attempts, fmt.Errorf("ошибка разбора счетчика попыток: %w", err)
+	}
+	return attempts, nil
+}
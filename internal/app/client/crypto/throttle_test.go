@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{6, 8 * time.Second},
+		{20, 15 * time.Minute},
+		{1000, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, UnlockDelay(tt.failures), "failures=%d", tt.failures)
+	}
+}
+
+func TestUnlockThrottle(t *testing.T) {
+	newThrottle := func(t *testing.T) (*UnlockThrottle, *time.Time) {
+		dir := t.TempDir()
+		now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+		throttle := NewUnlockThrottle(filepath.Join(dir, "unlock_attempts"), newTestStateCipher(dir, nil, "machine-a"))
+		throttle.now = func() time.Time { return now }
+		return throttle, &now
+	}
+
+	t.Run("First failures are not delayed", func(t *testing.T) {
+		throttle, _ := newThrottle(t)
+
+		for i := 1; i < unlockFreeAttempts; i++ {
+			failures, err := throttle.RecordFailure()
+			require.NoError(t, err)
+			assert.Equal(t, i, failures)
+			assert.NoError(t, throttle.Check())
+		}
+	})
+
+	t.Run("Delay grows and expires", func(t *testing.T) {
+		throttle, now := newThrottle(t)
+
+		for i := 0; i < unlockFreeAttempts+1; i++ {
+			_, err := throttle.RecordFailure()
+			require.NoError(t, err)
+		}
+
+		err := throttle.Check()
+		var throttled *ThrottledError
+		require.True(t, errors.As(err, &throttled))
+		assert.Equal(t, unlockFreeAttempts+1, throttled.Failures)
+		assert.Equal(t, 2*time.Second, throttled.RetryAfter)
+
+		*now = now.Add(2 * time.Second)
+		assert.NoError(t, throttle.Check())
+	})
+
+	t.Run("Counter survives restart and is encrypted", func(t *testing.T) {
+		throttle, _ := newThrottle(t)
+		for i := 0; i < 5; i++ {
+			_, err := throttle.RecordFailure()
+			require.NoError(t, err)
+		}
+
+		raw, err := os.ReadFile(throttle.path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "failures")
+
+		restarted := NewUnlockThrottle(throttle.path, throttle.cipher)
+		restarted.now = throttle.now
+		attempts, err := restarted.Attempts()
+		require.NoError(t, err)
+		assert.Equal(t, 5, attempts.Failures)
+		assert.Error(t, restarted.Check())
+	})
+
+	t.Run("Reset clears counter", func(t *testing.T) {
+		throttle, _ := newThrottle(t)
+		for i := 0; i < 5; i++ {
+			_, err := throttle.RecordFailure()
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, throttle.Reset())
+		assert.NoError(t, throttle.Check())
+		assert.NoFileExists(t, throttle.path)
+		require.NoError(t, throttle.Reset())
+	})
+
+	t.Run("Corrupted counter keeps delay", func(t *testing.T) {
+		throttle, _ := newThrottle(t)
+		require.NoError(t, os.WriteFile(throttle.path, []byte("garbage"), 0600))
+
+		failures, err := throttle.RecordFailure()
+		require.NoError(t, err)
+		assert.Equal(t, unlockFreeAttempts+1, failures)
+	})
+}
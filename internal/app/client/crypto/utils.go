@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"math/big"
 	"strings"
 	"unicode"
@@ -117,7 +118,7 @@ func CheckPasswordStrength(password string) PasswordStrength {
 // GenerateRandomBytes генерирует криптографически безопасные случайные байты
 func GenerateRandomBytes(n int) ([]byte, error) {
 	b := make([]byte, n)
-	_, err := rand.Read(b)
+	_, err := io.ReadFull(randReader, b)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации случайных байт: %w", err)
 	}
@@ -150,7 +151,7 @@ func MaskSensitiveData(data string) string {
 // secureRandInt возвращает криптографически безопасное случайное число
 // nolint
 func secureRandInt(max int) int {
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	n, err := rand.Int(randReader, big.NewInt(int64(max)))
 	if err != nil {
 		panic(fmt.Sprintf("ошибка генерации случайного числа: %v", err))
 	}
@@ -173,7 +174,7 @@ func DeriveKeyFromPassword(password string, salt []byte, iterations int, keyLeng
 // GenerateSalt генерирует случайную соль
 func GenerateSalt(length int) ([]byte, error) {
 	salt := make([]byte, length)
-	_, err := rand.Read(salt)
+	_, err := io.ReadFull(randReader, salt)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка генерации соли: %w", err)
 	}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/config"
+	"gophkeeper/internal/domain/record"
+)
+
+// untarDebugBundle возвращает файлы отладочного архива
+func untarDebugBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func TestApp_WriteDebugBundle(t *testing.T) {
+	app := newTestApp(t)
+	dir := app.config.ConfigDir
+
+	const (
+		token        = "eyJhbGciOiJIUzI1NiJ9.session-token.c2lnbmF0dXJl"
+		password     = "hunter2-master-password"
+		serverPass   = "srv-basic-auth-pass"
+		configToken  = "cfg-api-token-value"
+		login        = "alice@example.com"
+		recordTitle  = "Bank of Secrets"
+		recordSecret = "record-payload-not-for-bundle"
+		syncSecret   = "sync-config-secret-value"
+		logPassword  = "log-password-value"
+		logAPIKey    = "log-api-key-value"
+		logJSONToken = "log-json-token-value"
+		logBearer    = "log.bearer.token"
+	)
+
+	// Конфигурация с учетными данными в адресе сервера и секретным параметром
+	app.config.ServerAddress = "https://admin:" + serverPass + "@keeper.example.com"
+	app.config.Values = []config.Value{
+		{Key: "server_address", Value: "https://keeper.example.com", Source: "file"},
+		{Key: "api_token", Value: configToken, Source: "env"},
+	}
+
+	require.NoError(t, app.InitMasterKey(password))
+	require.NoError(t, os.WriteFile(app.config.TokenPath, []byte(token), 0600))
+	app.httpClient.SetToken(token)
+	app.state.Update(func(s *AppState) { s.UserLogin = login })
+
+	storage, err := NewSQLiteStorage(filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+	app.storage = storage
+	require.NoError(t, storage.SaveRecord(&LocalRecord{
+		Type:          record.RecTypeLogin,
+		EncryptedData: recordSecret,
+		Meta:          json.RawMessage(`{"title":"` + recordTitle + `"}`),
+		Version:       1,
+	}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sync_config.json"),
+		[]byte(`{"batch_size":50,"webhook_secret":"`+syncSecret+`"}`), 0600))
+	require.NoError(t, app.stateCipher.WriteFile(filepath.Join(dir, "sync_metadata.json"),
+		[]byte(`{"client_id":"client-1","cursor":"c1"}`)))
+
+	log := "level=INFO msg=\"unlock\" password=" + logPassword + "\n" +
+		"level=DEBUG msg=request Authorization: Bearer " + logBearer + "\n" +
+		"level=DEBUG msg=body payload={\"token\":\"" + logJSONToken + "\"}\n" +
+		"level=DEBUG msg=config api_key: \"" + logAPIKey + "\"\n" +
+		"level=INFO msg=dial url=https://admin:" + serverPass + "@keeper.example.com\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "agent.log"), []byte(log), 0600))
+
+	var buf bytes.Buffer
+	names, err := app.WriteDebugBundle(&buf)
+	require.NoError(t, err)
+
+	files := untarDebugBundle(t, buf.Bytes())
+	got := make([]string, 0, len(files))
+	for name := range files {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+	sort.Strings(names)
+	assert.Equal(t, names, got)
+	for _, name := range []string{"config.json", "state.json", "sync_config.json", "sync_metadata.json", "db_schema.json", "agent.log", "manifest.json"} {
+		assert.Contains(t, files, name)
+	}
+
+	masterKeyFile, err := os.ReadFile(app.config.MasterKeyPath)
+	require.NoError(t, err)
+	keyHash, err := app.crypto.GetKeyHash()
+	require.NoError(t, err)
+
+	secrets := []string{
+		token, password, serverPass, configToken, login, recordTitle, recordSecret, syncSecret,
+		logPassword, logAPIKey, logJSONToken, logBearer, keyHash,
+	}
+	for name, content := range files {
+		for _, secret := range secrets {
+			assert.NotContains(t, content, secret, "секрет в %s", name)
+		}
+		assert.NotContains(t, content, string(masterKeyFile), "файл ключа в %s", name)
+	}
+
+	// Служебные сведения, ради которых архив собирается, остаются
+	assert.Contains(t, files["config.json"], "keeper.example.com")
+	assert.Contains(t, files["config.json"], "api_token")
+	assert.Contains(t, files["sync_config.json"], `"batch_size": 50`)
+	assert.Contains(t, files["sync_metadata.json"], "client-1")
+	assert.Contains(t, files["db_schema.json"], `"login": 1`)
+	assert.Contains(t, files["agent.log"], `msg="unlock"`)
+}
@@ -0,0 +1,304 @@
+// internal/app/client/device.go
+package client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Идентификатор устройства - случайный UUID установки клиента. Он хранится
+// в device.json и, если доступно, в хранилище секретов ОС: после переустановки
+// с удалением каталога конфигурации клиент восстанавливает тот же UUID и
+// сервер узнает прежнее устройство, а не заводит новое.
+//
+// Кроме UUID у установки есть ключ Ed25519. Открытый ключ передается при
+// регистрации: новое устройство не получает данные, пока его не подтвердят
+// с доверенного устройства, сверив отпечаток ключа (device approve).
+
+const deviceFile = "device.json"
+
+// DeviceIdentity - идентификатор установки клиента и ее запись на сервере
+type DeviceIdentity struct {
+	UUID         string    `json:"uuid"`
+	ServerID     int       `json:"server_id,omitempty"`
+	Name         string    `json:"name"`
+	RegisteredAt time.Time `json:"registered_at,omitempty"`
+	// PrivateKey - seed ключа Ed25519 устройства в base64
+	PrivateKey string `json:"private_key,omitempty"`
+	// Status - состояние доверия устройства по последней регистрации
+	Status sync.DeviceStatus `json:"status,omitempty"`
+}
+
+// PublicKey возвращает открытый ключ устройства в base64
+func (d *DeviceIdentity) PublicKey() string {
+	seed, err := base64.StdEncoding.DecodeString(d.PrivateKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return ""
+	}
+	public := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(public)
+}
+
+// signingKey возвращает ключ устройства для подписи запросов
+func (d *DeviceIdentity) signingKey() ed25519.PrivateKey {
+	seed, err := base64.StdEncoding.DecodeString(d.PrivateKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil
+	}
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// Fingerprint возвращает отпечаток ключа устройства для сверки при подтверждении
+func (d *DeviceIdentity) Fingerprint() string {
+	return sync.DeviceFingerprint(d.PublicKey())
+}
+
+// DeviceIdentity возвращает идентификатор устройства, создавая его при первом вызове
+func (a *App) DeviceIdentity() (*DeviceIdentity, error) {
+	a.deviceMu.Lock()
+	defer a.deviceMu.Unlock()
+	return a.loadDeviceIdentity()
+}
+
+func (a *App) loadDeviceIdentity() (*DeviceIdentity, error) {
+	if a.device != nil {
+		return a.device, nil
+	}
+
+	var identity DeviceIdentity
+	data, err := os.ReadFile(a.deviceFilePath())
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &identity); err != nil {
+			return nil, fmt.Errorf("ошибка чтения %s: %w", deviceFile, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("ошибка чтения %s: %w", deviceFile, err)
+	}
+
+	changed := false
+	if _, err := uuid.Parse(identity.UUID); err != nil {
+		identity = DeviceIdentity{UUID: a.restoreDeviceUUID(), Name: getDeviceName()}
+		changed = true
+	}
+	if identity.PublicKey() == "" {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, fmt.Errorf("ошибка генерации ключа устройства: %w", err)
+		}
+		identity.PrivateKey = base64.StdEncoding.EncodeToString(seed)
+		changed = true
+	}
+	if changed {
+		if err := a.saveDeviceIdentity(&identity); err != nil {
+			return nil, err
+		}
+	}
+
+	a.device = &identity
+	if a.httpClient != nil {
+		a.httpClient.SetDeviceID(identity.UUID)
+	}
+	return a.device, nil
+}
+
+// restoreDeviceUUID берет UUID из хранилища ОС или создает новый
+func (a *App) restoreDeviceUUID() string {
+	account := a.deviceAccount()
+
+	if a.keyProvider.Available() {
+		stored, err := a.keyProvider.Load(account)
+		if err == nil {
+			if id, err := uuid.ParseBytes(stored); err == nil {
+				a.log.Info("Идентификатор устройства восстановлен из хранилища ОС", "uuid", id.String())
+				return id.String()
+			}
+		} else if !errors.Is(err, crypto.ErrKeyNotFound) {
+			a.log.Debug("Не удалось прочитать идентификатор устройства из хранилища ОС", "error", err)
+		}
+	}
+
+	id := uuid.NewString()
+	if a.keyProvider.Available() {
+		if err := a.keyProvider.Store(account, []byte(id)); err != nil {
+			a.log.Debug("Не удалось сохранить идентификатор устройства в хранилище ОС", "error", err)
+		}
+	}
+	return id
+}
+
+func (a *App) saveDeviceIdentity(identity *DeviceIdentity) error {
+	data, err := json.MarshalIndent(identity, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации идентификатора устройства: %w", err)
+	}
+	if err := os.MkdirAll(a.config.ConfigDir, 0700); err != nil {
+		return fmt.Errorf("ошибка создания директории конфигурации: %w", err)
+	}
+	if err := os.WriteFile(a.deviceFilePath(), data, 0600); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", deviceFile, err)
+	}
+	return nil
+}
+
+func (a *App) deviceFilePath() string {
+	return filepath.Join(a.config.ConfigDir, deviceFile)
+}
+
+// deviceAccount - имя записи в хранилище ОС. Зависит от каталога конфигурации,
+// чтобы несколько профилей на одной машине были разными устройствами.
+func (a *App) deviceAccount() string {
+	sum := sha256.Sum256([]byte(a.config.ConfigDir))
+	return "device-id-" + hex.EncodeToString(sum[:8])
+}
+
+// RegisterDevice регистрирует устройство на сервере. claimID > 0 забирает
+// существующую запись устройства (например, после переустановки без
+// сохраненного UUID); сервер удаляет оставшиеся дубликаты.
+func (a *App) RegisterDevice(ctx context.Context, claimID int) (*sync.RegisterDeviceResponse, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация")
+	}
+
+	identity, err := a.DeviceIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := a.httpClient.RegisterDevice(ctx, sync.RegisterDeviceRequest{
+		UUID:      identity.UUID,
+		Name:      getDeviceName(),
+		Type:      "desktop",
+		ClaimID:   claimID,
+		PublicKey: identity.PublicKey(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка регистрации устройства: %w", err)
+	}
+
+	a.deviceMu.Lock()
+	defer a.deviceMu.Unlock()
+	identity.ServerID = response.Data.ID
+	identity.Name = response.Data.Name
+	identity.Status = response.Data.Status
+	identity.RegisteredAt = time.Now()
+	if err := a.saveDeviceIdentity(identity); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ApproveDevice подтверждает устройство deviceID с этого устройства.
+// fingerprint - отпечаток ключа, который показывает новое устройство;
+// если он указан, сервер отклонит подтверждение устройства с другим ключом.
+func (a *App) ApproveDevice(ctx context.Context, deviceID int, fingerprint string) (*sync.DeviceInfo, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+	if _, err := a.DeviceIdentity(); err != nil {
+		return nil, err
+	}
+
+	response, err := a.httpClient.ApproveDevice(ctx, deviceID, sync.ApproveDeviceRequest{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подтверждения устройства: %w", err)
+	}
+	return response.Data, nil
+}
+
+// ensureDeviceRegistered регистрирует устройство перед первой синхронизацией.
+// Неподтвержденное устройство регистрируется при каждой синхронизации, чтобы
+// узнать о подтверждении; пока его нет, возвращается ErrDevicePending.
+func (a *App) ensureDeviceRegistered(ctx context.Context) error {
+	identity, err := a.DeviceIdentity()
+	if err != nil {
+		a.log.Warn("Не удалось получить идентификатор устройства", "error", err)
+		return nil
+	}
+	if identity.ServerID != 0 && identity.Status != sync.DevicePending {
+		return nil
+	}
+
+	response, err := a.RegisterDevice(ctx, 0)
+	if err != nil {
+		a.log.Warn("Не удалось зарегистрировать устройство", "error", err)
+		return nil
+	}
+	if response.Merged > 0 {
+		a.log.Info("Удалены дубликаты устройства", "count", response.Merged)
+	}
+	if response.Data.Status == sync.DevicePending {
+		return ErrDevicePending
+	}
+	return nil
+}
+
+// forgetDeviceRegistration сбрасывает серверный ID устройства, которое сервер
+// больше не знает (например, его удалили): следующая синхронизация
+// зарегистрирует устройство заново
+func (a *App) forgetDeviceRegistration() {
+	a.deviceMu.Lock()
+	defer a.deviceMu.Unlock()
+
+	identity, err := a.loadDeviceIdentity()
+	if err != nil || identity.ServerID == 0 {
+		return
+	}
+	identity.ServerID = 0
+	identity.Status = ""
+	if err := a.saveDeviceIdentity(identity); err != nil {
+		a.log.Warn("Не удалось сохранить идентификатор устройства", "error", err)
+	}
+}
+
+// deviceUUID возвращает UUID устройства для заголовка X-Device-ID
+func (a *App) deviceUUID() string {
+	identity, err := a.DeviceIdentity()
+	if err != nil {
+		a.log.Debug("Не удалось получить идентификатор устройства", "error", err)
+		return ""
+	}
+	return identity.UUID
+}
+
+// deviceSigningKey возвращает ключ устройства для подписи запросов
+func (a *App) deviceSigningKey() ed25519.PrivateKey {
+	identity, err := a.DeviceIdentity()
+	if err != nil {
+		a.log.Debug("Не удалось получить ключ устройства", "error", err)
+		return nil
+	}
+	return identity.signingKey()
+}
+
+// deviceTrustFromResponse распознает отказ сервера неподтвержденному или
+// незарегистрированному устройству
+func deviceTrustFromResponse(resp *http.Response) error {
+	if resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	switch resp.Header.Get(sync.DeviceStatusHeader) {
+	case string(sync.DevicePending):
+		return ErrDevicePending
+	case sync.DeviceUnregistered:
+		return ErrDeviceUnregistered
+	}
+	return nil
+}
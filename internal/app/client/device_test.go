@@ -0,0 +1,109 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/sync"
+)
+
+func TestApp_DeviceIdentity_GeneratesKey(t *testing.T) {
+	app := newTestApp(t)
+	// device.json клиента без ключа устройства
+	legacy := `{"uuid":"0f8fad5b-d9cb-469f-a165-70867728950e","server_id":3,"name":"laptop"}`
+	require.NoError(t, os.WriteFile(app.deviceFilePath(), []byte(legacy), 0600))
+
+	identity, err := app.DeviceIdentity()
+	require.NoError(t, err)
+	assert.Equal(t, "0f8fad5b-d9cb-469f-a165-70867728950e", identity.UUID)
+	assert.Equal(t, 3, identity.ServerID)
+	require.NotEmpty(t, identity.PublicKey())
+	assert.Equal(t, sync.DeviceFingerprint(identity.PublicKey()), identity.Fingerprint())
+
+	// Ключ сохраняется и не меняется при следующем запуске
+	app.device = nil
+	reloaded, err := app.DeviceIdentity()
+	require.NoError(t, err)
+	assert.Equal(t, identity.PublicKey(), reloaded.PublicKey())
+
+	app.forgetDeviceRegistration()
+	assert.Zero(t, app.device.ServerID)
+}
+
+func TestHTTPClient_SignsDeviceRequests(t *testing.T) {
+	var proofs []sync.DeviceProof
+	gzipped := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Подпись проверяется по распакованному телу, как на сервере
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = gz
+			gzipped++
+		}
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		proofs = append(proofs, sync.DeviceProof{
+			UUID:      r.Header.Get(sync.DeviceHeader),
+			Timestamp: r.Header.Get(sync.DeviceTimestampHeader),
+			Signature: r.Header.Get(sync.DeviceSignatureHeader),
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			BodyHash:  sync.DeviceBodyHash(data),
+		})
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	legacy := `{"uuid":"0f8fad5b-d9cb-469f-a165-70867728950e","name":"laptop"}`
+	require.NoError(t, os.WriteFile(app.deviceFilePath(), []byte(legacy), 0600))
+	app.httpClient.deviceSource = app.deviceUUID
+	app.httpClient.deviceKey = app.deviceSigningKey
+
+	_, err := app.httpClient.doRequest(context.Background(), "GET", "/api/sync/changes?limit=10", nil)
+	require.NoError(t, err)
+	_, err = app.httpClient.doRequest(context.Background(), "POST", "/api/sync/devices/7/approve", struct{}{})
+	require.NoError(t, err)
+	app.httpClient.gzipRequests.Store(true)
+	_, err = app.httpClient.doRequest(context.Background(), "POST", "/api/sync/batch", map[string]string{
+		"data": strings.Repeat("encrypted-record ", gzipMinSize),
+	})
+	require.NoError(t, err)
+
+	identity, err := app.DeviceIdentity()
+	require.NoError(t, err)
+	require.Len(t, proofs, 3)
+	assert.Equal(t, 1, gzipped)
+	assert.Equal(t, "/api/sync/changes?limit=10", proofs[0].URI)
+	for _, proof := range proofs {
+		assert.Equal(t, identity.UUID, proof.UUID)
+		assert.NoError(t, proof.Verify(identity.PublicKey(), time.Now()))
+	}
+}
+
+func TestDeviceTrustFromResponse(t *testing.T) {
+	response := func(status int, deviceStatus string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if deviceStatus != "" {
+			resp.Header.Set(sync.DeviceStatusHeader, deviceStatus)
+		}
+		return resp
+	}
+
+	assert.ErrorIs(t, deviceTrustFromResponse(response(http.StatusForbidden, string(sync.DevicePending))), ErrDevicePending)
+	assert.ErrorIs(t, deviceTrustFromResponse(response(http.StatusForbidden, sync.DeviceUnregistered)), ErrDeviceUnregistered)
+	assert.NoError(t, deviceTrustFromResponse(response(http.StatusForbidden, "")))
+	assert.NoError(t, deviceTrustFromResponse(response(http.StatusOK, string(sync.DevicePending))))
+}
@@ -7,13 +7,15 @@ import (
 	"fmt"
 
 	"gophkeeper/internal/domain/record"
+
+	"github.com/google/uuid"
 )
 
 // encryptRecordData шифрует данные записи перед отправкой на сервер
 func (a *App) encryptRecordData(data interface{}) (string, error) {
 	// Проверяем, что мастер-ключ разблокирован
 	if !a.IsMasterKeyUnlocked() {
-		return "", fmt.Errorf("мастер-ключ заблокирован")
+		return "", ErrMasterKeyLocked
 	}
 
 	// Сериализуем данные в JSON
@@ -36,7 +38,7 @@ func (a *App) encryptRecordData(data interface{}) (string, error) {
 func (a *App) decryptRecordData(encryptedData string, target interface{}) error {
 	// Проверяем, что мастер-ключ разблокирован
 	if !a.IsMasterKeyUnlocked() {
-		return fmt.Errorf("мастер-ключ заблокирован")
+		return ErrMasterKeyLocked
 	}
 
 	// Декодируем из base64
@@ -45,14 +47,15 @@ func (a *App) decryptRecordData(encryptedData string, target interface{}) error
 		return fmt.Errorf("ошибка декодирования base64: %w", err)
 	}
 
-	// Расшифровываем данные
-	decryptedData, err := a.encryptor.DecryptRecord(encrypted)
+	// Расшифровываем данные; открытый JSON затирается после разбора
+	decryptedData, err := a.encryptor.DecryptRecordSecure(encrypted)
 	if err != nil {
 		return fmt.Errorf("ошибка расшифровки данных: %w", err)
 	}
+	defer decryptedData.Close()
 
 	// Десериализуем JSON в целевую структуру
-	if err := json.Unmarshal(decryptedData, target); err != nil {
+	if err := json.Unmarshal(decryptedData.Bytes(), target); err != nil {
 		return fmt.Errorf("ошибка десериализации данных: %w", err)
 	}
 
@@ -73,3 +76,14 @@ func (a *App) prepareEncryptedRecord(recType record.RecType, data interface{}, m
 		Meta: meta,
 	}, nil
 }
+
+// prepareNewRecord подготавливает создаваемую запись. UUID назначается до
+// отправки: по нему сервер узнает повторную отправку, ответ на которую не дошел.
+func (a *App) prepareNewRecord(recType record.RecType, data interface{}, meta json.RawMessage) (GenericRecordRequest, error) {
+	req, err := a.prepareEncryptedRecord(recType, data, meta)
+	if err != nil {
+		return GenericRecordRequest{}, err
+	}
+	req.UUID = uuid.NewString()
+	return req, nil
+}
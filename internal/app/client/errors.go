@@ -0,0 +1,184 @@
+// internal/app/client/errors.go
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/mfa"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+)
+
+// Ошибки клиента. Серверные ответы переводятся в те же виды apperr, что и
+// ошибки доменов сервера, поэтому команды CLI проверяют их через errors.Is:
+// errors.Is(err, apperr.NotFound), errors.Is(err, apperr.Conflict) и т.д.
+// Код ответа (поле code) переводится обратно в сентинел домена, поэтому
+// конкретные причины проверяются так же: errors.Is(err, record.ErrVersionConflict).
+var (
+	// ErrMasterKeyLocked - операция требует разблокированного мастер-ключа
+	ErrMasterKeyLocked = errors.New("мастер-ключ заблокирован. Выполните: gophkeeper unlock")
+	// ErrAuthRequired - команда работает с сервером, а вход не выполнен
+	ErrAuthRequired = apperr.New(apperr.Unauthorized, "требуется аутентификация. Выполните: gophkeeper auth login")
+	// ErrNotLoggedIn - на устройстве нет токена сервера
+	ErrNotLoggedIn = apperr.New(apperr.Unauthorized, "токен не найден. Выполните вход: gophkeeper auth login")
+	// ErrRecordNotFound - записи нет в локальном хранилище
+	ErrRecordNotFound = apperr.New(apperr.NotFound, "запись не найдена")
+	// ErrRecordLocked - запись защищена от изменений и удаления
+	ErrRecordLocked = apperr.New(apperr.Conflict, "запись защищена от изменений. Снимите защиту: gophkeeper record unlock <ID>")
+	// ErrFolderNotFound - папки с таким путем нет
+	ErrFolderNotFound = apperr.New(apperr.NotFound, "папка не найдена. Список папок: gophkeeper folder list")
+	// ErrBlobNotFound - блоба с содержимым файла нет на сервере
+	ErrBlobNotFound = apperr.New(apperr.NotFound, "содержимое файла не найдено на сервере")
+	// ErrNothingToUndo - журнал отмены пуст
+	ErrNothingToUndo = apperr.New(apperr.NotFound, "нет изменений для отмены")
+	// ErrUndoSynced - последнее изменение уже отправлено на сервер
+	ErrUndoSynced = apperr.New(apperr.Conflict, "изменение уже отправлено на сервер. Прошлые версии записи: gophkeeper record history <ID>")
+	// ErrBackupTargetNotFound - цели резервного копирования с таким именем нет
+	ErrBackupTargetNotFound = apperr.New(apperr.NotFound, "цель резервного копирования не найдена. Список целей: gophkeeper backup target list")
+	// ErrKeyFileNotFound - на сервере нет файла мастер-ключа учетной записи
+	ErrKeyFileNotFound = apperr.New(apperr.NotFound, "на сервере нет файла мастер-ключа. Восстановите хранилище из резервной копии: gophkeeper import-backup <файл>")
+	// ErrDevicePending - сервер не отдает данные, пока устройство не подтверждено
+	ErrDevicePending = apperr.New(apperr.Forbidden, "устройство ожидает подтверждения. Подтвердите его на доверенном устройстве: gophkeeper device approve <ID>")
+	// ErrDeviceUnregistered - сервер не знает это устройство (например, его удалили)
+	ErrDeviceUnregistered = apperr.New(apperr.Forbidden, "устройство не зарегистрировано на сервере. Выполните: gophkeeper device register")
+	// ErrUploadRejected - файл не пропустил хук pre-upload или политика вложений сервера
+	ErrUploadRejected = apperr.New(apperr.Forbidden, "файл отклонен проверкой перед загрузкой")
+	// ErrServerUnavailable - сервер не ответил или отвечает ошибками 5xx после всех повторов
+	ErrServerUnavailable = errors.New("сервер недоступен")
+)
+
+// serverErrors - сентинелы доменов, в которые переводятся коды ответов
+// сервера. Коды, общие для нескольких доменов, переводятся в первый сентинел.
+var serverErrors = indexByCode(
+	record.ErrNotFound,
+	record.ErrInvalidData,
+	record.ErrVersionConflict,
+	record.ErrRecordDeleted,
+	record.ErrForbidden,
+	record.ErrNotDeleted,
+	record.ErrRecordLocked,
+	record.ErrAttachmentNotFound,
+	record.ErrAttachmentTooLarge,
+	record.ErrTooManyAttachments,
+	sync.ErrStorageLimit,
+	sync.ErrVersionNotNewer,
+	sync.ErrInvalidCursor,
+	sync.ErrInvalidProtocol,
+	sync.ErrProtocolDisabled,
+	sync.ErrDeviceNotFound,
+	sync.ErrDevicePending,
+	sync.ErrDeviceUnregistered,
+	sync.ErrDeviceNotTrusted,
+	session.ErrInvalidSession,
+	user.ErrInvalidAuth,
+	user.ErrDisabled,
+	user.ErrLoginTaken,
+	user.ErrSamePassword,
+	keyfile.ErrNotFound,
+	keyfile.ErrKeyMismatch,
+	blob.ErrNotFound,
+	blob.ErrRejected,
+	folder.ErrNotFound,
+	folder.ErrExists,
+	folder.ErrNotEmpty,
+	mfa.ErrInvalidCode,
+	mfa.ErrChallengeNotFound,
+	mfa.ErrTooManyAttempts,
+	secretlink.ErrNotFound,
+)
+
+func indexByCode(sentinels ...*apperr.Error) map[apperr.Code]error {
+	index := make(map[apperr.Code]error, len(sentinels))
+	for _, sentinel := range sentinels {
+		if _, ok := index[sentinel.Code()]; !ok {
+			index[sentinel.Code()] = sentinel
+		}
+	}
+	return index
+}
+
+// ServerError - ответ сервера с кодом 4xx. Вид ошибки определяется по коду
+// ответа, причина - по коду ошибки из тела, текст - из тела ответа.
+type ServerError struct {
+	StatusCode int
+	// Code - машиночитаемый код ошибки; пуст в ответах старых серверов
+	Code    apperr.Code
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	switch e.Kind() {
+	case apperr.Unauthorized:
+		if e.sessionExpired() {
+			return "сессия истекла или вход не выполнен. Выполните: gophkeeper auth login"
+		}
+		// Остальные коды - причины отказа при входе, например неверный код 2FA
+		return "ошибка входа: " + e.message()
+	case apperr.Forbidden:
+		return "недостаточно прав: " + e.message()
+	case apperr.Conflict:
+		return "конфликт версий: " + e.message()
+	}
+	return "ошибка сервера: " + e.message()
+}
+
+func (e *ServerError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("статус %d", e.StatusCode)
+}
+
+// Kind возвращает вид ошибки по коду ответа
+func (e *ServerError) Kind() apperr.Kind {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return apperr.Unauthorized
+	case http.StatusForbidden:
+		return apperr.Forbidden
+	case http.StatusNotFound:
+		return apperr.NotFound
+	case http.StatusGone:
+		return apperr.Gone
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return apperr.Conflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return apperr.Invalid
+	case http.StatusRequestEntityTooLarge:
+		return apperr.QuotaExceeded
+	}
+	return ""
+}
+
+// sessionExpired сообщает, что ответ 401 отклонил токен, а не данные входа
+func (e *ServerError) sessionExpired() bool {
+	switch e.Code {
+	case apperr.CodeOf(session.ErrInvalidSession), apperr.CodeUnauthorized:
+		return true
+	case "":
+		// Старые серверы отвечают "Unauthorized" на недействительный токен
+		return e.Message == "" || e.Message == "Unauthorized"
+	}
+	return false
+}
+
+// Is позволяет сравнивать ошибку с видами apperr
+func (e *ServerError) Is(target error) bool {
+	kind, ok := target.(apperr.Kind)
+	return ok && kind != "" && kind == e.Kind()
+}
+
+// Unwrap возвращает сентинел домена по коду ошибки, чтобы причину отказа
+// можно было проверить через errors.Is
+func (e *ServerError) Unwrap() error {
+	return serverErrors[e.Code]
+}
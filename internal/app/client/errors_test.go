@@ -0,0 +1,122 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+)
+
+func TestParseResponse_ServerErrors(t *testing.T) {
+	h := &httpClient{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	respond := func(status int, body string) error {
+		return h.parseResponse(&http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil)
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		kind    apperr.Kind
+		message string
+	}{
+		{"not found", http.StatusNotFound, `{"detail":"record not found"}`, apperr.NotFound, "ошибка сервера: record not found"},
+		{"conflict", http.StatusConflict, `{"detail":"record version conflict"}`, apperr.Conflict, "конфликт версий: record version conflict"},
+		{"expired token", http.StatusUnauthorized, `{"error":"Unauthorized"}`, apperr.Unauthorized, "сессия истекла"},
+		{"wrong 2fa code", http.StatusUnauthorized, `{"detail":"invalid two-factor code"}`, apperr.Unauthorized, "ошибка входа: invalid two-factor code"},
+		{"forbidden", http.StatusForbidden, `{"detail":"access to record denied"}`, apperr.Forbidden, "недостаточно прав"},
+		{"no body", http.StatusBadRequest, ``, apperr.Invalid, "ошибка сервера: статус 400"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("операция: %w", respond(tt.status, tt.body))
+
+			assert.ErrorIs(t, err, tt.kind)
+			assert.Equal(t, tt.kind, apperr.KindOf(err))
+			assert.Contains(t, err.Error(), tt.message)
+
+			var serverErr *ServerError
+			if assert.True(t, errors.As(err, &serverErr)) {
+				assert.Equal(t, tt.status, serverErr.StatusCode)
+			}
+		})
+	}
+}
+
+func TestParseResponse_ErrorCodes(t *testing.T) {
+	h := &httpClient{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	respond := func(status int, body string) error {
+		return h.parseResponse(&http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil)
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		target  error
+		message string
+	}{
+		{"version conflict", http.StatusConflict, `{"detail":"record version conflict","code":"VERSION_CONFLICT"}`, record.ErrVersionConflict, "конфликт версий"},
+		{"record not found", http.StatusNotFound, `{"detail":"record not found","code":"RECORD_NOT_FOUND"}`, record.ErrNotFound, "record not found"},
+		{"storage limit", http.StatusRequestEntityTooLarge, `{"detail":"storage limit exceeded","code":"STORAGE_LIMIT"}`, sync.ErrStorageLimit, "storage limit exceeded"},
+		{"auth expired", http.StatusUnauthorized, `{"detail":"invalid session","code":"AUTH_EXPIRED"}`, session.ErrInvalidSession, "сессия истекла"},
+		{"wrong password", http.StatusUnauthorized, `{"detail":"Invalid credentials","code":"INVALID_CREDENTIALS"}`, user.ErrInvalidAuth, "ошибка входа: Invalid credentials"},
+		{"device pending", http.StatusForbidden, `{"detail":"device is awaiting approval","code":"DEVICE_PENDING"}`, sync.ErrDevicePending, "недостаточно прав"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("операция: %w", respond(tt.status, tt.body))
+
+			assert.ErrorIs(t, err, tt.target)
+			assert.Equal(t, apperr.KindOf(tt.target), apperr.KindOf(err))
+			assert.Equal(t, apperr.CodeOf(tt.target), apperr.CodeOf(err))
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+
+	// Неизвестный код сохраняется, но сентинела у него нет
+	err := respond(http.StatusConflict, `{"detail":"new reason","code":"SOMETHING_NEW"}`)
+	var serverErr *ServerError
+	if assert.ErrorAs(t, err, &serverErr) {
+		assert.Equal(t, apperr.Code("SOMETHING_NEW"), serverErr.Code)
+	}
+	assert.NotErrorIs(t, err, record.ErrVersionConflict)
+	assert.ErrorIs(t, err, apperr.Conflict)
+}
+
+func TestParseResponse_QuotaExceeded(t *testing.T) {
+	h := &httpClient{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	err := h.parseResponse(&http.Response{
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Body:       io.NopCloser(strings.NewReader(`{"used":90,"limit":100,"requested":20,"remaining":10}`)),
+	}, nil)
+
+	var quotaErr *QuotaExceededError
+	assert.ErrorAs(t, err, &quotaErr)
+	assert.ErrorIs(t, err, apperr.QuotaExceeded)
+}
+
+func TestLocalErrors(t *testing.T) {
+	storage := NewMemoryStorage()
+	_, err := storage.GetRecord(42)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.ErrorIs(t, err, apperr.NotFound)
+	assert.Equal(t, "запись не найдена: 42", err.Error())
+}
@@ -0,0 +1,57 @@
+// internal/app/client/expiry.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Срок действия записи (meta.expires_at) хранится в открытых метаданных и
+// синхронизируется вместе с записью. Для карты срок заполняется при создании
+// из срока действия карты, для остальных записей задается явно.
+
+// SetRecordExpiry устанавливает срок действия записи; nil снимает срок
+func (a *App) SetRecordExpiry(ctx context.Context, id int, at *time.Time) error {
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return err
+	}
+	if rec.DeletedAt != nil {
+		return fmt.Errorf("%w: запись в корзине", ErrRecordNotFound)
+	}
+
+	current, ok := record.ExpiresAt(rec.Meta)
+	if (at == nil && !ok) || (at != nil && ok && current.Equal(*at)) {
+		return nil
+	}
+
+	meta, err := record.SetExpiresAt(rec.Meta, at)
+	if err != nil {
+		return fmt.Errorf("ошибка изменения метаданных записи: %w", err)
+	}
+
+	return a.updateRecord(ctx, rec, GenericRecordRequest{
+		Type: rec.Type,
+		Data: rec.EncryptedData,
+		Meta: meta,
+	})
+}
+
+// ExpiringRecords возвращает локальные записи, срок действия которых
+// истекает в ближайшие within, включая уже истекшие, начиная с ближайшего
+// срока. RecordID в результате - локальный ID записи.
+func (a *App) ExpiringRecords(within time.Duration) ([]record.Expiration, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{ExpiresBefore: time.Now().Add(within)})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	converted := make([]record.Record, 0, len(records))
+	for _, rec := range records {
+		converted = append(converted, record.Record{ID: rec.ID, Type: rec.Type, Meta: rec.Meta})
+	}
+	return record.Expiring(converted, time.Now().Add(within)), nil
+}
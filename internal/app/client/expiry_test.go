@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_RecordExpiry(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	ctx := context.Background()
+
+	save := func(meta string) int {
+		rec := &LocalRecord{Type: record.RecTypeText, EncryptedData: "encrypted", Meta: json.RawMessage(meta), LastModified: time.Now()}
+		require.NoError(t, app.storage.SaveRecord(rec))
+		return rec.ID
+	}
+	cert := save(`{"title":"TLS certificate"}`)
+	expired := save(`{"title":"Old token","expires_at":"2020-01-01"}`)
+	save(`{"title":"Notes"}`)
+
+	soon := time.Now().Add(10 * 24 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, app.SetRecordExpiry(ctx, cert, &soon))
+	rec, err := app.storage.GetRecord(cert)
+	require.NoError(t, err)
+	at, ok := record.ExpiresAt(rec.Meta)
+	require.True(t, ok)
+	assert.True(t, soon.Equal(at))
+
+	expiring, err := app.ExpiringRecords(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, expiring, 2)
+	assert.Equal(t, expired, expiring[0].RecordID)
+	assert.Equal(t, "TLS certificate", expiring[1].Title)
+
+	expiring, err = app.ExpiringRecords(0)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, expired, expiring[0].RecordID)
+
+	require.NoError(t, app.SetRecordExpiry(ctx, cert, nil))
+	rec, err = app.storage.GetRecord(cert)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"TLS certificate"}`, string(rec.Meta))
+}
@@ -0,0 +1,318 @@
+// internal/app/client/folders.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/record"
+)
+
+// Папки упорядочивают записи в дерево: Work/Cloud/AWS. Папки хранятся на
+// сервере, клиент кэширует их список, чтобы пути были видны без связи с
+// сервером. Запись ссылается на папку через folder_id в открытых метаданных,
+// поэтому перемещение записи синхронизируется как обычное изменение записи.
+
+// FolderInfo - папка с полным путем
+type FolderInfo struct {
+	folder.Folder
+	Path string
+	// Records - число записей непосредственно в папке
+	Records int
+}
+
+// ListFolders возвращает папки, упорядоченные по пути. Список запрашивается
+// с сервера, без связи с ним - из локального кэша.
+func (a *App) ListFolders(ctx context.Context) ([]FolderInfo, error) {
+	folders, err := a.loadFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	records, err := a.storage.ListRecords(&RecordFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения локальных записей: %w", err)
+	}
+	for _, rec := range records {
+		if id := folder.RecordFolder(rec.Meta); id > 0 {
+			counts[id]++
+		}
+	}
+
+	paths := folder.Paths(folders)
+	infos := make([]FolderInfo, 0, len(folders))
+	for _, f := range folders {
+		infos = append(infos, FolderInfo{Folder: f, Path: paths[f.ID], Records: counts[f.ID]})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Path < infos[j].Path })
+	return infos, nil
+}
+
+// CreateFolder создает папку по пути вместе с недостающими родительскими
+// папками: Work/Cloud/AWS
+func (a *App) CreateFolder(ctx context.Context, path string) (*FolderInfo, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	names := splitFolderPath(path)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("не указан путь папки")
+	}
+
+	folders, err := a.fetchFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID *int
+	var current *folder.Folder
+	created := false
+	for _, name := range names {
+		current = findChild(folders, parentID, name)
+		if current == nil {
+			if current, err = a.httpClient.CreateFolder(ctx, name, parentID); err != nil {
+				return nil, fmt.Errorf("ошибка создания папки %s: %w", name, err)
+			}
+			folders = append(folders, *current)
+			created = true
+		}
+		parentID = &current.ID
+	}
+	if !created {
+		return nil, fmt.Errorf("папка %s уже существует", strings.Join(names, folder.Separator))
+	}
+
+	a.cacheFolders(folders)
+	return &FolderInfo{Folder: *current, Path: folder.Paths(folders)[current.ID]}, nil
+}
+
+// RenameFolder переименовывает папку
+func (a *App) RenameFolder(ctx context.Context, path, name string) error {
+	return a.changeFolder(ctx, path, func([]folder.Folder) (*string, *int, error) {
+		return &name, nil, nil
+	})
+}
+
+// MoveFolder переносит папку в parentPath; пустой путь или "/" - на верхний уровень
+func (a *App) MoveFolder(ctx context.Context, path, parentPath string) error {
+	return a.changeFolder(ctx, path, func(folders []folder.Folder) (*string, *int, error) {
+		parentID := 0
+		if len(splitFolderPath(parentPath)) > 0 {
+			parent, err := findFolder(folders, parentPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			parentID = parent.ID
+		}
+		return nil, &parentID, nil
+	})
+}
+
+// DeleteFolder удаляет пустую папку: без вложенных папок и записей
+func (a *App) DeleteFolder(ctx context.Context, path string) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	folders, err := a.fetchFolders(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := findFolder(folders, path)
+	if err != nil {
+		return err
+	}
+
+	if err := a.httpClient.DeleteFolder(ctx, f.ID); err != nil {
+		return fmt.Errorf("ошибка удаления папки: %w", err)
+	}
+	a.refreshFolders(ctx)
+	return nil
+}
+
+// MoveRecord переносит запись в папку path; пустой путь или "/" убирает
+// запись из папок
+func (a *App) MoveRecord(ctx context.Context, id int, path string) error {
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return err
+	}
+	if rec.DeletedAt != nil {
+		return fmt.Errorf("%w: запись в корзине", ErrRecordNotFound)
+	}
+	if record.IsLocked(rec.Meta) {
+		return ErrRecordLocked
+	}
+
+	folderID := 0
+	if len(splitFolderPath(path)) > 0 {
+		folders, err := a.loadFolders(ctx)
+		if err != nil {
+			return err
+		}
+		f, err := findFolder(folders, path)
+		if err != nil {
+			return err
+		}
+		folderID = f.ID
+	}
+	if folder.RecordFolder(rec.Meta) == folderID {
+		return nil
+	}
+
+	meta, err := folder.SetRecordFolder(rec.Meta, folderID)
+	if err != nil {
+		return fmt.Errorf("ошибка изменения метаданных записи: %w", err)
+	}
+
+	return a.updateRecord(ctx, rec, GenericRecordRequest{
+		Type: rec.Type,
+		Data: rec.EncryptedData,
+		Meta: meta,
+	})
+}
+
+// RecordFolderPath возвращает путь папки записи по кэшу папок; пустая
+// строка - запись вне папок или папка неизвестна
+func (a *App) RecordFolderPath(rec *LocalRecord) string {
+	id := folder.RecordFolder(rec.Meta)
+	if id == 0 {
+		return ""
+	}
+	folders, err := a.storage.ListFolders()
+	if err != nil {
+		a.log.Warn("Не удалось прочитать кэш папок", "error", err)
+		return ""
+	}
+	return folder.Paths(folders)[id]
+}
+
+// resolveFolderFilter заполняет ID папок фильтра: папка filter.Folder и
+// вложенные в нее
+func (a *App) resolveFolderFilter(ctx context.Context, filter *RecordFilter) error {
+	if filter == nil || filter.Folder == "" {
+		return nil
+	}
+
+	folders, err := a.loadFolders(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := findFolder(folders, filter.Folder)
+	if err != nil {
+		return err
+	}
+	filter.folderIDs = folder.Subtree(folders, f.ID)
+	return nil
+}
+
+// changeFolder переименовывает или перемещает папку по пути path
+func (a *App) changeFolder(ctx context.Context, path string, change func([]folder.Folder) (name *string, parentID *int, err error)) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	folders, err := a.fetchFolders(ctx)
+	if err != nil {
+		return err
+	}
+	f, err := findFolder(folders, path)
+	if err != nil {
+		return err
+	}
+
+	name, parentID, err := change(folders)
+	if err != nil {
+		return err
+	}
+	if _, err := a.httpClient.UpdateFolder(ctx, f.ID, name, parentID); err != nil {
+		return fmt.Errorf("ошибка изменения папки: %w", err)
+	}
+	a.refreshFolders(ctx)
+	return nil
+}
+
+// loadFolders возвращает папки с сервера, без связи с ним - из кэша
+func (a *App) loadFolders(ctx context.Context) ([]folder.Folder, error) {
+	if a.IsAuthenticated() {
+		folders, err := a.fetchFolders(ctx)
+		if err == nil {
+			return folders, nil
+		}
+		a.log.Warn("Не удалось получить папки с сервера, используется кэш", "error", err)
+	}
+
+	folders, err := a.storage.ListFolders()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кэша папок: %w", err)
+	}
+	return folders, nil
+}
+
+// fetchFolders получает папки с сервера и обновляет кэш
+func (a *App) fetchFolders(ctx context.Context) ([]folder.Folder, error) {
+	folders, err := a.httpClient.ListFolders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения папок: %w", err)
+	}
+	a.cacheFolders(folders)
+	return folders, nil
+}
+
+// refreshFolders обновляет кэш папок после изменения на сервере
+func (a *App) refreshFolders(ctx context.Context) {
+	if _, err := a.fetchFolders(ctx); err != nil {
+		a.log.Warn("Не удалось обновить кэш папок", "error", err)
+	}
+}
+
+func (a *App) cacheFolders(folders []folder.Folder) {
+	if err := a.storage.SaveFolders(folders); err != nil {
+		a.log.Warn("Не удалось сохранить папки в кэш", "error", err)
+	}
+}
+
+// splitFolderPath разбивает путь папки на имена, пропуская пустые: /Work//Cloud/ - [Work Cloud]
+func splitFolderPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, folder.Separator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// findFolder ищет папку по полному пути
+func findFolder(folders []folder.Folder, path string) (*folder.Folder, error) {
+	var parentID *int
+	var current *folder.Folder
+	for _, name := range splitFolderPath(path) {
+		if current = findChild(folders, parentID, name); current == nil {
+			return nil, fmt.Errorf("%w: %s", ErrFolderNotFound, path)
+		}
+		parentID = &current.ID
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFolderNotFound, path)
+	}
+	return current, nil
+}
+
+// findChild ищет папку name в родительской папке parentID; nil - верхний уровень
+func findChild(folders []folder.Folder, parentID *int, name string) *folder.Folder {
+	for i, f := range folders {
+		if f.Name != name {
+			continue
+		}
+		if (parentID == nil && f.ParentID == nil) || (parentID != nil && f.ParentID != nil && *parentID == *f.ParentID) {
+			return &folders[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/record"
+)
+
+// folderServer - папки пользователя на сервере для тестов
+type folderServer struct {
+	mu      gosync.Mutex
+	nextID  int
+	folders map[int]folder.Folder
+}
+
+func (s *folderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/folders")
+	switch {
+	case r.Method == http.MethodGet && rest == "":
+		list := []folder.Folder{}
+		for id := 1; id <= s.nextID; id++ {
+			if f, ok := s.folders[id]; ok {
+				list = append(list, f)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "Ok", "folders": list})
+	case r.Method == http.MethodPost && rest == "":
+		var body struct {
+			Name     string `json:"name"`
+			ParentID *int   `json:"parent_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.nextID++
+		f := folder.Folder{ID: s.nextID, ParentID: body.ParentID, Name: body.Name, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		s.folders[f.ID] = f
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(f)
+	default:
+		var id int
+		if _, err := fmt.Sscanf(rest, "/%d", &id); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f, ok := s.folders[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": "folder not found"})
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(s.folders, id)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "Ok"})
+			return
+		}
+		var body struct {
+			Name     *string `json:"name"`
+			ParentID *int    `json:"parent_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body.Name != nil {
+			f.Name = *body.Name
+		}
+		if body.ParentID != nil {
+			f.ParentID = body.ParentID
+			if *body.ParentID == 0 {
+				f.ParentID = nil
+			}
+		}
+		s.folders[id] = f
+		_ = json.NewEncoder(w).Encode(f)
+	}
+}
+
+func TestApp_Folders(t *testing.T) {
+	server := &folderServer{folders: map[int]folder.Folder{}}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.httpClient.baseURL = srv.URL
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.loggedIn("alice", "token"))
+
+	ctx := context.Background()
+
+	aws, err := app.CreateFolder(ctx, "Work/Cloud/AWS")
+	require.NoError(t, err)
+	assert.Equal(t, "Work/Cloud/AWS", aws.Path)
+	assert.Len(t, server.folders, 3, "родительские папки создаются вместе с вложенной")
+
+	_, err = app.CreateFolder(ctx, "/Work/Cloud/")
+	assert.ErrorContains(t, err, "уже существует")
+
+	_, err = app.CreateFolder(ctx, "Personal")
+	require.NoError(t, err)
+
+	// Записи без сервера: ServerID == 0, поэтому изменения только локальные
+	inAWS := &LocalRecord{Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"aws keys"}`)}
+	inWork := &LocalRecord{Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"vpn"}`)}
+	require.NoError(t, app.storage.SaveRecord(inAWS))
+	require.NoError(t, app.storage.SaveRecord(inWork))
+
+	require.NoError(t, app.MoveRecord(ctx, inAWS.ID, "Work/Cloud/AWS"))
+	require.NoError(t, app.MoveRecord(ctx, inWork.ID, "Work"))
+	assert.ErrorIs(t, app.MoveRecord(ctx, inWork.ID, "Work/Missing"), ErrFolderNotFound)
+
+	moved, err := app.storage.GetRecord(inAWS.ID)
+	require.NoError(t, err)
+	assert.Equal(t, aws.ID, folder.RecordFolder(moved.Meta))
+	assert.Contains(t, string(moved.Meta), `"title":"aws keys"`, "остальные метаданные сохраняются")
+	assert.Equal(t, "Work/Cloud/AWS", app.RecordFolderPath(moved))
+
+	// App.ListRecords после входа запускает синхронизацию, поэтому фильтр
+	// разрешается отдельно и применяется к локальному хранилищу
+	listFolder := func(ctx context.Context, path string) ([]*LocalRecord, error) {
+		filter := &RecordFilter{Folder: path}
+		if err := app.resolveFolderFilter(ctx, filter); err != nil {
+			return nil, err
+		}
+		return app.storage.ListRecords(filter)
+	}
+
+	// Фильтр по папке включает вложенные папки
+	records, err := listFolder(ctx, "Work")
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	records, err = listFolder(ctx, "Work/Cloud")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, inAWS.ID, records[0].ID)
+	_, err = listFolder(ctx, "Nope")
+	assert.ErrorIs(t, err, ErrFolderNotFound)
+
+	// Перемещение и переименование меняют пути вложенных папок
+	require.NoError(t, app.MoveFolder(ctx, "Work/Cloud", "/"))
+	require.NoError(t, app.RenameFolder(ctx, "Cloud", "Infra"))
+
+	folders, err := app.ListFolders(ctx)
+	require.NoError(t, err)
+	var paths []string
+	for _, f := range folders {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"Infra", "Infra/AWS", "Personal", "Work"}, paths)
+	assert.Equal(t, 1, folders[1].Records)
+
+	// Убрать запись из папок
+	require.NoError(t, app.MoveRecord(ctx, inWork.ID, "/"))
+	require.NoError(t, app.DeleteFolder(ctx, "Work"))
+
+	// Без связи с сервером пути берутся из кэша
+	srv.Close()
+	offline, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	records, err = listFolder(offline, "Infra/AWS")
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestApp_MoveRecord_Locked(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+
+	locked := &LocalRecord{Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"codes","locked":true}`)}
+	require.NoError(t, app.storage.SaveRecord(locked))
+
+	assert.ErrorIs(t, app.MoveRecord(context.Background(), locked.ID, "/"), ErrRecordLocked)
+}
@@ -0,0 +1,226 @@
+// internal/app/client/gitcredential.go
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+)
+
+// git credential helper: git передает описание учетных данных строками
+// key=value, а помощник отвечает username и password. Учетные данные - это
+// логины, ресурс которых совпадает с адресом репозитория (протокол и хост),
+// поэтому один логин github.com подходит и браузеру, и git.
+
+// GitCredential - учетные данные в протоколе git credential
+type GitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// ReadGitCredential читает описание учетных данных до пустой строки или конца
+// ввода. Незнакомые атрибуты (capability[], wwwauth[] и т.д.) пропускаются.
+func ReadGitCredential(r io.Reader) (*GitCredential, error) {
+	c := &GitCredential{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, apperr.New(apperr.Invalid, fmt.Sprintf("неверная строка протокола git credential: %q", line))
+		}
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			// Поле url заменяет остальные: git передает его с credential.useHttpPath
+			if err := c.setURL(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения запроса git: %w", err)
+	}
+	return c, nil
+}
+
+func (c *GitCredential) setURL(raw string) error {
+	protocol, rest, ok := strings.Cut(raw, "://")
+	if !ok {
+		return apperr.New(apperr.Invalid, fmt.Sprintf("неверный адрес в запросе git: %q", raw))
+	}
+	c.Protocol = protocol
+	host, path, _ := strings.Cut(rest, "/")
+	if user, h, ok := strings.Cut(host, "@"); ok {
+		c.Username, host = user, h
+	}
+	c.Host, c.Path = host, path
+	return nil
+}
+
+// Write отправляет git найденные учетные данные
+func (c *GitCredential) Write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "username=%s\npassword=%s\n", c.Username, c.Password)
+	return err
+}
+
+// url возвращает адрес, с которым сравниваются ресурсы логинов
+func (c *GitCredential) url() (string, bool) {
+	if c.Host == "" || (c.Protocol != "https" && c.Protocol != "http") {
+		return "", false
+	}
+	return c.Protocol + "://" + c.Host, true
+}
+
+// GitCredentialGet ищет логин для репозитория. Если git уже знает имя
+// пользователя, подходит только логин с этим именем; из нескольких логинов
+// выбирается измененный последним. nil без ошибки - подходящего логина нет,
+// и git спросит учетные данные сам.
+func (a *App) GitCredentialGet(ctx context.Context, c *GitCredential) (*GitCredential, error) {
+	logins, err := a.gitLogins(ctx, c)
+	if err != nil || len(logins) == 0 {
+		return nil, err
+	}
+
+	best := logins[0]
+	return &GitCredential{
+		Protocol: c.Protocol,
+		Host:     c.Host,
+		Path:     c.Path,
+		Username: best.data.Username,
+		Password: best.data.Password,
+	}, nil
+}
+
+// GitCredentialStore сохраняет учетные данные, которые приняла удаленная
+// сторона: создает логин или обновляет пароль логина с тем же именем.
+// Защищенная запись не меняется.
+func (a *App) GitCredentialStore(ctx context.Context, c *GitCredential) error {
+	if c.Username == "" || c.Password == "" {
+		return nil
+	}
+	origin, ok := c.url()
+	if !ok {
+		return nil
+	}
+
+	logins, err := a.gitLogins(ctx, c)
+	if err != nil {
+		return err
+	}
+	if len(logins) > 0 {
+		login := logins[0]
+		if login.data.Password == c.Password || record.IsLocked(login.rec.Meta) {
+			return nil
+		}
+		login.data.Password = c.Password
+		req, err := a.prepareEncryptedRecord(record.RecTypeLogin, login.data, login.rec.Meta)
+		if err != nil {
+			return err
+		}
+		if err := a.UpdateRecord(ctx, login.rec.ID, req); err != nil {
+			return err
+		}
+		a.log.Info("Пароль git обновлен", "record_id", login.rec.ID, "host", c.Host)
+		return nil
+	}
+
+	id, err := a.CreateLoginRecord(ctx, CreateLoginRequest{
+		Username: c.Username,
+		Password: c.Password,
+		Title:    resourceLabel(origin),
+		Resource: strings.ToLower(origin),
+	})
+	if err != nil {
+		return err
+	}
+	a.log.Info("Учетные данные git сохранены", "record_id", id, "host", c.Host)
+	return nil
+}
+
+// GitCredentialErase убирает в корзину логин, который отклонила удаленная
+// сторона. Запись удаляется, только если совпадают имя пользователя и пароль,
+// поэтому одна неудачная попытка с чужим паролем ничего не удалит; защищенная
+// запись не удаляется.
+func (a *App) GitCredentialErase(ctx context.Context, c *GitCredential) error {
+	if c.Username == "" || c.Password == "" {
+		return nil
+	}
+
+	logins, err := a.gitLogins(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, login := range logins {
+		if login.data.Password != c.Password || record.IsLocked(login.rec.Meta) {
+			continue
+		}
+		if err := a.DeleteRecord(ctx, login.rec.ID, false); err != nil {
+			return err
+		}
+		a.log.Info("Отклоненный логин git перемещен в корзину", "record_id", login.rec.ID, "host", c.Host)
+	}
+	return nil
+}
+
+// gitLogin - расшифрованный логин для репозитория
+type gitLogin struct {
+	rec  *LocalRecord
+	data record.LoginData
+}
+
+// gitLogins возвращает логины для адреса из запроса (с именем пользователя из
+// запроса, если оно есть), начиная с измененного последним
+func (a *App) gitLogins(ctx context.Context, c *GitCredential) ([]gitLogin, error) {
+	pageURL, ok := c.url()
+	if !ok {
+		return nil, nil
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	matches, err := a.matchingLogins(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var logins []gitLogin
+	for _, m := range matches {
+		var data record.LoginData
+		if err := a.decryptRecordData(m.rec.EncryptedData, &data); err != nil {
+			a.log.Warn("Не удалось расшифровать логин", "record_id", m.rec.ID, "error", err)
+			continue
+		}
+		if c.Username != "" && data.Username != c.Username {
+			continue
+		}
+		logins = append(logins, gitLogin{rec: m.rec, data: data})
+	}
+
+	sort.SliceStable(logins, func(i, j int) bool {
+		return logins[i].rec.LastModified.After(logins[j].rec.LastModified)
+	})
+	return logins, nil
+}
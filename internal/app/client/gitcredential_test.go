@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// saveTestRecord сохраняет локальную запись с зашифрованными данными
+func saveTestRecord(t *testing.T, app *App, recType record.RecType, meta, data interface{}) int {
+	t.Helper()
+
+	encrypted, err := app.encryptRecordData(data)
+	require.NoError(t, err)
+	metaJSON, err := json.Marshal(meta)
+	require.NoError(t, err)
+	rec := &LocalRecord{Type: recType, EncryptedData: encrypted, Meta: metaJSON, LastModified: time.Now()}
+	require.NoError(t, app.storage.SaveRecord(rec))
+	return rec.ID
+}
+
+func TestReadGitCredential(t *testing.T) {
+	input := "capability[]=authtype\nprotocol=https\nhost=github.com\nusername=alice\nwwwauth[]=Basic realm=\"GitHub\"\n\nignored=1\n"
+	c, err := ReadGitCredential(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, &GitCredential{Protocol: "https", Host: "github.com", Username: "alice"}, c)
+
+	c, err = ReadGitCredential(strings.NewReader("url=https://bob@git.example.com:8443/team/repo.git\n"))
+	require.NoError(t, err)
+	assert.Equal(t, &GitCredential{Protocol: "https", Host: "git.example.com:8443", Path: "team/repo.git", Username: "bob"}, c)
+
+	_, err = ReadGitCredential(strings.NewReader("garbage\n"))
+	assert.Error(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, (&GitCredential{Username: "alice", Password: "s3cret"}).Write(&out))
+	assert.Equal(t, "username=alice\npassword=s3cret\n", out.String())
+}
+
+func TestApp_GitCredential(t *testing.T) {
+	ctx := context.Background()
+	app := newUndoTestApp(t)
+
+	older := saveTestRecord(t, app, record.RecTypeLogin,
+		record.LoginMeta{Title: "GitHub", Resource: "github.com"},
+		record.LoginData{Username: "alice", Password: "old", Notes: "keep"})
+	saveTestRecord(t, app, record.RecTypeLogin,
+		record.LoginMeta{Title: "GitHub bot", Resource: "https://github.com"},
+		record.LoginData{Username: "bot", Password: "token"})
+
+	query := &GitCredential{Protocol: "https", Host: "github.com"}
+
+	t.Run("get prefers the latest login or the requested user", func(t *testing.T) {
+		got, err := app.GitCredentialGet(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, "bot", got.Username)
+
+		got, err = app.GitCredentialGet(ctx, &GitCredential{Protocol: "https", Host: "github.com", Username: "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, "old", got.Password)
+
+		// Ресурс без схемы не подходит http, другие протоколы не обслуживаются
+		for _, c := range []*GitCredential{
+			{Protocol: "http", Host: "github.com"},
+			{Protocol: "ssh", Host: "github.com"},
+			{Protocol: "https", Host: "gitlab.com"},
+		} {
+			got, err = app.GitCredentialGet(ctx, c)
+			require.NoError(t, err)
+			assert.Nil(t, got, c.Protocol+"://"+c.Host)
+		}
+	})
+
+	t.Run("store updates password of the same user", func(t *testing.T) {
+		require.NoError(t, app.GitCredentialStore(ctx, &GitCredential{Protocol: "https", Host: "github.com", Username: "alice", Password: "new"}))
+
+		rec, err := app.storage.GetRecord(older)
+		require.NoError(t, err)
+		var data record.LoginData
+		require.NoError(t, app.decryptRecordData(rec.EncryptedData, &data))
+		assert.Equal(t, record.LoginData{Username: "alice", Password: "new", Notes: "keep"}, data)
+	})
+
+	t.Run("erase moves only the rejected login to trash", func(t *testing.T) {
+		require.NoError(t, app.GitCredentialErase(ctx, &GitCredential{Protocol: "https", Host: "github.com", Username: "alice", Password: "wrong"}))
+		rec, err := app.storage.GetRecord(older)
+		require.NoError(t, err)
+		assert.Nil(t, rec.DeletedAt)
+
+		require.NoError(t, app.GitCredentialErase(ctx, &GitCredential{Protocol: "https", Host: "github.com", Username: "alice", Password: "new"}))
+		rec, err = app.storage.GetRecord(older)
+		require.NoError(t, err)
+		assert.NotNil(t, rec.DeletedAt)
+	})
+}
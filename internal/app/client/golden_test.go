@@ -0,0 +1,214 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Эталоны в testdata фиксируют побайтно строку записи в локальной базе и
+// тела запросов синхронизации. Мастер-ключ и шифротекст записи берутся из
+// эталонов пакета crypto, созданных в его детерминированном режиме, а
+// остальные поля записи заданы в тесте, поэтому результат не зависит от
+// случайности и времени запуска. После намеренного изменения формата
+// эталоны обновляются командой
+//
+//	go test ./internal/app/client -run Golden -update
+
+var updateGolden = flag.Bool("update", false, "перезаписать эталонные файлы в testdata")
+
+const (
+	// cryptoGoldenDir - эталоны пакета crypto
+	cryptoGoldenDir = "crypto/testdata"
+
+	goldenPassword = "golden-password"
+	goldenRecord   = "golden record"
+)
+
+var goldenTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// assertGolden сравнивает данные с эталоном или перезаписывает его с -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "нет эталона, запустите тест с -update")
+	assert.True(t, bytes.Equal(want, got), "%s отличается от эталона:\n%s", name, got)
+}
+
+func readGolden(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+// newGoldenApp возвращает приложение, разблокированное эталонным
+// мастер-ключом пакета crypto, с локальной базой SQLite
+func newGoldenApp(t *testing.T) *App {
+	t.Helper()
+
+	app := newTestApp(t)
+	storage, err := NewSQLiteStorage(filepath.Join(app.config.ConfigDir, "data.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+	app.storage = storage
+
+	require.NoError(t, app.crypto.RestoreKeyFile(readGolden(t, filepath.Join(cryptoGoldenDir, "master_key_v2.golden"))))
+	require.NoError(t, app.crypto.UnlockMasterKey(goldenPassword))
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	return app
+}
+
+// goldenLocalRecord - запись с эталонным шифротекстом и фиксированными полями
+func goldenLocalRecord(t *testing.T) *LocalRecord {
+	t.Helper()
+
+	return &LocalRecord{
+		UUID:          "00000000-0000-4000-8000-000000000001",
+		UserID:        1,
+		Type:          record.RecTypeLogin,
+		EncryptedData: base64.StdEncoding.EncodeToString(readGolden(t, filepath.Join(cryptoGoldenDir, "record_envelope.golden"))),
+		Meta:          json.RawMessage(`{"title":"Golden","resource":"https://example.com/login"}`),
+		Version:       1,
+		LastModified:  goldenTime,
+		Checksum:      "golden-checksum",
+		DeviceID:      "golden-device",
+		CreatedAt:     goldenTime,
+	}
+}
+
+// assertGoldenPlaintext проверяет, что шифротекст записи открывается
+// эталонным мастер-ключом
+func assertGoldenPlaintext(t *testing.T, app *App, encryptedData string) {
+	t.Helper()
+
+	encrypted, err := base64.StdEncoding.DecodeString(encryptedData)
+	require.NoError(t, err)
+	plaintext, err := app.encryptor.DecryptRecord(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, goldenRecord, string(plaintext))
+}
+
+// dumpRecordRow выводит строку таблицы records как есть, по столбцу на строку
+func dumpRecordRow(t *testing.T, db *sql.DB, id int) []byte {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT * FROM records WHERE id = ?`, id)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	require.NoError(t, rows.Scan(dest...))
+
+	var buf strings.Builder
+	for i, column := range columns {
+		value := "NULL"
+		if values[i].Valid {
+			value = values[i].String
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", column, value)
+	}
+	return []byte(buf.String())
+}
+
+func TestGolden_StorageRecord(t *testing.T) {
+	app := newGoldenApp(t)
+	storage := app.storage.(*SQLiteStorage)
+
+	rec := goldenLocalRecord(t)
+	require.NoError(t, storage.SaveRecord(rec))
+	assertGolden(t, "storage_record.golden", dumpRecordRow(t, storage.GetDB(), rec.ID))
+
+	stored, err := storage.GetRecord(rec.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rec.EncryptedData, stored.EncryptedData)
+	assertGoldenPlaintext(t, app, stored.EncryptedData)
+}
+
+func TestGolden_SyncBatch(t *testing.T) {
+	app := newGoldenApp(t)
+
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/sync/batch", r.URL.Path)
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sync.BatchSyncResponse{Status: "Ok", Processed: 1})
+	}))
+	t.Cleanup(ts.Close)
+	app.httpClient.baseURL = ts.URL
+
+	records := toSyncRecords([]*LocalRecord{goldenLocalRecord(t)})
+	_, err := app.httpClient.SendBatchSync(context.Background(), sync.BatchSyncRequest{Records: records})
+	require.NoError(t, err)
+	assertGolden(t, "sync_batch.golden", body)
+}
+
+func TestGolden_SyncChanges(t *testing.T) {
+	app := newGoldenApp(t)
+
+	// Ответ сервера с изменениями тоже фиксируется эталоном: клиент должен
+	// читать его побайтно в том виде, в каком он сохранен в testdata
+	response, err := json.Marshal(sync.GetChangesResponse{
+		Status:      "Ok",
+		Records:     toSyncRecords([]*LocalRecord{goldenLocalRecord(t)}),
+		NextCursor:  "golden-cursor",
+		ServerTime:  goldenTime,
+		SyncVersion: 7,
+	})
+	require.NoError(t, err)
+	assertGolden(t, "sync_changes.golden", response)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(readGolden(t, filepath.Join("testdata", "sync_changes.golden")))
+	}))
+	t.Cleanup(ts.Close)
+	app.httpClient.baseURL = ts.URL
+
+	changes, _, err := app.httpClient.GetSyncChanges(context.Background(), sync.GetChangesRequest{}, "")
+	require.NoError(t, err)
+	require.Len(t, changes.Records, 1)
+	assert.Equal(t, "golden-cursor", changes.NextCursor)
+
+	rec := fromSyncRecord(changes.Records[0])
+	require.NoError(t, app.storage.SaveRecord(rec))
+	stored, err := app.storage.GetRecordByUUID(rec.UUID)
+	require.NoError(t, err)
+	assertGoldenPlaintext(t, app, stored.EncryptedData)
+}
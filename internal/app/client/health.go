@@ -0,0 +1,213 @@
+// internal/app/client/health.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"time"
+)
+
+// Результат проверки сервера кэшируется ненадолго: перед синхронизацией
+// и в интерактивных командах не нужно каждый раз ждать ответа /health.
+// Агент пингует сервер в фоне и сохраняет результат в файл, поэтому
+// короткие команды CLI сразу узнают о недоступном сервере.
+
+const (
+	healthOKTTL        = 30 * time.Second
+	healthFailTTL      = 10 * time.Second
+	healthPingInterval = 30 * time.Second
+	healthPingJitter   = 10 * time.Second
+	healthStatusFile   = "health.json"
+)
+
+// HealthStatus - результат последней проверки сервера
+type HealthStatus struct {
+	OK        bool          `json:"ok"`
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"latency"`
+	CheckedAt time.Time     `json:"checked_at"`
+	// Maintenance - сервер доступен, но в режиме обслуживания не принимает изменения
+	Maintenance *MaintenanceError `json:"maintenance,omitempty"`
+}
+
+// MaintenanceErr возвращает ошибку режима обслуживания, если он еще действует
+func (s *HealthStatus) MaintenanceErr() *MaintenanceError {
+	if s.Maintenance == nil || time.Now().After(s.Maintenance.Until) {
+		return nil
+	}
+	return s.Maintenance
+}
+
+// fresh проверяет, не устарел ли результат проверки
+func (s *HealthStatus) fresh(now time.Time) bool {
+	ttl := healthFailTTL
+	if s.OK {
+		ttl = healthOKTTL
+	}
+	age := now.Sub(s.CheckedAt)
+	return age >= 0 && age < ttl
+}
+
+func (s *HealthStatus) err() error {
+	if s.OK {
+		return nil
+	}
+	return fmt.Errorf("%s (проверено %s назад)", s.Error, time.Since(s.CheckedAt).Round(time.Second))
+}
+
+// healthCache хранит последний результат проверки и не дает выполнять
+// несколько проверок одновременно
+type healthCache struct {
+	mu       gosync.Mutex
+	checking gosync.Mutex
+	status   *HealthStatus
+	// now - источник времени для проверки свежести; nil - time.Now
+	now func() time.Time
+}
+
+func (c *healthCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// CheckConnection проверяет соединение с сервером. Недавний результат
+// берется из кэша (в памяти или от агента).
+func (a *App) CheckConnection(ctx context.Context) error {
+	return a.checkHealth(ctx, false).err()
+}
+
+// ConnectionStatus возвращает результат проверки сервера с учетом кэша
+func (a *App) ConnectionStatus(ctx context.Context, refresh bool) *HealthStatus {
+	return a.checkHealth(ctx, refresh)
+}
+
+func (a *App) checkHealth(ctx context.Context, refresh bool) *HealthStatus {
+	if !refresh {
+		if status := a.cachedHealth(); status != nil {
+			return status
+		}
+	}
+
+	a.health.checking.Lock()
+	defer a.health.checking.Unlock()
+
+	// Пока ждали, проверку мог выполнить другой вызов
+	if !refresh {
+		if status := a.cachedHealth(); status != nil {
+			return status
+		}
+	}
+
+	start := time.Now()
+	err := a.httpClient.HealthCheck(ctx)
+	status := &HealthStatus{
+		OK:        err == nil,
+		Latency:   time.Since(start),
+		CheckedAt: a.health.clock(),
+	}
+	var merr *MaintenanceError
+	if errors.As(err, &merr) {
+		status.OK = true
+		status.Maintenance = merr
+		err = nil
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// Отмена вызывающим не говорит о состоянии сервера
+			return &HealthStatus{Error: err.Error(), CheckedAt: status.CheckedAt}
+		}
+		status.Error = err.Error()
+	}
+
+	a.health.mu.Lock()
+	a.health.status = status
+	a.health.mu.Unlock()
+
+	if err := a.saveHealthStatus(status); err != nil {
+		a.log.Debug("Не удалось сохранить состояние сервера", "error", err)
+	}
+
+	return status
+}
+
+// cachedHealth возвращает свежий результат из памяти или из файла агента
+func (a *App) cachedHealth() *HealthStatus {
+	now := a.health.clock()
+
+	a.health.mu.Lock()
+	defer a.health.mu.Unlock()
+
+	if a.health.status != nil && a.health.status.fresh(now) {
+		return a.health.status
+	}
+
+	status, err := a.loadHealthStatus()
+	if err != nil || !status.fresh(now) {
+		return nil
+	}
+	a.health.status = status
+	return status
+}
+
+func (a *App) healthStatusPath() string {
+	return filepath.Join(a.config.ConfigDir, healthStatusFile)
+}
+
+func (a *App) loadHealthStatus() (*HealthStatus, error) {
+	data, err := os.ReadFile(a.healthStatusPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var status HealthStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (a *App) saveHealthStatus(status *HealthStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	// Пишем через временный файл, чтобы CLI не прочитал его наполовину
+	tmp := a.healthStatusPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.healthStatusPath())
+}
+
+// runHealthPinger периодически проверяет сервер. Интервал сдвигается на
+// случайную величину, чтобы клиенты не обращались к серверу одновременно.
+func (a *App) runHealthPinger(ctx context.Context) {
+	timer := time.NewTimer(healthPingDelay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			status := a.checkHealth(ctx, true)
+			if !status.OK && ctx.Err() == nil {
+				a.log.Debug("Сервер недоступен", "error", status.Error)
+			}
+			timer.Reset(healthPingDelay())
+		}
+	}
+}
+
+func healthPingDelay() time.Duration {
+	return healthPingInterval - healthPingJitter + rand.N(2*healthPingJitter)
+}
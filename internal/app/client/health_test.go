@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHealthApp возвращает приложение, которое проверяет сервер handler,
+// с управляемыми часами кэша; hits считает запросы /health
+func newHealthApp(t *testing.T, handler http.HandlerFunc) (*App, *time.Time, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/health" {
+			hits.Add(1)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newTestApp(t)
+	app.httpClient.baseURL = ts.URL
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	app.health.now = func() time.Time { return now }
+	return app, &now, &hits
+}
+
+func TestHealthStatus_Fresh(t *testing.T) {
+	checked := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ok := &HealthStatus{OK: true, CheckedAt: checked}
+	failed := &HealthStatus{Error: "connection refused", CheckedAt: checked}
+
+	assert.True(t, ok.fresh(checked))
+	assert.True(t, ok.fresh(checked.Add(healthOKTTL-time.Second)))
+	assert.False(t, ok.fresh(checked.Add(healthOKTTL)))
+	assert.True(t, failed.fresh(checked.Add(healthFailTTL-time.Second)))
+	assert.False(t, failed.fresh(checked.Add(healthFailTTL)))
+	// Результат из будущего (часы переведены назад) не считается свежим
+	assert.False(t, ok.fresh(checked.Add(-time.Second)))
+}
+
+func TestApp_CheckConnection_Cache(t *testing.T) {
+	ctx := context.Background()
+	app, now, hits := newHealthApp(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	require.NoError(t, app.CheckConnection(ctx))
+	require.NoError(t, app.CheckConnection(ctx))
+	assert.Equal(t, int32(1), hits.Load(), "свежий результат берется из кэша")
+
+	// Результат агента из файла видят другие процессы клиента
+	cli := newTestApp(t)
+	cli.config.ConfigDir = app.config.ConfigDir
+	cli.httpClient.baseURL = app.httpClient.baseURL
+	cli.health.now = app.health.now
+	require.NoError(t, cli.CheckConnection(ctx))
+	assert.Equal(t, int32(1), hits.Load(), "результат берется из файла агента")
+
+	*now = now.Add(healthOKTTL)
+	require.NoError(t, app.CheckConnection(ctx))
+	assert.Equal(t, int32(2), hits.Load(), "устаревший результат проверяется заново")
+
+	status := app.ConnectionStatus(ctx, true)
+	assert.True(t, status.OK)
+	assert.Equal(t, int32(3), hits.Load(), "refresh проверяет сервер в обход кэша")
+}
+
+func TestApp_CheckConnection_DeadServer(t *testing.T) {
+	ctx := context.Background()
+	// Сервер принимает соединение, но не отвечает: проверка ждет таймаута
+	app, now, hits := newHealthApp(t, func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	app.config.HealthTimeout = 200 * time.Millisecond
+
+	start := time.Now()
+	require.Error(t, app.CheckConnection(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), app.config.HealthTimeout)
+
+	start = time.Now()
+	err := app.CheckConnection(ctx)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), app.config.HealthTimeout, "недоступный сервер не ждется повторно")
+	assert.Equal(t, int32(1), hits.Load())
+
+	*now = now.Add(healthFailTTL)
+	require.Error(t, app.CheckConnection(ctx))
+	assert.Equal(t, int32(2), hits.Load(), "после healthFailTTL сервер проверяется снова")
+}
+
+func TestHealthPingDelay(t *testing.T) {
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		d := healthPingDelay()
+		assert.GreaterOrEqual(t, d, healthPingInterval-healthPingJitter)
+		assert.Less(t, d, healthPingInterval+healthPingJitter)
+		seen[d] = true
+	}
+	assert.Greater(t, len(seen), 1, "интервал пинга сдвигается случайно")
+}
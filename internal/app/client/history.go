@@ -0,0 +1,129 @@
+// internal/app/client/history.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// История хранится на сервере: при каждом обновлении сервер сохраняет прежнее
+// состояние записи в record_versions. Восстановление не откатывает историю,
+// а записывает содержимое старой версии как новую версию.
+
+// RecordVersion - версия записи в истории
+type RecordVersion struct {
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Checksum  string    `json:"checksum,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+}
+
+// RecordHistory возвращает версии записи, начиная с текущей
+func (a *App) RecordHistory(ctx context.Context, id int) ([]RecordVersion, error) {
+	localRec, err := a.historyRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := a.httpClient.GetRecordVersions(ctx, localRec.ServerRef())
+	if err != nil {
+		return nil, err
+	}
+
+	history := []RecordVersion{{
+		Version:   localRec.Version,
+		Title:     buildRecordPreview(localRec.Type, localRec.Meta).Title,
+		Checksum:  localRec.Checksum,
+		CreatedAt: localRec.LastModified,
+		Current:   true,
+	}}
+	for _, v := range versions {
+		if v.Version >= localRec.Version {
+			continue
+		}
+		history = append(history, RecordVersion{
+			Version:   v.Version,
+			Title:     buildRecordPreview(localRec.Type, v.Meta).Title,
+			Checksum:  v.Checksum,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Version > history[j].Version })
+
+	return history, nil
+}
+
+// RestoreRecordVersion записывает содержимое версии version как новую версию записи.
+// Возвращает номер новой версии.
+func (a *App) RestoreRecordVersion(ctx context.Context, id, version int) (int, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return 0, ErrMasterKeyLocked
+	}
+
+	localRec, err := a.historyRecord(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !localRec.Synced {
+		return 0, fmt.Errorf("у записи %d есть несинхронизированные изменения. Сначала выполните: gophkeeper sync", id)
+	}
+	if version == localRec.Version {
+		return 0, fmt.Errorf("версия %d уже текущая", version)
+	}
+
+	versions, err := a.httpClient.GetRecordVersions(ctx, localRec.ServerRef())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range versions {
+		if v.Version != version || v.Version > localRec.Version {
+			continue
+		}
+
+		// Версия могла быть зашифрована другим ключом - проверяем до записи
+		var data interface{}
+		if err := a.decryptRecordData(v.EncryptedData, &data); err != nil {
+			return 0, fmt.Errorf("не удалось расшифровать версию %d: %w", version, err)
+		}
+
+		meta, err := keepAnnotations(localRec.Meta, v.Meta)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка переноса пометок записи: %w", err)
+		}
+
+		req := GenericRecordRequest{
+			Type: localRec.Type,
+			Data: v.EncryptedData,
+			Meta: meta,
+		}
+		if err := a.UpdateRecord(ctx, id, req); err != nil {
+			return 0, err
+		}
+
+		a.log.Info("Версия записи восстановлена", "record_id", id, "from_version", version)
+		return localRec.Version + 1, nil
+	}
+
+	return 0, fmt.Errorf("версия %d записи %d не найдена", version, id)
+}
+
+// historyRecord возвращает актуальную запись, история которой есть на сервере
+func (a *App) historyRecord(ctx context.Context, id int) (*LocalRecord, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("история версий хранится на сервере, требуется аутентификация")
+	}
+
+	localRec, _, err := a.RefreshRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if localRec.ServerID == 0 {
+		return nil, fmt.Errorf("запись %d еще не синхронизирована, истории версий нет", id)
+	}
+
+	return localRec, nil
+}
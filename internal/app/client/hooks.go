@@ -0,0 +1,273 @@
+// internal/app/client/hooks.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// События, на которые можно подписать хуки
+const (
+	HookEventPostSync         = "post-sync"
+	HookEventConflictDetected = "conflict-detected"
+	HookEventRecordCreated    = "record-created"
+	// HookEventPreUpload - проверка файла до шифрования и загрузки. В отличие
+	// от других событий, ненулевой код хука отменяет создание записи.
+	HookEventPreUpload = "pre-upload"
+
+	// hookEventAny - хук вызывается для любого события
+	hookEventAny = "*"
+
+	defaultHookTimeout = 10 * time.Second
+	hooksFileName      = "hooks.json"
+)
+
+// HookConfig описание одного хука из hooks.json
+type HookConfig struct {
+	Event          string `json:"event"`   // post-sync, conflict-detected, record-created, pre-upload или *
+	Command        string `json:"command"` // выполняется через sh -c (cmd /C на Windows)
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+// HooksFile содержимое файла hooks.json
+type HooksFile struct {
+	Hooks []HookConfig `json:"hooks"`
+}
+
+// HookEvent событие, которое передается хуку в stdin в виде JSON.
+// Секретные данные записей в событие никогда не попадают.
+type HookEvent struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// RecordCreatedEvent данные события record-created
+type RecordCreatedEvent struct {
+	ID     int    `json:"id"`
+	Type   string `json:"type"`
+	Title  string `json:"title,omitempty"`
+	Synced bool   `json:"synced"`
+}
+
+// PreUploadEvent данные события pre-upload. Содержимое файла передается
+// не в событии, а во временном файле по пути из GOPHKEEPER_FILE.
+type PreUploadEvent struct {
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+	Title       string `json:"title,omitempty"`
+}
+
+// ConflictDetectedEvent данные события conflict-detected
+type ConflictDetectedEvent struct {
+	RecordID     int    `json:"record_id"`
+	RecordType   string `json:"record_type,omitempty"`
+	Title        string `json:"title,omitempty"`
+	ConflictType string `json:"conflict_type"`
+	Strategy     string `json:"strategy"`
+}
+
+// HookRunner запускает внешние команды при событиях клиента
+type HookRunner struct {
+	log   *slog.Logger
+	hooks []HookConfig
+}
+
+// NewHookRunner загружает хуки из <configDir>/hooks.json.
+// Отсутствие файла не является ошибкой - хуки просто не вызываются.
+func NewHookRunner(configDir string, log *slog.Logger) *HookRunner {
+	runner := &HookRunner{log: log}
+
+	hooks, err := loadHooks(filepath.Join(configDir, hooksFileName))
+	if err != nil {
+		log.Warn("Не удалось загрузить хуки", "error", err)
+		return runner
+	}
+	runner.hooks = hooks
+
+	return runner
+}
+
+func loadHooks(path string) ([]HookConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения %s: %w", path, err)
+	}
+
+	var file HooksFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга %s: %w", path, err)
+	}
+
+	hooks := make([]HookConfig, 0, len(file.Hooks))
+	for i, hook := range file.Hooks {
+		if hook.Command == "" {
+			return nil, fmt.Errorf("хук #%d: команда не указана", i+1)
+		}
+		switch hook.Event {
+		case HookEventPostSync, HookEventConflictDetected, HookEventRecordCreated, HookEventPreUpload, hookEventAny:
+		default:
+			return nil, fmt.Errorf("хук #%d: неизвестное событие %q", i+1, hook.Event)
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, nil
+}
+
+// Fire вызывает все хуки, подписанные на событие.
+// Хуки выполняются последовательно; ошибки только логируются и не прерывают работу клиента.
+func (r *HookRunner) Fire(ctx context.Context, event string, data interface{}) {
+	if r == nil || len(r.hooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(HookEvent{
+		Event:     event,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		r.log.Warn("Ошибка сериализации события для хука", "event", event, "error", err)
+		return
+	}
+
+	for _, hook := range r.hooks {
+		if hook.Event != event && hook.Event != hookEventAny {
+			continue
+		}
+		if err := r.run(ctx, hook, event, payload, nil); err != nil {
+			r.log.Warn("Ошибка выполнения хука", "event", event, "command", hook.Command, "error", err)
+		}
+	}
+}
+
+// Has сообщает, есть ли хуки, подписанные именно на событие event
+func (r *HookRunner) Has(event string) bool {
+	if r == nil {
+		return false
+	}
+	for _, hook := range r.hooks {
+		if hook.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Check вызывает хуки, подписанные именно на событие event (хуки "*" не
+// вызываются), с дополнительными переменными окружения env. Первая ошибка
+// хука прерывает проверку и возвращается вызывающему.
+func (r *HookRunner) Check(ctx context.Context, event string, data interface{}, env ...string) error {
+	if !r.Has(event) {
+		return nil
+	}
+
+	payload, err := json.Marshal(HookEvent{
+		Event:     event,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события для хука: %w", err)
+	}
+
+	for _, hook := range r.hooks {
+		if hook.Event != event {
+			continue
+		}
+		if err := r.run(ctx, hook, event, payload, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *HookRunner) run(ctx context.Context, hook HookConfig, event string, payload []byte, env []string) error {
+	timeout := defaultHookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	}
+
+	var output bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = append(os.Environ(), "GOPHKEEPER_EVENT="+event)
+	cmd.Env = append(cmd.Env, env...)
+	// Дочерние процессы команды могут держать stdout открытым после
+	// завершения оболочки по таймауту - не ждем их дольше секунды
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("превышено время ожидания %s", timeout)
+		}
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(output.Bytes()))
+	}
+
+	r.log.Debug("Хук выполнен", "event", event, "command", hook.Command, "output", output.String())
+	return nil
+}
+
+// fireRecordCreated уведомляет хуки о создании записи
+func (a *App) fireRecordCreated(ctx context.Context, id int, recType string, title string, synced bool) {
+	a.hooks.Fire(ctx, HookEventRecordCreated, RecordCreatedEvent{
+		ID:     id,
+		Type:   recType,
+		Title:  title,
+		Synced: synced,
+	})
+}
+
+// scanUpload передает файл хукам pre-upload до шифрования: содержимое
+// записывается во временный файл с правами 0600, путь к нему - в
+// GOPHKEEPER_FILE. Файл удаляется сразу после проверки.
+func (a *App) scanUpload(ctx context.Context, event PreUploadEvent, content []byte) error {
+	if !a.hooks.Has(HookEventPreUpload) {
+		return nil
+	}
+
+	file, err := os.CreateTemp("", "gophkeeper-upload-*"+filepath.Ext(event.Filename))
+	if err != nil {
+		return fmt.Errorf("ошибка создания временного файла для проверки: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка записи временного файла для проверки: %w", err)
+	}
+
+	if err := a.hooks.Check(ctx, HookEventPreUpload, event, "GOPHKEEPER_FILE="+file.Name()); err != nil {
+		a.log.Warn("Файл отклонен проверкой перед загрузкой", "filename", event.Filename, "error", err)
+		return fmt.Errorf("%w: %v", ErrUploadRejected, err)
+	}
+	return nil
+}
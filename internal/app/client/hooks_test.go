@@ -0,0 +1,175 @@
+// internal/app/client/hooks_test.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestLoadHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), hooksFileName)
+	require.NoError(t, os.WriteFile(path, []byte(`{"hooks": [
+		{"event": "pre-upload", "command": "clamscan --no-summary \"$GOPHKEEPER_FILE\""},
+		{"event": "*", "command": "logger gophkeeper"}
+	]}`), 0600))
+
+	hooks, err := loadHooks(path)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+	assert.Equal(t, HookEventPreUpload, hooks[0].Event)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"hooks": [{"event": "pre-download", "command": "true"}]}`), 0600))
+	_, err = loadHooks(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"hooks": [{"event": "post-sync"}]}`), 0600))
+	_, err = loadHooks(path)
+	assert.ErrorContains(t, err, "команда не указана")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"hooks": `), 0600))
+	_, err = loadHooks(path)
+	assert.Error(t, err)
+
+	// Без hooks.json хуков нет, и это не ошибка
+	hooks, err = loadHooks(filepath.Join(t.TempDir(), hooksFileName))
+	require.NoError(t, err)
+	assert.Empty(t, hooks)
+}
+
+func TestNewHookRunner_InvalidFile(t *testing.T) {
+	app := newTestApp(t)
+	require.NoError(t, os.WriteFile(filepath.Join(app.config.ConfigDir, hooksFileName), []byte(`not json`), 0600))
+
+	// Испорченный файл хуков не мешает работе клиента
+	runner := NewHookRunner(app.config.ConfigDir, app.log)
+	require.NotNil(t, runner)
+	assert.False(t, runner.Has(HookEventPostSync))
+	runner.Fire(context.Background(), HookEventPostSync, nil)
+}
+
+// readHookEvent читает событие, которое хук сохранил из stdin
+func readHookEvent(t *testing.T, path string) (HookEvent, map[string]interface{}) {
+	t.Helper()
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var event HookEvent
+	require.NoError(t, json.Unmarshal(raw, &event))
+	data, _ := event.Data.(map[string]interface{})
+	return event, data
+}
+
+func TestHookRunner_Fire(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in the test use sh")
+	}
+
+	app := newTestApp(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+	syncOut := filepath.Join(dir, "post-sync.json")
+	anyOut := filepath.Join(dir, "any.log")
+
+	app.hooks = &HookRunner{log: app.log, hooks: []HookConfig{
+		{Event: HookEventPostSync, Command: "exit 3"},
+		{Event: HookEventPostSync, Command: "cat > " + syncOut},
+		{Event: hookEventAny, Command: `echo "$GOPHKEEPER_EVENT" >> ` + anyOut},
+		{Event: HookEventConflictDetected, Command: "sleep 5", TimeoutSeconds: 1},
+	}}
+
+	// Ошибка первого хука не мешает вызвать следующие
+	app.hooks.Fire(ctx, HookEventPostSync, &SyncResult{Uploaded: 2, Downloaded: 1})
+	event, data := readHookEvent(t, syncOut)
+	assert.Equal(t, HookEventPostSync, event.Event)
+	assert.False(t, event.Timestamp.IsZero())
+	assert.EqualValues(t, 2, data["uploaded"])
+	assert.EqualValues(t, 1, data["downloaded"])
+
+	// Зависший хук прерывается по таймауту
+	start := time.Now()
+	app.hooks.Fire(ctx, HookEventConflictDetected, ConflictDetectedEvent{RecordID: 7, ConflictType: "update_update"})
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	app.hooks.Fire(ctx, HookEventRecordCreated, RecordCreatedEvent{ID: 1})
+
+	events, err := os.ReadFile(anyOut)
+	require.NoError(t, err)
+	assert.Equal(t, "post-sync\nconflict-detected\nrecord-created\n", string(events))
+
+	// Без хуков и у nil-runner Fire ничего не делает
+	var none *HookRunner
+	none.Fire(ctx, HookEventPostSync, nil)
+}
+
+func TestApp_SaveLocalRecord_FiresRecordCreated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in the test use sh")
+	}
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	out := filepath.Join(t.TempDir(), "event.json")
+	app.hooks = &HookRunner{log: app.log, hooks: []HookConfig{{Event: HookEventRecordCreated, Command: "cat > " + out}}}
+
+	id, err := app.saveLocalRecord(context.Background(), record.RecTypeLogin, CreateLoginRequest{
+		Title:    "GitHub",
+		Username: "alice",
+		Password: "s3cr3t-password",
+	})
+	require.NoError(t, err)
+
+	event, data := readHookEvent(t, out)
+	assert.Equal(t, HookEventRecordCreated, event.Event)
+	assert.EqualValues(t, id, data["id"])
+	assert.Equal(t, "login", data["type"])
+	assert.Equal(t, "GitHub", data["title"])
+	assert.Equal(t, false, data["synced"])
+
+	// Секретные данные записи в событие не попадают
+	raw, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "s3cr3t-password")
+	assert.NotContains(t, string(raw), "alice")
+}
+
+func TestApp_ScanUpload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands in the test use sh")
+	}
+
+	app := newTestApp(t)
+	ctx := context.Background()
+	event := PreUploadEvent{Filename: "report.pdf", Size: 5}
+
+	// Без хуков pre-upload файл не проверяется; хук "*" проверку не выполняет
+	app.hooks = &HookRunner{log: app.log, hooks: []HookConfig{{Event: hookEventAny, Command: "exit 1"}}}
+	require.NoError(t, app.scanUpload(ctx, event, []byte("clean")))
+
+	seen := filepath.Join(t.TempDir(), "seen")
+	app.hooks = &HookRunner{log: app.log, hooks: []HookConfig{{
+		Event:   HookEventPreUpload,
+		Command: `echo "$GOPHKEEPER_FILE" > ` + seen + `; if grep -q EICAR "$GOPHKEEPER_FILE"; then echo "infected: EICAR"; exit 1; fi`,
+	}}}
+
+	require.NoError(t, app.scanUpload(ctx, event, []byte("clean")))
+
+	err := app.scanUpload(ctx, event, []byte("X5O EICAR test"))
+	assert.ErrorIs(t, err, ErrUploadRejected)
+	assert.Contains(t, err.Error(), "infected: EICAR")
+
+	// Временный файл с содержимым удаляется после проверки
+	path, err := os.ReadFile(seen)
+	require.NoError(t, err)
+	assert.Equal(t, ".pdf", filepath.Ext(string(path[:len(path)-1])))
+	assert.NoFileExists(t, string(path[:len(path)-1]))
+}
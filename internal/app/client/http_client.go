@@ -3,18 +3,37 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/exp/slog"
 
 	"gophkeeper/internal/app/client/config"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
 	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/settings"
+	"gophkeeper/internal/domain/stats"
 	"gophkeeper/internal/domain/sync"
 	"gophkeeper/internal/domain/user"
+	"gophkeeper/internal/domain/viewer"
 )
 
 const (
@@ -28,18 +47,60 @@ type httpClient struct {
 	config    *config.Config
 	log       *slog.Logger
 	baseURL   string
-	token     string
 	userAgent string
+
+	tokenMu gosync.RWMutex
+	token   string
+
+	// gzipRequests - сервер объявил Accept-Encoding: gzip, и тела запросов можно сжимать
+	gzipRequests atomic.Bool
+	// lists - последние ответы списков с ETag для условных запросов
+	lists conditionalCache
+	// syncV2 - операции синхронизации идут по протоколу v2 (/api/v2/sync)
+	syncV2 atomic.Bool
+	// deviceID - UUID устройства для заголовка X-Device-ID
+	deviceID atomic.Value
+	// deviceSource загружает UUID устройства, если он еще не задан
+	deviceSource func() string
+	// deviceKey возвращает ключ устройства для подписи запросов; nil - запросы
+	// не подписываются, и сервер с подтверждением устройств не даст доступ к данным
+	deviceKey func() ed25519.PrivateKey
+	// meta запечатывает и открывает метаданные личных записей; nil - метаданные
+	// передаются как есть
+	meta metaCodec
 }
 
+// operationClass - класс операции, от которого зависит таймаут запроса
+type operationClass int
+
+const (
+	opHealth   operationClass = iota // проверка доступности сервера
+	opRequest                        // обычный запрос API
+	opTransfer                       // бинарные данные, списки записей, синхронизация
+)
+
 func newHTTPClient(cfg *config.Config, log *slog.Logger) (*httpClient, error) {
+	dialer := &net.Dialer{
+		Timeout:   cfg.ConnectTimeout,
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Общего таймаута у клиента нет: время запроса ограничивается
+	// контекстом в зависимости от класса операции
 	client := &http.Client{
-		Timeout: 30 * time.Second,
 		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSClientConfig:     tlsConfig,
+			TLSHandshakeTimeout: cfg.ConnectTimeout,
 			MaxIdleConns:        100,
 			IdleConnTimeout:     90 * time.Second,
 			DisableCompression:  false,
-			DisableKeepAlives:   false,
+			DisableKeepAlives:   cfg.KeepAlive == 0,
 			MaxIdleConnsPerHost: 10,
 		},
 	}
@@ -56,49 +117,153 @@ func newHTTPClient(cfg *config.Config, log *slog.Logger) (*httpClient, error) {
 		config:    cfg,
 		log:       log,
 		baseURL:   baseURL,
-		userAgent: "GophKeeper-Client/1.0",
+		userAgent: "GophKeeper-Client/" + Version,
 	}, nil
 }
 
 // SetToken устанавливает токен аутентификации
 func (h *httpClient) SetToken(token string) {
+	h.tokenMu.Lock()
+	defer h.tokenMu.Unlock()
 	h.token = token
 }
 
+func (h *httpClient) authToken() string {
+	h.tokenMu.RLock()
+	defer h.tokenMu.RUnlock()
+	return h.token
+}
+
+// SetDeviceID задает UUID устройства, который отправляется с каждым запросом:
+// по нему сервер учитывает трафик и операции устройства
+func (h *httpClient) SetDeviceID(id string) {
+	h.deviceID.Store(id)
+}
+
+// signDeviceRequest подписывает запрос с телом body ключом устройства id:
+// заголовку X-Device-ID без подписи сервер не верит. Подписывается тело до
+// сжатия - сервер проверяет подпись по распакованному телу.
+func (h *httpClient) signDeviceRequest(req *http.Request, id string, body []byte) {
+	if h.deviceKey == nil {
+		return
+	}
+	key := h.deviceKey()
+	if key == nil {
+		return
+	}
+	timestamp, signature := sync.SignDeviceRequest(key, req.Method, req.URL.RequestURI(), id, body, time.Now())
+	req.Header.Set(sync.DeviceTimestampHeader, timestamp)
+	req.Header.Set(sync.DeviceSignatureHeader, signature)
+}
+
 // setAuthToken устанавливает токен аутентификации (alias для SetToken)
 func (h *httpClient) setAuthToken(token string) {
-	h.token = token
+	h.SetToken(token)
+}
+
+// timeout возвращает таймаут запроса для класса операции
+func (h *httpClient) timeout(op operationClass) time.Duration {
+	switch op {
+	case opHealth:
+		return h.config.HealthTimeout
+	case opTransfer:
+		return h.config.TransferTimeout
+	default:
+		return h.config.RequestTimeout
+	}
+}
+
+// withTimeout ограничивает контекст таймаутом класса операции.
+// Если у родительского контекста срок короче, используется он.
+func (h *httpClient) withTimeout(ctx context.Context, op operationClass) (context.Context, context.CancelFunc) {
+	if d := h.timeout(op); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// cancelOnClose освобождает контекст запроса после чтения тела ответа
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// healthResponse - ответ /api/v1/health
+type healthResponse struct {
+	Status      string `json:"status"`
+	Maintenance *struct {
+		Message    string `json:"message"`
+		RetryAfter int    `json:"retry_after_seconds"`
+	} `json:"maintenance,omitempty"`
 }
 
-// HealthCheck проверяет доступность сервера
+// HealthCheck проверяет доступность сервера. Если сервер в режиме
+// обслуживания, возвращает *MaintenanceError.
 func (h *httpClient) HealthCheck(ctx context.Context) error {
+	ctx, cancel := h.withTimeout(ctx, opHealth)
+	defer cancel()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", h.baseURL+"/api/v1/health", nil)
 	if err != nil {
 		return fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
 	req.Header.Set("User-Agent", h.userAgent)
+	setVersionHeader(req)
 
 	resp, err := h.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("сервер недоступен: %w", err)
+		return fmt.Errorf("%w: %w", ErrServerUnavailable, err)
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(resp.Body)
 
+	if uerr := upgradeFromResponse(resp); uerr != nil {
+		return uerr
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("сервер вернул статус: %d", resp.StatusCode)
 	}
 
+	var health healthResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&health); err == nil && health.Maintenance != nil {
+		return newMaintenanceError(health.Maintenance.Message,
+			time.Duration(health.Maintenance.RetryAfter)*time.Second)
+	}
+
 	return nil
 }
 
 func (h *httpClient) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
-	return h.doRequestWithRetry(ctx, method, path, body, maxRetries)
+	return h.doRequestWithRetry(ctx, opRequest, method, path, body, nil, maxRetries)
+}
+
+// doTransfer выполняет запрос с большим телом запроса или ответа
+func (h *httpClient) doTransfer(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	return h.doRequestWithRetry(ctx, opTransfer, method, path, body, nil, maxRetries)
+}
+
+// doConditional выполняет doTransfer с If-None-Match: если ответ не изменился
+// с прошлого раза, сервер возвращает 304 без тела
+func (h *httpClient) doConditional(ctx context.Context, method, path string, body interface{}, etag string) (*http.Response, error) {
+	var header http.Header
+	if etag != "" {
+		header = http.Header{"If-None-Match": {etag}}
+	}
+	return h.doRequestWithRetry(ctx, opTransfer, method, path, body, header, maxRetries)
 }
 
-func (h *httpClient) doRequestWithRetry(ctx context.Context, method, path string, body interface{}, retries int) (*http.Response, error) {
+// doRequestWithRetry выполняет запрос с повторами. Таймаут класса операции
+// действует на каждую попытку отдельно, включая чтение тела ответа.
+// header - дополнительные заголовки запроса, может быть nil.
+func (h *httpClient) doRequestWithRetry(ctx context.Context, op operationClass, method, path string, body interface{}, header http.Header, retries int) (*http.Response, error) {
 	var lastErr error
 	delay := retryDelay
 
@@ -123,25 +288,51 @@ func (h *httpClient) doRequestWithRetry(ctx context.Context, method, path string
 		}
 
 		var reqBody io.Reader
+		var jsonData []byte
+		compressed := false
 		if body != nil {
-			jsonData, err := json.Marshal(body)
-			if err != nil {
+			var err error
+			if jsonData, err = json.Marshal(body); err != nil {
 				return nil, fmt.Errorf("ошибка маршалинга тела запроса: %w", err)
 			}
-			reqBody = bytes.NewBuffer(jsonData)
+			wireData := jsonData
+			if compressed = h.gzipRequests.Load() && len(jsonData) >= gzipMinSize; compressed {
+				if wireData, err = gzipBytes(jsonData); err != nil {
+					return nil, fmt.Errorf("ошибка сжатия тела запроса: %w", err)
+				}
+			}
+			reqBody = bytes.NewBuffer(wireData)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, h.baseURL+path, reqBody)
+		attemptCtx, cancel := h.withTimeout(ctx, op)
+		req, err := http.NewRequestWithContext(attemptCtx, method, h.baseURL+path, reqBody)
 		if err != nil {
+			cancel()
 			return nil, fmt.Errorf("ошибка создания запроса: %w", err)
 		}
 
-		// Добавляем заголовки
+		// Добавляем заголовки. Accept-Encoding не задаем: тогда http.Transport
+		// сам запрашивает gzip и прозрачно распаковывает ответ
+		for name, values := range header {
+			req.Header[name] = values
+		}
 		req.Header.Set("Content-Type", "application/json")
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
 		req.Header.Set("User-Agent", h.userAgent)
-		h.log.Debug("token", h.token)
-		if h.token != "" {
-			req.Header.Set("Authorization", "Bearer "+h.token)
+		setVersionHeader(req)
+		id, _ := h.deviceID.Load().(string)
+		if id == "" && h.deviceSource != nil {
+			id = h.deviceSource()
+		}
+		if id != "" {
+			req.Header.Set(sync.DeviceHeader, id)
+			h.signDeviceRequest(req, id, jsonData)
+		}
+		token := h.authToken()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
 		}
 
 		h.log.Debug("Отправка запроса",
@@ -152,24 +343,59 @@ func (h *httpClient) doRequestWithRetry(ctx context.Context, method, path string
 
 		resp, err := h.client.Do(req)
 		if err != nil {
-			lastErr = err
+			cancel()
+			lastErr = fmt.Errorf("%w: %w", ErrServerUnavailable, err)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				lastErr = fmt.Errorf("%w: таймаут запроса (%s): %w", ErrServerUnavailable, h.timeout(op), err)
+			}
 			h.log.Warn("Ошибка выполнения запроса",
 				"error", err,
 				"attempt", attempt+1,
 			)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		h.noteRequestEncoding(resp)
+
+		// Сервер не принимает сжатые запросы (RFC 7694): повторяем без сжатия
+		if compressed && resp.StatusCode == http.StatusUnsupportedMediaType {
+			_ = resp.Body.Close()
+			h.gzipRequests.Store(false)
+			lastErr = fmt.Errorf("сервер не принимает сжатые запросы")
 			continue
 		}
 
+		// Клиент устарел: повтор не поможет, нужно обновление
+		if uerr := upgradeFromResponse(resp); uerr != nil {
+			_ = resp.Body.Close()
+			return nil, uerr
+		}
+
+		// Устройство не подтверждено или не зарегистрировано: повтор не поможет
+		if derr := deviceTrustFromResponse(resp); derr != nil {
+			_ = resp.Body.Close()
+			return nil, derr
+		}
+
 		// Проверяем статус код - некоторые ошибки не требуют retry
 		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
 			// Клиентские ошибки (4xx) не требуют retry
 			return resp, nil
 		}
 
+		// Режим обслуживания: повторять запрос до Retry-After бессмысленно
+		if merr := maintenanceFromResponse(resp); merr != nil {
+			_ = resp.Body.Close()
+			return nil, merr
+		}
+
 		if resp.StatusCode >= 500 {
 			// Серверные ошибки (5xx) - пробуем retry
 			_ = resp.Body.Close()
-			lastErr = fmt.Errorf("сервер вернул ошибку: %d", resp.StatusCode)
+			lastErr = fmt.Errorf("%w: сервер вернул ошибку: %d", ErrServerUnavailable, resp.StatusCode)
 			h.log.Warn("Серверная ошибка, повторяем запрос",
 				"status", resp.StatusCode,
 				"attempt", attempt+1,
@@ -194,20 +420,31 @@ func (h *httpClient) parseResponse(resp *http.Response, result interface{}) erro
 		return fmt.Errorf("ошибка чтения ответа: %w", err)
 	}
 
+	// Тело ответа в журнал не пишется: в нем записи, токены и коды восстановления
 	h.log.Debug("Получен ответ",
 		"status", resp.StatusCode,
-		"body", string(body),
+		"size", len(body),
 	)
 
 	if resp.StatusCode >= 400 {
+		if qerr := quotaFromResponse(resp.StatusCode, body); qerr != nil {
+			return qerr
+		}
+
 		var errResp struct {
-			Error  string `json:"error"`
-			Status string `json:"status"`
+			Error  string      `json:"error"`
+			Status string      `json:"status"`
+			Detail string      `json:"detail"`
+			Code   apperr.Code `json:"code"`
 		}
+		serverErr := &ServerError{StatusCode: resp.StatusCode}
 		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
-			return fmt.Errorf("ошибка сервера: %s", errResp.Error)
+			serverErr.Message = errResp.Error
+		} else if errResp.Detail != "" {
+			serverErr.Message = errResp.Detail
 		}
-		return fmt.Errorf("ошибка сервера: статус %d", resp.StatusCode)
+		serverErr.Code = errResp.Code
+		return serverErr
 	}
 
 	if result != nil && len(body) > 0 {
@@ -221,7 +458,8 @@ func (h *httpClient) parseResponse(resp *http.Response, result interface{}) erro
 
 // ==================== Auth API ====================
 
-// Login выполняет вход пользователя
+// Login выполняет вход пользователя. Если у пользователя включена
+// двухфакторная аутентификация, возвращает *MFARequiredError.
 func (h *httpClient) Login(ctx context.Context, login, password string) (string, error) {
 	req := user.BaseRequest{
 		Login:    login,
@@ -234,16 +472,20 @@ func (h *httpClient) Login(ctx context.Context, login, password string) (string,
 	}
 
 	var loginResp struct {
-		Token  string `json:"token"`
-		Status string `json:"status"`
-		Error  string `json:"error"`
+		Token     string `json:"token"`
+		Challenge string `json:"challenge"`
+		Status    string `json:"status"`
+		Error     string `json:"error"`
 	}
 
 	if err := h.parseResponse(resp, &loginResp); err != nil {
 		return "", err
 	}
 
-	if loginResp.Status == "Error" {
+	if loginResp.Status == "MFARequired" {
+		return "", &MFARequiredError{Challenge: loginResp.Challenge}
+	}
+	if loginResp.Token == "" {
 		return "", fmt.Errorf("ошибка входа: %s", loginResp.Error)
 	}
 
@@ -251,6 +493,30 @@ func (h *httpClient) Login(ctx context.Context, login, password string) (string,
 	return loginResp.Token, nil
 }
 
+// LoginMFA завершает вход кодом двухфакторной аутентификации или кодом восстановления
+func (h *httpClient) LoginMFA(ctx context.Context, challenge, code string) (string, error) {
+	req := map[string]string{
+		"challenge": challenge,
+		"code":      code,
+	}
+
+	resp, err := h.doRequest(ctx, "POST", "/user/login/2fa", req)
+	if err != nil {
+		return "", err
+	}
+
+	var loginResp struct {
+		Token string `json:"token"`
+	}
+
+	if err := h.parseResponse(resp, &loginResp); err != nil {
+		return "", err
+	}
+
+	h.setAuthToken(loginResp.Token)
+	return loginResp.Token, nil
+}
+
 // Register регистрирует нового пользователя
 func (h *httpClient) Register(ctx context.Context, login, password string) error {
 	req := user.BaseRequest{
@@ -273,29 +539,32 @@ func (h *httpClient) Register(ctx context.Context, login, password string) error
 		return err
 	}
 
-	if registerResp.Status == "Error" {
-		return fmt.Errorf("ошибка регистрации: %s", registerResp.Error)
-	}
-
 	return nil
 }
 
-// ChangePassword меняет пароль пользователя
-// TODO: Реализовать на сервере эндпоинт /user/change-password
-// func (h *httpClient) ChangePassword(ctx context.Context, req user.ChangePasswordRequest) error {
-// 	resp, err := h.doRequest(ctx, "POST", "/user/change-password", req)
-// 	if err != nil {
-// 		return err
-// 	}
-//
-// 	return h.parseResponse(resp, nil)
-// }
+// ChangePassword меняет пароль входа. Сервер завершает все сессии
+// пользователя и возвращает новый токен для текущего устройства.
+func (h *httpClient) ChangePassword(ctx context.Context, req user.ChangePasswordRequest) (string, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/password", req)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := h.parseResponse(resp, &result); err != nil {
+		return "", err
+	}
+	return result.Token, nil
+}
 
 // ==================== Records API ====================
 
 // RecordResponse - ответ сервера на операции с записями
 type RecordResponse struct {
 	ID      int    `json:"id,omitempty"`
+	UUID    string `json:"uuid,omitempty"`
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
 	Error   string `json:"error,omitempty"`
@@ -359,32 +628,74 @@ type CreateBinaryRequest struct {
 	Tags        []string `json:"tags,omitempty"`
 	Description string   `json:"description,omitempty"`
 	DeviceID    string   `json:"device_id,omitempty"`
+	// Blob и BlobKey - контрольная сумма блоба с содержимым и ключ его расшифровки.
+	// Хранятся только в зашифрованных данных записи; при заданном Blob поле Data пустое.
+	Blob    string `json:"blob,omitempty"`
+	BlobKey []byte `json:"blob_key,omitempty"`
+}
+
+// CreateOTPRequest - запрос на создание записи TOTP
+type CreateOTPRequest struct {
+	Secret      string   `json:"secret"`
+	Algorithm   string   `json:"algorithm,omitempty"`
+	Digits      int      `json:"digits,omitempty"`
+	Period      int      `json:"period,omitempty"`
+	Title       string   `json:"title"`
+	Issuer      string   `json:"issuer,omitempty"`
+	AccountName string   `json:"account_name,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	DeviceID    string   `json:"device_id,omitempty"`
+}
+
+// CreateSSHKeyRequest - запрос на создание записи SSH-ключа
+type CreateSSHKeyRequest struct {
+	PrivateKey  string   `json:"private_key,omitempty"`
+	PublicKey   string   `json:"public_key,omitempty"`
+	Passphrase  string   `json:"passphrase,omitempty"`
+	Certificate string   `json:"certificate,omitempty"`
+	Title       string   `json:"title"`
+	Comment     string   `json:"comment,omitempty"`
+	Hosts       []string `json:"hosts,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	DeviceID    string   `json:"device_id,omitempty"`
 }
 
 // GenericRecordRequest - generic запрос на создание записи
 type GenericRecordRequest struct {
+	// UUID создаваемой записи; при изменении записи не передается
+	UUID string          `json:"uuid,omitempty"`
 	Type record.RecType  `json:"type"`
 	Data string          `json:"data"` // base64 encrypted data
 	Meta json.RawMessage `json:"meta"`
 }
 
-// CreateRecord создает запись на сервере (generic)
-func (h *httpClient) CreateRecord(ctx context.Context, req GenericRecordRequest) (int, error) {
-	resp, err := h.doRequest(ctx, "POST", "/api/records", req)
+// CreateRecord создает запись на сервере (generic). UUID в ответе - тот, под
+// которым запись сохранена на сервере: он может отличаться от req.UUID по
+// регистру, а при повторной отправке сервер возвращает уже созданную запись.
+func (h *httpClient) CreateRecord(ctx context.Context, req GenericRecordRequest) (*RecordResponse, error) {
+	req, err := h.sealRequest(req)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	resp, err := h.doTransfer(ctx, "POST", "/api/records", req)
+	if err != nil {
+		return nil, err
 	}
 
 	var createResp RecordResponse
 	if err := h.parseResponse(resp, &createResp); err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if createResp.Status == "Error" {
-		return 0, fmt.Errorf("ошибка создания записи: %s", createResp.Error)
+	// Сервер прежней версии UUID не возвращает
+	if createResp.UUID == "" {
+		createResp.UUID = req.UUID
 	}
 
-	return createResp.ID, nil
+	return &createResp, nil
 }
 
 // CreateLoginRecord создает запись логина на сервере
@@ -399,10 +710,6 @@ func (h *httpClient) CreateLoginRecord(ctx context.Context, req CreateLoginReque
 		return 0, err
 	}
 
-	if createResp.Status == "Error" {
-		return 0, fmt.Errorf("ошибка создания записи: %s", createResp.Error)
-	}
-
 	return createResp.ID, nil
 }
 
@@ -418,10 +725,6 @@ func (h *httpClient) CreateTextRecord(ctx context.Context, req CreateTextRequest
 		return 0, err
 	}
 
-	if createResp.Status == "Error" {
-		return 0, fmt.Errorf("ошибка создания записи: %s", createResp.Error)
-	}
-
 	return createResp.ID, nil
 }
 
@@ -437,16 +740,27 @@ func (h *httpClient) CreateCardRecord(ctx context.Context, req CreateCardRequest
 		return 0, err
 	}
 
-	if createResp.Status == "Error" {
-		return 0, fmt.Errorf("ошибка создания записи: %s", createResp.Error)
-	}
-
 	return createResp.ID, nil
 }
 
 // CreateBinaryRecord создает бинарную запись на сервере
 func (h *httpClient) CreateBinaryRecord(ctx context.Context, req CreateBinaryRequest) (int, error) {
-	resp, err := h.doRequest(ctx, "POST", "/api/records/binary", req)
+	resp, err := h.doTransfer(ctx, "POST", "/api/records/binary", req)
+	if err != nil {
+		return 0, err
+	}
+
+	var createResp RecordResponse
+	if err := h.parseResponse(resp, &createResp); err != nil {
+		return 0, err
+	}
+
+	return createResp.ID, nil
+}
+
+// CreateOTPRecord создает запись TOTP на сервере
+func (h *httpClient) CreateOTPRecord(ctx context.Context, req CreateOTPRequest) (int, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/records/otp", req)
 	if err != nil {
 		return 0, err
 	}
@@ -456,35 +770,52 @@ func (h *httpClient) CreateBinaryRecord(ctx context.Context, req CreateBinaryReq
 		return 0, err
 	}
 
-	if createResp.Status == "Error" {
-		return 0, fmt.Errorf("ошибка создания записи: %s", createResp.Error)
+	return createResp.ID, nil
+}
+
+// CreateSSHKeyRecord создает запись SSH-ключа на сервере
+func (h *httpClient) CreateSSHKeyRecord(ctx context.Context, req CreateSSHKeyRequest) (int, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/records/ssh-key", req)
+	if err != nil {
+		return 0, err
+	}
+
+	var createResp RecordResponse
+	if err := h.parseResponse(resp, &createResp); err != nil {
+		return 0, err
 	}
 
 	return createResp.ID, nil
 }
 
 // UpdateRecord обновляет запись на сервере
-func (h *httpClient) UpdateRecord(ctx context.Context, id int, req GenericRecordRequest) error {
-	resp, err := h.doRequest(ctx, "PUT", fmt.Sprintf("/api/records/%d", id), req)
+func (h *httpClient) UpdateRecord(ctx context.Context, ref string, req GenericRecordRequest) error {
+	req, err := h.sealRequest(req)
 	if err != nil {
 		return err
 	}
 
-	var updateResp RecordResponse
-	if err := h.parseResponse(resp, &updateResp); err != nil {
+	resp, err := h.doTransfer(ctx, "PUT", "/api/records/"+ref, req)
+	if err != nil {
 		return err
 	}
 
-	if updateResp.Status == "Error" {
-		return fmt.Errorf("ошибка обновления записи: %s", updateResp.Error)
+	var updateResp RecordResponse
+	if err := h.parseResponse(resp, &updateResp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// DeleteRecord удаляет запись на сервере
-func (h *httpClient) DeleteRecord(ctx context.Context, id int) error {
-	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/records/%d", id), nil)
+// DeleteRecord удаляет запись на сервере. Без permanent запись попадает в корзину.
+func (h *httpClient) DeleteRecord(ctx context.Context, ref string, permanent bool) error {
+	path := "/api/records/" + ref
+	if permanent {
+		path += "?permanent=true"
+	}
+
+	resp, err := h.doRequest(ctx, "DELETE", path, nil)
 	if err != nil {
 		return err
 	}
@@ -494,16 +825,12 @@ func (h *httpClient) DeleteRecord(ctx context.Context, id int) error {
 		return err
 	}
 
-	if deleteResp.Status == "Error" {
-		return fmt.Errorf("ошибка удаления записи: %s", deleteResp.Error)
-	}
-
 	return nil
 }
 
 // GetRecord получает запись с сервера
-func (h *httpClient) GetRecord(ctx context.Context, id int) (*record.Record, error) {
-	resp, err := h.doRequest(ctx, "GET", fmt.Sprintf("/api/records/%d", id), nil)
+func (h *httpClient) GetRecord(ctx context.Context, ref string) (*record.Record, error) {
+	resp, err := h.doTransfer(ctx, "GET", "/api/records/"+ref, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -518,159 +845,1118 @@ func (h *httpClient) GetRecord(ctx context.Context, id int) (*record.Record, err
 		return nil, err
 	}
 
-	if findResp.Status == "Error" {
-		return nil, fmt.Errorf("ошибка получения записи: %s", findResp.Error)
+	if findResp.Record != nil {
+		if findResp.Record.Meta, err = h.openMetaRaw(findResp.Record.Meta); err != nil {
+			return nil, err
+		}
 	}
 
 	return findResp.Record, nil
 }
 
-// ListRecords получает список записей с сервера
-func (h *httpClient) ListRecords(ctx context.Context) (*record.ListResponse, error) {
-	resp, err := h.doRequest(ctx, "GET", "/api/records", nil)
+// RecordHead - версия записи на сервере без ее содержимого
+type RecordHead struct {
+	Version int
+	ETag    string
+	Deleted bool
+}
+
+// HeadRecord получает версию записи запросом HEAD, не загружая данные.
+// Для удаленной записи возвращает Deleted, для отсутствующей - record.ErrNotFound.
+func (h *httpClient) HeadRecord(ctx context.Context, ref string) (*RecordHead, error) {
+	resp, err := h.doRequest(ctx, "HEAD", "/api/records/"+ref, nil)
 	if err != nil {
 		return nil, err
 	}
+	_ = resp.Body.Close()
 
-	var listResp record.ListResponse
-	if err := h.parseResponse(resp, &listResp); err != nil {
-		return nil, err
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return &RecordHead{Deleted: true}, nil
+	case http.StatusNotFound:
+		return nil, record.ErrNotFound
+	default:
+		return nil, &ServerError{StatusCode: resp.StatusCode}
 	}
 
-	return &listResp, nil
-}
+	version, err := strconv.Atoi(resp.Header.Get("X-Record-Version"))
+	if err != nil {
+		return nil, fmt.Errorf("сервер не вернул версию записи")
+	}
 
-// ==================== Sync API ====================
+	return &RecordHead{Version: version, ETag: resp.Header.Get("ETag")}, nil
+}
 
-// GetSyncChanges получает изменения с сервера
-func (h *httpClient) GetSyncChanges(ctx context.Context, req sync.GetChangesRequest) (*sync.GetChangesResponse, error) {
-	resp, err := h.doRequest(ctx, "POST", "/api/sync/changes", req)
+// GetRecordVersions получает историю версий записи с сервера
+func (h *httpClient) GetRecordVersions(ctx context.Context, ref string) ([]record.Version, error) {
+	resp, err := h.doTransfer(ctx, "GET", "/api/records/"+ref+"/versions", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
-	var result sync.GetChangesResponse
-	if err := h.parseResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var versionsResp struct {
+		Status   string           `json:"status"`
+		Versions []record.Version `json:"versions"`
+		Error    string           `json:"error,omitempty"`
 	}
 
-	if result.Status == "Error" {
-		return nil, fmt.Errorf("server error: %s", result.Error)
+	if err := h.parseResponse(resp, &versionsResp); err != nil {
+		return nil, err
 	}
 
-	return &result, nil
+	for i := range versionsResp.Versions {
+		if versionsResp.Versions[i].Meta, err = h.openMetaRaw(versionsResp.Versions[i].Meta); err != nil {
+			return nil, err
+		}
+	}
+
+	return versionsResp.Versions, nil
 }
 
-// SendBatchSync отправляет пакет записей для синхронизации
-func (h *httpClient) SendBatchSync(ctx context.Context, req sync.BatchSyncRequest) (*sync.BatchSyncResponse, error) {
-	resp, err := h.doRequest(ctx, "POST", "/api/sync/batch", req)
+// ListTrash получает записи из корзины на сервере
+func (h *httpClient) ListTrash(ctx context.Context) ([]record.Record, error) {
+	resp, err := h.doTransfer(ctx, "GET", "/api/records/trash", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
-	var result sync.BatchSyncResponse
-	if err := h.parseResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var trashResp struct {
+		Status  string          `json:"status"`
+		Records []record.Record `json:"records"`
+		Error   string          `json:"error,omitempty"`
 	}
 
-	if result.Status == "Error" {
-		return nil, fmt.Errorf("server error: %s", result.Error)
+	if err := h.parseResponse(resp, &trashResp); err != nil {
+		return nil, err
 	}
 
-	return &result, nil
+	if err := h.openRecords(trashResp.Records); err != nil {
+		return nil, err
+	}
+
+	return trashResp.Records, nil
 }
 
-// GetSyncStatus получает статус синхронизации с сервера
-func (h *httpClient) GetSyncStatus(ctx context.Context) (*sync.Status, error) {
-	resp, err := h.doRequest(ctx, "GET", "/api/sync/status", nil)
+// RestoreRecord возвращает запись из корзины на сервере. Возвращает новую версию записи.
+func (h *httpClient) RestoreRecord(ctx context.Context, ref string) (int, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/records/"+ref+"/restore", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return 0, err
 	}
 
-	var result sync.GetStatusResponse
-	if err := h.parseResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var restoreResp struct {
+		Status  string `json:"status"`
+		Version int    `json:"version"`
+		Error   string `json:"error,omitempty"`
 	}
 
-	if result.Status == "Error" {
-		return nil, fmt.Errorf("server error: %s", result.Error)
+	if err := h.parseResponse(resp, &restoreResp); err != nil {
+		return 0, err
 	}
 
-	return result.Data, nil
+	return restoreResp.Version, nil
 }
 
-// GetSyncConflicts получает конфликты с сервера
-func (h *httpClient) GetSyncConflicts(ctx context.Context) ([]sync.Conflict, error) {
-	resp, err := h.doRequest(ctx, "GET", "/api/sync/conflicts", nil)
+// GetQuota возвращает использование хранилища и лимит пользователя
+func (h *httpClient) GetQuota(ctx context.Context) (*QuotaUsage, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/account/quota", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-
-	var result sync.GetConflictsResponse
-	if err := h.parseResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
-	if result.Status == "Error" {
-		return nil, fmt.Errorf("server error: %s", result.Error)
+	var usage QuotaUsage
+	if err := h.parseResponse(resp, &usage); err != nil {
+		return nil, err
 	}
 
-	return result.Data, nil
+	return &usage, nil
 }
 
-// ResolveConflict разрешает конфликт на сервере
-func (h *httpClient) ResolveConflict(ctx context.Context, conflictID int, req sync.ResolveConflictRequest) error {
-	resp, err := h.doRequest(ctx, "POST", fmt.Sprintf("/api/sync/conflicts/%d/resolve", conflictID), req)
+// GetAccountStats возвращает статистику хранилища пользователя с ростом за days дней
+func (h *httpClient) GetAccountStats(ctx context.Context, days int) (*stats.Stats, error) {
+	resp, err := h.doRequest(ctx, "GET", fmt.Sprintf("/api/account/stats?days=%d", days), nil)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
-	var result sync.ResolveConflictResponse
+	var result stats.Stats
 	if err := h.parseResponse(resp, &result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if result.Status == "Error" {
-		return fmt.Errorf("server error: %s", result.Error)
+		return nil, err
 	}
 
-	return nil
+	return &result, nil
 }
 
-// GetDevices получает список устройств с сервера
-func (h *httpClient) GetDevices(ctx context.Context) ([]sync.DeviceInfo, error) {
-	resp, err := h.doRequest(ctx, "GET", "/api/sync/devices", nil)
+// ListSessions возвращает активные сессии пользователя; текущая отмечена Current
+func (h *httpClient) ListSessions(ctx context.Context) ([]session.Session, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/account/sessions", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
-	var result sync.GetDevicesResponse
-	if err := h.parseResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var sessions []session.Session
+	if err := h.parseResponse(resp, &sessions); err != nil {
+		return nil, err
 	}
+	return sessions, nil
+}
 
-	if result.Status == "Error" {
-		return nil, fmt.Errorf("server error: %s", result.Error)
+// RevokeSession завершает сессию пользователя по ID
+func (h *httpClient) RevokeSession(ctx context.Context, id int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/account/sessions/%d", id), nil)
+	if err != nil {
+		return err
 	}
 
-	return result.Data, nil
+	return h.parseResponse(resp, nil)
 }
 
-// RemoveDevice удаляет устройство на сервере
-func (h *httpClient) RemoveDevice(ctx context.Context, deviceID int) error {
-	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/sync/devices/%d", deviceID), nil)
+// RevokeOtherSessions завершает все сессии пользователя, кроме текущей, и
+// возвращает их число
+func (h *httpClient) RevokeOtherSessions(ctx context.Context) (int64, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/sessions/revoke-others", nil)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return 0, err
 	}
 
-	var result sync.RemoveDeviceResponse
+	var result struct {
+		Revoked int64 `json:"revoked"`
+	}
 	if err := h.parseResponse(resp, &result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		return 0, err
+	}
+	return result.Revoked, nil
+}
+
+// ListAttachments получает список вложений записи без содержимого
+func (h *httpClient) ListAttachments(ctx context.Context, recordRef string) ([]record.Attachment, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/records/"+recordRef+"/attachments", nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if result.Status == "Error" {
-		return fmt.Errorf("server error: %s", result.Error)
+	var listResp struct {
+		Attachments []record.Attachment `json:"attachments"`
+	}
+	if err := h.parseResponse(resp, &listResp); err != nil {
+		return nil, err
 	}
+	return listResp.Attachments, nil
+}
 
-	return nil
+// AddAttachment загружает зашифрованное вложение записи
+func (h *httpClient) AddAttachment(ctx context.Context, recordRef string, encryptedMeta string, data []byte) (*record.Attachment, error) {
+	body := struct {
+		EncryptedMeta string `json:"encrypted_meta"`
+		Data          []byte `json:"data"`
+	}{EncryptedMeta: encryptedMeta, Data: data}
+
+	resp, err := h.doTransfer(ctx, "POST", "/api/records/"+recordRef+"/attachments", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var addResp struct {
+		Attachment *record.Attachment `json:"attachment"`
+	}
+	if err := h.parseResponse(resp, &addResp); err != nil {
+		return nil, err
+	}
+	if addResp.Attachment == nil {
+		return nil, fmt.Errorf("сервер не вернул вложение")
+	}
+	return addResp.Attachment, nil
+}
+
+// GetAttachment загружает вложение записи вместе с зашифрованным содержимым
+func (h *httpClient) GetAttachment(ctx context.Context, recordRef string, attachmentID int) (*record.Attachment, []byte, error) {
+	resp, err := h.doTransfer(ctx, "GET", fmt.Sprintf("/api/records/%s/attachments/%d", recordRef, attachmentID), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var getResp struct {
+		Attachment *record.Attachment `json:"attachment"`
+		Data       []byte             `json:"data"`
+	}
+	if err := h.parseResponse(resp, &getResp); err != nil {
+		return nil, nil, err
+	}
+	if getResp.Attachment == nil {
+		return nil, nil, fmt.Errorf("сервер не вернул вложение")
+	}
+	return getResp.Attachment, getResp.Data, nil
+}
+
+// DeleteAttachment удаляет вложение записи
+func (h *httpClient) DeleteAttachment(ctx context.Context, recordRef string, attachmentID int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/records/%s/attachments/%d", recordRef, attachmentID), nil)
+	if err != nil {
+		return err
+	}
+	return h.parseResponse(resp, nil)
+}
+
+// BlobInfo - блоб на сервере без его содержимого
+type BlobInfo struct {
+	Size       int64
+	References int
+}
+
+// GetBlobByChecksum проверяет запросом HEAD, хранится ли блоб на сервере.
+// Для отсутствующего блоба возвращает ErrBlobNotFound.
+func (h *httpClient) GetBlobByChecksum(ctx context.Context, checksum string) (*BlobInfo, error) {
+	resp, err := h.doRequest(ctx, "HEAD", "/api/blobs/"+checksum, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrBlobNotFound
+	default:
+		return nil, &ServerError{StatusCode: resp.StatusCode}
+	}
+
+	size, _ := strconv.ParseInt(resp.Header.Get("X-Blob-Size"), 10, 64)
+	refs, _ := strconv.Atoi(resp.Header.Get("X-Blob-References"))
+	return &BlobInfo{Size: size, References: refs}, nil
+}
+
+// PutBlob загружает зашифрованный блоб. created - false, если такой блоб уже хранился.
+// Имя и тип файла передаются для проверки политикой вложений сервера.
+func (h *httpClient) PutBlob(ctx context.Context, checksum string, data []byte, file blob.FileInfo) (bool, error) {
+	body := struct {
+		Data []byte `json:"data"`
+		blob.FileInfo
+	}{Data: data, FileInfo: file}
+
+	resp, err := h.doTransfer(ctx, "PUT", "/api/blobs/"+checksum, body)
+	if err != nil {
+		return false, err
+	}
+
+	var putResp struct {
+		Created bool `json:"created"`
+	}
+	if err := h.parseResponse(resp, &putResp); err != nil {
+		// 403 отвечает и проверка устройства, поэтому отказ политики
+		// вложений узнается по коду ошибки, а у старых серверов - по тексту
+		var serverErr *ServerError
+		if errors.As(err, &serverErr) && (errors.Is(err, blob.ErrRejected) ||
+			serverErr.Code == "" && strings.HasPrefix(serverErr.Message, blob.ErrRejected.Error())) {
+			return false, fmt.Errorf("%w: %s", ErrUploadRejected, serverErr.Message)
+		}
+		return false, err
+	}
+	return putResp.Created, nil
+}
+
+// GetBlob загружает зашифрованный блоб
+func (h *httpClient) GetBlob(ctx context.Context, checksum string) ([]byte, error) {
+	resp, err := h.doTransfer(ctx, "GET", "/api/blobs/"+checksum, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var getResp struct {
+		Data []byte `json:"data"`
+	}
+	if err := h.parseResponse(resp, &getResp); err != nil {
+		return nil, err
+	}
+	return getResp.Data, nil
+}
+
+// GetMFAStatus возвращает состояние двухфакторной аутентификации учетной записи
+func (h *httpClient) GetMFAStatus(ctx context.Context) (*MFAStatus, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/account/2fa", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status MFAStatus
+	if err := h.parseResponse(resp, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// EnrollMFA получает новый секрет TOTP для приложения-аутентификатора
+func (h *httpClient) EnrollMFA(ctx context.Context) (*MFAEnrollment, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/2fa/enroll", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrollment MFAEnrollment
+	if err := h.parseResponse(resp, &enrollment); err != nil {
+		return nil, err
+	}
+
+	return &enrollment, nil
+}
+
+// ConfirmMFA включает двухфакторную аутентификацию. Возвращает коды
+// восстановления и новый токен сессии.
+func (h *httpClient) ConfirmMFA(ctx context.Context, code string) ([]string, string, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/2fa/confirm", map[string]string{"code": code})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var confirmResp struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+		Token         string   `json:"token"`
+	}
+
+	if err := h.parseResponse(resp, &confirmResp); err != nil {
+		return nil, "", err
+	}
+
+	return confirmResp.RecoveryCodes, confirmResp.Token, nil
+}
+
+// DisableMFA выключает двухфакторную аутентификацию
+func (h *httpClient) DisableMFA(ctx context.Context, code string) error {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/2fa/disable", map[string]string{"code": code})
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// RegenerateRecoveryCodes получает новые коды восстановления
+func (h *httpClient) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/account/2fa/recovery-codes", map[string]string{"code": code})
+	if err != nil {
+		return nil, err
+	}
+
+	var codesResp struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	if err := h.parseResponse(resp, &codesResp); err != nil {
+		return nil, err
+	}
+
+	return codesResp.RecoveryCodes, nil
+}
+
+// PurgeTrash окончательно удаляет записи, пролежавшие в корзине дольше olderThan.
+// Возвращает число удаленных записей.
+func (h *httpClient) PurgeTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	path := "/api/records/trash?older_than=" + url.QueryEscape(olderThan.String())
+
+	resp, err := h.doRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var purgeResp struct {
+		Status string `json:"status"`
+		Purged int    `json:"purged"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	if err := h.parseResponse(resp, &purgeResp); err != nil {
+		return 0, err
+	}
+
+	return purgeResp.Purged, nil
+}
+
+// ListRecords получает список записей с сервера. Фильтр может быть nil.
+// Повторный запрос того же списка условный: если список не изменился,
+// сервер отвечает 304 и используется сохраненный ответ.
+func (h *httpClient) ListRecords(ctx context.Context, filter *RecordFilter) (*record.ListResponse, error) {
+	path := "/api/records"
+	if query := filter.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	body, err := h.getConditional(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp record.ListResponse
+	if err := json.Unmarshal(body, &listResp); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+
+	for i := range listResp.Records {
+		if listResp.Records[i].Meta, err = h.openMetaRaw(listResp.Records[i].Meta); err != nil {
+			return nil, fmt.Errorf("запись %d: %w", listResp.Records[i].ID, err)
+		}
+	}
+
+	return &listResp, nil
+}
+
+// ==================== Sync API ====================
+
+// GetSyncChanges получает изменения с сервера. С etag прошлого ответа запрос
+// условный: если изменения те же, возвращается ErrNotModified. Второе значение -
+// ETag ответа.
+func (h *httpClient) GetSyncChanges(ctx context.Context, req sync.GetChangesRequest, etag string) (*sync.GetChangesResponse, string, error) {
+	resp, err := h.doSync("changes", func(path string) (*http.Response, error) {
+		return h.doConditional(ctx, "POST", path, req, etag)
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		return nil, etag, ErrNotModified
+	}
+
+	var result sync.GetChangesResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for i := range result.Records {
+		if result.Records[i].Meta, err = h.openMetaRaw(result.Records[i].Meta); err != nil {
+			return nil, "", fmt.Errorf("запись %d: %w", result.Records[i].ID, err)
+		}
+	}
+
+	return &result, resp.Header.Get("ETag"), nil
+}
+
+// NegotiateSync отправляет индекс локальных записей и получает ID различающихся
+func (h *httpClient) NegotiateSync(ctx context.Context, req sync.NegotiateRequest) (*sync.NegotiateResponse, error) {
+	resp, err := h.doSync("negotiate", func(path string) (*http.Response, error) {
+		return h.doTransfer(ctx, "POST", path, req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.NegotiateResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// SendBatchSync отправляет пакет записей для синхронизации
+func (h *httpClient) SendBatchSync(ctx context.Context, req sync.BatchSyncRequest) (*sync.BatchSyncResponse, error) {
+	records, err := h.sealSyncRecords(req.Records)
+	if err != nil {
+		return nil, err
+	}
+	req.Records = records
+
+	resp, err := h.doSync("batch", func(path string) (*http.Response, error) {
+		return h.doTransfer(ctx, "POST", path, req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.BatchSyncResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetSyncStatus получает статус синхронизации с сервера
+func (h *httpClient) GetSyncStatus(ctx context.Context) (*sync.Status, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/sync/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.GetStatusResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// GetSyncConflicts получает конфликты с сервера
+func (h *httpClient) GetSyncConflicts(ctx context.Context) ([]sync.Conflict, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/sync/conflicts", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.GetConflictsResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// ResolveConflict разрешает конфликт на сервере
+func (h *httpClient) ResolveConflict(ctx context.Context, conflictID int, req sync.ResolveConflictRequest) error {
+	if req.ResolvedData != nil {
+		sealed, err := h.sealSyncRecords([]sync.RecordSync{*req.ResolvedData})
+		if err != nil {
+			return err
+		}
+		req.ResolvedData = &sealed[0]
+	}
+
+	resp, err := h.doRequest(ctx, "POST", fmt.Sprintf("/api/sync/conflicts/%d/resolve", conflictID), req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.ResolveConflictResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// GetDevices получает список устройств с сервера
+func (h *httpClient) GetDevices(ctx context.Context) ([]sync.DeviceInfo, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/sync/devices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.GetDevicesResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// RegisterDevice регистрирует устройство на сервере по его UUID
+func (h *httpClient) RegisterDevice(ctx context.Context, req sync.RegisterDeviceRequest) (*sync.RegisterDeviceResponse, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/sync/devices/register", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.RegisterDeviceResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// RemoveDevice удаляет устройство на сервере
+func (h *httpClient) RemoveDevice(ctx context.Context, deviceID int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/sync/devices/%d", deviceID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.RemoveDeviceResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// ApproveDevice подтверждает устройство с этого (доверенного) устройства
+func (h *httpClient) ApproveDevice(ctx context.Context, deviceID int, req sync.ApproveDeviceRequest) (*sync.ApproveDeviceResponse, error) {
+	resp, err := h.doRequest(ctx, "POST", fmt.Sprintf("/api/sync/devices/%d/approve", deviceID), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.ApproveDeviceResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ==================== Settings API ====================
+
+// GetSettings получает настройки пользователя с сервера
+func (h *httpClient) GetSettings(ctx context.Context) (map[string]string, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/settings", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result settings.GetResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// UpdateSettings частично обновляет настройки пользователя на сервере
+func (h *httpClient) UpdateSettings(ctx context.Context, req settings.UpdateRequest) (map[string]string, error) {
+	resp, err := h.doRequest(ctx, "PATCH", "/api/settings", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result settings.GetResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Data, nil
+}
+
+// ==================== Key File API ====================
+
+// GetKeyFile получает файл мастер-ключа учетной записи. Если файл не
+// сохранен на сервере, возвращает ошибку вида apperr.NotFound.
+func (h *httpClient) GetKeyFile(ctx context.Context) (*keyfile.KeyFile, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/account/key-file", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyfile.KeyFile
+	if err := h.parseResponse(resp, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// PutKeyFile сохраняет файл мастер-ключа учетной записи. previousKeyHash -
+// хэш из заголовка прежнего файла, если мастер-пароль сменился с прошлой отправки
+func (h *httpClient) PutKeyFile(ctx context.Context, data []byte, keyHash, previousKeyHash string) error {
+	body := struct {
+		Data            []byte `json:"data"`
+		KeyHash         string `json:"key_hash"`
+		PreviousKeyHash string `json:"previous_key_hash,omitempty"`
+	}{Data: data, KeyHash: keyHash, PreviousKeyHash: previousKeyHash}
+
+	resp, err := h.doRequest(ctx, "PUT", "/api/account/key-file", body)
+	if err != nil {
+		return err
+	}
+	return h.parseResponse(resp, nil)
+}
+
+// ==================== Org API ====================
+
+// CreateOrg создает организацию; encryptedKey - ключ хранилища, зашифрованный мастер-ключом
+func (h *httpClient) CreateOrg(ctx context.Context, name, encryptedKey string) (*org.Organization, error) {
+	resp, err := h.doRequest(ctx, "POST", "/api/orgs", map[string]string{
+		"name":          name,
+		"encrypted_key": encryptedKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var created org.Organization
+	if err := h.parseResponse(resp, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// ListOrgs возвращает организации пользователя и приглашения
+func (h *httpClient) ListOrgs(ctx context.Context) ([]org.Summary, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/orgs", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Organizations []org.Summary `json:"organizations"`
+	}
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Organizations, nil
+}
+
+// GetOrg возвращает организацию с ролью и ключом хранилища пользователя
+func (h *httpClient) GetOrg(ctx context.Context, orgID int) (*org.Summary, error) {
+	resp, err := h.doRequest(ctx, "GET", fmt.Sprintf("/api/orgs/%d", orgID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary org.Summary
+	if err := h.parseResponse(resp, &summary); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// DeleteOrg удаляет организацию вместе с хранилищем
+func (h *httpClient) DeleteOrg(ctx context.Context, orgID int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/orgs/%d", orgID), nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// ListOrgMembers возвращает участников организации
+func (h *httpClient) ListOrgMembers(ctx context.Context, orgID int) ([]membership.Member, error) {
+	resp, err := h.doRequest(ctx, "GET", fmt.Sprintf("/api/orgs/%d/members", orgID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Members []membership.Member `json:"members"`
+	}
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return result.Members, nil
+}
+
+// InviteOrgMember приглашает пользователя; encryptedKey зашифрован кодом приглашения
+func (h *httpClient) InviteOrgMember(ctx context.Context, orgID int, login string, role org.Role, encryptedKey string) error {
+	resp, err := h.doRequest(ctx, "POST", fmt.Sprintf("/api/orgs/%d/members", orgID), map[string]string{
+		"login":         login,
+		"role":          string(role),
+		"encrypted_key": encryptedKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// AcceptOrgInvite принимает приглашение; encryptedKey зашифрован мастер-ключом
+func (h *httpClient) AcceptOrgInvite(ctx context.Context, orgID int, encryptedKey string) error {
+	resp, err := h.doRequest(ctx, "POST", fmt.Sprintf("/api/orgs/%d/accept", orgID), map[string]string{
+		"encrypted_key": encryptedKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// ChangeOrgMemberRole меняет роль участника
+func (h *httpClient) ChangeOrgMemberRole(ctx context.Context, orgID, userID int, role org.Role) error {
+	resp, err := h.doRequest(ctx, "PATCH", fmt.Sprintf("/api/orgs/%d/members/%d", orgID, userID), map[string]string{
+		"role": string(role),
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// RemoveOrgMember исключает участника или отзывает приглашение
+func (h *httpClient) RemoveOrgMember(ctx context.Context, orgID, userID int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/orgs/%d/members/%d", orgID, userID), nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// ListOrgRecords возвращает записи хранилища организации
+func (h *httpClient) ListOrgRecords(ctx context.Context, orgID int) (*record.ListResponse, error) {
+	resp, err := h.doTransfer(ctx, "GET", fmt.Sprintf("/api/orgs/%d/records", orgID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp record.ListResponse
+	if err := h.parseResponse(resp, &listResp); err != nil {
+		return nil, err
+	}
+
+	return &listResp, nil
+}
+
+// CreateOrgRecord создает запись в хранилище организации
+func (h *httpClient) CreateOrgRecord(ctx context.Context, orgID int, req GenericRecordRequest) (int, error) {
+	resp, err := h.doTransfer(ctx, "POST", fmt.Sprintf("/api/orgs/%d/records", orgID), req)
+	if err != nil {
+		return 0, err
+	}
+
+	var createResp RecordResponse
+	if err := h.parseResponse(resp, &createResp); err != nil {
+		return 0, err
+	}
+
+	return createResp.ID, nil
+}
+
+// ListFolders возвращает папки пользователя
+func (h *httpClient) ListFolders(ctx context.Context) ([]folder.Folder, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/folders", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp struct {
+		Folders []folder.Folder `json:"folders"`
+	}
+	if err := h.parseResponse(resp, &listResp); err != nil {
+		return nil, err
+	}
+	return listResp.Folders, nil
+}
+
+// CreateFolder создает папку; parentID == nil - папка верхнего уровня
+func (h *httpClient) CreateFolder(ctx context.Context, name string, parentID *int) (*folder.Folder, error) {
+	body := map[string]interface{}{"name": name}
+	if parentID != nil {
+		body["parent_id"] = *parentID
+	}
+
+	resp, err := h.doRequest(ctx, "POST", "/api/folders", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var created folder.Folder
+	if err := h.parseResponse(resp, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateFolder переименовывает и перемещает папку; nil-поля не меняются,
+// parentID 0 переносит папку на верхний уровень
+func (h *httpClient) UpdateFolder(ctx context.Context, id int, name *string, parentID *int) (*folder.Folder, error) {
+	body := map[string]interface{}{}
+	if name != nil {
+		body["name"] = *name
+	}
+	if parentID != nil {
+		body["parent_id"] = *parentID
+	}
+
+	resp, err := h.doRequest(ctx, "PATCH", fmt.Sprintf("/api/folders/%d", id), body)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated folder.Folder
+	if err := h.parseResponse(resp, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteFolder удаляет пустую папку
+func (h *httpClient) DeleteFolder(ctx context.Context, id int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/folders/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// CreateViewerToken выпускает токен просмотра папок folderIDs на срок ttl
+func (h *httpClient) CreateViewerToken(ctx context.Context, name string, folderIDs []int, ttl time.Duration) (*viewer.Issued, error) {
+	body := map[string]interface{}{"name": name, "folder_ids": folderIDs}
+	if ttl > 0 {
+		body["ttl"] = ttl.String()
+	}
+
+	resp, err := h.doRequest(ctx, "POST", "/api/share/tokens", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var createResp struct {
+		Data *viewer.Issued `json:"data"`
+	}
+	if err := h.parseResponse(resp, &createResp); err != nil {
+		return nil, err
+	}
+	if createResp.Data == nil {
+		return nil, fmt.Errorf("сервер не вернул токен")
+	}
+	return createResp.Data, nil
+}
+
+// ListViewerTokens возвращает токены просмотра пользователя
+func (h *httpClient) ListViewerTokens(ctx context.Context) ([]viewer.Token, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/share/tokens", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp struct {
+		Tokens []viewer.Token `json:"tokens"`
+	}
+	if err := h.parseResponse(resp, &listResp); err != nil {
+		return nil, err
+	}
+	return listResp.Tokens, nil
+}
+
+// RevokeViewerToken отзывает токен просмотра
+func (h *httpClient) RevokeViewerToken(ctx context.Context, id int) error {
+	resp, err := h.doRequest(ctx, "DELETE", fmt.Sprintf("/api/share/tokens/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// CreateSecretLink сохраняет шифротекст секрета и возвращает ссылку на него
+func (h *httpClient) CreateSecretLink(ctx context.Context, ciphertext []byte, ttl time.Duration, maxViews int) (*secretlink.Link, error) {
+	body := map[string]interface{}{"ciphertext": ciphertext}
+	if ttl > 0 {
+		body["ttl"] = ttl.String()
+	}
+	if maxViews > 0 {
+		body["max_views"] = maxViews
+	}
+
+	resp, err := h.doRequest(ctx, "POST", "/api/share/links", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var createResp struct {
+		Data *secretlink.Link `json:"data"`
+	}
+	if err := h.parseResponse(resp, &createResp); err != nil {
+		return nil, err
+	}
+	if createResp.Data == nil {
+		return nil, fmt.Errorf("сервер не вернул ссылку")
+	}
+	return createResp.Data, nil
+}
+
+// ListSecretLinks возвращает ссылки на секреты пользователя
+func (h *httpClient) ListSecretLinks(ctx context.Context) ([]secretlink.Link, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/share/links", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp struct {
+		Links []secretlink.Link `json:"links"`
+	}
+	if err := h.parseResponse(resp, &listResp); err != nil {
+		return nil, err
+	}
+	return listResp.Links, nil
+}
+
+// RevokeSecretLink удаляет ссылку на секрет
+func (h *httpClient) RevokeSecretLink(ctx context.Context, id string) error {
+	resp, err := h.doRequest(ctx, "DELETE", "/api/share/links/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// OpenSecretLink засчитывает просмотр секрета на сервере origin и возвращает
+// шифротекст. Ссылка может вести на чужой сервер, поэтому токен сессии не
+// отправляется. Запрос не повторяется: повтор мог бы израсходовать еще один
+// просмотр.
+func (h *httpClient) OpenSecretLink(ctx context.Context, origin, id string) ([]byte, error) {
+	ctx, cancel := h.withTimeout(ctx, opRequest)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", origin+"/api/secrets/"+url.PathEscape(id)+"/open", nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("User-Agent", h.userAgent)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrServerUnavailable, err)
+	}
+
+	var openResp struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := h.parseResponse(resp, &openResp); err != nil {
+		return nil, err
+	}
+	return openResp.Ciphertext, nil
+}
+
+// ScheduleAccountDeletion планирует удаление учетной записи на сервере
+func (h *httpClient) ScheduleAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	resp, err := h.doRequest(ctx, "DELETE", "/api/v1/account", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletion AccountDeletion
+	if err := h.parseResponse(resp, &deletion); err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// GetAccountDeletion возвращает запланированное удаление учетной записи
+func (h *httpClient) GetAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/v1/account/deletion", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletion AccountDeletion
+	if err := h.parseResponse(resp, &deletion); err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// CancelAccountDeletion отменяет запланированное удаление учетной записи
+func (h *httpClient) CancelAccountDeletion(ctx context.Context) error {
+	resp, err := h.doRequest(ctx, "DELETE", "/api/v1/account/deletion", nil)
+	if err != nil {
+		return err
+	}
+
+	return h.parseResponse(resp, nil)
+}
+
+// ExportAccount загружает zip-архив со всеми данными учетной записи в w
+func (h *httpClient) ExportAccount(ctx context.Context, w io.Writer) (int64, error) {
+	resp, err := h.doTransfer(ctx, "GET", "/api/v1/account/export", nil)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 400 {
+		return 0, h.parseResponse(resp, nil)
+	}
+	defer resp.Body.Close()
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("ошибка загрузки архива: %w", err)
+	}
+	return n, nil
 }
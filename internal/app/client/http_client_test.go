@@ -0,0 +1,41 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/utils/logger"
+)
+
+func TestHTTPClient_DebugLogRedacted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer client-secret-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"id":1,"encrypted_data":"cipher-secret","token":"response-secret","title":"plain-title"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	var buf bytes.Buffer
+	app.httpClient.log = logger.NewTo(config.EnvDev, &buf)
+	app.httpClient.SetToken("client-secret-token")
+
+	resp, err := app.httpClient.doRequest(context.Background(), "GET", "/api/records/1", nil)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, app.httpClient.parseResponse(resp, &result))
+	assert.Equal(t, "response-secret", result["token"])
+
+	out := buf.String()
+	assert.Contains(t, out, "Получен ответ")
+	assert.Contains(t, out, `"size":89`)
+	for _, secret := range []string{"client-secret-token", "cipher-secret", "response-secret", "plain-title"} {
+		assert.NotContains(t, out, secret)
+	}
+}
@@ -0,0 +1,101 @@
+// internal/app/client/http_conditional.go
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	gosync "sync"
+)
+
+// Экономия трафика: ответы сервер сжимает сам (http.Transport запрашивает
+// gzip и распаковывает ответ), тела запросов клиент сжимает, когда сервер
+// объявил Accept-Encoding: gzip. Списки и изменения синхронизации
+// запрашиваются условно по ETag, чтобы не загружать те же данные повторно.
+
+// gzipMinSize - тела запросов меньше этого размера не сжимаются
+const gzipMinSize = 1024
+
+// maxCachedLists - сколько ответов списков хранится для условных запросов
+const maxCachedLists = 32
+
+// ErrNotModified - данные на сервере не изменились с прошлого запроса (304)
+var ErrNotModified = errors.New("данные не изменились")
+
+// noteRequestEncoding запоминает, принимает ли сервер сжатые запросы (RFC 7694)
+func (h *httpClient) noteRequestEncoding(resp *http.Response) {
+	accepted := resp.Header.Get("Accept-Encoding")
+	if accepted == "" {
+		return
+	}
+	h.gzipRequests.Store(strings.Contains(strings.ToLower(accepted), "gzip"))
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// conditionalCache хранит последние ответы с ETag по пути запроса
+type conditionalCache struct {
+	mu      gosync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	etag string
+	body json.RawMessage
+}
+
+func (c *conditionalCache) get(path string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	return entry, ok
+}
+
+func (c *conditionalCache) put(path string, entry cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= maxCachedLists {
+		c.entries = make(map[string]cachedResponse)
+	}
+	c.entries[path] = entry
+}
+
+// getConditional выполняет GET с If-None-Match сохраненного ответа и
+// возвращает тело ответа, при 304 - сохраненное
+func (h *httpClient) getConditional(ctx context.Context, path string) (json.RawMessage, error) {
+	cached, ok := h.lists.get(path)
+
+	resp, err := h.doConditional(ctx, "GET", path, nil, cached.etag)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && ok {
+		_ = resp.Body.Close()
+		h.log.Debug("Ответ не изменился, используется сохраненный", "path", path)
+		return cached.body, nil
+	}
+
+	var body json.RawMessage
+	if err := h.parseResponse(resp, &body); err != nil {
+		return nil, err
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		h.lists.put(path, cachedResponse{etag: etag, body: body})
+	}
+	return body, nil
+}
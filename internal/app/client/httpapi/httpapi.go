@@ -0,0 +1,77 @@
+// Package httpapi - общее для HTTP-провайдеров секретов агента: хранилище,
+// из которого они берут поля записей, проверка токена и JSON-ответы.
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Vault - хранилище, из которого провайдер берет секреты
+type Vault interface {
+	IsMasterKeyUnlocked() bool
+	// SecretFields возвращает строковые поля записи по ID или точному названию
+	SecretFields(ctx context.Context, record string) (map[string]string, error)
+}
+
+// BearerToken возвращает токен из заголовка Authorization: Bearer; для
+// заголовка с другой схемой - пустую строку
+func BearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// Authorized пропускает к next только запросы, для которых tokenOf
+// возвращает токен провайдера token. Пустой токен не подходит никогда:
+// провайдер без токена не отдает секреты. Отказ пишет deny.
+func Authorized(token string, tokenOf func(*http.Request) string, deny func(http.ResponseWriter), log *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			got := tokenOf(r)
+			if token == "" || got == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				log.Warn("unauthorized secret request", "path", r.URL.Path, "remote", r.RemoteAddr)
+				deny(w)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// StatusOf возвращает HTTP-статус ответа на ошибку хранилища
+func StatusOf(err error) int {
+	if errors.Is(err, context.Canceled) {
+		return http.StatusServiceUnavailable
+	}
+
+	switch apperr.KindOf(err) {
+	case apperr.NotFound:
+		return http.StatusNotFound
+	case apperr.Conflict:
+		return http.StatusConflict
+	case apperr.Forbidden:
+		return http.StatusForbidden
+	case apperr.Invalid:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteJSON пишет ответ body; секреты в ответах не кэшируются
+func WriteJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+func TestAuthorized(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+	deny := func(w http.ResponseWriter) { w.WriteHeader(http.StatusUnauthorized) }
+
+	serve := func(token, header string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/secrets/db", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		Authorized(token, BearerToken, deny, log)(ok)(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("s3cret", "Bearer s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "Bearer other"))
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", "s3cret"), "только схема Bearer")
+	assert.Equal(t, http.StatusUnauthorized, serve("s3cret", ""))
+	// Провайдер без токена не пускает никого, в том числе запросы без токена
+	assert.Equal(t, http.StatusUnauthorized, serve("", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve("", "Bearer "))
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("read: %w", apperr.New(apperr.NotFound, "record not found")), http.StatusNotFound},
+		{apperr.New(apperr.Conflict, "ambiguous"), http.StatusConflict},
+		{apperr.New(apperr.Forbidden, "denied"), http.StatusForbidden},
+		{apperr.New(apperr.Invalid, "bad name"), http.StatusBadRequest},
+		{context.Canceled, http.StatusServiceUnavailable},
+		{io.ErrUnexpectedEOF, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, StatusOf(tt.err), tt.err.Error())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteJSON(rec, http.StatusTeapot, map[string]string{"value": "s3cret"})
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"value":"s3cret"}`, rec.Body.String())
+}
@@ -0,0 +1,237 @@
+// internal/app/client/icons.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Значки сайтов для логинов. Загрузка выключена по умолчанию (FETCH_ICONS=true),
+// потому что запрос к сайту раскрывает ему, что у пользователя есть учетная
+// запись: значок запрашивается напрямую с сайта, без участия сервера GophKeeper.
+// Кэш лежит в <config_dir>/icons, файлы зашифрованы мастер-ключом, а имена
+// файлов - HMAC от хоста, поэтому по кэшу не видно, какие сайты сохранены.
+
+const (
+	iconCacheDir  = "icons"
+	iconCacheTTL  = 30 * 24 * time.Hour
+	iconMaxSize   = 256 << 10
+	iconUserAgent = "GophKeeper"
+)
+
+// iconPaths - адреса, по которым ищется значок сайта, в порядке приоритета
+var iconPaths = []string{"/favicon.ico", "/apple-touch-icon.png"}
+
+// iconClient загружает значки напрямую с сайтов
+var iconClient = &http.Client{Timeout: 10 * time.Second}
+
+// ErrIconsDisabled возвращается, если загрузка значков не включена
+var ErrIconsDisabled = errors.New("загрузка значков отключена. Установите FETCH_ICONS=true")
+
+// Icon - значок сайта из кэша. Пустой Data означает, что у сайта значка нет.
+type Icon struct {
+	Host        string    `json:"host"`
+	ContentType string    `json:"content_type,omitempty"`
+	Data        []byte    `json:"data,omitempty"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// RecordIcon возвращает значок сайта логина. Значок берется из кэша,
+// а если его нет, он устарел или refresh - загружается с сайта.
+func (a *App) RecordIcon(ctx context.Context, id int, refresh bool) (*Icon, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Type != record.RecTypeLogin {
+		return nil, fmt.Errorf("значки доступны только для логинов, запись %d: %s", id, rec.Type)
+	}
+
+	host := ""
+	if rec.Preview != nil {
+		host = rec.Preview.Label
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return nil, fmt.Errorf("у записи %d не указан сайт", id)
+	}
+
+	return a.siteIcon(ctx, host, refresh)
+}
+
+// PrefetchIcons загружает значки для всех логинов, которых нет в кэше.
+// Возвращает число загруженных значков.
+func (a *App) PrefetchIcons(ctx context.Context) (int, error) {
+	if !a.config.FetchIcons {
+		return 0, ErrIconsDisabled
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return 0, ErrMasterKeyLocked
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeLogin})
+	if err != nil {
+		return 0, err
+	}
+
+	hosts := make(map[string]struct{})
+	for _, rec := range records {
+		if rec.Preview != nil && rec.Preview.Label != "" && !strings.ContainsAny(rec.Preview.Label, " /") {
+			hosts[rec.Preview.Label] = struct{}{}
+		}
+	}
+
+	fetched := 0
+	for host := range hosts {
+		if ctx.Err() != nil {
+			return fetched, ctx.Err()
+		}
+		if icon, err := a.loadIcon(host); err == nil && time.Since(icon.FetchedAt) < iconCacheTTL {
+			continue
+		}
+		if _, err := a.siteIcon(ctx, host, true); err != nil {
+			a.log.Warn("Не удалось загрузить значок", "host", host, "error", err)
+			continue
+		}
+		fetched++
+	}
+
+	return fetched, nil
+}
+
+func (a *App) siteIcon(ctx context.Context, host string, refresh bool) (*Icon, error) {
+	if !refresh {
+		if icon, err := a.loadIcon(host); err == nil && time.Since(icon.FetchedAt) < iconCacheTTL {
+			return icon, nil
+		}
+	}
+
+	if !a.config.FetchIcons {
+		return nil, ErrIconsDisabled
+	}
+
+	icon, err := fetchIcon(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.saveIcon(icon); err != nil {
+		a.log.Warn("Не удалось сохранить значок в кэш", "host", host, "error", err)
+	}
+
+	return icon, nil
+}
+
+// fetchIcon загружает значок с сайта. Отсутствие значка - не ошибка:
+// возвращается пустой значок, чтобы кэш не запрашивал сайт повторно.
+func fetchIcon(ctx context.Context, host string) (*Icon, error) {
+	icon := &Icon{Host: host, FetchedAt: time.Now()}
+
+	var lastErr error
+	for _, path := range iconPaths {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("неверный адрес сайта: %w", err)
+		}
+		req.Header.Set("User-Agent", iconUserAgent)
+
+		resp, err := iconClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, iconMaxSize+1))
+		_ = resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		contentType := resp.Header.Get("Content-Type")
+		if resp.StatusCode != http.StatusOK || len(data) == 0 || len(data) > iconMaxSize {
+			continue
+		}
+		if !strings.HasPrefix(contentType, "image/") {
+			contentType = http.DetectContentType(data)
+			if !strings.HasPrefix(contentType, "image/") {
+				continue
+			}
+		}
+
+		icon.ContentType = contentType
+		icon.Data = data
+		return icon, nil
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("ошибка загрузки значка %s: %w", host, lastErr)
+	}
+	return icon, nil
+}
+
+func (a *App) iconPath(host string) (string, error) {
+	name, err := a.encryptor.GenerateHMAC([]byte("icon:" + strings.ToLower(host)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(a.config.ConfigDir, iconCacheDir, name), nil
+}
+
+func (a *App) loadIcon(host string) (*Icon, error) {
+	path, err := a.iconPath(host)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.crypto.DecryptData(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки значка: %w", err)
+	}
+
+	var icon Icon
+	if err := json.Unmarshal(data, &icon); err != nil {
+		return nil, fmt.Errorf("поврежден кэш значка: %w", err)
+	}
+	return &icon, nil
+}
+
+func (a *App) saveIcon(icon *Icon) error {
+	path, err := a.iconPath(icon.Host)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(icon)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := a.crypto.EncryptData(data)
+	if err != nil {
+		return fmt.Errorf("ошибка шифрования значка: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, encrypted, 0600)
+}
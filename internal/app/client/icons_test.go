@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	gosync "sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+// testPNG - начало файла PNG, по которому значок можно найти в открытом виде
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte("icon-pixels"), 16)...)
+
+// iconSite подменяет сайты при загрузке значков и запоминает запросы
+type iconSite struct {
+	mu       gosync.Mutex
+	requests []string
+}
+
+func (s *iconSite) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.String())
+	s.mu.Unlock()
+
+	rec := httptest.NewRecorder()
+	if r.URL.Path == "/favicon.ico" {
+		rec.Header().Set("Content-Type", "image/png")
+		_, _ = rec.Write(testPNG)
+	} else {
+		rec.WriteHeader(http.StatusNotFound)
+	}
+	return rec.Result(), nil
+}
+
+func (s *iconSite) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// newIconApp возвращает разблокированное приложение с логином на сайте
+// example.com; запросы к сайтам уходят в iconSite
+func newIconApp(t *testing.T) (*App, int, *iconSite) {
+	t.Helper()
+
+	site := &iconSite{}
+	prev := iconClient
+	iconClient = &http.Client{Transport: site}
+	t.Cleanup(func() { iconClient = prev })
+
+	app := newTestApp(t)
+	require.NoError(t, app.InitMasterKey("password123"))
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	app.storage = NewMemoryStorage()
+
+	rec := &LocalRecord{
+		Type: record.RecTypeLogin,
+		Meta: json.RawMessage(`{"title":"Example","resource":"https://www.example.com/login"}`),
+	}
+	require.NoError(t, app.storage.SaveRecord(rec))
+	return app, rec.ID, site
+}
+
+func TestApp_RecordIcon_Disabled(t *testing.T) {
+	ctx := context.Background()
+	app, id, site := newIconApp(t)
+
+	_, err := app.RecordIcon(ctx, id, false)
+	assert.ErrorIs(t, err, ErrIconsDisabled)
+	_, err = app.RecordIcon(ctx, id, true)
+	assert.ErrorIs(t, err, ErrIconsDisabled)
+	_, err = app.PrefetchIcons(ctx)
+	assert.ErrorIs(t, err, ErrIconsDisabled)
+
+	assert.Zero(t, site.count(), "без согласия пользователя сайты не запрашиваются")
+	assert.NoDirExists(t, filepath.Join(app.config.ConfigDir, iconCacheDir))
+}
+
+func TestApp_RecordIcon_Cache(t *testing.T) {
+	ctx := context.Background()
+	app, id, site := newIconApp(t)
+	app.config.FetchIcons = true
+
+	icon, err := app.RecordIcon(ctx, id, false)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", icon.Host)
+	assert.Equal(t, testPNG, icon.Data)
+	assert.Equal(t, []string{"https://example.com/favicon.ico"}, site.requests)
+
+	t.Run("cache is encrypted", func(t *testing.T) {
+		entries, err := os.ReadDir(filepath.Join(app.config.ConfigDir, iconCacheDir))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.NotContains(t, entries[0].Name(), "example")
+
+		data, err := os.ReadFile(filepath.Join(app.config.ConfigDir, iconCacheDir, entries[0].Name()))
+		require.NoError(t, err)
+		assert.False(t, bytes.Contains(data, testPNG[:8]), "в кэше нет открытого PNG")
+		assert.NotContains(t, string(data), "example.com")
+	})
+
+	t.Run("cached icon is not fetched again", func(t *testing.T) {
+		cached, err := app.RecordIcon(ctx, id, false)
+		require.NoError(t, err)
+		assert.Equal(t, testPNG, cached.Data)
+
+		fetched, err := app.PrefetchIcons(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, fetched)
+		assert.Equal(t, 1, site.count())
+	})
+
+	t.Run("refresh fetches again", func(t *testing.T) {
+		_, err := app.RecordIcon(ctx, id, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, site.count())
+	})
+
+	t.Run("locked key hides the cache", func(t *testing.T) {
+		app.LockMasterKey()
+		_, err := app.RecordIcon(ctx, id, false)
+		assert.ErrorIs(t, err, ErrMasterKeyLocked)
+		assert.Equal(t, 2, site.count())
+	})
+}
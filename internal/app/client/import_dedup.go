@@ -0,0 +1,286 @@
+// internal/app/client/import_dedup.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// При импорте новая запись сравнивается с локальными, чтобы повторный перенос
+// не плодил копии. Дубликатом считается запись с теми же зашифрованными данными
+// или контрольной суммой (точная копия, всегда пропускается) и логин с тем же
+// ресурсом и именем пользователя. Логины сравниваются только при разблокированном
+// мастер-ключе: имя пользователя хранится в зашифрованных данных.
+
+// DuplicatePolicy - что делать с импортируемым логином, совпавшим с локальным
+type DuplicatePolicy string
+
+const (
+	// DuplicateSkip - оставить локальную запись без изменений
+	DuplicateSkip DuplicatePolicy = "skip"
+	// DuplicateOverwrite - заменить данные и метаданные локальной записи данными из копии
+	DuplicateOverwrite DuplicatePolicy = "overwrite"
+	// DuplicateMerge - дополнить локальную запись: пустые поля заполняются из копии,
+	// теги объединяются, непустые локальные значения сохраняются
+	DuplicateMerge DuplicatePolicy = "merge"
+)
+
+// ParseDuplicatePolicy разбирает политику из флага командной строки; пустая строка - skip
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return DuplicateSkip, nil
+	case DuplicateSkip, DuplicateOverwrite, DuplicateMerge:
+		return p, nil
+	}
+	return "", fmt.Errorf("неизвестная политика дубликатов %q: ожидается skip, overwrite или merge", s)
+}
+
+// ImportOptions - настройки импорта записей
+type ImportOptions struct {
+	// Rules раскладывают добавленные и обновленные записи по тегам и категориям (может быть nil)
+	Rules *ImportRules
+	// OnDuplicate - политика для совпавших логинов, по умолчанию skip
+	OnDuplicate DuplicatePolicy
+}
+
+// importIndex - локальные записи, с которыми сравниваются импортируемые
+type importIndex struct {
+	app        *App
+	byData     map[string]*LocalRecord
+	byChecksum map[string]*LocalRecord
+	byLogin    map[string]*LocalRecord
+}
+
+func (a *App) newImportIndex() (*importIndex, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения локальных записей: %w", err)
+	}
+
+	idx := &importIndex{
+		app:        a,
+		byData:     make(map[string]*LocalRecord, len(records)),
+		byChecksum: make(map[string]*LocalRecord),
+		byLogin:    make(map[string]*LocalRecord),
+	}
+	for _, rec := range records {
+		idx.add(rec)
+	}
+	return idx, nil
+}
+
+func (idx *importIndex) add(rec *LocalRecord) {
+	if rec.DeletedAt != nil {
+		return
+	}
+	if rec.EncryptedData != "" {
+		idx.byData[rec.EncryptedData] = rec
+	}
+	if rec.Checksum != "" {
+		idx.byChecksum[rec.Checksum] = rec
+	}
+	if key := idx.loginKey(rec); key != "" {
+		idx.byLogin[key] = rec
+	}
+}
+
+// find ищет локальную запись, совпадающую с импортируемой; exact - точная копия
+func (idx *importIndex) find(rec *LocalRecord) (existing *LocalRecord, exact bool) {
+	if rec.DeletedAt != nil {
+		return nil, false
+	}
+	if existing, ok := idx.byData[rec.EncryptedData]; ok && rec.EncryptedData != "" {
+		return existing, true
+	}
+	if existing, ok := idx.byChecksum[rec.Checksum]; ok && rec.Checksum != "" {
+		return existing, true
+	}
+	if key := idx.loginKey(rec); key != "" {
+		if existing, ok := idx.byLogin[key]; ok {
+			return existing, false
+		}
+	}
+	return nil, false
+}
+
+// loginKey - ресурс и имя пользователя логина или "", если сравнить нельзя
+func (idx *importIndex) loginKey(rec *LocalRecord) string {
+	if rec.Type != record.RecTypeLogin || !idx.app.IsMasterKeyUnlocked() {
+		return ""
+	}
+	resource := auditResource(rec.Meta)
+	if resource == "" {
+		return ""
+	}
+
+	var data record.LoginData
+	if err := idx.app.decryptRecordData(rec.EncryptedData, &data); err != nil {
+		idx.app.log.Debug("Не удалось расшифровать запись для поиска дубликатов", "record_id", rec.ID, "error", err)
+		return ""
+	}
+	username := strings.ToLower(strings.TrimSpace(data.Username))
+	if username == "" {
+		return ""
+	}
+	return resource + "\x00" + username
+}
+
+// resolveDuplicate применяет политику к импортируемой записи rec, совпавшей с existing.
+// Защищенные локальные записи не изменяются.
+func (a *App) resolveDuplicate(existing, rec *LocalRecord, opts ImportOptions, idx *importIndex, result *BackupResult) error {
+	result.Duplicates++
+	if opts.OnDuplicate == DuplicateSkip || opts.OnDuplicate == "" || record.IsLocked(existing.Meta) {
+		result.Skipped++
+		return nil
+	}
+
+	if err := applyImportRules(rec, opts.Rules, result); err != nil {
+		return err
+	}
+
+	updated := *existing
+	switch opts.OnDuplicate {
+	case DuplicateOverwrite:
+		updated.Type = rec.Type
+		updated.EncryptedData = rec.EncryptedData
+		updated.Meta = rec.Meta
+		updated.Checksum = ""
+	case DuplicateMerge:
+		changed, err := a.mergeImportedRecord(&updated, rec)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			result.Skipped++
+			return nil
+		}
+	}
+
+	updated.LastModified = time.Now()
+	updated.Synced = false
+	if updated.ServerID > 0 {
+		updated.Version++
+	}
+	if err := a.storage.SaveRecord(&updated); err != nil {
+		return fmt.Errorf("ошибка обновления записи %d: %w", existing.ID, err)
+	}
+	idx.add(&updated)
+
+	if opts.OnDuplicate == DuplicateMerge {
+		result.Merged++
+	} else {
+		result.Updated++
+	}
+	return nil
+}
+
+// mergeImportedRecord дополняет dst данными и метаданными src
+func (a *App) mergeImportedRecord(dst, src *LocalRecord) (bool, error) {
+	var local, imported map[string]interface{}
+	if err := a.decryptRecordData(dst.EncryptedData, &local); err != nil {
+		return false, fmt.Errorf("ошибка расшифровки записи %d: %w", dst.ID, err)
+	}
+	if err := a.decryptRecordData(src.EncryptedData, &imported); err != nil {
+		return false, fmt.Errorf("ошибка расшифровки записи из копии: %w", err)
+	}
+
+	dataChanged := false
+	for k, v := range imported {
+		if isEmptyValue(local[k]) && !isEmptyValue(v) {
+			local[k] = v
+			dataChanged = true
+		}
+	}
+
+	meta, metaChanged, err := mergeMeta(dst.Meta, src.Meta)
+	if err != nil {
+		return false, err
+	}
+
+	if dataChanged {
+		if dst.EncryptedData, err = a.encryptRecordData(local); err != nil {
+			return false, err
+		}
+		dst.Checksum = ""
+	}
+	dst.Meta = meta
+	return dataChanged || metaChanged, nil
+}
+
+// mergeMeta добавляет в метаданные dst отсутствующие ключи src и объединяет теги
+func mergeMeta(dst, src json.RawMessage) (json.RawMessage, bool, error) {
+	var local, imported map[string]json.RawMessage
+	if len(src) == 0 || json.Unmarshal(src, &imported) != nil {
+		return dst, false, nil
+	}
+	if len(dst) > 0 {
+		// Метаданные в неожиданном формате оставляем как есть
+		if err := json.Unmarshal(dst, &local); err != nil {
+			return dst, false, nil
+		}
+	}
+	if local == nil {
+		local = map[string]json.RawMessage{}
+	}
+
+	changed := false
+	for k, v := range imported {
+		if k == "tags" {
+			continue
+		}
+		if current, ok := local[k]; !ok || isEmptyJSON(current) {
+			if !isEmptyJSON(v) {
+				local[k] = v
+				changed = true
+			}
+		}
+	}
+
+	var localTags, importedTags []string
+	_ = json.Unmarshal(local["tags"], &localTags)
+	_ = json.Unmarshal(imported["tags"], &importedTags)
+	tags := localTags
+	for _, tag := range importedTags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != len(localTags) {
+		data, err := json.Marshal(tags)
+		if err != nil {
+			return nil, false, err
+		}
+		local["tags"] = data
+		changed = true
+	}
+
+	if !changed {
+		return dst, false, nil
+	}
+	merged, err := json.Marshal(local)
+	if err != nil {
+		return nil, false, err
+	}
+	return merged, true, nil
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	}
+	return false
+}
+
+func isEmptyJSON(raw json.RawMessage) bool {
+	s := strings.TrimSpace(string(raw))
+	return s == "" || s == "null" || s == `""`
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_ImportBackupRecord_Duplicates(t *testing.T) {
+	app := newTestApp(t)
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	encrypt := func(data interface{}) string {
+		enc, err := app.encryptRecordData(data)
+		require.NoError(t, err)
+		return enc
+	}
+	login := func(data map[string]string, meta string) *LocalRecord {
+		return &LocalRecord{
+			Type:          record.RecTypeLogin,
+			EncryptedData: encrypt(data),
+			Meta:          json.RawMessage(meta),
+			LastModified:  time.Now(),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policy     DuplicatePolicy
+		imported   *LocalRecord
+		wantData   map[string]interface{}
+		wantMeta   string
+		wantResult BackupResult
+	}{
+		{
+			name:       "skip",
+			policy:     DuplicateSkip,
+			imported:   login(map[string]string{"username": "Alice", "password": "new"}, `{"title":"GitHub","resource":"https://www.github.com/login"}`),
+			wantData:   map[string]interface{}{"username": "alice", "password": "old"},
+			wantMeta:   `{"title":"GitHub","resource":"github.com","tags":["dev"]}`,
+			wantResult: BackupResult{Skipped: 1, Duplicates: 1},
+		},
+		{
+			name:       "overwrite",
+			policy:     DuplicateOverwrite,
+			imported:   login(map[string]string{"username": "alice", "password": "new"}, `{"title":"GitHub work","resource":"github.com"}`),
+			wantData:   map[string]interface{}{"username": "alice", "password": "new"},
+			wantMeta:   `{"title":"GitHub work","resource":"github.com"}`,
+			wantResult: BackupResult{Updated: 1, Duplicates: 1},
+		},
+		{
+			name:       "merge",
+			policy:     DuplicateMerge,
+			imported:   login(map[string]string{"username": "alice", "password": "new", "notes": "2FA on"}, `{"title":"Other","resource":"github.com","category":"Dev","tags":["dev","work"]}`),
+			wantData:   map[string]interface{}{"username": "alice", "password": "old", "notes": "2FA on"},
+			wantMeta:   `{"title":"GitHub","resource":"github.com","category":"Dev","tags":["dev","work"]}`,
+			wantResult: BackupResult{Merged: 1, Duplicates: 1},
+		},
+		{
+			name:       "other username is imported",
+			policy:     DuplicateSkip,
+			imported:   login(map[string]string{"username": "bob", "password": "x"}, `{"title":"GitHub","resource":"github.com"}`),
+			wantData:   map[string]interface{}{"username": "alice", "password": "old"},
+			wantMeta:   `{"title":"GitHub","resource":"github.com","tags":["dev"]}`,
+			wantResult: BackupResult{Imported: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.storage = NewMemoryStorage()
+			local := login(map[string]string{"username": "alice", "password": "old"}, `{"title":"GitHub","resource":"github.com","tags":["dev"]}`)
+			require.NoError(t, app.storage.SaveRecord(local))
+
+			idx, err := app.newImportIndex()
+			require.NoError(t, err)
+
+			result := &BackupResult{}
+			opts := ImportOptions{OnDuplicate: tt.policy}
+			require.NoError(t, app.importBackupRecord(tt.imported, idx, opts, result))
+			assert.Equal(t, tt.wantResult, *result)
+
+			got, err := app.storage.GetRecord(local.ID)
+			require.NoError(t, err)
+			var data map[string]interface{}
+			require.NoError(t, app.decryptRecordData(got.EncryptedData, &data))
+			assert.Equal(t, tt.wantData, data)
+			assert.JSONEq(t, tt.wantMeta, string(got.Meta))
+
+			// Повторный импорт той же записи - точная копия
+			again := *tt.imported
+			again.ID = 0
+			result = &BackupResult{}
+			require.NoError(t, app.importBackupRecord(&again, idx, opts, result))
+			assert.Equal(t, BackupResult{Skipped: 1, Duplicates: 1}, *result)
+		})
+	}
+}
+
+func TestApp_ImportBackupRecord_LockedDuplicate(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	data, err := app.encryptRecordData(record.LoginData{Username: "alice", Password: "old"})
+	require.NoError(t, err)
+	local := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: data, Meta: json.RawMessage(`{"resource":"github.com","locked":true}`)}
+	require.NoError(t, app.storage.SaveRecord(local))
+
+	data, err = app.encryptRecordData(record.LoginData{Username: "alice", Password: "new"})
+	require.NoError(t, err)
+	imported := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: data, Meta: json.RawMessage(`{"resource":"github.com"}`)}
+
+	idx, err := app.newImportIndex()
+	require.NoError(t, err)
+	result := &BackupResult{}
+	require.NoError(t, app.importBackupRecord(imported, idx, ImportOptions{OnDuplicate: DuplicateOverwrite}, result))
+	assert.Equal(t, BackupResult{Skipped: 1, Duplicates: 1}, *result)
+
+	got, err := app.storage.GetRecord(local.ID)
+	require.NoError(t, err)
+	assert.Equal(t, local.EncryptedData, got.EncryptedData)
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	p, err := ParseDuplicatePolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, DuplicateSkip, p)
+
+	p, err = ParseDuplicatePolicy(" Merge ")
+	require.NoError(t, err)
+	assert.Equal(t, DuplicateMerge, p)
+
+	_, err = ParseDuplicatePolicy("replace")
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+// internal/app/client/import_rules.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Правила импорта раскладывают записи по тегам и категориям при восстановлении,
+// чтобы перенесенное хранилище не оказалось плоским списком. Правила читаются
+// из JSON-файла:
+//
+//	{
+//	  "rules": [
+//	    {"title": "(?i)aws|gcp", "tags": ["cloud"], "category": "Инфраструктура"},
+//	    {"resource": "github\\.com$", "tags": ["dev"]}
+//	  ]
+//	}
+//
+// Применяются все подходящие правила: теги добавляются к уже имеющимся, категория
+// берется из первого подходящего правила и только если у записи ее еще нет.
+// Защищенные записи (record lock) не изменяются.
+
+// ImportRule - правило: регулярные выражения по названию и ресурсу из открытых
+// метаданных и то, что назначается подходящей записи. Если заданы оба выражения,
+// должны совпасть оба.
+type ImportRule struct {
+	Title    string   `json:"title,omitempty"`
+	Resource string   `json:"resource,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Category string   `json:"category,omitempty"`
+
+	title    *regexp.Regexp
+	resource *regexp.Regexp
+}
+
+// ImportRules - набор правил импорта
+type ImportRules struct {
+	Rules []ImportRule `json:"rules"`
+}
+
+// LoadImportRules читает и проверяет файл правил импорта
+func LoadImportRules(path string) (*ImportRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения правил импорта: %w", err)
+	}
+
+	var rules ImportRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("ошибка разбора правил импорта %s: %w", path, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r *ImportRules) compile() error {
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Title == "" && rule.Resource == "" {
+			return fmt.Errorf("правило %d: задайте title или resource", i+1)
+		}
+		if len(rule.Tags) == 0 && rule.Category == "" {
+			return fmt.Errorf("правило %d: задайте tags или category", i+1)
+		}
+
+		var err error
+		if rule.title, err = compileRulePattern(rule.Title); err != nil {
+			return fmt.Errorf("правило %d: неверное выражение title: %w", i+1, err)
+		}
+		if rule.resource, err = compileRulePattern(rule.Resource); err != nil {
+			return fmt.Errorf("правило %d: неверное выражение resource: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func (rule *ImportRule) matches(title, resource string) bool {
+	if rule.title != nil && !rule.title.MatchString(title) {
+		return false
+	}
+	return rule.resource == nil || rule.resource.MatchString(resource)
+}
+
+// Apply применяет правила к открытым метаданным записи. Возвращает новые
+// метаданные и признак того, что они изменились; остальные ключи сохраняются.
+func (r *ImportRules) Apply(meta json.RawMessage) (json.RawMessage, bool, error) {
+	if r == nil || len(r.Rules) == 0 || record.IsLocked(meta) {
+		return meta, false, nil
+	}
+
+	var known struct {
+		Title    string   `json:"title"`
+		Resource string   `json:"resource"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+	}
+	if len(meta) > 0 {
+		// Метаданные в неожиданном формате оставляем как есть
+		if err := json.Unmarshal(meta, &known); err != nil {
+			return meta, false, nil
+		}
+	}
+
+	tags, category := known.Tags, known.Category
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if !rule.matches(known.Title, known.Resource) {
+			continue
+		}
+		for _, tag := range rule.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if category == "" {
+			category = rule.Category
+		}
+	}
+
+	if len(tags) == len(known.Tags) && category == known.Category {
+		return meta, false, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if len(meta) > 0 {
+		if err := json.Unmarshal(meta, &fields); err != nil {
+			return meta, false, nil
+		}
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+	var err error
+	if fields["tags"], err = json.Marshal(tags); err != nil {
+		return nil, false, err
+	}
+	if category != "" {
+		if fields["category"], err = json.Marshal(category); err != nil {
+			return nil, false, err
+		}
+	}
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestRules(t *testing.T, content string) (*ImportRules, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return LoadImportRules(path)
+}
+
+func TestImportRules_Apply(t *testing.T) {
+	rules, err := loadTestRules(t, `{"rules": [
+		{"title": "(?i)aws|gcp", "tags": ["cloud"], "category": "Инфраструктура"},
+		{"resource": "github\\.com$", "tags": ["dev", "cloud"]},
+		{"title": "(?i)prod", "resource": "github\\.com$", "category": "Продакшен"}
+	]}`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		meta        string
+		wantMeta    string
+		wantChanged bool
+	}{
+		{
+			name:        "title rule",
+			meta:        `{"title":"AWS root","favorite":true}`,
+			wantMeta:    `{"title":"AWS root","favorite":true,"tags":["cloud"],"category":"Инфраструктура"}`,
+			wantChanged: true,
+		},
+		{
+			name:        "all matching rules, first category wins",
+			meta:        `{"title":"prod deploy","resource":"api.github.com","tags":["ci"]}`,
+			wantMeta:    `{"title":"prod deploy","resource":"api.github.com","tags":["ci","dev","cloud"],"category":"Продакшен"}`,
+			wantChanged: true,
+		},
+		{
+			name:        "existing category kept",
+			meta:        `{"title":"GCP","category":"Личное"}`,
+			wantMeta:    `{"title":"GCP","category":"Личное","tags":["cloud"]}`,
+			wantChanged: true,
+		},
+		{
+			name:     "nothing matches",
+			meta:     `{"title":"Bank"}`,
+			wantMeta: `{"title":"Bank"}`,
+		},
+		{
+			name:     "already organized",
+			meta:     `{"title":"AWS","tags":["cloud"],"category":"Инфраструктура"}`,
+			wantMeta: `{"title":"AWS","tags":["cloud"],"category":"Инфраструктура"}`,
+		},
+		{
+			name:     "locked record",
+			meta:     `{"title":"AWS root","locked":true}`,
+			wantMeta: `{"title":"AWS root","locked":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, changed, err := rules.Apply(json.RawMessage(tt.meta))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.JSONEq(t, tt.wantMeta, string(meta))
+		})
+	}
+}
+
+func TestLoadImportRules_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no pattern":  `{"rules": [{"tags": ["cloud"]}]}`,
+		"no action":   `{"rules": [{"title": "aws"}]}`,
+		"bad pattern": `{"rules": [{"title": "(", "tags": ["cloud"]}]}`,
+		"bad json":    `{"rules": `,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadTestRules(t, content)
+			assert.Error(t, err)
+		})
+	}
+}
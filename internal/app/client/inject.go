@@ -0,0 +1,66 @@
+// internal/app/client/inject.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+)
+
+// RenderTemplate выполняет шаблон text/template и подставляет в него секреты.
+// Функция secret принимает ссылку gk://<запись>/<поле> или запись и поле
+// отдельными аргументами:
+//
+//	password: {{ secret "gk://Postgres/password" }}
+//	user: {{ secret "Postgres" "username" }}
+//
+// Каждая ссылка разрешается один раз. Неразрешенная ссылка прерывает
+// выполнение: файл конфигурации не должен получиться с пропусками.
+func (a *App) RenderTemplate(ctx context.Context, name, text string) ([]byte, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	resolved := make(map[SecretRef]string)
+	secret := func(args ...string) (string, error) {
+		var ref SecretRef
+		switch len(args) {
+		case 1:
+			r, err := ParseSecretRef(args[0])
+			if err != nil {
+				return "", err
+			}
+			ref = r
+		case 2:
+			ref = SecretRef{Record: args[0], Field: args[1]}
+		default:
+			return "", fmt.Errorf("%w: secret ожидает ссылку или запись и поле, получено аргументов: %d", ErrInvalidSecretRef, len(args))
+		}
+
+		if value, ok := resolved[ref]; ok {
+			return value, nil
+		}
+		value, err := a.ResolveSecretRef(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		resolved[ref] = value
+		return value, nil
+	}
+
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"secret": secret}).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора шаблона: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, fmt.Errorf("ошибка выполнения шаблона: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
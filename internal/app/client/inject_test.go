@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_RenderTemplate(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	ctx := context.Background()
+
+	tmpl := "user: {{ secret \"Postgres\" \"username\" }}\npassword: {{ secret \"gk://Postgres/password\" }}\n"
+
+	_, err := app.RenderTemplate(ctx, "config.tmpl", tmpl)
+	require.ErrorIs(t, err, ErrMasterKeyLocked)
+
+	require.NoError(t, app.InitMasterKey("password123"))
+	encrypted, err := app.encryptRecordData(record.LoginData{Username: "app", Password: "s3cret"})
+	require.NoError(t, err)
+	meta, err := json.Marshal(map[string]string{"title": "Postgres"})
+	require.NoError(t, err)
+	require.NoError(t, app.storage.SaveRecord(&LocalRecord{
+		Type: record.RecTypeLogin, EncryptedData: encrypted, Meta: meta, LastModified: time.Now(),
+	}))
+
+	out, err := app.RenderTemplate(ctx, "config.tmpl", tmpl)
+	require.NoError(t, err)
+	assert.Equal(t, "user: app\npassword: s3cret\n", string(out))
+
+	_, err = app.RenderTemplate(ctx, "config.tmpl", `{{ secret "gk://Postgres/token" }}`)
+	assert.ErrorIs(t, err, ErrNoSecretField)
+
+	_, err = app.RenderTemplate(ctx, "config.tmpl", `{{ secret "Postgres" }}`)
+	assert.ErrorIs(t, err, ErrInvalidSecretRef)
+}
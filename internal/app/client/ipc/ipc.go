@@ -0,0 +1,204 @@
+// internal/app/client/ipc/ipc.go
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	gosync "sync"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Протокол: по соединению передаются JSON-объекты, по одному на строку.
+// На каждый Request агент отвечает одним Response.
+//
+// Транспорт зависит от ОС: unix-сокет с правами 0600 в директории конфигурации
+// или именованный канал Windows с ACL, разрешающим доступ только текущему пользователю.
+
+var (
+	// ErrAgentNotRunning агент не запущен или недоступен по адресу
+	ErrAgentNotRunning = errors.New("агент не запущен. Выполните: gophkeeper agent start или gophkeeper agent install")
+	// ErrAgentRunning по адресу уже отвечает другой экземпляр агента
+	ErrAgentRunning = errors.New("агент уже запущен")
+	// ErrUnknownMethod агент не поддерживает метод
+	ErrUnknownMethod = errors.New("неизвестный метод")
+)
+
+// Request запрос к агенту
+type Request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response ответ агента
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// Kind - вид ошибки apperr, чтобы клиент различал отказ, отсутствие записи и т.д.
+	Kind string `json:"kind,omitempty"`
+}
+
+// HandlerFunc обработчик метода; результат сериализуется в JSON
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Server принимает запросы локальных инструментов к агенту
+type Server struct {
+	addr     string
+	handlers map[string]HandlerFunc
+	log      *slog.Logger
+
+	mu    gosync.Mutex
+	conns map[net.Conn]struct{}
+	wg    gosync.WaitGroup
+}
+
+func NewServer(addr string, log *slog.Logger) *Server {
+	return &Server{
+		addr:     addr,
+		handlers: make(map[string]HandlerFunc),
+		log:      log.With("component", "ipc"),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Handle регистрирует обработчик метода. Вызывается до Serve.
+func (s *Server) Handle(method string, h HandlerFunc) {
+	s.handlers[method] = h
+}
+
+// Serve слушает адрес до отмены контекста
+func (s *Server) Serve(ctx context.Context) error {
+	listener, err := listen(s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("IPC агента запущен", "address", s.addr)
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+
+		s.mu.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				s.wg.Wait()
+				return nil
+			}
+			s.log.Error("Ошибка приема соединения", "error", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(ctx, conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	enc := json.NewEncoder(conn)
+
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+
+		if err := enc.Encode(s.dispatch(ctx, &req)); err != nil {
+			s.log.Debug("Ошибка отправки ответа", "error", err)
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(ctx context.Context, req *Request) *Response {
+	handler, ok := s.handlers[req.Method]
+	if !ok {
+		return &Response{Error: fmt.Sprintf("%s: %s", ErrUnknownMethod, req.Method)}
+	}
+
+	result, err := handler(ctx, req.Params)
+	if err != nil {
+		return &Response{Error: err.Error(), Kind: string(apperr.KindOf(err))}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &Response{Error: fmt.Sprintf("ошибка сериализации ответа: %v", err)}
+	}
+
+	return &Response{Result: data}
+}
+
+// Call выполняет один запрос к агенту. result может быть nil.
+func Call(ctx context.Context, addr, method string, params, result interface{}) error {
+	conn, err := dial(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Отмена контекста прерывает ожидание ответа
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	req := Request{Method: method}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return fmt.Errorf("ошибка сериализации запроса: %w", err)
+		}
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("ошибка отправки запроса агенту: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("ошибка чтения ответа агента: %w", err)
+	}
+
+	if resp.Error != "" {
+		if resp.Kind != "" {
+			return apperr.New(apperr.Kind(resp.Kind), resp.Error)
+		}
+		return errors.New(resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("ошибка разбора ответа агента: %w", err)
+		}
+	}
+
+	return nil
+}
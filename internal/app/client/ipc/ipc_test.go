@@ -0,0 +1,140 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// startServer запускает сервер на адресе addr и ждет, пока он начнет
+// принимать соединения. Сервер останавливается в конце теста.
+func startServer(t *testing.T, addr string, handlers map[string]HandlerFunc) {
+	t.Helper()
+
+	srv := NewServer(addr, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for method, h := range handlers {
+		srv.Handle(method, h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		conn, err := dial(ctx, addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func echoHandlers() map[string]HandlerFunc {
+	return map[string]HandlerFunc{
+		"echo": func(_ context.Context, params json.RawMessage) (interface{}, error) {
+			var p map[string]string
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return p, nil
+		},
+		"denied": func(context.Context, json.RawMessage) (interface{}, error) {
+			return nil, apperr.New(apperr.Forbidden, "доступ запрещен")
+		},
+		"failed": func(context.Context, json.RawMessage) (interface{}, error) {
+			return nil, errors.New("сбой")
+		},
+	}
+}
+
+func TestCall(t *testing.T) {
+	ctx := context.Background()
+	addr := testAddress(t)
+	startServer(t, addr, echoHandlers())
+
+	var got map[string]string
+	require.NoError(t, Call(ctx, addr, "echo", map[string]string{"ref": "gk://db/password"}, &got))
+	assert.Equal(t, map[string]string{"ref": "gk://db/password"}, got)
+
+	// Вид ошибки apperr передается клиенту
+	err := Call(ctx, addr, "denied", nil, nil)
+	assert.Equal(t, apperr.Forbidden, apperr.KindOf(err))
+	assert.EqualError(t, err, "доступ запрещен")
+
+	err = Call(ctx, addr, "failed", nil, nil)
+	assert.EqualError(t, err, "сбой")
+
+	err = Call(ctx, addr, "missing", nil, nil)
+	assert.ErrorContains(t, err, ErrUnknownMethod.Error())
+}
+
+// TestServer_Framing проверяет формат обмена: JSON-объекты по одному на
+// строку, ответы в порядке запросов в одном соединении
+func TestServer_Framing(t *testing.T) {
+	ctx := context.Background()
+	addr := testAddress(t)
+	startServer(t, addr, echoHandlers())
+
+	conn, err := dial(ctx, addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Несколько запросов одной записью
+	_, err = io.WriteString(conn,
+		`{"method":"echo","params":{"n":"1"}}`+"\n"+
+			`{"method":"denied"}`+"\n"+
+			`{"method":"echo","params":{"n":"2"}}`+"\n")
+	require.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	readLine := func() string {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+	assert.Equal(t, `{"result":{"n":"1"}}`+"\n", readLine())
+	assert.Equal(t, `{"error":"доступ запрещен","kind":"forbidden"}`+"\n", readLine())
+	assert.Equal(t, `{"result":{"n":"2"}}`+"\n", readLine())
+
+	// Поврежденный запрос закрывает соединение без ответа
+	_, err = io.WriteString(conn, "not json\n")
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = r.ReadString('\n')
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestCall_ContextCanceled(t *testing.T) {
+	addr := testAddress(t)
+	release := make(chan struct{})
+	startServer(t, addr, map[string]HandlerFunc{
+		"slow": func(ctx context.Context, _ json.RawMessage) (interface{}, error) {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil, nil
+		},
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, Call(ctx, addr, "slow", nil, nil), context.DeadlineExceeded)
+}
@@ -0,0 +1,63 @@
+// internal/app/client/ipc/ipc_unix.go
+//go:build !windows
+
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const socketName = "agent.sock"
+
+// DefaultAddress возвращает путь к unix-сокету агента в директории конфигурации
+func DefaultAddress(configDir string) string {
+	return filepath.Join(configDir, socketName)
+}
+
+func listen(addr string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(addr), 0700); err != nil {
+		return nil, fmt.Errorf("ошибка создания директории сокета: %w", err)
+	}
+
+	// Сокет мог остаться после аварийного завершения агента
+	if _, err := os.Stat(addr); err == nil {
+		if conn, err := net.Dial("unix", addr); err == nil {
+			_ = conn.Close()
+			return nil, ErrAgentRunning
+		}
+		if err := os.Remove(addr); err != nil {
+			return nil, fmt.Errorf("ошибка удаления старого сокета: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания сокета: %w", err)
+	}
+
+	// Директория конфигурации уже закрыта (0700), права на сам сокет - вторая линия защиты
+	if err := os.Chmod(addr, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("ошибка установки прав на сокет: %w", err)
+	}
+
+	return listener, nil
+}
+
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", addr)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+			return nil, ErrAgentNotRunning
+		}
+		return nil, fmt.Errorf("ошибка подключения к агенту: %w", err)
+	}
+	return conn, nil
+}
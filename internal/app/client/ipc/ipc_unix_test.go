@@ -0,0 +1,62 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAddress(t *testing.T) string {
+	return DefaultAddress(t.TempDir())
+}
+
+func TestListen_SocketAccess(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	addr := DefaultAddress(dir)
+	startServer(t, addr, echoHandlers())
+
+	// Сокет доступен только владельцу, директория создается закрытой
+	info, err := os.Stat(addr)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSocket, info.Mode().Type())
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	info, err = os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	// Второй агент на том же адресе не запускается и не перехватывает сокет
+	_, err = listen(addr)
+	assert.ErrorIs(t, err, ErrAgentRunning)
+	assert.NoError(t, Call(context.Background(), addr, "echo", map[string]string{}, nil))
+}
+
+func TestListen_StaleSocket(t *testing.T) {
+	addr := testAddress(t)
+
+	// Сокет остался после аварийного завершения агента
+	l, err := listen(addr)
+	require.NoError(t, err)
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+	require.FileExists(t, addr)
+
+	assert.ErrorIs(t, Call(context.Background(), addr, "echo", nil, nil), ErrAgentNotRunning)
+
+	// Новый агент заменяет такой сокет
+	l, err = listen(addr)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestCall_NotRunning(t *testing.T) {
+	err := Call(context.Background(), testAddress(t), "status", nil, nil)
+	assert.ErrorIs(t, err, ErrAgentNotRunning)
+}
@@ -0,0 +1,236 @@
+// internal/app/client/ipc/ipc_windows.go
+//go:build windows
+
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strings"
+	gosync "sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	pipePrefix     = `\\.\pipe\`
+	pipeBufferSize = 64 * 1024
+	dialRetryDelay = 50 * time.Millisecond
+)
+
+// DefaultAddress возвращает имя именованного канала агента.
+// Имя пользователя в имени канала разводит агентов разных пользователей одной машины.
+func DefaultAddress(_ string) string {
+	name := "gophkeeper-agent"
+	if u, err := user.Current(); err == nil {
+		name += "-" + strings.NewReplacer(`\`, "-", "/", "-", " ", "_").Replace(u.Username)
+	}
+	return pipePrefix + name
+}
+
+// pipeSecurity возвращает дескриптор безопасности, дающий полный доступ
+// только текущему пользователю; остальные (включая администраторов) не подключатся
+func pipeSecurity() (*windows.SecurityAttributes, error) {
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения SID пользователя: %w", err)
+	}
+
+	sd, err := windows.SecurityDescriptorFromString(fmt.Sprintf("D:P(A;;GA;;;%s)", tokenUser.User.Sid.String()))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания дескриптора безопасности: %w", err)
+	}
+
+	sa := &windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(*sa))
+	return sa, nil
+}
+
+type pipeListener struct {
+	path string
+	sa   *windows.SecurityAttributes
+
+	mu      gosync.Mutex
+	pending windows.Handle // экземпляр канала, ожидающий подключения
+	closed  bool
+}
+
+func listen(addr string) (net.Listener, error) {
+	sa, err := pipeSecurity()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &pipeListener{path: addr, sa: sa}
+
+	// Первый экземпляр создается с FILE_FLAG_FIRST_PIPE_INSTANCE: если канал с таким
+	// именем уже создан (другим агентом или чужим процессом), запуск завершится ошибкой
+	h, err := l.createInstance(true)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) || errors.Is(err, windows.ERROR_PIPE_BUSY) {
+			return nil, ErrAgentRunning
+		}
+		return nil, fmt.Errorf("ошибка создания именованного канала: %w", err)
+	}
+	l.pending = h
+
+	return l, nil
+}
+
+func (l *pipeListener) createInstance(first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(l.path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+
+	return windows.CreateNamedPipe(name, flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, l.sa)
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, net.ErrClosed
+	}
+	h := l.pending
+	l.pending = windows.InvalidHandle
+	l.mu.Unlock()
+
+	if h == windows.InvalidHandle {
+		var err error
+		if h, err = l.createInstance(false); err != nil {
+			return nil, fmt.Errorf("ошибка создания экземпляра канала: %w", err)
+		}
+
+		l.mu.Lock()
+		closed := l.closed
+		l.mu.Unlock()
+		if closed {
+			_ = windows.CloseHandle(h)
+			return nil, net.ErrClosed
+		}
+	}
+
+	// Блокирующее ожидание клиента; Close разблокирует его собственным подключением
+	err := windows.ConnectNamedPipe(h, nil)
+	if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		_ = windows.CloseHandle(h)
+		return nil, fmt.Errorf("ошибка ожидания подключения: %w", err)
+	}
+
+	l.mu.Lock()
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		_ = windows.CloseHandle(h)
+		return nil, net.ErrClosed
+	}
+
+	return newPipeConn(h, l.path, true), nil
+}
+
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	pending := l.pending
+	l.pending = windows.InvalidHandle
+	l.mu.Unlock()
+
+	if pending != windows.InvalidHandle {
+		return windows.CloseHandle(pending)
+	}
+
+	// Accept ждет в ConnectNamedPipe - подключаемся сами, чтобы он вернулся
+	if h, err := openPipe(l.path); err == nil {
+		_ = windows.CloseHandle(h)
+	}
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.path)
+}
+
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	for {
+		h, err := openPipe(addr)
+		if err == nil {
+			return newPipeConn(h, addr, false), nil
+		}
+
+		switch {
+		case errors.Is(err, windows.ERROR_FILE_NOT_FOUND):
+			return nil, ErrAgentNotRunning
+		case errors.Is(err, windows.ERROR_PIPE_BUSY):
+			// Все экземпляры заняты: агент создаст новый после текущего подключения
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(dialRetryDelay):
+			}
+		default:
+			return nil, fmt.Errorf("ошибка подключения к агенту: %w", err)
+		}
+	}
+}
+
+func openPipe(path string) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+
+	return windows.CreateFile(name, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil,
+		windows.OPEN_EXISTING, windows.SECURITY_SQOS_PRESENT|windows.SECURITY_IDENTIFICATION, 0)
+}
+
+// pipeConn соединение по именованному каналу с синхронным вводом-выводом.
+// Дедлайны не поддерживаются: чтение прерывается закрытием соединения.
+type pipeConn struct {
+	*os.File
+	handle windows.Handle
+	server bool
+	addr   pipeAddr
+}
+
+func newPipeConn(h windows.Handle, path string, server bool) *pipeConn {
+	return &pipeConn{File: os.NewFile(uintptr(h), path), handle: h, server: server, addr: pipeAddr(path)}
+}
+
+func (c *pipeConn) Close() error {
+	// Синхронное чтение не прерывается закрытием дескриптора;
+	// отключение клиента завершает его с ошибкой
+	if c.server {
+		_ = windows.DisconnectNamedPipe(c.handle)
+	}
+	return c.File.Close()
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return c.addr }
+func (c *pipeConn) RemoteAddr() net.Addr { return c.addr }
+
+func (c *pipeConn) SetDeadline(time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(time.Time) error { return nil }
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
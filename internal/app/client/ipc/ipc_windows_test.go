@@ -0,0 +1,13 @@
+//go:build windows
+
+package ipc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testAddress(t *testing.T) string {
+	return fmt.Sprintf(`%sgophkeeper-test-%d`, pipePrefix, time.Now().UnixNano())
+}
@@ -0,0 +1,112 @@
+// Package k8s отдает секреты хранилища в кластер Kubernetes по HTTP.
+// Формат ответов подходит для webhook-провайдера External Secrets Operator:
+// значение поля извлекается JSONPath-выражением $.value, вся запись - $.
+package k8s
+
+import (
+	"net/http"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/app/client/httpapi"
+)
+
+// ValueResponse - значение одного поля записи
+type ValueResponse struct {
+	Value string `json:"value"`
+}
+
+// ErrorResponse - тело ответа с ошибкой
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler обслуживает запросы секретов:
+//
+//	GET /healthz                      - готовность: 503, пока мастер-ключ заблокирован
+//	GET /v1/secrets/{record}          - все поля записи объектом {"поле": "значение"}
+//	GET /v1/secrets/{record}/{field}  - одно поле: {"value": "..."}
+//
+// Запросы секретов требуют заголовка Authorization: Bearer <токен>.
+// Название записи с "/" экранируется как %2F.
+type Handler struct {
+	vault      httpapi.Vault
+	log        *slog.Logger
+	mux        *http.ServeMux
+	authorized func(http.HandlerFunc) http.HandlerFunc
+}
+
+// NewHandler создает обработчик; token - общий секрет с клиентами в кластере
+func NewHandler(vault httpapi.Vault, token string, log *slog.Logger) *Handler {
+	h := &Handler{
+		vault: vault,
+		log:   log.With("component", "k8s_provider"),
+		mux:   http.NewServeMux(),
+	}
+	h.authorized = httpapi.Authorized(token, httpapi.BearerToken, func(w http.ResponseWriter) {
+		httpapi.WriteJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "unauthorized"})
+	}, h.log)
+
+	h.mux.HandleFunc("GET /healthz", h.healthz)
+	h.mux.HandleFunc("GET /v1/secrets/{record}", h.authorized(h.getRecord))
+	h.mux.HandleFunc("GET /v1/secrets/{record}/{field}", h.authorized(h.getField))
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) healthz(w http.ResponseWriter, _ *http.Request) {
+	if !h.vault.IsMasterKeyUnlocked() {
+		httpapi.WriteJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "master key is locked"})
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) getRecord(w http.ResponseWriter, r *http.Request) {
+	fields, ok := h.fields(w, r)
+	if !ok {
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, fields)
+}
+
+func (h *Handler) getField(w http.ResponseWriter, r *http.Request) {
+	fields, ok := h.fields(w, r)
+	if !ok {
+		return
+	}
+
+	value, found := fields[r.PathValue("field")]
+	if !found {
+		httpapi.WriteJSON(w, http.StatusNotFound, ErrorResponse{Error: "field not found"})
+		return
+	}
+	httpapi.WriteJSON(w, http.StatusOK, ValueResponse{Value: value})
+}
+
+// fields читает запись из пути запроса; при ошибке сам пишет ответ
+func (h *Handler) fields(w http.ResponseWriter, r *http.Request) (map[string]string, bool) {
+	name := r.PathValue("record")
+
+	if !h.vault.IsMasterKeyUnlocked() {
+		httpapi.WriteJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: "master key is locked"})
+		return nil, false
+	}
+
+	fields, err := h.vault.SecretFields(r.Context(), name)
+	if err != nil {
+		status := httpapi.StatusOf(err)
+		if status == http.StatusInternalServerError {
+			h.log.Error("failed to read secret", "record", name, "error", err)
+		}
+		httpapi.WriteJSON(w, status, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	h.log.Info("secret served", "record", name, "field", r.PathValue("field"), "remote", r.RemoteAddr)
+	return fields, true
+}
@@ -0,0 +1,150 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+const testToken = "provider-token-0123456789"
+
+var errTestNotFound = apperr.New(apperr.NotFound, "record not found")
+
+type fakeVault struct {
+	unlocked bool
+	records  map[string]map[string]string
+}
+
+func (v *fakeVault) IsMasterKeyUnlocked() bool { return v.unlocked }
+
+func (v *fakeVault) SecretFields(_ context.Context, record string) (map[string]string, error) {
+	fields, ok := v.records[record]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errTestNotFound, record)
+	}
+	return fields, nil
+}
+
+func newTestServer(t *testing.T, vault *fakeVault) *httptest.Server {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(NewHandler(vault, testToken, log))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, url, token string) (int, map[string]string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestHandler(t *testing.T) {
+	vault := &fakeVault{
+		unlocked: true,
+		records: map[string]map[string]string{
+			"db/prod": {"username": "app", "password": "s3cret"},
+		},
+	}
+	srv := newTestServer(t, vault)
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+		wantBody   map[string]string
+	}{
+		{
+			name:       "field",
+			path:       "/v1/secrets/db%2Fprod/password",
+			token:      testToken,
+			wantStatus: http.StatusOK,
+			wantBody:   map[string]string{"value": "s3cret"},
+		},
+		{
+			name:       "whole record",
+			path:       "/v1/secrets/db%2Fprod",
+			token:      testToken,
+			wantStatus: http.StatusOK,
+			wantBody:   map[string]string{"username": "app", "password": "s3cret"},
+		},
+		{
+			name:       "missing field",
+			path:       "/v1/secrets/db%2Fprod/token",
+			token:      testToken,
+			wantStatus: http.StatusNotFound,
+			wantBody:   map[string]string{"error": "field not found"},
+		},
+		{
+			name:       "missing record",
+			path:       "/v1/secrets/unknown/password",
+			token:      testToken,
+			wantStatus: http.StatusNotFound,
+			wantBody:   map[string]string{"error": "record not found: unknown"},
+		},
+		{
+			name:       "no token",
+			path:       "/v1/secrets/db%2Fprod/password",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   map[string]string{"error": "unauthorized"},
+		},
+		{
+			name:       "wrong token",
+			path:       "/v1/secrets/db%2Fprod/password",
+			token:      "wrong",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   map[string]string{"error": "unauthorized"},
+		},
+		{
+			name:       "healthz without token",
+			path:       "/healthz",
+			wantStatus: http.StatusOK,
+			wantBody:   map[string]string{"status": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, srv.URL+tt.path, tt.token)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantBody, body)
+		})
+	}
+}
+
+func TestHandler_Locked(t *testing.T) {
+	vault := &fakeVault{
+		records: map[string]map[string]string{"db": {"password": "s3cret"}},
+	}
+	srv := newTestServer(t, vault)
+
+	status, _ := get(t, srv.URL+"/healthz", "")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	status, body := get(t, srv.URL+"/v1/secrets/db/password", testToken)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "master key is locked", body["error"])
+}
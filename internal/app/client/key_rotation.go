@@ -0,0 +1,237 @@
+// internal/app/client/key_rotation.go
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Ротация ключа данных. Новый случайный ключ защищается тем же мастер-паролем,
+// файл ключа публикуется на сервере, затем все записи перешифровываются и
+// отправляются на сервер с новой версией. Прежние ключи остаются в файле
+// ключа, поэтому записи, которые еще не перешифрованы (прерванная ротация,
+// корзина, устройства без нового файла ключа), продолжают читаться. Другие
+// устройства получают новый файл ключа при синхронизации.
+
+// KeyRotationResult - итог ротации ключа или перешифрования записей
+type KeyRotationResult struct {
+	// KeyVersion - версия текущего ключа данных
+	KeyVersion int
+	// Updated - записи, перешифрованные и отправленные на сервер
+	Updated int
+	// Pending - записи, перешифрованные локально: отправятся при синхронизации
+	Pending int
+	// Skipped - записи в корзине: остаются зашифрованы прежним ключом
+	Skipped int
+	// Failed - записи, которые не удалось обновить, с причиной
+	Failed []string
+	// PINDisabled - PIN отключен: он защищал прежний ключ
+	PINDisabled bool
+}
+
+// KeyUsage - число локальных записей по версиям ключа данных
+type KeyUsage struct {
+	KeyVersion int
+	Records    map[int]int
+}
+
+// RotateKey заменяет ключ данных и перешифровывает им все записи. Пароль
+// подтверждает ротацию. Копия ключа в хранилище ОС обновляется, PIN
+// отключается: его нужно включить заново. Если перешифрование прервано,
+// его продолжает ReencryptRecords.
+func (a *App) RotateKey(ctx context.Context, password string) (*KeyRotationResult, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	version, err := a.crypto.RotateKey(password)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка ротации ключа: %w", err)
+	}
+	a.log.Info("Ключ данных заменен", "key_version", version)
+
+	result := &KeyRotationResult{KeyVersion: version}
+	if a.crypto.KeyProviderEnabled() {
+		if err := a.crypto.EnableKeyProvider(a.keyProvider); err != nil {
+			a.log.Warn("Не удалось обновить ключ в хранилище ОС", "error", err)
+			_ = a.crypto.DisableKeyProvider(a.keyProvider)
+		}
+	}
+	if a.crypto.PINEnabled() {
+		if err := a.crypto.DisablePIN(a.keyProvider); err != nil {
+			a.log.Warn("Не удалось отключить PIN", "error", err)
+		}
+		result.PINDisabled = true
+	}
+
+	reencrypted, err := a.ReencryptRecords(ctx)
+	if reencrypted == nil {
+		return result, err
+	}
+	reencrypted.PINDisabled = result.PINDisabled
+	return reencrypted, err
+}
+
+// ReencryptRecords перешифровывает текущим ключом записи, зашифрованные
+// прежними ключами, и отправляет их на сервер. Файл ключа публикуется до
+// отправки записей, чтобы другие устройства могли их прочитать.
+func (a *App) ReencryptRecords(ctx context.Context) (*KeyRotationResult, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	if err := a.PublishKeyFile(ctx); err != nil {
+		return nil, fmt.Errorf("ошибка публикации файла ключа: %w", err)
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	current := a.crypto.KeyVersion()
+	result := &KeyRotationResult{KeyVersion: current}
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if rec.EncryptedData == "" || recordKeyVersion(rec) >= current {
+			continue
+		}
+		if rec.DeletedAt != nil {
+			result.Skipped++
+			continue
+		}
+
+		data, err := a.reencryptData(rec.EncryptedData)
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("запись %d: %v", rec.ID, err))
+			continue
+		}
+
+		if rec.ServerID == 0 || !rec.Synced {
+			rec.EncryptedData = data
+			rec.Synced = false
+			if err := a.storage.UpdateRecord(rec); err != nil {
+				result.Failed = append(result.Failed, fmt.Sprintf("запись %d: %v", rec.ID, err))
+				continue
+			}
+			result.Pending++
+			continue
+		}
+
+		err = a.httpClient.UpdateRecord(ctx, rec.ServerRef(), GenericRecordRequest{
+			Type: rec.Type,
+			Data: data,
+			Meta: rec.Meta,
+		})
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("запись %d: %v", rec.ID, err))
+			continue
+		}
+
+		// Сервер увеличивает версию при каждом обновлении
+		rec.EncryptedData = data
+		rec.Version++
+		if err := a.storage.UpdateRecord(rec); err != nil {
+			a.log.Warn("Не удалось сохранить перешифрованную запись", "record_id", rec.ID, "error", err)
+		}
+		result.Updated++
+	}
+
+	a.log.Info("Записи перешифрованы", "key_version", current, "updated", result.Updated,
+		"pending", result.Pending, "skipped", result.Skipped, "failed", len(result.Failed))
+	return result, nil
+}
+
+// KeyUsage возвращает версию текущего ключа и число локальных записей,
+// зашифрованных каждой версией
+func (a *App) KeyUsage() (*KeyUsage, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	usage := &KeyUsage{KeyVersion: a.crypto.KeyVersion(), Records: make(map[int]int)}
+	for _, rec := range records {
+		if rec.EncryptedData == "" {
+			continue
+		}
+		usage.Records[recordKeyVersion(rec)]++
+	}
+	return usage, nil
+}
+
+// adoptRotatedKey загружает файл ключа с сервера, если полученные записи
+// зашифрованы более новым ключом, чем локальный. Возвращает
+// crypto.ErrKeyRotated, если файл заменен и ключ нужно разблокировать паролем.
+func (a *App) adoptRotatedKey(ctx context.Context, records []*LocalRecord) error {
+	current := a.crypto.KeyVersion()
+	for _, rec := range records {
+		if recordKeyVersion(rec) > current {
+			return a.adoptServerKeyFile(ctx)
+		}
+	}
+	return nil
+}
+
+// adoptServerKeyFile заменяет локальный файл ключа файлом с сервера, если на
+// сервере ключ более новой версии
+func (a *App) adoptServerKeyFile(ctx context.Context) error {
+	file, err := a.httpClient.GetKeyFile(ctx)
+	if errors.Is(err, apperr.NotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки файла мастер-ключа: %w", err)
+	}
+
+	adopted, err := a.crypto.AdoptKeyFile(file.Data)
+	if err != nil || !adopted {
+		return err
+	}
+
+	a.state.SetMasterKeyReady(false)
+	if err := a.state.Update(func(s *AppState) {
+		s.KeyFileChecksum = keyFileChecksum(file.Data)
+		s.PublishedKeyHash = file.KeyHash
+	}); err != nil {
+		return fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+	a.log.Info("Получен новый ключ данных", "key_version", a.crypto.KeyVersion())
+	return crypto.ErrKeyRotated
+}
+
+// reencryptData шифрует ключ записи текущим мастер-ключом; данные записи
+// прежнего формата перешифровываются целиком
+func (a *App) reencryptData(encryptedData string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return "", fmt.Errorf("ошибка декодирования base64: %w", err)
+	}
+	reencrypted, err := a.encryptor.RewrapRecord(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("ошибка перешифрования данных: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(reencrypted), nil
+}
+
+// recordKeyVersion возвращает версию ключа, которым зашифрованы данные записи
+func recordKeyVersion(rec *LocalRecord) int {
+	encrypted, err := base64.StdEncoding.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return 1
+	}
+	return crypto.CiphertextKeyVersion(encrypted)
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_RotateKey(t *testing.T) {
+	ctx := context.Background()
+	keyFiles := &keyFileServer{}
+	records := &metaServer{records: map[int]record.Record{}}
+	mux := http.NewServeMux()
+	mux.Handle("/api/account/key-file", keyFiles)
+	mux.Handle("/api/records/", records)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.PublishKeyFile(ctx))
+	original, err := app.crypto.KeyFile()
+	require.NoError(t, err)
+
+	device := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, device.RestoreMasterKeyFromServer(ctx))
+	require.NoError(t, device.UnlockMasterKey("password123"))
+
+	secret := map[string]string{"password": "s3cret"}
+	data, err := app.encryptRecordData(secret)
+	require.NoError(t, err)
+
+	records.records[10] = record.Record{ID: 10, Type: record.RecTypeLogin, EncryptedData: data, Version: 1}
+	deleted := time.Now()
+	synced := &LocalRecord{ServerID: 10, Type: record.RecTypeLogin, EncryptedData: data, Version: 1, Synced: true}
+	pending := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: data}
+	trashed := &LocalRecord{ServerID: 11, Type: record.RecTypeLogin, EncryptedData: data, Synced: true, DeletedAt: &deleted}
+	for _, rec := range []*LocalRecord{synced, pending, trashed} {
+		require.NoError(t, app.storage.SaveRecord(rec))
+	}
+
+	_, err = app.RotateKey(ctx, "wrong-password")
+	require.Error(t, err)
+
+	result, err := app.RotateKey(ctx, "password123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.KeyVersion)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Pending)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Empty(t, result.Failed)
+
+	// Сервер получил файл нового ключа и перешифрованную запись с новой версией
+	uploaded := records.records[10]
+	assert.Equal(t, 2, uploaded.Version)
+	assert.Equal(t, 2, recordKeyVersion(&LocalRecord{EncryptedData: uploaded.EncryptedData}))
+	local, err := app.storage.GetRecord(synced.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uploaded.Version, local.Version)
+	assert.Equal(t, uploaded.EncryptedData, local.EncryptedData)
+
+	local, err = app.storage.GetRecord(pending.ID)
+	require.NoError(t, err)
+	assert.False(t, local.Synced)
+	assert.Equal(t, 2, recordKeyVersion(local))
+
+	// Запись в корзине зашифрована прежним ключом и по-прежнему читается
+	usage, err := app.KeyUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.KeyVersion)
+	assert.Equal(t, map[int]int{1: 1, 2: 2}, usage.Records)
+	var got map[string]string
+	require.NoError(t, app.decryptRecordData(data, &got))
+	assert.Equal(t, secret, got)
+
+	// Повторный запуск ничего не отправляет
+	again, err := app.ReencryptRecords(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, again.Updated)
+	assert.Equal(t, 1, again.Skipped)
+
+	t.Run("other device adopts the new key", func(t *testing.T) {
+		// Без нового файла ключа устройство не отправляет прежний поверх него
+		require.NoError(t, device.state.Update(func(s *AppState) { s.KeyFileChecksum = "" }))
+		err := device.PublishKeyFile(ctx)
+		assert.ErrorIs(t, err, crypto.ErrKeyRotated)
+		assert.False(t, device.IsMasterKeyUnlocked())
+		assert.Equal(t, 2, device.crypto.KeyVersion())
+
+		require.NoError(t, device.UnlockMasterKey("password123"))
+		require.NoError(t, device.decryptRecordData(uploaded.EncryptedData, &got))
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("sync detects rotated records", func(t *testing.T) {
+		stale := newKeyFileTestApp(t, ts.URL)
+		require.NoError(t, stale.crypto.RestoreKeyFile(original))
+		require.NoError(t, stale.UnlockMasterKey("password123"))
+
+		err := stale.adoptRotatedKey(ctx, []*LocalRecord{{EncryptedData: data}})
+		require.NoError(t, err, "записи прежнего ключа не требуют нового файла")
+
+		err = stale.adoptRotatedKey(ctx, []*LocalRecord{{EncryptedData: uploaded.EncryptedData}})
+		assert.ErrorIs(t, err, crypto.ErrKeyRotated)
+		assert.Equal(t, 2, stale.crypto.KeyVersion())
+	})
+}
@@ -0,0 +1,45 @@
+// internal/app/client/keychain.go
+package client
+
+// Разблокировка через хранилище секретов ОС. Копия мастер-ключа шифруется
+// ключом, который хранится в связке ключей ОС; мастер-пароль при этом
+// продолжает работать и нужен, если хранилище ОС недоступно.
+
+// KeychainName возвращает название хранилища секретов ОС
+func (a *App) KeychainName() string {
+	return a.keyProvider.Name()
+}
+
+// KeychainAvailable проверяет, доступно ли хранилище секретов ОС
+func (a *App) KeychainAvailable() bool {
+	return a.keyProvider.Available()
+}
+
+// KeychainEnabled проверяет, включена ли разблокировка через хранилище ОС
+func (a *App) KeychainEnabled() bool {
+	return a.crypto.KeyProviderEnabled()
+}
+
+// EnableKeychainUnlock сохраняет копию мастер-ключа в хранилище ОС
+func (a *App) EnableKeychainUnlock() error {
+	if !a.IsMasterKeyUnlocked() {
+		return ErrMasterKeyLocked
+	}
+	return a.crypto.EnableKeyProvider(a.keyProvider)
+}
+
+// DisableKeychainUnlock удаляет копию мастер-ключа из хранилища ОС
+func (a *App) DisableKeychainUnlock() error {
+	return a.crypto.DisableKeyProvider(a.keyProvider)
+}
+
+// UnlockWithKeychain разблокирует мастер-ключ через хранилище ОС
+func (a *App) UnlockWithKeychain() error {
+	if err := a.crypto.UnlockWithProvider(a.keyProvider); err != nil {
+		return err
+	}
+
+	a.state.SetMasterKeyReady(true)
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// internal/app/client/maintenance.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultMaintenanceRetry - пауза, если сервер не указал Retry-After
+const defaultMaintenanceRetry = time.Minute
+
+// MaintenanceError - сервер в режиме обслуживания и не принимает изменения.
+// Это не ошибка синхронизации: локальные изменения отправятся позже.
+type MaintenanceError struct {
+	Message    string        `json:"message,omitempty"`
+	RetryAfter time.Duration `json:"retry_after"`
+	Until      time.Time     `json:"until"` // до какого времени синхронизация приостановлена
+}
+
+func (e *MaintenanceError) Error() string {
+	msg := "сервер на обслуживании"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return fmt.Sprintf("%s, повторите после %s", msg, e.Until.Format("15:04:05"))
+}
+
+func newMaintenanceError(message string, retryAfter time.Duration) *MaintenanceError {
+	if retryAfter <= 0 {
+		retryAfter = defaultMaintenanceRetry
+	}
+	return &MaintenanceError{
+		Message:    message,
+		RetryAfter: retryAfter,
+		Until:      time.Now().Add(retryAfter),
+	}
+}
+
+// maintenanceFromResponse распознает ответ 503 режима обслуживания.
+// Обычные 503 без Retry-After остаются ошибками сервера.
+func maintenanceFromResponse(resp *http.Response) *MaintenanceError {
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		return nil
+	}
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return nil
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		retryAfter = time.Until(at)
+	}
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	_ = json.Unmarshal(data, &body)
+
+	return newMaintenanceError(body.Error, retryAfter)
+}
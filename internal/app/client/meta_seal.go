@@ -0,0 +1,180 @@
+// internal/app/client/meta_seal.go
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Сквозное шифрование метаданных (encrypt_meta). Метаданные личных записей
+// запечатываются мастер-ключом перед отправкой на сервер и открываются при
+// получении, поэтому локальное хранилище и все команды клиента работают с
+// открытыми метаданными. Запечатанные метаданные с сервера открываются
+// всегда, даже если режим выключен на этом устройстве. Записи организаций
+// не запечатываются: их метаданные читают другие участники.
+
+// metaCodec запечатывает и открывает метаданные записей для httpClient
+type metaCodec interface {
+	sealMeta(meta json.RawMessage) (json.RawMessage, error)
+	openMeta(meta json.RawMessage) (json.RawMessage, error)
+}
+
+// sealMeta запечатывает метаданные, если включен режим encrypt_meta
+func (a *App) sealMeta(meta json.RawMessage) (json.RawMessage, error) {
+	if !a.config.EncryptMeta {
+		return meta, nil
+	}
+	return record.SealMeta(meta, func(plaintext []byte) (string, error) {
+		if a.encryptor == nil || !a.IsMasterKeyUnlocked() {
+			return "", ErrMasterKeyLocked
+		}
+		encrypted, err := a.encryptor.EncryptRecord(plaintext)
+		if err != nil {
+			return "", fmt.Errorf("ошибка шифрования метаданных: %w", err)
+		}
+		return base64.StdEncoding.EncodeToString(encrypted), nil
+	})
+}
+
+// openMeta открывает запечатанные метаданные; обычные возвращает как есть
+func (a *App) openMeta(meta json.RawMessage) (json.RawMessage, error) {
+	return record.OpenMeta(meta, func(sealed string) ([]byte, error) {
+		if a.encryptor == nil || !a.IsMasterKeyUnlocked() {
+			return nil, ErrMasterKeyLocked
+		}
+		encrypted, err := base64.StdEncoding.DecodeString(sealed)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка декодирования метаданных: %w", err)
+		}
+		plaintext, err := a.encryptor.DecryptRecord(encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки метаданных: %w", err)
+		}
+		return plaintext, nil
+	})
+}
+
+// MetaMigrationResult - итог перевода записей на зашифрованные метаданные
+type MetaMigrationResult struct {
+	Updated int
+	// Pending - записи, еще не отправленные на сервер: их метаданные будут
+	// запечатаны при ближайшей синхронизации
+	Pending int
+	// Skipped - записи в корзине: на сервере их метаданные остаются открытыми,
+	// пока запись не восстановят
+	Skipped int
+	// Failed - записи, которые не удалось обновить, с причиной
+	Failed []string
+}
+
+// EncryptRecordsMeta повторно отправляет на сервер все синхронизированные
+// личные записи, чтобы заменить их открытые метаданные запечатанными.
+// Требует включенного режима encrypt_meta.
+func (a *App) EncryptRecordsMeta(ctx context.Context) (*MetaMigrationResult, error) {
+	if !a.config.EncryptMeta {
+		return nil, fmt.Errorf("шифрование метаданных выключено, выполните: gophkeeper config set encrypt-meta true")
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	result := &MetaMigrationResult{}
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		switch {
+		case rec.DeletedAt != nil:
+			result.Skipped++
+			continue
+		case rec.ServerID == 0 || !rec.Synced:
+			result.Pending++
+			continue
+		}
+
+		err := a.httpClient.UpdateRecord(ctx, rec.ServerRef(), GenericRecordRequest{
+			Type: rec.Type,
+			Data: rec.EncryptedData,
+			Meta: rec.Meta,
+		})
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("запись %d: %v", rec.ID, err))
+			continue
+		}
+
+		// Сервер увеличивает версию при каждом обновлении
+		rec.Version++
+		if err := a.storage.UpdateRecord(rec); err != nil {
+			a.log.Warn("Не удалось сохранить версию записи", "record_id", rec.ID, "error", err)
+		}
+		result.Updated++
+	}
+
+	a.log.Info("Метаданные записей зашифрованы", "updated", result.Updated,
+		"pending", result.Pending, "skipped", result.Skipped, "failed", len(result.Failed))
+	return result, nil
+}
+
+// sealRequest запечатывает метаданные запроса на создание или изменение записи
+func (h *httpClient) sealRequest(req GenericRecordRequest) (GenericRecordRequest, error) {
+	if h.meta == nil {
+		return req, nil
+	}
+	meta, err := h.meta.sealMeta(req.Meta)
+	if err != nil {
+		return req, err
+	}
+	req.Meta = meta
+	return req, nil
+}
+
+// sealSyncRecords возвращает копию отправляемых записей синхронизации с
+// запечатанными метаданными
+func (h *httpClient) sealSyncRecords(records []sync.RecordSync) ([]sync.RecordSync, error) {
+	if h.meta == nil {
+		return records, nil
+	}
+	sealed := make([]sync.RecordSync, len(records))
+	for i, rec := range records {
+		meta, err := h.meta.sealMeta(rec.Meta)
+		if err != nil {
+			return nil, fmt.Errorf("запись %d: %w", rec.ID, err)
+		}
+		rec.Meta = meta
+		sealed[i] = rec
+	}
+	return sealed, nil
+}
+
+// openRecords открывает метаданные полученных записей
+func (h *httpClient) openRecords(records []record.Record) error {
+	for i := range records {
+		meta, err := h.openMetaRaw(records[i].Meta)
+		if err != nil {
+			return fmt.Errorf("запись %d: %w", records[i].ID, err)
+		}
+		records[i].Meta = meta
+	}
+	return nil
+}
+
+// openMetaRaw открывает метаданные, если у клиента есть метакодек
+func (h *httpClient) openMetaRaw(meta json.RawMessage) (json.RawMessage, error) {
+	if h.meta == nil || !record.IsSealedMeta(meta) {
+		return meta, nil
+	}
+	return h.meta.openMeta(meta)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+// metaServer - записи сервера для тестов: хранит метаданные как есть
+type metaServer struct {
+	mu      gosync.Mutex
+	records map[int]record.Record
+}
+
+func (s *metaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/records/"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	rec, ok := s.records[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req GenericRecordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rec.EncryptedData = req.Data
+		rec.Meta = req.Meta
+		rec.Version++
+		s.records[id] = rec
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "OK", "id": id})
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "OK", "record": rec})
+	}
+}
+
+func newMetaSealApp(t *testing.T, serverURL string) *App {
+	t.Helper()
+
+	app := newTestApp(t)
+	app.config.EncryptMeta = true
+	app.storage = NewMemoryStorage()
+	app.httpClient.baseURL = serverURL
+	app.httpClient.meta = app
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	return app
+}
+
+func TestApp_SealMeta(t *testing.T) {
+	app := newMetaSealApp(t, "http://localhost:0")
+	meta := json.RawMessage(`{"title":"Bank","resource":"bank.com","folder_id":2}`)
+
+	sealed, err := app.sealMeta(meta)
+	require.NoError(t, err)
+	assert.True(t, record.IsSealedMeta(sealed))
+	assert.NotContains(t, string(sealed), "bank.com")
+
+	opened, err := app.openMeta(sealed)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(meta), string(opened))
+
+	// Выключенный режим не запечатывает, но открывает метаданные других устройств
+	app.config.EncryptMeta = false
+	plain, err := app.sealMeta(meta)
+	require.NoError(t, err)
+	assert.Equal(t, meta, plain)
+	opened, err = app.openMeta(sealed)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(meta), string(opened))
+
+	app.LockMasterKey()
+	_, err = app.openMeta(sealed)
+	assert.ErrorIs(t, err, ErrMasterKeyLocked)
+}
+
+func TestApp_EncryptRecordsMeta(t *testing.T) {
+	server := &metaServer{records: map[int]record.Record{
+		10: {ID: 10, Type: record.RecTypeLogin, EncryptedData: "data", Meta: json.RawMessage(`{"title":"Bank","folder_id":2}`), Version: 1},
+	}}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	app := newMetaSealApp(t, srv.URL)
+	require.NoError(t, app.loggedIn("alice", "token"))
+
+	deleted := time.Now()
+	synced := &LocalRecord{ServerID: 10, Type: record.RecTypeLogin, EncryptedData: "data", Meta: json.RawMessage(`{"title":"Bank","folder_id":2}`), Version: 1, Synced: true}
+	pending := &LocalRecord{Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"Draft"}`)}
+	trashed := &LocalRecord{ServerID: 11, Type: record.RecTypeText, Meta: json.RawMessage(`{"title":"Old"}`), Synced: true, DeletedAt: &deleted}
+	for _, rec := range []*LocalRecord{synced, pending, trashed} {
+		require.NoError(t, app.storage.SaveRecord(rec))
+	}
+
+	ctx := context.Background()
+	result, err := app.EncryptRecordsMeta(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Pending)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Empty(t, result.Failed)
+
+	// Сервер видит только конверт с открытой папкой
+	stored := server.records[10].Meta
+	assert.True(t, record.IsSealedMeta(stored))
+	assert.NotContains(t, string(stored), "Bank")
+	assert.Contains(t, string(stored), `"folder_id":2`)
+
+	local, err := app.storage.GetRecord(synced.ID)
+	require.NoError(t, err)
+	assert.Equal(t, server.records[10].Version, local.Version)
+	assert.JSONEq(t, `{"title":"Bank","folder_id":2}`, string(local.Meta))
+
+	// Клиент получает открытые метаданные
+	rec, err := app.httpClient.GetRecord(ctx, "10")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Bank","folder_id":2}`, string(rec.Meta))
+
+	app.config.EncryptMeta = false
+	_, err = app.EncryptRecordsMeta(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+// internal/app/client/mfa.go
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// MFARequiredError возвращается при входе, если для учетной записи
+// включена двухфакторная аутентификация
+type MFARequiredError struct {
+	// Challenge - незавершенный вход, действует несколько минут
+	Challenge string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "требуется код двухфакторной аутентификации"
+}
+
+// MFAStatus - состояние двухфакторной аутентификации учетной записи
+type MFAStatus struct {
+	Enabled bool `json:"enabled"`
+	// Pending - секрет выдан, но не подтвержден кодом
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// MFAEnrollment - секрет для приложения-аутентификатора
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// LoginMFA завершает вход кодом из приложения-аутентификатора или кодом восстановления
+func (a *App) LoginMFA(ctx context.Context, login, challenge, code string) (string, error) {
+	token, err := a.httpClient.LoginMFA(ctx, challenge, code)
+	if err != nil {
+		return "", err
+	}
+
+	return token, a.loggedIn(login, token)
+}
+
+// MFAStatus возвращает состояние двухфакторной аутентификации
+func (a *App) MFAStatus(ctx context.Context) (*MFAStatus, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	return a.httpClient.GetMFAStatus(ctx)
+}
+
+// EnrollMFA начинает подключение двухфакторной аутентификации
+func (a *App) EnrollMFA(ctx context.Context) (*MFAEnrollment, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	return a.httpClient.EnrollMFA(ctx)
+}
+
+// ConfirmMFA включает двухфакторную аутентификацию и возвращает коды восстановления.
+// Сервер отзывает прежние токены, поэтому сохраняется новый.
+func (a *App) ConfirmMFA(ctx context.Context, code string) ([]string, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	codes, token, err := a.httpClient.ConfirmMFA(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.SaveToken(token); err != nil {
+		return codes, fmt.Errorf("двухфакторная аутентификация включена, но %w", err)
+	}
+
+	a.log.Info("Двухфакторная аутентификация включена")
+	return codes, nil
+}
+
+// DisableMFA выключает двухфакторную аутентификацию
+func (a *App) DisableMFA(ctx context.Context, code string) error {
+	if !a.IsAuthenticated() {
+		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	if err := a.httpClient.DisableMFA(ctx, code); err != nil {
+		return err
+	}
+
+	a.log.Info("Двухфакторная аутентификация выключена")
+	return nil
+}
+
+// RegenerateRecoveryCodes выдает новые коды восстановления взамен прежних
+func (a *App) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	return a.httpClient.RegenerateRecoveryCodes(ctx, code)
+}
@@ -3,9 +3,16 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"gophkeeper/internal/domain/folder"
 	"gophkeeper/internal/domain/record"
+
+	"github.com/google/uuid"
 )
 
 // LocalRecord - локальная модель записи для хранения в SQLite
@@ -13,6 +20,7 @@ import (
 type LocalRecord struct {
 	ID            int             `json:"id"`
 	ServerID      int             `json:"server_id,omitempty"` // ID на сервере (может отличаться от локального)
+	UUID          string          `json:"uuid,omitempty"`      // постоянный идентификатор, общий с сервером
 	UserID        int             `json:"user_id"`
 	Type          record.RecType  `json:"type"`
 	EncryptedData string          `json:"encrypted_data,omitempty"`
@@ -26,12 +34,25 @@ type LocalRecord struct {
 	Synced      bool      `json:"synced"`
 	SyncVersion int64     `json:"sync_version"`
 	CreatedAt   time.Time `json:"created_at"`
+	// Preview - строки для списков, обновляются при каждом сохранении записи
+	Preview *RecordPreview `json:"preview,omitempty"`
+}
+
+// ServerRef возвращает идентификатор записи для путей /api/records/{id}.
+// Запись адресуется по UUID; числовой ServerID используется только для записей,
+// чей серверный UUID клиент еще не получил (созданы до перехода на UUID).
+func (r *LocalRecord) ServerRef() string {
+	if r.UUID != "" {
+		return r.UUID
+	}
+	return strconv.Itoa(r.ServerID)
 }
 
 // ToServerRecord конвертирует локальную запись в серверную модель
 func (r *LocalRecord) ToServerRecord() *record.Record {
 	return &record.Record{
 		ID:            r.ServerID,
+		UUID:          r.UUID,
 		UserID:        r.UserID,
 		Type:          r.Type,
 		EncryptedData: r.EncryptedData,
@@ -48,6 +69,7 @@ func (r *LocalRecord) ToServerRecord() *record.Record {
 func FromServerRecord(r *record.Record) *LocalRecord {
 	return &LocalRecord{
 		ServerID:      r.ID,
+		UUID:          r.UUID,
 		UserID:        r.UserID,
 		Type:          r.Type,
 		EncryptedData: r.EncryptedData,
@@ -68,31 +90,152 @@ type RecordFilter struct {
 	ShowDeleted bool
 	Limit       int
 	Offset      int
+	// Фильтры по метаданным, как в GET /api/records
+	Search   string   // подстрока в названии или ресурсе
+	Tags     []string // запись должна содержать все теги
+	Category string
+	Resource string
+	// Workspace - категория-каталог: запись в ней или во вложенной категории
+	// (work/aws включает work/aws/prod). Проверяется только локально.
+	Workspace string
+	// Folder - путь папки (Work/Cloud); в выборку входят и записи вложенных папок
+	Folder string
+	// ExpiresBefore - срок действия записи (meta.expires_at) истекает раньше,
+	// включая уже истекшие. Проверяется только локально.
+	ExpiresBefore time.Time
+	// folderIDs - ID папки Folder и вложенных в нее, заполняет App.ListRecords
+	folderIDs []int
+}
+
+// query возвращает параметры фильтра для GET /api/records
+func (f *RecordFilter) query() url.Values {
+	q := url.Values{}
+	if f == nil {
+		return q
+	}
+	if f.Type != "" {
+		q.Set("type", string(f.Type))
+	}
+	if f.Search != "" {
+		q.Set("search", f.Search)
+	}
+	if len(f.Tags) > 0 {
+		q.Set("tag", strings.Join(f.Tags, ","))
+	}
+	if f.Category != "" {
+		q.Set("category", f.Category)
+	}
+	if f.Resource != "" {
+		q.Set("resource", f.Resource)
+	}
+	if len(f.folderIDs) > 0 {
+		q.Set("folder", strconv.Itoa(f.folderIDs[0]))
+	}
+	return q
+}
+
+// hasMetaFilters проверяет, заданы ли фильтры по метаданным
+func (f *RecordFilter) hasMetaFilters() bool {
+	return f.Search != "" || len(f.Tags) > 0 || f.Category != "" || f.Resource != "" || f.Workspace != "" || f.Folder != "" ||
+		!f.ExpiresBefore.IsZero()
+}
+
+// matchesMeta проверяет метаданные записи на соответствие фильтрам
+func (f *RecordFilter) matchesMeta(raw json.RawMessage) bool {
+	if !f.hasMetaFilters() {
+		return true
+	}
+
+	var meta struct {
+		Title    string   `json:"title"`
+		Resource string   `json:"resource"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+		FolderID int      `json:"folder_id"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return false
+	}
+	if !f.ExpiresBefore.IsZero() {
+		if at, ok := record.ExpiresAt(raw); !ok || !at.Before(f.ExpiresBefore) {
+			return false
+		}
+	}
+
+	contains := func(s, substr string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+	}
+	if f.Search != "" && !contains(meta.Title, f.Search) && !contains(meta.Resource, f.Search) {
+		return false
+	}
+	if f.Resource != "" && !contains(meta.Resource, f.Resource) {
+		return false
+	}
+	if f.Category != "" && meta.Category != f.Category {
+		return false
+	}
+	if f.Workspace != "" && !inWorkspace(meta.Category, f.Workspace) {
+		return false
+	}
+	if f.Folder != "" && !slices.Contains(f.folderIDs, meta.FolderID) {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(meta.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// paginate применяет смещение и ограничение к отфильтрованному списку
+func paginate(records []*LocalRecord, limit, offset int) []*LocalRecord {
+	offset = max(offset, 0)
+	if offset >= len(records) {
+		return nil
+	}
+	records = records[offset:]
+	if limit > 0 && limit < len(records) {
+		records = records[:limit]
+	}
+	return records
 }
 
 // MemoryStorage - временное in-memory хранилище
 type MemoryStorage struct {
-	records   map[int]*LocalRecord
-	nextID    int
-	serverMap map[int]int // serverID -> localID
+	records     map[int]*LocalRecord
+	nextID      int
+	uuidMap     map[string]int
+	attachments map[int][]*LocalAttachment
+	folders     []folder.Folder
 }
 
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		records:   make(map[int]*LocalRecord),
-		nextID:    1,
-		serverMap: make(map[int]int),
+		records:     make(map[int]*LocalRecord),
+		nextID:      1,
+		uuidMap:     make(map[string]int),
+		attachments: make(map[int][]*LocalAttachment),
 	}
 }
 
 func (m *MemoryStorage) SaveRecord(rec *LocalRecord) error {
+	preview := buildRecordPreview(rec.Type, rec.Meta)
+	if rec.Type == record.RecTypeCard && rec.Preview != nil {
+		preview.Masked = rec.Preview.Masked
+	}
+	rec.Preview = preview
+
 	if rec.ID == 0 {
 		rec.ID = m.nextID
 		m.nextID++
 	}
+	if rec.UUID == "" && rec.ServerID == 0 {
+		rec.UUID = uuid.NewString()
+	}
 	m.records[rec.ID] = rec
-	if rec.ServerID > 0 {
-		m.serverMap[rec.ServerID] = rec.ID
+	if rec.UUID != "" {
+		m.uuidMap[rec.UUID] = rec.ID
 	}
 	return nil
 }
@@ -100,15 +243,26 @@ func (m *MemoryStorage) SaveRecord(rec *LocalRecord) error {
 func (m *MemoryStorage) GetRecord(id int) (*LocalRecord, error) {
 	rec, exists := m.records[id]
 	if !exists {
-		return nil, fmt.Errorf("запись не найдена: %d", id)
+		return nil, fmt.Errorf("%w: %d", ErrRecordNotFound, id)
 	}
 	return rec, nil
 }
 
+// GetRecordByServerID нужен только для записей без UUID, поэтому отдельный
+// индекс по серверному ID не ведется
 func (m *MemoryStorage) GetRecordByServerID(serverID int) (*LocalRecord, error) {
-	localID, exists := m.serverMap[serverID]
+	for _, rec := range m.records {
+		if serverID > 0 && rec.ServerID == serverID {
+			return rec, nil
+		}
+	}
+	return nil, fmt.Errorf("%w по server_id: %d", ErrRecordNotFound, serverID)
+}
+
+func (m *MemoryStorage) GetRecordByUUID(recordUUID string) (*LocalRecord, error) {
+	localID, exists := m.uuidMap[recordUUID]
 	if !exists {
-		return nil, fmt.Errorf("запись не найдена по server_id: %d", serverID)
+		return nil, fmt.Errorf("%w по uuid: %s", ErrRecordNotFound, recordUUID)
 	}
 	return m.GetRecord(localID)
 }
@@ -122,6 +276,9 @@ func (m *MemoryStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error
 		if filter.Type != "" && rec.Type != filter.Type {
 			continue
 		}
+		if !filter.matchesMeta(rec.Meta) {
+			continue
+		}
 		records = append(records, rec)
 	}
 	return records, nil
@@ -129,11 +286,11 @@ func (m *MemoryStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error
 
 func (m *MemoryStorage) UpdateRecord(rec *LocalRecord) error {
 	if _, exists := m.records[rec.ID]; !exists {
-		return fmt.Errorf("запись не найдена: %d", rec.ID)
+		return fmt.Errorf("%w: %d", ErrRecordNotFound, rec.ID)
 	}
 	m.records[rec.ID] = rec
-	if rec.ServerID > 0 {
-		m.serverMap[rec.ServerID] = rec.ID
+	if rec.UUID != "" {
+		m.uuidMap[rec.UUID] = rec.ID
 	}
 	return nil
 }
@@ -150,10 +307,9 @@ func (m *MemoryStorage) DeleteRecord(id int) error {
 
 func (m *MemoryStorage) HardDeleteRecord(id int) error {
 	if rec, exists := m.records[id]; exists {
-		if rec.ServerID > 0 {
-			delete(m.serverMap, rec.ServerID)
-		}
+		delete(m.uuidMap, rec.UUID)
 		delete(m.records, id)
+		delete(m.attachments, id)
 	}
 	return nil
 }
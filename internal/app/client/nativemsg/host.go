@@ -0,0 +1,221 @@
+package nativemsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Действия расширения
+const (
+	// ActionStatus - запущен ли агент и разблокирован ли мастер-ключ
+	ActionStatus = "status"
+	// ActionLogins - логины, ресурс которых совпадает с адресом страницы (без паролей)
+	ActionLogins = "logins"
+	// ActionCredentials - имя пользователя и пароль логина для автозаполнения
+	ActionCredentials = "credentials"
+	// ActionSave - сохранить новый логин для адреса страницы
+	ActionSave = "save"
+)
+
+// Коды ошибок в ответе, по которым расширение выбирает подсказку
+const (
+	CodeAgentNotRunning = "agent_not_running"
+	CodeLocked          = "locked"
+	CodeDenied          = "denied"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeInvalid         = "invalid"
+	CodeAuthRequired    = "auth_required"
+	CodeError           = "error"
+)
+
+// Request - сообщение расширения
+type Request struct {
+	// ID возвращается в ответе, чтобы расширение сопоставляло ответы с запросами
+	ID       string `json:"id,omitempty"`
+	Action   string `json:"action"`
+	URL      string `json:"url,omitempty"`
+	RecordID int    `json:"record_id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// Response - ответ хоста
+type Response struct {
+	ID          string       `json:"id,omitempty"`
+	OK          bool         `json:"ok"`
+	Error       string       `json:"error,omitempty"`
+	Code        string       `json:"code,omitempty"`
+	Status      *Status      `json:"status,omitempty"`
+	Logins      []Login      `json:"logins,omitempty"`
+	Credentials *Credentials `json:"credentials,omitempty"`
+	// RecordID - ID сохраненной записи (save)
+	RecordID int `json:"record_id,omitempty"`
+}
+
+// Status - состояние агента
+type Status struct {
+	AgentRunning bool `json:"agent_running"`
+	Unlocked     bool `json:"unlocked"`
+}
+
+// Login - логин, подходящий странице
+type Login struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Resource string `json:"resource"`
+	Username string `json:"username"`
+}
+
+// Credentials - данные для автозаполнения формы входа
+type Credentials struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// SaveRequest - новый логин со страницы
+type SaveRequest struct {
+	URL      string
+	Title    string
+	Username string
+	Password string
+}
+
+// Backend выполняет запросы расширения (через агент GophKeeper)
+type Backend interface {
+	// Status возвращает состояние агента; незапущенный агент - не ошибка
+	Status(ctx context.Context) (*Status, error)
+	Logins(ctx context.Context, pageURL string) ([]Login, error)
+	Credentials(ctx context.Context, pageURL string, recordID int) (*Credentials, error)
+	Save(ctx context.Context, req SaveRequest) (int, error)
+}
+
+// Host обслуживает одно подключение расширения: браузер запускает
+// отдельный процесс хоста на каждый connectNative
+type Host struct {
+	backend Backend
+	log     *slog.Logger
+}
+
+// NewHost создает хост
+func NewHost(backend Backend, log *slog.Logger) *Host {
+	return &Host{
+		backend: backend,
+		log:     log.With("component", "native_host"),
+	}
+}
+
+// Serve обрабатывает сообщения по очереди, пока браузер не закроет канал.
+// Ответ на каждое сообщение отправляется до чтения следующего: вопросы
+// подтверждения не перемешиваются.
+func (h *Host) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	for {
+		var req Request
+		if err := ReadMessage(r, &req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if err := WriteMessage(w, h.handle(ctx, &req)); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func (h *Host) handle(ctx context.Context, req *Request) *Response {
+	status, err := h.backend.Status(ctx)
+	if err != nil {
+		return h.failure(req, err)
+	}
+
+	if req.Action == ActionStatus {
+		return &Response{ID: req.ID, OK: true, Status: status}
+	}
+
+	if !status.AgentRunning {
+		return &Response{ID: req.ID, Code: CodeAgentNotRunning, Error: "агент GophKeeper не запущен"}
+	}
+	if !status.Unlocked {
+		return &Response{ID: req.ID, Code: CodeLocked, Error: "мастер-ключ заблокирован"}
+	}
+
+	switch req.Action {
+	case ActionLogins:
+		if req.URL == "" {
+			return invalid(req, "не указан url")
+		}
+		logins, err := h.backend.Logins(ctx, req.URL)
+		if err != nil {
+			return h.failure(req, err)
+		}
+		return &Response{ID: req.ID, OK: true, Logins: logins}
+
+	case ActionCredentials:
+		if req.URL == "" || req.RecordID <= 0 {
+			return invalid(req, "не указаны url и record_id")
+		}
+		creds, err := h.backend.Credentials(ctx, req.URL, req.RecordID)
+		if err != nil {
+			return h.failure(req, err)
+		}
+		return &Response{ID: req.ID, OK: true, Credentials: creds}
+
+	case ActionSave:
+		if req.URL == "" || req.Username == "" || req.Password == "" {
+			return invalid(req, "не указаны url, username и password")
+		}
+		id, err := h.backend.Save(ctx, SaveRequest{
+			URL:      req.URL,
+			Title:    req.Title,
+			Username: req.Username,
+			Password: req.Password,
+		})
+		if err != nil {
+			return h.failure(req, err)
+		}
+		return &Response{ID: req.ID, OK: true, RecordID: id}
+	}
+
+	return invalid(req, fmt.Sprintf("неизвестное действие %q", req.Action))
+}
+
+func invalid(req *Request, msg string) *Response {
+	return &Response{ID: req.ID, Code: CodeInvalid, Error: msg}
+}
+
+func (h *Host) failure(req *Request, err error) *Response {
+	code := codeOf(err)
+	if code == CodeError {
+		h.log.Error("Ошибка запроса расширения", "action", req.Action, "error", err)
+	}
+	return &Response{ID: req.ID, Code: code, Error: err.Error()}
+}
+
+func codeOf(err error) string {
+	switch apperr.KindOf(err) {
+	case apperr.Forbidden:
+		return CodeDenied
+	case apperr.NotFound, apperr.Gone:
+		return CodeNotFound
+	case apperr.Conflict:
+		return CodeConflict
+	case apperr.Invalid:
+		return CodeInvalid
+	case apperr.Unauthorized:
+		return CodeAuthRequired
+	}
+	return CodeError
+}
@@ -0,0 +1,125 @@
+package nativemsg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+type MockBackend struct {
+	mock.Mock
+}
+
+func (m *MockBackend) Status(ctx context.Context) (*Status, error) {
+	args := m.Called(ctx)
+	status, _ := args.Get(0).(*Status)
+	return status, args.Error(1)
+}
+
+func (m *MockBackend) Logins(ctx context.Context, pageURL string) ([]Login, error) {
+	args := m.Called(ctx, pageURL)
+	logins, _ := args.Get(0).([]Login)
+	return logins, args.Error(1)
+}
+
+func (m *MockBackend) Credentials(ctx context.Context, pageURL string, recordID int) (*Credentials, error) {
+	args := m.Called(ctx, pageURL, recordID)
+	creds, _ := args.Get(0).(*Credentials)
+	return creds, args.Error(1)
+}
+
+func (m *MockBackend) Save(ctx context.Context, req SaveRequest) (int, error) {
+	args := m.Called(ctx, req)
+	return args.Int(0), args.Error(1)
+}
+
+// exchange отправляет хосту запросы и возвращает его ответы
+func exchange(t *testing.T, backend Backend, requests ...Request) []Response {
+	t.Helper()
+
+	var in, out bytes.Buffer
+	for _, req := range requests {
+		require.NoError(t, WriteMessage(&in, req))
+	}
+
+	host := NewHost(backend, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, host.Serve(context.Background(), &in, &out))
+
+	responses := make([]Response, 0, len(requests))
+	for range requests {
+		var resp Response
+		require.NoError(t, ReadMessage(&out, &resp))
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestHost_Serve(t *testing.T) {
+	const page = "https://github.com/login"
+	unlocked := &Status{AgentRunning: true, Unlocked: true}
+
+	t.Run("requests are answered in order", func(t *testing.T) {
+		backend := new(MockBackend)
+		backend.On("Status", mock.Anything).Return(unlocked, nil)
+		backend.On("Logins", mock.Anything, page).Return([]Login{{ID: 1, Title: "GitHub", Username: "alice"}}, nil)
+		backend.On("Credentials", mock.Anything, page, 1).Return(&Credentials{ID: 1, Username: "alice", Password: "p"}, nil)
+		backend.On("Save", mock.Anything, SaveRequest{URL: page, Username: "bob", Password: "x"}).Return(7, nil)
+
+		resp := exchange(t, backend,
+			Request{ID: "a", Action: ActionStatus},
+			Request{ID: "b", Action: ActionLogins, URL: page},
+			Request{ID: "c", Action: ActionCredentials, URL: page, RecordID: 1},
+			Request{ID: "d", Action: ActionSave, URL: page, Username: "bob", Password: "x"},
+		)
+
+		assert.Equal(t, Response{ID: "a", OK: true, Status: unlocked}, resp[0])
+		assert.Equal(t, []Login{{ID: 1, Title: "GitHub", Username: "alice"}}, resp[1].Logins)
+		assert.Equal(t, "p", resp[2].Credentials.Password)
+		assert.Equal(t, Response{ID: "d", OK: true, RecordID: 7}, resp[3])
+		backend.AssertExpectations(t)
+	})
+
+	t.Run("agent state is checked first", func(t *testing.T) {
+		stopped := new(MockBackend)
+		stopped.On("Status", mock.Anything).Return(&Status{}, nil)
+		resp := exchange(t, stopped, Request{Action: ActionLogins, URL: page})
+		assert.Equal(t, CodeAgentNotRunning, resp[0].Code)
+
+		locked := new(MockBackend)
+		locked.On("Status", mock.Anything).Return(&Status{AgentRunning: true}, nil)
+		resp = exchange(t, locked, Request{Action: ActionCredentials, URL: page, RecordID: 1})
+		assert.Equal(t, CodeLocked, resp[0].Code)
+		assert.False(t, resp[0].OK)
+
+		locked.AssertNotCalled(t, "Credentials", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("errors carry codes", func(t *testing.T) {
+		backend := new(MockBackend)
+		backend.On("Status", mock.Anything).Return(unlocked, nil)
+		backend.On("Credentials", mock.Anything, page, 1).Return(nil, apperr.New(apperr.Forbidden, "отклонено"))
+		backend.On("Credentials", mock.Anything, page, 2).Return(nil, apperr.New(apperr.NotFound, "нет"))
+
+		resp := exchange(t, backend,
+			Request{Action: ActionCredentials, URL: page, RecordID: 1},
+			Request{Action: ActionCredentials, URL: page, RecordID: 2},
+			Request{Action: ActionCredentials, URL: page},
+			Request{Action: ActionSave, URL: page, Username: "bob"},
+			Request{Action: "delete"},
+		)
+
+		assert.Equal(t, Response{Code: CodeDenied, Error: "отклонено"}, resp[0])
+		assert.Equal(t, CodeNotFound, resp[1].Code)
+		for _, r := range resp[2:] {
+			assert.Equal(t, CodeInvalid, r.Code)
+		}
+	})
+}
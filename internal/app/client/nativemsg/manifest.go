@@ -0,0 +1,252 @@
+package nativemsg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Browser - браузер, для которого регистрируется хост
+type Browser string
+
+const (
+	Chrome   Browser = "chrome"
+	Chromium Browser = "chromium"
+	Edge     Browser = "edge"
+	Firefox  Browser = "firefox"
+)
+
+// Browsers возвращает поддерживаемые браузеры
+func Browsers() []Browser {
+	return []Browser{Chrome, Chromium, Edge, Firefox}
+}
+
+// ParseBrowser проверяет имя браузера
+func ParseBrowser(name string) (Browser, error) {
+	b := Browser(strings.ToLower(strings.TrimSpace(name)))
+	for _, known := range Browsers() {
+		if b == known {
+			return b, nil
+		}
+	}
+
+	names := make([]string, 0, len(Browsers()))
+	for _, known := range Browsers() {
+		names = append(names, string(known))
+	}
+	return "", fmt.Errorf("неизвестный браузер %q, доступны: %s", name, strings.Join(names, ", "))
+}
+
+// Manifest - манифест хоста native messaging. Chromium-браузеры ограничивают
+// доступ списком allowed_origins, Firefox - allowed_extensions.
+type Manifest struct {
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Path              string   `json:"path"`
+	Type              string   `json:"type"`
+	AllowedOrigins    []string `json:"allowed_origins,omitempty"`
+	AllowedExtensions []string `json:"allowed_extensions,omitempty"`
+}
+
+// NewManifest описывает хост, запускаемый программой launcher, для расширения extensionID
+func NewManifest(browser Browser, launcher, extensionID string) (*Manifest, error) {
+	extensionID = strings.TrimSpace(extensionID)
+	if extensionID == "" {
+		return nil, fmt.Errorf("не указан ID расширения")
+	}
+
+	m := &Manifest{
+		Name:        HostName,
+		Description: "GophKeeper password manager",
+		Path:        launcher,
+		Type:        "stdio",
+	}
+	if browser == Firefox {
+		m.AllowedExtensions = []string{extensionID}
+	} else {
+		m.AllowedOrigins = []string{"chrome-extension://" + strings.Trim(strings.TrimPrefix(extensionID, "chrome-extension://"), "/") + "/"}
+	}
+	return m, nil
+}
+
+// commandRunner выполняет системную команду (reg в Windows)
+type commandRunner func(name string, args ...string) error
+
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Installer регистрирует хост в браузерах текущего пользователя. Браузер
+// запускает хост без аргументов командной строки GophKeeper, поэтому рядом
+// с манифестом создается скрипт запуска, который передает gophkeeper
+// конфигурацию и вызывает gophkeeper browser host.
+type Installer struct {
+	home      string
+	goos      string
+	configDir string
+	run       commandRunner
+}
+
+// NewInstaller создает установщик для ОС goos; configDir - директория конфигурации клиента
+func NewInstaller(home, goos, configDir string) *Installer {
+	return &Installer{home: home, goos: goos, configDir: configDir, run: runCommand}
+}
+
+// LauncherPath возвращает путь к скрипту запуска хоста
+func (i *Installer) LauncherPath() string {
+	if i.goos == "windows" {
+		return filepath.Join(i.configDir, "native-host.bat")
+	}
+	return filepath.Join(i.configDir, "native-host.sh")
+}
+
+// ManifestPath возвращает путь к манифесту хоста для браузера. В Windows
+// манифест лежит в директории конфигурации, а браузер находит его через реестр.
+func (i *Installer) ManifestPath(browser Browser) (string, error) {
+	name := HostName + ".json"
+
+	switch i.goos {
+	case "windows":
+		return filepath.Join(i.configDir, "native-host-"+string(browser)+".json"), nil
+	case "darwin":
+		support := filepath.Join(i.home, "Library", "Application Support")
+		dirs := map[Browser]string{
+			Chrome:   filepath.Join(support, "Google", "Chrome", "NativeMessagingHosts"),
+			Chromium: filepath.Join(support, "Chromium", "NativeMessagingHosts"),
+			Edge:     filepath.Join(support, "Microsoft Edge", "NativeMessagingHosts"),
+			Firefox:  filepath.Join(support, "Mozilla", "NativeMessagingHosts"),
+		}
+		return filepath.Join(dirs[browser], name), nil
+	case "linux":
+		config := filepath.Join(i.home, ".config")
+		dirs := map[Browser]string{
+			Chrome:   filepath.Join(config, "google-chrome", "NativeMessagingHosts"),
+			Chromium: filepath.Join(config, "chromium", "NativeMessagingHosts"),
+			Edge:     filepath.Join(config, "microsoft-edge", "NativeMessagingHosts"),
+			Firefox:  filepath.Join(i.home, ".mozilla", "native-messaging-hosts"),
+		}
+		return filepath.Join(dirs[browser], name), nil
+	}
+	return "", fmt.Errorf("регистрация хоста браузера не поддерживается в %s", i.goos)
+}
+
+// registryKey возвращает ключ реестра Windows, по которому браузер ищет манифест
+func registryKey(browser Browser) string {
+	vendors := map[Browser]string{
+		Chrome:   `Google\Chrome`,
+		Chromium: `Chromium`,
+		Edge:     `Microsoft\Edge`,
+		Firefox:  `Mozilla`,
+	}
+	return `HKCU\Software\` + vendors[browser] + `\NativeMessagingHosts\` + HostName
+}
+
+// Install записывает скрипт запуска и манифест. executable и args -
+// команда, которую выполняет скрипт, env - ее окружение.
+func (i *Installer) Install(browser Browser, extensionID, executable string, args []string, env map[string]string) (string, error) {
+	manifestPath, err := i.ManifestPath(browser)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := NewManifest(browser, i.LauncherPath(), extensionID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(i.configDir, 0700); err != nil {
+		return "", fmt.Errorf("ошибка создания директории %s: %w", i.configDir, err)
+	}
+	if err := os.WriteFile(i.LauncherPath(), []byte(i.renderLauncher(executable, args, env)), 0700); err != nil {
+		return "", fmt.Errorf("ошибка записи %s: %w", i.LauncherPath(), err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("ошибка сериализации манифеста: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return "", fmt.Errorf("ошибка создания директории %s: %w", filepath.Dir(manifestPath), err)
+	}
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("ошибка записи %s: %w", manifestPath, err)
+	}
+
+	if i.goos == "windows" {
+		if err := i.run("reg", "add", registryKey(browser), "/ve", "/t", "REG_SZ", "/d", manifestPath, "/f"); err != nil {
+			return "", err
+		}
+	}
+
+	return manifestPath, nil
+}
+
+// Uninstall удаляет манифест браузера. Скрипт запуска остается: его могут
+// использовать другие браузеры.
+func (i *Installer) Uninstall(browser Browser) (string, error) {
+	manifestPath, err := i.ManifestPath(browser)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(manifestPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("хост не зарегистрирован: %s не найден", manifestPath)
+		}
+		return "", fmt.Errorf("ошибка удаления %s: %w", manifestPath, err)
+	}
+
+	if i.goos == "windows" {
+		// Ключа может не быть, если его удалили вручную
+		_ = i.run("reg", "delete", registryKey(browser), "/f")
+	}
+
+	return manifestPath, nil
+}
+
+func (i *Installer) renderLauncher(executable string, args []string, env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	if i.goos == "windows" {
+		b.WriteString("@echo off\r\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "set \"%s=%s\"\r\n", k, env[k])
+		}
+		fmt.Fprintf(&b, "\"%s\"", executable)
+		for _, arg := range args {
+			fmt.Fprintf(&b, " \"%s\"", arg)
+		}
+		b.WriteString(" %*\r\n")
+		return b.String()
+	}
+
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Хост native messaging GophKeeper: браузер запускает этот скрипт\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(env[k]))
+	}
+	b.WriteString("exec " + shellQuote(executable))
+	for _, arg := range args {
+		b.WriteString(" " + shellQuote(arg))
+	}
+	b.WriteString(" \"$@\"\n")
+	return b.String()
+}
+
+// shellQuote заключает значение в одинарные кавычки для sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,106 @@
+package nativemsg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManifest(t *testing.T) {
+	chrome, err := NewManifest(Chrome, "/home/alice/.gophkeeper/native-host.sh", "abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, HostName, chrome.Name)
+	assert.Equal(t, "stdio", chrome.Type)
+	assert.Equal(t, []string{"chrome-extension://abcdef/"}, chrome.AllowedOrigins)
+	assert.Empty(t, chrome.AllowedExtensions)
+
+	// ID можно передать и адресом расширения
+	edge, err := NewManifest(Edge, "/x", "chrome-extension://abcdef/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chrome-extension://abcdef/"}, edge.AllowedOrigins)
+
+	firefox, err := NewManifest(Firefox, "/x", "gophkeeper@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gophkeeper@example.com"}, firefox.AllowedExtensions)
+	assert.Empty(t, firefox.AllowedOrigins)
+
+	_, err = NewManifest(Chrome, "/x", " ")
+	assert.Error(t, err)
+}
+
+func TestParseBrowser(t *testing.T) {
+	b, err := ParseBrowser("Firefox")
+	require.NoError(t, err)
+	assert.Equal(t, Firefox, b)
+
+	_, err = ParseBrowser("safari")
+	assert.Error(t, err)
+}
+
+func TestInstaller_Linux(t *testing.T) {
+	home := t.TempDir()
+	configDir := filepath.Join(home, "it's config")
+	installer := NewInstaller(home, "linux", configDir)
+
+	path, err := installer.Install(Firefox, "gk@example.com", "/usr/bin/gophkeeper",
+		[]string{"browser", "host"}, map[string]string{"CONFIG_DIR": configDir})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".mozilla", "native-messaging-hosts", HostName+".json"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, installer.LauncherPath(), manifest.Path)
+
+	launcher, err := os.ReadFile(installer.LauncherPath())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(launcher), "#!/bin/sh\n"))
+	assert.Contains(t, string(launcher), `export CONFIG_DIR='`+strings.ReplaceAll(configDir, "'", `'\''`)+"'\n")
+	assert.Contains(t, string(launcher), `exec '/usr/bin/gophkeeper' 'browser' 'host' "$@"`)
+
+	info, err := os.Stat(installer.LauncherPath())
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100, "скрипт запуска должен быть исполняемым")
+
+	_, err = installer.Uninstall(Firefox)
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+
+	_, err = installer.Uninstall(Firefox)
+	assert.Error(t, err)
+}
+
+func TestInstaller_Windows(t *testing.T) {
+	home := t.TempDir()
+	var calls [][]string
+	installer := NewInstaller(home, "windows", home)
+	installer.run = func(name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		return nil
+	}
+
+	path, err := installer.Install(Chrome, "abcdef", `C:\gophkeeper.exe`, []string{"browser", "host"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "native-host-chrome.json"), path)
+	require.Len(t, calls, 1)
+	assert.Equal(t, []string{"reg", "add", `HKCU\Software\Google\Chrome\NativeMessagingHosts\` + HostName, "/ve", "/t", "REG_SZ", "/d", path, "/f"}, calls[0])
+
+	launcher, err := os.ReadFile(installer.LauncherPath())
+	require.NoError(t, err)
+	assert.Equal(t, "@echo off\r\n\"C:\\gophkeeper.exe\" \"browser\" \"host\" %*\r\n", string(launcher))
+
+	_, err = installer.Uninstall(Chrome)
+	require.NoError(t, err)
+	assert.Equal(t, "delete", calls[1][1])
+}
+
+func TestInstaller_Unsupported(t *testing.T) {
+	_, err := NewInstaller(t.TempDir(), "plan9", t.TempDir()).ManifestPath(Chrome)
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// Package nativemsg - хост native messaging для браузерного расширения
+// GophKeeper (Chrome, Chromium, Edge, Firefox). Браузер запускает хост
+// отдельным процессом и обменивается с ним JSON-сообщениями через
+// stdin/stdout: перед каждым сообщением передается его длина - 4 байта
+// в порядке байтов платформы.
+//
+// Хост не хранит ключей: запросы расширения передаются агенту GophKeeper,
+// который держит мастер-ключ разблокированным и спрашивает пользователя
+// перед выдачей и сохранением паролей.
+package nativemsg
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// HostName - имя хоста в манифесте и в chrome.runtime.connectNative
+	HostName = "com.gophkeeper.native"
+
+	// MaxResponseSize - браузер не принимает от хоста сообщения больше 1 МБ
+	MaxResponseSize = 1 << 20
+	// MaxRequestSize - ограничение на сообщение от расширения. Браузер
+	// допускает до 64 МБ, но запросам хоста достаточно малой части.
+	MaxRequestSize = 1 << 20
+)
+
+// ErrMessageTooLarge - сообщение больше допустимого размера
+var ErrMessageTooLarge = errors.New("сообщение превышает допустимый размер")
+
+// ReadMessage читает одно сообщение в v. Когда браузер закрывает канал
+// между сообщениями, возвращается io.EOF.
+func ReadMessage(r io.Reader, v interface{}) error {
+	var size uint32
+	if err := binary.Read(r, binary.NativeEndian, &size); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("обрыв заголовка сообщения: %w", err)
+		}
+		return err
+	}
+	if size > MaxRequestSize {
+		return fmt.Errorf("%w: %d байт", ErrMessageTooLarge, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return fmt.Errorf("ошибка чтения сообщения: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("неверный JSON в сообщении: %w", err)
+	}
+	return nil
+}
+
+// WriteMessage записывает v одним сообщением
+func WriteMessage(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации сообщения: %w", err)
+	}
+	if len(data) > MaxResponseSize {
+		return fmt.Errorf("%w: %d байт", ErrMessageTooLarge, len(data))
+	}
+
+	buf := make([]byte, 4+len(data))
+	binary.NativeEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+
+	// Заголовок и тело пишутся одним вызовом, чтобы браузер не получил их по частям
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("ошибка отправки сообщения: %w", err)
+	}
+	return nil
+}
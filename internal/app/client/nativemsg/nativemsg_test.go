@@ -0,0 +1,58 @@
+package nativemsg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteMessage(&buf, Request{ID: "1", Action: ActionLogins, URL: "https://github.com"}))
+	require.NoError(t, WriteMessage(&buf, Request{ID: "2", Action: ActionStatus}))
+
+	// Длина - первые 4 байта в порядке байтов платформы
+	size := binary.NativeEndian.Uint32(buf.Bytes()[:4])
+	assert.Equal(t, strings.Index(buf.String()[4:], "}")+1, int(size))
+
+	var first, second Request
+	require.NoError(t, ReadMessage(&buf, &first))
+	require.NoError(t, ReadMessage(&buf, &second))
+	assert.Equal(t, "https://github.com", first.URL)
+	assert.Equal(t, ActionStatus, second.Action)
+
+	assert.ErrorIs(t, ReadMessage(&buf, &first), io.EOF)
+}
+
+func TestReadMessage_Errors(t *testing.T) {
+	header := func(size uint32) []byte {
+		b := make([]byte, 4)
+		binary.NativeEndian.PutUint32(b, size)
+		return b
+	}
+
+	var req Request
+	err := ReadMessage(bytes.NewReader(header(MaxRequestSize+1)), &req)
+	assert.ErrorIs(t, err, ErrMessageTooLarge)
+
+	err = ReadMessage(bytes.NewReader(append(header(10), `{"a"`...)), &req)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	err = ReadMessage(bytes.NewReader([]byte{1, 0}), &req)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	err = ReadMessage(bytes.NewReader(append(header(3), "abc"...)), &req)
+	assert.Error(t, err)
+}
+
+func TestWriteMessage_TooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteMessage(&buf, map[string]string{"x": strings.Repeat("a", MaxResponseSize)})
+	assert.ErrorIs(t, err, ErrMessageTooLarge)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,254 @@
+// internal/app/client/org.go
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+)
+
+// Хранилище организации - общие записи, зашифрованные ключом организации.
+// Ключ создается на клиенте и хранится на сервере отдельно для каждого
+// участника: зашифрованный его мастер-ключом, а до принятия приглашения -
+// кодом приглашения, который передается вне сервиса. Записи хранилища не
+// попадают в локальную базу: участники читают их с сервера, поэтому
+// изменения сразу видны всем. Данные записей передаются в hex, как их хранит сервер.
+
+// CreateOrg создает организацию и ключ ее хранилища
+func (a *App) CreateOrg(ctx context.Context, name string) (*org.Organization, error) {
+	if err := a.requireOrgAccess(); err != nil {
+		return nil, err
+	}
+
+	key, err := crypto.GenerateOrgKey()
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := a.crypto.EncryptData(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования ключа организации: %w", err)
+	}
+
+	return a.httpClient.CreateOrg(ctx, name, hex.EncodeToString(wrapped))
+}
+
+// ListOrgs возвращает организации пользователя и приглашения в них
+func (a *App) ListOrgs(ctx context.Context) ([]org.Summary, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.ListOrgs(ctx)
+}
+
+// DeleteOrg удаляет организацию вместе с записями хранилища
+func (a *App) DeleteOrg(ctx context.Context, orgID int) error {
+	if !a.IsAuthenticated() {
+		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.DeleteOrg(ctx, orgID)
+}
+
+// InviteOrgMember приглашает пользователя и возвращает код приглашения.
+// Код нужно передать приглашенному лично: без него ключ хранилища не расшифровать.
+func (a *App) InviteOrgMember(ctx context.Context, orgID int, login string, role org.Role) (string, error) {
+	if err := role.Validate(); err != nil {
+		return "", err
+	}
+
+	key, err := a.orgKey(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := crypto.GenerateInviteCode()
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := crypto.WrapKeyWithCode(key, code)
+	if err != nil {
+		return "", fmt.Errorf("ошибка шифрования ключа организации: %w", err)
+	}
+
+	if err := a.httpClient.InviteOrgMember(ctx, orgID, login, role, hex.EncodeToString(wrapped)); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// AcceptOrgInvite расшифровывает ключ хранилища кодом приглашения
+// и сохраняет его на сервере зашифрованным мастер-ключом
+func (a *App) AcceptOrgInvite(ctx context.Context, orgID int, code string) error {
+	if err := a.requireOrgAccess(); err != nil {
+		return err
+	}
+
+	summary, err := a.httpClient.GetOrg(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if summary.Status != org.StatusInvited {
+		return fmt.Errorf("приглашение в организацию %d не найдено", orgID)
+	}
+
+	wrapped, err := hex.DecodeString(summary.EncryptedKey)
+	if err != nil {
+		return fmt.Errorf("неверный формат ключа приглашения: %w", err)
+	}
+
+	key, err := crypto.UnwrapKeyWithCode(wrapped, code)
+	if err != nil {
+		return err
+	}
+
+	rewrapped, err := a.crypto.EncryptData(key)
+	if err != nil {
+		return fmt.Errorf("ошибка шифрования ключа организации: %w", err)
+	}
+
+	return a.httpClient.AcceptOrgInvite(ctx, orgID, hex.EncodeToString(rewrapped))
+}
+
+// ListOrgMembers возвращает участников организации
+func (a *App) ListOrgMembers(ctx context.Context, orgID int) ([]membership.Member, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.ListOrgMembers(ctx, orgID)
+}
+
+// ChangeOrgMemberRole меняет роль участника
+func (a *App) ChangeOrgMemberRole(ctx context.Context, orgID, userID int, role org.Role) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	if !a.IsAuthenticated() {
+		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.ChangeOrgMemberRole(ctx, orgID, userID, role)
+}
+
+// RemoveOrgMember исключает участника; свой ID - выход из организации
+func (a *App) RemoveOrgMember(ctx context.Context, orgID, userID int) error {
+	if !a.IsAuthenticated() {
+		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.RemoveOrgMember(ctx, orgID, userID)
+}
+
+// ListOrgRecords возвращает записи хранилища организации (без данных)
+func (a *App) ListOrgRecords(ctx context.Context, orgID int) (*record.ListResponse, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	return a.httpClient.ListOrgRecords(ctx, orgID)
+}
+
+// ShareRecord копирует локальную запись в хранилище организации.
+// Данные перешифровываются ключом организации, личная запись не меняется.
+func (a *App) ShareRecord(ctx context.Context, orgID, recordID int) (int, error) {
+	key, err := a.orgKey(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+
+	localRec, err := a.GetRecord(ctx, recordID)
+	if err != nil {
+		return 0, err
+	}
+
+	var data json.RawMessage
+	if err := a.decryptRecordData(localRec.EncryptedData, &data); err != nil {
+		return 0, fmt.Errorf("ошибка расшифровки данных: %w", err)
+	}
+
+	encrypted, err := crypto.EncryptWithOrgKey(key, data)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка шифрования данных: %w", err)
+	}
+
+	return a.httpClient.CreateOrgRecord(ctx, orgID, GenericRecordRequest{
+		Type: localRec.Type,
+		Data: hex.EncodeToString(encrypted),
+		Meta: localRec.Meta,
+	})
+}
+
+// GetOrgRecord возвращает расшифрованную запись хранилища организации
+func (a *App) GetOrgRecord(ctx context.Context, orgID, recordID int) (*record.Record, interface{}, error) {
+	key, err := a.orgKey(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rec, err := a.httpClient.GetRecord(ctx, strconv.Itoa(recordID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if rec.OrgID == nil || *rec.OrgID != orgID {
+		return nil, nil, fmt.Errorf("запись %d не принадлежит организации %d", recordID, orgID)
+	}
+
+	encrypted, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("неверный формат данных записи: %w", err)
+	}
+
+	plaintext, err := crypto.DecryptWithOrgKey(key, encrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка расшифровки данных: %w", err)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, nil, fmt.Errorf("ошибка десериализации данных: %w", err)
+	}
+
+	return rec, data, nil
+}
+
+// orgKey получает ключ хранилища организации, расшифрованный мастер-ключом
+func (a *App) orgKey(ctx context.Context, orgID int) ([]byte, error) {
+	if err := a.requireOrgAccess(); err != nil {
+		return nil, err
+	}
+
+	summary, err := a.httpClient.GetOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status != org.StatusActive {
+		return nil, fmt.Errorf("приглашение не принято. Выполните: gophkeeper org accept %d", orgID)
+	}
+
+	wrapped, err := hex.DecodeString(summary.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат ключа организации: %w", err)
+	}
+
+	key, err := a.crypto.DecryptData(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки ключа организации: %w", err)
+	}
+
+	return key, nil
+}
+
+func (a *App) requireOrgAccess() error {
+	if !a.IsAuthenticated() {
+		return fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+	if !a.IsMasterKeyUnlocked() {
+		return ErrMasterKeyLocked
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// internal/app/client/otp_migration.go
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// Пакетный перенос секретов TOTP в формате otpauth-migration (QR-коды
+// «Перенести аккаунты» Google Authenticator). Импорт создает записи TOTP,
+// пропуская секреты, которые уже есть в хранилище; экспорт собирает
+// сохраненные секреты обратно в адреса otpauth-migration.
+
+// OTPImportResult - итог импорта секретов TOTP
+type OTPImportResult struct {
+	Imported   int
+	Duplicates int
+	// Skipped - секреты, которые нельзя импортировать (HOTP, MD5), с причиной
+	Skipped []string
+}
+
+// OTPExportResult - адреса otpauth-migration с сохраненными секретами
+type OTPExportResult struct {
+	URIs     []string
+	Exported int
+	// Skipped - записи, которые нельзя передать (период не 30 секунд), с причиной
+	Skipped []string
+}
+
+// ImportOTPMigration создает записи TOTP из адресов otpauth-migration.
+// Все адреса разбираются до создания первой записи, чтобы ошибка в одном
+// из них не оставила импорт наполовину выполненным.
+func (a *App) ImportOTPMigration(ctx context.Context, uris []string) (*OTPImportResult, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	result := &OTPImportResult{}
+	var accounts []record.OTPAccount
+	for i, uri := range uris {
+		batch, err := record.ParseOTPMigrationURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("адрес %d: %w", i+1, err)
+		}
+		accounts = append(accounts, batch.Accounts...)
+		result.Skipped = append(result.Skipped, batch.Skipped...)
+	}
+
+	known, err := a.otpSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, acc := range accounts {
+		secret := normalizeOTPSecret(acc.Secret)
+		if known[secret] {
+			result.Duplicates++
+			continue
+		}
+
+		_, err := a.CreateOTPRecord(ctx, CreateOTPRequest{
+			Secret:      acc.Secret,
+			Algorithm:   acc.Algorithm,
+			Digits:      acc.Digits,
+			Title:       otpTitle(acc.Issuer, acc.AccountName),
+			Issuer:      acc.Issuer,
+			AccountName: acc.AccountName,
+		})
+		if err != nil {
+			return result, fmt.Errorf("ошибка импорта %s: %w", otpTitle(acc.Issuer, acc.AccountName), err)
+		}
+		known[secret] = true
+		result.Imported++
+	}
+
+	a.log.Info("Секреты TOTP импортированы", "imported", result.Imported, "duplicates", result.Duplicates, "skipped", len(result.Skipped))
+	return result, nil
+}
+
+// ExportOTPMigration собирает записи TOTP ids (все записи TOTP, если ids
+// пуст) в адреса otpauth-migration для переноса в приложение-аутентификатор
+func (a *App) ExportOTPMigration(ctx context.Context, ids []int) (*OTPExportResult, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	var records []*LocalRecord
+	if len(ids) == 0 {
+		var err error
+		if records, err = a.storage.ListRecords(&RecordFilter{Type: record.RecTypeOTP}); err != nil {
+			return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+		}
+	} else {
+		for _, id := range ids {
+			rec, err := a.GetRecord(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if rec.Type != record.RecTypeOTP {
+				return nil, fmt.Errorf("запись %d не является записью TOTP (тип: %s)", id, rec.Type)
+			}
+			records = append(records, rec)
+		}
+	}
+
+	result := &OTPExportResult{}
+	var accounts []record.OTPAccount
+	for _, rec := range records {
+		var data record.OTPData
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			return nil, fmt.Errorf("ошибка расшифровки записи %d: %w", rec.ID, err)
+		}
+		var meta record.OTPMeta
+		if len(rec.Meta) > 0 {
+			_ = json.Unmarshal(rec.Meta, &meta)
+		}
+
+		acc := record.OTPAccount{OTPData: data, Issuer: meta.Issuer, AccountName: meta.AccountName}
+		if acc.Issuer == "" && acc.AccountName == "" {
+			acc.AccountName = meta.Title
+		}
+		// Проверяем каждую запись отдельно, чтобы пропустить непереносимые
+		if _, err := record.OTPMigrationURIs([]record.OTPAccount{acc}, 0); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("запись %d: %v", rec.ID, err))
+			continue
+		}
+		accounts = append(accounts, acc)
+	}
+
+	var batchID [4]byte
+	if _, err := rand.Read(batchID[:]); err != nil {
+		return nil, fmt.Errorf("ошибка генерации идентификатора экспорта: %w", err)
+	}
+	uris, err := record.OTPMigrationURIs(accounts, int(binary.BigEndian.Uint32(batchID[:])>>1))
+	if err != nil {
+		return nil, err
+	}
+	result.URIs = uris
+	result.Exported = len(accounts)
+	return result, nil
+}
+
+// otpSecrets возвращает нормализованные секреты сохраненных записей TOTP
+func (a *App) otpSecrets(ctx context.Context) (map[string]bool, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{Type: record.RecTypeOTP})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]bool, len(records))
+	for _, rec := range records {
+		var data record.OTPData
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			a.log.Warn("Не удалось расшифровать запись TOTP", "record_id", rec.ID, "error", err)
+			continue
+		}
+		secrets[normalizeOTPSecret(data.Secret)] = true
+	}
+	return secrets, nil
+}
+
+// normalizeOTPSecret приводит секрет base32 к виду для сравнения
+func normalizeOTPSecret(secret string) string {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return strings.TrimRight(secret, "=")
+}
+
+// otpTitle - название записи TOTP: сервис и аккаунт
+func otpTitle(issuer, account string) string {
+	switch {
+	case issuer == "" && account == "":
+		return "TOTP"
+	case issuer == "":
+		return account
+	case account == "" || account == issuer:
+		return issuer
+	default:
+		return issuer + " (" + account + ")"
+	}
+}
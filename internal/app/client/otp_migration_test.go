@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_ExportImportOTPMigration(t *testing.T) {
+	ctx := context.Background()
+	app := newUndoTestApp(t)
+
+	saveTestRecord(t, app, record.RecTypeOTP,
+		record.OTPMeta{Title: "GitHub", Issuer: "GitHub", AccountName: "alice"},
+		record.OTPData{Secret: "JBSWY3DPEHPK3PXP"})
+	saveTestRecord(t, app, record.RecTypeOTP,
+		record.OTPMeta{Title: "Steam"},
+		record.OTPData{Secret: "GEZDGNBVGY3TQOJQ", Digits: 8, Algorithm: "SHA256"})
+	slow := saveTestRecord(t, app, record.RecTypeOTP,
+		record.OTPMeta{Title: "Bank"},
+		record.OTPData{Secret: "MFRGGZDFMZTWQ2LK", Period: 60})
+
+	exported, err := app.ExportOTPMigration(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exported.Exported)
+	require.Len(t, exported.URIs, 1)
+	require.Len(t, exported.Skipped, 1)
+	assert.Contains(t, exported.Skipped[0], "запись")
+
+	batch, err := record.ParseOTPMigrationURI(exported.URIs[0])
+	require.NoError(t, err)
+	var titles []string
+	for _, acc := range batch.Accounts {
+		titles = append(titles, otpTitle(acc.Issuer, acc.AccountName))
+	}
+	assert.ElementsMatch(t, []string{"GitHub (alice)", "Steam"}, titles)
+
+	_, err = app.ExportOTPMigration(ctx, []int{slow})
+	require.NoError(t, err)
+
+	t.Run("import skips secrets already in the vault", func(t *testing.T) {
+		// Все секреты экспорта уже есть в хранилище: записи не создаются
+		result, err := app.ImportOTPMigration(ctx, exported.URIs)
+		require.NoError(t, err)
+		assert.Zero(t, result.Imported)
+		assert.Equal(t, 2, result.Duplicates)
+
+		_, err = app.ImportOTPMigration(ctx, []string{exported.URIs[0], "otpauth-migration://offline?data=CjEK"})
+		assert.ErrorContains(t, err, "адрес 2")
+	})
+}
+
+func TestOTPTitle(t *testing.T) {
+	assert.Equal(t, "GitHub (alice)", otpTitle("GitHub", "alice"))
+	assert.Equal(t, "GitHub", otpTitle("GitHub", ""))
+	assert.Equal(t, "alice", otpTitle("", "alice"))
+	assert.Equal(t, "TOTP", otpTitle("", ""))
+}
@@ -0,0 +1,131 @@
+// internal/app/client/password.go
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/user"
+)
+
+// Пароль входа и мастер-пароль меняются отдельно. Пароль входа хранится на
+// сервере: после смены сервер завершает все сессии, а текущее устройство
+// получает новый токен. Мастер-пароль защищает только файл ключа: ключ
+// данных не меняется, поэтому записи не перешифровываются. Новый файл ключа
+// проверяется до замены прежнего и публикуется на сервере, откуда его
+// получают новые устройства.
+
+// MasterPasswordChange - итог смены мастер-пароля
+type MasterPasswordChange struct {
+	// SampleRecordID - локальный ID записи, расшифрованной новым файлом ключа
+	// перед заменой (0 - локальных записей нет)
+	SampleRecordID int
+	// Published - файл ключа сохранен на сервере
+	Published bool
+	// PublishError - причина, по которой файл не сохранен на сервере; он
+	// отправится при следующей синхронизации
+	PublishError error
+}
+
+// ChangeAccountPassword меняет пароль входа. Остальные сессии учетной записи
+// завершаются; токен текущего устройства заменяется новым.
+func (a *App) ChangeAccountPassword(ctx context.Context, oldPassword, newPassword string) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	token, err := a.httpClient.ChangePassword(ctx, user.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка смены пароля: %w", err)
+	}
+
+	if err := a.SaveToken(token); err != nil {
+		return fmt.Errorf("ошибка сохранения токена: %w", err)
+	}
+
+	a.log.Info("Пароль входа изменен, остальные сессии завершены")
+	return nil
+}
+
+// ChangeMasterPassword меняет мастер-пароль. Новый файл ключа заменяет
+// прежний, только если он открывается новым паролем и расшифровывает
+// образцовую запись хранилища. Затем файл публикуется на сервере; ошибка
+// публикации не отменяет смену и возвращается в результате.
+func (a *App) ChangeMasterPassword(ctx context.Context, oldPassword, newPassword string) (*MasterPasswordChange, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	sample, err := a.sampleRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	// Сервер заменит файл, только зная хэш прежнего пароля из заголовка
+	if a.state.Snapshot().PublishedKeyHash == "" {
+		previous := a.crypto.HeaderKeyHash()
+		if err := a.state.Update(func(s *AppState) { s.PublishedKeyHash = previous }); err != nil {
+			return nil, fmt.Errorf("ошибка сохранения состояния: %w", err)
+		}
+	}
+
+	result := &MasterPasswordChange{}
+	verify := func(check *crypto.MasterKeyManager) error {
+		if sample == nil {
+			return nil
+		}
+		// Менеджер приложения занят сменой пароля: расшифровываем
+		// проверочной копией, а не a.encryptor
+		encrypted, err := base64.StdEncoding.DecodeString(sample.EncryptedData)
+		if err != nil {
+			return fmt.Errorf("ошибка декодирования base64: %w", err)
+		}
+		plaintext, err := crypto.NewRecordEncryptor(check).DecryptRecordSecure(encrypted)
+		if err != nil {
+			return fmt.Errorf("запись %d не расшифровывается: %w", sample.ID, err)
+		}
+		defer plaintext.Close()
+		var data map[string]any
+		if err := json.Unmarshal(plaintext.Bytes(), &data); err != nil {
+			return fmt.Errorf("запись %d не расшифровывается: %w", sample.ID, err)
+		}
+		result.SampleRecordID = sample.ID
+		return nil
+	}
+
+	if err := a.crypto.ChangeMasterPasswordVerified(oldPassword, newPassword, verify); err != nil {
+		return nil, fmt.Errorf("ошибка смены мастер-пароля: %w", err)
+	}
+	a.log.Info("Мастер-пароль изменен", "sample_record", result.SampleRecordID)
+
+	if !a.IsAuthenticated() {
+		return result, nil
+	}
+	if err := a.PublishKeyFile(ctx); err != nil {
+		a.log.Warn("Файл мастер-ключа не сохранен на сервере", "error", err)
+		result.PublishError = err
+		return result, nil
+	}
+	result.Published = true
+	return result, nil
+}
+
+// sampleRecord возвращает зашифрованную запись для проверки нового файла ключа
+func (a *App) sampleRecord() (*LocalRecord, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	for _, rec := range records {
+		if rec.EncryptedData != "" {
+			return rec, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/user"
+)
+
+func TestApp_ChangeMasterPassword(t *testing.T) {
+	ctx := context.Background()
+	srv := &keyFileServer{}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, app.InitMasterKey("old-password"))
+	require.NoError(t, app.PublishKeyFile(ctx))
+	oldHash := srv.file.KeyHash
+
+	encrypted, err := app.encryptRecordData(map[string]string{"password": "s3cret"})
+	require.NoError(t, err)
+	rec := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: encrypted, Meta: json.RawMessage(`{"title":"mail"}`)}
+	require.NoError(t, app.storage.SaveRecord(rec))
+
+	_, err = app.ChangeMasterPassword(ctx, "wrong-password", "new-password")
+	assert.Error(t, err)
+	assert.Equal(t, oldHash, app.crypto.HeaderKeyHash())
+
+	result, err := app.ChangeMasterPassword(ctx, "old-password", "new-password")
+	require.NoError(t, err)
+	assert.Equal(t, rec.ID, result.SampleRecordID)
+	assert.True(t, result.Published)
+	assert.NoError(t, result.PublishError)
+
+	// Сервер заменил файл, хотя хэш из заголовка изменился
+	assert.NotEqual(t, oldHash, srv.file.KeyHash)
+	assert.Equal(t, app.crypto.HeaderKeyHash(), srv.file.KeyHash)
+	assert.Equal(t, srv.file.KeyHash, app.state.Snapshot().PublishedKeyHash)
+
+	// Новое устройство открывает файл с сервера новым паролем
+	device := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, device.RestoreMasterKeyFromServer(ctx))
+	require.NoError(t, device.crypto.UnlockMasterKey("new-password"))
+	var data map[string]string
+	require.NoError(t, device.decryptRecordData(encrypted, &data))
+	assert.Equal(t, "s3cret", data["password"])
+
+	app.LockMasterKey()
+	_, err = app.ChangeMasterPassword(ctx, "new-password", "other-password")
+	assert.ErrorIs(t, err, ErrMasterKeyLocked)
+}
+
+func TestApp_ChangeAccountPassword(t *testing.T) {
+	var got user.ChangePasswordRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/account/password" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		if got.OldPassword != "old-password" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": "current password is incorrect"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "new-token"})
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx := context.Background()
+	app := newKeyFileTestApp(t, ts.URL)
+
+	err := app.ChangeAccountPassword(ctx, "wrong", "new-password")
+	assert.ErrorContains(t, err, "current password is incorrect")
+	token, err := app.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	require.NoError(t, app.ChangeAccountPassword(ctx, "old-password", "new-password"))
+	assert.Equal(t, "new-password", got.NewPassword)
+	token, err = app.GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, "new-token", token)
+}
@@ -0,0 +1,160 @@
+// internal/app/client/permissions.go
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Каталог конфигурации содержит зашифрованный мастер-ключ, токен и локальную
+// базу. Доступ к ним должен быть только у владельца: каталоги 0700, файлы 0600.
+// На Windows вместо битов прав проверяется ACL.
+
+const (
+	secretDirPerm  os.FileMode = 0700
+	secretFilePerm os.FileMode = 0600
+)
+
+// PermissionIssue - файл или каталог с неверными правами доступа
+type PermissionIssue struct {
+	Path    string `json:"path"`
+	Dir     bool   `json:"dir"`
+	Problem string `json:"problem"`
+	// Exposed - доступ есть у других пользователей, а не только лишние биты владельца
+	Exposed  bool   `json:"exposed"`
+	Fixed    bool   `json:"fixed,omitempty"`
+	FixError string `json:"fix_error,omitempty"`
+}
+
+type permissionTarget struct {
+	path string
+	dir  bool
+}
+
+// CheckPermissions проверяет права на каталог конфигурации и файлы с секретами
+func (a *App) CheckPermissions() []PermissionIssue {
+	var issues []PermissionIssue
+	for _, target := range a.permissionTargets() {
+		info, err := os.Lstat(target.path)
+		if err != nil {
+			continue
+		}
+		problem, exposed, err := permissionProblem(target.path, info, target.dir)
+		if err != nil {
+			a.log.Debug("Не удалось проверить права доступа", "path", target.path, "error", err)
+			continue
+		}
+		if problem != "" {
+			issues = append(issues, PermissionIssue{
+				Path:    target.path,
+				Dir:     target.dir,
+				Problem: problem,
+				Exposed: exposed,
+			})
+		}
+	}
+	return issues
+}
+
+// FixPermissions исправляет права доступа, найденные CheckPermissions
+func (a *App) FixPermissions() []PermissionIssue {
+	issues := a.CheckPermissions()
+	for i := range issues {
+		if err := restrictPermissions(issues[i].Path, issues[i].Dir); err != nil {
+			issues[i].FixError = err.Error()
+			continue
+		}
+		issues[i].Fixed = true
+	}
+	return issues
+}
+
+// permissionTargets возвращает каталог конфигурации, его содержимое первого
+// уровня и файлы с секретами, которые могут лежать вне него
+func (a *App) permissionTargets() []permissionTarget {
+	seen := make(map[string]bool)
+	var targets []permissionTarget
+	add := func(path string, dir bool) {
+		path = filepath.Clean(path)
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		targets = append(targets, permissionTarget{path: path, dir: dir})
+	}
+
+	add(a.config.ConfigDir, true)
+
+	entries, err := os.ReadDir(a.config.ConfigDir)
+	if err == nil {
+		for _, entry := range entries {
+			path := filepath.Join(a.config.ConfigDir, entry.Name())
+			switch {
+			case entry.IsDir():
+				add(path, true)
+			case entry.Type().IsRegular():
+				add(path, false)
+			}
+			// Сокет агента и символические ссылки не проверяем
+		}
+	}
+
+	for _, path := range []string{
+		a.config.MasterKeyPath,
+		a.config.TokenPath,
+		a.config.DataPath,
+		a.config.DataPath + "-wal",
+		a.config.DataPath + "-shm",
+	} {
+		add(path, false)
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].path < targets[j].path })
+	return targets
+}
+
+func wantPerm(dir bool) os.FileMode {
+	if dir {
+		return secretDirPerm
+	}
+	return secretFilePerm
+}
+
+// writeSecretFile записывает файл с секретом через временный файл в том же
+// каталоге: права 0600 выставляются до записи данных, а прежний файл с
+// любыми правами заменяется целиком. os.WriteFile права существующего файла
+// не меняет, и ключ успел бы попасть в файл, доступный другим.
+func writeSecretFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// CreateTemp создает файл с 0600; Chmod не зависит от umask
+	if err := tmp.Chmod(secretFilePerm); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка установки прав: %w", err)
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("ошибка сохранения файла: %w", err)
+	}
+	return nil
+}
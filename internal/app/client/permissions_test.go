@@ -0,0 +1,36 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSecretFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("права файлов на Windows задает ACL")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "id_ed25519")
+
+	// Прежний файл доступен всем: после записи ключа права должны стать 0600
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+	require.NoError(t, os.Chmod(path, 0644))
+
+	require.NoError(t, writeSecretFile(path, []byte("private key")))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, secretFilePerm, info.Mode().Perm())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "временный файл не должен оставаться")
+}
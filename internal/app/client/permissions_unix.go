@@ -0,0 +1,29 @@
+// internal/app/client/permissions_unix.go
+//go:build !windows
+
+package client
+
+import (
+	"fmt"
+	"os"
+)
+
+// permissionProblem сравнивает биты прав с 0600/0700. exposed - права есть
+// у группы или остальных пользователей.
+func permissionProblem(_ string, info os.FileInfo, dir bool) (string, bool, error) {
+	perm := info.Mode().Perm()
+	want := wantPerm(dir)
+	// Более строгие права (например, 0400) допустимы
+	if perm&^want == 0 {
+		return "", false, nil
+	}
+
+	if perm&0077 != 0 {
+		return fmt.Sprintf("права %04o: доступ есть у группы или других пользователей (нужно %04o)", perm, want), true, nil
+	}
+	return fmt.Sprintf("права %04o (нужно %04o)", perm, want), false, nil
+}
+
+func restrictPermissions(path string, dir bool) error {
+	return os.Chmod(path, wantPerm(dir))
+}
@@ -0,0 +1,129 @@
+// internal/app/client/permissions_windows.go
+//go:build windows
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// На Windows биты прав не отражают доступ: проверяется DACL. Разрешающие
+// записи допустимы только для текущего пользователя, SYSTEM и администраторов.
+
+func permissionProblem(path string, _ os.FileInfo, _ bool) (string, bool, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return "", false, err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return "", false, err
+	}
+	if dacl == nil {
+		return "DACL отсутствует: доступ есть у всех пользователей", true, nil
+	}
+
+	allowed, err := allowedSIDs()
+	if err != nil {
+		return "", false, err
+	}
+
+	var others []string
+	for i := uint32(0); i < uint32(dacl.AceCount); i++ {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return "", false, err
+		}
+		if ace.Header.AceType != windows.ACCESS_ALLOWED_ACE_TYPE {
+			continue
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+		if sidIn(sid, allowed) {
+			continue
+		}
+		others = append(others, sidName(sid))
+	}
+
+	if len(others) == 0 {
+		return "", false, nil
+	}
+	return "доступ есть у " + strings.Join(others, ", "), true, nil
+}
+
+// restrictPermissions заменяет DACL на защищенный от наследования список:
+// полный доступ для текущего пользователя, SYSTEM и администраторов
+func restrictPermissions(path string, dir bool) error {
+	allowed, err := allowedSIDs()
+	if err != nil {
+		return err
+	}
+
+	inheritance := uint32(windows.NO_INHERITANCE)
+	if dir {
+		inheritance = windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT
+	}
+
+	entries := make([]windows.EXPLICIT_ACCESS, 0, len(allowed))
+	for _, sid := range allowed {
+		entries = append(entries, windows.EXPLICIT_ACCESS{
+			AccessPermissions: windows.GENERIC_ALL,
+			AccessMode:        windows.GRANT_ACCESS,
+			Inheritance:       inheritance,
+			Trustee: windows.TRUSTEE{
+				TrusteeForm:  windows.TRUSTEE_IS_SID,
+				TrusteeValue: windows.TrusteeValueFromSID(sid),
+			},
+		})
+	}
+
+	acl, err := windows.ACLFromEntries(entries, nil)
+	if err != nil {
+		return err
+	}
+
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION,
+		nil, nil, acl, nil)
+}
+
+func allowedSIDs() ([]*windows.SID, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить текущего пользователя: %w", err)
+	}
+
+	sids := []*windows.SID{user.User.Sid}
+	for _, known := range []windows.WELL_KNOWN_SID_TYPE{windows.WinLocalSystemSid, windows.WinBuiltinAdministratorsSid} {
+		sid, err := windows.CreateWellKnownSid(known)
+		if err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+	return sids, nil
+}
+
+func sidIn(sid *windows.SID, list []*windows.SID) bool {
+	for _, s := range list {
+		if sid.Equals(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func sidName(sid *windows.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+	if domain != "" {
+		return domain + `\` + account
+	}
+	return account
+}
@@ -0,0 +1,51 @@
+// internal/app/client/pin.go
+package client
+
+import (
+	"fmt"
+)
+
+// Быстрая разблокировка по PIN. Копия мастер-ключа шифруется ключом из PIN
+// и секрета в хранилище ОС. После pin_attempts неверных PIN подряд копия
+// удаляется, и разблокировать ключ можно только мастер-паролем.
+
+// PINEnabled проверяет, включена ли разблокировка по PIN
+func (a *App) PINEnabled() bool {
+	return a.crypto.PINEnabled()
+}
+
+// PINAttemptsLeft возвращает число оставшихся попыток ввода PIN
+func (a *App) PINAttemptsLeft() int {
+	return max(a.config.PINAttempts-a.crypto.PINFailures(), 0)
+}
+
+// EnablePIN включает разблокировку по PIN. Требует разблокированный мастер-ключ
+// и доступное хранилище секретов ОС.
+func (a *App) EnablePIN(pin string) error {
+	if !a.IsMasterKeyUnlocked() {
+		return ErrMasterKeyLocked
+	}
+	if !a.keyProvider.Available() {
+		return fmt.Errorf("хранилище %s недоступно: PIN требует хранилище секретов ОС", a.keyProvider.Name())
+	}
+	return a.crypto.EnablePIN(a.keyProvider, pin)
+}
+
+// DisablePIN отключает разблокировку по PIN
+func (a *App) DisablePIN() error {
+	return a.crypto.DisablePIN(a.keyProvider)
+}
+
+// UnlockWithPIN разблокирует мастер-ключ по PIN.
+// Возвращает *crypto.WrongPINError при неверном PIN и crypto.ErrPINDisabled,
+// если попытки исчерпаны и нужен мастер-пароль.
+func (a *App) UnlockWithPIN(pin string) error {
+	if err := a.crypto.UnlockWithPIN(a.keyProvider, pin, a.config.PINAttempts); err != nil {
+		a.log.Warn("Неудачная разблокировка по PIN", "error", err)
+		return err
+	}
+
+	a.state.SetMasterKeyReady(true)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// internal/app/client/preview.go
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"gophkeeper/internal/domain/record"
+)
+
+const previewSnippetLen = 60
+
+// RecordPreview - готовые строки для отображения записи в списках.
+// Вычисляется при записи в локальную базу и хранится в отдельной колонке,
+// поэтому списку не нужно расшифровывать данные или разбирать метаданные.
+type RecordPreview struct {
+	Title   string `json:"title"`
+	Label   string `json:"label,omitempty"`   // короткая метка: хост ресурса, банк, имя файла
+	Masked  string `json:"masked,omitempty"`  // маскированный номер карты
+	Snippet string `json:"snippet,omitempty"` // начало описания или комментария
+}
+
+// Summary возвращает строку деталей записи для вывода рядом с названием
+func (p *RecordPreview) Summary() string {
+	if p == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, 3)
+	for _, s := range []string{p.Label, p.Masked, p.Snippet} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// buildRecordPreview строит превью из открытых метаданных записи.
+// Masked вычисляется только из расшифрованных данных и здесь не заполняется.
+func buildRecordPreview(recType record.RecType, meta []byte) *RecordPreview {
+	var m map[string]interface{}
+	if len(meta) > 0 {
+		_ = json.Unmarshal(meta, &m)
+	}
+
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return strings.TrimSpace(s)
+	}
+
+	p := &RecordPreview{Title: str("title")}
+	if p.Title == "" {
+		p.Title = "Без названия"
+	}
+
+	switch recType {
+	case record.RecTypeLogin:
+		p.Label = resourceLabel(str("resource"))
+	case record.RecTypeCard:
+		p.Label = str("bank_name")
+	case record.RecTypeText:
+		p.Label = str("format")
+	case record.RecTypeBinary:
+		p.Label = str("filename")
+		p.Snippet = snippet(str("description"))
+	case record.RecTypeOTP:
+		p.Label = str("issuer")
+		p.Snippet = str("account_name")
+	case record.RecTypeSSHKey:
+		p.Label = strings.TrimSpace(str("key_type") + " " + shortFingerprint(str("fingerprint")))
+		p.Snippet = snippet(str("comment"))
+	}
+
+	return p
+}
+
+// resourceLabel возвращает хост ресурса без схемы и www - как подпись у значка сайта
+func resourceLabel(resource string) string {
+	if resource == "" {
+		return ""
+	}
+
+	raw := resource
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return snippet(resource)
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func shortFingerprint(fp string) string {
+	if utf8.RuneCountInString(fp) <= 20 {
+		return fp
+	}
+	return string([]rune(fp)[:20]) + "…"
+}
+
+func snippet(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= previewSnippetLen {
+		return s
+	}
+	return string([]rune(s)[:previewSnippetLen-1]) + "…"
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func newPreviewStorage(t *testing.T) *SQLiteStorage {
+	t.Helper()
+
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "data.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+	return storage
+}
+
+// storedPreview читает колонку preview записи
+func storedPreview(t *testing.T, storage *SQLiteStorage, id int) string {
+	t.Helper()
+
+	var preview string
+	require.NoError(t, storage.GetDB().QueryRow(`SELECT preview FROM records WHERE id = ?`, id).Scan(&preview))
+	return preview
+}
+
+func TestBuildRecordPreview(t *testing.T) {
+	tests := []struct {
+		recType record.RecType
+		meta    string
+		want    RecordPreview
+	}{
+		{record.RecTypeLogin, `{"title":"GitHub","resource":"https://www.GitHub.com/login"}`, RecordPreview{Title: "GitHub", Label: "github.com"}},
+		{record.RecTypeCard, `{"title":"Visa","bank_name":"Tinkoff"}`, RecordPreview{Title: "Visa", Label: "Tinkoff"}},
+		{record.RecTypeBinary, `{"title":"Key","filename":"id_rsa"}`, RecordPreview{Title: "Key", Label: "id_rsa"}},
+		{record.RecTypeText, `{}`, RecordPreview{Title: "Без названия"}},
+		{record.RecTypeText, `not json`, RecordPreview{Title: "Без названия"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, &tt.want, buildRecordPreview(tt.recType, []byte(tt.meta)), tt.meta)
+	}
+}
+
+func TestSQLiteStorage_Preview(t *testing.T) {
+	storage := newPreviewStorage(t)
+
+	rec := &LocalRecord{
+		Type:         record.RecTypeLogin,
+		Meta:         json.RawMessage(`{"title":"GitHub","resource":"https://github.com/login"}`),
+		LastModified: time.Now(),
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(t, storage.SaveRecord(rec))
+	assert.JSONEq(t, `{"title":"GitHub","label":"github.com"}`, storedPreview(t, storage, rec.ID))
+	assert.Equal(t, "github.com", rec.Preview.Label)
+
+	t.Run("update rewrites the preview", func(t *testing.T) {
+		rec.Meta = json.RawMessage(`{"title":"GitLab","resource":"gitlab.com"}`)
+		require.NoError(t, storage.UpdateRecord(rec))
+		assert.JSONEq(t, `{"title":"GitLab","label":"gitlab.com"}`, storedPreview(t, storage, rec.ID))
+	})
+
+	t.Run("stale preview is rebuilt when the record changes", func(t *testing.T) {
+		_, err := storage.GetDB().Exec(`UPDATE records SET preview = '{"title":"stale"}' WHERE id = ?`, rec.ID)
+		require.NoError(t, err)
+
+		// Изменение с сервера при синхронизации сохраняется той же записью
+		stored, err := storage.GetRecord(rec.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "stale", stored.Preview.Title)
+
+		stored.Meta = json.RawMessage(`{"title":"Bitbucket","resource":"bitbucket.org"}`)
+		stored.Version++
+		require.NoError(t, storage.UpdateRecord(stored))
+		assert.JSONEq(t, `{"title":"Bitbucket","label":"bitbucket.org"}`, storedPreview(t, storage, rec.ID))
+	})
+
+	t.Run("card keeps the masked number on meta updates", func(t *testing.T) {
+		card := &LocalRecord{
+			Type:         record.RecTypeCard,
+			Meta:         json.RawMessage(`{"title":"Visa","bank_name":"Tinkoff"}`),
+			LastModified: time.Now(),
+			CreatedAt:    time.Now(),
+			Preview:      &RecordPreview{Masked: record.MaskCardNumber("4111111111111111")},
+		}
+		require.NoError(t, storage.SaveRecord(card))
+
+		updated := &LocalRecord{
+			ID:           card.ID,
+			UUID:         card.UUID,
+			Type:         record.RecTypeCard,
+			Meta:         json.RawMessage(`{"title":"Visa Gold","bank_name":"Tinkoff"}`),
+			LastModified: time.Now(),
+			CreatedAt:    card.CreatedAt,
+		}
+		require.NoError(t, storage.UpdateRecord(updated))
+
+		stored, err := storage.GetRecord(card.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Visa Gold", stored.Preview.Title)
+		assert.Equal(t, card.Preview.Masked, stored.Preview.Masked)
+		assert.NotContains(t, storedPreview(t, storage, card.ID), "4111111111111111")
+	})
+}
+
+// TestApp_ListRecords_Preview проверяет, что список записей (record ls)
+// строится из сохраненного превью: без мастер-ключа, расшифровки данных и
+// разбора метаданных
+func TestApp_ListRecords_Preview(t *testing.T) {
+	app := newTestApp(t)
+	storage := newPreviewStorage(t)
+	app.storage = storage
+
+	rec := &LocalRecord{
+		Type:          record.RecTypeLogin,
+		EncryptedData: "not-a-ciphertext",
+		Meta:          json.RawMessage(`{"title":"GitHub","resource":"github.com"}`),
+		LastModified:  time.Now(),
+		CreatedAt:     time.Now(),
+	}
+	require.NoError(t, storage.SaveRecord(rec))
+	_, err := storage.GetDB().Exec(`UPDATE records SET meta = 'not json' WHERE id = ?`, rec.ID)
+	require.NoError(t, err)
+
+	require.False(t, app.IsMasterKeyUnlocked())
+	records, err := app.ListRecords(context.Background(), &RecordFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.NotNil(t, records[0].Preview)
+	assert.Equal(t, "GitHub", records[0].Preview.Title)
+	assert.Equal(t, "github.com", records[0].Preview.Summary())
+}
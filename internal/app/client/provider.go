@@ -0,0 +1,66 @@
+// internal/app/client/provider.go
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// providerShutdownTimeout - сколько ждать завершения начатых запросов при остановке
+const providerShutdownTimeout = 5 * time.Second
+
+// RunSecretsProvider обслуживает srv (по TLS, если задан srv.TLSConfig) и
+// синхронизирует хранилище с сервером до отмены ctx. Первая синхронизация выполняется сразу, чтобы не отдавать
+// секреты из устаревшей копии. В отличие от агента, мастер-ключ не
+// блокируется по простою: провайдер отвечает на запросы без участия человека.
+func (a *App) RunSecretsProvider(ctx context.Context, srv *http.Server) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if token, err := a.GetToken(); err == nil {
+		a.httpClient.SetToken(token)
+		if _, err := a.syncService.Sync(ctx); err != nil {
+			a.log.Warn("Первая синхронизация не удалась, секреты отдаются из локальной копии", "error", err)
+		}
+	} else {
+		a.log.Warn("Вход не выполнен: секреты отдаются из локальной копии без синхронизации")
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.startSync(ctx)
+	}()
+	defer a.wg.Wait()
+
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- srv.ListenAndServe()
+	}()
+	a.log.Info("Провайдер секретов запущен", "addr", srv.Addr)
+
+	select {
+	case err := <-errCh:
+		cancel()
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, stop := context.WithTimeout(context.Background(), providerShutdownTimeout)
+	defer stop()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	a.log.Info("Провайдер секретов остановлен")
+	return nil
+}
@@ -0,0 +1,81 @@
+// internal/app/client/quota.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// QuotaUsage - использование хранилища на сервере
+type QuotaUsage struct {
+	Used      int64 `json:"used"`
+	Limit     int64 `json:"limit"`
+	Remaining int64 `json:"remaining"`
+	// Custom - лимит задан администратором
+	Custom bool `json:"custom"`
+}
+
+// QuotaExceededError возвращается, если сервер отклонил запись из-за превышения квоты
+type QuotaExceededError struct {
+	Used      int64 `json:"used"`
+	Limit     int64 `json:"limit"`
+	Requested int64 `json:"requested"`
+	Remaining int64 `json:"remaining"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("превышена квота хранилища: занято %s из %s, требуется еще %s (свободно %s)",
+		FormatBytes(e.Used), FormatBytes(e.Limit), FormatBytes(e.Requested), FormatBytes(e.Remaining))
+}
+
+func (e *QuotaExceededError) Kind() apperr.Kind {
+	return apperr.QuotaExceeded
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, apperr.QuotaExceeded)
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == apperr.QuotaExceeded
+}
+
+// quotaFromResponse распознает ответ 413 о превышении квоты
+func quotaFromResponse(statusCode int, body []byte) *QuotaExceededError {
+	if statusCode != http.StatusRequestEntityTooLarge {
+		return nil
+	}
+
+	var qerr QuotaExceededError
+	if err := json.Unmarshal(body, &qerr); err != nil || qerr.Limit == 0 {
+		return nil
+	}
+	return &qerr
+}
+
+// FormatBytes выводит размер в удобных единицах
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d Б", n)
+	}
+
+	units := []string{"КБ", "МБ", "ГБ", "ТБ"}
+	value := float64(n) / unit
+	i := 0
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// Quota возвращает использование хранилища на сервере
+func (a *App) Quota(ctx context.Context) (*QuotaUsage, error) {
+	if !a.IsAuthenticated() {
+		return nil, fmt.Errorf("требуется аутентификация. Выполните: gophkeeper auth login")
+	}
+
+	return a.httpClient.GetQuota(ctx)
+}
@@ -0,0 +1,58 @@
+// internal/app/client/record_lock.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+// Защита записи от изменений: защищенную запись (коды восстановления,
+// root-учетные данные) нельзя изменить или удалить, пока защиту не снимут
+// явно с повторным вводом мастер-пароля. Признак хранится в открытых
+// метаданных (meta.locked) и синхронизируется с остальными устройствами;
+// сервер тоже отклоняет изменение и удаление защищенных записей.
+
+// LockRecord защищает запись от изменений и удаления
+func (a *App) LockRecord(ctx context.Context, id int) error {
+	return a.setRecordLocked(ctx, id, true)
+}
+
+// UnlockRecord снимает защиту с записи после проверки мастер-пароля
+func (a *App) UnlockRecord(ctx context.Context, id int, password string) error {
+	if err := a.VerifyMasterPassword(password); err != nil {
+		if errors.Is(err, crypto.ErrWrongPassword) {
+			return fmt.Errorf("неверный мастер-пароль: %w", err)
+		}
+		return fmt.Errorf("ошибка проверки мастер-пароля: %w", err)
+	}
+	return a.setRecordLocked(ctx, id, false)
+}
+
+func (a *App) setRecordLocked(ctx context.Context, id int, locked bool) error {
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return err
+	}
+	if rec.DeletedAt != nil {
+		return fmt.Errorf("%w: запись в корзине", ErrRecordNotFound)
+	}
+	if record.IsLocked(rec.Meta) == locked {
+		return nil
+	}
+
+	meta, err := record.SetLocked(rec.Meta, locked)
+	if err != nil {
+		return fmt.Errorf("ошибка изменения метаданных записи: %w", err)
+	}
+
+	// Данные записи не меняются: сервер допускает снятие защиты только так
+	return a.updateRecord(ctx, rec, GenericRecordRequest{
+		Type: rec.Type,
+		Data: rec.EncryptedData,
+		Meta: meta,
+	})
+}
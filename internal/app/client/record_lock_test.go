@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestApp_LockRecord(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	ctx := context.Background()
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	rec := &LocalRecord{
+		Type:          record.RecTypeText,
+		EncryptedData: "encrypted",
+		Meta:          json.RawMessage(`{"title":"Recovery codes"}`),
+		LastModified:  time.Now(),
+	}
+	require.NoError(t, app.storage.SaveRecord(rec))
+
+	require.NoError(t, app.LockRecord(ctx, rec.ID))
+	locked, err := app.storage.GetRecord(rec.ID)
+	require.NoError(t, err)
+	assert.True(t, record.IsLocked(locked.Meta))
+	assert.Equal(t, "encrypted", locked.EncryptedData)
+
+	update := GenericRecordRequest{Type: record.RecTypeText, Data: "changed", Meta: locked.Meta}
+	assert.ErrorIs(t, app.UpdateRecord(ctx, rec.ID, update), ErrRecordLocked)
+	assert.ErrorIs(t, app.DeleteRecord(ctx, rec.ID, false), ErrRecordLocked)
+	assert.ErrorIs(t, app.DeleteRecord(ctx, rec.ID, true), ErrRecordLocked)
+
+	// Снятие защиты требует мастер-пароля
+	assert.ErrorIs(t, app.UnlockRecord(ctx, rec.ID, "wrong"), crypto.ErrWrongPassword)
+	require.NoError(t, app.UnlockRecord(ctx, rec.ID, "password123"))
+
+	unlocked, err := app.storage.GetRecord(rec.ID)
+	require.NoError(t, err)
+	assert.False(t, record.IsLocked(unlocked.Meta))
+	assert.JSONEq(t, `{"title":"Recovery codes"}`, string(unlocked.Meta))
+	assert.NoError(t, app.UpdateRecord(ctx, rec.ID, update))
+}
@@ -0,0 +1,118 @@
+// Package secretapi - HTTP-API секретов агента в стиле HashiCorp Vault (KV v1).
+// Инструменты, которые умеют читать секреты только из HTTP-бэкенда
+// (провайдер Terraform http, клиенты Vault), получают поля записи по
+// GET /v1/secret/<запись> в ответе {"data": {...}}.
+package secretapi
+
+import (
+	"net/http"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/app/client/httpapi"
+)
+
+// TokenHeader - заголовок с токеном, как в Vault; принимается и Authorization: Bearer
+const TokenHeader = "X-Vault-Token"
+
+// SecretResponse - поля записи в формате ответа Vault KV v1
+type SecretResponse struct {
+	Data          map[string]string `json:"data"`
+	LeaseDuration int               `json:"lease_duration"`
+	Renewable     bool              `json:"renewable"`
+}
+
+// HealthResponse - ответ /v1/sys/health: заблокированный мастер-ключ
+// соответствует запечатанному (sealed) хранилищу Vault
+type HealthResponse struct {
+	Initialized bool `json:"initialized"`
+	Sealed      bool `json:"sealed"`
+}
+
+// ErrorResponse - ошибки в формате Vault
+type ErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// Handler обслуживает запросы:
+//
+//	GET /v1/sys/health        - 200, или 503, пока мастер-ключ заблокирован
+//	GET /v1/secret/{record...} - поля записи: {"data": {"поле": "значение"}}
+//
+// Запись задается ID или точным названием; "/" в названии допускается.
+// Запросы секретов требуют токена в заголовке X-Vault-Token или
+// Authorization: Bearer.
+type Handler struct {
+	vault      httpapi.Vault
+	log        *slog.Logger
+	mux        *http.ServeMux
+	authorized func(http.HandlerFunc) http.HandlerFunc
+}
+
+// NewHandler создает обработчик; token - общий секрет с локальными инструментами
+func NewHandler(vault httpapi.Vault, token string, log *slog.Logger) *Handler {
+	h := &Handler{
+		vault: vault,
+		log:   log.With("component", "secret_api"),
+		mux:   http.NewServeMux(),
+	}
+	h.authorized = httpapi.Authorized(token, tokenOf, func(w http.ResponseWriter) {
+		writeError(w, http.StatusForbidden, "permission denied")
+	}, h.log)
+
+	h.mux.HandleFunc("GET /v1/sys/health", h.health)
+	h.mux.HandleFunc("GET /v1/secret/{record...}", h.authorized(h.getSecret))
+
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) health(w http.ResponseWriter, _ *http.Request) {
+	sealed := !h.vault.IsMasterKeyUnlocked()
+	status := http.StatusOK
+	if sealed {
+		status = http.StatusServiceUnavailable
+	}
+	httpapi.WriteJSON(w, status, HealthResponse{Initialized: true, Sealed: sealed})
+}
+
+func (h *Handler) getSecret(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("record")
+	if name == "" {
+		writeError(w, http.StatusNotFound, "record name is required")
+		return
+	}
+
+	if !h.vault.IsMasterKeyUnlocked() {
+		writeError(w, http.StatusServiceUnavailable, "master key is locked")
+		return
+	}
+
+	fields, err := h.vault.SecretFields(r.Context(), name)
+	if err != nil {
+		status := httpapi.StatusOf(err)
+		if status == http.StatusInternalServerError {
+			h.log.Error("failed to read secret", "record", name, "error", err)
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+
+	h.log.Info("secret served", "record", name, "remote", r.RemoteAddr)
+	httpapi.WriteJSON(w, http.StatusOK, SecretResponse{Data: fields})
+}
+
+// tokenOf возвращает токен из X-Vault-Token или Authorization: Bearer
+func tokenOf(r *http.Request) string {
+	if token := r.Header.Get(TokenHeader); token != "" {
+		return token
+	}
+	return httpapi.BearerToken(r)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	httpapi.WriteJSON(w, status, ErrorResponse{Errors: []string{msg}})
+}
@@ -0,0 +1,109 @@
+package secretapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+const testToken = "agent-token-0123456789"
+
+var errTestNotFound = apperr.New(apperr.NotFound, "record not found")
+
+type fakeVault struct {
+	unlocked bool
+	records  map[string]map[string]string
+}
+
+func (v *fakeVault) IsMasterKeyUnlocked() bool { return v.unlocked }
+
+func (v *fakeVault) SecretFields(_ context.Context, record string) (map[string]string, error) {
+	fields, ok := v.records[record]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errTestNotFound, record)
+	}
+	return fields, nil
+}
+
+func newTestServer(t *testing.T, vault *fakeVault) *httptest.Server {
+	t.Helper()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(NewHandler(vault, testToken, log))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(t *testing.T, url string, header http.Header, body interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header = header
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(body))
+	return resp.StatusCode
+}
+
+func TestHandler_GetSecret(t *testing.T) {
+	vault := &fakeVault{
+		unlocked: true,
+		records: map[string]map[string]string{
+			"prod/db": {"username": "app", "password": "s3cret"},
+		},
+	}
+	srv := newTestServer(t, vault)
+	vaultToken := http.Header{TokenHeader: {testToken}}
+
+	var secret SecretResponse
+	status := get(t, srv.URL+"/v1/secret/prod/db", vaultToken, &secret)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]string{"username": "app", "password": "s3cret"}, secret.Data)
+
+	status = get(t, srv.URL+"/v1/secret/prod/db", http.Header{"Authorization": {"Bearer " + testToken}}, &secret)
+	assert.Equal(t, http.StatusOK, status)
+
+	var errResp ErrorResponse
+	status = get(t, srv.URL+"/v1/secret/unknown", vaultToken, &errResp)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, []string{"record not found: unknown"}, errResp.Errors)
+
+	for _, header := range []http.Header{{}, {TokenHeader: {"wrong"}}, {"Authorization": {"Basic " + testToken}}} {
+		status = get(t, srv.URL+"/v1/secret/prod/db", header, &errResp)
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Equal(t, []string{"permission denied"}, errResp.Errors)
+	}
+}
+
+func TestHandler_Sealed(t *testing.T) {
+	vault := &fakeVault{records: map[string]map[string]string{"db": {"password": "s3cret"}}}
+	srv := newTestServer(t, vault)
+
+	var health HealthResponse
+	status := get(t, srv.URL+"/v1/sys/health", http.Header{}, &health)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.True(t, health.Sealed)
+
+	var errResp ErrorResponse
+	status = get(t, srv.URL+"/v1/secret/db", http.Header{TokenHeader: {testToken}}, &errResp)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, []string{"master key is locked"}, errResp.Errors)
+
+	vault.unlocked = true
+	status = get(t, srv.URL+"/v1/sys/health", http.Header{}, &health)
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, health.Sealed)
+}
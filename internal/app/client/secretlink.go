@@ -0,0 +1,187 @@
+// internal/app/client/secretlink.go
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/secretlink"
+)
+
+// Одноразовые ссылки на секреты. Поле или запись шифруется случайным ключом,
+// на сервер загружается только шифротекст, а ключ записывается в фрагмент
+// ссылки (после #): браузеры и HTTP-клиенты не отправляют фрагмент на сервер.
+// Сервер ограничивает срок и число просмотров и удаляет шифротекст после
+// последнего просмотра. Ссылку открывает браузер (страница /s/<id>) или
+// команда gophkeeper share open.
+
+// secretLinkPath - путь страницы секрета на сервере
+const secretLinkPath = "/s/"
+
+var (
+	// ErrInvalidSecretLink - ссылка не соответствует формату <сервер>/s/<id>#<ключ>
+	ErrInvalidSecretLink = apperr.New(apperr.Invalid, "неверная ссылка на секрет")
+	// ErrSecretLinkGone - секрета нет: срок истек, просмотры исчерпаны или ссылка удалена
+	ErrSecretLinkGone = apperr.New(apperr.NotFound, "секрет не найден: срок истек, просмотры исчерпаны или ссылка удалена")
+)
+
+// SharedSecret - содержимое одноразовой ссылки
+type SharedSecret struct {
+	Title  string            `json:"title,omitempty"`
+	Type   string            `json:"type,omitempty"`
+	Fields map[string]string `json:"fields"`
+}
+
+// SecretLink - ссылка на секрет; URL с ключом известен только при создании
+type SecretLink struct {
+	secretlink.Link
+	URL string
+}
+
+// CreateSecretLink шифрует поле field записи name (ID или название) или, если
+// field пуст, все строковые поля записи и выпускает ссылку на срок ttl с
+// maxViews просмотрами; нулевые значения - значения по умолчанию сервера
+func (a *App) CreateSecretLink(ctx context.Context, name, field string, ttl time.Duration, maxViews int) (*SecretLink, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	rec, err := a.findSecretRecord(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := a.SecretFields(ctx, strconv.Itoa(rec.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	secret := SharedSecret{Type: string(rec.Type), Fields: fields}
+	if rec.Preview != nil {
+		secret.Title = rec.Preview.Title
+	}
+	if field != "" {
+		value, ok := fields[field]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for k := range fields {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("%w: %q в записи %q (доступны: %s)", ErrNoSecretField, field, name, strings.Join(names, ", "))
+		}
+		secret.Fields = map[string]string{field: value}
+	}
+	if len(secret.Fields) == 0 {
+		return nil, fmt.Errorf("в записи %q нет полей, которые можно передать ссылкой", name)
+	}
+
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации секрета: %w", err)
+	}
+	key, ciphertext, err := crypto.SealShared(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := a.httpClient.CreateSecretLink(ctx, ciphertext, ttl, maxViews)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ссылки: %w", err)
+	}
+	a.log.Info("Создана ссылка на секрет", "link_id", link.ID, "record_id", rec.ID,
+		"max_views", link.MaxViews, "expires_at", link.ExpiresAt)
+
+	return &SecretLink{
+		Link: *link,
+		URL:  a.httpClient.baseURL + secretLinkPath + link.ID + "#" + base64.RawURLEncoding.EncodeToString(key),
+	}, nil
+}
+
+// ListSecretLinks возвращает ссылки на секреты, которые еще можно открыть
+// (и истекшие, пока сервер их не удалил); URL с ключом сервер не знает
+func (a *App) ListSecretLinks(ctx context.Context) ([]secretlink.Link, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	links, err := a.httpClient.ListSecretLinks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ссылок: %w", err)
+	}
+	return links, nil
+}
+
+// RevokeSecretLink удаляет ссылку вместе с шифротекстом
+func (a *App) RevokeSecretLink(ctx context.Context, id string) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	if err := a.httpClient.RevokeSecretLink(ctx, id); err != nil {
+		return fmt.Errorf("ошибка удаления ссылки: %w", err)
+	}
+	return nil
+}
+
+// OpenSecretLink открывает ссылку и расшифровывает секрет. Ссылка может вести
+// на другой сервер; вход и мастер-ключ не нужны. Открытие расходует просмотр.
+func (a *App) OpenSecretLink(ctx context.Context, rawURL string) (*SharedSecret, error) {
+	origin, id, key, err := ParseSecretLink(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := a.httpClient.OpenSecretLink(ctx, origin, id)
+	if err != nil {
+		if errors.Is(err, apperr.NotFound) {
+			return nil, ErrSecretLinkGone
+		}
+		return nil, fmt.Errorf("ошибка открытия ссылки: %w", err)
+	}
+
+	plaintext, err := crypto.OpenShared(key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	var secret SharedSecret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return nil, fmt.Errorf("ошибка разбора секрета: %w", err)
+	}
+	return &secret, nil
+}
+
+// ParseSecretLink разбирает ссылку <сервер>/s/<id>#<ключ> на адрес сервера,
+// ID ссылки и ключ расшифровки
+func ParseSecretLink(rawURL string) (origin, id string, key []byte, err error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", nil, fmt.Errorf("%w: ожидается http(s)://<сервер>%s<id>#<ключ>", ErrInvalidSecretLink, secretLinkPath)
+	}
+
+	id, ok := strings.CutPrefix(u.Path, secretLinkPath)
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", "", nil, fmt.Errorf("%w: ожидается путь %s<id>", ErrInvalidSecretLink, secretLinkPath)
+	}
+	if u.Fragment == "" {
+		return "", "", nil, fmt.Errorf("%w: в ссылке нет ключа после #", ErrInvalidSecretLink)
+	}
+	key, err = base64.RawURLEncoding.DecodeString(u.Fragment)
+	if err != nil || len(key) != crypto.ShareKeyLength {
+		return "", "", nil, fmt.Errorf("%w: поврежден ключ после #", ErrInvalidSecretLink)
+	}
+
+	return u.Scheme + "://" + u.Host, id, key, nil
+}
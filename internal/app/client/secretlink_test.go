@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+)
+
+// secretLinkServer хранит шифротексты ссылок и удаляет их после последнего просмотра
+type secretLinkServer struct {
+	links       map[string]*secretlink.Link
+	ciphertexts map[string][]byte
+	openAuth    []string
+}
+
+func (s *secretLinkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/share/links":
+		var body struct {
+			Ciphertext []byte `json:"ciphertext"`
+			TTL        string `json:"ttl"`
+			MaxViews   int    `json:"max_views"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		ttl, _ := time.ParseDuration(body.TTL)
+		id := "link" + string(rune('a'+len(s.links)))
+		s.links[id] = &secretlink.Link{ID: id, MaxViews: body.MaxViews, ExpiresAt: time.Now().Add(ttl)}
+		s.ciphertexts[id] = body.Ciphertext
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "Ok", "data": s.links[id]})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/secrets/"):
+		s.openAuth = append(s.openAuth, r.Header.Get("Authorization"))
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/secrets/"), "/open")
+		link, ok := s.links[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": "secret link not found or expired"})
+			return
+		}
+		ciphertext := s.ciphertexts[id]
+		if link.Views++; link.Views >= link.MaxViews {
+			delete(s.links, id)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ciphertext": ciphertext})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestApp_SecretLink(t *testing.T) {
+	ctx := context.Background()
+	srv := &secretLinkServer{links: map[string]*secretlink.Link{}, ciphertexts: map[string][]byte{}}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, app.InitMasterKey("password123"))
+	encrypted, err := app.encryptRecordData(map[string]string{"username": "alice", "password": "s3cret"})
+	require.NoError(t, err)
+	rec := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: encrypted,
+		Meta: json.RawMessage(`{"title":"GitHub"}`), LastModified: time.Now()}
+	require.NoError(t, app.storage.SaveRecord(rec))
+
+	link, err := app.CreateSecretLink(ctx, "GitHub", "password", time.Hour, 1)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link.URL, ts.URL+"/s/"+link.ID+"#"), link.URL)
+	assert.NotContains(t, string(srv.ciphertexts[link.ID]), "s3cret")
+
+	// Ссылку открывает получатель без входа и мастер-ключа
+	recipient := newTestApp(t)
+	secret, err := recipient.OpenSecretLink(ctx, link.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "GitHub", secret.Title)
+	assert.Equal(t, map[string]string{"password": "s3cret"}, secret.Fields)
+	assert.Equal(t, []string{""}, srv.openAuth, "токен сессии не отправляется")
+
+	_, err = recipient.OpenSecretLink(ctx, link.URL)
+	assert.ErrorIs(t, err, ErrSecretLinkGone)
+
+	whole, err := app.CreateSecretLink(ctx, "GitHub", "", 0, 0)
+	require.NoError(t, err)
+	secret, err = recipient.OpenSecretLink(ctx, whole.URL)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"username": "alice", "password": "s3cret"}, secret.Fields)
+
+	_, err = app.CreateSecretLink(ctx, "GitHub", "pin", 0, 0)
+	assert.ErrorIs(t, err, ErrNoSecretField)
+
+	// Чужой ключ не расшифровывает секрет
+	other, err := app.CreateSecretLink(ctx, "GitHub", "password", time.Hour, 1)
+	require.NoError(t, err)
+	forged := strings.Split(other.URL, "#")[0] + "#" + strings.Split(link.URL, "#")[1]
+	_, err = recipient.OpenSecretLink(ctx, forged)
+	assert.Error(t, err)
+}
+
+func TestParseSecretLink(t *testing.T) {
+	key := strings.Repeat("A", 43)
+	tests := []struct {
+		url     string
+		origin  string
+		id      string
+		wantErr bool
+	}{
+		{url: "https://vault.example.com/s/abc#" + key, origin: "https://vault.example.com", id: "abc"},
+		{url: " http://localhost:8080/s/abc#" + key + " ", origin: "http://localhost:8080", id: "abc"},
+		{url: "https://vault.example.com/s/abc", wantErr: true},
+		{url: "https://vault.example.com/s/abc#short", wantErr: true},
+		{url: "https://vault.example.com/x/abc#" + key, wantErr: true},
+		{url: "https://vault.example.com/s/#" + key, wantErr: true},
+		{url: "ftp://vault.example.com/s/abc#" + key, wantErr: true},
+		{url: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			origin, id, k, err := ParseSecretLink(tt.url)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSecretLink)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.origin, origin)
+			assert.Equal(t, tt.id, id)
+			assert.Len(t, k, 32)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// internal/app/client/secretref.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/record"
+)
+
+// SecretRefScheme - префикс ссылки на секрет в значении переменной окружения:
+// gk://<запись>/<поле>. Запись задается ID или названием.
+const SecretRefScheme = "gk://"
+
+// otpCodeField - вычисляемое поле записи TOTP: текущий код вместо секрета
+const otpCodeField = "code"
+
+var (
+	// ErrInvalidSecretRef - значение не соответствует формату gk://<запись>/<поле>
+	ErrInvalidSecretRef = apperr.New(apperr.Invalid, "неверная ссылка на секрет")
+	// ErrAmbiguousRecord - название ссылки совпадает с несколькими записями
+	ErrAmbiguousRecord = apperr.New(apperr.Conflict, "несколько записей с таким названием, укажите ID")
+	// ErrNoSecretField - в данных записи нет поля из ссылки
+	ErrNoSecretField = apperr.New(apperr.NotFound, "в записи нет такого поля")
+)
+
+// SecretRef - ссылка на поле расшифрованной записи
+type SecretRef struct {
+	Record string // ID или название записи
+	Field  string // ключ в данных записи: password, content, private_key...
+}
+
+// IsSecretRef сообщает, является ли значение ссылкой на секрет
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefScheme)
+}
+
+// ParseSecretRef разбирает ссылку gk://<запись>/<поле>. Название записи может
+// содержать "/", полем считается последний сегмент; пробелы и другие символы
+// в названии можно экранировать как в URL (%20).
+func ParseSecretRef(value string) (SecretRef, error) {
+	if !IsSecretRef(value) {
+		return SecretRef{}, fmt.Errorf("%w %q: ожидается %s<запись>/<поле>", ErrInvalidSecretRef, value, SecretRefScheme)
+	}
+
+	rest := strings.TrimPrefix(value, SecretRefScheme)
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 || i == len(rest)-1 {
+		return SecretRef{}, fmt.Errorf("%w %q: ожидается %s<запись>/<поле>", ErrInvalidSecretRef, value, SecretRefScheme)
+	}
+
+	name, err := url.PathUnescape(rest[:i])
+	if err != nil {
+		return SecretRef{}, fmt.Errorf("%w %q: %v", ErrInvalidSecretRef, value, err)
+	}
+
+	return SecretRef{Record: name, Field: rest[i+1:]}, nil
+}
+
+// ResolveSecretRef возвращает значение поля расшифрованной записи.
+// Запись ищется только в локальном хранилище, чтобы запуск команды не зависел от сервера.
+func (a *App) ResolveSecretRef(ctx context.Context, ref SecretRef) (string, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return "", ErrMasterKeyLocked
+	}
+
+	rec, err := a.findSecretRecord(ctx, ref.Record)
+	if err != nil {
+		return "", err
+	}
+
+	if rec.Type == record.RecTypeOTP && ref.Field == otpCodeField {
+		code, _, err := a.GetOTPCode(ctx, rec.ID)
+		return code, err
+	}
+
+	var data map[string]interface{}
+	if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+		return "", fmt.Errorf("ошибка расшифровки записи %d: %w", rec.ID, err)
+	}
+
+	return secretField(data, ref)
+}
+
+// findSecretRecord находит запись по ID или точному названию
+func (a *App) findSecretRecord(ctx context.Context, name string) (*LocalRecord, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		rec, err := a.storage.GetRecord(id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d", ErrRecordNotFound, id)
+		}
+		return rec, nil
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{Search: name})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска записи: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var found []*LocalRecord
+	for _, rec := range records {
+		if rec.Preview != nil && rec.Preview.Title == name {
+			found = append(found, rec)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrRecordNotFound, name)
+	case 1:
+		return found[0], nil
+	}
+
+	ids := make([]string, len(found))
+	for i, rec := range found {
+		ids[i] = strconv.Itoa(rec.ID)
+	}
+	return nil, fmt.Errorf("%w: %q (ID: %s)", ErrAmbiguousRecord, name, strings.Join(ids, ", "))
+}
+
+// SecretFields возвращает все поля расшифрованной записи, которые можно
+// передать строкой; у записей TOTP добавляется поле code с текущим кодом
+func (a *App) SecretFields(ctx context.Context, name string) (map[string]string, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	rec, err := a.findSecretRecord(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+	if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки записи %d: %w", rec.ID, err)
+	}
+
+	fields := make(map[string]string, len(data)+1)
+	for k, v := range data {
+		if s, ok := stringValue(v); ok {
+			fields[k] = s
+		}
+	}
+	if rec.Type == record.RecTypeOTP {
+		code, _, err := a.GetOTPCode(ctx, rec.ID)
+		if err != nil {
+			return nil, err
+		}
+		fields[otpCodeField] = code
+	}
+
+	return fields, nil
+}
+
+// stringValue приводит значение поля к строке; вложенные объекты и списки не приводятся
+func stringValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// secretField возвращает строковое значение поля; при ошибке перечисляет доступные поля
+func secretField(data map[string]interface{}, ref SecretRef) (string, error) {
+	if s, ok := stringValue(data[ref.Field]); ok {
+		return s, nil
+	}
+
+	fields := make([]string, 0, len(data))
+	for k, v := range data {
+		if _, ok := v.(map[string]interface{}); !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return "", fmt.Errorf("%w: %q в записи %q (доступны: %s)", ErrNoSecretField, ref.Field, ref.Record, strings.Join(fields, ", "))
+}
+
+// ResolveEnv заменяет в окружении вида KEY=VALUE значения-ссылки gk://
+// на расшифрованные секреты. Возвращает новое окружение и имена замененных
+// переменных. Первая ошибка прерывает разрешение: команда не должна
+// запускаться с неполным набором секретов.
+func (a *App) ResolveEnv(ctx context.Context, env []string) ([]string, []string, error) {
+	resolved := make([]string, 0, len(env))
+	var names []string
+
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !IsSecretRef(value) {
+			resolved = append(resolved, kv)
+			continue
+		}
+
+		ref, err := ParseSecretRef(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+		secret, err := a.ResolveSecretRef(ctx, ref)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		resolved = append(resolved, key+"="+secret)
+		names = append(names, key)
+	}
+
+	return resolved, names, nil
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SecretRef
+		wantErr bool
+	}{
+		{value: "gk://12/password", want: SecretRef{Record: "12", Field: "password"}},
+		{value: "gk://GitHub/username", want: SecretRef{Record: "GitHub", Field: "username"}},
+		{value: "gk://prod/db/password", want: SecretRef{Record: "prod/db", Field: "password"}},
+		{value: "gk://My%20Bank/pin", want: SecretRef{Record: "My Bank", Field: "pin"}},
+		{value: "gk://GitHub", wantErr: true},
+		{value: "gk://GitHub/", wantErr: true},
+		{value: "gk:///password", wantErr: true},
+		{value: "https://example.com/x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, err := ParseSecretRef(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSecretRef)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ref)
+		})
+	}
+}
+
+func TestApp_ResolveEnv(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	ctx := context.Background()
+
+	saveRecord := func(title string, data interface{}) int {
+		encrypted, err := app.encryptRecordData(data)
+		require.NoError(t, err)
+		meta, err := json.Marshal(map[string]string{"title": title})
+		require.NoError(t, err)
+		rec := &LocalRecord{Type: record.RecTypeLogin, EncryptedData: encrypted, Meta: meta, LastModified: time.Now()}
+		require.NoError(t, app.storage.SaveRecord(rec))
+		return rec.ID
+	}
+
+	env := []string{"HOME=/home/alice", "DB_PASSWORD=gk://Postgres/password"}
+
+	// Без разблокированного ключа секреты не разрешаются
+	_, _, err := app.ResolveEnv(ctx, env)
+	require.ErrorIs(t, err, ErrMasterKeyLocked)
+
+	require.NoError(t, app.InitMasterKey("password123"))
+	id := saveRecord("Postgres", record.LoginData{Username: "app", Password: "s3cret"})
+
+	resolved, names, err := app.ResolveEnv(ctx, append(env, fmt.Sprintf("DB_USER=gk://%d/username", id)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HOME=/home/alice", "DB_PASSWORD=s3cret", "DB_USER=app"}, resolved)
+	assert.Equal(t, []string{"DB_PASSWORD", "DB_USER"}, names)
+
+	_, _, err = app.ResolveEnv(ctx, []string{"X=gk://Postgres/token"})
+	assert.ErrorIs(t, err, ErrNoSecretField)
+
+	_, _, err = app.ResolveEnv(ctx, []string{"X=gk://Redis/password"})
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	saveRecord("Postgres", record.LoginData{Username: "other", Password: "x"})
+	_, _, err = app.ResolveEnv(ctx, env)
+	assert.ErrorIs(t, err, ErrAmbiguousRecord)
+}
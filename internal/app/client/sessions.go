@@ -0,0 +1,70 @@
+// internal/app/client/sessions.go
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"gophkeeper/internal/domain/session"
+)
+
+// Sessions возвращает активные сессии учетной записи: устройство, IP-адрес,
+// User-Agent, время входа и последнего использования
+func (a *App) Sessions(ctx context.Context) ([]session.Session, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	sessions, err := a.httpClient.ListSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения сессий: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession завершает сессию по ID. Если это сессия этого клиента,
+// локальный токен удаляется так же, как при выходе; current сообщает об этом.
+func (a *App) RevokeSession(ctx context.Context, id int) (current bool, err error) {
+	sessions, err := a.Sessions(ctx)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	for _, s := range sessions {
+		if s.ID == id {
+			found, current = true, s.Current
+			break
+		}
+	}
+	if !found {
+		return false, fmt.Errorf("сессия %d не найдена. Список сессий: gophkeeper auth sessions", id)
+	}
+
+	if err := a.httpClient.RevokeSession(ctx, id); err != nil {
+		return false, fmt.Errorf("ошибка завершения сессии: %w", err)
+	}
+	if current {
+		if err := a.ClearToken(); err != nil {
+			return true, err
+		}
+	}
+
+	a.log.Info("Сессия завершена", "session_id", id, "current", current)
+	return current, nil
+}
+
+// RevokeOtherSessions завершает все сессии учетной записи, кроме текущей, и
+// возвращает их число
+func (a *App) RevokeOtherSessions(ctx context.Context) (int64, error) {
+	if !a.IsAuthenticated() {
+		return 0, ErrAuthRequired
+	}
+
+	revoked, err := a.httpClient.RevokeOtherSessions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка завершения сессий: %w", err)
+	}
+
+	a.log.Info("Остальные сессии завершены", "revoked", revoked)
+	return revoked, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_Sessions(t *testing.T) {
+	ctx := context.Background()
+	var revoked []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/account/sessions":
+			_ = json.NewEncoder(w).Encode([]session.Session{
+				{ID: 1, DeviceName: "laptop", UserAgent: "gophkeeper/1.0", CreatedAt: time.Now(), Current: true},
+				{ID: 2, DeviceFingerprint: "1a2b-3c4d-5e6f-7a8b", IPAddress: "192.0.2.7", CreatedAt: time.Now()},
+			})
+		case r.Method == http.MethodDelete:
+			revoked = append(revoked, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/account/sessions/revoke-others":
+			revoked = append(revoked, r.URL.Path)
+			_, _ = w.Write([]byte(`{"revoked":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	sessions, err := app.Sessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.True(t, sessions[0].Current)
+	assert.Equal(t, "192.0.2.7", sessions[1].IPAddress)
+
+	_, err = app.RevokeSession(ctx, 5)
+	assert.ErrorContains(t, err, "сессия 5 не найдена")
+	assert.Empty(t, revoked, "неизвестная сессия не отправляется на сервер")
+
+	current, err := app.RevokeSession(ctx, 2)
+	require.NoError(t, err)
+	assert.False(t, current)
+	assert.True(t, app.IsAuthenticated())
+
+	n, err := app.RevokeOtherSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	current, err = app.RevokeSession(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, current)
+	assert.False(t, app.IsAuthenticated(), "после завершения своей сессии токен удален")
+	assert.Equal(t, []string{
+		"/api/account/sessions/2",
+		"/api/account/sessions/revoke-others",
+		"/api/account/sessions/1",
+	}, revoked)
+
+	_, err = app.Sessions(ctx)
+	assert.ErrorIs(t, err, ErrAuthRequired)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// VaultCheck - результат проверки целостности локальных записей
+type VaultCheck struct {
+	Total int
+	Valid int
+	// Corrupt - локальные ID записей, которые не расшифровываются мастер-ключом
+	Corrupt []int
+}
+
+// RestoreMasterKeyFromServer загружает файл мастер-ключа учетной записи на
+// новом устройстве. Ключ в файле зашифрован мастер-паролем, после загрузки
+// его нужно разблокировать. Если файла на сервере нет, возвращает ErrKeyFileNotFound.
+func (a *App) RestoreMasterKeyFromServer(ctx context.Context) error {
+	if a.crypto.IsInitialized() {
+		return fmt.Errorf("мастер-ключ уже инициализирован")
+	}
+
+	file, err := a.httpClient.GetKeyFile(ctx)
+	if errors.Is(err, apperr.NotFound) {
+		return ErrKeyFileNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки файла мастер-ключа: %w", err)
+	}
+
+	if err := a.crypto.RestoreKeyFile(file.Data); err != nil {
+		return fmt.Errorf("ошибка восстановления мастер-ключа: %w", err)
+	}
+
+	if err := a.state.Update(func(s *AppState) {
+		s.Initialized = true
+		s.MasterKeyHash = file.KeyHash
+		s.KeyFileChecksum = keyFileChecksum(file.Data)
+		s.PublishedKeyHash = file.KeyHash
+	}); err != nil {
+		return fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+
+	return nil
+}
+
+// PublishKeyFile сохраняет файл мастер-ключа на сервере, если он изменился с
+// последней отправки: новый ключ, ротация, смена мастер-пароля или обновление формата.
+// Сервер не принимает файл другого ключа - записи учетной записи зашифрованы прежним.
+func (a *App) PublishKeyFile(ctx context.Context) error {
+	if !a.crypto.IsInitialized() || !a.IsAuthenticated() {
+		return nil
+	}
+
+	data, err := a.crypto.KeyFile()
+	if err != nil {
+		return err
+	}
+
+	checksum := keyFileChecksum(data)
+	if a.state.Snapshot().KeyFileChecksum == checksum {
+		return nil
+	}
+
+	// Файл старой версии ключа не должен заменить на сервере файл после
+	// ротации на другом устройстве: вместо отправки принимаем новый файл
+	if err := a.adoptServerKeyFile(ctx); err != nil {
+		return err
+	}
+
+	keyHash := a.crypto.HeaderKeyHash()
+	previous := a.state.Snapshot().PublishedKeyHash
+	if previous == keyHash {
+		previous = ""
+	}
+
+	if err := a.httpClient.PutKeyFile(ctx, data, keyHash, previous); err != nil {
+		if errors.Is(err, apperr.Conflict) {
+			return fmt.Errorf("на сервере сохранен файл другого мастер-ключа: записи этого устройства зашифрованы иначе, чем записи учетной записи")
+		}
+		return err
+	}
+
+	if err := a.state.Update(func(s *AppState) {
+		s.KeyFileChecksum = checksum
+		s.PublishedKeyHash = keyHash
+	}); err != nil {
+		return fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+
+	a.log.Info("Файл мастер-ключа сохранен на сервере")
+	return nil
+}
+
+// VerifyVault расшифровывает каждую локальную запись. Шифр аутентифицирован,
+// поэтому поврежденная или подмененная запись не расшифровывается.
+func (a *App) VerifyVault() (*VaultCheck, error) {
+	if !a.IsMasterKeyUnlocked() {
+		return nil, ErrMasterKeyLocked
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	check := &VaultCheck{}
+	for _, rec := range records {
+		if rec.EncryptedData == "" {
+			continue
+		}
+
+		check.Total++
+		var data map[string]any
+		if err := a.decryptRecordData(rec.EncryptedData, &data); err != nil {
+			a.log.Warn("Запись не расшифровывается", "id", rec.ID, "server_id", rec.ServerID, "error", err)
+			check.Corrupt = append(check.Corrupt, rec.ID)
+			continue
+		}
+		check.Valid++
+	}
+
+	return check, nil
+}
+
+func keyFileChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
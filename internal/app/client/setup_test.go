@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/keyfile"
+)
+
+// keyFileServer хранит файл мастер-ключа, как /api/account/key-file
+type keyFileServer struct {
+	mu   gosync.Mutex
+	file *keyfile.KeyFile
+	puts int
+}
+
+func (s *keyFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != "/api/account/key-file" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		if s.file == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": keyfile.ErrNotFound.Error()})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.file)
+	case http.MethodPut:
+		s.puts++
+		var req struct {
+			keyfile.KeyFile
+			PreviousKeyHash string `json:"previous_key_hash"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		file := req.KeyFile
+		if s.file != nil && s.file.KeyHash != file.KeyHash && s.file.KeyHash != req.PreviousKeyHash {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": keyfile.ErrKeyMismatch.Error()})
+			return
+		}
+		s.file = &file
+		_ = json.NewEncoder(w).Encode(s.file)
+	}
+}
+
+func newKeyFileTestApp(t *testing.T, url string) *App {
+	t.Helper()
+	app := newTestApp(t)
+	app.httpClient.baseURL = url
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.SaveToken("token"))
+	return app
+}
+
+func TestApp_KeyFileRestore(t *testing.T) {
+	ctx := context.Background()
+	srv := &keyFileServer{}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	fresh := newKeyFileTestApp(t, ts.URL)
+	assert.ErrorIs(t, fresh.RestoreMasterKeyFromServer(ctx), ErrKeyFileNotFound)
+	assert.False(t, fresh.IsInitialized())
+
+	original := newKeyFileTestApp(t, ts.URL)
+	require.NoError(t, original.InitMasterKey("master-password"))
+	require.NoError(t, original.PublishKeyFile(ctx))
+	require.NoError(t, original.PublishKeyFile(ctx))
+	assert.Equal(t, 1, srv.puts, "неизмененный файл не отправляется повторно")
+
+	encrypted, err := original.encryptRecordData(map[string]string{"password": "s3cret"})
+	require.NoError(t, err)
+
+	t.Run("new device", func(t *testing.T) {
+		device := newKeyFileTestApp(t, ts.URL)
+		require.NoError(t, device.RestoreMasterKeyFromServer(ctx))
+		assert.True(t, device.IsInitialized())
+		assert.Error(t, device.UnlockMasterKey("wrong-password"))
+		require.NoError(t, device.UnlockMasterKey("master-password"))
+
+		require.NoError(t, device.storage.SaveRecord(&LocalRecord{ID: 1, Type: "password", EncryptedData: encrypted}))
+		require.NoError(t, device.storage.SaveRecord(&LocalRecord{ID: 2, Type: "password", EncryptedData: encrypted[:len(encrypted)-8] + "AAAAAAA="}))
+
+		check, err := device.VerifyVault()
+		require.NoError(t, err)
+		assert.Equal(t, 2, check.Total)
+		assert.Equal(t, 1, check.Valid)
+		assert.Equal(t, []int{2}, check.Corrupt)
+
+		assert.Error(t, device.RestoreMasterKeyFromServer(ctx), "ключ уже восстановлен")
+	})
+
+	t.Run("another master key rejected", func(t *testing.T) {
+		other := newKeyFileTestApp(t, ts.URL)
+		require.NoError(t, other.InitMasterKey("other-password"))
+		assert.Error(t, other.PublishKeyFile(ctx))
+		assert.Empty(t, other.state.Snapshot().KeyFileChecksum)
+	})
+
+	t.Run("locked vault", func(t *testing.T) {
+		original.LockMasterKey()
+		_, err := original.VerifyVault()
+		assert.ErrorIs(t, err, ErrMasterKeyLocked)
+	})
+}
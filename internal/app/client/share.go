@@ -0,0 +1,91 @@
+// internal/app/client/share.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/viewer"
+)
+
+// Токены просмотра открывают записи выбранных папок только для чтения на
+// ограниченный срок: для аудитора или экстренного доступа. Сервер хранит
+// хэш токена, поэтому сам токен выводится один раз, при выпуске.
+
+// ViewerToken - токен просмотра с путями его папок
+type ViewerToken struct {
+	viewer.Token
+	// Folders - пути папок токена; удаленные папки не выводятся
+	Folders []string
+}
+
+// CreateViewerToken выпускает токен просмотра папок paths (вместе с
+// вложенными папками) на срок ttl; 0 - срок по умолчанию сервера
+func (a *App) CreateViewerToken(ctx context.Context, name string, paths []string, ttl time.Duration) (*viewer.Issued, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	folders, err := a.fetchFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(paths))
+	for _, path := range paths {
+		f, err := findFolder(folders, path)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, f.ID)
+	}
+
+	issued, err := a.httpClient.CreateViewerToken(ctx, name, ids, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выпуска токена просмотра: %w", err)
+	}
+	a.log.Info("Выпущен токен просмотра", "token_id", issued.ID, "folders", ids, "expires_at", issued.ExpiresAt)
+	return issued, nil
+}
+
+// ListViewerTokens возвращает токены просмотра, включая истекшие
+func (a *App) ListViewerTokens(ctx context.Context) ([]ViewerToken, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+
+	tokens, err := a.httpClient.ListViewerTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения токенов просмотра: %w", err)
+	}
+	folders, err := a.loadFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := folder.Paths(folders)
+
+	out := make([]ViewerToken, 0, len(tokens))
+	for _, t := range tokens {
+		token := ViewerToken{Token: t}
+		for _, id := range t.FolderIDs {
+			if path, ok := paths[id]; ok {
+				token.Folders = append(token.Folders, path)
+			}
+		}
+		out = append(out, token)
+	}
+	return out, nil
+}
+
+// RevokeViewerToken отзывает токен просмотра
+func (a *App) RevokeViewerToken(ctx context.Context, id int) error {
+	if !a.IsAuthenticated() {
+		return ErrAuthRequired
+	}
+
+	if err := a.httpClient.RevokeViewerToken(ctx, id); err != nil {
+		return fmt.Errorf("ошибка отзыва токена просмотра: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// internal/app/client/state.go
+package client
+
+import (
+	gosync "sync"
+)
+
+// appState - изменяемое состояние App. Его читают и меняют команды CLI, агент
+// и фоновые горутины (синхронизация, автоблокировка), поэтому доступ идет
+// только через методы под одним мьютексом.
+type appState struct {
+	mu             gosync.RWMutex
+	data           AppState
+	masterKeyReady bool
+	authenticated  bool
+
+	// save записывает состояние на диск; вызывается под mu, чтобы снимки
+	// попадали в файл в том же порядке, в котором менялось состояние
+	save func(AppState) error
+}
+
+func newAppState(data AppState, save func(AppState) error) *appState {
+	return &appState{data: data, save: save}
+}
+
+// Snapshot возвращает копию сохраняемого состояния
+func (s *appState) Snapshot() AppState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data
+}
+
+// Update изменяет состояние и сохраняет его на диск. При ошибке сохранения
+// изменения остаются в памяти.
+func (s *appState) Update(fn func(*AppState)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.data)
+	if s.save == nil {
+		return nil
+	}
+	return s.save(s.data)
+}
+
+// Modify изменяет состояние только в памяти
+func (s *appState) Modify(fn func(*AppState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.data)
+}
+
+// MasterKeyReady сообщает, разблокирован ли мастер-ключ для шифрования записей
+func (s *appState) MasterKeyReady() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.masterKeyReady
+}
+
+func (s *appState) SetMasterKeyReady(ready bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.masterKeyReady = ready
+}
+
+// Authenticated сообщает, выполнен ли вход на сервер
+func (s *appState) Authenticated() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.authenticated
+}
+
+func (s *appState) SetAuthenticated(authenticated bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authenticated = authenticated
+}
+
+// Reset сбрасывает состояние после удаления локальных данных
+func (s *appState) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = AppState{}
+	s.masterKeyReady = false
+	s.authenticated = false
+}
+
+// incRecordsCount учитывает новую запись; передается в Update
+func incRecordsCount(s *AppState) {
+	s.RecordsCount++
+}
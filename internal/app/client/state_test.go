@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/app/client/config"
+	"gophkeeper/internal/app/client/crypto"
+)
+
+// Тесты состояния App имеют смысл прежде всего с -race: CI запускает их
+// так же, как `go test -race ./...`.
+
+// newTestApp собирает App без сервера и хранилища ОС
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	dir := t.TempDir()
+	cfg := &config.Config{
+		ServerAddress: "localhost:0",
+		ConfigDir:     dir,
+		MasterKeyPath: filepath.Join(dir, "master.key"),
+		TokenPath:     filepath.Join(dir, "token"),
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	masterKey, err := crypto.NewMasterKeyManager(cfg.MasterKeyPath)
+	require.NoError(t, err)
+	require.NoError(t, masterKey.SetAlgorithms(crypto.KDFPBKDF2, crypto.AEADAESGCM))
+	t.Cleanup(masterKey.Lock)
+
+	httpCl, err := newHTTPClient(cfg, log)
+	require.NoError(t, err)
+
+	stateCipher := crypto.NewStateCipher(dir, nil)
+	app := &App{
+		config:         cfg,
+		log:            log,
+		crypto:         masterKey,
+		stateCipher:    stateCipher,
+		httpClient:     httpCl,
+		unlockThrottle: crypto.NewUnlockThrottle(filepath.Join(dir, unlockAttemptsFile), stateCipher),
+	}
+	app.state = newAppState(AppState{}, app.saveAppState)
+	app.ctx, app.stop = context.WithCancel(context.Background())
+	t.Cleanup(app.stop)
+	return app
+}
+
+func TestApp_LockMasterKey(t *testing.T) {
+	app := newTestApp(t)
+	require.NoError(t, app.InitMasterKey("password123"))
+	assert.True(t, app.IsInitialized())
+	assert.True(t, app.IsMasterKeyUnlocked())
+	assert.True(t, app.state.MasterKeyReady())
+
+	app.LockMasterKey()
+	assert.False(t, app.IsMasterKeyUnlocked())
+	assert.False(t, app.state.MasterKeyReady())
+
+	// Повторная блокировка не зависает
+	app.LockMasterKey()
+
+	require.NoError(t, app.UnlockMasterKey("password123"))
+	assert.True(t, app.IsMasterKeyUnlocked())
+	assert.True(t, app.state.MasterKeyReady())
+}
+
+func TestApp_AuthFlow(t *testing.T) {
+	app := newTestApp(t)
+	assert.False(t, app.IsAuthenticated())
+
+	require.NoError(t, app.loggedIn("alice", "token"))
+	assert.True(t, app.IsAuthenticated())
+	assert.Equal(t, "alice", app.state.Snapshot().UserLogin)
+
+	// Состояние сохраняется на диск и читается при следующем запуске
+	saved, err := loadAppState(app.config, app.stateCipher)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", saved.UserLogin)
+
+	require.NoError(t, app.ClearToken())
+	assert.False(t, app.IsAuthenticated())
+	assert.Empty(t, app.state.Snapshot().UserLogin)
+}
+
+func TestApp_ConcurrentStateAccess(t *testing.T) {
+	app := newTestApp(t)
+	require.NoError(t, app.InitMasterKey("password123"))
+
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			app.LockMasterKey()
+			_ = app.IsMasterKeyUnlocked()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = app.UnlockMasterKey("password123")
+		}()
+		go func() {
+			defer wg.Done()
+			_ = app.loggedIn("alice", "token")
+			_ = app.IsAuthenticated()
+			_ = app.ClearToken()
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, app.state.Update(incRecordsCount))
+			_ = app.IsInitialized()
+			_ = app.state.Snapshot()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, workers, app.state.Snapshot().RecordsCount)
+
+	saved, err := loadAppState(app.config, app.stateCipher)
+	require.NoError(t, err)
+	assert.Equal(t, app.state.Snapshot(), *saved)
+
+	app.LockMasterKey()
+	assert.False(t, app.state.MasterKeyReady())
+}
+
+func TestAppState_Update(t *testing.T) {
+	var saved []int
+	state := newAppState(AppState{}, func(s AppState) error {
+		saved = append(saved, s.RecordsCount)
+		if s.RecordsCount > 2 {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		err := state.Update(incRecordsCount)
+		if i < 2 {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+		}
+	}
+	// Снимки сохраняются по порядку, изменение остается в памяти и при ошибке записи
+	assert.Equal(t, []int{1, 2, 3}, saved)
+	assert.Equal(t, 3, state.Snapshot().RecordsCount)
+
+	state.Modify(func(s *AppState) { s.RecordsCount = 10 })
+	assert.Equal(t, []int{1, 2, 3}, saved)
+
+	state.SetAuthenticated(true)
+	state.SetMasterKeyReady(true)
+	state.Reset()
+	assert.Equal(t, AppState{}, state.Snapshot())
+	assert.False(t, state.Authenticated())
+	assert.False(t, state.MasterKeyReady())
+}
@@ -0,0 +1,26 @@
+// internal/app/client/stats.go
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"gophkeeper/internal/domain/stats"
+)
+
+// AccountStats возвращает статистику хранилища на сервере: записи по типам,
+// рост за последние days дней и активность устройств
+func (a *App) AccountStats(ctx context.Context, days int) (*stats.Stats, error) {
+	if !a.IsAuthenticated() {
+		return nil, ErrAuthRequired
+	}
+	if days < 1 || days > stats.MaxDays {
+		return nil, fmt.Errorf("период должен быть от 1 до %d дней", stats.MaxDays)
+	}
+
+	result, err := a.httpClient.GetAccountStats(ctx, days)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/stats"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_AccountStats(t *testing.T) {
+	ctx := context.Background()
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/account/stats" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.RawQuery
+		_ = json.NewEncoder(w).Encode(stats.Stats{
+			Records: record.StatsResponse{TotalRecords: 2, ActiveDays: 1},
+			Days:    7,
+			Growth:  []stats.Day{{Day: "2024-03-10", Created: 2, Records: 2}},
+		})
+	}))
+	t.Cleanup(ts.Close)
+
+	app := newKeyFileTestApp(t, ts.URL)
+	result, err := app.AccountStats(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "days=7", query)
+	assert.Equal(t, int64(2), result.Records.TotalRecords)
+	require.Len(t, result.Growth, 1)
+	assert.Equal(t, int64(2), result.Growth[0].Records)
+
+	_, err = app.AccountStats(ctx, stats.MaxDays+1)
+	assert.Error(t, err)
+
+	require.NoError(t, app.ClearToken())
+	_, err = app.AccountStats(ctx, 7)
+	assert.ErrorIs(t, err, ErrAuthRequired)
+}
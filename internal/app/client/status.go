@@ -0,0 +1,83 @@
+// internal/app/client/status.go
+package client
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/internal/domain/session"
+)
+
+// LocalStats - записи локальной базы для gophkeeper status
+type LocalStats struct {
+	// Total - записи вне корзины, ByType - они же по типам
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+	// Trashed - записи в корзине
+	Trashed int `json:"trashed"`
+	// Pending - изменения, еще не отправленные на сервер, включая удаления
+	Pending int `json:"pending"`
+}
+
+// LocalStats считает локальные записи по типам и неотправленные изменения.
+// Записи не расшифровываются, поэтому мастер-ключ не нужен, если локальная
+// база не зашифрована.
+func (a *App) LocalStats() (*LocalStats, error) {
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+
+	stats := &LocalStats{ByType: make(map[string]int)}
+	for _, rec := range records {
+		if !rec.Synced {
+			stats.Pending++
+		}
+		if rec.DeletedAt != nil {
+			stats.Trashed++
+			continue
+		}
+		stats.Total++
+		stats.ByType[string(rec.Type)]++
+	}
+	return stats, nil
+}
+
+// SessionInfo - вход на сервер на этом устройстве
+type SessionInfo struct {
+	Authenticated bool   `json:"authenticated"`
+	Login         string `json:"login,omitempty"`
+	// LoggedInAt и ExpiresAt - время входа и окончания сессии на сервере;
+	// нулевые, если вход не выполнен
+	LoggedInAt time.Time `json:"logged_in_at,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+}
+
+// Expired сообщает, что срок сессии истек и нужен повторный вход
+func (s *SessionInfo) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// Session возвращает сведения о входе. Сервер не сообщает срок токена,
+// поэтому он считается от времени входа; для входа, выполненного прежней
+// версией клиента, временем входа служит время записи файла токена.
+func (a *App) Session() *SessionInfo {
+	info := &SessionInfo{Authenticated: a.IsAuthenticated()}
+	if !info.Authenticated {
+		return info
+	}
+
+	state := a.state.Snapshot()
+	info.Login = state.UserLogin
+	info.LoggedInAt = state.LoggedInAt
+	if info.LoggedInAt.IsZero() {
+		if fi, err := os.Stat(a.config.TokenPath); err == nil {
+			info.LoggedInAt = fi.ModTime()
+		}
+	}
+	if !info.LoggedInAt.IsZero() {
+		info.ExpiresAt = info.LoggedInAt.Add(session.TTL)
+	}
+	return info
+}
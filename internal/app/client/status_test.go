@@ -0,0 +1,65 @@
+package client
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/session"
+)
+
+func TestApp_LocalStats(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+
+	deleted := time.Now()
+	for _, rec := range []*LocalRecord{
+		{Type: record.RecTypeLogin, Synced: true},
+		{Type: record.RecTypeLogin},
+		{Type: record.RecTypeCard, Synced: true},
+		{Type: record.RecTypeText, DeletedAt: &deleted},
+	} {
+		rec.EncryptedData = "encrypted"
+		require.NoError(t, app.storage.SaveRecord(rec))
+	}
+
+	stats, err := app.LocalStats()
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Total)
+	assert.Equal(t, map[string]int{string(record.RecTypeLogin): 2, string(record.RecTypeCard): 1}, stats.ByType)
+	assert.Equal(t, 1, stats.Trashed)
+	assert.Equal(t, 2, stats.Pending, "неотправленная запись и удаление")
+}
+
+func TestApp_Session(t *testing.T) {
+	app := newTestApp(t)
+	assert.False(t, app.Session().Authenticated)
+
+	require.NoError(t, app.loggedIn("alice", "token"))
+	info := app.Session()
+	require.True(t, info.Authenticated)
+	assert.Equal(t, "alice", info.Login)
+	assert.WithinDuration(t, time.Now(), info.LoggedInAt, time.Minute)
+	assert.Equal(t, info.LoggedInAt.Add(session.TTL), info.ExpiresAt)
+	assert.False(t, info.Expired(time.Now()))
+	assert.True(t, info.Expired(info.ExpiresAt))
+
+	t.Run("login by older client", func(t *testing.T) {
+		require.NoError(t, app.state.Update(func(s *AppState) { s.LoggedInAt = time.Time{} }))
+		written := time.Now().Add(-30 * time.Hour).Truncate(time.Second)
+		require.NoError(t, os.Chtimes(app.config.TokenPath, written, written))
+
+		info := app.Session()
+		assert.True(t, written.Equal(info.LoggedInAt))
+		assert.True(t, info.Expired(time.Now()))
+	})
+
+	require.NoError(t, app.ClearToken())
+	info = app.Session()
+	assert.False(t, info.Authenticated)
+	assert.True(t, info.ExpiresAt.IsZero())
+}
@@ -0,0 +1,554 @@
+// internal/app/client/storage_cipher.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	gosync "sync"
+	"time"
+
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Шифрование локальной базы (encrypt_local_db). Файл базы целиком шифруется
+// SQLCipher ключом, производным от мастер-ключа, поэтому база открывается
+// только после разблокировки: до нее хранилище возвращает ErrMasterKeyLocked.
+// SQLCipher подключается сборкой с -tags libsqlite3 против libsqlcipher;
+// обычная сборка открывает только открытые базы. Открытая база переводится
+// в зашифрованную и обратно командами config encrypt-db и config decrypt-db.
+
+// localDBKeyInfo - назначение ключа локальной базы при выводе из мастер-ключа
+const localDBKeyInfo = "gophkeeper local db v1"
+
+// sqliteHeader - начало файла открытой базы SQLite. Файл SQLCipher начинается
+// со случайной соли.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+var (
+	// ErrCipherUnsupported - клиент собран без SQLCipher
+	ErrCipherUnsupported = errors.New("клиент собран без SQLCipher: соберите его с -tags libsqlite3 и библиотекой libsqlcipher")
+	// ErrLocalDBPlain - encrypt_local_db включен, а база еще не зашифрована
+	ErrLocalDBPlain = errors.New("локальная база не зашифрована. Выполните: gophkeeper config encrypt-db")
+	// ErrLocalDBEncrypted - база зашифрована, а encrypt_local_db выключен
+	ErrLocalDBEncrypted = errors.New("локальная база зашифрована. Выполните: gophkeeper config set encrypt-local-db true")
+	// ErrLocalDBKey - ключ не подходит к зашифрованной базе
+	ErrLocalDBKey = errors.New("ключ не подходит к локальной базе")
+)
+
+// dbFormat - формат файла локальной базы
+type dbFormat int
+
+const (
+	dbFormatNone      dbFormat = iota // файла нет или он пуст
+	dbFormatPlain                     // открытая база SQLite
+	dbFormatEncrypted                 // база SQLCipher
+)
+
+// localDBFormat определяет формат файла базы по заголовку
+func localDBFormat(path string) (dbFormat, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return dbFormatNone, nil
+		}
+		return dbFormatNone, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(f, header)
+	switch {
+	case n == 0:
+		return dbFormatNone, nil
+	case err != nil && !errors.Is(err, io.ErrUnexpectedEOF):
+		return dbFormatNone, err
+	case bytes.Equal(header[:n], sqliteHeader):
+		return dbFormatPlain, nil
+	}
+	return dbFormatEncrypted, nil
+}
+
+// sqliteConnector открывает соединения драйвером с ConnectHook
+type sqliteConnector struct {
+	driver *sqlite3.SQLiteDriver
+	dsn    string
+}
+
+func (c sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sqlcipherKey - значение ключа SQLCipher в виде сырого ключа (без KDF)
+func sqlcipherKey(key []byte) string {
+	return fmt.Sprintf("x'%s'", hex.EncodeToString(key))
+}
+
+// openCipherSQLite открывает базу драйвером, проверяющим наличие SQLCipher.
+// Непустой ключ задается первой командой каждого соединения, до обращения
+// к страницам базы.
+func openCipherSQLite(path, key string) (*sql.DB, error) {
+	drv := &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if key != "" {
+				if _, err := conn.Exec(fmt.Sprintf(`PRAGMA key = "%s"`, key), nil); err != nil {
+					return err
+				}
+			}
+			if err := checkCipher(conn); err != nil {
+				return err
+			}
+			if key == "" {
+				return nil
+			}
+			// Первое чтение страниц базы: с чужим ключом оно не проходит
+			for _, stmt := range []string{`PRAGMA foreign_keys = ON`, `PRAGMA journal_mode = WAL`} {
+				if _, err := conn.Exec(stmt, nil); err != nil {
+					return fmt.Errorf("%w: %v", ErrLocalDBKey, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	db := sql.OpenDB(sqliteConnector{driver: drv, dsn: path})
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// checkCipher проверяет, что драйвер собран с SQLCipher. Обычный SQLite
+// молча пропускает неизвестные PRAGMA, поэтому без проверки база осталась бы
+// открытой.
+func checkCipher(conn *sqlite3.SQLiteConn) error {
+	rows, err := conn.Query(`PRAGMA cipher_version`, nil)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	dest := make([]driver.Value, 1)
+	if err := rows.Next(dest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrCipherUnsupported
+		}
+		return err
+	}
+	return nil
+}
+
+// NewEncryptedSQLiteStorage открывает или создает локальную базу,
+// зашифрованную SQLCipher ключом key
+func NewEncryptedSQLiteStorage(path string, key []byte) (*SQLiteStorage, error) {
+	format, err := localDBFormat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения базы данных: %w", err)
+	}
+	if format == dbFormatPlain {
+		return nil, ErrLocalDBPlain
+	}
+
+	createDBFile(path)
+
+	db, err := openCipherSQLite(path, sqlcipherKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы данных: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	return newSQLiteStorage(db, path)
+}
+
+// exportSQLite копирует базу соединения conn в файл dst средствами SQLCipher.
+// dstKey - ключ новой базы; пустой ключ создает открытую базу.
+func exportSQLite(ctx context.Context, conn *sql.Conn, dst, dstKey string) error {
+	var userVersion int
+	if err := conn.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&userVersion); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS export KEY ?`, dst, dstKey); err != nil {
+		return fmt.Errorf("ошибка создания новой базы: %w", err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE export`) //nolint:errcheck
+
+	if _, err := conn.ExecContext(ctx, `SELECT sqlcipher_export('export')`); err != nil {
+		return fmt.Errorf("ошибка копирования базы: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(`PRAGMA export.user_version = %d`, userVersion)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// convertSQLiteFile перезаписывает базу path копией, созданной exportSQLite.
+// Копия пишется во временный файл и заменяет базу только целиком.
+func convertSQLiteFile(db *sql.DB, path, dstKey string) (err error) {
+	ctx := context.Background()
+	tmp := path + ".convert"
+	_ = os.Remove(tmp)
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if err := exportSQLiteFile(ctx, db, tmp, dstKey); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(tmp, path)
+}
+
+// exportSQLiteFile сбрасывает журнал базы, копирует ее в dst и закрывает
+func exportSQLiteFile(ctx context.Context, db *sql.DB, dst, dstKey string) error {
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("ошибка сброса журнала: %w", err)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return exportSQLite(ctx, conn, dst, dstKey)
+}
+
+// encryptSQLiteFile шифрует открытую базу path ключом key
+func encryptSQLiteFile(path string, key []byte) error {
+	format, err := localDBFormat(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case dbFormatNone:
+		return nil
+	case dbFormatEncrypted:
+		return fmt.Errorf("локальная база уже зашифрована")
+	}
+
+	db, err := openCipherSQLite(path, "")
+	if err != nil {
+		return err
+	}
+	return convertSQLiteFile(db, path, sqlcipherKey(key))
+}
+
+// decryptSQLiteFile расшифровывает базу path, зашифрованную ключом key
+func decryptSQLiteFile(path string, key []byte) error {
+	format, err := localDBFormat(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case dbFormatNone:
+		return nil
+	case dbFormatPlain:
+		return fmt.Errorf("локальная база не зашифрована")
+	}
+
+	db, err := openCipherSQLite(path, sqlcipherKey(key))
+	if err != nil {
+		return err
+	}
+	return convertSQLiteFile(db, path, "")
+}
+
+// lockedStorage - зашифрованная локальная база, открываемая при первом
+// обращении после разблокировки мастер-ключа
+type lockedStorage struct {
+	path string
+	key  func() ([]byte, error)
+
+	mu     gosync.Mutex
+	opened *SQLiteStorage
+}
+
+var _ Storage = (*lockedStorage)(nil)
+
+func newLockedStorage(path string, key func() ([]byte, error)) *lockedStorage {
+	return &lockedStorage{path: path, key: key}
+}
+
+// open возвращает открытую базу, открывая ее при первом вызове
+func (s *lockedStorage) open() (*SQLiteStorage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opened != nil {
+		return s.opened, nil
+	}
+	key, err := s.key()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+
+	storage, err := NewEncryptedSQLiteStorage(s.path, key)
+	if err != nil {
+		return nil, err
+	}
+	s.opened = storage
+	return storage, nil
+}
+
+func (s *lockedStorage) SaveRecord(rec *LocalRecord) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.SaveRecord(rec)
+}
+
+func (s *lockedStorage) GetRecord(id int) (*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetRecord(id)
+}
+
+func (s *lockedStorage) GetRecordByServerID(serverID int) (*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetRecordByServerID(serverID)
+}
+
+func (s *lockedStorage) GetRecordByUUID(recordUUID string) (*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetRecordByUUID(recordUUID)
+}
+
+func (s *lockedStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.ListRecords(filter)
+}
+
+func (s *lockedStorage) UpdateRecord(rec *LocalRecord) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.UpdateRecord(rec)
+}
+
+func (s *lockedStorage) DeleteRecord(id int) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.DeleteRecord(id)
+}
+
+func (s *lockedStorage) HardDeleteRecord(id int) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.HardDeleteRecord(id)
+}
+
+func (s *lockedStorage) CountRecords() (int, error) {
+	db, err := s.open()
+	if err != nil {
+		return 0, err
+	}
+	return db.CountRecords()
+}
+
+func (s *lockedStorage) GetUnsyncedRecords() ([]*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetUnsyncedRecords()
+}
+
+func (s *lockedStorage) GetRecordsModifiedAfter(since time.Time, limit int, filter sync.Filter) ([]*LocalRecord, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.GetRecordsModifiedAfter(since, limit, filter)
+}
+
+func (s *lockedStorage) MarkAsSynced(id int, serverID int, syncVersion int64) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.MarkAsSynced(id, serverID, syncVersion)
+}
+
+func (s *lockedStorage) SaveAttachments(recordID int, attachments []*LocalAttachment) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.SaveAttachments(recordID, attachments)
+}
+
+func (s *lockedStorage) ListAttachments(recordID int) ([]*LocalAttachment, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.ListAttachments(recordID)
+}
+
+func (s *lockedStorage) SaveFolders(folders []folder.Folder) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	return db.SaveFolders(folders)
+}
+
+func (s *lockedStorage) ListFolders() ([]folder.Folder, error) {
+	db, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return db.ListFolders()
+}
+
+// Close закрывает базу, если она была открыта
+func (s *lockedStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.opened == nil {
+		return nil
+	}
+	err := s.opened.Close()
+	s.opened = nil
+	return err
+}
+
+// localDBKey выводит ключ локальной базы из мастер-ключа
+func localDBKey(masterKey interface {
+	IsLocked() bool
+	DeriveSubkey(info string) ([]byte, error)
+}) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if masterKey.IsLocked() {
+			return nil, ErrMasterKeyLocked
+		}
+		return masterKey.DeriveSubkey(localDBKeyInfo)
+	}
+}
+
+// openLocalStorage открывает локальную базу в режиме, заданном encrypt_local_db
+func openLocalStorage(path string, encrypted bool, key func() ([]byte, error)) (Storage, error) {
+	if encrypted {
+		return newLockedStorage(path, key), nil
+	}
+
+	format, err := localDBFormat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения базы данных: %w", err)
+	}
+	if format == dbFormatEncrypted {
+		return nil, ErrLocalDBEncrypted
+	}
+	return NewSQLiteStorage(path)
+}
+
+// sqliteStorage возвращает открытую SQLite-базу хранилища, если она есть
+func (a *App) sqliteStorage() (*SQLiteStorage, bool) {
+	switch s := a.storage.(type) {
+	case *SQLiteStorage:
+		return s, true
+	case *lockedStorage:
+		db, err := s.open()
+		return db, err == nil
+	}
+	return nil, false
+}
+
+// EncryptLocalDB шифрует локальную базу ключом, производным от мастер-ключа,
+// и переключает хранилище на зашифрованную базу
+func (a *App) EncryptLocalDB() error {
+	return a.convertLocalDB(true)
+}
+
+// DecryptLocalDB расшифровывает локальную базу и переключает хранилище на
+// открытую базу
+func (a *App) DecryptLocalDB() error {
+	return a.convertLocalDB(false)
+}
+
+func (a *App) convertLocalDB(encrypt bool) error {
+	if !a.IsMasterKeyUnlocked() {
+		return ErrMasterKeyLocked
+	}
+	keyFunc := localDBKey(a.crypto)
+	key, err := keyFunc()
+	if err != nil {
+		return fmt.Errorf("ошибка получения ключа локальной базы: %w", err)
+	}
+	defer clear(key)
+
+	if err := a.storage.Close(); err != nil {
+		a.log.Warn("Не удалось закрыть локальную базу", "error", err)
+	}
+
+	path := a.config.DataPath
+	if encrypt {
+		err = encryptSQLiteFile(path, key)
+	} else {
+		err = decryptSQLiteFile(path, key)
+	}
+
+	// Хранилище открывается заново и при ошибке: исходный файл не изменен
+	enabled := encrypt
+	if err != nil {
+		enabled = !encrypt
+	}
+	storage, openErr := openLocalStorage(path, enabled, keyFunc)
+	if openErr != nil {
+		a.log.Warn("Не удалось открыть локальную базу, используем память", "error", openErr)
+		storage = NewMemoryStorage()
+	}
+	a.storage = storage
+	if err != nil {
+		return err
+	}
+
+	a.config.EncryptLocalDB = encrypt
+	a.log.Info("Формат локальной базы изменен", "encrypted", encrypt)
+	return nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalDBFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	format, err := localDBFormat(filepath.Join(dir, "missing.db"))
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatNone, format)
+
+	empty := filepath.Join(dir, "empty.db")
+	require.NoError(t, os.WriteFile(empty, nil, 0600))
+	format, err = localDBFormat(empty)
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatNone, format)
+
+	plain := filepath.Join(dir, "plain.db")
+	storage, err := NewSQLiteStorage(plain)
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+	format, err = localDBFormat(plain)
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatPlain, format)
+
+	encrypted := filepath.Join(dir, "encrypted.db")
+	require.NoError(t, os.WriteFile(encrypted, bytes.Repeat([]byte{0xA5}, 4096), 0600))
+	format, err = localDBFormat(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatEncrypted, format)
+
+	_, err = openLocalStorage(encrypted, false, nil)
+	assert.ErrorIs(t, err, ErrLocalDBEncrypted)
+
+	_, err = NewEncryptedSQLiteStorage(plain, bytes.Repeat([]byte{1}, 32))
+	assert.ErrorIs(t, err, ErrLocalDBPlain)
+}
+
+func TestLockedStorage_RequiresUnlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.db")
+	storage := newLockedStorage(path, func() ([]byte, error) {
+		return nil, ErrMasterKeyLocked
+	})
+
+	_, err := storage.ListRecords(&RecordFilter{})
+	assert.ErrorIs(t, err, ErrMasterKeyLocked)
+	assert.ErrorIs(t, storage.SaveRecord(&LocalRecord{Type: record.RecTypeText}), ErrMasterKeyLocked)
+	_, err = storage.CountRecords()
+	assert.ErrorIs(t, err, ErrMasterKeyLocked)
+	assert.NoError(t, storage.Close())
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "до разблокировки файл базы не создается")
+}
+
+func TestEncryptSQLiteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.db")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	plain, err := NewSQLiteStorage(path)
+	require.NoError(t, err)
+	rec := &LocalRecord{Type: record.RecTypeText, EncryptedData: "00", Version: 1, LastModified: time.Now()}
+	require.NoError(t, plain.SaveRecord(rec))
+	require.NoError(t, plain.Close())
+
+	err = encryptSQLiteFile(path, key)
+	if err != nil {
+		require.ErrorIs(t, err, ErrCipherUnsupported)
+
+		// Без SQLCipher база остается открытой и читается как прежде
+		format, err := localDBFormat(path)
+		require.NoError(t, err)
+		assert.Equal(t, dbFormatPlain, format)
+
+		reopened, err := NewSQLiteStorage(path)
+		require.NoError(t, err)
+		defer reopened.Close()
+		_, err = reopened.GetRecordByUUID(rec.UUID)
+		require.NoError(t, err)
+
+		_, err = NewEncryptedSQLiteStorage(filepath.Join(t.TempDir(), "new.db"), key)
+		assert.ErrorIs(t, err, ErrCipherUnsupported)
+		t.Skip("клиент собран без SQLCipher")
+	}
+
+	format, err := localDBFormat(path)
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatEncrypted, format)
+
+	_, err = NewEncryptedSQLiteStorage(path, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorIs(t, err, ErrLocalDBKey)
+
+	encrypted, err := NewEncryptedSQLiteStorage(path, key)
+	require.NoError(t, err)
+	got, err := encrypted.GetRecordByUUID(rec.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, rec.ID, got.ID)
+	require.NoError(t, encrypted.Close())
+
+	require.NoError(t, decryptSQLiteFile(path, key))
+	format, err = localDBFormat(path)
+	require.NoError(t, err)
+	assert.Equal(t, dbFormatPlain, format)
+}
@@ -0,0 +1,292 @@
+package client
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"github.com/google/uuid"
+)
+
+// sqliteMigration - версионированное изменение схемы локальной базы.
+// Примененные версии хранятся в таблице schema_version.
+type sqliteMigration struct {
+	version int
+	name    string
+	// destructive - миграция удаляет или перестраивает данные; перед ней
+	// делается резервная копия файла базы
+	destructive bool
+	up          func(tx *sql.Tx) error
+}
+
+// sqliteMigrations - миграции локальной базы по возрастанию версий. Новые
+// миграции только добавляются в конец, уже выпущенные не меняются.
+var sqliteMigrations = []sqliteMigration{
+	{version: 1, name: "create records", up: migrateCreateRecords},
+	{version: 2, name: "records preview", up: migrateRecordsPreview},
+	{version: 3, name: "record attachments", up: migrateRecordAttachments},
+	{version: 4, name: "folders", up: migrateFolders},
+	{version: 5, name: "record uuid", up: migrateRecordUUID},
+	{version: 6, name: "server record uuid", up: migrateServerRecordUUID},
+}
+
+// ErrSchemaTooNew - база создана более новой версией клиента
+var ErrSchemaTooNew = errors.New("локальная база создана более новой версией клиента. Обновите gophkeeper")
+
+// sqliteMigrationReport - итог приведения схемы локальной базы к текущей версии
+type sqliteMigrationReport struct {
+	From, To int
+	// Backup - резервная копия базы перед разрушающей миграцией; пусто - копии нет
+	Backup string
+}
+
+// Migrated сообщает, что при открытии была обновлена существующая база
+func (r sqliteMigrationReport) Migrated() bool {
+	return r.From > 0 && r.To > r.From
+}
+
+// migrateSQLite применяет к базе неприменённые миграции. Каждая миграция
+// выполняется в своей транзакции вместе с записью в schema_version.
+func migrateSQLite(db *sql.DB, path string, migrations []sqliteMigration) error {
+	_, err := runSQLiteMigrations(db, path, migrations)
+	return err
+}
+
+// runSQLiteMigrations применяет миграции и сообщает, с какой версии схемы на
+// какую обновлена база и где лежит резервная копия
+func runSQLiteMigrations(db *sql.DB, path string, migrations []sqliteMigration) (sqliteMigrationReport, error) {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return sqliteMigrationReport{}, fmt.Errorf("ошибка создания schema_version: %w", err)
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return sqliteMigrationReport{}, err
+	}
+	report := sqliteMigrationReport{From: current, To: current}
+	if n := len(migrations); n > 0 && current > migrations[n-1].version {
+		return report, fmt.Errorf("%w (версия схемы %d, поддерживается %d)", ErrSchemaTooNew, current, migrations[n-1].version)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if m.destructive {
+			backup, err := backupSQLite(db, path, current)
+			if err != nil {
+				return report, fmt.Errorf("ошибка резервного копирования перед миграцией %d: %w", m.version, err)
+			}
+			if backup != "" {
+				report.Backup = backup
+			}
+		}
+		if err := applySQLiteMigration(db, m); err != nil {
+			return report, fmt.Errorf("ошибка миграции %d (%s): %w", m.version, m.name, err)
+		}
+		current = m.version
+		report.To = current
+	}
+	return report, nil
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("ошибка чтения версии схемы: %w", err)
+	}
+	return version, nil
+}
+
+func applySQLiteMigration(db *sql.DB, m sqliteMigration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now().UTC(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backupSQLite сохраняет копию базы рядом с ней: <path>.v<версия>.bak.
+// VACUUM INTO пишет согласованный снимок с учетом WAL.
+// Возвращает путь копии; для базы в памяти копия не делается.
+func backupSQLite(db *sql.DB, path string, version int) (string, error) {
+	if path == "" || path == ":memory:" {
+		return "", nil
+	}
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if _, err := db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return "", err
+	}
+	return backupPath, os.Chmod(backupPath, 0600)
+}
+
+func migrateCreateRecords(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS records (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			server_id INTEGER DEFAULT 0,
+			user_id INTEGER DEFAULT 0,
+			type TEXT NOT NULL,
+			encrypted_data TEXT,
+			meta TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			last_modified DATETIME NOT NULL,
+			deleted_at DATETIME,
+			checksum TEXT,
+			device_id TEXT,
+			synced BOOLEAN NOT NULL DEFAULT 0,
+			sync_version INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_records_type ON records(type);
+		CREATE INDEX IF NOT EXISTS idx_records_deleted ON records(deleted_at);
+		CREATE INDEX IF NOT EXISTS idx_records_synced ON records(synced);
+		CREATE INDEX IF NOT EXISTS idx_records_server_id ON records(server_id);
+		CREATE INDEX IF NOT EXISTS idx_records_last_modified ON records(last_modified);
+	`)
+	return err
+}
+
+// migrateRecordsPreview добавляет колонку превью и заполняет превью для
+// записей, сохраненных без него. Базы, созданные до появления schema_version,
+// могут уже содержать колонку, поэтому ее наличие проверяется.
+func migrateRecordsPreview(tx *sql.Tx) error {
+	var exists int
+	if err := tx.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('records') WHERE name = 'preview'`,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("ошибка проверки схемы: %w", err)
+	}
+	if exists == 0 {
+		if _, err := tx.Exec(`ALTER TABLE records ADD COLUMN preview TEXT`); err != nil {
+			return fmt.Errorf("ошибка добавления колонки preview: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(`SELECT id, type, meta FROM records WHERE preview IS NULL`)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения записей без превью: %w", err)
+	}
+
+	previews := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var recType record.RecType
+		var meta sql.NullString
+		if err := rows.Scan(&id, &recType, &meta); err != nil {
+			rows.Close()
+			return fmt.Errorf("ошибка сканирования записи: %w", err)
+		}
+		previews[id] = encodePreview(buildRecordPreview(recType, []byte(meta.String)))
+	}
+	rows.Close()
+
+	for id, preview := range previews {
+		if _, err := tx.Exec(`UPDATE records SET preview = ? WHERE id = ?`, preview, id); err != nil {
+			return fmt.Errorf("ошибка сохранения превью: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateRecordAttachments создает кэш списков вложений записей. Содержимое
+// вложений не кэшируется: оно загружается с сервера при скачивании.
+func migrateRecordAttachments(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS attachments (
+			id INTEGER PRIMARY KEY,
+			record_id INTEGER NOT NULL REFERENCES records(id) ON DELETE CASCADE,
+			encrypted_meta TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_attachments_record ON attachments(record_id);
+	`)
+	return err
+}
+
+// migrateFolders создает кэш папок, чтобы пути папок были доступны без
+// связи с сервером
+func migrateFolders(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS folders (
+			id INTEGER PRIMARY KEY,
+			parent_id INTEGER,
+			name TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`)
+	return err
+}
+
+// migrateRecordUUID добавляет записям постоянный UUID. Записи, уже
+// сохраненные на сервере, получат серверный UUID при следующей
+// синхронизации: клиент сопоставит их по server_id.
+func migrateRecordUUID(tx *sql.Tx) error {
+	if _, err := tx.Exec(`ALTER TABLE records ADD COLUMN uuid TEXT`); err != nil {
+		return fmt.Errorf("ошибка добавления колонки uuid: %w", err)
+	}
+
+	rows, err := tx.Query(`SELECT id FROM records`)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения записей: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("ошибка сканирования записи: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		if _, err := tx.Exec(`UPDATE records SET uuid = ? WHERE id = ?`, uuid.NewString(), id); err != nil {
+			return fmt.Errorf("ошибка сохранения uuid: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_records_uuid ON records(uuid)`)
+	return err
+}
+
+// migrateServerRecordUUID сбрасывает UUID, выданные миграцией 5 записям, которые
+// уже были на сервере: сервер назначил им свои UUID, и локальные с ними не
+// совпадают. Настоящий UUID клиент получает при следующей синхронизации, до
+// этого запись адресуется по server_id.
+func migrateServerRecordUUID(tx *sql.Tx) error {
+	if _, err := tx.Exec(`UPDATE records SET uuid = NULL WHERE server_id > 0`); err != nil {
+		return fmt.Errorf("ошибка сброса uuid: %w", err)
+	}
+	return nil
+}
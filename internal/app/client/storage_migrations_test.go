@@ -0,0 +1,183 @@
+package client
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/folder"
+)
+
+func openTestDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestNewSQLiteStorage_MigratesLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+
+	// База старого клиента: таблица без колонки preview и без schema_version
+	legacy := openTestDB(t, path)
+	tx, err := legacy.Begin()
+	require.NoError(t, err)
+	require.NoError(t, migrateCreateRecords(tx))
+	require.NoError(t, tx.Commit())
+	_, err = legacy.Exec(`
+		INSERT INTO records (type, meta, last_modified, created_at)
+		VALUES ('credentials', '{"login":"alice"}', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`)
+	require.NoError(t, err)
+	require.NoError(t, legacy.Close())
+
+	storage, err := NewSQLiteStorage(path)
+	require.NoError(t, err)
+	defer storage.Close()
+
+	version, err := schemaVersion(storage.GetDB())
+	require.NoError(t, err)
+	assert.Equal(t, sqliteMigrations[len(sqliteMigrations)-1].version, version)
+
+	var preview sql.NullString
+	require.NoError(t, storage.GetDB().QueryRow(`SELECT preview FROM records`).Scan(&preview))
+	assert.True(t, preview.Valid)
+
+	// Записи прежних версий получают UUID
+	var recordUUID string
+	require.NoError(t, storage.GetDB().QueryRow(`SELECT uuid FROM records`).Scan(&recordUUID))
+	_, err = uuid.Parse(recordUUID)
+	assert.NoError(t, err)
+
+	// Повторное открытие ничего не применяет
+	require.NoError(t, storage.Close())
+	storage, err = NewSQLiteStorage(path)
+	require.NoError(t, err)
+	defer storage.Close()
+	var applied int
+	require.NoError(t, storage.GetDB().QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied))
+	assert.Equal(t, len(sqliteMigrations), applied)
+}
+
+func TestMigrateSQLite_BackupBeforeDestructive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	db := openTestDB(t, path)
+	require.NoError(t, migrateSQLite(db, path, sqliteMigrations))
+	_, err := db.Exec(`INSERT INTO records (type, last_modified, created_at) VALUES ('text', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+
+	drop := sqliteMigration{
+		version:     len(sqliteMigrations) + 1,
+		name:        "drop records",
+		destructive: true,
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DELETE FROM records`)
+			return err
+		},
+	}
+	report, err := runSQLiteMigrations(db, path, append(sqliteMigrations[:len(sqliteMigrations):len(sqliteMigrations)], drop))
+	require.NoError(t, err)
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, sqliteMigrations[len(sqliteMigrations)-1].version)
+	assert.Equal(t, sqliteMigrationReport{From: drop.version - 1, To: drop.version, Backup: backupPath}, report)
+	assert.True(t, report.Migrated())
+	info, err := os.Stat(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	var count int
+	require.NoError(t, openTestDB(t, backupPath).QueryRow(`SELECT COUNT(*) FROM records`).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestMigrateSQLite_FailedMigrationRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	db := openTestDB(t, path)
+
+	broken := sqliteMigration{
+		version: 1,
+		name:    "broken",
+		up: func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`CREATE TABLE t (id INTEGER)`); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO missing VALUES (1)`)
+			return err
+		},
+	}
+	require.Error(t, migrateSQLite(db, path, []sqliteMigration{broken}))
+
+	version, err := schemaVersion(db)
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+	var tables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 't'`).Scan(&tables))
+	assert.Zero(t, tables)
+}
+
+func TestMigrateSQLite_RejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	db := openTestDB(t, path)
+	require.NoError(t, migrateSQLite(db, path, sqliteMigrations))
+
+	err := migrateSQLite(db, path, sqliteMigrations[:1])
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+}
+
+func TestSQLiteStorage_Attachments(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "data.db"))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	rec := &LocalRecord{Type: "text", LastModified: time.Now(), CreatedAt: time.Now()}
+	require.NoError(t, storage.SaveRecord(rec))
+
+	require.NoError(t, storage.SaveAttachments(rec.ID, []*LocalAttachment{
+		{ID: 5, EncryptedMeta: "a", Size: 1, CreatedAt: time.Now()},
+		{ID: 6, EncryptedMeta: "b", Size: 2, CreatedAt: time.Now()},
+	}))
+	// Новый список заменяет прежний
+	require.NoError(t, storage.SaveAttachments(rec.ID, []*LocalAttachment{{ID: 6, EncryptedMeta: "b", Size: 2, CreatedAt: time.Now()}}))
+
+	attachments, err := storage.ListAttachments(rec.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, 6, attachments[0].ID)
+	assert.Equal(t, rec.ID, attachments[0].RecordID)
+
+	// Окончательное удаление записи удаляет и ее вложения
+	require.NoError(t, storage.HardDeleteRecord(rec.ID))
+	attachments, err = storage.ListAttachments(rec.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+}
+
+func TestSQLiteStorage_Folders(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "data.db"))
+	require.NoError(t, err)
+	defer storage.Close()
+
+	parent := 1
+	require.NoError(t, storage.SaveFolders([]folder.Folder{{ID: 1, Name: "Old", CreatedAt: time.Now(), UpdatedAt: time.Now()}}))
+	// Новый список заменяет прежний
+	require.NoError(t, storage.SaveFolders([]folder.Folder{
+		{ID: 1, Name: "Work", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: 2, ParentID: &parent, Name: "Cloud", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}))
+
+	folders, err := storage.ListFolders()
+	require.NoError(t, err)
+	require.Len(t, folders, 2)
+	assert.Nil(t, folders[0].ParentID)
+	require.NotNil(t, folders[1].ParentID)
+	assert.Equal(t, 1, *folders[1].ParentID)
+	assert.Equal(t, "Work/Cloud", folder.Paths(folders)[2])
+}
@@ -4,62 +4,102 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"gophkeeper/internal/domain/folder"
 	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
 
+	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3" //nolint
 )
 
 type SQLiteStorage struct {
 	db *sql.DB
+	// migration - как обновлялась схема при открытии базы
+	migration sqliteMigrationReport
 }
 
 func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	createDBFile(path)
+
 	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_journal_mode=WAL")
 	if err != nil {
 		return nil, fmt.Errorf("ошибка открытия базы данных: %w", err)
 	}
 
+	return newSQLiteStorage(db, path)
+}
+
+// createDBFile создает файл базы с правами 0600, если его нет. SQLite создает
+// файл с правами по umask; файлы -wal и -shm получают права основного файла.
+func createDBFile(path string) {
+	if f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600); err == nil {
+		f.Close()
+	}
+}
+
+// newSQLiteStorage приводит схему открытой базы к текущей версии.
+// При ошибке база закрывается.
+func newSQLiteStorage(db *sql.DB, path string) (*SQLiteStorage, error) {
 	storage := &SQLiteStorage{db: db}
 
-	// Создаем таблицы
-	if err := storage.initTables(); err != nil {
+	var err error
+	storage.migration, err = runSQLiteMigrations(db, path, sqliteMigrations)
+	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("ошибка инициализации таблиц: %w", err)
+		return nil, fmt.Errorf("ошибка миграции базы данных: %w", err)
 	}
 
 	return storage, nil
 }
 
-func (s *SQLiteStorage) initTables() error {
-	// Таблица записей
-	_, err := s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS records (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			server_id INTEGER DEFAULT 0,
-			user_id INTEGER DEFAULT 0,
-			type TEXT NOT NULL,
-			encrypted_data TEXT,
-			meta TEXT,
-			version INTEGER NOT NULL DEFAULT 1,
-			last_modified DATETIME NOT NULL,
-			deleted_at DATETIME,
-			checksum TEXT,
-			device_id TEXT,
-			synced BOOLEAN NOT NULL DEFAULT 0,
-			sync_version INTEGER NOT NULL DEFAULT 0,
-			created_at DATETIME NOT NULL
-		);
-		
-		CREATE INDEX IF NOT EXISTS idx_records_type ON records(type);
-		CREATE INDEX IF NOT EXISTS idx_records_deleted ON records(deleted_at);
-		CREATE INDEX IF NOT EXISTS idx_records_synced ON records(synced);
-		CREATE INDEX IF NOT EXISTS idx_records_server_id ON records(server_id);
-		CREATE INDEX IF NOT EXISTS idx_records_last_modified ON records(last_modified);
-	`)
+// recordPreview вычисляет превью сохраняемой записи. Маскированный номер карты
+// берется из записи, а если его там нет - из ранее сохраненного превью,
+// чтобы обновление метаданных (например, при синхронизации) его не стирало.
+func (s *SQLiteStorage) recordPreview(rec *LocalRecord) *RecordPreview {
+	preview := buildRecordPreview(rec.Type, rec.Meta)
+	if rec.Type != record.RecTypeCard {
+		return preview
+	}
+
+	if rec.Preview != nil && rec.Preview.Masked != "" {
+		preview.Masked = rec.Preview.Masked
+		return preview
+	}
 
-	return err
+	var stored sql.NullString
+	err := s.db.QueryRow(`
+		SELECT preview FROM records
+		WHERE (id = ? OR uuid = NULLIF(?, '')) AND type = ?
+		ORDER BY id = ? DESC LIMIT 1
+	`, rec.ID, rec.UUID, rec.Type, rec.ID).Scan(&stored)
+	if err == nil {
+		if old := decodePreview(stored); old != nil {
+			preview.Masked = old.Masked
+		}
+	}
+
+	return preview
+}
+
+func encodePreview(p *RecordPreview) string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+func decodePreview(s sql.NullString) *RecordPreview {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	var p RecordPreview
+	if err := json.Unmarshal([]byte(s.String), &p); err != nil {
+		return nil
+	}
+	return &p
 }
 
 func (s *SQLiteStorage) SaveRecord(rec *LocalRecord) error {
@@ -73,16 +113,25 @@ func (s *SQLiteStorage) SaveRecord(rec *LocalRecord) error {
 		deletedAt = sql.NullTime{Time: *rec.DeletedAt, Valid: true}
 	}
 
+	preview := s.recordPreview(rec)
+	previewJSON := encodePreview(preview)
+
+	// UUID новой записи назначает клиент. Запись с сервера без UUID получит
+	// его при синхронизации - выдуманный локально с серверным не совпадет
+	if rec.UUID == "" && rec.ServerID == 0 {
+		rec.UUID = uuid.NewString()
+	}
+
 	if rec.ID == 0 {
 		// Вставляем новую запись
 		result, err := s.db.Exec(`
-			INSERT INTO records (server_id, user_id, type, encrypted_data, meta, version, 
+			INSERT INTO records (server_id, uuid, user_id, type, encrypted_data, meta, version, 
 			                     last_modified, deleted_at, checksum, device_id, synced, 
-			                     sync_version, created_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`, rec.ServerID, rec.UserID, rec.Type, rec.EncryptedData, metaJSON, rec.Version,
+			                     sync_version, created_at, preview)
+			VALUES (?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, rec.ServerID, rec.UUID, rec.UserID, rec.Type, rec.EncryptedData, metaJSON, rec.Version,
 			rec.LastModified, deletedAt, rec.Checksum, rec.DeviceID, rec.Synced,
-			rec.SyncVersion, rec.CreatedAt)
+			rec.SyncVersion, rec.CreatedAt, previewJSON)
 		if err != nil {
 			return fmt.Errorf("ошибка вставки записи: %w", err)
 		}
@@ -96,18 +145,19 @@ func (s *SQLiteStorage) SaveRecord(rec *LocalRecord) error {
 		// Обновляем существующую запись
 		_, err := s.db.Exec(`
 			UPDATE records 
-			SET server_id = ?, user_id = ?, type = ?, encrypted_data = ?, meta = ?, 
+			SET server_id = ?, uuid = NULLIF(?, ''), user_id = ?, type = ?, encrypted_data = ?, meta = ?, 
 			    version = ?, last_modified = ?, deleted_at = ?, checksum = ?, 
-			    device_id = ?, synced = ?, sync_version = ?
+			    device_id = ?, synced = ?, sync_version = ?, preview = ?
 			WHERE id = ?
-		`, rec.ServerID, rec.UserID, rec.Type, rec.EncryptedData, metaJSON, rec.Version,
+		`, rec.ServerID, rec.UUID, rec.UserID, rec.Type, rec.EncryptedData, metaJSON, rec.Version,
 			rec.LastModified, deletedAt, rec.Checksum, rec.DeviceID, rec.Synced,
-			rec.SyncVersion, rec.ID)
+			rec.SyncVersion, previewJSON, rec.ID)
 		if err != nil {
 			return fmt.Errorf("ошибка обновления записи: %w", err)
 		}
 	}
 
+	rec.Preview = preview
 	return nil
 }
 
@@ -115,25 +165,27 @@ func (s *SQLiteStorage) GetRecord(id int) (*LocalRecord, error) {
 	var rec LocalRecord
 	var metaJSON string
 	var deletedAt sql.NullTime
+	var preview sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, server_id, user_id, type, encrypted_data, meta, version, 
+		SELECT id, server_id, COALESCE(uuid, ''), user_id, type, encrypted_data, meta, version, 
 		       last_modified, deleted_at, checksum, device_id, synced, 
-		       sync_version, created_at
+		       sync_version, created_at, preview
 		FROM records 
 		WHERE id = ?
-	`, id).Scan(&rec.ID, &rec.ServerID, &rec.UserID, &rec.Type, &rec.EncryptedData,
+	`, id).Scan(&rec.ID, &rec.ServerID, &rec.UUID, &rec.UserID, &rec.Type, &rec.EncryptedData,
 		&metaJSON, &rec.Version, &rec.LastModified, &deletedAt, &rec.Checksum,
-		&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt)
+		&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt, &preview)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("запись не найдена: %d", id)
+		return nil, fmt.Errorf("%w: %d", ErrRecordNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения записи: %w", err)
 	}
 
 	rec.Meta = json.RawMessage(metaJSON)
+	rec.Preview = decodePreview(preview)
 	if deletedAt.Valid {
 		rec.DeletedAt = &deletedAt.Time
 	}
@@ -145,25 +197,59 @@ func (s *SQLiteStorage) GetRecordByServerID(serverID int) (*LocalRecord, error)
 	var rec LocalRecord
 	var metaJSON string
 	var deletedAt sql.NullTime
+	var preview sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, server_id, user_id, type, encrypted_data, meta, version, 
+		SELECT id, server_id, COALESCE(uuid, ''), user_id, type, encrypted_data, meta, version, 
 		       last_modified, deleted_at, checksum, device_id, synced, 
-		       sync_version, created_at
+		       sync_version, created_at, preview
 		FROM records 
 		WHERE server_id = ?
-	`, serverID).Scan(&rec.ID, &rec.ServerID, &rec.UserID, &rec.Type, &rec.EncryptedData,
+	`, serverID).Scan(&rec.ID, &rec.ServerID, &rec.UUID, &rec.UserID, &rec.Type, &rec.EncryptedData,
+		&metaJSON, &rec.Version, &rec.LastModified, &deletedAt, &rec.Checksum,
+		&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt, &preview)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w по server_id: %d", ErrRecordNotFound, serverID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записи: %w", err)
+	}
+
+	rec.Meta = json.RawMessage(metaJSON)
+	rec.Preview = decodePreview(preview)
+	if deletedAt.Valid {
+		rec.DeletedAt = &deletedAt.Time
+	}
+
+	return &rec, nil
+}
+
+func (s *SQLiteStorage) GetRecordByUUID(recordUUID string) (*LocalRecord, error) {
+	var rec LocalRecord
+	var metaJSON string
+	var deletedAt sql.NullTime
+	var preview sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT id, server_id, COALESCE(uuid, ''), user_id, type, encrypted_data, meta, version, 
+		       last_modified, deleted_at, checksum, device_id, synced, 
+		       sync_version, created_at, preview
+		FROM records 
+		WHERE uuid = ?
+	`, recordUUID).Scan(&rec.ID, &rec.ServerID, &rec.UUID, &rec.UserID, &rec.Type, &rec.EncryptedData,
 		&metaJSON, &rec.Version, &rec.LastModified, &deletedAt, &rec.Checksum,
-		&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt)
+		&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt, &preview)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("запись не найдена по server_id: %d", serverID)
+		return nil, fmt.Errorf("%w по uuid: %s", ErrRecordNotFound, recordUUID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения записи: %w", err)
 	}
 
 	rec.Meta = json.RawMessage(metaJSON)
+	rec.Preview = decodePreview(preview)
 	if deletedAt.Valid {
 		rec.DeletedAt = &deletedAt.Time
 	}
@@ -172,9 +258,9 @@ func (s *SQLiteStorage) GetRecordByServerID(serverID int) (*LocalRecord, error)
 }
 
 func (s *SQLiteStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error) {
-	query := `SELECT id, server_id, user_id, type, encrypted_data, meta, version, 
+	query := `SELECT id, server_id, COALESCE(uuid, ''), user_id, type, encrypted_data, meta, version, 
 	                 last_modified, deleted_at, checksum, device_id, synced, 
-	                 sync_version, created_at 
+	                 sync_version, created_at, preview
 	          FROM records WHERE 1=1`
 	args := []interface{}{}
 
@@ -189,14 +275,20 @@ func (s *SQLiteStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error
 
 	query += " ORDER BY last_modified DESC"
 
-	if filter.Limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, filter.Limit)
-	}
+	// Фильтры по метаданным проверяются в Go: LIKE в SQLite не учитывает
+	// регистр только для ASCII, а названия бывают на русском. Пагинация
+	// в этом случае применяется после фильтрации.
+	metaFilters := filter.hasMetaFilters()
+	if !metaFilters {
+		if filter.Limit > 0 {
+			query += " LIMIT ?"
+			args = append(args, filter.Limit)
+		}
 
-	if filter.Offset > 0 {
-		query += " OFFSET ?"
-		args = append(args, filter.Offset)
+		if filter.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, filter.Offset)
+		}
 	}
 
 	rows, err := s.db.Query(query, args...)
@@ -210,14 +302,19 @@ func (s *SQLiteStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error
 		var rec LocalRecord
 		var metaJSON string
 		var deletedAt sql.NullTime
+		var preview sql.NullString
 
-		if err := rows.Scan(&rec.ID, &rec.ServerID, &rec.UserID, &rec.Type, &rec.EncryptedData,
+		if err := rows.Scan(&rec.ID, &rec.ServerID, &rec.UUID, &rec.UserID, &rec.Type, &rec.EncryptedData,
 			&metaJSON, &rec.Version, &rec.LastModified, &deletedAt, &rec.Checksum,
-			&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt); err != nil {
+			&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt, &preview); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования записи: %w", err)
 		}
 
 		rec.Meta = json.RawMessage(metaJSON)
+		if metaFilters && !filter.matchesMeta(rec.Meta) {
+			continue
+		}
+		rec.Preview = decodePreview(preview)
 		if deletedAt.Valid {
 			rec.DeletedAt = &deletedAt.Time
 		}
@@ -225,6 +322,10 @@ func (s *SQLiteStorage) ListRecords(filter *RecordFilter) ([]*LocalRecord, error
 		records = append(records, &rec)
 	}
 
+	if metaFilters {
+		records = paginate(records, filter.Limit, filter.Offset)
+	}
+
 	return records, nil
 }
 
@@ -271,16 +372,18 @@ func (s *SQLiteStorage) GetUnsyncedRecords() ([]*LocalRecord, error) {
 	})
 }
 
-func (s *SQLiteStorage) GetRecordsModifiedAfter(since time.Time, limit int) ([]*LocalRecord, error) {
-	query := `SELECT id, server_id, user_id, type, encrypted_data, meta, version, 
+func (s *SQLiteStorage) GetRecordsModifiedAfter(since time.Time, limit int, filter sync.Filter) ([]*LocalRecord, error) {
+	conditions, filterArgs := syncFilterConditions(filter)
+	query := `SELECT id, server_id, COALESCE(uuid, ''), user_id, type, encrypted_data, meta, version, 
 	                 last_modified, deleted_at, checksum, device_id, synced, 
-	                 sync_version, created_at 
+	                 sync_version, created_at, preview
 	          FROM records 
-	          WHERE (synced = 0 OR last_modified > ?)
+	          WHERE (synced = 0 OR last_modified > ?)` + conditions + `
 	          ORDER BY last_modified ASC
 	          LIMIT ?`
 
-	rows, err := s.db.Query(query, since, limit)
+	args := append([]any{since}, filterArgs...)
+	rows, err := s.db.Query(query, append(args, limit)...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
@@ -291,14 +394,16 @@ func (s *SQLiteStorage) GetRecordsModifiedAfter(since time.Time, limit int) ([]*
 		var rec LocalRecord
 		var metaJSON string
 		var deletedAt sql.NullTime
+		var preview sql.NullString
 
-		if err := rows.Scan(&rec.ID, &rec.ServerID, &rec.UserID, &rec.Type, &rec.EncryptedData,
+		if err := rows.Scan(&rec.ID, &rec.ServerID, &rec.UUID, &rec.UserID, &rec.Type, &rec.EncryptedData,
 			&metaJSON, &rec.Version, &rec.LastModified, &deletedAt, &rec.Checksum,
-			&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt); err != nil {
+			&rec.DeviceID, &rec.Synced, &rec.SyncVersion, &rec.CreatedAt, &preview); err != nil {
 			return nil, fmt.Errorf("ошибка сканирования записи: %w", err)
 		}
 
 		rec.Meta = json.RawMessage(metaJSON)
+		rec.Preview = decodePreview(preview)
 		if deletedAt.Valid {
 			rec.DeletedAt = &deletedAt.Time
 		}
@@ -309,6 +414,32 @@ func (s *SQLiteStorage) GetRecordsModifiedAfter(since time.Time, limit int) ([]*
 	return records, nil
 }
 
+// syncFilterConditions возвращает условия выборочной синхронизации для WHERE:
+// тип записи и теги из открытых метаданных
+func syncFilterConditions(filter sync.Filter) (string, []any) {
+	var conditions string
+	var args []any
+
+	in := func(values []string) string {
+		for _, v := range values {
+			args = append(args, v)
+		}
+		return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	}
+
+	if len(filter.Types) > 0 {
+		conditions += " AND type IN (" + in(filter.Types) + ")"
+	}
+	if len(filter.Tags) > 0 {
+		conditions += " AND EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(meta) THEN meta END, '$.tags') WHERE value IN (" + in(filter.Tags) + "))"
+	}
+	if len(filter.ExcludeTags) > 0 {
+		conditions += " AND NOT EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(meta) THEN meta END, '$.tags') WHERE value IN (" + in(filter.ExcludeTags) + "))"
+	}
+
+	return conditions, args
+}
+
 func (s *SQLiteStorage) MarkAsSynced(id int, serverID int, syncVersion int64) error {
 	_, err := s.db.Exec(`
 		UPDATE records 
@@ -322,6 +453,106 @@ func (s *SQLiteStorage) MarkAsSynced(id int, serverID int, syncVersion int64) er
 	return nil
 }
 
+// SaveAttachments заменяет сохраненный список вложений записи
+func (s *SQLiteStorage) SaveAttachments(recordID int, attachments []*LocalAttachment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`DELETE FROM attachments WHERE record_id = ?`, recordID); err != nil {
+		return fmt.Errorf("ошибка очистки вложений: %w", err)
+	}
+	for _, a := range attachments {
+		if _, err := tx.Exec(`
+			INSERT INTO attachments (id, record_id, encrypted_meta, size, created_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, a.ID, recordID, a.EncryptedMeta, a.Size, a.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка сохранения вложения: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListAttachments возвращает сохраненный список вложений записи
+func (s *SQLiteStorage) ListAttachments(recordID int) ([]*LocalAttachment, error) {
+	rows, err := s.db.Query(`
+		SELECT id, record_id, encrypted_meta, size, created_at
+		FROM attachments
+		WHERE record_id = ?
+		ORDER BY id
+	`, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения вложений: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*LocalAttachment
+	for rows.Next() {
+		a := &LocalAttachment{}
+		if err := rows.Scan(&a.ID, &a.RecordID, &a.EncryptedMeta, &a.Size, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования вложения: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// SaveFolders заменяет сохраненный список папок
+func (s *SQLiteStorage) SaveFolders(folders []folder.Folder) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(`DELETE FROM folders`); err != nil {
+		return fmt.Errorf("ошибка очистки папок: %w", err)
+	}
+	for _, f := range folders {
+		if _, err := tx.Exec(`
+			INSERT INTO folders (id, parent_id, name, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, f.ID, f.ParentID, f.Name, f.CreatedAt, f.UpdatedAt); err != nil {
+			return fmt.Errorf("ошибка сохранения папки: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListFolders возвращает сохраненный список папок
+func (s *SQLiteStorage) ListFolders() ([]folder.Folder, error) {
+	rows, err := s.db.Query(`
+		SELECT id, parent_id, name, created_at, updated_at
+		FROM folders
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения папок: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []folder.Folder
+	for rows.Next() {
+		var f folder.Folder
+		var parentID sql.NullInt64
+		if err := rows.Scan(&f.ID, &parentID, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка сканирования папки: %w", err)
+		}
+		if parentID.Valid {
+			id := int(parentID.Int64)
+			f.ParentID = &id
+		}
+		folders = append(folders, f)
+	}
+
+	return folders, rows.Err()
+}
+
 // GetDB возвращает подключение к базе данных (для sync service)
 func (s *SQLiteStorage) GetDB() *sql.DB {
 	return s.db
@@ -336,14 +567,23 @@ type Storage interface {
 	SaveRecord(rec *LocalRecord) error
 	GetRecord(id int) (*LocalRecord, error)
 	GetRecordByServerID(serverID int) (*LocalRecord, error)
+	GetRecordByUUID(recordUUID string) (*LocalRecord, error)
 	ListRecords(filter *RecordFilter) ([]*LocalRecord, error)
 	UpdateRecord(rec *LocalRecord) error
 	DeleteRecord(id int) error
 	HardDeleteRecord(id int) error
 	CountRecords() (int, error)
 	GetUnsyncedRecords() ([]*LocalRecord, error)
-	GetRecordsModifiedAfter(since time.Time, limit int) ([]*LocalRecord, error)
+	// GetRecordsModifiedAfter возвращает несинхронизированные записи и измененные
+	// после since, подходящие под фильтр выборочной синхронизации
+	GetRecordsModifiedAfter(since time.Time, limit int, filter sync.Filter) ([]*LocalRecord, error)
 	MarkAsSynced(id int, serverID int, syncVersion int64) error
+	// SaveAttachments заменяет кэш списка вложений записи, ListAttachments читает его
+	SaveAttachments(recordID int, attachments []*LocalAttachment) error
+	ListAttachments(recordID int) ([]*LocalAttachment, error)
+	// SaveFolders заменяет кэш папок, ListFolders читает его
+	SaveFolders(folders []folder.Folder) error
+	ListFolders() ([]folder.Folder, error)
 	Close() error
 }
 
@@ -353,9 +593,12 @@ var _ Storage = (*MemoryStorage)(nil)
 
 // Добавляем недостающие методы в MemoryStorage
 
-func (m *MemoryStorage) GetRecordsModifiedAfter(since time.Time, limit int) ([]*LocalRecord, error) {
+func (m *MemoryStorage) GetRecordsModifiedAfter(since time.Time, limit int, filter sync.Filter) ([]*LocalRecord, error) {
 	var records []*LocalRecord
 	for _, rec := range m.records {
+		if !filter.Matches(string(rec.Type), rec.Meta) {
+			continue
+		}
 		if !rec.Synced || rec.LastModified.After(since) {
 			records = append(records, rec)
 			if len(records) >= limit {
@@ -369,14 +612,34 @@ func (m *MemoryStorage) GetRecordsModifiedAfter(since time.Time, limit int) ([]*
 func (m *MemoryStorage) MarkAsSynced(id int, serverID int, syncVersion int64) error {
 	rec, exists := m.records[id]
 	if !exists {
-		return fmt.Errorf("запись не найдена: %d", id)
+		return fmt.Errorf("%w: %d", ErrRecordNotFound, id)
 	}
 	rec.Synced = true
 	rec.ServerID = serverID
 	rec.SyncVersion = syncVersion
-	m.serverMap[serverID] = id
 	return nil
 }
 
+func (m *MemoryStorage) SaveAttachments(recordID int, attachments []*LocalAttachment) error {
+	if _, exists := m.records[recordID]; !exists {
+		return fmt.Errorf("%w: %d", ErrRecordNotFound, recordID)
+	}
+	m.attachments[recordID] = attachments
+	return nil
+}
+
+func (m *MemoryStorage) ListAttachments(recordID int) ([]*LocalAttachment, error) {
+	return m.attachments[recordID], nil
+}
+
+func (m *MemoryStorage) SaveFolders(folders []folder.Folder) error {
+	m.folders = slices.Clone(folders)
+	return nil
+}
+
+func (m *MemoryStorage) ListFolders() ([]folder.Folder, error) {
+	return slices.Clone(m.folders), nil
+}
+
 // Unused import fix
 var _ = record.RecTypeLogin
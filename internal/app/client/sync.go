@@ -4,6 +4,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	gosync "sync"
@@ -12,9 +13,16 @@ import (
 	"golang.org/x/exp/slog"
 
 	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/settings"
 	"gophkeeper/internal/domain/sync"
 )
 
+// defaultConflictStrategy стратегия разрешения конфликтов по умолчанию
+const defaultConflictStrategy = "newer"
+
+// maxUploadBatches - сколько пакетов по BatchSize записей отправляется за одну синхронизацию
+const maxUploadBatches = 20
+
 // SyncService управляет синхронизацией данных между клиентом и сервером
 type SyncService struct {
 	app       *App
@@ -24,6 +32,12 @@ type SyncService struct {
 	lastSync  time.Time
 	isSyncing bool
 	stats     *SyncStats
+
+	// localStrategy - стратегия задана в sync_config.json и имеет приоритет над настройками сервера
+	localStrategy bool
+
+	// paused - сервер в режиме обслуживания, синхронизация приостановлена до paused.Until
+	paused *MaintenanceError
 }
 
 // SyncConfig конфигурация синхронизации
@@ -33,8 +47,10 @@ type SyncConfig struct {
 	BatchSize        int           `json:"batch_size"`
 	MaxRetries       int           `json:"max_retries"`
 	RetryDelay       time.Duration `json:"retry_delay"`
-	ConflictStrategy string        `json:"conflict_strategy"` // client, server, newer, manual
+	ConflictStrategy string        `json:"conflict_strategy"` // client, server, newer, merge, manual
 	AutoResolve      bool          `json:"auto_resolve"`      // автоматически разрешать конфликты
+	// Filter - выборочная синхронизация: только записи этих типов и тегов
+	Filter sync.Filter `json:"filter"`
 }
 
 // SyncError ошибка синхронизации
@@ -57,15 +73,25 @@ type SyncResult struct {
 	Duration   time.Duration `json:"duration"`
 	StartTime  time.Time     `json:"start_time"`
 	EndTime    time.Time     `json:"end_time"`
+	// Paused - синхронизация не выполнялась или прервана: сервер на обслуживании
+	Paused *MaintenanceError `json:"paused,omitempty"`
 }
 
 // SyncMetadata метаданные для синхронизации
 type SyncMetadata struct {
-	ClientID      string    `json:"client_id"`
-	LastSyncTime  time.Time `json:"last_sync_time"`
-	SyncVersion   int64     `json:"sync_version"`
-	DeviceName    string    `json:"device_name"`
-	ClientVersion string    `json:"client_version"`
+	ClientID     string    `json:"client_id"`
+	LastSyncTime time.Time `json:"last_sync_time"`
+	// Cursor - токен сервера, после которого запрашиваются изменения.
+	// Пустой у серверов прежних версий: тогда используется LastSyncTime.
+	Cursor string `json:"cursor,omitempty"`
+	// Filter - фильтр, с которым получен Cursor
+	Filter sync.Filter `json:"filter"`
+	// ChangesETag - ETag последнего примененного ответа с изменениями: если
+	// на следующий запрос сервер ответил бы тем же, он вернет 304 без тела
+	ChangesETag   string `json:"changes_etag,omitempty"`
+	SyncVersion   int64  `json:"sync_version"`
+	DeviceName    string `json:"device_name"`
+	ClientVersion string `json:"client_version"`
 }
 
 // SyncStats статистика синхронизации (локальная версия)
@@ -78,6 +104,11 @@ type SyncStats struct {
 	TotalResolved   int       `json:"total_resolved"`
 	TotalErrors     int       `json:"total_errors"`
 	AvgSyncDuration float64   `json:"avg_sync_duration"`
+	// LastAttempt, LastSuccess и LastError - итог последнего запуска, в том
+	// числе прерванного до обмена данными
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+	LastSuccess bool      `json:"last_success"`
+	LastError   string    `json:"last_error,omitempty"`
 }
 
 // LocalConflict конфликт синхронизации (локальная версия с расширенными полями)
@@ -96,47 +127,49 @@ func NewSyncService(app *App) *SyncService {
 		BatchSize:        50,
 		MaxRetries:       3,
 		RetryDelay:       5 * time.Second,
-		ConflictStrategy: "newer", // по умолчанию выбираем новую версию
+		ConflictStrategy: defaultConflictStrategy, // по умолчанию выбираем новую версию
 		AutoResolve:      true,
 	}
 
 	// Загружаем конфигурацию из файла если есть
+	localStrategy := false
 	if config, err := loadSyncConfig(app.config.ConfigDir); err == nil {
 		// Объединяем с дефолтными значениями
 		mergeConfigs(defaultConfig, config)
+		localStrategy = config.ConflictStrategy != ""
 	}
 
 	return &SyncService{
-		app:    app,
-		log:    app.log,
-		config: defaultConfig,
-		stats:  &SyncStats{},
+		app:           app,
+		log:           app.log,
+		config:        defaultConfig,
+		stats:         loadSyncStats(app),
+		localStrategy: localStrategy,
 	}
 }
 
 // Sync запускает процесс синхронизации
 func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
-	s.mu.Lock()
-	if s.isSyncing {
-		s.mu.Unlock()
-		return nil, fmt.Errorf("синхронизация уже выполняется")
+	if err := s.begin(); err != nil {
+		return nil, err
 	}
-
-	s.isSyncing = true
-	s.mu.Unlock()
-
-	defer func() {
-		s.mu.Lock()
-		s.isSyncing = false
-		s.mu.Unlock()
-	}()
+	defer s.end()
 
 	result := &SyncResult{
 		StartTime: time.Now(),
 		Errors:    []SyncError{},
 	}
+	defer s.recordOutcome(result)
 
 	if err := s.preSyncChecks(ctx); err != nil {
+		var merr *MaintenanceError
+		if errors.As(err, &merr) {
+			// Режим обслуживания - не ошибка: изменения отправятся после паузы
+			result.Paused = merr
+			result.EndTime = time.Now()
+			result.Duration = result.EndTime.Sub(result.StartTime)
+			return result, err
+		}
 		result.Errors = append(result.Errors, SyncError{
 			Error:     err.Error(),
 			Operation: "pre_sync_check",
@@ -150,13 +183,11 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 
 	s.log.Info("Начало синхронизации", "start_time", result.StartTime)
 
-	// 1. Получаем метаданные синхронизации
-	syncMeta, err := s.getSyncMetadata(ctx)
-	if err != nil {
-		s.log.Error("Ошибка получения метаданных синхронизации", "error", err)
+	if err := s.app.ensureDeviceRegistered(ctx); err != nil {
+		s.log.Warn("Устройство ожидает подтверждения", "error", err)
 		result.Errors = append(result.Errors, SyncError{
 			Error:     err.Error(),
-			Operation: "get_metadata",
+			Operation: "device_trust",
 			Timestamp: time.Now(),
 		})
 		result.Success = false
@@ -164,40 +195,45 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 		result.Duration = result.EndTime.Sub(result.StartTime)
 		return result, err
 	}
+	s.selectProtocol(ctx)
+	if err := s.app.PublishKeyFile(ctx); err != nil {
+		s.log.Warn("Не удалось сохранить файл мастер-ключа на сервере", "error", err)
+	}
 
-	// 2. Получаем локальные изменения
-	localChanges, err := s.getLocalChanges(ctx, syncMeta)
-	if err != nil {
-		s.log.Error("Ошибка получения локальных изменений", "error", err)
-		result.Errors = append(result.Errors, SyncError{
-			Error:     err.Error(),
-			Operation: "get_local_changes",
-			Timestamp: time.Now(),
-		})
+	// Подтягиваем настройки пользователя с сервера (не критично для синхронизации)
+	if values, err := s.app.httpClient.GetSettings(ctx); err != nil {
+		s.log.Warn("Не удалось получить настройки пользователя", "error", err)
+	} else {
+		s.ApplyUserSettings(values)
 	}
 
-	// 3. Получаем изменения с сервера
-	serverChanges, err := s.getServerChanges(ctx, syncMeta)
+	// 1-4. Находим изменения обеих сторон и конфликты между ними
+	changes, err := s.collectChanges(ctx, false)
 	if err != nil {
-		s.log.Error("Ошибка получения изменений с сервера", "error", err)
 		result.Errors = append(result.Errors, SyncError{
 			Error:     err.Error(),
-			Operation: "get_server_changes",
+			Operation: "get_metadata",
 			Timestamp: time.Now(),
 		})
+		result.Success = false
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result, err
 	}
+	result.Errors = append(result.Errors, changes.errors...)
+	localChanges, serverChanges, conflicts := changes.local, changes.server, changes.conflicts
+	advanceCursor := changes.advanceCursor
 
-	// 4. Обнаруживаем и разрешаем конфликты
-	conflicts, err := s.detectConflicts(localChanges, serverChanges)
-	if err != nil {
-		s.log.Error("Ошибка обнаружения конфликтов", "error", err)
-		result.Errors = append(result.Errors, SyncError{
-			Error:     err.Error(),
-			Operation: "detect_conflicts",
-			Timestamp: time.Now(),
+	result.Conflicts = len(conflicts)
+	for _, conflict := range conflicts {
+		s.app.hooks.Fire(ctx, HookEventConflictDetected, ConflictDetectedEvent{
+			RecordID:     conflict.RecordID,
+			RecordType:   conflict.RecordType,
+			Title:        conflict.Title,
+			ConflictType: conflict.ConflictType,
+			Strategy:     s.config.ConflictStrategy,
 		})
 	}
-	result.Conflicts = len(conflicts)
 
 	// 5. Разрешаем конфликты
 	resolvedConflicts, err := s.resolveConflicts(ctx, conflicts)
@@ -211,6 +247,12 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 	}
 	result.Resolved = len(resolvedConflicts)
 
+	// Прерванная синхронизация не применяет изменения частично и не сдвигает
+	// курсор: следующий запуск начнет с того же места
+	if err := ctx.Err(); err != nil {
+		return s.interrupted(result, err)
+	}
+
 	// 6. Отправляем изменения на сервер
 	if len(localChanges) > 0 {
 		uploaded, uploadErrors := s.uploadChanges(ctx, localChanges)
@@ -223,10 +265,25 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 		downloaded, downloadErrors := s.applyServerChanges(ctx, serverChanges)
 		result.Downloaded = downloaded
 		result.Errors = append(result.Errors, downloadErrors...)
+		advanceCursor = advanceCursor && len(downloadErrors) == 0
+	}
+
+	if err := ctx.Err(); err != nil {
+		return s.interrupted(result, err)
 	}
 
 	// 8. Обновляем метаданные синхронизации
-	if err := s.updateSyncMetadata(ctx); err != nil {
+	// ETag сохраняется только вместе с курсором: иначе 304 в следующий раз
+	// скрыл бы изменения, которые не удалось применить
+	syncMeta := changes.meta
+	if advanceCursor {
+		syncMeta.Cursor = changes.nextCursor
+		syncMeta.Filter = changes.filter
+		syncMeta.ChangesETag = changes.changesETag
+	} else {
+		syncMeta.ChangesETag = ""
+	}
+	if err := s.updateSyncMetadata(ctx, syncMeta); err != nil {
 		s.log.Error("Ошибка обновления метаданных синхронизации", "error", err)
 		result.Errors = append(result.Errors, SyncError{
 			Error:     err.Error(),
@@ -237,6 +294,7 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 
 	// 9. Обновляем статистику
 	s.updateStats(result)
+	result.Paused = s.Paused()
 
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
@@ -256,11 +314,142 @@ func (s *SyncService) Sync(ctx context.Context) (*SyncResult, error) {
 		)
 	}
 
+	s.app.hooks.Fire(ctx, HookEventPostSync, result)
+
 	return result, nil
 }
 
+// syncChanges - изменения обеих сторон и конфликты между ними, найденные
+// до обмена данными
+type syncChanges struct {
+	meta   *SyncMetadata
+	filter sync.Filter
+	local  []*LocalRecord
+	server []*LocalRecord
+	// nextCursor и changesETag сохраняются, если advanceCursor: все
+	// изменения сервера получены
+	nextCursor    string
+	changesETag   string
+	advanceCursor bool
+	conflicts     []*LocalConflict
+	errors        []SyncError
+}
+
+// collectChanges выполняет шаги 1-4 синхронизации: читает метаданные, находит
+// локальные изменения, изменения сервера и конфликты между ними. Ошибки шагов
+// 2-4 не прерывают сбор и возвращаются в errors. В режиме dryRun ничего не
+// записывается: ключ после ротации на другом устройстве не принимается,
+// регистрация устройства не сбрасывается.
+func (s *SyncService) collectChanges(ctx context.Context, dryRun bool) (*syncChanges, error) {
+	// 1. Получаем метаданные синхронизации
+	syncMeta, err := s.getSyncMetadata(ctx)
+	if err != nil {
+		s.log.Error("Ошибка получения метаданных синхронизации", "error", err)
+		return nil, err
+	}
+	changes := &syncChanges{meta: syncMeta, filter: s.Filter()}
+
+	// 2. Получаем локальные изменения
+	changes.local, err = s.getLocalChanges(ctx, syncMeta)
+	if err != nil {
+		s.log.Error("Ошибка получения локальных изменений", "error", err)
+		changes.errors = append(changes.errors, SyncError{
+			Error:     err.Error(),
+			Operation: "get_local_changes",
+			Timestamp: time.Now(),
+		})
+	}
+
+	// 3. Получаем изменения с сервера. Без курсора сначала сверяем индекс,
+	// чтобы не загружать заново записи, которые уже есть локально. Курсор,
+	// полученный с другим фильтром, пропустил бы записи, которые теперь
+	// попадают в выборку, поэтому после смены фильтра сверка тоже нужна.
+	filter := changes.filter
+	fetchMeta := syncMeta
+	if !filter.Equal(syncMeta.Filter) {
+		s.log.Info("Фильтр синхронизации изменился, записи сверяются заново")
+		fetchMeta = &SyncMetadata{}
+	}
+
+	negotiated := false
+	if fetchMeta.Cursor == "" && fetchMeta.LastSyncTime.IsZero() {
+		changes.server, changes.nextCursor, negotiated, err = s.negotiateServerChanges(ctx, filter)
+	}
+	if !negotiated && err == nil {
+		changes.server, changes.nextCursor, changes.changesETag, err = s.getServerChanges(ctx, fetchMeta, filter)
+	}
+	// Курсор сдвигается, только если все изменения сервера получены и
+	// применены: иначе следующий запуск запросит их снова
+	changes.advanceCursor = err == nil
+	if errors.Is(err, ErrDeviceUnregistered) && !dryRun {
+		s.app.forgetDeviceRegistration()
+	}
+	if err != nil {
+		s.log.Error("Ошибка получения изменений с сервера", "error", err)
+		changes.errors = append(changes.errors, SyncError{
+			Error:     err.Error(),
+			Operation: "get_server_changes",
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Записи, зашифрованные ключом после ротации на другом устройстве,
+	// сохраняются как есть; для их чтения загружается новый файл ключа
+	if !dryRun {
+		if err := s.app.adoptRotatedKey(ctx, changes.server); err != nil {
+			s.log.Warn("Ключ данных заменен на другом устройстве", "error", err)
+			changes.errors = append(changes.errors, SyncError{
+				Error:     err.Error(),
+				Operation: "key_rotation",
+				Timestamp: time.Now(),
+			})
+		}
+	}
+
+	// 4. Обнаруживаем конфликты
+	changes.conflicts, err = s.detectConflicts(changes.local, changes.server)
+	if err != nil {
+		s.log.Error("Ошибка обнаружения конфликтов", "error", err)
+		changes.errors = append(changes.errors, SyncError{
+			Error:     err.Error(),
+			Operation: "detect_conflicts",
+			Timestamp: time.Now(),
+		})
+	}
+
+	return changes, nil
+}
+
+// interrupted завершает синхронизацию, прерванную отменой контекста
+func (s *SyncService) interrupted(result *SyncResult, err error) (*SyncResult, error) {
+	s.log.Warn("Синхронизация прервана", "error", err)
+	result.Success = false
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	return result, err
+}
+
+// begin отмечает начало синхронизации; одновременно выполняется только одна
+func (s *SyncService) begin() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isSyncing {
+		return fmt.Errorf("синхронизация уже выполняется")
+	}
+	s.isSyncing = true
+	return nil
+}
+
+// end отмечает окончание синхронизации
+func (s *SyncService) end() {
+	s.mu.Lock()
+	s.isSyncing = false
+	s.mu.Unlock()
+}
+
 // preSyncChecks проверяет условия для синхронизации
-func (s *SyncService) preSyncChecks(_ context.Context) error {
+func (s *SyncService) preSyncChecks(ctx context.Context) error {
 	// 1. Проверяем, включена ли синхронизация
 	if !s.config.Enabled {
 		return fmt.Errorf("синхронизация отключена")
@@ -271,14 +460,28 @@ func (s *SyncService) preSyncChecks(_ context.Context) error {
 		return fmt.Errorf("пользователь не аутентифицирован")
 	}
 
-	// 3. Проверяем соединение с сервером
-	if err := s.app.CheckConnection(); err != nil {
+	// По UUID устройства в запросах сервер учитывает трафик устройства и
+	// проверяет, что устройство подтверждено
+	if _, err := s.app.DeviceIdentity(); err != nil {
+		s.log.Warn("Не удалось получить идентификатор устройства", "error", err)
+	}
+
+	// 3. Проверяем соединение с сервером и режим обслуживания
+	if merr := s.Paused(); merr != nil {
+		return merr
+	}
+	health := s.app.ConnectionStatus(ctx, false)
+	if err := health.err(); err != nil {
 		return fmt.Errorf("сервер недоступен: %w", err)
 	}
+	if merr := health.MaintenanceErr(); merr != nil {
+		s.pause(merr)
+		return merr
+	}
 
 	// 4. Проверяем мастер-ключ
 	if s.app.crypto.IsLocked() {
-		return fmt.Errorf("мастер-ключ заблокирован")
+		return ErrMasterKeyLocked
 	}
 
 	// 5. Проверяем, не слишком ли часто пытаемся синхронизироваться
@@ -292,14 +495,16 @@ func (s *SyncService) preSyncChecks(_ context.Context) error {
 // getSyncMetadata получает метаданные синхронизации
 func (s *SyncService) getSyncMetadata(_ context.Context) (*SyncMetadata, error) {
 	meta := &SyncMetadata{
-		ClientID:      s.app.config.ConfigDir, // Используем директорию конфига как ID клиента
-		ClientVersion: "1.0.0",
+		ClientID:      s.clientID(),
+		ClientVersion: Version,
 		DeviceName:    getDeviceName(),
 	}
 
 	// Загружаем сохраненные метаданные
 	if data, err := s.loadSyncMetadata(); err == nil {
 		meta.LastSyncTime = data.LastSyncTime
+		meta.Cursor = data.Cursor
+		meta.Filter = data.Filter
 		meta.SyncVersion = data.SyncVersion
 	}
 
@@ -308,8 +513,9 @@ func (s *SyncService) getSyncMetadata(_ context.Context) (*SyncMetadata, error)
 
 // getLocalChanges получает локальные изменения
 func (s *SyncService) getLocalChanges(_ context.Context, meta *SyncMetadata) ([]*LocalRecord, error) {
-	// Получаем записи, которые не синхронизированы или изменились после последней синхронизации
-	records, err := s.app.storage.GetRecordsModifiedAfter(meta.LastSyncTime, s.config.BatchSize)
+	// Получаем записи, которые не синхронизированы или изменились после последней синхронизации.
+	// Остальные отправятся при следующей синхронизации, записи вне фильтра остаются локальными.
+	records, err := s.app.storage.GetRecordsModifiedAfter(meta.LastSyncTime, s.config.BatchSize*maxUploadBatches, s.Filter())
 	if err != nil {
 		return nil, fmt.Errorf("ошибка запроса локальных изменений: %w", err)
 	}
@@ -318,104 +524,104 @@ func (s *SyncService) getLocalChanges(_ context.Context, meta *SyncMetadata) ([]
 	return records, nil
 }
 
-// getServerChanges получает изменения с сервера
-func (s *SyncService) getServerChanges(ctx context.Context, meta *SyncMetadata) ([]*LocalRecord, error) {
-	// Используем HTTP клиент для получения изменений с сервера
+// getServerChanges постранично получает изменения с сервера после курсора.
+// Первая страница запрашивается условно по meta.ChangesETag: ответ 304
+// означает, что новых изменений нет. Возвращает также ETag последней страницы.
+// Возвращает записи, подходящие под filter, и курсор после последней страницы.
+func (s *SyncService) getServerChanges(ctx context.Context, meta *SyncMetadata, filter sync.Filter) ([]*LocalRecord, string, string, error) {
 	req := sync.GetChangesRequest{
-		LastSyncTime: meta.LastSyncTime,
+		Cursor:       meta.Cursor,
+		LastSyncTime: meta.LastSyncTime, // для сервера без курсоров
 		Limit:        s.config.BatchSize,
 	}
-
-	response, err := s.app.httpClient.GetSyncChanges(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка получения изменений с сервера: %w", err)
+	if !filter.IsEmpty() {
+		req.Filter = &filter
 	}
 
-	// Конвертируем серверные записи в локальные
 	var records []*LocalRecord
-	for _, syncRec := range response.Records {
-		localRec := &LocalRecord{
-			ServerID:     syncRec.ID,
-			UserID:       syncRec.UserID,
-			Type:         record.RecType(syncRec.Type),
-			Version:      syncRec.Version,
-			LastModified: syncRec.LastModified,
-			Synced:       true,
+	etag := meta.ChangesETag
+	for {
+		response, responseETag, err := s.app.httpClient.GetSyncChanges(ctx, req, etag)
+		if errors.Is(err, ErrNotModified) {
+			s.log.Debug("Изменений на сервере нет (304)")
+			return nil, req.Cursor, etag, nil
 		}
-		if syncRec.DeletedAt != nil {
-			localRec.DeletedAt = syncRec.DeletedAt
+		if err != nil {
+			return nil, "", "", fmt.Errorf("ошибка получения изменений с сервера: %w", err)
 		}
-		// Используем Meta напрямую
-		localRec.Meta = syncRec.Meta
-		localRec.EncryptedData = syncRec.EncryptedData
-		records = append(records, localRec)
+		// Следующие страницы - новые ответы, условными они не бывают
+		etag = responseETag
+
+		for _, syncRec := range response.Records {
+			// Сервер прежней версии фильтр не применяет
+			if !filter.Matches(syncRec.Type, syncRec.Meta) {
+				continue
+			}
+			records = append(records, fromSyncRecord(syncRec))
+		}
+
+		// Сервер прежней версии курсор не выдает, и страницы по времени
+		// ненадежны: как и раньше, берем только первую
+		if !response.HasMore || response.NextCursor == "" || response.NextCursor == req.Cursor {
+			req.Cursor = response.NextCursor
+			break
+		}
+		req.Cursor = response.NextCursor
 	}
 
 	s.log.Debug("Получены изменения с сервера", "count", len(records))
-	return records, nil
+	return records, req.Cursor, etag, nil
+}
+
+// fromSyncRecord конвертирует серверную запись в локальную
+func fromSyncRecord(syncRec sync.RecordSync) *LocalRecord {
+	return &LocalRecord{
+		ServerID:      syncRec.ID,
+		UUID:          syncRec.UUID,
+		UserID:        syncRec.UserID,
+		Type:          record.RecType(syncRec.Type),
+		EncryptedData: syncRec.EncryptedData,
+		Meta:          syncRec.Meta,
+		Version:       syncRec.Version,
+		LastModified:  syncRec.LastModified,
+		DeletedAt:     syncRec.DeletedAt,
+		Synced:        true,
+	}
 }
 
 // detectConflicts обнаруживает конфликты между локальными и серверными изменениями
 func (s *SyncService) detectConflicts(localChanges, serverChanges []*LocalRecord) ([]*LocalConflict, error) {
 	var conflicts []*LocalConflict
 
-	// Создаем карту для быстрого поиска записей по ServerID
-	localMap := make(map[int]*LocalRecord)
+	// Локальные изменения по локальному ID: серверная запись сопоставляется
+	// с локальной по UUID, серверный ID для этого не используется
+	pending := make(map[int]*LocalRecord)
 	for _, rec := range localChanges {
-		if rec.ServerID > 0 {
-			localMap[rec.ServerID] = rec
-		}
+		pending[rec.ID] = rec
 	}
 
-	serverMap := make(map[int]*LocalRecord)
-	for _, rec := range serverChanges {
-		serverMap[rec.ServerID] = rec
-	}
-
-	// Проверяем каждую запись на конфликты
 	checkedIDs := make(map[int]bool)
-
-	// Проверяем локальные изменения
-	for _, localRec := range localChanges {
-		if localRec.ServerID == 0 {
-			continue // Новая локальная запись, не может быть конфликта
+	for _, serverRec := range serverChanges {
+		// Получаем локальную версию записи (если есть)
+		localRec, err := s.localRecordFor(serverRec)
+		if err != nil || localRec == nil {
+			continue
 		}
-		if checkedIDs[localRec.ServerID] {
+		if checkedIDs[localRec.ID] {
 			continue
 		}
-		checkedIDs[localRec.ServerID] = true
-
-		if serverRec, exists := serverMap[localRec.ServerID]; exists {
-			// Обе стороны изменили запись
-			conflict, err := s.checkRecordConflict(localRec, serverRec)
-			if err != nil {
-				return nil, fmt.Errorf("ошибка проверки конфликта записи %d: %w", localRec.ID, err)
-			}
+		checkedIDs[localRec.ID] = true
 
-			if conflict != nil {
-				conflicts = append(conflicts, conflict)
-			}
+		if changed, ok := pending[localRec.ID]; ok {
+			localRec = changed
 		}
-	}
 
-	// Проверяем серверные изменения, которые не были в локальных
-	for _, serverRec := range serverChanges {
-		if checkedIDs[serverRec.ServerID] {
-			continue
+		conflict, err := s.checkRecordConflict(localRec, serverRec)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка проверки конфликта записи %d: %w", localRec.ID, err)
 		}
-
-		// Получаем локальную версию записи (если есть)
-		localRec, err := s.app.storage.GetRecordByServerID(serverRec.ServerID)
-		if err == nil && localRec != nil {
-			// Запись существует локально, проверяем конфликт
-			conflict, err := s.checkRecordConflict(localRec, serverRec)
-			if err != nil {
-				return nil, fmt.Errorf("ошибка проверки конфликта записи %d: %w", serverRec.ServerID, err)
-			}
-
-			if conflict != nil {
-				conflicts = append(conflicts, conflict)
-			}
+		if conflict != nil {
+			conflicts = append(conflicts, conflict)
 		}
 	}
 
@@ -451,12 +657,23 @@ func (s *SyncService) checkRecordConflict(localRec, serverRec *LocalRecord) (*Lo
 		return nil, nil
 	}
 
+	title := sync.TitleFromMeta(serverRec.Meta)
+	if title == "" {
+		title = sync.TitleFromMeta(localRec.Meta)
+	}
+
 	return &LocalConflict{
 		Conflict: sync.Conflict{
-			RecordID:     localRec.ServerID,
-			ConflictType: conflictType,
-			CreatedAt:    time.Now(),
-			Resolved:     false,
+			RecordID:       localRec.ServerID,
+			ConflictType:   conflictType,
+			CreatedAt:      time.Now(),
+			Resolved:       false,
+			RecordType:     string(localRec.Type),
+			Title:          title,
+			LocalVersion:   localRec.Version,
+			ServerVersion:  serverRec.Version,
+			LocalModified:  localRec.LastModified,
+			ServerModified: serverRec.LastModified,
 		},
 		LocalRecord:  localRec,
 		ServerRecord: serverRec,
@@ -464,7 +681,7 @@ func (s *SyncService) checkRecordConflict(localRec, serverRec *LocalRecord) (*Lo
 }
 
 // resolveConflicts разрешает конфликты
-func (s *SyncService) resolveConflicts(_ context.Context, conflicts []*LocalConflict) ([]*LocalConflict, error) {
+func (s *SyncService) resolveConflicts(ctx context.Context, conflicts []*LocalConflict) ([]*LocalConflict, error) {
 	var resolvedConflicts []*LocalConflict
 
 	for _, conflict := range conflicts {
@@ -472,10 +689,10 @@ func (s *SyncService) resolveConflicts(_ context.Context, conflicts []*LocalConf
 		var err error
 
 		if s.config.AutoResolve {
-			resolvedConflict, err = s.autoResolveConflict(conflict)
+			resolvedConflict, err = s.autoResolveConflict(ctx, conflict)
 		} else {
 			// TODO: В будущем можно добавить интерактивное разрешение конфликтов
-			resolvedConflict, err = s.autoResolveConflict(conflict)
+			resolvedConflict, err = s.autoResolveConflict(ctx, conflict)
 		}
 
 		if err != nil {
@@ -502,7 +719,7 @@ func (s *SyncService) resolveConflicts(_ context.Context, conflicts []*LocalConf
 }
 
 // autoResolveConflict автоматически разрешает конфликт
-func (s *SyncService) autoResolveConflict(conflict *LocalConflict) (*LocalConflict, error) {
+func (s *SyncService) autoResolveConflict(ctx context.Context, conflict *LocalConflict) (*LocalConflict, error) {
 	// Копируем конфликт
 	resolved := *conflict
 	resolved.Resolved = true
@@ -528,6 +745,10 @@ func (s *SyncService) autoResolveConflict(conflict *LocalConflict) (*LocalConfli
 			resolved.MergedRecord = conflict.ServerRecord
 		}
 
+	case "merge":
+		// Трехстороннее слияние по полям относительно общего предка
+		return s.mergeConflict(ctx, conflict)
+
 	case "manual":
 		// Требуется ручное разрешение
 		resolved.Resolved = false
@@ -553,6 +774,9 @@ func (s *SyncService) applyConflictResolution(conflict *LocalConflict) error {
 	if conflict.Resolution == "server" {
 		// Если выбрана серверная версия, увеличиваем версию
 		conflict.MergedRecord.Version = conflict.ServerRecord.Version + 1
+	} else if conflict.Resolution == "merged" {
+		// Объединенная версия должна быть новее обеих сторон
+		conflict.MergedRecord.Version = max(conflict.LocalRecord.Version, conflict.ServerRecord.Version) + 1
 	} else {
 		// Если выбрана локальная версия, увеличиваем версию
 		conflict.MergedRecord.Version = conflict.LocalRecord.Version + 1
@@ -561,72 +785,171 @@ func (s *SyncService) applyConflictResolution(conflict *LocalConflict) error {
 	return s.app.storage.UpdateRecord(conflict.MergedRecord)
 }
 
-// uploadChanges отправляет локальные изменения на сервер
+// uploadChanges отправляет локальные изменения на сервер пакетами по BatchSize.
+// Повторы при сетевых ошибках выполняет HTTP-клиент; записи, которые сервер не
+// сохранил, остаются несинхронизированными и уйдут при следующей синхронизации.
 func (s *SyncService) uploadChanges(ctx context.Context, changes []*LocalRecord) (int, []SyncError) {
-	var errors []SyncError
+	var syncErrors []SyncError
 	uploaded := 0
 
-	// Конвертируем локальные записи в формат для batch sync
-	var syncRecords []sync.RecordSync
-	for _, rec := range changes {
-		// Данные уже зашифрованы на клиенте, просто передаем их
-		syncRec := sync.RecordSync{
-			ID:            rec.ServerID,      // ID на сервере
-			UserID:        rec.UserID,        // ID пользователя
-			Type:          string(rec.Type),  // Тип записи
-			EncryptedData: rec.EncryptedData, // Зашифрованные данные
-			Meta:          rec.Meta,          // Метаданные (не шифруются для поиска)
-			Version:       rec.Version,       // Версия
-			LastModified:  rec.LastModified,  // Время последнего изменения
-			Checksum:      rec.Checksum,      // Контрольная сумма
-			DeviceID:      rec.DeviceID,      // ID устройства
+	for start := 0; start < len(changes); start += s.config.BatchSize {
+		if ctx.Err() != nil {
+			break
 		}
-		// DeletedAt опциональное поле
-		if rec.DeletedAt != nil {
-			syncRec.DeletedAt = rec.DeletedAt
+		batch := changes[start:min(start+s.config.BatchSize, len(changes))]
+
+		response, err := s.app.httpClient.SendBatchSync(ctx, sync.BatchSyncRequest{Records: toSyncRecords(batch)})
+		var merr *MaintenanceError
+		if errors.As(err, &merr) {
+			// Записи остаются неотправленными до окончания обслуживания
+			s.pause(merr)
+			break
+		}
+		if err != nil {
+			syncErrors = append(syncErrors, SyncError{
+				Error:     err.Error(),
+				Operation: "batch_upload",
+				Timestamp: time.Now(),
+			})
+			break
 		}
-		syncRecords = append(syncRecords, syncRec)
-	}
 
-	// Отправляем batch запрос
-	req := sync.BatchSyncRequest{
-		Records: syncRecords,
+		n, batchErrors := s.applyBatchResults(batch, response)
+		uploaded += n
+		syncErrors = append(syncErrors, batchErrors...)
 	}
 
-	response, err := s.app.httpClient.SendBatchSync(ctx, req)
-	if err != nil {
-		errors = append(errors, SyncError{
-			Error:     err.Error(),
-			Operation: "batch_upload",
-			Timestamp: time.Now(),
-		})
-		return 0, errors
+	s.log.Debug("Загружено записей на сервер", "count", uploaded, "errors", len(syncErrors))
+	return uploaded, syncErrors
+}
+
+// toSyncRecords конвертирует локальные записи в формат для batch sync.
+// Данные уже зашифрованы на клиенте и передаются как есть.
+func toSyncRecords(records []*LocalRecord) []sync.RecordSync {
+	syncRecords := make([]sync.RecordSync, len(records))
+	for i, rec := range records {
+		syncRecords[i] = sync.RecordSync{
+			ID:            rec.ServerID, // ID на сервере, 0 у новой записи
+			UUID:          rec.UUID,
+			UserID:        rec.UserID,
+			Type:          string(rec.Type),
+			EncryptedData: rec.EncryptedData,
+			Meta:          rec.Meta, // Метаданные не шифруются для поиска
+			Version:       rec.Version,
+			LastModified:  rec.LastModified,
+			DeletedAt:     rec.DeletedAt,
+			Checksum:      rec.Checksum,
+			DeviceID:      rec.DeviceID,
+		}
 	}
+	return syncRecords
+}
 
-	uploaded = response.Processed
+// applyBatchResults помечает синхронизированными записи, которые сервер сохранил,
+// и связывает новые записи с серверными ID. Возвращает число сохраненных записей.
+func (s *SyncService) applyBatchResults(batch []*LocalRecord, response *sync.BatchSyncResponse) (int, []SyncError) {
+	var syncErrors []SyncError
 
-	// Помечаем записи как синхронизированные
-	for _, rec := range changes {
-		rec.Synced = true
-		if err := s.app.storage.UpdateRecord(rec); err != nil {
-			s.log.Warn("Ошибка обновления статуса синхронизации",
+	// Сервер прежней версии не сообщает итог по записям
+	if len(response.Results) == 0 {
+		for _, rec := range batch {
+			s.markUploaded(rec)
+		}
+		return response.Processed, nil
+	}
+
+	uploaded := 0
+	for _, res := range response.Results {
+		if res.Index < 0 || res.Index >= len(batch) {
+			continue
+		}
+		rec := batch[res.Index]
+
+		switch res.Status {
+		case sync.BatchRecordSaved:
+			rec.ServerID = res.ID
+			rec.Version = res.Version
+			// Запись, созданная до появления UUID, получает серверный
+			if res.UUID != "" {
+				rec.UUID = res.UUID
+			}
+			uploaded++
+		case sync.BatchRecordConflict:
+			// Конфликт сохранен на сервере и разрешается там; повторная
+			// отправка той же версии создала бы новый конфликт
+			s.log.Info("Конфликт версий при отправке записи",
 				"record_id", rec.ID,
-				"error", err)
+				"server_id", rec.ServerID,
+				"server_version", res.Version)
+		default:
+			syncErrors = append(syncErrors, SyncError{
+				RecordID:  rec.ID,
+				Error:     res.Error,
+				Operation: "upload",
+				Timestamp: time.Now(),
+			})
+			continue
 		}
+
+		s.markUploaded(rec)
 	}
 
-	s.log.Debug("Загружено записей на сервер", "count", uploaded, "errors", len(errors))
-	return uploaded, errors
+	return uploaded, syncErrors
+}
+
+// markUploaded помечает запись как синхронизированную
+func (s *SyncService) markUploaded(rec *LocalRecord) {
+	rec.Synced = true
+	if err := s.app.storage.UpdateRecord(rec); err != nil {
+		s.log.Warn("Ошибка обновления статуса синхронизации",
+			"record_id", rec.ID,
+			"error", err)
+	}
+}
+
+// localRecordFor находит локальную копию серверной записи по UUID. Запись,
+// отправленная без ответа сервера, еще не знает свой серверный ID. По серверному
+// ID ищутся только записи, у которых на одной из сторон UUID еще нет: записи
+// прежних версий сервера и локальные копии, созданные до перехода на UUID.
+func (s *SyncService) localRecordFor(serverRec *LocalRecord) (*LocalRecord, error) {
+	return findLocalRecord(s.app.storage, serverRec)
+}
+
+// findLocalRecord - поиск localRecordFor для хранилища storage
+func findLocalRecord(storage Storage, serverRec *LocalRecord) (*LocalRecord, error) {
+	if serverRec.UUID != "" {
+		if rec, err := storage.GetRecordByUUID(serverRec.UUID); err == nil {
+			return rec, nil
+		}
+	}
+	rec, err := storage.GetRecordByServerID(serverRec.ServerID)
+	if err != nil {
+		return nil, err
+	}
+	if serverRec.UUID != "" && rec.UUID != "" {
+		// Локальная запись с другим UUID - это другая запись
+		return nil, fmt.Errorf("%w по uuid: %s", ErrRecordNotFound, serverRec.UUID)
+	}
+	return rec, nil
+}
+
+// keepsLocal сообщает, что локальная копия изменена позже серверной и
+// обновление с сервера к ней не применяется
+func keepsLocal(localRec, serverRec *LocalRecord) bool {
+	return !localRec.Synced && localRec.LastModified.After(serverRec.LastModified)
 }
 
 // applyServerChanges применяет изменения с сервера
-func (s *SyncService) applyServerChanges(_ context.Context, changes []*LocalRecord) (int, []SyncError) {
+func (s *SyncService) applyServerChanges(ctx context.Context, changes []*LocalRecord) (int, []SyncError) {
 	var errors []SyncError
 	downloaded := 0
 
 	for _, serverRec := range changes {
+		if ctx.Err() != nil {
+			break
+		}
 		// Получаем локальную версию записи
-		localRec, err := s.app.storage.GetRecordByServerID(serverRec.ServerID)
+		localRec, err := s.localRecordFor(serverRec)
 
 		if err != nil {
 			// Запись не существует локально, создаем новую
@@ -643,7 +966,7 @@ func (s *SyncService) applyServerChanges(_ context.Context, changes []*LocalReco
 		} else {
 			// Запись существует, обновляем
 			// Проверяем, не было ли локальных изменений
-			if !localRec.Synced && localRec.LastModified.After(serverRec.LastModified) {
+			if keepsLocal(localRec, serverRec) {
 				// Есть локальные изменения, пропускаем это обновление
 				// Конфликт уже должен был быть обработан
 				continue
@@ -651,6 +974,9 @@ func (s *SyncService) applyServerChanges(_ context.Context, changes []*LocalReco
 
 			// Обновляем локальную запись
 			serverRec.ID = localRec.ID // Сохраняем локальный ID
+			if serverRec.UUID == "" {
+				serverRec.UUID = localRec.UUID
+			}
 			serverRec.Synced = true
 			if err := s.app.storage.UpdateRecord(serverRec); err != nil {
 				errors = append(errors, SyncError{
@@ -670,14 +996,18 @@ func (s *SyncService) applyServerChanges(_ context.Context, changes []*LocalReco
 	return downloaded, errors
 }
 
-// updateSyncMetadata обновляет метаданные синхронизации
-func (s *SyncService) updateSyncMetadata(_ context.Context) error {
+// updateSyncMetadata сохраняет курсор, фильтр и ETag изменений из synced
+// вместе со временем синхронизации
+func (s *SyncService) updateSyncMetadata(_ context.Context, synced *SyncMetadata) error {
 	meta := &SyncMetadata{
-		ClientID:      s.app.config.ConfigDir,
+		ClientID:      s.clientID(),
 		LastSyncTime:  time.Now(),
+		Cursor:        synced.Cursor,
+		Filter:        synced.Filter,
+		ChangesETag:   synced.ChangesETag,
 		SyncVersion:   int64(s.stats.TotalSyncs + 1),
 		DeviceName:    getDeviceName(),
-		ClientVersion: "1.0.0",
+		ClientVersion: Version,
 	}
 
 	// Сохраняем метаданные
@@ -711,8 +1041,43 @@ func (s *SyncService) updateStats(result *SyncResult) {
 	s.saveStats()
 }
 
+// recordOutcome запоминает итог запуска синхронизации для gophkeeper status
+func (s *SyncService) recordOutcome(result *SyncResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.LastAttempt = result.StartTime
+	s.stats.LastSuccess = result.Success
+	s.stats.LastError = ""
+	switch {
+	case len(result.Errors) > 0:
+		s.stats.LastError = result.Errors[0].Operation + ": " + result.Errors[0].Error
+	case result.Paused != nil:
+		s.stats.LastError = result.Paused.Error()
+	}
+	s.saveStats()
+}
+
 // Вспомогательные методы для работы с файлами
 
+// loadSyncStats читает статистику прошлых запусков; без файла - пустая
+func loadSyncStats(app *App) *SyncStats {
+	data, err := app.stateCipher.ReadFile(app.config.ConfigDir + "/sync_stats.json")
+	if err != nil {
+		if !os.IsNotExist(err) {
+			app.log.Warn("Не удалось прочитать статистику синхронизации", "error", err)
+		}
+		return &SyncStats{}
+	}
+
+	var stats SyncStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		app.log.Warn("Не удалось разобрать статистику синхронизации", "error", err)
+		return &SyncStats{}
+	}
+	return &stats
+}
+
 func loadSyncConfig(configDir string) (*SyncConfig, error) {
 	configPath := configDir + "/sync_config.json"
 
@@ -740,7 +1105,7 @@ func (s *SyncService) loadSyncMetadata() (*SyncMetadata, error) {
 		return &SyncMetadata{}, nil
 	}
 
-	data, err := os.ReadFile(metaPath)
+	data, err := s.app.stateCipher.ReadFile(metaPath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка чтения метаданных: %w", err)
 	}
@@ -761,7 +1126,7 @@ func (s *SyncService) saveSyncMetadata(meta *SyncMetadata) error {
 		return fmt.Errorf("ошибка сериализации метаданных: %w", err)
 	}
 
-	if err := os.WriteFile(metaPath, data, 0600); err != nil {
+	if err := s.app.stateCipher.WriteFile(metaPath, data); err != nil {
 		return fmt.Errorf("ошибка записи метаданных: %w", err)
 	}
 
@@ -777,11 +1142,21 @@ func (s *SyncService) saveStats() {
 		return
 	}
 
-	if err := os.WriteFile(statsPath, data, 0600); err != nil {
+	if err := s.app.stateCipher.WriteFile(statsPath, data); err != nil {
 		s.log.Error("Ошибка записи статистики", "error", err)
 	}
 }
 
+// clientID - постоянный UUID устройства. Если его не удалось получить,
+// используется каталог конфигурации, как в прежних версиях.
+func (s *SyncService) clientID() string {
+	identity, err := s.app.DeviceIdentity()
+	if err != nil {
+		return s.app.config.ConfigDir
+	}
+	return identity.UUID
+}
+
 func getDeviceName() string {
 	hostname, err := os.Hostname()
 	if err != nil {
@@ -807,6 +1182,7 @@ func mergeConfigs(defaultConfig, userConfig *SyncConfig) {
 		defaultConfig.ConflictStrategy = userConfig.ConflictStrategy
 	}
 	defaultConfig.AutoResolve = userConfig.AutoResolve
+	defaultConfig.Filter = userConfig.Filter
 }
 
 // Методы управления синхронизацией
@@ -866,6 +1242,43 @@ func (s *SyncService) IsSyncing() bool {
 	return s.isSyncing
 }
 
+// ApplyUserSettings применяет настройки пользователя с сервера к конфигурации синхронизации.
+// Стратегия из локального sync_config.json имеет приоритет.
+func (s *SyncService) ApplyUserSettings(values map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.localStrategy {
+		return
+	}
+
+	if strategy, ok := values[settings.KeyConflictStrategy]; ok {
+		s.config.ConflictStrategy = strategy
+	} else {
+		s.config.ConflictStrategy = defaultConflictStrategy
+	}
+}
+
+// Filter возвращает действующий фильтр выборочной синхронизации
+func (s *SyncService) Filter() sync.Filter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config.Filter
+}
+
+// SetFilter заменяет фильтр из sync_config.json до завершения процесса
+// (флаги gophkeeper sync). Смена фильтра приводит к сверке индекса записей.
+func (s *SyncService) SetFilter(filter sync.Filter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.Filter = filter
+	return nil
+}
+
 // ResetStats сбрасывает статистику синхронизации
 func (s *SyncService) ResetStats() {
 	s.mu.Lock()
@@ -874,3 +1287,28 @@ func (s *SyncService) ResetStats() {
 	s.stats = &SyncStats{}
 	s.saveStats()
 }
+
+// Paused возвращает режим обслуживания сервера, если синхронизация приостановлена
+func (s *SyncService) Paused() *MaintenanceError {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.paused == nil || time.Now().After(s.paused.Until) {
+		return nil
+	}
+	return s.paused
+}
+
+// pause приостанавливает синхронизацию до окончания обслуживания сервера
+func (s *SyncService) pause(merr *MaintenanceError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.paused == nil || time.Now().After(s.paused.Until) {
+		s.log.Info("Сервер на обслуживании, синхронизация приостановлена",
+			"message", merr.Message,
+			"until", merr.Until.Format(time.RFC3339),
+		)
+	}
+	s.paused = merr
+}
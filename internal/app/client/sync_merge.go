@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gophkeeper/internal/domain/record"
+)
+
+// mergeConflict выполняет трехстороннее слияние по полям.
+// Обе версии расшифровываются и сравниваются с общим предком из истории версий
+// сервера. Если одно и то же поле изменено с обеих сторон по-разному,
+// конфликт остается неразрешенным и требует ручного вмешательства.
+func (s *SyncService) mergeConflict(ctx context.Context, conflict *LocalConflict) (*LocalConflict, error) {
+	resolved := *conflict
+	resolved.Resolved = false
+
+	// Удаление с одной из сторон нельзя объединить по полям
+	if conflict.LocalRecord.DeletedAt != nil || conflict.ServerRecord.DeletedAt != nil {
+		s.log.Debug("Слияние невозможно: запись удалена на одной из сторон", "record_id", conflict.RecordID)
+		return &resolved, nil
+	}
+
+	base, err := s.findCommonAncestor(ctx, conflict)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		s.log.Debug("Общий предок не найден, требуется ручное разрешение", "record_id", conflict.RecordID)
+		return &resolved, nil
+	}
+
+	var baseData, localData, serverData map[string]interface{}
+	if err := s.app.decryptRecordData(base.EncryptedData, &baseData); err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки общего предка: %w", err)
+	}
+	if err := s.app.decryptRecordData(conflict.LocalRecord.EncryptedData, &localData); err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки локальной версии: %w", err)
+	}
+	if err := s.app.decryptRecordData(conflict.ServerRecord.EncryptedData, &serverData); err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки серверной версии: %w", err)
+	}
+
+	mergedData, dataConflicts := mergeFields(baseData, localData, serverData)
+
+	mergedMeta := conflict.ServerRecord.Meta
+	var metaConflicts []string
+	var baseMeta, localMeta, serverMeta map[string]interface{}
+	if json.Unmarshal(withoutAnnotations(base.Meta), &baseMeta) == nil &&
+		json.Unmarshal(withoutAnnotations(conflict.LocalRecord.Meta), &localMeta) == nil &&
+		json.Unmarshal(withoutAnnotations(conflict.ServerRecord.Meta), &serverMeta) == nil {
+		var merged map[string]interface{}
+		merged, metaConflicts = mergeFields(baseMeta, localMeta, serverMeta)
+		if mergedMeta, err = json.Marshal(merged); err != nil {
+			return nil, fmt.Errorf("ошибка сериализации метаданных: %w", err)
+		}
+	}
+	// Пометки только дополняются, поэтому берутся с обеих сторон
+	if mergedMeta, err = keepAnnotations(conflict.LocalRecord.Meta, mergedMeta); err != nil {
+		return nil, fmt.Errorf("ошибка объединения пометок: %w", err)
+	}
+	if mergedMeta, err = keepAnnotations(conflict.ServerRecord.Meta, mergedMeta); err != nil {
+		return nil, fmt.Errorf("ошибка объединения пометок: %w", err)
+	}
+
+	if len(dataConflicts) > 0 || len(metaConflicts) > 0 {
+		s.log.Info("Поля изменены на обеих сторонах, требуется ручное разрешение",
+			"record_id", conflict.RecordID,
+			"data_fields", dataConflicts,
+			"meta_fields", metaConflicts)
+		return &resolved, nil
+	}
+
+	encrypted, err := s.app.encryptRecordData(mergedData)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования объединенной записи: %w", err)
+	}
+
+	// За основу берем локальную запись, чтобы сохранить локальный ID
+	merged := *conflict.LocalRecord
+	merged.EncryptedData = encrypted
+	merged.Meta = mergedMeta
+
+	resolved.Resolved = true
+	resolved.Resolution = "merged"
+	resolved.MergedRecord = &merged
+
+	return &resolved, nil
+}
+
+// findCommonAncestor ищет в истории версий сервера последнюю версию,
+// от которой произошли обе стороны конфликта
+func (s *SyncService) findCommonAncestor(ctx context.Context, conflict *LocalConflict) (*record.Version, error) {
+	versions, err := s.app.httpClient.GetRecordVersions(ctx, conflict.ServerRecord.ServerRef())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории версий: %w", err)
+	}
+
+	// Локальная правка увеличивает версию, поэтому предок строго меньше локальной версии
+	limit := min(conflict.LocalRecord.Version-1, conflict.ServerRecord.Version)
+
+	var ancestor *record.Version
+	for i := range versions {
+		v := &versions[i]
+		if v.Version > limit {
+			continue
+		}
+		if ancestor == nil || v.Version > ancestor.Version {
+			ancestor = v
+		}
+	}
+
+	return ancestor, nil
+}
+
+// mergeFields объединяет поля двух версий относительно общего предка.
+// Возвращает объединенный набор полей и список полей, измененных на обеих сторонах по-разному.
+func mergeFields(base, local, server map[string]interface{}) (map[string]interface{}, []string) {
+	keys := make(map[string]struct{})
+	for _, m := range []map[string]interface{}{base, local, server} {
+		for k := range m {
+			keys[k] = struct{}{}
+		}
+	}
+
+	merged := make(map[string]interface{}, len(keys))
+	var conflicts []string
+
+	for k := range keys {
+		b, inBase := base[k]
+		l, inLocal := local[k]
+		srv, inServer := server[k]
+
+		localChanged := inLocal != inBase || !reflect.DeepEqual(l, b)
+		serverChanged := inServer != inBase || !reflect.DeepEqual(srv, b)
+
+		switch {
+		case !localChanged:
+			if inServer {
+				merged[k] = srv
+			}
+		case !serverChanged:
+			if inLocal {
+				merged[k] = l
+			}
+		case inLocal == inServer && reflect.DeepEqual(l, srv):
+			// Обе стороны внесли одинаковое изменение
+			if inLocal {
+				merged[k] = l
+			}
+		default:
+			conflicts = append(conflicts, k)
+		}
+	}
+
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+func TestMergeFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          map[string]interface{}
+		local         map[string]interface{}
+		server        map[string]interface{}
+		want          map[string]interface{}
+		wantConflicts []string
+	}{
+		{
+			name:   "disjoint fields",
+			base:   map[string]interface{}{"login": "alice", "password": "old", "url": "a.example"},
+			local:  map[string]interface{}{"login": "alice", "password": "new", "url": "a.example"},
+			server: map[string]interface{}{"login": "alice", "password": "old", "url": "b.example"},
+			want:   map[string]interface{}{"login": "alice", "password": "new", "url": "b.example"},
+		},
+		{
+			name:          "same field changed differently",
+			base:          map[string]interface{}{"password": "old", "url": "a.example"},
+			local:         map[string]interface{}{"password": "local", "url": "a.example"},
+			server:        map[string]interface{}{"password": "server", "url": "b.example"},
+			want:          map[string]interface{}{"url": "b.example"},
+			wantConflicts: []string{"password"},
+		},
+		{
+			name:   "same field changed equally",
+			base:   map[string]interface{}{"password": "old"},
+			local:  map[string]interface{}{"password": "new"},
+			server: map[string]interface{}{"password": "new"},
+			want:   map[string]interface{}{"password": "new"},
+		},
+		{
+			name:   "field added on both sides",
+			base:   map[string]interface{}{"login": "alice"},
+			local:  map[string]interface{}{"login": "alice", "notes": "local"},
+			server: map[string]interface{}{"login": "alice", "otp": "server"},
+			want:   map[string]interface{}{"login": "alice", "notes": "local", "otp": "server"},
+		},
+		{
+			name:   "field deleted on one side",
+			base:   map[string]interface{}{"login": "alice", "notes": "old"},
+			local:  map[string]interface{}{"login": "alice"},
+			server: map[string]interface{}{"login": "bob", "notes": "old"},
+			want:   map[string]interface{}{"login": "bob"},
+		},
+		{
+			name:   "field deleted on both sides",
+			base:   map[string]interface{}{"login": "alice", "notes": "old"},
+			local:  map[string]interface{}{"login": "alice"},
+			server: map[string]interface{}{"login": "alice"},
+			want:   map[string]interface{}{"login": "alice"},
+		},
+		{
+			name:          "deleted on one side, changed on the other",
+			base:          map[string]interface{}{"notes": "old", "tags": []interface{}{"a"}},
+			local:         map[string]interface{}{"tags": []interface{}{"a"}},
+			server:        map[string]interface{}{"notes": "changed", "tags": []interface{}{"a", "b"}},
+			want:          map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			wantConflicts: []string{"notes"},
+		},
+		{
+			name:          "conflicts are sorted",
+			base:          map[string]interface{}{},
+			local:         map[string]interface{}{"z": 1.0, "a": 1.0},
+			server:        map[string]interface{}{"z": 2.0, "a": 2.0},
+			want:          map[string]interface{}{},
+			wantConflicts: []string{"a", "z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := mergeFields(tt.base, tt.local, tt.server)
+			assert.Equal(t, tt.want, merged)
+			assert.Equal(t, tt.wantConflicts, conflicts)
+		})
+	}
+}
+
+// versionsHandler отдает историю версий записи как GET /api/records/{id}/versions
+func versionsHandler(t *testing.T, versions []record.Version) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/records/7/versions", r.URL.Path)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "versions": versions})
+	}
+}
+
+func TestSyncService_FindCommonAncestor(t *testing.T) {
+	history := []record.Version{{Version: 3}, {Version: 1}, {Version: 5}, {Version: 2}}
+
+	tests := []struct {
+		name          string
+		versions      []record.Version
+		localVersion  int
+		serverVersion int
+		want          int // 0 - предок не найден
+	}{
+		{name: "latest version older than both sides", versions: history, localVersion: 4, serverVersion: 6, want: 3},
+		{name: "local edit of the server version", versions: history, localVersion: 6, serverVersion: 5, want: 5},
+		{name: "server behind local", versions: history, localVersion: 6, serverVersion: 2, want: 2},
+		{name: "gap in history", versions: []record.Version{{Version: 1}, {Version: 4}}, localVersion: 4, serverVersion: 5, want: 1},
+		{name: "no old enough version", versions: history, localVersion: 1, serverVersion: 4, want: 0},
+		{name: "empty history", versions: nil, localVersion: 3, serverVersion: 3, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSyncService(t, versionsHandler(t, tt.versions))
+			conflict := &LocalConflict{
+				LocalRecord:  &LocalRecord{ID: 1, Version: tt.localVersion},
+				ServerRecord: &LocalRecord{ServerID: 7, Version: tt.serverVersion},
+			}
+
+			ancestor, err := s.findCommonAncestor(context.Background(), conflict)
+			require.NoError(t, err)
+			if tt.want == 0 {
+				assert.Nil(t, ancestor)
+				return
+			}
+			require.NotNil(t, ancestor)
+			assert.Equal(t, tt.want, ancestor.Version)
+		})
+	}
+}
+
+func TestSyncService_MergeConflict(t *testing.T) {
+	var versions []record.Version
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		versionsHandler(t, versions)(w, r)
+	})
+	s.app.encryptor = crypto.NewRecordEncryptor(s.app.crypto)
+	require.NoError(t, s.app.InitMasterKey("master-password"))
+
+	encrypt := func(data map[string]interface{}) string {
+		encrypted, err := s.app.encryptRecordData(data)
+		require.NoError(t, err)
+		return encrypted
+	}
+	base := encrypt(map[string]interface{}{"login": "alice", "password": "old", "url": "a.example"})
+	versions = []record.Version{{RecordID: 7, Version: 1, EncryptedData: base, Meta: json.RawMessage(`{"title":"Mail"}`)}}
+
+	newConflict := func(local, server map[string]interface{}) *LocalConflict {
+		return &LocalConflict{
+			Conflict: sync.Conflict{RecordID: 7},
+			LocalRecord: &LocalRecord{ID: 1, Version: 2, EncryptedData: encrypt(local),
+				Meta: json.RawMessage(`{"title":"Mail"}`)},
+			ServerRecord: &LocalRecord{ServerID: 7, Version: 2, EncryptedData: encrypt(server),
+				Meta: json.RawMessage(`{"title":"Work mail"}`)},
+		}
+	}
+
+	t.Run("disjoint changes are merged", func(t *testing.T) {
+		conflict := newConflict(
+			map[string]interface{}{"login": "alice", "password": "new", "url": "a.example"},
+			map[string]interface{}{"login": "alice", "password": "old", "url": "b.example"},
+		)
+
+		resolved, err := s.mergeConflict(context.Background(), conflict)
+		require.NoError(t, err)
+		require.True(t, resolved.Resolved)
+		assert.Equal(t, "merged", resolved.Resolution)
+		assert.Equal(t, 1, resolved.MergedRecord.ID, "локальный ID сохраняется")
+		assert.JSONEq(t, `{"title":"Work mail"}`, string(resolved.MergedRecord.Meta))
+
+		var data map[string]interface{}
+		require.NoError(t, s.app.decryptRecordData(resolved.MergedRecord.EncryptedData, &data))
+		assert.Equal(t, map[string]interface{}{"login": "alice", "password": "new", "url": "b.example"}, data)
+	})
+
+	t.Run("same field left for manual resolution", func(t *testing.T) {
+		conflict := newConflict(
+			map[string]interface{}{"login": "alice", "password": "local", "url": "a.example"},
+			map[string]interface{}{"login": "alice", "password": "server", "url": "a.example"},
+		)
+
+		resolved, err := s.mergeConflict(context.Background(), conflict)
+		require.NoError(t, err)
+		assert.False(t, resolved.Resolved)
+		assert.Nil(t, resolved.MergedRecord)
+	})
+
+	t.Run("deleted record is not merged", func(t *testing.T) {
+		conflict := newConflict(map[string]interface{}{"login": "alice"}, map[string]interface{}{"login": "bob"})
+		deletedAt := time.Now()
+		conflict.ServerRecord.DeletedAt = &deletedAt
+
+		resolved, err := s.mergeConflict(context.Background(), conflict)
+		require.NoError(t, err)
+		assert.False(t, resolved.Resolved)
+	})
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gophkeeper/internal/domain/sync"
+)
+
+// Без курсора синхронизации (переустановка, удаленный sync_metadata.json)
+// сервер отдал бы все записи заново. Если локально уже есть записи с сервера,
+// клиент сначала отправляет их индекс (ID, версия, контрольная сумма) и
+// загружает только те записи, которые отличаются.
+
+// localIndex возвращает индекс записей, которые уже были на сервере и
+// подходят под filter
+func (s *SyncService) localIndex(filter sync.Filter) (map[int]*LocalRecord, []sync.RecordDigest, error) {
+	records, err := s.app.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("ошибка чтения локальных записей: %w", err)
+	}
+
+	byServerID := make(map[int]*LocalRecord)
+	var digests []sync.RecordDigest
+	for _, rec := range records {
+		if rec.ServerID == 0 || !filter.Matches(string(rec.Type), rec.Meta) {
+			continue
+		}
+		byServerID[rec.ServerID] = rec
+		digests = append(digests, sync.RecordDigest{
+			ID:       rec.ServerID,
+			Version:  rec.Version,
+			Checksum: rec.Checksum,
+			Deleted:  rec.DeletedAt != nil,
+		})
+	}
+
+	return byServerID, digests, nil
+}
+
+// negotiateServerChanges сверяет индекс с сервером и загружает только
+// различающиеся записи вместе с курсором на момент сверки. Возвращает false,
+// если сверка не нужна или сервер ее не поддерживает - тогда изменения
+// загружаются обычным способом.
+func (s *SyncService) negotiateServerChanges(ctx context.Context, filter sync.Filter) ([]*LocalRecord, string, bool, error) {
+	local, digests, err := s.localIndex(filter)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(digests) == 0 {
+		return nil, "", false, nil
+	}
+
+	req := sync.NegotiateRequest{Records: digests}
+	if !filter.IsEmpty() {
+		req.Filter = &filter
+	}
+	response, err := s.app.httpClient.NegotiateSync(ctx, req)
+	if err != nil {
+		s.log.Debug("Сверка индекса недоступна, загружаем все изменения", "error", err)
+		return nil, "", false, nil
+	}
+
+	s.log.Info("Сверка индекса записей",
+		"unchanged", response.Unchanged,
+		"changed", len(response.Changed),
+		"missing", len(response.Missing),
+		"deleted", len(response.Deleted),
+	)
+
+	var records []*LocalRecord
+	for _, ids := range [][]int{response.Changed, response.Missing} {
+		for _, id := range ids {
+			serverRec, err := s.app.httpClient.GetRecord(ctx, strconv.Itoa(id))
+			if err != nil {
+				return nil, "", true, fmt.Errorf("ошибка загрузки записи %d: %w", id, err)
+			}
+			rec := FromServerRecord(serverRec)
+			if !filter.Matches(string(rec.Type), rec.Meta) {
+				continue
+			}
+			records = append(records, rec)
+		}
+	}
+
+	now := time.Now()
+	for _, id := range response.Deleted {
+		rec, ok := local[id]
+		if !ok {
+			continue
+		}
+		deleted := *rec
+		deleted.DeletedAt = &now
+		deleted.LastModified = now
+		deleted.Synced = true
+		records = append(records, &deleted)
+	}
+
+	return records, response.Cursor, true, nil
+}
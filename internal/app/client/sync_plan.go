@@ -0,0 +1,167 @@
+// internal/app/client/sync_plan.go
+package client
+
+import (
+	"context"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Действия с записью в плане синхронизации
+const (
+	PlanCreate = "create"
+	PlanUpdate = "update"
+	PlanDelete = "delete"
+)
+
+// PlannedChange - запись, которую синхронизация отправила бы на сервер или
+// загрузила с него
+type PlannedChange struct {
+	// RecordID - локальный ID; 0 у записи, которой еще нет на устройстве
+	RecordID int `json:"record_id,omitempty"`
+	// ServerID - ID на сервере; 0 у записи, которой еще нет на сервере
+	ServerID int            `json:"server_id,omitempty"`
+	Type     record.RecType `json:"type"`
+	Title    string         `json:"title,omitempty"`
+	// Action - PlanCreate, PlanUpdate или PlanDelete
+	Action  string `json:"action"`
+	Version int    `json:"version"`
+}
+
+// PlannedConflict - конфликт и то, как его разрешила бы текущая стратегия
+type PlannedConflict struct {
+	sync.Conflict
+	// Resolution - client, server или merged; пустая, если нужно ручное
+	// разрешение
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// SyncPlan - что сделала бы синхронизация (gophkeeper sync --dry-run)
+type SyncPlan struct {
+	Upload    []PlannedChange   `json:"upload"`
+	Download  []PlannedChange   `json:"download"`
+	Conflicts []PlannedConflict `json:"conflicts"`
+	// Strategy - стратегия разрешения конфликтов
+	Strategy string      `json:"strategy"`
+	Filter   sync.Filter `json:"filter"`
+	// Errors - шаги, которые не удалось выполнить: план по ним неполный
+	Errors    []SyncError `json:"errors"`
+	StartTime time.Time   `json:"start_time"`
+	EndTime   time.Time   `json:"end_time"`
+}
+
+// Plan выполняет синхронизацию вхолостую: находит изменения обеих сторон и
+// конфликты, но ничего не применяет. Локальная база, курсор, статистика и
+// ключ не меняются, на сервер ничего не отправляется; устройство не
+// регистрируется, поэтому неподтвержденному устройству сервер откажет.
+func (s *SyncService) Plan(ctx context.Context) (*SyncPlan, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+
+	if err := s.preSyncChecks(ctx); err != nil {
+		return nil, err
+	}
+	s.selectProtocol(ctx)
+	// Стратегия из настроек пользователя определяет разрешение конфликтов
+	if values, err := s.app.httpClient.GetSettings(ctx); err != nil {
+		s.log.Warn("Не удалось получить настройки пользователя", "error", err)
+	} else {
+		s.ApplyUserSettings(values)
+	}
+
+	plan := &SyncPlan{
+		Upload:    []PlannedChange{},
+		Download:  []PlannedChange{},
+		Conflicts: []PlannedConflict{},
+		StartTime: time.Now(),
+	}
+	changes, err := s.collectChanges(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	plan.Strategy = s.config.ConflictStrategy
+	s.mu.RUnlock()
+	plan.Filter = changes.filter
+	plan.Errors = append([]SyncError{}, changes.errors...)
+
+	for _, rec := range changes.local {
+		plan.Upload = append(plan.Upload, plannedUpload(rec))
+	}
+	for _, serverRec := range changes.server {
+		if change, ok := s.plannedDownload(serverRec); ok {
+			plan.Download = append(plan.Download, change)
+		}
+	}
+	for _, conflict := range changes.conflicts {
+		planned := PlannedConflict{Conflict: conflict.Conflict}
+		resolved, err := s.autoResolveConflict(ctx, conflict)
+		if err != nil {
+			plan.Errors = append(plan.Errors, SyncError{
+				RecordID:  conflict.RecordID,
+				Error:     err.Error(),
+				Operation: "resolve_conflicts",
+				Timestamp: time.Now(),
+			})
+		} else if resolved.Resolved {
+			planned.Resolution = resolved.Resolution
+		}
+		plan.Conflicts = append(plan.Conflicts, planned)
+	}
+
+	plan.EndTime = time.Now()
+	s.log.Info("План синхронизации",
+		"upload", len(plan.Upload),
+		"download", len(plan.Download),
+		"conflicts", len(plan.Conflicts),
+		"errors", len(plan.Errors),
+	)
+	return plan, nil
+}
+
+// plannedUpload описывает отправку локального изменения
+func plannedUpload(rec *LocalRecord) PlannedChange {
+	change := PlannedChange{
+		RecordID: rec.ID,
+		ServerID: rec.ServerID,
+		Type:     rec.Type,
+		Title:    sync.TitleFromMeta(rec.Meta),
+		Action:   PlanUpdate,
+		Version:  rec.Version,
+	}
+	switch {
+	case rec.DeletedAt != nil:
+		change.Action = PlanDelete
+	case rec.ServerID == 0:
+		change.Action = PlanCreate
+	}
+	return change
+}
+
+// plannedDownload описывает применение изменения с сервера так же, как
+// applyServerChanges; false - изменение не применялось бы
+func (s *SyncService) plannedDownload(serverRec *LocalRecord) (PlannedChange, bool) {
+	change := PlannedChange{
+		ServerID: serverRec.ServerID,
+		Type:     serverRec.Type,
+		Title:    sync.TitleFromMeta(serverRec.Meta),
+		Action:   PlanCreate,
+		Version:  serverRec.Version,
+	}
+	if localRec, err := s.localRecordFor(serverRec); err == nil {
+		if keepsLocal(localRec, serverRec) {
+			return change, false
+		}
+		change.RecordID = localRec.ID
+		change.Action = PlanUpdate
+	}
+	if serverRec.DeletedAt != nil {
+		change.Action = PlanDelete
+	}
+	return change, true
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+func TestSyncService_Plan(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	var paths []string
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v1/health":
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case "/api/sync/changes":
+			_ = json.NewEncoder(w).Encode(sync.GetChangesResponse{Status: "Ok", NextCursor: "c1", Records: []sync.RecordSync{
+				{ID: 5, Type: "text", Meta: []byte(`{"title":"Edited"}`), Version: 2, LastModified: now},
+				{ID: 6, Type: "text", Meta: []byte(`{"title":"Stale"}`), Version: 2, LastModified: now},
+				{ID: 7, Type: "login", Meta: []byte(`{"title":"Remote"}`), Version: 1, LastModified: now},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	app := s.app
+	require.NoError(t, app.InitMasterKey("password123"))
+	require.NoError(t, app.loggedIn("alice", "token"))
+	device := `{"uuid":"0f8fad5b-d9cb-469f-a165-70867728950e","server_id":3,"name":"laptop","status":"approved"}`
+	require.NoError(t, os.WriteFile(app.deviceFilePath(), []byte(device), 0600))
+
+	created := &LocalRecord{Type: record.RecTypeText, EncryptedData: "00", Meta: json.RawMessage(`{"title":"New"}`), LastModified: now}
+	edited := &LocalRecord{ServerID: 5, Type: record.RecTypeText, EncryptedData: "01", Meta: json.RawMessage(`{"title":"Edited"}`), Version: 1, LastModified: now.Add(-time.Hour)}
+	stale := &LocalRecord{ServerID: 6, Type: record.RecTypeText, EncryptedData: "02", Meta: json.RawMessage(`{"title":"Stale"}`), Version: 1, Synced: true}
+	for _, rec := range []*LocalRecord{created, edited, stale} {
+		require.NoError(t, app.storage.SaveRecord(rec))
+	}
+
+	plan, err := s.Plan(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, plan.Errors)
+	assert.Equal(t, defaultConflictStrategy, plan.Strategy)
+
+	assert.ElementsMatch(t, []PlannedChange{
+		{RecordID: created.ID, Type: record.RecTypeText, Title: "New", Action: PlanCreate},
+		{RecordID: edited.ID, ServerID: 5, Type: record.RecTypeText, Title: "Edited", Action: PlanUpdate, Version: 1},
+	}, plan.Upload)
+	assert.ElementsMatch(t, []PlannedChange{
+		{RecordID: edited.ID, ServerID: 5, Type: record.RecTypeText, Title: "Edited", Action: PlanUpdate, Version: 2},
+		{RecordID: stale.ID, ServerID: 6, Type: record.RecTypeText, Title: "Stale", Action: PlanUpdate, Version: 2},
+		{ServerID: 7, Type: record.RecTypeLogin, Title: "Remote", Action: PlanCreate, Version: 1},
+	}, plan.Download)
+
+	require.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, 5, plan.Conflicts[0].RecordID)
+	assert.Equal(t, "edit-edit", plan.Conflicts[0].ConflictType)
+	assert.Equal(t, "server", plan.Conflicts[0].Resolution, "серверная версия новее")
+
+	// Ничего не записано: ни на сервер, ни локально
+	for _, p := range paths {
+		assert.NotContains(t, []string{"POST /api/sync/batch", "POST /api/devices", "PUT /api/keyfile"}, p)
+	}
+	records, err := app.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+	got, err := app.storage.GetRecord(edited.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Synced)
+	assert.Equal(t, 1, got.Version)
+
+	_, err = os.Stat(filepath.Join(app.config.ConfigDir, "sync_metadata.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "курсор не сдвигается")
+	assert.Zero(t, s.GetStats().TotalSyncs)
+	assert.True(t, s.GetStats().LastAttempt.IsZero())
+}
@@ -0,0 +1,94 @@
+// internal/app/client/sync_protocol.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"gophkeeper/internal/domain/sync"
+)
+
+// Протокол синхронизации выбирает сервер: новые версии протокола включаются
+// части аккаунтов и могут быть откачены. Клиент читает протокол из
+// /api/sync/capabilities перед каждой синхронизацией; сервер без поддержки
+// протоколов обслуживается по v1.
+
+// GetSyncCapabilities возвращает параметры синхронизации пользователя
+func (h *httpClient) GetSyncCapabilities(ctx context.Context) (*sync.Capabilities, error) {
+	resp, err := h.doRequest(ctx, "GET", "/api/sync/capabilities", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var result sync.GetCapabilitiesResponse
+	if err := h.parseResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if result.Data == nil {
+		return nil, fmt.Errorf("server error: %s", result.Error)
+	}
+
+	return result.Data, nil
+}
+
+// SyncProtocol возвращает протокол, по которому клиент синхронизируется
+func (h *httpClient) SyncProtocol() sync.Protocol {
+	if h.syncV2.Load() {
+		return sync.ProtocolV2
+	}
+	return sync.ProtocolV1
+}
+
+// SetSyncProtocol переключает операции синхронизации на протокол p.
+// Неизвестный клиенту протокол означает v1.
+func (h *httpClient) SetSyncProtocol(p sync.Protocol) {
+	h.syncV2.Store(p == sync.ProtocolV2)
+}
+
+// syncPath возвращает путь операции синхронизации для протокола
+func syncPath(p sync.Protocol, op string) string {
+	if p == sync.ProtocolV2 {
+		return "/api/v2/sync/" + op
+	}
+	return "/api/sync/" + op
+}
+
+// doSync выполняет операцию синхронизации по текущему протоколу. Если
+// пользователя откатили на другой протокол после его выбора, сервер отвечает
+// 409 с действующим протоколом в X-Sync-Protocol: клиент переключается и
+// повторяет запрос.
+func (h *httpClient) doSync(op string, send func(path string) (*http.Response, error)) (*http.Response, error) {
+	current := h.SyncProtocol()
+	resp, err := send(syncPath(current, op))
+	if err != nil || resp.StatusCode != http.StatusConflict {
+		return resp, err
+	}
+
+	p := sync.Protocol(resp.Header.Get(sync.ProtocolHeader))
+	if !p.Valid() || p == current {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	h.log.Info("Сервер сменил протокол синхронизации", "from", current, "to", p)
+	h.SetSyncProtocol(p)
+	return send(syncPath(p, op))
+}
+
+// selectProtocol выбирает протокол синхронизации по параметрам сервера
+func (s *SyncService) selectProtocol(ctx context.Context) {
+	p := sync.ProtocolV1
+	caps, err := s.app.httpClient.GetSyncCapabilities(ctx)
+	if err != nil {
+		s.log.Debug("Не удалось получить параметры синхронизации, используется v1", "error", err)
+	} else if caps.Protocol.Valid() {
+		p = caps.Protocol
+	}
+
+	if p != s.app.httpClient.SyncProtocol() {
+		s.log.Info("Протокол синхронизации", "protocol", p)
+	}
+	s.app.httpClient.SetSyncProtocol(p)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/sync"
+)
+
+// rolloutServer - сервер с протоколом пользователя, который можно сменить
+// между запросами, как при раскатке и откате v2
+type rolloutServer struct {
+	mu       gosync.Mutex
+	protocol sync.Protocol
+	paths    []string
+}
+
+func (s *rolloutServer) setProtocol(p sync.Protocol) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protocol = p
+}
+
+func (s *rolloutServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths = append(s.paths, r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/api/sync/capabilities":
+		_ = json.NewEncoder(w).Encode(sync.GetCapabilitiesResponse{
+			Status: "Ok",
+			Data:   &sync.Capabilities{BatchSize: 100, Protocol: s.protocol, Protocols: sync.Protocols},
+		})
+	case "/api/v2/sync/batch":
+		if s.protocol != sync.ProtocolV2 {
+			w.Header().Set(sync.ProtocolHeader, string(s.protocol))
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]string{"detail": sync.ErrProtocolDisabled.Error()})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(sync.BatchSyncResponse{Status: "Ok"})
+	case "/api/sync/batch":
+		_ = json.NewEncoder(w).Encode(sync.BatchSyncResponse{Status: "Ok"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSyncService_SelectProtocol(t *testing.T) {
+	ctx := context.Background()
+	srv := &rolloutServer{protocol: sync.ProtocolV2}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	app := newTestApp(t)
+	app.httpClient.baseURL = ts.URL
+	s := NewSyncService(app)
+
+	s.selectProtocol(ctx)
+	assert.Equal(t, sync.ProtocolV2, app.httpClient.SyncProtocol())
+	_, err := app.httpClient.SendBatchSync(ctx, sync.BatchSyncRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/sync/batch", srv.paths[len(srv.paths)-1])
+
+	t.Run("rollback during sync", func(t *testing.T) {
+		srv.setProtocol(sync.ProtocolV1)
+
+		_, err := app.httpClient.SendBatchSync(ctx, sync.BatchSyncRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/api/v2/sync/batch", "/api/sync/batch"}, srv.paths[len(srv.paths)-2:])
+		assert.Equal(t, sync.ProtocolV1, app.httpClient.SyncProtocol())
+	})
+
+	t.Run("server without protocols", func(t *testing.T) {
+		app.httpClient.SetSyncProtocol(sync.ProtocolV2)
+		old := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(old.Close)
+		app.httpClient.baseURL = old.URL
+
+		s.selectProtocol(ctx)
+		assert.Equal(t, sync.ProtocolV1, app.httpClient.SyncProtocol())
+	})
+}
@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// newTestSyncService возвращает сервис синхронизации, который ходит на handler
+func newTestSyncService(t *testing.T, handler http.HandlerFunc) *SyncService {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.httpClient.baseURL = srv.URL
+
+	s := NewSyncService(app)
+	s.config.BatchSize = 2
+	return s
+}
+
+func TestSyncService_GetServerChanges_Pages(t *testing.T) {
+	var cursors []string
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sync.GetChangesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		cursors = append(cursors, req.Cursor)
+
+		resp := sync.GetChangesResponse{Status: "Ok"}
+		switch req.Cursor {
+		case "":
+			resp.Records = []sync.RecordSync{{ID: 1, Type: "text"}, {ID: 2, Type: "text"}}
+			resp.HasMore = true
+			resp.NextCursor = "c1"
+		case "c1":
+			resp.Records = []sync.RecordSync{{ID: 3, Type: "text"}}
+			resp.NextCursor = "c2"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	records, cursor, _, err := s.getServerChanges(context.Background(), &SyncMetadata{}, sync.Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "c1"}, cursors)
+	assert.Equal(t, "c2", cursor)
+	require.Len(t, records, 3)
+	assert.Equal(t, 3, records[2].ServerID)
+	assert.True(t, records[2].Synced)
+}
+
+func TestSyncService_GetServerChanges_Filter(t *testing.T) {
+	filter := sync.Filter{Types: []string{"login"}, ExcludeTags: []string{"archive"}}
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sync.GetChangesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotNil(t, req.Filter)
+		assert.True(t, filter.Equal(*req.Filter))
+
+		// Сервер прежней версии фильтр не применяет
+		resp := sync.GetChangesResponse{Status: "Ok", Records: []sync.RecordSync{
+			{ID: 1, Type: "login", Meta: []byte(`{"tags":["work"]}`)},
+			{ID: 2, Type: "binary"},
+			{ID: 3, Type: "login", Meta: []byte(`{"tags":["archive"]}`)},
+		}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	records, _, _, err := s.getServerChanges(context.Background(), &SyncMetadata{}, filter)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, 1, records[0].ServerID)
+}
+
+func TestSyncService_GetServerChanges_NotModified(t *testing.T) {
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"e1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"e1"`)
+		_ = json.NewEncoder(w).Encode(sync.GetChangesResponse{Status: "Ok", NextCursor: "c1"})
+	})
+
+	records, cursor, etag, err := s.getServerChanges(context.Background(), &SyncMetadata{Cursor: "c1"}, sync.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Equal(t, "c1", cursor)
+	assert.Equal(t, `"e1"`, etag)
+
+	records, cursor, etag, err = s.getServerChanges(context.Background(), &SyncMetadata{Cursor: "c1", ChangesETag: etag}, sync.Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Equal(t, "c1", cursor)
+	assert.Equal(t, `"e1"`, etag)
+}
+
+func TestHTTPClient_CompressionAndConditionalList(t *testing.T) {
+	list := record.ListResponse{Records: []record.Item{{ID: 1, Type: record.RecTypeText}}, Total: 1}
+	var requests, notModified int
+	var encodings []string
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Accept-Encoding", "gzip")
+		if r.Method == http.MethodPost {
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			_ = json.NewEncoder(w).Encode(sync.BatchSyncResponse{Status: "Ok"})
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	h := s.app.httpClient
+	ctx := context.Background()
+
+	for range 2 {
+		got, err := h.ListRecords(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, list.Records[0].ID, got.Records[0].ID)
+	}
+	assert.Equal(t, 1, notModified)
+
+	// После Accept-Encoding: gzip крупные тела запросов сжимаются
+	big := sync.BatchSyncRequest{Records: []sync.RecordSync{{EncryptedData: strings.Repeat("00", gzipMinSize)}}}
+	_, err := h.SendBatchSync(ctx, big)
+	require.NoError(t, err)
+	_, err = h.SendBatchSync(ctx, sync.BatchSyncRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gzip", ""}, encodings)
+	assert.Equal(t, 4, requests)
+}
+
+func TestSQLiteStorage_GetRecordsModifiedAfter_Filter(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "records.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	for _, rec := range []*LocalRecord{
+		{Type: record.RecTypeLogin, Meta: json.RawMessage(`{"tags":["work"]}`)},
+		{Type: record.RecTypeLogin, Meta: json.RawMessage(`{"tags":["archive"]}`)},
+		{Type: record.RecTypeBinary, Meta: json.RawMessage(`{}`)},
+		{Type: record.RecTypeText},
+	} {
+		rec.EncryptedData = "00"
+		rec.Version = 1
+		rec.LastModified = time.Now()
+		require.NoError(t, storage.SaveRecord(rec))
+	}
+
+	for _, tt := range []struct {
+		filter sync.Filter
+		want   int
+	}{
+		{filter: sync.Filter{}, want: 4},
+		{filter: sync.Filter{Types: []string{"login", "text"}}, want: 3},
+		{filter: sync.Filter{ExcludeTags: []string{"archive"}}, want: 3},
+		{filter: sync.Filter{Tags: []string{"work"}}, want: 1},
+	} {
+		records, err := storage.GetRecordsModifiedAfter(time.Time{}, 10, tt.filter)
+		require.NoError(t, err)
+		assert.Len(t, records, tt.want, "%+v", tt.filter)
+	}
+}
+
+func TestSQLiteStorage_GetRecordByUUID(t *testing.T) {
+	storage, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "records.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	rec := &LocalRecord{Type: record.RecTypeText, EncryptedData: "00", Version: 1, LastModified: time.Now()}
+	require.NoError(t, storage.SaveRecord(rec))
+	require.NotEmpty(t, rec.UUID, "новая запись получает UUID")
+
+	got, err := storage.GetRecordByUUID(rec.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, rec.ID, got.ID)
+	assert.Equal(t, rec.UUID, got.UUID)
+
+	_, err = storage.GetRecordByUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestSyncService_UploadChanges_Batches(t *testing.T) {
+	var batches [][]sync.RecordSync
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {
+		var req sync.BatchSyncRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		batches = append(batches, req.Records)
+
+		resp := sync.BatchSyncResponse{Status: "Ok"}
+		if len(batches) == 1 {
+			resp.Results = []sync.BatchRecordResult{
+				{Index: 0, Status: sync.BatchRecordSaved, ID: 10, Version: 1},
+				{Index: 1, Status: sync.BatchRecordFailed, Error: "failed to save record"},
+			}
+		} else {
+			resp.Results = []sync.BatchRecordResult{{Index: 0, Status: sync.BatchRecordSaved, ID: 12, Version: 1}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+
+	var changes []*LocalRecord
+	for i := 0; i < 3; i++ {
+		rec := &LocalRecord{Type: record.RecTypeText, EncryptedData: "00", Version: 1, LastModified: time.Now()}
+		require.NoError(t, s.app.storage.SaveRecord(rec))
+		changes = append(changes, rec)
+	}
+
+	uploaded, errs := s.uploadChanges(context.Background(), changes)
+	assert.Len(t, batches, 2, "записи отправляются пакетами по BatchSize")
+	assert.Equal(t, 2, uploaded)
+	require.Len(t, errs, 1)
+	assert.Equal(t, changes[1].ID, errs[0].RecordID)
+
+	first, err := s.app.storage.GetRecord(changes[0].ID)
+	require.NoError(t, err)
+	assert.True(t, first.Synced)
+	assert.Equal(t, 10, first.ServerID)
+
+	failed, err := s.app.storage.GetRecord(changes[1].ID)
+	require.NoError(t, err)
+	assert.False(t, failed.Synced, "несохраненная запись уйдет при следующей синхронизации")
+	assert.Zero(t, failed.ServerID)
+}
+
+func TestSyncService_ApplyServerChanges_MatchesByUUID(t *testing.T) {
+	s := newTestSyncService(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	// Запись отправлена, но ответ с серверным ID не дошел
+	pending := &LocalRecord{Type: record.RecTypeText, EncryptedData: "00", Version: 1, LastModified: time.Now().Add(-time.Hour)}
+	require.NoError(t, s.app.storage.SaveRecord(pending))
+	require.NotEmpty(t, pending.UUID)
+
+	// Запись прежнего клиента без серверного UUID
+	legacy := &LocalRecord{ServerID: 20, Type: record.RecTypeText, EncryptedData: "01", Version: 1, Synced: true}
+	require.NoError(t, s.app.storage.SaveRecord(legacy))
+
+	changes := []*LocalRecord{
+		fromSyncRecord(sync.RecordSync{ID: 10, UUID: pending.UUID, Type: "text", EncryptedData: "00", Version: 1, LastModified: time.Now()}),
+		fromSyncRecord(sync.RecordSync{ID: 20, UUID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Type: "text", EncryptedData: "02", Version: 2, LastModified: time.Now()}),
+	}
+	downloaded, errs := s.applyServerChanges(context.Background(), changes)
+	require.Empty(t, errs)
+	assert.Equal(t, 2, downloaded)
+
+	records, err := s.app.storage.ListRecords(&RecordFilter{})
+	require.NoError(t, err)
+	assert.Len(t, records, 2, "повторно отправленная запись не дублируется")
+
+	got, err := s.app.storage.GetRecord(pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, got.ServerID)
+	assert.True(t, got.Synced)
+
+	got, err = s.app.storage.GetRecord(legacy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", got.UUID, "запись получает серверный UUID")
+	assert.Equal(t, 2, got.Version)
+}
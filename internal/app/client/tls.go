@@ -0,0 +1,104 @@
+// internal/app/client/tls.go
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gophkeeper/internal/app/client/config"
+)
+
+// Настройки TLS клиента. Сертификат сервера проверяется по системным УЦ и
+// набору из ca_cert_path. Если заданы tls_pins, цепочка сервера должна
+// содержать сертификат с одним из закрепленных ключей: подмена сертификата
+// другим доверенным УЦ не пройдет. tls_client_cert и tls_client_key
+// предъявляются серверу, требующему сертификат клиента.
+
+// PinMismatchError - ни один сертификат в цепочке сервера не совпал
+// с закрепленными ключами
+type PinMismatchError struct {
+	Host string
+	// Got - ключи цепочки сервера, начиная с его собственного сертификата
+	Got []string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf("ключ сертификата сервера %s не совпадает с закрепленным в tls_pins (получено: %s). "+
+		"Если сертификат сервера заменен намеренно, обновите tls_pins", e.Host, strings.Join(e.Got, ", "))
+}
+
+// SPKIPin возвращает закрепляемый ключ сертификата: SHA-256 его
+// SubjectPublicKeyInfo в виде sha256/<base64>
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return config.PinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newTLSConfig собирает настройки TLS клиента; nil - настройки по умолчанию
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if !cfg.EnableTLS {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CACertPath != "" {
+		pem, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения ca_cert_path: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_cert_path %s: нет сертификатов в формате PEM", cfg.CACertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSClientCert, cfg.TLSClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки сертификата клиента: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(cfg.TLSPins) > 0 {
+		pins := slices.Clone(cfg.TLSPins)
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPins(cs, pins)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyPins проверяет, что в проверенной цепочке сервера есть сертификат
+// с закрепленным ключом. Вызывается после стандартной проверки цепочки.
+func verifyPins(cs tls.ConnectionState, pins []string) error {
+	chains := cs.VerifiedChains
+	if len(chains) == 0 {
+		chains = [][]*x509.Certificate{cs.PeerCertificates}
+	}
+
+	var got []string
+	for _, chain := range chains {
+		for _, cert := range chain {
+			pin := SPKIPin(cert)
+			if slices.Contains(pins, pin) {
+				return nil
+			}
+			if !slices.Contains(got, pin) {
+				got = append(got, pin)
+			}
+		}
+	}
+	return &PinMismatchError{Host: cs.ServerName, Got: got}
+}
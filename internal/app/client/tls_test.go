@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/client/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// testCert - сертификат с ключом для тестового УЦ, сервера или клиента
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// writeFiles сохраняет сертификат и ключ в PEM и возвращает пути к ним
+func (c *testCert) writeFiles(t *testing.T, dir, name string) (certPath, keyPath string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, c.pem, 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certPath, keyPath
+}
+
+func TestHTTPClient_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test ca", nil, 0)
+	serverCert := newTestCert(t, "127.0.0.1", ca, x509.ExtKeyUsageServerAuth)
+	caPath, _ := ca.writeFiles(t, dir, "ca")
+
+	clientCA := newTestCert(t, "client ca", nil, 0)
+	clientCert := newTestCert(t, "device", clientCA, x509.ExtKeyUsageClientAuth)
+	clientCertPath, clientKeyPath := clientCert.writeFiles(t, dir, "client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate()},
+	}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	mtls := httptest.NewUnstartedServer(srv.Config.Handler)
+	mtls.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.tlsCertificate()},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	mtls.Config.ErrorLog = log.New(io.Discard, "", 0)
+	mtls.StartTLS()
+	t.Cleanup(mtls.Close)
+
+	healthCheck := func(t *testing.T, serverURL string, mutate func(*config.Config)) error {
+		t.Helper()
+		cfg := &config.Config{
+			ServerAddress:  strings.TrimPrefix(serverURL, "https://"),
+			EnableTLS:      true,
+			CACertPath:     caPath,
+			ConnectTimeout: 5 * time.Second,
+			HealthTimeout:  5 * time.Second,
+		}
+		if mutate != nil {
+			mutate(cfg)
+		}
+		cl, err := newHTTPClient(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		require.NoError(t, err)
+		return cl.HealthCheck(context.Background())
+	}
+
+	t.Run("custom CA", func(t *testing.T) {
+		assert.NoError(t, healthCheck(t, srv.URL, nil))
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		err := healthCheck(t, srv.URL, func(cfg *config.Config) { cfg.CACertPath = "" })
+		var unknown x509.UnknownAuthorityError
+		assert.ErrorAs(t, err, &unknown)
+	})
+
+	t.Run("pin matches server or CA key", func(t *testing.T) {
+		for _, pin := range []string{SPKIPin(serverCert.cert), SPKIPin(ca.cert)} {
+			assert.NoError(t, healthCheck(t, srv.URL, func(cfg *config.Config) {
+				cfg.TLSPins = []string{pin}
+			}))
+		}
+	})
+
+	t.Run("pin mismatch", func(t *testing.T) {
+		err := healthCheck(t, srv.URL, func(cfg *config.Config) {
+			cfg.TLSPins = []string{SPKIPin(clientCA.cert)}
+		})
+		var mismatch *PinMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, []string{SPKIPin(serverCert.cert), SPKIPin(ca.cert)}, mismatch.Got)
+		assert.Contains(t, err.Error(), "tls_pins")
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		assert.Error(t, healthCheck(t, mtls.URL, nil), "без сертификата клиента сервер отклоняет соединение")
+		assert.NoError(t, healthCheck(t, mtls.URL, func(cfg *config.Config) {
+			cfg.TLSClientCert, cfg.TLSClientKey = clientCertPath, clientKeyPath
+		}))
+	})
+
+	t.Run("bad CA bundle", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.pem")
+		require.NoError(t, os.WriteFile(bad, []byte("not a certificate"), 0600))
+		_, err := newHTTPClient(&config.Config{EnableTLS: true, CACertPath: bad}, slog.Default())
+		assert.ErrorContains(t, err, "ca_cert_path")
+	})
+}
+
+func TestValidatePin(t *testing.T) {
+	ca := newTestCert(t, "test ca", nil, 0)
+	assert.NoError(t, config.ValidatePin(SPKIPin(ca.cert)))
+	assert.Error(t, config.ValidatePin("sha1/abc"))
+	assert.Error(t, config.ValidatePin("sha256/not-base64"))
+	assert.Error(t, config.ValidatePin("sha256/AAAA"))
+}
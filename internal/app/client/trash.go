@@ -0,0 +1,175 @@
+// internal/app/client/trash.go
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Удаленная запись остается в корзине: локально у нее заполнен DeletedAt,
+// на сервере - deleted_at. Пока запись в корзине, ее можно восстановить.
+// Сервер сам окончательно удаляет записи по истечении срока хранения.
+
+// TrashPurgeResult - итог очистки корзины
+type TrashPurgeResult struct {
+	// Local - сколько записей удалено из локального хранилища
+	Local int `json:"local"`
+	// Server - сколько записей удалено на сервере
+	Server int `json:"server"`
+}
+
+// ListTrash возвращает записи в корзине, начиная с удаленных последними.
+// При наличии аутентификации подтягивает с сервера записи, которых еще нет локально.
+func (a *App) ListTrash(ctx context.Context) ([]*LocalRecord, error) {
+	if a.IsAuthenticated() {
+		if err := a.pullServerTrash(ctx); err != nil {
+			a.log.Warn("Не удалось получить корзину с сервера", "error", err)
+		}
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей: %w", err)
+	}
+
+	var trash []*LocalRecord
+	for _, rec := range records {
+		if rec.DeletedAt != nil {
+			trash = append(trash, rec)
+		}
+	}
+	sort.Slice(trash, func(i, j int) bool { return trash[i].DeletedAt.After(*trash[j].DeletedAt) })
+
+	return trash, nil
+}
+
+// pullServerTrash сохраняет локально записи из серверной корзины,
+// чтобы их можно было восстановить по локальному ID
+func (a *App) pullServerTrash(ctx context.Context) error {
+	serverTrash, err := a.httpClient.ListTrash(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range serverTrash {
+		serverRec := &serverTrash[i]
+
+		localRec, err := findLocalRecord(a.storage, FromServerRecord(serverRec))
+		if err != nil || localRec == nil {
+			if err := a.storage.SaveRecord(FromServerRecord(serverRec)); err != nil {
+				return fmt.Errorf("ошибка сохранения записи: %w", err)
+			}
+			continue
+		}
+
+		// Удаление еще не пришло синхронизацией; локальные изменения не трогаем
+		if localRec.DeletedAt == nil && localRec.Synced {
+			updated := FromServerRecord(serverRec)
+			updated.ID = localRec.ID
+			updated.CreatedAt = localRec.CreatedAt
+			if err := a.storage.SaveRecord(updated); err != nil {
+				return fmt.Errorf("ошибка сохранения записи: %w", err)
+			}
+
+			a.state.Modify(func(s *AppState) { s.RecordsCount-- })
+		}
+	}
+
+	return nil
+}
+
+// RestoreFromTrash возвращает запись из корзины.
+// Запись, уже известная серверу, восстанавливается сначала на сервере.
+func (a *App) RestoreFromTrash(ctx context.Context, id int) (*LocalRecord, error) {
+	rec, err := a.storage.GetRecord(id)
+	if err != nil {
+		return nil, fmt.Errorf("запись не найдена: %w", err)
+	}
+	if rec.DeletedAt == nil {
+		return nil, fmt.Errorf("запись %d не находится в корзине", id)
+	}
+
+	rec.DeletedAt = nil
+	rec.LastModified = time.Now()
+
+	if rec.ServerID > 0 {
+		if !a.IsAuthenticated() {
+			return nil, fmt.Errorf("запись %d удалена на сервере, для восстановления требуется аутентификация", id)
+		}
+
+		version, err := a.httpClient.RestoreRecord(ctx, rec.ServerRef())
+		if err != nil {
+			return nil, err
+		}
+		rec.Version = version
+		rec.Synced = true
+	} else {
+		rec.Synced = false
+	}
+
+	if err := a.storage.SaveRecord(rec); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения записи: %w", err)
+	}
+
+	if err := a.state.Update(incRecordsCount); err != nil {
+		a.log.Warn("Не удалось сохранить состояние", "error", err)
+	}
+
+	a.log.Info("Запись восстановлена из корзины", "record_id", id, "version", rec.Version)
+	return rec, nil
+}
+
+// PurgeTrash окончательно удаляет записи, пролежавшие в корзине дольше olderThan.
+// Нулевой olderThan очищает корзину полностью.
+func (a *App) PurgeTrash(ctx context.Context, olderThan time.Duration) (*TrashPurgeResult, error) {
+	if olderThan < 0 {
+		return nil, fmt.Errorf("срок не может быть отрицательным")
+	}
+
+	records, err := a.storage.ListRecords(&RecordFilter{ShowDeleted: true})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей: %w", err)
+	}
+
+	result := &TrashPurgeResult{}
+	cutoff := time.Now().Add(-olderThan)
+	authenticated := a.IsAuthenticated()
+
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if rec.DeletedAt == nil || rec.DeletedAt.After(cutoff) {
+			continue
+		}
+
+		// Сервер еще не знает об удалении: без него запись вернется при синхронизации
+		if rec.ServerID > 0 && !rec.Synced {
+			if !authenticated {
+				continue
+			}
+			if err := a.httpClient.DeleteRecord(ctx, rec.ServerRef(), true); err != nil {
+				a.log.Warn("Не удалось удалить запись на сервере", "error", err, "record_id", rec.ID)
+				continue
+			}
+		}
+
+		if err := a.storage.HardDeleteRecord(rec.ID); err != nil {
+			return result, fmt.Errorf("ошибка удаления записи: %w", err)
+		}
+		result.Local++
+	}
+
+	if authenticated {
+		purged, err := a.httpClient.PurgeTrash(ctx, olderThan)
+		if err != nil {
+			return result, err
+		}
+		result.Server = purged
+	}
+
+	a.log.Info("Корзина очищена", "local", result.Local, "server", result.Server)
+	return result, nil
+}
@@ -0,0 +1,236 @@
+// internal/app/client/undo.go
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Журнал отмены хранит последние локальные изменения записей: создание,
+// обновление и удаление вместе с состоянием записи до изменения (зашифрованные
+// данные и метаданные). gophkeeper undo отменяет последнее изменение, пока оно
+// не ушло на сервер; после синхронизации прошлые версии записи доступны через
+// gophkeeper record history. Журнал зашифрован, как остальные служебные файлы.
+
+const (
+	undoLogFile = "undo.json"
+	// maxUndoEntries - сколько последних изменений хранит журнал
+	maxUndoEntries = 10
+)
+
+// UndoAction - вид отменяемого изменения
+type UndoAction string
+
+const (
+	UndoCreate UndoAction = "create"
+	UndoUpdate UndoAction = "update"
+	UndoDelete UndoAction = "delete"
+)
+
+// UndoEntry - изменение записи в журнале отмены
+type UndoEntry struct {
+	Action UndoAction `json:"action"`
+	// RecordID - локальный ID записи; после отмены окончательного удаления - новый ID
+	RecordID int `json:"record_id"`
+	// Before - запись до изменения; при создании пусто
+	Before *LocalRecord `json:"before,omitempty"`
+	// Modified - LastModified записи после изменения; по нему видно,
+	// что запись с тех пор не менялась
+	Modified time.Time `json:"modified"`
+	// Synced - изменение сразу отправлено на сервер
+	Synced bool      `json:"synced"`
+	At     time.Time `json:"at"`
+}
+
+// Undo отменяет последнее локальное изменение записи, если оно еще не
+// отправлено на сервер, и возвращает отмененное изменение
+func (a *App) Undo() (*UndoEntry, error) {
+	a.undoMu.Lock()
+	defer a.undoMu.Unlock()
+
+	entries, err := a.loadUndoLog()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+
+	entry := entries[len(entries)-1]
+	if entry.Synced {
+		return nil, ErrUndoSynced
+	}
+
+	switch entry.Action {
+	case UndoCreate:
+		err = a.undoCreate(&entry)
+	case UndoUpdate:
+		err = a.undoUpdate(&entry)
+	case UndoDelete:
+		err = a.undoDelete(&entry)
+	default:
+		err = fmt.Errorf("неизвестное действие в журнале отмены: %s", entry.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.saveUndoLog(entries[:len(entries)-1]); err != nil {
+		a.log.Warn("Не удалось сохранить журнал отмены", "error", err)
+	}
+	return &entry, nil
+}
+
+// undoCreate удаляет созданную запись
+func (a *App) undoCreate(entry *UndoEntry) error {
+	rec, err := a.undoTarget(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := a.storage.HardDeleteRecord(rec.ID); err != nil {
+		return fmt.Errorf("ошибка удаления записи: %w", err)
+	}
+	if rec.DeletedAt == nil {
+		a.state.Modify(func(s *AppState) { s.RecordsCount-- })
+	}
+	return nil
+}
+
+// undoUpdate возвращает запись к состоянию до обновления
+func (a *App) undoUpdate(entry *UndoEntry) error {
+	rec, err := a.undoTarget(entry)
+	if err != nil {
+		return err
+	}
+
+	before := *entry.Before
+	before.ID = rec.ID
+	if err := a.storage.SaveRecord(&before); err != nil {
+		return fmt.Errorf("ошибка восстановления записи: %w", err)
+	}
+	return nil
+}
+
+// undoDelete возвращает удаленную запись. Окончательно удаленная запись
+// сохраняется заново и получает новый локальный ID.
+func (a *App) undoDelete(entry *UndoEntry) error {
+	before := *entry.Before
+
+	rec, err := a.storage.GetRecord(entry.RecordID)
+	switch {
+	case errors.Is(err, ErrRecordNotFound):
+		before.ID = 0
+	case err != nil:
+		return err
+	case rec.DeletedAt == nil || rec.Synced || !rec.LastModified.Equal(entry.Modified):
+		return fmt.Errorf("запись %d изменена после удаления, отмена невозможна", entry.RecordID)
+	}
+
+	if err := a.storage.SaveRecord(&before); err != nil {
+		return fmt.Errorf("ошибка восстановления записи: %w", err)
+	}
+	entry.RecordID = before.ID
+
+	if before.DeletedAt == nil {
+		a.state.Modify(func(s *AppState) { s.RecordsCount++ })
+	}
+	return nil
+}
+
+// undoTarget возвращает запись, если она не менялась после изменения из журнала
+// и не была синхронизирована
+func (a *App) undoTarget(entry *UndoEntry) (*LocalRecord, error) {
+	rec, err := a.storage.GetRecord(entry.RecordID)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Synced {
+		return nil, ErrUndoSynced
+	}
+	if !rec.LastModified.Equal(entry.Modified) {
+		return nil, fmt.Errorf("запись %d изменена после этого действия, отмена невозможна", entry.RecordID)
+	}
+	return rec, nil
+}
+
+// rememberUndo добавляет изменение в журнал отмены. Ошибка журнала не мешает
+// самому изменению и только записывается в лог.
+func (a *App) rememberUndo(entry UndoEntry) {
+	a.undoMu.Lock()
+	defer a.undoMu.Unlock()
+
+	entries, err := a.loadUndoLog()
+	if err != nil {
+		a.log.Warn("Не удалось прочитать журнал отмены", "error", err)
+		entries = nil
+	}
+
+	entry.At = time.Now()
+	entries = append(entries, entry)
+	if len(entries) > maxUndoEntries {
+		entries = entries[len(entries)-maxUndoEntries:]
+	}
+
+	if err := a.saveUndoLog(entries); err != nil {
+		a.log.Warn("Не удалось сохранить журнал отмены", "error", err)
+	}
+}
+
+// undoLogItem - изменение в файле журнала. EncryptedData записи бывает
+// произвольными байтами, которые не переживают JSON-строку, поэтому
+// сохраняется отдельно в base64.
+type undoLogItem struct {
+	UndoEntry
+	BeforeData []byte `json:"before_data,omitempty"`
+}
+
+func (a *App) loadUndoLog() ([]UndoEntry, error) {
+	path := filepath.Join(a.config.ConfigDir, undoLogFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := a.stateCipher.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения журнала отмены: %w", err)
+	}
+
+	var items []undoLogItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("ошибка разбора журнала отмены: %w", err)
+	}
+
+	entries := make([]UndoEntry, 0, len(items))
+	for _, item := range items {
+		if item.Before != nil {
+			item.Before.EncryptedData = string(item.BeforeData)
+		}
+		entries = append(entries, item.UndoEntry)
+	}
+	return entries, nil
+}
+
+func (a *App) saveUndoLog(entries []UndoEntry) error {
+	items := make([]undoLogItem, 0, len(entries))
+	for _, entry := range entries {
+		item := undoLogItem{UndoEntry: entry}
+		if entry.Before != nil {
+			before := *entry.Before
+			item.BeforeData = []byte(before.EncryptedData)
+			before.EncryptedData = ""
+			item.Before = &before
+		}
+		items = append(items, item)
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации журнала отмены: %w", err)
+	}
+	return a.stateCipher.WriteFile(filepath.Join(a.config.ConfigDir, undoLogFile), data)
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/app/client/crypto"
+	"gophkeeper/internal/domain/record"
+)
+
+func newUndoTestApp(t *testing.T) *App {
+	t.Helper()
+
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+	app.encryptor = crypto.NewRecordEncryptor(app.crypto)
+	require.NoError(t, app.InitMasterKey("password123"))
+	return app
+}
+
+func TestApp_Undo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("update restores previous payload", func(t *testing.T) {
+		app := newUndoTestApp(t)
+		id, err := app.saveLocalRecord(ctx, record.RecTypeText, CreateTextRequest{Title: "notes", Content: "first"})
+		require.NoError(t, err)
+		original, err := app.storage.GetRecord(id)
+		require.NoError(t, err)
+		originalData := original.EncryptedData
+
+		require.NoError(t, app.UpdateRecord(ctx, id, GenericRecordRequest{
+			Type: record.RecTypeText,
+			Meta: json.RawMessage(`{"title":"oops"}`),
+			Data: "overwritten",
+		}))
+
+		entry, err := app.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, UndoUpdate, entry.Action)
+
+		rec, err := app.storage.GetRecord(id)
+		require.NoError(t, err)
+		assert.Equal(t, originalData, rec.EncryptedData)
+		assert.Equal(t, 1, rec.Version)
+		assert.NotContains(t, string(rec.Meta), "oops")
+
+		// Следующая отмена убирает созданную запись
+		entry, err = app.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, UndoCreate, entry.Action)
+		_, err = app.storage.GetRecord(id)
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		_, err = app.Undo()
+		assert.ErrorIs(t, err, ErrNothingToUndo)
+	})
+
+	t.Run("delete is reverted", func(t *testing.T) {
+		app := newUndoTestApp(t)
+		id, err := app.saveLocalRecord(ctx, record.RecTypeText, CreateTextRequest{Title: "notes", Content: "keep"})
+		require.NoError(t, err)
+
+		require.NoError(t, app.DeleteRecord(ctx, id, false))
+		entry, err := app.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, UndoDelete, entry.Action)
+		rec, err := app.storage.GetRecord(id)
+		require.NoError(t, err)
+		assert.Nil(t, rec.DeletedAt)
+
+		require.NoError(t, app.DeleteRecord(ctx, id, true))
+		entry, err = app.Undo()
+		require.NoError(t, err)
+		rec, err = app.storage.GetRecord(entry.RecordID)
+		require.NoError(t, err)
+		assert.Nil(t, rec.DeletedAt)
+		assert.Equal(t, 1, app.state.Snapshot().RecordsCount)
+	})
+
+	t.Run("synced change is not reverted", func(t *testing.T) {
+		app := newUndoTestApp(t)
+		id, err := app.saveLocalRecord(ctx, record.RecTypeText, CreateTextRequest{Title: "notes", Content: "first"})
+		require.NoError(t, err)
+		require.NoError(t, app.storage.MarkAsSynced(id, 5, 1))
+
+		_, err = app.Undo()
+		assert.ErrorIs(t, err, ErrUndoSynced)
+		_, err = app.storage.GetRecord(id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("record changed after the logged change", func(t *testing.T) {
+		app := newUndoTestApp(t)
+		id, err := app.saveLocalRecord(ctx, record.RecTypeText, CreateTextRequest{Title: "notes", Content: "first"})
+		require.NoError(t, err)
+		rec, err := app.storage.GetRecord(id)
+		require.NoError(t, err)
+		rec.LastModified = rec.LastModified.Add(time.Minute)
+		require.NoError(t, app.storage.SaveRecord(rec))
+
+		_, err = app.Undo()
+		assert.ErrorContains(t, err, "изменена")
+	})
+}
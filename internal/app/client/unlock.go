@@ -0,0 +1,137 @@
+// internal/app/client/unlock.go
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gophkeeper/internal/app/client/crypto"
+)
+
+// Защита от подбора мастер-пароля на оставленном без присмотра устройстве:
+// после нескольких ошибок попытки разблокировки замедляются, а при
+// unlock_wipe_after > 0 локальные данные удаляются после N ошибок подряд.
+// Данные на сервере не затрагиваются.
+
+const unlockAttemptsFile = "unlock_attempts"
+
+// ErrLocalDataWiped возвращается, если локальные данные удалены после превышения числа попыток
+var ErrLocalDataWiped = errors.New("превышено число попыток разблокировки, локальные данные удалены")
+
+// UnlockMasterKey разблокирует мастер-ключ
+func (a *App) UnlockMasterKey(password string) error {
+	if err := a.unlockThrottle.Check(); err != nil {
+		var throttled *crypto.ThrottledError
+		if errors.As(err, &throttled) {
+			return err
+		}
+		a.log.Warn("Не удалось прочитать счетчик попыток разблокировки", "error", err)
+	}
+
+	if err := a.crypto.UnlockMasterKey(password); err != nil {
+		if errors.Is(err, crypto.ErrWrongPassword) {
+			return a.unlockFailed(err)
+		}
+		return fmt.Errorf("ошибка разблокировки мастер-ключа: %w", err)
+	}
+
+	if err := a.unlockThrottle.Reset(); err != nil {
+		a.log.Warn("Не удалось сбросить счетчик попыток разблокировки", "error", err)
+	}
+
+	a.state.SetMasterKeyReady(true)
+
+	return nil
+}
+
+// UnlockAttemptsLeft возвращает число попыток до удаления локальных данных.
+// Если удаление выключено, возвращает -1.
+func (a *App) UnlockAttemptsLeft() int {
+	if a.config.UnlockWipeAfter == 0 {
+		return -1
+	}
+
+	attempts, err := a.unlockThrottle.Attempts()
+	if err != nil {
+		return -1
+	}
+	return max(a.config.UnlockWipeAfter-attempts.Failures, 0)
+}
+
+// unlockFailed учитывает неверный пароль и при превышении порога удаляет локальные данные
+func (a *App) unlockFailed(cause error) error {
+	failures, err := a.unlockThrottle.RecordFailure()
+	if err != nil {
+		a.log.Warn("Не удалось сохранить счетчик попыток разблокировки", "error", err)
+	}
+	a.log.Warn("Неудачная попытка разблокировки", "failures", failures)
+
+	wipeAfter := a.config.UnlockWipeAfter
+	if wipeAfter == 0 {
+		return fmt.Errorf("неверный мастер-пароль: %w", cause)
+	}
+
+	if failures >= wipeAfter {
+		a.log.Warn("Превышено число попыток разблокировки, удаляем локальные данные", "failures", failures)
+		if err := a.wipeLocalData(); err != nil {
+			return fmt.Errorf("%w, но не все файлы удалось удалить: %v", ErrLocalDataWiped, err)
+		}
+		return ErrLocalDataWiped
+	}
+
+	return fmt.Errorf("неверный мастер-пароль, до удаления локальных данных осталось попыток: %d: %w",
+		wipeAfter-failures, cause)
+}
+
+// wipeLocalData удаляет мастер-ключ, его копии для хранилища ОС и PIN и
+// секретные файлы из wipePaths. Удаляется только то, что перечислено явно:
+// конфигурация, хуки, настройки синхронизации и каталоги (кэш значков)
+// сохраняются.
+func (a *App) wipeLocalData() error {
+	_ = a.crypto.DisableKeyProvider(a.keyProvider)
+	_ = a.crypto.DisablePIN(a.keyProvider)
+	a.crypto.Lock()
+	if err := a.storage.Close(); err != nil {
+		a.log.Warn("Не удалось закрыть локальное хранилище", "error", err)
+	}
+
+	var errs []error
+	for _, path := range a.wipePaths() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	a.state.Reset()
+
+	return errors.Join(errs...)
+}
+
+// wipePaths возвращает файлы с секретами и данными хранилища: мастер-ключ
+// и сессию его разблокировки, токен, локальную базу с журналами, ключ
+// устройства, токен агента, служебные файлы состояния и синхронизации,
+// журнал отмены и счетчик попыток разблокировки
+func (a *App) wipePaths() []string {
+	paths := []string{
+		a.config.MasterKeyPath,
+		filepath.Join(filepath.Dir(a.config.MasterKeyPath), ".session"),
+		a.config.TokenPath,
+		a.config.DataPath,
+		a.config.DataPath + "-wal",
+		a.config.DataPath + "-shm",
+	}
+	for _, name := range []string{
+		deviceFile,
+		agentTokenFile,
+		"state.json",
+		"sync_metadata.json",
+		"sync_stats.json",
+		undoLogFile,
+		unlockAttemptsFile,
+	} {
+		paths = append(paths, filepath.Join(a.config.ConfigDir, name))
+	}
+	return paths
+}
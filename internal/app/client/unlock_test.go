@@ -0,0 +1,61 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/app/client/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noKeyProvider - хранилище секретов ОС без сохраненных ключей
+type noKeyProvider struct{}
+
+func (noKeyProvider) Name() string                { return "none" }
+func (noKeyProvider) Available() bool             { return false }
+func (noKeyProvider) Store(string, []byte) error  { return crypto.ErrKeyNotFound }
+func (noKeyProvider) Load(string) ([]byte, error) { return nil, crypto.ErrKeyNotFound }
+func (noKeyProvider) Delete(string) error         { return crypto.ErrKeyNotFound }
+
+func TestApp_UnlockWipe(t *testing.T) {
+	app := newTestApp(t)
+	dir := app.config.ConfigDir
+	app.config.DataPath = filepath.Join(dir, "data.db")
+	app.config.UnlockWipeAfter = 2
+	app.storage = NewMemoryStorage()
+	app.keyProvider = noKeyProvider{}
+	require.NoError(t, app.InitMasterKey("password123"))
+	app.LockMasterKey()
+
+	secrets := []string{
+		"master.key", ".session", "token", "data.db", "data.db-wal", "data.db-shm",
+		deviceFile, agentTokenFile, "state.json", "sync_metadata.json", "sync_stats.json", undoLogFile,
+	}
+	kept := []string{"config.yaml", "config.yml", "config.toml", hooksFileName, "sync_config.json", backupTargetsFile, healthStatusFile}
+	for _, name := range append(append([]string{}, secrets...), kept...) {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, iconCacheDir), 0700))
+
+	err := app.UnlockMasterKey("wrong")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrLocalDataWiped)
+	assert.FileExists(t, filepath.Join(dir, unlockAttemptsFile))
+
+	assert.ErrorIs(t, app.UnlockMasterKey("wrong"), ErrLocalDataWiped)
+
+	for _, name := range append(secrets, unlockAttemptsFile) {
+		assert.NoFileExists(t, filepath.Join(dir, name), "секретный файл должен быть удален")
+	}
+	for _, name := range kept {
+		assert.FileExists(t, filepath.Join(dir, name), "пользовательский файл должен сохраниться")
+	}
+	assert.DirExists(t, filepath.Join(dir, iconCacheDir))
+}
@@ -0,0 +1,195 @@
+// internal/app/client/upgrade.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/utils/version"
+)
+
+// Уведомления и одноразовые задачи после обновления клиента. Версия, с
+// которой клиент запускался в прошлый раз, хранится в состоянии; при первом
+// запуске новой версии CheckUpgrade выполняет задачи всех версий между
+// прошлой и текущей и возвращает уведомления для пользователя, как раздел
+// CHANGELOG о переходе на новую версию.
+
+// upgradeHook - уведомление и задача для перехода на версию клиента
+type upgradeHook struct {
+	// version - версия клиента, в которой появилось изменение
+	version string
+	name    string
+	// notice - уведомление для пользователя; пусто - задача выполняется молча
+	notice string
+	// run - одноразовая задача; nil - только уведомление. Непустой результат
+	// дополняет уведомление, errSkipNotice скрывает его. Задача должна быть
+	// идемпотентной: если другая задача завершится ошибкой, все задачи
+	// повторятся при следующем запуске.
+	run func(ctx context.Context, a *App) (string, error)
+}
+
+// upgradeHooks - задачи обновления по возрастанию версий. Новые задачи
+// только добавляются в конец, уже выпущенные не меняются.
+var upgradeHooks = []upgradeHook{
+	{
+		version: "1.0.0",
+		name:    "restrict permissions",
+		notice:  "Файлы с секретами теперь доступны только владельцу",
+		run:     upgradeRestrictPermissions,
+	},
+	{
+		version: "1.0.0",
+		name:    "encrypted meta",
+		notice: "Названия, адреса и теги записей можно шифровать мастер-ключом: " +
+			"gophkeeper config set encrypt-meta true, затем gophkeeper record encrypt-meta",
+		run: upgradeEncryptMetaAdvice,
+	},
+	{
+		version: "1.0.0",
+		name:    "record keys",
+		notice: "Новые и измененные записи шифруются отдельными ключами записей: " +
+			"обновите клиент на всех устройствах, прежние версии такие записи не прочитают",
+	},
+}
+
+// errSkipNotice - задача выполнена, но уведомление к этому устройству не относится
+var errSkipNotice = errors.New("уведомление не требуется")
+
+// UpgradeNotice - уведомление об изменении после обновления клиента
+type UpgradeNotice struct {
+	Version string `json:"version,omitempty"`
+	Message string `json:"message"`
+	// Error - задача обновления не выполнена и повторится при следующем запуске
+	Error string `json:"error,omitempty"`
+}
+
+// UpgradeReport - итог первого запуска новой версии клиента
+type UpgradeReport struct {
+	// From - версия прошлого запуска; пусто - версия не сохранялась
+	From    string          `json:"from,omitempty"`
+	To      string          `json:"to"`
+	Notices []UpgradeNotice `json:"notices"`
+}
+
+// CheckUpgrade сравнивает версию клиента с версией прошлого запуска и при
+// обновлении выполняет задачи новых версий. Возвращает nil, если клиент не
+// обновлялся или это первый запуск на устройстве. Версия сохраняется, только
+// когда все задачи выполнены.
+func (a *App) CheckUpgrade(ctx context.Context) (*UpgradeReport, error) {
+	return a.runUpgradeHooks(ctx, Version, upgradeHooks)
+}
+
+func (a *App) runUpgradeHooks(ctx context.Context, current string, hooks []upgradeHook) (*UpgradeReport, error) {
+	to, err := version.Parse(current)
+	if err != nil {
+		return nil, fmt.Errorf("неверная версия клиента: %w", err)
+	}
+
+	state := a.state.Snapshot()
+	var from version.Version
+	if state.ClientVersion != "" {
+		if from, err = version.Parse(state.ClientVersion); err != nil {
+			a.log.Warn("Неверная версия прошлого запуска", "version", state.ClientVersion, "error", err)
+		}
+		if !from.Less(to) {
+			// Та же версия или возврат на старую: задачи не выполняются
+			return nil, nil
+		}
+	} else if !state.Initialized {
+		// Первый запуск на устройстве: переходить не с чего
+		return nil, a.saveClientVersion(current)
+	}
+
+	report := &UpgradeReport{From: state.ClientVersion, To: current}
+	if migration := a.storageMigration(); migration.Migrated() {
+		message := fmt.Sprintf("Локальная база обновлена (схема %d → %d)", migration.From, migration.To)
+		if migration.Backup != "" {
+			message += ", резервная копия: " + migration.Backup
+		}
+		report.Notices = append(report.Notices, UpgradeNotice{Message: message})
+	}
+
+	failed := false
+	for _, hook := range hooks {
+		v, err := version.Parse(hook.version)
+		if err != nil {
+			return nil, fmt.Errorf("задача обновления %q: %w", hook.name, err)
+		}
+		if !from.Less(v) || to.Less(v) {
+			continue
+		}
+
+		notice := UpgradeNotice{Version: hook.version, Message: hook.notice}
+		if hook.run != nil {
+			detail, err := hook.run(ctx, a)
+			if errors.Is(err, errSkipNotice) {
+				continue
+			}
+			if err != nil {
+				a.log.Warn("Задача обновления не выполнена", "hook", hook.name, "error", err)
+				notice.Error = err.Error()
+				failed = true
+			}
+			if detail != "" {
+				if notice.Message == "" {
+					notice.Message = detail
+				} else {
+					notice.Message += ": " + detail
+				}
+			}
+		}
+		if notice.Message != "" || notice.Error != "" {
+			report.Notices = append(report.Notices, notice)
+		}
+	}
+
+	if !failed {
+		if err := a.saveClientVersion(current); err != nil {
+			return report, err
+		}
+	}
+	a.log.Info("Клиент обновлен", "from", report.From, "to", report.To, "notices", len(report.Notices))
+	return report, nil
+}
+
+// saveClientVersion запоминает версию клиента для следующего запуска
+func (a *App) saveClientVersion(current string) error {
+	if err := a.state.Update(func(s *AppState) { s.ClientVersion = current }); err != nil {
+		return fmt.Errorf("ошибка сохранения версии клиента: %w", err)
+	}
+	return nil
+}
+
+// storageMigration возвращает итог обновления схемы локальной базы при открытии
+func (a *App) storageMigration() sqliteMigrationReport {
+	if sqlite, ok := a.sqliteStorage(); ok {
+		return sqlite.migration
+	}
+	return sqliteMigrationReport{}
+}
+
+// upgradeRestrictPermissions исправляет права доступа к файлам с секретами,
+// созданным старыми версиями клиента
+func upgradeRestrictPermissions(_ context.Context, a *App) (string, error) {
+	fixed := 0
+	for _, issue := range a.FixPermissions() {
+		if issue.FixError != "" {
+			return "", fmt.Errorf("%s: %s", issue.Path, issue.FixError)
+		}
+		fixed++
+	}
+	if fixed == 0 {
+		return "", errSkipNotice
+	}
+	return fmt.Sprintf("исправлены права: %d", fixed), nil
+}
+
+// upgradeEncryptMetaAdvice показывает совет о шифровании метаданных, только
+// если режим еще не включен
+func upgradeEncryptMetaAdvice(_ context.Context, a *App) (string, error) {
+	if a.config.EncryptMeta {
+		return "", errSkipNotice
+	}
+	return "", nil
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_RunUpgradeHooks(t *testing.T) {
+	app := newTestApp(t)
+	ctx := context.Background()
+
+	var ran []string
+	hook := func(name, version, notice, detail string, err error) upgradeHook {
+		return upgradeHook{version: version, name: name, notice: notice, run: func(context.Context, *App) (string, error) {
+			ran = append(ran, name)
+			return detail, err
+		}}
+	}
+	hooks := []upgradeHook{
+		hook("old", "1.0.0", "старое", "", nil),
+		hook("next", "1.1.0", "база перестроена", "записей: 3", nil),
+		{version: "1.1.0", name: "advice", notice: "новая команда"},
+		hook("silent", "1.2.0", "не для этого устройства", "", errSkipNotice),
+		hook("future", "2.0.0", "будущее", "", nil),
+	}
+
+	// Первый запуск на устройстве только запоминает версию
+	report, err := app.runUpgradeHooks(ctx, "1.0.0", hooks)
+	require.NoError(t, err)
+	assert.Nil(t, report)
+	assert.Empty(t, ran)
+	assert.Equal(t, "1.0.0", app.state.Snapshot().ClientVersion)
+
+	report, err = app.runUpgradeHooks(ctx, "1.2.0", hooks)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, "1.0.0", report.From)
+	assert.Equal(t, "1.2.0", report.To)
+	assert.Equal(t, []string{"next", "silent"}, ran)
+	assert.Equal(t, []UpgradeNotice{
+		{Version: "1.1.0", Message: "база перестроена: записей: 3"},
+		{Version: "1.1.0", Message: "новая команда"},
+	}, report.Notices)
+	assert.Equal(t, "1.2.0", app.state.Snapshot().ClientVersion)
+
+	// Повторный запуск и возврат на старую версию задачи не выполняют
+	ran = nil
+	for _, current := range []string{"1.2.0", "1.1.0"} {
+		report, err = app.runUpgradeHooks(ctx, current, hooks)
+		require.NoError(t, err)
+		assert.Nil(t, report)
+	}
+	assert.Empty(t, ran)
+}
+
+func TestApp_RunUpgradeHooks_RetriesFailed(t *testing.T) {
+	app := newTestApp(t)
+	ctx := context.Background()
+	// Установка старой версии, которая еще не сохраняла версию клиента
+	require.NoError(t, app.state.Update(func(s *AppState) { s.Initialized = true }))
+
+	failure := errors.New("диск недоступен")
+	attempts := 0
+	hooks := []upgradeHook{{version: "1.0.0", name: "flaky", notice: "перенос данных", run: func(context.Context, *App) (string, error) {
+		attempts++
+		if attempts == 1 {
+			return "", failure
+		}
+		return "", nil
+	}}}
+
+	report, err := app.runUpgradeHooks(ctx, "1.0.0", hooks)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Empty(t, report.From)
+	assert.Equal(t, []UpgradeNotice{{Version: "1.0.0", Message: "перенос данных", Error: failure.Error()}}, report.Notices)
+	assert.Empty(t, app.state.Snapshot().ClientVersion, "версия сохраняется только после всех задач")
+
+	report, err = app.runUpgradeHooks(ctx, "1.0.0", hooks)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.Equal(t, []UpgradeNotice{{Version: "1.0.0", Message: "перенос данных"}}, report.Notices)
+	assert.Equal(t, "1.0.0", app.state.Snapshot().ClientVersion)
+	assert.Equal(t, 2, attempts)
+}
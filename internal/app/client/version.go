@@ -0,0 +1,48 @@
+// internal/app/client/version.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gophkeeper/internal/utils/version"
+)
+
+// Version - версия клиента. Передается серверу в X-Client-Version: сервер
+// отклоняет клиентов старше заданной администратором минимальной версии,
+// чтобы клиенты с известными ошибками синхронизации не портили данные.
+const Version = "1.0.0"
+
+// UpgradeRequiredError - сервер больше не поддерживает эту версию клиента (426)
+type UpgradeRequiredError struct {
+	ClientVersion string `json:"client_version"`
+	MinVersion    string `json:"min_version"`
+}
+
+func (e *UpgradeRequiredError) Error() string {
+	if e.MinVersion == "" {
+		return fmt.Sprintf("версия клиента %s больше не поддерживается сервером. Обновите gophkeeper", e.ClientVersion)
+	}
+	return fmt.Sprintf("версия клиента %s больше не поддерживается сервером, нужна %s или новее. Обновите gophkeeper",
+		e.ClientVersion, e.MinVersion)
+}
+
+// upgradeFromResponse распознает ответ 426 о слишком старом клиенте
+func upgradeFromResponse(resp *http.Response) *UpgradeRequiredError {
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		return nil
+	}
+
+	uerr := &UpgradeRequiredError{ClientVersion: Version}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(uerr); err != nil || uerr.MinVersion == "" {
+		uerr.MinVersion = resp.Header.Get("X-Min-Client-Version")
+	}
+	return uerr
+}
+
+// setVersionHeader передает серверу версию клиента
+func setVersionHeader(req *http.Request) {
+	req.Header.Set(version.Header, Version)
+}
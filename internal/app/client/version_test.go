@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/utils/version"
+)
+
+func TestHTTPClient_UpgradeRequired(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, Version, r.Header.Get(version.Header))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUpgradeRequired)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"status":         "UpgradeRequired",
+			"error":          "client version is no longer supported",
+			"client_version": Version,
+			"min_version":    "9.0.0",
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	app := newTestApp(t)
+	app.httpClient.baseURL = srv.URL
+
+	_, err := app.httpClient.ListFolders(context.Background())
+	var upgradeErr *UpgradeRequiredError
+	require.True(t, errors.As(err, &upgradeErr), "ошибка: %v", err)
+	assert.Equal(t, "9.0.0", upgradeErr.MinVersion)
+	assert.Equal(t, Version, upgradeErr.ClientVersion)
+	assert.Equal(t, 1, requests, "устаревший клиент не повторяет запрос")
+
+	err = app.httpClient.HealthCheck(context.Background())
+	assert.True(t, errors.As(err, &upgradeErr), "ошибка: %v", err)
+}
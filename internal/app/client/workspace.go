@@ -0,0 +1,51 @@
+// internal/app/client/workspace.go
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Контекст (gophkeeper use work/aws) - категория, которой ограничиваются
+// последующие команды со списками записей, чтобы не повторять фильтры.
+// Категории с "/" образуют каталоги: контекст work включает work/aws.
+// Контекст хранится в состоянии клиента и действует до смены или сброса.
+
+// Workspace возвращает текущий контекст; пустая строка - контекст не задан
+func (a *App) Workspace() string {
+	return a.state.Snapshot().Workspace
+}
+
+// SetWorkspace задает текущий контекст; пустой путь сбрасывает его
+func (a *App) SetWorkspace(path string) error {
+	workspace, err := normalizeWorkspace(path)
+	if err != nil {
+		return err
+	}
+	if err := a.state.Update(func(s *AppState) { s.Workspace = workspace }); err != nil {
+		return fmt.Errorf("ошибка сохранения состояния: %w", err)
+	}
+	return nil
+}
+
+// normalizeWorkspace убирает лишние "/" и пробелы вокруг частей пути
+func normalizeWorkspace(path string) (string, error) {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("недопустимый контекст %q", path)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// inWorkspace проверяет, что категория совпадает с контекстом или вложена в него
+func inWorkspace(category, workspace string) bool {
+	category = strings.Trim(category, "/")
+	return category == workspace || strings.HasPrefix(category, workspace+"/")
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gophkeeper/internal/domain/record"
+)
+
+func TestNormalizeWorkspace(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "work/aws", want: "work/aws"},
+		{path: "/work//aws/", want: "work/aws"},
+		{path: " work / aws ", want: "work/aws"},
+		{path: "/", want: ""},
+		{path: "work/../personal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := normalizeWorkspace(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApp_Workspace(t *testing.T) {
+	app := newTestApp(t)
+	app.storage = NewMemoryStorage()
+
+	for _, category := range []string{"work/aws", "work/aws/prod", "work/awsome", "personal", ""} {
+		meta, err := json.Marshal(map[string]string{"title": category + " key", "category": category})
+		require.NoError(t, err)
+		require.NoError(t, app.storage.SaveRecord(&LocalRecord{Type: record.RecTypeLogin, Meta: meta}))
+	}
+
+	require.NoError(t, app.SetWorkspace("/work/aws/"))
+	assert.Equal(t, "work/aws", app.Workspace())
+
+	// Контекст сохраняется в состоянии клиента
+	saved, err := loadAppState(app.config, app.stateCipher)
+	require.NoError(t, err)
+	assert.Equal(t, "work/aws", saved.Workspace)
+
+	records, err := app.ListRecords(context.Background(), &RecordFilter{Workspace: app.Workspace()})
+	require.NoError(t, err)
+	var categories []string
+	for _, rec := range records {
+		var meta struct {
+			Category string `json:"category"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Meta, &meta))
+		categories = append(categories, meta.Category)
+	}
+	assert.ElementsMatch(t, []string{"work/aws", "work/aws/prod"}, categories)
+
+	records, err = app.ListRecords(context.Background(), &RecordFilter{Workspace: app.Workspace(), Search: "prod"})
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	require.NoError(t, app.SetWorkspace(""))
+	assert.Empty(t, app.Workspace())
+}
@@ -0,0 +1,15 @@
+package account
+
+import (
+	"errors"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/user"
+)
+
+var (
+	ErrInvalidConfig   = errors.New("invalid account config")
+	ErrUnauthenticated = apperr.New(apperr.Unauthorized, "unauthenticated")
+	ErrUserNotFound    = user.ErrNotFound
+	ErrNotScheduled    = apperr.New(apperr.NotFound, "account deletion is not scheduled").WithCode("DELETION_NOT_SCHEDULED")
+)
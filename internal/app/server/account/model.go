@@ -0,0 +1,57 @@
+package account
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExportFormat - версия формата архива экспорта
+const ExportFormat = 1
+
+// Config параметры удаления учетных записей
+type Config struct {
+	// GracePeriod - через сколько после запроса учетная запись удаляется;
+	// до этого удаление можно отменить
+	GracePeriod time.Duration
+	// Interval - период запуска удаления учетных записей с истекшим сроком
+	Interval time.Duration
+}
+
+// DefaultConfig возвращает параметры по умолчанию: 30 дней на отмену
+func DefaultConfig() *Config {
+	return &Config{
+		GracePeriod: 30 * 24 * time.Hour,
+		Interval:    time.Hour,
+	}
+}
+
+// Validate проверяет параметры удаления
+func (c *Config) Validate() error {
+	if c.GracePeriod < 0 {
+		return fmt.Errorf("%w: deletion grace period must not be negative", ErrInvalidConfig)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("%w: deletion interval must be positive", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Deletion запланированное удаление учетной записи
+type Deletion struct {
+	DeleteAfter time.Time `json:"delete_after" doc:"Время, после которого учетная запись и все данные будут удалены"`
+}
+
+// Manifest - account.json в архиве экспорта. Данные записей, версий,
+// вложений и файлов остаются зашифрованными ключом пользователя.
+type Manifest struct {
+	Format      int        `json:"format"`
+	UserID      int        `json:"user_id"`
+	Login       string     `json:"login"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExportedAt  time.Time  `json:"exported_at"`
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+	Records     int        `json:"records"`
+	Versions    int        `json:"versions"`
+	Attachments int        `json:"attachments"`
+	Blobs       int        `json:"blobs"`
+}
@@ -0,0 +1,62 @@
+package account
+
+import (
+	"context"
+
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+)
+
+// RecordLister возвращает все записи пользователя, включая удаленные
+type RecordLister interface {
+	ListAllRecords(ctx context.Context, userID int) ([]record.Record, error)
+}
+
+// RecordRepository отдает историю и вложения записей
+type RecordRepository interface {
+	GetVersions(ctx context.Context, recordID int) ([]record.Version, error)
+	ListAttachments(ctx context.Context, recordID int) ([]record.Attachment, error)
+	GetAttachment(ctx context.Context, recordID, attachmentID int) (*record.Attachment, error)
+}
+
+// SyncRepository отдает устройства и конфликты синхронизации
+type SyncRepository interface {
+	ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error)
+	GetSyncConflicts(ctx context.Context, userID int) ([]*sync.Conflict, error)
+}
+
+// BlobRepository отдает содержимое бинарных записей
+type BlobRepository interface {
+	GetData(ctx context.Context, userID int, checksum string) ([]byte, error)
+}
+
+// FolderRepository отдает папки пользователя
+type FolderRepository interface {
+	List(ctx context.Context, userID int) ([]folder.Folder, error)
+}
+
+// SettingsRepository отдает настройки пользователя
+type SettingsRepository interface {
+	Get(ctx context.Context, userID int) (map[string]string, error)
+}
+
+// KeyFileRepository отдает зашифрованный файл мастер-ключа
+type KeyFileRepository interface {
+	Get(ctx context.Context, userID int) (*keyfile.KeyFile, error)
+}
+
+// Repositories хранилища с данными учетной записи.
+// Поля storage.Repositories подходят без адаптеров.
+type Repositories struct {
+	Users    user.Repository
+	Records  RecordLister
+	History  RecordRepository
+	Blobs    BlobRepository
+	Folders  FolderRepository
+	Settings SettingsRepository
+	KeyFiles KeyFileRepository
+	Sync     SyncRepository
+}
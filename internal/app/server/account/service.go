@@ -0,0 +1,347 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса учетной записи текущего пользователя
+type Servicer interface {
+	// ScheduleDeletion планирует удаление учетной записи через GracePeriod.
+	// Повторный запрос не сдвигает уже назначенный срок.
+	ScheduleDeletion(ctx context.Context) (*Deletion, error)
+	// Deletion возвращает запланированное удаление или ErrNotScheduled
+	Deletion(ctx context.Context) (*Deletion, error)
+	CancelDeletion(ctx context.Context) error
+	// Export пишет в w zip-архив со всеми данными пользователя
+	Export(ctx context.Context, w io.Writer) error
+}
+
+// Service управляет удалением и экспортом учетной записи. Сервер не знает
+// ключей пользователя, поэтому экспорт содержит данные в зашифрованном виде.
+type Service struct {
+	repos  Repositories
+	config *Config
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+func NewService(repos Repositories, config *Config, log *slog.Logger) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &Service{
+		repos:  repos,
+		config: config,
+		log:    log.With("component", "account"),
+		now:    time.Now,
+	}
+}
+
+func (s *Service) ScheduleDeletion(ctx context.Context) (*Deletion, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	u, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if u.DeleteAfter != nil {
+		return &Deletion{DeleteAfter: *u.DeleteAfter}, nil
+	}
+
+	deleteAfter := s.now().Add(s.config.GracePeriod).UTC()
+	if err := s.repos.Users.SetDeleteAfter(ctx, userID, &deleteAfter); err != nil {
+		return nil, fmt.Errorf("schedule account deletion: %w", err)
+	}
+
+	s.log.Info("account deletion scheduled", "user_id", userID, "delete_after", deleteAfter)
+	return &Deletion{DeleteAfter: deleteAfter}, nil
+}
+
+func (s *Service) Deletion(ctx context.Context) (*Deletion, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	u, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if u.DeleteAfter == nil {
+		return nil, ErrNotScheduled
+	}
+	return &Deletion{DeleteAfter: *u.DeleteAfter}, nil
+}
+
+func (s *Service) CancelDeletion(ctx context.Context) error {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if _, err := s.Deletion(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repos.Users.SetDeleteAfter(ctx, userID, nil); err != nil {
+		return fmt.Errorf("cancel account deletion: %w", err)
+	}
+
+	s.log.Info("account deletion cancelled", "user_id", userID)
+	return nil
+}
+
+// Run удаляет учетные записи с истекшим сроком по расписанию до отмены
+// контекста. Без интервала задача не запускается.
+func (s *Service) Run(ctx context.Context) {
+	if s.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	s.log.Info("scheduled account deletion started", "grace_period", s.config.GracePeriod,
+		"interval", s.config.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.DeleteDue(ctx)
+			if err != nil {
+				s.log.Error("scheduled account deletion failed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				s.log.Info("scheduled account deletion completed", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// DeleteDue удаляет учетные записи, срок удаления которых наступил, вместе
+// с записями, версиями, конфликтами, устройствами и сессиями
+func (s *Service) DeleteDue(ctx context.Context) (int, error) {
+	ids, err := s.repos.Users.ListDeleteDue(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("list accounts due for deletion: %w", err)
+	}
+
+	var deleted int
+	for _, id := range ids {
+		if err := s.repos.Users.Delete(ctx, id); err != nil {
+			return deleted, fmt.Errorf("delete account %d: %w", id, err)
+		}
+		deleted++
+		s.log.Info("account deleted", "user_id", id)
+	}
+	return deleted, nil
+}
+
+// Раскладка архива экспорта:
+//
+//	account.json           - Manifest
+//	records.json           - все записи, включая корзину
+//	versions.json          - история версий по ID записи
+//	attachments.json       - описания вложений по ID записи
+//	attachments/<id>       - зашифрованное содержимое вложения
+//	blobs/<checksum>       - зашифрованное содержимое бинарных записей
+//	folders.json, settings.json, keyfile.json, devices.json, conflicts.json
+func (s *Service) Export(ctx context.Context, w io.Writer) error {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	u, err := s.repos.Users.FindByID(ctx, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	records, err := s.repos.Records.ListAllRecords(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list records: %w", err)
+	}
+
+	manifest := Manifest{
+		Format:      ExportFormat,
+		UserID:      u.ID,
+		Login:       u.Login,
+		CreatedAt:   u.CreatedAt,
+		ExportedAt:  s.now().UTC(),
+		DeleteAfter: u.DeleteAfter,
+		Records:     len(records),
+	}
+
+	zw := zip.NewWriter(w)
+	versions := make(map[string][]record.Version)
+	attachments := make(map[string][]record.Attachment)
+	checksums := make(map[string]bool)
+
+	for _, rec := range records {
+		key := strconv.Itoa(rec.ID)
+
+		recVersions, err := s.repos.History.GetVersions(ctx, rec.ID)
+		if err != nil {
+			return fmt.Errorf("versions of record %d: %w", rec.ID, err)
+		}
+		if len(recVersions) > 0 {
+			versions[key] = recVersions
+			manifest.Versions += len(recVersions)
+		}
+
+		recAttachments, err := s.repos.History.ListAttachments(ctx, rec.ID)
+		if err != nil {
+			return fmt.Errorf("attachments of record %d: %w", rec.ID, err)
+		}
+		for _, a := range recAttachments {
+			full, err := s.repos.History.GetAttachment(ctx, rec.ID, a.ID)
+			if err != nil {
+				return fmt.Errorf("attachment %d: %w", a.ID, err)
+			}
+			if err := writeFile(zw, "attachments/"+strconv.Itoa(a.ID), full.Data); err != nil {
+				return err
+			}
+		}
+		if len(recAttachments) > 0 {
+			attachments[key] = recAttachments
+			manifest.Attachments += len(recAttachments)
+		}
+
+		if checksum := blobChecksum(rec.Meta); checksum != "" {
+			checksums[checksum] = true
+		}
+	}
+
+	for _, checksum := range sortedKeys(checksums) {
+		data, err := s.repos.Blobs.GetData(ctx, userID, checksum)
+		if errors.Is(err, blob.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("blob %s: %w", checksum, err)
+		}
+		if err := writeFile(zw, "blobs/"+checksum, data); err != nil {
+			return err
+		}
+		manifest.Blobs++
+	}
+
+	folders, err := s.repos.Folders.List(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list folders: %w", err)
+	}
+	settings, err := s.repos.Settings.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get settings: %w", err)
+	}
+	devices, err := s.repos.Sync.ListUserDevices(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list devices: %w", err)
+	}
+	conflicts, err := s.repos.Sync.GetSyncConflicts(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("list conflicts: %w", err)
+	}
+	keyFile, err := s.repos.KeyFiles.Get(ctx, userID)
+	if err != nil && !errors.Is(err, keyfile.ErrNotFound) {
+		return fmt.Errorf("get key file: %w", err)
+	}
+
+	files := []exportFile{
+		{"account.json", manifest},
+		{"records.json", records},
+		{"versions.json", versions},
+		{"attachments.json", attachments},
+		{"folders.json", folders},
+		{"settings.json", settings},
+		{"devices.json", devices},
+		{"conflicts.json", conflicts},
+	}
+	if keyFile != nil {
+		files = append(files, exportFile{"keyfile.json", keyFile})
+	}
+	for _, f := range files {
+		if err := writeJSON(zw, f.name, f.v); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finish export archive: %w", err)
+	}
+
+	s.log.Info("account exported", "user_id", userID, "records", manifest.Records,
+		"attachments", manifest.Attachments, "blobs", manifest.Blobs)
+	return nil
+}
+
+// exportFile - JSON-файл архива экспорта
+type exportFile struct {
+	name string
+	v    any
+}
+
+// blobChecksum возвращает ссылку бинарной записи на блоб из открытых метаданных
+func blobChecksum(meta json.RawMessage) string {
+	if len(meta) == 0 {
+		return ""
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(meta, &fields); err != nil {
+		return ""
+	}
+	var checksum string
+	if err := json.Unmarshal(fields[blob.MetaKey], &checksum); err != nil || !blob.ValidChecksum(checksum) {
+		return ""
+	}
+	return checksum
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	return writeFile(zw, name, data)
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,327 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockUserRepository реализует user.Repository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, login, passwordHash string) (int, error) {
+	args := m.Called(ctx, login, passwordHash)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByLogin(ctx context.Context, login string) (user.User, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByID(ctx context.Context, id int) (user.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context) ([]user.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetRole(ctx context.Context, id int, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetDeleteAfter(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ListDeleteDue(ctx context.Context, before time.Time) ([]int, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockDataRepository реализует хранилища данных пользователя
+type MockDataRepository struct {
+	mock.Mock
+}
+
+func (m *MockDataRepository) ListAllRecords(ctx context.Context, userID int) ([]record.Record, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]record.Record), args.Error(1)
+}
+
+func (m *MockDataRepository) GetVersions(ctx context.Context, recordID int) ([]record.Version, error) {
+	args := m.Called(ctx, recordID)
+	return args.Get(0).([]record.Version), args.Error(1)
+}
+
+func (m *MockDataRepository) ListAttachments(ctx context.Context, recordID int) ([]record.Attachment, error) {
+	args := m.Called(ctx, recordID)
+	return args.Get(0).([]record.Attachment), args.Error(1)
+}
+
+func (m *MockDataRepository) GetAttachment(ctx context.Context, recordID, attachmentID int) (*record.Attachment, error) {
+	args := m.Called(ctx, recordID, attachmentID)
+	return args.Get(0).(*record.Attachment), args.Error(1)
+}
+
+func (m *MockDataRepository) GetData(ctx context.Context, userID int, checksum string) ([]byte, error) {
+	args := m.Called(ctx, userID, checksum)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+func (m *MockDataRepository) List(ctx context.Context, userID int) ([]folder.Folder, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]folder.Folder), args.Error(1)
+}
+
+func (m *MockDataRepository) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*sync.DeviceInfo), args.Error(1)
+}
+
+func (m *MockDataRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*sync.Conflict, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*sync.Conflict), args.Error(1)
+}
+
+// MockSettingsRepository реализует SettingsRepository
+type MockSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingsRepository) Get(ctx context.Context, userID int) (map[string]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+// MockKeyFileRepository реализует KeyFileRepository
+type MockKeyFileRepository struct {
+	mock.Mock
+}
+
+func (m *MockKeyFileRepository) Get(ctx context.Context, userID int) (*keyfile.KeyFile, error) {
+	args := m.Called(ctx, userID)
+	kf, _ := args.Get(0).(*keyfile.KeyFile)
+	return kf, args.Error(1)
+}
+
+type testRepos struct {
+	users    *MockUserRepository
+	data     *MockDataRepository
+	settings *MockSettingsRepository
+	keyFiles *MockKeyFileRepository
+}
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*Service, testRepos) {
+	repos := testRepos{
+		users:    new(MockUserRepository),
+		data:     new(MockDataRepository),
+		settings: new(MockSettingsRepository),
+		keyFiles: new(MockKeyFileRepository),
+	}
+	service := NewService(Repositories{
+		Users:    repos.users,
+		Records:  repos.data,
+		History:  repos.data,
+		Blobs:    repos.data,
+		Folders:  repos.data,
+		Settings: repos.settings,
+		KeyFiles: repos.keyFiles,
+		Sync:     repos.data,
+	}, &Config{GracePeriod: 7 * 24 * time.Hour, Interval: time.Hour}, slog.Default())
+	service.now = func() time.Time { return testNow }
+	return service, repos
+}
+
+func TestService_ScheduleDeletion(t *testing.T) {
+	service, repos := newTestService()
+	ctx := auth.WithUserID(context.Background(), 1)
+	deleteAfter := testNow.Add(7 * 24 * time.Hour)
+
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1}, nil).Once()
+	repos.users.On("SetDeleteAfter", mock.Anything, 1, &deleteAfter).Return(nil)
+
+	deletion, err := service.ScheduleDeletion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, deleteAfter, deletion.DeleteAfter)
+
+	// Повторный запрос возвращает прежний срок
+	scheduled := testNow.Add(time.Hour)
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1, DeleteAfter: &scheduled}, nil).Once()
+
+	deletion, err = service.ScheduleDeletion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, scheduled, deletion.DeleteAfter)
+	repos.users.AssertNumberOfCalls(t, "SetDeleteAfter", 1)
+}
+
+func TestService_ScheduleDeletion_Unauthenticated(t *testing.T) {
+	service, _ := newTestService()
+
+	_, err := service.ScheduleDeletion(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestService_CancelDeletion(t *testing.T) {
+	service, repos := newTestService()
+	ctx := auth.WithUserID(context.Background(), 1)
+	scheduled := testNow.Add(time.Hour)
+
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1, DeleteAfter: &scheduled}, nil).Once()
+	repos.users.On("SetDeleteAfter", mock.Anything, 1, (*time.Time)(nil)).Return(nil)
+	require.NoError(t, service.CancelDeletion(ctx))
+
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1}, nil)
+	assert.ErrorIs(t, service.CancelDeletion(ctx), ErrNotScheduled)
+	_, err := service.Deletion(ctx)
+	assert.ErrorIs(t, err, ErrNotScheduled)
+	repos.users.AssertNumberOfCalls(t, "SetDeleteAfter", 1)
+}
+
+func TestService_DeleteDue(t *testing.T) {
+	service, repos := newTestService()
+
+	repos.users.On("ListDeleteDue", mock.Anything, testNow).Return([]int{2, 3}, nil)
+	repos.users.On("Delete", mock.Anything, 2).Return(nil)
+	repos.users.On("Delete", mock.Anything, 3).Return(errors.New("db down"))
+
+	deleted, err := service.DeleteDue(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestService_Export(t *testing.T) {
+	service, repos := newTestService()
+	ctx := auth.WithUserID(context.Background(), 1)
+	checksum := strings.Repeat("ab", 32)
+	deletedAt := testNow.Add(-time.Hour)
+
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1, Login: "alice"}, nil)
+	repos.data.On("ListAllRecords", mock.Anything, 1).Return([]record.Record{
+		{ID: 10, UserID: 1, Type: record.RecTypeBinary, EncryptedData: "01", Meta: json.RawMessage(`{"blob":"` + checksum + `"}`)},
+		{ID: 11, UserID: 1, Type: record.RecTypeText, EncryptedData: "02", DeletedAt: &deletedAt},
+	}, nil)
+	repos.data.On("GetVersions", mock.Anything, 10).Return([]record.Version{{RecordID: 10, Version: 1}}, nil)
+	repos.data.On("GetVersions", mock.Anything, 11).Return([]record.Version(nil), nil)
+	repos.data.On("ListAttachments", mock.Anything, 10).Return([]record.Attachment{{ID: 5, RecordID: 10, Size: 4}}, nil)
+	repos.data.On("ListAttachments", mock.Anything, 11).Return([]record.Attachment(nil), nil)
+	repos.data.On("GetAttachment", mock.Anything, 10, 5).Return(&record.Attachment{ID: 5, Data: []byte("scan")}, nil)
+	repos.data.On("GetData", mock.Anything, 1, checksum).Return([]byte("file-ciphertext"), nil)
+	repos.data.On("List", mock.Anything, 1).Return([]folder.Folder{}, nil)
+	repos.data.On("ListUserDevices", mock.Anything, 1).Return([]*sync.DeviceInfo{}, nil)
+	repos.data.On("GetSyncConflicts", mock.Anything, 1).Return([]*sync.Conflict{}, nil)
+	repos.settings.On("Get", mock.Anything, 1).Return(map[string]string{}, nil)
+	repos.keyFiles.On("Get", mock.Anything, 1).Return(nil, keyfile.ErrNotFound)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.Export(ctx, &buf))
+
+	files := readArchive(t, buf.Bytes())
+	for _, name := range []string{"account.json", "records.json", "versions.json", "attachments.json",
+		"folders.json", "settings.json", "devices.json", "conflicts.json"} {
+		assert.Contains(t, files, name)
+	}
+	assert.NotContains(t, files, "keyfile.json")
+	assert.Equal(t, []byte("scan"), files["attachments/5"])
+	assert.Equal(t, []byte("file-ciphertext"), files["blobs/"+checksum])
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(files["account.json"], &manifest))
+	assert.Equal(t, Manifest{Format: ExportFormat, UserID: 1, Login: "alice", ExportedAt: testNow,
+		Records: 2, Versions: 1, Attachments: 1, Blobs: 1}, manifest)
+
+	// Корзина тоже попадает в экспорт
+	var records []record.Record
+	require.NoError(t, json.Unmarshal(files["records.json"], &records))
+	require.Len(t, records, 2)
+	assert.NotNil(t, records[1].DeletedAt)
+}
+
+func TestService_Export_MissingBlob(t *testing.T) {
+	service, repos := newTestService()
+	ctx := auth.WithUserID(context.Background(), 1)
+	checksum := strings.Repeat("cd", 32)
+
+	repos.users.On("FindByID", mock.Anything, 1).Return(user.User{ID: 1, Login: "alice"}, nil)
+	repos.data.On("ListAllRecords", mock.Anything, 1).Return([]record.Record{
+		{ID: 10, Type: record.RecTypeBinary, Meta: json.RawMessage(`{"blob":"` + checksum + `"}`)},
+	}, nil)
+	repos.data.On("GetVersions", mock.Anything, 10).Return([]record.Version(nil), nil)
+	repos.data.On("ListAttachments", mock.Anything, 10).Return([]record.Attachment(nil), nil)
+	repos.data.On("GetData", mock.Anything, 1, checksum).Return(nil, blob.ErrNotFound)
+	repos.data.On("List", mock.Anything, 1).Return([]folder.Folder{}, nil)
+	repos.data.On("ListUserDevices", mock.Anything, 1).Return([]*sync.DeviceInfo{}, nil)
+	repos.data.On("GetSyncConflicts", mock.Anything, 1).Return([]*sync.Conflict{}, nil)
+	repos.settings.On("Get", mock.Anything, 1).Return(map[string]string{}, nil)
+	repos.keyFiles.On("Get", mock.Anything, 1).Return(&keyfile.KeyFile{Data: []byte("key")}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.Export(ctx, &buf))
+
+	files := readArchive(t, buf.Bytes())
+	assert.NotContains(t, files, "blobs/"+checksum)
+	assert.Contains(t, files, "keyfile.json")
+}
+
+// readArchive распаковывает zip-архив экспорта в память
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = content
+	}
+	return files
+}
@@ -0,0 +1,12 @@
+package admin
+
+import (
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/user"
+)
+
+var (
+	ErrUserNotFound  = user.ErrNotFound
+	ErrInvalidRole   = apperr.New(apperr.Invalid, "unknown role")
+	ErrInvalidPeriod = apperr.New(apperr.Invalid, "purge period must not be negative")
+)
@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"time"
+
+	"gophkeeper/internal/domain/user"
+)
+
+// UserInfo сведения о пользователе для администратора. Содержимое записей
+// зашифровано на клиенте, поэтому сервер показывает только объемы.
+type UserInfo struct {
+	ID          int        `json:"id"`
+	Login       string     `json:"login"`
+	Role        string     `json:"role"`
+	Disabled    bool       `json:"disabled"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StorageUsed int64      `json:"storage_used" doc:"Объем зашифрованных данных в байтах"`
+	Devices     int        `json:"devices" doc:"Число зарегистрированных устройств"`
+}
+
+// PasswordReset результат принудительного сброса пароля
+type PasswordReset struct {
+	UserID int `json:"user_id"`
+	// TemporaryPassword показывается один раз; пользователь входит с ним и меняет пароль.
+	// Мастер-пароль и зашифрованные данные сброс не затрагивает.
+	TemporaryPassword string `json:"temporary_password"`
+	SessionsRevoked   int64  `json:"sessions_revoked"`
+}
+
+// PurgeResult итог очистки корзины
+type PurgeResult struct {
+	UserID int       `json:"user_id,omitempty"` // 0 - все пользователи
+	Before time.Time `json:"before"`
+	Purged int       `json:"purged"`
+}
+
+func newUserInfo(u user.User) UserInfo {
+	role := u.Role
+	if role == "" {
+		role = user.RoleUser
+	}
+
+	return UserInfo{
+		ID:         u.ID,
+		Login:      u.Login,
+		Role:       role,
+		Disabled:   u.Disabled(),
+		DisabledAt: u.DisabledAt,
+		CreatedAt:  u.CreatedAt,
+	}
+}
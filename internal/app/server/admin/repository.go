@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+)
+
+// SessionRepository завершает сессии пользователя
+type SessionRepository interface {
+	DeleteByUser(ctx context.Context, userID int) (int64, error)
+}
+
+// UsageRepository считает объем данных пользователя
+type UsageRepository interface {
+	Used(ctx context.Context, userID int) (int64, error)
+}
+
+// DeviceRepository возвращает устройства пользователя
+type DeviceRepository interface {
+	ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error)
+}
+
+// RecordRepository окончательно удаляет записи из корзины
+type RecordRepository interface {
+	PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error)
+	PurgeAllDeleted(ctx context.Context, before time.Time) (int, error)
+}
+
+// Repositories хранилища, с которыми работает администратор.
+// Поля storage.Repositories подходят без адаптеров.
+type Repositories struct {
+	Users    user.Repository
+	Sessions SessionRepository
+	Usage    UsageRepository
+	Devices  DeviceRepository
+	Records  RecordRepository
+}
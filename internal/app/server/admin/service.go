@@ -0,0 +1,256 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gophkeeper/internal/domain/user"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/exp/slog"
+)
+
+// temporaryPasswordLength длина временного пароля при сбросе
+const temporaryPasswordLength = 16
+
+// Наборы символов временного пароля: по одному символу каждого набора
+// гарантируют, что пароль проходит user.PasswordValidator
+var passwordAlphabets = []string{
+	"abcdefghijkmnopqrstuvwxyz",
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"23456789",
+	"!@#$%^&*-_=+",
+}
+
+// Servicer интерфейс административного сервиса
+type Servicer interface {
+	ListUsers(ctx context.Context) ([]UserInfo, error)
+	GetUser(ctx context.Context, id int) (*UserInfo, error)
+	// Disable блокирует учетную запись и завершает все ее сессии
+	Disable(ctx context.Context, id int) (*UserInfo, error)
+	Enable(ctx context.Context, id int) (*UserInfo, error)
+	// ResetPassword заменяет пароль входа временным и завершает все сессии
+	ResetPassword(ctx context.Context, id int) (*PasswordReset, error)
+	SetRole(ctx context.Context, id int, role string) (*UserInfo, error)
+	// Purge окончательно удаляет записи, лежащие в корзине дольше olderThan.
+	// userID 0 очищает корзины всех пользователей.
+	Purge(ctx context.Context, userID int, olderThan time.Duration) (*PurgeResult, error)
+}
+
+// Service управляет учетными записями пользователей от имени администратора
+type Service struct {
+	repos Repositories
+	log   *slog.Logger
+	now   func() time.Time
+}
+
+func NewService(repos Repositories, log *slog.Logger) *Service {
+	return &Service{
+		repos: repos,
+		log:   log.With("component", "admin"),
+		now:   time.Now,
+	}
+}
+
+func (s *Service) ListUsers(ctx context.Context) ([]UserInfo, error) {
+	users, err := s.repos.Users.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	infos := make([]UserInfo, 0, len(users))
+	for _, u := range users {
+		info, err := s.userInfo(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+func (s *Service) GetUser(ctx context.Context, id int) (*UserInfo, error) {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userInfo(ctx, u)
+}
+
+func (s *Service) Disable(ctx context.Context, id int) (*UserInfo, error) {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.Disabled() {
+		now := s.now().UTC()
+		if err := s.repos.Users.SetDisabled(ctx, id, &now); err != nil {
+			return nil, fmt.Errorf("disable user: %w", err)
+		}
+		u.DisabledAt = &now
+	}
+
+	// Сессии перестают действовать сразу после блокировки, удаление лишь
+	// убирает их из базы
+	revoked, err := s.repos.Sessions.DeleteByUser(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	s.log.Info("user disabled", "user_id", id, "sessions_revoked", revoked)
+	return s.userInfo(ctx, u)
+}
+
+func (s *Service) Enable(ctx context.Context, id int) (*UserInfo, error) {
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Disabled() {
+		if err := s.repos.Users.SetDisabled(ctx, id, nil); err != nil {
+			return nil, fmt.Errorf("enable user: %w", err)
+		}
+		u.DisabledAt = nil
+		s.log.Info("user enabled", "user_id", id)
+	}
+
+	return s.userInfo(ctx, u)
+}
+
+func (s *Service) ResetPassword(ctx context.Context, id int) (*PasswordReset, error) {
+	if _, err := s.findUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	password, err := temporaryPassword()
+	if err != nil {
+		return nil, fmt.Errorf("generate password: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("password hash: %w", err)
+	}
+
+	if err := s.repos.Users.UpdatePassword(ctx, id, string(hash)); err != nil {
+		return nil, fmt.Errorf("update password: %w", err)
+	}
+
+	revoked, err := s.repos.Sessions.DeleteByUser(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	s.log.Info("user password reset", "user_id", id, "sessions_revoked", revoked)
+	return &PasswordReset{UserID: id, TemporaryPassword: password, SessionsRevoked: revoked}, nil
+}
+
+func (s *Service) SetRole(ctx context.Context, id int, role string) (*UserInfo, error) {
+	if !user.ValidRole(role) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+
+	u, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Role != role {
+		if err := s.repos.Users.SetRole(ctx, id, role); err != nil {
+			return nil, fmt.Errorf("set role: %w", err)
+		}
+		s.log.Info("user role changed", "user_id", id, "from", u.Role, "to", role)
+		u.Role = role
+	}
+
+	return s.userInfo(ctx, u)
+}
+
+func (s *Service) Purge(ctx context.Context, userID int, olderThan time.Duration) (*PurgeResult, error) {
+	if olderThan < 0 {
+		return nil, ErrInvalidPeriod
+	}
+
+	before := s.now().Add(-olderThan).UTC()
+	result := &PurgeResult{UserID: userID, Before: before}
+
+	var err error
+	if userID == 0 {
+		result.Purged, err = s.repos.Records.PurgeAllDeleted(ctx, before)
+	} else {
+		if _, err := s.findUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		result.Purged, err = s.repos.Records.PurgeDeleted(ctx, userID, before)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("purge deleted records: %w", err)
+	}
+
+	s.log.Info("deleted records purged", "user_id", userID, "before", before, "purged", result.Purged)
+	return result, nil
+}
+
+// findUser возвращает пользователя по ID. Как и user.Service.Get, любая
+// ошибка FindByID считается отсутствием пользователя.
+func (s *Service) findUser(ctx context.Context, id int) (user.User, error) {
+	u, err := s.repos.Users.FindByID(ctx, id)
+	if err != nil {
+		s.log.Debug("find user", "user_id", id, "error", err)
+		return u, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *Service) userInfo(ctx context.Context, u user.User) (*UserInfo, error) {
+	info := newUserInfo(u)
+
+	used, err := s.repos.Usage.Used(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("storage usage of user %d: %w", u.ID, err)
+	}
+	devices, err := s.repos.Devices.ListUserDevices(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("devices of user %d: %w", u.ID, err)
+	}
+
+	info.StorageUsed = used
+	info.Devices = len(devices)
+	return &info, nil
+}
+
+// temporaryPassword генерирует пароль со строчной и заглавной буквой,
+// цифрой и спецсимволом
+func temporaryPassword() (string, error) {
+	var all string
+	for _, alphabet := range passwordAlphabets {
+		all += alphabet
+	}
+
+	password := make([]byte, 0, temporaryPasswordLength)
+	for i := 0; i < temporaryPasswordLength; i++ {
+		alphabet := all
+		if i < len(passwordAlphabets) {
+			alphabet = passwordAlphabets[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		if err != nil {
+			return "", err
+		}
+		password = append(password, alphabet[n.Int64()])
+	}
+
+	// Перемешиваем, чтобы обязательные символы не стояли в начале
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
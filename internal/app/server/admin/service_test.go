@@ -0,0 +1,243 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository реализует все хранилища Repositories
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, login, passwordHash string) (int, error) {
+	args := m.Called(ctx, login, passwordHash)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) FindByLogin(ctx context.Context, login string) (user.User, error) {
+	args := m.Called(ctx, login)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (m *MockRepository) FindByID(ctx context.Context, id int) (user.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(user.User), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context) ([]user.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]user.User), args.Error(1)
+}
+
+func (m *MockRepository) SetDisabled(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetRole(ctx context.Context, id int, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetDeleteAfter(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListDeleteDue(ctx context.Context, before time.Time) ([]int, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Used(ctx context.Context, userID int) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*sync.DeviceInfo), args.Error(1)
+}
+
+func (m *MockRepository) PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error) {
+	args := m.Called(ctx, userID, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) PurgeAllDeleted(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService() (*Service, *MockRepository) {
+	repo := new(MockRepository)
+	service := NewService(Repositories{
+		Users:    repo,
+		Sessions: repo,
+		Usage:    repo,
+		Devices:  repo,
+		Records:  repo,
+	}, slog.Default())
+	service.now = func() time.Time { return testNow }
+	return service, repo
+}
+
+// expectStats задает объем хранилища и устройства пользователя
+func expectStats(repo *MockRepository, userID int, used int64, devices int) {
+	repo.On("Used", mock.Anything, userID).Return(used, nil)
+	repo.On("ListUserDevices", mock.Anything, userID).Return(make([]*sync.DeviceInfo, devices), nil)
+}
+
+func TestService_ListUsers(t *testing.T) {
+	service, repo := newTestService()
+	disabledAt := testNow.Add(-time.Hour)
+
+	repo.On("List", mock.Anything).Return([]user.User{
+		{ID: 1, Login: "alice", Role: user.RoleAdmin},
+		{ID: 2, Login: "bob", Role: user.RoleUser, DisabledAt: &disabledAt},
+	}, nil)
+	expectStats(repo, 1, 2048, 2)
+	expectStats(repo, 2, 0, 0)
+
+	users, err := service.ListUsers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	assert.Equal(t, UserInfo{ID: 1, Login: "alice", Role: user.RoleAdmin, StorageUsed: 2048, Devices: 2}, users[0])
+	assert.True(t, users[1].Disabled)
+	assert.Equal(t, &disabledAt, users[1].DisabledAt)
+}
+
+func TestService_GetUser_NotFound(t *testing.T) {
+	service, repo := newTestService()
+	repo.On("FindByID", mock.Anything, 7).Return(user.User{ID: 7}, errors.New("user not found"))
+
+	_, err := service.GetUser(context.Background(), 7)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestService_Disable(t *testing.T) {
+	service, repo := newTestService()
+
+	repo.On("FindByID", mock.Anything, 2).Return(user.User{ID: 2, Login: "bob", Role: user.RoleUser}, nil)
+	repo.On("SetDisabled", mock.Anything, 2, mock.MatchedBy(func(at *time.Time) bool {
+		return at != nil && at.Equal(testNow)
+	})).Return(nil)
+	repo.On("DeleteByUser", mock.Anything, 2).Return(int64(3), nil)
+	expectStats(repo, 2, 10, 1)
+
+	info, err := service.Disable(context.Background(), 2)
+	require.NoError(t, err)
+	assert.True(t, info.Disabled)
+	repo.AssertExpectations(t)
+}
+
+func TestService_Enable(t *testing.T) {
+	service, repo := newTestService()
+	disabledAt := testNow.Add(-time.Hour)
+
+	repo.On("FindByID", mock.Anything, 2).Return(user.User{ID: 2, DisabledAt: &disabledAt}, nil)
+	repo.On("SetDisabled", mock.Anything, 2, (*time.Time)(nil)).Return(nil)
+	expectStats(repo, 2, 0, 0)
+
+	info, err := service.Enable(context.Background(), 2)
+	require.NoError(t, err)
+	assert.False(t, info.Disabled)
+	assert.Nil(t, info.DisabledAt)
+	repo.AssertExpectations(t)
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	service, repo := newTestService()
+
+	var hash string
+	repo.On("FindByID", mock.Anything, 2).Return(user.User{ID: 2}, nil)
+	repo.On("UpdatePassword", mock.Anything, 2, mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { hash = args.String(2) }).Return(nil)
+	repo.On("DeleteByUser", mock.Anything, 2).Return(int64(1), nil)
+
+	reset, err := service.ResetPassword(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reset.SessionsRevoked)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte(reset.TemporaryPassword)))
+	assert.NoError(t, user.NewPasswordValidator().ValidatePassword(reset.TemporaryPassword))
+}
+
+func TestService_SetRole(t *testing.T) {
+	service, repo := newTestService()
+
+	_, err := service.SetRole(context.Background(), 2, "root")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	repo.On("FindByID", mock.Anything, 2).Return(user.User{ID: 2, Role: user.RoleUser}, nil)
+	repo.On("SetRole", mock.Anything, 2, user.RoleAdmin).Return(nil)
+	expectStats(repo, 2, 0, 0)
+
+	info, err := service.SetRole(context.Background(), 2, user.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, info.Role)
+	repo.AssertExpectations(t)
+}
+
+func TestService_Purge(t *testing.T) {
+	service, repo := newTestService()
+	before := testNow.Add(-30 * 24 * time.Hour)
+
+	_, err := service.Purge(context.Background(), 0, -time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+
+	repo.On("PurgeAllDeleted", mock.Anything, before).Return(5, nil)
+	result, err := service.Purge(context.Background(), 0, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, &PurgeResult{Before: before, Purged: 5}, result)
+
+	repo.On("FindByID", mock.Anything, 2).Return(user.User{ID: 2}, nil)
+	repo.On("PurgeDeleted", mock.Anything, 2, testNow).Return(1, nil)
+	result, err = service.Purge(context.Background(), 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Purged)
+	assert.Equal(t, 2, result.UserID)
+}
+
+func TestTemporaryPassword(t *testing.T) {
+	validator := user.NewPasswordValidator()
+	seen := make(map[string]bool)
+
+	for i := 0; i < 50; i++ {
+		password, err := temporaryPassword()
+		require.NoError(t, err)
+		assert.Len(t, password, temporaryPasswordLength)
+		assert.NoError(t, validator.ValidatePassword(password))
+		seen[password] = true
+	}
+	assert.Len(t, seen, 50)
+}
@@ -5,29 +5,132 @@
 
 //POST /user/register     # Регистрация (публичный)
 //POST /user/login        # Логин (публичный)
+//POST /user/login/2fa    # Второй шаг логина с кодом 2FA (публичный)
 //POST /api/records       # Создать запись (auth)
 //GET  /api/records       # Список записей (auth)
 //GET  /api/records/{id}  # Получить запись (auth)
 //PUT  /api/records/{id}  # Обновить запись (auth)
 //DELETE /api/records/{id} # Удалить запись (auth)
+//GET  /api/records/{id}/attachments # Вложения записи (auth)
+//POST /api/records/{id}/attachments # Прикрепить файл (auth)
+//GET  /api/records/{id}/attachments/{attachment_id} # Скачать вложение (auth)
+//DELETE /api/records/{id}/attachments/{attachment_id} # Открепить файл (auth)
+//HEAD /api/blobs/{checksum} # Проверить наличие содержимого файла (auth)
+//GET  /api/blobs/{checksum} # Получить содержимое файла (auth)
+//PUT  /api/blobs/{checksum} # Загрузить содержимое файла (auth)
+//POST /api/orgs           # Создать организацию (auth)
+//GET  /api/orgs           # Организации пользователя (auth)
+//GET  /api/orgs/{id}/members # Участники организации (auth)
+//POST /api/orgs/{id}/members # Пригласить участника (auth)
+//POST /api/orgs/{id}/accept  # Принять приглашение (auth)
+//GET  /api/orgs/{id}/records # Записи хранилища организации (auth)
+//GET  /api/folders        # Папки пользователя (auth)
+//POST /api/folders        # Создать папку (auth)
+//PATCH /api/folders/{id}  # Переименовать или переместить папку (auth)
+//DELETE /api/folders/{id} # Удалить пустую папку (auth)
+//POST /api/admin/backups  # Создать резервную копию (X-Admin-Token)
+//GET  /api/admin/backups  # Список резервных копий (X-Admin-Token)
+//POST /api/admin/backups/{id}/restore # Восстановить записи пользователя (X-Admin-Token)
+//GET  /api/admin/maintenance  # Состояние режима обслуживания (X-Admin-Token)
+//PUT  /api/admin/maintenance  # Включить/выключить режим обслуживания (X-Admin-Token)
+//GET  /api/account/quota      # Использование хранилища (auth)
+//GET  /api/account/stats      # Записи по типам, рост по дням, устройства (auth)
+//GET  /api/account/2fa       # Состояние 2FA (auth)
+//POST /api/account/2fa/enroll          # Секрет и otpauth:// URI (auth)
+//POST /api/account/2fa/confirm         # Включить 2FA, коды восстановления (auth)
+//POST /api/account/2fa/disable         # Выключить 2FA (auth)
+//POST /api/account/2fa/recovery-codes  # Новые коды восстановления (auth)
+//POST /api/account/password  # Сменить пароль входа, завершить сессии (auth)
+//GET  /api/admin/users/{id}/quota    # Квота пользователя (X-Admin-Token)
+//PUT  /api/admin/users/{id}/quota    # Задать лимит пользователю (X-Admin-Token)
+//DELETE /api/admin/users/{id}/quota  # Сбросить лимит пользователя (X-Admin-Token)
+//POST /api/v2/sync/changes    # Изменения по курсору, протокол v2 (auth)
+//POST /api/v2/sync/negotiate  # Сверка индекса, протокол v2 (auth)
+//POST /api/v2/sync/batch      # Пакетная загрузка, протокол v2 (auth)
+//GET  /api/admin/users/{id}/sync-protocol    # Протокол синхронизации пользователя (X-Admin-Token)
+//PUT  /api/admin/users/{id}/sync-protocol    # Назначить протокол (X-Admin-Token)
+//DELETE /api/admin/users/{id}/sync-protocol  # Сбросить протокол (X-Admin-Token)
+//POST /api/share/tokens        # Выпустить токен просмотра папок (auth)
+//GET  /api/share/tokens        # Токены просмотра (auth)
+//DELETE /api/share/tokens/{id} # Отозвать токен просмотра (auth)
+//GET  /api/shared/records      # Записи папок токена (токен просмотра)
+//GET  /api/shared/records/{id} # Запись из папок токена (токен просмотра)
+//POST /api/share/links        # Одноразовая ссылка на зашифрованный секрет (auth)
+//GET  /api/share/links        # Ссылки на секреты (auth)
+//DELETE /api/share/links/{id} # Удалить ссылку на секрет (auth)
+//POST /api/secrets/{id}/open  # Открыть секрет по ссылке (публичный)
+//GET  /s/{id}                 # Страница расшифровки секрета в браузере (публичный)
+//DELETE /api/v1/account          # Запланировать удаление учетной записи (auth)
+//GET  /api/v1/account/deletion   # Срок удаления учетной записи (auth)
+//DELETE /api/v1/account/deletion # Отменить удаление учетной записи (auth)
+//GET  /api/v1/account/export     # Архив всех данных пользователя (auth)
+//GET  /api/v1/admin/users                     # Пользователи с хранилищем и устройствами (X-Admin-Token или роль admin)
+//GET  /api/v1/admin/users/{id}                # Сведения о пользователе (X-Admin-Token или роль admin)
+//POST /api/v1/admin/users/{id}/disable        # Заблокировать учетную запись (X-Admin-Token или роль admin)
+//POST /api/v1/admin/users/{id}/enable         # Разблокировать учетную запись (X-Admin-Token или роль admin)
+//POST /api/v1/admin/users/{id}/password-reset # Временный пароль вместо текущего (X-Admin-Token или роль admin)
+//POST /api/v1/admin/users/{id}/role           # Назначить роль (X-Admin-Token или роль admin)
+//POST /api/v1/admin/purge                     # Очистить корзину (X-Admin-Token или роль admin)
 
 package api
 
 import (
+	"gophkeeper/internal/app/server/account"
+	adminService "gophkeeper/internal/app/server/admin"
+	accountAPI "gophkeeper/internal/app/server/api/http/account"
+	adminAPI "gophkeeper/internal/app/server/api/http/admin"
+	backupAPI "gophkeeper/internal/app/server/api/http/backup"
+	blobAPI "gophkeeper/internal/app/server/api/http/blob"
+	folderAPI "gophkeeper/internal/app/server/api/http/folder"
 	healthAPI "gophkeeper/internal/app/server/api/http/health"
+	keyfileAPI "gophkeeper/internal/app/server/api/http/keyfile"
+	maintenanceAPI "gophkeeper/internal/app/server/api/http/maintenance"
+	mfaAPI "gophkeeper/internal/app/server/api/http/mfa"
 	"gophkeeper/internal/app/server/api/http/middleware"
+	"gophkeeper/internal/app/server/api/http/middleware/admin"
 	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/app/server/api/http/middleware/bodylimit"
+	"gophkeeper/internal/app/server/api/http/middleware/clientinfo"
+	"gophkeeper/internal/app/server/api/http/middleware/clientversion"
+	"gophkeeper/internal/app/server/api/http/middleware/compress"
+	"gophkeeper/internal/app/server/api/http/middleware/cors"
+	"gophkeeper/internal/app/server/api/http/middleware/devicetrust"
 	"gophkeeper/internal/app/server/api/http/middleware/logger"
+	maintenanceMW "gophkeeper/internal/app/server/api/http/middleware/maintenance"
+	"gophkeeper/internal/app/server/api/http/middleware/recoverer"
+	"gophkeeper/internal/app/server/api/http/middleware/requestid"
+	"gophkeeper/internal/app/server/api/http/middleware/usage"
+	"gophkeeper/internal/app/server/api/http/middleware/viewertoken"
+	orgAPI "gophkeeper/internal/app/server/api/http/org"
+	passwordAPI "gophkeeper/internal/app/server/api/http/password"
+	"gophkeeper/internal/app/server/api/http/problem"
+	quotaAPI "gophkeeper/internal/app/server/api/http/quota"
 	recordAPI "gophkeeper/internal/app/server/api/http/record"
+	sessionAPI "gophkeeper/internal/app/server/api/http/session"
+	settingsAPI "gophkeeper/internal/app/server/api/http/settings"
+	shareAPI "gophkeeper/internal/app/server/api/http/share"
+	statsAPI "gophkeeper/internal/app/server/api/http/stats"
 	syncAPI "gophkeeper/internal/app/server/api/http/sync"
 	userAPI "gophkeeper/internal/app/server/api/http/user"
+	"gophkeeper/internal/app/server/backup"
+	"gophkeeper/internal/app/server/maintenance"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/mfa"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/quota"
 	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
 	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/settings"
+	"gophkeeper/internal/domain/stats"
 	"gophkeeper/internal/domain/sync"
 	"gophkeeper/internal/domain/user"
-	"gophkeeper/internal/infrastructure/storage/postgres"
-
-	"github.com/jackc/pgx/v5/pgxpool"
+	"gophkeeper/internal/domain/viewer"
+	"gophkeeper/internal/infrastructure/storage"
+	"gophkeeper/internal/utils/version"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/adapters/humachi"
@@ -36,65 +139,320 @@ import (
 )
 
 type Handlers struct {
-	Health *healthAPI.Handler
-	User   *userAPI.Handler
-	Record *recordAPI.Handler
-	Sync   *syncAPI.Handler
+	Health   *healthAPI.Handler
+	User     *userAPI.Handler
+	Record   *recordAPI.Handler
+	Blob     *blobAPI.Handler
+	Sync     *syncAPI.Handler
+	Settings *settingsAPI.Handler
+	Backup   *backupAPI.Handler
+	Org      *orgAPI.Handler
+	Folder   *folderAPI.Handler
+	Quota    *quotaAPI.Handler
+	Stats    *statsAPI.Handler
+	MFA      *mfaAPI.Handler
+	Password *passwordAPI.Handler
+	Session  *sessionAPI.Handler
+	KeyFile  *keyfileAPI.Handler
+	Share    *shareAPI.Handler
+	Shared   *shareAPI.ViewerHandler
+	Links    *shareAPI.LinkHandler
+	Secrets  *shareAPI.SecretHandler
+	Account  *accountAPI.Handler
+
+	Maintenance *maintenanceAPI.Handler
+	QuotaAdmin  *quotaAPI.AdminHandler
+	SyncAdmin   *syncAPI.AdminHandler
+	Admin       *adminAPI.Handler
+
+	// Usage считает трафик и операции устройств; его net/http мидлварь
+	// подключается к mux отдельно
+	Usage *usage.Meter
+	// Logger пишет журнал доступа; как и Usage, подключается к mux отдельно
+	Logger *logger.Logger
+}
+
+// HTTPConfig - параметры net/http мидлварей, общих для всех маршрутов
+type HTTPConfig struct {
+	// MinClientVersion - клиенты старше этой версии получают 426; нулевая - без проверки
+	MinClientVersion version.Version
+	// MaxBodySize ограничивает тело любого запроса в байтах по сети; 0 - без ограничения
+	MaxBodySize int64
+	// CORS - источники браузерных клиентов; nil или пустой список - без CORS
+	CORS *cors.Config
 }
 
 // New создает *chi.Mux с ВСЕМИ операциями через huma.Register
-func New(pool *pgxpool.Pool, log *slog.Logger) *chi.Mux {
+// backupService обслуживает admin API резервного копирования; доступ к нему
+// открывается только при непустом adminToken. accountService удаляет и
+// экспортирует учетную запись пользователя. mode - режим обслуживания:
+// пока он включен, изменяющие запросы получают 503. httpConfig задает
+// общие для всех запросов проверки: версию клиента, размер тела и CORS.
+func New(repos *storage.Repositories, log *slog.Logger, syncConfig *sync.ServiceConfig,
+	backupService backup.Servicer, accountService account.Servicer, adminToken string, mode *maintenance.Mode,
+	httpConfig *HTTPConfig, scanner blob.Scanner) *chi.Mux {
+	h := handlers(repos, log, syncConfig, backupService, accountService, adminToken, mode, scanner)
+
+	corsConfig := httpConfig.CORS
+	if corsConfig == nil {
+		corsConfig = cors.DefaultConfig()
+	}
+
 	mux := chi.NewMux()
+	// net/http мидлвари работают для всех запросов и должны стоять до
+	// регистрации маршрутов. Идентификатор запроса назначается первым, чтобы
+	// попасть во все записи журнала; журнал доступа стоит до recoverer,
+	// чтобы записать ответ 500 на панику. CORS отвечает на предварительные
+	// запросы браузера до проверок тела и версии. Лимит тела и учет трафика
+	// идут до сжатия, чтобы считать байты по сети, а не распакованные.
+	mux.Use(requestid.Handler)
+	mux.Use(clientinfo.Handler)
+	mux.Use(h.Logger.Handler)
+	mux.Use(recoverer.New(log).Handler)
+	mux.Use(cors.New(corsConfig).Handler)
+	mux.Use(bodylimit.New(httpConfig.MaxBodySize, log).Handler)
+	mux.Use(h.Usage.Handler)
+	mux.Use(compress.New(log).Handler)
+	mux.Use(clientversion.New(httpConfig.MinClientVersion, log).Handler)
+
+	// Ответы с ошибкой, в том числе созданные самой huma, несут машинно-читаемый код
+	problem.Install()
 
 	config := huma.DefaultConfig("Gophkeeper API", "1.0.0")
+	config.Components.Schemas = huma.NewMapRegistry("#/components/schemas/", schemaNamer())
 	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
 		"bearer": {Type: "http", Scheme: "bearer"},
 	}
+	config.Transformers = append(config.Transformers, problem.Transformer)
 
 	API := humachi.New(mux, config)
 
-	h := handlers(pool, log)
 	h.Health.SetupRoutes(API)
 	h.User.SetupRoutes(API)
 	h.Record.SetupRoutes(API)
+	h.Blob.SetupRoutes(API)
 	h.Sync.SetupRoutes(API)
+	h.Settings.SetupRoutes(API)
+	h.Backup.SetupRoutes(API)
+	h.Org.SetupRoutes(API)
+	h.Folder.SetupRoutes(API)
+	h.Maintenance.SetupRoutes(API)
+	h.Quota.SetupRoutes(API)
+	h.Stats.SetupRoutes(API)
+	h.KeyFile.SetupRoutes(API)
+	h.Share.SetupRoutes(API)
+	h.Shared.SetupRoutes(API)
+	h.Links.SetupRoutes(API)
+	h.Secrets.SetupRoutes(API)
+	h.Account.SetupRoutes(API)
+	h.MFA.SetupRoutes(API)
+	h.Password.SetupRoutes(API)
+	h.Session.SetupRoutes(API)
+	h.QuotaAdmin.SetupRoutes(API)
+	h.SyncAdmin.SetupRoutes(API)
+	h.Admin.SetupRoutes(API)
 
 	return mux
 }
 
-func handlers(pool *pgxpool.Pool, log *slog.Logger) *Handlers {
-	sessionRepo := postgres.NewSessionRepository(pool, log)
-	sessionService := session.NewService(sessionRepo, log)
+func handlers(repos *storage.Repositories, log *slog.Logger, syncConfig *sync.ServiceConfig,
+	backupService backup.Servicer, accountService account.Servicer, adminToken string, mode *maintenance.Mode,
+	scanner blob.Scanner) *Handlers {
+	sessionService := session.NewService(repos.Sessions, log)
 	authMW := auth.New(sessionService, log)
 	loggerMW := logger.New(log)
+	readOnlyMW := maintenanceMW.New(mode, log)
 	middlewares := middleware.NewContainer()
 
 	middlewares.Add(loggerMW.Middleware())
-	healthHandler := healthAPI.NewHandler(log, middlewares.GetAllAndClear())
+	healthHandler := healthAPI.NewHandler(mode, log, middlewares.GetAllAndClear())
 
-	userRepo := postgres.NewUserRepository(pool, log)
 	userValidator := user.NewPasswordValidator()
-	userService := user.NewService(userRepo, userValidator, log)
+	userService := user.NewService(repos.Users, userValidator, log)
+	mfaService := mfa.NewService(repos.MFA, log)
 	middlewares.Add(loggerMW.Middleware())
-	userHandler := userAPI.NewHandler(userService, sessionService, log, middlewares.GetAllAndClear())
+	middlewares.Add(readOnlyMW.Middleware())
+	userHandler := userAPI.NewHandler(userService, sessionService, mfaService, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	mfaHandler := mfaAPI.NewHandler(mfaService, userService, sessionService, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	passwordHandler := passwordAPI.NewHandler(userService, sessionService, mfaService, log, middlewares.GetAllAndClear())
+
+	syncService := sync.NewService(repos.Sync, log, syncConfig)
+	usageMW := usage.New(syncService, log)
+	// Неподтвержденные устройства не получают доступ к записям, файлам,
+	// синхронизации, сессиям, ключевому файлу, папкам, настройкам и статистике
+	deviceMW := devicetrust.New(syncService, log)
+
+	// Завершить чужую сессию можно и в режиме обслуживания
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	sessionHandler := sessionAPI.NewHandler(sessionService, log, middlewares.GetAllAndClear())
 
-	recordRepo := postgres.NewRecordRepository(pool, log)
 	recordFactory := record.NewFactory()
-	recordService := record.NewService(recordRepo, recordFactory, log)
+	membershipService := membership.NewService(repos.Memberships, log)
+	quotaService := quota.NewService(repos.Quotas, syncConfig.StorageLimit, log)
+	recordService := record.NewService(repos.Records, recordFactory, membershipService, quotaService, log)
 	middlewares.Add(authMW.Middleware())
+	middlewares.Add(usageMW.Middleware())
 	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
 	recordHandler := recordAPI.NewHandler(recordService, log, middlewares.GetAllAndClear())
 
-	syncRepo := postgres.NewSyncRepository(pool, log)
-	syncService := sync.NewService(syncRepo, log, nil)
+	blobService := blob.NewService(repos.Blobs, quotaService, scanner, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(usageMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	blobHandler := blobAPI.NewHandler(blobService, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	quotaHandler := quotaAPI.NewHandler(quotaService, log, middlewares.GetAllAndClear())
+
+	statsService := stats.NewService(repos.Stats, recordService, repos.Sync, log)
 	middlewares.Add(authMW.Middleware())
 	middlewares.Add(loggerMW.Middleware())
-	syncHandler := syncAPI.NewHandler(syncService, log, middlewares.GetAllAndClear())
+	middlewares.Add(deviceMW.Middleware())
+	statsHandler := statsAPI.NewHandler(statsService, log, middlewares.GetAllAndClear())
+
+	orgService := org.NewService(repos.Orgs, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	orgHandler := orgAPI.NewHandler(orgService, membershipService, recordService, log, middlewares.GetAllAndClear())
+
+	folderService := folder.NewService(repos.Folders, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	folderHandler := folderAPI.NewHandler(folderService, log, middlewares.GetAllAndClear())
+
+	syncRollout := sync.NewRollout(repos.SyncRollout, syncConfig.V2RolloutPercent, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(usageMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	syncHandler := syncAPI.NewHandler(syncService, syncRollout, log, middlewares.GetAllAndClear())
+
+	settingsService := settings.NewService(repos.Settings, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	settingsHandler := settingsAPI.NewHandler(settingsService, log, middlewares.GetAllAndClear())
+
+	keyFileService := keyfile.NewService(repos.KeyFiles, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	keyFileHandler := keyfileAPI.NewHandler(keyFileService, log, middlewares.GetAllAndClear())
+
+	viewerService := viewer.NewService(repos.Viewers, repos.Records, repos.Folders, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	shareHandler := shareAPI.NewHandler(viewerService, log, middlewares.GetAllAndClear())
+
+	// Токен просмотра открывает только чтение /api/shared, поэтому режим
+	// обслуживания и проверка устройства здесь не нужны
+	viewerMW := viewertoken.New(viewerService, log)
+	middlewares.Add(viewerMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	sharedHandler := shareAPI.NewViewerHandler(viewerService, log, middlewares.GetAllAndClear())
+
+	secretLinkService := secretlink.NewService(repos.SecretLinks, log)
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	linkHandler := shareAPI.NewLinkHandler(secretLinkService, log, middlewares.GetAllAndClear())
+
+	// Секрет открывает получатель без учетной записи. Просмотр изменяет
+	// данные, поэтому в режиме обслуживания он тоже недоступен.
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	secretHandler := shareAPI.NewSecretHandler(secretLinkService, log, middlewares.GetAllAndClear())
+
+	// Экспорт отдает все данные, поэтому нужен подтвержденный доступ устройства
+	middlewares.Add(authMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	middlewares.Add(deviceMW.Middleware())
+	middlewares.Add(readOnlyMW.Middleware())
+	accountHandler := accountAPI.NewHandler(accountService, log, middlewares.GetAllAndClear())
+
+	// Кроме X-Admin-Token admin API принимает сессии пользователей с ролью admin
+	adminMW := admin.New(adminToken, log).WithRole(authMW, userService, user.RoleAdmin)
+	middlewares.Add(adminMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	backupHandler := backupAPI.NewHandler(backupService, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(adminMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	maintenanceHandler := maintenanceAPI.NewHandler(mode, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(adminMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	quotaAdminHandler := quotaAPI.NewAdminHandler(quotaService, log, middlewares.GetAllAndClear())
+
+	middlewares.Add(adminMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	syncAdminHandler := syncAPI.NewAdminHandler(syncRollout, log, middlewares.GetAllAndClear())
+
+	accountAdmin := adminService.NewService(adminService.Repositories{
+		Users:    repos.Users,
+		Sessions: repos.Sessions,
+		Usage:    repos.Quotas,
+		Devices:  repos.Sync,
+		Records:  repos.Records,
+	}, log)
+	middlewares.Add(adminMW.Middleware())
+	middlewares.Add(loggerMW.Middleware())
+	adminHandler := adminAPI.NewHandler(accountAdmin, log, middlewares.GetAllAndClear())
 
 	return &Handlers{
-		Health: healthHandler,
-		User:   userHandler,
-		Record: recordHandler,
-		Sync:   syncHandler,
+		Health:   healthHandler,
+		User:     userHandler,
+		Record:   recordHandler,
+		Blob:     blobHandler,
+		Sync:     syncHandler,
+		Settings: settingsHandler,
+		Backup:   backupHandler,
+		Org:      orgHandler,
+		Folder:   folderHandler,
+		Quota:    quotaHandler,
+		Stats:    statsHandler,
+		MFA:      mfaHandler,
+		Password: passwordHandler,
+		Session:  sessionHandler,
+		KeyFile:  keyFileHandler,
+		Share:    shareHandler,
+		Shared:   sharedHandler,
+		Links:    linkHandler,
+		Secrets:  secretHandler,
+		Account:  accountHandler,
+
+		Maintenance: maintenanceHandler,
+		QuotaAdmin:  quotaAdminHandler,
+		SyncAdmin:   syncAdminHandler,
+		Admin:       adminHandler,
+
+		Usage:  usageMW,
+		Logger: loggerMW,
 	}
 }
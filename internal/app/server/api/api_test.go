@@ -0,0 +1,820 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/account"
+	"gophkeeper/internal/app/server/api/http/middleware/cors"
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/app/server/maintenance"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/infrastructure/migration"
+	"gophkeeper/internal/infrastructure/storage/sqlite"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// newTestRouter собирает маршрутизатор поверх SQLite с примененными миграциями
+func newTestRouter(t *testing.T, adminToken string) http.Handler {
+	t.Helper()
+	return newTestRouterWith(t, adminToken, &HTTPConfig{})
+}
+
+// newTestRouterWith - newTestRouter с параметрами общих мидлварей
+func newTestRouterWith(t *testing.T, adminToken string, httpConfig *HTTPConfig) http.Handler {
+	t.Helper()
+	return newTestRouterWithSync(t, adminToken, httpConfig, &sync.ServiceConfig{StorageLimit: 1 << 20})
+}
+
+// newTestRouterWithSync - newTestRouterWith с параметрами синхронизации
+func newTestRouterWithSync(t *testing.T, adminToken string, httpConfig *HTTPConfig, syncConfig *sync.ServiceConfig) http.Handler {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.SQLitePath = filepath.Join(t.TempDir(), "gophkeeper.db")
+	require.NoError(t, migration.NewMigration(cfg, migration.DefaultEngine).Up())
+	db, err := sqlite.Open(cfg.DB.SQLitePath)
+	require.NoError(t, err)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := sqlite.NewRepositories(db, log)
+	t.Cleanup(repos.Close)
+
+	var mux http.Handler
+	require.NotPanics(t, func() {
+		accounts := account.NewService(account.Repositories{
+			Users:    repos.Users,
+			Records:  repos.Backups,
+			History:  repos.Records,
+			Blobs:    repos.Blobs,
+			Folders:  repos.Folders,
+			Settings: repos.Settings,
+			KeyFiles: repos.KeyFiles,
+			Sync:     repos.Sync,
+		}, nil, log)
+		mux = New(repos, log, syncConfig, nil, accounts, adminToken, maintenance.New(&maintenance.Config{}), httpConfig, nil)
+	})
+	return mux
+}
+
+// TestNew регистрирует все операции: huma паникует при конфликте имен схем
+func TestNew(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var spec struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+	// maintenance.Status регистрируется первым, одноименные типы - с префиксом пакета
+	for _, name := range []string{"Status", "SyncStatus", "MfaStatus"} {
+		assert.Contains(t, spec.Components.Schemas, name)
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	const adminToken = "admin-secret"
+	mux := newTestRouter(t, adminToken)
+
+	do := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	login := func() (token, code string) {
+		var resp struct {
+			Token string `json:"token"`
+			Code  string `json:"code"`
+		}
+		decode(do(http.MethodPost, "/user/login", credentials, nil), &resp)
+		return resp.Token, resp.Code
+	}
+
+	var registered struct {
+		ID     int    `json:"user_id"`
+		Status string `json:"status"`
+	}
+	decode(do(http.MethodPost, "/user/register", credentials, nil), &registered)
+	require.Equal(t, "Ok", registered.Status)
+	userPath := "/api/v1/admin/users/" + strconv.Itoa(registered.ID)
+
+	token, _ := login()
+	require.NotEmpty(t, token)
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+	byToken := map[string]string{"X-Admin-Token": adminToken}
+
+	// Обычный пользователь не получает доступ к admin API
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/admin/users", "", bearer).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/admin/users", "", nil).Code)
+
+	rec := do(http.MethodGet, "/api/v1/admin/users", "", byToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var list struct {
+		Users []struct {
+			Login string `json:"login"`
+			Role  string `json:"role"`
+		} `json:"users"`
+	}
+	decode(rec, &list)
+	require.Len(t, list.Users, 1)
+	assert.Equal(t, "alice", list.Users[0].Login)
+	assert.Equal(t, "user", list.Users[0].Role)
+
+	// После назначения роли сессия пользователя открывает admin API
+	rec = do(http.MethodPost, userPath+"/role", `{"role":"admin"}`, byToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/admin/users", "", bearer).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/admin/users/999", "", bearer).Code)
+
+	// Блокировка завершает сессии и запрещает вход
+	rec = do(http.MethodPost, userPath+"/disable", "", byToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/account/quota", "", bearer).Code)
+	_, code := login()
+	assert.Equal(t, "ACCOUNT_DISABLED", code)
+
+	rec = do(http.MethodPost, userPath+"/enable", "", byToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	token, _ = login()
+	assert.NotEmpty(t, token)
+
+	// Временный пароль заменяет прежний
+	rec = do(http.MethodPost, userPath+"/password-reset", "", byToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var reset struct {
+		TemporaryPassword string `json:"temporary_password"`
+	}
+	decode(rec, &reset)
+	token, _ = login()
+	assert.Empty(t, token)
+
+	rec = do(http.MethodPost, "/api/v1/admin/purge", `{"older_than":"720h"}`, byToken)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = do(http.MethodPost, "/api/v1/admin/purge", `{"older_than":"soon"}`, byToken)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestAccountAPI(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials, nil).Code)
+	var auth struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials, nil).Body.Bytes(), &auth))
+	bearer := map[string]string{"Authorization": "Bearer " + auth.Token}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodDelete, "/api/v1/account", "", nil).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/account/deletion", "", bearer).Code)
+
+	// Экспорт отдается zip-архивом
+	rec := do(http.MethodGet, "/api/v1/account/export", "", bearer)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	_, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+
+	rec = do(http.MethodDelete, "/api/v1/account", "", bearer)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var deletion struct {
+		DeleteAfter time.Time `json:"delete_after"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deletion))
+	assert.WithinDuration(t, time.Now().Add(account.DefaultConfig().GracePeriod), deletion.DeleteAfter, time.Minute)
+
+	// До истечения срока учетная запись работает и удаление можно отменить
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/account/deletion", "", bearer).Code)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/account/deletion", "", bearer).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/v1/account/deletion", "", bearer).Code)
+}
+
+func TestAccountStats(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials, "").Code)
+	var auth struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials, "").Body.Bytes(), &auth))
+
+	for _, data := range []string{"abcd", "abce"} {
+		rec := do(http.MethodPost, "/api/records", `{"type":"text","data":"`+data+`","meta":{"title":"note"}}`, auth.Token)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/account/stats", "", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(http.MethodGet, "/api/account/stats?days=366", "", auth.Token).Code)
+
+	rec := do(http.MethodGet, "/api/account/stats?days=7", "", auth.Token)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var stats struct {
+		Records struct {
+			TotalRecords int64 `json:"total_records"`
+			ActiveDays   int   `json:"active_days"`
+			ByType       map[string]struct {
+				Count int64 `json:"count"`
+			} `json:"by_type"`
+		} `json:"records"`
+		Days   int `json:"days"`
+		Growth []struct {
+			Day     string `json:"day"`
+			Created int64  `json:"created"`
+			Records int64  `json:"records"`
+		} `json:"growth"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, int64(2), stats.Records.TotalRecords)
+	assert.Equal(t, int64(2), stats.Records.ByType["text"].Count)
+	assert.Equal(t, 1, stats.Records.ActiveDays)
+	assert.Equal(t, 7, stats.Days)
+	require.Len(t, stats.Growth, 7)
+	today := stats.Growth[6]
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), today.Day)
+	assert.Equal(t, int64(2), today.Created)
+	assert.Equal(t, int64(2), today.Records)
+	assert.Zero(t, stats.Growth[0].Records)
+}
+
+func TestAccountSessions(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body, token, device string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "gophkeeper-test/"+device)
+		req.Header.Set("X-Device-ID", device)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	login := func(credentials, device string) string {
+		var auth struct {
+			Token string `json:"token"`
+		}
+		rec := do(http.MethodPost, "/user/login", credentials, "", device)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &auth))
+		return auth.Token
+	}
+	type sessionItem struct {
+		ID        int    `json:"id"`
+		IPAddress string `json:"ip_address"`
+		UserAgent string `json:"user_agent"`
+		Current   bool   `json:"current"`
+	}
+	list := func(token string) []sessionItem {
+		rec := do(http.MethodGet, "/api/account/sessions", "", token, "laptop")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var items []sessionItem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &items))
+		assert.NotContains(t, rec.Body.String(), "device_id", "UUID устройства не отдается")
+		return items
+	}
+
+	const alice = `{"login":"alice","password":"Secret-123"}`
+	const bob = `{"login":"bob","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", alice, "", "laptop").Code)
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", bob, "", "phone").Code)
+	laptop := login(alice, "laptop")
+	phone := login(alice, "phone")
+	tablet := login(alice, "tablet")
+	bobToken := login(bob, "phone")
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/account/sessions", "", "", "laptop").Code)
+
+	items := list(laptop)
+	require.Len(t, items, 3)
+	var current, phoneSession sessionItem
+	for _, item := range items {
+		if item.Current {
+			current = item
+		}
+		if item.UserAgent == "gophkeeper-test/phone" {
+			phoneSession = item
+		}
+	}
+	assert.Equal(t, "gophkeeper-test/laptop", current.UserAgent)
+	assert.NotEmpty(t, current.IPAddress)
+	require.NotZero(t, phoneSession.ID)
+
+	// Чужую сессию завершить нельзя
+	bobItems := list(bobToken)
+	require.NotEmpty(t, bobItems)
+	rec := do(http.MethodDelete, "/api/account/sessions/"+strconv.Itoa(bobItems[0].ID), "", laptop, "laptop")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "SESSION_NOT_FOUND")
+
+	rec = do(http.MethodDelete, "/api/account/sessions/"+strconv.Itoa(phoneSession.ID), "", laptop, "laptop")
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/account/sessions", "", phone, "phone").Code)
+
+	rec = do(http.MethodPost, "/api/account/sessions/revoke-others", "", laptop, "laptop")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"revoked":1`)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/account/sessions", "", tablet, "tablet").Code)
+
+	items = list(laptop)
+	require.Len(t, items, 1)
+	assert.True(t, items[0].Current)
+	assert.Len(t, list(bobToken), 1, "сессии других пользователей не затронуты")
+}
+
+func TestDeviceSignatures(t *testing.T) {
+	mux := newTestRouterWithSync(t, "", &HTTPConfig{}, &sync.ServiceConfig{StorageLimit: 1 << 20, DeviceApproval: true})
+
+	type device struct {
+		uuid   string
+		public string
+		key    ed25519.PrivateKey
+	}
+	newDevice := func(id string) device {
+		public, key, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		return device{uuid: id, public: base64.StdEncoding.EncodeToString(public), key: key}
+	}
+	laptop := newDevice("0f8fad5b-d9cb-469f-a165-70867728950e")
+	phone := newDevice("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+
+	var token string
+	// doSigned выполняет запрос с телом body от имени устройства uuid,
+	// подписанный ключом key (nil - без подписи) для тела signed
+	doSigned := func(method, path, body, signed, uuid string, key ed25519.PrivateKey) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if uuid != "" {
+			req.Header.Set(sync.DeviceHeader, uuid)
+		}
+		if key != nil {
+			timestamp, signature := sync.SignDeviceRequest(key, method, path, uuid, []byte(signed), time.Now())
+			req.Header.Set(sync.DeviceTimestampHeader, timestamp)
+			req.Header.Set(sync.DeviceSignatureHeader, signature)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	// do выполняет запрос от имени устройства uuid с подписью ключом key (nil - без подписи)
+	do := func(method, path, body, uuid string, key ed25519.PrivateKey) *httptest.ResponseRecorder {
+		return doSigned(method, path, body, body, uuid, key)
+	}
+	register := func(d device) int {
+		body := `{"uuid":"` + d.uuid + `","name":"` + d.uuid[:8] + `","public_key":"` + d.public + `"}`
+		rec := do(http.MethodPost, "/api/sync/devices/register", body, d.uuid, nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			Data struct {
+				ID int `json:"id"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data.ID
+	}
+
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials, "", nil).Code)
+	var auth struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials, "", nil).Body.Bytes(), &auth))
+	token = auth.Token
+
+	register(laptop)
+	phoneID := register(phone)
+	approvePath := "/api/sync/devices/" + strconv.Itoa(phoneID) + "/approve"
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/records", "", laptop.uuid, laptop.key).Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/records", "", phone.uuid, phone.key).Code)
+
+	// Ключевой файл, папки, настройки, квота и статистика тоже закрыты
+	// для неподтвержденного устройства
+	for _, path := range []string{"/api/account/key-file", "/api/folders", "/api/settings", "/api/account/quota", "/api/account/stats"} {
+		rec := do(http.MethodGet, path, "", phone.uuid, phone.key)
+		assert.Equal(t, http.StatusForbidden, rec.Code, path)
+		assert.Equal(t, string(sync.DevicePending), rec.Header().Get(sync.DeviceStatusHeader), path)
+		assert.NotEqual(t, http.StatusForbidden, do(http.MethodGet, path, "", laptop.uuid, laptop.key).Code, path)
+	}
+	writes := []struct{ method, path string }{
+		{http.MethodPost, "/api/folders"},
+		{http.MethodPatch, "/api/settings"},
+		{http.MethodPut, "/api/account/key-file"},
+	}
+	for _, w := range writes {
+		assert.Equal(t, http.StatusForbidden, do(w.method, w.path, `{}`, phone.uuid, phone.key).Code, w.path)
+	}
+
+	// UUID подтвержденного ноутбука без его ключа ничего не дает
+	rec := do(http.MethodGet, "/api/records", "", laptop.uuid, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "DEVICE_SIGNATURE_INVALID")
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/account/sessions", "", laptop.uuid, nil).Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/account/sessions", "", laptop.uuid, laptop.key).Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/records", "", laptop.uuid, phone.key).Code)
+	confirmed := `{"fingerprint":"` + sync.DeviceFingerprint(phone.public) + `"}`
+	rec = do(http.MethodPost, approvePath, confirmed, laptop.uuid, phone.key)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "DEVICE_NOT_TRUSTED")
+
+	// Без сверки отпечатка ключа устройство не подтверждается
+	assert.Equal(t, http.StatusUnprocessableEntity, do(http.MethodPost, approvePath, `{}`, laptop.uuid, laptop.key).Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/records", "", phone.uuid, phone.key).Code)
+
+	rec = do(http.MethodPost, approvePath, confirmed, laptop.uuid, laptop.key)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/records", "", phone.uuid, phone.key).Code)
+
+	// Подпись, перехваченная с запросом, не подходит к другому телу того же запроса
+	rec = doSigned(http.MethodPost, "/api/folders", `{"name":"Stolen"}`, `{"name":"Work"}`, laptop.uuid, laptop.key)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "DEVICE_SIGNATURE_INVALID")
+	rec = do(http.MethodPost, "/api/folders", `{"name":"Work"}`, laptop.uuid, laptop.key)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"Work"`)
+
+	// Регистрация с UUID ноутбука и чужим ключом, не подписанная ключом
+	// ноутбука, не получает доверия, а снимает его
+	intruder := newDevice(laptop.uuid)
+	body := `{"uuid":"` + laptop.uuid + `","name":"laptop","public_key":"` + intruder.public + `"}`
+	rec = do(http.MethodPost, "/api/sync/devices/register", body, laptop.uuid, intruder.key)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/records", "", laptop.uuid, intruder.key).Code)
+}
+
+func TestPasswordChangeAPI(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	type tokenResponse struct {
+		Token string `json:"token"`
+	}
+	// При неверном пароле /user/login отвечает 401 без токена
+	login := func(password string) string {
+		rec := do(http.MethodPost, "/user/login", `{"login":"alice","password":"`+password+`"}`, nil)
+		var resp tokenResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp.Token
+	}
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", `{"login":"alice","password":"Secret-123"}`, nil).Code)
+	first := login("Secret-123")
+	second := login("Secret-123")
+
+	rec := do(http.MethodPost, "/api/account/password", `{"old_password":"wrong","new_password":"Secret-456"}`, bearer(first))
+	assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+
+	rec = do(http.MethodPost, "/api/account/password", `{"old_password":"Secret-123","new_password":"Secret-456"}`, bearer(first))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var changed tokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &changed))
+	require.NotEmpty(t, changed.Token)
+
+	// Все прежние сессии завершены, новый токен работает
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/account/deletion", "", bearer(first)).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/account/deletion", "", bearer(second)).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/account/deletion", "", bearer(changed.Token)).Code)
+
+	assert.Empty(t, login("Secret-123"))
+	assert.NotEmpty(t, login("Secret-456"))
+
+	// Файл ключа после смены мастер-пароля заменяется по прежнему хэшу
+	oldHash, newHash := strings.Repeat("ab", 32), strings.Repeat("cd", 32)
+	put := func(hash, previous string) int {
+		body := `{"data":"AQID","key_hash":"` + hash + `","previous_key_hash":"` + previous + `"}`
+		return do(http.MethodPut, "/api/account/key-file", body, bearer(changed.Token)).Code
+	}
+	require.Equal(t, http.StatusOK, put(oldHash, ""))
+	assert.Equal(t, http.StatusConflict, put(newHash, ""))
+	assert.Equal(t, http.StatusOK, put(newHash, oldHash))
+	assert.Equal(t, http.StatusConflict, put(oldHash, ""))
+}
+
+func TestErrorCodes(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	// Ошибки отдаются единым конвертом с машиночитаемым кодом
+	problem := func(rec *httptest.ResponseRecorder, status int, code string) {
+		t.Helper()
+		require.Equal(t, status, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		var body struct {
+			Status    int    `json:"status"`
+			Code      string `json:"code"`
+			Detail    string `json:"detail"`
+			RequestID string `json:"request_id"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+		assert.Equal(t, status, body.Status)
+		assert.Equal(t, code, body.Code)
+		assert.NotEmpty(t, body.Detail)
+		// По идентификатору из ответа ошибку можно найти в журнале сервера
+		assert.NotEmpty(t, body.RequestID)
+		assert.Equal(t, rec.Header().Get("X-Request-ID"), body.RequestID)
+	}
+
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials, nil).Code)
+	problem(do(http.MethodPost, "/user/register", credentials, nil), http.StatusConflict, "LOGIN_TAKEN")
+	problem(do(http.MethodPost, "/user/login", `{"login":"alice","password":"wrong"}`, nil), http.StatusUnauthorized, "INVALID_CREDENTIALS")
+	problem(do(http.MethodPost, "/user/login", `{"login":"bob","password":"Secret-123"}`, nil), http.StatusUnauthorized, "INVALID_CREDENTIALS")
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials, nil).Body.Bytes(), &auth))
+	bearer := map[string]string{"Authorization": "Bearer " + auth.Token}
+
+	problem(do(http.MethodGet, "/api/records/999", "", bearer), http.StatusNotFound, "RECORD_NOT_FOUND")
+	problem(do(http.MethodGet, "/api/records/999", "", map[string]string{"Authorization": "Bearer expired"}), http.StatusUnauthorized, "AUTH_EXPIRED")
+	problem(do(http.MethodGet, "/api/records/999", "", nil), http.StatusUnauthorized, "UNAUTHORIZED")
+	problem(do(http.MethodGet, "/api/v1/account/deletion", "", bearer), http.StatusNotFound, "DELETION_NOT_SCHEDULED")
+}
+
+func TestSyncProtocolV2Changes(t *testing.T) {
+	newUser := func(percent int) func(method, path, body string) *httptest.ResponseRecorder {
+		syncConfig := sync.DefaultServiceConfig()
+		syncConfig.DeviceApproval = false
+		syncConfig.V2RolloutPercent = percent
+		mux := newTestRouterWithSync(t, "", &HTTPConfig{}, syncConfig)
+		var token string
+		do := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec
+		}
+		const credentials = `{"login":"alice","password":"Secret-123"}`
+		require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials).Code)
+		var auth struct {
+			Token string `json:"token"`
+		}
+		require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials).Body.Bytes(), &auth))
+		token = auth.Token
+		return do
+	}
+	type problemBody struct {
+		Code string `json:"code"`
+	}
+	// changes - тело запроса ленты изменений
+	changes := func(cursor string, offset int) string {
+		return `{"cursor":"` + cursor + `","last_sync_time":"2000-01-01T00:00:00Z","limit":100,"offset":` + strconv.Itoa(offset) + `}`
+	}
+
+	do := newUser(100)
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/records", `{"type":"text","data":"abcd","meta":{"title":"note"}}`).Code)
+
+	// v1 принимает устаревший offset, v2 - только курсор, с кодом ошибки
+	// в том же формате, что у остальных операций
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/sync/changes", changes("", 1)).Code)
+	rec := do(http.MethodPost, "/api/v2/sync/changes", changes("", 1))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	var body problemBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "INVALID_SYNC_CURSOR", body.Code)
+
+	// Курсор из ответа одинаково продолжает выборку в обоих протоколах
+	rec = do(http.MethodPost, "/api/v2/sync/changes", changes("", 0))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page struct {
+		Records    []json.RawMessage `json:"records"`
+		NextCursor string            `json:"next_cursor"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Len(t, page.Records, 1)
+	require.NotEmpty(t, page.NextCursor)
+	next := changes(page.NextCursor, 0)
+	for _, path := range []string{"/api/sync/changes", "/api/v2/sync/changes"} {
+		rec = do(http.MethodPost, path, next)
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		page.Records = nil
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Empty(t, page.Records, path)
+	}
+
+	// Пользователю на v1 лента v2 недоступна
+	do = newUser(0)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/sync/changes", changes("", 1)).Code)
+	rec = do(http.MethodPost, "/api/v2/sync/changes", changes("", 0))
+	require.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "SYNC_PROTOCOL_DISABLED", body.Code)
+	assert.Equal(t, string(sync.ProtocolV1), rec.Header().Get(sync.ProtocolHeader))
+}
+
+func TestRecordUUID(t *testing.T) {
+	mux := newTestRouter(t, "")
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	const credentials = `{"login":"alice","password":"Secret-123"}`
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/user/register", credentials, "").Code)
+	var auth struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/user/login", credentials, "").Body.Bytes(), &auth))
+
+	const recordUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	createWith := func(uuidField, data string) (int, string) {
+		t.Helper()
+		rec := do(http.MethodPost, "/api/records",
+			`{`+uuidField+`"type":"text","data":"`+data+`","meta":{"title":"note"}}`, auth.Token)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body struct {
+			ID   int    `json:"id"`
+			UUID string `json:"uuid"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.ID, body.UUID
+	}
+	create := func() int {
+		t.Helper()
+		id, got := createWith(`"uuid":"`+strings.ToUpper(recordUUID)+`",`, "abcd")
+		assert.Equal(t, recordUUID, got, "в ответе UUID сохраненной записи")
+		return id
+	}
+
+	// Повтор создания с тем же UUID возвращает ту же запись
+	id := create()
+	assert.Equal(t, id, create())
+
+	// Без UUID в запросе ответ содержит UUID, назначенный сервером
+	plainID, assigned := createWith("", "0123")
+	assert.NotEqual(t, id, plainID)
+	_, err := uuid.Parse(assigned)
+	assert.NoError(t, err, "assigned uuid %q", assigned)
+
+	// Запись доступна и по числовому ID, и по UUID
+	for _, ref := range []string{strconv.Itoa(id), recordUUID} {
+		rec := do(http.MethodGet, "/api/records/"+ref, "", auth.Token)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body struct {
+			Record struct {
+				ID   int    `json:"id"`
+				UUID string `json:"uuid"`
+			} `json:"record"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, id, body.Record.ID)
+		assert.Equal(t, recordUUID, body.Record.UUID)
+	}
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/records/6ba7b811-9dad-11d1-80b4-00c04fd430c8", "", auth.Token).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(http.MethodGet, "/api/records/note", "", auth.Token).Code)
+
+	// Пакет синхронизации узнает запись по UUID и не создает дубликат
+	batch := `{"records":[
+		{"id":0,"uuid":"` + recordUUID + `","user_id":0,"type":"text","encrypted_data":"abcd","meta":"e30=","version":1,"last_modified":"2026-01-01T00:00:00Z"},
+		{"id":0,"uuid":"6ba7b811-9dad-11d1-80b4-00c04fd430c8","user_id":0,"type":"text","encrypted_data":"abce","meta":"e30=","version":1,"last_modified":"2026-01-01T00:00:00Z"}
+	]}`
+	rec := do(http.MethodPost, "/api/sync/batch", batch, auth.Token)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result struct {
+		Results []struct {
+			Status string `json:"status"`
+			ID     int    `json:"id"`
+			UUID   string `json:"uuid"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, "saved", result.Results[0].Status)
+	assert.Equal(t, id, result.Results[0].ID)
+	assert.Equal(t, "saved", result.Results[1].Status)
+	assert.NotEqual(t, id, result.Results[1].ID)
+	assert.Equal(t, "6ba7b811-9dad-11d1-80b4-00c04fd430c8", result.Results[1].UUID)
+
+	rec = do(http.MethodGet, "/api/records/6ba7b811-9dad-11d1-80b4-00c04fd430c8", "", auth.Token)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestMiddlewareStack(t *testing.T) {
+	mux := newTestRouterWith(t, "", &HTTPConfig{
+		MaxBodySize: 1 << 10,
+		CORS:        &cors.Config{AllowedOrigins: []string{"https://vault.example.com"}, MaxAge: time.Minute},
+	})
+
+	t.Run("request id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("X-Request-ID", "client-req-1")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, "client-req-1", rec.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("CORS preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/records", nil)
+		req.Header.Set("Origin", "https://vault.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://vault.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("body limit", func(t *testing.T) {
+		body := `{"login":"alice","password":"` + strings.Repeat("a", 2048) + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/user/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), rec.Header().Get("X-Request-ID"))
+	})
+}
@@ -0,0 +1,13 @@
+package account
+
+import "gophkeeper/internal/app/server/account"
+
+type deletionOutput struct {
+	Body account.Deletion
+}
+
+type exportOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/app/server/account"
+	"gophkeeper/internal/app/server/api/http/httperr"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler удаляет и экспортирует учетную запись текущего пользователя
+type Handler struct {
+	service    account.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service account.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.deleteOp(), h.delete)
+	huma.Register(api, h.deletionOp(), h.deletion)
+	huma.Register(api, h.cancelOp(), h.cancel)
+	huma.Register(api, h.exportOp(), h.export)
+}
+
+func (h *Handler) delete(ctx context.Context, _ *struct{}) (*deletionOutput, error) {
+	deletion, err := h.service.ScheduleDeletion(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &deletionOutput{Body: *deletion}, nil
+}
+
+func (h *Handler) deletion(ctx context.Context, _ *struct{}) (*deletionOutput, error) {
+	deletion, err := h.service.Deletion(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &deletionOutput{Body: *deletion}, nil
+}
+
+func (h *Handler) cancel(ctx context.Context, _ *struct{}) (*struct{}, error) {
+	if err := h.service.CancelDeletion(ctx); err != nil {
+		return nil, h.mapError(err)
+	}
+	return nil, nil
+}
+
+func (h *Handler) export(ctx context.Context, _ *struct{}) (*exportOutput, error) {
+	var buf bytes.Buffer
+	if err := h.service.Export(ctx, &buf); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	name := fmt.Sprintf("gophkeeper-export-%s.zip", time.Now().UTC().Format("20060102T150405Z"))
+	return &exportOutput{
+		ContentType:        "application/zip",
+		ContentDisposition: fmt.Sprintf("attachment; filename=%q", name),
+		Body:               buf.Bytes(),
+	}, nil
+}
+
+func (h *Handler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("account operation failed", "error", err)
+	return huma.Error500InternalServerError("account operation failed")
+}
@@ -0,0 +1,60 @@
+package account
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) deleteOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "account-delete",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/account",
+		Summary:       "Удалить учетную запись",
+		Description:   "Планирует удаление учетной записи со всеми записями, версиями, конфликтами, устройствами и сессиями. До delete_after удаление можно отменить; повторный запрос срок не сдвигает.",
+		Tags:          []string{"account"},
+		DefaultStatus: http.StatusAccepted,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) deletionOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-deletion-get",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/account/deletion",
+		Summary:     "Запланированное удаление",
+		Description: "Возвращает срок удаления учетной записи или 404, если удаление не запланировано.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) cancelOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "account-deletion-cancel",
+		Method:        http.MethodDelete,
+		Path:          "/api/v1/account/deletion",
+		Summary:       "Отменить удаление учетной записи",
+		Tags:          []string{"account"},
+		DefaultStatus: http.StatusNoContent,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) exportOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-export",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/account/export",
+		Summary:     "Экспорт всех данных",
+		Description: "Возвращает zip-архив с записями (включая корзину), версиями, вложениями, файлами, папками, настройками, файлом ключа, устройствами и конфликтами. Данные остаются зашифрованными ключом пользователя.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"time"
+
+	"gophkeeper/internal/app/server/admin"
+)
+
+type userInput struct {
+	ID int `path:"id" minimum:"1" doc:"ID пользователя"`
+}
+
+type userOutput struct {
+	Body admin.UserInfo
+}
+
+type usersOutput struct {
+	Body usersResponse
+}
+
+type usersResponse struct {
+	Users []admin.UserInfo `json:"users"`
+}
+
+type roleInput struct {
+	ID   int `path:"id" minimum:"1" doc:"ID пользователя"`
+	Body roleRequest
+}
+
+type roleRequest struct {
+	Role string `json:"role" enum:"user,admin" doc:"Новая роль пользователя"`
+}
+
+type passwordResetOutput struct {
+	Body admin.PasswordReset
+}
+
+type purgeInput struct {
+	Body purgeRequest
+}
+
+type purgeRequest struct {
+	UserID    int    `json:"user_id,omitempty" minimum:"0" doc:"ID пользователя; 0 или пусто - все пользователи"`
+	OlderThan string `json:"older_than,omitempty" doc:"Удалить записи, лежащие в корзине дольше этого срока (Go duration, например 720h); пусто - все"`
+}
+
+type purgeOutput struct {
+	Body admin.PurgeResult
+}
+
+// olderThan разбирает срок очистки; пустая строка - все удаленные записи
+func (r purgeRequest) olderThan() (time.Duration, error) {
+	if r.OlderThan == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.OlderThan)
+}
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/admin"
+	"gophkeeper/internal/app/server/api/http/httperr"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler управляет учетными записями пользователей через /api/v1/admin
+type Handler struct {
+	service    admin.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service admin.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.disableOp(), h.disable)
+	huma.Register(api, h.enableOp(), h.enable)
+	huma.Register(api, h.resetPasswordOp(), h.resetPassword)
+	huma.Register(api, h.roleOp(), h.role)
+	huma.Register(api, h.purgeOp(), h.purge)
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*usersOutput, error) {
+	users, err := h.service.ListUsers(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &usersOutput{Body: usersResponse{Users: users}}, nil
+}
+
+func (h *Handler) get(ctx context.Context, input *userInput) (*userOutput, error) {
+	info, err := h.service.GetUser(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &userOutput{Body: *info}, nil
+}
+
+func (h *Handler) disable(ctx context.Context, input *userInput) (*userOutput, error) {
+	info, err := h.service.Disable(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &userOutput{Body: *info}, nil
+}
+
+func (h *Handler) enable(ctx context.Context, input *userInput) (*userOutput, error) {
+	info, err := h.service.Enable(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &userOutput{Body: *info}, nil
+}
+
+func (h *Handler) resetPassword(ctx context.Context, input *userInput) (*passwordResetOutput, error) {
+	reset, err := h.service.ResetPassword(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &passwordResetOutput{Body: *reset}, nil
+}
+
+func (h *Handler) role(ctx context.Context, input *roleInput) (*userOutput, error) {
+	info, err := h.service.SetRole(ctx, input.ID, input.Body.Role)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &userOutput{Body: *info}, nil
+}
+
+func (h *Handler) purge(ctx context.Context, input *purgeInput) (*purgeOutput, error) {
+	olderThan, err := input.Body.olderThan()
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity("invalid older_than: " + err.Error())
+	}
+
+	result, err := h.service.Purge(ctx, input.Body.UserID, olderThan)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &purgeOutput{Body: *result}, nil
+}
+
+func (h *Handler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("admin operation failed", "error", err)
+	return huma.Error500InternalServerError("admin operation failed")
+}
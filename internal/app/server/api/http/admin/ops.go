@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// adminAccess - общая часть описания доступа к операциям
+const adminAccess = " Требует заголовок X-Admin-Token или сессию пользователя с ролью admin."
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-list",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/users",
+		Summary:     "Список пользователей",
+		Description: "Возвращает всех пользователей с ролью, состоянием блокировки, объемом хранилища и числом устройств." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-get",
+		Method:      http.MethodGet,
+		Path:        "/api/v1/admin/users/{id}",
+		Summary:     "Сведения о пользователе",
+		Description: "Возвращает роль, состояние блокировки, объем хранилища и число устройств пользователя." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) disableOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-disable",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/users/{id}/disable",
+		Summary:     "Заблокировать учетную запись",
+		Description: "Запрещает вход и завершает все сессии пользователя. Данные не удаляются." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) enableOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-enable",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/users/{id}/enable",
+		Summary:     "Разблокировать учетную запись",
+		Description: "Снимает блокировку; пользователь снова может войти." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) resetPasswordOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-password-reset",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/users/{id}/password-reset",
+		Summary:     "Принудительно сбросить пароль",
+		Description: "Заменяет пароль входа временным, который возвращается один раз, и завершает все сессии. Мастер-пароль и зашифрованные данные не затрагиваются." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) roleOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-users-role",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/users/{id}/role",
+		Summary:     "Назначить роль",
+		Description: "Роль admin открывает пользователю /api/v1/admin и остальные административные операции по его сессии." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) purgeOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-purge",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/admin/purge",
+		Summary:     "Очистить корзину",
+		Description: "Окончательно удаляет записи, помеченные удаленными раньше older_than, у одного или всех пользователей." + adminAccess,
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
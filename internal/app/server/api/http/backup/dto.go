@@ -0,0 +1,30 @@
+package backup
+
+import (
+	"gophkeeper/internal/app/server/backup"
+)
+
+type triggerOutput struct {
+	Body backup.Snapshot
+}
+
+type listOutput struct {
+	Body listResponse
+}
+
+type listResponse struct {
+	Snapshots []backup.Snapshot `json:"snapshots"`
+}
+
+type restoreInput struct {
+	ID   string `path:"id" example:"20250101T030000Z" doc:"ID снимка"`
+	Body restoreRequest
+}
+
+type restoreRequest struct {
+	UserID int `json:"user_id" minimum:"1" doc:"ID пользователя, записи которого восстанавливаются"`
+}
+
+type restoreOutput struct {
+	Body backup.RestoreResult
+}
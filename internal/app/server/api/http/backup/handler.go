@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"context"
+	"errors"
+
+	"gophkeeper/internal/app/server/backup"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+type Handler struct {
+	service    backup.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service backup.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.triggerOp(), h.trigger)
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.restoreOp(), h.restore)
+}
+
+func (h *Handler) trigger(ctx context.Context, _ *struct{}) (*triggerOutput, error) {
+	snapshot, err := h.service.Backup(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &triggerOutput{Body: *snapshot}, nil
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+	snapshots, err := h.service.ListSnapshots(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &listOutput{Body: listResponse{Snapshots: snapshots}}, nil
+}
+
+func (h *Handler) restore(ctx context.Context, input *restoreInput) (*restoreOutput, error) {
+	result, err := h.service.Restore(ctx, input.ID, input.Body.UserID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &restoreOutput{Body: *result}, nil
+}
+
+func (h *Handler) mapError(err error) error {
+	switch {
+	case errors.Is(err, backup.ErrDisabled):
+		return huma.Error503ServiceUnavailable(err.Error())
+	case errors.Is(err, backup.ErrSnapshotNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, backup.ErrInProgress):
+		return huma.Error409Conflict(err.Error())
+	default:
+		h.log.Error("backup operation failed", "error", err)
+		return huma.Error500InternalServerError("backup operation failed")
+	}
+}
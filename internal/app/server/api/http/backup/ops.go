@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) triggerOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "admin-backups-trigger",
+		Method:        http.MethodPost,
+		Path:          "/api/admin/backups",
+		Summary:       "Создать резервную копию",
+		Description:   "Сохраняет зашифрованные записи всех пользователей в S3 и применяет политику хранения. Требует заголовок X-Admin-Token.",
+		Tags:          []string{"admin"},
+		DefaultStatus: http.StatusCreated,
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-backups-list",
+		Method:      http.MethodGet,
+		Path:        "/api/admin/backups",
+		Summary:     "Список резервных копий",
+		Description: "Возвращает завершенные снимки, от новых к старым. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) restoreOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-backups-restore",
+		Method:      http.MethodPost,
+		Path:        "/api/admin/backups/{id}/restore",
+		Summary:     "Восстановить записи пользователя из снимка",
+		Description: "Возвращает записи пользователя к состоянию из снимка. Восстановленные записи получают новую версию и приходят клиентам при синхронизации. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,45 @@
+package blob
+
+import (
+	"gophkeeper/internal/domain/blob"
+)
+
+type checksumInput struct {
+	Checksum string `path:"checksum" pattern:"^[0-9a-f]{64}$" doc:"SHA-256 зашифрованных данных блоба в hex"`
+}
+
+type headOutput struct {
+	Size       int64 `header:"X-Blob-Size"`
+	References int   `header:"X-Blob-References"`
+}
+
+type getOutput struct {
+	Body getResponse
+}
+
+type getResponse struct {
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+	Data     []byte `json:"data" doc:"Зашифрованные данные в base64"`
+}
+
+type putInput struct {
+	Checksum string `path:"checksum" pattern:"^[0-9a-f]{64}$" doc:"SHA-256 зашифрованных данных блоба в hex"`
+	Body     putRequest
+}
+
+type putRequest struct {
+	Data []byte `json:"data" doc:"Зашифрованные данные в base64"`
+	blob.FileInfo
+}
+
+type putOutput struct {
+	Status int
+	Body   putResponse
+}
+
+type putResponse struct {
+	blob.Blob
+	// Created - блоб сохранен этим запросом; false - такой блоб уже хранился
+	Created bool `json:"created"`
+}
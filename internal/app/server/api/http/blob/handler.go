@@ -0,0 +1,80 @@
+package blob
+
+import (
+	"context"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/blob"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler хранит зашифрованное содержимое бинарных записей по контрольной сумме
+type Handler struct {
+	service    blob.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service blob.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.headOp(), h.head)
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.putOp(), h.put)
+}
+
+func (h *Handler) head(ctx context.Context, input *checksumInput) (*headOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	b, err := h.service.GetByChecksum(ctx, userID, input.Checksum)
+	if err != nil {
+		return nil, httperr.Map(err)
+	}
+
+	return &headOutput{Size: b.Size, References: b.RefCount}, nil
+}
+
+func (h *Handler) get(ctx context.Context, input *checksumInput) (*getOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	b, err := h.service.Get(ctx, userID, input.Checksum)
+	if err != nil {
+		return nil, httperr.Map(err)
+	}
+
+	return &getOutput{Body: getResponse{Checksum: b.Checksum, Size: b.Size, Data: b.Data}}, nil
+}
+
+func (h *Handler) put(ctx context.Context, input *putInput) (*putOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	b, created, err := h.service.Put(ctx, userID, input.Checksum, input.Body.Data, input.Body.FileInfo)
+	if err != nil {
+		return nil, httperr.Map(err)
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return &putOutput{Status: status, Body: putResponse{Blob: *b, Created: created}}, nil
+}
@@ -0,0 +1,52 @@
+package blob
+
+import (
+	"net/http"
+
+	"gophkeeper/internal/domain/blob"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// maxPutBodyBytes - блоб наибольшего размера в base64 и обертка JSON
+const maxPutBodyBytes = blob.MaxSize/3*4 + 1024
+
+func (h *Handler) headOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "blobs-head",
+		Method:      http.MethodHead,
+		Path:        "/api/blobs/{checksum}",
+		Summary:     "Проверить наличие блоба",
+		Description: "Отвечает 200 с размером блоба в X-Blob-Size, если блоб с такой контрольной суммой уже хранится, иначе 404. Клиент проверяет наличие перед загрузкой файла, чтобы не передавать его повторно.",
+		Tags:        []string{"blobs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "blobs-get",
+		Method:      http.MethodGet,
+		Path:        "/api/blobs/{checksum}",
+		Summary:     "Получить блоб",
+		Description: "Возвращает зашифрованное содержимое бинарной записи. Запись ссылается на блоб ключом blob в метаданных.",
+		Tags:        []string{"blobs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) putOp() huma.Operation {
+	return huma.Operation{
+		OperationID:  "blobs-put",
+		Method:       http.MethodPut,
+		Path:         "/api/blobs/{checksum}",
+		Summary:      "Загрузить блоб",
+		Description:  "Сохраняет зашифрованное содержимое бинарной записи под его SHA-256. Повторная загрузка того же блоба не занимает места и отвечает 200 вместо 201. Новый блоб учитывается в квоте хранилища; блоб, на который не ссылается ни одна запись, удаляется через сутки. Если на сервере настроена политика вложений, имя и тип файла (filename, content_type) и размер блоба проверяются до сохранения, а отклоненная загрузка получает 403.",
+		Tags:         []string{"blobs"},
+		Security:     []map[string][]string{{"bearer": {}}},
+		Middlewares:  h.middleware,
+		MaxBodyBytes: maxPutBodyBytes,
+	}
+}
@@ -0,0 +1,42 @@
+// Package conditional - условные запросы по ETag: если содержимое ответа не
+// изменилось с прошлого запроса клиента (If-None-Match), сервер отвечает 304
+// без тела, и клиент не загружает те же данные повторно.
+package conditional
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// ETag возвращает сильный ETag содержимого v: хэш его JSON-представления
+func ETag(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// NotModified возвращает ответ 304 с заголовком ETag, если etag совпадает с
+// одним из значений If-None-Match, иначе nil
+func NotModified(ifNoneMatch, etag string) error {
+	if ifNoneMatch == "" || etag == "" {
+		return nil
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			header := http.Header{}
+			header.Set("ETag", etag)
+			return huma.ErrorWithHeaders(huma.Status304NotModified(), header)
+		}
+	}
+	return nil
+}
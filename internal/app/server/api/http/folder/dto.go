@@ -0,0 +1,47 @@
+package folder
+
+import "gophkeeper/internal/domain/folder"
+
+type folderInput struct {
+	ID int `path:"id" example:"1" doc:"ID папки"`
+}
+
+type createInput struct {
+	Body createRequest
+}
+
+type createRequest struct {
+	Name     string `json:"name" minLength:"1" maxLength:"100" doc:"Имя папки, без /"`
+	ParentID *int   `json:"parent_id,omitempty" minimum:"1" doc:"Родительская папка; без нее папка создается на верхнем уровне"`
+}
+
+type updateInput struct {
+	ID   int `path:"id" example:"1" doc:"ID папки"`
+	Body updateRequest
+}
+
+type updateRequest struct {
+	Name     *string `json:"name,omitempty" minLength:"1" maxLength:"100" doc:"Новое имя папки"`
+	ParentID *int    `json:"parent_id,omitempty" minimum:"0" doc:"Новая родительская папка; 0 - верхний уровень"`
+}
+
+type folderOutput struct {
+	Body folder.Folder
+}
+
+type listOutput struct {
+	Body listResponse
+}
+
+type listResponse struct {
+	Status  string          `json:"status"`
+	Folders []folder.Folder `json:"folders"`
+}
+
+type statusOutput struct {
+	Body statusResponse
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,102 @@
+package folder
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/folder"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+type Handler struct {
+	service    folder.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service folder.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.createOp(), h.create)
+	huma.Register(api, h.updateOp(), h.update)
+	huma.Register(api, h.deleteOp(), h.delete)
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	folders, err := h.service.List(ctx, userID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	if folders == nil {
+		folders = []folder.Folder{}
+	}
+
+	return &listOutput{Body: listResponse{Status: "Ok", Folders: folders}}, nil
+}
+
+func (h *Handler) create(ctx context.Context, input *createInput) (*folderOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	created, err := h.service.Create(ctx, userID, input.Body.Name, input.Body.ParentID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &folderOutput{Body: *created}, nil
+}
+
+func (h *Handler) update(ctx context.Context, input *updateInput) (*folderOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	updated, err := h.service.Update(ctx, userID, input.ID, folder.UpdateRequest{
+		Name:     input.Body.Name,
+		ParentID: input.Body.ParentID,
+	})
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &folderOutput{Body: *updated}, nil
+}
+
+func (h *Handler) delete(ctx context.Context, input *folderInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.service.Delete(ctx, userID, input.ID); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+func (h *Handler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("folder operation failed", "error", err)
+	return huma.Error500InternalServerError("folder operation failed")
+}
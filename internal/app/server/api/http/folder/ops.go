@@ -0,0 +1,60 @@
+package folder
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "folders-list",
+		Method:      http.MethodGet,
+		Path:        "/api/folders",
+		Summary:     "Папки пользователя",
+		Description: "Возвращает все папки пользователя; вложенность задается parent_id.",
+		Tags:        []string{"folders"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) createOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "folders-create",
+		Method:        http.MethodPost,
+		Path:          "/api/folders",
+		Summary:       "Создать папку",
+		Description:   "Создает папку верхнего уровня или вложенную в parent_id. Имена соседних папок не повторяются.",
+		Tags:          []string{"folders"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) updateOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "folders-update",
+		Method:      http.MethodPatch,
+		Path:        "/api/folders/{id}",
+		Summary:     "Переименовать или переместить папку",
+		Description: "Меняет имя и/или родителя папки. parent_id = 0 переносит папку на верхний уровень. Папку нельзя переместить в нее саму или во вложенную папку.",
+		Tags:        []string{"folders"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) deleteOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "folders-delete",
+		Method:      http.MethodDelete,
+		Path:        "/api/folders/{id}",
+		Summary:     "Удалить папку",
+		Description: "Удаляет пустую папку. Папку с вложенными папками или записями удалить нельзя (409).",
+		Tags:        []string{"folders"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -1,5 +1,7 @@
 package health
 
+import "gophkeeper/internal/app/server/maintenance"
+
 // Input represents the input for health check endpoint
 type Input struct{}
 
@@ -10,5 +12,6 @@ type Output struct {
 
 // HResponse represents the health check response
 type HResponse struct {
-	Status string `json:"status" example:"OK" doc:"Health status of the service"`
+	Status      string              `json:"status" example:"OK" doc:"Health status of the service"`
+	Maintenance *maintenance.Status `json:"maintenance,omitempty" doc:"Maintenance mode details, present only while it is enabled"`
 }
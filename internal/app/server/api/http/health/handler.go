@@ -3,19 +3,24 @@ package health
 import (
 	"context"
 
+	"gophkeeper/internal/app/server/maintenance"
+
 	"github.com/danielgtaylor/huma/v2"
 	"golang.org/x/exp/slog"
 )
 
 type Handler struct {
-	log        *slog.Logger
-	middleware huma.Middlewares
+	maintenance *maintenance.Mode
+	log         *slog.Logger
+	middleware  huma.Middlewares
 }
 
-func NewHandler(log *slog.Logger, middleware huma.Middlewares) *Handler {
+// NewHandler создает обработчик проверки состояния. mode может быть nil.
+func NewHandler(mode *maintenance.Mode, log *slog.Logger, middleware huma.Middlewares) *Handler {
 	return &Handler{
-		log:        log,
-		middleware: middleware,
+		maintenance: mode,
+		log:         log,
+		middleware:  middleware,
 	}
 }
 
@@ -26,6 +31,15 @@ func (h *Handler) SetupRoutes(api huma.API) {
 func (h *Handler) healthCheck(_ context.Context, _ *Input) (*Output, error) {
 	h.log.Debug("health check request received")
 
+	if status := h.maintenance.Status(); status.Enabled {
+		return &Output{
+			Body: HResponse{
+				Status:      "MAINTENANCE",
+				Maintenance: &status,
+			},
+		}, nil
+	}
+
 	return &Output{
 		Body: HResponse{
 			Status: "OK",
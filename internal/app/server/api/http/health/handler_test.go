@@ -3,6 +3,9 @@ package health
 import (
 	"context"
 	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/maintenance"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +28,7 @@ func TestHandler_healthCheck(t *testing.T) {
 			// Arrange
 			log := slog.Default()
 			middleware := huma.Middlewares{}
-			handler := NewHandler(log, middleware)
+			handler := NewHandler(nil, log, middleware)
 			ctx := context.Background()
 			input := &Input{}
 
@@ -40,13 +43,34 @@ func TestHandler_healthCheck(t *testing.T) {
 	}
 }
 
+func TestHandler_healthCheck_Maintenance(t *testing.T) {
+	mode := maintenance.New(maintenance.DefaultConfig())
+	mode.Enable("database upgrade", time.Minute)
+	handler := NewHandler(mode, slog.Default(), huma.Middlewares{})
+
+	output, err := handler.healthCheck(context.Background(), &Input{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MAINTENANCE", output.Body.Status)
+	if assert.NotNil(t, output.Body.Maintenance) {
+		assert.Equal(t, "database upgrade", output.Body.Maintenance.Message)
+		assert.Equal(t, 60, output.Body.Maintenance.RetryAfter)
+	}
+
+	mode.Disable()
+	output, err = handler.healthCheck(context.Background(), &Input{})
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", output.Body.Status)
+	assert.Nil(t, output.Body.Maintenance)
+}
+
 func TestNewHandler(t *testing.T) {
 	// Arrange
 	log := slog.Default()
 	middleware := huma.Middlewares{}
 
 	// Act
-	handler := NewHandler(log, middleware)
+	handler := NewHandler(nil, log, middleware)
 
 	// Assert
 	assert.NotNil(t, handler)
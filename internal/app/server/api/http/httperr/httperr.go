@@ -0,0 +1,48 @@
+// Package httperr переводит ошибки доменов в HTTP-ответы по их виду
+// (apperr.Kind), чтобы обработчики не перечисляли сентинелы каждого домена.
+// Код ответа (problem.Error.Code) задает сентинел домена через
+// apperr.Error.WithCode, иначе он выводится из вида ошибки.
+package httperr
+
+import (
+	"errors"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/app/server/api/http/quota"
+	"gophkeeper/internal/domain/apperr"
+	domainQuota "gophkeeper/internal/domain/quota"
+)
+
+// Known сообщает, есть ли у ошибки вид, который Map переводит в статус ответа
+func Known(err error) bool {
+	return apperr.KindOf(err) != ""
+}
+
+// Map возвращает ответ с кодом, соответствующим виду ошибки. Ошибки без
+// вида возвращаются без изменений: huma ответит на них 500.
+func Map(err error) error {
+	code := apperr.CodeOf(err)
+	switch apperr.KindOf(err) {
+	case apperr.NotFound:
+		return problem.New(http.StatusNotFound, code, err.Error())
+	case apperr.Gone:
+		return problem.New(http.StatusGone, code, err.Error())
+	case apperr.Conflict:
+		return problem.New(http.StatusConflict, code, err.Error())
+	case apperr.Unauthorized:
+		return problem.New(http.StatusUnauthorized, code, err.Error())
+	case apperr.Forbidden:
+		return problem.New(http.StatusForbidden, code, err.Error())
+	case apperr.Invalid:
+		return problem.New(http.StatusUnprocessableEntity, code, err.Error())
+	case apperr.QuotaExceeded:
+		// Превышение персональной квоты отдается с текущим использованием
+		if errors.As(err, new(*domainQuota.ExceededError)) {
+			return quota.MapError(err)
+		}
+		return problem.New(http.StatusRequestEntityTooLarge, code, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	quotaAPI "gophkeeper/internal/app/server/api/http/quota"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/quota"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   apperr.Code
+	}{
+		{"record not found", fmt.Errorf("find record: %w", record.ErrNotFound), http.StatusNotFound, "RECORD_NOT_FOUND"},
+		{"record deleted", record.ErrRecordDeleted, http.StatusGone, "RECORD_DELETED"},
+		{"version conflict", fmt.Errorf("update: %w", record.ErrVersionConflict), http.StatusConflict, "VERSION_CONFLICT"},
+		{"invalid data", record.ErrInvalidData, http.StatusUnprocessableEntity, "INVALID_RECORD_DATA"},
+		{"record forbidden", record.ErrForbidden, http.StatusForbidden, "RECORD_FORBIDDEN"},
+		{"invalid credentials", user.ErrInvalidAuth, http.StatusUnauthorized, "INVALID_CREDENTIALS"},
+		{"invalid session", session.ErrInvalidSession, http.StatusUnauthorized, "AUTH_EXPIRED"},
+		{"device not owned", sync.ErrDeviceNotOwned, http.StatusForbidden, "DEVICE_NOT_OWNED"},
+		{"sync storage limit", sync.ErrStorageLimit, http.StatusRequestEntityTooLarge, "STORAGE_LIMIT"},
+		{"generic code", sync.ErrInvalidFilter, http.StatusUnprocessableEntity, apperr.CodeInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, Known(tt.err))
+
+			var pe *problem.Error
+			if assert.ErrorAs(t, Map(tt.err), &pe) {
+				assert.Equal(t, tt.status, pe.GetStatus())
+				assert.Equal(t, tt.code, pe.Code)
+				assert.Equal(t, tt.err.Error(), pe.Detail)
+			}
+		})
+	}
+}
+
+func TestMap_QuotaExceededKeepsUsage(t *testing.T) {
+	exceeded := &quota.ExceededError{Usage: quota.Usage{Used: 90, Limit: 100}, Requested: 20}
+
+	var body *quotaAPI.ExceededError
+	if assert.ErrorAs(t, Map(fmt.Errorf("create: %w", exceeded)), &body) {
+		assert.Equal(t, int64(100), body.Limit)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, body.GetStatus())
+	}
+}
+
+func TestMap_UnclassifiedUnchanged(t *testing.T) {
+	err := errors.New("connection reset")
+	assert.False(t, Known(err))
+	assert.Same(t, err, Map(err))
+}
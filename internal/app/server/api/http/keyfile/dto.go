@@ -0,0 +1,19 @@
+package keyfile
+
+import "gophkeeper/internal/domain/keyfile"
+
+type keyFileOutput struct {
+	Body keyfile.KeyFile
+}
+
+type putInput struct {
+	Body PutRequest
+}
+
+// PutRequest - файл мастер-ключа в base64 и хэш ключа из его заголовка
+type PutRequest struct {
+	Data    []byte `json:"data" doc:"Файл мастер-ключа в base64"`
+	KeyHash string `json:"key_hash" doc:"SHA-256 мастер-ключа (hex) из заголовка файла"`
+	// PreviousKeyHash передается после смены мастер-пароля
+	PreviousKeyHash string `json:"previous_key_hash,omitempty" doc:"Хэш из заголовка прежнего файла: после смены мастер-пароля заменяет файл с этим хэшем"`
+}
@@ -0,0 +1,66 @@
+package keyfile
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/domain/keyfile"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler хранит файл мастер-ключа учетной записи
+type Handler struct {
+	service    keyfile.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service keyfile.Servicer, log *slog.Logger, middleware huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: middleware,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.putOp(), h.put)
+}
+
+func (h *Handler) get(ctx context.Context, _ *struct{}) (*keyFileOutput, error) {
+	file, err := h.service.Get(ctx)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &keyFileOutput{Body: *file}, nil
+}
+
+func (h *Handler) put(ctx context.Context, input *putInput) (*keyFileOutput, error) {
+	file := keyfile.KeyFile{
+		Data:    input.Body.Data,
+		KeyHash: input.Body.KeyHash,
+	}
+
+	var err error
+	var saved *keyfile.KeyFile
+	if previous := input.Body.PreviousKeyHash; previous != "" && previous != file.KeyHash {
+		saved, err = h.service.Replace(ctx, file, previous)
+	} else {
+		saved, err = h.service.Save(ctx, file)
+	}
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &keyFileOutput{Body: *saved}, nil
+}
+
+func (h *Handler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("key file request failed", "error", err)
+	return huma.Error500InternalServerError("failed to process key file")
+}
@@ -0,0 +1,33 @@
+package keyfile
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-key-file-get",
+		Method:      http.MethodGet,
+		Path:        "/api/account/key-file",
+		Summary:     "Получить файл мастер-ключа",
+		Description: "Возвращает файл мастер-ключа для восстановления хранилища на новом устройстве. Ключ в файле зашифрован мастер-паролем.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) putOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-key-file-put",
+		Method:      http.MethodPut,
+		Path:        "/api/account/key-file",
+		Summary:     "Сохранить файл мастер-ключа",
+		Description: "Сохраняет или обновляет файл мастер-ключа. После смены мастер-пароля хэш в заголовке меняется: файл заменяет прежний, если передан previous_key_hash прежнего файла. Файл другого ключа отклоняется с 409: записи учетной записи зашифрованы сохраненным ключом.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
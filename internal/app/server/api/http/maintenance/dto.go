@@ -0,0 +1,19 @@
+package maintenance
+
+import (
+	"gophkeeper/internal/app/server/maintenance"
+)
+
+type statusOutput struct {
+	Body maintenance.Status
+}
+
+type setInput struct {
+	Body setRequest
+}
+
+type setRequest struct {
+	Enabled    bool   `json:"enabled" doc:"Включить режим обслуживания"`
+	Message    string `json:"message,omitempty" maxLength:"500" doc:"Сообщение для клиентов"`
+	RetryAfter int    `json:"retry_after_seconds,omitempty" minimum:"0" doc:"Через сколько секунд клиентам повторить запрос; 0 - значение из конфигурации"`
+}
@@ -0,0 +1,46 @@
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"gophkeeper/internal/app/server/maintenance"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+type Handler struct {
+	mode       *maintenance.Mode
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(mode *maintenance.Mode, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		mode:       mode,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.setOp(), h.set)
+}
+
+func (h *Handler) get(_ context.Context, _ *struct{}) (*statusOutput, error) {
+	return &statusOutput{Body: h.mode.Status()}, nil
+}
+
+func (h *Handler) set(_ context.Context, input *setInput) (*statusOutput, error) {
+	if input.Body.Enabled {
+		h.mode.Enable(input.Body.Message, time.Duration(input.Body.RetryAfter)*time.Second)
+		h.log.Warn("maintenance mode enabled", "message", input.Body.Message)
+	} else {
+		h.mode.Disable()
+		h.log.Info("maintenance mode disabled")
+	}
+
+	return &statusOutput{Body: h.mode.Status()}, nil
+}
@@ -0,0 +1,31 @@
+package maintenance
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-maintenance-get",
+		Method:      http.MethodGet,
+		Path:        "/api/admin/maintenance",
+		Summary:     "Состояние режима обслуживания",
+		Description: "Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) setOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-maintenance-set",
+		Method:      http.MethodPut,
+		Path:        "/api/admin/maintenance",
+		Summary:     "Включить или выключить режим обслуживания",
+		Description: "В режиме обслуживания изменяющие запросы получают 503 с заголовком Retry-After, чтение и вход продолжают работать. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,40 @@
+package mfa
+
+import (
+	"gophkeeper/internal/domain/mfa"
+)
+
+type statusOutput struct {
+	Body mfa.Status
+}
+
+type enrollOutput struct {
+	Body mfa.Enrollment
+}
+
+type codeInput struct {
+	Body codeRequest
+}
+
+type codeRequest struct {
+	Code string `json:"code" minLength:"1" doc:"Код из приложения или код восстановления"`
+}
+
+type confirmOutput struct {
+	Body confirmResponse
+}
+
+// confirmResponse - коды восстановления и новый токен: сессии без второго
+// фактора после включения 2FA перестают приниматься
+type confirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Token         string   `json:"token"`
+}
+
+type recoveryCodesOutput struct {
+	Body recoveryCodesResponse
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
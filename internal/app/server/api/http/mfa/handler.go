@@ -0,0 +1,126 @@
+package mfa
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/mfa"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler управляет двухфакторной аутентификацией учетной записи
+type Handler struct {
+	service    mfa.Servicer
+	users      user.Servicer
+	session    session.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service mfa.Servicer, users user.Servicer, session session.Servicer,
+	log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		users:      users,
+		session:    session,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.statusOp(), h.status)
+	huma.Register(api, h.enrollOp(), h.enroll)
+	huma.Register(api, h.confirmOp(), h.confirm)
+	huma.Register(api, h.disableOp(), h.disable)
+	huma.Register(api, h.recoveryCodesOp(), h.recoveryCodes)
+}
+
+func (h *Handler) status(ctx context.Context, _ *struct{}) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	status, err := h.service.Status(ctx, userID)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+	return &statusOutput{Body: *status}, nil
+}
+
+func (h *Handler) enroll(ctx context.Context, _ *struct{}) (*enrollOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	u, err := h.users.Get(ctx, userID)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+
+	enrollment, err := h.service.Enroll(ctx, userID, u.Login)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+	return &enrollOutput{Body: *enrollment}, nil
+}
+
+func (h *Handler) confirm(ctx context.Context, input *codeInput) (*confirmOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	codes, err := h.service.Confirm(ctx, userID, input.Body.Code)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+
+	token, err := h.session.CreateMFA(ctx, userID)
+	if err != nil {
+		h.log.Error("create session", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("two-factor authentication enabled, but failed to create session")
+	}
+
+	return &confirmOutput{Body: confirmResponse{RecoveryCodes: codes, Token: token}}, nil
+}
+
+func (h *Handler) disable(ctx context.Context, input *codeInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.service.Disable(ctx, userID, input.Body.Code); err != nil {
+		return nil, h.mapError(err, userID)
+	}
+	return &statusOutput{Body: mfa.Status{}}, nil
+}
+
+func (h *Handler) recoveryCodes(ctx context.Context, input *codeInput) (*recoveryCodesOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(ctx, userID, input.Body.Code)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+	return &recoveryCodesOutput{Body: recoveryCodesResponse{RecoveryCodes: codes}}, nil
+}
+
+func (h *Handler) mapError(err error, userID int) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("two-factor operation failed", "error", err, "user_id", userID)
+	return huma.Error500InternalServerError("two-factor operation failed")
+}
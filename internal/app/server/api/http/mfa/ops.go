@@ -0,0 +1,71 @@
+package mfa
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) statusOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-2fa-status",
+		Method:      http.MethodGet,
+		Path:        "/api/account/2fa",
+		Summary:     "Состояние двухфакторной аутентификации",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) enrollOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-2fa-enroll",
+		Method:      http.MethodPost,
+		Path:        "/api/account/2fa/enroll",
+		Summary:     "Начать подключение двухфакторной аутентификации",
+		Description: "Выдает секрет TOTP и otpauth:// URI для приложения-аутентификатора. Второй фактор включается после подтверждения первым кодом.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) confirmOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-2fa-confirm",
+		Method:      http.MethodPost,
+		Path:        "/api/account/2fa/confirm",
+		Summary:     "Включить двухфакторную аутентификацию",
+		Description: "Проверяет код из приложения, включает второй фактор и возвращает коды восстановления. Прежние токены перестают действовать, вместо текущего выдается новый.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) disableOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-2fa-disable",
+		Method:      http.MethodPost,
+		Path:        "/api/account/2fa/disable",
+		Summary:     "Выключить двухфакторную аутентификацию",
+		Description: "Требует код из приложения или код восстановления.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) recoveryCodesOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-2fa-recovery-codes",
+		Method:      http.MethodPost,
+		Path:        "/api/account/2fa/recovery-codes",
+		Summary:     "Новые коды восстановления",
+		Description: "Выдает новые коды восстановления, прежние перестают действовать. Требует код из приложения или код восстановления.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// TokenHeader заголовок с токеном администратора
+const TokenHeader = "X-Admin-Token"
+
+type Admin struct {
+	token string
+	log   *slog.Logger
+
+	// session и role проверяют запросы без X-Admin-Token (см. WithRole)
+	session func(huma.Context, func(huma.Context))
+	role    func(huma.Context, func(huma.Context))
+}
+
+// New создает middleware административного API. Пустой токен отключает API.
+func New(token string, log *slog.Logger) *Admin {
+	return &Admin{
+		token: token,
+		log:   log.With("component", "admin middleware"),
+	}
+}
+
+// WithRole открывает admin API пользователям с ролью role: запрос без
+// X-Admin-Token проходит проверку сессии и роли вместо проверки токена
+func (a *Admin) WithRole(authMW *auth.Auth, roles auth.RoleSource, role string) *Admin {
+	a.session = authMW.Middleware()
+	a.role = authMW.RequireRole(roles, role)
+	return a
+}
+
+// Middleware пропускает запросы с верным X-Admin-Token, а при WithRole -
+// также запросы администраторов с действующей сессией
+func (a *Admin) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if a.session != nil && ctx.Header(TokenHeader) == "" {
+			a.session(ctx, func(ctx huma.Context) {
+				a.role(ctx, next)
+			})
+			return
+		}
+
+		if a.token == "" {
+			a.writeError(ctx, http.StatusForbidden, apperr.CodeForbidden, "admin API disabled")
+			return
+		}
+
+		token := ctx.Header(TokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			a.log.WarnContext(ctx.Context(), "invalid admin token", "path", ctx.URL().Path)
+			a.writeError(ctx, http.StatusUnauthorized, apperr.CodeUnauthorized, "Unauthorized")
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func (a *Admin) writeError(ctx huma.Context, status int, code apperr.Code, message string) {
+	if err := problem.Write(ctx, status, code, message); err != nil {
+		a.log.Error("json encoding", "error", err)
+	}
+}
@@ -2,7 +2,9 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
 	"gophkeeper/internal/domain/session"
 	"net/http"
 
@@ -27,47 +29,85 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// tokenKey - токен сессии запроса: по нему операции сессий находят текущую
+const tokenKey contextKey = "sessionToken"
+
 // Middleware возвращает middleware для Huma с сигнатурой func(ctx Context, next func(Context))
 func (a *Auth) Middleware() func(huma.Context, func(huma.Context)) {
 	return func(ctx huma.Context, next func(huma.Context)) {
 		token := ctx.Header("Authorization")
 
 		if len(token) < 7 || token[:7] != "Bearer " {
-			a.log.Error("wrong Bearer: ", token)
-			ctx.SetStatus(http.StatusUnauthorized)
-			ctx.SetHeader("Content-Type", "application/json")
-
-			w := ctx.BodyWriter()
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"error": "Unauthorized",
-			})
+			a.log.ErrorContext(ctx.Context(), "missing bearer token", "path", ctx.URL().Path)
+			a.writeError(ctx, http.StatusUnauthorized, apperr.CodeUnauthorized, "Unauthorized")
 			return
 		}
 
 		// Валидируем токен
 		userID, err := a.session.Validate(ctx.Context(), token[7:])
 		if err != nil {
-			a.log.Error("validate error", "error", err)
-			ctx.SetStatus(http.StatusUnauthorized)
-			ctx.SetHeader("Content-Type", "application/json")
-
-			w := ctx.BodyWriter()
-			err = json.NewEncoder(w).Encode(map[string]string{
-				"error": "Unauthorized",
-			})
-			if err != nil {
-				a.log.Error("json encoding", "error", err)
+			a.log.ErrorContext(ctx.Context(), "validate error", "error", err)
+			// Недействительный токен - 401; сбой хранилища сессий - 500,
+			// чтобы клиент повторил запрос, а не требовал повторного входа
+			// (код AUTH_EXPIRED у session.ErrInvalidSession)
+			if errors.Is(err, apperr.Unauthorized) {
+				a.writeError(ctx, http.StatusUnauthorized, apperr.CodeOf(err), "Unauthorized")
+			} else {
+				a.writeError(ctx, http.StatusInternalServerError, apperr.CodeInternal, "failed to validate session")
 			}
 			return
 		}
 
 		newCtx := context.WithValue(ctx.Context(), UserIDKey, userID)
+		newCtx = context.WithValue(newCtx, tokenKey, token[7:])
 		newHumaCtx := huma.WithContext(ctx, newCtx)
 
 		next(newHumaCtx)
 	}
 }
 
+// RoleSource возвращает роль пользователя. Ошибка вида apperr.Forbidden
+// (например, заблокированная учетная запись) отклоняет запрос с 403.
+type RoleSource interface {
+	Role(ctx context.Context, userID int) (string, error)
+}
+
+// RequireRole пропускает только пользователей с ролью role. Ставится после
+// Middleware: без пользователя в контексте запрос получает 401, с другой ролью - 403.
+func (a *Auth) RequireRole(roles RoleSource, role string) func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		userID, ok := GetUserID(ctx.Context())
+		if !ok {
+			a.writeError(ctx, http.StatusUnauthorized, apperr.CodeUnauthorized, "Unauthorized")
+			return
+		}
+
+		got, err := roles.Role(ctx.Context(), userID)
+		switch {
+		case errors.Is(err, apperr.Forbidden), errors.Is(err, apperr.NotFound):
+			a.writeError(ctx, http.StatusForbidden, apperr.CodeForbidden, "Forbidden")
+			return
+		case err != nil:
+			a.log.ErrorContext(ctx.Context(), "get user role", "error", err, "user_id", userID)
+			a.writeError(ctx, http.StatusInternalServerError, apperr.CodeInternal, "failed to check user role")
+			return
+		case got != role:
+			a.log.WarnContext(ctx.Context(), "insufficient role", "user_id", userID, "role", got, "required", role,
+				"path", ctx.URL().Path)
+			a.writeError(ctx, http.StatusForbidden, apperr.CodeForbidden, "Forbidden")
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func (a *Auth) writeError(ctx huma.Context, status int, code apperr.Code, message string) {
+	if err := problem.Write(ctx, status, code, message); err != nil {
+		a.log.Error("json encoding", "error", err)
+	}
+}
+
 func GetUserID(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
 	return userID, ok
@@ -76,3 +116,9 @@ func GetUserID(ctx context.Context) (int, bool) {
 func WithUserID(ctx context.Context, userID int) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
+
+// GetToken возвращает токен сессии, с которой выполнен запрос
+func GetToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenKey).(string)
+	return token, ok
+}
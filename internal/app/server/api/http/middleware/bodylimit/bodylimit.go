@@ -0,0 +1,53 @@
+package bodylimit
+
+import (
+	"net/http"
+	"strconv"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+
+	"golang.org/x/exp/slog"
+)
+
+// BodyLimit ограничивает размер тела любого запроса. Лимит относится к
+// байтам по сети: он ставится до распаковки gzip, а размер распакованного
+// тела ограничивает huma (MaxBodyBytes операции). Запрос с Content-Length
+// больше лимита получает 413 без чтения тела; тело без длины (chunked)
+// обрывается на лимите, и операция завершается ошибкой чтения.
+type BodyLimit struct {
+	limit int64
+	log   *slog.Logger
+}
+
+// New создает мидлварь ограничения тела; limit <= 0 отключает проверку
+func New(limit int64, log *slog.Logger) *BodyLimit {
+	return &BodyLimit{
+		limit: limit,
+		log:   log.With(slog.String("component", "http_body_limit")),
+	}
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (b *BodyLimit) Handler(next http.Handler) http.Handler {
+	if b.limit <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > b.limit {
+			b.log.WarnContext(r.Context(), "request body too large",
+				"path", r.URL.Path, "content_length", r.ContentLength, "limit", b.limit)
+			status := http.StatusRequestEntityTooLarge
+			if err := problem.WriteHTTP(w, r, status, problem.CodeForStatus(status),
+				"request body is too large limit="+strconv.FormatInt(b.limit, 10)+" bytes"); err != nil {
+				b.log.ErrorContext(r.Context(), "json encoding", "error", err)
+			}
+			return
+		}
+
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, b.limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,65 @@
+package bodylimit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestBodyLimit(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var readErr error
+	handler := New(8, log).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(body string, contentLength int64) *httptest.ResponseRecorder {
+		readErr = nil
+		req := httptest.NewRequest(http.MethodPost, "/api/records", strings.NewReader(body))
+		req.ContentLength = contentLength
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("12345678", 8).Code)
+		assert.NoError(t, readErr)
+	})
+
+	t.Run("content length over limit", func(t *testing.T) {
+		rec := serve("123456789", 9)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		var body problem.Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, apperr.CodeQuotaExceeded, body.Code)
+		assert.Contains(t, body.Detail, "limit=8")
+	})
+
+	t.Run("unknown length is cut at limit", func(t *testing.T) {
+		serve("123456789", -1)
+		var maxErr *http.MaxBytesError
+		assert.ErrorAs(t, readErr, &maxErr)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 64)))
+		New(0, log).Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
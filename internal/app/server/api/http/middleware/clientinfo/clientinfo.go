@@ -0,0 +1,45 @@
+package clientinfo
+
+import (
+	"net"
+	"net/http"
+
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Предельные длины сведений о клиенте: значения приходят из заголовков
+// и попадают в базу и список сессий
+const (
+	maxDeviceID  = 64
+	maxUserAgent = 256
+)
+
+// Handler сохраняет в контексте запроса, откуда он пришел: UUID устройства
+// (X-Device-ID), IP-адрес и User-Agent. По этим сведениям сессия запоминает
+// клиента при входе и при использовании (session.ClientFrom).
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		client := session.Client{
+			DeviceID:  truncate(r.Header.Get(sync.DeviceHeader), maxDeviceID),
+			IPAddress: ip,
+			UserAgent: truncate(r.UserAgent(), maxUserAgent),
+		}
+		next.ServeHTTP(w, r.WithContext(session.WithClient(r.Context(), client)))
+	})
+}
+
+// truncate обрезает s до n байт, не разрывая символ UTF-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,35 @@
+package clientinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	var got session.Client
+	var ok bool
+	handler := Handler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got, ok = session.ClientFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/records", nil)
+	req.RemoteAddr = "192.0.2.1:54321"
+	req.Header.Set(sync.DeviceHeader, "0f8fad5b-d9cb-469f-a165-70867728950e")
+	req.Header.Set("User-Agent", "gophkeeper-cli/1.0 "+strings.Repeat("ж", 200))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, ok)
+	assert.Equal(t, "192.0.2.1", got.IPAddress)
+	assert.Equal(t, "0f8fad5b-d9cb-469f-a165-70867728950e", got.DeviceID)
+	assert.LessOrEqual(t, len(got.UserAgent), maxUserAgent)
+	assert.True(t, strings.HasPrefix(got.UserAgent, "gophkeeper-cli/1.0 "))
+	assert.True(t, strings.HasSuffix(got.UserAgent, "ж"), "символ UTF-8 не разрывается")
+}
@@ -0,0 +1,82 @@
+package clientversion
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/utils/version"
+
+	"golang.org/x/exp/slog"
+)
+
+// MinVersionHeader - заголовок ответа 426 с минимальной версией клиента
+const MinVersionHeader = "X-Min-Client-Version"
+
+// ClientVersion отклоняет запросы клиентов старше минимальной версии.
+// Клиент передает версию в X-Client-Version; запросы без заголовка
+// (curl, сторонние интеграции) пропускаются. Работает на уровне net/http,
+// чтобы старый клиент получил 426 на любой маршрут, включая health.
+type ClientVersion struct {
+	min version.Version
+	log *slog.Logger
+}
+
+// New создает мидлварь минимальной версии клиента; нулевая min отключает проверку
+func New(min version.Version, log *slog.Logger) *ClientVersion {
+	return &ClientVersion{
+		min: min,
+		log: log.With(slog.String("component", "client_version")),
+	}
+}
+
+// upgradeResponse - тело ответа 426
+type upgradeResponse struct {
+	Status        string      `json:"status"`
+	Code          apperr.Code `json:"code"`
+	Error         string      `json:"error"`
+	ClientVersion string      `json:"client_version"`
+	MinVersion    string      `json:"min_version"`
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (c *ClientVersion) Handler(next http.Handler) http.Handler {
+	if c.min.IsZero() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(version.Header)
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v, err := version.Parse(header)
+		if err != nil {
+			c.log.DebugContext(r.Context(), "invalid client version header", "value", header, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !v.Less(c.min) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		c.log.DebugContext(r.Context(), "outdated client rejected",
+			"client_version", v.String(), "min_version", c.min.String(), "path", r.URL.Path)
+
+		w.Header().Set(MinVersionHeader, c.min.String())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUpgradeRequired)
+		if err := json.NewEncoder(w).Encode(upgradeResponse{
+			Status:        "UpgradeRequired",
+			Code:          apperr.CodeUpgradeRequired,
+			Error:         "client version " + v.String() + " is no longer supported, upgrade to " + c.min.String() + " or newer",
+			ClientVersion: v.String(),
+			MinVersion:    c.min.String(),
+		}); err != nil {
+			c.log.Error("json encoding", "error", err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package clientversion
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gophkeeper/internal/utils/version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestClientVersion(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := New(version.Version{Major: 1, Minor: 2}, log).Handler(ok)
+
+	serve := func(clientVersion string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/sync/batch", nil)
+		if clientVersion != "" {
+			req.Header.Set(version.Header, clientVersion)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("outdated client gets 426", func(t *testing.T) {
+		rec := serve("1.1.9")
+
+		require.Equal(t, http.StatusUpgradeRequired, rec.Code)
+		assert.Equal(t, "1.2.0", rec.Header().Get(MinVersionHeader))
+
+		var body upgradeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "UpgradeRequired", body.Status)
+		assert.Equal(t, "1.1.9", body.ClientVersion)
+		assert.Equal(t, "1.2.0", body.MinVersion)
+	})
+
+	t.Run("supported and unknown clients pass", func(t *testing.T) {
+		for _, v := range []string{"1.2.0", "1.3.0-rc1", "2.0.0", "", "not-a-version"} {
+			assert.Equal(t, http.StatusOK, serve(v).Code, v)
+		}
+	})
+
+	t.Run("zero minimum disables check", func(t *testing.T) {
+		handler := New(version.Version{}, log).Handler(ok)
+		req := httptest.NewRequest(http.MethodGet, "/api/records", nil)
+		req.Header.Set(version.Header, "0.0.1")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
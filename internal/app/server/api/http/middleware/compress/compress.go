@@ -0,0 +1,75 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+)
+
+// level - уровень сжатия ответов: JSON с base64 сжимается хорошо уже на
+// средних уровнях, а более высокие заметно нагружают процессор
+const level = 5
+
+// compressibleTypes - типы ответов, которые сжимаются
+var compressibleTypes = []string{"application/json", "application/problem+json"}
+
+// Compress сжимает ответы gzip или deflate по Accept-Encoding клиента и
+// распаковывает тела запросов с Content-Encoding: gzip. Каждый ответ
+// объявляет Accept-Encoding: gzip (RFC 7694), чтобы клиент знал, что может
+// сжимать запросы. Работает на уровне net/http: huma-мидлвари не могут
+// подменить тело запроса и ResponseWriter.
+type Compress struct {
+	log        *slog.Logger
+	compressor *middleware.Compressor
+}
+
+// New создает мидлварь сжатия
+func New(log *slog.Logger) *Compress {
+	return &Compress{
+		log:        log.With(slog.String("component", "http_compress")),
+		compressor: middleware.NewCompressor(level, compressibleTypes...),
+	}
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (c *Compress) Handler(next http.Handler) http.Handler {
+	return c.compressor.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Encoding", "gzip")
+
+		switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			body, err := gzip.NewReader(r.Body)
+			if err != nil {
+				c.log.WarnContext(r.Context(), "invalid gzip request body", "path", r.URL.Path, "error", err)
+				http.Error(w, "invalid gzip request body", http.StatusBadRequest)
+				return
+			}
+			// Размер распакованного тела ограничивает huma (MaxBodyBytes операции)
+			r.Body = &gzipBody{Reader: body, orig: r.Body}
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		default:
+			http.Error(w, "unsupported content encoding: "+encoding, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// gzipBody закрывает и распаковщик, и исходное тело запроса
+type gzipBody struct {
+	*gzip.Reader
+	orig io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.Reader.Close()
+	return b.orig.Close()
+}
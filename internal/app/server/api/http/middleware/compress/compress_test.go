@@ -0,0 +1,89 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// echo возвращает тело запроса как JSON-ответ
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+})
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestCompress(t *testing.T) {
+	handler := New(slog.New(slog.NewTextHandler(io.Discard, nil))).Handler(echo)
+	payload := `{"data":"` + strings.Repeat("a", 4096) + `"}`
+
+	t.Run("gzip request and response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/sync/batch", bytes.NewReader(gzipped(t, payload)))
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "gzip", rec.Header().Get("Accept-Encoding"))
+		assert.Less(t, rec.Body.Len(), len(payload))
+
+		zr, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(body))
+	})
+
+	t.Run("plain request and response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/sync/batch", strings.NewReader(payload))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, payload, rec.Body.String())
+	})
+
+	t.Run("unsupported encoding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/sync/batch", strings.NewReader(payload))
+		req.Header.Set("Content-Encoding", "br")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get("Accept-Encoding"))
+	})
+
+	t.Run("corrupt gzip body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/sync/batch", strings.NewReader(payload))
+		req.Header.Set("Content-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,142 @@
+package cors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/clientversion"
+	"gophkeeper/internal/app/server/api/http/middleware/requestid"
+	"gophkeeper/internal/domain/sync"
+)
+
+// ErrInvalidConfig некорректные параметры CORS
+var ErrInvalidConfig = errors.New("invalid CORS config")
+
+// AnyOrigin разрешает запросы с любого источника
+const AnyOrigin = "*"
+
+// allowedMethods - методы, которые использует API
+var allowedMethods = strings.Join([]string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}, ", ")
+
+// exposedHeaders - заголовки ответов API, доступные скриптам браузера
+var exposedHeaders = strings.Join([]string{
+	requestid.Header, clientversion.MinVersionHeader, sync.DeviceStatusHeader, sync.ProtocolHeader,
+	"ETag", "Last-Modified", "Retry-After", "Content-Disposition",
+	"X-Record-Version", "X-Blob-Size", "X-Blob-References",
+}, ", ")
+
+// Config параметры CORS для браузерных клиентов
+type Config struct {
+	// AllowedOrigins - источники вида https://vault.example.com или "*";
+	// пустой список выключает CORS
+	AllowedOrigins []string
+	// MaxAge - сколько браузер кеширует ответ на предварительный запрос
+	MaxAge time.Duration
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию (CORS выключен)
+func DefaultConfig() *Config {
+	return &Config{MaxAge: 10 * time.Minute}
+}
+
+// Validate проверяет корректность конфигурации
+func (c *Config) Validate() error {
+	if c.MaxAge < 0 {
+		return fmt.Errorf("%w: max age must not be negative", ErrInvalidConfig)
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == AnyOrigin {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.User != nil {
+			return fmt.Errorf("%w: origin %q must be scheme://host[:port]", ErrInvalidConfig, origin)
+		}
+	}
+	return nil
+}
+
+// CORS отвечает на предварительные запросы браузера и разрешает ответы
+// для источников из конфигурации. Запросы без Origin (CLI, агент) и с
+// неразрешенных источников проходят без заголовков CORS: браузер сам
+// не отдаст такой ответ скрипту. Учетные данные передаются в Authorization,
+// а не в cookie, поэтому Access-Control-Allow-Credentials не нужен.
+type CORS struct {
+	any     bool
+	origins map[string]struct{}
+	maxAge  string
+}
+
+// New создает мидлварь CORS
+func New(cfg *Config) *CORS {
+	c := &CORS{
+		origins: make(map[string]struct{}, len(cfg.AllowedOrigins)),
+		maxAge:  strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == AnyOrigin {
+			c.any = true
+		}
+		c.origins[strings.ToLower(origin)] = struct{}{}
+	}
+	return c
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	if len(c.origins) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if c.any {
+			h.Set("Access-Control-Allow-Origin", AnyOrigin)
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", allowedMethods)
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			h.Set("Access-Control-Max-Age", c.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h.Set("Access-Control-Expose-Headers", exposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *CORS) allowed(origin string) bool {
+	if c.any {
+		return true
+	}
+	_, ok := c.origins[strings.ToLower(origin)]
+	return ok
+}
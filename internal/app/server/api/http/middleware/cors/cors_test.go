@@ -0,0 +1,97 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestCORS(t *testing.T) {
+	handler := New(&Config{AllowedOrigins: []string{"https://vault.example.com"}, MaxAge: time.Minute}).Handler(ok)
+
+	serve := func(method, origin string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/records", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://vault.example.com", map[string]string{
+			"Access-Control-Request-Method":  http.MethodPut,
+			"Access-Control-Request-Headers": "authorization, content-type, x-device-id",
+		})
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://vault.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPut)
+		assert.Equal(t, "authorization, content-type, x-device-id", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "60", rec.Header().Get("Access-Control-Max-Age"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("simple request", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://VAULT.example.com", nil)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://VAULT.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+		assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("other origin gets no CORS headers", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://evil.example.com", map[string]string{
+			"Access-Control-Request-Method": http.MethodDelete,
+		})
+		assert.Equal(t, http.StatusOK, rec.Code, "запрос уходит дальше, как без CORS")
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("request without origin", func(t *testing.T) {
+		rec := serve(http.MethodGet, "", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, rec.Header().Get("Vary"))
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/records", nil)
+		req.Header.Set("Origin", "http://localhost:3000")
+		New(&Config{AllowedOrigins: []string{AnyOrigin}}).Handler(ok).ServeHTTP(rec, req)
+		assert.Equal(t, AnyOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/records", nil)
+		req.Header.Set("Origin", "https://vault.example.com")
+		New(DefaultConfig()).Handler(ok).ServeHTTP(rec, req)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := []string{AnyOrigin, "https://vault.example.com", "http://localhost:3000"}
+	assert.NoError(t, (&Config{AllowedOrigins: valid}).Validate())
+
+	for _, origin := range []string{"vault.example.com", "https://vault.example.com/app", "ftp://example.com", "https://"} {
+		err := (&Config{AllowedOrigins: []string{origin}}).Validate()
+		assert.ErrorIs(t, err, ErrInvalidConfig, origin)
+	}
+	assert.ErrorIs(t, (&Config{MaxAge: -time.Second}).Validate(), ErrInvalidConfig)
+}
@@ -0,0 +1,170 @@
+package devicetrust
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Checker проверяет, что устройству пользователя разрешен доступ к данным
+type Checker interface {
+	CheckDevice(ctx context.Context, userID int, proof sync.DeviceProof) error
+}
+
+// exemptPaths - операции, доступные неподтвержденному устройству:
+// регистрация, параметры сервиса и подтверждение, которое проверяет
+// доверие устройства само
+var exemptPaths = map[string]bool{
+	"/api/sync/devices/register":     true,
+	"/api/sync/devices/{id}/approve": true,
+	"/api/sync/capabilities":         true,
+}
+
+// DeviceTrust не пускает к данным устройства, которые не подтверждены
+// с доверенного устройства. Устройство берется из заголовка X-Device-ID и
+// должно подписать запрос своим ключом, пользователь - из аутентификации,
+// поэтому мидлварь ставится после auth. Подпись кладется в контекст и для
+// освобожденных операций: подтверждение проверяет ее само.
+type DeviceTrust struct {
+	checker Checker
+	log     *slog.Logger
+}
+
+// New создает проверку доверия устройств
+func New(checker Checker, log *slog.Logger) *DeviceTrust {
+	return &DeviceTrust{
+		checker: checker,
+		log:     log.With("component", "device trust middleware"),
+	}
+}
+
+// Middleware отвечает 403 с заголовком X-Device-Status на запросы
+// неподтвержденных и незарегистрированных устройств и 403 без него на
+// запросы без верной подписи устройства
+func (d *DeviceTrust) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		proof := proofFrom(ctx)
+		if proof.UUID != "" {
+			body, err := readBody(ctx)
+			if err != nil {
+				status := http.StatusBadRequest
+				var maxErr *http.MaxBytesError
+				if errors.Is(err, errBodyTooLarge) || errors.As(err, &maxErr) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				d.log.DebugContext(ctx.Context(), "request rejected: failed to read body", "device", proof.UUID, "error", err)
+				if err := problem.Write(ctx, status, problem.CodeForStatus(status), err.Error()); err != nil {
+					d.log.Error("json encoding", "error", err)
+				}
+				return
+			}
+			// Операция читает то же тело, хэш которого проверен подписью
+			proof.BodyHash = sync.DeviceBodyHash(body)
+			ctx = bodyContext{humaContext: ctx, body: bytes.NewReader(body)}
+		}
+		ctx = huma.WithContext(ctx, sync.WithDeviceProof(ctx.Context(), proof))
+
+		userID, ok := auth.GetUserID(ctx.Context())
+		if !ok || (ctx.Operation() != nil && exemptPaths[ctx.Operation().Path]) {
+			next(ctx)
+			return
+		}
+
+		device := proof.UUID
+		err := d.checker.CheckDevice(ctx.Context(), userID, proof)
+		switch {
+		case err == nil:
+			next(ctx)
+		case errors.Is(err, sync.ErrDevicePending):
+			d.log.DebugContext(ctx.Context(), "request rejected: device awaiting approval", "user_id", userID, "device", device)
+			d.reject(ctx, http.StatusForbidden, string(sync.DevicePending), err)
+		case errors.Is(err, sync.ErrDeviceSignature):
+			d.log.WarnContext(ctx.Context(), "request rejected: bad device signature", "user_id", userID, "device", device, "error", err)
+			d.reject(ctx, http.StatusForbidden, "", err)
+		case errors.Is(err, sync.ErrDeviceUnregistered):
+			d.log.DebugContext(ctx.Context(), "request rejected: device not registered", "user_id", userID, "device", device)
+			d.reject(ctx, http.StatusForbidden, sync.DeviceUnregistered, err)
+		default:
+			d.log.ErrorContext(ctx.Context(), "device check failed", "user_id", userID, "error", err)
+			d.reject(ctx, http.StatusInternalServerError, "", errors.New("failed to check device"))
+		}
+	}
+}
+
+// proofFrom возвращает подпись устройства из заголовков запроса
+func proofFrom(ctx huma.Context) sync.DeviceProof {
+	u := ctx.URL()
+	return sync.DeviceProof{
+		UUID:      ctx.Header(sync.DeviceHeader),
+		Timestamp: ctx.Header(sync.DeviceTimestampHeader),
+		Signature: ctx.Header(sync.DeviceSignatureHeader),
+		Method:    ctx.Method(),
+		URI:       u.RequestURI(),
+	}
+}
+
+// errBodyTooLarge - тело запроса больше MaxBodyBytes операции
+var errBodyTooLarge = errors.New("request body is too large")
+
+// readBody читает тело запроса, подпись которого проверяется, не больше
+// MaxBodyBytes операции: huma сама ограничила бы его тем же лимитом
+func readBody(ctx huma.Context) ([]byte, error) {
+	limit := int64(1024 * 1024)
+	if op := ctx.Operation(); op != nil && op.MaxBodyBytes != 0 {
+		limit = op.MaxBodyBytes
+	}
+
+	reader := ctx.BodyReader()
+	if reader == nil {
+		return nil, nil
+	}
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w limit=%d bytes", errBodyTooLarge, limit)
+	}
+	return body, nil
+}
+
+// humaContext позволяет встроить huma.Context, у которого есть метод Context
+type humaContext = huma.Context
+
+// bodyContext отдает операции тело запроса, уже прочитанное мидлварью
+type bodyContext struct {
+	humaContext
+	body io.Reader
+}
+
+func (c bodyContext) BodyReader() io.Reader {
+	return c.body
+}
+
+// Unwrap нужен адаптерам huma, чтобы добраться до исходного запроса
+func (c bodyContext) Unwrap() huma.Context {
+	return c.humaContext
+}
+
+func (d *DeviceTrust) reject(ctx huma.Context, status int, deviceStatus string, err error) {
+	if deviceStatus != "" {
+		ctx.SetHeader(sync.DeviceStatusHeader, deviceStatus)
+	}
+	if err := problem.Write(ctx, status, apperr.CodeOf(err), err.Error()); err != nil {
+		d.log.Error("json encoding", "error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package devicetrust
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+type fakeChecker struct {
+	err    error
+	proofs []sync.DeviceProof
+}
+
+func (f *fakeChecker) CheckDevice(_ context.Context, _ int, proof sync.DeviceProof) error {
+	f.proofs = append(f.proofs, proof)
+	return f.err
+}
+
+type emptyOutput struct {
+	Body struct {
+		Status string `json:"status"`
+	}
+}
+
+func newServer(trust *DeviceTrust) http.Handler {
+	mux := chi.NewMux()
+	api := humachi.New(mux, huma.DefaultConfig("test", "1.0.0"))
+
+	// Вместо мидлвари auth пользователь ставится напрямую
+	fakeAuth := func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, auth.WithUserID(ctx.Context(), 42)))
+	}
+	handler := func(context.Context, *struct{}) (*emptyOutput, error) {
+		out := &emptyOutput{}
+		out.Body.Status = "Ok"
+		return out, nil
+	}
+
+	for _, path := range []string{"/api/sync/changes", "/api/sync/devices/register"} {
+		huma.Register(api, huma.Operation{
+			OperationID: path,
+			Method:      http.MethodGet,
+			Path:        path,
+			Middlewares: huma.Middlewares{fakeAuth, trust.Middleware()},
+		}, handler)
+	}
+
+	// Операция с телом отвечает тем, что прочитала из запроса
+	huma.Register(api, huma.Operation{
+		OperationID:  "folders-create",
+		Method:       http.MethodPost,
+		Path:         "/api/folders",
+		MaxBodyBytes: 64,
+		Middlewares:  huma.Middlewares{fakeAuth, trust.Middleware()},
+	}, func(_ context.Context, in *struct {
+		Body struct {
+			Name string `json:"name"`
+		}
+	}) (*emptyOutput, error) {
+		out := &emptyOutput{}
+		out.Body.Status = in.Body.Name
+		return out, nil
+	})
+	return mux
+}
+
+// serveBody выполняет запрос устройства dev-1 с телом body
+func serveBody(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sync.DeviceHeader, "dev-1")
+	req.Header.Set(sync.DeviceTimestampHeader, "1700000000")
+	req.Header.Set(sync.DeviceSignatureHeader, "c2lnbmF0dXJl")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeviceTrust(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	serve := func(h http.Handler, path string) *httptest.ResponseRecorder {
+		return serveBody(h, http.MethodGet, path, "")
+	}
+
+	t.Run("approved device passes", func(t *testing.T) {
+		checker := &fakeChecker{}
+		rec := serve(newServer(New(checker, log)), "/api/sync/changes?limit=10")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []sync.DeviceProof{{
+			UUID:      "dev-1",
+			Timestamp: "1700000000",
+			Signature: "c2lnbmF0dXJl",
+			Method:    http.MethodGet,
+			URI:       "/api/sync/changes?limit=10",
+			BodyHash:  sync.DeviceBodyHash(nil),
+		}}, checker.proofs)
+	})
+
+	t.Run("body is hashed and passed to the operation", func(t *testing.T) {
+		checker := &fakeChecker{}
+		body := `{"name":"Work"}`
+		rec := serveBody(newServer(New(checker, log)), http.MethodPost, "/api/folders", body)
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"Work"`)
+		require.Len(t, checker.proofs, 1)
+		assert.Equal(t, sync.DeviceBodyHash([]byte(body)), checker.proofs[0].BodyHash)
+	})
+
+	t.Run("body over the operation limit is rejected", func(t *testing.T) {
+		checker := &fakeChecker{}
+		body := `{"name":"` + strings.Repeat("a", 64) + `"}`
+		rec := serveBody(newServer(New(checker, log)), http.MethodPost, "/api/folders", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Empty(t, checker.proofs)
+	})
+
+	t.Run("bad signature is rejected", func(t *testing.T) {
+		rec := serve(newServer(New(&fakeChecker{err: sync.ErrDeviceSignature}, log)), "/api/sync/changes")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get(sync.DeviceStatusHeader), "устройство не должно перерегистрироваться")
+		assert.Contains(t, rec.Body.String(), "DEVICE_SIGNATURE_INVALID")
+	})
+
+	t.Run("pending device is rejected", func(t *testing.T) {
+		rec := serve(newServer(New(&fakeChecker{err: sync.ErrDevicePending}, log)), "/api/sync/changes")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, string(sync.DevicePending), rec.Header().Get(sync.DeviceStatusHeader))
+		assert.Contains(t, rec.Body.String(), "awaiting approval")
+	})
+
+	t.Run("unregistered device is rejected", func(t *testing.T) {
+		rec := serve(newServer(New(&fakeChecker{err: sync.ErrDeviceUnregistered}, log)), "/api/sync/changes")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Equal(t, sync.DeviceUnregistered, rec.Header().Get(sync.DeviceStatusHeader))
+	})
+
+	t.Run("check failure is internal error", func(t *testing.T) {
+		rec := serve(newServer(New(&fakeChecker{err: errors.New("db down")}, log)), "/api/sync/changes")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get(sync.DeviceStatusHeader))
+	})
+
+	t.Run("registration is exempt", func(t *testing.T) {
+		checker := &fakeChecker{err: sync.ErrDevicePending}
+		rec := serve(newServer(New(checker, log)), "/api/sync/devices/register")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, checker.proofs)
+	})
+}
@@ -1,13 +1,21 @@
 package logger
 
 import (
+	"context"
+	"net/http"
 	"time"
 
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-chi/chi/v5/middleware"
 	"golang.org/x/exp/slog"
 )
 
-// Logger middleware для логирования входящих HTTP запросов
+// Logger пишет журнал доступа: по записи на каждый HTTP-запрос, включая
+// отклоненные до операций huma (неизвестный маршрут, 426, 413, паника).
+// Запись пишет net/http мидлварь (Handler); операцию и пользователя к ней
+// добавляет huma-мидлварь (Middleware), которая ставится после auth.
 type Logger struct {
 	log *slog.Logger
 }
@@ -19,41 +27,74 @@ func New(log *slog.Logger) *Logger {
 	}
 }
 
-// Middleware возвращает middleware функцию для логирования HTTP запросов
-func (l *Logger) Middleware() func(huma.Context, func(huma.Context)) {
-	return func(ctx huma.Context, next func(huma.Context)) {
-		start := time.Now()
+type entryKey struct{}
+
+// entry - сведения о запросе, известные только внутри операции huma.
+// Запрос обрабатывается в одной горутине, поэтому синхронизация не нужна.
+type entry struct {
+	operation string
+	userID    int
+}
 
-		method := ctx.Method()
-		path := ctx.URL().Path
-		remoteAddr := ctx.RemoteAddr()
-		contentType := ctx.Header("Content-Type")
+// Handler возвращает net/http мидлварь для chi. Ставится после requestid,
+// чтобы запись получила request_id, и до recoverer, чтобы в журнал попал
+// статус ответа на панику.
+func (l *Logger) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		e := &entry{}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-		next(ctx)
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), entryKey{}, e)))
 
-		duration := time.Since(start)
-		status := ctx.Status()
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
 
 		attrs := []any{
-			slog.String("method", method),
-			slog.String("path", path),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
 			slog.Int("status", status),
-			slog.Duration("duration", duration),
-			slog.String("remote_addr", remoteAddr),
-			slog.String("content_type", contentType),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("bytes_out", ww.BytesWritten()),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
 		}
-
-		if userID := ctx.Header("X-User-ID"); userID != "" {
-			attrs = append(attrs, slog.String("user_id", userID))
+		if r.ContentLength > 0 {
+			attrs = append(attrs, slog.Int64("bytes_in", r.ContentLength))
+		}
+		if e.operation != "" {
+			attrs = append(attrs, slog.String("operation", e.operation))
+		}
+		if e.userID != 0 {
+			attrs = append(attrs, slog.Int("user_id", e.userID))
 		}
 
+		level := slog.LevelInfo
 		switch {
 		case status >= 500:
-			l.log.Error("HTTP request", attrs...)
+			level = slog.LevelError
 		case status >= 400:
-			l.log.Warn("HTTP request", attrs...)
-		default:
-			l.log.Info("HTTP request", attrs...)
+			level = slog.LevelWarn
 		}
+		l.log.Log(r.Context(), level, "HTTP request", attrs...)
+	})
+}
+
+// Middleware возвращает huma-мидлварь, которая дополняет запись журнала
+// доступа операцией и пользователем
+func (l *Logger) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if e, ok := ctx.Context().Value(entryKey{}).(*entry); ok {
+			if op := ctx.Operation(); op != nil {
+				e.operation = op.OperationID
+			}
+			if userID, ok := auth.GetUserID(ctx.Context()); ok {
+				e.userID = userID
+			}
+		}
+
+		next(ctx)
 	}
 }
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(slog.New(sl.NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+
+	mux := chi.NewMux()
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(sl.WithRequestID(r.Context(), "req-1")))
+		})
+	})
+	mux.Use(l.Handler)
+	api := humachi.New(mux, huma.DefaultConfig("test", "1.0.0"))
+
+	authenticate := func(ctx huma.Context, next func(huma.Context)) {
+		next(huma.WithContext(ctx, auth.WithUserID(ctx.Context(), 42)))
+	}
+	huma.Register(api, huma.Operation{
+		OperationID: "get-item",
+		Method:      http.MethodGet,
+		Path:        "/items",
+		Middlewares: huma.Middlewares{authenticate, l.Middleware()},
+	}, func(context.Context, *struct{}) (*struct{ Body string }, error) {
+		return &struct{ Body string }{Body: "ok"}, nil
+	})
+
+	serve := func(path string) map[string]any {
+		buf.Reset()
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &rec))
+		return rec
+	}
+
+	t.Run("operation", func(t *testing.T) {
+		rec := serve("/items")
+		assert.Equal(t, "INFO", rec["level"])
+		assert.Equal(t, "GET", rec["method"])
+		assert.Equal(t, "/items", rec["path"])
+		assert.EqualValues(t, http.StatusOK, rec["status"])
+		assert.Equal(t, "get-item", rec["operation"])
+		assert.EqualValues(t, 42, rec["user_id"])
+		assert.Equal(t, "req-1", rec["request_id"])
+		assert.Positive(t, rec["bytes_out"])
+	})
+
+	t.Run("unknown route", func(t *testing.T) {
+		rec := serve("/missing")
+		assert.Equal(t, "WARN", rec["level"])
+		assert.EqualValues(t, http.StatusNotFound, rec["status"])
+		assert.NotContains(t, rec, "operation")
+		assert.NotContains(t, rec, "user_id")
+	})
+}
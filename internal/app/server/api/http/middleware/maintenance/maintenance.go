@@ -0,0 +1,71 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"gophkeeper/internal/app/server/maintenance"
+	"gophkeeper/internal/domain/apperr"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// readOnlyPaths - POST-запросы, которые не изменяют данные и работают
+// в режиме обслуживания
+var readOnlyPaths = map[string]bool{
+	"/user/login":         true,
+	"/user/login/2fa":     true,
+	"/api/sync/changes":   true,
+	"/api/sync/negotiate": true,
+}
+
+type Maintenance struct {
+	mode *maintenance.Mode
+	log  *slog.Logger
+}
+
+// New создает middleware режима обслуживания
+func New(mode *maintenance.Mode, log *slog.Logger) *Maintenance {
+	return &Maintenance{
+		mode: mode,
+		log:  log.With("component", "maintenance middleware"),
+	}
+}
+
+// Middleware отвечает 503 с Retry-After на изменяющие запросы,
+// пока включен режим обслуживания
+func (m *Maintenance) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if !m.mode.Enabled() || !isWrite(ctx) {
+			next(ctx)
+			return
+		}
+
+		status := m.mode.Status()
+		m.log.DebugContext(ctx.Context(), "write rejected: maintenance mode",
+			"method", ctx.Method(), "path", ctx.URL().Path)
+
+		ctx.SetHeader("Retry-After", strconv.Itoa(status.RetryAfter))
+		ctx.SetHeader("Content-Type", "application/json")
+		ctx.SetStatus(http.StatusServiceUnavailable)
+
+		if err := json.NewEncoder(ctx.BodyWriter()).Encode(map[string]interface{}{
+			"error":               status.Message,
+			"status":              "Maintenance",
+			"code":                apperr.CodeMaintenance,
+			"retry_after_seconds": status.RetryAfter,
+		}); err != nil {
+			m.log.Error("json encoding", "error", err)
+		}
+	}
+}
+
+func isWrite(ctx huma.Context) bool {
+	switch ctx.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !readOnlyPaths[ctx.URL().Path]
+}
@@ -0,0 +1,55 @@
+package recoverer
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+
+	"golang.org/x/exp/slog"
+)
+
+// Recoverer перехватывает панику обработчика: пишет ее в журнал со стеком
+// и отвечает 500 с идентификатором запроса, по которому запись можно найти.
+// Без него паника обрывает соединение, и клиент не получает ответа.
+type Recoverer struct {
+	log *slog.Logger
+}
+
+// New создает мидлварь восстановления после паники
+func New(log *slog.Logger) *Recoverer {
+	return &Recoverer{
+		log: log.With(slog.String("component", "http_recoverer")),
+	}
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (rc *Recoverer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// Обработчик намеренно прервал ответ; net/http закроет соединение сам
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			rc.log.ErrorContext(r.Context(), "panic in HTTP handler",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("panic", fmt.Sprint(rec)),
+				slog.String("stack", string(debug.Stack())))
+
+			if err := problem.WriteHTTP(w, r, http.StatusInternalServerError, apperr.CodeInternal,
+				"internal server error"); err != nil {
+				rc.log.ErrorContext(r.Context(), "json encoding", "error", err)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,53 @@
+package recoverer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestRecoverer(t *testing.T) {
+	var logs bytes.Buffer
+	rc := New(slog.New(sl.NewContextHandler(slog.NewJSONHandler(&logs, nil))))
+
+	t.Run("panic becomes 500 with request id", func(t *testing.T) {
+		handler := rc.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/records", nil)
+		req = req.WithContext(sl.WithRequestID(req.Context(), "req-1"))
+		rec := httptest.NewRecorder()
+
+		require.NotPanics(t, func() { handler.ServeHTTP(rec, req) })
+
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		var body problem.Error
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, apperr.CodeInternal, body.Code)
+		assert.Equal(t, "req-1", body.RequestID)
+
+		assert.Contains(t, logs.String(), `"panic":"boom"`)
+		assert.Contains(t, logs.String(), `"request_id":"req-1"`)
+		assert.Contains(t, logs.String(), "recoverer_test.go")
+	})
+
+	t.Run("abort handler is propagated", func(t *testing.T) {
+		handler := rc.Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package requestid
+
+import (
+	"net/http"
+
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/google/uuid"
+)
+
+// Header - заголовок с идентификатором запроса
+const Header = "X-Request-ID"
+
+// maxLength - предельная длина идентификатора, присланного клиентом
+const maxLength = 64
+
+// Handler назначает запросу идентификатор и возвращает его в X-Request-ID.
+// Идентификатор клиента сохраняется, если он короткий и состоит из
+// безопасных символов; иначе выдается новый UUID. Идентификатор попадает
+// в контекст запроса (sl.RequestID), а оттуда - в записи журнала с
+// контекстом и в тела ответов с ошибкой. Стоит первым в цепочке.
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(sl.WithRequestID(r.Context(), id)))
+	})
+}
+
+// valid пропускает только идентификаторы, безопасные для журнала и заголовков
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
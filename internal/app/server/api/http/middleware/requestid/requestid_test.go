@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	var got string
+	handler := Handler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = sl.RequestID(r.Context())
+	}))
+
+	serve := func(id string) string {
+		got = ""
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		if id != "" {
+			req.Header.Set(Header, id)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, got, rec.Header().Get(Header), "ответ несет идентификатор из контекста")
+		return got
+	}
+
+	t.Run("generated", func(t *testing.T) {
+		first, second := serve(""), serve("")
+		assert.NoError(t, uuid.Validate(first))
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("client id is kept", func(t *testing.T) {
+		assert.Equal(t, "cli-7f3a.1:2_b", serve("cli-7f3a.1:2_b"))
+	})
+
+	t.Run("unsafe client id is replaced", func(t *testing.T) {
+		for _, id := range []string{"bad id", "line\nbreak", `"quoted"`, strings.Repeat("a", maxLength+1)} {
+			got := serve(id)
+			assert.NotEqual(t, id, got)
+			assert.NoError(t, uuid.Validate(got), id)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/exp/slog"
+)
+
+// Recorder сохраняет учтенные трафик и операции устройства
+type Recorder interface {
+	RecordDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage sync.DeviceUsage) error
+}
+
+// Meter считает трафик и операции синхронизации по устройствам. Устройство
+// берется из заголовка X-Device-ID, пользователь - из аутентификации:
+// запросы без заголовка или без аутентификации не учитываются.
+//
+// Счет байтов работает на уровне net/http (Handler) и должен стоять до
+// сжатия, чтобы учитывался трафик по сети. Пользователя запросу назначает
+// huma-мидлварь (Middleware) после auth.
+type Meter struct {
+	recorder Recorder
+	log      *slog.Logger
+}
+
+// New создает учет трафика устройств
+func New(recorder Recorder, log *slog.Logger) *Meter {
+	return &Meter{
+		recorder: recorder,
+		log:      log.With(slog.String("component", "device_usage")),
+	}
+}
+
+type meterKey struct{}
+
+// request - счетчики одного запроса. Запрос обрабатывается в одной
+// горутине, поэтому синхронизация не нужна.
+type request struct {
+	userID            int
+	recordsUploaded   int64
+	recordsDownloaded int64
+}
+
+// Handler возвращает net/http мидлварь для chi
+func (m *Meter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		device := r.Header.Get(sync.DeviceHeader)
+		if device == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req := &request{}
+		body := &countingBody{ReadCloser: r.Body}
+		r.Body = body
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), meterKey{}, req)))
+
+		if req.userID == 0 {
+			return
+		}
+
+		usage := sync.DeviceUsage{
+			BytesUploaded:     body.n,
+			BytesDownloaded:   int64(ww.BytesWritten()),
+			Requests:          1,
+			RecordsUploaded:   req.recordsUploaded,
+			RecordsDownloaded: req.recordsDownloaded,
+		}
+		// Учет не должен пропасть, если клиент уже закрыл соединение
+		if err := m.recorder.RecordDeviceUsage(context.WithoutCancel(r.Context()), req.userID, device, usage); err != nil {
+			m.log.WarnContext(r.Context(), "failed to record device usage", "user_id", req.userID, "device", device, "error", err)
+		}
+	})
+}
+
+// Middleware возвращает huma-мидлварь, которая относит запрос к пользователю.
+// Ставится после мидлвари auth.
+func (m *Meter) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		if req, ok := ctx.Context().Value(meterKey{}).(*request); ok {
+			if userID, ok := auth.GetUserID(ctx.Context()); ok {
+				req.userID = userID
+			}
+		}
+		next(ctx)
+	}
+}
+
+// AddRecords учитывает записи, загруженные (up) и отданные (down) в запросе
+func AddRecords(ctx context.Context, up, down int) {
+	req, ok := ctx.Value(meterKey{}).(*request)
+	if !ok {
+		return
+	}
+	req.recordsUploaded += int64(up)
+	req.recordsDownloaded += int64(down)
+}
+
+// countingBody считает прочитанные байты тела запроса
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
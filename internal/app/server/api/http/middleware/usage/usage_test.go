@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/adapters/humachi"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+type recorded struct {
+	userID int
+	device string
+	usage  sync.DeviceUsage
+}
+
+type fakeRecorder struct {
+	calls []recorded
+}
+
+func (f *fakeRecorder) RecordDeviceUsage(_ context.Context, userID int, deviceUUID string, usage sync.DeviceUsage) error {
+	f.calls = append(f.calls, recorded{userID: userID, device: deviceUUID, usage: usage})
+	return nil
+}
+
+type echoInput struct {
+	Body struct {
+		Records []string `json:"records"`
+	}
+}
+
+type echoOutput struct {
+	Body struct {
+		Records []string `json:"records"`
+	}
+}
+
+func newServer(meter *Meter, authenticated bool) http.Handler {
+	mux := chi.NewMux()
+	mux.Use(meter.Handler)
+	api := humachi.New(mux, huma.DefaultConfig("test", "1.0.0"))
+
+	// Вместо мидлвари auth пользователь ставится напрямую
+	fakeAuth := func(ctx huma.Context, next func(huma.Context)) {
+		if authenticated {
+			ctx = huma.WithContext(ctx, auth.WithUserID(ctx.Context(), 42))
+		}
+		next(ctx)
+	}
+
+	huma.Register(api, huma.Operation{
+		Method:      http.MethodPost,
+		Path:        "/echo",
+		Middlewares: huma.Middlewares{fakeAuth, meter.Middleware()},
+	}, func(ctx context.Context, input *echoInput) (*echoOutput, error) {
+		AddRecords(ctx, len(input.Body.Records), 1)
+		out := &echoOutput{}
+		out.Body.Records = input.Body.Records[:1]
+		return out, nil
+	})
+	return mux
+}
+
+func TestMeter(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	body := `{"records":["a","b","c"]}`
+
+	serve := func(h http.Handler, device string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if device != "" {
+			req.Header.Set(sync.DeviceHeader, device)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("authenticated device request is recorded", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		rec := serve(newServer(New(recorder, log), true), "dev-1")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		require.Len(t, recorder.calls, 1)
+		call := recorder.calls[0]
+		assert.Equal(t, 42, call.userID)
+		assert.Equal(t, "dev-1", call.device)
+		assert.Equal(t, sync.DeviceUsage{
+			BytesUploaded:     int64(len(body)),
+			BytesDownloaded:   int64(rec.Body.Len()),
+			Requests:          1,
+			RecordsUploaded:   3,
+			RecordsDownloaded: 1,
+		}, call.usage)
+	})
+
+	t.Run("request without device or user is not recorded", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		serve(newServer(New(recorder, log), true), "")
+		serve(newServer(New(recorder, log), false), "dev-1")
+		assert.Empty(t, recorder.calls)
+	})
+}
+
+func TestAddRecords_WithoutMeter(t *testing.T) {
+	assert.NotPanics(t, func() { AddRecords(context.Background(), 1, 1) })
+}
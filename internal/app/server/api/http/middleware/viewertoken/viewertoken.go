@@ -0,0 +1,68 @@
+package viewertoken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/viewer"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Authenticator проверяет секрет токена просмотра
+type Authenticator interface {
+	Authenticate(ctx context.Context, secret string) (*viewer.Token, error)
+}
+
+// ViewerToken пропускает запросы с действующим токеном просмотра
+// (Authorization: Bearer gkv_...) и кладет токен в контекст. Токены сессий
+// здесь не принимаются, а токены просмотра - в мидлвари auth, поэтому
+// токен просмотра открывает только операции /api/shared.
+type ViewerToken struct {
+	auth Authenticator
+	log  *slog.Logger
+}
+
+// New создает проверку токенов просмотра
+func New(auth Authenticator, log *slog.Logger) *ViewerToken {
+	return &ViewerToken{
+		auth: auth,
+		log:  log.With("component", "viewer token middleware"),
+	}
+}
+
+// Middleware отвечает 401 на запросы без действующего токена просмотра
+func (v *ViewerToken) Middleware() func(huma.Context, func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		secret, ok := strings.CutPrefix(ctx.Header("Authorization"), "Bearer ")
+		if !ok {
+			v.reject(ctx, http.StatusUnauthorized, apperr.CodeUnauthorized, "Unauthorized")
+			return
+		}
+
+		token, err := v.auth.Authenticate(ctx.Context(), strings.TrimSpace(secret))
+		if err != nil {
+			if errors.Is(err, apperr.Unauthorized) {
+				v.log.WarnContext(ctx.Context(), "viewer token rejected", "error", err)
+				v.reject(ctx, http.StatusUnauthorized, apperr.CodeOf(err), "Unauthorized")
+				return
+			}
+			v.log.ErrorContext(ctx.Context(), "viewer token check failed", "error", err)
+			v.reject(ctx, http.StatusInternalServerError, apperr.CodeInternal, "failed to validate viewer token")
+			return
+		}
+
+		next(huma.WithContext(ctx, viewer.WithToken(ctx.Context(), token)))
+	}
+}
+
+func (v *ViewerToken) reject(ctx huma.Context, status int, code apperr.Code, message string) {
+	if err := problem.Write(ctx, status, code, message); err != nil {
+		v.log.Error("json encoding", "error", err)
+	}
+}
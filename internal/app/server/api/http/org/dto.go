@@ -0,0 +1,117 @@
+package org
+
+import (
+	"encoding/json"
+
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+)
+
+type orgInput struct {
+	ID int `path:"id" example:"1" doc:"ID организации"`
+}
+
+type createInput struct {
+	Body createRequest
+}
+
+type createRequest struct {
+	Name         string `json:"name" minLength:"1" maxLength:"255" doc:"Название организации"`
+	EncryptedKey string `json:"encrypted_key" minLength:"1" doc:"Ключ хранилища, зашифрованный мастер-ключом создателя"`
+}
+
+type createOutput struct {
+	Body org.Organization
+}
+
+type listOutput struct {
+	Body listResponse
+}
+
+type listResponse struct {
+	Organizations []org.Summary `json:"organizations"`
+}
+
+type getOutput struct {
+	Body org.Summary
+}
+
+type statusOutput struct {
+	Body statusResponse
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+type membersOutput struct {
+	Body membersResponse
+}
+
+type membersResponse struct {
+	Members []membership.Member `json:"members"`
+}
+
+type inviteInput struct {
+	ID   int `path:"id" example:"1" doc:"ID организации"`
+	Body inviteRequest
+}
+
+type inviteRequest struct {
+	Login        string   `json:"login" minLength:"1" doc:"Логин приглашаемого пользователя"`
+	Role         org.Role `json:"role" enum:"owner,admin,member,read-only" doc:"Роль участника"`
+	EncryptedKey string   `json:"encrypted_key" minLength:"1" doc:"Ключ хранилища, зашифрованный кодом приглашения"`
+}
+
+type inviteOutput struct {
+	Body *membership.Membership
+}
+
+type memberInput struct {
+	ID     int `path:"id" example:"1" doc:"ID организации"`
+	UserID int `path:"user_id" example:"2" doc:"ID участника"`
+}
+
+type changeRoleInput struct {
+	ID     int `path:"id" example:"1" doc:"ID организации"`
+	UserID int `path:"user_id" example:"2" doc:"ID участника"`
+	Body   changeRoleRequest
+}
+
+type changeRoleRequest struct {
+	Role org.Role `json:"role" enum:"owner,admin,member,read-only" doc:"Новая роль участника"`
+}
+
+type acceptInput struct {
+	ID   int `path:"id" example:"1" doc:"ID организации"`
+	Body acceptRequest
+}
+
+type acceptRequest struct {
+	EncryptedKey string `json:"encrypted_key" minLength:"1" doc:"Ключ хранилища, зашифрованный мастер-ключом участника"`
+}
+
+type recordsOutput struct {
+	Body record.ListResponse
+}
+
+type createRecordInput struct {
+	ID   int `path:"id" example:"1" doc:"ID организации"`
+	Body createRecordRequest
+}
+
+type createRecordRequest struct {
+	Type          record.RecType  `json:"type" doc:"Тип записи, одно из login, text, binary, card"`
+	EncryptedData string          `json:"data" doc:"Данные, зашифрованные ключом хранилища"`
+	Meta          json.RawMessage `json:"meta"`
+}
+
+type createRecordOutput struct {
+	Body createRecordResponse
+}
+
+type createRecordResponse struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
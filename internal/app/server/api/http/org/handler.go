@@ -0,0 +1,218 @@
+package org
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+type Handler struct {
+	orgs       org.Servicer
+	members    membership.Servicer
+	records    record.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(orgs org.Servicer, members membership.Servicer, records record.Servicer,
+	log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		orgs:       orgs,
+		members:    members,
+		records:    records,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.createOp(), h.create)
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.deleteOp(), h.delete)
+
+	huma.Register(api, h.membersOp(), h.listMembers)
+	huma.Register(api, h.inviteOp(), h.invite)
+	huma.Register(api, h.acceptOp(), h.accept)
+	huma.Register(api, h.changeRoleOp(), h.changeRole)
+	huma.Register(api, h.removeMemberOp(), h.removeMember)
+
+	huma.Register(api, h.recordsOp(), h.listRecords)
+	huma.Register(api, h.createRecordOp(), h.createRecord)
+}
+
+func (h *Handler) create(ctx context.Context, input *createInput) (*createOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	created, err := h.orgs.Create(ctx, userID, input.Body.Name, input.Body.EncryptedKey)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &createOutput{Body: *created}, nil
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	orgs, err := h.orgs.List(ctx, userID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	if orgs == nil {
+		orgs = []org.Summary{}
+	}
+
+	return &listOutput{Body: listResponse{Organizations: orgs}}, nil
+}
+
+func (h *Handler) get(ctx context.Context, input *orgInput) (*getOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	summary, err := h.orgs.Get(ctx, userID, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &getOutput{Body: *summary}, nil
+}
+
+func (h *Handler) delete(ctx context.Context, input *orgInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.orgs.Delete(ctx, userID, input.ID); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+func (h *Handler) listMembers(ctx context.Context, input *orgInput) (*membersOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	members, err := h.members.List(ctx, userID, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	if members == nil {
+		members = []membership.Member{}
+	}
+
+	return &membersOutput{Body: membersResponse{Members: members}}, nil
+}
+
+func (h *Handler) invite(ctx context.Context, input *inviteInput) (*inviteOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	m, err := h.members.Invite(ctx, userID, input.ID, input.Body.Login, input.Body.Role, input.Body.EncryptedKey)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &inviteOutput{Body: m}, nil
+}
+
+func (h *Handler) accept(ctx context.Context, input *acceptInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.members.Accept(ctx, userID, input.ID, input.Body.EncryptedKey); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+func (h *Handler) changeRole(ctx context.Context, input *changeRoleInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.members.ChangeRole(ctx, userID, input.ID, input.UserID, input.Body.Role); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+func (h *Handler) removeMember(ctx context.Context, input *memberInput) (*statusOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.members.Remove(ctx, userID, input.ID, input.UserID); err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+func (h *Handler) listRecords(ctx context.Context, input *orgInput) (*recordsOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	records, err := h.records.ListOrg(ctx, userID, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &recordsOutput{Body: records}, nil
+}
+
+func (h *Handler) createRecord(ctx context.Context, input *createRecordInput) (*createRecordOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.records.CreateInOrg(ctx, userID, input.ID, record.CreateRequest{
+		Type:          input.Body.Type,
+		EncryptedData: input.Body.EncryptedData,
+		Meta:          input.Body.Meta,
+	})
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	return &createRecordOutput{Body: createRecordResponse{ID: recordID, Status: "Ok"}}, nil
+}
+
+// mapError переводит ошибки доменов в HTTP-ответы
+func (h *Handler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("organization operation failed", "error", err)
+	return huma.Error500InternalServerError("organization operation failed")
+}
@@ -0,0 +1,107 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/exp/slog"
+)
+
+type MockOrgService struct {
+	mock.Mock
+}
+
+func (m *MockOrgService) Create(ctx context.Context, userID int, name, encryptedKey string) (*org.Organization, error) {
+	args := m.Called(ctx, userID, name, encryptedKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*org.Organization), args.Error(1)
+}
+
+func (m *MockOrgService) List(ctx context.Context, userID int) ([]org.Summary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]org.Summary), args.Error(1)
+}
+
+func (m *MockOrgService) Get(ctx context.Context, userID, orgID int) (*org.Summary, error) {
+	args := m.Called(ctx, userID, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*org.Summary), args.Error(1)
+}
+
+func (m *MockOrgService) Delete(ctx context.Context, userID, orgID int) error {
+	args := m.Called(ctx, userID, orgID)
+	return args.Error(0)
+}
+
+func TestHandler_Create(t *testing.T) {
+	svc := new(MockOrgService)
+	h := NewHandler(svc, nil, nil, slog.Default(), nil)
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	input := &createInput{}
+	input.Body.Name = "Team"
+	input.Body.EncryptedKey = "wrapped"
+
+	svc.On("Create", ctx, 5, "Team", "wrapped").Return(&org.Organization{ID: 7, Name: "Team"}, nil)
+
+	resp, err := h.create(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, resp.Body.ID)
+
+	_, err = h.create(context.Background(), input)
+	assert.Error(t, err)
+}
+
+func TestHandler_List_Empty(t *testing.T) {
+	svc := new(MockOrgService)
+	h := NewHandler(svc, nil, nil, slog.Default(), nil)
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	svc.On("List", ctx, 5).Return(nil, nil)
+
+	resp, err := h.list(ctx, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.Body.Organizations)
+}
+
+func TestHandler_MapError(t *testing.T) {
+	h := NewHandler(nil, nil, nil, slog.Default(), nil)
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{org.ErrNotFound, http.StatusNotFound},
+		{membership.ErrUserNotFound, http.StatusNotFound},
+		{record.ErrNotFound, http.StatusNotFound},
+		{org.ErrForbidden, http.StatusForbidden},
+		{record.ErrForbidden, http.StatusForbidden},
+		{membership.ErrLastOwner, http.StatusConflict},
+		{membership.ErrAlreadyMember, http.StatusConflict},
+		{org.ErrInvalidRole, http.StatusUnprocessableEntity},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		var statusErr huma.StatusError
+		assert.True(t, errors.As(h.mapError(tt.err), &statusErr))
+		assert.Equal(t, tt.status, statusErr.GetStatus(), tt.err.Error())
+	}
+}
@@ -0,0 +1,150 @@
+package org
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) createOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "orgs-create",
+		Method:        http.MethodPost,
+		Path:          "/api/orgs",
+		Summary:       "Создать организацию",
+		Description:   "Создает организацию с общим хранилищем. Создатель становится владельцем. Ключ хранилища генерируется на клиенте и передается только в зашифрованном виде.",
+		Tags:          []string{"orgs"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-list",
+		Method:      http.MethodGet,
+		Path:        "/api/orgs",
+		Summary:     "Организации пользователя",
+		Description: "Возвращает организации пользователя и приглашения в них вместе с зашифрованным ключом хранилища.",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-get",
+		Method:      http.MethodGet,
+		Path:        "/api/orgs/{id}",
+		Summary:     "Получить организацию",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) deleteOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-delete",
+		Method:      http.MethodDelete,
+		Path:        "/api/orgs/{id}",
+		Summary:     "Удалить организацию",
+		Description: "Удаляет организацию вместе с записями хранилища. Доступно только владельцу.",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) membersOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-members-list",
+		Method:      http.MethodGet,
+		Path:        "/api/orgs/{id}/members",
+		Summary:     "Участники организации",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) inviteOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "orgs-members-invite",
+		Method:        http.MethodPost,
+		Path:          "/api/orgs/{id}/members",
+		Summary:       "Пригласить участника",
+		Description:   "Приглашает пользователя по логину. Ключ хранилища передается зашифрованным кодом приглашения, который владелец передает участнику вне сервиса. Доступно владельцам и администраторам.",
+		Tags:          []string{"orgs"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) acceptOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-accept",
+		Method:      http.MethodPost,
+		Path:        "/api/orgs/{id}/accept",
+		Summary:     "Принять приглашение",
+		Description: "Активирует участие. Клиент передает ключ хранилища, перешифрованный мастер-ключом участника.",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) changeRoleOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-members-role",
+		Method:      http.MethodPatch,
+		Path:        "/api/orgs/{id}/members/{user_id}",
+		Summary:     "Изменить роль участника",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) removeMemberOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-members-remove",
+		Method:      http.MethodDelete,
+		Path:        "/api/orgs/{id}/members/{user_id}",
+		Summary:     "Исключить участника",
+		Description: "Исключает участника или отзывает приглашение. Участник может выйти сам, указав свой ID.",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) recordsOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "orgs-records-list",
+		Method:      http.MethodGet,
+		Path:        "/api/orgs/{id}/records",
+		Summary:     "Записи хранилища организации",
+		Description: "Записи читаются, изменяются и удаляются через /api/records/{id} с проверкой роли.",
+		Tags:        []string{"orgs"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) createRecordOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "orgs-records-create",
+		Method:        http.MethodPost,
+		Path:          "/api/orgs/{id}/records",
+		Summary:       "Создать запись в хранилище организации",
+		Description:   "Данные должны быть зашифрованы ключом хранилища. Недоступно участникам с ролью read-only.",
+		Tags:          []string{"orgs"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
@@ -0,0 +1,16 @@
+package password
+
+import "gophkeeper/internal/domain/user"
+
+type changeInput struct {
+	Body user.ChangePasswordRequest
+}
+
+type changeOutput struct {
+	Body changeResponse
+}
+
+// changeResponse - новый токен: прежние сессии после смены пароля завершаются
+type changeResponse struct {
+	Token string `json:"token"`
+}
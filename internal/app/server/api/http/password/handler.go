@@ -0,0 +1,91 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/mfa"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/user"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler меняет пароль входа текущего пользователя
+type Handler struct {
+	users      user.Servicer
+	session    session.Servicer
+	mfa        mfa.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(users user.Servicer, session session.Servicer, mfa mfa.Servicer,
+	log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		users:      users,
+		session:    session,
+		mfa:        mfa,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.changeOp(), h.change)
+}
+
+func (h *Handler) change(ctx context.Context, input *changeInput) (*changeOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	err := h.users.ChangePassword(ctx, userID, input.Body.OldPassword, input.Body.NewPassword)
+	if errors.Is(err, user.ErrInvalidAuth) {
+		// 401 клиент понимает как истекшую сессию
+		return nil, problem.New(http.StatusForbidden, apperr.CodeOf(user.ErrInvalidAuth), "current password is incorrect")
+	}
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+
+	// Сессии с прежним паролем, в том числе на других устройствах, завершаются
+	revoked, err := h.session.RevokeAll(ctx, userID)
+	if err != nil {
+		h.log.Error("revoke sessions", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("password changed, but failed to revoke sessions")
+	}
+
+	// Текущая сессия прошла второй фактор, если он включен: новая тоже
+	required, err := h.mfa.Required(ctx, userID)
+	if err != nil {
+		return nil, h.mapError(err, userID)
+	}
+	create := h.session.Create
+	if required {
+		create = h.session.CreateMFA
+	}
+	token, err := create(ctx, userID)
+	if err != nil {
+		h.log.Error("create session", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("password changed, but failed to create session")
+	}
+
+	h.log.Info("sessions revoked after password change", "user_id", userID, "revoked", revoked)
+	return &changeOutput{Body: changeResponse{Token: token}}, nil
+}
+
+func (h *Handler) mapError(err error, userID int) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("password change failed", "error", err, "user_id", userID)
+	return huma.Error500InternalServerError("failed to change password")
+}
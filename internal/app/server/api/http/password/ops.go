@@ -0,0 +1,20 @@
+package password
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) changeOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-password-change",
+		Method:      http.MethodPost,
+		Path:        "/api/account/password",
+		Summary:     "Сменить пароль входа",
+		Description: "Проверяет текущий пароль и задает новый. Все сессии пользователя завершаются, вместо текущей выдается новый токен. Мастер-пароль не меняется: он не известен серверу.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
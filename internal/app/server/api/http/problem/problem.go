@@ -0,0 +1,104 @@
+// Package problem - общая форма ответов API с ошибкой: problem+json
+// (RFC 9457) с машинно-читаемым кодом, например
+// {"status":404,"title":"Not Found","detail":"record not found","code":"RECORD_NOT_FOUND"}.
+// Клиенты ветвятся по коду, а не по тексту сообщения.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// Error - тело ответа с ошибкой
+type Error struct {
+	huma.ErrorModel
+	Code apperr.Code `json:"code,omitempty" example:"RECORD_NOT_FOUND" doc:"Машинно-читаемый код ошибки"`
+	// RequestID совпадает с заголовком X-Request-ID и записями журнала сервера
+	RequestID string `json:"request_id,omitempty" doc:"Идентификатор запроса для поиска в журнале сервера"`
+}
+
+// humaNewError - конструктор huma по умолчанию, до замены в Install
+var humaNewError = huma.NewError
+
+// New возвращает ответ с заданным статусом и кодом
+func New(status int, code apperr.Code, msg string, errs ...error) *Error {
+	e := &Error{Code: code}
+	if model, ok := humaNewError(status, msg, errs...).(*huma.ErrorModel); ok {
+		e.ErrorModel = *model
+	} else {
+		e.ErrorModel = huma.ErrorModel{Status: status, Title: http.StatusText(status), Detail: msg}
+	}
+	return e
+}
+
+// NewError - замена huma.NewError: ошибки, которые создает сама huma
+// (валидация, неизвестный маршрут) и обработчики через huma.ErrorXXX,
+// получают код по статусу ответа
+func NewError(status int, msg string, errs ...error) huma.StatusError {
+	return New(status, CodeForStatus(status), msg, errs...)
+}
+
+// Install заменяет конструктор ошибок huma. Вызывается до регистрации
+// операций: по нему huma строит схему ответов с ошибкой.
+func Install() {
+	huma.NewError = NewError
+}
+
+// Write отвечает ошибкой из мидлвари, до вызова обработчика
+func Write(ctx huma.Context, status int, code apperr.Code, msg string) error {
+	e := New(status, code, msg)
+	e.RequestID = sl.RequestID(ctx.Context())
+	ctx.SetHeader("Content-Type", "application/problem+json")
+	ctx.SetStatus(status)
+	return json.NewEncoder(ctx.BodyWriter()).Encode(e)
+}
+
+// WriteHTTP отвечает ошибкой из net/http мидлвари, вне операций huma
+func WriteHTTP(w http.ResponseWriter, r *http.Request, status int, code apperr.Code, msg string) error {
+	e := New(status, code, msg)
+	e.RequestID = sl.RequestID(r.Context())
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(e)
+}
+
+// Transformer добавляет идентификатор запроса в ответы с ошибкой,
+// созданные обработчиками и самой huma
+func Transformer(ctx huma.Context, _ string, v any) (any, error) {
+	if e, ok := v.(*Error); ok && e.RequestID == "" {
+		e.RequestID = sl.RequestID(ctx.Context())
+	}
+	return v, nil
+}
+
+// CodeForStatus возвращает общий код для ответа без ошибки домена
+func CodeForStatus(status int) apperr.Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return apperr.CodeInvalid
+	case http.StatusUnauthorized:
+		return apperr.CodeUnauthorized
+	case http.StatusForbidden:
+		return apperr.CodeForbidden
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return apperr.CodeNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return apperr.CodeConflict
+	case http.StatusGone:
+		return apperr.CodeGone
+	case http.StatusRequestEntityTooLarge:
+		return apperr.CodeQuotaExceeded
+	case http.StatusServiceUnavailable:
+		return apperr.CodeUnavailable
+	}
+	if status < http.StatusBadRequest {
+		// 304 Not Modified huma тоже создает через NewError
+		return ""
+	}
+	return apperr.CodeInternal
+}
@@ -0,0 +1,46 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"gophkeeper/internal/domain/apperr"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   apperr.Code
+	}{
+		{http.StatusUnprocessableEntity, apperr.CodeInvalid},
+		{http.StatusUnauthorized, apperr.CodeUnauthorized},
+		{http.StatusNotFound, apperr.CodeNotFound},
+		{http.StatusPreconditionFailed, apperr.CodeConflict},
+		{http.StatusServiceUnavailable, apperr.CodeUnavailable},
+		{http.StatusInternalServerError, apperr.CodeInternal},
+		{http.StatusNotModified, ""},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var pe *Error
+			if assert.ErrorAs(t, NewError(tt.status, "failed"), &pe) {
+				assert.Equal(t, tt.status, pe.GetStatus())
+				assert.Equal(t, tt.code, pe.Code)
+				assert.Equal(t, "failed", pe.Detail)
+			}
+		})
+	}
+}
+
+func TestNew_KeepsDetails(t *testing.T) {
+	pe := New(http.StatusUnprocessableEntity, "INVALID_RECORD_DATA", "validation failed",
+		&huma.ErrorDetail{Location: "body.type", Message: "required"}, errors.New("plain"))
+
+	assert.Equal(t, apperr.Code("INVALID_RECORD_DATA"), pe.Code)
+	assert.Equal(t, http.StatusText(http.StatusUnprocessableEntity), pe.Title)
+	assert.Len(t, pe.Errors, 2)
+}
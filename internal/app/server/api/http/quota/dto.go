@@ -0,0 +1,31 @@
+package quota
+
+import (
+	"gophkeeper/internal/domain/quota"
+)
+
+type usageOutput struct {
+	Body usageResponse
+}
+
+type usageResponse struct {
+	quota.Usage
+	Remaining int64 `json:"remaining"`
+}
+
+type userInput struct {
+	ID int `path:"id" minimum:"1" doc:"ID пользователя"`
+}
+
+type setInput struct {
+	ID   int `path:"id" minimum:"1" doc:"ID пользователя"`
+	Body setRequest
+}
+
+type setRequest struct {
+	Limit int64 `json:"limit" minimum:"1" doc:"Лимит хранилища в байтах"`
+}
+
+func newUsageOutput(usage *quota.Usage) *usageOutput {
+	return &usageOutput{Body: usageResponse{Usage: *usage, Remaining: usage.Remaining()}}
+}
@@ -0,0 +1,47 @@
+package quota
+
+import (
+	"errors"
+	"net/http"
+
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/quota"
+)
+
+// ExceededError - тело ответа 413 при превышении квоты хранилища
+type ExceededError struct {
+	Message   string      `json:"error"`
+	Status    string      `json:"status"`
+	Code      apperr.Code `json:"code"`
+	Used      int64       `json:"used"`
+	Limit     int64       `json:"limit"`
+	Requested int64       `json:"requested"`
+	Remaining int64       `json:"remaining"`
+}
+
+func (e *ExceededError) Error() string {
+	return e.Message
+}
+
+func (e *ExceededError) GetStatus() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// MapError заменяет ошибку превышения квоты ответом 413 с текущим использованием.
+// Остальные ошибки возвращаются без изменений.
+func MapError(err error) error {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return err
+	}
+
+	return &ExceededError{
+		Message:   exceeded.Error(),
+		Status:    "QuotaExceeded",
+		Code:      apperr.CodeOf(exceeded),
+		Used:      exceeded.Used,
+		Limit:     exceeded.Limit,
+		Requested: exceeded.Requested,
+		Remaining: exceeded.Remaining(),
+	}
+}
@@ -0,0 +1,107 @@
+package quota
+
+import (
+	"context"
+	"errors"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/quota"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler отдает пользователю использование его хранилища
+type Handler struct {
+	service    quota.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service quota.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.usageOp(), h.usage)
+}
+
+func (h *Handler) usage(ctx context.Context, _ *struct{}) (*usageOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	usage, err := h.service.Usage(ctx, userID)
+	if err != nil {
+		h.log.Error("get storage usage", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to get storage usage")
+	}
+
+	return newUsageOutput(usage), nil
+}
+
+// AdminHandler управляет лимитами пользователей через admin API
+type AdminHandler struct {
+	service    quota.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewAdminHandler(service quota.Servicer, log *slog.Logger, mws huma.Middlewares) *AdminHandler {
+	return &AdminHandler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *AdminHandler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.setOp(), h.set)
+	huma.Register(api, h.resetOp(), h.reset)
+}
+
+func (h *AdminHandler) get(ctx context.Context, input *userInput) (*usageOutput, error) {
+	usage, err := h.service.Usage(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return newUsageOutput(usage), nil
+}
+
+func (h *AdminHandler) set(ctx context.Context, input *setInput) (*usageOutput, error) {
+	usage, err := h.service.SetLimit(ctx, input.ID, input.Body.Limit)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	h.log.Info("admin changed storage limit", "user_id", input.ID, "limit", input.Body.Limit)
+	return newUsageOutput(usage), nil
+}
+
+func (h *AdminHandler) reset(ctx context.Context, input *userInput) (*usageOutput, error) {
+	usage, err := h.service.ResetLimit(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	h.log.Info("admin reset storage limit", "user_id", input.ID)
+	return newUsageOutput(usage), nil
+}
+
+func (h *AdminHandler) mapError(err error) error {
+	switch {
+	case errors.Is(err, quota.ErrUserNotFound):
+		return huma.Error404NotFound(err.Error())
+	case errors.Is(err, quota.ErrInvalidLimit):
+		return huma.Error422UnprocessableEntity(err.Error())
+	default:
+		h.log.Error("quota operation failed", "error", err)
+		return huma.Error500InternalServerError("quota operation failed")
+	}
+}
@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) usageOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-quota",
+		Method:      http.MethodGet,
+		Path:        "/api/account/quota",
+		Summary:     "Использование хранилища",
+		Description: "Возвращает объем зашифрованных данных пользователя и действующий лимит в байтах. Записи в корзине не учитываются.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-quota-get",
+		Method:      http.MethodGet,
+		Path:        "/api/admin/users/{id}/quota",
+		Summary:     "Квота пользователя",
+		Description: "Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) setOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-quota-set",
+		Method:      http.MethodPut,
+		Path:        "/api/admin/users/{id}/quota",
+		Summary:     "Задать лимит хранилища пользователю",
+		Description: "Задает собственный лимит вместо значения из конфигурации. Уже сохраненные данные не удаляются, даже если превышают новый лимит. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) resetOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-quota-reset",
+		Method:      http.MethodDelete,
+		Path:        "/api/admin/users/{id}/quota",
+		Summary:     "Сбросить лимит пользователя",
+		Description: "Возвращает пользователю лимит из конфигурации сервера. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
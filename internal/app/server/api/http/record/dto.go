@@ -3,9 +3,39 @@ package record
 import (
 	"encoding/json"
 	"gophkeeper/internal/domain/record"
+	"time"
 )
 
+type listInput struct {
+	Type     string   `query:"type" doc:"Тип записи"`
+	Search   string   `query:"search" maxLength:"200" doc:"Подстрока в названии или ресурсе записи"`
+	Title    string   `query:"title" maxLength:"200" doc:"Подстрока в названии записи"`
+	Tags     []string `query:"tag" maxItems:"20" doc:"Теги через запятую; запись должна содержать все"`
+	Category string   `query:"category" maxLength:"100" doc:"Категория записи"`
+	Resource string   `query:"resource" maxLength:"200" doc:"Подстрока в ресурсе логина"`
+	Folder   int      `query:"folder" minimum:"0" doc:"ID папки; в список входят и записи вложенных папок"`
+	Limit    int      `query:"limit" minimum:"0" maximum:"1000" doc:"Максимальное число записей"`
+	Offset   int      `query:"offset" minimum:"0" doc:"Смещение"`
+
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag прошлого ответа: если список не изменился, сервер ответит 304 без тела"`
+}
+
+func (i *listInput) criteria() record.SearchCriteria {
+	return record.SearchCriteria{
+		Type:     i.Type,
+		Query:    i.Search,
+		Title:    i.Title,
+		Tags:     i.Tags,
+		Category: i.Category,
+		Resource: i.Resource,
+		Folder:   i.Folder,
+		Limit:    i.Limit,
+		Offset:   i.Offset,
+	}
+}
+
 type listOutput struct {
+	ETag string `header:"ETag"`
 	Body record.ListResponse
 }
 
@@ -18,19 +48,27 @@ type output struct {
 }
 
 type findOutput struct {
+	ETag string `header:"ETag"`
 	Body findResponse
 }
 
+type headOutput struct {
+	ETag         string `header:"ETag"`
+	Version      int    `header:"X-Record-Version"`
+	LastModified string `header:"Last-Modified"`
+}
+
 type findInput struct {
-	ID int `path:"id" example:"1" doc:"ID записи"`
+	ID string `path:"id" example:"1" doc:"ID или UUID записи"`
 }
 
 type updateInput struct {
-	ID   int `path:"id" example:"1" doc:"ID записи"`
+	ID   string `path:"id" example:"1" doc:"ID или UUID записи"`
 	Body request
 }
 
 type request struct {
+	UUID          string          `json:"uuid,omitempty" doc:"UUID записи, созданный клиентом; повторная отправка с тем же UUID не создает дубликат"`
 	Type          record.RecType  `json:"type" doc:"Тип записи, одно из login, text, binary, card"`
 	EncryptedData string          `json:"data"` // base64
 	Meta          json.RawMessage `json:"meta"`
@@ -38,6 +76,7 @@ type request struct {
 
 type response struct {
 	ID      int    `json:"id,omitempty"`
+	UUID    string `json:"uuid,omitempty"`
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
 	Error   string `json:"error,omitempty"`
@@ -49,6 +88,72 @@ type findResponse struct {
 	Error  string         `json:"error,omitempty"`
 }
 
+type versionsOutput struct {
+	Body versionsResponse
+}
+
+type versionsResponse struct {
+	Status   string           `json:"status"`
+	Versions []record.Version `json:"versions"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type deleteInput struct {
+	ID        string `path:"id" example:"1" doc:"ID или UUID записи"`
+	Permanent bool   `query:"permanent" doc:"Удалить окончательно, минуя корзину"`
+}
+
+type trashOutput struct {
+	Body trashResponse
+}
+
+type trashResponse struct {
+	Status  string          `json:"status"`
+	Records []record.Record `json:"records"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// defaultExpiringWithin - окно истекающих записей без параметра within
+const defaultExpiringWithin = 30 * 24 * time.Hour
+
+type expiringInput struct {
+	Within string `query:"within" example:"720h" doc:"Окно до истечения срока (Go duration), по умолчанию 720h"`
+}
+
+type expiringOutput struct {
+	Body expiringResponse
+}
+
+type expiringResponse struct {
+	Status  string              `json:"status"`
+	Records []record.Expiration `json:"records"`
+	Error   string              `json:"error,omitempty"`
+}
+
+type purgeInput struct {
+	OlderThan string `query:"older_than" example:"720h" doc:"Удалять записи, пролежавшие в корзине дольше (Go duration)"`
+}
+
+type purgeOutput struct {
+	Body purgeResponse
+}
+
+type purgeResponse struct {
+	Status string `json:"status"`
+	Purged int    `json:"purged"`
+	Error  string `json:"error,omitempty"`
+}
+
+type restoreOutput struct {
+	Body restoreResponse
+}
+
+type restoreResponse struct {
+	Status  string `json:"status"`
+	Version int    `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
 // ==================== Login ====================
 
 type createLoginInput struct {
@@ -103,7 +208,7 @@ type createCardInput struct {
 
 type createCardRequest struct {
 	// Data fields
-	CardNumber     string `json:"card_number" doc:"Номер карты" minLength:"13" maxLength:"19"`
+	CardNumber     string `json:"card_number" doc:"Номер карты: 13-19 цифр, можно группами через пробел или дефис" minLength:"13" maxLength:"23"`
 	CardHolder     string `json:"card_holder" doc:"Имя держателя карты" minLength:"1"`
 	ExpiryMonth    string `json:"expiry_month" doc:"Месяц истечения (01-12)" pattern:"^(0[1-9]|1[0-2])$"`
 	ExpiryYear     string `json:"expiry_year" doc:"Год истечения (20XX)" pattern:"^20\\d{2}$"`
@@ -114,7 +219,7 @@ type createCardRequest struct {
 	// Meta fields
 	Title         string   `json:"title" doc:"Название записи" minLength:"1"`
 	BankName      string   `json:"bank_name,omitempty" doc:"Название банка"`
-	PaymentSystem string   `json:"payment_system,omitempty" doc:"Платежная система: visa, mastercard, mir, unionpay, amex, jcb"`
+	PaymentSystem string   `json:"payment_system,omitempty" doc:"Платежная система: visa, mastercard, mir, unionpay, amex, jcb. Не указана - определяется по номеру карты"`
 	Category      string   `json:"category,omitempty" doc:"Категория"`
 	Tags          []string `json:"tags,omitempty" doc:"Теги"`
 	Notes         string   `json:"notes,omitempty" doc:"Заметки"`
@@ -153,3 +258,86 @@ type createBinaryRequest struct {
 	// Common fields
 	DeviceID string `json:"device_id,omitempty" doc:"ID устройства"`
 }
+
+// ==================== OTP ====================
+
+type createOTPInput struct {
+	Body createOTPRequest
+}
+
+type createOTPRequest struct {
+	// Data fields
+	Secret    string `json:"secret" doc:"Секрет TOTP в base32" minLength:"1"`
+	Algorithm string `json:"algorithm,omitempty" doc:"Алгоритм: SHA1, SHA256, SHA512" default:"SHA1"`
+	Digits    int    `json:"digits,omitempty" doc:"Количество цифр кода: 6 или 8" default:"6"`
+	Period    int    `json:"period,omitempty" doc:"Период смены кода в секундах" default:"30"`
+
+	// Meta fields
+	Title       string   `json:"title" doc:"Название записи" minLength:"1"`
+	Issuer      string   `json:"issuer,omitempty" doc:"Сервис, выдавший секрет"`
+	AccountName string   `json:"account_name,omitempty" doc:"Имя учетной записи"`
+	Category    string   `json:"category,omitempty" doc:"Категория"`
+	Tags        []string `json:"tags,omitempty" doc:"Теги"`
+
+	// Common fields
+	DeviceID string `json:"device_id,omitempty" doc:"ID устройства"`
+}
+
+// ==================== SSH Key ====================
+
+type createSSHKeyInput struct {
+	Body createSSHKeyRequest
+}
+
+type createSSHKeyRequest struct {
+	// Data fields
+	PrivateKey  string `json:"private_key,omitempty" doc:"Закрытый ключ в формате PEM/OpenSSH"`
+	PublicKey   string `json:"public_key,omitempty" doc:"Открытый ключ в формате authorized_keys"`
+	Passphrase  string `json:"passphrase,omitempty" doc:"Парольная фраза закрытого ключа"`
+	Certificate string `json:"certificate,omitempty" doc:"OpenSSH-сертификат"`
+
+	// Meta fields
+	Title    string   `json:"title" doc:"Название записи" minLength:"1"`
+	Comment  string   `json:"comment,omitempty" doc:"Комментарий ключа"`
+	Hosts    []string `json:"hosts,omitempty" doc:"Хосты, для которых используется ключ"`
+	Category string   `json:"category,omitempty" doc:"Категория"`
+	Tags     []string `json:"tags,omitempty" doc:"Теги"`
+
+	// Common fields
+	DeviceID string `json:"device_id,omitempty" doc:"ID устройства"`
+}
+
+type attachmentInput struct {
+	ID           string `path:"id" example:"1" doc:"ID или UUID записи"`
+	AttachmentID int    `path:"attachment_id" example:"1" doc:"ID вложения"`
+}
+
+type addAttachmentInput struct {
+	ID   string `path:"id" example:"1" doc:"ID или UUID записи"`
+	Body addAttachmentRequest
+}
+
+type addAttachmentRequest struct {
+	EncryptedMeta string `json:"encrypted_meta" minLength:"1" maxLength:"4096" doc:"Имя и тип файла, зашифрованные клиентом"`
+	Data          []byte `json:"data" doc:"Зашифрованное содержимое файла в base64"`
+}
+
+type attachmentsOutput struct {
+	Body attachmentsResponse
+}
+
+type attachmentsResponse struct {
+	Status      string              `json:"status"`
+	Attachments []record.Attachment `json:"attachments"`
+}
+
+type attachmentOutput struct {
+	Body attachmentResponse
+}
+
+type attachmentResponse struct {
+	Status     string             `json:"status"`
+	Attachment *record.Attachment `json:"attachment"`
+	// Data - содержимое вложения; только при скачивании
+	Data []byte `json:"data,omitempty"`
+}
@@ -3,9 +3,14 @@ package record
 import (
 	"context"
 	"encoding/base64"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
+	"gophkeeper/internal/app/server/api/http/conditional"
+	"gophkeeper/internal/app/server/api/http/httperr"
 	"gophkeeper/internal/app/server/api/http/middleware/auth"
 	"gophkeeper/internal/domain/record"
 
@@ -32,28 +37,65 @@ func (h *Handler) SetupRoutes(api huma.API) {
 	huma.Register(api, h.listOp(), h.list)
 	huma.Register(api, h.createOp(), h.create)
 	huma.Register(api, h.findOp(), h.find)
+	huma.Register(api, h.headOp(), h.head)
 	huma.Register(api, h.updateOp(), h.update)
 	huma.Register(api, h.deleteOp(), h.delete)
+	huma.Register(api, h.versionsOp(), h.versions)
+
+	// Корзина
+	huma.Register(api, h.trashListOp(), h.trashList)
+	huma.Register(api, h.trashPurgeOp(), h.trashPurge)
+	huma.Register(api, h.restoreOp(), h.restore)
+
+	// Срок действия
+	huma.Register(api, h.expiringOp(), h.expiring)
+
+	// Вложения
+	huma.Register(api, h.attachmentsListOp(), h.attachmentsList)
+	huma.Register(api, h.attachmentAddOp(), h.attachmentAdd)
+	huma.Register(api, h.attachmentGetOp(), h.attachmentGet)
+	huma.Register(api, h.attachmentDeleteOp(), h.attachmentDelete)
 
 	// Typed create handlers
 	huma.Register(api, h.createLoginOp(), h.createLogin)
 	huma.Register(api, h.createTextOp(), h.createText)
 	huma.Register(api, h.createCardOp(), h.createCard)
 	huma.Register(api, h.createBinaryOp(), h.createBinary)
+	huma.Register(api, h.createOTPOp(), h.createOTP)
+	huma.Register(api, h.createSSHKeyOp(), h.createSSHKey)
 }
 
-func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+func (h *Handler) list(ctx context.Context, input *listInput) (*listOutput, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
 		return nil, huma.Error401Unauthorized("Unauthorized")
 	}
 
-	records, err := h.service.List(ctx, userID)
+	var records record.ListResponse
+	criteria := input.criteria()
+	if criteria.HasMetaFilters() || criteria.Type != "" || criteria.Limit > 0 || criteria.Offset > 0 {
+		found, err := h.service.Search(ctx, userID, criteria)
+		if err != nil {
+			return nil, err
+		}
+		records = record.NewListResponse(found)
+	} else {
+		var err error
+		if records, err = h.service.List(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	etag, err := conditional.ETag(records)
 	if err != nil {
 		return nil, err
 	}
+	if err := conditional.NotModified(input.IfNoneMatch, etag); err != nil {
+		return nil, err
+	}
 
 	return &listOutput{
+		ETag: etag,
 		Body: records,
 	}, nil
 }
@@ -64,16 +106,18 @@ func (h *Handler) find(ctx context.Context, input *findInput) (*findOutput, erro
 		return nil, huma.Error401Unauthorized("Unauthorized")
 	}
 
-	rec, err := h.service.Find(ctx, userID, input.ID)
+	recordID, err := h.recordID(ctx, userID, input.ID)
 	if err != nil {
-		return &findOutput{
-			Body: findResponse{
-				Status: "Error",
-			},
-		}, err
+		return nil, serviceError(err)
+	}
+
+	rec, err := h.service.Find(ctx, userID, recordID)
+	if err != nil {
+		return nil, serviceError(err)
 	}
 
 	return &findOutput{
+		ETag: rec.Head().ETag(),
 		Body: findResponse{
 			Status: "Ok",
 			Record: rec,
@@ -81,22 +125,51 @@ func (h *Handler) find(ctx context.Context, input *findInput) (*findOutput, erro
 	}, nil
 }
 
+func (h *Handler) head(ctx context.Context, input *findInput) (*headOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	head, err := h.service.Head(ctx, userID, recordID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &headOutput{
+		ETag:         head.ETag(),
+		Version:      head.Version,
+		LastModified: head.LastModified.UTC().Format(http.TimeFormat),
+	}, nil
+}
+
 func (h *Handler) create(ctx context.Context, input *createInput) (*output, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
 		return nil, huma.Error401Unauthorized("Unauthorized")
 	}
 
-	recordID, err := h.service.Create(ctx, userID, input.Body.Type, input.Body.EncryptedData, input.Body.Meta)
+	// UUID берется из сохраненной записи: без UUID в запросе его назначает
+	// сервер, а повтор запроса возвращает запись, созданную первой
+	created, err := h.service.CreateRecord(ctx, userID, record.CreateRequest{
+		UUID:          input.Body.UUID,
+		Type:          input.Body.Type,
+		EncryptedData: input.Body.EncryptedData,
+		Meta:          input.Body.Meta,
+	})
 	if err != nil {
-		return &output{
-			Body: response{Status: "Error"},
-		}, err
+		return nil, serviceError(err)
 	}
 
 	return &output{
 		Body: response{
-			ID:     recordID,
+			ID:     created.ID,
+			UUID:   created.UUID,
 			Status: "Ok",
 		},
 	}, nil
@@ -108,37 +181,277 @@ func (h *Handler) update(ctx context.Context, input *updateInput) (*output, erro
 		return nil, huma.Error401Unauthorized("Unauthorized")
 	}
 
-	err := h.service.Update(ctx, userID, input.ID, input.Body.Type, input.Body.EncryptedData, input.Body.Meta)
+	recordID, err := h.recordID(ctx, userID, input.ID)
 	if err != nil {
-		return &output{
-			Body: response{
-				ID:     input.ID,
-				Status: "Error",
-			},
-		}, err
+		return nil, serviceError(err)
+	}
+
+	err = h.service.Update(ctx, userID, record.UpdateRequest{
+		RecordID:      recordID,
+		Type:          input.Body.Type,
+		EncryptedData: input.Body.EncryptedData,
+		Meta:          input.Body.Meta,
+	})
+	if err != nil {
+		return nil, serviceError(err)
 	}
 	return &output{
 		Body: response{
-			ID:     input.ID,
+			ID:     recordID,
+			Status: "Ok",
+		},
+	}, nil
+}
+
+func (h *Handler) delete(ctx context.Context, input *deleteInput) (*output, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	if input.Permanent {
+		err = h.service.Delete(ctx, userID, recordID)
+	} else {
+		err = h.service.SoftDelete(ctx, userID, recordID)
+	}
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return &output{
+		Body: response{
+			Status: "Ok",
+		},
+	}, nil
+}
+
+func (h *Handler) versions(ctx context.Context, input *findInput) (*versionsOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	versions, err := h.service.GetVersions(ctx, userID, recordID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &versionsOutput{
+		Body: versionsResponse{
+			Status:   "Ok",
+			Versions: versions,
+		},
+	}, nil
+}
+
+func (h *Handler) trashList(ctx context.Context, _ *struct{}) (*trashOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	records, err := h.service.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if records == nil {
+		records = []record.Record{}
+	}
+
+	return &trashOutput{
+		Body: trashResponse{
+			Status:  "Ok",
+			Records: records,
+		},
+	}, nil
+}
+
+func (h *Handler) trashPurge(ctx context.Context, input *purgeInput) (*purgeOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	var olderThan time.Duration
+	if input.OlderThan != "" {
+		d, err := time.ParseDuration(input.OlderThan)
+		if err != nil || d < 0 {
+			return nil, huma.Error400BadRequest("older_than must be a non-negative duration")
+		}
+		olderThan = d
+	}
+
+	purged, err := h.service.PurgeTrash(ctx, userID, olderThan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &purgeOutput{
+		Body: purgeResponse{
 			Status: "Ok",
+			Purged: purged,
+		},
+	}, nil
+}
+
+func (h *Handler) expiring(ctx context.Context, input *expiringInput) (*expiringOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	within := defaultExpiringWithin
+	if input.Within != "" {
+		d, err := time.ParseDuration(input.Within)
+		if err != nil || d < 0 {
+			return nil, huma.Error400BadRequest("within must be a non-negative duration")
+		}
+		within = d
+	}
+
+	expiring, err := h.service.ListExpiring(ctx, userID, within)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if expiring == nil {
+		expiring = []record.Expiration{}
+	}
+
+	return &expiringOutput{
+		Body: expiringResponse{
+			Status:  "Ok",
+			Records: expiring,
+		},
+	}, nil
+}
+
+func (h *Handler) restore(ctx context.Context, input *findInput) (*restoreOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	version, err := h.service.Restore(ctx, userID, recordID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &restoreOutput{
+		Body: restoreResponse{
+			Status:  "Ok",
+			Version: version,
+		},
+	}, nil
+}
+
+func (h *Handler) attachmentsList(ctx context.Context, input *findInput) (*attachmentsOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	attachments, err := h.service.ListAttachments(ctx, userID, recordID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if attachments == nil {
+		attachments = []record.Attachment{}
+	}
+
+	return &attachmentsOutput{
+		Body: attachmentsResponse{
+			Status:      "Ok",
+			Attachments: attachments,
 		},
 	}, nil
 }
 
-func (h *Handler) delete(ctx context.Context, input *updateInput) (*output, error) {
+func (h *Handler) attachmentAdd(ctx context.Context, input *addAttachmentInput) (*attachmentOutput, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
 		return nil, huma.Error401Unauthorized("Unauthorized")
 	}
 
-	err := h.service.Delete(ctx, userID, input.ID)
+	recordID, err := h.recordID(ctx, userID, input.ID)
 	if err != nil {
-		return &output{
-			Body: response{
-				Status: "Error",
-			},
-		}, err
+		return nil, serviceError(err)
 	}
+
+	attachment, err := h.service.AddAttachment(ctx, userID, recordID, record.AttachmentRequest{
+		EncryptedMeta: input.Body.EncryptedMeta,
+		Data:          input.Body.Data,
+	})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &attachmentOutput{
+		Body: attachmentResponse{
+			Status:     "Ok",
+			Attachment: attachment,
+		},
+	}, nil
+}
+
+func (h *Handler) attachmentGet(ctx context.Context, input *attachmentInput) (*attachmentOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	attachment, err := h.service.GetAttachment(ctx, userID, recordID, input.AttachmentID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &attachmentOutput{
+		Body: attachmentResponse{
+			Status:     "Ok",
+			Attachment: attachment,
+			Data:       attachment.Data,
+		},
+	}, nil
+}
+
+func (h *Handler) attachmentDelete(ctx context.Context, input *attachmentInput) (*output, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	recordID, err := h.recordID(ctx, userID, input.ID)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	if err := h.service.DeleteAttachment(ctx, userID, recordID, input.AttachmentID); err != nil {
+		return nil, serviceError(err)
+	}
+
 	return &output{
 		Body: response{
 			Status: "Ok",
@@ -176,17 +489,15 @@ func (h *Handler) createLogin(ctx context.Context, input *createLoginInput) (*ou
 	}
 
 	// Создание записи
-	recordID, err := h.service.CreateWithModels(ctx, userID,
-		record.RecTypeLogin,
-		loginData,
-		loginMeta,
-		input.Body.DeviceID,
-	)
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeLogin,
+		Data:     loginData,
+		Meta:     loginMeta,
+		DeviceID: input.Body.DeviceID,
+	})
 
 	if err != nil {
-		return &output{
-			Body: response{Status: "Error", Message: err.Error()},
-		}, err
+		return nil, serviceError(err)
 	}
 
 	return &output{
@@ -231,17 +542,15 @@ func (h *Handler) createText(ctx context.Context, input *createTextInput) (*outp
 	}
 
 	// Создание записи
-	recordID, err := h.service.CreateWithModels(ctx, userID,
-		record.RecTypeText,
-		textData,
-		textMeta,
-		input.Body.DeviceID,
-	)
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeText,
+		Data:     textData,
+		Meta:     textMeta,
+		DeviceID: input.Body.DeviceID,
+	})
 
 	if err != nil {
-		return &output{
-			Body: response{Status: "Error", Message: err.Error()},
-		}, err
+		return nil, serviceError(err)
 	}
 
 	return &output{
@@ -292,18 +601,20 @@ func (h *Handler) createCard(ctx context.Context, input *createCardInput) (*outp
 		return nil, huma.Error422UnprocessableEntity(err.Error())
 	}
 
+	if err := cardMeta.ApplyPaymentSystem(cardData); err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+
 	// Создание записи
-	recordID, err := h.service.CreateWithModels(ctx, userID,
-		record.RecTypeCard,
-		cardData,
-		cardMeta,
-		input.Body.DeviceID,
-	)
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeCard,
+		Data:     cardData,
+		Meta:     cardMeta,
+		DeviceID: input.Body.DeviceID,
+	})
 
 	if err != nil {
-		return &output{
-			Body: response{Status: "Error", Message: err.Error()},
-		}, err
+		return nil, serviceError(err)
 	}
 
 	return &output{
@@ -363,17 +674,15 @@ func (h *Handler) createBinary(ctx context.Context, input *createBinaryInput) (*
 	}
 
 	// Создание записи
-	recordID, err := h.service.CreateWithModels(ctx, userID,
-		record.RecTypeBinary,
-		binaryData,
-		binaryMeta,
-		input.Body.DeviceID,
-	)
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeBinary,
+		Data:     binaryData,
+		Meta:     binaryMeta,
+		DeviceID: input.Body.DeviceID,
+	})
 
 	if err != nil {
-		return &output{
-			Body: response{Status: "Error", Message: err.Error()},
-		}, err
+		return nil, serviceError(err)
 	}
 
 	return &output{
@@ -386,6 +695,107 @@ func (h *Handler) createBinary(ctx context.Context, input *createBinaryInput) (*
 
 // Helper functions
 
+func (h *Handler) createOTP(ctx context.Context, input *createOTPInput) (*output, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	otpData := &record.OTPData{
+		Secret:    input.Body.Secret,
+		Algorithm: input.Body.Algorithm,
+		Digits:    input.Body.Digits,
+		Period:    input.Body.Period,
+	}
+
+	otpMeta := &record.OTPMeta{
+		Title:       input.Body.Title,
+		Issuer:      input.Body.Issuer,
+		AccountName: input.Body.AccountName,
+		Category:    input.Body.Category,
+		Tags:        input.Body.Tags,
+	}
+
+	if err := otpData.Validate(); err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+
+	if err := otpMeta.Validate(); err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeOTP,
+		Data:     otpData,
+		Meta:     otpMeta,
+		DeviceID: input.Body.DeviceID,
+	})
+
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &output{
+		Body: response{
+			ID:     recordID,
+			Status: "Ok",
+		},
+	}, nil
+}
+
+func (h *Handler) createSSHKey(ctx context.Context, input *createSSHKeyInput) (*output, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	keyData := &record.SSHKeyData{
+		PrivateKey:  input.Body.PrivateKey,
+		PublicKey:   input.Body.PublicKey,
+		Passphrase:  input.Body.Passphrase,
+		Certificate: input.Body.Certificate,
+	}
+
+	info, err := keyData.Parse()
+	if err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+
+	keyMeta := &record.SSHKeyMeta{
+		Title:          input.Body.Title,
+		KeyType:        info.KeyType,
+		Bits:           info.Bits,
+		Fingerprint:    info.Fingerprint,
+		Comment:        input.Body.Comment,
+		Hosts:          input.Body.Hosts,
+		HasCertificate: keyData.Certificate != "",
+		Category:       input.Body.Category,
+		Tags:           input.Body.Tags,
+	}
+
+	if err := keyMeta.Validate(); err != nil {
+		return nil, huma.Error422UnprocessableEntity(err.Error())
+	}
+
+	recordID, err := h.service.CreateWithModels(ctx, userID, record.ModelRequest{
+		Type:     record.RecTypeSSHKey,
+		Data:     keyData,
+		Meta:     keyMeta,
+		DeviceID: input.Body.DeviceID,
+	})
+
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &output{
+		Body: response{
+			ID:     recordID,
+			Status: "Ok",
+		},
+	}, nil
+}
+
 func countWords(s string) int {
 	words := strings.Fields(s)
 	return len(words)
@@ -398,3 +808,16 @@ func truncateString(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen]) + "..."
 }
+
+// serviceError переводит ошибки сервиса записей в HTTP-статусы
+func serviceError(err error) error {
+	return httperr.Map(err)
+}
+
+// recordID возвращает ID записи по параметру пути: числовому ID или UUID
+func (h *Handler) recordID(ctx context.Context, userID int, ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	return h.service.ResolveUUID(ctx, userID, ref)
+}
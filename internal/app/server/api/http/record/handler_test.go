@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	quotaAPI "gophkeeper/internal/app/server/api/http/quota"
+	"gophkeeper/internal/domain/quota"
 	"gophkeeper/internal/domain/record"
 	"testing"
 	"time"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,16 +21,26 @@ type MockService struct {
 	mock.Mock
 }
 
+var _ record.Servicer = (*MockService)(nil)
+
 func (m *MockService) List(ctx context.Context, userID int) (record.ListResponse, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).(record.ListResponse), args.Error(1)
 }
 
-func (m *MockService) Create(ctx context.Context, userID int, typ record.RecType, encryptedData string, meta json.RawMessage) (int, error) {
-	args := m.Called(ctx, userID, typ, encryptedData, meta)
+func (m *MockService) Create(ctx context.Context, userID int, req record.CreateRequest) (int, error) {
+	args := m.Called(ctx, userID, req)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockService) CreateRecord(ctx context.Context, userID int, req record.CreateRequest) (*record.Record, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*record.Record), args.Error(1)
+}
+
 func (m *MockService) Find(ctx context.Context, userID, recordID int) (*record.Record, error) {
 	args := m.Called(ctx, userID, recordID)
 	// Безопасное приведение nil к указателю
@@ -36,8 +50,16 @@ func (m *MockService) Find(ctx context.Context, userID, recordID int) (*record.R
 	return args.Get(0).(*record.Record), args.Error(1)
 }
 
-func (m *MockService) Update(ctx context.Context, userID, recordID int, typ record.RecType, encryptedData string, meta json.RawMessage) error {
-	args := m.Called(ctx, userID, recordID, typ, encryptedData, meta)
+func (m *MockService) Head(ctx context.Context, userID, recordID int) (*record.Head, error) {
+	args := m.Called(ctx, userID, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*record.Head), args.Error(1)
+}
+
+func (m *MockService) Update(ctx context.Context, userID int, req record.UpdateRequest) error {
+	args := m.Called(ctx, userID, req)
 	return args.Error(0)
 }
 
@@ -76,8 +98,8 @@ func (m *MockService) BatchUpdate(ctx context.Context, userID int, updates []rec
 	return args.Get(0).(record.BatchUpdateResponse), args.Error(1)
 }
 
-func (m *MockService) GetByType(ctx context.Context, userID int, recordType string) ([]record.Record, error) {
-	args := m.Called(ctx, userID, recordType)
+func (m *MockService) GetByType(ctx context.Context, userID int, typ record.RecType) ([]record.Record, error) {
+	args := m.Called(ctx, userID, typ)
 	return args.Get(0).([]record.Record), args.Error(1)
 }
 
@@ -86,21 +108,85 @@ func (m *MockService) GetVersions(ctx context.Context, userID, recordID int) ([]
 	return args.Get(0).([]record.Version), args.Error(1)
 }
 
-func (m *MockService) UpdateWithModels(ctx context.Context, recordID int, userID int, data record.Data, meta record.MetaData, deviceID string) error {
-	args := m.Called(ctx, recordID, userID, data, meta, deviceID)
+func (m *MockService) ResolveUUID(ctx context.Context, userID int, recordUUID string) (int, error) {
+	args := m.Called(ctx, userID, recordUUID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) UpdateWithModels(ctx context.Context, userID, recordID int, req record.ModelRequest) error {
+	args := m.Called(ctx, userID, recordID, req)
 	return args.Error(0)
 }
 
-func (m *MockService) GetRecordWithModels(ctx context.Context, recordID, userID int) (record.Data, record.MetaData, error) {
-	args := m.Called(ctx, recordID, userID)
+func (m *MockService) GetRecordWithModels(ctx context.Context, userID, recordID int) (record.Data, record.MetaData, error) {
+	args := m.Called(ctx, userID, recordID)
 	return args.Get(0).(record.Data), args.Get(1).(record.MetaData), args.Error(2)
 }
 
-func (m *MockService) CreateWithModels(ctx context.Context, userID int, typ record.RecType, data record.Data, meta record.MetaData, deviceID string) (int, error) {
-	args := m.Called(ctx, userID, typ, data, meta, deviceID)
+func (m *MockService) CreateWithModels(ctx context.Context, userID int, req record.ModelRequest) (int, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) ListAttachments(ctx context.Context, userID, recordID int) ([]record.Attachment, error) {
+	args := m.Called(ctx, userID, recordID)
+	return args.Get(0).([]record.Attachment), args.Error(1)
+}
+
+func (m *MockService) AddAttachment(ctx context.Context, userID, recordID int, req record.AttachmentRequest) (*record.Attachment, error) {
+	args := m.Called(ctx, userID, recordID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*record.Attachment), args.Error(1)
+}
+
+func (m *MockService) GetAttachment(ctx context.Context, userID, recordID, attachmentID int) (*record.Attachment, error) {
+	args := m.Called(ctx, userID, recordID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*record.Attachment), args.Error(1)
+}
+
+func (m *MockService) DeleteAttachment(ctx context.Context, userID, recordID, attachmentID int) error {
+	args := m.Called(ctx, userID, recordID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *MockService) ListOrg(ctx context.Context, userID, orgID int) (record.ListResponse, error) {
+	args := m.Called(ctx, userID, orgID)
+	return args.Get(0).(record.ListResponse), args.Error(1)
+}
+
+func (m *MockService) CreateInOrg(ctx context.Context, userID, orgID int, req record.CreateRequest) (int, error) {
+	args := m.Called(ctx, userID, orgID, req)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) ListTrash(ctx context.Context, userID int) ([]record.Record, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]record.Record), args.Error(1)
+}
+
+func (m *MockService) Restore(ctx context.Context, userID, recordID int) (int, error) {
+	args := m.Called(ctx, userID, recordID)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockService) PurgeTrash(ctx context.Context, userID int, olderThan time.Duration) (int, error) {
+	args := m.Called(ctx, userID, olderThan)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockService) ListExpiring(ctx context.Context, userID int, within time.Duration) ([]record.Expiration, error) {
+	args := m.Called(ctx, userID, within)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]record.Expiration), args.Error(1)
+}
+
 func TestHandler_CreateBinary(t *testing.T) {
 	userID := 123
 
@@ -123,14 +209,14 @@ func TestHandler_CreateBinary(t *testing.T) {
 		svc.On("CreateWithModels",
 			mock.Anything,
 			userID,
-			record.RecTypeBinary,
-			mock.MatchedBy(func(d *record.BinaryData) bool {
-				return string(d.Data) == "hello binary" && d.Size == 12
-			}),
-			mock.MatchedBy(func(m *record.BinaryMeta) bool {
-				return m.Title == "My File"
+			mock.MatchedBy(func(req record.ModelRequest) bool {
+				d, ok := req.Data.(*record.BinaryData)
+				if !ok || req.Type != record.RecTypeBinary {
+					return false
+				}
+				m, ok := req.Meta.(*record.BinaryMeta)
+				return ok && string(d.Data) == "hello binary" && d.Size == 12 && m.Title == "My File"
 			}),
-			mock.Anything,
 		).Return(123, nil)
 
 		resp, err := h.createBinary(authCtx, input)
@@ -166,13 +252,11 @@ func TestHandler_CreateText_MetadataLogic(t *testing.T) {
 
 	svc.On("CreateWithModels",
 		mock.Anything, // ctx
-		userID,        // В твоем CreateWithModels почему-то userID string?
-		record.RecTypeText,
-		mock.Anything,
-		mock.MatchedBy(func(m *record.TextMeta) bool {
-			return m.WordCount == 4
+		userID,
+		mock.MatchedBy(func(req record.ModelRequest) bool {
+			m, ok := req.Meta.(*record.TextMeta)
+			return ok && req.Type == record.RecTypeText && m.WordCount == 4
 		}),
-		mock.Anything,
 	).Return(1, nil)
 
 	// Вызываем метод
@@ -181,3 +265,262 @@ func TestHandler_CreateText_MetadataLogic(t *testing.T) {
 	// Если тут падает Unauthorized, значит auth.GetUserID(ctx) вернул ok=false
 	assert.NoError(t, err)
 }
+
+func TestHandler_Versions(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+
+	versions := []record.Version{
+		{ID: 2, RecordID: 10, Version: 2, CreatedAt: time.Now()},
+		{ID: 1, RecordID: 10, Version: 1, CreatedAt: time.Now()},
+	}
+	svc.On("GetVersions", mock.Anything, userID, 10).Return(versions, nil)
+
+	resp, err := h.versions(ctx, &findInput{ID: "10"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Ok", resp.Body.Status)
+	assert.Len(t, resp.Body.Versions, 2)
+
+	_, err = h.versions(context.Background(), &findInput{ID: "10"})
+	assert.Error(t, err)
+}
+
+func TestHandler_ListWithFilters(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+
+	t.Run("no filters uses List", func(t *testing.T) {
+		svc.On("List", mock.Anything, userID).Return(record.ListResponse{Total: 0}, nil).Once()
+
+		resp, err := h.list(ctx, &listInput{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, resp.Body.Total)
+	})
+
+	t.Run("meta filters use Search", func(t *testing.T) {
+		expected := record.SearchCriteria{
+			Query: "github",
+			Tags:  []string{"work"},
+		}
+		found := []record.Record{
+			{ID: 3, Type: record.RecTypeLogin, EncryptedData: "secret", Meta: json.RawMessage(`{"title":"GitHub","tags":["work"]}`)},
+		}
+		svc.On("Search", mock.Anything, userID, expected).Return(found, nil).Once()
+
+		resp, err := h.list(ctx, &listInput{Search: "github", Tags: []string{"work"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, resp.Body.Total)
+		assert.Equal(t, 3, resp.Body.Records[0].ID)
+	})
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandler_ListNotModified(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+	list := record.ListResponse{
+		Records: []record.Item{{ID: 1, Type: record.RecTypeText, Version: 2}},
+		Total:   1,
+	}
+	svc.On("List", mock.Anything, userID).Return(list, nil)
+
+	resp, err := h.list(ctx, &listInput{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.ETag)
+
+	_, err = h.list(ctx, &listInput{IfNoneMatch: resp.ETag})
+	assertStatus(t, err, 304)
+	var he huma.HeadersError
+	if assert.ErrorAs(t, err, &he) {
+		assert.Equal(t, resp.ETag, he.GetHeaders().Get("ETag"))
+	}
+
+	resp2, err := h.list(ctx, &listInput{IfNoneMatch: `"stale"`})
+	assert.NoError(t, err)
+	assert.Equal(t, resp.ETag, resp2.ETag)
+}
+
+func TestHandler_Head(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+	modified := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	head := &record.Head{ID: 10, UserID: userID, Version: 3, Checksum: "abcdef0123456789abcdef", LastModified: modified}
+	svc.On("Head", mock.Anything, userID, 10).Return(head, nil)
+	svc.On("Head", mock.Anything, userID, 11).Return(nil, record.ErrNotFound)
+	svc.On("Head", mock.Anything, userID, 12).Return(&record.Head{ID: 12, Version: 5}, record.ErrRecordDeleted)
+
+	resp, err := h.head(ctx, &findInput{ID: "10"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"3-abcdef0123456789"`, resp.ETag)
+	assert.Equal(t, 3, resp.Version)
+	assert.Equal(t, "Sat, 01 Mar 2025 12:00:00 GMT", resp.LastModified)
+
+	_, err = h.head(ctx, &findInput{ID: "11"})
+	assertStatus(t, err, 404)
+
+	_, err = h.head(ctx, &findInput{ID: "12"})
+	assertStatus(t, err, 410)
+
+	_, err = h.head(context.Background(), &findInput{ID: "10"})
+	assertStatus(t, err, 401)
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var se huma.StatusError
+	if assert.ErrorAs(t, err, &se) {
+		assert.Equal(t, status, se.GetStatus())
+	}
+}
+
+func TestHandler_Trash(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+
+	t.Run("delete moves record to trash", func(t *testing.T) {
+		svc.On("SoftDelete", mock.Anything, userID, 10).Return(nil).Once()
+
+		resp, err := h.delete(ctx, &deleteInput{ID: "10"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Ok", resp.Body.Status)
+	})
+
+	t.Run("permanent delete", func(t *testing.T) {
+		svc.On("Delete", mock.Anything, userID, 10).Return(nil).Once()
+
+		_, err := h.delete(ctx, &deleteInput{ID: "10", Permanent: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		deleted := time.Now()
+		svc.On("ListTrash", mock.Anything, userID).Return([]record.Record{{ID: 10, DeletedAt: &deleted}}, nil).Once()
+
+		resp, err := h.trashList(ctx, &struct{}{})
+		assert.NoError(t, err)
+		assert.Len(t, resp.Body.Records, 1)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		svc.On("Restore", mock.Anything, userID, 10).Return(4, nil).Once()
+		svc.On("Restore", mock.Anything, userID, 11).Return(0, record.ErrNotDeleted).Once()
+		svc.On("Restore", mock.Anything, userID, 12).Return(0, record.ErrNotFound).Once()
+
+		resp, err := h.restore(ctx, &findInput{ID: "10"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, resp.Body.Version)
+
+		_, err = h.restore(ctx, &findInput{ID: "11"})
+		assertStatus(t, err, 409)
+
+		_, err = h.restore(ctx, &findInput{ID: "12"})
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		svc.On("PurgeTrash", mock.Anything, userID, 720*time.Hour).Return(3, nil).Once()
+		svc.On("PurgeTrash", mock.Anything, userID, time.Duration(0)).Return(5, nil).Once()
+
+		resp, err := h.trashPurge(ctx, &purgeInput{OlderThan: "720h"})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, resp.Body.Purged)
+
+		resp, err = h.trashPurge(ctx, &purgeInput{})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, resp.Body.Purged)
+
+		_, err = h.trashPurge(ctx, &purgeInput{OlderThan: "30d"})
+		assertStatus(t, err, 400)
+	})
+
+	_, err := h.trashList(context.Background(), &struct{}{})
+	assertStatus(t, err, 401)
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandler_Expiring(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+	soon := []record.Expiration{{RecordID: 3, Type: record.RecTypeCard, Title: "Visa", ExpiresAt: time.Now().Add(48 * time.Hour)}}
+
+	svc.On("ListExpiring", mock.Anything, userID, 30*24*time.Hour).Return(soon, nil).Once()
+	svc.On("ListExpiring", mock.Anything, userID, 168*time.Hour).Return(nil, nil).Once()
+
+	resp, err := h.expiring(ctx, &expiringInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, soon, resp.Body.Records)
+
+	resp, err = h.expiring(ctx, &expiringInput{Within: "168h"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.Body.Records)
+	assert.Empty(t, resp.Body.Records)
+
+	_, err = h.expiring(ctx, &expiringInput{Within: "-1h"})
+	assertStatus(t, err, 400)
+
+	_, err = h.expiring(context.Background(), &expiringInput{})
+	assertStatus(t, err, 401)
+
+	svc.AssertExpectations(t)
+}
+
+func TestHandler_QuotaExceeded(t *testing.T) {
+	svc := new(MockService)
+	h := NewHandler(svc, nil, nil)
+
+	userID := 7
+	ctx := auth.WithUserID(context.Background(), userID)
+	exceeded := &quota.ExceededError{
+		Usage:     quota.Usage{UserID: userID, Used: 90, Limit: 100},
+		Requested: 20,
+	}
+
+	svc.On("CreateRecord", mock.Anything, userID, record.CreateRequest{Type: record.RecTypeText, EncryptedData: "data"}).
+		Return(nil, exceeded).Once()
+	svc.On("Update", mock.Anything, userID, record.UpdateRequest{RecordID: 10, Type: record.RecTypeText, EncryptedData: "data"}).
+		Return(fmt.Errorf("update: %w", exceeded)).Once()
+
+	input := &createInput{}
+	input.Body.Type = record.RecTypeText
+	input.Body.EncryptedData = "data"
+	_, err := h.create(ctx, input)
+	assertStatus(t, err, 413)
+
+	var body *quotaAPI.ExceededError
+	if assert.ErrorAs(t, err, &body) {
+		assert.Equal(t, int64(90), body.Used)
+		assert.Equal(t, int64(100), body.Limit)
+		assert.Equal(t, int64(20), body.Requested)
+		assert.Equal(t, int64(10), body.Remaining)
+	}
+
+	update := &updateInput{ID: "10"}
+	update.Body.Type = record.RecTypeText
+	update.Body.EncryptedData = "data"
+	_, err = h.update(ctx, update)
+	assertStatus(t, err, 413)
+}
@@ -3,6 +3,8 @@ package record
 import (
 	"net/http"
 
+	"gophkeeper/internal/domain/record"
+
 	"github.com/danielgtaylor/huma/v2"
 )
 
@@ -12,6 +14,7 @@ func (h *Handler) listOp() huma.Operation {
 		Method:      http.MethodGet,
 		Path:        "/api/records",
 		Summary:     "Список записей пользователя",
+		Description: "Без параметров возвращает все записи. Фильтры по метаданным: search - подстрока в названии или ресурсе, tag - теги (все должны присутствовать), category, resource. Ответ содержит ETag; с If-None-Match неизменившийся список возвращает 304 без тела.",
 		Tags:        []string{"records"},
 		Security:    []map[string][]string{{"bearer": {}}},
 		Middlewares: h.middleware,
@@ -43,6 +46,19 @@ func (h *Handler) findOp() huma.Operation {
 	}
 }
 
+func (h *Handler) headOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-head",
+		Method:      http.MethodHead,
+		Path:        "/api/records/{id}",
+		Summary:     "Проверить версию записи",
+		Description: "Возвращает версию записи в заголовках ETag, X-Record-Version и Last-Modified без данных. Клиент сверяет их с кэшем перед загрузкой записи. Удаленная запись возвращает 410.",
+		Tags:        []string{"records"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
 func (h *Handler) updateOp() huma.Operation {
 	return huma.Operation{
 		OperationID: "records-update",
@@ -61,12 +77,133 @@ func (h *Handler) deleteOp() huma.Operation {
 		Method:      http.MethodDelete,
 		Path:        "/api/records/{id}",
 		Summary:     "Удалить запись",
+		Description: "Перемещает запись в корзину, откуда ее можно восстановить до истечения срока хранения. С permanent=true запись удаляется окончательно.",
+		Tags:        []string{"records"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) versionsOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-versions",
+		Method:      http.MethodGet,
+		Path:        "/api/records/{id}/versions",
+		Summary:     "История версий записи",
+		Description: "Возвращает предыдущие версии записи. Используется клиентом для трехстороннего слияния при конфликтах.",
+		Tags:        []string{"records"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) trashListOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-trash-list",
+		Method:      http.MethodGet,
+		Path:        "/api/records/trash",
+		Summary:     "Корзина",
+		Description: "Возвращает личные записи, перемещенные в корзину, начиная с последних удаленных.",
+		Tags:        []string{"records", "trash"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) trashPurgeOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-trash-purge",
+		Method:      http.MethodDelete,
+		Path:        "/api/records/trash",
+		Summary:     "Очистить корзину",
+		Description: "Окончательно удаляет записи, пролежавшие в корзине дольше older_than. Без параметра корзина очищается полностью.",
+		Tags:        []string{"records", "trash"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) restoreOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-restore",
+		Method:      http.MethodPost,
+		Path:        "/api/records/{id}/restore",
+		Summary:     "Восстановить запись из корзины",
+		Description: "Снимает пометку удаления и возвращает новую версию записи. Запись не из корзины возвращает 409.",
+		Tags:        []string{"records", "trash"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) expiringOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-expiring",
+		Method:      http.MethodGet,
+		Path:        "/api/records/expiring",
+		Summary:     "Истекающие записи",
+		Description: "Возвращает личные записи, срок действия которых (meta.expires_at) истекает в ближайшие within, включая уже истекшие, начиная с ближайшего срока.",
 		Tags:        []string{"records"},
 		Security:    []map[string][]string{{"bearer": {}}},
 		Middlewares: h.middleware,
 	}
 }
 
+// maxAttachmentBodyBytes - вложение наибольшего размера в base64, метаданные и обертка JSON
+const maxAttachmentBodyBytes = record.MaxAttachmentSize/3*4 + 8*1024
+
+func (h *Handler) attachmentsListOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-attachments-list",
+		Method:      http.MethodGet,
+		Path:        "/api/records/{id}/attachments",
+		Summary:     "Вложения записи",
+		Description: "Возвращает вложения записи без содержимого: ID, зашифрованные клиентом имя и тип файла, размер.",
+		Tags:        []string{"records", "attachments"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) attachmentAddOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "records-attachments-add",
+		Method:        http.MethodPost,
+		Path:          "/api/records/{id}/attachments",
+		Summary:       "Прикрепить файл к записи",
+		Description:   "Сохраняет зашифрованный клиентом файл как вложение записи. Вложение учитывается в квоте хранилища; к защищенной записи файлы не прикрепляются (409).",
+		Tags:          []string{"records", "attachments"},
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+		DefaultStatus: http.StatusCreated,
+		MaxBodyBytes:  maxAttachmentBodyBytes,
+	}
+}
+
+func (h *Handler) attachmentGetOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-attachments-get",
+		Method:      http.MethodGet,
+		Path:        "/api/records/{id}/attachments/{attachment_id}",
+		Summary:     "Скачать вложение",
+		Tags:        []string{"records", "attachments"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) attachmentDeleteOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-attachments-delete",
+		Method:      http.MethodDelete,
+		Path:        "/api/records/{id}/attachments/{attachment_id}",
+		Summary:     "Открепить файл от записи",
+		Tags:        []string{"records", "attachments"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
 // ==================== Typed Create Operations ====================
 
 func (h *Handler) createLoginOp() huma.Operation {
@@ -120,3 +257,29 @@ func (h *Handler) createBinaryOp() huma.Operation {
 		Middlewares: h.middleware,
 	}
 }
+
+func (h *Handler) createOTPOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-create-otp",
+		Method:      http.MethodPost,
+		Path:        "/api/records/otp",
+		Summary:     "Создать запись TOTP",
+		Description: "Создает запись с секретом двухфакторной аутентификации (TOTP). Коды генерируются на клиенте.",
+		Tags:        []string{"records", "otp"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) createSSHKeyOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "records-create-ssh-key",
+		Method:      http.MethodPost,
+		Path:        "/api/records/ssh-key",
+		Summary:     "Создать запись SSH-ключа",
+		Description: "Создает запись с SSH-ключом и, опционально, сертификатом. Тип, длина и отпечаток ключа извлекаются при валидации.",
+		Tags:        []string{"records", "ssh-key"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,22 @@
+package session
+
+import (
+	"gophkeeper/internal/domain/session"
+)
+
+type listOutput struct {
+	Body []session.Session
+}
+
+type revokeInput struct {
+	ID int `path:"id" doc:"ID сессии"`
+}
+
+type revokeOthersOutput struct {
+	Body revokeOthersResponse
+}
+
+// revokeOthersResponse - сколько сессий завершено; текущая сессия остается
+type revokeOthersResponse struct {
+	Revoked int64 `json:"revoked" doc:"Число завершенных сессий"`
+}
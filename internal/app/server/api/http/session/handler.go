@@ -0,0 +1,82 @@
+package session
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/session"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler показывает пользователю его сессии и завершает их
+type Handler struct {
+	service    session.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service session.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.revokeOp(), h.revoke)
+	huma.Register(api, h.revokeOthersOp(), h.revokeOthers)
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+	token, _ := auth.GetToken(ctx)
+
+	sessions, err := h.service.List(ctx, userID, token)
+	if err != nil {
+		h.log.Error("list sessions", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to list sessions")
+	}
+	return &listOutput{Body: sessions}, nil
+}
+
+func (h *Handler) revoke(ctx context.Context, input *revokeInput) (*struct{}, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	if err := h.service.Revoke(ctx, userID, input.ID); err != nil {
+		if httperr.Known(err) {
+			return nil, httperr.Map(err)
+		}
+		h.log.Error("revoke session", "error", err, "user_id", userID, "session_id", input.ID)
+		return nil, huma.Error500InternalServerError("failed to revoke session")
+	}
+	return nil, nil
+}
+
+func (h *Handler) revokeOthers(ctx context.Context, _ *struct{}) (*revokeOthersOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+	token, ok := auth.GetToken(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	revoked, err := h.service.RevokeOthers(ctx, userID, token)
+	if err != nil {
+		h.log.Error("revoke other sessions", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to revoke sessions")
+	}
+	return &revokeOthersOutput{Body: revokeOthersResponse{Revoked: revoked}}, nil
+}
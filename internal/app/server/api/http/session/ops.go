@@ -0,0 +1,47 @@
+package session
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-sessions",
+		Method:      http.MethodGet,
+		Path:        "/api/account/sessions",
+		Summary:     "Активные сессии",
+		Description: "Возвращает неистекшие сессии пользователя: устройство, IP-адрес, User-Agent, время входа и последнего использования. Сессия, с которой выполнен запрос, отмечена current.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) revokeOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "account-session-revoke",
+		Method:        http.MethodDelete,
+		Path:          "/api/account/sessions/{id}",
+		Summary:       "Завершить сессию",
+		Description:   "Завершает сессию пользователя по ID: ее токен больше не принимается. Завершение текущей сессии равносильно выходу.",
+		Tags:          []string{"account"},
+		DefaultStatus: http.StatusNoContent,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) revokeOthersOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-sessions-revoke-others",
+		Method:      http.MethodPost,
+		Path:        "/api/account/sessions/revoke-others",
+		Summary:     "Завершить остальные сессии",
+		Description: "Завершает все сессии пользователя, кроме той, с которой выполнен запрос.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,22 @@
+package settings
+
+import (
+	"gophkeeper/internal/domain/settings"
+)
+
+// Request/Response для Get
+type getInput struct {
+}
+
+type getOutput struct {
+	Body settings.GetResponse
+}
+
+// Request/Response для Update
+type updateInput struct {
+	Body settings.UpdateRequest
+}
+
+type updateOutput struct {
+	Body settings.GetResponse
+}
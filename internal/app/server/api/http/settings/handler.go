@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"context"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/domain/settings"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+type Handler struct {
+	service    settings.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service settings.Servicer, log *slog.Logger, middleware huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: middleware,
+	}
+}
+
+// serviceError переводит ошибку сервиса в ответ: ошибки доменов - с их
+// кодом, остальные - в 500 без подробностей
+func (h *Handler) serviceError(op string, err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error(op, "error", err)
+	return huma.Error500InternalServerError("failed to " + op)
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getOp(), h.get)
+	huma.Register(api, h.updateOp(), h.update)
+}
+
+func (h *Handler) get(ctx context.Context, _ *getInput) (*getOutput, error) {
+	response, err := h.service.Get(ctx)
+	if err != nil {
+		return nil, h.serviceError("get settings", err)
+	}
+
+	return &getOutput{
+		Body: *response,
+	}, nil
+}
+
+func (h *Handler) update(ctx context.Context, input *updateInput) (*updateOutput, error) {
+	response, err := h.service.Update(ctx, input.Body)
+	if err != nil {
+		return nil, h.serviceError("update settings", err)
+	}
+
+	return &updateOutput{
+		Body: *response,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "settings-get",
+		Method:      http.MethodGet,
+		Path:        "/api/settings",
+		Summary:     "Получить настройки пользователя",
+		Description: "Возвращает заданные пользователем настройки. Отсутствующие ключи используют значения по умолчанию клиента.",
+		Tags:        []string{"settings"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) updateOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "settings-update",
+		Method:      http.MethodPatch,
+		Path:        "/api/settings",
+		Summary:     "Обновить настройки пользователя",
+		Description: "Частично обновляет настройки. Значение null сбрасывает ключ к значению по умолчанию.",
+		Tags:        []string{"settings"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
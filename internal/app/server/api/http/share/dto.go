@@ -0,0 +1,106 @@
+package share
+
+import (
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/viewer"
+)
+
+type createInput struct {
+	Body createRequest
+}
+
+type createRequest struct {
+	Name      string `json:"name" minLength:"1" maxLength:"100" doc:"Название токена: кому и зачем выдан"`
+	FolderIDs []int  `json:"folder_ids" minItems:"1" maxItems:"20" doc:"Папки, записи которых (вместе с вложенными папками) открывает токен"`
+	TTL       string `json:"ttl,omitempty" example:"24h" doc:"Срок действия (Go duration, от 1m до 720h), по умолчанию 24h"`
+}
+
+type tokenInput struct {
+	ID int `path:"id" example:"1" doc:"ID токена"`
+}
+
+type issuedOutput struct {
+	Body issuedResponse
+}
+
+type issuedResponse struct {
+	Status string         `json:"status"`
+	Data   *viewer.Issued `json:"data"`
+}
+
+type listOutput struct {
+	Body listResponse
+}
+
+type listResponse struct {
+	Status string         `json:"status"`
+	Tokens []viewer.Token `json:"tokens"`
+}
+
+type statusOutput struct {
+	Body statusResponse
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+type viewOutput struct {
+	Body viewer.View
+}
+
+type recordInput struct {
+	ID int `path:"id" example:"1" doc:"ID записи"`
+}
+
+type recordOutput struct {
+	Body record.Record
+}
+
+type linkCreateInput struct {
+	Body linkCreateRequest
+}
+
+type linkCreateRequest struct {
+	Ciphertext []byte `json:"ciphertext" minLength:"1" doc:"Секрет, зашифрованный ключом из фрагмента ссылки (base64)"`
+	TTL        string `json:"ttl,omitempty" example:"1h" doc:"Срок действия (Go duration, от 1m до 168h), по умолчанию 24h"`
+	MaxViews   int    `json:"max_views,omitempty" minimum:"0" maximum:"100" example:"1" doc:"Число просмотров, по умолчанию 1"`
+}
+
+type linkInput struct {
+	ID string `path:"id" maxLength:"32" doc:"ID ссылки"`
+}
+
+type linkOutput struct {
+	Body linkResponse
+}
+
+type linkResponse struct {
+	Status string           `json:"status"`
+	Data   *secretlink.Link `json:"data"`
+}
+
+type linkListOutput struct {
+	Body linkListResponse
+}
+
+type linkListResponse struct {
+	Status string            `json:"status"`
+	Links  []secretlink.Link `json:"links"`
+}
+
+type secretOutput struct {
+	Body secretResponse
+}
+
+type secretResponse struct {
+	Ciphertext []byte `json:"ciphertext" doc:"Зашифрованный секрет (base64)"`
+}
+
+type pageOutput struct {
+	ContentType  string `header:"Content-Type"`
+	CacheControl string `header:"Cache-Control"`
+	Referrer     string `header:"Referrer-Policy"`
+	Body         []byte
+}
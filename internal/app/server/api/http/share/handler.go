@@ -0,0 +1,228 @@
+package share
+
+import (
+	"context"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/viewer"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler управляет токенами просмотра владельца записей
+type Handler struct {
+	service    viewer.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service viewer.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.createOp(), h.create)
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.revokeOp(), h.revoke)
+}
+
+func (h *Handler) create(ctx context.Context, input *createInput) (*issuedOutput, error) {
+	var ttl time.Duration
+	if input.Body.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(input.Body.TTL); err != nil {
+			return nil, huma.Error400BadRequest("ttl must be a Go duration, e.g. 24h")
+		}
+	}
+
+	issued, err := h.service.Create(ctx, viewer.CreateRequest{
+		Name:      input.Body.Name,
+		FolderIDs: input.Body.FolderIDs,
+		TTL:       ttl,
+	})
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &issuedOutput{Body: issuedResponse{Status: "Ok", Data: issued}}, nil
+}
+
+func (h *Handler) list(ctx context.Context, _ *struct{}) (*listOutput, error) {
+	tokens, err := h.service.List(ctx)
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+	if tokens == nil {
+		tokens = []viewer.Token{}
+	}
+
+	return &listOutput{Body: listResponse{Status: "Ok", Tokens: tokens}}, nil
+}
+
+func (h *Handler) revoke(ctx context.Context, input *tokenInput) (*statusOutput, error) {
+	if err := h.service.Revoke(ctx, input.ID); err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+// ViewerHandler отдает записи по токену просмотра. Операции только читают:
+// изменяющих операций для токена просмотра нет.
+type ViewerHandler struct {
+	service    viewer.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewViewerHandler(service viewer.Servicer, log *slog.Logger, mws huma.Middlewares) *ViewerHandler {
+	return &ViewerHandler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *ViewerHandler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.viewOp(), h.view)
+	huma.Register(api, h.recordOp(), h.record)
+}
+
+func (h *ViewerHandler) view(ctx context.Context, _ *struct{}) (*viewOutput, error) {
+	view, err := h.service.View(ctx)
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+	if view.Folders == nil {
+		view.Folders = []viewer.SharedFolder{}
+	}
+
+	return &viewOutput{Body: *view}, nil
+}
+
+func (h *ViewerHandler) record(ctx context.Context, input *recordInput) (*recordOutput, error) {
+	rec, err := h.service.Record(ctx, input.ID)
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &recordOutput{Body: *rec}, nil
+}
+
+func mapError(log *slog.Logger, err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	log.Error("share request failed", "error", err)
+	return huma.Error500InternalServerError("failed to process request")
+}
+
+// LinkHandler управляет одноразовыми ссылками на секреты владельца
+type LinkHandler struct {
+	service    secretlink.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewLinkHandler(service secretlink.Servicer, log *slog.Logger, mws huma.Middlewares) *LinkHandler {
+	return &LinkHandler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *LinkHandler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.createOp(), h.create)
+	huma.Register(api, h.listOp(), h.list)
+	huma.Register(api, h.revokeOp(), h.revoke)
+}
+
+func (h *LinkHandler) create(ctx context.Context, input *linkCreateInput) (*linkOutput, error) {
+	var ttl time.Duration
+	if input.Body.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(input.Body.TTL); err != nil {
+			return nil, huma.Error400BadRequest("ttl must be a Go duration, e.g. 1h")
+		}
+	}
+
+	link, err := h.service.Create(ctx, secretlink.CreateRequest{
+		Ciphertext: input.Body.Ciphertext,
+		TTL:        ttl,
+		MaxViews:   input.Body.MaxViews,
+	})
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &linkOutput{Body: linkResponse{Status: "Ok", Data: link}}, nil
+}
+
+func (h *LinkHandler) list(ctx context.Context, _ *struct{}) (*linkListOutput, error) {
+	links, err := h.service.List(ctx)
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+	if links == nil {
+		links = []secretlink.Link{}
+	}
+
+	return &linkListOutput{Body: linkListResponse{Status: "Ok", Links: links}}, nil
+}
+
+func (h *LinkHandler) revoke(ctx context.Context, input *linkInput) (*statusOutput, error) {
+	if err := h.service.Revoke(ctx, input.ID); err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &statusOutput{Body: statusResponse{Status: "Ok"}}, nil
+}
+
+// SecretHandler открывает секреты по ссылке без аутентификации. Сервер
+// отдает только шифротекст: ключ есть лишь во фрагменте ссылки у получателя.
+type SecretHandler struct {
+	service    secretlink.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewSecretHandler(service secretlink.Servicer, log *slog.Logger, mws huma.Middlewares) *SecretHandler {
+	return &SecretHandler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *SecretHandler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.openOp(), h.open)
+	huma.Register(api, h.pageOp(), h.page)
+}
+
+func (h *SecretHandler) open(ctx context.Context, input *linkInput) (*secretOutput, error) {
+	ciphertext, err := h.service.Open(ctx, input.ID)
+	if err != nil {
+		return nil, mapError(h.log, err)
+	}
+
+	return &secretOutput{Body: secretResponse{Ciphertext: ciphertext}}, nil
+}
+
+// page отдает страницу без обращения к сервису: просмотр засчитывается,
+// только когда получатель нажимает кнопку
+func (h *SecretHandler) page(_ context.Context, _ *linkInput) (*pageOutput, error) {
+	return &pageOutput{
+		ContentType:  "text/html; charset=utf-8",
+		CacheControl: "no-store",
+		Referrer:     "no-referrer",
+		Body:         secretPage,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package share
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) createOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "share-token-create",
+		Method:        http.MethodPost,
+		Path:          "/api/share/tokens",
+		Summary:       "Выпустить токен просмотра",
+		Description:   "Выпускает токен только для чтения записей указанных папок на ограниченный срок. Секрет токена возвращается один раз; сервер хранит только его хэш.",
+		Tags:          []string{"share"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *Handler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "share-token-list",
+		Method:      http.MethodGet,
+		Path:        "/api/share/tokens",
+		Summary:     "Токены просмотра",
+		Description: "Возвращает токены просмотра пользователя, включая истекшие, без секретов.",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) revokeOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "share-token-revoke",
+		Method:      http.MethodDelete,
+		Path:        "/api/share/tokens/{id}",
+		Summary:     "Отозвать токен просмотра",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *ViewerHandler) viewOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "shared-records-list",
+		Method:      http.MethodGet,
+		Path:        "/api/shared/records",
+		Summary:     "Записи, открытые токеном просмотра",
+		Description: "Возвращает папки токена и их записи без зашифрованных данных. Требует токен просмотра (Authorization: Bearer gkv_...).",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *ViewerHandler) recordOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "shared-records-get",
+		Method:      http.MethodGet,
+		Path:        "/api/shared/records/{id}",
+		Summary:     "Запись, открытая токеном просмотра",
+		Description: "Возвращает запись с зашифрованными данными, если она лежит в папках токена; иначе 404. Требует токен просмотра.",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *LinkHandler) createOp() huma.Operation {
+	return huma.Operation{
+		OperationID:   "share-link-create",
+		Method:        http.MethodPost,
+		Path:          "/api/share/links",
+		Summary:       "Создать одноразовую ссылку на секрет",
+		Description:   "Сохраняет шифротекст секрета с ограничением срока и числа просмотров. Ключ остается в фрагменте ссылки у клиента и на сервер не передается.",
+		Tags:          []string{"share"},
+		DefaultStatus: http.StatusCreated,
+		Security:      []map[string][]string{{"bearer": {}}},
+		Middlewares:   h.middleware,
+	}
+}
+
+func (h *LinkHandler) listOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "share-link-list",
+		Method:      http.MethodGet,
+		Path:        "/api/share/links",
+		Summary:     "Ссылки на секреты",
+		Description: "Возвращает ссылки пользователя, еще не открытые до конца, без шифротекста.",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *LinkHandler) revokeOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "share-link-revoke",
+		Method:      http.MethodDelete,
+		Path:        "/api/share/links/{id}",
+		Summary:     "Удалить ссылку на секрет",
+		Tags:        []string{"share"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *SecretHandler) openOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "secret-open",
+		Method:      http.MethodPost,
+		Path:        "/api/secrets/{id}/open",
+		Summary:     "Открыть секрет по ссылке",
+		Description: "Засчитывает просмотр и возвращает шифротекст; после последнего просмотра секрет удаляется. Метод POST, чтобы предпросмотр ссылок в мессенджерах не расходовал просмотры. Аутентификация не нужна.",
+		Tags:        []string{"share"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *SecretHandler) pageOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "secret-page",
+		Method:      http.MethodGet,
+		Path:        "/s/{id}",
+		Summary:     "Страница секрета",
+		Description: "HTML-страница, которая по нажатию кнопки открывает секрет и расшифровывает его в браузере ключом из фрагмента ссылки.",
+		Tags:        []string{"share"},
+		Middlewares: h.middleware,
+	}
+}
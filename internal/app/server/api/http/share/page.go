@@ -0,0 +1,9 @@
+package share
+
+import _ "embed"
+
+// secretPage расшифровывает секрет в браузере получателя (WebCrypto,
+// AES-256-GCM): ключ берется из фрагмента ссылки и на сервер не уходит
+//
+//go:embed secret.html
+var secretPage []byte
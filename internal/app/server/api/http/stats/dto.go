@@ -0,0 +1,13 @@
+package stats
+
+import (
+	"gophkeeper/internal/domain/stats"
+)
+
+type statsInput struct {
+	Days int `query:"days" default:"30" minimum:"1" maximum:"365" doc:"Период роста хранилища в днях, включая текущий"`
+}
+
+type statsOutput struct {
+	Body *stats.Stats
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"context"
+	"errors"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/stats"
+
+	"github.com/danielgtaylor/huma/v2"
+	"golang.org/x/exp/slog"
+)
+
+// Handler отдает пользователю статистику его хранилища
+type Handler struct {
+	service    stats.Servicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewHandler(service stats.Servicer, log *slog.Logger, mws huma.Middlewares) *Handler {
+	return &Handler{
+		service:    service,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *Handler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getOp(), h.get)
+}
+
+func (h *Handler) get(ctx context.Context, input *statsInput) (*statsOutput, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	result, err := h.service.Get(ctx, userID, input.Days)
+	if err != nil {
+		if errors.Is(err, stats.ErrInvalidPeriod) {
+			return nil, huma.Error422UnprocessableEntity(err.Error())
+		}
+		h.log.Error("get account stats", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to get account stats")
+	}
+
+	return &statsOutput{Body: result}, nil
+}
@@ -0,0 +1,20 @@
+package stats
+
+import (
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func (h *Handler) getOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "account-stats",
+		Method:      http.MethodGet,
+		Path:        "/api/account/stats",
+		Summary:     "Статистика хранилища",
+		Description: "Возвращает число и объем личных записей по типам, рост хранилища по дням за период и активность устройств. Записи в корзине и хранилищах организаций не учитываются.",
+		Tags:        []string{"account"},
+		Security:    []map[string][]string{{"bearer": {}}},
+		Middlewares: h.middleware,
+	}
+}
@@ -6,13 +6,25 @@ import (
 
 // Request/Response структуры для GetChanges
 type getChangesInput struct {
-	Body sync.GetChangesRequest
+	IfNoneMatch string `header:"If-None-Match" doc:"ETag прошлого ответа: если изменения те же, сервер ответит 304 без тела"`
+	DeviceID    string `header:"X-Device-ID" doc:"UUID устройства: запрос с курсором подтверждает получение изменений до него"`
+	Body        sync.GetChangesRequest
 }
 
 type getChangesOutput struct {
+	ETag string `header:"ETag"`
 	Body sync.GetChangesResponse
 }
 
+// Request/Response для Negotiate
+type negotiateInput struct {
+	Body sync.NegotiateRequest
+}
+
+type negotiateOutput struct {
+	Body sync.NegotiateResponse
+}
+
 // Request/Response для BatchSync
 type batchSyncInput struct {
 	Body sync.BatchSyncRequest
@@ -56,6 +68,15 @@ type getDevicesOutput struct {
 	Body sync.GetDevicesResponse
 }
 
+// Request/Response для RegisterDevice
+type registerDeviceInput struct {
+	Body sync.RegisterDeviceRequest
+}
+
+type registerDeviceOutput struct {
+	Body sync.RegisterDeviceResponse
+}
+
 // Request/Response для RemoveDevice
 type removeDeviceInput struct {
 	ID int `path:"id"`
@@ -64,3 +85,39 @@ type removeDeviceInput struct {
 type removeDeviceOutput struct {
 	Body sync.RemoveDeviceResponse
 }
+
+// Request/Response для ApproveDevice
+type approveDeviceInput struct {
+	ID   int `path:"id"`
+	Body sync.ApproveDeviceRequest
+}
+
+type approveDeviceOutput struct {
+	Body sync.ApproveDeviceResponse
+}
+
+// Request/Response для GetCapabilities
+type getCapabilitiesInput struct {
+}
+
+type getCapabilitiesOutput struct {
+	Body sync.GetCapabilitiesResponse
+}
+
+// Request/Response для admin API протокола синхронизации
+type protocolUserInput struct {
+	ID int `path:"id" minimum:"1" doc:"ID пользователя"`
+}
+
+type protocolSetInput struct {
+	ID   int `path:"id" minimum:"1" doc:"ID пользователя"`
+	Body protocolSetRequest
+}
+
+type protocolSetRequest struct {
+	Protocol sync.Protocol `json:"protocol" enum:"v1,v2" doc:"Протокол синхронизации"`
+}
+
+type protocolOutput struct {
+	Body sync.Assignment
+}
@@ -2,7 +2,13 @@ package sync
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
+	"gophkeeper/internal/app/server/api/http/conditional"
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/app/server/api/http/middleware/usage"
 	"gophkeeper/internal/domain/sync"
 
 	"github.com/danielgtaylor/huma/v2"
@@ -11,40 +17,87 @@ import (
 
 type Handler struct {
 	service    sync.Servicer
+	rollout    sync.RolloutServicer
 	log        *slog.Logger
 	middleware huma.Middlewares
 }
 
-func NewHandler(service sync.Servicer, log *slog.Logger, middleware huma.Middlewares) *Handler {
+// NewHandler создает обработчик синхронизации. rollout выбирает протокол
+// пользователя: операции /api/v2/sync доступны только переведенным на v2.
+func NewHandler(service sync.Servicer, rollout sync.RolloutServicer, log *slog.Logger, middleware huma.Middlewares) *Handler {
 	return &Handler{
 		service:    service,
+		rollout:    rollout,
 		log:        log,
 		middleware: middleware,
 	}
 }
 
+// serviceError переводит ошибку сервиса в ответ: ошибки доменов - с их
+// кодом, остальные - в 500 без подробностей
+func (h *Handler) serviceError(op string, err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error(op, "error", err)
+	return huma.Error500InternalServerError("failed to " + op)
+}
+
 func (h *Handler) SetupRoutes(api huma.API) {
 	huma.Register(api, h.getChangesOp(), h.getChanges)
+	huma.Register(api, h.negotiateOp(), h.negotiate)
 	huma.Register(api, h.batchSyncOp(), h.batchSync)
 	huma.Register(api, h.getStatusOp(), h.getStatus)
 	huma.Register(api, h.getConflictsOp(), h.getConflicts)
 	huma.Register(api, h.resolveConflictOp(), h.resolveConflict)
 	huma.Register(api, h.getDevicesOp(), h.getDevices)
+	huma.Register(api, h.registerDeviceOp(), h.registerDevice)
 	huma.Register(api, h.removeDeviceOp(), h.removeDevice)
+	huma.Register(api, h.approveDeviceOp(), h.approveDevice)
+	huma.Register(api, h.getCapabilitiesOp(), h.getCapabilities)
+
+	huma.Register(api, h.getChangesV2Op(), h.getChangesV2)
+	huma.Register(api, h.negotiateV2Op(), h.negotiateV2)
+	huma.Register(api, h.batchSyncV2Op(), h.batchSyncV2)
 }
 
 func (h *Handler) getChanges(ctx context.Context, input *getChangesInput) (*getChangesOutput, error) {
-	response, err := h.service.GetChanges(ctx, input.Body)
+	req := input.Body
+	req.DeviceUUID = input.DeviceID
+
+	response, err := h.service.GetChanges(ctx, req)
+	if err != nil {
+		return nil, h.serviceError("get changes", err)
+	}
+
+	// Время сервера и статистика меняются при каждом запросе, поэтому в ETag
+	// входят только сами изменения и курсор
+	etag, err := conditional.ETag(struct {
+		Records    []sync.RecordSync
+		HasMore    bool
+		NextCursor string
+	}{response.Records, response.HasMore, response.NextCursor})
 	if err != nil {
-		return &getChangesOutput{
-			Body: sync.GetChangesResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, err
+	}
+	if err := conditional.NotModified(input.IfNoneMatch, etag); err != nil {
+		return nil, err
 	}
+	usage.AddRecords(ctx, 0, len(response.Records))
 
 	return &getChangesOutput{
+		ETag: etag,
+		Body: *response,
+	}, nil
+}
+
+func (h *Handler) negotiate(ctx context.Context, input *negotiateInput) (*negotiateOutput, error) {
+	response, err := h.service.Negotiate(ctx, input.Body)
+	if err != nil {
+		return nil, h.serviceError("negotiate sync", err)
+	}
+
+	return &negotiateOutput{
 		Body: *response,
 	}, nil
 }
@@ -52,13 +105,9 @@ func (h *Handler) getChanges(ctx context.Context, input *getChangesInput) (*getC
 func (h *Handler) batchSync(ctx context.Context, input *batchSyncInput) (*batchSyncOutput, error) {
 	response, err := h.service.ProcessBatch(ctx, input.Body)
 	if err != nil {
-		return &batchSyncOutput{
-			Body: sync.BatchSyncResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("process sync batch", err)
 	}
+	usage.AddRecords(ctx, response.Processed, 0)
 
 	return &batchSyncOutput{
 		Body: *response,
@@ -68,12 +117,7 @@ func (h *Handler) batchSync(ctx context.Context, input *batchSyncInput) (*batchS
 func (h *Handler) getStatus(ctx context.Context, _ *getStatusInput) (*getStatusOutput, error) {
 	response, err := h.service.GetStatus(ctx)
 	if err != nil {
-		return &getStatusOutput{
-			Body: sync.GetStatusResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("get sync status", err)
 	}
 
 	return &getStatusOutput{
@@ -84,12 +128,7 @@ func (h *Handler) getStatus(ctx context.Context, _ *getStatusInput) (*getStatusO
 func (h *Handler) getConflicts(ctx context.Context, _ *getConflictsInput) (*getConflictsOutput, error) {
 	response, err := h.service.GetConflicts(ctx)
 	if err != nil {
-		return &getConflictsOutput{
-			Body: sync.GetConflictsResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("get sync conflicts", err)
 	}
 
 	return &getConflictsOutput{
@@ -100,12 +139,7 @@ func (h *Handler) getConflicts(ctx context.Context, _ *getConflictsInput) (*getC
 func (h *Handler) resolveConflict(ctx context.Context, input *resolveConflictInput) (*resolveConflictOutput, error) {
 	response, err := h.service.ResolveConflict(ctx, input.ID, input.Body)
 	if err != nil {
-		return &resolveConflictOutput{
-			Body: sync.ResolveConflictResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("resolve sync conflict", err)
 	}
 
 	return &resolveConflictOutput{
@@ -116,12 +150,7 @@ func (h *Handler) resolveConflict(ctx context.Context, input *resolveConflictInp
 func (h *Handler) getDevices(ctx context.Context, _ *getDevicesInput) (*getDevicesOutput, error) {
 	response, err := h.service.GetDevices(ctx)
 	if err != nil {
-		return &getDevicesOutput{
-			Body: sync.GetDevicesResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("get devices", err)
 	}
 
 	// Конвертируем []*DeviceInfo в []DeviceInfo
@@ -138,18 +167,173 @@ func (h *Handler) getDevices(ctx context.Context, _ *getDevicesInput) (*getDevic
 	}, nil
 }
 
+func (h *Handler) registerDevice(ctx context.Context, input *registerDeviceInput) (*registerDeviceOutput, error) {
+	response, err := h.service.RegisterDevice(ctx, input.Body)
+	if err != nil {
+		return nil, h.serviceError("register device", err)
+	}
+
+	return &registerDeviceOutput{
+		Body: *response,
+	}, nil
+}
+
 func (h *Handler) removeDevice(ctx context.Context, input *removeDeviceInput) (*removeDeviceOutput, error) {
 	response, err := h.service.RemoveDevice(ctx, input.ID)
 	if err != nil {
-		return &removeDeviceOutput{
-			Body: sync.RemoveDeviceResponse{
-				Status: "Error",
-				Error:  err.Error(),
-			},
-		}, nil
+		return nil, h.serviceError("remove device", err)
 	}
 
 	return &removeDeviceOutput{
 		Body: *response,
 	}, nil
 }
+
+func (h *Handler) approveDevice(ctx context.Context, input *approveDeviceInput) (*approveDeviceOutput, error) {
+	response, err := h.service.ApproveDevice(ctx, input.ID, input.Body)
+	if err != nil {
+		return nil, h.serviceError("approve device", err)
+	}
+
+	return &approveDeviceOutput{
+		Body: *response,
+	}, nil
+}
+
+func (h *Handler) getCapabilities(ctx context.Context, _ *getCapabilitiesInput) (*getCapabilitiesOutput, error) {
+	response, err := h.service.GetCapabilities(ctx)
+	if err != nil {
+		return nil, h.serviceError("get sync capabilities", err)
+	}
+
+	if response.Data != nil {
+		assignment, err := h.protocol(ctx)
+		if err != nil {
+			return nil, err
+		}
+		response.Data.Protocol = assignment.Protocol
+		response.Data.Protocols = sync.Protocols
+	}
+
+	return &getCapabilitiesOutput{
+		Body: *response,
+	}, nil
+}
+
+// Протокол v2 работает рядом с v1 и включается пользователям постепенно.
+// Лента изменений v2 принимает только курсор, а записи загружаются только
+// пакетами. v1 остается доступен всем: после отката пользователя на v1
+// клиент перечитывает /api/sync/capabilities и продолжает по старым путям.
+
+func (h *Handler) getChangesV2(ctx context.Context, input *getChangesInput) (*getChangesOutput, error) {
+	if err := h.requireProtocol(ctx, sync.ProtocolV2); err != nil {
+		return nil, err
+	}
+	if input.Body.Offset > 0 {
+		return nil, httperr.Map(fmt.Errorf("%w: offset is not supported in sync protocol v2, use cursor", sync.ErrInvalidCursor))
+	}
+
+	return h.getChanges(ctx, input)
+}
+
+func (h *Handler) negotiateV2(ctx context.Context, input *negotiateInput) (*negotiateOutput, error) {
+	if err := h.requireProtocol(ctx, sync.ProtocolV2); err != nil {
+		return nil, err
+	}
+
+	return h.negotiate(ctx, input)
+}
+
+func (h *Handler) batchSyncV2(ctx context.Context, input *batchSyncInput) (*batchSyncOutput, error) {
+	if err := h.requireProtocol(ctx, sync.ProtocolV2); err != nil {
+		return nil, err
+	}
+
+	return h.batchSync(ctx, input)
+}
+
+// protocol возвращает протокол текущего пользователя
+func (h *Handler) protocol(ctx context.Context) (*sync.Assignment, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, huma.Error401Unauthorized("Unauthorized")
+	}
+
+	assignment, err := h.rollout.Protocol(ctx, userID)
+	if err != nil {
+		h.log.Error("get sync protocol", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to get sync protocol")
+	}
+	return assignment, nil
+}
+
+// requireProtocol отклоняет запрос, если пользователь не переведен на протокол p
+func (h *Handler) requireProtocol(ctx context.Context, p sync.Protocol) error {
+	assignment, err := h.protocol(ctx)
+	if err != nil {
+		return err
+	}
+	if assignment.Protocol != p {
+		return huma.ErrorWithHeaders(httperr.Map(sync.ErrProtocolDisabled),
+			http.Header{sync.ProtocolHeader: {string(assignment.Protocol)}})
+	}
+	return nil
+}
+
+// AdminHandler назначает пользователям протокол синхронизации через admin API:
+// перевести отдельные аккаунты на v2 раньше остальных или вернуть их на v1
+type AdminHandler struct {
+	rollout    sync.RolloutServicer
+	log        *slog.Logger
+	middleware huma.Middlewares
+}
+
+func NewAdminHandler(rollout sync.RolloutServicer, log *slog.Logger, mws huma.Middlewares) *AdminHandler {
+	return &AdminHandler{
+		rollout:    rollout,
+		log:        log,
+		middleware: mws,
+	}
+}
+
+func (h *AdminHandler) SetupRoutes(api huma.API) {
+	huma.Register(api, h.getProtocolOp(), h.get)
+	huma.Register(api, h.setProtocolOp(), h.set)
+	huma.Register(api, h.resetProtocolOp(), h.reset)
+}
+
+func (h *AdminHandler) get(ctx context.Context, input *protocolUserInput) (*protocolOutput, error) {
+	assignment, err := h.rollout.Protocol(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+	return &protocolOutput{Body: *assignment}, nil
+}
+
+func (h *AdminHandler) set(ctx context.Context, input *protocolSetInput) (*protocolOutput, error) {
+	assignment, err := h.rollout.Assign(ctx, input.ID, input.Body.Protocol)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	h.log.Info("admin changed sync protocol", "user_id", input.ID, "protocol", input.Body.Protocol)
+	return &protocolOutput{Body: *assignment}, nil
+}
+
+func (h *AdminHandler) reset(ctx context.Context, input *protocolUserInput) (*protocolOutput, error) {
+	assignment, err := h.rollout.Reset(ctx, input.ID)
+	if err != nil {
+		return nil, h.mapError(err)
+	}
+
+	h.log.Info("admin reset sync protocol", "user_id", input.ID, "protocol", assignment.Protocol)
+	return &protocolOutput{Body: *assignment}, nil
+}
+
+func (h *AdminHandler) mapError(err error) error {
+	if httperr.Known(err) {
+		return httperr.Map(err)
+	}
+	h.log.Error("sync protocol operation failed", "error", err)
+	return huma.Error500InternalServerError("sync protocol operation failed")
+}
@@ -12,7 +12,19 @@ func (h *Handler) getChangesOp() huma.Operation {
 		Method:      http.MethodPost,
 		Path:        "/api/sync/changes",
 		Summary:     "Получить изменения для синхронизации",
-		Description: "Возвращает записи, измененные после указанного времени",
+		Description: "Возвращает записи, измененные после указанного времени. Ответ содержит ETag; с If-None-Match те же изменения возвращают 304 без тела.",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) negotiateOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-negotiate",
+		Method:      http.MethodPost,
+		Path:        "/api/sync/negotiate",
+		Summary:     "Сравнить индекс записей",
+		Description: "Принимает (id, version, checksum) записей клиента и возвращает только ID различающихся записей",
 		Tags:        []string{"sync"},
 		Middlewares: h.middleware,
 	}
@@ -78,6 +90,18 @@ func (h *Handler) getDevicesOp() huma.Operation {
 	}
 }
 
+func (h *Handler) registerDeviceOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-register-device",
+		Method:      http.MethodPost,
+		Path:        "/api/sync/devices/register",
+		Summary:     "Зарегистрировать устройство",
+		Description: "Регистрирует устройство по постоянному UUID клиента. С claim_id забирает существующую запись устройства после переустановки и удаляет ее дубликаты",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
 func (h *Handler) removeDeviceOp() huma.Operation {
 	return huma.Operation{
 		OperationID: "sync-remove-device",
@@ -89,3 +113,99 @@ func (h *Handler) removeDeviceOp() huma.Operation {
 		Middlewares: h.middleware,
 	}
 }
+
+func (h *Handler) approveDeviceOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-approve-device",
+		Method:      http.MethodPost,
+		Path:        "/api/sync/devices/{id}/approve",
+		Summary:     "Подтвердить устройство",
+		Description: "Разрешает ожидающему устройству доступ к данным. Выполняется с подтвержденного устройства: X-Device-ID и подпись запроса его ключом (X-Device-Timestamp, X-Device-Signature); с fingerprint сервер сверяет отпечаток ключа подтверждаемого устройства",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) getCapabilitiesOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-get-capabilities",
+		Method:      http.MethodGet,
+		Path:        "/api/sync/capabilities",
+		Summary:     "Получить параметры синхронизации",
+		Description: "Возвращает эффективные параметры сервиса: размер пакета, лимит хранилища, время жизни конфликтов, протокол синхронизации пользователя",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) getChangesV2Op() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-v2-get-changes",
+		Method:      http.MethodPost,
+		Path:        "/api/v2/sync/changes",
+		Summary:     "Получить изменения (протокол v2)",
+		Description: "Лента изменений только по курсору: offset не поддерживается. Доступна пользователям, переведенным на v2, остальные получают 409.",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) negotiateV2Op() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-v2-negotiate",
+		Method:      http.MethodPost,
+		Path:        "/api/v2/sync/negotiate",
+		Summary:     "Сравнить индекс записей (протокол v2)",
+		Description: "Как /api/sync/negotiate. Доступна пользователям, переведенным на v2, остальные получают 409.",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *Handler) batchSyncV2Op() huma.Operation {
+	return huma.Operation{
+		OperationID: "sync-v2-batch",
+		Method:      http.MethodPost,
+		Path:        "/api/v2/sync/batch",
+		Summary:     "Пакетная синхронизация записей (протокол v2)",
+		Description: "Единственный способ загрузки записей в протоколе v2. Доступна пользователям, переведенным на v2, остальные получают 409.",
+		Tags:        []string{"sync"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) getProtocolOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-sync-protocol-get",
+		Method:      http.MethodGet,
+		Path:        "/api/admin/users/{id}/sync-protocol",
+		Summary:     "Протокол синхронизации пользователя",
+		Description: "override = true, если протокол назначен администратором, а не выбран по SYNC_V2_ROLLOUT_PERCENT. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) setProtocolOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-sync-protocol-set",
+		Method:      http.MethodPut,
+		Path:        "/api/admin/users/{id}/sync-protocol",
+		Summary:     "Назначить протокол синхронизации",
+		Description: "Переводит пользователя на протокол независимо от процента раскатки. Клиент переключится при следующей синхронизации. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
+
+func (h *AdminHandler) resetProtocolOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "admin-sync-protocol-reset",
+		Method:      http.MethodDelete,
+		Path:        "/api/admin/users/{id}/sync-protocol",
+		Summary:     "Сбросить протокол синхронизации",
+		Description: "Удаляет назначение: протокол снова выбирается по SYNC_V2_ROLLOUT_PERCENT. Требует заголовок X-Admin-Token.",
+		Tags:        []string{"admin"},
+		Middlewares: h.middleware,
+	}
+}
@@ -24,8 +24,20 @@ type loginOutput struct {
 	Body LoginResponse
 }
 
+// LoginResponse - ответ на вход. Если у пользователя включен второй фактор,
+// Status = "MFARequired", а вместо токена выдается Challenge для /user/login/2fa.
 type LoginResponse struct {
-	Token  string `json:"token"`
-	Status string `json:"status"`
-	Error  string `json:"error"`
+	Token     string `json:"token"`
+	Challenge string `json:"challenge,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+}
+
+type loginMFAInput struct {
+	Body LoginMFARequest
+}
+
+type LoginMFARequest struct {
+	Challenge string `json:"challenge" minLength:"1"`
+	Code      string `json:"code" minLength:"1" doc:"Код из приложения или код восстановления"`
 }
@@ -2,7 +2,13 @@ package user
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"net/http"
+
+	"gophkeeper/internal/app/server/api/http/httperr"
+	"gophkeeper/internal/app/server/api/http/problem"
+	"gophkeeper/internal/domain/apperr"
+	"gophkeeper/internal/domain/mfa"
 	"gophkeeper/internal/domain/session"
 	"gophkeeper/internal/domain/user"
 
@@ -13,14 +19,17 @@ import (
 type Handler struct {
 	service    user.Servicer
 	session    session.Servicer
+	mfa        mfa.Servicer
 	log        *slog.Logger
 	middleware huma.Middlewares
 }
 
-func NewHandler(service user.Servicer, session session.Servicer, log *slog.Logger, middleware huma.Middlewares) *Handler {
+func NewHandler(service user.Servicer, session session.Servicer, mfa mfa.Servicer,
+	log *slog.Logger, middleware huma.Middlewares) *Handler {
 	return &Handler{
 		service:    service,
 		session:    session,
+		mfa:        mfa,
 		log:        log,
 		middleware: middleware,
 	}
@@ -29,14 +38,17 @@ func NewHandler(service user.Servicer, session session.Servicer, log *slog.Logge
 func (h *Handler) SetupRoutes(api huma.API) {
 	huma.Register(api, h.registerOp(), h.register)
 	huma.Register(api, h.loginOp(), h.login)
+	huma.Register(api, h.loginMFAOp(), h.loginMFA)
 }
 
 func (h *Handler) register(ctx context.Context, input *registerInput) (*registerOutput, error) {
 	userID, err := h.service.Register(ctx, input.Body.Login, input.Body.Password)
+	if httperr.Known(err) {
+		return nil, httperr.Map(err)
+	}
 	if err != nil {
-		return &registerOutput{
-			Body: RegisterResponse{Status: "Error", Error: err.Error()},
-		}, nil
+		h.log.Error("register user", "error", err)
+		return nil, huma.Error500InternalServerError("failed to register user")
 	}
 
 	return &registerOutput{
@@ -46,30 +58,60 @@ func (h *Handler) register(ctx context.Context, input *registerInput) (*register
 
 func (h *Handler) login(ctx context.Context, input *loginInput) (*loginOutput, error) {
 	u, err := h.service.Authenticate(ctx, input.Body.Login, input.Body.Password)
+	if errors.Is(err, user.ErrDisabled) {
+		return nil, httperr.Map(err)
+	}
+	// Неизвестный логин и неверный пароль неразличимы для клиента
+	if err != nil {
+		return nil, problem.New(http.StatusUnauthorized, apperr.CodeOf(user.ErrInvalidAuth), "Invalid credentials")
+	}
+
+	required, err := h.mfa.Required(ctx, u.ID)
 	if err != nil {
+		h.log.Error("check two-factor authentication", "error", err, "user_id", u.ID)
+		return nil, huma.Error500InternalServerError("failed to check two-factor authentication")
+	}
+	if required {
+		challenge, err := h.mfa.StartLogin(ctx, u.ID)
+		if err != nil {
+			h.log.Error("start two-factor login", "error", err, "user_id", u.ID)
+			return nil, huma.Error500InternalServerError("failed to start two-factor login")
+		}
 		return &loginOutput{
-			Body: LoginResponse{
-				Status: "Error",
-				Error:  "Invalid credentials",
-			},
+			Body: LoginResponse{Challenge: challenge, Status: "MFARequired"},
 		}, nil
 	}
 
 	token, err := h.session.Create(ctx, u.ID)
 	if err != nil {
-		err = fmt.Errorf("create session: %w", err)
+		h.log.Error("create session", "error", err, "user_id", u.ID)
+		return nil, huma.Error500InternalServerError("failed to create session")
+	}
+
+	return &loginOutput{
+		Body: LoginResponse{Token: token, Status: "Ok"},
+	}, nil
+}
+
+func (h *Handler) loginMFA(ctx context.Context, input *loginMFAInput) (*loginOutput, error) {
+	userID, err := h.mfa.CompleteLogin(ctx, input.Body.Challenge, input.Body.Code)
+	switch {
+	case errors.Is(err, mfa.ErrInvalidCode), errors.Is(err, mfa.ErrTooManyAttempts):
+		return nil, problem.New(http.StatusUnauthorized, apperr.CodeOf(err), err.Error())
+	case errors.Is(err, mfa.ErrChallengeNotFound), errors.Is(err, mfa.ErrNotEnabled):
+		return nil, problem.New(http.StatusUnauthorized, apperr.CodeOf(mfa.ErrChallengeNotFound), mfa.ErrChallengeNotFound.Error())
+	case err != nil:
+		h.log.Error("complete two-factor login", "error", err)
+		return nil, huma.Error500InternalServerError("failed to complete two-factor login")
 	}
 
-	errMsg := ""
+	token, err := h.session.CreateMFA(ctx, userID)
 	if err != nil {
-		errMsg = err.Error()
+		h.log.Error("create session", "error", err, "user_id", userID)
+		return nil, huma.Error500InternalServerError("failed to create session")
 	}
 
 	return &loginOutput{
-		Body: LoginResponse{
-			Token:  token,
-			Status: "Ok",
-			Error:  errMsg,
-		},
+		Body: LoginResponse{Token: token, Status: "Ok"},
 	}, nil
 }
@@ -27,3 +27,15 @@ func (h *Handler) loginOp() huma.Operation {
 		Middlewares: h.middleware,
 	}
 }
+
+func (h *Handler) loginMFAOp() huma.Operation {
+	return huma.Operation{
+		OperationID: "user-login-2fa",
+		Method:      http.MethodPost,
+		Path:        "/user/login/2fa",
+		Summary:     "Второй шаг авторизации",
+		Description: "Проверяет код двухфакторной аутентификации или код восстановления для незавершенного входа и выдает токен.",
+		Tags:        []string{"users"},
+		Middlewares: h.middleware,
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// schemaNamer называет схемы OpenAPI как huma.DefaultSchemaNamer, но типы с
+// одинаковыми именами из разных пакетов (sync.Status, maintenance.Status,
+// mfa.Status) не конфликтуют: имя получает тип, зарегистрированный первым,
+// остальные - с префиксом пакета (SyncStatus). Без этого huma
+// паникует при регистрации операций.
+func schemaNamer() func(reflect.Type, string) string {
+	var (
+		mu    sync.Mutex
+		names = make(map[reflect.Type]string)
+		owner = make(map[string]reflect.Type)
+	)
+
+	return func(t reflect.Type, hint string) string {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		name := huma.DefaultSchemaNamer(t, hint)
+		if t.Name() == "" || t.PkgPath() == "" {
+			return name
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if known, ok := names[t]; ok {
+			return known
+		}
+		if other, taken := owner[name]; taken && other != t {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		owner[name] = t
+		names[t] = name
+		return name
+	}
+}
@@ -0,0 +1,297 @@
+// Package server собирает сервер GophKeeper: хранилище, миграции,
+// фоновые задачи и HTTP API, и управляет их запуском и остановкой.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	gosync "sync"
+
+	"gophkeeper/internal/app/server/account"
+	"gophkeeper/internal/app/server/api"
+	"gophkeeper/internal/app/server/backup"
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/app/server/maintenance"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/infrastructure/migration"
+	"gophkeeper/internal/infrastructure/objectstore"
+	"gophkeeper/internal/infrastructure/storage"
+	"gophkeeper/internal/infrastructure/storage/postgres"
+	"gophkeeper/internal/infrastructure/storage/sqlite"
+	"gophkeeper/internal/utils/logger/sl"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// App - собранный сервер
+type App struct {
+	cfg   *config.Config
+	log   *slog.Logger
+	repos *storage.Repositories
+
+	server   *http.Server
+	backups  *backup.Service
+	accounts *account.Service
+	trash    *record.TrashPurger
+	expiry   *record.ExpiryScanner
+	blobs    *blob.Pruner
+	sessions *session.Cleaner
+}
+
+// New подключается к базе, применяет миграции и собирает HTTP API.
+// Ресурсы освобождаются в Close.
+func New(ctx context.Context, cfg *config.Config, log *slog.Logger) (*App, error) {
+	repos, err := OpenStorage(ctx, cfg, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	app, err := newApp(cfg, log, repos)
+	if err != nil {
+		repos.Close()
+		return nil, err
+	}
+	return app, nil
+}
+
+// OpenStorage подключается к базе драйвера из конфигурации. Миграции не
+// применяются: сервер и команды cmd/server запускают их сами.
+func OpenStorage(ctx context.Context, cfg *config.Config, log *slog.Logger) (*storage.Repositories, error) {
+	switch cfg.DB.Driver {
+	case config.DriverSQLite:
+		db, err := sqlite.Open(cfg.DB.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("using sqlite storage", slog.String("path", cfg.DB.SQLitePath))
+		return sqlite.NewRepositories(db, log), nil
+	default:
+		pool, err := pgxpool.New(ctx, cfg.DB.DatabaseURI)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewRepositories(pool, log), nil
+	}
+}
+
+func newApp(cfg *config.Config, log *slog.Logger, repos *storage.Repositories) (*App, error) {
+	if err := migration.NewMigration(cfg, migration.DefaultEngine).Up(); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	var backupStore backup.ObjectStore
+	if cfg.Backup.Enabled {
+		s3Store, err := objectstore.NewS3Store(cfg.Backup.S3, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init backup storage: %w", err)
+		}
+		backupStore = s3Store
+	}
+	backups := backup.NewService(repos.Backups, backupStore, cfg.Backup, log)
+	trash := record.NewTrashPurger(repos.Records, cfg.Trash, log)
+	accounts := account.NewService(account.Repositories{
+		Users:    repos.Users,
+		Records:  repos.Backups,
+		History:  repos.Records,
+		Blobs:    repos.Blobs,
+		Folders:  repos.Folders,
+		Settings: repos.Settings,
+		KeyFiles: repos.KeyFiles,
+		Sync:     repos.Sync,
+	}, cfg.Account, log)
+	expiry := record.NewExpiryScanner(repos.Records, cfg.Expiry, log)
+	blobs := blob.NewPruner(repos.Blobs, log)
+	sessions := session.NewCleaner(repos.Sessions, log)
+
+	mode := maintenance.New(cfg.Maintenance)
+	if mode.Enabled() {
+		log.Warn("starting in maintenance mode: write requests are rejected")
+	}
+
+	scanner := blob.NewScanner(cfg.Attachments)
+	if scanner != nil {
+		log.Info("attachment policy enabled", slog.Int64("max_size", cfg.Attachments.MaxSize),
+			slog.Bool("scan_command", cfg.Attachments.Command != ""))
+	}
+
+	router := api.New(repos, log, cfg.Sync, backups, accounts, cfg.Backup.AdminToken, mode, &api.HTTPConfig{
+		MinClientVersion: cfg.Server.MinClientVersion,
+		MaxBodySize:      cfg.Server.MaxBodySize,
+		CORS:             cfg.Server.CORS,
+	}, scanner)
+	if !cfg.Server.MinClientVersion.IsZero() {
+		log.Info("outdated clients are rejected", slog.String("min_client_version", cfg.Server.MinClientVersion.String()))
+	}
+	if cfg.Server.CORS != nil && len(cfg.Server.CORS.AllowedOrigins) > 0 {
+		log.Info("CORS enabled", slog.Any("origins", cfg.Server.CORS.AllowedOrigins))
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.RunPort),
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(log.Handler(), slog.LevelWarn),
+	}
+	if cfg.Server.TLSEnabled() {
+		tlsConfig, err := serverTLSConfig(cfg.Server.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		server.TLSConfig = tlsConfig
+		if cfg.Server.TLSClientCAFile != "" {
+			log.Info("client certificates are required", slog.String("client_ca", cfg.Server.TLSClientCAFile))
+		}
+	}
+
+	return &App{
+		cfg:      cfg,
+		log:      log,
+		repos:    repos,
+		server:   server,
+		backups:  backups,
+		accounts: accounts,
+		trash:    trash,
+		expiry:   expiry,
+		blobs:    blobs,
+		sessions: sessions,
+	}, nil
+}
+
+// serverTLSConfig возвращает настройки TLS сервера. Если задан clientCAFile,
+// клиент обязан предъявить сертификат, выданный этим УЦ.
+func serverTLSConfig(clientCAFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client CA %s contains no PEM certificates", clientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConfig, nil
+}
+
+// Run запускает фоновые задачи и HTTP-сервер и работает до отмены ctx.
+// После отмены сервер перестает принимать соединения и ждет завершения
+// активных запросов не дольше ShutdownTimeout; фоновые задачи останавливаются
+// после того, как запросы обработаны.
+func (a *App) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.server.Addr, err)
+	}
+	return a.Serve(ctx, listener)
+}
+
+// Serve работает как Run на уже открытом listener
+func (a *App) Serve(ctx context.Context, listener net.Listener) error {
+	// Контекст запросов не зависит от ctx: при остановке активные запросы
+	// дорабатывают, а отменяются только по истечении ShutdownTimeout
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	a.server.BaseContext = func(net.Listener) context.Context { return requestsCtx }
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs gosync.WaitGroup
+	jobs.Add(6)
+	go func() {
+		defer jobs.Done()
+		a.backups.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		a.accounts.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		a.trash.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		a.expiry.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		a.blobs.Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		a.sessions.Run(jobsCtx)
+	}()
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- a.serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	a.log.Info("shutting down server gracefully", slog.Duration("timeout", a.cfg.Server.ShutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := a.server.Shutdown(shutdownCtx); err != nil {
+		a.log.Error("server forced shutdown", sl.Err(err))
+		cancelRequests()
+		_ = a.server.Close()
+	}
+	if err := <-serveErr; err != nil {
+		a.log.Error("server failed", sl.Err(err))
+	}
+
+	a.log.Info("server stopped")
+	return nil
+}
+
+// serve обслуживает соединения до Shutdown; http.ErrServerClosed - штатная остановка
+func (a *App) serve(listener net.Listener) error {
+	a.log.Info("server starting",
+		slog.String("addr", listener.Addr().String()),
+		slog.Bool("tls", a.cfg.Server.TLSEnabled()),
+	)
+
+	var err error
+	if a.cfg.Server.TLSEnabled() {
+		err = a.server.ServeTLS(listener, a.cfg.Server.TLSCertFile, a.cfg.Server.TLSKeyFile)
+	} else {
+		err = a.server.Serve(listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close закрывает соединения с базой
+func (a *App) Close() {
+	a.repos.Close()
+}
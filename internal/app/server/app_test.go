@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/account"
+	"gophkeeper/internal/app/server/backup"
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// newTestApp собирает App без базы: фоновые задачи выключены конфигурацией
+func newTestApp(handler http.Handler, shutdownTimeout time.Duration) *App {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{}
+	cfg.Server.ShutdownTimeout = shutdownTimeout
+
+	return &App{
+		cfg:      cfg,
+		log:      log,
+		server:   &http.Server{Handler: handler},
+		backups:  backup.NewService(nil, nil, &backup.Config{}, log),
+		accounts: account.NewService(account.Repositories{}, &account.Config{}, log),
+		trash:    record.NewTrashPurger(nil, &record.TrashConfig{}, log),
+		expiry:   record.NewExpiryScanner(nil, &record.ExpiryConfig{}, log),
+		blobs:    blob.NewPruner(nil, log),
+		sessions: session.NewCleaner(nil, log),
+	}
+}
+
+func serveTestApp(t *testing.T, app *App) (stop context.CancelFunc, addr string, done <-chan error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	t.Cleanup(stop)
+
+	errc := make(chan error, 1)
+	go func() { errc <- app.Serve(ctx, listener) }()
+	return stop, "http://" + listener.Addr().String(), errc
+}
+
+func TestApp_GracefulShutdownDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app := newTestApp(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		// Запрос не отменяется, пока сервер дожидается его завершения
+		assert.NoError(t, r.Context().Err())
+		_, _ = w.Write([]byte("done"))
+	}), 5*time.Second)
+
+	stop, addr, done := serveTestApp(t, app)
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(addr)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	stop()
+
+	// Новые соединения не принимаются, пока активный запрос дорабатывает
+	require.Eventually(t, func() bool {
+		conn, err := net.DialTimeout("tcp", addr[len("http://"):], 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	select {
+	case <-done:
+		t.Fatal("сервер остановился, не дождавшись активного запроса")
+	default:
+	}
+
+	close(release)
+	res := <-responses
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("сервер не остановился после завершения запроса")
+	}
+}
+
+func TestApp_ShutdownTimeoutCancelsRequests(t *testing.T) {
+	started := make(chan struct{})
+	app := newTestApp(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		close(started)
+		// Зависший запрос прерывается по истечении ShutdownTimeout
+		<-r.Context().Done()
+	}), 100*time.Millisecond)
+
+	stop, addr, done := serveTestApp(t, app)
+
+	go func() {
+		resp, err := http.Get(addr)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	stop()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("сервер не остановился по таймауту")
+	}
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	plain, err := serverTLSConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, plain.ClientAuth)
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "client-ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+
+	mtls, err := serverTLSConfig(caFile)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, mtls.ClientAuth)
+	assert.NotNil(t, mtls.ClientCAs)
+
+	badFile := filepath.Join(dir, "bad.pem")
+	require.NoError(t, os.WriteFile(badFile, []byte("not a certificate"), 0600))
+	_, err = serverTLSConfig(badFile)
+	assert.Error(t, err)
+
+	_, err = serverTLSConfig(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,15 @@
+package backup
+
+import (
+	"errors"
+
+	"gophkeeper/internal/infrastructure/objectstore"
+)
+
+var (
+	ErrInvalidConfig    = errors.New("invalid backup config")
+	ErrDisabled         = errors.New("backups are disabled")
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	ErrObjectNotFound   = objectstore.ErrNotFound
+	ErrInProgress       = errors.New("backup already in progress")
+)
@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/infrastructure/objectstore"
+)
+
+// snapshotIDLayout - ID снимка совпадает с временем создания (UTC), что дает сортировку по строке
+const snapshotIDLayout = "20060102T150405Z"
+
+// ObjectStore - хранилище снимков
+type ObjectStore = objectstore.Store
+
+// Config параметры резервного копирования
+type Config struct {
+	Enabled    bool
+	Interval   time.Duration // 0 - только ручной запуск через admin API
+	KeepLast   int           // сколько последних снимков хранить
+	AdminToken string        // токен для admin API; пустой - API отключено
+	S3         objectstore.S3Config
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию (резервное копирование выключено)
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:  false,
+		Interval: 24 * time.Hour,
+		KeepLast: 7,
+		S3: objectstore.S3Config{
+			Region: "us-east-1",
+			Prefix: "gophkeeper",
+			UseSSL: true,
+		},
+	}
+}
+
+// Validate проверяет корректность конфигурации
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	switch {
+	case c.Interval < 0:
+		return fmt.Errorf("%w: interval must not be negative", ErrInvalidConfig)
+	case c.KeepLast <= 0:
+		return fmt.Errorf("%w: keep last must be positive", ErrInvalidConfig)
+	case c.S3.Endpoint == "":
+		return fmt.Errorf("%w: s3 endpoint is required", ErrInvalidConfig)
+	case c.S3.Bucket == "":
+		return fmt.Errorf("%w: s3 bucket is required", ErrInvalidConfig)
+	case c.S3.AccessKey == "" || c.S3.SecretKey == "":
+		return fmt.Errorf("%w: s3 credentials are required", ErrInvalidConfig)
+	case c.S3.Region == "":
+		return fmt.Errorf("%w: s3 region is required", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Snapshot описание снимка (хранится в manifests/<id>.json)
+type Snapshot struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Users     int       `json:"users"`
+	Records   int       `json:"records"`
+}
+
+// UserDump записи одного пользователя в снимке.
+// Данные записей остаются зашифрованными на клиенте.
+type UserDump struct {
+	SnapshotID string          `json:"snapshot_id"`
+	UserID     int             `json:"user_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Records    []record.Record `json:"records"`
+}
+
+// RestoreResult итог восстановления записей пользователя
+type RestoreResult struct {
+	SnapshotID string `json:"snapshot_id"`
+	UserID     int    `json:"user_id"`
+	Records    int    `json:"records"`  // записей в снимке
+	Restored   int    `json:"restored"` // изменено или создано заново
+}
@@ -0,0 +1,16 @@
+package backup
+
+import (
+	"context"
+
+	"gophkeeper/internal/domain/record"
+)
+
+type Repository interface {
+	ListUserIDs(ctx context.Context) ([]int, error)
+	// ListAllRecords возвращает все записи пользователя, включая удаленные
+	ListAllRecords(ctx context.Context, userID int) ([]record.Record, error)
+	// RestoreRecords возвращает записи к состоянию из снимка и увеличивает их версию,
+	// чтобы клиенты получили изменения при синхронизации. Возвращает число измененных записей.
+	RestoreRecords(ctx context.Context, userID int, records []record.Record) (int, error)
+}
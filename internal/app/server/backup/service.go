@@ -0,0 +1,307 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса резервного копирования
+type Servicer interface {
+	Backup(ctx context.Context) (*Snapshot, error)
+	ListSnapshots(ctx context.Context) ([]Snapshot, error)
+	Restore(ctx context.Context, snapshotID string, userID int) (*RestoreResult, error)
+}
+
+// Service периодически сохраняет зашифрованные записи пользователей в объектное хранилище.
+//
+// Раскладка объектов:
+//
+//	<prefix>/snapshots/<id>/users/<user_id>.json - записи пользователя
+//	<prefix>/manifests/<id>.json                 - описание снимка, пишется последним
+//
+// Снимок без манифеста считается незавершенным и не виден при восстановлении.
+type Service struct {
+	repo   Repository
+	store  ObjectStore
+	config *Config
+	log    *slog.Logger
+	now    func() time.Time
+
+	mu      gosync.Mutex
+	running bool
+}
+
+// NewService создает сервис резервного копирования. При выключенном
+// резервном копировании store может быть nil - методы вернут ErrDisabled.
+func NewService(repo Repository, store ObjectStore, config *Config, log *slog.Logger) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &Service{
+		repo:   repo,
+		store:  store,
+		config: config,
+		log:    log.With("component", "backup"),
+		now:    time.Now,
+	}
+}
+
+// Run запускает резервное копирование по расписанию до отмены контекста
+func (s *Service) Run(ctx context.Context) {
+	if !s.enabled() || s.config.Interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	s.log.Info("scheduled backups started", "interval", s.config.Interval, "keep_last", s.config.KeepLast)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot, err := s.Backup(ctx)
+			if err != nil {
+				s.log.Error("scheduled backup failed", "error", err)
+				continue
+			}
+			s.log.Info("scheduled backup completed",
+				"snapshot", snapshot.ID, "users", snapshot.Users, "records", snapshot.Records)
+		}
+	}
+}
+
+// Backup создает снимок записей всех пользователей и применяет политику хранения
+func (s *Service) Backup(ctx context.Context) (*Snapshot, error) {
+	if !s.enabled() {
+		return nil, ErrDisabled
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrInProgress
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	createdAt := s.now().UTC()
+	snapshot := &Snapshot{
+		ID:        createdAt.Format(snapshotIDLayout),
+		CreatedAt: createdAt,
+	}
+
+	userIDs, err := s.repo.ListUserIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		records, err := s.repo.ListAllRecords(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("list records of user %d: %w", userID, err)
+		}
+
+		data, err := json.Marshal(UserDump{
+			SnapshotID: snapshot.ID,
+			UserID:     userID,
+			CreatedAt:  createdAt,
+			Records:    records,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal records of user %d: %w", userID, err)
+		}
+
+		if err := s.store.Put(ctx, s.userKey(snapshot.ID, userID), data); err != nil {
+			return nil, err
+		}
+
+		snapshot.Users++
+		snapshot.Records += len(records)
+	}
+
+	manifest, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := s.store.Put(ctx, s.manifestKey(snapshot.ID), manifest); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRetention(ctx); err != nil {
+		// Снимок уже сохранен, ошибка очистки не делает его недействительным
+		s.log.Warn("failed to apply retention policy", "error", err)
+	}
+
+	return snapshot, nil
+}
+
+// ListSnapshots возвращает завершенные снимки, от новых к старым
+func (s *Service) ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	if !s.enabled() {
+		return nil, ErrDisabled
+	}
+
+	ids, err := s.snapshotIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		data, err := s.store.Get(ctx, s.manifestKey(ids[i]))
+		if err != nil {
+			return nil, err
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("decode manifest %s: %w", ids[i], err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// Restore возвращает записи пользователя к состоянию из снимка
+func (s *Service) Restore(ctx context.Context, snapshotID string, userID int) (*RestoreResult, error) {
+	if !s.enabled() {
+		return nil, ErrDisabled
+	}
+
+	if _, err := time.Parse(snapshotIDLayout, snapshotID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, snapshotID)
+	}
+
+	if _, err := s.store.Get(ctx, s.manifestKey(snapshotID)); err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, snapshotID)
+		}
+		return nil, err
+	}
+
+	data, err := s.store.Get(ctx, s.userKey(snapshotID, userID))
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, fmt.Errorf("%w: no records of user %d in %s", ErrSnapshotNotFound, userID, snapshotID)
+		}
+		return nil, err
+	}
+
+	var dump UserDump
+	if err := json.Unmarshal(data, &dump); err != nil {
+		return nil, fmt.Errorf("decode user dump: %w", err)
+	}
+	if dump.UserID != userID {
+		return nil, fmt.Errorf("user dump belongs to user %d", dump.UserID)
+	}
+
+	restored, err := s.repo.RestoreRecords(ctx, userID, dump.Records)
+	if err != nil {
+		return nil, fmt.Errorf("restore records: %w", err)
+	}
+
+	s.log.Info("records restored from snapshot",
+		"snapshot", snapshotID, "user_id", userID, "restored", restored)
+
+	return &RestoreResult{
+		SnapshotID: snapshotID,
+		UserID:     userID,
+		Records:    len(dump.Records),
+		Restored:   restored,
+	}, nil
+}
+
+// applyRetention удаляет снимки сверх KeepLast. Манифест удаляется первым,
+// поэтому частично удаленный снимок не будет использован для восстановления.
+func (s *Service) applyRetention(ctx context.Context) error {
+	ids, err := s.snapshotIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(ids) <= s.config.KeepLast {
+		return nil
+	}
+
+	for _, id := range ids[:len(ids)-s.config.KeepLast] {
+		if err := s.store.Delete(ctx, s.manifestKey(id)); err != nil {
+			return err
+		}
+
+		keys, err := s.store.List(ctx, s.snapshotPrefix(id))
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := s.store.Delete(ctx, key); err != nil {
+				return err
+			}
+		}
+
+		s.log.Info("snapshot removed by retention policy", "snapshot", id)
+	}
+
+	return nil
+}
+
+// snapshotIDs возвращает ID завершенных снимков по возрастанию
+func (s *Service) snapshotIDs(ctx context.Context) ([]string, error) {
+	keys, err := s.store.List(ctx, s.key("manifests")+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id := strings.TrimSuffix(path.Base(key), ".json")
+		if _, err := time.Parse(snapshotIDLayout, id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func (s *Service) enabled() bool {
+	return s.config.Enabled && s.store != nil
+}
+
+func (s *Service) key(parts ...string) string {
+	if s.config.S3.Prefix != "" {
+		parts = append([]string{s.config.S3.Prefix}, parts...)
+	}
+	return strings.Join(parts, "/")
+}
+
+func (s *Service) manifestKey(id string) string {
+	return s.key("manifests", id+".json")
+}
+
+func (s *Service) snapshotPrefix(id string) string {
+	return s.key("snapshots", id) + "/"
+}
+
+func (s *Service) userKey(id string, userID int) string {
+	return s.key("snapshots", id, "users", fmt.Sprintf("%d.json", userID))
+}
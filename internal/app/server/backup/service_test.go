@@ -0,0 +1,237 @@
+package backup
+
+import (
+	"context"
+	"sort"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) ListUserIDs(ctx context.Context) ([]int, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockRepository) ListAllRecords(ctx context.Context, userID int) ([]record.Record, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]record.Record), args.Error(1)
+}
+
+func (m *MockRepository) RestoreRecords(ctx context.Context, userID int, records []record.Record) (int, error) {
+	args := m.Called(ctx, userID, records)
+	return args.Int(0), args.Error(1)
+}
+
+// memoryStore хранит объекты в памяти
+type memoryStore struct {
+	mu      gosync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (s *memoryStore) List(_ context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func enabledConfig(keepLast int) *Config {
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	cfg.KeepLast = keepLast
+	return cfg
+}
+
+func newTestService(repo Repository, store ObjectStore, cfg *Config, start time.Time) *Service {
+	service := NewService(repo, store, cfg, slog.Default())
+	current := start
+	service.now = func() time.Time {
+		t := current
+		current = current.Add(time.Hour)
+		return t
+	}
+	return service
+}
+
+func TestService_BackupAndRestore(t *testing.T) {
+	mockRepo := new(MockRepository)
+	store := newMemoryStore()
+	service := newTestService(mockRepo, store, enabledConfig(7), time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC))
+
+	records := []record.Record{
+		{ID: 1, UserID: 5, Type: record.RecTypeLogin, EncryptedData: "abcd", Version: 2},
+		{ID: 2, UserID: 5, Type: record.RecTypeText, EncryptedData: "ef01", Version: 1},
+	}
+	mockRepo.On("ListUserIDs", mock.Anything).Return([]int{5, 6}, nil)
+	mockRepo.On("ListAllRecords", mock.Anything, 5).Return(records, nil)
+	mockRepo.On("ListAllRecords", mock.Anything, 6).Return([]record.Record{}, nil)
+
+	snapshot, err := service.Backup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "20250101T030000Z", snapshot.ID)
+	assert.Equal(t, 2, snapshot.Users)
+	assert.Equal(t, 2, snapshot.Records)
+	assert.Contains(t, store.objects, "gophkeeper/manifests/20250101T030000Z.json")
+	assert.Contains(t, store.objects, "gophkeeper/snapshots/20250101T030000Z/users/5.json")
+
+	snapshots, err := service.ListSnapshots(context.Background())
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, snapshot.ID, snapshots[0].ID)
+
+	mockRepo.On("RestoreRecords", mock.Anything, 5, records).Return(1, nil)
+
+	result, err := service.Restore(context.Background(), snapshot.ID, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Records)
+	assert.Equal(t, 1, result.Restored)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Restore_NotFound(t *testing.T) {
+	mockRepo := new(MockRepository)
+	store := newMemoryStore()
+	service := newTestService(mockRepo, store, enabledConfig(7), time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC))
+
+	_, err := service.Restore(context.Background(), "20250101T030000Z", 5)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	_, err = service.Restore(context.Background(), "../other", 5)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	// Снимок без манифеста не завершен
+	_ = store.Put(context.Background(), "gophkeeper/snapshots/20250101T030000Z/users/5.json", []byte(`{}`))
+	_, err = service.Restore(context.Background(), "20250101T030000Z", 5)
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	mockRepo.AssertNotCalled(t, "RestoreRecords", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_Retention(t *testing.T) {
+	mockRepo := new(MockRepository)
+	store := newMemoryStore()
+	service := newTestService(mockRepo, store, enabledConfig(2), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	mockRepo.On("ListUserIDs", mock.Anything).Return([]int{1}, nil)
+	mockRepo.On("ListAllRecords", mock.Anything, 1).Return([]record.Record{{ID: 1, UserID: 1}}, nil)
+
+	for i := 0; i < 4; i++ {
+		_, err := service.Backup(context.Background())
+		require.NoError(t, err)
+	}
+
+	snapshots, err := service.ListSnapshots(context.Background())
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "20250101T030000Z", snapshots[0].ID)
+	assert.Equal(t, "20250101T020000Z", snapshots[1].ID)
+
+	keys, _ := store.List(context.Background(), "gophkeeper/snapshots/")
+	assert.Len(t, keys, 2)
+}
+
+func TestService_Disabled(t *testing.T) {
+	service := NewService(new(MockRepository), nil, DefaultConfig(), slog.Default())
+
+	_, err := service.Backup(context.Background())
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = service.ListSnapshots(context.Background())
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = service.Restore(context.Background(), "20250101T030000Z", 1)
+	assert.ErrorIs(t, err, ErrDisabled)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := func() *Config {
+		cfg := enabledConfig(3)
+		cfg.S3.Endpoint = "localhost:9000"
+		cfg.S3.Bucket = "backups"
+		cfg.S3.AccessKey = "key"
+		cfg.S3.SecretKey = "secret"
+		return cfg
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "disabled ignores fields", modify: func(c *Config) { c.Enabled = false; c.S3.Bucket = "" }},
+		{name: "manual only", modify: func(c *Config) { c.Interval = 0 }},
+		{name: "negative interval", modify: func(c *Config) { c.Interval = -time.Second }, wantErr: true},
+		{name: "keep last zero", modify: func(c *Config) { c.KeepLast = 0 }, wantErr: true},
+		{name: "no bucket", modify: func(c *Config) { c.S3.Bucket = "" }, wantErr: true},
+		{name: "no credentials", modify: func(c *Config) { c.S3.SecretKey = "" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidConfig)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
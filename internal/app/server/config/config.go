@@ -1,7 +1,20 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/server/account"
+	"gophkeeper/internal/app/server/api/http/middleware/cors"
+	"gophkeeper/internal/app/server/backup"
+	"gophkeeper/internal/app/server/maintenance"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/infrastructure/objectstore"
+	"gophkeeper/internal/utils/version"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -14,11 +27,29 @@ const (
 	EnvProd  = "prod"
 )
 
+// Драйверы хранилища сервера
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 type Config struct {
 	Env    string
 	DB     db
 	Server server
 	Logger logger
+	Sync   *sync.ServiceConfig
+	Backup *backup.Config
+	// Maintenance - режим обслуживания при запуске; переключается через admin API
+	Maintenance *maintenance.Config
+	// Trash - срок хранения удаленных записей
+	Trash *record.TrashConfig
+	// Expiry - поиск записей с истекающим сроком действия
+	Expiry *record.ExpiryConfig
+	// Attachments - политика и внешняя проверка загружаемых файлов
+	Attachments *blob.ScanConfig
+	// Account - срок отмены удаления учетных записей
+	Account *account.Config
 }
 
 type defaultConfig struct {
@@ -33,12 +64,40 @@ type defaultConfig struct {
 }
 
 type db struct {
+	// Driver - хранилище сервера: postgres (по умолчанию) или sqlite
+	Driver      string `env:"STORAGE_DRIVER"`
 	DatabaseURI string `env:"DATABASE_URI"`
-	Migrations  string `env:"MIGRATIONS_PATH"`
+	// SQLitePath - файл базы для драйвера sqlite
+	SQLitePath string `env:"SQLITE_PATH"`
+	Migrations string `env:"MIGRATIONS_PATH"`
 }
 
 type server struct {
 	RunPort int `env:"RUN_PORT"`
+	// TLSCertFile и TLSKeyFile - сертификат и ключ; если оба заданы, сервер
+	// принимает только HTTPS
+	TLSCertFile string `env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"TLS_KEY_FILE"`
+	// TLSClientCAFile - УЦ сертификатов клиентов (PEM); если задан, сервер
+	// принимает только клиентов с сертификатом этого УЦ (mTLS)
+	TLSClientCAFile string `env:"TLS_CLIENT_CA_FILE"`
+	// ReadHeaderTimeout ограничивает чтение заголовков запроса
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT"`
+	// IdleTimeout закрывает простаивающие keep-alive соединения
+	IdleTimeout time.Duration `env:"IDLE_TIMEOUT"`
+	// ShutdownTimeout - сколько ждать завершения активных запросов при остановке
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT"`
+	// MinClientVersion - клиенты старше этой версии получают 426; не задана - проверки нет
+	MinClientVersion version.Version `env:"MIN_CLIENT_VERSION"`
+	// MaxBodySize - предельный размер тела запроса в байтах; 0 - без ограничения
+	MaxBodySize int64 `env:"MAX_BODY_SIZE"`
+	// CORS - источники браузерных клиентов (CORS_ALLOWED_ORIGINS, CORS_MAX_AGE)
+	CORS *cors.Config
+}
+
+// TLSEnabled сообщает, настроен ли HTTPS
+func (s server) TLSEnabled() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
 }
 
 type logger struct {
@@ -47,10 +106,19 @@ type logger struct {
 
 func MustLoad() *Config {
 	if err := godotenv.Load(envPath); err != nil {
-		log.Fatalln("No .env file found, relying on environment variables")
+		log.Println("No .env file found, relying on environment variables")
 	}
 
 	viper.AutomaticEnv()
+	// Файл конфигурации (YAML или TOML по расширению) из CONFIG_FILE: ключи -
+	// имена переменных окружения в нижнем регистре. Переменные окружения
+	// важнее файла, значения по умолчанию - слабее.
+	if path := viper.GetString("config_file"); path != "" {
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			log.Fatalln("Не удалось прочитать файл конфигурации:", err)
+		}
+	}
 	d := defaultConfig{
 		RunPort:     viper.GetInt("run_port"),
 		DatabaseURI: viper.GetString("database_uri"),
@@ -63,15 +131,327 @@ func MustLoad() *Config {
 		log.Fatalln("Ключ шифрования должен быть указан в настройках конфигурации")
 	}
 
+	syncConfig, err := loadSyncConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация синхронизации:", err)
+	}
+
+	backupConfig, err := loadBackupConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация резервного копирования:", err)
+	}
+
+	maintenanceConfig, err := loadMaintenanceConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация режима обслуживания:", err)
+	}
+
+	trashConfig, err := loadTrashConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация корзины:", err)
+	}
+
+	expiryConfig, err := loadExpiryConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация сроков действия записей:", err)
+	}
+
+	attachmentsConfig, err := loadAttachmentsConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация проверки вложений:", err)
+	}
+
+	accountConfig, err := loadAccountConfig()
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация удаления учетных записей:", err)
+	}
+
+	serverConfig, err := loadServerConfig(d.RunPort)
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация HTTP-сервера:", err)
+	}
+
+	dbConfig, err := loadDBConfig(d)
+	if err != nil {
+		log.Fatalln("Некорректная конфигурация хранилища:", err)
+	}
+
 	config := Config{
-		Env: d.Env,
-		DB: db{
-			DatabaseURI: d.DatabaseURI,
-			Migrations:  d.Migrations,
-		},
-		Server: server{RunPort: d.RunPort},
+		Env:    d.Env,
+		DB:     dbConfig,
+		Server: serverConfig,
 		Logger: logger{LogLevel: d.LogLevel},
+		Sync:   syncConfig,
+		Backup: backupConfig,
+
+		Maintenance: maintenanceConfig,
+		Trash:       trashConfig,
+		Expiry:      expiryConfig,
+		Attachments: attachmentsConfig,
+		Account:     accountConfig,
 	}
 
 	return &config
 }
+
+// loadDBConfig выбирает драйвер хранилища и проверяет его параметры
+func loadDBConfig(d defaultConfig) (db, error) {
+	viper.SetDefault("storage_driver", DriverPostgres)
+	viper.SetDefault("sqlite_path", "gophkeeper.db")
+
+	cfg := db{
+		Driver:      viper.GetString("storage_driver"),
+		DatabaseURI: d.DatabaseURI,
+		SQLitePath:  viper.GetString("sqlite_path"),
+		Migrations:  d.Migrations,
+	}
+
+	switch cfg.Driver {
+	case DriverPostgres:
+		if cfg.DatabaseURI == "" {
+			return cfg, fmt.Errorf("для драйвера postgres нужен DATABASE_URI")
+		}
+	case DriverSQLite:
+		if cfg.SQLitePath == "" {
+			return cfg, fmt.Errorf("для драйвера sqlite нужен SQLITE_PATH")
+		}
+	default:
+		return cfg, fmt.Errorf("неизвестный STORAGE_DRIVER %q: поддерживаются %s и %s",
+			cfg.Driver, DriverPostgres, DriverSQLite)
+	}
+
+	return cfg, nil
+}
+
+// loadServerConfig читает параметры HTTP-сервера из окружения
+func loadServerConfig(runPort int) (server, error) {
+	viper.SetDefault("read_header_timeout", 10*time.Second)
+	viper.SetDefault("idle_timeout", 2*time.Minute)
+	viper.SetDefault("shutdown_timeout", 15*time.Second)
+	// Загрузка файла до 100 МиБ в base64 занимает около 134 МиБ
+	viper.SetDefault("max_body_size", 160<<20)
+	corsDefaults := cors.DefaultConfig()
+	viper.SetDefault("cors_max_age", corsDefaults.MaxAge)
+
+	cfg := server{
+		RunPort:           runPort,
+		TLSCertFile:       viper.GetString("tls_cert_file"),
+		TLSKeyFile:        viper.GetString("tls_key_file"),
+		TLSClientCAFile:   viper.GetString("tls_client_ca_file"),
+		ReadHeaderTimeout: viper.GetDuration("read_header_timeout"),
+		IdleTimeout:       viper.GetDuration("idle_timeout"),
+		ShutdownTimeout:   viper.GetDuration("shutdown_timeout"),
+		MaxBodySize:       viper.GetInt64("max_body_size"),
+		CORS: &cors.Config{
+			AllowedOrigins: splitList(viper.GetString("cors_allowed_origins")),
+			MaxAge:         viper.GetDuration("cors_max_age"),
+		},
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return cfg, fmt.Errorf("TLS_CERT_FILE и TLS_KEY_FILE задаются вместе")
+	}
+	if cfg.TLSClientCAFile != "" && !cfg.TLSEnabled() {
+		return cfg, fmt.Errorf("TLS_CLIENT_CA_FILE требует TLS_CERT_FILE и TLS_KEY_FILE")
+	}
+	if cfg.ReadHeaderTimeout <= 0 || cfg.IdleTimeout <= 0 || cfg.ShutdownTimeout <= 0 {
+		return cfg, fmt.Errorf("таймауты HTTP-сервера должны быть положительными")
+	}
+	if cfg.MaxBodySize < 0 {
+		return cfg, fmt.Errorf("MAX_BODY_SIZE не может быть отрицательным")
+	}
+	if err := cfg.CORS.Validate(); err != nil {
+		return cfg, err
+	}
+	if minVersion := viper.GetString("min_client_version"); minVersion != "" {
+		v, err := version.Parse(minVersion)
+		if err != nil {
+			return cfg, fmt.Errorf("MIN_CLIENT_VERSION: %w", err)
+		}
+		cfg.MinClientVersion = v
+	}
+
+	return cfg, nil
+}
+
+// loadSyncConfig читает параметры сервиса синхронизации из окружения.
+// Незаданные значения берутся из sync.DefaultServiceConfig.
+func loadSyncConfig() (*sync.ServiceConfig, error) {
+	defaults := sync.DefaultServiceConfig()
+	viper.SetDefault("sync_batch_size", defaults.BatchSize)
+	viper.SetDefault("sync_max_records", defaults.MaxSyncRecords)
+	viper.SetDefault("sync_conflict_ttl", defaults.ConflictTTL)
+	viper.SetDefault("sync_device_interval", defaults.DeviceSyncInterval)
+	viper.SetDefault("sync_storage_limit", defaults.StorageLimit)
+	viper.SetDefault("sync_v2_rollout_percent", defaults.V2RolloutPercent)
+	viper.SetDefault("sync_device_approval", defaults.DeviceApproval)
+
+	cfg := &sync.ServiceConfig{
+		BatchSize:          viper.GetInt("sync_batch_size"),
+		MaxSyncRecords:     viper.GetInt("sync_max_records"),
+		ConflictTTL:        viper.GetDuration("sync_conflict_ttl"),
+		DeviceSyncInterval: viper.GetDuration("sync_device_interval"),
+		StorageLimit:       viper.GetInt64("sync_storage_limit"),
+		V2RolloutPercent:   viper.GetInt("sync_v2_rollout_percent"),
+		DeviceApproval:     viper.GetBool("sync_device_approval"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadBackupConfig читает параметры резервного копирования в S3 из окружения.
+// Незаданные значения берутся из backup.DefaultConfig.
+func loadBackupConfig() (*backup.Config, error) {
+	defaults := backup.DefaultConfig()
+	viper.SetDefault("backup_enabled", defaults.Enabled)
+	viper.SetDefault("backup_interval", defaults.Interval)
+	viper.SetDefault("backup_keep_last", defaults.KeepLast)
+	viper.SetDefault("backup_s3_region", defaults.S3.Region)
+	viper.SetDefault("backup_s3_prefix", defaults.S3.Prefix)
+	viper.SetDefault("backup_s3_use_ssl", defaults.S3.UseSSL)
+
+	cfg := &backup.Config{
+		Enabled:    viper.GetBool("backup_enabled"),
+		Interval:   viper.GetDuration("backup_interval"),
+		KeepLast:   viper.GetInt("backup_keep_last"),
+		AdminToken: viper.GetString("admin_token"),
+		S3: objectstore.S3Config{
+			Endpoint:  viper.GetString("backup_s3_endpoint"),
+			Region:    viper.GetString("backup_s3_region"),
+			Bucket:    viper.GetString("backup_s3_bucket"),
+			AccessKey: viper.GetString("backup_s3_access_key"),
+			SecretKey: viper.GetString("backup_s3_secret_key"),
+			Prefix:    viper.GetString("backup_s3_prefix"),
+			UseSSL:    viper.GetBool("backup_s3_use_ssl"),
+		},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadMaintenanceConfig читает параметры режима обслуживания из окружения.
+// Незаданные значения берутся из maintenance.DefaultConfig.
+func loadMaintenanceConfig() (*maintenance.Config, error) {
+	defaults := maintenance.DefaultConfig()
+	viper.SetDefault("maintenance_mode", defaults.Enabled)
+	viper.SetDefault("maintenance_retry_after", defaults.RetryAfter)
+	viper.SetDefault("maintenance_message", defaults.Message)
+
+	cfg := &maintenance.Config{
+		Enabled:    viper.GetBool("maintenance_mode"),
+		RetryAfter: viper.GetDuration("maintenance_retry_after"),
+		Message:    viper.GetString("maintenance_message"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadExpiryConfig читает параметры поиска истекающих записей из окружения.
+// Незаданные значения берутся из record.DefaultExpiryConfig.
+func loadExpiryConfig() (*record.ExpiryConfig, error) {
+	defaults := record.DefaultExpiryConfig()
+	viper.SetDefault("expiry_window", defaults.Window)
+	viper.SetDefault("expiry_scan_interval", defaults.Interval)
+
+	cfg := &record.ExpiryConfig{
+		Window:   viper.GetDuration("expiry_window"),
+		Interval: viper.GetDuration("expiry_scan_interval"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadAttachmentsConfig читает политику вложений из окружения. Списки
+// расширений и типов задаются через запятую. Незаданные значения берутся
+// из blob.DefaultScanConfig.
+func loadAttachmentsConfig() (*blob.ScanConfig, error) {
+	defaults := blob.DefaultScanConfig()
+	viper.SetDefault("attachment_max_size", defaults.MaxSize)
+	viper.SetDefault("attachment_scan_timeout", defaults.CommandTimeout)
+
+	cfg := &blob.ScanConfig{
+		Policy: blob.Policy{
+			MaxSize:             viper.GetInt64("attachment_max_size"),
+			AllowedExtensions:   splitList(viper.GetString("attachment_allowed_extensions")),
+			BlockedExtensions:   splitList(viper.GetString("attachment_blocked_extensions")),
+			BlockedContentTypes: splitList(viper.GetString("attachment_blocked_content_types")),
+		},
+		Command:        viper.GetString("attachment_scan_command"),
+		CommandTimeout: viper.GetDuration("attachment_scan_timeout"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadAccountConfig читает параметры удаления учетных записей из окружения.
+// Незаданные значения берутся из account.DefaultConfig.
+func loadAccountConfig() (*account.Config, error) {
+	defaults := account.DefaultConfig()
+	viper.SetDefault("account_deletion_grace_period", defaults.GracePeriod)
+	viper.SetDefault("account_deletion_interval", defaults.Interval)
+
+	cfg := &account.Config{
+		GracePeriod: viper.GetDuration("account_deletion_grace_period"),
+		Interval:    viper.GetDuration("account_deletion_interval"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadTrashConfig читает срок хранения удаленных записей из окружения.
+// Незаданные значения берутся из record.DefaultTrashConfig.
+func loadTrashConfig() (*record.TrashConfig, error) {
+	defaults := record.DefaultTrashConfig()
+	viper.SetDefault("trash_retention", defaults.Retention)
+	viper.SetDefault("trash_purge_interval", defaults.Interval)
+	viper.SetDefault("trash_require_device_ack", defaults.RequireDeviceAck)
+	viper.SetDefault("trash_device_ack_timeout", defaults.DeviceAckTimeout)
+
+	cfg := &record.TrashConfig{
+		Retention:        viper.GetDuration("trash_retention"),
+		Interval:         viper.GetDuration("trash_purge_interval"),
+		RequireDeviceAck: viper.GetBool("trash_require_device_ack"),
+		DeviceAckTimeout: viper.GetDuration("trash_device_ack_timeout"),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
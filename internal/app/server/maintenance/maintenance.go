@@ -0,0 +1,125 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidConfig некорректные параметры режима обслуживания
+var ErrInvalidConfig = errors.New("invalid maintenance config")
+
+// Config параметры режима обслуживания при запуске сервера
+type Config struct {
+	Enabled    bool
+	RetryAfter time.Duration // через сколько клиентам повторить запрос
+	Message    string
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию (режим выключен)
+func DefaultConfig() *Config {
+	return &Config{
+		RetryAfter: 5 * time.Minute,
+		Message:    "server is under maintenance",
+	}
+}
+
+// Validate проверяет корректность конфигурации
+func (c *Config) Validate() error {
+	if c.RetryAfter <= 0 {
+		return fmt.Errorf("%w: retry after must be positive", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Status текущее состояние режима обслуживания
+type Status struct {
+	Enabled    bool      `json:"enabled"`
+	Message    string    `json:"message,omitempty"`
+	RetryAfter int       `json:"retry_after_seconds,omitempty" doc:"Через сколько секунд повторить запрос"`
+	Since      time.Time `json:"since,omitempty"`
+}
+
+// Mode - флаг режима обслуживания. Пока он включен, изменяющие запросы
+// получают 503 с Retry-After, чтение продолжает работать.
+// Переключается без перезапуска через admin API.
+type Mode struct {
+	mu         sync.RWMutex
+	enabled    bool
+	message    string
+	retryAfter time.Duration
+	since      time.Time
+	defaults   Config
+}
+
+// New создает флаг режима обслуживания в состоянии из конфигурации
+func New(cfg *Config) *Mode {
+	m := &Mode{defaults: *cfg}
+	if cfg.Enabled {
+		m.Enable(cfg.Message, cfg.RetryAfter)
+	}
+	return m
+}
+
+// Enable включает режим обслуживания. Пустое сообщение и нулевой
+// Retry-After заменяются значениями из конфигурации.
+func (m *Mode) Enable(message string, retryAfter time.Duration) {
+	if message == "" {
+		message = m.defaults.Message
+	}
+	if retryAfter <= 0 {
+		retryAfter = m.defaults.RetryAfter
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.enabled {
+		m.since = time.Now()
+	}
+	m.enabled = true
+	m.message = message
+	m.retryAfter = retryAfter
+}
+
+// Disable выключает режим обслуживания
+func (m *Mode) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.enabled = false
+	m.message = ""
+	m.since = time.Time{}
+}
+
+// Enabled проверяет, включен ли режим обслуживания
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// Status возвращает текущее состояние
+func (m *Mode) Status() Status {
+	if m == nil {
+		return Status{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.enabled {
+		return Status{}
+	}
+	return Status{
+		Enabled:    true,
+		Message:    m.message,
+		RetryAfter: int(m.retryAfter.Round(time.Second) / time.Second),
+		Since:      m.since,
+	}
+}
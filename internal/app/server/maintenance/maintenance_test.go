@@ -0,0 +1,56 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMode(t *testing.T) {
+	m := New(DefaultConfig())
+	assert.False(t, m.Enabled())
+	assert.Equal(t, Status{}, m.Status())
+
+	m.Enable("", 0)
+	status := m.Status()
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "server is under maintenance", status.Message)
+	assert.Equal(t, 300, status.RetryAfter)
+	assert.False(t, status.Since.IsZero())
+
+	since := status.Since
+	m.Enable("database upgrade", 90*time.Second)
+	status = m.Status()
+	assert.Equal(t, "database upgrade", status.Message)
+	assert.Equal(t, 90, status.RetryAfter)
+	assert.Equal(t, since, status.Since, "повторное включение не сбрасывает время начала")
+
+	m.Disable()
+	assert.False(t, m.Enabled())
+	assert.Equal(t, Status{}, m.Status())
+}
+
+func TestMode_EnabledFromConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	cfg.RetryAfter = time.Minute
+
+	m := New(cfg)
+	assert.True(t, m.Enabled())
+	assert.Equal(t, 60, m.Status().RetryAfter)
+}
+
+func TestMode_Nil(t *testing.T) {
+	var m *Mode
+	assert.False(t, m.Enabled())
+	assert.Equal(t, Status{}, m.Status())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultConfig().Validate())
+
+	cfg := DefaultConfig()
+	cfg.RetryAfter = 0
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+}
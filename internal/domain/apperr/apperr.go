@@ -0,0 +1,156 @@
+// Package apperr classifies domain errors by kind, so transport layers can
+// map them to status codes in one place instead of knowing every sentinel.
+package apperr
+
+import "errors"
+
+// Kind is a class of failure shared by all domains. A Kind is itself an
+// error, so callers can test for it with errors.Is(err, apperr.NotFound).
+type Kind string
+
+const (
+	// NotFound - the requested entity does not exist or is not visible to the caller
+	NotFound Kind = "not found"
+	// Gone - the entity existed but was deleted
+	Gone Kind = "gone"
+	// Conflict - the request conflicts with the current state, e.g. a stale version
+	Conflict Kind = "conflict"
+	// Unauthorized - the caller is not authenticated or the credentials are invalid
+	Unauthorized Kind = "unauthorized"
+	// Forbidden - the caller is authenticated but not allowed to do this
+	Forbidden Kind = "forbidden"
+	// Invalid - the request is malformed or fails validation
+	Invalid Kind = "invalid"
+	// QuotaExceeded - the request would exceed a storage limit
+	QuotaExceeded Kind = "quota exceeded"
+)
+
+func (k Kind) Error() string {
+	return string(k)
+}
+
+// Code is a machine-readable error code sent to API clients, e.g.
+// RECORD_NOT_FOUND. Unlike the message it is part of the API contract.
+type Code string
+
+// Codes of errors that carry only a kind
+const (
+	CodeNotFound      Code = "NOT_FOUND"
+	CodeGone          Code = "GONE"
+	CodeConflict      Code = "CONFLICT"
+	CodeUnauthorized  Code = "UNAUTHORIZED"
+	CodeForbidden     Code = "FORBIDDEN"
+	CodeInvalid       Code = "INVALID_REQUEST"
+	CodeQuotaExceeded Code = "QUOTA_EXCEEDED"
+	CodeInternal      Code = "INTERNAL"
+)
+
+// Codes of transport-level failures that have no domain error
+const (
+	// CodeUnavailable - the server cannot handle the request right now, retry later
+	CodeUnavailable Code = "UNAVAILABLE"
+	// CodeMaintenance - writes are rejected while maintenance mode is on
+	CodeMaintenance Code = "MAINTENANCE"
+	// CodeUpgradeRequired - the client version is no longer supported
+	CodeUpgradeRequired Code = "UPGRADE_REQUIRED"
+)
+
+// Code returns the generic code of the kind
+func (k Kind) Code() Code {
+	switch k {
+	case NotFound:
+		return CodeNotFound
+	case Gone:
+		return CodeGone
+	case Conflict:
+		return CodeConflict
+	case Unauthorized:
+		return CodeUnauthorized
+	case Forbidden:
+		return CodeForbidden
+	case Invalid:
+		return CodeInvalid
+	case QuotaExceeded:
+		return CodeQuotaExceeded
+	}
+	return CodeInternal
+}
+
+// Error is a sentinel error of a given kind. Domains declare their sentinels
+// with New and keep comparing them with errors.Is as before.
+type Error struct {
+	kind Kind
+	code Code
+	msg  string
+}
+
+// New returns a sentinel error with the given kind and message
+func New(kind Kind, msg string) *Error {
+	return &Error{kind: kind, msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// WithCode returns a copy of e with a specific API code. It is meant for
+// sentinel declarations:
+//
+//	ErrNotFound = apperr.New(apperr.NotFound, "record not found").WithCode("RECORD_NOT_FOUND")
+func (e *Error) WithCode(code Code) *Error {
+	return &Error{kind: e.kind, code: code, msg: e.msg}
+}
+
+// Kind returns the class of the error
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Code returns the API code of the error, or the generic code of its kind
+func (e *Error) Code() Code {
+	if e.code != "" {
+		return e.code
+	}
+	return e.kind.Code()
+}
+
+// Is reports whether target is the kind of e
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k == e.kind
+}
+
+// kinded is implemented by errors that carry a kind, including typed errors
+// with extra fields such as quota.ExceededError
+type kinded interface {
+	Kind() Kind
+}
+
+// coded is implemented by errors that carry an API code
+type coded interface {
+	Code() Code
+}
+
+// CodeOf returns the API code of the first error in err's chain that has one,
+// the generic code of its kind, or CodeInternal for unclassified errors.
+func CodeOf(err error) Code {
+	var c coded
+	if errors.As(err, &c) {
+		return c.Code()
+	}
+	return KindOf(err).Code()
+}
+
+// KindOf returns the kind of the first error in err's chain that has one,
+// or an empty Kind for unclassified errors.
+func KindOf(err error) Kind {
+	var k kinded
+	if errors.As(err, &k) {
+		return k.Kind()
+	}
+	var kind Kind
+	if errors.As(err, &kind) {
+		return kind
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type quotaError struct{}
+
+func (quotaError) Error() string { return "quota" }
+func (quotaError) Kind() Kind    { return QuotaExceeded }
+
+func TestError_IsKind(t *testing.T) {
+	errNotFound := New(NotFound, "record not found")
+	wrapped := fmt.Errorf("find record: %w", errNotFound)
+
+	assert.ErrorIs(t, wrapped, errNotFound)
+	assert.ErrorIs(t, wrapped, NotFound)
+	assert.NotErrorIs(t, wrapped, Conflict)
+	assert.Equal(t, "find record: record not found", wrapped.Error())
+
+	// Разные сентинелы одного вида не равны друг другу
+	assert.NotErrorIs(t, wrapped, New(NotFound, "record not found"))
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"sentinel", New(Conflict, "version conflict"), Conflict},
+		{"wrapped", fmt.Errorf("update: %w", New(Forbidden, "denied")), Forbidden},
+		{"typed", fmt.Errorf("create: %w", quotaError{}), QuotaExceeded},
+		{"bare kind", fmt.Errorf("%w: bad uuid", Invalid), Invalid},
+		{"unclassified", errors.New("connection reset"), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, KindOf(tt.err))
+		})
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	errNotFound := New(NotFound, "record not found")
+	errCoded := errNotFound.WithCode("RECORD_NOT_FOUND")
+
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"sentinel code", fmt.Errorf("find: %w", errCoded), "RECORD_NOT_FOUND"},
+		{"kind code", errNotFound, CodeNotFound},
+		{"typed", quotaError{}, CodeQuotaExceeded},
+		{"bare kind", fmt.Errorf("%w: bad uuid", Invalid), CodeInvalid},
+		{"unclassified", errors.New("connection reset"), CodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CodeOf(tt.err))
+		})
+	}
+
+	// WithCode возвращает отдельный сентинел того же вида
+	assert.ErrorIs(t, errCoded, NotFound)
+	assert.NotErrorIs(t, errCoded, errNotFound)
+	assert.Equal(t, errNotFound.Error(), errCoded.Error())
+}
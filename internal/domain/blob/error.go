@@ -0,0 +1,11 @@
+package blob
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound         = apperr.New(apperr.NotFound, "blob not found").WithCode("BLOB_NOT_FOUND")
+	ErrInvalidChecksum  = apperr.New(apperr.Invalid, "checksum must be a lowercase hex SHA-256")
+	ErrChecksumMismatch = apperr.New(apperr.Invalid, "checksum does not match blob data").WithCode("CHECKSUM_MISMATCH")
+	ErrEmptyBlob        = apperr.New(apperr.Invalid, "blob data is empty")
+	ErrTooLarge         = apperr.New(apperr.Invalid, "blob too large").WithCode("BLOB_TOO_LARGE")
+)
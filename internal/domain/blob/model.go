@@ -0,0 +1,29 @@
+package blob
+
+import (
+	"regexp"
+	"time"
+)
+
+// MetaKey - ключ ссылки на блоб в открытых метаданных бинарной записи
+const MetaKey = "blob"
+
+// Blob - зашифрованное содержимое бинарной записи. Одинаковые файлы клиент
+// шифрует в одинаковый блоб, поэтому записи пользователя с тем же содержимым
+// ссылаются на один блоб, и файл хранится и загружается один раз.
+type Blob struct {
+	UserID   int    `json:"-"`
+	Checksum string `json:"checksum"` // SHA-256 зашифрованных данных в hex
+	Size     int64  `json:"size"`
+	// RefCount - сколько записей пользователя (включая корзину) ссылаются на блоб
+	RefCount  int       `json:"ref_count"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      []byte    `json:"-"`
+}
+
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidChecksum проверяет формат контрольной суммы: SHA-256 в hex нижнего регистра
+func ValidChecksum(checksum string) bool {
+	return checksumPattern.MatchString(checksum)
+}
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"context"
+	"time"
+)
+
+// Repository интерфейс хранилища блобов. Счетчик ссылок ведет база: триггеры
+// на таблице records учитывают ключ "blob" в метаданных записей.
+type Repository interface {
+	// GetByChecksum возвращает блоб пользователя без данных или ErrNotFound
+	GetByChecksum(ctx context.Context, userID int, checksum string) (*Blob, error)
+
+	// GetData возвращает зашифрованные данные блоба или ErrNotFound
+	GetData(ctx context.Context, userID int, checksum string) ([]byte, error)
+
+	// Create сохраняет блоб. Если блоб с той же контрольной суммой уже есть,
+	// данные не перезаписываются и возвращается created == false.
+	Create(ctx context.Context, blob *Blob) (created bool, err error)
+
+	// PruneUnreferenced удаляет блобы без ссылок, счетчик которых не менялся с before
+	PruneUnreferenced(ctx context.Context, before time.Time) (int, error)
+}
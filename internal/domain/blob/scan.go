@@ -0,0 +1,227 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Проверка вложений. Содержимое блоба зашифровано на клиенте, поэтому сервер
+// не может проверить его антивирусом: Scanner видит только размер блоба и
+// имя и тип файла, которые сообщил клиент. Этого достаточно для политик
+// организации (запрет исполняемых файлов, предел размера), но не для защиты
+// от клиента, который сообщает ложные сведения. Проверка содержимого
+// выполняется на клиенте до шифрования хуком pre-upload.
+
+// ErrRejected - загрузка отклонена политикой вложений
+var ErrRejected = apperr.New(apperr.Forbidden, "upload rejected by attachment policy").WithCode("UPLOAD_REJECTED")
+
+const defaultScanTimeout = 30 * time.Second
+
+// FileInfo - сведения о файле, которые клиент сообщает при загрузке блоба
+type FileInfo struct {
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// Upload - загружаемый блоб, который проверяет Scanner
+type Upload struct {
+	UserID   int    `json:"user_id"`
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+	FileInfo
+}
+
+// Scanner проверяет загрузку до сохранения блоба. Ошибка с ErrRejected
+// отклоняет загрузку с 403, любая другая ошибка считается сбоем проверки.
+type Scanner interface {
+	Scan(ctx context.Context, upload Upload) error
+}
+
+// ScanFunc позволяет использовать функцию как Scanner
+type ScanFunc func(ctx context.Context, upload Upload) error
+
+func (f ScanFunc) Scan(ctx context.Context, upload Upload) error {
+	return f(ctx, upload)
+}
+
+// Scanners выполняет проверки по порядку до первой ошибки
+type Scanners []Scanner
+
+func (s Scanners) Scan(ctx context.Context, upload Upload) error {
+	for _, scanner := range s {
+		if err := scanner.Scan(ctx, upload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Policy - правила вложений по размеру, расширению и типу файла
+type Policy struct {
+	// MaxSize - наибольший размер блоба; 0 - только общий предел MaxSize
+	MaxSize int64
+	// AllowedExtensions - разрешенные расширения; пусто - любые
+	AllowedExtensions []string
+	// BlockedExtensions - запрещенные расширения
+	BlockedExtensions []string
+	// BlockedContentTypes - запрещенные типы; "application/" запрещает все
+	// типы с этим префиксом
+	BlockedContentTypes []string
+}
+
+func (p *Policy) Scan(_ context.Context, upload Upload) error {
+	if p.MaxSize > 0 && upload.Size > p.MaxSize {
+		return fmt.Errorf("%w: file is larger than %d bytes", ErrRejected, p.MaxSize)
+	}
+
+	ext := normalizeExt(filepath.Ext(upload.Filename))
+	if len(p.AllowedExtensions) > 0 && (ext == "" || !containsExt(p.AllowedExtensions, ext)) {
+		if upload.Filename == "" {
+			return fmt.Errorf("%w: filename is required", ErrRejected)
+		}
+		return fmt.Errorf("%w: extension %q is not allowed", ErrRejected, ext)
+	}
+	if ext != "" && containsExt(p.BlockedExtensions, ext) {
+		return fmt.Errorf("%w: extension %q is blocked", ErrRejected, ext)
+	}
+
+	contentType := strings.ToLower(strings.TrimSpace(upload.ContentType))
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+	for _, blocked := range p.BlockedContentTypes {
+		blocked = strings.ToLower(strings.TrimSpace(blocked))
+		if blocked == "" || contentType == "" {
+			continue
+		}
+		if contentType == blocked || (strings.HasSuffix(blocked, "/") && strings.HasPrefix(contentType, blocked)) {
+			return fmt.Errorf("%w: content type %q is blocked", ErrRejected, contentType)
+		}
+	}
+	return nil
+}
+
+func normalizeExt(ext string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
+
+func containsExt(list []string, ext string) bool {
+	for _, item := range list {
+		if normalizeExt(item) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandScanner передает сведения о загрузке внешней команде (обертке над
+// DLP или журналом аудита организации). Команда получает Upload в JSON на
+// stdin: код 0 разрешает загрузку, иной код отклоняет ее, и вывод команды
+// становится причиной отказа. Если команду не удалось выполнить, загрузка
+// не принимается.
+type CommandScanner struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (c *CommandScanner) Scan(ctx context.Context, upload Upload) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultScanTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(upload)
+	if err != nil {
+		return fmt.Errorf("marshal upload: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Command)
+	}
+	var output bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.Env = os.Environ()
+	// Дочерние процессы оболочки могут держать вывод открытым после таймаута
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("scan command timed out after %s", timeout)
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return fmt.Errorf("run scan command: %w", err)
+	}
+
+	reason := strings.TrimSpace(output.String())
+	if reason == "" {
+		return ErrRejected
+	}
+	return fmt.Errorf("%w: %s", ErrRejected, reason)
+}
+
+// ScanConfig - параметры проверки вложений
+type ScanConfig struct {
+	Policy
+	// Command - внешняя команда проверки; пусто - не вызывается
+	Command string
+	// CommandTimeout - сколько ждать команду проверки
+	CommandTimeout time.Duration
+}
+
+// DefaultScanConfig возвращает параметры по умолчанию: проверка выключена
+func DefaultScanConfig() *ScanConfig {
+	return &ScanConfig{CommandTimeout: defaultScanTimeout}
+}
+
+// Validate проверяет параметры
+func (c *ScanConfig) Validate() error {
+	if c.MaxSize < 0 || c.MaxSize > MaxSize {
+		return fmt.Errorf("attachment size limit must be between 0 and %d", MaxSize)
+	}
+	if c.CommandTimeout <= 0 {
+		return fmt.Errorf("scan command timeout must be positive")
+	}
+	return nil
+}
+
+// NewScanner собирает проверки из конфигурации. Если ничего не настроено,
+// возвращает nil - загрузки не проверяются.
+func NewScanner(config *ScanConfig) Scanner {
+	if config == nil {
+		return nil
+	}
+
+	var scanners Scanners
+	policy := config.Policy
+	if policy.MaxSize > 0 || len(policy.AllowedExtensions) > 0 ||
+		len(policy.BlockedExtensions) > 0 || len(policy.BlockedContentTypes) > 0 {
+		scanners = append(scanners, &policy)
+	}
+	if config.Command != "" {
+		scanners = append(scanners, &CommandScanner{Command: config.Command, Timeout: config.CommandTimeout})
+	}
+	if len(scanners) == 0 {
+		return nil
+	}
+	return scanners
+}
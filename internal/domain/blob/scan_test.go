@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Scan(t *testing.T) {
+	ctx := context.Background()
+	policy := &Policy{
+		MaxSize:             1024,
+		BlockedExtensions:   []string{".exe", "BAT"},
+		BlockedContentTypes: []string{"application/x-msdownload", "video/"},
+	}
+
+	tests := []struct {
+		name   string
+		upload Upload
+		ok     bool
+	}{
+		{"allowed", Upload{Size: 100, FileInfo: FileInfo{Filename: "passport.pdf", ContentType: "application/pdf"}}, true},
+		{"no file info", Upload{Size: 100}, true},
+		{"too large", Upload{Size: 2048, FileInfo: FileInfo{Filename: "passport.pdf"}}, false},
+		{"blocked extension", Upload{Size: 100, FileInfo: FileInfo{Filename: "Setup.EXE"}}, false},
+		{"blocked extension without dot", Upload{Size: 100, FileInfo: FileInfo{Filename: "run.bat"}}, false},
+		{"blocked type", Upload{Size: 100, FileInfo: FileInfo{ContentType: "application/x-msdownload; charset=binary"}}, false},
+		{"blocked type prefix", Upload{Size: 100, FileInfo: FileInfo{ContentType: "video/mp4"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Scan(ctx, tt.upload)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrRejected)
+			}
+		})
+	}
+
+	allowList := &Policy{AllowedExtensions: []string{"pdf", ".png"}}
+	assert.NoError(t, allowList.Scan(ctx, Upload{FileInfo: FileInfo{Filename: "scan.PNG"}}))
+	assert.ErrorIs(t, allowList.Scan(ctx, Upload{FileInfo: FileInfo{Filename: "notes.txt"}}), ErrRejected)
+	assert.ErrorIs(t, allowList.Scan(ctx, Upload{}), ErrRejected)
+}
+
+func TestCommandScanner_Scan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("scan command test uses sh")
+	}
+	ctx := context.Background()
+	upload := Upload{UserID: 7, Checksum: "abc", Size: 10, FileInfo: FileInfo{Filename: "report.pdf"}}
+
+	accept := &CommandScanner{Command: `grep -q '"filename":"report.pdf"'`}
+	assert.NoError(t, accept.Scan(ctx, upload))
+
+	reject := &CommandScanner{Command: "echo 'infected: EICAR'; exit 1"}
+	err := reject.Scan(ctx, upload)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Contains(t, err.Error(), "infected: EICAR")
+
+	broken := &CommandScanner{Command: "sleep 5", Timeout: 50 * time.Millisecond}
+	err = broken.Scan(ctx, upload)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+}
+
+func TestNewScanner(t *testing.T) {
+	assert.Nil(t, NewScanner(nil))
+	assert.Nil(t, NewScanner(DefaultScanConfig()))
+
+	config := DefaultScanConfig()
+	config.BlockedExtensions = []string{"exe"}
+	config.Command = "true"
+	scanner := NewScanner(config)
+	require.IsType(t, Scanners{}, scanner)
+	assert.Len(t, scanner.(Scanners), 2)
+}
+
+func TestScanConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultScanConfig().Validate())
+
+	config := DefaultScanConfig()
+	config.MaxSize = MaxSize + 1
+	assert.Error(t, config.Validate())
+
+	config = DefaultScanConfig()
+	config.CommandTimeout = 0
+	assert.Error(t, config.Validate())
+}
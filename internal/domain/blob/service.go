@@ -0,0 +1,198 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// MaxSize - наибольший размер блоба: файл до 100 МБ и накладные расходы шифрования
+const MaxSize = 100*1024*1024 + 4096
+
+const (
+	// DefaultPruneInterval - как часто удаляются блобы без ссылок
+	DefaultPruneInterval = time.Hour
+	// DefaultPruneGrace - сколько хранится блоб без ссылок. Клиент загружает блоб
+	// до создания записи, поэтому новый блоб не должен удаляться сразу.
+	DefaultPruneGrace = 24 * time.Hour
+)
+
+// QuotaChecker проверяет квоту хранилища перед сохранением нового блоба
+type QuotaChecker interface {
+	Check(ctx context.Context, userID int, delta int64) error
+}
+
+// Servicer интерфейс сервиса блобов
+type Servicer interface {
+	// GetByChecksum возвращает сведения о блобе без данных
+	GetByChecksum(ctx context.Context, userID int, checksum string) (*Blob, error)
+
+	// Get возвращает блоб вместе с данными
+	Get(ctx context.Context, userID int, checksum string) (*Blob, error)
+
+	// Put сохраняет блоб, если его еще нет. created == false, если блоб
+	// с той же контрольной суммой уже хранится. file - сведения о файле от
+	// клиента для проверки вложений.
+	Put(ctx context.Context, userID int, checksum string, data []byte, file FileInfo) (blob *Blob, created bool, err error)
+}
+
+// Service реализация сервиса блобов
+type Service struct {
+	repo    Repository
+	quota   QuotaChecker
+	scanner Scanner
+	log     *slog.Logger
+}
+
+// NewService создает сервис блобов. quota может быть nil - тогда квота не проверяется,
+// scanner может быть nil - тогда загрузки не проверяются политикой вложений.
+func NewService(repo Repository, quota QuotaChecker, scanner Scanner, log *slog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		quota:   quota,
+		scanner: scanner,
+		log:     log.With("component", "blob_service"),
+	}
+}
+
+func (s *Service) GetByChecksum(ctx context.Context, userID int, checksum string) (*Blob, error) {
+	if !ValidChecksum(checksum) {
+		return nil, ErrInvalidChecksum
+	}
+	return s.repo.GetByChecksum(ctx, userID, checksum)
+}
+
+func (s *Service) Get(ctx context.Context, userID int, checksum string) (*Blob, error) {
+	blob, err := s.GetByChecksum(ctx, userID, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	if blob.Data, err = s.repo.GetData(ctx, userID, checksum); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+func (s *Service) Put(ctx context.Context, userID int, checksum string, data []byte, file FileInfo) (*Blob, bool, error) {
+	if !ValidChecksum(checksum) {
+		return nil, false, ErrInvalidChecksum
+	}
+	if len(data) == 0 {
+		return nil, false, ErrEmptyBlob
+	}
+	if len(data) > MaxSize {
+		return nil, false, ErrTooLarge
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return nil, false, ErrChecksumMismatch
+	}
+
+	// Проверяем до поиска хранящегося блоба: повторная загрузка того же
+	// содержимого под другим именем тоже подчиняется политике
+	if s.scanner != nil {
+		upload := Upload{UserID: userID, Checksum: checksum, Size: int64(len(data)), FileInfo: file}
+		if err := s.scanner.Scan(ctx, upload); err != nil {
+			if errors.Is(err, ErrRejected) {
+				s.log.Warn("blob upload rejected", "user_id", userID, "checksum", checksum,
+					"filename", file.Filename, "size", upload.Size, "reason", err)
+				return nil, false, err
+			}
+			s.log.Error("blob scan failed", "user_id", userID, "checksum", checksum, "error", err)
+			return nil, false, fmt.Errorf("scan blob: %w", err)
+		}
+	}
+
+	existing, err := s.repo.GetByChecksum(ctx, userID, checksum)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+
+	if s.quota != nil {
+		if err := s.quota.Check(ctx, userID, int64(len(data))); err != nil {
+			return nil, false, err
+		}
+	}
+
+	blob := &Blob{
+		UserID:   userID,
+		Checksum: checksum,
+		Size:     int64(len(data)),
+		Data:     data,
+	}
+	created, err := s.repo.Create(ctx, blob)
+	if err != nil {
+		return nil, false, fmt.Errorf("create blob: %w", err)
+	}
+
+	// Блоб мог загрузить параллельный запрос: возвращаем сохраненный
+	stored, err := s.repo.GetByChecksum(ctx, userID, checksum)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		s.log.Info("blob stored", "user_id", userID, "checksum", checksum, "size", blob.Size)
+	}
+	return stored, created, nil
+}
+
+// Pruner удаляет блобы, на которые не ссылается ни одна запись
+type Pruner struct {
+	repo     Repository
+	interval time.Duration
+	grace    time.Duration
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// NewPruner создает задачу очистки с интервалом DefaultPruneInterval и
+// сроком хранения блобов без ссылок DefaultPruneGrace
+func NewPruner(repo Repository, log *slog.Logger) *Pruner {
+	return &Pruner{
+		repo:     repo,
+		interval: DefaultPruneInterval,
+		grace:    DefaultPruneGrace,
+		log:      log.With("component", "blob_pruner"),
+		now:      time.Now,
+	}
+}
+
+// Run запускает очистку по расписанию до отмены контекста
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := p.Prune(ctx)
+			if err != nil {
+				p.log.Error("scheduled blob prune failed", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				p.log.Info("unreferenced blobs pruned", "pruned", pruned)
+			}
+		}
+	}
+}
+
+// Prune удаляет блобы без ссылок старше срока хранения
+func (p *Pruner) Prune(ctx context.Context) (int, error) {
+	pruned, err := p.repo.PruneUnreferenced(ctx, p.now().Add(-p.grace))
+	if err != nil {
+		return 0, fmt.Errorf("prune blobs: %w", err)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,160 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetByChecksum(ctx context.Context, userID int, checksum string) (*Blob, error) {
+	args := m.Called(ctx, userID, checksum)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Blob), args.Error(1)
+}
+
+func (m *MockRepository) GetData(ctx context.Context, userID int, checksum string) ([]byte, error) {
+	args := m.Called(ctx, userID, checksum)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockRepository) Create(ctx context.Context, blob *Blob) (bool, error) {
+	args := m.Called(ctx, blob)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) PruneUnreferenced(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+type quotaFunc func(ctx context.Context, userID int, delta int64) error
+
+func (f quotaFunc) Check(ctx context.Context, userID int, delta int64) error {
+	return f(ctx, userID, delta)
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestService_Put(t *testing.T) {
+	ctx := context.Background()
+	data := []byte("encrypted file")
+	checksum := checksumOf(data)
+
+	t.Run("Invalid checksum", func(t *testing.T) {
+		service := NewService(new(MockRepository), nil, nil, slog.Default())
+
+		_, _, err := service.Put(ctx, 1, "ABC", data, FileInfo{})
+		assert.ErrorIs(t, err, ErrInvalidChecksum)
+
+		_, _, err = service.Put(ctx, 1, checksumOf([]byte("other")), data, FileInfo{})
+		assert.ErrorIs(t, err, ErrChecksumMismatch)
+	})
+
+	t.Run("Already stored", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, quotaFunc(func(context.Context, int, int64) error {
+			return errors.New("quota must not be checked for stored blobs")
+		}), nil, slog.Default())
+		stored := &Blob{UserID: 1, Checksum: checksum, Size: int64(len(data)), RefCount: 2}
+		repo.On("GetByChecksum", mock.Anything, 1, checksum).Return(stored, nil)
+
+		got, created, err := service.Put(ctx, 1, checksum, data, FileInfo{})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, stored, got)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("New blob", func(t *testing.T) {
+		repo := new(MockRepository)
+		var requested int64
+		service := NewService(repo, quotaFunc(func(_ context.Context, _ int, delta int64) error {
+			requested = delta
+			return nil
+		}), nil, slog.Default())
+		stored := &Blob{UserID: 1, Checksum: checksum, Size: int64(len(data))}
+		repo.On("GetByChecksum", mock.Anything, 1, checksum).Return(nil, ErrNotFound).Once()
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(b *Blob) bool {
+			return b.Checksum == checksum && string(b.Data) == string(data)
+		})).Return(true, nil)
+		repo.On("GetByChecksum", mock.Anything, 1, checksum).Return(stored, nil).Once()
+
+		got, created, err := service.Put(ctx, 1, checksum, data, FileInfo{})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, stored, got)
+		assert.Equal(t, int64(len(data)), requested)
+	})
+
+	t.Run("Quota exceeded", func(t *testing.T) {
+		repo := new(MockRepository)
+		errQuota := errors.New("quota exceeded")
+		service := NewService(repo, quotaFunc(func(context.Context, int, int64) error { return errQuota }), nil, slog.Default())
+		repo.On("GetByChecksum", mock.Anything, 1, checksum).Return(nil, ErrNotFound)
+
+		_, _, err := service.Put(ctx, 1, checksum, data, FileInfo{})
+		assert.ErrorIs(t, err, errQuota)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Rejected by scanner", func(t *testing.T) {
+		repo := new(MockRepository)
+		var scanned Upload
+		service := NewService(repo, nil, ScanFunc(func(_ context.Context, upload Upload) error {
+			scanned = upload
+			return fmt.Errorf("%w: extension \"exe\" is blocked", ErrRejected)
+		}), slog.Default())
+
+		_, _, err := service.Put(ctx, 1, checksum, data, FileInfo{Filename: "setup.exe"})
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, Upload{UserID: 1, Checksum: checksum, Size: int64(len(data)), FileInfo: FileInfo{Filename: "setup.exe"}}, scanned)
+		repo.AssertNotCalled(t, "GetByChecksum", mock.Anything, mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Scanner failure", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, nil, ScanFunc(func(context.Context, Upload) error {
+			return errors.New("scanner unavailable")
+		}), slog.Default())
+
+		_, _, err := service.Put(ctx, 1, checksum, data, FileInfo{})
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrRejected)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPruner_Prune(t *testing.T) {
+	repo := new(MockRepository)
+	pruner := NewPruner(repo, slog.Default())
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	pruner.now = func() time.Time { return now }
+	repo.On("PruneUnreferenced", mock.Anything, now.Add(-DefaultPruneGrace)).Return(3, nil)
+
+	pruned, err := pruner.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, pruned)
+}
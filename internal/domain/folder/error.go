@@ -0,0 +1,12 @@
+package folder
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound    = apperr.New(apperr.NotFound, "folder not found").WithCode("FOLDER_NOT_FOUND")
+	ErrInvalidName = apperr.New(apperr.Invalid, "invalid folder name")
+	ErrExists      = apperr.New(apperr.Conflict, "folder with this name already exists").WithCode("FOLDER_EXISTS")
+	ErrCycle       = apperr.New(apperr.Invalid, "folder cannot be moved into itself or its subfolder").WithCode("FOLDER_CYCLE")
+	ErrTooDeep     = apperr.New(apperr.Invalid, "folder nesting is too deep")
+	ErrNotEmpty    = apperr.New(apperr.Conflict, "folder is not empty").WithCode("FOLDER_NOT_EMPTY")
+)
@@ -0,0 +1,122 @@
+package folder
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxNameLength - наибольшая длина имени папки
+	MaxNameLength = 100
+	// MaxDepth - наибольшая вложенность папок
+	MaxDepth = 10
+	// Separator разделяет имена папок в пути (Work/Cloud/AWS)
+	Separator = "/"
+	// MetaKey - ключ открытых метаданных записи с ID ее папки
+	MetaKey = "folder_id"
+)
+
+// Folder - папка для записей пользователя. Записи ссылаются на папку через
+// folder_id в метаданных, поэтому перемещение записи синхронизируется как
+// обычное изменение метаданных.
+type Folder struct {
+	ID     int `json:"id"`
+	UserID int `json:"-"`
+	// ParentID - родительская папка; nil у папок верхнего уровня
+	ParentID  *int      `json:"parent_id,omitempty"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ValidateName проверяет имя папки: непустое, без разделителя пути
+func ValidateName(name string) error {
+	if name == "" || len(name) > MaxNameLength || strings.Contains(name, Separator) {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+// Paths возвращает полные пути папок по их ID: Work/Cloud/AWS
+func Paths(folders []Folder) map[int]string {
+	byID := make(map[int]Folder, len(folders))
+	for _, f := range folders {
+		byID[f.ID] = f
+	}
+
+	paths := make(map[int]string, len(folders))
+	var path func(id int, depth int) string
+	path = func(id int, depth int) string {
+		if p, ok := paths[id]; ok {
+			return p
+		}
+		f := byID[id]
+		p := f.Name
+		// Глубина ограничивает обход, если в данных окажется цикл
+		if f.ParentID != nil && depth < MaxDepth {
+			if _, ok := byID[*f.ParentID]; ok {
+				p = path(*f.ParentID, depth+1) + Separator + p
+			}
+		}
+		paths[id] = p
+		return p
+	}
+
+	for _, f := range folders {
+		path(f.ID, 0)
+	}
+	return paths
+}
+
+// Subtree возвращает ID папки и всех вложенных в нее папок
+func Subtree(folders []Folder, id int) []int {
+	children := make(map[int][]int)
+	for _, f := range folders {
+		if f.ParentID != nil {
+			children[*f.ParentID] = append(children[*f.ParentID], f.ID)
+		}
+	}
+
+	ids := []int{id}
+	seen := map[int]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// RecordFolder возвращает ID папки записи по ее метаданным; 0 - запись вне папок
+func RecordFolder(meta json.RawMessage) int {
+	var fields struct {
+		FolderID int `json:"folder_id"`
+	}
+	if err := json.Unmarshal(meta, &fields); err != nil {
+		return 0
+	}
+	return fields.FolderID
+}
+
+// SetRecordFolder возвращает метаданные записи с папкой id; 0 убирает запись из папки
+func SetRecordFolder(meta json.RawMessage, id int) (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(meta) > 0 && string(meta) != "null" {
+		if err := json.Unmarshal(meta, &fields); err != nil {
+			return nil, fmt.Errorf("meta is not a JSON object: %w", err)
+		}
+	}
+
+	if id > 0 {
+		fields[MetaKey] = json.RawMessage(strconv.Itoa(id))
+	} else {
+		delete(fields, MetaKey)
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,17 @@
+package folder
+
+import "context"
+
+// Repository хранилище папок
+type Repository interface {
+	// List возвращает все папки пользователя
+	List(ctx context.Context, userID int) ([]Folder, error)
+	// Get возвращает папку пользователя или ErrNotFound
+	Get(ctx context.Context, userID, id int) (*Folder, error)
+	Create(ctx context.Context, f *Folder) (int, error)
+	// Update сохраняет имя и родителя папки
+	Update(ctx context.Context, f *Folder) error
+	Delete(ctx context.Context, userID, id int) error
+	// CountRecords возвращает, сколько записей вне корзины лежит в папке
+	CountRecords(ctx context.Context, userID, id int) (int, error)
+}
@@ -0,0 +1,220 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса папок
+type Servicer interface {
+	List(ctx context.Context, userID int) ([]Folder, error)
+	// Create создает папку; parentID == nil - папка верхнего уровня
+	Create(ctx context.Context, userID int, name string, parentID *int) (*Folder, error)
+	// Update переименовывает и перемещает папку
+	Update(ctx context.Context, userID, id int, req UpdateRequest) (*Folder, error)
+	// Delete удаляет пустую папку: без вложенных папок и записей
+	Delete(ctx context.Context, userID, id int) error
+}
+
+// UpdateRequest - изменение папки; nil-поля не меняются
+type UpdateRequest struct {
+	Name *string
+	// ParentID - новая родительская папка; 0 переносит папку на верхний уровень
+	ParentID *int
+}
+
+// Service реализация сервиса папок
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+// NewService создает новый сервис папок
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With("component", "folder_service"),
+	}
+}
+
+func (s *Service) List(ctx context.Context, userID int) ([]Folder, error) {
+	folders, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+	return folders, nil
+}
+
+func (s *Service) Create(ctx context.Context, userID int, name string, parentID *int) (*Folder, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	folders, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	f := &Folder{UserID: userID, ParentID: parentID, Name: name}
+	if err := checkPlacement(folders, f, 1); err != nil {
+		return nil, err
+	}
+
+	f.CreatedAt = time.Now()
+	f.UpdatedAt = f.CreatedAt
+	if f.ID, err = s.repo.Create(ctx, f); err != nil {
+		s.log.Error("failed to create folder", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("create folder: %w", err)
+	}
+
+	s.log.Info("folder created", "folder_id", f.ID, "user_id", userID)
+	return f, nil
+}
+
+func (s *Service) Update(ctx context.Context, userID, id int, req UpdateRequest) (*Folder, error) {
+	f, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := ValidateName(name); err != nil {
+			return nil, err
+		}
+		f.Name = name
+	}
+
+	folders, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		f.ParentID = nil
+		if *req.ParentID != 0 {
+			parentID := *req.ParentID
+			for _, sub := range Subtree(folders, id) {
+				if sub == parentID {
+					return nil, ErrCycle
+				}
+			}
+			f.ParentID = &parentID
+		}
+	}
+
+	if err := checkPlacement(folders, f, subtreeHeight(folders, id)); err != nil {
+		return nil, err
+	}
+
+	f.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, f); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to update folder", "folder_id", id, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("update folder: %w", err)
+	}
+
+	s.log.Info("folder updated", "folder_id", id, "user_id", userID)
+	return f, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID, id int) error {
+	if _, err := s.get(ctx, userID, id); err != nil {
+		return err
+	}
+
+	folders, err := s.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(Subtree(folders, id)) > 1 {
+		return fmt.Errorf("%w: contains subfolders", ErrNotEmpty)
+	}
+
+	count, err := s.repo.CountRecords(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("count folder records: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: contains %d records", ErrNotEmpty, count)
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		s.log.Error("failed to delete folder", "folder_id", id, "user_id", userID, "error", err)
+		return fmt.Errorf("delete folder: %w", err)
+	}
+
+	s.log.Info("folder deleted", "folder_id", id, "user_id", userID)
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, userID, id int) (*Folder, error) {
+	f, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get folder: %w", err)
+	}
+	return f, nil
+}
+
+// checkPlacement проверяет, что папку f высотой height можно поместить в ее
+// родителя: родитель существует, вложенность не превышает MaxDepth и среди
+// соседних папок нет папки с тем же именем
+func checkPlacement(folders []Folder, f *Folder, height int) error {
+	depth := 0
+	if f.ParentID != nil {
+		parents := make(map[int]*int, len(folders))
+		for _, other := range folders {
+			parents[other.ID] = other.ParentID
+		}
+		ancestor, ok := parents[*f.ParentID]
+		if !ok {
+			return ErrNotFound
+		}
+		depth = 1
+		for ancestor != nil && depth <= MaxDepth {
+			depth++
+			ancestor = parents[*ancestor]
+		}
+	}
+	if depth+height > MaxDepth {
+		return ErrTooDeep
+	}
+
+	for _, other := range folders {
+		if other.ID != f.ID && other.Name == f.Name && sameParent(other.ParentID, f.ParentID) {
+			return ErrExists
+		}
+	}
+	return nil
+}
+
+// subtreeHeight возвращает число уровней папки id вместе с вложенными
+func subtreeHeight(folders []Folder, id int) int {
+	height := 1
+	for _, f := range folders {
+		if f.ParentID != nil && *f.ParentID == id && f.ID != id {
+			height = max(height, subtreeHeight(folders, f.ID)+1)
+		}
+	}
+	return height
+}
+
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,221 @@
+package folder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) List(ctx context.Context, userID int) ([]Folder, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Folder), args.Error(1)
+}
+
+func (m *MockRepository) Get(ctx context.Context, userID, id int) (*Folder, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Folder), args.Error(1)
+}
+
+func (m *MockRepository) Create(ctx context.Context, f *Folder) (int, error) {
+	args := m.Called(ctx, f)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, f *Folder) error {
+	args := m.Called(ctx, f)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, userID, id int) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CountRecords(ctx context.Context, userID, id int) (int, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// tree - Work/Cloud/AWS и Personal
+func tree() []Folder {
+	return []Folder{
+		{ID: 1, UserID: 5, Name: "Work"},
+		{ID: 2, UserID: 5, Name: "Cloud", ParentID: intPtr(1)},
+		{ID: 3, UserID: 5, Name: "AWS", ParentID: intPtr(2)},
+		{ID: 4, UserID: 5, Name: "Personal"},
+	}
+}
+
+func TestPaths(t *testing.T) {
+	paths := Paths(tree())
+	assert.Equal(t, "Work/Cloud/AWS", paths[3])
+	assert.Equal(t, "Work/Cloud", paths[2])
+	assert.Equal(t, "Personal", paths[4])
+
+	assert.ElementsMatch(t, []int{1, 2, 3}, Subtree(tree(), 1))
+	assert.Equal(t, []int{4}, Subtree(tree(), 4))
+}
+
+func TestService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("nested folder", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("List", ctx, 5).Return(tree(), nil)
+		repo.On("Create", ctx, mock.MatchedBy(func(f *Folder) bool {
+			return f.Name == "GCP" && f.ParentID != nil && *f.ParentID == 2 && f.UserID == 5
+		})).Return(9, nil)
+
+		f, err := service.Create(ctx, 5, " GCP ", intPtr(2))
+		require.NoError(t, err)
+		assert.Equal(t, 9, f.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+		repo.On("List", ctx, 5).Return(tree(), nil)
+
+		_, err := service.Create(ctx, 5, "Cloud", intPtr(1))
+		assert.ErrorIs(t, err, ErrExists)
+		_, err = service.Create(ctx, 5, "a/b", nil)
+		assert.ErrorIs(t, err, ErrInvalidName)
+		_, err = service.Create(ctx, 5, "Orphan", intPtr(42))
+		assert.ErrorIs(t, err, ErrNotFound)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("move to another parent", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 2).Return(&tree()[1], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(f *Folder) bool {
+			return f.ID == 2 && f.Name == "Infra" && *f.ParentID == 4
+		})).Return(nil)
+
+		f, err := service.Update(ctx, 5, 2, UpdateRequest{Name: strPtr("Infra"), ParentID: intPtr(4)})
+		require.NoError(t, err)
+		assert.Equal(t, "Infra", f.Name)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("move to root", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 3).Return(&tree()[2], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(f *Folder) bool {
+			return f.ID == 3 && f.ParentID == nil
+		})).Return(nil)
+
+		_, err := service.Update(ctx, 5, 3, UpdateRequest{ParentID: intPtr(0)})
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("into own subfolder", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 1).Return(&tree()[0], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+
+		_, err := service.Update(ctx, 5, 1, UpdateRequest{ParentID: intPtr(3)})
+		assert.ErrorIs(t, err, ErrCycle)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		var chain []Folder
+		for id := 1; id <= MaxDepth; id++ {
+			f := Folder{ID: id, Name: "level"}
+			if id > 1 {
+				f.ParentID = intPtr(id - 1)
+			}
+			chain = append(chain, f)
+		}
+		chain = append(chain, Folder{ID: 100, Name: "Other"}, Folder{ID: 101, Name: "Child", ParentID: intPtr(100)})
+
+		repo.On("Get", ctx, 5, 100).Return(&chain[MaxDepth], nil)
+		repo.On("List", ctx, 5).Return(chain, nil)
+
+		_, err := service.Update(ctx, 5, 100, UpdateRequest{ParentID: intPtr(MaxDepth - 1)})
+		assert.ErrorIs(t, err, ErrTooDeep)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty folder", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 4).Return(&tree()[3], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+		repo.On("CountRecords", ctx, 5, 4).Return(0, nil)
+		repo.On("Delete", ctx, 5, 4).Return(nil)
+
+		require.NoError(t, service.Delete(ctx, 5, 4))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("with subfolders", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 1).Return(&tree()[0], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+
+		assert.ErrorIs(t, service.Delete(ctx, 5, 1), ErrNotEmpty)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("with records", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, slog.Default())
+
+		repo.On("Get", ctx, 5, 3).Return(&tree()[2], nil)
+		repo.On("List", ctx, 5).Return(tree(), nil)
+		repo.On("CountRecords", ctx, 5, 3).Return(2, nil)
+
+		assert.ErrorIs(t, service.Delete(ctx, 5, 3), ErrNotEmpty)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func strPtr(s string) *string {
+	return &s
+}
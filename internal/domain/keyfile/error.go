@@ -0,0 +1,11 @@
+package keyfile
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound       = apperr.New(apperr.NotFound, "key file not found").WithCode("KEY_FILE_NOT_FOUND")
+	ErrKeyMismatch    = apperr.New(apperr.Conflict, "key file belongs to another master key").WithCode("KEY_MISMATCH")
+	ErrInvalidKeyFile = apperr.New(apperr.Invalid, "invalid key file").WithCode("INVALID_KEY_FILE")
+
+	ErrUnauthenticated = apperr.New(apperr.Unauthorized, "user not authenticated")
+)
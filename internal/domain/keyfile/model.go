@@ -0,0 +1,41 @@
+// Package keyfile хранит на сервере файл мастер-ключа пользователя, чтобы
+// хранилище можно было восстановить на новом устройстве без резервной копии.
+// Ключ в файле зашифрован мастер-паролем: сервер хранит только соль, параметры
+// KDF и хэш ключа, а расшифровать записи без мастер-пароля не может.
+package keyfile
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// MaxSize - наибольший размер файла мастер-ключа
+const MaxSize = 64 << 10
+
+// KeyFile - файл мастер-ключа пользователя
+type KeyFile struct {
+	Data []byte `json:"data"`
+	// KeyHash - хэш из заголовка файла: отличает файл другого ключа. Меняется
+	// при смене мастер-пароля, поэтому новый файл заменяет прежний только
+	// вместе с его хэшем (Replace).
+	KeyHash   string    `json:"key_hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate проверяет файл перед сохранением
+func (f KeyFile) Validate() error {
+	if len(f.Data) == 0 || len(f.Data) > MaxSize {
+		return fmt.Errorf("%w: size must be 1..%d bytes", ErrInvalidKeyFile, MaxSize)
+	}
+	if !ValidKeyHash(f.KeyHash) {
+		return fmt.Errorf("%w: key_hash must be a hex sha256", ErrInvalidKeyFile)
+	}
+	return nil
+}
+
+// ValidKeyHash проверяет, что хэш - hex SHA-256
+func ValidKeyHash(hash string) bool {
+	b, err := hex.DecodeString(hash)
+	return err == nil && len(b) == 32
+}
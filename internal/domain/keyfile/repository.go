@@ -0,0 +1,18 @@
+package keyfile
+
+import "context"
+
+// Repository интерфейс хранилища файлов мастер-ключа
+type Repository interface {
+	// Get возвращает файл пользователя или ErrNotFound
+	Get(ctx context.Context, userID int) (*KeyFile, error)
+
+	// Save сохраняет файл, если у пользователя его нет или сохраненный файл
+	// относится к тому же ключу (file.KeyHash). Иначе возвращает ErrKeyMismatch.
+	Save(ctx context.Context, userID int, file KeyFile) error
+
+	// Replace сохраняет файл после смены мастер-пароля: сохраненный файл
+	// заменяется, если его хэш равен file.KeyHash или previousKeyHash.
+	// Иначе возвращает ErrKeyMismatch.
+	Replace(ctx context.Context, userID int, file KeyFile, previousKeyHash string) error
+}
@@ -0,0 +1,97 @@
+package keyfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса файлов мастер-ключа
+type Servicer interface {
+	// Get возвращает файл мастер-ключа текущего пользователя
+	Get(ctx context.Context) (*KeyFile, error)
+
+	// Save сохраняет файл мастер-ключа текущего пользователя. Файл другого
+	// ключа не заменяет сохраненный: записи зашифрованы прежним ключом.
+	Save(ctx context.Context, file KeyFile) (*KeyFile, error)
+
+	// Replace сохраняет файл, защищенный новым мастер-паролем. previousKeyHash -
+	// хэш из заголовка прежнего файла того же ключа.
+	Replace(ctx context.Context, file KeyFile, previousKeyHash string) (*KeyFile, error)
+}
+
+// Service реализация сервиса файлов мастер-ключа
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+// NewService создает новый сервис файлов мастер-ключа
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (s *Service) Get(ctx context.Context) (*KeyFile, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	file, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get key file: %w", err)
+	}
+	return file, nil
+}
+
+func (s *Service) Save(ctx context.Context, file KeyFile) (*KeyFile, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, userID, file); err != nil {
+		if errors.Is(err, ErrKeyMismatch) {
+			s.log.Warn("key file of another master key rejected", "user_id", userID)
+		}
+		return nil, fmt.Errorf("save key file: %w", err)
+	}
+
+	s.log.Info("key file saved", "user_id", userID, "size", len(file.Data))
+	return s.Get(ctx)
+}
+
+func (s *Service) Replace(ctx context.Context, file KeyFile, previousKeyHash string) (*KeyFile, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+	if !ValidKeyHash(previousKeyHash) {
+		return nil, fmt.Errorf("%w: previous_key_hash must be a hex sha256", ErrInvalidKeyFile)
+	}
+
+	if err := s.repo.Replace(ctx, userID, file, previousKeyHash); err != nil {
+		if errors.Is(err, ErrKeyMismatch) {
+			s.log.Warn("key file replacement rejected", "user_id", userID)
+		}
+		return nil, fmt.Errorf("replace key file: %w", err)
+	}
+
+	s.log.Info("key file replaced after master password change", "user_id", userID)
+	return s.Get(ctx)
+}
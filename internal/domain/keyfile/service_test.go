@@ -0,0 +1,121 @@
+package keyfile
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/apperr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Get(ctx context.Context, userID int) (*KeyFile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*KeyFile), args.Error(1)
+}
+
+func (m *MockRepository) Save(ctx context.Context, userID int, file KeyFile) error {
+	args := m.Called(ctx, userID, file)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Replace(ctx context.Context, userID int, file KeyFile, previousKeyHash string) error {
+	args := m.Called(ctx, userID, file, previousKeyHash)
+	return args.Error(0)
+}
+
+var testHash = strings.Repeat("ab", 32)
+
+func TestService_Save(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	file := KeyFile{Data: []byte("key file"), KeyHash: testHash}
+	mockRepo.On("Save", mock.Anything, 5, file).Return(nil)
+	mockRepo.On("Get", mock.Anything, 5).Return(&file, nil)
+
+	saved, err := service.Save(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, file.Data, saved.Data)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Save_KeyMismatch(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	file := KeyFile{Data: []byte("key file"), KeyHash: testHash}
+	mockRepo.On("Save", mock.Anything, 5, file).Return(ErrKeyMismatch)
+
+	_, err := service.Save(auth.WithUserID(context.Background(), 5), file)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+	assert.ErrorIs(t, err, apperr.Conflict)
+}
+
+func TestService_Save_Invalid(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	tests := []struct {
+		name string
+		file KeyFile
+	}{
+		{"empty data", KeyFile{KeyHash: testHash}},
+		{"too large", KeyFile{Data: make([]byte, MaxSize+1), KeyHash: testHash}},
+		{"bad hash", KeyFile{Data: []byte("key file"), KeyHash: "xyz"}},
+		{"short hash", KeyFile{Data: []byte("key file"), KeyHash: "abcd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Save(ctx, tt.file)
+			assert.ErrorIs(t, err, ErrInvalidKeyFile)
+		})
+	}
+}
+
+func TestService_Replace(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+	previous := strings.Repeat("cd", 32)
+
+	file := KeyFile{Data: []byte("new password"), KeyHash: testHash}
+	mockRepo.On("Replace", mock.Anything, 5, file, previous).Return(nil)
+	mockRepo.On("Get", mock.Anything, 5).Return(&file, nil)
+
+	saved, err := service.Replace(ctx, file, previous)
+	require.NoError(t, err)
+	assert.Equal(t, testHash, saved.KeyHash)
+
+	_, err = service.Replace(ctx, file, "xyz")
+	assert.ErrorIs(t, err, ErrInvalidKeyFile)
+
+	mockRepo.On("Replace", mock.Anything, 5, file, testHash).Return(ErrKeyMismatch)
+	_, err = service.Replace(ctx, file, testHash)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+}
+
+func TestService_NotAuthenticated(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default())
+
+	_, err := service.Get(context.Background())
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	_, err = service.Save(context.Background(), KeyFile{})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
@@ -0,0 +1,12 @@
+package membership
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound      = apperr.New(apperr.NotFound, "membership not found")
+	ErrUserNotFound  = apperr.New(apperr.NotFound, "user not found")
+	ErrAlreadyMember = apperr.New(apperr.Conflict, "user is already a member or invited").WithCode("ALREADY_MEMBER")
+	ErrNotInvited    = apperr.New(apperr.Conflict, "no pending invitation")
+	ErrLastOwner     = apperr.New(apperr.Conflict, "organization must keep at least one owner").WithCode("LAST_OWNER")
+	ErrForbidden     = apperr.New(apperr.Forbidden, "insufficient organization role")
+)
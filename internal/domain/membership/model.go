@@ -0,0 +1,33 @@
+package membership
+
+import (
+	"time"
+
+	"gophkeeper/internal/domain/org"
+)
+
+// Membership участие пользователя в организации
+type Membership struct {
+	OrgID        int        `json:"org_id"`
+	UserID       int        `json:"user_id"`
+	Role         org.Role   `json:"role"`
+	Status       org.Status `json:"status"`
+	EncryptedKey string     `json:"-"`
+	InvitedBy    *int       `json:"invited_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Member участник в списке организации (без ключа хранилища)
+type Member struct {
+	UserID    int        `json:"user_id"`
+	Login     string     `json:"login"`
+	Role      org.Role   `json:"role"`
+	Status    org.Status `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Active - приглашение принято
+func (m *Membership) Active() bool {
+	return m.Status == org.StatusActive
+}
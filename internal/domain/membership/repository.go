@@ -0,0 +1,23 @@
+package membership
+
+import (
+	"context"
+
+	"gophkeeper/internal/domain/org"
+)
+
+// Repository хранилище участников организаций
+type Repository interface {
+	// Get возвращает участие пользователя или ErrNotFound
+	Get(ctx context.Context, orgID, userID int) (*Membership, error)
+	Create(ctx context.Context, m *Membership) error
+	// Activate принимает приглашение, заменяя ключ хранилища
+	Activate(ctx context.Context, orgID, userID int, encryptedKey string) error
+	UpdateRole(ctx context.Context, orgID, userID int, role org.Role) error
+	Delete(ctx context.Context, orgID, userID int) error
+	List(ctx context.Context, orgID int) ([]Member, error)
+	// CountOwners возвращает число активных владельцев
+	CountOwners(ctx context.Context, orgID int) (int, error)
+	// FindUserID ищет пользователя по логину, возвращает ErrUserNotFound
+	FindUserID(ctx context.Context, login string) (int, error)
+}
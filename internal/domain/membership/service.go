@@ -0,0 +1,241 @@
+package membership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса участников организаций
+type Servicer interface {
+	// Invite приглашает пользователя; encryptedKey - ключ хранилища, зашифрованный кодом приглашения
+	Invite(ctx context.Context, actorID, orgID int, login string, role org.Role, encryptedKey string) (*Membership, error)
+	// Accept принимает приглашение; encryptedKey - ключ хранилища, зашифрованный мастер-ключом участника
+	Accept(ctx context.Context, userID, orgID int, encryptedKey string) error
+	List(ctx context.Context, actorID, orgID int) ([]Member, error)
+	ChangeRole(ctx context.Context, actorID, orgID, userID int, role org.Role) error
+	// Remove исключает участника или отзывает приглашение; участник может выйти сам
+	Remove(ctx context.Context, actorID, orgID, userID int) error
+	// Authorize проверяет доступ к хранилищу организации (реализует record.OrgAuthorizer)
+	Authorize(ctx context.Context, orgID, userID int, access record.Access) error
+}
+
+// Service реализация сервиса участников
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+// NewService создает новый сервис участников организаций
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With("component", "membership_service"),
+	}
+}
+
+func (s *Service) Invite(ctx context.Context, actorID, orgID int, login string, role org.Role, encryptedKey string) (*Membership, error) {
+	if err := role.Validate(); err != nil {
+		return nil, err
+	}
+	if encryptedKey == "" {
+		return nil, org.ErrInvalidKey
+	}
+
+	actor, err := s.activeMember(ctx, orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Role.CanManageMembers() || !actor.Role.CanAssign(role) {
+		return nil, ErrForbidden
+	}
+
+	userID, err := s.repo.FindUserID(ctx, strings.TrimSpace(login))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Get(ctx, orgID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("get membership: %w", err)
+	}
+
+	now := time.Now()
+	m := &Membership{
+		OrgID:        orgID,
+		UserID:       userID,
+		Role:         role,
+		Status:       org.StatusInvited,
+		EncryptedKey: encryptedKey,
+		InvitedBy:    &actorID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := s.repo.Create(ctx, m); err != nil {
+		s.log.Error("failed to create invitation", "org_id", orgID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+
+	s.log.Info("member invited", "org_id", orgID, "user_id", userID, "role", role, "invited_by", actorID)
+	return m, nil
+}
+
+func (s *Service) Accept(ctx context.Context, userID, orgID int, encryptedKey string) error {
+	if encryptedKey == "" {
+		return org.ErrInvalidKey
+	}
+
+	m, err := s.repo.Get(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotInvited
+		}
+		return fmt.Errorf("get membership: %w", err)
+	}
+	if m.Active() {
+		return ErrNotInvited
+	}
+
+	if err := s.repo.Activate(ctx, orgID, userID, encryptedKey); err != nil {
+		return fmt.Errorf("activate membership: %w", err)
+	}
+
+	s.log.Info("invitation accepted", "org_id", orgID, "user_id", userID)
+	return nil
+}
+
+func (s *Service) List(ctx context.Context, actorID, orgID int) ([]Member, error) {
+	if _, err := s.activeMember(ctx, orgID, actorID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.List(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	return members, nil
+}
+
+func (s *Service) ChangeRole(ctx context.Context, actorID, orgID, userID int, role org.Role) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+
+	actor, err := s.activeMember(ctx, orgID, actorID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.repo.Get(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	// Роль меняется, только если действующий может выдать и текущую, и новую роль
+	if !actor.Role.CanManageMembers() || !actor.Role.CanAssign(target.Role) || !actor.Role.CanAssign(role) {
+		return ErrForbidden
+	}
+	if target.Role == role {
+		return nil
+	}
+
+	if target.Role == org.RoleOwner && target.Active() {
+		if err := s.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.UpdateRole(ctx, orgID, userID, role); err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+
+	s.log.Info("member role changed", "org_id", orgID, "user_id", userID, "role", role, "changed_by", actorID)
+	return nil
+}
+
+func (s *Service) Remove(ctx context.Context, actorID, orgID, userID int) error {
+	target, err := s.repo.Get(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	if actorID != userID {
+		actor, err := s.activeMember(ctx, orgID, actorID)
+		if err != nil {
+			return err
+		}
+		if !actor.Role.CanManageMembers() || !actor.Role.CanAssign(target.Role) {
+			return ErrForbidden
+		}
+	}
+
+	if target.Role == org.RoleOwner && target.Active() {
+		if err := s.ensureAnotherOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Delete(ctx, orgID, userID); err != nil {
+		return fmt.Errorf("delete membership: %w", err)
+	}
+
+	s.log.Info("member removed", "org_id", orgID, "user_id", userID, "removed_by", actorID)
+	return nil
+}
+
+// Authorize возвращает record.ErrNotFound, если пользователь не активный участник,
+// и record.ErrForbidden, если роль не позволяет изменять записи
+func (s *Service) Authorize(ctx context.Context, orgID, userID int, access record.Access) error {
+	m, err := s.repo.Get(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return record.ErrNotFound
+		}
+		return fmt.Errorf("get membership: %w", err)
+	}
+
+	if !m.Active() {
+		return record.ErrNotFound
+	}
+	if access == record.AccessWrite && !m.Role.CanWrite() {
+		return record.ErrForbidden
+	}
+
+	return nil
+}
+
+// activeMember возвращает участие действующего пользователя. Посторонним
+// и не принявшим приглашение возвращается org.ErrNotFound.
+func (s *Service) activeMember(ctx context.Context, orgID, userID int) (*Membership, error) {
+	m, err := s.repo.Get(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, org.ErrNotFound
+		}
+		return nil, fmt.Errorf("get membership: %w", err)
+	}
+	if !m.Active() {
+		return nil, org.ErrNotFound
+	}
+	return m, nil
+}
+
+func (s *Service) ensureAnotherOwner(ctx context.Context, orgID int) error {
+	owners, err := s.repo.CountOwners(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("count owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package membership
+
+import (
+	"context"
+	"testing"
+
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/record"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Get(ctx context.Context, orgID, userID int) (*Membership, error) {
+	args := m.Called(ctx, orgID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Membership), args.Error(1)
+}
+
+func (m *MockRepository) Create(ctx context.Context, membership *Membership) error {
+	args := m.Called(ctx, membership)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Activate(ctx context.Context, orgID, userID int, encryptedKey string) error {
+	args := m.Called(ctx, orgID, userID, encryptedKey)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateRole(ctx context.Context, orgID, userID int, role org.Role) error {
+	args := m.Called(ctx, orgID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, orgID, userID int) error {
+	args := m.Called(ctx, orgID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) List(ctx context.Context, orgID int) ([]Member, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Member), args.Error(1)
+}
+
+func (m *MockRepository) CountOwners(ctx context.Context, orgID int) (int, error) {
+	args := m.Called(ctx, orgID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) FindUserID(ctx context.Context, login string) (int, error) {
+	args := m.Called(ctx, login)
+	return args.Int(0), args.Error(1)
+}
+
+func active(orgID, userID int, role org.Role) *Membership {
+	return &Membership{OrgID: orgID, UserID: userID, Role: role, Status: org.StatusActive}
+}
+
+func TestService_Invite(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleAdmin), nil)
+	mockRepo.On("FindUserID", ctx, "bob").Return(20, nil)
+	mockRepo.On("Get", ctx, 1, 20).Return(nil, ErrNotFound)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(m *Membership) bool {
+		return m.UserID == 20 && m.Role == org.RoleMember && m.Status == org.StatusInvited && m.EncryptedKey == "wrapped"
+	})).Return(nil)
+
+	m, err := service.Invite(ctx, 10, 1, "bob", org.RoleMember, "wrapped")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, m.UserID)
+	assert.Equal(t, 10, *m.InvitedBy)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Invite_Forbidden(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		actor   *Membership
+		role    org.Role
+		wantErr error
+	}{
+		{"member cannot invite", active(1, 10, org.RoleMember), org.RoleReadOnly, ErrForbidden},
+		{"admin cannot invite owner", active(1, 10, org.RoleAdmin), org.RoleOwner, ErrForbidden},
+		{"pending invitation", &Membership{OrgID: 1, UserID: 10, Role: org.RoleOwner, Status: org.StatusInvited}, org.RoleMember, org.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, slog.Default())
+			mockRepo.On("Get", ctx, 1, 10).Return(tt.actor, nil)
+
+			_, err := service.Invite(ctx, 10, 1, "bob", tt.role, "wrapped")
+			assert.ErrorIs(t, err, tt.wantErr)
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_Invite_AlreadyMember(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleOwner), nil)
+	mockRepo.On("FindUserID", ctx, "bob").Return(20, nil)
+	mockRepo.On("Get", ctx, 1, 20).Return(active(1, 20, org.RoleMember), nil)
+
+	_, err := service.Invite(ctx, 10, 1, "bob", org.RoleAdmin, "wrapped")
+	assert.ErrorIs(t, err, ErrAlreadyMember)
+}
+
+func TestService_Accept(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Get", ctx, 1, 20).Return(&Membership{OrgID: 1, UserID: 20, Role: org.RoleMember, Status: org.StatusInvited}, nil)
+	mockRepo.On("Activate", ctx, 1, 20, "rewrapped").Return(nil)
+
+	assert.NoError(t, service.Accept(ctx, 20, 1, "rewrapped"))
+	mockRepo.AssertExpectations(t)
+
+	mockRepo = new(MockRepository)
+	service = NewService(mockRepo, slog.Default())
+	mockRepo.On("Get", ctx, 1, 20).Return(active(1, 20, org.RoleMember), nil)
+
+	assert.ErrorIs(t, service.Accept(ctx, 20, 1, "rewrapped"), ErrNotInvited)
+}
+
+func TestService_ChangeRole_LastOwner(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleOwner), nil)
+	mockRepo.On("CountOwners", ctx, 1).Return(1, nil)
+
+	err := service.ChangeRole(ctx, 10, 1, 10, org.RoleAdmin)
+	assert.ErrorIs(t, err, ErrLastOwner)
+	mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ChangeRole_AdminCannotDemoteOwner(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleAdmin), nil)
+	mockRepo.On("Get", ctx, 1, 20).Return(active(1, 20, org.RoleOwner), nil)
+
+	err := service.ChangeRole(ctx, 10, 1, 20, org.RoleMember)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestService_Remove(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("member leaves", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default())
+		mockRepo.On("Get", ctx, 1, 20).Return(active(1, 20, org.RoleReadOnly), nil)
+		mockRepo.On("Delete", ctx, 1, 20).Return(nil)
+
+		assert.NoError(t, service.Remove(ctx, 20, 1, 20))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("member cannot remove others", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default())
+		mockRepo.On("Get", ctx, 1, 20).Return(active(1, 20, org.RoleReadOnly), nil)
+		mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleMember), nil)
+
+		assert.ErrorIs(t, service.Remove(ctx, 10, 1, 20), ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("last owner cannot leave", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default())
+		mockRepo.On("Get", ctx, 1, 10).Return(active(1, 10, org.RoleOwner), nil)
+		mockRepo.On("CountOwners", ctx, 1).Return(1, nil)
+
+		assert.ErrorIs(t, service.Remove(ctx, 10, 1, 10), ErrLastOwner)
+	})
+}
+
+func TestService_Authorize(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		membership *Membership
+		repoErr    error
+		access     record.Access
+		wantErr    error
+	}{
+		{"read-only reads", active(1, 20, org.RoleReadOnly), nil, record.AccessRead, nil},
+		{"read-only writes", active(1, 20, org.RoleReadOnly), nil, record.AccessWrite, record.ErrForbidden},
+		{"member writes", active(1, 20, org.RoleMember), nil, record.AccessWrite, nil},
+		{"invited reads", &Membership{OrgID: 1, UserID: 20, Role: org.RoleAdmin, Status: org.StatusInvited}, nil, record.AccessRead, record.ErrNotFound},
+		{"stranger reads", nil, ErrNotFound, record.AccessRead, record.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, slog.Default())
+			mockRepo.On("Get", ctx, 1, 20).Return(tt.membership, tt.repoErr)
+
+			err := service.Authorize(ctx, 1, 20, tt.access)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package mfa
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotEnabled        = apperr.New(apperr.Conflict, "two-factor authentication is not enabled").WithCode("MFA_NOT_ENABLED")
+	ErrAlreadyEnabled    = apperr.New(apperr.Conflict, "two-factor authentication is already enabled").WithCode("MFA_ALREADY_ENABLED")
+	ErrNotPending        = apperr.New(apperr.Conflict, "no pending two-factor enrollment")
+	ErrInvalidCode       = apperr.New(apperr.Invalid, "invalid two-factor code").WithCode("MFA_INVALID_CODE")
+	ErrChallengeNotFound = apperr.New(apperr.Unauthorized, "login challenge expired or not found").WithCode("MFA_CHALLENGE_EXPIRED")
+	ErrTooManyAttempts   = apperr.New(apperr.Unauthorized, "too many invalid two-factor codes, log in again").WithCode("MFA_TOO_MANY_ATTEMPTS")
+)
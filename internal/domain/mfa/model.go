@@ -0,0 +1,26 @@
+package mfa
+
+// TOTP - секрет второго фактора пользователя
+type TOTP struct {
+	UserID int
+	Secret string // base32
+	// Enabled - секрет подтвержден кодом и обязателен при входе
+	Enabled bool
+	// LastStep - шаг TOTP последнего принятого кода; коды этого и более
+	// ранних шагов повторно не принимаются
+	LastStep int64
+}
+
+// Enrollment - данные для добавления учетной записи в приложение-аутентификатор
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// Status - состояние двухфакторной аутентификации пользователя
+type Status struct {
+	Enabled bool `json:"enabled"`
+	// Pending - секрет выдан, но еще не подтвержден кодом
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
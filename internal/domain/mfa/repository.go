@@ -0,0 +1,48 @@
+package mfa
+
+import (
+	"context"
+	"time"
+)
+
+// Repository интерфейс хранилища второго фактора
+type Repository interface {
+	// Get возвращает секрет пользователя или ErrNotEnabled, если его нет
+	Get(ctx context.Context, userID int) (*TOTP, error)
+
+	// SavePending сохраняет неподтвержденный секрет, заменяя прежний неподтвержденный.
+	// Если второй фактор уже включен, возвращает ErrAlreadyEnabled.
+	SavePending(ctx context.Context, userID int, secret string) error
+
+	// Enable включает второй фактор, запоминает шаг подтверждающего кода
+	// и заменяет коды восстановления
+	Enable(ctx context.Context, userID int, step int64, recoveryHashes []string) error
+
+	// Delete удаляет секрет и коды восстановления
+	Delete(ctx context.Context, userID int) error
+
+	// UseStep отмечает шаг TOTP использованным. Возвращает false,
+	// если код этого или более позднего шага уже принимался.
+	UseStep(ctx context.Context, userID int, step int64) (bool, error)
+
+	// ReplaceRecoveryCodes заменяет коды восстановления
+	ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryHashes []string) error
+
+	// UseRecoveryCode отмечает код восстановления использованным.
+	// Возвращает false, если кода нет или он уже использован.
+	UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error)
+
+	// RecoveryCodesLeft возвращает число неиспользованных кодов восстановления
+	RecoveryCodesLeft(ctx context.Context, userID int) (int, error)
+
+	// CreateChallenge сохраняет незавершенный вход
+	CreateChallenge(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+
+	// AttemptChallenge учитывает попытку ввода кода и возвращает пользователя
+	// и число попыток с учетом текущей. Для истекшего или неизвестного входа
+	// возвращает ErrChallengeNotFound.
+	AttemptChallenge(ctx context.Context, tokenHash string) (userID int, attempts int, err error)
+
+	// DeleteChallenge удаляет незавершенный вход
+	DeleteChallenge(ctx context.Context, tokenHash string) error
+}
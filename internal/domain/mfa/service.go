@@ -0,0 +1,343 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	issuer = "GophKeeper"
+
+	secretLength = 20 // 160 бит, как рекомендует RFC 4226
+	codeDigits   = 6
+	codePeriod   = 30
+	// codeSkew - сколько соседних шагов принимается из-за расхождения часов
+	codeSkew = 1
+
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+
+	challengeTTL         = 5 * time.Minute
+	maxChallengeAttempts = 5
+)
+
+// recoveryAlphabet - символы кодов восстановления без похожих 0/o, 1/l
+const recoveryAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// Servicer интерфейс сервиса двухфакторной аутентификации
+type Servicer interface {
+	// Status возвращает состояние второго фактора пользователя
+	Status(ctx context.Context, userID int) (*Status, error)
+
+	// Enroll выдает новый секрет. Второй фактор включается только после Confirm.
+	Enroll(ctx context.Context, userID int, account string) (*Enrollment, error)
+
+	// Confirm проверяет первый код из приложения, включает второй фактор
+	// и возвращает коды восстановления
+	Confirm(ctx context.Context, userID int, code string) ([]string, error)
+
+	// Disable выключает второй фактор. Требует код из приложения или код восстановления.
+	Disable(ctx context.Context, userID int, code string) error
+
+	// RegenerateRecoveryCodes выдает новые коды восстановления взамен старых
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+
+	// Required сообщает, нужен ли пользователю второй фактор при входе
+	Required(ctx context.Context, userID int) (bool, error)
+
+	// StartLogin создает незавершенный вход после проверки пароля
+	StartLogin(ctx context.Context, userID int) (string, error)
+
+	// CompleteLogin проверяет код для незавершенного входа и возвращает пользователя
+	CompleteLogin(ctx context.Context, challenge, code string) (int, error)
+}
+
+// Service реализация сервиса двухфакторной аутентификации
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+	now  func() time.Time
+}
+
+// NewService создает сервис двухфакторной аутентификации
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With("component", "mfa_service"),
+		now:  time.Now,
+	}
+}
+
+func (s *Service) Status(ctx context.Context, userID int) (*Status, error) {
+	totp, err := s.repo.Get(ctx, userID)
+	if err == ErrNotEnabled {
+		return &Status{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get totp: %w", err)
+	}
+
+	status := &Status{Enabled: totp.Enabled, Pending: !totp.Enabled}
+	if totp.Enabled {
+		status.RecoveryCodesLeft, err = s.repo.RecoveryCodesLeft(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("count recovery codes: %w", err)
+		}
+	}
+	return status, nil
+}
+
+func (s *Service) Enroll(ctx context.Context, userID int, account string) (*Enrollment, error) {
+	raw := make([]byte, secretLength)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate secret: %w", err)
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	if err := s.repo.SavePending(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("two-factor enrollment started", "user_id", userID)
+	return &Enrollment{Secret: secret, URI: otpauthURI(account, secret)}, nil
+}
+
+func (s *Service) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	totp, err := s.repo.Get(ctx, userID)
+	if err == ErrNotEnabled {
+		return nil, ErrNotPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get totp: %w", err)
+	}
+	if totp.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	step, ok := s.matchStep(totp, code)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, fmt.Errorf("enable totp: %w", err)
+	}
+
+	s.log.Info("two-factor authentication enabled", "user_id", userID)
+	return codes, nil
+}
+
+func (s *Service) Disable(ctx context.Context, userID int, code string) error {
+	if err := s.verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("delete totp: %w", err)
+	}
+
+	s.log.Info("two-factor authentication disabled", "user_id", userID)
+	return nil
+}
+
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	if err := s.verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("replace recovery codes: %w", err)
+	}
+
+	s.log.Info("recovery codes regenerated", "user_id", userID)
+	return codes, nil
+}
+
+func (s *Service) Required(ctx context.Context, userID int) (bool, error) {
+	totp, err := s.repo.Get(ctx, userID)
+	if err == ErrNotEnabled {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get totp: %w", err)
+	}
+	return totp.Enabled, nil
+}
+
+func (s *Service) StartLogin(ctx context.Context, userID int) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("generate challenge: %w", err)
+	}
+	challenge := base64.URLEncoding.EncodeToString(tokenBytes)
+
+	if err := s.repo.CreateChallenge(ctx, userID, hashToken(challenge), s.now().Add(challengeTTL)); err != nil {
+		return "", fmt.Errorf("save challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+func (s *Service) CompleteLogin(ctx context.Context, challenge, code string) (int, error) {
+	tokenHash := hashToken(challenge)
+
+	userID, attempts, err := s.repo.AttemptChallenge(ctx, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	if attempts > maxChallengeAttempts {
+		_ = s.repo.DeleteChallenge(ctx, tokenHash)
+		s.log.Warn("two-factor login attempts exceeded", "user_id", userID)
+		return 0, ErrTooManyAttempts
+	}
+
+	if err := s.verify(ctx, userID, code); err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.DeleteChallenge(ctx, tokenHash); err != nil {
+		s.log.Warn("failed to delete login challenge", "user_id", userID, "error", err)
+	}
+
+	return userID, nil
+}
+
+// verify принимает код из приложения или код восстановления включенного второго фактора
+func (s *Service) verify(ctx context.Context, userID int, code string) error {
+	totp, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !totp.Enabled {
+		return ErrNotEnabled
+	}
+
+	if isTOTPCode(code) {
+		step, ok := s.matchStep(totp, code)
+		if !ok {
+			return ErrInvalidCode
+		}
+		fresh, err := s.repo.UseStep(ctx, userID, step)
+		if err != nil {
+			return fmt.Errorf("use totp step: %w", err)
+		}
+		if !fresh {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidCode
+	}
+
+	s.log.Info("recovery code used", "user_id", userID)
+	return nil
+}
+
+// matchStep ищет шаг TOTP, которому соответствует код, с допуском codeSkew
+func (s *Service) matchStep(totp *TOTP, code string) (int64, bool) {
+	code = strings.TrimSpace(code)
+	otp := record.OTPData{Secret: totp.Secret, Digits: codeDigits, Period: codePeriod}
+	current := s.now().Unix() / codePeriod
+
+	for step := current - codeSkew; step <= current+codeSkew; step++ {
+		if step <= totp.LastStep {
+			continue
+		}
+		expected, err := otp.Code(time.Unix(step*codePeriod, 0))
+		if err == nil && expected == code {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != codeDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes генерирует коды восстановления вида xxxxx-xxxxx и их хэши
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	raw := make([]byte, recoveryCodeLength)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		var b strings.Builder
+		for j, v := range raw {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			b.WriteByte(recoveryAlphabet[int(v)%len(recoveryAlphabet)])
+		}
+
+		code := b.String()
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode нормализует код (регистр, дефисы, пробелы) и хэширует его
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func otpauthURI(account, secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(codeDigits))
+	query.Set("period", fmt.Sprint(codePeriod))
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
@@ -0,0 +1,322 @@
+package mfa
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Get(ctx context.Context, userID int) (*TOTP, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TOTP), args.Error(1)
+}
+
+func (m *MockRepository) SavePending(ctx context.Context, userID int, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Enable(ctx context.Context, userID int, step int64, recoveryHashes []string) error {
+	args := m.Called(ctx, userID, step, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryHashes []string) error {
+	args := m.Called(ctx, userID, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error) {
+	args := m.Called(ctx, userID, hash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) RecoveryCodesLeft(ctx context.Context, userID int) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateChallenge(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) AttemptChallenge(ctx context.Context, tokenHash string) (int, int, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockRepository) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+const testSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(repo Repository) *Service {
+	s := NewService(repo, slog.Default())
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+func codeAt(t *testing.T, at time.Time) string {
+	t.Helper()
+	code, err := (&record.OTPData{Secret: testSecret}).Code(at)
+	require.NoError(t, err)
+	return code
+}
+
+func TestService_Enroll(t *testing.T) {
+	repo := new(MockRepository)
+	service := newTestService(repo)
+	repo.On("SavePending", mock.Anything, 1, mock.AnythingOfType("string")).Return(nil)
+
+	enrollment, err := service.Enroll(context.Background(), 1, "alice")
+	require.NoError(t, err)
+	assert.Len(t, enrollment.Secret, 32)
+
+	uri, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/GophKeeper:alice", uri.Path)
+	assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+	assert.Equal(t, "GophKeeper", uri.Query().Get("issuer"))
+
+	// Секрет из URI дает те же коды, что проверяет сервис
+	otp := &record.OTPData{Secret: enrollment.Secret}
+	require.NoError(t, otp.Validate())
+}
+
+func TestService_Confirm(t *testing.T) {
+	t.Run("Valid code enables and returns recovery codes", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(&TOTP{UserID: 1, Secret: testSecret}, nil)
+		repo.On("Enable", mock.Anything, 1, testNow.Unix()/30, mock.MatchedBy(func(hashes []string) bool {
+			return len(hashes) == recoveryCodeCount
+		})).Return(nil)
+
+		codes, err := service.Confirm(context.Background(), 1, codeAt(t, testNow))
+		require.NoError(t, err)
+		assert.Len(t, codes, recoveryCodeCount)
+		for _, code := range codes {
+			assert.Len(t, code, recoveryCodeLength+1)
+			assert.Contains(t, code, "-")
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Previous step is accepted", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(&TOTP{UserID: 1, Secret: testSecret}, nil)
+		repo.On("Enable", mock.Anything, 1, testNow.Unix()/30-1, mock.Anything).Return(nil)
+
+		_, err := service.Confirm(context.Background(), 1, codeAt(t, testNow.Add(-30*time.Second)))
+		require.NoError(t, err)
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(&TOTP{UserID: 1, Secret: testSecret}, nil)
+
+		_, err := service.Confirm(context.Background(), 1, codeAt(t, testNow.Add(5*time.Minute)))
+		assert.ErrorIs(t, err, ErrInvalidCode)
+		repo.AssertNotCalled(t, "Enable", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("No pending enrollment", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(nil, ErrNotEnabled)
+
+		_, err := service.Confirm(context.Background(), 1, "123456")
+		assert.ErrorIs(t, err, ErrNotPending)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(&TOTP{UserID: 1, Secret: testSecret, Enabled: true}, nil)
+
+		_, err := service.Confirm(context.Background(), 1, codeAt(t, testNow))
+		assert.ErrorIs(t, err, ErrAlreadyEnabled)
+	})
+}
+
+func TestService_Disable(t *testing.T) {
+	enabled := &TOTP{UserID: 1, Secret: testSecret, Enabled: true}
+
+	t.Run("TOTP code", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(enabled, nil)
+		repo.On("UseStep", mock.Anything, 1, testNow.Unix()/30).Return(true, nil)
+		repo.On("Delete", mock.Anything, 1).Return(nil)
+
+		require.NoError(t, service.Disable(context.Background(), 1, codeAt(t, testNow)))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Replayed TOTP code", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(enabled, nil)
+		repo.On("UseStep", mock.Anything, 1, testNow.Unix()/30).Return(false, nil)
+
+		err := service.Disable(context.Background(), 1, codeAt(t, testNow))
+		assert.ErrorIs(t, err, ErrInvalidCode)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already used step is skipped", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		used := &TOTP{UserID: 1, Secret: testSecret, Enabled: true, LastStep: testNow.Unix() / 30}
+		repo.On("Get", mock.Anything, 1).Return(used, nil)
+
+		err := service.Disable(context.Background(), 1, codeAt(t, testNow))
+		assert.ErrorIs(t, err, ErrInvalidCode)
+	})
+
+	t.Run("Recovery code is normalized", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(enabled, nil)
+		repo.On("UseRecoveryCode", mock.Anything, 1, hashRecoveryCode("abcde-fghij")).Return(true, nil)
+		repo.On("Delete", mock.Anything, 1).Return(nil)
+
+		require.NoError(t, service.Disable(context.Background(), 1, " ABCDE FGHIJ "))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Not enabled", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("Get", mock.Anything, 1).Return(&TOTP{UserID: 1, Secret: testSecret}, nil)
+
+		err := service.Disable(context.Background(), 1, codeAt(t, testNow))
+		assert.ErrorIs(t, err, ErrNotEnabled)
+	})
+}
+
+func TestService_Login(t *testing.T) {
+	enabled := &TOTP{UserID: 1, Secret: testSecret, Enabled: true}
+
+	t.Run("Challenge is completed with a valid code", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+
+		var savedHash string
+		repo.On("CreateChallenge", mock.Anything, 1, mock.AnythingOfType("string"), testNow.Add(challengeTTL)).
+			Run(func(args mock.Arguments) { savedHash = args.String(2) }).Return(nil)
+
+		challenge, err := service.StartLogin(context.Background(), 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, challenge, savedHash)
+		assert.Equal(t, hashToken(challenge), savedHash)
+
+		repo.On("AttemptChallenge", mock.Anything, savedHash).Return(1, 1, nil)
+		repo.On("Get", mock.Anything, 1).Return(enabled, nil)
+		repo.On("UseStep", mock.Anything, 1, testNow.Unix()/30).Return(true, nil)
+		repo.On("DeleteChallenge", mock.Anything, savedHash).Return(nil)
+
+		userID, err := service.CompleteLogin(context.Background(), challenge, codeAt(t, testNow))
+		require.NoError(t, err)
+		assert.Equal(t, 1, userID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("AttemptChallenge", mock.Anything, hashToken("challenge")).Return(1, maxChallengeAttempts+1, nil)
+		repo.On("DeleteChallenge", mock.Anything, hashToken("challenge")).Return(nil)
+
+		_, err := service.CompleteLogin(context.Background(), "challenge", codeAt(t, testNow))
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+		repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown challenge", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := newTestService(repo)
+		repo.On("AttemptChallenge", mock.Anything, mock.Anything).Return(0, 0, ErrChallengeNotFound)
+
+		_, err := service.CompleteLogin(context.Background(), "challenge", "123456")
+		assert.ErrorIs(t, err, ErrChallengeNotFound)
+	})
+}
+
+func TestService_StatusAndRequired(t *testing.T) {
+	repo := new(MockRepository)
+	service := newTestService(repo)
+	repo.On("Get", mock.Anything, 1).Return(nil, ErrNotEnabled)
+	repo.On("Get", mock.Anything, 2).Return(&TOTP{UserID: 2, Secret: testSecret}, nil)
+	repo.On("Get", mock.Anything, 3).Return(&TOTP{UserID: 3, Secret: testSecret, Enabled: true}, nil)
+	repo.On("RecoveryCodesLeft", mock.Anything, 3).Return(7, nil)
+
+	tests := []struct {
+		userID   int
+		status   Status
+		required bool
+	}{
+		{1, Status{}, false},
+		{2, Status{Pending: true}, false},
+		{3, Status{Enabled: true, RecoveryCodesLeft: 7}, true},
+	}
+	for _, tt := range tests {
+		status, err := service.Status(context.Background(), tt.userID)
+		require.NoError(t, err)
+		assert.Equal(t, tt.status, *status, "user %d", tt.userID)
+
+		required, err := service.Required(context.Background(), tt.userID)
+		require.NoError(t, err)
+		assert.Equal(t, tt.required, required, "user %d", tt.userID)
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, hashes, len(codes))
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		assert.False(t, seen[code])
+		seen[code] = true
+		assert.Equal(t, hashRecoveryCode(strings.ToUpper(code)), hashes[i])
+		assert.False(t, isTOTPCode(code))
+	}
+}
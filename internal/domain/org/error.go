@@ -0,0 +1,11 @@
+package org
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound    = apperr.New(apperr.NotFound, "organization not found").WithCode("ORG_NOT_FOUND")
+	ErrInvalidName = apperr.New(apperr.Invalid, "invalid organization name")
+	ErrInvalidRole = apperr.New(apperr.Invalid, "invalid member role")
+	ErrInvalidKey  = apperr.New(apperr.Invalid, "encrypted vault key is required")
+	ErrForbidden   = apperr.New(apperr.Forbidden, "insufficient organization role").WithCode("ORG_FORBIDDEN")
+)
@@ -0,0 +1,80 @@
+package org
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role роль участника организации
+type Role string
+
+const (
+	RoleOwner    Role = "owner"
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "read-only"
+)
+
+// Validate проверяет, что роль известна
+func (r Role) Validate() error {
+	switch r {
+	case RoleOwner, RoleAdmin, RoleMember, RoleReadOnly:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidRole, r)
+	}
+}
+
+// CanWrite - может создавать, изменять и удалять записи хранилища
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleAdmin || r == RoleMember
+}
+
+// CanManageMembers - может приглашать и исключать участников
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// CanAssign - может выдать роль target. Администратор управляет только
+// участниками и читателями; владельцев и администраторов назначает владелец.
+func (r Role) CanAssign(target Role) bool {
+	switch r {
+	case RoleOwner:
+		return true
+	case RoleAdmin:
+		return target == RoleMember || target == RoleReadOnly
+	default:
+		return false
+	}
+}
+
+// Status состояние участия в организации
+type Status string
+
+const (
+	// StatusInvited - приглашение отправлено, ключ хранилища зашифрован кодом приглашения
+	StatusInvited Status = "invited"
+	// StatusActive - приглашение принято, ключ зашифрован мастер-ключом участника
+	StatusActive Status = "active"
+)
+
+const maxNameLength = 255
+
+// Organization организация с общим хранилищем записей
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy int       `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Summary организация глазами пользователя: его роль, статус и ключ хранилища
+type Summary struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Role         Role      `json:"role"`
+	Status       Status    `json:"status"`
+	EncryptedKey string    `json:"encrypted_key,omitempty"`
+	Members      int       `json:"members"`
+	CreatedAt    time.Time `json:"created_at"`
+}
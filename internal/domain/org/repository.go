@@ -0,0 +1,14 @@
+package org
+
+import "context"
+
+// Repository хранилище организаций
+type Repository interface {
+	// Create создает организацию и делает создателя активным владельцем
+	Create(ctx context.Context, org *Organization, ownerKey string) (int, error)
+	// ListForUser возвращает организации, в которых состоит пользователь (включая приглашения)
+	ListForUser(ctx context.Context, userID int) ([]Summary, error)
+	// GetForUser возвращает организацию с участием пользователя или ErrNotFound
+	GetForUser(ctx context.Context, orgID, userID int) (*Summary, error)
+	Delete(ctx context.Context, orgID int) error
+}
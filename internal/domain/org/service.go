@@ -0,0 +1,98 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса организаций
+type Servicer interface {
+	// Create создает организацию; encryptedKey - ключ хранилища, зашифрованный мастер-ключом создателя
+	Create(ctx context.Context, userID int, name, encryptedKey string) (*Organization, error)
+	List(ctx context.Context, userID int) ([]Summary, error)
+	Get(ctx context.Context, userID, orgID int) (*Summary, error)
+	// Delete удаляет организацию вместе с записями хранилища; доступно только владельцу
+	Delete(ctx context.Context, userID, orgID int) error
+}
+
+// Service реализация сервиса организаций
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+// NewService создает новый сервис организаций
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With("component", "org_service"),
+	}
+}
+
+func (s *Service) Create(ctx context.Context, userID int, name, encryptedKey string) (*Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, ErrInvalidName
+	}
+	if encryptedKey == "" {
+		return nil, ErrInvalidKey
+	}
+
+	org := &Organization{
+		Name:      name,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+
+	id, err := s.repo.Create(ctx, org, encryptedKey)
+	if err != nil {
+		s.log.Error("failed to create organization", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("create organization: %w", err)
+	}
+	org.ID = id
+
+	s.log.Info("organization created", "org_id", id, "user_id", userID)
+	return org, nil
+}
+
+func (s *Service) List(ctx context.Context, userID int) ([]Summary, error) {
+	orgs, err := s.repo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+func (s *Service) Get(ctx context.Context, userID, orgID int) (*Summary, error) {
+	org, err := s.repo.GetForUser(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+	return org, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID, orgID int) error {
+	org, err := s.Get(ctx, userID, orgID)
+	if err != nil {
+		return err
+	}
+	if org.Status != StatusActive || org.Role != RoleOwner {
+		return ErrForbidden
+	}
+
+	if err := s.repo.Delete(ctx, orgID); err != nil {
+		s.log.Error("failed to delete organization", "org_id", orgID, "error", err)
+		return fmt.Errorf("delete organization: %w", err)
+	}
+
+	s.log.Info("organization deleted", "org_id", orgID, "user_id", userID)
+	return nil
+}
@@ -0,0 +1,108 @@
+package org
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, org *Organization, ownerKey string) (int, error) {
+	args := m.Called(ctx, org, ownerKey)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) ListForUser(ctx context.Context, userID int) ([]Summary, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Summary), args.Error(1)
+}
+
+func (m *MockRepository) GetForUser(ctx context.Context, orgID, userID int) (*Summary, error) {
+	args := m.Called(ctx, orgID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Summary), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, orgID int) error {
+	args := m.Called(ctx, orgID)
+	return args.Error(0)
+}
+
+func TestService_Create(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(o *Organization) bool {
+		return o.Name == "Team" && o.CreatedBy == 5
+	}), "wrapped").Return(7, nil)
+
+	org, err := service.Create(ctx, 5, "  Team ", "wrapped")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, org.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default())
+
+	_, err := service.Create(context.Background(), 5, "   ", "wrapped")
+	assert.ErrorIs(t, err, ErrInvalidName)
+
+	_, err = service.Create(context.Background(), 5, "Team", "")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestService_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		summary *Summary
+		wantErr error
+	}{
+		{"owner", &Summary{ID: 7, Role: RoleOwner, Status: StatusActive}, nil},
+		{"admin", &Summary{ID: 7, Role: RoleAdmin, Status: StatusActive}, ErrForbidden},
+		{"invited owner", &Summary{ID: 7, Role: RoleOwner, Status: StatusInvited}, ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			service := NewService(mockRepo, slog.Default())
+			mockRepo.On("GetForUser", ctx, 7, 5).Return(tt.summary, nil)
+			if tt.wantErr == nil {
+				mockRepo.On("Delete", ctx, 7).Return(nil)
+			}
+
+			err := service.Delete(ctx, 5, 7)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRole_CanAssign(t *testing.T) {
+	assert.True(t, RoleOwner.CanAssign(RoleOwner))
+	assert.True(t, RoleAdmin.CanAssign(RoleReadOnly))
+	assert.False(t, RoleAdmin.CanAssign(RoleAdmin))
+	assert.False(t, RoleMember.CanAssign(RoleReadOnly))
+	assert.False(t, RoleReadOnly.CanWrite())
+}
@@ -0,0 +1,32 @@
+package quota
+
+import (
+	"fmt"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+var (
+	ErrInvalidLimit = apperr.New(apperr.Invalid, "storage limit must be positive")
+	ErrUserNotFound = apperr.New(apperr.NotFound, "user not found")
+)
+
+// ExceededError возвращается, если запись данных превысит квоту пользователя
+type ExceededError struct {
+	Usage
+	// Requested - на сколько байт запрос увеличивает использование
+	Requested int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("storage quota exceeded: used %d of %d bytes, requested %d", e.Used, e.Limit, e.Requested)
+}
+
+func (e *ExceededError) Kind() apperr.Kind {
+	return apperr.QuotaExceeded
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, apperr.QuotaExceeded)
+func (e *ExceededError) Is(target error) bool {
+	return target == apperr.QuotaExceeded
+}
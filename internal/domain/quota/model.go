@@ -0,0 +1,16 @@
+package quota
+
+// Usage - использование хранилища пользователем.
+// Учитываются зашифрованные данные записей, кроме записей в корзине, блобы бинарных записей и вложения.
+type Usage struct {
+	UserID int   `json:"user_id"`
+	Used   int64 `json:"used"`
+	Limit  int64 `json:"limit"`
+	// Custom - лимит задан администратором, а не взят из конфигурации
+	Custom bool `json:"custom"`
+}
+
+// Remaining возвращает, сколько байт еще можно записать
+func (u *Usage) Remaining() int64 {
+	return max(u.Limit-u.Used, 0)
+}
@@ -0,0 +1,18 @@
+package quota
+
+import "context"
+
+// Repository интерфейс хранилища квот
+type Repository interface {
+	// Used возвращает размер данных записей пользователя без учета корзины, с блобами и вложениями
+	Used(ctx context.Context, userID int) (int64, error)
+
+	// GetLimit возвращает лимит, заданный пользователю. ok == false, если лимит не задан.
+	GetLimit(ctx context.Context, userID int) (limit int64, ok bool, err error)
+
+	// SetLimit задает лимит пользователю. Для несуществующего пользователя возвращает ErrUserNotFound.
+	SetLimit(ctx context.Context, userID int, limit int64) error
+
+	// DeleteLimit удаляет заданный лимит; пользователю снова действует лимит по умолчанию
+	DeleteLimit(ctx context.Context, userID int) error
+}
@@ -0,0 +1,102 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса квот
+type Servicer interface {
+	// Usage возвращает использование хранилища и действующий лимит
+	Usage(ctx context.Context, userID int) (*Usage, error)
+
+	// Check возвращает *ExceededError, если после записи delta байт квота будет превышена.
+	// Уменьшение объема (delta <= 0) разрешено всегда.
+	Check(ctx context.Context, userID int, delta int64) error
+
+	// SetLimit задает пользователю собственный лимит
+	SetLimit(ctx context.Context, userID int, limit int64) (*Usage, error)
+
+	// ResetLimit возвращает пользователю лимит по умолчанию
+	ResetLimit(ctx context.Context, userID int) (*Usage, error)
+}
+
+// Service реализация сервиса квот
+type Service struct {
+	repo         Repository
+	defaultLimit int64
+	log          *slog.Logger
+}
+
+// NewService создает сервис квот. defaultLimit действует для пользователей без собственного лимита.
+func NewService(repo Repository, defaultLimit int64, log *slog.Logger) *Service {
+	return &Service{
+		repo:         repo,
+		defaultLimit: defaultLimit,
+		log:          log.With("component", "quota_service"),
+	}
+}
+
+func (s *Service) Usage(ctx context.Context, userID int) (*Usage, error) {
+	used, err := s.repo.Used(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get storage usage: %w", err)
+	}
+
+	limit, custom, err := s.repo.GetLimit(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get storage limit: %w", err)
+	}
+	if !custom {
+		limit = s.defaultLimit
+	}
+
+	return &Usage{
+		UserID: userID,
+		Used:   used,
+		Limit:  limit,
+		Custom: custom,
+	}, nil
+}
+
+func (s *Service) Check(ctx context.Context, userID int, delta int64) error {
+	if delta <= 0 {
+		return nil
+	}
+
+	usage, err := s.Usage(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if usage.Used+delta > usage.Limit {
+		s.log.Info("storage quota exceeded", "user_id", userID, "used", usage.Used, "limit", usage.Limit, "requested", delta)
+		return &ExceededError{Usage: *usage, Requested: delta}
+	}
+
+	return nil
+}
+
+func (s *Service) SetLimit(ctx context.Context, userID int, limit int64) (*Usage, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidLimit
+	}
+
+	if err := s.repo.SetLimit(ctx, userID, limit); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("storage limit changed", "user_id", userID, "limit", limit)
+	return s.Usage(ctx, userID)
+}
+
+func (s *Service) ResetLimit(ctx context.Context, userID int) (*Usage, error) {
+	if err := s.repo.DeleteLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("storage limit reset to default", "user_id", userID)
+	return s.Usage(ctx, userID)
+}
@@ -0,0 +1,151 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Used(ctx context.Context, userID int) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) GetLimit(ctx context.Context, userID int) (int64, bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockRepository) SetLimit(ctx context.Context, userID int, limit int64) error {
+	args := m.Called(ctx, userID, limit)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteLimit(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestService_Usage(t *testing.T) {
+	t.Run("Default limit", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+		repo.On("Used", mock.Anything, 1).Return(int64(250), nil)
+		repo.On("GetLimit", mock.Anything, 1).Return(int64(0), false, nil)
+
+		usage, err := service.Usage(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, &Usage{UserID: 1, Used: 250, Limit: 1000}, usage)
+		assert.Equal(t, int64(750), usage.Remaining())
+	})
+
+	t.Run("Custom limit", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+		repo.On("Used", mock.Anything, 1).Return(int64(250), nil)
+		repo.On("GetLimit", mock.Anything, 1).Return(int64(5000), true, nil)
+
+		usage, err := service.Usage(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5000), usage.Limit)
+		assert.True(t, usage.Custom)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+		repo.On("Used", mock.Anything, 1).Return(int64(0), errors.New("db down"))
+
+		_, err := service.Usage(context.Background(), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Check(t *testing.T) {
+	newService := func(used int64) (*Service, *MockRepository) {
+		repo := new(MockRepository)
+		repo.On("Used", mock.Anything, 1).Return(used, nil)
+		repo.On("GetLimit", mock.Anything, 1).Return(int64(0), false, nil)
+		return NewService(repo, 1000, slog.Default()), repo
+	}
+
+	t.Run("Within quota", func(t *testing.T) {
+		service, _ := newService(900)
+		assert.NoError(t, service.Check(context.Background(), 1, 100))
+	})
+
+	t.Run("Exceeds quota", func(t *testing.T) {
+		service, _ := newService(900)
+
+		err := service.Check(context.Background(), 1, 101)
+		var exceeded *ExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.Equal(t, int64(900), exceeded.Used)
+		assert.Equal(t, int64(1000), exceeded.Limit)
+		assert.Equal(t, int64(101), exceeded.Requested)
+	})
+
+	t.Run("Shrinking is always allowed", func(t *testing.T) {
+		service, repo := newService(5000)
+
+		assert.NoError(t, service.Check(context.Background(), 1, -10))
+		assert.NoError(t, service.Check(context.Background(), 1, 0))
+		repo.AssertNotCalled(t, "Used", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_SetLimit(t *testing.T) {
+	t.Run("Sets limit", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+		repo.On("SetLimit", mock.Anything, 1, int64(2000)).Return(nil)
+		repo.On("Used", mock.Anything, 1).Return(int64(10), nil)
+		repo.On("GetLimit", mock.Anything, 1).Return(int64(2000), true, nil)
+
+		usage, err := service.SetLimit(context.Background(), 1, 2000)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2000), usage.Limit)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+
+		_, err := service.SetLimit(context.Background(), 1, 0)
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+		repo.AssertNotCalled(t, "SetLimit", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, 1000, slog.Default())
+		repo.On("SetLimit", mock.Anything, 99, int64(2000)).Return(ErrUserNotFound)
+
+		_, err := service.SetLimit(context.Background(), 99, 2000)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestService_ResetLimit(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, 1000, slog.Default())
+	repo.On("DeleteLimit", mock.Anything, 1).Return(nil)
+	repo.On("Used", mock.Anything, 1).Return(int64(10), nil)
+	repo.On("GetLimit", mock.Anything, 1).Return(int64(0), false, nil)
+
+	usage, err := service.ResetLimit(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), usage.Limit)
+	assert.False(t, usage.Custom)
+}
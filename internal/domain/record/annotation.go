@@ -0,0 +1,114 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Пометки (meta.annotations) - датированные заметки к записи вроде «пароль
+// сменен после инцидента X». Текст пометки шифруется клиентом отдельно от
+// данных записи, а список только дополняется: пометки переходят из версии
+// в версию и могут добавляться даже к защищенной записи.
+
+// metaAnnotationsKey - ключ списка пометок в метаданных записи
+const metaAnnotationsKey = "annotations"
+
+// MaxAnnotationSize - предельный размер шифротекста одной пометки
+const MaxAnnotationSize = 8 * 1024
+
+// Annotation - пометка к записи
+type Annotation struct {
+	At time.Time `json:"at"`
+	// Data - зашифрованный текст пометки в base64
+	Data string `json:"data"`
+}
+
+// Annotations возвращает пометки из метаданных meta в порядке добавления
+func Annotations(meta json.RawMessage) []Annotation {
+	var m struct {
+		Annotations []Annotation `json:"annotations"`
+	}
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil {
+		return nil
+	}
+	return m.Annotations
+}
+
+// AddAnnotation возвращает метаданные с пометкой a в конце списка.
+// Остальные ключи метаданных сохраняются как есть.
+func AddAnnotation(meta json.RawMessage, a Annotation) (json.RawMessage, error) {
+	if a.Data == "" || len(a.Data) > MaxAnnotationSize {
+		return nil, fmt.Errorf("%w: annotation must be 1-%d bytes", ErrInvalidData, MaxAnnotationSize)
+	}
+	return WithAnnotations(meta, append(Annotations(meta), a))
+}
+
+// WithAnnotations возвращает метаданные meta со списком пометок annotations
+func WithAnnotations(meta json.RawMessage, annotations []Annotation) (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(meta) > 0 && string(meta) != "null" {
+		if err := json.Unmarshal(meta, &fields); err != nil {
+			return nil, fmt.Errorf("%w: meta is not a JSON object", ErrInvalidData)
+		}
+	}
+
+	if len(annotations) == 0 {
+		delete(fields, metaAnnotationsKey)
+		return json.Marshal(fields)
+	}
+
+	list, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, err
+	}
+	fields[metaAnnotationsKey] = list
+	return json.Marshal(fields)
+}
+
+// MergeAnnotations объединяет списки пометок двух версий записи без повторов,
+// упорядочивая их по времени добавления
+func MergeAnnotations(lists ...[]Annotation) []Annotation {
+	seen := make(map[Annotation]bool)
+	var merged []Annotation
+	for _, list := range lists {
+		for _, a := range list {
+			key := Annotation{At: a.At.UTC(), Data: a.Data}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, a)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].At.Before(merged[j].At) })
+	return merged
+}
+
+// onlyAnnotated сообщает, что updated отличается от current только новыми
+// пометками в конце списка
+func onlyAnnotated(current, updated json.RawMessage) bool {
+	before, after := Annotations(current), Annotations(updated)
+	if len(after) <= len(before) {
+		return false
+	}
+	for i := range before {
+		if !before[i].At.Equal(after[i].At) || before[i].Data != after[i].Data {
+			return false
+		}
+	}
+
+	return reflect.DeepEqual(withoutAnnotations(current), withoutAnnotations(updated))
+}
+
+// withoutAnnotations разбирает метаданные без списка пометок
+func withoutAnnotations(meta json.RawMessage) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if len(meta) > 0 {
+		_ = json.Unmarshal(meta, &fields)
+	}
+	delete(fields, metaAnnotationsKey)
+	return fields
+}
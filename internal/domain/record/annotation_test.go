@@ -0,0 +1,64 @@
+package record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotations(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	meta, err := AddAnnotation(json.RawMessage(`{"title":"root","locked":true}`), Annotation{At: at, Data: "Zmlyc3Q="})
+	require.NoError(t, err)
+	meta, err = AddAnnotation(meta, Annotation{At: at.Add(time.Hour), Data: "c2Vjb25k"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Annotation{{At: at, Data: "Zmlyc3Q="}, {At: at.Add(time.Hour), Data: "c2Vjb25k"}}, Annotations(meta))
+	assert.True(t, IsLocked(meta), "остальные ключи сохраняются")
+	assert.Empty(t, Annotations(json.RawMessage(`{"title":"root"}`)))
+
+	_, err = AddAnnotation(meta, Annotation{At: at})
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	cleared, err := WithAnnotations(meta, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"root","locked":true}`, string(cleared))
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	first := Annotation{At: at, Data: "a"}
+	local := Annotation{At: at.Add(2 * time.Hour), Data: "b"}
+	server := Annotation{At: at.Add(time.Hour), Data: "c"}
+
+	merged := MergeAnnotations([]Annotation{first, local}, []Annotation{first, server})
+	assert.Equal(t, []Annotation{first, server, local}, merged)
+	assert.Empty(t, MergeAnnotations(nil, nil))
+}
+
+func TestOnlyAnnotated(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	base, err := AddAnnotation(json.RawMessage(`{"title":"root"}`), Annotation{At: at, Data: "a"})
+	require.NoError(t, err)
+
+	appended, err := AddAnnotation(base, Annotation{At: at.Add(time.Hour), Data: "b"})
+	require.NoError(t, err)
+	assert.True(t, onlyAnnotated(base, appended))
+
+	// Изменение или удаление прежних пометок - это не дополнение
+	rewritten, err := WithAnnotations(base, []Annotation{{At: at, Data: "x"}, {At: at, Data: "b"}})
+	require.NoError(t, err)
+	assert.False(t, onlyAnnotated(base, rewritten))
+	assert.False(t, onlyAnnotated(appended, base))
+	assert.False(t, onlyAnnotated(base, base))
+
+	renamed, err := AddAnnotation(json.RawMessage(`{"title":"other"}`), Annotation{At: at, Data: "a"})
+	require.NoError(t, err)
+	renamed, err = AddAnnotation(renamed, Annotation{At: at.Add(time.Hour), Data: "b"})
+	require.NoError(t, err)
+	assert.False(t, onlyAnnotated(base, renamed))
+}
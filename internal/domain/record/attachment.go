@@ -0,0 +1,162 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+// Вложения - файлы, прикрепленные к записи любого типа (скан карты, PDF с
+// кодами восстановления). Имя файла и содержимое шифрует клиент. Вложения
+// хранятся отдельно от данных записи: не входят в ее версии и не передаются
+// при синхронизации, клиент загружает их по запросу. Окончательное удаление
+// записи удаляет и ее вложения.
+
+const (
+	// MaxAttachmentSize - наибольший размер зашифрованного вложения
+	MaxAttachmentSize = 25*1024*1024 + 4096
+	// MaxAttachments - сколько вложений можно прикрепить к одной записи
+	MaxAttachments = 20
+)
+
+var (
+	ErrAttachmentNotFound = apperr.New(apperr.NotFound, "attachment not found").WithCode("ATTACHMENT_NOT_FOUND")
+	ErrAttachmentTooLarge = apperr.New(apperr.Invalid, "attachment is too large").WithCode("ATTACHMENT_TOO_LARGE")
+	ErrTooManyAttachments = apperr.New(apperr.Conflict, "too many attachments").WithCode("TOO_MANY_ATTACHMENTS")
+)
+
+// Attachment - зашифрованный файл, прикрепленный к записи
+type Attachment struct {
+	ID       int `json:"id"`
+	RecordID int `json:"record_id"`
+	// UserID - загрузивший пользователь, вложение учитывается в его квоте
+	UserID int `json:"-"`
+	// EncryptedMeta - имя, тип и размер файла, зашифрованные клиентом
+	EncryptedMeta string    `json:"encrypted_meta"`
+	Size          int64     `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+	Data          []byte    `json:"-"`
+}
+
+// AttachmentRequest - новое вложение записи
+type AttachmentRequest struct {
+	EncryptedMeta string
+	Data          []byte
+}
+
+// ListAttachments returns attachments of a record without their data
+func (s *Service) ListAttachments(ctx context.Context, userID, recordID int) ([]Attachment, error) {
+	if _, err := s.getAttachable(ctx, userID, recordID, AccessRead); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.ListAttachments(ctx, recordID)
+	if err != nil {
+		s.log.Error("failed to list attachments", "record_id", recordID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// AddAttachment attaches an encrypted file to a record
+func (s *Service) AddAttachment(ctx context.Context, userID, recordID int, req AttachmentRequest) (*Attachment, error) {
+	if req.EncryptedMeta == "" || len(req.Data) == 0 {
+		return nil, ErrInvalidData
+	}
+	if len(req.Data) > MaxAttachmentSize {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	rec, err := s.getAttachable(ctx, userID, recordID, AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+	if IsLocked(rec.Meta) {
+		return nil, ErrRecordLocked
+	}
+
+	existing, err := s.repo.ListAttachments(ctx, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	if len(existing) >= MaxAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	size := int64(len(req.Data))
+	if err := s.checkQuota(ctx, userID, size); err != nil {
+		return nil, err
+	}
+
+	attachment := &Attachment{
+		RecordID:      recordID,
+		UserID:        userID,
+		EncryptedMeta: req.EncryptedMeta,
+		Size:          size,
+		Data:          req.Data,
+	}
+	if attachment.ID, err = s.repo.CreateAttachment(ctx, attachment); err != nil {
+		s.log.Error("failed to create attachment", "record_id", recordID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("create attachment: %w", err)
+	}
+
+	s.log.Info("attachment added", "record_id", recordID, "attachment_id", attachment.ID, "user_id", userID, "size", size)
+	return attachment, nil
+}
+
+// GetAttachment returns an attachment of a record with its data
+func (s *Service) GetAttachment(ctx context.Context, userID, recordID, attachmentID int) (*Attachment, error) {
+	if _, err := s.getAttachable(ctx, userID, recordID, AccessRead); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.repo.GetAttachment(ctx, recordID, attachmentID)
+	if err != nil {
+		if errors.Is(err, ErrAttachmentNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to get attachment", "record_id", recordID, "attachment_id", attachmentID, "error", err)
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment from a record
+func (s *Service) DeleteAttachment(ctx context.Context, userID, recordID, attachmentID int) error {
+	rec, err := s.getAttachable(ctx, userID, recordID, AccessWrite)
+	if err != nil {
+		return err
+	}
+	if IsLocked(rec.Meta) {
+		return ErrRecordLocked
+	}
+
+	if err := s.repo.DeleteAttachment(ctx, recordID, attachmentID); err != nil {
+		if errors.Is(err, ErrAttachmentNotFound) {
+			return err
+		}
+		s.log.Error("failed to delete attachment", "record_id", recordID, "attachment_id", attachmentID, "error", err)
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+
+	s.log.Info("attachment deleted", "record_id", recordID, "attachment_id", attachmentID, "user_id", userID)
+	return nil
+}
+
+// getAttachable returns a record whose attachments the user may access
+func (s *Service) getAttachable(ctx context.Context, userID, recordID int, access Access) (*Record, error) {
+	rec, err := s.getAccessible(ctx, userID, recordID, access)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("get record for attachments: %w", err)
+	}
+	if rec.DeletedAt != nil {
+		return nil, ErrRecordDeleted
+	}
+	return rec, nil
+}
@@ -0,0 +1,96 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestService_AddAttachment(t *testing.T) {
+	ctx := context.Background()
+	req := AttachmentRequest{EncryptedMeta: "meta", Data: []byte("0123456789")}
+	rec := &Record{ID: 1, UserID: 1, Type: RecTypeCard, Meta: json.RawMessage(`{"title":"visa"}`), Version: 1, LastModified: time.Now()}
+
+	t.Run("attaches file within quota", func(t *testing.T) {
+		repo := new(MockRepository)
+		quota := &stubQuota{free: 100}
+		service := NewService(repo, NewFactory(), nil, quota, slog.Default())
+
+		repo.On("Get", mock.Anything, 1, 1).Return(rec, nil)
+		repo.On("ListAttachments", mock.Anything, 1).Return([]Attachment{{ID: 1}}, nil)
+		repo.On("CreateAttachment", mock.Anything, mock.MatchedBy(func(a *Attachment) bool {
+			return a.RecordID == 1 && a.UserID == 1 && a.Size == 10 && a.EncryptedMeta == "meta"
+		})).Return(2, nil)
+
+		attachment, err := service.AddAttachment(ctx, 1, 1, req)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attachment.ID)
+		assert.Equal(t, []int64{10}, quota.checks)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("over quota is rejected", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, &stubQuota{free: 5}, slog.Default())
+
+		repo.On("Get", mock.Anything, 1, 1).Return(rec, nil)
+		repo.On("ListAttachments", mock.Anything, 1).Return(nil, nil)
+
+		_, err := service.AddAttachment(ctx, 1, 1, req)
+		assert.ErrorIs(t, err, errQuotaExceeded)
+		repo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("locked record is rejected", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		locked := *rec
+		locked.Meta = json.RawMessage(`{"title":"visa","locked":true}`)
+		repo.On("Get", mock.Anything, 1, 1).Return(&locked, nil)
+
+		_, err := service.AddAttachment(ctx, 1, 1, req)
+		assert.ErrorIs(t, err, ErrRecordLocked)
+		assert.ErrorIs(t, service.DeleteAttachment(ctx, 1, 1, 3), ErrRecordLocked)
+		repo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "DeleteAttachment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("attachment limit", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("Get", mock.Anything, 1, 1).Return(rec, nil)
+		repo.On("ListAttachments", mock.Anything, 1).Return(make([]Attachment, MaxAttachments), nil)
+
+		_, err := service.AddAttachment(ctx, 1, 1, req)
+		assert.ErrorIs(t, err, ErrTooManyAttachments)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		service := NewService(new(MockRepository), NewFactory(), nil, nil, slog.Default())
+
+		_, err := service.AddAttachment(ctx, 1, 1, AttachmentRequest{EncryptedMeta: "meta"})
+		assert.ErrorIs(t, err, ErrInvalidData)
+		_, err = service.AddAttachment(ctx, 1, 1, AttachmentRequest{EncryptedMeta: "meta", Data: make([]byte, MaxAttachmentSize+1)})
+		assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	})
+}
+
+func TestService_GetAttachment_OtherUser(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+	// Чужая запись неотличима от несуществующей, вложения не выдаются
+	repo.On("Get", mock.Anything, 2, 1).Return(nil, ErrNotFound)
+
+	_, err := service.GetAttachment(context.Background(), 2, 1, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	repo.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
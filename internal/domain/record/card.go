@@ -6,8 +6,24 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
+
+	"golang.org/x/exp/slog"
+)
+
+// Платежные системы карт (CardMeta.PaymentSystem)
+const (
+	PaymentSystemVisa       = "visa"
+	PaymentSystemMastercard = "mastercard"
+	PaymentSystemMir        = "mir"
+	PaymentSystemUnionPay   = "unionpay"
+	PaymentSystemAmex       = "amex"
+	PaymentSystemJCB        = "jcb"
 )
 
+// maxCardValidity - на сколько лет вперед может быть выпущена карта
+const maxCardValidity = 20
+
 // CardData - данные карты (до шифрования)
 type CardData struct {
 	CardNumber     string `json:"card_number"`
@@ -24,68 +40,190 @@ func (c *CardData) GetType() RecType {
 }
 
 func (c *CardData) Validate() error {
-	// Валидация номера карты (Luhn algorithm можно добавить)
 	if strings.TrimSpace(c.CardNumber) == "" {
 		return fmt.Errorf("card number is required")
 	}
 
-	// Очистка номера от пробелов и дефисов
-	cleaned := regexp.MustCompile(`[-\s]`).ReplaceAllString(c.CardNumber, "")
-	if len(cleaned) < 13 || len(cleaned) > 19 {
+	// Номер может быть записан группами через пробелы или дефисы
+	digits, ok := CardNumberDigits(c.CardNumber)
+	if !ok {
+		return fmt.Errorf("card number must contain only digits")
+	}
+	if len(digits) < 13 || len(digits) > 19 {
 		return fmt.Errorf("invalid card number length")
 	}
 
+	system := DetectPaymentSystem(digits)
+	// Часть карт UnionPay выпускается без контрольной цифры
+	if system != PaymentSystemUnionPay && !luhnValid(digits) {
+		return fmt.Errorf("invalid card number checksum")
+	}
+
 	if strings.TrimSpace(c.CardHolder) == "" {
 		return fmt.Errorf("card holder is required")
 	}
 
-	// Валидация срока действия
-	if !isValidExpiry(c.ExpiryMonth, c.ExpiryYear) {
-		return fmt.Errorf("invalid expiry date")
+	if err := validateExpiry(c.ExpiryMonth, c.ExpiryYear, time.Now()); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(c.CVV) == "" {
 		return fmt.Errorf("CVV is required")
 	}
-
-	if len(c.CVV) < 3 || len(c.CVV) > 4 {
+	if !isDigits(c.CVV) || len(c.CVV) < 3 || len(c.CVV) > 4 {
 		return fmt.Errorf("CVV must be 3 or 4 digits")
 	}
+	if system == PaymentSystemAmex && len(c.CVV) != 4 {
+		return fmt.Errorf("amex CVV must be 4 digits")
+	}
+
+	if c.PIN != "" && (!isDigits(c.PIN) || len(c.PIN) < 4 || len(c.PIN) > 12) {
+		return fmt.Errorf("PIN must be 4 to 12 digits")
+	}
 
 	return nil
 }
 
-func isValidExpiry(month, year string) bool {
+// validateExpiry проверяет срок действия карты: месяц 01-12, год 20XX,
+// срок не истек и не дальше maxCardValidity лет от now
+func validateExpiry(month, year string, now time.Time) error {
 	if month == "" || year == "" {
-		return false
+		return fmt.Errorf("expiry date is required")
 	}
 
-	// Проверяем что это числа
 	matchMonth, _ := regexp.MatchString(`^(0[1-9]|1[0-2])$`, month)
 	matchYear, _ := regexp.MatchString(`^20\d{2}$`, year)
-
 	if !matchMonth || !matchYear {
-		return false
+		return fmt.Errorf("invalid expiry date")
+	}
+
+	// Карта действует до конца указанного месяца
+	expiresAt, err := CardExpiresAt(month, year)
+	if err != nil {
+		return fmt.Errorf("invalid expiry date")
+	}
+	if !expiresAt.After(now) {
+		return fmt.Errorf("card is expired")
+	}
+	if expiresAt.After(now.AddDate(maxCardValidity, 1, 0)) {
+		return fmt.Errorf("expiry date is too far in the future")
+	}
+	return nil
+}
+
+// CardNumberDigits возвращает цифры номера карты без пробелов и дефисов;
+// false - в номере есть другие символы
+func CardNumberDigits(number string) (string, bool) {
+	var b strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || unicode.IsSpace(r):
+		default:
+			return "", false
+		}
 	}
+	return b.String(), true
+}
 
-	// Проверяем что срок не истек
-	now := time.Now()
-	currentYear := now.Year()
-	currentMonth := int(now.Month())
+// luhnValid проверяет контрольную цифру номера по алгоритму Луна
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
 
-	expYear := 0
-	expMonth := 0
-	_, _ = fmt.Sscanf(year, "%d", &expYear)
-	_, _ = fmt.Sscanf(month, "%d", &expMonth)
+// DetectPaymentSystem определяет платежную систему по первым цифрам номера
+// карты (BIN). Пусто - система не распознана или не поддерживается.
+func DetectPaymentSystem(number string) string {
+	digits, ok := CardNumberDigits(number)
+	if !ok || len(digits) < 4 {
+		return ""
+	}
+	prefix := func(n int) int {
+		v := 0
+		for _, r := range digits[:n] {
+			v = v*10 + int(r-'0')
+		}
+		return v
+	}
 
-	if expYear < currentYear {
-		return false
+	// Диапазоны проверяются от более узких к более широким
+	switch p4, p2 := prefix(4), prefix(2); {
+	case p4 >= 2200 && p4 <= 2204:
+		return PaymentSystemMir
+	case p4 >= 2221 && p4 <= 2720, p2 >= 51 && p2 <= 55:
+		return PaymentSystemMastercard
+	case p4 >= 3528 && p4 <= 3589:
+		return PaymentSystemJCB
+	case p2 == 34 || p2 == 37:
+		return PaymentSystemAmex
+	case p2 == 62:
+		return PaymentSystemUnionPay
+	case digits[0] == '4':
+		return PaymentSystemVisa
 	}
+	return ""
+}
 
-	if expYear == currentYear && expMonth < currentMonth {
-		return false
+// MaskCardNumber оставляет видимыми только последние 4 цифры номера:
+// "•••• 1234". Пусто - в номере меньше 4 цифр и показать нечего.
+func MaskCardNumber(number string) string {
+	var digits []rune
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) < 4 {
+		return ""
+	}
+	return "•••• " + string(digits[len(digits)-4:])
+}
+
+// Masked возвращает копию данных карты для вывода: от номера видны только
+// последние 4 цифры, CVV и PIN скрыты
+func (c CardData) Masked() CardData {
+	c.CardNumber = MaskCardNumber(c.CardNumber)
+	if c.CVV != "" {
+		c.CVV = "***"
+	}
+	if c.PIN != "" {
+		c.PIN = "****"
 	}
+	return c
+}
 
+// LogValue не пускает в журнал номер карты, CVV и PIN: записываются только
+// последние 4 цифры номера и платежная система
+func (c CardData) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("masked_number", MaskCardNumber(c.CardNumber)),
+		slog.String("payment_system", DetectPaymentSystem(c.CardNumber)),
+	)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
 	return true
 }
 
@@ -120,8 +258,8 @@ func (m *CardMeta) Validate() error {
 
 	// Валидация платежной системы
 	validSystems := map[string]bool{
-		"visa": true, "mastercard": true, "mir": true,
-		"unionpay": true, "amex": true, "jcb": true,
+		PaymentSystemVisa: true, PaymentSystemMastercard: true, PaymentSystemMir: true,
+		PaymentSystemUnionPay: true, PaymentSystemAmex: true, PaymentSystemJCB: true,
 	}
 
 	if m.PaymentSystem != "" && !validSystems[m.PaymentSystem] {
@@ -131,6 +269,19 @@ func (m *CardMeta) Validate() error {
 	return nil
 }
 
+// ApplyPaymentSystem заполняет платежную систему по номеру карты, если она
+// не указана, и проверяет, что указанная система совпадает с номером
+func (m *CardMeta) ApplyPaymentSystem(card *CardData) error {
+	detected := DetectPaymentSystem(card.CardNumber)
+	switch {
+	case m.PaymentSystem == "":
+		m.PaymentSystem = detected
+	case detected != "" && m.PaymentSystem != detected:
+		return fmt.Errorf("payment system %s does not match card number (%s)", m.PaymentSystem, detected)
+	}
+	return nil
+}
+
 func (m *CardMeta) ToJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
@@ -0,0 +1,109 @@
+package record
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func validCard() *CardData {
+	return &CardData{
+		CardNumber:  "4111 1111 1111 1111",
+		CardHolder:  "IVAN IVANOV",
+		ExpiryMonth: "12",
+		ExpiryYear:  strconv.Itoa(time.Now().Year() + 2),
+		CVV:         "123",
+	}
+}
+
+func TestCardData_Validate(t *testing.T) {
+	require.NoError(t, validCard().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(c *CardData)
+		err    string
+	}{
+		{"empty number", func(c *CardData) { c.CardNumber = " " }, "card number is required"},
+		{"letters", func(c *CardData) { c.CardNumber = "4111 1111 1111 111a" }, "only digits"},
+		{"short", func(c *CardData) { c.CardNumber = "4111 1111" }, "length"},
+		{"luhn", func(c *CardData) { c.CardNumber = "4111-1111-1111-1112" }, "checksum"},
+		{"holder", func(c *CardData) { c.CardHolder = "" }, "card holder is required"},
+		{"month", func(c *CardData) { c.ExpiryMonth = "13" }, "invalid expiry date"},
+		{"expired", func(c *CardData) { c.ExpiryYear = strconv.Itoa(time.Now().Year() - 1) }, "card is expired"},
+		{"too far", func(c *CardData) { c.ExpiryYear = strconv.Itoa(time.Now().Year() + 30) }, "too far"},
+		{"cvv letters", func(c *CardData) { c.CVV = "12a" }, "CVV must be 3 or 4 digits"},
+		{"amex cvv", func(c *CardData) { c.CardNumber = "3782 822463 10005" }, "amex CVV"},
+		{"pin", func(c *CardData) { c.PIN = "12" }, "PIN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := validCard()
+			tt.modify(card)
+			assert.ErrorContains(t, card.Validate(), tt.err)
+		})
+	}
+
+	// UnionPay допускает номера без контрольной цифры
+	card := validCard()
+	card.CardNumber = "6200 0000 0000 0001"
+	assert.NoError(t, card.Validate())
+}
+
+func TestDetectPaymentSystem(t *testing.T) {
+	tests := map[string]string{
+		"4111 1111 1111 1111": PaymentSystemVisa,
+		"5555555555554444":    PaymentSystemMastercard,
+		"2221000000000009":    PaymentSystemMastercard,
+		"2200 1234 5678 9010": PaymentSystemMir,
+		"3782 822463 10005":   PaymentSystemAmex,
+		"3530111333300000":    PaymentSystemJCB,
+		"6200000000000005":    PaymentSystemUnionPay,
+		"6011111111111117":    "",
+		"12":                  "",
+	}
+	for number, want := range tests {
+		assert.Equal(t, want, DetectPaymentSystem(number), number)
+	}
+}
+
+func TestCardMeta_ApplyPaymentSystem(t *testing.T) {
+	meta := &CardMeta{Title: "Card"}
+	require.NoError(t, meta.ApplyPaymentSystem(validCard()))
+	assert.Equal(t, PaymentSystemVisa, meta.PaymentSystem)
+
+	meta = &CardMeta{Title: "Card", PaymentSystem: PaymentSystemMir}
+	assert.ErrorContains(t, meta.ApplyPaymentSystem(validCard()), "does not match")
+}
+
+func TestCardData_Masking(t *testing.T) {
+	card := validCard()
+	card.PIN = "1234"
+
+	assert.Equal(t, "•••• 1111", MaskCardNumber(card.CardNumber))
+	assert.Empty(t, MaskCardNumber("12"))
+
+	masked := card.Masked()
+	assert.Equal(t, "•••• 1111", masked.CardNumber)
+	assert.Equal(t, "***", masked.CVV)
+	assert.Equal(t, "****", masked.PIN)
+	assert.Equal(t, card.CardHolder, masked.CardHolder)
+	assert.Equal(t, "4111 1111 1111 1111", card.CardNumber, "исходные данные не меняются")
+
+	var buf bytes.Buffer
+	opts := &slog.HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}
+	slog.New(slog.NewTextHandler(&buf, opts)).Info("card", "card", card)
+	assert.Contains(t, buf.String(), "1111")
+	assert.NotContains(t, buf.String(), "4111")
+	assert.NotContains(t, buf.String(), card.CVV)
+}
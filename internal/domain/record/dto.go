@@ -7,6 +7,7 @@ import (
 
 type Item struct {
 	ID           int             `json:"id"`
+	UUID         string          `json:"uuid,omitempty"`
 	Type         RecType         `json:"type"`
 	Meta         json.RawMessage `json:"meta"`
 	Version      int             `json:"version"`
@@ -17,3 +18,23 @@ type ListResponse struct {
 	Records []Item `json:"records"`
 	Total   int    `json:"total"`
 }
+
+// NewListResponse формирует список записей без зашифрованных данных
+func NewListResponse(records []Record) ListResponse {
+	items := make([]Item, len(records))
+	for i, r := range records {
+		items[i] = Item{
+			ID:           r.ID,
+			UUID:         r.UUID,
+			Type:         r.Type,
+			Meta:         r.Meta,
+			Version:      r.Version,
+			LastModified: r.LastModified,
+		}
+	}
+
+	return ListResponse{
+		Records: items,
+		Total:   len(items),
+	}
+}
@@ -1,12 +1,16 @@
 package record
 
-import (
-	"errors"
-)
+import "gophkeeper/internal/domain/apperr"
 
 var (
-	ErrNotFound        = errors.New("record not found")
-	ErrInvalidData     = errors.New("invalid record data")
-	ErrVersionConflict = errors.New("record version conflict")
-	ErrRecordDeleted   = errors.New("record was deleted")
+	ErrNotFound        = apperr.New(apperr.NotFound, "record not found").WithCode("RECORD_NOT_FOUND")
+	ErrInvalidData     = apperr.New(apperr.Invalid, "invalid record data").WithCode("INVALID_RECORD_DATA")
+	ErrVersionConflict = apperr.New(apperr.Conflict, "record version conflict").WithCode("VERSION_CONFLICT")
+	ErrRecordDeleted   = apperr.New(apperr.Gone, "record was deleted").WithCode("RECORD_DELETED")
+	ErrForbidden       = apperr.New(apperr.Forbidden, "access to record denied").WithCode("RECORD_FORBIDDEN")
+	ErrNotDeleted      = apperr.New(apperr.Conflict, "record is not in trash").WithCode("RECORD_NOT_IN_TRASH")
+	ErrRecordLocked    = apperr.New(apperr.Conflict, "record is locked").WithCode("RECORD_LOCKED")
+	// ErrDuplicateUUID возвращает хранилище, когда запись с тем же UUID
+	// создана параллельным запросом
+	ErrDuplicateUUID = apperr.New(apperr.Conflict, "record uuid already exists").WithCode("RECORD_UUID_EXISTS")
 )
@@ -0,0 +1,267 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Срок действия записи (meta.expires_at): окончание срока карты или
+// сертификата, дата плановой смены пароля. Срок хранится в открытых
+// метаданных, поэтому сервер находит истекающие записи без расшифровки.
+// ExpiryScanner периодически считает записи, срок которых истекает в
+// ближайшее окно, а клиент предупреждает о них при синхронизации.
+
+// metaExpiresAtKey - ключ срока действия в метаданных записи
+const metaExpiresAtKey = "expires_at"
+
+// ParseExpiry разбирает срок действия: RFC 3339, дату 2006-01-02 или месяц
+// 2006-01 и 01/26 (как на карте). Месяц действует до конца, поэтому срок -
+// начало следующего месяца. Даты без времени считаются в UTC.
+func ParseExpiry(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01", value); err == nil {
+		return t.AddDate(0, 1, 0), nil
+	}
+	if month, year, ok := strings.Cut(value, "/"); ok {
+		if t, err := CardExpiresAt(month, year); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: expiry must be RFC 3339, YYYY-MM-DD, YYYY-MM or MM/YY", ErrInvalidData)
+}
+
+// CardExpiresAt возвращает срок действия карты: начало месяца, следующего
+// за месяцем month года year (две или четыре цифры)
+func CardExpiresAt(month, year string) (time.Time, error) {
+	m, err := strconv.Atoi(strings.TrimSpace(month))
+	if err != nil || m < 1 || m > 12 {
+		return time.Time{}, fmt.Errorf("%w: invalid expiry month %q", ErrInvalidData, month)
+	}
+	year = strings.TrimSpace(year)
+	y, err := strconv.Atoi(year)
+	if err != nil || (len(year) != 2 && len(year) != 4) {
+		return time.Time{}, fmt.Errorf("%w: invalid expiry year %q", ErrInvalidData, year)
+	}
+	if len(year) == 2 {
+		y += 2000
+	}
+	return time.Date(y, time.Month(m), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0), nil
+}
+
+// ExpiresAt возвращает срок действия записи с метаданными meta.
+// Нет срока или он не разбирается - false.
+func ExpiresAt(meta json.RawMessage) (time.Time, bool) {
+	var m struct {
+		ExpiresAt *string `json:"expires_at"`
+	}
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil || m.ExpiresAt == nil || *m.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	t, err := ParseExpiry(*m.ExpiresAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// SetExpiresAt возвращает метаданные с установленным (at не nil) или снятым
+// сроком действия. Остальные ключи метаданных сохраняются как есть.
+func SetExpiresAt(meta json.RawMessage, at *time.Time) (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(meta) > 0 && string(meta) != "null" {
+		if err := json.Unmarshal(meta, &fields); err != nil {
+			return nil, fmt.Errorf("%w: meta is not a JSON object", ErrInvalidData)
+		}
+	}
+
+	if at == nil {
+		delete(fields, metaExpiresAtKey)
+	} else {
+		value, err := json.Marshal(at.UTC().Format(time.RFC3339))
+		if err != nil {
+			return nil, err
+		}
+		fields[metaExpiresAtKey] = value
+	}
+	return json.Marshal(fields)
+}
+
+// Expiration - запись, срок действия которой истек или скоро истекает
+type Expiration struct {
+	RecordID  int       `json:"record_id"`
+	UserID    int       `json:"-"`
+	Type      RecType   `json:"type"`
+	Title     string    `json:"title,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired сообщает, что срок уже истек к моменту now
+func (e Expiration) Expired(now time.Time) bool {
+	return !e.ExpiresAt.After(now)
+}
+
+// Expiring выбирает из records неудаленные записи со сроком действия раньше
+// before, включая уже истекшие, начиная с ближайшего срока
+func Expiring(records []Record, before time.Time) []Expiration {
+	var out []Expiration
+	for _, rec := range records {
+		if rec.DeletedAt != nil {
+			continue
+		}
+		at, ok := ExpiresAt(rec.Meta)
+		if !ok || !at.Before(before) {
+			continue
+		}
+
+		var m struct {
+			Title string `json:"title"`
+		}
+		_ = json.Unmarshal(rec.Meta, &m)
+		out = append(out, Expiration{RecordID: rec.ID, UserID: rec.UserID, Type: rec.Type, Title: m.Title, ExpiresAt: at})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].ExpiresAt.Before(out[j].ExpiresAt)
+	})
+	return out
+}
+
+// ListExpiring returns personal records of the user that expire within the
+// given period, including already expired ones
+func (s *Service) ListExpiring(ctx context.Context, userID int, within time.Duration) ([]Expiration, error) {
+	if within < 0 {
+		return nil, ErrInvalidData
+	}
+
+	records, err := s.repo.ListWithExpiry(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to list records with expiry", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list expiring records: %w", err)
+	}
+	return Expiring(records, time.Now().Add(within)), nil
+}
+
+// ExpiryConfig - параметры поиска истекающих записей
+type ExpiryConfig struct {
+	// Window - за сколько до срока запись считается истекающей
+	Window time.Duration
+	// Interval - период пересчета; 0 - задача выключена
+	Interval time.Duration
+}
+
+// DefaultExpiryConfig возвращает параметры по умолчанию
+func DefaultExpiryConfig() *ExpiryConfig {
+	return &ExpiryConfig{
+		Window:   30 * 24 * time.Hour,
+		Interval: 24 * time.Hour,
+	}
+}
+
+// Validate проверяет параметры
+func (c *ExpiryConfig) Validate() error {
+	if c.Window <= 0 {
+		return fmt.Errorf("expiry window must be positive")
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("expiry scan interval must not be negative")
+	}
+	return nil
+}
+
+// ExpiryScanner периодически находит записи всех пользователей, срок
+// действия которых истекает в окно Window, и сообщает о них в журнал
+type ExpiryScanner struct {
+	repo   Repository
+	config *ExpiryConfig
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+// NewExpiryScanner создает задачу поиска истекающих записей
+func NewExpiryScanner(repo Repository, config *ExpiryConfig, log *slog.Logger) *ExpiryScanner {
+	if config == nil {
+		config = DefaultExpiryConfig()
+	}
+
+	return &ExpiryScanner{
+		repo:   repo,
+		config: config,
+		log:    log.With("component", "expiry"),
+		now:    time.Now,
+	}
+}
+
+// Run запускает поиск по расписанию до отмены контекста
+func (s *ExpiryScanner) Run(ctx context.Context) {
+	if s.config.Interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	s.log.Info("scheduled expiry scan started", "window", s.config.Window, "interval", s.config.Interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Scan(ctx); err != nil {
+				s.log.Error("scheduled expiry scan failed", "error", err)
+			}
+		}
+	}
+}
+
+// Scan возвращает записи, срок действия которых истекает в окно Window,
+// и пишет в журнал сводку по каждому пользователю
+func (s *ExpiryScanner) Scan(ctx context.Context) ([]Expiration, error) {
+	records, err := s.repo.ListAllWithExpiry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list records with expiry: %w", err)
+	}
+
+	now := s.now()
+	expiring := Expiring(records, now.Add(s.config.Window))
+
+	type summary struct {
+		expiring, expired int
+		next              time.Time
+	}
+	byUser := make(map[int]*summary)
+	for _, e := range expiring {
+		sum, ok := byUser[e.UserID]
+		if !ok {
+			sum = &summary{}
+			byUser[e.UserID] = sum
+		}
+		if e.Expired(now) {
+			sum.expired++
+			continue
+		}
+		if sum.expiring == 0 {
+			sum.next = e.ExpiresAt
+		}
+		sum.expiring++
+	}
+	for userID, sum := range byUser {
+		s.log.Info("records expiring soon", "user_id", userID,
+			"expiring", sum.expiring, "expired", sum.expired, "next_expires_at", sum.next)
+	}
+
+	return expiring, nil
+}
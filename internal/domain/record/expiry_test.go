@@ -0,0 +1,131 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-03-31T12:00:00+03:00", time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"2026-03-31", time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"2026-03", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"12/26", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{" 03/2026 ", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseExpiry(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.True(t, tt.want.Equal(got), "%s: %s", tt.value, got)
+	}
+
+	for _, bad := range []string{"", "soon", "13/26", "2026-13-01", "3/2"} {
+		_, err := ParseExpiry(bad)
+		assert.ErrorIs(t, err, ErrInvalidData, bad)
+	}
+}
+
+func TestSetExpiresAt(t *testing.T) {
+	at := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	meta, err := SetExpiresAt(json.RawMessage(`{"title":"cert","locked":true}`), &at)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"cert","locked":true,"expires_at":"2026-03-31T00:00:00Z"}`, string(meta))
+
+	got, ok := ExpiresAt(meta)
+	require.True(t, ok)
+	assert.True(t, at.Equal(got))
+
+	meta, err = SetExpiresAt(meta, nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"cert","locked":true}`, string(meta))
+	_, ok = ExpiresAt(meta)
+	assert.False(t, ok)
+
+	_, ok = ExpiresAt(json.RawMessage(`{"expires_at":"someday"}`))
+	assert.False(t, ok)
+	_, err = SetExpiresAt(json.RawMessage(`[]`), &at)
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestExpiring(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	deleted := now
+	records := []Record{
+		{ID: 1, Type: RecTypeCard, Meta: json.RawMessage(`{"title":"Visa","expires_at":"2026-02-01T00:00:00Z"}`)},
+		{ID: 2, Type: RecTypeLogin, Meta: json.RawMessage(`{"title":"Old","expires_at":"2026-01-01"}`)},
+		{ID: 3, Type: RecTypeText, Meta: json.RawMessage(`{"title":"Later","expires_at":"2027-01-01"}`)},
+		{ID: 4, Type: RecTypeText, Meta: json.RawMessage(`{"title":"None"}`)},
+		{ID: 5, Type: RecTypeText, Meta: json.RawMessage(`{"expires_at":"2026-01-05"}`), DeletedAt: &deleted},
+	}
+
+	expiring := Expiring(records, now.Add(30*24*time.Hour))
+	require.Len(t, expiring, 2)
+	assert.Equal(t, 2, expiring[0].RecordID)
+	assert.True(t, expiring[0].Expired(now))
+	assert.Equal(t, Expiration{RecordID: 1, Type: RecTypeCard, Title: "Visa", ExpiresAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}, expiring[1])
+	assert.False(t, expiring[1].Expired(now))
+}
+
+func TestService_ListExpiring(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+	soon := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	repo.On("ListWithExpiry", mock.Anything, 1).Return([]Record{
+		{ID: 7, UserID: 1, Type: RecTypeLogin, Meta: json.RawMessage(`{"title":"db","expires_at":"` + soon + `"}`)},
+	}, nil)
+
+	expiring, err := service.ListExpiring(context.Background(), 1, 7*24*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, 7, expiring[0].RecordID)
+
+	expiring, err = service.ListExpiring(context.Background(), 1, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	_, err = service.ListExpiring(context.Background(), 1, -time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestExpiryScanner_Scan(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	repo := new(MockRepository)
+	scanner := NewExpiryScanner(repo, &ExpiryConfig{Window: 7 * 24 * time.Hour, Interval: time.Hour}, slog.Default())
+	scanner.now = func() time.Time { return now }
+
+	repo.On("ListAllWithExpiry", mock.Anything).Return([]Record{
+		{ID: 1, UserID: 1, Meta: json.RawMessage(`{"expires_at":"2026-01-12"}`)},
+		{ID: 2, UserID: 2, Meta: json.RawMessage(`{"expires_at":"2026-01-01"}`)},
+		{ID: 3, UserID: 2, Meta: json.RawMessage(`{"expires_at":"2026-03-01"}`)},
+	}, nil).Once()
+
+	expiring, err := scanner.Scan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, expiring, 2)
+	assert.Equal(t, []int{2, 1}, []int{expiring[0].RecordID, expiring[1].RecordID})
+
+	repo.On("ListAllWithExpiry", mock.Anything).Return(nil, errors.New("db down")).Once()
+	_, err = scanner.Scan(context.Background())
+	assert.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestExpiryConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultExpiryConfig().Validate())
+	assert.NoError(t, (&ExpiryConfig{Window: time.Hour}).Validate())
+	assert.Error(t, (&ExpiryConfig{}).Validate())
+	assert.Error(t, (&ExpiryConfig{Window: time.Hour, Interval: -time.Hour}).Validate())
+}
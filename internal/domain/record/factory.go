@@ -24,6 +24,10 @@ func (f *Factory) CreateData(typ RecType) (Data, error) {
 		return &BinaryData{}, nil
 	case RecTypeCard:
 		return &CardData{}, nil
+	case RecTypeOTP:
+		return &OTPData{}, nil
+	case RecTypeSSHKey:
+		return &SSHKeyData{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported record type: %s", typ)
 	}
@@ -40,6 +44,10 @@ func (f *Factory) CreateMeta(typ RecType) (MetaData, error) {
 		return &BinaryMeta{}, nil
 	case RecTypeCard:
 		return &CardMeta{}, nil
+	case RecTypeOTP:
+		return &OTPMeta{}, nil
+	case RecTypeSSHKey:
+		return &SSHKeyMeta{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported record type: %s", typ)
 	}
@@ -151,6 +159,10 @@ func (f *Factory) GetDefaultMeta(typ RecType) (MetaData, error) {
 		m.Category = "Карты"
 		m.IsActive = true
 		m.IsVirtual = false
+	case *OTPMeta:
+		m.Category = "2FA"
+	case *SSHKeyMeta:
+		m.Category = "SSH"
 	}
 
 	return meta, nil
@@ -0,0 +1,57 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Защищенная запись (meta.locked = true) не изменяется и не удаляется, пока
+// защиту явно не снимут. Признак хранится в открытых метаданных, поэтому
+// синхронизируется между устройствами вместе с записью.
+
+// metaLockedKey - ключ признака защиты в метаданных записи
+const metaLockedKey = "locked"
+
+// IsLocked сообщает, защищена ли запись с метаданными meta от изменений
+func IsLocked(meta json.RawMessage) bool {
+	var m struct {
+		Locked bool `json:"locked"`
+	}
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil {
+		return false
+	}
+	return m.Locked
+}
+
+// SetLocked возвращает метаданные с установленным или снятым признаком защиты.
+// Остальные ключи метаданных сохраняются как есть.
+func SetLocked(meta json.RawMessage, locked bool) (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(meta) > 0 && string(meta) != "null" {
+		if err := json.Unmarshal(meta, &fields); err != nil {
+			return nil, fmt.Errorf("%w: meta is not a JSON object", ErrInvalidData)
+		}
+	}
+
+	if locked {
+		fields[metaLockedKey] = json.RawMessage("true")
+	} else {
+		delete(fields, metaLockedKey)
+	}
+	return json.Marshal(fields)
+}
+
+// checkUnlocked запрещает изменять защищенную запись current. Допустимы только
+// снятие защиты и новые пометки без правки данных записи.
+func checkUnlocked(current *Record, encryptedData string, meta json.RawMessage) error {
+	if !IsLocked(current.Meta) {
+		return nil
+	}
+	if encryptedData != current.EncryptedData {
+		return ErrRecordLocked
+	}
+	if IsLocked(meta) && !onlyAnnotated(current.Meta, meta) {
+		return ErrRecordLocked
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package record
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
 type Record struct {
 	ID            int             `json:"id"`
+	UUID          string          `json:"uuid,omitempty"` // постоянный идентификатор записи
 	UserID        int             `json:"user_id"`
 	Type          RecType         `json:"type"`
 	EncryptedData string          `json:"encrypted_data,omitempty"`
@@ -16,6 +18,46 @@ type Record struct {
 	DeletedAt     *time.Time      `json:"deleted_at,omitempty"`
 	Checksum      string          `json:"checksum,omitempty"`
 	DeviceID      string          `json:"device_id,omitempty"`
+	OrgID         *int            `json:"org_id,omitempty"` // запись хранилища организации
+}
+
+// Head - версия записи без данных. Клиент сверяет ее с кэшем, чтобы
+// не загружать запись целиком (HEAD /api/records/{id}).
+type Head struct {
+	ID           int
+	UserID       int
+	OrgID        *int
+	Version      int
+	Checksum     string
+	LastModified time.Time
+	DeletedAt    *time.Time
+	Size         int64
+}
+
+// Head возвращает версию записи без данных
+func (r *Record) Head() *Head {
+	return &Head{
+		ID:           r.ID,
+		UserID:       r.UserID,
+		OrgID:        r.OrgID,
+		Version:      r.Version,
+		Checksum:     r.Checksum,
+		LastModified: r.LastModified,
+		DeletedAt:    r.DeletedAt,
+		Size:         int64(len(r.EncryptedData)),
+	}
+}
+
+// ETag возвращает ETag записи: меняется при каждом изменении версии или данных
+func (h *Head) ETag() string {
+	if h.Checksum == "" {
+		return fmt.Sprintf(`"%d"`, h.Version)
+	}
+	checksum := h.Checksum
+	if len(checksum) > 16 {
+		checksum = checksum[:16]
+	}
+	return fmt.Sprintf(`"%d-%s"`, h.Version, checksum)
 }
 
 type BaseRecord struct {
@@ -65,8 +107,39 @@ type BatchUpdate struct {
 type SearchCriteria struct {
 	Type      string
 	MetaQuery json.RawMessage
-	FromDate  *time.Time
-	ToDate    *time.Time
-	Limit     int
-	Offset    int
+	// Query - подстрока в названии или ресурсе записи, без учета регистра
+	Query    string
+	Title    string   // подстрока в названии
+	Tags     []string // запись должна содержать все перечисленные теги
+	Category string   // точное совпадение категории
+	Resource string   // подстрока в ресурсе (URL или хост логина)
+	// Folder - ID папки: записи в ней и во вложенных папках
+	Folder   int
+	FromDate *time.Time
+	ToDate   *time.Time
+	Limit    int
+	Offset   int
+}
+
+// HasMetaFilters проверяет, заданы ли фильтры по метаданным
+func (c SearchCriteria) HasMetaFilters() bool {
+	return c.Query != "" || c.Title != "" || len(c.Tags) > 0 ||
+		c.Category != "" || c.Resource != "" || c.Folder > 0 || len(c.MetaQuery) > 0
+}
+
+// MetaContains возвращает JSON-документ для проверки вхождения в meta
+// (оператор @> в Postgres) по точным фильтрам: тегам и категории.
+// Возвращает nil, если точных фильтров нет.
+func (c SearchCriteria) MetaContains() (json.RawMessage, error) {
+	doc := make(map[string]interface{})
+	if len(c.Tags) > 0 {
+		doc["tags"] = c.Tags
+	}
+	if c.Category != "" {
+		doc["category"] = c.Category
+	}
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(doc)
 }
@@ -0,0 +1,129 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Access уровень доступа к записи хранилища организации
+type Access int
+
+const (
+	AccessRead Access = iota
+	AccessWrite
+)
+
+// OrgAuthorizer проверяет права пользователя на хранилище организации.
+// Authorize возвращает ErrNotFound, если пользователь не активный участник
+// (существование хранилища не раскрывается), и ErrForbidden, если роли недостаточно.
+type OrgAuthorizer interface {
+	Authorize(ctx context.Context, orgID, userID int, access Access) error
+}
+
+// getAccessible возвращает личную запись пользователя или запись хранилища
+// организации, если у пользователя есть доступ нужного уровня
+func (s *Service) getAccessible(ctx context.Context, userID, recordID int, access Access) (*Record, error) {
+	rec, err := s.repo.Get(ctx, userID, recordID)
+	if err == nil || !errors.Is(err, ErrNotFound) || s.orgs == nil {
+		return rec, err
+	}
+
+	rec, err = s.repo.GetShared(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.orgs.Authorize(ctx, *rec.OrgID, userID, access); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// authorizeHead checks access to a record found by GetHead, which also returns
+// deleted records and records of other users
+func (s *Service) authorizeHead(ctx context.Context, head *Head, userID int, access Access) error {
+	if head.OrgID == nil {
+		// Чужая личная запись неотличима от несуществующей
+		if head.UserID != userID {
+			return ErrNotFound
+		}
+		return nil
+	}
+
+	if s.orgs == nil {
+		return ErrNotFound
+	}
+	return s.orgs.Authorize(ctx, *head.OrgID, userID, access)
+}
+
+// ListOrg returns records of an organization vault
+func (s *Service) ListOrg(ctx context.Context, userID, orgID int) (ListResponse, error) {
+	if err := s.authorizeOrg(ctx, orgID, userID, AccessRead); err != nil {
+		return ListResponse{}, err
+	}
+
+	records, err := s.repo.ListByOrg(ctx, orgID)
+	if err != nil {
+		s.log.Error("failed to list org records", "org_id", orgID, "user_id", userID, "error", err)
+		return ListResponse{}, fmt.Errorf("list org records: %w", err)
+	}
+
+	return NewListResponse(records), nil
+}
+
+// CreateInOrg creates a record in an organization vault.
+// Data must be encrypted with the vault key, not the user's master key.
+func (s *Service) CreateInOrg(ctx context.Context, userID, orgID int, req CreateRequest) (int, error) {
+	if req.Type == "" || req.EncryptedData == "" {
+		return -1, ErrInvalidData
+	}
+
+	if err := s.authorizeOrg(ctx, orgID, userID, AccessWrite); err != nil {
+		return -1, err
+	}
+
+	recordUUID, existing, err := s.claimUUID(ctx, userID, req.UUID)
+	if err != nil {
+		return -1, err
+	}
+	if existing != nil {
+		return existing.ID, nil
+	}
+
+	// Записи хранилища учитываются в квоте автора
+	if err := s.checkQuota(ctx, userID, int64(len(req.EncryptedData))); err != nil {
+		return -1, err
+	}
+
+	record := &Record{
+		UUID:          recordUUID,
+		UserID:        userID,
+		OrgID:         &orgID,
+		Type:          req.Type,
+		EncryptedData: req.EncryptedData,
+		Meta:          req.Meta,
+		Checksum:      s.generateChecksum(req.EncryptedData, req.Type, req.Meta),
+		Version:       1,
+		LastModified:  time.Now(),
+		DeviceID:      req.DeviceID,
+	}
+
+	created, err := s.createOnce(ctx, record)
+	if err != nil {
+		s.log.Error("failed to create org record", "org_id", orgID, "user_id", userID, "error", err)
+		return -1, fmt.Errorf("create org record: %w", err)
+	}
+
+	s.log.Info("org record created", "record_id", created.ID, "org_id", orgID, "user_id", userID, "type", req.Type)
+	return created.ID, nil
+}
+
+func (s *Service) authorizeOrg(ctx context.Context, orgID, userID int, access Access) error {
+	if s.orgs == nil {
+		return ErrNotFound
+	}
+	return s.orgs.Authorize(ctx, orgID, userID, access)
+}
@@ -0,0 +1,157 @@
+package record
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOTPDigits = 6
+	defaultOTPPeriod = 30
+)
+
+// OTPData - секрет TOTP (до шифрования)
+type OTPData struct {
+	Secret    string `json:"secret"`              // base32, как в otpauth:// URI
+	Algorithm string `json:"algorithm,omitempty"` // SHA1, SHA256, SHA512
+	Digits    int    `json:"digits,omitempty"`    // 6 или 8
+	Period    int    `json:"period,omitempty"`    // шаг в секундах
+}
+
+func (o *OTPData) GetType() RecType {
+	return RecTypeOTP
+}
+
+func (o *OTPData) Validate() error {
+	if strings.TrimSpace(o.Secret) == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	if _, err := o.key(); err != nil {
+		return fmt.Errorf("secret must be valid base32: %w", err)
+	}
+
+	if _, err := o.hash(); err != nil {
+		return err
+	}
+
+	if o.Digits != 0 && o.Digits != 6 && o.Digits != 8 {
+		return fmt.Errorf("digits must be 6 or 8")
+	}
+
+	if o.Period < 0 {
+		return fmt.Errorf("period must be positive")
+	}
+
+	return nil
+}
+
+func (o *OTPData) ToJSON() ([]byte, error) {
+	return json.Marshal(o)
+}
+
+func (o *OTPData) FromJSON(data []byte) error {
+	return json.Unmarshal(data, o)
+}
+
+// Code генерирует TOTP-код (RFC 6238) для указанного момента времени
+func (o *OTPData) Code(t time.Time) (string, error) {
+	key, err := o.key()
+	if err != nil {
+		return "", fmt.Errorf("invalid secret: %w", err)
+	}
+
+	newHash, err := o.hash()
+	if err != nil {
+		return "", err
+	}
+
+	digits := o.Digits
+	if digits == 0 {
+		digits = defaultOTPDigits
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix())/uint64(o.period()))
+
+	mac := hmac.New(newHash, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Динамическое усечение (RFC 4226, раздел 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod), nil
+}
+
+// Remaining возвращает время до смены кода
+func (o *OTPData) Remaining(t time.Time) time.Duration {
+	period := int64(o.period())
+	return time.Duration(period-t.Unix()%period) * time.Second
+}
+
+func (o *OTPData) period() int {
+	if o.Period <= 0 {
+		return defaultOTPPeriod
+	}
+	return o.Period
+}
+
+func (o *OTPData) key() ([]byte, error) {
+	secret := strings.ToUpper(strings.ReplaceAll(o.Secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	return base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+}
+
+func (o *OTPData) hash() (func() hash.Hash, error) {
+	switch strings.ToUpper(o.Algorithm) {
+	case "", "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", o.Algorithm)
+	}
+}
+
+// OTPMeta - метаданные TOTP
+type OTPMeta struct {
+	Title       string          `json:"title"`
+	Issuer      string          `json:"issuer,omitempty"`
+	AccountName string          `json:"account_name,omitempty"`
+	Category    string          `json:"category,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	CustomData  json.RawMessage `json:"custom_data,omitempty"`
+}
+
+func (m *OTPMeta) Validate() error {
+	if strings.TrimSpace(m.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	return nil
+}
+
+func (m *OTPMeta) ToJSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+func (m *OTPMeta) FromJSON(data []byte) error {
+	return json.Unmarshal(data, m)
+}
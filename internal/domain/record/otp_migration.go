@@ -0,0 +1,330 @@
+package record
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Формат otpauth-migration://offline?data=... - QR-коды переноса Google
+// Authenticator. Параметр data - base64 от сообщения protobuf MigrationPayload:
+//
+//	message MigrationPayload {
+//	  repeated OtpParameters otp_parameters = 1;
+//	  int32 version = 2; int32 batch_size = 3; int32 batch_index = 4; int32 batch_id = 5;
+//	}
+//	message OtpParameters {
+//	  bytes secret = 1; string name = 2; string issuer = 3;
+//	  Algorithm algorithm = 4; DigitCount digits = 5; OtpType type = 6; int64 counter = 7;
+//	}
+//
+// Период в формате не передается: Google Authenticator поддерживает только 30 секунд.
+
+const (
+	OTPMigrationScheme = "otpauth-migration"
+	// OTPMigrationBatchSize - число секретов в одном QR-коде экспорта,
+	// как у Google Authenticator: больше не помещается в читаемый QR-код
+	OTPMigrationBatchSize = 10
+
+	otpMigrationVersion = 1
+
+	migrationAlgoSHA1   = 1
+	migrationAlgoSHA256 = 2
+	migrationAlgoSHA512 = 3
+	migrationAlgoMD5    = 4
+
+	migrationDigitsSix   = 1
+	migrationDigitsEight = 2
+
+	migrationTypeHOTP = 1
+	migrationTypeTOTP = 2
+)
+
+// ErrOTPNotMigratable - секрет нельзя передать в формате otpauth-migration
+var ErrOTPNotMigratable = errors.New("otp is not supported by otpauth-migration")
+
+// OTPAccount - секрет TOTP с подписью из QR-кода переноса
+type OTPAccount struct {
+	OTPData
+	Issuer      string
+	AccountName string
+}
+
+// OTPMigrationBatch - содержимое одного QR-кода переноса
+type OTPMigrationBatch struct {
+	Accounts []OTPAccount
+	// Skipped - записи, которые не являются TOTP (HOTP) или используют
+	// неподдерживаемый алгоритм, с причиной
+	Skipped    []string
+	BatchSize  int
+	BatchIndex int
+	BatchID    int
+}
+
+// ParseOTPMigrationURI разбирает адрес otpauth-migration://offline?data=...
+func ParseOTPMigrationURI(raw string) (*OTPMigrationBatch, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration uri: %w", err)
+	}
+	if u.Scheme != OTPMigrationScheme || u.Host != "offline" {
+		return nil, fmt.Errorf("not an otpauth-migration://offline uri")
+	}
+
+	data := u.Query().Get("data")
+	if data == "" {
+		return nil, fmt.Errorf("migration uri has no data")
+	}
+	// Неэкранированный "+" в запросе превращается в пробел
+	data = strings.ReplaceAll(data, " ", "+")
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if payload, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+			return nil, fmt.Errorf("migration data must be base64: %w", err)
+		}
+	}
+
+	return decodeMigrationPayload(payload)
+}
+
+// OTPMigrationURIs кодирует секреты в адреса otpauth-migration, по
+// OTPMigrationBatchSize секретов в каждом. batchID связывает части одного
+// экспорта. Секреты с периодом, отличным от 30 секунд, передать нельзя.
+func OTPMigrationURIs(accounts []OTPAccount, batchID int) ([]string, error) {
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	batches := (len(accounts) + OTPMigrationBatchSize - 1) / OTPMigrationBatchSize
+	uris := make([]string, 0, batches)
+	for i := 0; i < batches; i++ {
+		end := min((i+1)*OTPMigrationBatchSize, len(accounts))
+
+		var payload []byte
+		for _, acc := range accounts[i*OTPMigrationBatchSize : end] {
+			params, err := encodeOTPParameters(acc)
+			if err != nil {
+				return nil, err
+			}
+			payload = appendBytesField(payload, 1, params)
+		}
+		payload = appendVarintField(payload, 2, otpMigrationVersion)
+		payload = appendVarintField(payload, 3, uint64(batches))
+		payload = appendVarintField(payload, 4, uint64(i))
+		payload = appendVarintField(payload, 5, uint64(uint32(batchID)))
+
+		query := url.Values{"data": {base64.StdEncoding.EncodeToString(payload)}}
+		uris = append(uris, OTPMigrationScheme+"://offline?"+query.Encode())
+	}
+	return uris, nil
+}
+
+func encodeOTPParameters(acc OTPAccount) ([]byte, error) {
+	if err := acc.Validate(); err != nil {
+		return nil, err
+	}
+	if acc.period() != defaultOTPPeriod {
+		return nil, fmt.Errorf("%w: period %d", ErrOTPNotMigratable, acc.Period)
+	}
+	key, err := acc.key()
+	if err != nil {
+		return nil, err
+	}
+
+	var algo uint64
+	switch strings.ToUpper(acc.Algorithm) {
+	case "", "SHA1":
+		algo = migrationAlgoSHA1
+	case "SHA256":
+		algo = migrationAlgoSHA256
+	case "SHA512":
+		algo = migrationAlgoSHA512
+	}
+	digits := uint64(migrationDigitsSix)
+	if acc.Digits == 8 {
+		digits = migrationDigitsEight
+	}
+
+	name := acc.AccountName
+	if name == "" {
+		name = acc.Issuer
+	}
+
+	var params []byte
+	params = appendBytesField(params, 1, key)
+	params = appendBytesField(params, 2, []byte(name))
+	params = appendBytesField(params, 3, []byte(acc.Issuer))
+	params = appendVarintField(params, 4, algo)
+	params = appendVarintField(params, 5, digits)
+	params = appendVarintField(params, 6, migrationTypeTOTP)
+	return params, nil
+}
+
+func decodeMigrationPayload(payload []byte) (*OTPMigrationBatch, error) {
+	batch := &OTPMigrationBatch{}
+	err := readProtoFields(payload, func(num int, varint uint64, data []byte) error {
+		switch num {
+		case 1:
+			acc, err := decodeOTPParameters(data)
+			if err != nil {
+				if errors.Is(err, ErrOTPNotMigratable) {
+					batch.Skipped = append(batch.Skipped, err.Error())
+					return nil
+				}
+				return err
+			}
+			batch.Accounts = append(batch.Accounts, *acc)
+		case 3:
+			batch.BatchSize = int(varint)
+		case 4:
+			batch.BatchIndex = int(varint)
+		case 5:
+			batch.BatchID = int(int32(varint))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration payload: %w", err)
+	}
+	return batch, nil
+}
+
+func decodeOTPParameters(data []byte) (*OTPAccount, error) {
+	var (
+		secret       []byte
+		name, issuer string
+		algo, digits uint64
+		otpType      uint64
+	)
+	err := readProtoFields(data, func(num int, varint uint64, data []byte) error {
+		switch num {
+		case 1:
+			secret = data
+		case 2:
+			name = string(data)
+		case 3:
+			issuer = string(data)
+		case 4:
+			algo = varint
+		case 5:
+			digits = varint
+		case 6:
+			otpType = varint
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Имя в Google Authenticator обычно имеет вид "Issuer:account"
+	account := name
+	if prefix, rest, ok := strings.Cut(name, ":"); ok && (issuer == "" || prefix == issuer) {
+		account = strings.TrimSpace(rest)
+		if issuer == "" {
+			issuer = prefix
+		}
+	}
+
+	label := account
+	if label == "" {
+		label = issuer
+	}
+	if otpType == migrationTypeHOTP {
+		return nil, fmt.Errorf("%w: %q is HOTP", ErrOTPNotMigratable, label)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("otp %q has no secret", label)
+	}
+
+	acc := &OTPAccount{
+		OTPData: OTPData{
+			Secret: base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+			Digits: defaultOTPDigits,
+		},
+		Issuer:      issuer,
+		AccountName: account,
+	}
+	switch algo {
+	case 0, migrationAlgoSHA1:
+		acc.Algorithm = "SHA1"
+	case migrationAlgoSHA256:
+		acc.Algorithm = "SHA256"
+	case migrationAlgoSHA512:
+		acc.Algorithm = "SHA512"
+	case migrationAlgoMD5:
+		return nil, fmt.Errorf("%w: %q uses MD5", ErrOTPNotMigratable, label)
+	default:
+		return nil, fmt.Errorf("%w: %q uses unknown algorithm %d", ErrOTPNotMigratable, label, algo)
+	}
+	if digits == migrationDigitsEight {
+		acc.Digits = 8
+	}
+	return acc, nil
+}
+
+// readProtoFields обходит поля сообщения protobuf. Для полей varint
+// передается значение, для полей с длиной - содержимое; поля фиксированной
+// длины пропускаются.
+func readProtoFields(buf []byte, fn func(num int, varint uint64, data []byte) error) error {
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return fmt.Errorf("malformed field tag")
+		}
+		buf = buf[n:]
+		num, wire := int(tag>>3), tag&7
+
+		var (
+			varint uint64
+			data   []byte
+		)
+		switch wire {
+		case 0:
+			varint, n = binary.Uvarint(buf)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", num)
+			}
+			buf = buf[n:]
+		case 1, 5:
+			size := 8
+			if wire == 5 {
+				size = 4
+			}
+			if len(buf) < size {
+				return fmt.Errorf("truncated field %d", num)
+			}
+			buf = buf[size:]
+			continue
+		case 2:
+			size, n := binary.Uvarint(buf)
+			if n <= 0 || size > uint64(len(buf)-n) {
+				return fmt.Errorf("truncated field %d", num)
+			}
+			data = buf[n : n+int(size)]
+			buf = buf[n+int(size):]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wire, num)
+		}
+
+		if err := fn(num, varint, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendVarintField(buf []byte, num int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(num)<<3)
+	return binary.AppendUvarint(buf, v)
+}
+
+func appendBytesField(buf []byte, num int, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(num)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
@@ -0,0 +1,97 @@
+package record
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOTPMigrationURI(t *testing.T) {
+	// Пример экспорта Google Authenticator: секрет "Hello!\xde\xad\xbe\xef",
+	// имя "Example:alice@google.com", SHA1, 6 цифр, TOTP
+	const uri = "otpauth-migration://offline?data=CjEKCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZSABKAEwAhABGAEgACgA"
+
+	batch, err := ParseOTPMigrationURI(uri)
+	require.NoError(t, err)
+	require.Len(t, batch.Accounts, 1)
+	assert.Equal(t, OTPAccount{
+		OTPData:     OTPData{Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6},
+		Issuer:      "Example",
+		AccountName: "alice@google.com",
+	}, batch.Accounts[0])
+	assert.Equal(t, 1, batch.BatchSize)
+	assert.Equal(t, 0, batch.BatchIndex)
+	assert.Empty(t, batch.Skipped)
+
+	// Неэкранированный "+" из base64 разбирается так же, как %2B
+	uris, err := OTPMigrationURIs([]OTPAccount{{OTPData: OTPData{Secret: "567PX3567PX356Y"}, AccountName: "plus"}}, 1)
+	require.NoError(t, err)
+	require.Contains(t, uris[0], "%2B")
+	batch, err = ParseOTPMigrationURI(strings.ReplaceAll(uris[0], "%2B", "+"))
+	require.NoError(t, err)
+	assert.Equal(t, "567PX3567PX356Y", batch.Accounts[0].Secret)
+
+	for _, bad := range []string{
+		"otpauth://totp/x?secret=JBSWY3DPEHPK3PXP",
+		"otpauth-migration://offline",
+		"otpauth-migration://offline?data=!!!",
+		"otpauth-migration://offline?data=CjEK",
+	} {
+		_, err := ParseOTPMigrationURI(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestOTPMigrationURIs_RoundTrip(t *testing.T) {
+	accounts := []OTPAccount{
+		{OTPData: OTPData{Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6}, Issuer: "GitHub", AccountName: "alice"},
+		{OTPData: OTPData{Secret: "GEZDGNBVGY3TQOJQ", Algorithm: "SHA512", Digits: 8}, Issuer: "AWS", AccountName: "root"},
+	}
+	for i := 0; i < OTPMigrationBatchSize; i++ {
+		accounts = append(accounts, OTPAccount{OTPData: OTPData{Secret: "MFRGGZDFMZTWQ2LK", Algorithm: "SHA256", Digits: 6}, AccountName: "bulk"})
+	}
+
+	uris, err := OTPMigrationURIs(accounts, 42)
+	require.NoError(t, err)
+	require.Len(t, uris, 2)
+
+	var got []OTPAccount
+	for i, uri := range uris {
+		batch, err := ParseOTPMigrationURI(uri)
+		require.NoError(t, err)
+		assert.Equal(t, 2, batch.BatchSize)
+		assert.Equal(t, i, batch.BatchIndex)
+		assert.Equal(t, 42, batch.BatchID)
+		got = append(got, batch.Accounts...)
+	}
+	assert.Equal(t, accounts, got)
+
+	// Период в формате не передается
+	_, err = OTPMigrationURIs([]OTPAccount{{OTPData: OTPData{Secret: "JBSWY3DPEHPK3PXP", Period: 60}}}, 1)
+	assert.ErrorIs(t, err, ErrOTPNotMigratable)
+}
+
+func TestParseOTPMigrationURI_SkipsHOTP(t *testing.T) {
+	var hotp, totp []byte
+	hotp = appendBytesField(hotp, 1, []byte("secret-one"))
+	hotp = appendBytesField(hotp, 2, []byte("counter"))
+	hotp = appendVarintField(hotp, 6, migrationTypeHOTP)
+	hotp = appendVarintField(hotp, 7, 5)
+	totp = appendBytesField(totp, 1, []byte("secret-two"))
+	totp = appendBytesField(totp, 2, []byte("Acme:bob"))
+	totp = appendVarintField(totp, 6, migrationTypeTOTP)
+
+	var payload []byte
+	payload = appendBytesField(payload, 1, hotp)
+	payload = appendBytesField(payload, 1, totp)
+
+	batch, err := decodeMigrationPayload(payload)
+	require.NoError(t, err)
+	require.Len(t, batch.Accounts, 1)
+	assert.Equal(t, "Acme", batch.Accounts[0].Issuer)
+	assert.Equal(t, "bob", batch.Accounts[0].AccountName)
+	require.Len(t, batch.Skipped, 1)
+	assert.Contains(t, batch.Skipped[0], "HOTP")
+}
@@ -0,0 +1,56 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тестовые векторы из RFC 6238, приложение B
+func TestOTPData_Code_RFC6238(t *testing.T) {
+	const (
+		sha1Secret   = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+		sha256Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZA"
+		sha512Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQGEZDGNA"
+	)
+
+	tests := []struct {
+		unix      int64
+		secret    string
+		algorithm string
+		want      string
+	}{
+		{59, sha1Secret, "SHA1", "94287082"},
+		{59, sha256Secret, "SHA256", "46119246"},
+		{59, sha512Secret, "SHA512", "90693936"},
+		{1111111109, sha1Secret, "SHA1", "07081804"},
+		{1234567890, sha256Secret, "SHA256", "91819424"},
+		{20000000000, sha512Secret, "SHA512", "47863826"},
+	}
+
+	for _, tt := range tests {
+		otp := &OTPData{Secret: tt.secret, Algorithm: tt.algorithm, Digits: 8, Period: 30}
+		code, err := otp.Code(time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "%s at %d", tt.algorithm, tt.unix)
+	}
+}
+
+func TestOTPData_Defaults(t *testing.T) {
+	otp := &OTPData{Secret: "gezd gnbv gy3t qojq gezd gnbv gy3t qojq"}
+	require.NoError(t, otp.Validate())
+
+	code, err := otp.Code(time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+	assert.Equal(t, time.Second, otp.Remaining(time.Unix(59, 0)))
+}
+
+func TestOTPData_Validate(t *testing.T) {
+	assert.Error(t, (&OTPData{}).Validate())
+	assert.Error(t, (&OTPData{Secret: "not-base32!"}).Validate())
+	assert.Error(t, (&OTPData{Secret: "GEZDGNBV", Algorithm: "MD5"}).Validate())
+	assert.Error(t, (&OTPData{Secret: "GEZDGNBV", Digits: 7}).Validate())
+}
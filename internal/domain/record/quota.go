@@ -0,0 +1,25 @@
+package record
+
+import (
+	"context"
+)
+
+// QuotaChecker проверяет квоту хранилища пользователя перед записью данных.
+// Check возвращает ошибку, если после добавления delta байт квота будет превышена.
+type QuotaChecker interface {
+	Check(ctx context.Context, userID int, delta int64) error
+}
+
+// checkQuota проверяет, поместится ли еще delta байт в квоту пользователя.
+// Ошибку превышения квоты возвращает без обертки, чтобы ее распознал обработчик.
+func (s *Service) checkQuota(ctx context.Context, userID int, delta int64) error {
+	if s.quota == nil || delta <= 0 {
+		return nil
+	}
+	return s.quota.Check(ctx, userID, delta)
+}
+
+// sizeDelta возвращает изменение объема хранилища при замене данных записи
+func sizeDelta(before, after string) int64 {
+	return int64(len(after)) - int64(len(before))
+}
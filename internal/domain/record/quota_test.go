@@ -0,0 +1,93 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// stubQuota разрешает запись, пока общий объем не превышает free байт
+type stubQuota struct {
+	free   int64
+	checks []int64
+}
+
+func (q *stubQuota) Check(_ context.Context, _ int, delta int64) error {
+	q.checks = append(q.checks, delta)
+	if delta > q.free {
+		return errQuotaExceeded
+	}
+	return nil
+}
+
+func TestService_Quota(t *testing.T) {
+	ctx := context.Background()
+	meta := json.RawMessage(`{"title":"x"}`)
+
+	t.Run("Create over quota is rejected", func(t *testing.T) {
+		repo := new(MockRepository)
+		quota := &stubQuota{free: 5}
+		service := NewService(repo, NewFactory(), nil, quota, slog.Default())
+
+		_, err := service.Create(ctx, 1, CreateRequest{Type: RecTypeText, EncryptedData: "0123456789", Meta: meta})
+		assert.ErrorIs(t, err, errQuotaExceeded)
+		assert.Equal(t, []int64{10}, quota.checks)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update checks only growth", func(t *testing.T) {
+		repo := new(MockRepository)
+		quota := &stubQuota{free: 5}
+		service := NewService(repo, NewFactory(), nil, quota, slog.Default())
+
+		current := &Record{ID: 1, UserID: 1, Type: RecTypeText, EncryptedData: "0123456789", Version: 1, LastModified: time.Now()}
+		repo.On("Get", mock.Anything, 1, 1).Return(current, nil)
+		repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		repo.On("SaveVersion", mock.Anything, mock.Anything).Return(nil)
+
+		// Уменьшение объема квоту не проверяет
+		require.NoError(t, service.Update(ctx, 1, UpdateRequest{RecordID: 1, Type: RecTypeText, EncryptedData: "01234", Meta: meta}))
+		assert.Empty(t, quota.checks)
+
+		require.NoError(t, service.Update(ctx, 1, UpdateRequest{RecordID: 1, Type: RecTypeText, EncryptedData: "012345678901234", Meta: meta}))
+		err := service.Update(ctx, 1, UpdateRequest{RecordID: 1, Type: RecTypeText, EncryptedData: "0123456789012345", Meta: meta})
+		assert.ErrorIs(t, err, errQuotaExceeded)
+		assert.Equal(t, []int64{5, 6}, quota.checks)
+	})
+
+	t.Run("Batch create is rejected as a whole", func(t *testing.T) {
+		repo := new(MockRepository)
+		quota := &stubQuota{free: 10}
+		service := NewService(repo, NewFactory(), nil, quota, slog.Default())
+
+		_, err := service.BatchCreate(ctx, 1, []CreateRequest{
+			{Type: RecTypeText, EncryptedData: "012345", Meta: meta},
+			{Type: RecTypeText, EncryptedData: "012345", Meta: meta},
+		})
+		assert.ErrorIs(t, err, errQuotaExceeded)
+		assert.Equal(t, []int64{12}, quota.checks)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Restore from trash counts record size", func(t *testing.T) {
+		repo := new(MockRepository)
+		quota := &stubQuota{free: 10}
+		service := NewService(repo, NewFactory(), nil, quota, slog.Default())
+
+		deletedAt := time.Now()
+		repo.On("GetHead", mock.Anything, 10).Return(&Head{ID: 10, UserID: 1, DeletedAt: &deletedAt, Size: 20}, nil)
+
+		_, err := service.Restore(ctx, 1, 10)
+		assert.ErrorIs(t, err, errQuotaExceeded)
+		repo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
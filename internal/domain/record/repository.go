@@ -10,7 +10,12 @@ type Repository interface {
 	// Базовые CRUD операции
 	List(ctx context.Context, userID int) ([]Record, error)
 	Get(ctx context.Context, userID, recordID int) (*Record, error)
+	// GetHead возвращает версию записи без данных, включая удаленные и записи организаций
+	GetHead(ctx context.Context, recordID int) (*Head, error)
 	GetByChecksum(ctx context.Context, userID int, checksum string) (*Record, error)
+	// GetByUUID возвращает запись пользователя по UUID, включая удаленные и
+	// записи организаций; отсутствующая запись - ErrNotFound
+	GetByUUID(ctx context.Context, userID int, recordUUID string) (*Record, error)
 	Create(ctx context.Context, record *Record) (int, error)
 	Update(ctx context.Context, record *Record) error
 	Delete(ctx context.Context, userID, recordID int) error
@@ -24,6 +29,39 @@ type Repository interface {
 	// Статистика
 	GetStats(ctx context.Context, userID int) (map[string]interface{}, error)
 
+	// Хранилища организаций: личные методы выше записи организаций не возвращают
+	ListByOrg(ctx context.Context, orgID int) ([]Record, error)
+	GetShared(ctx context.Context, recordID int) (*Record, error)
+	DeleteInOrg(ctx context.Context, orgID, recordID int) error
+	SoftDeleteInOrg(ctx context.Context, orgID, recordID int) error
+
+	// Корзина: записи с deleted_at
+	ListDeleted(ctx context.Context, userID int) ([]Record, error)
+	// Restore снимает пометку удаления и возвращает новую версию записи
+	Restore(ctx context.Context, userID, recordID int) (int, error)
+	RestoreInOrg(ctx context.Context, orgID, recordID int) (int, error)
+	// PurgeDeleted окончательно удаляет личные записи пользователя, удаленные раньше before
+	PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error)
+	// PurgeAllDeleted окончательно удаляет все записи, удаленные раньше before
+	PurgeAllDeleted(ctx context.Context, before time.Time) (int, error)
+	// PurgeAllAcknowledged окончательно удаляет записи, удаленные раньше before,
+	// удаление которых получили все устройства владельца, синхронизировавшиеся
+	// после activeSince. Записи организаций на устройства не синхронизируются
+	// и удаляются только по сроку.
+	PurgeAllAcknowledged(ctx context.Context, before, activeSince time.Time) (int, error)
+
+	// Срок действия: неудаленные личные записи с expires_at в метаданных.
+	// ListWithExpiry - записи пользователя, ListAllWithExpiry - всех пользователей.
+	ListWithExpiry(ctx context.Context, userID int) ([]Record, error)
+	ListAllWithExpiry(ctx context.Context) ([]Record, error)
+
+	// Вложения: GetAttachment возвращает вложение с данными, ListAttachments - без них.
+	// Отсутствующее вложение - ErrAttachmentNotFound.
+	ListAttachments(ctx context.Context, recordID int) ([]Attachment, error)
+	GetAttachment(ctx context.Context, recordID, attachmentID int) (*Attachment, error)
+	CreateAttachment(ctx context.Context, attachment *Attachment) (int, error)
+	DeleteAttachment(ctx context.Context, recordID, attachmentID int) error
+
 	// Вспомогательные методы
 	SaveVersion(ctx context.Context, version *Version) error
 	GetVersions(ctx context.Context, recordID int) ([]Version, error)
@@ -0,0 +1,100 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+)
+
+// Сквозное шифрование метаданных. В этом режиме клиент отправляет на сервер
+// вместо метаданных конверт: все поля зашифрованы мастер-ключом в поле
+// sealed, а открытыми остаются только поля, без которых не работает сервер.
+// Сервер хранит конверт как обычные метаданные и не видит названий, адресов
+// и тегов; поиск и списки клиент строит по локальному хранилищу.
+
+// MetaSealedKey - ключ зашифрованных метаданных в конверте
+const MetaSealedKey = "sealed"
+
+// OpenMetaKeys - поля, которые остаются открытыми в конверте: по ним сервер
+// считает ссылки на блобы, удаляет папки с записями, защищает записи от
+// изменений и находит истекающие записи
+var OpenMetaKeys = []string{blob.MetaKey, folder.MetaKey, metaLockedKey, metaExpiresAtKey}
+
+// IsSealedMeta сообщает, что метаданные - конверт с зашифрованными полями
+func IsSealedMeta(meta json.RawMessage) bool {
+	var m map[string]json.RawMessage
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil {
+		return false
+	}
+	_, ok := m[MetaSealedKey]
+	return ok
+}
+
+// SealMeta заменяет метаданные конвертом; encrypt шифрует JSON всех полей.
+// Пустые и уже запечатанные метаданные возвращаются без изменений.
+func SealMeta(meta json.RawMessage, encrypt func([]byte) (string, error)) (json.RawMessage, error) {
+	if len(meta) == 0 || string(meta) == "null" || IsSealedMeta(meta) {
+		return meta, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(meta, &fields); err != nil {
+		return nil, fmt.Errorf("%w: meta is not a JSON object", ErrInvalidData)
+	}
+
+	sealed, err := encrypt(meta)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := map[string]json.RawMessage{MetaSealedKey: value}
+	for _, key := range OpenMetaKeys {
+		if v, ok := fields[key]; ok {
+			envelope[key] = v
+		}
+	}
+	return json.Marshal(envelope)
+}
+
+// OpenMeta восстанавливает метаданные из конверта; decrypt расшифровывает
+// поле sealed. Открытые поля берутся из конверта, а не из зашифрованной
+// части: сервер мог изменить их, например при удалении папки. Обычные
+// метаданные возвращаются без изменений.
+func OpenMeta(meta json.RawMessage, decrypt func(string) ([]byte, error)) (json.RawMessage, error) {
+	var envelope map[string]json.RawMessage
+	if len(meta) == 0 || json.Unmarshal(meta, &envelope) != nil {
+		return meta, nil
+	}
+	raw, ok := envelope[MetaSealedKey]
+	if !ok {
+		return meta, nil
+	}
+
+	var sealed string
+	if err := json.Unmarshal(raw, &sealed); err != nil {
+		return nil, fmt.Errorf("%w: sealed meta must be a string", ErrInvalidData)
+	}
+	plaintext, err := decrypt(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(plaintext, &fields); err != nil {
+		return nil, fmt.Errorf("%w: sealed meta is not a JSON object", ErrInvalidData)
+	}
+	for _, key := range OpenMetaKeys {
+		if v, ok := envelope[key]; ok {
+			fields[key] = v
+		} else {
+			delete(fields, key)
+		}
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,89 @@
+package record
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeal(plaintext []byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(plaintext), nil
+}
+
+func testOpen(sealed string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(sealed)
+}
+
+func TestSealMeta(t *testing.T) {
+	meta := json.RawMessage(`{"title":"Bank","resource":"bank.com","tags":["work"],"folder_id":3,"locked":true,"expires_at":"2027-01-01T00:00:00Z","blob":"abc"}`)
+
+	sealed, err := SealMeta(meta, testSeal)
+	require.NoError(t, err)
+	assert.True(t, IsSealedMeta(sealed))
+	assert.NotContains(t, string(sealed), "Bank")
+	assert.NotContains(t, string(sealed), "bank.com")
+	assert.NotContains(t, string(sealed), "work")
+
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(sealed, &envelope))
+	assert.JSONEq(t, `3`, string(envelope["folder_id"]))
+	assert.JSONEq(t, `true`, string(envelope["locked"]))
+	assert.JSONEq(t, `"abc"`, string(envelope["blob"]))
+	assert.JSONEq(t, `"2027-01-01T00:00:00Z"`, string(envelope["expires_at"]))
+	assert.True(t, IsLocked(sealed))
+
+	again, err := SealMeta(sealed, testSeal)
+	require.NoError(t, err)
+	assert.Equal(t, sealed, again)
+
+	for _, empty := range []json.RawMessage{nil, json.RawMessage(`null`)} {
+		out, err := SealMeta(empty, testSeal)
+		require.NoError(t, err)
+		assert.Equal(t, empty, out)
+	}
+
+	_, err = SealMeta(json.RawMessage(`[1]`), testSeal)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	failed := errors.New("locked")
+	_, err = SealMeta(meta, func([]byte) (string, error) { return "", failed })
+	assert.ErrorIs(t, err, failed)
+}
+
+func TestOpenMeta(t *testing.T) {
+	meta := json.RawMessage(`{"title":"Bank","folder_id":3,"locked":true}`)
+	sealed, err := SealMeta(meta, testSeal)
+	require.NoError(t, err)
+
+	opened, err := OpenMeta(sealed, testOpen)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(meta), string(opened))
+
+	// Сервер снял папку и блокировку: открытые поля конверта главнее
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(sealed, &envelope))
+	delete(envelope, "folder_id")
+	envelope["locked"] = json.RawMessage(`false`)
+	changed, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	opened, err = OpenMeta(changed, testOpen)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Bank","locked":false}`, string(opened))
+
+	plain := json.RawMessage(`{"title":"Plain"}`)
+	opened, err = OpenMeta(plain, testOpen)
+	require.NoError(t, err)
+	assert.Equal(t, plain, opened)
+
+	_, err = OpenMeta(json.RawMessage(`{"sealed":1}`), testOpen)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	failed := errors.New("wrong key")
+	_, err = OpenMeta(sealed, func(string) ([]byte, error) { return nil, failed })
+	assert.ErrorIs(t, err, failed)
+}
@@ -19,14 +19,21 @@ var ()
 type Service struct {
 	repo    Repository
 	factory *Factory
+	orgs    OrgAuthorizer
+	quota   QuotaChecker
 	log     *slog.Logger
 }
 
+// Servicer is the record service used by HTTP handlers.
+// Writes take typed requests; record and owner IDs are always passed as userID, recordID.
 type Servicer interface {
 	List(ctx context.Context, userID int) (ListResponse, error)
-	Create(ctx context.Context, userID int, typ RecType, encryptedData string, meta json.RawMessage) (int, error)
+	Create(ctx context.Context, userID int, req CreateRequest) (int, error)
+	// CreateRecord создает запись и возвращает ее в сохраненном виде, с UUID
+	CreateRecord(ctx context.Context, userID int, req CreateRequest) (*Record, error)
 	Find(ctx context.Context, userID, recordID int) (*Record, error)
-	Update(ctx context.Context, userID, recordID int, typ RecType, encryptedData string, meta json.RawMessage) error
+	Head(ctx context.Context, userID, recordID int) (*Head, error)
+	Update(ctx context.Context, userID int, req UpdateRequest) error
 	Delete(ctx context.Context, userID, recordID int) error
 	SoftDelete(ctx context.Context, userID, recordID int) error
 	Search(ctx context.Context, userID int, criteria SearchCriteria) ([]Record, error)
@@ -34,29 +41,40 @@ type Servicer interface {
 	GetModifiedSince(ctx context.Context, userID int, since time.Time) ([]Record, error)
 	BatchCreate(ctx context.Context, userID int, records []CreateRequest) (BatchCreateResponse, error)
 	BatchUpdate(ctx context.Context, userID int, updates []UpdateRequest) (BatchUpdateResponse, error)
-	GetByType(ctx context.Context, userID int, recordType string) ([]Record, error)
+	GetByType(ctx context.Context, userID int, typ RecType) ([]Record, error)
 	GetVersions(ctx context.Context, userID, recordID int) ([]Version, error)
-
-	CreateWithModels(
-		ctx context.Context,
-		userID int,
-		typ RecType,
-		data Data,
-		meta MetaData,
-		deviceID string,
-	) (int, error)
-	UpdateWithModels(
-		ctx context.Context,
-		recordID int,
-		userID int,
-		data Data,
-		meta MetaData,
-		deviceID string,
-	) error
-	GetRecordWithModels(ctx context.Context, recordID, userID int) (Data, MetaData, error)
+	// ResolveUUID возвращает ID записи пользователя по ее UUID
+	ResolveUUID(ctx context.Context, userID int, recordUUID string) (int, error)
+
+	// Корзина
+	ListTrash(ctx context.Context, userID int) ([]Record, error)
+	Restore(ctx context.Context, userID, recordID int) (int, error)
+	PurgeTrash(ctx context.Context, userID int, olderThan time.Duration) (int, error)
+
+	// Срок действия
+	ListExpiring(ctx context.Context, userID int, within time.Duration) ([]Expiration, error)
+
+	// Вложения
+	ListAttachments(ctx context.Context, userID, recordID int) ([]Attachment, error)
+	AddAttachment(ctx context.Context, userID, recordID int, req AttachmentRequest) (*Attachment, error)
+	GetAttachment(ctx context.Context, userID, recordID, attachmentID int) (*Attachment, error)
+	DeleteAttachment(ctx context.Context, userID, recordID, attachmentID int) error
+
+	// Хранилища организаций
+	ListOrg(ctx context.Context, userID, orgID int) (ListResponse, error)
+	CreateInOrg(ctx context.Context, userID, orgID int, req CreateRequest) (int, error)
+
+	// Типизированные записи
+	CreateWithModels(ctx context.Context, userID int, req ModelRequest) (int, error)
+	UpdateWithModels(ctx context.Context, userID, recordID int, req ModelRequest) error
+	GetRecordWithModels(ctx context.Context, userID, recordID int) (Data, MetaData, error)
 }
 
+var _ Servicer = (*Service)(nil)
+
 type CreateRequest struct {
+	// UUID - identifier generated by the client; empty assigns a new one
+	UUID          string          `json:"uuid,omitempty"`
 	Type          RecType         `json:"type"`
 	EncryptedData string          `json:"encrypted_data"`
 	Meta          json.RawMessage `json:"meta"`
@@ -64,6 +82,16 @@ type CreateRequest struct {
 	DeviceID      string          `json:"device_id,omitempty"`
 }
 
+// ModelRequest creates or updates a record from typed models.
+// Type is ignored on update: the stored record keeps its type.
+type ModelRequest struct {
+	UUID     string
+	Type     RecType
+	Data     Data
+	Meta     MetaData
+	DeviceID string
+}
+
 type UpdateRequest struct {
 	RecordID      int             `json:"record_id"`
 	Type          RecType         `json:"type"`
@@ -104,11 +132,15 @@ type TypeStats struct {
 	Size  int64 `json:"size"`
 }
 
-// NewService creates a new record service
-func NewService(repo Repository, factory *Factory, log *slog.Logger) Servicer {
+// NewService creates a new record service.
+// orgs may be nil, then records of organization vaults are not accessible.
+// quota may be nil, then storage quotas are not enforced.
+func NewService(repo Repository, factory *Factory, orgs OrgAuthorizer, quota QuotaChecker, log *slog.Logger) Servicer {
 	return &Service{
 		repo:    repo,
 		factory: factory,
+		orgs:    orgs,
+		quota:   quota,
 		log:     log.With("component", "record_service"),
 	}
 }
@@ -121,57 +153,67 @@ func (s *Service) List(ctx context.Context, userID int) (ListResponse, error) {
 		return ListResponse{}, fmt.Errorf("list records: %w", err)
 	}
 
-	items := make([]Item, len(records))
-	for i, r := range records {
-		items[i] = Item{
-			ID:           r.ID,
-			Type:         r.Type,
-			Meta:         r.Meta,
-			Version:      r.Version,
-			LastModified: r.LastModified,
-		}
-	}
-
-	return ListResponse{
-		Records: items,
-		Total:   len(items),
-	}, nil
+	return NewListResponse(records), nil
 }
 
 // Create creates a new record
-func (s *Service) Create(ctx context.Context, userID int, typ RecType, encryptedData string, meta json.RawMessage) (int, error) {
-	if typ == "" || encryptedData == "" {
-		return -1, ErrInvalidData
+func (s *Service) Create(ctx context.Context, userID int, req CreateRequest) (int, error) {
+	record, err := s.CreateRecord(ctx, userID, req)
+	if err != nil {
+		return -1, err
 	}
+	return record.ID, nil
+}
 
-	checksum := s.generateChecksum(encryptedData, typ, meta)
+// CreateRecord creates a new record and returns it as stored. A request
+// with the UUID of an existing record returns that record instead.
+func (s *Service) CreateRecord(ctx context.Context, userID int, req CreateRequest) (*Record, error) {
+	if req.Type == "" || req.EncryptedData == "" {
+		return nil, ErrInvalidData
+	}
+
+	recordUUID, existing, err := s.claimUUID(ctx, userID, req.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	if err := s.checkQuota(ctx, userID, int64(len(req.EncryptedData))); err != nil {
+		return nil, err
+	}
+
+	checksum := s.generateChecksum(req.EncryptedData, req.Type, req.Meta)
 	record := &Record{
+		UUID:          recordUUID,
 		UserID:        userID,
-		Type:          typ,
-		EncryptedData: encryptedData,
-		Meta:          meta,
+		Type:          req.Type,
+		EncryptedData: req.EncryptedData,
+		Meta:          req.Meta,
 		Checksum:      checksum,
 		Version:       1,
 		LastModified:  time.Now(),
+		DeviceID:      req.DeviceID,
 	}
 
-	recordID, err := s.repo.Create(ctx, record)
+	created, err := s.createOnce(ctx, record)
 	if err != nil {
-		s.log.Error("failed to create record", "user_id", userID, "type", typ, "error", err.Error())
-		return -1, fmt.Errorf("create record: %w", err)
+		s.log.Error("failed to create record", "user_id", userID, "type", req.Type, "error", err.Error())
+		return nil, fmt.Errorf("create record: %w", err)
 	}
 
-	s.log.Info("record created successfully", "record_id", recordID, "user_id", userID, "type", typ)
+	s.log.Info("record created successfully", "record_id", created.ID, "user_id", userID, "type", req.Type)
 
-	return recordID, nil
+	return created, nil
 }
 
 // Find returns a specific record by ID
 func (s *Service) Find(ctx context.Context, userID, recordID int) (*Record, error) {
-	record, err := s.repo.Get(ctx, userID, recordID)
+	record, err := s.getAccessible(ctx, userID, recordID, AccessRead)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return nil, ErrNotFound
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return nil, err
 		}
 		s.log.Error("failed to find record", "record_id", recordID, "user_id", userID, "error", err)
 		return nil, fmt.Errorf("find record: %w", err)
@@ -184,13 +226,37 @@ func (s *Service) Find(ctx context.Context, userID, recordID int) (*Record, erro
 	return record, nil
 }
 
+// Head returns record version info without data. Soft-deleted records
+// return ErrRecordDeleted so the client can drop its cached copy.
+func (s *Service) Head(ctx context.Context, userID, recordID int) (*Head, error) {
+	head, err := s.repo.GetHead(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		s.log.Error("failed to get record head", "record_id", recordID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get record head: %w", err)
+	}
+
+	if err := s.authorizeHead(ctx, head, userID, AccessRead); err != nil {
+		return nil, err
+	}
+
+	if head.DeletedAt != nil {
+		return head, ErrRecordDeleted
+	}
+
+	return head, nil
+}
+
 // Update updates an existing record
-func (s *Service) Update(ctx context.Context, userID, recordID int, typ RecType, encryptedData string, meta json.RawMessage) error {
+func (s *Service) Update(ctx context.Context, userID int, req UpdateRequest) error {
+	recordID := req.RecordID
 	// Get the current record to check permissions and get version
-	currentRecord, err := s.repo.Get(ctx, userID, recordID)
+	currentRecord, err := s.getAccessible(ctx, userID, recordID, AccessWrite)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return ErrNotFound
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return err
 		}
 		return fmt.Errorf("get record for update: %w", err)
 	}
@@ -200,18 +266,28 @@ func (s *Service) Update(ctx context.Context, userID, recordID int, typ RecType,
 		return ErrRecordDeleted
 	}
 
+	if err := checkUnlocked(currentRecord, req.EncryptedData, req.Meta); err != nil {
+		return err
+	}
+
+	if err := s.checkQuota(ctx, userID, sizeDelta(currentRecord.EncryptedData, req.EncryptedData)); err != nil {
+		return err
+	}
+
 	// Generate new checksum
-	checksum := s.generateChecksum(encryptedData, typ, meta)
+	checksum := s.generateChecksum(req.EncryptedData, req.Type, req.Meta)
 
 	// Update the record
 	updatedRecord := &Record{
 		ID:            recordID,
 		UserID:        userID,
-		Type:          typ,
-		EncryptedData: encryptedData,
-		Meta:          meta,
+		Type:          req.Type,
+		EncryptedData: req.EncryptedData,
+		Meta:          req.Meta,
 		Checksum:      checksum,
 		Version:       currentRecord.Version,
+		DeviceID:      req.DeviceID,
+		OrgID:         currentRecord.OrgID,
 	}
 
 	err = s.repo.Update(ctx, updatedRecord)
@@ -223,6 +299,18 @@ func (s *Service) Update(ctx context.Context, userID, recordID int, typ RecType,
 		return fmt.Errorf("update record: %w", err)
 	}
 
+	// Keep the previous state in the version history so it can be restored
+	previous := &Version{
+		RecordID:      recordID,
+		Version:       currentRecord.Version,
+		EncryptedData: currentRecord.EncryptedData,
+		Meta:          currentRecord.Meta,
+		Checksum:      currentRecord.Checksum,
+	}
+	if err := s.repo.SaveVersion(ctx, previous); err != nil {
+		s.log.Warn("failed to save record version", "record_id", recordID, "version", previous.Version, "error", err)
+	}
+
 	s.log.Info("record updated successfully", "record_id", recordID, "user_id", userID, "new_version", updatedRecord.Version)
 	return nil
 }
@@ -230,15 +318,23 @@ func (s *Service) Update(ctx context.Context, userID, recordID int, typ RecType,
 // Delete permanently deletes a record
 func (s *Service) Delete(ctx context.Context, userID, recordID int) error {
 	// First check if record exists and belongs to user
-	record, err := s.repo.Get(ctx, userID, recordID)
+	record, err := s.getAccessible(ctx, userID, recordID, AccessWrite)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return ErrNotFound
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return err
 		}
 		return fmt.Errorf("get record for delete: %w", err)
 	}
 
-	err = s.repo.Delete(ctx, userID, recordID)
+	if IsLocked(record.Meta) {
+		return ErrRecordLocked
+	}
+
+	if record.OrgID != nil {
+		err = s.repo.DeleteInOrg(ctx, *record.OrgID, recordID)
+	} else {
+		err = s.repo.Delete(ctx, userID, recordID)
+	}
 	if err != nil {
 		s.log.Error("failed to delete record", "record_id", recordID, "user_id", userID, "error", err)
 		return fmt.Errorf("delete record: %w", err)
@@ -261,10 +357,10 @@ func (s *Service) Delete(ctx context.Context, userID, recordID int) error {
 // SoftDelete marks a record as deleted without removing it
 func (s *Service) SoftDelete(ctx context.Context, userID, recordID int) error {
 	// First check if record exists and belongs to user
-	record, err := s.repo.Get(ctx, userID, recordID)
+	record, err := s.getAccessible(ctx, userID, recordID, AccessWrite)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return ErrNotFound
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return err
 		}
 		return fmt.Errorf("get record for soft delete: %w", err)
 	}
@@ -274,7 +370,15 @@ func (s *Service) SoftDelete(ctx context.Context, userID, recordID int) error {
 		return nil
 	}
 
-	err = s.repo.SoftDelete(ctx, userID, recordID)
+	if IsLocked(record.Meta) {
+		return ErrRecordLocked
+	}
+
+	if record.OrgID != nil {
+		err = s.repo.SoftDeleteInOrg(ctx, *record.OrgID, recordID)
+	} else {
+		err = s.repo.SoftDelete(ctx, userID, recordID)
+	}
 	if err != nil {
 		s.log.Error("failed to soft delete record", "record_id", recordID, "user_id", userID, "error", err)
 		return fmt.Errorf("soft delete record: %w", err)
@@ -349,6 +453,15 @@ func (s *Service) BatchCreate(ctx context.Context, userID int, requests []Create
 		return BatchCreateResponse{}, nil
 	}
 
+	// The batch is accepted or rejected as a whole
+	var total int64
+	for _, req := range requests {
+		total += int64(len(req.EncryptedData))
+	}
+	if err := s.checkQuota(ctx, userID, total); err != nil {
+		return BatchCreateResponse{}, err
+	}
+
 	var failed []FailedOperation
 	successCount := 0
 
@@ -396,7 +509,7 @@ func (s *Service) BatchUpdate(ctx context.Context, userID int, updates []UpdateR
 
 	for i, update := range updates {
 		// Get current record to verify ownership and version
-		record, err := s.repo.Get(ctx, userID, update.RecordID)
+		record, err := s.getAccessible(ctx, userID, update.RecordID, AccessWrite)
 		if err != nil {
 			failed = append(failed, FailedOperation{
 				Index:    i,
@@ -406,6 +519,15 @@ func (s *Service) BatchUpdate(ctx context.Context, userID int, updates []UpdateR
 			continue
 		}
 
+		if err := checkUnlocked(record, update.EncryptedData, update.Meta); err != nil {
+			failed = append(failed, FailedOperation{
+				Index:    i,
+				RecordID: update.RecordID,
+				Error:    err.Error(),
+			})
+			continue
+		}
+
 		// Check version
 		if record.Version != update.Version {
 			failed = append(failed, FailedOperation{
@@ -416,6 +538,15 @@ func (s *Service) BatchUpdate(ctx context.Context, userID int, updates []UpdateR
 			continue
 		}
 
+		if err := s.checkQuota(ctx, userID, sizeDelta(record.EncryptedData, update.EncryptedData)); err != nil {
+			failed = append(failed, FailedOperation{
+				Index:    i,
+				RecordID: update.RecordID,
+				Error:    err.Error(),
+			})
+			continue
+		}
+
 		// Prepare updated record
 		updatedRecord := &Record{
 			ID:            update.RecordID,
@@ -426,6 +557,7 @@ func (s *Service) BatchUpdate(ctx context.Context, userID int, updates []UpdateR
 			Checksum:      update.Checksum,
 			Version:       update.Version,
 			DeviceID:      update.DeviceID,
+			OrgID:         record.OrgID,
 		}
 
 		// Generate checksum if not provided
@@ -460,14 +592,14 @@ func (s *Service) generateChecksum(encryptedData string, typ RecType, meta json.
 }
 
 // GetByType returns records of specific type
-func (s *Service) GetByType(ctx context.Context, userID int, recordType string) ([]Record, error) {
-	return s.repo.GetByType(ctx, userID, recordType)
+func (s *Service) GetByType(ctx context.Context, userID int, typ RecType) ([]Record, error) {
+	return s.repo.GetByType(ctx, userID, typ.String())
 }
 
 // GetVersions returns version history for a record
 func (s *Service) GetVersions(ctx context.Context, userID, recordID int) ([]Version, error) {
-	// First verify ownership
-	_, err := s.repo.Get(ctx, userID, recordID)
+	// First verify ownership or organization access
+	_, err := s.getAccessible(ctx, userID, recordID, AccessRead)
 	if err != nil {
 		return nil, fmt.Errorf("verify record ownership: %w", err)
 	}
@@ -475,59 +607,68 @@ func (s *Service) GetVersions(ctx context.Context, userID, recordID int) ([]Vers
 	return s.repo.GetVersions(ctx, recordID)
 }
 
-func (s *Service) CreateWithModels(
-	ctx context.Context,
-	userID int,
-	typ RecType,
-	data Data,
-	meta MetaData,
-	deviceID string,
-) (int, error) {
+func (s *Service) CreateWithModels(ctx context.Context, userID int, req ModelRequest) (int, error) {
+	typ := req.Type
+
 	// Валидация
-	if data.GetType() != typ {
-		return -1, fmt.Errorf("data type mismatch: expected %s, got %s", typ, data.GetType())
+	if req.Data.GetType() != typ {
+		return -1, fmt.Errorf("data type mismatch: expected %s, got %s", typ, req.Data.GetType())
 	}
 
-	if err := data.Validate(); err != nil {
+	if err := req.Data.Validate(); err != nil {
 		return -1, fmt.Errorf("data validation failed: %w", err)
 	}
 
-	if err := meta.Validate(); err != nil {
+	if err := req.Meta.Validate(); err != nil {
 		return -1, fmt.Errorf("meta validation failed: %w", err)
 	}
 
+	recordUUID, existing, err := s.claimUUID(ctx, userID, req.UUID)
+	if err != nil {
+		return -1, err
+	}
+	if existing != nil {
+		return existing.ID, nil
+	}
+
 	// Подготовка записи
-	record, err := s.factory.PrepareRecord(typ, data, meta)
+	record, err := s.factory.PrepareRecord(typ, req.Data, req.Meta)
 	if err != nil {
 		return -1, fmt.Errorf("failed to prepare record: %w", err)
 	}
+	record.UUID = recordUUID
+
+	if err := s.checkQuota(ctx, userID, int64(len(record.EncryptedData))); err != nil {
+		return -1, err
+	}
 
 	record.UserID = userID
-	record.DeviceID = deviceID
+	record.DeviceID = req.DeviceID
 	record.LastModified = time.Now()
 
 	// Генерация checksum
 	record.Checksum = s.generateChecksum(record.EncryptedData, typ, record.Meta)
 
 	// Сохранение в БД
-	recordID, err := s.repo.Create(ctx, record)
+	created, err := s.createOnce(ctx, record)
 	if err != nil {
 		s.log.Error("failed to create record", "user_id", userID, "type", typ, "error", err.Error())
 		return -1, fmt.Errorf("create record: %w", err)
 	}
+	recordID := created.ID
 
 	s.log.Info("record created successfully",
 		"record_id", recordID,
 		"user_id", userID,
 		"type", typ,
-		"device_id", deviceID,
+		"device_id", req.DeviceID,
 	)
 
 	return recordID, nil
 }
 
 // GetRecordWithModels получает запись с парсингом в модели
-func (s *Service) GetRecordWithModels(ctx context.Context, recordID, userID int) (Data, MetaData, error) {
+func (s *Service) GetRecordWithModels(ctx context.Context, userID, recordID int) (Data, MetaData, error) {
 	record, err := s.repo.Get(ctx, recordID, userID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get record: %w", err)
@@ -552,14 +693,7 @@ func (s *Service) GetRecordWithModels(ctx context.Context, recordID, userID int)
 	return data, meta, nil
 }
 
-func (s *Service) UpdateWithModels(
-	ctx context.Context,
-	recordID int,
-	userID int,
-	data Data,
-	meta MetaData,
-	deviceID string,
-) error {
+func (s *Service) UpdateWithModels(ctx context.Context, userID, recordID int, req ModelRequest) error {
 	// Получаем текущую запись
 	record, err := s.repo.Get(ctx, recordID, userID)
 	if err != nil {
@@ -571,16 +705,24 @@ func (s *Service) UpdateWithModels(
 	}
 
 	// Подготовка обновленной записи
-	updatedRecord, err := s.factory.PrepareRecord(record.Type, data, meta)
+	updatedRecord, err := s.factory.PrepareRecord(record.Type, req.Data, req.Meta)
 	if err != nil {
 		return fmt.Errorf("failed to prepare updated record: %w", err)
 	}
 
+	if err := checkUnlocked(record, updatedRecord.EncryptedData, updatedRecord.Meta); err != nil {
+		return err
+	}
+
+	if err := s.checkQuota(ctx, userID, sizeDelta(record.EncryptedData, updatedRecord.EncryptedData)); err != nil {
+		return err
+	}
+
 	updatedRecord.ID = recordID
 	updatedRecord.UserID = userID
 	updatedRecord.Version = record.Version + 1
 	updatedRecord.LastModified = time.Now()
-	updatedRecord.DeviceID = deviceID
+	updatedRecord.DeviceID = req.DeviceID
 	updatedRecord.Checksum = s.generateChecksum(updatedRecord.EncryptedData, record.Type, updatedRecord.Meta)
 
 	// Сохраняем в БД
@@ -597,7 +739,7 @@ func (s *Service) UpdateWithModels(
 		"record_id", recordID,
 		"user_id", userID,
 		"version", updatedRecord.Version,
-		"device_id", deviceID,
+		"device_id", req.DeviceID,
 	)
 
 	return nil
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/slog"
 )
 
@@ -53,6 +54,14 @@ func (m *MockRepository) List(ctx context.Context, userID int) ([]Record, error)
 	return args.Get(0).([]Record), args.Error(1)
 }
 
+func (m *MockRepository) GetHead(ctx context.Context, recordID int) (*Head, error) {
+	args := m.Called(ctx, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Head), args.Error(1)
+}
+
 func (m *MockRepository) Search(ctx context.Context, userID int, criteria SearchCriteria) ([]Record, error) {
 	args := m.Called(ctx, userID, criteria)
 	if args.Get(0) == nil {
@@ -93,6 +102,32 @@ func (m *MockRepository) GetVersions(ctx context.Context, recordID int) ([]Versi
 	return args.Get(0).([]Version), args.Error(1)
 }
 
+func (m *MockRepository) ListAttachments(ctx context.Context, recordID int) ([]Attachment, error) {
+	args := m.Called(ctx, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Attachment), args.Error(1)
+}
+
+func (m *MockRepository) GetAttachment(ctx context.Context, recordID, attachmentID int) (*Attachment, error) {
+	args := m.Called(ctx, recordID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Attachment), args.Error(1)
+}
+
+func (m *MockRepository) CreateAttachment(ctx context.Context, attachment *Attachment) (int, error) {
+	args := m.Called(ctx, attachment)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteAttachment(ctx context.Context, recordID, attachmentID int) error {
+	args := m.Called(ctx, recordID, attachmentID)
+	return args.Error(0)
+}
+
 func (m *MockRepository) GetByChecksum(ctx context.Context, userID int, checksum string) (*Record, error) {
 	args := m.Called(ctx, userID, checksum)
 	if args.Get(0) == nil {
@@ -101,16 +136,99 @@ func (m *MockRepository) GetByChecksum(ctx context.Context, userID int, checksum
 	return args.Get(0).(*Record), args.Error(1)
 }
 
+func (m *MockRepository) GetByUUID(ctx context.Context, userID int, recordUUID string) (*Record, error) {
+	args := m.Called(ctx, userID, recordUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Record), args.Error(1)
+}
+
+func (m *MockRepository) ListByOrg(ctx context.Context, orgID int) ([]Record, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Record), args.Error(1)
+}
+
+func (m *MockRepository) GetShared(ctx context.Context, recordID int) (*Record, error) {
+	args := m.Called(ctx, recordID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Record), args.Error(1)
+}
+
+func (m *MockRepository) DeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	args := m.Called(ctx, orgID, recordID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SoftDeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	args := m.Called(ctx, orgID, recordID)
+	return args.Error(0)
+}
+
 func (m *MockRepository) SaveVersion(ctx context.Context, version *Version) error {
 	args := m.Called(ctx, version)
 	return args.Error(0)
 }
 
+func (m *MockRepository) ListDeleted(ctx context.Context, userID int) ([]Record, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Record), args.Error(1)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, userID, recordID int) (int, error) {
+	args := m.Called(ctx, userID, recordID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) RestoreInOrg(ctx context.Context, orgID, recordID int) (int, error) {
+	args := m.Called(ctx, orgID, recordID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error) {
+	args := m.Called(ctx, userID, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) PurgeAllDeleted(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) PurgeAllAcknowledged(ctx context.Context, before, activeSince time.Time) (int, error) {
+	args := m.Called(ctx, before, activeSince)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) ListWithExpiry(ctx context.Context, userID int) ([]Record, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Record), args.Error(1)
+}
+
+func (m *MockRepository) ListAllWithExpiry(ctx context.Context) ([]Record, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Record), args.Error(1)
+}
+
 func TestService_List(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	records := []Record{
 		{
@@ -145,7 +263,7 @@ func TestService_Create(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	encryptedData := "encrypted_data"
 	meta := json.RawMessage(`{"title": "test"}`)
@@ -158,7 +276,7 @@ func TestService_Create(t *testing.T) {
 			r.Version == 1
 	})).Return(123, nil)
 
-	recordID, err := service.Create(context.Background(), 1, RecTypeLogin, encryptedData, meta)
+	recordID, err := service.Create(context.Background(), 1, CreateRequest{Type: RecTypeLogin, EncryptedData: encryptedData, Meta: meta})
 	assert.NoError(t, err)
 	assert.Equal(t, 123, recordID)
 
@@ -169,15 +287,15 @@ func TestService_Create_InvalidData(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	// Test empty type
-	_, err := service.Create(context.Background(), 1, "", "data", nil)
+	_, err := service.Create(context.Background(), 1, CreateRequest{Type: "", EncryptedData: "data"})
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidData, err)
 
 	// Test empty encrypted data
-	_, err = service.Create(context.Background(), 1, RecTypeLogin, "", nil)
+	_, err = service.Create(context.Background(), 1, CreateRequest{Type: RecTypeLogin, EncryptedData: ""})
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidData, err)
 }
@@ -186,7 +304,7 @@ func TestService_Find(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	record := &Record{
 		ID:           1,
@@ -209,7 +327,7 @@ func TestService_Find_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	mockRepo.On("Get", mock.Anything, 1, 1).Return((*Record)(nil), ErrNotFound)
 
@@ -224,7 +342,7 @@ func TestService_Find_Deleted(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	deletedAt := time.Now()
 	record := &Record{
@@ -249,7 +367,7 @@ func TestService_Update(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	// Current record
 	currentRecord := &Record{
@@ -273,8 +391,11 @@ func TestService_Update(t *testing.T) {
 			string(r.Meta) == string(meta) &&
 			r.Version == 1 // Version should not be incremented in basic Update
 	})).Return(nil)
+	mockRepo.On("SaveVersion", mock.Anything, mock.MatchedBy(func(v *Version) bool {
+		return v.RecordID == 1 && v.Version == 1 // snapshot of the state before the update
+	})).Return(nil)
 
-	err := service.Update(context.Background(), 1, 1, RecTypeLogin, encryptedData, meta)
+	err := service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: encryptedData, Meta: meta})
 	assert.NoError(t, err)
 
 	mockRepo.AssertExpectations(t)
@@ -284,11 +405,11 @@ func TestService_Update_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	mockRepo.On("Get", mock.Anything, 1, 1).Return((*Record)(nil), ErrNotFound)
 
-	err := service.Update(context.Background(), 1, 1, RecTypeLogin, "data", nil)
+	err := service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data"})
 	assert.Error(t, err)
 	assert.Equal(t, ErrNotFound, err)
 
@@ -299,7 +420,7 @@ func TestService_Update_Deleted(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	deletedAt := time.Now()
 	record := &Record{
@@ -313,18 +434,114 @@ func TestService_Update_Deleted(t *testing.T) {
 
 	mockRepo.On("Get", mock.Anything, 1, 1).Return(record, nil)
 
-	err := service.Update(context.Background(), 1, 1, RecTypeLogin, "data", nil)
+	err := service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data"})
 	assert.Error(t, err)
 	assert.Equal(t, ErrRecordDeleted, err)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_Update_Locked(t *testing.T) {
+	mockRepo := new(MockRepository)
+	factory := NewFactory()
+	logger := slog.Default()
+	service := NewService(mockRepo, factory, nil, nil, logger)
+
+	record := &Record{
+		ID:            1,
+		UserID:        1,
+		Type:          RecTypeLogin,
+		EncryptedData: "data",
+		Meta:          json.RawMessage(`{"title":"root","locked":true}`),
+		Version:       1,
+		LastModified:  time.Now(),
+	}
+	mockRepo.On("Get", mock.Anything, 1, 1).Return(record, nil)
+
+	// Правка данных и метаданных защищенной записи запрещена
+	err := service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "changed", Meta: record.Meta})
+	assert.ErrorIs(t, err, ErrRecordLocked)
+	err = service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "changed", Meta: json.RawMessage(`{"title":"root"}`)})
+	assert.ErrorIs(t, err, ErrRecordLocked)
+	err = service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data", Meta: json.RawMessage(`{"title":"renamed","locked":true}`)})
+	assert.ErrorIs(t, err, ErrRecordLocked)
+
+	// Снятие защиты без правки данных допускается
+	unlocked := json.RawMessage(`{"title":"root"}`)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *Record) bool {
+		return r.ID == 1 && string(r.Meta) == string(unlocked)
+	})).Return(nil).Once()
+	mockRepo.On("SaveVersion", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err = service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data", Meta: unlocked})
+	assert.NoError(t, err)
+
+	// Новая пометка тоже допускается, а правка метаданных вместе с ней - нет
+	annotated, err := AddAnnotation(record.Meta, Annotation{At: time.Now(), Data: "bm90ZQ=="})
+	require.NoError(t, err)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(r *Record) bool {
+		return r.ID == 1 && string(r.Meta) == string(annotated)
+	})).Return(nil).Once()
+	mockRepo.On("SaveVersion", mock.Anything, mock.Anything).Return(nil).Once()
+
+	err = service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data", Meta: annotated})
+	assert.NoError(t, err)
+
+	renamed, err := AddAnnotation(json.RawMessage(`{"title":"renamed","locked":true}`), Annotation{At: time.Now(), Data: "bm90ZQ=="})
+	require.NoError(t, err)
+	err = service.Update(context.Background(), 1, UpdateRequest{RecordID: 1, Type: RecTypeLogin, EncryptedData: "data", Meta: renamed})
+	assert.ErrorIs(t, err, ErrRecordLocked)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Delete_Locked(t *testing.T) {
+	mockRepo := new(MockRepository)
+	factory := NewFactory()
+	logger := slog.Default()
+	service := NewService(mockRepo, factory, nil, nil, logger)
+
+	record := &Record{
+		ID:           1,
+		UserID:       1,
+		Type:         RecTypeText,
+		Meta:         json.RawMessage(`{"title":"recovery codes","locked":true}`),
+		Version:      1,
+		LastModified: time.Now(),
+	}
+	mockRepo.On("Get", mock.Anything, 1, 1).Return(record, nil)
+
+	assert.ErrorIs(t, service.Delete(context.Background(), 1, 1), ErrRecordLocked)
+	assert.ErrorIs(t, service.SoftDelete(context.Background(), 1, 1), ErrRecordLocked)
+
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSetLocked(t *testing.T) {
+	meta, err := SetLocked(json.RawMessage(`{"title":"root","tags":["infra"]}`), true)
+	require.NoError(t, err)
+	assert.True(t, IsLocked(meta))
+	assert.JSONEq(t, `{"title":"root","tags":["infra"],"locked":true}`, string(meta))
+
+	meta, err = SetLocked(meta, false)
+	require.NoError(t, err)
+	assert.False(t, IsLocked(meta))
+	assert.JSONEq(t, `{"title":"root","tags":["infra"]}`, string(meta))
+
+	meta, err = SetLocked(nil, true)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"locked":true}`, string(meta))
+
+	_, err = SetLocked(json.RawMessage(`[1]`), true)
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
 func TestService_Delete(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	record := &Record{
 		ID:           1,
@@ -348,7 +565,7 @@ func TestService_Delete_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	mockRepo.On("Get", mock.Anything, 1, 1).Return((*Record)(nil), ErrNotFound)
 
@@ -363,7 +580,7 @@ func TestService_SoftDelete(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	record := &Record{
 		ID:           1,
@@ -386,7 +603,7 @@ func TestService_SoftDelete_AlreadyDeleted(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	deletedAt := time.Now()
 	record := &Record{
@@ -410,7 +627,7 @@ func TestService_Search(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	records := []Record{
 		{
@@ -435,11 +652,56 @@ func TestService_Search(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestSearchCriteria_MetaContains(t *testing.T) {
+	doc, err := SearchCriteria{Query: "github"}.MetaContains()
+	assert.NoError(t, err)
+	assert.Nil(t, doc)
+
+	doc, err = SearchCriteria{Tags: []string{"work", "dev"}, Category: "Логины"}.MetaContains()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"tags":["work","dev"],"category":"Логины"}`, string(doc))
+
+	assert.False(t, SearchCriteria{Type: "login"}.HasMetaFilters())
+	assert.True(t, SearchCriteria{Resource: "github.com"}.HasMetaFilters())
+}
+
+func TestService_Head(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, NewFactory(), nil, nil, slog.Default())
+
+	orgID := 4
+	deletedAt := time.Now()
+	mockRepo.On("GetHead", mock.Anything, 1).Return(&Head{ID: 1, UserID: 1, Version: 2}, nil)
+	mockRepo.On("GetHead", mock.Anything, 2).Return(&Head{ID: 2, UserID: 9, Version: 1}, nil)
+	mockRepo.On("GetHead", mock.Anything, 3).Return(&Head{ID: 3, UserID: 1, Version: 4, DeletedAt: &deletedAt}, nil)
+	mockRepo.On("GetHead", mock.Anything, 4).Return(&Head{ID: 4, UserID: 1, OrgID: &orgID}, nil)
+
+	head, err := service.Head(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, head.Version)
+
+	_, err = service.Head(context.Background(), 1, 2)
+	assert.ErrorIs(t, err, ErrNotFound, "чужая запись")
+
+	head, err = service.Head(context.Background(), 1, 3)
+	assert.ErrorIs(t, err, ErrRecordDeleted)
+	assert.Equal(t, 4, head.Version)
+
+	_, err = service.Head(context.Background(), 1, 4)
+	assert.ErrorIs(t, err, ErrNotFound, "без авторизатора организаций запись недоступна")
+}
+
+func TestHead_ETag(t *testing.T) {
+	assert.Equal(t, `"2"`, (&Head{Version: 2}).ETag())
+	assert.Equal(t, `"2-0123456789abcdef"`, (&Head{Version: 2, Checksum: "0123456789abcdef0123"}).ETag())
+	assert.NotEqual(t, (&Head{Version: 2, Checksum: "aa"}).ETag(), (&Head{Version: 3, Checksum: "aa"}).ETag())
+}
+
 func TestService_GetStats(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	stats := map[string]interface{}{
 		"total_records": int64(10),
@@ -474,7 +736,7 @@ func TestService_GetModifiedSince(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	records := []Record{
 		{
@@ -501,7 +763,7 @@ func TestService_BatchCreate(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	requests := []CreateRequest{
 		{
@@ -536,7 +798,7 @@ func TestService_BatchCreate_WithErrors(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	requests := []CreateRequest{
 		{
@@ -573,7 +835,7 @@ func TestService_BatchUpdate(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	currentRecord := &Record{
 		ID:           1,
@@ -611,7 +873,7 @@ func TestService_BatchUpdate_VersionMismatch(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	currentRecord := &Record{
 		ID:           1,
@@ -649,7 +911,7 @@ func TestService_GenerateChecksum(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	encryptedData := "test_data"
 	typ := RecTypeLogin
@@ -670,7 +932,7 @@ func TestService_GetByType(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	records := []Record{
 		{
@@ -684,7 +946,7 @@ func TestService_GetByType(t *testing.T) {
 
 	mockRepo.On("GetByType", mock.Anything, 1, "login").Return(records, nil)
 
-	result, err := service.GetByType(context.Background(), 1, "login")
+	result, err := service.GetByType(context.Background(), 1, RecTypeLogin)
 	assert.NoError(t, err)
 	assert.Equal(t, records, result)
 
@@ -695,7 +957,7 @@ func TestService_GetVersions(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	record := &Record{
 		ID:           1,
@@ -730,7 +992,7 @@ func TestService_GetVersions_NotFound(t *testing.T) {
 	mockRepo := new(MockRepository)
 	factory := NewFactory()
 	logger := slog.Default()
-	service := NewService(mockRepo, factory, logger)
+	service := NewService(mockRepo, factory, nil, nil, logger)
 
 	mockRepo.On("Get", mock.Anything, 1, 1).Return((*Record)(nil), ErrNotFound)
 
@@ -739,3 +1001,74 @@ func TestService_GetVersions_NotFound(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+// stubAuthorizer выдает заданный уровень доступа к одной организации
+type stubAuthorizer struct {
+	orgID  int
+	access Access
+}
+
+func (a stubAuthorizer) Authorize(_ context.Context, orgID, _ int, access Access) error {
+	if orgID != a.orgID {
+		return ErrNotFound
+	}
+	if access > a.access {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func TestService_OrgRecords(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.Default()
+	orgID := 3
+	shared := &Record{ID: 9, UserID: 1, OrgID: &orgID, Type: RecTypeText, EncryptedData: "abcd", Version: 2}
+
+	t.Run("read-only member reads but cannot delete", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, NewFactory(), stubAuthorizer{orgID: orgID, access: AccessRead}, nil, logger)
+
+		mockRepo.On("Get", ctx, 2, 9).Return(nil, ErrNotFound)
+		mockRepo.On("GetShared", ctx, 9).Return(shared, nil)
+
+		rec, err := service.Find(ctx, 2, 9)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, rec.ID)
+
+		assert.ErrorIs(t, service.Delete(ctx, 2, 9), ErrForbidden)
+		mockRepo.AssertNotCalled(t, "DeleteInOrg", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("member deletes org record", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, NewFactory(), stubAuthorizer{orgID: orgID, access: AccessWrite}, nil, logger)
+
+		mockRepo.On("Get", ctx, 2, 9).Return(nil, ErrNotFound)
+		mockRepo.On("GetShared", ctx, 9).Return(shared, nil)
+		mockRepo.On("SaveVersion", ctx, mock.Anything).Return(nil)
+		mockRepo.On("DeleteInOrg", ctx, orgID, 9).Return(nil)
+
+		assert.NoError(t, service.Delete(ctx, 2, 9))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stranger cannot list org", func(t *testing.T) {
+		service := NewService(new(MockRepository), NewFactory(), stubAuthorizer{orgID: orgID, access: AccessWrite}, nil, logger)
+
+		_, err := service.ListOrg(ctx, 2, orgID+1)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("create in org sets org id", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, NewFactory(), stubAuthorizer{orgID: orgID, access: AccessWrite}, nil, logger)
+
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(r *Record) bool {
+			return r.OrgID != nil && *r.OrgID == orgID && r.UserID == 2
+		})).Return(11, nil)
+
+		id, err := service.CreateInOrg(ctx, 2, orgID, CreateRequest{Type: RecTypeText, EncryptedData: "abcd", Meta: json.RawMessage(`{}`)})
+		assert.NoError(t, err)
+		assert.Equal(t, 11, id)
+	})
+}
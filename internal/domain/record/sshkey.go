@@ -0,0 +1,168 @@
+package record
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKeyData - SSH-ключ и сертификат (до шифрования)
+type SSHKeyData struct {
+	PrivateKey  string `json:"private_key,omitempty"` // PEM / OpenSSH
+	PublicKey   string `json:"public_key,omitempty"`  // формат authorized_keys
+	Passphrase  string `json:"passphrase,omitempty"`
+	Certificate string `json:"certificate,omitempty"` // OpenSSH-сертификат (*-cert.pub)
+}
+
+// SSHKeyInfo - сведения, извлеченные из ключа
+type SSHKeyInfo struct {
+	KeyType     string
+	Bits        int
+	Fingerprint string
+	PublicKey   string
+}
+
+func (k *SSHKeyData) GetType() RecType {
+	return RecTypeSSHKey
+}
+
+func (k *SSHKeyData) Validate() error {
+	_, err := k.Parse()
+	return err
+}
+
+// Parse разбирает ключ и возвращает его тип, длину и отпечаток SHA256.
+// Если заданы оба ключа, проверяется, что открытый ключ соответствует закрытому.
+func (k *SSHKeyData) Parse() (*SSHKeyInfo, error) {
+	if strings.TrimSpace(k.PrivateKey) == "" && strings.TrimSpace(k.PublicKey) == "" {
+		return nil, fmt.Errorf("private or public key is required")
+	}
+
+	var pub ssh.PublicKey
+
+	if strings.TrimSpace(k.PrivateKey) != "" {
+		signer, err := k.signer()
+		if err != nil {
+			return nil, err
+		}
+		pub = signer.PublicKey()
+	}
+
+	if strings.TrimSpace(k.PublicKey) != "" {
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		if pub != nil && ssh.FingerprintSHA256(pub) != ssh.FingerprintSHA256(parsed) {
+			return nil, fmt.Errorf("public key does not match private key")
+		}
+		pub = parsed
+	}
+
+	if strings.TrimSpace(k.Certificate) != "" {
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.Certificate))
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %w", err)
+		}
+		cert, ok := parsed.(*ssh.Certificate)
+		if !ok {
+			return nil, fmt.Errorf("certificate is not an OpenSSH certificate")
+		}
+		if ssh.FingerprintSHA256(cert.Key) != ssh.FingerprintSHA256(pub) {
+			return nil, fmt.Errorf("certificate does not match key")
+		}
+	}
+
+	return &SSHKeyInfo{
+		KeyType:     pub.Type(),
+		Bits:        keyBits(pub),
+		Fingerprint: ssh.FingerprintSHA256(pub),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))),
+	}, nil
+}
+
+func (k *SSHKeyData) signer() (ssh.Signer, error) {
+	if k.Passphrase != "" {
+		signer, err := ssh.ParsePrivateKeyWithPassphrase([]byte(k.PrivateKey), []byte(k.Passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key or passphrase: %w", err)
+		}
+		return signer, nil
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(k.PrivateKey))
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("private key is encrypted, passphrase is required")
+		}
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return signer, nil
+}
+
+func keyBits(pub ssh.PublicKey) int {
+	cryptoPub, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0
+	}
+
+	switch key := cryptoPub.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return 256
+	default:
+		return 0
+	}
+}
+
+func (k *SSHKeyData) ToJSON() ([]byte, error) {
+	return json.Marshal(k)
+}
+
+func (k *SSHKeyData) FromJSON(data []byte) error {
+	return json.Unmarshal(data, k)
+}
+
+// SSHKeyMeta - метаданные SSH-ключа
+type SSHKeyMeta struct {
+	Title          string          `json:"title"`
+	KeyType        string          `json:"key_type,omitempty"` // ssh-ed25519, ssh-rsa, ecdsa-sha2-nistp256
+	Bits           int             `json:"bits,omitempty"`
+	Fingerprint    string          `json:"fingerprint,omitempty"` // SHA256:...
+	Comment        string          `json:"comment,omitempty"`
+	Hosts          []string        `json:"hosts,omitempty"`
+	HasCertificate bool            `json:"has_certificate,omitempty"`
+	Category       string          `json:"category,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+	CustomData     json.RawMessage `json:"custom_data,omitempty"`
+}
+
+func (m *SSHKeyMeta) Validate() error {
+	if strings.TrimSpace(m.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+
+	if m.Fingerprint != "" && !strings.HasPrefix(m.Fingerprint, "SHA256:") {
+		return fmt.Errorf("fingerprint must be in SHA256 format")
+	}
+
+	return nil
+}
+
+func (m *SSHKeyMeta) ToJSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+func (m *SSHKeyMeta) FromJSON(data []byte) error {
+	return json.Unmarshal(data, m)
+}
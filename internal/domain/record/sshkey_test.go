@@ -0,0 +1,92 @@
+package record
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func generateSSHKey(t *testing.T, passphrase string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "test", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, "test")
+	}
+	require.NoError(t, err)
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(block)), sshPub
+}
+
+func TestSSHKeyData_Parse(t *testing.T) {
+	privateKey, pub := generateSSHKey(t, "")
+
+	key := &SSHKeyData{PrivateKey: privateKey}
+	info, err := key.Parse()
+	require.NoError(t, err)
+
+	assert.Equal(t, ssh.KeyAlgoED25519, info.KeyType)
+	assert.Equal(t, 256, info.Bits)
+	assert.Equal(t, ssh.FingerprintSHA256(pub), info.Fingerprint)
+	assert.True(t, strings.HasPrefix(info.PublicKey, "ssh-ed25519 "))
+}
+
+func TestSSHKeyData_Parse_Passphrase(t *testing.T) {
+	privateKey, pub := generateSSHKey(t, "secret")
+
+	_, err := (&SSHKeyData{PrivateKey: privateKey}).Parse()
+	assert.ErrorContains(t, err, "passphrase is required")
+
+	_, err = (&SSHKeyData{PrivateKey: privateKey, Passphrase: "wrong"}).Parse()
+	assert.Error(t, err)
+
+	info, err := (&SSHKeyData{PrivateKey: privateKey, Passphrase: "secret"}).Parse()
+	require.NoError(t, err)
+	assert.Equal(t, ssh.FingerprintSHA256(pub), info.Fingerprint)
+}
+
+func TestSSHKeyData_Validate(t *testing.T) {
+	privateKey, pub := generateSSHKey(t, "")
+	_, otherPub := generateSSHKey(t, "")
+
+	authorized := string(ssh.MarshalAuthorizedKey(pub))
+	other := string(ssh.MarshalAuthorizedKey(otherPub))
+
+	tests := []struct {
+		name    string
+		key     SSHKeyData
+		wantErr bool
+	}{
+		{"empty", SSHKeyData{}, true},
+		{"garbage", SSHKeyData{PrivateKey: "not a key"}, true},
+		{"public only", SSHKeyData{PublicKey: authorized}, false},
+		{"matching pair", SSHKeyData{PrivateKey: privateKey, PublicKey: authorized}, false},
+		{"mismatched pair", SSHKeyData{PrivateKey: privateKey, PublicKey: other}, true},
+		{"invalid certificate", SSHKeyData{PublicKey: authorized, Certificate: authorized}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.key.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Удаленная запись (deleted_at не NULL) остается в корзине: ее можно восстановить,
+// пока пользователь не очистит корзину или не истечет срок хранения.
+// TrashPurger окончательно удаляет записи, пролежавшие в корзине дольше срока.
+// С RequireDeviceAck запись удаляется только после того, как удаление получили
+// все устройства владельца: иначе устройство, долго не выходившее в сеть, не
+// узнает об удалении и вернет запись при следующей синхронизации.
+
+// TrashConfig - параметры хранения удаленных записей
+type TrashConfig struct {
+	// Retention - срок хранения удаленных записей; 0 - хранить бессрочно
+	Retention time.Duration
+	// Interval - период запуска очистки
+	Interval time.Duration
+	// RequireDeviceAck - удалять запись только после подтверждения всеми устройствами
+	RequireDeviceAck bool
+	// DeviceAckTimeout - устройство, не синхронизировавшееся дольше, не задерживает
+	// удаление; 0 - ждать все устройства бессрочно
+	DeviceAckTimeout time.Duration
+}
+
+// DefaultTrashConfig возвращает параметры корзины по умолчанию
+func DefaultTrashConfig() *TrashConfig {
+	return &TrashConfig{
+		Retention:        30 * 24 * time.Hour,
+		Interval:         time.Hour,
+		DeviceAckTimeout: 90 * 24 * time.Hour,
+	}
+}
+
+// Validate проверяет параметры корзины
+func (c *TrashConfig) Validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("trash retention must not be negative")
+	}
+	if c.Retention > 0 && c.Interval <= 0 {
+		return fmt.Errorf("trash purge interval must be positive")
+	}
+	if c.DeviceAckTimeout < 0 {
+		return fmt.Errorf("trash device ack timeout must not be negative")
+	}
+	return nil
+}
+
+// ListTrash returns personal records of the user that are in trash
+func (s *Service) ListTrash(ctx context.Context, userID int) ([]Record, error) {
+	records, err := s.repo.ListDeleted(ctx, userID)
+	if err != nil {
+		s.log.Error("failed to list trash", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	return records, nil
+}
+
+// Restore takes a record out of trash and returns its new version
+func (s *Service) Restore(ctx context.Context, userID, recordID int) (int, error) {
+	head, err := s.repo.GetHead(ctx, recordID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("get record for restore: %w", err)
+	}
+
+	if err := s.authorizeHead(ctx, head, userID, AccessWrite); err != nil {
+		return 0, err
+	}
+
+	if head.DeletedAt == nil {
+		return 0, ErrNotDeleted
+	}
+
+	// Records in trash are not counted in the quota of their author
+	if err := s.checkQuota(ctx, head.UserID, head.Size); err != nil {
+		return 0, err
+	}
+
+	var version int
+	if head.OrgID != nil {
+		version, err = s.repo.RestoreInOrg(ctx, *head.OrgID, recordID)
+	} else {
+		version, err = s.repo.Restore(ctx, userID, recordID)
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		s.log.Error("failed to restore record", "record_id", recordID, "user_id", userID, "error", err)
+		return 0, fmt.Errorf("restore record: %w", err)
+	}
+
+	s.log.Info("record restored from trash", "record_id", recordID, "user_id", userID, "version", version)
+	return version, nil
+}
+
+// PurgeTrash permanently deletes personal records that have been in trash
+// longer than olderThan. Zero olderThan empties the trash.
+func (s *Service) PurgeTrash(ctx context.Context, userID int, olderThan time.Duration) (int, error) {
+	if olderThan < 0 {
+		return 0, ErrInvalidData
+	}
+
+	purged, err := s.repo.PurgeDeleted(ctx, userID, time.Now().Add(-olderThan))
+	if err != nil {
+		s.log.Error("failed to purge trash", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+
+	s.log.Info("trash purged", "user_id", userID, "purged", purged)
+	return purged, nil
+}
+
+// TrashPurger периодически удаляет записи, пролежавшие в корзине дольше срока хранения
+type TrashPurger struct {
+	repo   Repository
+	config *TrashConfig
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+// NewTrashPurger создает задачу очистки корзины
+func NewTrashPurger(repo Repository, config *TrashConfig, log *slog.Logger) *TrashPurger {
+	if config == nil {
+		config = DefaultTrashConfig()
+	}
+
+	return &TrashPurger{
+		repo:   repo,
+		config: config,
+		log:    log.With("component", "trash"),
+		now:    time.Now,
+	}
+}
+
+// Run запускает очистку по расписанию до отмены контекста
+func (p *TrashPurger) Run(ctx context.Context) {
+	if p.config.Retention == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	p.log.Info("scheduled trash purge started", "retention", p.config.Retention, "interval", p.config.Interval,
+		"require_device_ack", p.config.RequireDeviceAck)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.Purge(ctx)
+			if err != nil {
+				p.log.Error("scheduled trash purge failed", "error", err)
+				continue
+			}
+			if purged > 0 {
+				p.log.Info("scheduled trash purge completed", "purged", purged)
+			}
+		}
+	}
+}
+
+// Purge удаляет записи, удаленные раньше срока хранения
+func (p *TrashPurger) Purge(ctx context.Context) (int, error) {
+	if p.config.Retention == 0 {
+		return 0, nil
+	}
+
+	now := p.now()
+	before := now.Add(-p.config.Retention)
+
+	var purged int
+	var err error
+	if p.config.RequireDeviceAck {
+		var activeSince time.Time
+		if p.config.DeviceAckTimeout > 0 {
+			activeSince = now.Add(-p.config.DeviceAckTimeout)
+		}
+		purged, err = p.repo.PurgeAllAcknowledged(ctx, before, activeSince)
+	} else {
+		purged, err = p.repo.PurgeAllDeleted(ctx, before)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("purge trash: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,162 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestService_Restore(t *testing.T) {
+	deletedAt := time.Now().Add(-time.Hour)
+
+	t.Run("Personal record", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetHead", mock.Anything, 10).Return(&Head{ID: 10, UserID: 1, Version: 3, DeletedAt: &deletedAt}, nil)
+		repo.On("Restore", mock.Anything, 1, 10).Return(4, nil)
+
+		version, err := service.Restore(context.Background(), 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 4, version)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Org record requires write access", func(t *testing.T) {
+		orgID := 5
+		head := &Head{ID: 10, UserID: 2, OrgID: &orgID, DeletedAt: &deletedAt}
+
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), stubAuthorizer{orgID: orgID, access: AccessWrite}, nil, slog.Default())
+		repo.On("GetHead", mock.Anything, 10).Return(head, nil)
+		repo.On("RestoreInOrg", mock.Anything, orgID, 10).Return(2, nil)
+
+		version, err := service.Restore(context.Background(), 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, version)
+		repo.AssertExpectations(t)
+
+		readOnly := new(MockRepository)
+		service = NewService(readOnly, NewFactory(), stubAuthorizer{orgID: orgID, access: AccessRead}, nil, slog.Default())
+		readOnly.On("GetHead", mock.Anything, 10).Return(head, nil)
+
+		_, err = service.Restore(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("Record of another user", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetHead", mock.Anything, 10).Return(&Head{ID: 10, UserID: 2, DeletedAt: &deletedAt}, nil)
+
+		_, err := service.Restore(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrNotFound)
+		repo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Record not in trash", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetHead", mock.Anything, 10).Return(&Head{ID: 10, UserID: 1}, nil)
+
+		_, err := service.Restore(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrNotDeleted)
+	})
+}
+
+func TestService_PurgeTrash(t *testing.T) {
+	repo := new(MockRepository)
+	service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+	start := time.Now()
+	repo.On("PurgeDeleted", mock.Anything, 1, mock.MatchedBy(func(before time.Time) bool {
+		cutoff := start.Add(-24 * time.Hour)
+		return !before.Before(cutoff) && before.Before(cutoff.Add(time.Minute))
+	})).Return(2, nil)
+
+	purged, err := service.PurgeTrash(context.Background(), 1, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	_, err = service.PurgeTrash(context.Background(), 1, -time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	repo.AssertExpectations(t)
+}
+
+func TestTrashPurger_Purge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Deletes records past retention", func(t *testing.T) {
+		repo := new(MockRepository)
+		purger := NewTrashPurger(repo, &TrashConfig{Retention: 30 * 24 * time.Hour, Interval: time.Hour}, slog.Default())
+		purger.now = func() time.Time { return now }
+
+		repo.On("PurgeAllDeleted", mock.Anything, now.Add(-30*24*time.Hour)).Return(7, nil)
+
+		purged, err := purger.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 7, purged)
+	})
+
+	t.Run("Waits for device acknowledgements", func(t *testing.T) {
+		repo := new(MockRepository)
+		purger := NewTrashPurger(repo, &TrashConfig{
+			Retention:        24 * time.Hour,
+			Interval:         time.Hour,
+			RequireDeviceAck: true,
+			DeviceAckTimeout: 90 * 24 * time.Hour,
+		}, slog.Default())
+		purger.now = func() time.Time { return now }
+
+		repo.On("PurgeAllAcknowledged", mock.Anything, now.Add(-24*time.Hour), now.Add(-90*24*time.Hour)).Return(3, nil)
+
+		purged, err := purger.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, purged)
+		repo.AssertNotCalled(t, "PurgeAllDeleted", mock.Anything, mock.Anything)
+
+		// Без таймаута учитываются все устройства
+		purger.config.DeviceAckTimeout = 0
+		repo.On("PurgeAllAcknowledged", mock.Anything, now.Add(-24*time.Hour), time.Time{}).Return(0, nil)
+		_, err = purger.Purge(context.Background())
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Zero retention keeps records", func(t *testing.T) {
+		repo := new(MockRepository)
+		purger := NewTrashPurger(repo, &TrashConfig{}, slog.Default())
+
+		purged, err := purger.Purge(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+		repo.AssertNotCalled(t, "PurgeAllDeleted", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		repo := new(MockRepository)
+		purger := NewTrashPurger(repo, nil, slog.Default())
+
+		repo.On("PurgeAllDeleted", mock.Anything, mock.Anything).Return(0, errors.New("db down"))
+
+		_, err := purger.Purge(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestTrashConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultTrashConfig().Validate())
+	assert.NoError(t, (&TrashConfig{}).Validate())
+	assert.Error(t, (&TrashConfig{Retention: -time.Hour}).Validate())
+	assert.Error(t, (&TrashConfig{Retention: time.Hour}).Validate())
+	assert.Error(t, (&TrashConfig{DeviceAckTimeout: -time.Hour}).Validate())
+}
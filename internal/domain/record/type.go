@@ -13,6 +13,8 @@ const (
 	RecTypeText   RecType = "text"
 	RecTypeBinary RecType = "binary"
 	RecTypeCard   RecType = "card"
+	RecTypeOTP    RecType = "otp"
+	RecTypeSSHKey RecType = "ssh_key"
 )
 
 func (RecType) Schema() huma.Schema {
@@ -23,6 +25,8 @@ func (RecType) Schema() huma.Schema {
 			string(RecTypeText),
 			string(RecTypeBinary),
 			string(RecTypeCard),
+			string(RecTypeOTP),
+			string(RecTypeSSHKey),
 		},
 		Description: "Тип хранимой записи",
 		Examples:    []any{RecTypeLogin},
@@ -32,7 +36,7 @@ func (RecType) Schema() huma.Schema {
 // Validate реализует интерфейс huma.Validatable.
 func (t RecType) Validate() error {
 	switch t {
-	case RecTypeLogin, RecTypeText, RecTypeBinary, RecTypeCard:
+	case RecTypeLogin, RecTypeText, RecTypeBinary, RecTypeCard, RecTypeOTP, RecTypeSSHKey:
 		return nil
 	}
 	return fmt.Errorf("неверный тип записи: %s", t)
@@ -54,6 +58,10 @@ func (t RecType) DisplayName() string {
 		return "Бинарные данные"
 	case RecTypeCard:
 		return "Банковская карта"
+	case RecTypeOTP:
+		return "Одноразовые пароли (TOTP)"
+	case RecTypeSSHKey:
+		return "SSH-ключ"
 	default:
 		return "Неизвестный тип"
 	}
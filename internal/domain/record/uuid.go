@@ -0,0 +1,89 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// UUID - постоянный идентификатор записи, общий для клиента и сервера.
+// Клиент создает его вместе с записью, поэтому повторная отправка той же
+// записи не создает дубликат. Числовой ID остается для совместимости с
+// прежними клиентами: API принимает в пути записи и ID, и UUID.
+
+// NormalizeUUID возвращает UUID в канонической записи; пустая строка
+// заменяется новым UUID
+func NormalizeUUID(s string) (string, error) {
+	if s == "" {
+		return uuid.NewString(), nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad record uuid", ErrInvalidData)
+	}
+	return id.String(), nil
+}
+
+// ResolveUUID возвращает ID записи пользователя с UUID recordUUID, включая
+// удаленные и записи организаций, созданные пользователем
+func (s *Service) ResolveUUID(ctx context.Context, userID int, recordUUID string) (int, error) {
+	id, err := uuid.Parse(recordUUID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: record id must be a number or uuid", ErrInvalidData)
+	}
+
+	rec, err := s.repo.GetByUUID(ctx, userID, id.String())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		s.log.Error("failed to resolve record uuid", "uuid", recordUUID, "user_id", userID, "error", err)
+		return 0, fmt.Errorf("resolve record uuid: %w", err)
+	}
+	return rec.ID, nil
+}
+
+// claimUUID проверяет UUID, присланный клиентом при создании записи. Если
+// запись с этим UUID уже создана (клиент повторил запрос, не получив ответ),
+// возвращается и она. Пустой UUID остается пустым: его назначит хранилище.
+func (s *Service) claimUUID(ctx context.Context, userID int, recordUUID string) (string, *Record, error) {
+	if recordUUID == "" {
+		return "", nil, nil
+	}
+	normalized, err := NormalizeUUID(recordUUID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rec, err := s.repo.GetByUUID(ctx, userID, normalized)
+	switch {
+	case err == nil:
+		return normalized, rec, nil
+	case errors.Is(err, ErrNotFound):
+		return normalized, nil, nil
+	}
+	return "", nil, fmt.Errorf("check record uuid: %w", err)
+}
+
+// createOnce сохраняет новую запись. Повтор того же запроса может успеть
+// создать запись между claimUUID и вставкой - тогда вставка нарушает
+// уникальный индекс (user_id, uuid), и возвращается запись, созданная первой.
+func (s *Service) createOnce(ctx context.Context, rec *Record) (*Record, error) {
+	id, err := s.repo.Create(ctx, rec)
+	if err == nil {
+		rec.ID = id
+		return rec, nil
+	}
+	if !errors.Is(err, ErrDuplicateUUID) {
+		return nil, err
+	}
+
+	existing, gerr := s.repo.GetByUUID(ctx, rec.UserID, rec.UUID)
+	if gerr != nil {
+		return nil, fmt.Errorf("get concurrently created record: %w", gerr)
+	}
+	s.log.Info("record with this uuid was created concurrently", "record_id", existing.ID, "user_id", rec.UserID)
+	return existing, nil
+}
@@ -0,0 +1,118 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestNormalizeUUID(t *testing.T) {
+	generated, err := NormalizeUUID("")
+	require.NoError(t, err)
+	_, err = uuid.Parse(generated)
+	assert.NoError(t, err)
+
+	normalized, err := NormalizeUUID("6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	require.NoError(t, err)
+	assert.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", normalized)
+
+	_, err = NormalizeUUID("not-a-uuid")
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestService_Create_UUID(t *testing.T) {
+	const recordUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	ctx := context.Background()
+	meta := json.RawMessage(`{"title": "test"}`)
+
+	t.Run("new record keeps client uuid", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(nil, ErrNotFound).Once()
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(r *Record) bool {
+			return r.UUID == recordUUID
+		})).Return(7, nil).Once()
+
+		id, err := service.Create(ctx, 1, CreateRequest{
+			UUID: "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", Type: RecTypeLogin, EncryptedData: "data", Meta: meta,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 7, id)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("retry returns existing record", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(&Record{ID: 7, UUID: recordUUID}, nil).Once()
+
+		id, err := service.Create(ctx, 1, CreateRequest{UUID: recordUUID, Type: RecTypeLogin, EncryptedData: "data"})
+		require.NoError(t, err)
+		assert.Equal(t, 7, id)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent create returns the first record", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		// Параллельный запрос создает запись между проверкой UUID и вставкой
+		repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(nil, ErrNotFound).Once()
+		repo.On("Create", mock.Anything, mock.Anything).Return(0, ErrDuplicateUUID).Once()
+		repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(&Record{ID: 7, UUID: recordUUID}, nil).Once()
+
+		created, err := service.CreateRecord(ctx, 1, CreateRequest{UUID: recordUUID, Type: RecTypeLogin, EncryptedData: "data"})
+		require.NoError(t, err)
+		assert.Equal(t, 7, created.ID)
+		assert.Equal(t, recordUUID, created.UUID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("other create errors are returned", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(nil, ErrNotFound).Once()
+		repo.On("Create", mock.Anything, mock.Anything).Return(0, ErrInvalidData).Once()
+
+		_, err := service.CreateRecord(ctx, 1, CreateRequest{UUID: recordUUID, Type: RecTypeLogin, EncryptedData: "data"})
+		assert.ErrorIs(t, err, ErrInvalidData)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		repo := new(MockRepository)
+		service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+		_, err := service.Create(ctx, 1, CreateRequest{UUID: "42", Type: RecTypeLogin, EncryptedData: "data"})
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+}
+
+func TestService_ResolveUUID(t *testing.T) {
+	const recordUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	ctx := context.Background()
+	repo := new(MockRepository)
+	service := NewService(repo, NewFactory(), nil, nil, slog.Default())
+
+	repo.On("GetByUUID", mock.Anything, 1, recordUUID).Return(&Record{ID: 7, UUID: recordUUID}, nil).Once()
+	repo.On("GetByUUID", mock.Anything, 2, recordUUID).Return(nil, ErrNotFound).Once()
+
+	id, err := service.ResolveUUID(ctx, 1, recordUUID)
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	_, err = service.ResolveUUID(ctx, 2, recordUUID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = service.ResolveUUID(ctx, 1, "github")
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
@@ -0,0 +1,11 @@
+package secretlink
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	// ErrNotFound - ссылки нет, срок истек или просмотры исчерпаны
+	ErrNotFound       = apperr.New(apperr.NotFound, "secret link not found or expired").WithCode("SECRET_LINK_NOT_FOUND")
+	ErrInvalidRequest = apperr.New(apperr.Invalid, "invalid secret link request")
+
+	ErrUnauthenticated = apperr.New(apperr.Unauthorized, "user not authenticated")
+)
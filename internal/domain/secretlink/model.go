@@ -0,0 +1,79 @@
+// Package secretlink хранит одноразовые ссылки на секреты. Клиент шифрует
+// поле или запись случайным ключом и загружает только шифротекст; ключ
+// передается в фрагменте ссылки (после #) и на сервер не попадает. Сервер
+// ограничивает срок и число просмотров и удаляет шифротекст после последнего.
+package secretlink
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTTL - срок действия ссылки, если он не задан
+	DefaultTTL = 24 * time.Hour
+	// MaxTTL - наибольший срок действия ссылки
+	MaxTTL = 7 * 24 * time.Hour
+	// DefaultMaxViews - число просмотров, если оно не задано
+	DefaultMaxViews = 1
+	// MaxViewsLimit - наибольшее число просмотров
+	MaxViewsLimit = 100
+	// MaxCiphertextSize - наибольший размер шифротекста
+	MaxCiphertextSize = 64 << 10
+)
+
+// Link - ссылка на секрет без шифротекста
+type Link struct {
+	// ID - публичный случайный идентификатор из ссылки
+	ID        string    `json:"id"`
+	UserID    int       `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxViews  int       `json:"max_views"`
+	Views     int       `json:"views"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Expired сообщает, что срок действия ссылки истек к моменту now
+func (l Link) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// CreateRequest - параметры новой ссылки
+type CreateRequest struct {
+	Ciphertext []byte
+	// TTL - срок действия; 0 - DefaultTTL
+	TTL time.Duration
+	// MaxViews - число просмотров; 0 - DefaultMaxViews
+	MaxViews int
+}
+
+// normalize проверяет запрос и подставляет значения по умолчанию
+func (r *CreateRequest) normalize() error {
+	if len(r.Ciphertext) == 0 || len(r.Ciphertext) > MaxCiphertextSize {
+		return fmt.Errorf("%w: ciphertext must be 1..%d bytes", ErrInvalidRequest, MaxCiphertextSize)
+	}
+	if r.TTL == 0 {
+		r.TTL = DefaultTTL
+	}
+	if r.TTL < time.Minute || r.TTL > MaxTTL {
+		return fmt.Errorf("%w: ttl must be between 1m and %s", ErrInvalidRequest, MaxTTL)
+	}
+	if r.MaxViews == 0 {
+		r.MaxViews = DefaultMaxViews
+	}
+	if r.MaxViews < 1 || r.MaxViews > MaxViewsLimit {
+		return fmt.Errorf("%w: max views must be 1..%d", ErrInvalidRequest, MaxViewsLimit)
+	}
+	return nil
+}
+
+// newID создает публичный идентификатор ссылки: 16 случайных байт
+func newID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate secret link id: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,22 @@
+package secretlink
+
+import (
+	"context"
+	"time"
+)
+
+// Repository хранилище ссылок на секреты
+type Repository interface {
+	// Create сохраняет ссылку вместе с шифротекстом
+	Create(ctx context.Context, link *Link, ciphertext []byte) error
+	// List возвращает ссылки пользователя, включая истекшие
+	List(ctx context.Context, userID int) ([]Link, error)
+	// Delete отзывает ссылку пользователя; нет ссылки - ErrNotFound
+	Delete(ctx context.Context, userID int, id string) error
+	// Consume атомарно засчитывает просмотр действующей ссылки и возвращает
+	// шифротекст; после последнего просмотра ссылка удаляется. Нет ссылки,
+	// срок истек или просмотры исчерпаны - ErrNotFound.
+	Consume(ctx context.Context, id string, now time.Time) ([]byte, error)
+	// DeleteExpired удаляет ссылки, срок которых истек к моменту now
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
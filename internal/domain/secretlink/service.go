@@ -0,0 +1,120 @@
+package secretlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса ссылок на секреты
+type Servicer interface {
+	// Create сохраняет шифротекст и выпускает ссылку текущего пользователя
+	Create(ctx context.Context, req CreateRequest) (*Link, error)
+	// List возвращает ссылки текущего пользователя
+	List(ctx context.Context) ([]Link, error)
+	// Revoke удаляет ссылку текущего пользователя вместе с шифротекстом
+	Revoke(ctx context.Context, id string) error
+
+	// Open засчитывает просмотр и возвращает шифротекст; аутентификация не нужна
+	Open(ctx context.Context, id string) ([]byte, error)
+}
+
+// Service реализация сервиса ссылок на секреты
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+	now  func() time.Time
+}
+
+// NewService создает сервис ссылок на секреты
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log.With("component", "secret_link_service"),
+		now:  time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Link, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	// Истекшие шифротексты не нужны никому: удаляем их при выпуске новых
+	if n, err := s.repo.DeleteExpired(ctx, now); err != nil {
+		s.log.Warn("failed to delete expired secret links", "error", err)
+	} else if n > 0 {
+		s.log.Info("expired secret links deleted", "count", n)
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	link := Link{
+		ID:        id,
+		UserID:    userID,
+		ExpiresAt: now.Add(req.TTL),
+		MaxViews:  req.MaxViews,
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, &link, req.Ciphertext); err != nil {
+		return nil, fmt.Errorf("create secret link: %w", err)
+	}
+
+	s.log.Info("secret link created", "user_id", userID, "link_id", link.ID,
+		"max_views", link.MaxViews, "expires_at", link.ExpiresAt)
+	return &link, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]Link, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	links, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list secret links: %w", err)
+	}
+	return links, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, id string) error {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return fmt.Errorf("revoke secret link: %w", err)
+	}
+	s.log.Info("secret link revoked", "user_id", userID, "link_id", id)
+	return nil
+}
+
+func (s *Service) Open(ctx context.Context, id string) ([]byte, error) {
+	if id == "" {
+		return nil, ErrNotFound
+	}
+
+	ciphertext, err := s.repo.Consume(ctx, id, s.now())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("open secret link: %w", err)
+	}
+	s.log.Info("secret link opened", "link_id", id)
+	return ciphertext, nil
+}
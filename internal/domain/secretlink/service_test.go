@@ -0,0 +1,136 @@
+package secretlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, link *Link, ciphertext []byte) error {
+	args := m.Called(ctx, link, ciphertext)
+	return args.Error(0)
+}
+
+func (m *MockRepository) List(ctx context.Context, userID int) ([]Link, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Link), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, userID int, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Consume(ctx context.Context, id string, now time.Time) ([]byte, error) {
+	args := m.Called(ctx, id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestService_Create(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	ciphertext := []byte("ciphertext")
+	mockRepo.On("DeleteExpired", mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*secretlink.Link"), ciphertext).Return(nil)
+
+	link, err := service.Create(ctx, CreateRequest{Ciphertext: ciphertext, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Len(t, link.ID, 22)
+	assert.Equal(t, 5, link.UserID)
+	assert.Equal(t, DefaultMaxViews, link.MaxViews)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), link.ExpiresAt, time.Minute)
+
+	other, err := service.Create(ctx, CreateRequest{Ciphertext: ciphertext, MaxViews: 3})
+	require.NoError(t, err)
+	assert.NotEqual(t, link.ID, other.ID)
+	assert.Equal(t, 3, other.MaxViews)
+	assert.WithinDuration(t, time.Now().Add(DefaultTTL), other.ExpiresAt, time.Minute)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	tests := []struct {
+		name string
+		req  CreateRequest
+	}{
+		{"empty", CreateRequest{}},
+		{"too large", CreateRequest{Ciphertext: make([]byte, MaxCiphertextSize+1)}},
+		{"ttl too long", CreateRequest{Ciphertext: []byte("x"), TTL: MaxTTL + time.Hour}},
+		{"ttl too short", CreateRequest{Ciphertext: []byte("x"), TTL: time.Second}},
+		{"too many views", CreateRequest{Ciphertext: []byte("x"), MaxViews: MaxViewsLimit + 1}},
+		{"negative views", CreateRequest{Ciphertext: []byte("x"), MaxViews: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(ctx, tt.req)
+			assert.ErrorIs(t, err, ErrInvalidRequest)
+		})
+	}
+
+	_, err := service.Create(context.Background(), CreateRequest{Ciphertext: []byte("x")})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestService_Open(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := context.Background()
+
+	mockRepo.On("Consume", mock.Anything, "active", mock.AnythingOfType("time.Time")).Return([]byte("ciphertext"), nil)
+	mockRepo.On("Consume", mock.Anything, "burned", mock.AnythingOfType("time.Time")).Return(nil, ErrNotFound)
+	mockRepo.On("Consume", mock.Anything, "broken", mock.AnythingOfType("time.Time")).Return(nil, errors.New("db down"))
+
+	ciphertext, err := service.Open(ctx, "active")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ciphertext"), ciphertext)
+
+	_, err = service.Open(ctx, "burned")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.Open(ctx, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.Open(ctx, "broken")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_Revoke(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("Delete", mock.Anything, 5, "abc").Return(nil)
+	mockRepo.On("Delete", mock.Anything, 5, "missing").Return(ErrNotFound)
+
+	ctx := auth.WithUserID(context.Background(), 5)
+	require.NoError(t, service.Revoke(ctx, "abc"))
+	assert.ErrorIs(t, service.Revoke(ctx, "missing"), ErrNotFound)
+	assert.ErrorIs(t, service.Revoke(context.Background(), "abc"), ErrUnauthenticated)
+}
@@ -0,0 +1,10 @@
+package session
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	// ErrInvalidSession - токен неизвестен, истек или сессии не хватает второго фактора
+	ErrInvalidSession = apperr.New(apperr.Unauthorized, "invalid session").WithCode("AUTH_EXPIRED")
+	// ErrNotFound - у пользователя нет сессии с таким ID
+	ErrNotFound = apperr.New(apperr.NotFound, "session not found").WithCode("SESSION_NOT_FOUND")
+)
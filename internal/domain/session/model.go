@@ -0,0 +1,45 @@
+package session
+
+import (
+	"context"
+	"time"
+)
+
+// Session - активная сессия входа пользователя. Сам токен сервер не хранит,
+// сессия выбирается по ID.
+type Session struct {
+	ID int `json:"id"`
+	// DeviceName и DeviceFingerprint - имя и отпечаток ключа устройства, если
+	// оно зарегистрировано. UUID устройства не отдается: вместе с токеном он
+	// служил бы его идентификатором в чужих руках
+	DeviceName        string     `json:"device_name,omitempty"`
+	DeviceFingerprint string     `json:"device_fingerprint,omitempty"`
+	IPAddress         string     `json:"ip_address,omitempty"`
+	UserAgent         string     `json:"user_agent,omitempty"`
+	MFA               bool       `json:"mfa"`
+	CreatedAt         time.Time  `json:"created_at"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	// Current - сессия, с которой выполнен запрос
+	Current bool `json:"current"`
+}
+
+// Client - откуда пришел запрос, создающий или использующий сессию
+type Client struct {
+	DeviceID  string
+	IPAddress string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithClient возвращает контекст запроса от клиента c
+func WithClient(ctx context.Context, c Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, c)
+}
+
+// ClientFrom возвращает клиента запроса; false - запрос не из HTTP API
+func ClientFrom(ctx context.Context) (Client, bool) {
+	c, ok := ctx.Value(clientKey{}).(Client)
+	return c, ok
+}
@@ -7,5 +7,22 @@ import (
 
 type Repository interface {
 	Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	// CreateMFA сохраняет сессию, прошедшую второй фактор
+	CreateMFA(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	// Validate отклоняет сессии без второго фактора, если он включен у пользователя
 	Validate(ctx context.Context, tokenHash string) (int, error)
+	// DeleteByUser удаляет все сессии пользователя и возвращает их число
+	DeleteByUser(ctx context.Context, userID int) (int64, error)
+	// Touch отмечает использование сессии в момент at клиентом client.
+	// Сессию, использованную позже at - TouchInterval, не обновляет.
+	Touch(ctx context.Context, tokenHash string, client Client, at time.Time) error
+	// ListByUser возвращает неистекшие сессии пользователя, последние
+	// использованные первыми; сессия с хэшем currentHash отмечается Current
+	ListByUser(ctx context.Context, userID int, currentHash string) ([]Session, error)
+	// Delete удаляет сессию пользователя; ErrNotFound - такой сессии нет
+	Delete(ctx context.Context, userID, sessionID int) error
+	// DeleteOthers удаляет сессии пользователя, кроме сессии с хэшем keepHash
+	DeleteOthers(ctx context.Context, userID int, keepHash string) (int64, error)
+	// DeleteExpired удаляет сессии, истекшие до before
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
@@ -6,15 +6,38 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
 	"golang.org/x/exp/slog"
 )
 
+// TTL - срок действия сессии с момента входа
+const TTL = 24 * time.Hour
+
+// TouchInterval - как часто обновляется время последнего использования
+// сессии: не на каждый запрос, чтобы не писать в базу при каждом обращении
+const TouchInterval = time.Minute
+
+// DefaultCleanupInterval - интервал удаления истекших сессий
+const DefaultCleanupInterval = time.Hour
+
 type Servicer interface {
 	Create(ctx context.Context, userID int) (string, error)
+	// CreateMFA создает сессию после проверки второго фактора. Только такие
+	// сессии принимаются у пользователей с включенной двухфакторной аутентификацией.
+	CreateMFA(ctx context.Context, userID int) (string, error)
 	Validate(ctx context.Context, token string) (int, error)
+	// RevokeAll завершает все сессии пользователя
+	RevokeAll(ctx context.Context, userID int) (int64, error)
+	// List возвращает активные сессии пользователя; сессия токена token
+	// отмечается как текущая
+	List(ctx context.Context, userID int, token string) ([]Session, error)
+	// Revoke завершает одну сессию пользователя
+	Revoke(ctx context.Context, userID, sessionID int) error
+	// RevokeOthers завершает все сессии пользователя, кроме сессии токена token
+	RevokeOthers(ctx context.Context, userID int, token string) (int64, error)
 }
 
 type Service struct {
@@ -30,6 +53,14 @@ func NewService(repo Repository, log *slog.Logger) *Service {
 }
 
 func (s *Service) Create(ctx context.Context, userID int) (string, error) {
+	return s.create(ctx, userID, false)
+}
+
+func (s *Service) CreateMFA(ctx context.Context, userID int) (string, error) {
+	return s.create(ctx, userID, true)
+}
+
+func (s *Service) create(ctx context.Context, userID int, mfa bool) (string, error) {
 	// Генерация токена
 	tokenBytes := make([]byte, 32)
 	if _, err := rand.Read(tokenBytes); err != nil {
@@ -39,16 +70,130 @@ func (s *Service) Create(ctx context.Context, userID int) (string, error) {
 	token := base64.URLEncoding.EncodeToString(tokenBytes)
 	tokenHash := sha256.Sum256([]byte(token))
 
-	expiresAt := time.Now().Add(24 * time.Hour)
-	if err := s.repo.Create(ctx, userID, hex.EncodeToString(tokenHash[:]), expiresAt); err != nil {
+	expiresAt := time.Now().Add(TTL)
+	save := s.repo.Create
+	if mfa {
+		save = s.repo.CreateMFA
+	}
+	hash := hex.EncodeToString(tokenHash[:])
+	if err := save(ctx, userID, hash, expiresAt); err != nil {
 		return "", fmt.Errorf("save session: %w", err)
 	}
+	s.touch(ctx, hash)
 
 	return token, nil
 }
 
 func (s *Service) Validate(ctx context.Context, token string) (int, error) {
+	hash := hashToken(token)
+	userID, err := s.repo.Validate(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	s.touch(ctx, hash)
+	return userID, nil
+}
+
+// touch запоминает, откуда и когда использована сессия. Сбой не мешает
+// запросу: сведения нужны только для списка сессий.
+func (s *Service) touch(ctx context.Context, hash string) {
+	client, ok := ClientFrom(ctx)
+	if !ok {
+		return
+	}
+	if err := s.repo.Touch(ctx, hash, client, time.Now()); err != nil {
+		s.log.WarnContext(ctx, "failed to update session usage", "error", err)
+	}
+}
+
+func (s *Service) RevokeAll(ctx context.Context, userID int) (int64, error) {
+	n, err := s.repo.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("delete sessions: %w", err)
+	}
+	return n, nil
+}
+
+func (s *Service) List(ctx context.Context, userID int, token string) ([]Session, error) {
+	sessions, err := s.repo.ListByUser(ctx, userID, hashToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, userID, sessionID int) error {
+	if err := s.repo.Delete(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return fmt.Errorf("delete session: %w", err)
+	}
+	s.log.InfoContext(ctx, "session revoked", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+func (s *Service) RevokeOthers(ctx context.Context, userID int, token string) (int64, error) {
+	n, err := s.repo.DeleteOthers(ctx, userID, hashToken(token))
+	if err != nil {
+		return 0, fmt.Errorf("delete sessions: %w", err)
+	}
+	s.log.InfoContext(ctx, "other sessions revoked", "user_id", userID, "revoked", n)
+	return n, nil
+}
+
+// hashToken возвращает хэш токена, под которым хранится сессия
+func hashToken(token string) string {
 	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
 
-	return s.repo.Validate(ctx, hex.EncodeToString(tokenHash[:]))
+// Cleaner удаляет истекшие сессии: они уже не принимаются, но занимают
+// место и попадали бы в выборки по пользователю
+type Cleaner struct {
+	repo     Repository
+	interval time.Duration
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// NewCleaner создает задачу очистки с интервалом DefaultCleanupInterval
+func NewCleaner(repo Repository, log *slog.Logger) *Cleaner {
+	return &Cleaner{
+		repo:     repo,
+		interval: DefaultCleanupInterval,
+		log:      log.With("component", "session_cleaner"),
+		now:      time.Now,
+	}
+}
+
+// Run запускает очистку по расписанию до отмены контекста
+func (c *Cleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := c.Cleanup(ctx)
+			if err != nil {
+				c.log.Error("scheduled session cleanup failed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				c.log.Info("expired sessions deleted", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// Cleanup удаляет сессии, срок которых истек
+func (c *Cleaner) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := c.repo.DeleteExpired(ctx, c.now())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	return deleted, nil
 }
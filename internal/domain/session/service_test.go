@@ -21,11 +21,47 @@ func (m *MockRepository) Create(ctx context.Context, userID int, tokenHash strin
 	return args.Error(0)
 }
 
+func (m *MockRepository) CreateMFA(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
 func (m *MockRepository) Validate(ctx context.Context, tokenHash string) (int, error) {
 	args := m.Called(ctx, tokenHash)
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Touch(ctx context.Context, tokenHash string, client Client, at time.Time) error {
+	args := m.Called(ctx, tokenHash, client, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListByUser(ctx context.Context, userID int, currentHash string) ([]Session, error) {
+	args := m.Called(ctx, userID, currentHash)
+	sessions, _ := args.Get(0).([]Session)
+	return sessions, args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, userID, sessionID int) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteOthers(ctx context.Context, userID int, keepHash string) (int64, error) {
+	args := m.Called(ctx, userID, keepHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestService_Create(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -49,6 +85,20 @@ func TestService_Create(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_CreateMFA(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	mockRepo.On("CreateMFA", mock.Anything, 123, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+
+	token, err := service.CreateMFA(context.Background(), 123)
+	assert.NoError(t, err)
+	assert.Len(t, token, 44)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestService_Create_RepositoryError(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -231,3 +281,60 @@ func TestService_Validate_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestService_TouchWithClient(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	client := Client{DeviceID: "device", IPAddress: "192.0.2.1", UserAgent: "cli"}
+	ctx := WithClient(context.Background(), client)
+
+	mockRepo.On("Create", mock.Anything, 123, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+	mockRepo.On("Validate", mock.Anything, mock.AnythingOfType("string")).Return(123, nil)
+	// Сбой учета использования не отклоняет запрос
+	mockRepo.On("Touch", mock.Anything, mock.AnythingOfType("string"), client, mock.AnythingOfType("time.Time")).
+		Return(errors.New("database error"))
+
+	token, err := service.Create(ctx, 123)
+	assert.NoError(t, err)
+	userID, err := service.Validate(ctx, token)
+	assert.NoError(t, err)
+	assert.Equal(t, 123, userID)
+
+	mockRepo.AssertNumberOfCalls(t, "Touch", 2)
+}
+
+func TestService_ListAndRevoke(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := context.Background()
+	token := "current-token"
+
+	mockRepo.On("ListByUser", mock.Anything, 123, hashToken(token)).Return([]Session{{ID: 1, Current: true}, {ID: 2}}, nil)
+	sessions, err := service.List(ctx, 123, token)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	mockRepo.On("Delete", mock.Anything, 123, 2).Return(nil)
+	mockRepo.On("Delete", mock.Anything, 123, 9).Return(ErrNotFound)
+	assert.NoError(t, service.Revoke(ctx, 123, 2))
+	assert.ErrorIs(t, service.Revoke(ctx, 123, 9), ErrNotFound)
+
+	mockRepo.On("DeleteOthers", mock.Anything, 123, hashToken(token)).Return(int64(3), nil)
+	n, err := service.RevokeOthers(ctx, 123, token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCleaner_Cleanup(t *testing.T) {
+	mockRepo := new(MockRepository)
+	cleaner := NewCleaner(mockRepo, slog.Default())
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	cleaner.now = func() time.Time { return now }
+	mockRepo.On("DeleteExpired", mock.Anything, now).Return(int64(4), nil)
+
+	deleted, err := cleaner.Cleanup(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+}
@@ -0,0 +1,14 @@
+package settings
+
+// GetResponse ответ с настройками пользователя
+type GetResponse struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Data   map[string]string `json:"data,omitempty"`
+}
+
+// UpdateRequest частичное обновление настроек.
+// Значение null сбрасывает настройку к значению по умолчанию.
+type UpdateRequest struct {
+	Settings map[string]*string `json:"settings"`
+}
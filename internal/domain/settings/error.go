@@ -0,0 +1,10 @@
+package settings
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrUnknownKey   = apperr.New(apperr.Invalid, "unknown setting").WithCode("UNKNOWN_SETTING")
+	ErrInvalidValue = apperr.New(apperr.Invalid, "invalid setting value").WithCode("INVALID_SETTING_VALUE")
+
+	ErrUnauthenticated = apperr.New(apperr.Unauthorized, "user not authenticated")
+)
@@ -0,0 +1,49 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Поддерживаемые ключи настроек пользователя
+const (
+	KeyConflictStrategy = "conflict_strategy" // стратегия разрешения конфликтов по умолчанию
+	KeyNotifications    = "notifications"     // включены ли уведомления
+	KeyRetentionDays    = "retention_days"    // срок хранения удаленных записей в днях
+)
+
+// validators проверяют значения для каждого поддерживаемого ключа
+var validators = map[string]func(value string) error{
+	KeyConflictStrategy: func(value string) error {
+		switch value {
+		case "client", "server", "newer", "merge", "manual":
+			return nil
+		}
+		return fmt.Errorf("must be one of client, server, newer, merge, manual")
+	},
+	KeyNotifications: func(value string) error {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		return nil
+	},
+	KeyRetentionDays: func(value string) error {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			return fmt.Errorf("must be a positive integer")
+		}
+		return nil
+	},
+}
+
+// Validate проверяет ключ и значение настройки
+func Validate(key, value string) error {
+	validate, ok := validators[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	if err := validate(value); err != nil {
+		return fmt.Errorf("%w: %s %v", ErrInvalidValue, key, err)
+	}
+	return nil
+}
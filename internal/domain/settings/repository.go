@@ -0,0 +1,12 @@
+package settings
+
+import "context"
+
+// Repository интерфейс хранилища настроек пользователя
+type Repository interface {
+	// Get возвращает все заданные настройки пользователя
+	Get(ctx context.Context, userID int) (map[string]string, error)
+
+	// Set сохраняет значения и удаляет ключи из списка remove в одной транзакции
+	Set(ctx context.Context, userID int, values map[string]string, remove []string) error
+}
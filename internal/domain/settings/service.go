@@ -0,0 +1,84 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса настроек пользователя
+type Servicer interface {
+	// Get возвращает настройки текущего пользователя
+	Get(ctx context.Context) (*GetResponse, error)
+
+	// Update частично обновляет настройки текущего пользователя
+	Update(ctx context.Context, req UpdateRequest) (*GetResponse, error)
+}
+
+// Service реализация сервиса настроек
+type Service struct {
+	repo Repository
+	log  *slog.Logger
+}
+
+// NewService создает новый сервис настроек
+func NewService(repo Repository, log *slog.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Get возвращает настройки текущего пользователя
+func (s *Service) Get(ctx context.Context) (*GetResponse, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	values, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	return &GetResponse{
+		Status: "Ok",
+		Data:   values,
+	}, nil
+}
+
+// Update частично обновляет настройки текущего пользователя
+func (s *Service) Update(ctx context.Context, req UpdateRequest) (*GetResponse, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	values := make(map[string]string)
+	var remove []string
+
+	for key, value := range req.Settings {
+		if value == nil {
+			if _, known := validators[key]; !known {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+			}
+			remove = append(remove, key)
+			continue
+		}
+
+		if err := Validate(key, *value); err != nil {
+			return nil, err
+		}
+		values[key] = *value
+	}
+
+	if err := s.repo.Set(ctx, userID, values, remove); err != nil {
+		return nil, fmt.Errorf("failed to update settings: %w", err)
+	}
+
+	s.log.Info("user settings updated", "user_id", userID, "updated", len(values), "reset", len(remove))
+
+	return s.Get(ctx)
+}
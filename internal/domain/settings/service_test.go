@@ -0,0 +1,107 @@
+package settings
+
+import (
+	"context"
+	"testing"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Get(ctx context.Context, userID int) (map[string]string, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockRepository) Set(ctx context.Context, userID int, values map[string]string, remove []string) error {
+	args := m.Called(ctx, userID, values, remove)
+	return args.Error(0)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestService_Get(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	userID := 5
+	mockRepo.On("Get", mock.Anything, userID).Return(map[string]string{KeyConflictStrategy: "merge"}, nil)
+
+	response, err := service.Get(auth.WithUserID(context.Background(), userID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Ok", response.Status)
+	assert.Equal(t, "merge", response.Data[KeyConflictStrategy])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Get_NotAuthenticated(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default())
+
+	_, err := service.Get(context.Background())
+	assert.Error(t, err)
+}
+
+func TestService_Update(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+
+	userID := 5
+	mockRepo.On("Set", mock.Anything, userID,
+		map[string]string{KeyConflictStrategy: "server", KeyRetentionDays: "30"},
+		[]string{KeyNotifications},
+	).Return(nil)
+	mockRepo.On("Get", mock.Anything, userID).Return(map[string]string{KeyConflictStrategy: "server", KeyRetentionDays: "30"}, nil)
+
+	response, err := service.Update(auth.WithUserID(context.Background(), userID), UpdateRequest{
+		Settings: map[string]*string{
+			KeyConflictStrategy: strPtr("server"),
+			KeyRetentionDays:    strPtr("30"),
+			KeyNotifications:    nil,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "server", response.Data[KeyConflictStrategy])
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Update_Invalid(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default())
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	tests := []struct {
+		name     string
+		settings map[string]*string
+		wantErr  error
+	}{
+		{"unknown key", map[string]*string{"theme": strPtr("dark")}, ErrUnknownKey},
+		{"unknown key reset", map[string]*string{"theme": nil}, ErrUnknownKey},
+		{"bad strategy", map[string]*string{KeyConflictStrategy: strPtr("random")}, ErrInvalidValue},
+		{"bad bool", map[string]*string{KeyNotifications: strPtr("maybe")}, ErrInvalidValue},
+		{"bad retention", map[string]*string{KeyRetentionDays: strPtr("-1")}, ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Update(ctx, UpdateRequest{Settings: tt.settings})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	mockRepo.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
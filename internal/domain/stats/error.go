@@ -0,0 +1,9 @@
+package stats
+
+import (
+	"fmt"
+
+	"gophkeeper/internal/domain/apperr"
+)
+
+var ErrInvalidPeriod = apperr.New(apperr.Invalid, fmt.Sprintf("period must be between 1 and %d days", MaxDays))
@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"time"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Day - изменения личных записей пользователя за сутки (UTC)
+type Day struct {
+	Day string `json:"day" doc:"Дата в формате YYYY-MM-DD (UTC)"`
+	// Created - созданные и восстановленные из корзины записи
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+	// Deleted - перемещенные в корзину и удаленные записи
+	Deleted int64 `json:"deleted"`
+	// SizeDelta - изменение объема зашифрованных данных в байтах
+	SizeDelta int64 `json:"size_delta"`
+	// Records и Size - число записей и их объем на конец дня
+	Records int64 `json:"records"`
+	Size    int64 `json:"size"`
+}
+
+// Active сообщает, что в этот день записи менялись
+func (d Day) Active() bool {
+	return d.Created+d.Updated+d.Deleted > 0
+}
+
+// Device - активность устройства пользователя
+type Device struct {
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	Status       sync.DeviceStatus `json:"status"`
+	LastSyncTime time.Time         `json:"last_sync_time"`
+	Usage        sync.DeviceUsage  `json:"usage"`
+}
+
+// Stats - статистика хранилища пользователя: записи по типам, рост за период
+// и активность устройств. Содержимое записей зашифровано на клиенте, поэтому
+// сервер считает только количество и объемы.
+type Stats struct {
+	Records record.StatsResponse `json:"records"`
+	// Days - длина периода Growth в днях
+	Days int `json:"days"`
+	// Growth - изменения по дням за период, от старых к новым; дни без
+	// изменений включены, чтобы ряд был непрерывным
+	Growth  []Day    `json:"growth"`
+	Devices []Device `json:"devices"`
+}
@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+)
+
+// Repository интерфейс хранилища ежедневной статистики записей
+type Repository interface {
+	// Daily возвращает дни с изменениями записей пользователя начиная с даты
+	// since (UTC) по возрастанию. Records и Size не заполняются.
+	Daily(ctx context.Context, userID int, since time.Time) ([]Day, error)
+}
+
+// RecordStats считает записи пользователя по типам
+type RecordStats interface {
+	GetStats(ctx context.Context, userID int) (record.StatsResponse, error)
+}
+
+// DeviceRepository возвращает устройства пользователя
+type DeviceRepository interface {
+	ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error)
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// DefaultDays - период роста по умолчанию
+	DefaultDays = 30
+	// MaxDays - самый длинный период роста
+	MaxDays = 365
+
+	dayLayout = "2006-01-02"
+)
+
+// Servicer интерфейс сервиса статистики
+type Servicer interface {
+	// Get возвращает статистику пользователя с ростом за последние days дней,
+	// включая текущий
+	Get(ctx context.Context, userID, days int) (*Stats, error)
+}
+
+// Service собирает статистику из записей, ежедневных изменений и устройств
+type Service struct {
+	repo    Repository
+	records RecordStats
+	devices DeviceRepository
+	log     *slog.Logger
+	now     func() time.Time
+}
+
+func NewService(repo Repository, records RecordStats, devices DeviceRepository, log *slog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		records: records,
+		devices: devices,
+		log:     log.With("component", "stats_service"),
+		now:     time.Now,
+	}
+}
+
+func (s *Service) Get(ctx context.Context, userID, days int) (*Stats, error) {
+	if days < 1 || days > MaxDays {
+		return nil, ErrInvalidPeriod
+	}
+
+	records, err := s.records.GetStats(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get record stats: %w", err)
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, 1-days)
+	changes, err := s.repo.Daily(ctx, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("get daily stats: %w", err)
+	}
+
+	growth := growthSeries(changes, since, days, records.TotalRecords, records.TotalSize)
+	records.ActiveDays = 0
+	for _, d := range growth {
+		if d.Active() {
+			records.ActiveDays++
+		}
+	}
+
+	infos, err := s.devices.ListUserDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	devices := make([]Device, 0, len(infos))
+	for _, d := range infos {
+		devices = append(devices, Device{
+			ID:           d.ID,
+			Name:         d.Name,
+			Type:         d.Type,
+			Status:       d.Status,
+			LastSyncTime: d.LastSyncTime,
+			Usage:        d.Usage,
+		})
+	}
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].LastSyncTime.After(devices[j].LastSyncTime)
+	})
+
+	return &Stats{
+		Records: records,
+		Days:    days,
+		Growth:  growth,
+		Devices: devices,
+	}, nil
+}
+
+// growthSeries дополняет дни с изменениями днями без изменений и считает
+// число и объем записей на конец каждого дня, отматывая изменения назад от
+// текущих значений
+func growthSeries(changes []Day, since time.Time, days int, records, size int64) []Day {
+	byDay := make(map[string]Day, len(changes))
+	for _, d := range changes {
+		byDay[d.Day] = d
+	}
+
+	growth := make([]Day, days)
+	for i := days - 1; i >= 0; i-- {
+		key := since.AddDate(0, 0, i).Format(dayLayout)
+		d, ok := byDay[key]
+		if !ok {
+			d = Day{Day: key}
+		}
+		d.Records, d.Size = records, size
+		records -= d.Created - d.Deleted
+		size -= d.SizeDelta
+		growth[i] = d
+	}
+	return growth
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Daily(ctx context.Context, userID int, since time.Time) ([]Day, error) {
+	args := m.Called(ctx, userID, since)
+	days, _ := args.Get(0).([]Day)
+	return days, args.Error(1)
+}
+
+type MockRecords struct {
+	mock.Mock
+}
+
+func (m *MockRecords) GetStats(ctx context.Context, userID int) (record.StatsResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(record.StatsResponse), args.Error(1)
+}
+
+type MockDevices struct {
+	mock.Mock
+}
+
+func (m *MockDevices) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+	args := m.Called(ctx, userID)
+	devices, _ := args.Get(0).([]*sync.DeviceInfo)
+	return devices, args.Error(1)
+}
+
+func newTestService(now time.Time) (*Service, *MockRepository, *MockRecords, *MockDevices) {
+	repo, records, devices := new(MockRepository), new(MockRecords), new(MockDevices)
+	s := NewService(repo, records, devices, slog.Default())
+	s.now = func() time.Time { return now }
+	return s, repo, records, devices
+}
+
+func TestService_Get(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC)
+	since := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+
+	s, repo, records, devices := newTestService(now)
+	records.On("GetStats", mock.Anything, 1).Return(record.StatsResponse{
+		TotalRecords: 5,
+		TotalSize:    500,
+		ByType:       map[string]record.TypeStats{"text": {Count: 5, Size: 500}},
+	}, nil)
+	repo.On("Daily", mock.Anything, 1, since).Return([]Day{
+		{Day: "2024-03-08", Created: 3, SizeDelta: 300},
+		{Day: "2024-03-10", Created: 3, Updated: 2, Deleted: 1, SizeDelta: 150},
+	}, nil)
+	devices.On("ListUserDevices", mock.Anything, 1).Return([]*sync.DeviceInfo{
+		{ID: 1, Name: "laptop", LastSyncTime: now.Add(-48 * time.Hour)},
+		{ID: 2, Name: "phone", LastSyncTime: now.Add(-time.Hour), Usage: sync.DeviceUsage{Requests: 7}},
+	}, nil)
+
+	stats, err := s.Get(context.Background(), 1, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.Days)
+	assert.Equal(t, []Day{
+		{Day: "2024-03-08", Created: 3, SizeDelta: 300, Records: 3, Size: 350},
+		{Day: "2024-03-09", Records: 3, Size: 350},
+		{Day: "2024-03-10", Created: 3, Updated: 2, Deleted: 1, SizeDelta: 150, Records: 5, Size: 500},
+	}, stats.Growth)
+	assert.Equal(t, 2, stats.Records.ActiveDays)
+	assert.Equal(t, int64(5), stats.Records.TotalRecords)
+
+	require.Len(t, stats.Devices, 2)
+	assert.Equal(t, "phone", stats.Devices[0].Name, "недавно синхронизированные устройства первыми")
+	assert.Equal(t, int64(7), stats.Devices[0].Usage.Requests)
+}
+
+func TestService_GetErrors(t *testing.T) {
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Invalid period", func(t *testing.T) {
+		s, _, _, _ := newTestService(now)
+		for _, days := range []int{0, -1, MaxDays + 1} {
+			_, err := s.Get(context.Background(), 1, days)
+			assert.ErrorIs(t, err, ErrInvalidPeriod)
+		}
+	})
+
+	t.Run("Repository error", func(t *testing.T) {
+		s, repo, records, _ := newTestService(now)
+		records.On("GetStats", mock.Anything, 1).Return(record.StatsResponse{}, nil)
+		repo.On("Daily", mock.Anything, 1, mock.Anything).Return(nil, errors.New("db down"))
+
+		_, err := s.Get(context.Background(), 1, DefaultDays)
+		assert.ErrorContains(t, err, "db down")
+	})
+}
@@ -0,0 +1,47 @@
+package sync
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor - позиция в ленте изменений: время изменения и ID последней
+// отданной записи. Записи упорядочены по (last_modified, id), поэтому
+// следующая страница начинается строго после курсора, и запись, измененная
+// во время постраничной выборки, не пропадает, а приходит на одной из
+// следующих страниц. Смещение (offset) такой гарантии не дает.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   int       `json:"id,omitempty"`
+}
+
+// Encode возвращает непрозрачный токен курсора для клиента
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor разбирает токен, выданный сервером в GetChangesResponse.NextCursor
+func ParseCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.ID < 0 {
+		return Cursor{}, fmt.Errorf("%w: negative record id", ErrInvalidCursor)
+	}
+
+	return c, nil
+}
+
+// cursorOf возвращает курсор сразу после записи
+func cursorOf(rec *RecordSync) Cursor {
+	return Cursor{Time: rec.LastModified, ID: rec.ID}
+}
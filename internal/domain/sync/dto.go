@@ -6,22 +6,60 @@ import (
 
 // DTO (Data Transfer Objects) для API синхронизации
 
-// GetChangesRequest запрос на получение изменений
+// GetChangesRequest запрос на получение изменений.
+// Cursor - токен из NextCursor предыдущего ответа; если он задан, LastSyncTime
+// и Offset не используются. Без курсора выборка начинается после LastSyncTime
+// (клиенты прежних версий). Filter ограничивает выборку; курсор, полученный
+// с одним фильтром, с другим не используется: пропущенные записи остались
+// бы позади курсора.
 type GetChangesRequest struct {
+	Cursor       string    `json:"cursor,omitempty" maxLength:"512"`
 	LastSyncTime time.Time `json:"last_sync_time" example:"2024-01-01T12:00:00Z" format:"date-time"`
 	Limit        int       `json:"limit" minimum:"1" maximum:"1000" default:"100"`
-	Offset       int       `json:"offset" minimum:"0" default:"0"`
+	Offset       int       `json:"offset" minimum:"0" default:"0" doc:"Deprecated: use cursor"`
+	Filter       *Filter   `json:"filter,omitempty"`
+	// DeviceUUID - устройство из заголовка X-Device-ID; заполняет обработчик
+	DeviceUUID string `json:"-"`
 }
 
 // GetChangesResponse ответ с изменениями
 type GetChangesResponse struct {
-	Status      string       `json:"status"`
-	Error       string       `json:"error,omitempty"`
-	Records     []RecordSync `json:"records,omitempty"`
-	HasMore     bool         `json:"has_more,omitempty"`
-	ServerTime  time.Time    `json:"server_time,omitempty"`
-	SyncVersion int64        `json:"sync_version,omitempty"`
-	Stats       *StatsBrief  `json:"stats,omitempty"`
+	Status  string       `json:"status"`
+	Error   string       `json:"error,omitempty"`
+	Records []RecordSync `json:"records,omitempty"`
+	HasMore bool         `json:"has_more,omitempty"`
+	// NextCursor - токен для следующего запроса; клиент сохраняет его после
+	// применения записей и передает в Cursor, даже если HasMore = false
+	NextCursor  string      `json:"next_cursor,omitempty"`
+	ServerTime  time.Time   `json:"server_time,omitempty"`
+	SyncVersion int64       `json:"sync_version,omitempty"`
+	Stats       *StatsBrief `json:"stats,omitempty"`
+}
+
+// NegotiateRequest - индекс записей клиента (ID, версия, контрольная сумма).
+// Клиент отправляет его после потери курсора синхронизации или переустановки.
+type NegotiateRequest struct {
+	Records []RecordDigest `json:"records" maxItems:"100000"`
+	// Filter - записи сервера вне фильтра не попадают в Missing и Changed
+	Filter *Filter `json:"filter,omitempty"`
+}
+
+// NegotiateResponse - ID записей, которые клиенту нужно загрузить или удалить
+type NegotiateResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Changed - записи, версия или контрольная сумма которых отличается от клиентской
+	Changed []int `json:"changed,omitempty"`
+	// Missing - записи сервера, которых нет в индексе клиента
+	Missing []int `json:"missing,omitempty"`
+	// Deleted - записи, удаленные на сервере, но живые у клиента
+	Deleted []int `json:"deleted,omitempty"`
+	// Unknown - записи клиента, которых нет на сервере
+	Unknown    []int     `json:"unknown,omitempty"`
+	Unchanged  int       `json:"unchanged"`
+	ServerTime time.Time `json:"server_time,omitempty"`
+	// Cursor - курсор на момент сверки: дальше клиент запрашивает изменения с него
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // BatchSyncRequest запрос на пакетную синхронизацию
@@ -36,6 +74,27 @@ type BatchSyncResponse struct {
 	Processed int      `json:"processed,omitempty"`
 	Failed    int      `json:"failed,omitempty"`
 	Errors    []string `json:"errors,omitempty"`
+	// Results - итог по каждой записи запроса в том же порядке
+	Results []BatchRecordResult `json:"results,omitempty"`
+}
+
+// Итог обработки записи пакета
+const (
+	BatchRecordSaved    = "saved"
+	BatchRecordConflict = "conflict"
+	BatchRecordFailed   = "failed"
+)
+
+// BatchRecordResult итог обработки одной записи пакета. Для сохраненной
+// записи ID и Version - серверные: по ним клиент связывает новую локальную
+// запись с серверной.
+type BatchRecordResult struct {
+	Index   int    `json:"index"`
+	Status  string `json:"status" enum:"saved,conflict,failed"`
+	ID      int    `json:"id,omitempty"`
+	UUID    string `json:"uuid,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
 }
 
 // GetStatusResponse ответ со статусом синхронизации
@@ -72,6 +131,41 @@ type GetDevicesResponse struct {
 	Data   []DeviceInfo `json:"data,omitempty"`
 }
 
+// RegisterDeviceRequest запрос на регистрацию устройства.
+// ClaimID - ID существующей записи устройства, которую клиент забирает
+// себе после переустановки, если его прежний UUID утерян.
+type RegisterDeviceRequest struct {
+	UUID    string `json:"uuid" format:"uuid"`
+	Name    string `json:"name" minLength:"1" maxLength:"255"`
+	Type    string `json:"type,omitempty" enum:"desktop,mobile,web" default:"desktop"`
+	ClaimID int    `json:"claim_id,omitempty" minimum:"0"`
+	// PublicKey - открытый ключ Ed25519 устройства в base64
+	PublicKey string `json:"public_key,omitempty" maxLength:"64"`
+}
+
+// RegisterDeviceResponse ответ на регистрацию устройства
+type RegisterDeviceResponse struct {
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Data    *DeviceInfo `json:"data,omitempty"`
+	Claimed bool        `json:"claimed,omitempty"`
+	Merged  int         `json:"merged,omitempty"` // удалено дубликатов устройства
+}
+
+// ApproveDeviceRequest запрос на подтверждение устройства. Fingerprint -
+// отпечаток ключа, который показывает новое устройство: сервер сверяет его
+// с ключом подтверждаемого устройства, поэтому он обязателен.
+type ApproveDeviceRequest struct {
+	Fingerprint string `json:"fingerprint" minLength:"1" maxLength:"64"`
+}
+
+// ApproveDeviceResponse ответ на подтверждение устройства
+type ApproveDeviceResponse struct {
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Data   *DeviceInfo `json:"data,omitempty"`
+}
+
 // RemoveDeviceResponse ответ на удаление устройства
 type RemoveDeviceResponse struct {
 	Status  string `json:"status"`
@@ -87,3 +181,22 @@ type StatsBrief struct {
 	TotalConflicts  int     `json:"total_conflicts"`
 	TotalResolved   int     `json:"total_resolved"`
 }
+
+// Capabilities эффективные параметры сервиса синхронизации
+type Capabilities struct {
+	BatchSize                 int   `json:"batch_size"`
+	MaxSyncRecords            int   `json:"max_sync_records"`
+	ConflictTTLSeconds        int64 `json:"conflict_ttl_seconds"`
+	DeviceSyncIntervalSeconds int64 `json:"device_sync_interval_seconds"`
+	StorageLimit              int64 `json:"storage_limit"`
+	// Protocol - протокол синхронизации пользователя; Protocols - все поддерживаемые
+	Protocol  Protocol   `json:"protocol,omitempty"`
+	Protocols []Protocol `json:"protocols,omitempty"`
+}
+
+// GetCapabilitiesResponse ответ с параметрами сервиса синхронизации
+type GetCapabilitiesResponse struct {
+	Status string        `json:"status"`
+	Error  string        `json:"error,omitempty"`
+	Data   *Capabilities `json:"data,omitempty"`
+}
@@ -1,8 +1,40 @@
 package sync
 
-import "errors"
+import "gophkeeper/internal/domain/apperr"
 
 var (
-	ErrDeviceNotFound = errors.New("device not found")
-	ErrRecordNotFound = errors.New("record not found")
+	ErrDeviceNotFound  = apperr.New(apperr.NotFound, "device not found").WithCode("DEVICE_NOT_FOUND")
+	ErrInvalidDevice   = apperr.New(apperr.Invalid, "invalid device")
+	ErrRecordNotFound  = apperr.New(apperr.NotFound, "record not found").WithCode("RECORD_NOT_FOUND")
+	ErrInvalidConfig   = apperr.New(apperr.Invalid, "invalid sync config")
+	ErrInvalidCursor   = apperr.New(apperr.Invalid, "invalid sync cursor").WithCode("INVALID_SYNC_CURSOR")
+	ErrInvalidFilter   = apperr.New(apperr.Invalid, "invalid sync filter")
+	ErrInvalidProtocol = apperr.New(apperr.Invalid, "unsupported sync protocol").WithCode("UNSUPPORTED_SYNC_PROTOCOL")
+	ErrUserNotFound    = apperr.New(apperr.NotFound, "user not found")
+
+	// ErrProtocolDisabled - пользователю не включен запрошенный протокол;
+	// клиент должен перечитать /api/sync/capabilities
+	ErrProtocolDisabled = apperr.New(apperr.Conflict, "sync protocol is not enabled for this account").WithCode("SYNC_PROTOCOL_DISABLED")
+
+	ErrVersionNotNewer = apperr.New(apperr.Conflict, "record version is not newer than on server").WithCode("VERSION_NOT_NEWER")
+
+	ErrUnauthenticated  = apperr.New(apperr.Unauthorized, "user not authenticated")
+	ErrStorageLimit     = apperr.New(apperr.QuotaExceeded, "storage limit exceeded").WithCode("STORAGE_LIMIT")
+	ErrConflictNotOwned = apperr.New(apperr.Forbidden, "conflict does not belong to user")
+	ErrDeviceNotOwned   = apperr.New(apperr.Forbidden, "device does not belong to user").WithCode("DEVICE_NOT_OWNED")
+
+	// ErrDevicePending - устройство еще не подтверждено с доверенного устройства
+	ErrDevicePending = apperr.New(apperr.Forbidden, "device is awaiting approval from a trusted device").WithCode("DEVICE_PENDING")
+	// ErrDeviceUnregistered - запрос без X-Device-ID или от незарегистрированного устройства
+	ErrDeviceUnregistered = apperr.New(apperr.Forbidden, "device is not registered").WithCode("DEVICE_UNREGISTERED")
+	// ErrDeviceNotTrusted - подтверждать устройства может только подтвержденное устройство
+	ErrDeviceNotTrusted = apperr.New(apperr.Forbidden, "only an approved device can approve devices").WithCode("DEVICE_NOT_TRUSTED")
+	// ErrDeviceSignature - запрос не подписан ключом устройства, подпись неверна
+	// или устарела: заголовку X-Device-ID без подписи сервер не верит
+	ErrDeviceSignature = apperr.New(apperr.Forbidden, "device signature is missing or invalid").WithCode("DEVICE_SIGNATURE_INVALID")
+	// ErrFingerprintRequired - подтверждение без отпечатка ключа: сверка
+	// отпечатка с новым устройством обязательна
+	ErrFingerprintRequired = apperr.New(apperr.Invalid, "device key fingerprint is required").WithCode("DEVICE_FINGERPRINT_REQUIRED")
+	// ErrFingerprintMismatch - отпечаток ключа не совпадает с ключом устройства
+	ErrFingerprintMismatch = apperr.New(apperr.Conflict, "device key fingerprint does not match").WithCode("DEVICE_FINGERPRINT_MISMATCH")
 )
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// maxFilterValues - сколько типов или тегов можно перечислить в фильтре
+const maxFilterValues = 50
+
+// Filter - выборочная синхронизация: устройство получает и отправляет только
+// записи перечисленных типов и тегов. Теги берутся из открытых метаданных
+// записи (meta.tags). Пустой фильтр пропускает все записи.
+type Filter struct {
+	// Types - типы записей; пусто - любые
+	Types []string `json:"types,omitempty" maxItems:"50"`
+	// Tags - запись должна иметь хотя бы один из тегов; пусто - любые
+	Tags []string `json:"tags,omitempty" maxItems:"50"`
+	// ExcludeTags - записи с любым из этих тегов пропускаются
+	ExcludeTags []string `json:"exclude_tags,omitempty" maxItems:"50"`
+}
+
+// IsEmpty проверяет, что фильтр пропускает все записи
+func (f Filter) IsEmpty() bool {
+	return len(f.Types) == 0 && len(f.Tags) == 0 && len(f.ExcludeTags) == 0
+}
+
+// Validate проверяет, что в фильтре нет пустых значений и их не слишком много
+func (f Filter) Validate() error {
+	for name, values := range map[string][]string{"types": f.Types, "tags": f.Tags, "exclude_tags": f.ExcludeTags} {
+		if len(values) > maxFilterValues {
+			return fmt.Errorf("%w: too many %s (max %d)", ErrInvalidFilter, name, maxFilterValues)
+		}
+		if slices.Contains(values, "") {
+			return fmt.Errorf("%w: empty value in %s", ErrInvalidFilter, name)
+		}
+	}
+	return nil
+}
+
+// Equal сравнивает фильтры без учета порядка значений
+func (f Filter) Equal(other Filter) bool {
+	return sameValues(f.Types, other.Types) &&
+		sameValues(f.Tags, other.Tags) &&
+		sameValues(f.ExcludeTags, other.ExcludeTags)
+}
+
+// Matches проверяет, проходит ли запись с типом recType и метаданными meta
+// через фильтр. Используется там, где фильтр нельзя применить в запросе к БД.
+func (f Filter) Matches(recType string, meta []byte) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, recType) {
+		return false
+	}
+	if len(f.Tags) == 0 && len(f.ExcludeTags) == 0 {
+		return true
+	}
+
+	tags := tagsFromMeta(meta)
+	if len(f.Tags) > 0 && !slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(f.Tags, tag) }) {
+		return false
+	}
+	return !slices.ContainsFunc(tags, func(tag string) bool { return slices.Contains(f.ExcludeTags, tag) })
+}
+
+// tagsFromMeta возвращает теги из открытых метаданных записи
+func tagsFromMeta(meta []byte) []string {
+	var m struct {
+		Tags []string `json:"tags"`
+	}
+	if len(meta) == 0 || json.Unmarshal(meta, &m) != nil {
+		return nil
+	}
+	return m.Tags
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -1,6 +1,7 @@
 package sync
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -21,6 +22,7 @@ type Status struct {
 // JSON tags используют snake_case для совместимости с API
 type RecordSync struct {
 	ID            int        `json:"id"`
+	UUID          string     `json:"uuid,omitempty"` // постоянный идентификатор записи
 	UserID        int        `json:"user_id"`
 	Type          string     `json:"type"`
 	EncryptedData string     `json:"encrypted_data"` // hex-encoded, в БД: encrypted_data
@@ -32,10 +34,21 @@ type RecordSync struct {
 	DeviceID      string     `json:"device_id,omitempty"`
 }
 
+// RecordDigest - версия и контрольная сумма записи без данных.
+// По таким тройкам клиент и сервер выясняют, какие записи различаются.
+type RecordDigest struct {
+	ID       int    `json:"id"`
+	UUID     string `json:"uuid,omitempty"`
+	Version  int    `json:"version"`
+	Checksum string `json:"checksum,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
 // DeviceInfo информация об устройстве
 type DeviceInfo struct {
 	ID           int       `json:"id"`
 	UserID       int       `json:"user_id"`
+	UUID         string    `json:"uuid,omitempty"` // постоянный идентификатор установки клиента
 	Name         string    `json:"name"`
 	Type         string    `json:"type"` // mobile, desktop, web
 	LastSyncTime time.Time `json:"last_sync_time"`
@@ -43,6 +56,15 @@ type DeviceInfo struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 	IPAddress    string    `json:"ip_address,omitempty"`
 	UserAgent    string    `json:"user_agent,omitempty"`
+	// Usage - трафик и операции синхронизации устройства за все время
+	Usage DeviceUsage `json:"usage"`
+	// PublicKey - открытый ключ Ed25519 устройства (base64); по его отпечатку
+	// пользователь узнает устройство при подтверждении
+	PublicKey  string       `json:"public_key,omitempty"`
+	Status     DeviceStatus `json:"status"`
+	ApprovedAt *time.Time   `json:"approved_at,omitempty"`
+	// ApprovedBy - устройство, подтвердившее это
+	ApprovedBy *int `json:"approved_by,omitempty"`
 }
 
 // Conflict конфликт синхронизации
@@ -59,6 +81,14 @@ type Conflict struct {
 	ResolvedAt   time.Time `json:"resolved_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Метаданные для отображения конфликта без дополнительных запросов
+	RecordType     string    `json:"record_type,omitempty"`
+	Title          string    `json:"title,omitempty"` // из открытых метаданных записи
+	LocalVersion   int       `json:"local_version"`
+	ServerVersion  int       `json:"server_version"`
+	LocalModified  time.Time `json:"local_modified"`
+	ServerModified time.Time `json:"server_modified"`
 }
 
 // Stats статистика синхронизации
@@ -82,4 +112,42 @@ type ServiceConfig struct {
 	ConflictTTL        time.Duration `json:"conflict_ttl"`
 	DeviceSyncInterval time.Duration `json:"device_sync_interval"`
 	StorageLimit       int64         `json:"storage_limit"`
+	// V2RolloutPercent - доля пользователей (0-100), синхронизирующихся по ProtocolV2
+	V2RolloutPercent int `json:"v2_rollout_percent"`
+	// DeviceApproval - новые устройства ждут подтверждения с доверенного
+	// устройства и до него не получают данные
+	DeviceApproval bool `json:"device_approval"`
+}
+
+// DefaultServiceConfig возвращает конфигурацию сервиса по умолчанию
+func DefaultServiceConfig() *ServiceConfig {
+	return &ServiceConfig{
+		BatchSize:          100,
+		MaxSyncRecords:     1000,
+		ConflictTTL:        7 * 24 * time.Hour,
+		DeviceSyncInterval: 30 * time.Second,
+		StorageLimit:       100 * 1024 * 1024, // 100 MB
+		DeviceApproval:     true,
+	}
+}
+
+// Validate проверяет корректность конфигурации сервиса
+func (c *ServiceConfig) Validate() error {
+	switch {
+	case c.BatchSize <= 0:
+		return fmt.Errorf("%w: batch size must be positive", ErrInvalidConfig)
+	case c.MaxSyncRecords <= 0:
+		return fmt.Errorf("%w: max sync records must be positive", ErrInvalidConfig)
+	case c.BatchSize > c.MaxSyncRecords:
+		return fmt.Errorf("%w: batch size %d exceeds max sync records %d", ErrInvalidConfig, c.BatchSize, c.MaxSyncRecords)
+	case c.ConflictTTL <= 0:
+		return fmt.Errorf("%w: conflict ttl must be positive", ErrInvalidConfig)
+	case c.DeviceSyncInterval < 0:
+		return fmt.Errorf("%w: device sync interval must not be negative", ErrInvalidConfig)
+	case c.StorageLimit <= 0:
+		return fmt.Errorf("%w: storage limit must be positive", ErrInvalidConfig)
+	case c.V2RolloutPercent < 0 || c.V2RolloutPercent > 100:
+		return fmt.Errorf("%w: v2 rollout percent must be between 0 and 100", ErrInvalidConfig)
+	}
+	return nil
 }
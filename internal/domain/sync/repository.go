@@ -11,14 +11,33 @@ type Repository interface {
 	GetSyncStatus(ctx context.Context, userID int) (*Status, error)
 	UpdateSyncStatus(ctx context.Context, status *Status) error
 	GetDeviceInfo(ctx context.Context, deviceID int) (*DeviceInfo, error)
+	// GetDeviceByUUID возвращает устройство пользователя по UUID или ErrDeviceNotFound
+	GetDeviceByUUID(ctx context.Context, userID int, deviceUUID string) (*DeviceInfo, error)
 	RegisterDevice(ctx context.Context, device *DeviceInfo) error
 	UpdateDeviceSyncTime(ctx context.Context, deviceID int, syncTime time.Time) error
 	ListUserDevices(ctx context.Context, userID int) ([]*DeviceInfo, error)
 	DeleteDevice(ctx context.Context, deviceID int) error
+	// ApproveDevice подтверждает устройство пользователя устройством approvedBy
+	ApproveDevice(ctx context.Context, userID, deviceID, approvedBy int, at time.Time) error
+	// MergeDevices переносит конфликты дубликатов на устройство keepID и удаляет дубликаты
+	MergeDevices(ctx context.Context, keepID int, duplicateIDs []int) error
+	// AddDeviceUsage прибавляет usage к счетчикам устройства пользователя с UUID
+	// deviceUUID и ставит время последней синхронизации; неизвестное устройство пропускается
+	AddDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage DeviceUsage, at time.Time) error
+	// AckDeviceSync отмечает, что устройство пользователя с UUID deviceUUID получило
+	// все изменения до курсора through включительно. Позиция только растет;
+	// неизвестное устройство пропускается.
+	AckDeviceSync(ctx context.Context, userID int, deviceUUID string, through Cursor) error
 
 	// Sync methods
-	GetRecordsForSync(ctx context.Context, userID int, lastSyncTime time.Time, limit, offset int) ([]*RecordSync, error)
+	// GetRecordsForSync возвращает до limit записей, подходящих под filter,
+	// строго после курсора в порядке (last_modified, id)
+	GetRecordsForSync(ctx context.Context, userID int, after Cursor, limit int, filter Filter) ([]*RecordSync, error)
 	GetRecordByID(ctx context.Context, recordID int) (*RecordSync, error)
+	// GetRecordByUUID возвращает личную запись пользователя по UUID, включая
+	// удаленные; отсутствующая запись - ErrRecordNotFound
+	GetRecordByUUID(ctx context.Context, userID int, recordUUID string) (*RecordSync, error)
+	GetRecordIndex(ctx context.Context, userID int, filter Filter) ([]*RecordDigest, error)
 	GetRecordVersions(ctx context.Context, recordID int, limit int) ([]*RecordSync, error)
 	GetSyncConflicts(ctx context.Context, userID int) ([]*Conflict, error)
 	GetConflictByID(ctx context.Context, conflictID int) (*Conflict, error)
@@ -27,6 +46,9 @@ type Repository interface {
 	// Batch operations
 	SaveRecord(ctx context.Context, record *RecordSync) error
 	SaveConflict(ctx context.Context, conflict *Conflict) error
+	// BatchUpsertRecords сохраняет записи в одной транзакции и заполняет у сохраненных
+	// ID, версию и время изменения. Возвращает число сохраненных записей и индексы
+	// в records тех, что сохранить не удалось: у новых записей еще нет ID.
 	BatchUpsertRecords(ctx context.Context, records []*RecordSync) (int, []int, error)
 	BatchDeleteRecords(ctx context.Context, recordIDs []int, userID int) error
 
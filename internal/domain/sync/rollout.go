@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+
+	"golang.org/x/exp/slog"
+)
+
+// Protocol - версия протокола синхронизации. Протоколы работают параллельно:
+// v1 - /api/sync/*, v2 - /api/v2/sync/*. Новый протокол включается части
+// пользователей (процент из конфигурации и назначения администратора), а при
+// проблемах откатывается без перезапуска клиентов: клиент выбирает протокол
+// перед каждой синхронизацией по /api/sync/capabilities.
+type Protocol string
+
+const (
+	// ProtocolV1 - протокол по умолчанию
+	ProtocolV1 Protocol = "v1"
+	// ProtocolV2 - лента изменений только по курсору, загрузка только пакетами
+	ProtocolV2 Protocol = "v2"
+)
+
+// ProtocolHeader - заголовок ответа 409 на операцию чужого протокола с
+// действующим протоколом пользователя: клиент переключается без лишнего запроса
+const ProtocolHeader = "X-Sync-Protocol"
+
+// Protocols - поддерживаемые сервером протоколы
+var Protocols = []Protocol{ProtocolV1, ProtocolV2}
+
+// Valid проверяет, что протокол поддерживается сервером
+func (p Protocol) Valid() bool {
+	return p == ProtocolV1 || p == ProtocolV2
+}
+
+// RolloutRepository - протоколы, назначенные пользователям администратором
+type RolloutRepository interface {
+	// GetProtocol возвращает назначенный протокол. ok == false, если протокол не назначен.
+	GetProtocol(ctx context.Context, userID int) (p Protocol, ok bool, err error)
+
+	// SetProtocol назначает протокол. Для несуществующего пользователя возвращает ErrUserNotFound.
+	SetProtocol(ctx context.Context, userID int, p Protocol) error
+
+	// DeleteProtocol удаляет назначение; протокол снова выбирается по проценту
+	DeleteProtocol(ctx context.Context, userID int) error
+}
+
+// Assignment - протокол, по которому синхронизируется пользователь
+type Assignment struct {
+	UserID   int      `json:"user_id"`
+	Protocol Protocol `json:"protocol"`
+	// Override - протокол назначен администратором, а не выбран по проценту
+	Override bool `json:"override"`
+}
+
+// RolloutServicer выбирает протокол синхронизации пользователя
+type RolloutServicer interface {
+	// Protocol возвращает действующий протокол пользователя
+	Protocol(ctx context.Context, userID int) (*Assignment, error)
+
+	// Assign назначает пользователю протокол независимо от процента
+	Assign(ctx context.Context, userID int, p Protocol) (*Assignment, error)
+
+	// Reset удаляет назначение администратора
+	Reset(ctx context.Context, userID int) (*Assignment, error)
+}
+
+// Rollout реализация выбора протокола
+type Rollout struct {
+	repo    RolloutRepository
+	percent int
+	log     *slog.Logger
+}
+
+// NewRollout создает выбор протокола. percent - доля пользователей (0-100),
+// которые без назначения администратора получают ProtocolV2.
+func NewRollout(repo RolloutRepository, percent int, log *slog.Logger) *Rollout {
+	return &Rollout{
+		repo:    repo,
+		percent: percent,
+		log:     log.With("component", "sync_rollout"),
+	}
+}
+
+func (r *Rollout) Protocol(ctx context.Context, userID int) (*Assignment, error) {
+	p, ok, err := r.repo.GetProtocol(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get sync protocol: %w", err)
+	}
+	if ok && p.Valid() {
+		return &Assignment{UserID: userID, Protocol: p, Override: true}, nil
+	}
+
+	p = ProtocolV1
+	if rolloutBucket(userID) < r.percent {
+		p = ProtocolV2
+	}
+	return &Assignment{UserID: userID, Protocol: p}, nil
+}
+
+func (r *Rollout) Assign(ctx context.Context, userID int, p Protocol) (*Assignment, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidProtocol, p)
+	}
+	if err := r.repo.SetProtocol(ctx, userID, p); err != nil {
+		return nil, err
+	}
+
+	r.log.Info("sync protocol assigned", "user_id", userID, "protocol", p)
+	return &Assignment{UserID: userID, Protocol: p, Override: true}, nil
+}
+
+func (r *Rollout) Reset(ctx context.Context, userID int) (*Assignment, error) {
+	if err := r.repo.DeleteProtocol(ctx, userID); err != nil {
+		return nil, fmt.Errorf("delete sync protocol: %w", err)
+	}
+	return r.Protocol(ctx, userID)
+}
+
+// rolloutBucket - постоянная корзина пользователя 0-99: при увеличении
+// процента к v2 добавляются новые пользователи, а уже переведенные остаются
+func rolloutBucket(userID int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strconv.Itoa(userID)))
+	return int(h.Sum32() % 100)
+}
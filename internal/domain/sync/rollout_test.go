@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRolloutRepository is a mock implementation of the RolloutRepository interface for testing
+type MockRolloutRepository struct {
+	mock.Mock
+}
+
+func (m *MockRolloutRepository) GetProtocol(ctx context.Context, userID int) (Protocol, bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(Protocol), args.Bool(1), args.Error(2)
+}
+
+func (m *MockRolloutRepository) SetProtocol(ctx context.Context, userID int, p Protocol) error {
+	args := m.Called(ctx, userID, p)
+	return args.Error(0)
+}
+
+func (m *MockRolloutRepository) DeleteProtocol(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestRollout_Protocol(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Percent bounds", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("GetProtocol", mock.Anything, mock.Anything).Return(Protocol(""), false, nil)
+		none := NewRollout(repo, 0, slog.Default())
+		all := NewRollout(repo, 100, slog.Default())
+
+		for userID := 1; userID <= 50; userID++ {
+			a, err := none.Protocol(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, ProtocolV1, a.Protocol)
+			assert.False(t, a.Override)
+
+			a, err = all.Protocol(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, ProtocolV2, a.Protocol)
+		}
+	})
+
+	t.Run("Raising percent keeps migrated users", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("GetProtocol", mock.Anything, mock.Anything).Return(Protocol(""), false, nil)
+		low := NewRollout(repo, 20, slog.Default())
+		high := NewRollout(repo, 60, slog.Default())
+
+		migrated := 0
+		for userID := 1; userID <= 1000; userID++ {
+			a, err := low.Protocol(ctx, userID)
+			require.NoError(t, err)
+			if a.Protocol != ProtocolV2 {
+				continue
+			}
+			migrated++
+			a, err = high.Protocol(ctx, userID)
+			require.NoError(t, err)
+			assert.Equal(t, ProtocolV2, a.Protocol, "user %d", userID)
+		}
+		assert.InDelta(t, 200, migrated, 60)
+	})
+
+	t.Run("Override wins over percent", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("GetProtocol", mock.Anything, 7).Return(ProtocolV1, true, nil)
+		rollout := NewRollout(repo, 100, slog.Default())
+
+		a, err := rollout.Protocol(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, &Assignment{UserID: 7, Protocol: ProtocolV1, Override: true}, a)
+	})
+}
+
+func TestRollout_AssignReset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Assign", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("SetProtocol", mock.Anything, 3, ProtocolV2).Return(nil)
+		rollout := NewRollout(repo, 0, slog.Default())
+
+		a, err := rollout.Assign(ctx, 3, ProtocolV2)
+		require.NoError(t, err)
+		assert.Equal(t, &Assignment{UserID: 3, Protocol: ProtocolV2, Override: true}, a)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid protocol", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		rollout := NewRollout(repo, 0, slog.Default())
+
+		_, err := rollout.Assign(ctx, 3, Protocol("v9"))
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+		repo.AssertNotCalled(t, "SetProtocol", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("SetProtocol", mock.Anything, 99, ProtocolV2).Return(ErrUserNotFound)
+		rollout := NewRollout(repo, 0, slog.Default())
+
+		_, err := rollout.Assign(ctx, 99, ProtocolV2)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("Reset falls back to percent", func(t *testing.T) {
+		repo := new(MockRolloutRepository)
+		repo.On("DeleteProtocol", mock.Anything, 3).Return(nil)
+		repo.On("GetProtocol", mock.Anything, 3).Return(Protocol(""), false, nil)
+		rollout := NewRollout(repo, 0, slog.Default())
+
+		a, err := rollout.Reset(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, &Assignment{UserID: 3, Protocol: ProtocolV1}, a)
+	})
+}
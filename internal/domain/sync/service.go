@@ -2,19 +2,26 @@ package sync
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/exp/slog"
 )
 
 // Servicer интерфейс сервиса синхронизации
 type Servicer interface {
-	// GetChanges возвращает изменения после указанного времени
+	// GetChanges возвращает изменения после курсора
 	GetChanges(ctx context.Context, req GetChangesRequest) (*GetChangesResponse, error)
 
+	// Negotiate сравнивает индекс записей клиента с сервером
+	Negotiate(ctx context.Context, req NegotiateRequest) (*NegotiateResponse, error)
+
 	// ProcessBatch обрабатывает пакет записей для синхронизации
 	ProcessBatch(ctx context.Context, req BatchSyncRequest) (*BatchSyncResponse, error)
 
@@ -30,8 +37,24 @@ type Servicer interface {
 	// GetDevices возвращает список устройств пользователя
 	GetDevices(ctx context.Context) ([]*DeviceInfo, error)
 
+	// RegisterDevice регистрирует устройство по постоянному UUID или забирает существующую запись
+	RegisterDevice(ctx context.Context, req RegisterDeviceRequest) (*RegisterDeviceResponse, error)
+
 	// RemoveDevice удаляет устройство из списка синхронизации
 	RemoveDevice(ctx context.Context, deviceID int) (*RemoveDeviceResponse, error)
+
+	// ApproveDevice подтверждает ожидающее устройство с доверенного устройства,
+	// подписавшего запрос (DeviceProofFrom)
+	ApproveDevice(ctx context.Context, deviceID int, req ApproveDeviceRequest) (*ApproveDeviceResponse, error)
+
+	// CheckDevice проверяет, что устройству пользователя разрешен доступ к данным
+	CheckDevice(ctx context.Context, userID int, proof DeviceProof) error
+
+	// RecordDeviceUsage учитывает трафик и операции запроса устройства
+	RecordDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage DeviceUsage) error
+
+	// GetCapabilities возвращает эффективные параметры сервиса
+	GetCapabilities(ctx context.Context) (*GetCapabilitiesResponse, error)
 }
 
 // Service реализация сервиса синхронизации
@@ -39,32 +62,29 @@ type Service struct {
 	repo   Repository
 	log    *slog.Logger
 	config *ServiceConfig
+	now    func() time.Time
 }
 
 // NewService создает новый сервис синхронизации
 func NewService(repo Repository, log *slog.Logger, config *ServiceConfig) *Service {
 	if config == nil {
-		config = &ServiceConfig{
-			BatchSize:      100,
-			MaxSyncRecords: 1000,
-			ConflictTTL:    7 * 24 * time.Hour,
-			StorageLimit:   100 * 1024 * 1024, // 100 MB
-		}
+		config = DefaultServiceConfig()
 	}
 
 	return &Service{
 		repo:   repo,
 		log:    log,
 		config: config,
+		now:    time.Now,
 	}
 }
 
-// GetChanges возвращает изменения после указанного времени
+// GetChanges возвращает страницу изменений после курсора и курсор следующей страницы
 func (s *Service) GetChanges(ctx context.Context, req GetChangesRequest) (*GetChangesResponse, error) {
 	// Получаем userID из контекста (устанавливается middleware аутентификации)
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	// Валидация параметров
@@ -75,14 +95,37 @@ func (s *Service) GetChanges(ctx context.Context, req GetChangesRequest) (*GetCh
 		req.Limit = s.config.MaxSyncRecords
 	}
 
-	// Получаем записи из репозитория
-	records, err := s.repo.GetRecordsForSync(ctx, userID, req.LastSyncTime, req.Limit, req.Offset)
+	after, skip, err := changesStart(req)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := requestFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Лишняя запись показывает, есть ли следующая страница
+	records, err := s.repo.GetRecordsForSync(ctx, userID, after, skip+req.Limit+1, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get records for sync: %w", err)
 	}
+	records = records[min(skip, len(records)):]
+
+	hasMore := len(records) > req.Limit
+	if hasMore {
+		records = records[:req.Limit]
+	}
+
+	next := after
+	if len(records) > 0 {
+		next = cursorOf(records[len(records)-1])
+	}
+
+	s.ackDeviceSync(ctx, userID, req.DeviceUUID, after)
 
 	// Получаем статус синхронизации
-	status, err := s.repo.GetSyncStatus(ctx, userID)
+	status, err := s.syncStatus(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sync status: %w", err)
 	}
@@ -94,9 +137,6 @@ func (s *Service) GetChanges(ctx context.Context, req GetChangesRequest) (*GetCh
 		s.log.Warn("Failed to update sync status", "error", err)
 	}
 
-	// Проверяем, есть ли еще записи
-	hasMore := len(records) >= req.Limit
-
 	// Получаем статистику
 	stats, err := s.repo.GetSyncStats(ctx, userID)
 	if err != nil {
@@ -114,6 +154,7 @@ func (s *Service) GetChanges(ctx context.Context, req GetChangesRequest) (*GetCh
 		Status:      "Ok",
 		Records:     recordsSlice,
 		HasMore:     hasMore,
+		NextCursor:  next.Encode(),
 		ServerTime:  time.Now(),
 		SyncVersion: status.SyncVersion,
 	}
@@ -134,15 +175,81 @@ func (s *Service) GetChanges(ctx context.Context, req GetChangesRequest) (*GetCh
 	return response, nil
 }
 
+// Negotiate сравнивает индекс записей клиента с сервером и возвращает только
+// различающиеся ID. Удаленную запись клиент получает в Deleted, только если
+// у него она еще живая.
+func (s *Service) Negotiate(ctx context.Context, req NegotiateRequest) (*NegotiateResponse, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	// Курсор берется до чтения индекса: запись, измененная во время сверки,
+	// придет клиенту со следующими изменениями
+	filter, err := requestFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	index, err := s.repo.GetRecordIndex(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get record index: %w", err)
+	}
+
+	server := make(map[int]*RecordDigest, len(index))
+	for _, d := range index {
+		server[d.ID] = d
+	}
+
+	response := &NegotiateResponse{
+		Status:     "Ok",
+		ServerTime: now,
+		Cursor:     Cursor{Time: now}.Encode(),
+	}
+
+	seen := make(map[int]bool, len(req.Records))
+	for _, client := range req.Records {
+		if seen[client.ID] {
+			continue
+		}
+		seen[client.ID] = true
+
+		d, ok := server[client.ID]
+		switch {
+		case !ok:
+			response.Unknown = append(response.Unknown, client.ID)
+		case d.Deleted:
+			if client.Deleted {
+				response.Unchanged++
+			} else {
+				response.Deleted = append(response.Deleted, client.ID)
+			}
+		case d.Version != client.Version || d.Checksum != client.Checksum || client.Deleted:
+			response.Changed = append(response.Changed, client.ID)
+		default:
+			response.Unchanged++
+		}
+	}
+
+	for _, d := range index {
+		if !seen[d.ID] && !d.Deleted {
+			response.Missing = append(response.Missing, d.ID)
+		}
+	}
+
+	return response, nil
+}
+
 // ProcessBatch обрабатывает пакет записей для синхронизации
 func (s *Service) ProcessBatch(ctx context.Context, req BatchSyncRequest) (*BatchSyncResponse, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	// Проверяем лимит хранилища
-	status, err := s.repo.GetSyncStatus(ctx, userID)
+	status, err := s.syncStatus(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sync status: %w", err)
 	}
@@ -153,12 +260,18 @@ func (s *Service) ProcessBatch(ctx context.Context, req BatchSyncRequest) (*Batc
 		totalSize += int64(len(rec.EncryptedData))
 	}
 
-	if status.StorageUsed+totalSize > s.config.StorageLimit {
-		return nil, fmt.Errorf("storage limit exceeded")
+	if status.StorageUsed+totalSize > status.StorageLimit {
+		return nil, ErrStorageLimit
 	}
 
 	// Обрабатываем записи
-	processed, failed, errors := s.processBatchRecords(ctx, userID, req.Records)
+	results, errors := s.processBatchRecords(ctx, userID, req.Records)
+	processed := 0
+	for _, r := range results {
+		if r.Status == BatchRecordSaved {
+			processed++
+		}
+	}
 
 	// Обновляем статистику хранилища
 	status.StorageUsed += totalSize
@@ -174,8 +287,9 @@ func (s *Service) ProcessBatch(ctx context.Context, req BatchSyncRequest) (*Batc
 	return &BatchSyncResponse{
 		Status:    "Ok",
 		Processed: processed,
-		Failed:    failed,
+		Failed:    len(results) - processed,
 		Errors:    errors,
+		Results:   results,
 	}, nil
 }
 
@@ -183,10 +297,10 @@ func (s *Service) ProcessBatch(ctx context.Context, req BatchSyncRequest) (*Batc
 func (s *Service) GetStatus(ctx context.Context) (*GetStatusResponse, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
-	status, err := s.repo.GetSyncStatus(ctx, userID)
+	status, err := s.syncStatus(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sync status: %w", err)
 	}
@@ -201,7 +315,7 @@ func (s *Service) GetStatus(ctx context.Context) (*GetStatusResponse, error) {
 func (s *Service) GetConflicts(ctx context.Context) (*GetConflictsResponse, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	conflicts, err := s.repo.GetSyncConflicts(ctx, userID)
@@ -225,7 +339,7 @@ func (s *Service) GetConflicts(ctx context.Context) (*GetConflictsResponse, erro
 func (s *Service) ResolveConflict(ctx context.Context, conflictID int, req ResolveConflictRequest) (*ResolveConflictResponse, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	// Проверяем, что конфликт принадлежит пользователю
@@ -235,7 +349,7 @@ func (s *Service) ResolveConflict(ctx context.Context, conflictID int, req Resol
 	}
 
 	if conflict.UserID != userID {
-		return nil, fmt.Errorf("conflict does not belong to user")
+		return nil, ErrConflictNotOwned
 	}
 
 	// Разрешаем конфликт
@@ -261,7 +375,7 @@ func (s *Service) ResolveConflict(ctx context.Context, conflictID int, req Resol
 func (s *Service) GetDevices(ctx context.Context) ([]*DeviceInfo, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	devices, err := s.repo.ListUserDevices(ctx, userID)
@@ -272,11 +386,123 @@ func (s *Service) GetDevices(ctx context.Context) ([]*DeviceInfo, error) {
 	return devices, nil
 }
 
+// RegisterDevice регистрирует устройство пользователя. Устройство ищется по UUID;
+// если его нет, клиент может забрать существующую запись (ClaimID) - так после
+// переустановки не появляется новое устройство. Записи того же устройства без
+// UUID (зарегистрированные до появления UUID) и запись, которую UUID занимал
+// до захвата, удаляются как дубликаты.
+func (s *Service) RegisterDevice(ctx context.Context, req RegisterDeviceRequest) (*RegisterDeviceResponse, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	id, err := uuid.Parse(req.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad uuid", ErrInvalidDevice)
+	}
+	req.UUID = id.String()
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidDevice)
+	}
+	if req.Type == "" {
+		req.Type = "desktop"
+	}
+	if req.PublicKey != "" {
+		key, err := ParseDevicePublicKey(req.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		req.PublicKey = base64.StdEncoding.EncodeToString(key)
+	}
+
+	devices, err := s.repo.ListUserDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	var device, byUUID *DeviceInfo
+	for _, d := range devices {
+		if d.UUID == req.UUID {
+			byUUID = d
+		}
+	}
+
+	response := &RegisterDeviceResponse{Status: "Ok"}
+	var duplicates []int
+
+	switch {
+	case req.ClaimID > 0 && (byUUID == nil || byUUID.ID != req.ClaimID):
+		device, err = s.repo.GetDeviceInfo(ctx, req.ClaimID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device info: %w", err)
+		}
+		if device.UserID != userID {
+			return nil, ErrDeviceNotOwned
+		}
+		response.Claimed = true
+		if byUUID != nil {
+			duplicates = append(duplicates, byUUID.ID)
+		}
+	case byUUID != nil:
+		device = byUUID
+	default:
+		device = &DeviceInfo{UserID: userID, CreatedAt: time.Now()}
+	}
+
+	device.UUID = req.UUID
+	device.Name = req.Name
+	device.Type = req.Type
+	device.UpdatedAt = time.Now()
+
+	// Новое устройство и устройство с другим ключом (например, захваченная
+	// запись) подтверждаются заново. Устройству старого клиента без ключа
+	// ключ тоже не верится на слово: иначе его UUID и чужой ключ давали бы
+	// доверенное устройство. Если подтверждать некому, оно подтверждается сразу.
+	// Смена уже зарегистрированного ключа - исключение: ее должен подписать
+	// прежний ключ, иначе устройство ждет подтверждения, даже единственное.
+	previousKey := device.PublicKey
+	rekeyed := req.PublicKey != "" && previousKey != req.PublicKey
+	if req.PublicKey != "" {
+		device.PublicKey = req.PublicKey
+	}
+	switch {
+	case device.ID == 0 || (rekeyed && previousKey == ""):
+		s.setDeviceStatus(device, s.registrationStatus(device, devices))
+	case rekeyed && !s.signedByDevice(ctx, device.UUID, previousKey):
+		s.setDeviceStatus(device, s.rekeyStatus())
+	}
+
+	if err := s.repo.RegisterDevice(ctx, device); err != nil {
+		return nil, fmt.Errorf("failed to register device: %w", err)
+	}
+	if device.Status == DevicePending {
+		s.log.Info("Device awaiting approval", "user_id", userID, "device_id", device.ID)
+	}
+
+	for _, d := range devices {
+		if d.ID != device.ID && d.UUID == "" && d.Name == device.Name && d.Type == device.Type {
+			duplicates = append(duplicates, d.ID)
+		}
+	}
+	if len(duplicates) > 0 {
+		if err := s.repo.MergeDevices(ctx, device.ID, duplicates); err != nil {
+			s.log.Warn("Failed to merge duplicate devices", "error", err, "device_id", device.ID)
+		} else {
+			response.Merged = len(duplicates)
+		}
+	}
+
+	response.Data = device
+	return response, nil
+}
+
 // RemoveDevice удаляет устройство из списка синхронизации
 func (s *Service) RemoveDevice(ctx context.Context, deviceID int) (*RemoveDeviceResponse, error) {
 	userID, ok := auth.GetUserID(ctx)
 	if !ok {
-		return nil, fmt.Errorf("user not authenticated")
+		return nil, ErrUnauthenticated
 	}
 
 	// Проверяем, что устройство принадлежит пользователю
@@ -286,7 +512,7 @@ func (s *Service) RemoveDevice(ctx context.Context, deviceID int) (*RemoveDevice
 	}
 
 	if device.UserID != userID {
-		return nil, fmt.Errorf("device does not belong to user")
+		return nil, ErrDeviceNotOwned
 	}
 
 	// Удаляем устройство
@@ -300,52 +526,208 @@ func (s *Service) RemoveDevice(ctx context.Context, deviceID int) (*RemoveDevice
 	}, nil
 }
 
+// GetCapabilities возвращает эффективные параметры сервиса синхронизации
+func (s *Service) GetCapabilities(_ context.Context) (*GetCapabilitiesResponse, error) {
+	return &GetCapabilitiesResponse{
+		Status: "Ok",
+		Data: &Capabilities{
+			BatchSize:                 s.config.BatchSize,
+			MaxSyncRecords:            s.config.MaxSyncRecords,
+			ConflictTTLSeconds:        int64(s.config.ConflictTTL / time.Second),
+			DeviceSyncIntervalSeconds: int64(s.config.DeviceSyncInterval / time.Second),
+			StorageLimit:              s.config.StorageLimit,
+		},
+	}, nil
+}
+
 // Вспомогательные методы
-func (s *Service) processBatchRecords(ctx context.Context, userID int, records []RecordSync) (int, int, []string) {
-	var processed int
+
+// syncStatus возвращает статус синхронизации с действующим лимитом хранилища:
+// заданным пользователю администратором или лимитом из конфигурации
+func (s *Service) syncStatus(ctx context.Context, userID int) (*Status, error) {
+	status, err := s.repo.GetSyncStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if status.StorageLimit <= 0 {
+		status.StorageLimit = s.config.StorageLimit
+	}
+	return status, nil
+}
+
+// changesStart возвращает курсор, с которого начинается выборка изменений, и
+// число записей, которые нужно пропустить: смещение учитывается только в
+// запросах без курсора от клиентов прежних версий
+func changesStart(req GetChangesRequest) (Cursor, int, error) {
+	if req.Cursor != "" {
+		after, err := ParseCursor(req.Cursor)
+		return after, 0, err
+	}
+	return Cursor{Time: req.LastSyncTime}, req.Offset, nil
+}
+
+// requestFilter проверяет необязательный фильтр запроса
+func requestFilter(f *Filter) (Filter, error) {
+	if f == nil {
+		return Filter{}, nil
+	}
+	if err := f.Validate(); err != nil {
+		return Filter{}, err
+	}
+	return *f, nil
+}
+
+// processBatchRecords отбрасывает записи с конфликтом версий и сохраняет
+// остальные одним вызовом BatchUpsertRecords. Возвращает итог по каждой записи.
+func (s *Service) processBatchRecords(ctx context.Context, userID int, records []RecordSync) ([]BatchRecordResult, []string) {
+	results := make([]BatchRecordResult, len(records))
 	var errors []string
+	var pending []*RecordSync
+	var pendingIdx []int
 
-	for _, rec := range records {
-		// Проверяем, что запись принадлежит пользователю
+	for i := range records {
+		rec := &records[i]
 		rec.UserID = userID
+		results[i] = BatchRecordResult{Index: i, ID: rec.ID, UUID: rec.UUID, Status: BatchRecordFailed}
+
+		if rec.UUID != "" {
+			id, err := uuid.Parse(rec.UUID)
+			if err != nil {
+				results[i].Error = "invalid record uuid"
+				errors = append(errors, fmt.Sprintf("record %d (index %d): %s", rec.ID, i, results[i].Error))
+				continue
+			}
+			rec.UUID = id.String()
+			results[i].UUID = rec.UUID
+		}
+
+		// Запись без ID, чей UUID уже известен серверу, создана раньше: клиент
+		// не получил ответ и повторяет отправку. Те же данные не сохраняются
+		// повторно, изменения проверяются как у записи с этим ID.
+		if rec.ID == 0 && rec.UUID != "" {
+			if known, err := s.repo.GetRecordByUUID(ctx, userID, rec.UUID); err == nil {
+				if known.EncryptedData == rec.EncryptedData {
+					results[i].Status = BatchRecordSaved
+					results[i].ID = known.ID
+					results[i].Version = known.Version
+					continue
+				}
+				rec.ID = known.ID
+				results[i].ID = known.ID
+			}
+		}
+
+		// Новые записи (ID = 0) конфликтовать не могут
+		if rec.ID == 0 {
+			pending = append(pending, rec)
+			pendingIdx = append(pendingIdx, i)
+			continue
+		}
 
 		// Проверяем конфликты
 		existing, err := s.repo.GetRecordByID(ctx, rec.ID)
 		if err == nil && existing != nil {
-			// Обнаружен конфликт
+			if existing.UserID != userID {
+				results[i].Error = "record belongs to another user"
+				errors = append(errors, fmt.Sprintf("record %d: %s", rec.ID, results[i].Error))
+				continue
+			}
+			// Повтор пакета, ответ на который не дошел до клиента
+			if existing.Version == rec.Version && existing.EncryptedData == rec.EncryptedData {
+				results[i].Status = BatchRecordSaved
+				results[i].Version = existing.Version
+				continue
+			}
+			// Серверная версия новее или равна
 			if existing.Version >= rec.Version {
-				// Серверная версия новее или равна
-				if err := s.handleConflict(ctx, userID, rec, *existing); err != nil {
+				results[i].Status = BatchRecordConflict
+				results[i].Version = existing.Version
+				if err := s.handleConflict(ctx, userID, *rec, *existing); err != nil {
 					errors = append(errors, fmt.Sprintf("record %d: conflict handling failed: %v", rec.ID, err))
 				}
 				continue
 			}
 		}
 
-		// Сохраняем запись
-		if err := s.repo.SaveRecord(ctx, &rec); err != nil {
-			errors = append(errors, fmt.Sprintf("record %d: %v", rec.ID, err))
-			continue
+		pending = append(pending, rec)
+		pendingIdx = append(pendingIdx, i)
+	}
+
+	if len(pending) == 0 {
+		return results, errors
+	}
+
+	_, failed, err := s.repo.BatchUpsertRecords(ctx, pending)
+	if err != nil {
+		for _, i := range pendingIdx {
+			results[i].Error = err.Error()
 		}
+		return results, append(errors, fmt.Sprintf("batch upsert: %v", err))
+	}
 
-		processed++
+	notSaved := make(map[int]bool, len(failed))
+	for _, j := range failed {
+		notSaved[j] = true
+		i := pendingIdx[j]
+		results[i].Error = "failed to save record"
+		errors = append(errors, fmt.Sprintf("record %d (index %d): failed to save", records[i].ID, i))
+	}
+	for j, rec := range pending {
+		if notSaved[j] {
+			continue
+		}
+		i := pendingIdx[j]
+		results[i].Status = BatchRecordSaved
+		results[i].ID = rec.ID
+		results[i].UUID = rec.UUID
+		results[i].Version = rec.Version
 	}
 
-	return processed, len(records) - processed, errors
+	return results, errors
 }
 
 func (s *Service) handleConflict(ctx context.Context, userID int, local, server RecordSync) error {
 	// Создаем запись о конфликте
 	conflict := &Conflict{
-		RecordID:     local.ID,
-		UserID:       userID,
-		DeviceID:     0, // TODO: нужно получать из контекста устройства
-		LocalData:    []byte(local.EncryptedData),
-		ServerData:   []byte(server.EncryptedData),
-		ConflictType: "version_mismatch",
-		CreatedAt:    time.Now(),
+		RecordID:       local.ID,
+		UserID:         userID,
+		DeviceID:       0, // TODO: нужно получать из контекста устройства
+		LocalData:      []byte(local.EncryptedData),
+		ServerData:     []byte(server.EncryptedData),
+		ConflictType:   "version_mismatch",
+		CreatedAt:      time.Now(),
+		RecordType:     server.Type,
+		Title:          TitleFromMeta(server.Meta),
+		LocalVersion:   local.Version,
+		ServerVersion:  server.Version,
+		LocalModified:  local.LastModified,
+		ServerModified: server.LastModified,
+	}
+
+	if conflict.RecordType == "" {
+		conflict.RecordType = local.Type
+	}
+	if conflict.Title == "" {
+		conflict.Title = TitleFromMeta(local.Meta)
 	}
 
 	// Сохраняем конфликт
 	return s.repo.SaveConflict(ctx, conflict)
 }
+
+// TitleFromMeta извлекает название записи из открытых метаданных.
+// Возвращает пустую строку, если метаданные отсутствуют или не содержат title.
+func TitleFromMeta(meta []byte) string {
+	if len(meta) == 0 {
+		return ""
+	}
+
+	var m struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(meta, &m); err != nil {
+		return ""
+	}
+
+	return m.Title
+}
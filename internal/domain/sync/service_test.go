@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,6 +40,14 @@ func (m *MockRepository) GetDeviceInfo(ctx context.Context, deviceID int) (*Devi
 	return args.Get(0).(*DeviceInfo), args.Error(1)
 }
 
+func (m *MockRepository) GetDeviceByUUID(ctx context.Context, userID int, deviceUUID string) (*DeviceInfo, error) {
+	args := m.Called(ctx, userID, deviceUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*DeviceInfo), args.Error(1)
+}
+
 func (m *MockRepository) RegisterDevice(ctx context.Context, device *DeviceInfo) error {
 	args := m.Called(ctx, device)
 	return args.Error(0)
@@ -62,8 +71,28 @@ func (m *MockRepository) DeleteDevice(ctx context.Context, deviceID int) error {
 	return args.Error(0)
 }
 
-func (m *MockRepository) GetRecordsForSync(ctx context.Context, userID int, lastSyncTime time.Time, limit, offset int) ([]*RecordSync, error) {
-	args := m.Called(ctx, userID, lastSyncTime, limit, offset)
+func (m *MockRepository) ApproveDevice(ctx context.Context, userID, deviceID, approvedBy int, at time.Time) error {
+	args := m.Called(ctx, userID, deviceID, approvedBy, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) MergeDevices(ctx context.Context, keepID int, duplicateIDs []int) error {
+	args := m.Called(ctx, keepID, duplicateIDs)
+	return args.Error(0)
+}
+
+func (m *MockRepository) AddDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage DeviceUsage, at time.Time) error {
+	args := m.Called(ctx, userID, deviceUUID, usage, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) AckDeviceSync(ctx context.Context, userID int, deviceUUID string, through Cursor) error {
+	args := m.Called(ctx, userID, deviceUUID, through)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetRecordsForSync(ctx context.Context, userID int, after Cursor, limit int, filter Filter) ([]*RecordSync, error) {
+	args := m.Called(ctx, userID, after, limit, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -78,6 +107,22 @@ func (m *MockRepository) GetRecordByID(ctx context.Context, recordID int) (*Reco
 	return args.Get(0).(*RecordSync), args.Error(1)
 }
 
+func (m *MockRepository) GetRecordByUUID(ctx context.Context, userID int, recordUUID string) (*RecordSync, error) {
+	args := m.Called(ctx, userID, recordUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RecordSync), args.Error(1)
+}
+
+func (m *MockRepository) GetRecordIndex(ctx context.Context, userID int, filter Filter) ([]*RecordDigest, error) {
+	args := m.Called(ctx, userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*RecordDigest), args.Error(1)
+}
+
 func (m *MockRepository) GetRecordVersions(ctx context.Context, recordID int, limit int) ([]*RecordSync, error) {
 	args := m.Called(ctx, recordID, limit)
 	if args.Get(0) == nil {
@@ -201,7 +246,7 @@ func TestService_GetChanges(t *testing.T) {
 		AvgSyncDuration: 0.5,
 	}
 
-	mockRepo.On("GetRecordsForSync", mock.Anything, userID, req.LastSyncTime, req.Limit, req.Offset).Return(records, nil)
+	mockRepo.On("GetRecordsForSync", mock.Anything, userID, Cursor{Time: req.LastSyncTime}, req.Limit+1, Filter{}).Return(records, nil)
 	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(status, nil)
 	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.MatchedBy(func(s *Status) bool {
 		return s.UserID == userID && s.SyncVersion > 0
@@ -217,6 +262,10 @@ func TestService_GetChanges(t *testing.T) {
 	assert.Equal(t, records[0].ID, response.Records[0].ID)
 	assert.Equal(t, records[1].ID, response.Records[1].ID)
 	assert.False(t, response.HasMore) // 2 records < limit of 50, so no more
+	next, err := ParseCursor(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, records[1].ID, next.ID)
+	assert.True(t, records[1].LastModified.Equal(next.Time))
 	assert.NotNil(t, response.Stats)
 	assert.Equal(t, 10, response.Stats.TotalSyncs)
 
@@ -247,7 +296,7 @@ func TestService_GetChanges_RepositoryError(t *testing.T) {
 	req := GetChangesRequest{}
 	ctx := createContextWithUserID(userID)
 
-	mockRepo.On("GetRecordsForSync", mock.Anything, userID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("int"), mock.AnythingOfType("int")).Return([]*RecordSync{}, errors.New("database error"))
+	mockRepo.On("GetRecordsForSync", mock.Anything, userID, mock.AnythingOfType("sync.Cursor"), mock.AnythingOfType("int"), Filter{}).Return([]*RecordSync{}, errors.New("database error"))
 
 	_, err := service.GetChanges(ctx, req)
 	assert.Error(t, err)
@@ -256,6 +305,170 @@ func TestService_GetChanges_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_GetChanges_Filter(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{BatchSize: 10, MaxSyncRecords: 100})
+
+	userID := 123
+	filter := Filter{Types: []string{"login", "card"}, ExcludeTags: []string{"archive"}}
+
+	mockRepo.On("GetRecordsForSync", mock.Anything, userID, Cursor{}, 11, filter).Return([]*RecordSync{}, nil)
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(&Status{UserID: userID}, nil)
+	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetSyncStats", mock.Anything, userID).Return(&Stats{}, nil)
+
+	ctx := createContextWithUserID(userID)
+	_, err := service.GetChanges(ctx, GetChangesRequest{Filter: &filter})
+	assert.NoError(t, err)
+
+	_, err = service.GetChanges(ctx, GetChangesRequest{Filter: &Filter{Tags: []string{""}}})
+	assert.ErrorIs(t, err, ErrInvalidFilter)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	meta := []byte(`{"title":"Bank","tags":["work","archive"]}`)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		typ    string
+		meta   []byte
+		want   bool
+	}{
+		{name: "empty", filter: Filter{}, typ: "binary", meta: meta, want: true},
+		{name: "type matches", filter: Filter{Types: []string{"login", "card"}}, typ: "card", meta: meta, want: true},
+		{name: "type excluded", filter: Filter{Types: []string{"login", "card"}}, typ: "binary", meta: meta, want: false},
+		{name: "any tag", filter: Filter{Tags: []string{"home", "work"}}, typ: "login", meta: meta, want: true},
+		{name: "no tag", filter: Filter{Tags: []string{"home"}}, typ: "login", meta: meta, want: false},
+		{name: "excluded tag", filter: Filter{ExcludeTags: []string{"archive"}}, typ: "login", meta: meta, want: false},
+		{name: "exclude without meta", filter: Filter{ExcludeTags: []string{"archive"}}, typ: "login", meta: nil, want: true},
+		{name: "tag without meta", filter: Filter{Tags: []string{"work"}}, typ: "login", meta: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.typ, tt.meta))
+		})
+	}
+}
+
+func TestFilter_Equal(t *testing.T) {
+	a := Filter{Types: []string{"login", "card"}, ExcludeTags: []string{"archive"}}
+	b := Filter{Types: []string{"card", "login"}, ExcludeTags: []string{"archive"}}
+	assert.True(t, a.Equal(b))
+	assert.False(t, a.Equal(Filter{Types: []string{"login"}}))
+	assert.True(t, Filter{}.Equal(Filter{Tags: []string{}}))
+}
+
+func TestService_GetChanges_Cursor(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{BatchSize: 2, MaxSyncRecords: 100})
+
+	userID := 123
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	after := Cursor{Time: base, ID: 7}
+	// Две записи с одинаковым временем различаются по ID
+	records := []*RecordSync{
+		{ID: 8, UserID: userID, LastModified: base},
+		{ID: 3, UserID: userID, LastModified: base.Add(time.Second)},
+		{ID: 4, UserID: userID, LastModified: base.Add(time.Second)},
+	}
+
+	mockRepo.On("GetRecordsForSync", mock.Anything, userID, after, 3, Filter{}).Return(records, nil)
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(&Status{UserID: userID}, nil)
+	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetSyncStats", mock.Anything, userID).Return(&Stats{}, nil)
+
+	ctx := createContextWithUserID(userID)
+	response, err := service.GetChanges(ctx, GetChangesRequest{Cursor: after.Encode(), Offset: 10})
+	assert.NoError(t, err)
+	assert.Len(t, response.Records, 2)
+	assert.True(t, response.HasMore)
+
+	next, err := ParseCursor(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, Cursor{Time: base.Add(time.Second), ID: 3}, next)
+
+	_, err = service.GetChanges(ctx, GetChangesRequest{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_GetChanges_AcknowledgesDevice(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{BatchSize: 10, MaxSyncRecords: 100})
+
+	userID := 123
+	device := "0f8fad5b-d9cb-469f-a165-70867728950e"
+	after := Cursor{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), ID: 7}
+
+	mockRepo.On("GetRecordsForSync", mock.Anything, userID, mock.Anything, 11, Filter{}).Return([]*RecordSync{}, nil)
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(&Status{UserID: userID}, nil)
+	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetSyncStats", mock.Anything, userID).Return(&Stats{}, nil)
+	mockRepo.On("AckDeviceSync", mock.Anything, userID, device, after).Return(errors.New("db down"))
+
+	// Ошибка подтверждения не прерывает синхронизацию
+	ctx := createContextWithUserID(userID)
+	_, err := service.GetChanges(ctx, GetChangesRequest{Cursor: after.Encode(), DeviceUUID: strings.ToUpper(device)})
+	assert.NoError(t, err)
+
+	// Первая синхронизация и запрос без устройства ничего не подтверждают
+	_, err = service.GetChanges(ctx, GetChangesRequest{DeviceUUID: device})
+	assert.NoError(t, err)
+	_, err = service.GetChanges(ctx, GetChangesRequest{Cursor: after.Encode()})
+	assert.NoError(t, err)
+
+	mockRepo.AssertNumberOfCalls(t, "AckDeviceSync", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Negotiate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), nil)
+
+	userID := 123
+	mockRepo.On("GetRecordIndex", mock.Anything, userID, Filter{}).Return([]*RecordDigest{
+		{ID: 1, Version: 2, Checksum: "aaa"},
+		{ID: 2, Version: 3, Checksum: "bbb"},
+		{ID: 3, Version: 1, Checksum: "ccc"},
+		{ID: 4, Version: 5, Deleted: true},
+		{ID: 5, Version: 1, Checksum: "eee"},
+		{ID: 6, Version: 2, Deleted: true},
+		{ID: 7, Version: 1, Deleted: true},
+	}, nil)
+
+	req := NegotiateRequest{Records: []RecordDigest{
+		{ID: 1, Version: 2, Checksum: "aaa"}, // совпадает
+		{ID: 2, Version: 2, Checksum: "bbb"}, // другая версия
+		{ID: 3, Version: 1, Checksum: "xxx"}, // другая контрольная сумма
+		{ID: 4, Version: 4, Checksum: "ddd"}, // удалена на сервере
+		{ID: 6, Version: 2, Deleted: true},   // удалена везде
+		{ID: 9, Version: 1},                  // нет на сервере
+		{ID: 1, Version: 1},                  // дубликат игнорируется
+	}}
+
+	response, err := service.Negotiate(createContextWithUserID(userID), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ok", response.Status)
+	assert.Equal(t, []int{2, 3}, response.Changed)
+	assert.Equal(t, []int{4}, response.Deleted)
+	assert.Equal(t, []int{5}, response.Missing, "удаленные записи сервера клиенту не нужны")
+	assert.Equal(t, []int{9}, response.Unknown)
+	assert.Equal(t, 2, response.Unchanged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_Negotiate_NotAuthenticated(t *testing.T) {
+	service := NewService(new(MockRepository), slog.Default(), nil)
+
+	_, err := service.Negotiate(context.Background(), NegotiateRequest{})
+	assert.Error(t, err)
+}
+
 func TestService_ProcessBatch(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -295,12 +508,9 @@ func TestService_ProcessBatch(t *testing.T) {
 	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(status, nil)
 	mockRepo.On("GetRecordByID", mock.Anything, 1).Return((*RecordSync)(nil), ErrRecordNotFound)
 	mockRepo.On("GetRecordByID", mock.Anything, 2).Return((*RecordSync)(nil), ErrRecordNotFound)
-	mockRepo.On("SaveRecord", mock.Anything, mock.MatchedBy(func(r *RecordSync) bool {
-		return r.UserID == userID && r.ID == 1
-	})).Return(nil)
-	mockRepo.On("SaveRecord", mock.Anything, mock.MatchedBy(func(r *RecordSync) bool {
-		return r.UserID == userID && r.ID == 2
-	})).Return(nil)
+	mockRepo.On("BatchUpsertRecords", mock.Anything, mock.MatchedBy(func(rs []*RecordSync) bool {
+		return len(rs) == 2 && rs[0].UserID == userID && rs[0].ID == 1 && rs[1].UserID == userID && rs[1].ID == 2
+	})).Return(2, []int(nil), nil)
 	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.MatchedBy(func(s *Status) bool {
 		return s.UserID == userID && s.StorageUsed > 0
 	})).Return(nil)
@@ -314,10 +524,126 @@ func TestService_ProcessBatch(t *testing.T) {
 	assert.Equal(t, 2, response.Processed)
 	assert.Equal(t, 0, response.Failed)
 	assert.Empty(t, response.Errors)
+	assert.Equal(t, []BatchRecordResult{
+		{Index: 0, Status: BatchRecordSaved, ID: 1, Version: 1},
+		{Index: 1, Status: BatchRecordSaved, ID: 2, Version: 1},
+	}, response.Results)
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_ProcessBatch_RetryByUUID(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{StorageLimit: 100 * 1024 * 1024})
+
+	userID := 123
+	const (
+		sentUUID  = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+		newUUID   = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+		knownUUID = "6ba7b812-9dad-11d1-80b4-00c04fd430c8"
+	)
+	req := BatchSyncRequest{Records: []RecordSync{
+		// Ответ на прошлую отправку не дошел: запись уже на сервере
+		{UUID: sentUUID, Type: "login", EncryptedData: "aa", Version: 1},
+		{UUID: strings.ToUpper(newUUID), Type: "text", EncryptedData: "bb", Version: 1},
+		// Запись создана раньше и с тех пор изменена на клиенте
+		{UUID: knownUUID, Type: "text", EncryptedData: "cc", Version: 2},
+		{UUID: "bad", Type: "text", EncryptedData: "dd", Version: 1},
+	}}
+
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(&Status{UserID: userID, StorageLimit: 100 * 1024 * 1024}, nil)
+	mockRepo.On("GetRecordByUUID", mock.Anything, userID, sentUUID).
+		Return(&RecordSync{ID: 10, UUID: sentUUID, UserID: userID, EncryptedData: "aa", Version: 1}, nil)
+	mockRepo.On("GetRecordByUUID", mock.Anything, userID, newUUID).Return(nil, ErrRecordNotFound)
+	mockRepo.On("GetRecordByUUID", mock.Anything, userID, knownUUID).
+		Return(&RecordSync{ID: 11, UUID: knownUUID, UserID: userID, EncryptedData: "c0", Version: 1}, nil)
+	mockRepo.On("GetRecordByID", mock.Anything, 11).
+		Return(&RecordSync{ID: 11, UUID: knownUUID, UserID: userID, EncryptedData: "c0", Version: 1}, nil)
+	mockRepo.On("BatchUpsertRecords", mock.Anything, mock.MatchedBy(func(rs []*RecordSync) bool {
+		return len(rs) == 2 && rs[0].ID == 0 && rs[0].UUID == newUUID && rs[1].ID == 11
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).([]*RecordSync)[0].ID = 12
+	}).Return(2, []int(nil), nil)
+	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("IncrementSyncStats", mock.Anything, userID, int64(4), int64(0)).Return(nil)
+
+	response, err := service.ProcessBatch(createContextWithUserID(userID), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, response.Processed)
+	assert.Equal(t, []BatchRecordResult{
+		{Index: 0, Status: BatchRecordSaved, ID: 10, UUID: sentUUID, Version: 1},
+		{Index: 1, Status: BatchRecordSaved, ID: 12, UUID: newUUID, Version: 1},
+		{Index: 2, Status: BatchRecordSaved, ID: 11, UUID: knownUUID, Version: 2},
+		{Index: 3, Status: BatchRecordFailed, UUID: "bad", Error: "invalid record uuid"},
+	}, response.Results)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestService_ProcessBatch_ConflictMetadata(t *testing.T) {
+	mockRepo := new(MockRepository)
+	logger := slog.Default()
+	config := &ServiceConfig{
+		StorageLimit: 100 * 1024 * 1024,
+	}
+	service := NewService(mockRepo, logger, config)
+
+	userID := 123
+	localModified := time.Now().Add(-time.Hour)
+	serverModified := time.Now()
+
+	local := RecordSync{
+		ID:            1,
+		Type:          "login",
+		EncryptedData: "local_data",
+		Version:       2,
+		LastModified:  localModified,
+	}
+	existing := &RecordSync{
+		ID:            1,
+		UserID:        userID,
+		Type:          "login",
+		EncryptedData: "server_data",
+		Meta:          []byte(`{"title":"GitHub"}`),
+		Version:       3,
+		LastModified:  serverModified,
+	}
+
+	status := &Status{UserID: userID, StorageLimit: config.StorageLimit}
+
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(status, nil)
+	mockRepo.On("GetRecordByID", mock.Anything, 1).Return(existing, nil)
+	mockRepo.On("SaveConflict", mock.Anything, mock.MatchedBy(func(c *Conflict) bool {
+		return c.RecordID == 1 &&
+			c.UserID == userID &&
+			c.RecordType == "login" &&
+			c.Title == "GitHub" &&
+			c.LocalVersion == 2 &&
+			c.ServerVersion == 3 &&
+			c.LocalModified.Equal(localModified) &&
+			c.ServerModified.Equal(serverModified)
+	})).Return(nil)
+	mockRepo.On("UpdateSyncStatus", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("IncrementSyncStats", mock.Anything, userID, int64(1), int64(0)).Return(nil)
+
+	ctx := createContextWithUserID(userID)
+	response, err := service.ProcessBatch(ctx, BatchSyncRequest{Records: []RecordSync{local}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, response.Processed)
+	assert.Equal(t, 1, response.Failed)
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, []BatchRecordResult{{Index: 0, Status: BatchRecordConflict, ID: 1, Version: 3}}, response.Results)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTitleFromMeta(t *testing.T) {
+	assert.Equal(t, "Bank", TitleFromMeta([]byte(`{"title":"Bank","tags":["x"]}`)))
+	assert.Equal(t, "", TitleFromMeta(nil))
+	assert.Equal(t, "", TitleFromMeta([]byte(`not json`)))
+	assert.Equal(t, "", TitleFromMeta([]byte(`{"category":"work"}`)))
+}
+
 func TestService_ProcessBatch_StorageLimitExceeded(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -357,6 +683,28 @@ func TestService_ProcessBatch_StorageLimitExceeded(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_ProcessBatch_DefaultStorageLimit(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{StorageLimit: 100})
+
+	userID := 123
+	req := BatchSyncRequest{
+		Records: []RecordSync{{
+			ID:            1,
+			Type:          "login",
+			EncryptedData: "very_long_encrypted_data_that_exceeds_storage_limit",
+			Version:       1,
+			LastModified:  time.Now(),
+		}},
+	}
+
+	// Лимит не задан пользователю - действует лимит из конфигурации
+	mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(&Status{UserID: userID, StorageUsed: 50}, nil)
+
+	_, err := service.ProcessBatch(createContextWithUserID(userID), req)
+	assert.ErrorContains(t, err, "storage limit exceeded")
+}
+
 func TestService_GetStatus(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -517,6 +865,91 @@ func TestService_GetDevices(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_RegisterDevice(t *testing.T) {
+	userID := 123
+	deviceUUID := "5f0c3c1e-7a53-4d2a-9a4e-2b1f0c9d8e7a"
+	ctx := createContextWithUserID(userID)
+
+	t.Run("new device merges legacy duplicates", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{
+			{ID: 1, UserID: userID, Name: "laptop", Type: "desktop"},
+			{ID: 2, UserID: userID, Name: "laptop", Type: "desktop"},
+			{ID: 3, UserID: userID, Name: "phone", Type: "mobile"},
+			{ID: 4, UserID: userID, Name: "laptop", Type: "desktop", UUID: "0b7e6a1c-1d2e-4f3a-8b9c-0d1e2f3a4b5c"},
+		}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, mock.AnythingOfType("*sync.DeviceInfo")).
+			Run(func(args mock.Arguments) { args.Get(1).(*DeviceInfo).ID = 10 }).
+			Return(nil)
+		mockRepo.On("MergeDevices", mock.Anything, 10, []int{1, 2}).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: " laptop "})
+		assert.NoError(t, err)
+		assert.Equal(t, 10, response.Data.ID)
+		assert.Equal(t, "desktop", response.Data.Type)
+		assert.Equal(t, 2, response.Merged, "устройство с другим UUID не считается дубликатом")
+		assert.False(t, response.Claimed)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("known uuid updates existing device", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		existing := &DeviceInfo{ID: 5, UserID: userID, Name: "old", Type: "desktop", UUID: deviceUUID}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{existing}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, existing).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: strings.ToUpper(deviceUUID), Name: "new", Type: "desktop"})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, response.Data.ID)
+		assert.Equal(t, "new", response.Data.Name)
+		assert.Equal(t, deviceUUID, response.Data.UUID)
+		mockRepo.AssertNotCalled(t, "MergeDevices", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("claim takes over existing entry", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		claimed := &DeviceInfo{ID: 7, UserID: userID, Name: "laptop", Type: "desktop", UUID: "0b7e6a1c-1d2e-4f3a-8b9c-0d1e2f3a4b5c"}
+		fresh := &DeviceInfo{ID: 8, UserID: userID, Name: "laptop", Type: "desktop", UUID: deviceUUID}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{claimed, fresh}, nil)
+		mockRepo.On("GetDeviceInfo", mock.Anything, 7).Return(claimed, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, claimed).Return(nil)
+		mockRepo.On("MergeDevices", mock.Anything, 7, []int{8}).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", ClaimID: 7})
+		assert.NoError(t, err)
+		assert.True(t, response.Claimed)
+		assert.Equal(t, 7, response.Data.ID)
+		assert.Equal(t, deviceUUID, response.Data.UUID)
+		assert.Equal(t, 1, response.Merged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("claim of foreign device", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{}, nil)
+		mockRepo.On("GetDeviceInfo", mock.Anything, 9).Return(&DeviceInfo{ID: 9, UserID: 456}, nil)
+
+		_, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", ClaimID: 9})
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "RegisterDevice", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		service := NewService(new(MockRepository), slog.Default(), nil)
+
+		_, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: "not-a-uuid", Name: "laptop"})
+		assert.ErrorIs(t, err, ErrInvalidDevice)
+	})
+}
+
 func TestService_RemoveDevice(t *testing.T) {
 	mockRepo := new(MockRepository)
 	logger := slog.Default()
@@ -568,6 +1001,33 @@ func TestService_RemoveDevice_WrongUser(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_RecordDeviceUsage(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), &ServiceConfig{})
+
+	usage := DeviceUsage{BytesUploaded: 512, BytesDownloaded: 2048, Requests: 1, RecordsDownloaded: 3}
+	mockRepo.On("AddDeviceUsage", mock.Anything, 123, "0f8fad5b-d9cb-469f-a165-70867728950e", usage, mock.Anything).
+		Return(nil)
+
+	err := service.RecordDeviceUsage(context.Background(), 123, "0F8FAD5B-D9CB-469F-A165-70867728950E", usage)
+	assert.NoError(t, err)
+
+	// Пустой учет не пишется в базу, неверный UUID отклоняется
+	assert.NoError(t, service.RecordDeviceUsage(context.Background(), 123, "0f8fad5b-d9cb-469f-a165-70867728950e", DeviceUsage{}))
+	err = service.RecordDeviceUsage(context.Background(), 123, "not-a-uuid", usage)
+	assert.ErrorIs(t, err, ErrInvalidDevice)
+
+	mockRepo.AssertNumberOfCalls(t, "AddDeviceUsage", 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeviceUsage_Add(t *testing.T) {
+	a := DeviceUsage{BytesUploaded: 1, BytesDownloaded: 2, Requests: 3, RecordsUploaded: 4, RecordsDownloaded: 5}
+	assert.Equal(t, DeviceUsage{BytesUploaded: 2, BytesDownloaded: 4, Requests: 6, RecordsUploaded: 8, RecordsDownloaded: 10}, a.Add(a))
+	assert.True(t, DeviceUsage{}.IsZero())
+	assert.False(t, a.IsZero())
+}
+
 // Test edge cases for GetChanges with different limit values
 func TestService_GetChanges_LimitValidation(t *testing.T) {
 	mockRepo := new(MockRepository)
@@ -636,7 +1096,7 @@ func TestService_GetChanges_LimitValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockRepo.On("GetRecordsForSync", mock.Anything, userID, tt.req.LastSyncTime, tt.expectedLimit, tt.req.Offset).Return(records, nil)
+			mockRepo.On("GetRecordsForSync", mock.Anything, userID, Cursor{Time: tt.req.LastSyncTime}, tt.expectedLimit+1, Filter{}).Return(records, nil)
 			mockRepo.On("GetSyncStatus", mock.Anything, userID).Return(status, nil)
 			mockRepo.On("UpdateSyncStatus", mock.Anything, mock.AnythingOfType("*sync.Status")).Return(nil)
 			mockRepo.On("GetSyncStats", mock.Anything, userID).Return(stats, nil)
@@ -652,3 +1112,47 @@ func TestService_GetChanges_LimitValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultServiceConfig().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(c *ServiceConfig)
+	}{
+		{"zero batch size", func(c *ServiceConfig) { c.BatchSize = 0 }},
+		{"zero max records", func(c *ServiceConfig) { c.MaxSyncRecords = 0 }},
+		{"batch size above max records", func(c *ServiceConfig) { c.BatchSize = c.MaxSyncRecords + 1 }},
+		{"zero conflict ttl", func(c *ServiceConfig) { c.ConflictTTL = 0 }},
+		{"negative device interval", func(c *ServiceConfig) { c.DeviceSyncInterval = -time.Second }},
+		{"zero storage limit", func(c *ServiceConfig) { c.StorageLimit = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultServiceConfig()
+			tt.modify(cfg)
+			assert.ErrorIs(t, cfg.Validate(), ErrInvalidConfig)
+		})
+	}
+}
+
+func TestService_GetCapabilities(t *testing.T) {
+	config := &ServiceConfig{
+		BatchSize:          50,
+		MaxSyncRecords:     500,
+		ConflictTTL:        24 * time.Hour,
+		DeviceSyncInterval: time.Minute,
+		StorageLimit:       1024,
+	}
+	service := NewService(new(MockRepository), slog.Default(), config)
+
+	response, err := service.GetCapabilities(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "Ok", response.Status)
+	assert.Equal(t, 50, response.Data.BatchSize)
+	assert.Equal(t, 500, response.Data.MaxSyncRecords)
+	assert.Equal(t, int64(86400), response.Data.ConflictTTLSeconds)
+	assert.Equal(t, int64(60), response.Data.DeviceSyncIntervalSeconds)
+	assert.Equal(t, int64(1024), response.Data.StorageLimit)
+}
@@ -0,0 +1,295 @@
+package sync
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+
+	"github.com/google/uuid"
+)
+
+// Подтверждение устройств. Новое устройство регистрируется с открытым ключом
+// и получает состояние DevicePending: сервер не отдает ему данные, пока
+// пользователь не подтвердит его с уже доверенного устройства, сверив отпечаток
+// ключа. Первое устройство пользователя подтверждать некому, поэтому оно
+// доверенное сразу. Устройство, сменившее ключ, снова ждет подтверждения.
+//
+// UUID из X-Device-ID знает любой, кто видел запросы устройства, поэтому
+// сервер верит заголовку только вместе с подписью запроса ключом устройства
+// (SignDeviceRequest): подпись проверяется открытым ключом, сохраненным при
+// регистрации.
+
+// DeviceStatus - состояние доверия устройства
+type DeviceStatus string
+
+const (
+	DeviceApproved DeviceStatus = "approved"
+	DevicePending  DeviceStatus = "pending"
+)
+
+// DeviceStatusHeader - заголовок ответа 403, по которому клиент отличает
+// неподтвержденное (pending) и незарегистрированное (unregistered) устройство
+// от прочих отказов в доступе
+const DeviceStatusHeader = "X-Device-Status"
+
+// DeviceUnregistered - значение DeviceStatusHeader для незарегистрированного устройства
+const DeviceUnregistered = "unregistered"
+
+const (
+	// DeviceTimestampHeader - время подписи запроса, секунды Unix
+	DeviceTimestampHeader = "X-Device-Timestamp"
+	// DeviceSignatureHeader - подпись запроса ключом устройства в base64
+	DeviceSignatureHeader = "X-Device-Signature"
+)
+
+// MaxDeviceClockSkew - насколько время подписи может расходиться с часами
+// сервера. Ограничивает повтор перехваченного запроса.
+const MaxDeviceClockSkew = 5 * time.Minute
+
+// DeviceProof - подпись запроса устройством: UUID и подпись из заголовков
+// и то, что подписано, - метод и путь с параметрами
+type DeviceProof struct {
+	UUID      string
+	Timestamp string
+	Signature string
+	Method    string
+	URI       string
+	// BodyHash - DeviceBodyHash тела запроса, которое получил сервер
+	BodyHash string
+}
+
+type deviceProofKey struct{}
+
+// WithDeviceProof возвращает контекст запроса с подписью устройства
+func WithDeviceProof(ctx context.Context, proof DeviceProof) context.Context {
+	return context.WithValue(ctx, deviceProofKey{}, proof)
+}
+
+// DeviceProofFrom возвращает подпись устройства из контекста запроса
+func DeviceProofFrom(ctx context.Context) (DeviceProof, bool) {
+	proof, ok := ctx.Value(deviceProofKey{}).(DeviceProof)
+	return proof, ok
+}
+
+// SignDeviceRequest подписывает запрос method uri (путь с параметрами) с
+// телом body (без сжатия, nil для запроса без тела) устройства deviceUUID
+// ключом key и возвращает значения заголовков DeviceTimestampHeader и
+// DeviceSignatureHeader
+func SignDeviceRequest(key ed25519.PrivateKey, method, uri, deviceUUID string, body []byte, at time.Time) (timestamp, signature string) {
+	timestamp = strconv.FormatInt(at.Unix(), 10)
+	sig := ed25519.Sign(key, deviceSigningPayload(method, uri, deviceUUID, timestamp, DeviceBodyHash(body)))
+	return timestamp, base64.StdEncoding.EncodeToString(sig)
+}
+
+// DeviceBodyHash возвращает SHA-256 тела запроса в hex. Тело входит в
+// подпись устройства, чтобы перехваченную подпись нельзя было приложить к
+// другому телу того же запроса.
+func DeviceBodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// deviceSigningPayload - подписываемые данные запроса
+func deviceSigningPayload(method, uri, deviceUUID, timestamp, bodyHash string) []byte {
+	return []byte("gophkeeper-device-v1\n" + strings.ToUpper(method) + "\n" + uri + "\n" +
+		strings.ToLower(deviceUUID) + "\n" + timestamp + "\n" + bodyHash)
+}
+
+// Verify проверяет подпись запроса открытым ключом устройства publicKey
+func (p DeviceProof) Verify(publicKey string, now time.Time) error {
+	key, err := ParseDevicePublicKey(publicKey)
+	if err != nil {
+		return ErrDeviceSignature
+	}
+	unix, err := strconv.ParseInt(p.Timestamp, 10, 64)
+	if err != nil {
+		return ErrDeviceSignature
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > MaxDeviceClockSkew || skew < -MaxDeviceClockSkew {
+		return fmt.Errorf("%w: timestamp is off by %s", ErrDeviceSignature, skew.Round(time.Second))
+	}
+	sig, err := base64.StdEncoding.DecodeString(p.Signature)
+	if err != nil || !ed25519.Verify(key, deviceSigningPayload(p.Method, p.URI, p.UUID, p.Timestamp, p.BodyHash), sig) {
+		return ErrDeviceSignature
+	}
+	return nil
+}
+
+// ParseDevicePublicKey разбирает открытый ключ Ed25519 в base64
+func ParseDevicePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: public key must be a base64 Ed25519 key", ErrInvalidDevice)
+	}
+	return key, nil
+}
+
+// DeviceFingerprint возвращает отпечаток открытого ключа устройства для
+// сверки человеком: первые 8 байт SHA-256 ключа группами по 4 символа.
+// Для пустого или неверного ключа возвращает пустую строку.
+func DeviceFingerprint(publicKey string) string {
+	key, err := ParseDevicePublicKey(publicKey)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(key)
+	digest := hex.EncodeToString(sum[:8])
+	return digest[0:4] + "-" + digest[4:8] + "-" + digest[8:12] + "-" + digest[12:16]
+}
+
+// normalizeFingerprint приводит отпечаток, введенный пользователем, к виду DeviceFingerprint
+func normalizeFingerprint(s string) string {
+	s = strings.ToLower(strings.NewReplacer("-", "", ":", "", " ", "").Replace(s))
+	if len(s) != 16 {
+		return s
+	}
+	return s[0:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16]
+}
+
+// registrationStatus возвращает состояние нового или сменившего ключ
+// устройства. Подтвердить устройство может только устройство с UUID, поэтому
+// старые записи без UUID (дубликаты, которые удалит регистрация) не учитываются.
+func (s *Service) registrationStatus(device *DeviceInfo, devices []*DeviceInfo) DeviceStatus {
+	if !s.config.DeviceApproval {
+		return DeviceApproved
+	}
+	for _, d := range devices {
+		if d.ID != device.ID && d.UUID != "" && d.PublicKey != "" && d.Status == DeviceApproved {
+			return DevicePending
+		}
+	}
+	return DeviceApproved
+}
+
+// rekeyStatus возвращает состояние устройства, сменившего зарегистрированный
+// ключ без подписи прежним ключом. Такой запрос мог прислать кто угодно со
+// свежим токеном пользователя, поэтому устройство ждет подтверждения, даже
+// если подтверждать его некому.
+func (s *Service) rekeyStatus() DeviceStatus {
+	if !s.config.DeviceApproval {
+		return DeviceApproved
+	}
+	return DevicePending
+}
+
+// setDeviceStatus задает состояние устройства и сбрасывает прежнее подтверждение
+func (s *Service) setDeviceStatus(device *DeviceInfo, status DeviceStatus) {
+	device.Status = status
+	device.ApprovedAt, device.ApprovedBy = nil, nil
+	if status == DeviceApproved {
+		device.ApprovedAt = &device.UpdatedAt
+	}
+}
+
+// signedByDevice сообщает, что запрос из контекста подписан устройством
+// deviceUUID ключом publicKey
+func (s *Service) signedByDevice(ctx context.Context, deviceUUID, publicKey string) bool {
+	proof, ok := DeviceProofFrom(ctx)
+	if !ok || !strings.EqualFold(proof.UUID, deviceUUID) {
+		return false
+	}
+	return proof.Verify(publicKey, s.now()) == nil
+}
+
+// verifiedDevice возвращает устройство пользователя, подписавшее запрос.
+// Устройство без ключа (зарегистрированное старым клиентом) подписать запрос
+// не может и считается незарегистрированным: клиент зарегистрирует его с ключом.
+func (s *Service) verifiedDevice(ctx context.Context, userID int, proof DeviceProof) (*DeviceInfo, error) {
+	id, err := uuid.Parse(proof.UUID)
+	if err != nil {
+		return nil, ErrDeviceUnregistered
+	}
+	device, err := s.repo.GetDeviceByUUID(ctx, userID, id.String())
+	if err != nil {
+		if errors.Is(err, ErrDeviceNotFound) {
+			return nil, ErrDeviceUnregistered
+		}
+		return nil, fmt.Errorf("failed to get device: %w", err)
+	}
+	if device.PublicKey == "" {
+		return nil, ErrDeviceUnregistered
+	}
+	if err := proof.Verify(device.PublicKey, s.now()); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// ApproveDevice подтверждает устройство deviceID. Подтверждать может только
+// подтвержденное устройство того же пользователя, подписавшее запрос своим
+// ключом (DeviceProofFrom), и только сверив отпечаток ключа req.Fingerprint.
+// Повторное подтверждение ничего не меняет.
+func (s *Service) ApproveDevice(ctx context.Context, deviceID int, req ApproveDeviceRequest) (*ApproveDeviceResponse, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if strings.TrimSpace(req.Fingerprint) == "" {
+		return nil, ErrFingerprintRequired
+	}
+
+	proof, _ := DeviceProofFrom(ctx)
+	approver, err := s.verifiedDevice(ctx, userID, proof)
+	if err != nil {
+		if errors.Is(err, ErrDeviceUnregistered) || errors.Is(err, ErrDeviceSignature) {
+			return nil, ErrDeviceNotTrusted
+		}
+		return nil, fmt.Errorf("failed to verify approver device: %w", err)
+	}
+	if approver.Status != DeviceApproved {
+		return nil, ErrDeviceNotTrusted
+	}
+
+	device, err := s.repo.GetDeviceInfo(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device info: %w", err)
+	}
+	if device.UserID != userID {
+		return nil, ErrDeviceNotOwned
+	}
+	if device.Status == DeviceApproved {
+		return &ApproveDeviceResponse{Status: "Ok", Data: device}, nil
+	}
+	if normalizeFingerprint(req.Fingerprint) != DeviceFingerprint(device.PublicKey) {
+		return nil, ErrFingerprintMismatch
+	}
+
+	now := time.Now()
+	if err := s.repo.ApproveDevice(ctx, userID, deviceID, approver.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to approve device: %w", err)
+	}
+	device.Status = DeviceApproved
+	device.ApprovedAt = &now
+	device.ApprovedBy = &approver.ID
+
+	s.log.Info("Device approved", "user_id", userID, "device_id", deviceID, "approved_by", approver.ID)
+	return &ApproveDeviceResponse{Status: "Ok", Data: device}, nil
+}
+
+// CheckDevice возвращает ErrDeviceUnregistered для запроса без UUID устройства
+// или от незарегистрированного устройства, ErrDeviceSignature для запроса без
+// верной подписи ключом устройства и ErrDevicePending для неподтвержденного.
+// Без подтверждения устройств доступ не ограничивается.
+func (s *Service) CheckDevice(ctx context.Context, userID int, proof DeviceProof) error {
+	if !s.config.DeviceApproval {
+		return nil
+	}
+
+	device, err := s.verifiedDevice(ctx, userID, proof)
+	if err != nil {
+		return err
+	}
+	if device.Status != DeviceApproved {
+		return ErrDevicePending
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package sync
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func newDeviceKey(t *testing.T) string {
+	t.Helper()
+	public, _ := newDeviceKeyPair(t)
+	return public
+}
+
+func newDeviceKeyPair(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(pub), priv
+}
+
+// signedProof - запрос устройства deviceUUID без тела, подписанный ключом key в момент at
+func signedProof(key ed25519.PrivateKey, method, uri, deviceUUID string, at time.Time) DeviceProof {
+	return signedBodyProof(key, method, uri, deviceUUID, nil, at)
+}
+
+// signedBodyProof - запрос устройства deviceUUID с телом body, подписанный ключом key в момент at
+func signedBodyProof(key ed25519.PrivateKey, method, uri, deviceUUID string, body []byte, at time.Time) DeviceProof {
+	timestamp, signature := SignDeviceRequest(key, method, uri, deviceUUID, body, at)
+	return DeviceProof{
+		UUID:      deviceUUID,
+		Timestamp: timestamp,
+		Signature: signature,
+		Method:    method,
+		URI:       uri,
+		BodyHash:  DeviceBodyHash(body),
+	}
+}
+
+func TestDeviceProof_Verify(t *testing.T) {
+	deviceUUID := "5f0c3c1e-7a53-4d2a-9a4e-2b1f0c9d8e7a"
+	public, key := newDeviceKeyPair(t)
+	other, _ := newDeviceKeyPair(t)
+	now := time.Now()
+	proof := signedProof(key, "GET", "/api/sync/changes?limit=10", deviceUUID, now)
+
+	assert.NoError(t, proof.Verify(public, now))
+	assert.NoError(t, proof.Verify(public, now.Add(MaxDeviceClockSkew-time.Second)))
+
+	tests := []struct {
+		name  string
+		proof func(p DeviceProof) DeviceProof
+		key   string
+		now   time.Time
+	}{
+		{name: "another device key", proof: func(p DeviceProof) DeviceProof { return p }, key: other, now: now},
+		{name: "no device key", proof: func(p DeviceProof) DeviceProof { return p }, key: "", now: now},
+		{name: "stale", proof: func(p DeviceProof) DeviceProof { return p }, key: public, now: now.Add(MaxDeviceClockSkew + time.Second)},
+		{name: "from the future", proof: func(p DeviceProof) DeviceProof { return p }, key: public, now: now.Add(-MaxDeviceClockSkew - time.Second)},
+		{name: "unsigned", proof: func(p DeviceProof) DeviceProof { p.Signature = ""; return p }, key: public, now: now},
+		{name: "other uri", proof: func(p DeviceProof) DeviceProof { p.URI = "/api/records"; return p }, key: public, now: now},
+		{name: "other method", proof: func(p DeviceProof) DeviceProof { p.Method = "DELETE"; return p }, key: public, now: now},
+		{name: "other device", proof: func(p DeviceProof) DeviceProof { p.UUID = "0b7e6a1c-1d2e-4f3a-8b9c-0d1e2f3a4b5c"; return p }, key: public, now: now},
+		{name: "bad timestamp", proof: func(p DeviceProof) DeviceProof { p.Timestamp = "yesterday"; return p }, key: public, now: now},
+		{name: "with body", proof: func(p DeviceProof) DeviceProof { p.BodyHash = DeviceBodyHash([]byte(`{}`)); return p }, key: public, now: now},
+		{name: "no body hash", proof: func(p DeviceProof) DeviceProof { p.BodyHash = ""; return p }, key: public, now: now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.proof(proof).Verify(tt.key, tt.now), ErrDeviceSignature)
+		})
+	}
+}
+
+func TestDeviceProof_Verify_BodySwap(t *testing.T) {
+	deviceUUID := "5f0c3c1e-7a53-4d2a-9a4e-2b1f0c9d8e7a"
+	public, key := newDeviceKeyPair(t)
+	now := time.Now()
+	body := []byte(`{"records":[{"id":7,"deleted":false}]}`)
+	proof := signedBodyProof(key, "POST", "/api/sync/batch", deviceUUID, body, now)
+	require.NoError(t, proof.Verify(public, now))
+
+	// Подпись, перехваченная вместе с запросом, не подходит к другому телу
+	swapped := proof
+	swapped.BodyHash = DeviceBodyHash([]byte(`{"records":[{"id":7,"deleted":true}]}`))
+	assert.ErrorIs(t, swapped.Verify(public, now), ErrDeviceSignature)
+
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", DeviceBodyHash(nil))
+}
+
+func TestDeviceFingerprint(t *testing.T) {
+	key := newDeviceKey(t)
+	fp := DeviceFingerprint(key)
+	assert.Regexp(t, `^[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}$`, fp)
+	assert.Equal(t, fp, DeviceFingerprint(" "+key+"\n"))
+	assert.Equal(t, fp, normalizeFingerprint(strings.ToUpper(strings.ReplaceAll(fp, "-", ""))))
+
+	assert.Empty(t, DeviceFingerprint(""))
+	assert.Empty(t, DeviceFingerprint(base64.StdEncoding.EncodeToString([]byte("short"))))
+}
+
+func TestService_RegisterDevice_Approval(t *testing.T) {
+	userID := 123
+	deviceUUID := "5f0c3c1e-7a53-4d2a-9a4e-2b1f0c9d8e7a"
+	trusted := &DeviceInfo{ID: 1, UserID: userID, UUID: "0b7e6a1c-1d2e-4f3a-8b9c-0d1e2f3a4b5c", PublicKey: newDeviceKey(t), Status: DeviceApproved}
+	ctx := createContextWithUserID(userID)
+
+	t.Run("first device is approved", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, mock.AnythingOfType("*sync.DeviceInfo")).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t)})
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+		assert.NotNil(t, response.Data.ApprovedAt)
+	})
+
+	t.Run("new device waits for trusted one", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{trusted}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, mock.AnythingOfType("*sync.DeviceInfo")).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "phone", PublicKey: newDeviceKey(t)})
+		require.NoError(t, err)
+		assert.Equal(t, DevicePending, response.Data.Status)
+		assert.Nil(t, response.Data.ApprovedAt)
+	})
+
+	t.Run("approval disabled", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		config := DefaultServiceConfig()
+		config.DeviceApproval = false
+		service := NewService(mockRepo, slog.Default(), config)
+
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{trusted}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, mock.AnythingOfType("*sync.DeviceInfo")).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "phone"})
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+	})
+
+	t.Run("changed key requires approval again", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		existing := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t), Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{trusted, existing}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, existing).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t)})
+		require.NoError(t, err)
+		assert.Equal(t, DevicePending, response.Data.Status)
+	})
+
+	t.Run("changed key of the only device requires approval", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		// Чужой ключ с UUID единственного устройства и украденным токеном
+		existing := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t), Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{existing}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, existing).Return(nil)
+
+		attackerKey, attackerPriv := newDeviceKeyPair(t)
+		signedCtx := WithDeviceProof(ctx, signedProof(attackerPriv, "POST", "/api/sync/devices/register", deviceUUID, time.Now()))
+		response, err := service.RegisterDevice(signedCtx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: attackerKey})
+		require.NoError(t, err)
+		assert.Equal(t, DevicePending, response.Data.Status)
+		assert.Nil(t, response.Data.ApprovedAt)
+	})
+
+	t.Run("key change signed with the current key keeps approval", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		currentKey, currentPriv := newDeviceKeyPair(t)
+		existing := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", PublicKey: currentKey, Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{existing}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, existing).Return(nil)
+
+		signedCtx := WithDeviceProof(ctx, signedProof(currentPriv, "POST", "/api/sync/devices/register", deviceUUID, time.Now()))
+		newKey := newDeviceKey(t)
+		response, err := service.RegisterDevice(signedCtx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: newKey})
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+		assert.Equal(t, newKey, response.Data.PublicKey)
+	})
+
+	t.Run("key added to old client device requires approval", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		legacy := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{trusted, legacy}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, legacy).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t)})
+		require.NoError(t, err)
+		assert.Equal(t, DevicePending, response.Data.Status)
+	})
+
+	t.Run("old client devices do not block the first key", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		other := &DeviceInfo{ID: 4, UserID: userID, UUID: trusted.UUID, Name: "desktop", Status: DeviceApproved}
+		legacy := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{other, legacy}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, legacy).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: newDeviceKey(t)})
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+	})
+
+	t.Run("same key keeps status", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		key := newDeviceKey(t)
+		existing := &DeviceInfo{ID: 5, UserID: userID, UUID: deviceUUID, Name: "laptop", PublicKey: key, Status: DeviceApproved}
+		mockRepo.On("ListUserDevices", mock.Anything, userID).Return([]*DeviceInfo{trusted, existing}, nil)
+		mockRepo.On("RegisterDevice", mock.Anything, existing).Return(nil)
+
+		response, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: key})
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		service := NewService(new(MockRepository), slog.Default(), nil)
+		_, err := service.RegisterDevice(ctx, RegisterDeviceRequest{UUID: deviceUUID, Name: "laptop", PublicKey: "not-a-key"})
+		assert.ErrorIs(t, err, ErrInvalidDevice)
+	})
+}
+
+func TestService_ApproveDevice(t *testing.T) {
+	userID := 123
+	approverUUID := "0b7e6a1c-1d2e-4f3a-8b9c-0d1e2f3a4b5c"
+	approverKey, approverPriv := newDeviceKeyPair(t)
+	key := newDeviceKey(t)
+
+	// signed - контекст запроса подтверждения, подписанного ключом priv
+	signed := func(priv ed25519.PrivateKey) context.Context {
+		proof := signedProof(priv, "POST", "/api/sync/devices/7/approve", approverUUID, time.Now())
+		return WithDeviceProof(createContextWithUserID(userID), proof)
+	}
+	approver := func(status DeviceStatus) *DeviceInfo {
+		return &DeviceInfo{ID: 1, UserID: userID, UUID: approverUUID, PublicKey: approverKey, Status: status}
+	}
+	pending := func() *DeviceInfo {
+		return &DeviceInfo{ID: 7, UserID: userID, Name: "phone", PublicKey: key, Status: DevicePending}
+	}
+	confirmed := ApproveDeviceRequest{Fingerprint: DeviceFingerprint(key)}
+
+	t.Run("trusted device approves", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(approver(DeviceApproved), nil)
+		mockRepo.On("GetDeviceInfo", mock.Anything, 7).Return(pending(), nil)
+		mockRepo.On("ApproveDevice", mock.Anything, userID, 7, 1, mock.AnythingOfType("time.Time")).Return(nil)
+
+		response, err := service.ApproveDevice(signed(approverPriv), 7, confirmed)
+		require.NoError(t, err)
+		assert.Equal(t, DeviceApproved, response.Data.Status)
+		require.NotNil(t, response.Data.ApprovedBy)
+		assert.Equal(t, 1, *response.Data.ApprovedBy)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("fingerprint mismatch", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(approver(DeviceApproved), nil)
+		mockRepo.On("GetDeviceInfo", mock.Anything, 7).Return(pending(), nil)
+
+		_, err := service.ApproveDevice(signed(approverPriv), 7, ApproveDeviceRequest{Fingerprint: "0000-0000-0000-0000"})
+		assert.ErrorIs(t, err, ErrFingerprintMismatch)
+		mockRepo.AssertNotCalled(t, "ApproveDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fingerprint required", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		for _, fp := range []string{"", "  "} {
+			_, err := service.ApproveDevice(signed(approverPriv), 7, ApproveDeviceRequest{Fingerprint: fp})
+			assert.ErrorIs(t, err, ErrFingerprintRequired)
+		}
+		mockRepo.AssertNotCalled(t, "ApproveDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("pending device cannot approve", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(approver(DevicePending), nil)
+
+		_, err := service.ApproveDevice(signed(approverPriv), 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotTrusted)
+	})
+
+	t.Run("approved device UUID without its key", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(approver(DeviceApproved), nil)
+
+		_, attackerPriv := newDeviceKeyPair(t)
+		_, err := service.ApproveDevice(signed(attackerPriv), 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotTrusted)
+
+		unsigned := WithDeviceProof(createContextWithUserID(userID), DeviceProof{UUID: approverUUID})
+		_, err = service.ApproveDevice(unsigned, 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotTrusted)
+		mockRepo.AssertNotCalled(t, "ApproveDevice", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown approver", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(nil, ErrDeviceNotFound)
+
+		_, err := service.ApproveDevice(signed(approverPriv), 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotTrusted)
+
+		_, err = service.ApproveDevice(createContextWithUserID(userID), 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotTrusted)
+	})
+
+	t.Run("device of another user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service := NewService(mockRepo, slog.Default(), nil)
+
+		mockRepo.On("GetDeviceByUUID", mock.Anything, userID, approverUUID).Return(approver(DeviceApproved), nil)
+		mockRepo.On("GetDeviceInfo", mock.Anything, 7).Return(&DeviceInfo{ID: 7, UserID: 456, Status: DevicePending}, nil)
+
+		_, err := service.ApproveDevice(signed(approverPriv), 7, confirmed)
+		assert.ErrorIs(t, err, ErrDeviceNotOwned)
+	})
+}
+
+func TestService_CheckDevice(t *testing.T) {
+	userID := 123
+	deviceUUID := "5f0c3c1e-7a53-4d2a-9a4e-2b1f0c9d8e7a"
+	public, priv := newDeviceKeyPair(t)
+	ctx := createContextWithUserID(userID)
+	proof := signedProof(priv, "GET", "/api/records", deviceUUID, time.Now())
+
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, slog.Default(), nil)
+
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).
+		Return(&DeviceInfo{ID: 1, PublicKey: public, Status: DevicePending}, nil).Once()
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, proof), ErrDevicePending)
+
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).
+		Return(&DeviceInfo{ID: 1, PublicKey: public, Status: DeviceApproved}, nil).Once()
+	assert.NoError(t, service.CheckDevice(ctx, userID, proof))
+
+	// UUID подтвержденного устройства без его ключа доступа не дает
+	_, attacker := newDeviceKeyPair(t)
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).
+		Return(&DeviceInfo{ID: 1, PublicKey: public, Status: DeviceApproved}, nil).Once()
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, signedProof(attacker, "GET", "/api/records", deviceUUID, time.Now())), ErrDeviceSignature)
+
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).
+		Return(&DeviceInfo{ID: 1, PublicKey: public, Status: DeviceApproved}, nil).Once()
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, DeviceProof{UUID: strings.ToUpper(deviceUUID)}), ErrDeviceSignature)
+
+	// Устройство старого клиента без ключа регистрируется заново
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).
+		Return(&DeviceInfo{ID: 1, Status: DeviceApproved}, nil).Once()
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, proof), ErrDeviceUnregistered)
+
+	mockRepo.On("GetDeviceByUUID", mock.Anything, userID, deviceUUID).Return(nil, ErrDeviceNotFound).Once()
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, proof), ErrDeviceUnregistered)
+	assert.ErrorIs(t, service.CheckDevice(ctx, userID, DeviceProof{}), ErrDeviceUnregistered)
+
+	config := DefaultServiceConfig()
+	config.DeviceApproval = false
+	assert.NoError(t, NewService(new(MockRepository), slog.Default(), config).CheckDevice(ctx, userID, DeviceProof{}))
+}
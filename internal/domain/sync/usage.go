@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceHeader - заголовок запроса с постоянным UUID установки клиента.
+// По нему сервер относит трафик и операции синхронизации к устройству.
+const DeviceHeader = "X-Device-ID"
+
+// DeviceUsage - накопленные трафик и операции синхронизации устройства
+type DeviceUsage struct {
+	BytesUploaded     int64 `json:"bytes_uploaded"`
+	BytesDownloaded   int64 `json:"bytes_downloaded"`
+	Requests          int64 `json:"requests"`
+	RecordsUploaded   int64 `json:"records_uploaded"`
+	RecordsDownloaded int64 `json:"records_downloaded"`
+}
+
+// IsZero сообщает, что у устройства нет учтенной активности
+func (u DeviceUsage) IsZero() bool {
+	return u == DeviceUsage{}
+}
+
+// Add возвращает сумму счетчиков
+func (u DeviceUsage) Add(other DeviceUsage) DeviceUsage {
+	return DeviceUsage{
+		BytesUploaded:     u.BytesUploaded + other.BytesUploaded,
+		BytesDownloaded:   u.BytesDownloaded + other.BytesDownloaded,
+		Requests:          u.Requests + other.Requests,
+		RecordsUploaded:   u.RecordsUploaded + other.RecordsUploaded,
+		RecordsDownloaded: u.RecordsDownloaded + other.RecordsDownloaded,
+	}
+}
+
+// RecordDeviceUsage прибавляет трафик и операции запроса к счетчикам устройства
+// и обновляет время его последней синхронизации. Запросы неизвестного
+// устройства (еще не зарегистрированного или удаленного) не учитываются.
+func (s *Service) RecordDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage DeviceUsage) error {
+	id, err := uuid.Parse(deviceUUID)
+	if err != nil {
+		return fmt.Errorf("%w: bad uuid", ErrInvalidDevice)
+	}
+	if usage.IsZero() {
+		return nil
+	}
+
+	if err := s.repo.AddDeviceUsage(ctx, userID, id.String(), usage, time.Now()); err != nil {
+		return fmt.Errorf("failed to record device usage: %w", err)
+	}
+	return nil
+}
+
+// ackDeviceSync запоминает, что устройство получило изменения до курсора after:
+// клиент запрашивает следующую порцию только после того, как применил
+// предыдущую. Подтверждения нужны очистке корзины, поэтому ошибка не прерывает
+// синхронизацию.
+func (s *Service) ackDeviceSync(ctx context.Context, userID int, deviceUUID string, after Cursor) {
+	if deviceUUID == "" || after.Time.IsZero() {
+		return
+	}
+	id, err := uuid.Parse(deviceUUID)
+	if err != nil {
+		return
+	}
+
+	if err := s.repo.AckDeviceSync(ctx, userID, id.String(), after); err != nil {
+		s.log.Warn("Failed to acknowledge device sync", "user_id", userID, "device", deviceUUID, "error", err)
+	}
+}
@@ -1,11 +1,14 @@
 package user
 
-import "errors"
+import "gophkeeper/internal/domain/apperr"
 
 var (
-	ErrNotFound     = errors.New("user not found")
-	ErrInvalidAuth  = errors.New("invalid credentials")
-	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = apperr.New(apperr.NotFound, "user not found").WithCode("USER_NOT_FOUND")
+	ErrInvalidAuth  = apperr.New(apperr.Unauthorized, "invalid credentials").WithCode("INVALID_CREDENTIALS")
+	ErrInvalidInput = apperr.New(apperr.Invalid, "invalid input")
+	ErrDisabled     = apperr.New(apperr.Forbidden, "account disabled").WithCode("ACCOUNT_DISABLED")
+	ErrLoginTaken   = apperr.New(apperr.Conflict, "login is already taken").WithCode("LOGIN_TAKEN")
+	ErrSamePassword = apperr.New(apperr.Invalid, "new password must differ from the current one").WithCode("SAME_PASSWORD")
 )
 
 type DomainError struct {
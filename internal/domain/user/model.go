@@ -2,14 +2,40 @@ package user
 
 import "time"
 
+// Роли пользователей: администратор получает доступ к /api/v1/admin
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID        int
-	Login     string
-	Password  string // хэш
-	CreatedAt time.Time
+	ID         int
+	Login      string
+	Password   string // хэш
+	Role       string
+	DisabledAt *time.Time // nil - учётная запись активна
+	// DeleteAfter - время запланированного удаления учётной записи; nil - не запланировано
+	DeleteAfter *time.Time
+	CreatedAt   time.Time
+}
+
+// Disabled сообщает, заблокирована ли учётная запись
+func (u User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// ValidRole проверяет, что роль известна серверу
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
 
 type BaseRequest struct {
 	Login    string `json:"login" validate:"required,min=3,max=20"`
 	Password string `json:"password" validate:"required,min=4,max=20"`
 }
+
+// ChangePasswordRequest - смена пароля входа текущего пользователя
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" minLength:"1"`
+	NewPassword string `json:"new_password" minLength:"1"`
+}
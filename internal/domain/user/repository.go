@@ -2,9 +2,23 @@ package user
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
 	Create(ctx context.Context, login, passwordHash string) (int, error)
 	FindByLogin(ctx context.Context, login string) (User, error)
+	FindByID(ctx context.Context, id int) (User, error)
+	// List возвращает всех пользователей в порядке регистрации
+	List(ctx context.Context) ([]User, error)
+	// SetDisabled блокирует учётную запись (at != nil) или снимает блокировку (nil)
+	SetDisabled(ctx context.Context, id int, at *time.Time) error
+	SetRole(ctx context.Context, id int, role string) error
+	UpdatePassword(ctx context.Context, id int, passwordHash string) error
+	// SetDeleteAfter планирует удаление учётной записи (at != nil) или отменяет его (nil)
+	SetDeleteAfter(ctx context.Context, id int, at *time.Time) error
+	// ListDeleteDue возвращает ID пользователей, чьё удаление запланировано не позже before
+	ListDeleteDue(ctx context.Context, before time.Time) ([]int, error)
+	// Delete удаляет пользователя вместе со всеми его данными
+	Delete(ctx context.Context, id int) error
 }
@@ -11,6 +11,11 @@ import (
 type Servicer interface {
 	Register(ctx context.Context, login, password string) (int, error)
 	Authenticate(ctx context.Context, login, password string) (User, error)
+	Get(ctx context.Context, id int) (User, error)
+	// Role возвращает роль активного пользователя; для заблокированного - ErrDisabled
+	Role(ctx context.Context, id int) (string, error)
+	// ChangePassword меняет пароль входа после проверки текущего
+	ChangePassword(ctx context.Context, id int, oldPassword, newPassword string) error
 }
 
 type Service struct {
@@ -56,5 +61,58 @@ func (s *Service) Authenticate(ctx context.Context, login, password string) (Use
 		return user, ErrInvalidAuth
 	}
 
+	// Блокировку проверяем после пароля, чтобы не раскрывать её по одному логину
+	if user.Disabled() {
+		return user, ErrDisabled
+	}
+
+	return user, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int) (User, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return user, ErrNotFound
+	}
 	return user, nil
 }
+
+func (s *Service) Role(ctx context.Context, id int) (string, error) {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if user.Disabled() {
+		return "", ErrDisabled
+	}
+	return user.Role, nil
+}
+
+func (s *Service) ChangePassword(ctx context.Context, id int, oldPassword, newPassword string) error {
+	user, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return ErrInvalidAuth
+	}
+	if oldPassword == newPassword {
+		return ErrSamePassword
+	}
+	if err := s.validator.ValidatePassword(newPassword); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("password hash: %w", err)
+	}
+
+	if err := s.repo.UpdatePassword(ctx, id, string(hash)); err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+
+	s.log.Info("password changed", "user_id", id)
+	return nil
+}
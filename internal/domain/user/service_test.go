@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/exp/slog"
 )
@@ -30,6 +32,46 @@ func (m *MockRepository) FindByLogin(ctx context.Context, login string) (User, e
 	return args.Get(0).(User), args.Error(1)
 }
 
+func (m *MockRepository) FindByID(ctx context.Context, id int) (User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(User), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context) ([]User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]User), args.Error(1)
+}
+
+func (m *MockRepository) SetDisabled(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetRole(ctx context.Context, id int, role string) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SetDeleteAfter(ctx context.Context, id int, at *time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListDeleteDue(ctx context.Context, before time.Time) ([]int, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockValidator) ValidateRegister(login, password string) error {
 	args := m.Called(login, password)
 	return args.Error(0)
@@ -186,6 +228,70 @@ func TestService_Authenticate_InvalidHash(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_Authenticate_Disabled(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockValidator := new(MockValidator)
+	service := NewService(mockRepo, mockValidator, slog.Default())
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	assert.NoError(t, err)
+	disabledAt := time.Now()
+	user := User{ID: 123, Login: "testuser", Password: string(hash), DisabledAt: &disabledAt}
+
+	mockValidator.On("ValidateLogin", "testuser").Return(nil)
+	mockRepo.On("FindByLogin", mock.Anything, "testuser").Return(user, nil)
+
+	_, err = service.Authenticate(context.Background(), "testuser", "password123")
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	// С неверным паролем блокировка не раскрывается
+	_, err = service.Authenticate(context.Background(), "testuser", "wrongpassword")
+	assert.ErrorIs(t, err, ErrInvalidAuth)
+}
+
+func TestService_Role(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := NewService(mockRepo, new(MockValidator), slog.Default())
+	disabledAt := time.Now()
+
+	mockRepo.On("FindByID", mock.Anything, 1).Return(User{ID: 1, Role: RoleAdmin}, nil)
+	mockRepo.On("FindByID", mock.Anything, 2).Return(User{ID: 2, Role: RoleAdmin, DisabledAt: &disabledAt}, nil)
+	mockRepo.On("FindByID", mock.Anything, 3).Return(User{ID: 3}, errors.New("user not found"))
+
+	role, err := service.Role(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleAdmin, role)
+
+	_, err = service.Role(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrDisabled)
+
+	_, err = service.Role(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	mockRepo := new(MockRepository)
+	mockValidator := new(MockValidator)
+	service := NewService(mockRepo, mockValidator, slog.Default())
+	ctx := context.Background()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Old-pass1"), bcrypt.MinCost)
+	require.NoError(t, err)
+	mockRepo.On("FindByID", mock.Anything, 1).Return(User{ID: 1, Password: string(hash)}, nil)
+	mockValidator.On("ValidatePassword", "weak").Return(errors.New("too short"))
+	mockValidator.On("ValidatePassword", "New-pass2").Return(nil)
+	mockRepo.On("UpdatePassword", mock.Anything, 1, mock.AnythingOfType("string")).Return(nil)
+
+	assert.ErrorIs(t, service.ChangePassword(ctx, 1, "wrong", "New-pass2"), ErrInvalidAuth)
+	assert.ErrorIs(t, service.ChangePassword(ctx, 1, "Old-pass1", "Old-pass1"), ErrSamePassword)
+	assert.ErrorIs(t, service.ChangePassword(ctx, 1, "Old-pass1", "weak"), ErrInvalidInput)
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+
+	require.NoError(t, service.ChangePassword(ctx, 1, "Old-pass1", "New-pass2"))
+	newHash := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.String(2)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("New-pass2")))
+}
+
 func TestService_Register_EdgeCases(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,13 @@
+package viewer
+
+import "gophkeeper/internal/domain/apperr"
+
+var (
+	ErrNotFound       = apperr.New(apperr.NotFound, "viewer token not found")
+	ErrInvalidToken   = apperr.New(apperr.Unauthorized, "invalid or expired viewer token").WithCode("VIEWER_TOKEN_INVALID")
+	ErrInvalidRequest = apperr.New(apperr.Invalid, "invalid viewer token request")
+	// ErrRecordNotFound - записи нет или она вне папок токена
+	ErrRecordNotFound = apperr.New(apperr.NotFound, "record not found").WithCode("RECORD_NOT_FOUND")
+
+	ErrUnauthenticated = apperr.New(apperr.Unauthorized, "user not authenticated")
+)
@@ -0,0 +1,121 @@
+// Package viewer выдает токены только для чтения: для аудита или экстренного
+// доступа владелец открывает записи выбранных папок на ограниченный срок.
+// Токен не позволяет изменять данные и не видит записи вне своих папок.
+// Записи остаются зашифрованными мастер-ключом владельца: токен открывает
+// список записей, открытые метаданные и шифротекст.
+package viewer
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenPrefix отличает токены просмотра от токенов сессий
+	TokenPrefix = "gkv_"
+	// MaxNameLength - наибольшая длина названия токена
+	MaxNameLength = 100
+	// MaxFolders - наибольшее число папок в области токена
+	MaxFolders = 20
+	// DefaultTTL - срок действия токена, если он не задан
+	DefaultTTL = 24 * time.Hour
+	// MaxTTL - наибольший срок действия токена
+	MaxTTL = 30 * 24 * time.Hour
+)
+
+// Token - токен просмотра. Сервер хранит только хэш секрета, поэтому сам
+// секрет возвращается один раз, при выпуске.
+type Token struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"-"`
+	Name   string `json:"name"`
+	// FolderIDs - папки, записи которых (вместе с вложенными папками) видит токен
+	FolderIDs  []int      `json:"folder_ids"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Expired сообщает, что срок действия токена истек к моменту now
+func (t Token) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// Issued - выпущенный токен вместе с секретом
+type Issued struct {
+	Token
+	Secret string `json:"token"`
+}
+
+// CreateRequest - параметры нового токена
+type CreateRequest struct {
+	Name      string
+	FolderIDs []int
+	// TTL - срок действия; 0 - DefaultTTL
+	TTL time.Duration
+}
+
+// normalize проверяет запрос и убирает повторы папок
+func (r *CreateRequest) normalize() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name must be 1..%d characters", ErrInvalidRequest, MaxNameLength)
+	}
+	if r.TTL == 0 {
+		r.TTL = DefaultTTL
+	}
+	if r.TTL < time.Minute || r.TTL > MaxTTL {
+		return fmt.Errorf("%w: ttl must be between 1m and %s", ErrInvalidRequest, MaxTTL)
+	}
+
+	seen := make(map[int]bool, len(r.FolderIDs))
+	folders := r.FolderIDs[:0:0]
+	for _, id := range r.FolderIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: invalid folder id %d", ErrInvalidRequest, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			folders = append(folders, id)
+		}
+	}
+	if len(folders) == 0 || len(folders) > MaxFolders {
+		return fmt.Errorf("%w: 1..%d folders required", ErrInvalidRequest, MaxFolders)
+	}
+	r.FolderIDs = folders
+	return nil
+}
+
+// HashSecret возвращает хэш секрета токена для хранения и поиска
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSecret создает секрет токена: префикс и 32 случайных байта
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate viewer token: %w", err)
+	}
+	return TokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+type contextKey struct{}
+
+// WithToken возвращает контекст запроса, выполняемого по токену просмотра
+func WithToken(ctx context.Context, token *Token) context.Context {
+	return context.WithValue(ctx, contextKey{}, token)
+}
+
+// TokenFrom возвращает токен просмотра запроса
+func TokenFrom(ctx context.Context) (*Token, bool) {
+	token, ok := ctx.Value(contextKey{}).(*Token)
+	return token, ok && token != nil
+}
@@ -0,0 +1,20 @@
+package viewer
+
+import (
+	"context"
+	"time"
+)
+
+// Repository хранилище токенов просмотра
+type Repository interface {
+	// Create сохраняет токен с хэшем секрета и заполняет token.ID
+	Create(ctx context.Context, token *Token, secretHash string) error
+	// List возвращает токены пользователя, включая истекшие
+	List(ctx context.Context, userID int) ([]Token, error)
+	// GetByHash возвращает токен по хэшу секрета или ErrNotFound
+	GetByHash(ctx context.Context, secretHash string) (*Token, error)
+	// Delete отзывает токен пользователя; нет токена - ErrNotFound
+	Delete(ctx context.Context, userID, id int) error
+	// Touch запоминает время последнего обращения по токену
+	Touch(ctx context.Context, id int, at time.Time) error
+}
@@ -0,0 +1,249 @@
+package viewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/exp/slog"
+)
+
+// Servicer интерфейс сервиса токенов просмотра
+type Servicer interface {
+	// Create выпускает токен текущего пользователя для его папок
+	Create(ctx context.Context, req CreateRequest) (*Issued, error)
+	// List возвращает токены текущего пользователя
+	List(ctx context.Context) ([]Token, error)
+	// Revoke отзывает токен текущего пользователя
+	Revoke(ctx context.Context, id int) error
+
+	// Authenticate возвращает действующий токен по секрету или ErrInvalidToken
+	Authenticate(ctx context.Context, secret string) (*Token, error)
+	// View возвращает папки и записи, доступные токену запроса
+	View(ctx context.Context) (*View, error)
+	// Record возвращает запись, если она в папках токена запроса
+	Record(ctx context.Context, id int) (*record.Record, error)
+}
+
+// RecordReader - чтение личных записей владельца токена
+type RecordReader interface {
+	List(ctx context.Context, userID int) ([]record.Record, error)
+	Get(ctx context.Context, userID, recordID int) (*record.Record, error)
+}
+
+// FolderReader - чтение папок владельца токена
+type FolderReader interface {
+	List(ctx context.Context, userID int) ([]folder.Folder, error)
+	Get(ctx context.Context, userID, id int) (*folder.Folder, error)
+}
+
+// SharedFolder - папка в области токена с полным путем
+type SharedFolder struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+}
+
+// View - содержимое, доступное по токену: записи без зашифрованных данных
+type View struct {
+	Name      string         `json:"name"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Folders   []SharedFolder `json:"folders"`
+	Records   []record.Item  `json:"records"`
+}
+
+// Service реализация сервиса токенов просмотра
+type Service struct {
+	repo    Repository
+	records RecordReader
+	folders FolderReader
+	log     *slog.Logger
+	now     func() time.Time
+}
+
+// NewService создает сервис токенов просмотра
+func NewService(repo Repository, records RecordReader, folders FolderReader, log *slog.Logger) *Service {
+	return &Service{
+		repo:    repo,
+		records: records,
+		folders: folders,
+		log:     log.With("component", "viewer_service"),
+		now:     time.Now,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, req CreateRequest) (*Issued, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	for _, id := range req.FolderIDs {
+		if _, err := s.folders.Get(ctx, userID, id); err != nil {
+			return nil, fmt.Errorf("get folder %d: %w", id, err)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	token := Token{
+		UserID:    userID,
+		Name:      req.Name,
+		FolderIDs: req.FolderIDs,
+		ExpiresAt: now.Add(req.TTL),
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, &token, HashSecret(secret)); err != nil {
+		return nil, fmt.Errorf("create viewer token: %w", err)
+	}
+
+	s.log.Info("viewer token issued", "user_id", userID, "token_id", token.ID,
+		"folders", token.FolderIDs, "expires_at", token.ExpiresAt)
+	return &Issued{Token: token, Secret: secret}, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]Token, error) {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	tokens, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list viewer tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, id int) error {
+	userID, ok := auth.GetUserID(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return fmt.Errorf("revoke viewer token: %w", err)
+	}
+	s.log.Info("viewer token revoked", "user_id", userID, "token_id", id)
+	return nil
+}
+
+func (s *Service) Authenticate(ctx context.Context, secret string) (*Token, error) {
+	if !strings.HasPrefix(secret, TokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.repo.GetByHash(ctx, HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("get viewer token: %w", err)
+	}
+	now := s.now()
+	if token.Expired(now) {
+		return nil, ErrInvalidToken
+	}
+
+	// Время обращения нужно только для списка токенов: сбой не мешает чтению
+	if err := s.repo.Touch(ctx, token.ID, now); err != nil {
+		s.log.Warn("failed to record viewer token use", "token_id", token.ID, "error", err)
+	}
+	return token, nil
+}
+
+func (s *Service) View(ctx context.Context) (*View, error) {
+	token, ok := TokenFrom(ctx)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	folders, scope, err := s.scope(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.records.List(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+
+	var shared []record.Record
+	for _, rec := range all {
+		if scope[folder.RecordFolder(rec.Meta)] {
+			shared = append(shared, rec)
+		}
+	}
+
+	return &View{
+		Name:      token.Name,
+		ExpiresAt: token.ExpiresAt,
+		Folders:   folders,
+		Records:   record.NewListResponse(shared).Records,
+	}, nil
+}
+
+func (s *Service) Record(ctx context.Context, id int) (*record.Record, error) {
+	token, ok := TokenFrom(ctx)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	_, scope, err := s.scope(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := s.records.Get(ctx, token.UserID, id)
+	if err != nil {
+		if errors.Is(err, record.ErrNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("get record: %w", err)
+	}
+	// Запись вне папок токена неотличима от отсутствующей
+	if !scope[folder.RecordFolder(rec.Meta)] {
+		return nil, ErrRecordNotFound
+	}
+	return rec, nil
+}
+
+// scope возвращает папки токена с путями и множество ID папок, записи
+// которых видит токен: папки токена и все вложенные в них. Папки
+// проверяются при каждом запросе, поэтому перемещенная из области папка
+// перестает быть видна сразу.
+func (s *Service) scope(ctx context.Context, token *Token) ([]SharedFolder, map[int]bool, error) {
+	all, err := s.folders.List(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list folders: %w", err)
+	}
+
+	exists := make(map[int]bool, len(all))
+	for _, f := range all {
+		exists[f.ID] = true
+	}
+	paths := folder.Paths(all)
+
+	scope := make(map[int]bool)
+	var shared []SharedFolder
+	for _, id := range token.FolderIDs {
+		if !exists[id] {
+			continue
+		}
+		shared = append(shared, SharedFolder{ID: id, Path: paths[id]})
+		for _, sub := range folder.Subtree(all, id) {
+			scope[sub] = true
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i].Path < shared[j].Path })
+	return shared, scope, nil
+}
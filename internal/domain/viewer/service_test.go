@@ -0,0 +1,216 @@
+package viewer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/app/server/api/http/middleware/auth"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/record"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// MockRepository is a mock implementation of the Repository interface for testing
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, token *Token, secretHash string) error {
+	args := m.Called(ctx, token, secretHash)
+	return args.Error(0)
+}
+
+func (m *MockRepository) List(ctx context.Context, userID int) ([]Token, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]Token), args.Error(1)
+}
+
+func (m *MockRepository) GetByHash(ctx context.Context, secretHash string) (*Token, error) {
+	args := m.Called(ctx, secretHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Token), args.Error(1)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, userID, id int) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+// fakeRecords и fakeFolders отдают записи и папки одного владельца
+type fakeRecords struct {
+	records []record.Record
+}
+
+func (f *fakeRecords) List(_ context.Context, _ int) ([]record.Record, error) {
+	return f.records, nil
+}
+
+func (f *fakeRecords) Get(_ context.Context, _ int, id int) (*record.Record, error) {
+	for _, rec := range f.records {
+		if rec.ID == id {
+			return &rec, nil
+		}
+	}
+	return nil, record.ErrNotFound
+}
+
+type fakeFolders struct {
+	folders []folder.Folder
+}
+
+func (f *fakeFolders) List(_ context.Context, _ int) ([]folder.Folder, error) {
+	return f.folders, nil
+}
+
+func (f *fakeFolders) Get(_ context.Context, _ int, id int) (*folder.Folder, error) {
+	for _, fl := range f.folders {
+		if fl.ID == id {
+			return &fl, nil
+		}
+	}
+	return nil, folder.ErrNotFound
+}
+
+func inFolder(id, folderID int) record.Record {
+	meta, _ := json.Marshal(map[string]any{"title": "record", "folder_id": folderID})
+	if folderID == 0 {
+		meta = json.RawMessage(`{"title":"record"}`)
+	}
+	return record.Record{ID: id, UserID: 5, Type: record.RecTypeLogin, EncryptedData: "secret", Meta: meta}
+}
+
+func newTestService(repo Repository) *Service {
+	parent := 1
+	folders := &fakeFolders{folders: []folder.Folder{
+		{ID: 1, UserID: 5, Name: "Audit"},
+		{ID: 2, UserID: 5, Name: "Cloud", ParentID: &parent},
+		{ID: 3, UserID: 5, Name: "Personal"},
+	}}
+	records := &fakeRecords{records: []record.Record{inFolder(10, 1), inFolder(11, 2), inFolder(12, 3), inFolder(13, 0)}}
+	return NewService(repo, records, folders, slog.Default())
+}
+
+func TestService_Create(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo)
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	var hash string
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*viewer.Token"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*Token).ID = 7
+			hash = args.String(2)
+		}).Return(nil)
+
+	issued, err := service.Create(ctx, CreateRequest{Name: " auditor ", FolderIDs: []int{1, 1}, TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, 7, issued.ID)
+	assert.Equal(t, "auditor", issued.Name)
+	assert.Equal(t, []int{1}, issued.FolderIDs)
+	assert.True(t, strings.HasPrefix(issued.Secret, TokenPrefix))
+	assert.Equal(t, HashSecret(issued.Secret), hash, "сервер хранит только хэш секрета")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), issued.ExpiresAt, time.Minute)
+}
+
+func TestService_Create_Invalid(t *testing.T) {
+	service := newTestService(new(MockRepository))
+	ctx := auth.WithUserID(context.Background(), 5)
+
+	tests := []struct {
+		name string
+		req  CreateRequest
+		err  error
+	}{
+		{"no name", CreateRequest{FolderIDs: []int{1}}, ErrInvalidRequest},
+		{"no folders", CreateRequest{Name: "audit"}, ErrInvalidRequest},
+		{"ttl too long", CreateRequest{Name: "audit", FolderIDs: []int{1}, TTL: MaxTTL + time.Hour}, ErrInvalidRequest},
+		{"ttl too short", CreateRequest{Name: "audit", FolderIDs: []int{1}, TTL: time.Second}, ErrInvalidRequest},
+		{"unknown folder", CreateRequest{Name: "audit", FolderIDs: []int{42}}, folder.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(ctx, tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	_, err := service.Create(context.Background(), CreateRequest{Name: "audit", FolderIDs: []int{1}})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestService_Authenticate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo)
+	ctx := context.Background()
+
+	active := &Token{ID: 1, UserID: 5, ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &Token{ID: 2, UserID: 5, ExpiresAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("GetByHash", mock.Anything, HashSecret("gkv_active")).Return(active, nil)
+	mockRepo.On("GetByHash", mock.Anything, HashSecret("gkv_expired")).Return(expired, nil)
+	mockRepo.On("GetByHash", mock.Anything, HashSecret("gkv_unknown")).Return(nil, ErrNotFound)
+	mockRepo.On("Touch", mock.Anything, 1, mock.AnythingOfType("time.Time")).Return(nil)
+
+	token, err := service.Authenticate(ctx, "gkv_active")
+	require.NoError(t, err)
+	assert.Equal(t, 1, token.ID)
+
+	for _, secret := range []string{"gkv_expired", "gkv_unknown", "session-token"} {
+		_, err := service.Authenticate(ctx, secret)
+		assert.ErrorIs(t, err, ErrInvalidToken, secret)
+	}
+	mockRepo.AssertNumberOfCalls(t, "Touch", 1)
+}
+
+func TestService_View(t *testing.T) {
+	service := newTestService(new(MockRepository))
+	token := &Token{ID: 1, UserID: 5, Name: "audit", FolderIDs: []int{1}, ExpiresAt: time.Now().Add(time.Hour)}
+	ctx := WithToken(context.Background(), token)
+
+	view, err := service.View(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []SharedFolder{{ID: 1, Path: "Audit"}}, view.Folders)
+
+	var ids []int
+	for _, item := range view.Records {
+		ids = append(ids, item.ID)
+	}
+	assert.ElementsMatch(t, []int{10, 11}, ids, "видны записи папки и вложенных папок")
+
+	rec, err := service.Record(ctx, 11)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", rec.EncryptedData)
+
+	for _, id := range []int{12, 13, 99} {
+		_, err := service.Record(ctx, id)
+		assert.ErrorIs(t, err, ErrRecordNotFound, "запись %d вне области токена", id)
+	}
+
+	_, err = service.View(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestService_Revoke(t *testing.T) {
+	mockRepo := new(MockRepository)
+	service := newTestService(mockRepo)
+
+	mockRepo.On("Delete", mock.Anything, 5, 3).Return(ErrNotFound)
+	err := service.Revoke(auth.WithUserID(context.Background(), 5), 3)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -4,16 +4,53 @@ import (
 	"errors"
 	"fmt"
 	"gophkeeper/internal/app/server/config"
+	"gophkeeper/migrations"
+	"net/url"
 
 	"github.com/golang-migrate/migrate/v4"
-	// Blank import required for PostgreSQL driver registration for migrations
+	// Blank imports required for PostgreSQL and SQLite driver registration for migrations
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
+// Источники миграций, встроенных в бинарник
+const (
+	EmbeddedSourceURL       = "embed://"
+	EmbeddedSQLiteSourceURL = "embed://sqlite"
+)
+
+func init() {
+	source.Register("embed", &embeddedSource{})
+}
+
+// embeddedSource открывает миграции из пакета migrations: embed:// - PostgreSQL,
+// embed://sqlite - SQLite
+type embeddedSource struct {
+	iofs.PartialDriver
+}
+
+func (d *embeddedSource) Open(rawURL string) (source.Driver, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Host {
+	case "":
+		return iofs.New(migrations.FS, ".")
+	case "sqlite":
+		return iofs.New(migrations.SQLiteFS, "sqlite")
+	}
+	return nil, fmt.Errorf("unknown embedded migrations %q", u.Host)
+}
+
 // Migrator — интерфейс для самой библиотеки migrate.Migrate
 type Migrator interface {
 	Up() error
+	Steps(n int) error
+	Version() (version uint, dirty bool, err error)
 	Close() (error, error)
 }
 
@@ -37,8 +74,65 @@ func DefaultEngine(sourceURL, databaseURL string) (Migrator, error) {
 	return migrate.New(sourceURL, databaseURL)
 }
 
+// SourceURL возвращает источник миграций: каталог MIGRATIONS_PATH, если он
+// задан, иначе миграции выбранного драйвера, встроенные в бинарник
+func (mg *Migration) SourceURL() string {
+	if mg.cfg.DB.Migrations != "" {
+		return "file://" + mg.cfg.DB.Migrations
+	}
+	if mg.cfg.DB.Driver == config.DriverSQLite {
+		return EmbeddedSQLiteSourceURL
+	}
+	return EmbeddedSourceURL
+}
+
+// DatabaseURL возвращает адрес базы в формате golang-migrate
+func (mg *Migration) DatabaseURL() string {
+	if mg.cfg.DB.Driver == config.DriverSQLite {
+		return "sqlite3://" + mg.cfg.DB.SQLitePath
+	}
+	return mg.cfg.DB.DatabaseURI
+}
+
+// Up применяет все неприменённые миграции
 func (mg *Migration) Up() error {
-	m, err := mg.engine("file://"+mg.cfg.DB.Migrations, mg.cfg.DB.DatabaseURI)
+	return mg.run(func(m Migrator) error {
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("%w; migration up error", err)
+		}
+		return nil
+	})
+}
+
+// Down откатывает последние steps миграций
+func (mg *Migration) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return mg.run(func(m Migrator) error {
+		if err := m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("%w; migration down error", err)
+		}
+		return nil
+	})
+}
+
+// Version возвращает номер последней применённой миграции. dirty означает,
+// что миграция упала на середине и схему нужно чинить вручную.
+func (mg *Migration) Version() (version uint, dirty bool, err error) {
+	err = mg.run(func(m Migrator) error {
+		var verr error
+		version, dirty, verr = m.Version()
+		if errors.Is(verr, migrate.ErrNilVersion) {
+			return nil
+		}
+		return verr
+	})
+	return version, dirty, err
+}
+
+func (mg *Migration) run(fn func(Migrator) error) (err error) {
+	m, err := mg.engine(mg.SourceURL(), mg.DatabaseURL())
 	if err != nil {
 		return err
 	}
@@ -59,8 +153,5 @@ func (mg *Migration) Up() error {
 			}
 		}
 	}()
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("%w; migration up error", err)
-	}
-	return nil
+	return fn(m)
 }
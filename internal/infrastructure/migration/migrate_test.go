@@ -2,13 +2,20 @@ package migration
 
 import (
 	"errors"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"gophkeeper/internal/app/server/config"
+	"gophkeeper/migrations"
 )
 
 // MockMigrator — мок для интерфейса Migrator
@@ -21,18 +28,23 @@ func (m *MockMigrator) Up() error {
 	return args.Error(0)
 }
 
+func (m *MockMigrator) Steps(n int) error {
+	args := m.Called(n)
+	return args.Error(0)
+}
+
+func (m *MockMigrator) Version() (uint, bool, error) {
+	args := m.Called()
+	return args.Get(0).(uint), args.Bool(1), args.Error(2)
+}
+
 func (m *MockMigrator) Close() (error, error) {
 	args := m.Called()
 	return args.Error(0), args.Error(1)
 }
 
 func TestMigration_Up_Success(t *testing.T) {
-	cfg := &config.Config{
-		DB: struct {
-			DatabaseURI string `env:"DATABASE_URI"`
-			Migrations  string `env:"MIGRATIONS_PATH"`
-		}{DatabaseURI: "", Migrations: ""},
-	}
+	cfg := &config.Config{}
 	mockM := new(MockMigrator)
 
 	// Настраиваем поведение
@@ -52,12 +64,7 @@ func TestMigration_Up_Success(t *testing.T) {
 }
 
 func TestMigration_Up_NoChange(t *testing.T) {
-	cfg := &config.Config{
-		DB: struct {
-			DatabaseURI string `env:"DATABASE_URI"`
-			Migrations  string `env:"MIGRATIONS_PATH"`
-		}{DatabaseURI: "", Migrations: ""},
-	}
+	cfg := &config.Config{}
 	mockM := new(MockMigrator)
 
 	// ErrNoChange не должна считаться ошибкой в методе Up()
@@ -75,12 +82,7 @@ func TestMigration_Up_NoChange(t *testing.T) {
 }
 
 func TestMigration_Up_EngineError(t *testing.T) {
-	cfg := &config.Config{
-		DB: struct {
-			DatabaseURI string `env:"DATABASE_URI"`
-			Migrations  string `env:"MIGRATIONS_PATH"`
-		}{DatabaseURI: "", Migrations: ""},
-	}
+	cfg := &config.Config{}
 
 	// Ошибка на этапе создания мигратора (например, неверный драйвер)
 	engine := func(_, _ string) (Migrator, error) {
@@ -93,3 +95,138 @@ func TestMigration_Up_EngineError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "engine crash", err.Error())
 }
+
+func TestMigration_Down(t *testing.T) {
+	mockM := new(MockMigrator)
+	mockM.On("Steps", -2).Return(nil)
+	mockM.On("Close").Return(nil, nil)
+
+	mg := NewMigration(&config.Config{}, func(_, _ string) (Migrator, error) {
+		return mockM, nil
+	})
+
+	assert.NoError(t, mg.Down(2))
+	assert.Error(t, mg.Down(0))
+	mockM.AssertExpectations(t)
+}
+
+func TestMigration_CloseErrorIsReturned(t *testing.T) {
+	mockM := new(MockMigrator)
+	mockM.On("Up").Return(nil)
+	mockM.On("Close").Return(nil, errors.New("connection reset"))
+
+	mg := NewMigration(&config.Config{}, func(_, _ string) (Migrator, error) {
+		return mockM, nil
+	})
+
+	err := mg.Up()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+}
+
+func TestMigration_Version(t *testing.T) {
+	t.Run("Applied", func(t *testing.T) {
+		mockM := new(MockMigrator)
+		mockM.On("Version").Return(uint(15), false, nil)
+		mockM.On("Close").Return(nil, nil)
+
+		mg := NewMigration(&config.Config{}, func(_, _ string) (Migrator, error) {
+			return mockM, nil
+		})
+
+		version, dirty, err := mg.Version()
+		require.NoError(t, err)
+		assert.Equal(t, uint(15), version)
+		assert.False(t, dirty)
+	})
+
+	t.Run("EmptyDatabase", func(t *testing.T) {
+		mockM := new(MockMigrator)
+		mockM.On("Version").Return(uint(0), false, migrate.ErrNilVersion)
+		mockM.On("Close").Return(nil, nil)
+
+		mg := NewMigration(&config.Config{}, func(_, _ string) (Migrator, error) {
+			return mockM, nil
+		})
+
+		version, _, err := mg.Version()
+		require.NoError(t, err)
+		assert.Zero(t, version)
+	})
+}
+
+func TestMigration_SourceURL(t *testing.T) {
+	cfg := &config.Config{}
+	assert.Equal(t, EmbeddedSourceURL, NewMigration(cfg, DefaultEngine).SourceURL())
+
+	cfg.DB.Migrations = "/opt/migrations"
+	assert.Equal(t, "file:///opt/migrations", NewMigration(cfg, DefaultEngine).SourceURL())
+
+	cfg = &config.Config{}
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.SQLitePath = "/var/lib/gophkeeper/data.db"
+	mg := NewMigration(cfg, DefaultEngine)
+	assert.Equal(t, EmbeddedSQLiteSourceURL, mg.SourceURL())
+	assert.Equal(t, "sqlite3:///var/lib/gophkeeper/data.db", mg.DatabaseURL())
+}
+
+func TestMigration_SQLiteUpDown(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.SQLitePath = filepath.Join(t.TempDir(), "gophkeeper.db")
+	mg := NewMigration(cfg, DefaultEngine)
+
+	require.NoError(t, mg.Up())
+	version, dirty, err := mg.Version()
+	require.NoError(t, err)
+	assert.NotZero(t, version)
+	assert.False(t, dirty)
+
+	// Повторный запуск ничего не применяет
+	require.NoError(t, mg.Up())
+
+	require.NoError(t, mg.Down(int(version)))
+	version, _, err = mg.Version()
+	require.NoError(t, err)
+	assert.Zero(t, version)
+}
+
+func TestEmbeddedSource_ContainsAllMigrations(t *testing.T) {
+	ups, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	driver, err := source.Open(EmbeddedSourceURL)
+	require.NoError(t, err)
+	defer driver.Close()
+
+	version, err := driver.First()
+	require.NoError(t, err)
+	count := 1
+	for {
+		// У каждой миграции должен быть откат
+		r, _, err := driver.ReadDown(version)
+		require.NoError(t, err, "migration %d has no down file", version)
+		r.Close()
+
+		next, err := driver.Next(version)
+		if err != nil {
+			break
+		}
+		version = next
+		count++
+	}
+	assert.Equal(t, len(ups), count)
+
+	// Схема покрывает все таблицы, с которыми работает сервер
+	var schema strings.Builder
+	for _, name := range ups {
+		data, err := fs.ReadFile(migrations.FS, name)
+		require.NoError(t, err)
+		schema.Write(data)
+	}
+	for _, table := range []string{"users", "records", "record_versions", "sync_status_view", "sync_conflicts", "devices", "sessions"} {
+		created := regexp.MustCompile(`CREATE (TABLE|OR REPLACE VIEW) (IF NOT EXISTS )?` + table + `\b`)
+		assert.Regexp(t, created, schema.String(), "table %s", table)
+	}
+}
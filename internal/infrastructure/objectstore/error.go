@@ -0,0 +1,8 @@
+package objectstore
+
+import "errors"
+
+var (
+	ErrInvalidConfig = errors.New("invalid object store config")
+	ErrNotFound      = errors.New("object not found")
+)
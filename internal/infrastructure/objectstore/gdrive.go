@@ -0,0 +1,304 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	gosync "sync"
+	"time"
+)
+
+const (
+	driveTokenURL  = "https://oauth2.googleapis.com/token"
+	driveAPIURL    = "https://www.googleapis.com/drive/v3"
+	driveUploadURL = "https://www.googleapis.com/upload/drive/v3"
+)
+
+// DriveConfig параметры Google Drive. Доступ выдается OAuth-клиентом
+// пользователя: refresh token получается один раз (например, в OAuth Playground
+// со scope drive.file) и обменивается на короткоживущие токены доступа.
+type DriveConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	FolderID     string `json:"folder_id"` // папка, в которой хранятся объекты
+}
+
+// DriveStore - хранилище объектов в папке Google Drive (REST API v3).
+// Ключ - имя файла в папке.
+type DriveStore struct {
+	cfg    DriveConfig
+	client *http.Client
+
+	tokenURL  string
+	apiURL    string
+	uploadURL string
+	now       func() time.Time
+
+	mu      gosync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewDriveStore создает клиент Google Drive. Если client == nil, используется клиент с таймаутом 60 секунд.
+func NewDriveStore(cfg DriveConfig, client *http.Client) (*DriveStore, error) {
+	if cfg.ClientID == "" || cfg.RefreshToken == "" || cfg.FolderID == "" {
+		return nil, fmt.Errorf("%w: drive client id, refresh token and folder id are required", ErrInvalidConfig)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &DriveStore{
+		cfg:       cfg,
+		client:    client,
+		tokenURL:  driveTokenURL,
+		apiURL:    driveAPIURL,
+		uploadURL: driveUploadURL,
+		now:       time.Now,
+	}, nil
+}
+
+type driveFile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type driveFileList struct {
+	Files         []driveFile `json:"files"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// Put создает файл или заменяет содержимое файла с тем же именем
+func (s *DriveStore) Put(ctx context.Context, key string, data []byte) error {
+	file, err := s.find(ctx, key)
+	if err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+
+	if file != nil {
+		u := s.uploadURL + "/files/" + url.PathEscape(file.ID) + "?uploadType=media"
+		return s.call(ctx, http.MethodPatch, u, "application/octet-stream", data, nil)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	meta, err := json.Marshal(map[string]any{"name": key, "parents": []string{s.cfg.FolderID}})
+	if err != nil {
+		return err
+	}
+	part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	_, _ = part.Write(meta)
+	part, err = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/octet-stream"}})
+	if err != nil {
+		return err
+	}
+	_, _ = part.Write(data)
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	u := s.uploadURL + "/files?uploadType=multipart"
+	if err := s.call(ctx, http.MethodPost, u, "multipart/related; boundary="+mw.Boundary(), body.Bytes(), nil); err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *DriveStore) Get(ctx context.Context, key string) ([]byte, error) {
+	file, err := s.find(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	var data []byte
+	u := s.apiURL + "/files/" + url.PathEscape(file.ID) + "?alt=media"
+	if err := s.call(ctx, http.MethodGet, u, "", nil, func(r io.Reader) error {
+		var err error
+		data, err = io.ReadAll(r)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (s *DriveStore) Delete(ctx context.Context, key string) error {
+	file, err := s.find(ctx, key)
+	if err != nil || file == nil {
+		return err
+	}
+
+	if err := s.call(ctx, http.MethodDelete, s.apiURL+"/files/"+url.PathEscape(file.ID), "", nil, nil); err != nil {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *DriveStore) List(ctx context.Context, prefix string) ([]string, error) {
+	files, err := s.query(ctx, "name contains "+driveQuote(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+
+	var keys []string
+	for _, f := range files {
+		// contains ищет по словам, поэтому префикс проверяется еще раз
+		if strings.HasPrefix(f.Name, prefix) {
+			keys = append(keys, f.Name)
+		}
+	}
+	return keys, nil
+}
+
+// find возвращает файл папки с именем name или nil
+func (s *DriveStore) find(ctx context.Context, name string) (*driveFile, error) {
+	files, err := s.query(ctx, "name = "+driveQuote(name))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	return &files[0], nil
+}
+
+// query возвращает файлы папки, подходящие под условие поиска Drive
+func (s *DriveStore) query(ctx context.Context, cond string) ([]driveFile, error) {
+	q := driveQuote(s.cfg.FolderID) + " in parents and trashed = false"
+	if cond != "" {
+		q += " and " + cond
+	}
+
+	var files []driveFile
+	pageToken := ""
+	for {
+		params := url.Values{}
+		params.Set("q", q)
+		params.Set("fields", "nextPageToken,files(id,name)")
+		params.Set("pageSize", "1000")
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+
+		var page driveFileList
+		if err := s.call(ctx, http.MethodGet, s.apiURL+"/files?"+params.Encode(), "", nil, func(r io.Reader) error {
+			return json.NewDecoder(r).Decode(&page)
+		}); err != nil {
+			return nil, err
+		}
+
+		files = append(files, page.Files...)
+		if page.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// call выполняет запрос к Drive с токеном доступа. read получает тело успешного ответа.
+func (s *DriveStore) call(ctx context.Context, method, u, contentType string, body []byte, read func(io.Reader) error) error {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkDriveResponse(resp); err != nil {
+		return err
+	}
+	if read != nil {
+		return read(resp.Body)
+	}
+	return nil
+}
+
+// accessToken возвращает токен доступа, обновляя его по refresh token за минуту до истечения
+func (s *DriveStore) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expires.Add(-time.Minute)) {
+		return s.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("client_id", s.cfg.ClientID)
+	form.Set("client_secret", s.cfg.ClientSecret)
+	form.Set("refresh_token", s.cfg.RefreshToken)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("refresh drive token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkDriveResponse(resp); err != nil {
+		return "", fmt.Errorf("refresh drive token: %w", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("refresh drive token: %w", err)
+	}
+
+	s.token = token.AccessToken
+	s.expires = s.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+func checkDriveResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var apiErr struct {
+		Error any `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != nil {
+		return fmt.Errorf("drive error %d: %v", resp.StatusCode, apiErr.Error)
+	}
+	return fmt.Errorf("drive error %d", resp.StatusCode)
+}
+
+// driveQuote записывает строку литералом языка запросов Drive
+func driveQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
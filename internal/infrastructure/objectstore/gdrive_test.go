@@ -0,0 +1,106 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriveStore_Requests(t *testing.T) {
+	type file struct{ name, data string }
+	files := map[string]*file{}
+	tokenRequests := 0
+	nextID := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests++
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh", r.PostForm.Get("refresh_token"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "expires_in": 3600})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/files":
+			q := r.URL.Query().Get("q")
+			assert.Contains(t, q, "'folder' in parents")
+			var list driveFileList
+			for id, f := range files {
+				if strings.Contains(q, "name = '"+f.name+"'") || strings.Contains(q, "name contains") {
+					list.Files = append(list.Files, driveFile{ID: id, Name: f.name})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/files":
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			part, err := mr.NextPart()
+			require.NoError(t, err)
+			var meta struct {
+				Name    string   `json:"name"`
+				Parents []string `json:"parents"`
+			}
+			require.NoError(t, json.NewDecoder(part).Decode(&meta))
+			assert.Equal(t, []string{"folder"}, meta.Parents)
+			part, err = mr.NextPart()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(part)
+			nextID++
+			files[fmt.Sprint(nextID)] = &file{name: meta.Name, data: string(data)}
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/upload/files/"):
+			data, _ := io.ReadAll(r.Body)
+			files[strings.TrimPrefix(r.URL.Path, "/upload/files/")].data = string(data)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/files/"):
+			_, _ = io.WriteString(w, files[strings.TrimPrefix(r.URL.Path, "/api/files/")].data)
+		case r.Method == http.MethodDelete:
+			delete(files, strings.TrimPrefix(r.URL.Path, "/api/files/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewDriveStore(DriveConfig{ClientID: "id", ClientSecret: "s", RefreshToken: "refresh", FolderID: "folder"}, server.Client())
+	require.NoError(t, err)
+	store.tokenURL = server.URL + "/token"
+	store.apiURL = server.URL + "/api"
+	store.uploadURL = server.URL + "/upload"
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "vault-1.gkbackup", []byte("one")))
+	require.NoError(t, store.Put(ctx, "vault-1.gkbackup", []byte("two")))
+	assert.Len(t, files, 1, "повторная запись заменяет содержимое файла")
+
+	data, err := store.Get(ctx, "vault-1.gkbackup")
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+
+	keys, err := store.List(ctx, "vault-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vault-1.gkbackup"}, keys)
+
+	require.NoError(t, store.Delete(ctx, "vault-1.gkbackup"))
+	_, err = store.Get(ctx, "vault-1.gkbackup")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 1, tokenRequests, "токен доступа переиспользуется до истечения")
+
+	_, err = NewDriveStore(DriveConfig{ClientID: "id"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
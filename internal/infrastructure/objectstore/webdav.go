@@ -0,0 +1,197 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// WebDAVConfig параметры WebDAV-сервера (Nextcloud, ownCloud, nginx dav)
+type WebDAVConfig struct {
+	URL      string `json:"url"` // каталог, в котором хранятся объекты
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// WebDAVStore - хранилище объектов на WebDAV-сервере. Ключ - путь файла
+// относительно URL; недостающие каталоги создаются при записи.
+type WebDAVStore struct {
+	cfg    WebDAVConfig
+	base   *url.URL
+	client *http.Client
+}
+
+// NewWebDAVStore создает клиент WebDAV. Если client == nil, используется клиент с таймаутом 60 секунд.
+func NewWebDAVStore(cfg WebDAVConfig, client *http.Client) (*WebDAVStore, error) {
+	base, err := url.Parse(cfg.URL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("%w: invalid webdav url %q", ErrInvalidConfig, cfg.URL)
+	}
+	base.Path = strings.TrimRight(base.Path, "/") + "/"
+
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &WebDAVStore{
+		cfg:    cfg,
+		base:   base,
+		client: client,
+	}, nil
+}
+
+func (s *WebDAVStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := s.mkdirAll(ctx, path.Dir(key)); err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	return checkWebDAVResponse(resp)
+}
+
+func (s *WebDAVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err := checkWebDAVResponse(resp); err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (s *WebDAVStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkWebDAVResponse(resp)
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href       string    `xml:"href"`
+		Collection *struct{} `xml:"propstat>prop>resourcetype>collection"`
+	} `xml:"response"`
+}
+
+// List возвращает файлы каталога, в котором лежат ключи с префиксом prefix.
+// Вложенные каталоги не обходятся.
+func (s *WebDAVStore) List(ctx context.Context, prefix string) ([]string, error) {
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i+1]
+	}
+
+	header := http.Header{"Depth": {"1"}, "Content-Type": {"application/xml"}}
+	body := []byte(`<?xml version="1.0"?><d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/></d:prop></d:propfind>`)
+	resp, err := s.do(ctx, "PROPFIND", dir, header, body)
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := checkWebDAVResponse(resp); err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("list %s: %w", prefix, err)
+	}
+
+	var keys []string
+	for _, r := range result.Responses {
+		if r.Collection != nil {
+			continue
+		}
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		key := strings.TrimPrefix(href.Path, s.base.Path)
+		if key == href.Path || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// mkdirAll создает каталоги пути dir. Уже существующий каталог - не ошибка.
+func (s *WebDAVStore) mkdirAll(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+
+	current := ""
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		current += part + "/"
+		resp, err := s.do(ctx, "MKCOL", current, nil, nil)
+		if err != nil {
+			return fmt.Errorf("mkcol %s: %w", current, err)
+		}
+		_ = resp.Body.Close()
+		// 405 - каталог уже существует
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			if err := checkWebDAVResponse(resp); err != nil {
+				return fmt.Errorf("mkcol %s: %w", current, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *WebDAVStore) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	u := s.base.JoinPath(key)
+	if strings.HasSuffix(key, "/") && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if s.cfg.Username != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+
+	return s.client.Do(req)
+}
+
+func checkWebDAVResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("webdav error %d", resp.StatusCode)
+}
@@ -0,0 +1,98 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebDAVStore_Requests(t *testing.T) {
+	files := map[string]string{}
+	dirs := map[string]bool{"/dav/": true}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		p := r.URL.Path
+		switch r.Method {
+		case "MKCOL":
+			if dirs[p] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			dirs[p] = true
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			parent := p[:strings.LastIndex(p, "/")+1]
+			if !dirs[parent] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			files[p] = string(body)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := files[p]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, data)
+		case http.MethodDelete:
+			delete(files, p)
+			w.WriteHeader(http.StatusNoContent)
+		case "PROPFIND":
+			assert.Equal(t, "1", r.Header.Get("Depth"))
+			if !dirs[p] {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusMultiStatus)
+			_, _ = fmt.Fprintf(w, `<d:multistatus xmlns:d="DAV:"><d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop></d:propstat></d:response>`, p)
+			for name := range files {
+				if strings.HasPrefix(name, p) && !strings.Contains(name[len(p):], "/") {
+					_, _ = fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype/></d:prop></d:propstat></d:response>`, name)
+				}
+			}
+			_, _ = io.WriteString(w, `</d:multistatus>`)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewWebDAVStore(WebDAVConfig{URL: server.URL + "/dav", Username: "alice", Password: "secret"}, server.Client())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "p/q/a.bin", []byte("a")))
+	require.NoError(t, store.Put(ctx, "p/q/b.bin", []byte("b")))
+	assert.True(t, dirs["/dav/p/q/"])
+
+	data, err := store.Get(ctx, "p/q/a.bin")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	keys, err := store.List(ctx, "p/q/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"p/q/a.bin", "p/q/b.bin"}, keys)
+
+	keys, err = store.List(ctx, "missing/")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, store.Delete(ctx, "p/q/a.bin"))
+	_, err = store.Get(ctx, "p/q/a.bin")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = NewWebDAVStore(WebDAVConfig{URL: "not a url"}, nil)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
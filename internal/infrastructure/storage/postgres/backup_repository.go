@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/domain/record"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// BackupRepository реализует backup.Repository для PostgreSQL
+type BackupRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+func NewBackupRepository(pool *pgxpool.Pool, log *slog.Logger) *BackupRepository {
+	return &BackupRepository{
+		pool: pool,
+		log:  log.With("component", "backup_repository"),
+	}
+}
+
+// ListUserIDs возвращает ID всех пользователей, у которых есть записи
+func (r *BackupRepository) ListUserIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT user_id FROM records ORDER BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListAllRecords возвращает все записи пользователя, включая удаленные
+func (r *BackupRepository) ListAllRecords(ctx context.Context, userID int) ([]record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified,
+		       COALESCE(checksum, ''), COALESCE(device_id, ''), deleted_at, org_id,
+		       uuid::text
+		FROM records
+		WHERE user_id = $1
+		ORDER BY id`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list records for backup", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []record.Record
+	for rows.Next() {
+		var rec record.Record
+		var data []byte
+		var deletedAt sql.NullTime
+
+		if err := rows.Scan(
+			&rec.ID, &rec.UserID, &rec.Type, &data,
+			&rec.Meta, &rec.Version, &rec.LastModified,
+			&rec.Checksum, &rec.DeviceID, &deletedAt, &rec.OrgID,
+			&rec.UUID,
+		); err != nil {
+			return nil, fmt.Errorf("scan record: %w", err)
+		}
+
+		rec.EncryptedData = hex.EncodeToString(data)
+		if deletedAt.Valid {
+			rec.DeletedAt = &deletedAt.Time
+		}
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
+
+// RestoreRecords возвращает записи к состоянию из снимка в одной транзакции.
+// Измененные записи получают новую версию, чтобы клиенты забрали их при синхронизации;
+// удаленные из базы записи вставляются с исходным ID. Записи, созданные после
+// снимка, не затрагиваются. Возвращает число измененных записей.
+func (r *BackupRepository) RestoreRecords(ctx context.Context, userID int, records []record.Record) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	restored := 0
+	for _, rec := range records {
+		changed, err := r.restoreRecord(ctx, tx, userID, &rec)
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			restored++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored, nil
+}
+
+func (r *BackupRepository) restoreRecord(ctx context.Context, tx pgx.Tx, userID int, rec *record.Record) (bool, error) {
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return false, fmt.Errorf("%w: record %d: %v", record.ErrInvalidData, rec.ID, err)
+	}
+
+	var ownerID int
+	err = tx.QueryRow(ctx, `SELECT user_id FROM records WHERE id = $1 FOR UPDATE`, rec.ID).Scan(&ownerID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// Запись удаленной организации не восстанавливается
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO records (id, user_id, type, encrypted_data, meta, version,
+			                     last_modified, checksum, device_id, deleted_at, org_id, uuid)
+			OVERRIDING SYSTEM VALUE
+			SELECT $1, $2, $3, $4, $5, $6, NOW(), NULLIF($7, ''), NULLIF($8, ''), $9, $10::int,
+			       COALESCE(NULLIF($11, '')::uuid, gen_random_uuid())
+			WHERE $10::int IS NULL OR EXISTS (SELECT 1 FROM organizations WHERE id = $10::int)
+			ON CONFLICT DO NOTHING`,
+			rec.ID, userID, rec.Type, data, rec.Meta, rec.Version+1,
+			rec.Checksum, rec.DeviceID, rec.DeletedAt, rec.OrgID, rec.UUID)
+		if err != nil {
+			return false, fmt.Errorf("insert record %d: %w", rec.ID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			r.log.Warn("record skipped on restore: duplicate data or organization removed",
+				"record_id", rec.ID, "user_id", userID)
+		}
+		return tag.RowsAffected() > 0, nil
+	case err != nil:
+		return false, fmt.Errorf("lock record %d: %w", rec.ID, err)
+	case ownerID != userID:
+		return false, fmt.Errorf("record %d belongs to another user", rec.ID)
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE records
+		SET type = $1, encrypted_data = $2, meta = $3, checksum = NULLIF($4, ''),
+		    deleted_at = $5, version = version + 1, last_modified = NOW()
+		WHERE id = $6 AND user_id = $7
+		  AND (encrypted_data <> $2 OR meta <> $3::jsonb OR deleted_at IS DISTINCT FROM $5)`,
+		rec.Type, data, rec.Meta, rec.Checksum, rec.DeletedAt, rec.ID, userID)
+	if err != nil {
+		return false, fmt.Errorf("update record %d: %w", rec.ID, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
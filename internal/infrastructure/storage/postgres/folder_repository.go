@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/domain/folder"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// FolderRepository реализует folder.Repository для PostgreSQL
+type FolderRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+func NewFolderRepository(pool *pgxpool.Pool, log *slog.Logger) *FolderRepository {
+	return &FolderRepository{
+		pool: pool,
+		log:  log.With("component", "folder_repository"),
+	}
+}
+
+const folderColumns = `id, user_id, parent_id, name, created_at, updated_at`
+
+func (r *FolderRepository) List(ctx context.Context, userID int) ([]folder.Folder, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+folderColumns+`
+		FROM folders
+		WHERE user_id = $1
+		ORDER BY name, id`, userID)
+	if err != nil {
+		r.log.Error("failed to list folders", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []folder.Folder
+	for rows.Next() {
+		f, err := scanFolder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan folder: %w", err)
+		}
+		folders = append(folders, *f)
+	}
+
+	return folders, rows.Err()
+}
+
+func (r *FolderRepository) Get(ctx context.Context, userID, id int) (*folder.Folder, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+folderColumns+`
+		FROM folders
+		WHERE id = $1 AND user_id = $2`, id, userID)
+
+	f, err := scanFolder(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, folder.ErrNotFound
+		}
+		return nil, fmt.Errorf("get folder: %w", err)
+	}
+	return f, nil
+}
+
+func (r *FolderRepository) Create(ctx context.Context, f *folder.Folder) (int, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO folders (user_id, parent_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`,
+		f.UserID, f.ParentID, f.Name, f.CreatedAt, f.UpdatedAt,
+	).Scan(&f.ID)
+	if err != nil {
+		r.log.Error("failed to create folder", "user_id", f.UserID, "error", err)
+		return 0, fmt.Errorf("insert folder: %w", err)
+	}
+	return f.ID, nil
+}
+
+func (r *FolderRepository) Update(ctx context.Context, f *folder.Folder) error {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE folders
+		SET parent_id = $1, name = $2, updated_at = $3
+		WHERE id = $4 AND user_id = $5`,
+		f.ParentID, f.Name, f.UpdatedAt, f.ID, f.UserID)
+	if err != nil {
+		r.log.Error("failed to update folder", "folder_id", f.ID, "error", err)
+		return fmt.Errorf("update folder: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return folder.ErrNotFound
+	}
+	return nil
+}
+
+func (r *FolderRepository) Delete(ctx context.Context, userID, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM folders WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		r.log.Error("failed to delete folder", "folder_id", id, "error", err)
+		return fmt.Errorf("delete folder: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return folder.ErrNotFound
+	}
+	return nil
+}
+
+func (r *FolderRepository) CountRecords(ctx context.Context, userID, id int) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM records
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NULL AND meta->>'folder_id' = $2`,
+		userID, fmt.Sprint(id)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count folder records: %w", err)
+	}
+	return count, nil
+}
+
+func scanFolder(row interface{ Scan(dest ...any) error }) (*folder.Folder, error) {
+	var f folder.Folder
+	if err := row.Scan(&f.ID, &f.UserID, &f.ParentID, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
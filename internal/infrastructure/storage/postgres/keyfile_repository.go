@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/keyfile"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// KeyFileRepository реализует keyfile.Repository для PostgreSQL
+type KeyFileRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+// NewKeyFileRepository создает новый репозиторий файлов мастер-ключа
+func NewKeyFileRepository(pool *pgxpool.Pool, log *slog.Logger) *KeyFileRepository {
+	return &KeyFileRepository{
+		pool: pool,
+		log:  log,
+	}
+}
+
+// Get возвращает файл мастер-ключа пользователя
+func (r *KeyFileRepository) Get(ctx context.Context, userID int) (*keyfile.KeyFile, error) {
+	var file keyfile.KeyFile
+	err := r.pool.QueryRow(ctx,
+		`SELECT data, key_hash, updated_at FROM user_key_files WHERE user_id = $1`,
+		userID).Scan(&file.Data, &file.KeyHash, &file.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, keyfile.ErrNotFound
+		}
+		r.log.Error("failed to get key file", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get key file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// Save сохраняет файл мастер-ключа; файл другого ключа не заменяет сохраненный
+func (r *KeyFileRepository) Save(ctx context.Context, userID int, file keyfile.KeyFile) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO user_key_files (user_id, data, key_hash, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			data = EXCLUDED.data,
+			updated_at = EXCLUDED.updated_at
+		WHERE user_key_files.key_hash = EXCLUDED.key_hash`,
+		userID, file.Data, file.KeyHash)
+	if err != nil {
+		r.log.Error("failed to save key file", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to save key file: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return keyfile.ErrKeyMismatch
+	}
+
+	return nil
+}
+
+// Replace заменяет файл мастер-ключа после смены мастер-пароля
+func (r *KeyFileRepository) Replace(ctx context.Context, userID int, file keyfile.KeyFile, previousKeyHash string) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO user_key_files (user_id, data, key_hash, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			data = EXCLUDED.data,
+			key_hash = EXCLUDED.key_hash,
+			updated_at = EXCLUDED.updated_at
+		WHERE user_key_files.key_hash IN (EXCLUDED.key_hash, $4)`,
+		userID, file.Data, file.KeyHash, previousKeyHash)
+	if err != nil {
+		r.log.Error("failed to replace key file", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to replace key file: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return keyfile.ErrKeyMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/mfa"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// MFARepository реализует mfa.Repository для PostgreSQL
+type MFARepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+// NewMFARepository создает новый репозиторий второго фактора
+func NewMFARepository(pool *pgxpool.Pool, log *slog.Logger) *MFARepository {
+	return &MFARepository{
+		pool: pool,
+		log:  log,
+	}
+}
+
+// Get возвращает секрет TOTP пользователя
+func (r *MFARepository) Get(ctx context.Context, userID int) (*mfa.TOTP, error) {
+	totp := &mfa.TOTP{UserID: userID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT secret, enabled, last_used_step
+		FROM user_totp
+		WHERE user_id = $1`,
+		userID).Scan(&totp.Secret, &totp.Enabled, &totp.LastStep)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, mfa.ErrNotEnabled
+		}
+		r.log.Error("failed to get totp", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+
+	return totp, nil
+}
+
+// SavePending сохраняет неподтвержденный секрет
+func (r *MFARepository) SavePending(ctx context.Context, userID int, secret string) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			last_used_step = 0,
+			created_at = NOW()
+		WHERE NOT user_totp.enabled`,
+		userID, secret)
+	if err != nil {
+		r.log.Error("failed to save pending totp", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to save pending totp: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return mfa.ErrAlreadyEnabled
+	}
+
+	return nil
+}
+
+// Enable включает второй фактор и заменяет коды восстановления в одной транзакции
+func (r *MFARepository) Enable(ctx context.Context, userID int, step int64, recoveryHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE user_totp
+		SET enabled = TRUE, enabled_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND NOT enabled`,
+		userID, step)
+	if err != nil {
+		r.log.Error("failed to enable totp", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return mfa.ErrNotPending
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryHashes); err != nil {
+		r.log.Error("failed to save recovery codes", "user_id", userID, "error", err)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete удаляет секрет и коды восстановления
+func (r *MFARepository) Delete(ctx context.Context, userID int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		r.log.Error("failed to delete recovery codes", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		r.log.Error("failed to delete totp", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep атомарно сдвигает последний использованный шаг TOTP вперед
+func (r *MFARepository) UseStep(ctx context.Context, userID int, step int64) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2`,
+		userID, step)
+	if err != nil {
+		r.log.Error("failed to use totp step", "user_id", userID, "error", err)
+		return false, fmt.Errorf("failed to use totp step: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// ReplaceRecoveryCodes заменяет коды восстановления
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryHashes); err != nil {
+		r.log.Error("failed to replace recovery codes", "user_id", userID, "error", err)
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode отмечает код восстановления использованным
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int, hash string) (bool, error) {
+	result, err := r.pool.Exec(ctx, `
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, hash)
+	if err != nil {
+		r.log.Error("failed to use recovery code", "user_id", userID, "error", err)
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecoveryCodesLeft возвращает число неиспользованных кодов восстановления
+func (r *MFARepository) RecoveryCodesLeft(ctx context.Context, userID int) (int, error) {
+	var left int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM user_recovery_codes
+		WHERE user_id = $1 AND used_at IS NULL`,
+		userID).Scan(&left)
+	if err != nil {
+		r.log.Error("failed to count recovery codes", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return left, nil
+}
+
+// CreateChallenge сохраняет незавершенный вход и удаляет истекшие
+func (r *MFARepository) CreateChallenge(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM login_challenges WHERE expires_at <= NOW()`); err != nil {
+		r.log.Warn("failed to delete expired login challenges", "error", err)
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO login_challenges (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)`,
+		tokenHash, userID, expiresAt)
+	if err != nil {
+		r.log.Error("failed to create login challenge", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	return nil
+}
+
+// AttemptChallenge учитывает попытку ввода кода для незавершенного входа
+func (r *MFARepository) AttemptChallenge(ctx context.Context, tokenHash string) (int, int, error) {
+	var userID, attempts int
+	err := r.pool.QueryRow(ctx, `
+		UPDATE login_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1 AND expires_at > NOW()
+		RETURNING user_id, attempts`,
+		tokenHash).Scan(&userID, &attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, mfa.ErrChallengeNotFound
+		}
+		r.log.Error("failed to attempt login challenge", "error", err)
+		return 0, 0, fmt.Errorf("failed to attempt login challenge: %w", err)
+	}
+
+	return userID, attempts, nil
+}
+
+// DeleteChallenge удаляет незавершенный вход
+func (r *MFARepository) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM login_challenges WHERE token_hash = $1`, tokenHash); err != nil {
+		r.log.Error("failed to delete login challenge", "error", err)
+		return fmt.Errorf("failed to delete login challenge: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int, hashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range hashes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)`,
+			userID, hash)
+		if err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
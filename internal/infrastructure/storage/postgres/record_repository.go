@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"gophkeeper/internal/domain/record"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/jackc/pgx/v5"
@@ -30,9 +34,9 @@ func NewRecordRepository(pool *pgxpool.Pool, log *slog.Logger) *RecordRepository
 func (r *RecordRepository) List(ctx context.Context, userID int) ([]record.Record, error) {
 	const query = `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       checksum, device_id, deleted_at
+		       checksum, device_id, deleted_at, org_id, uuid::text
 		FROM records 
-		WHERE user_id = $1 AND deleted_at IS NULL 
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NULL 
 		ORDER BY last_modified DESC`
 
 	rows, err := r.pool.Query(ctx, query, userID)
@@ -48,9 +52,9 @@ func (r *RecordRepository) List(ctx context.Context, userID int) ([]record.Recor
 func (r *RecordRepository) Get(ctx context.Context, userID, recordID int) (*record.Record, error) {
 	const query = `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       checksum, device_id, deleted_at
+		       checksum, device_id, deleted_at, org_id, uuid::text
 		FROM records 
-		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+		WHERE id = $1 AND user_id = $2 AND org_id IS NULL AND deleted_at IS NULL`
 
 	row := r.pool.QueryRow(ctx, query, recordID, userID)
 
@@ -71,12 +75,40 @@ func (r *RecordRepository) Get(ctx context.Context, userID, recordID int) (*reco
 	return rec, nil
 }
 
+func (r *RecordRepository) GetHead(ctx context.Context, recordID int) (*record.Head, error) {
+	const query = `
+		SELECT id, user_id, org_id, version, COALESCE(checksum, ''), last_modified, deleted_at,
+		       COALESCE(LENGTH(encrypted_data), 0)
+		FROM records
+		WHERE id = $1`
+
+	var head record.Head
+	var deletedAt sql.NullTime
+	err := r.pool.QueryRow(ctx, query, recordID).Scan(
+		&head.ID, &head.UserID, &head.OrgID, &head.Version,
+		&head.Checksum, &head.LastModified, &deletedAt, &head.Size,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record head", "record_id", recordID, "error", err)
+		return nil, fmt.Errorf("get record head: %w", err)
+	}
+
+	if deletedAt.Valid {
+		head.DeletedAt = &deletedAt.Time
+	}
+
+	return &head, nil
+}
+
 func (r *RecordRepository) GetByChecksum(ctx context.Context, userID int, checksum string) (*record.Record, error) {
 	const query = `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       checksum, device_id, deleted_at
+		       checksum, device_id, deleted_at, org_id, uuid::text
 		FROM records 
-		WHERE checksum = $1 AND user_id = $2 AND deleted_at IS NULL`
+		WHERE checksum = $1 AND user_id = $2 AND org_id IS NULL AND deleted_at IS NULL`
 
 	row := r.pool.QueryRow(ctx, query, checksum, userID)
 
@@ -93,21 +125,49 @@ func (r *RecordRepository) GetByChecksum(ctx context.Context, userID int, checks
 	return rec, nil
 }
 
+func (r *RecordRepository) GetByUUID(ctx context.Context, userID int, recordUUID string) (*record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records 
+		WHERE uuid = $1 AND user_id = $2`
+
+	rec, err := r.scanRecord(r.pool.QueryRow(ctx, query, recordUUID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record by uuid",
+			"uuid", recordUUID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get record by uuid: %w", err)
+	}
+
+	return rec, nil
+}
+
 func (r *RecordRepository) Create(ctx context.Context, rec *record.Record) (int, error) {
 	const query = `
-		INSERT INTO records (user_id, type, encrypted_data, meta, checksum, device_id)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO records (uuid, user_id, type, encrypted_data, meta, checksum, device_id, org_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, version, last_modified`
 
 	data, err := hex.DecodeString(rec.EncryptedData)
 	if err != nil {
 		return 0, fmt.Errorf("%w: %v", record.ErrInvalidData, err)
 	}
+	if rec.UUID == "" {
+		rec.UUID = uuid.NewString()
+	}
 
 	err = r.pool.QueryRow(ctx, query,
-		rec.UserID, rec.Type, data, rec.Meta, rec.Checksum, rec.DeviceID,
+		rec.UUID, rec.UserID, rec.Type, data, rec.Meta, rec.Checksum, rec.DeviceID, rec.OrgID,
 	).Scan(&rec.ID, &rec.Version, &rec.LastModified)
 
+	// 23505 - unique_violation: запись с этим UUID создал параллельный запрос
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_records_user_uuid" {
+		return 0, record.ErrDuplicateUUID
+	}
 	if err != nil {
 		r.log.Error("failed to create record",
 			"user_id", rec.UserID, "type", rec.Type, "error", err)
@@ -123,7 +183,8 @@ func (r *RecordRepository) Update(ctx context.Context, rec *record.Record) error
 		SET type = $1, encrypted_data = $2, meta = $3, 
 			version = version + 1, last_modified = NOW(),
 			checksum = $4, device_id = $5
-		WHERE id = $6 AND user_id = $7 AND version = $8 AND deleted_at IS NULL
+		WHERE id = $6 AND version = $8 AND deleted_at IS NULL
+		  AND ((org_id IS NULL AND user_id = $7) OR org_id = $9)
 		RETURNING version, last_modified`
 
 	data, err := hex.DecodeString(rec.EncryptedData)
@@ -136,7 +197,7 @@ func (r *RecordRepository) Update(ctx context.Context, rec *record.Record) error
 
 	err = r.pool.QueryRow(ctx, query,
 		rec.Type, data, rec.Meta, rec.Checksum, rec.DeviceID,
-		rec.ID, rec.UserID, rec.Version,
+		rec.ID, rec.UserID, rec.Version, rec.OrgID,
 	).Scan(&newVersion, &newLastModified)
 
 	if err != nil {
@@ -154,7 +215,7 @@ func (r *RecordRepository) Update(ctx context.Context, rec *record.Record) error
 }
 
 func (r *RecordRepository) Delete(ctx context.Context, userID, recordID int) error {
-	const query = `DELETE FROM records WHERE id = $1 AND user_id = $2`
+	const query = `DELETE FROM records WHERE id = $1 AND user_id = $2 AND org_id IS NULL`
 
 	result, err := r.pool.Exec(ctx, query, recordID, userID)
 	if err != nil {
@@ -173,8 +234,8 @@ func (r *RecordRepository) Delete(ctx context.Context, userID, recordID int) err
 func (r *RecordRepository) SoftDelete(ctx context.Context, userID, recordID int) error {
 	const query = `
 		UPDATE records 
-		SET deleted_at = NOW(), version = version + 1
-		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+		SET deleted_at = NOW(), last_modified = NOW(), version = version + 1
+		WHERE id = $1 AND user_id = $2 AND org_id IS NULL AND deleted_at IS NULL`
 
 	result, err := r.pool.Exec(ctx, query, recordID, userID)
 	if err != nil {
@@ -193,9 +254,9 @@ func (r *RecordRepository) SoftDelete(ctx context.Context, userID, recordID int)
 func (r *RecordRepository) Search(ctx context.Context, userID int, criteria record.SearchCriteria) ([]record.Record, error) {
 	query := `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       checksum, device_id, deleted_at
+		       checksum, device_id, deleted_at, org_id, uuid::text
 		FROM records 
-		WHERE user_id = $1 AND deleted_at IS NULL`
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NULL`
 
 	args := []interface{}{userID}
 	argIndex := 2
@@ -206,6 +267,51 @@ func (r *RecordRepository) Search(ctx context.Context, userID int, criteria reco
 		argIndex++
 	}
 
+	if criteria.Query != "" {
+		query += fmt.Sprintf(" AND (meta->>'title' ILIKE $%d OR meta->>'resource' ILIKE $%d)", argIndex, argIndex)
+		args = append(args, likePattern(criteria.Query))
+		argIndex++
+	}
+
+	if criteria.Title != "" {
+		query += fmt.Sprintf(" AND meta->>'title' ILIKE $%d", argIndex)
+		args = append(args, likePattern(criteria.Title))
+		argIndex++
+	}
+
+	if criteria.Resource != "" {
+		query += fmt.Sprintf(" AND meta->>'resource' ILIKE $%d", argIndex)
+		args = append(args, likePattern(criteria.Resource))
+		argIndex++
+	}
+
+	// Теги и категория проверяются оператором @>, который использует GIN-индекс по meta
+	contains, err := criteria.MetaContains()
+	if err != nil {
+		return nil, fmt.Errorf("search records: %w", err)
+	}
+	for _, doc := range []json.RawMessage{contains, criteria.MetaQuery} {
+		if len(doc) == 0 {
+			continue
+		}
+		query += fmt.Sprintf(" AND meta @> $%d::jsonb", argIndex)
+		args = append(args, string(doc))
+		argIndex++
+	}
+
+	// Папка задана в meta.folder_id; подходят и записи вложенных папок
+	if criteria.Folder > 0 {
+		query += fmt.Sprintf(` AND meta->>'folder_id' IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = $%d AND user_id = $1
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT id::text FROM subtree)`, argIndex)
+		args = append(args, criteria.Folder)
+		argIndex++
+	}
+
 	if criteria.FromDate != nil {
 		query += fmt.Sprintf(" AND last_modified >= $%d", argIndex)
 		args = append(args, criteria.FromDate)
@@ -241,6 +347,12 @@ func (r *RecordRepository) Search(ctx context.Context, userID int, criteria reco
 	return r.scanRecords(rows)
 }
 
+// likePattern экранирует спецсимволы LIKE и оборачивает строку для поиска подстроки
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
 func (r *RecordRepository) GetByType(ctx context.Context, userID int, recordType string) ([]record.Record, error) {
 	return r.Search(ctx, userID, record.SearchCriteria{Type: recordType})
 }
@@ -248,9 +360,9 @@ func (r *RecordRepository) GetByType(ctx context.Context, userID int, recordType
 func (r *RecordRepository) GetModifiedSince(ctx context.Context, userID int, since time.Time) ([]record.Record, error) {
 	const query = `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       checksum, device_id, deleted_at
+		       checksum, device_id, deleted_at, org_id, uuid::text
 		FROM records 
-		WHERE user_id = $1 AND last_modified > $2 AND deleted_at IS NULL
+		WHERE user_id = $1 AND org_id IS NULL AND last_modified > $2 AND deleted_at IS NULL
 		ORDER BY last_modified DESC`
 
 	rows, err := r.pool.Query(ctx, query, userID, since)
@@ -271,7 +383,7 @@ func (r *RecordRepository) GetStats(ctx context.Context, userID int) (map[string
 			COUNT(*) as count,
 			COALESCE(SUM(LENGTH(encrypted_data)), 0) as total_size
 		FROM records 
-		WHERE user_id = $1 AND deleted_at IS NULL
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NULL
 		GROUP BY type`
 
 	rows, err := r.pool.Query(ctx, query, userID)
@@ -314,7 +426,8 @@ func (r *RecordRepository) GetStats(ctx context.Context, userID int) (map[string
 func (r *RecordRepository) SaveVersion(ctx context.Context, version *record.Version) error {
 	const query = `
 		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum)
-		VALUES ($1, $2, $3, $4, $5)`
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (record_id, version) DO NOTHING`
 
 	data, err := hex.DecodeString(version.EncryptedData)
 	if err != nil {
@@ -357,6 +470,304 @@ func (r *RecordRepository) GetVersions(ctx context.Context, recordID int) ([]rec
 	return versions, nil
 }
 
+// ListAttachments возвращает вложения записи без данных, в порядке загрузки
+func (r *RecordRepository) ListAttachments(ctx context.Context, recordID int) ([]record.Attachment, error) {
+	const query = `
+		SELECT id, record_id, user_id, encrypted_meta, size, created_at
+		FROM record_attachments
+		WHERE record_id = $1
+		ORDER BY id`
+
+	rows, err := r.pool.Query(ctx, query, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []record.Attachment
+	for rows.Next() {
+		var a record.Attachment
+		if err := rows.Scan(&a.ID, &a.RecordID, &a.UserID, &a.EncryptedMeta, &a.Size, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// GetAttachment возвращает вложение записи с данными
+func (r *RecordRepository) GetAttachment(ctx context.Context, recordID, attachmentID int) (*record.Attachment, error) {
+	const query = `
+		SELECT id, record_id, user_id, encrypted_meta, size, created_at, data
+		FROM record_attachments
+		WHERE id = $1 AND record_id = $2`
+
+	var a record.Attachment
+	err := r.pool.QueryRow(ctx, query, attachmentID, recordID).
+		Scan(&a.ID, &a.RecordID, &a.UserID, &a.EncryptedMeta, &a.Size, &a.CreatedAt, &a.Data)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, record.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+
+	return &a, nil
+}
+
+// CreateAttachment сохраняет вложение и возвращает его ID
+func (r *RecordRepository) CreateAttachment(ctx context.Context, a *record.Attachment) (int, error) {
+	const query = `
+		INSERT INTO record_attachments (record_id, user_id, encrypted_meta, data, size)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	var id int
+	err := r.pool.QueryRow(ctx, query, a.RecordID, a.UserID, a.EncryptedMeta, a.Data, a.Size).
+		Scan(&id, &a.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("create attachment: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteAttachment удаляет вложение записи
+func (r *RecordRepository) DeleteAttachment(ctx context.Context, recordID, attachmentID int) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM record_attachments WHERE id = $1 AND record_id = $2`,
+		attachmentID, recordID)
+	if err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return record.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// ListByOrg возвращает записи хранилища организации
+func (r *RecordRepository) ListByOrg(ctx context.Context, orgID int) ([]record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records 
+		WHERE org_id = $1 AND deleted_at IS NULL 
+		ORDER BY last_modified DESC`
+
+	rows, err := r.pool.Query(ctx, query, orgID)
+	if err != nil {
+		r.log.Error("failed to list org records", "org_id", orgID, "error", err)
+		return nil, fmt.Errorf("list org records: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRecords(rows)
+}
+
+// GetShared возвращает запись хранилища организации без проверки прав,
+// права проверяет вызывающий сервис
+func (r *RecordRepository) GetShared(ctx context.Context, recordID int) (*record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records 
+		WHERE id = $1 AND org_id IS NOT NULL AND deleted_at IS NULL`
+
+	rec, err := r.scanRecord(r.pool.QueryRow(ctx, query, recordID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get shared record", "record_id", recordID, "error", err)
+		return nil, fmt.Errorf("get shared record: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *RecordRepository) DeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	const query = `DELETE FROM records WHERE id = $1 AND org_id = $2`
+
+	result, err := r.pool.Exec(ctx, query, recordID, orgID)
+	if err != nil {
+		r.log.Error("failed to delete org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return fmt.Errorf("delete org record: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return record.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RecordRepository) SoftDeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	const query = `
+		UPDATE records 
+		SET deleted_at = NOW(), last_modified = NOW(), version = version + 1
+		WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, recordID, orgID)
+	if err != nil {
+		r.log.Error("failed to soft delete org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return fmt.Errorf("soft delete org record: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return record.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDeleted возвращает личные записи пользователя в корзине
+func (r *RecordRepository) ListDeleted(ctx context.Context, userID int) ([]record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records 
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NOT NULL 
+		ORDER BY deleted_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list deleted records", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list deleted records: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRecords(rows)
+}
+
+func (r *RecordRepository) Restore(ctx context.Context, userID, recordID int) (int, error) {
+	const query = `
+		UPDATE records 
+		SET deleted_at = NULL, version = version + 1, last_modified = NOW()
+		WHERE id = $1 AND user_id = $2 AND org_id IS NULL AND deleted_at IS NOT NULL
+		RETURNING version`
+
+	var version int
+	if err := r.pool.QueryRow(ctx, query, recordID, userID).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, record.ErrNotFound
+		}
+		r.log.Error("failed to restore record",
+			"record_id", recordID, "user_id", userID, "error", err)
+		return 0, fmt.Errorf("restore record: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *RecordRepository) RestoreInOrg(ctx context.Context, orgID, recordID int) (int, error) {
+	const query = `
+		UPDATE records 
+		SET deleted_at = NULL, version = version + 1, last_modified = NOW()
+		WHERE id = $1 AND org_id = $2 AND deleted_at IS NOT NULL
+		RETURNING version`
+
+	var version int
+	if err := r.pool.QueryRow(ctx, query, recordID, orgID).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, record.ErrNotFound
+		}
+		r.log.Error("failed to restore org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return 0, fmt.Errorf("restore org record: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *RecordRepository) PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error) {
+	const query = `
+		DELETE FROM records 
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NOT NULL AND deleted_at < $2`
+
+	result, err := r.pool.Exec(ctx, query, userID, before)
+	if err != nil {
+		r.log.Error("failed to purge deleted records", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("purge deleted records: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *RecordRepository) PurgeAllDeleted(ctx context.Context, before time.Time) (int, error) {
+	const query = `DELETE FROM records WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		r.log.Error("failed to purge deleted records", "error", err)
+		return 0, fmt.Errorf("purge deleted records: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// PurgeAllAcknowledged удаляет записи из корзины, удаление которых получили
+// все активные устройства владельца
+func (r *RecordRepository) PurgeAllAcknowledged(ctx context.Context, before, activeSince time.Time) (int, error) {
+	const query = `
+		DELETE FROM records r
+		WHERE r.deleted_at IS NOT NULL AND r.deleted_at < $1
+			AND (r.org_id IS NOT NULL OR NOT EXISTS (
+				SELECT 1 FROM devices d
+				WHERE d.user_id = r.user_id
+					AND COALESCE(d.last_sync_time, d.created_at) >= $2
+					AND (d.synced_through IS NULL
+						OR (r.last_modified, r.id) > (d.synced_through, d.synced_through_id))
+			))`
+
+	result, err := r.pool.Exec(ctx, query, before, activeSince)
+	if err != nil {
+		r.log.Error("failed to purge acknowledged records", "error", err)
+		return 0, fmt.Errorf("purge acknowledged records: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+func (r *RecordRepository) ListWithExpiry(ctx context.Context, userID int) ([]record.Record, error) {
+	// Данные записи не нужны: срок действия хранится в метаданных
+	const query = `
+		SELECT id, user_id, type, ''::bytea, meta, version, last_modified,
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records
+		WHERE user_id = $1 AND org_id IS NULL AND deleted_at IS NULL AND meta ? 'expires_at'`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list records with expiry", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list records with expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRecords(rows)
+}
+
+func (r *RecordRepository) ListAllWithExpiry(ctx context.Context) ([]record.Record, error) {
+	const query = `
+		SELECT id, user_id, type, ''::bytea, meta, version, last_modified,
+		       checksum, device_id, deleted_at, org_id, uuid::text
+		FROM records
+		WHERE org_id IS NULL AND deleted_at IS NULL AND meta ? 'expires_at'`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		r.log.Error("failed to list records with expiry", "error", err)
+		return nil, fmt.Errorf("list records with expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanRecords(rows)
+}
+
 // Вспомогательные методы
 func (r *RecordRepository) scanRecords(rows pgx.Rows) ([]record.Record, error) {
 	var records []record.Record
@@ -382,7 +793,8 @@ func (r *RecordRepository) scanRecord(row interface {
 	err := row.Scan(
 		&rec.ID, &rec.UserID, &rec.Type, &data,
 		&rec.Meta, &rec.Version, &rec.LastModified,
-		&rec.Checksum, &rec.DeviceID, &deletedAt,
+		&rec.Checksum, &rec.DeviceID, &deletedAt, &rec.OrgID,
+		&rec.UUID,
 	)
 
 	if err != nil {
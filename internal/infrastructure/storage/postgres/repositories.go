@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"gophkeeper/internal/infrastructure/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// NewRepositories создает репозитории сервера поверх пула PostgreSQL.
+// Close репозиториев закрывает пул.
+func NewRepositories(pool *pgxpool.Pool, log *slog.Logger) *storage.Repositories {
+	return &storage.Repositories{
+		Users:       NewUserRepository(pool, log),
+		Sessions:    NewSessionRepository(pool, log),
+		Records:     NewRecordRepository(pool, log),
+		Sync:        NewSyncRepository(pool, log),
+		Settings:    NewSettingsRepository(pool, log),
+		Orgs:        NewOrgRepository(pool, log),
+		Memberships: NewMembershipRepository(pool, log),
+		Quotas:      NewQuotaRepository(pool, log),
+		MFA:         NewMFARepository(pool, log),
+		Backups:     NewBackupRepository(pool, log),
+		Blobs:       NewBlobRepository(pool, log),
+		Folders:     NewFolderRepository(pool, log),
+		SyncRollout: NewSyncRolloutRepository(pool, log),
+		KeyFiles:    NewKeyFileRepository(pool, log),
+		Viewers:     NewViewerRepository(pool, log),
+		SecretLinks: NewSecretLinkRepository(pool, log),
+		Stats:       NewStatsRepository(pool, log),
+		Close:       pool.Close,
+	}
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/secretlink"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// SecretLinkRepository реализует secretlink.Repository для PostgreSQL
+type SecretLinkRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+func NewSecretLinkRepository(pool *pgxpool.Pool, log *slog.Logger) *SecretLinkRepository {
+	return &SecretLinkRepository{
+		pool: pool,
+		log:  log.With("component", "secret_link_repository"),
+	}
+}
+
+const secretLinkColumns = `id, user_id, expires_at, max_views, views, created_at`
+
+func (r *SecretLinkRepository) Create(ctx context.Context, link *secretlink.Link, ciphertext []byte) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO secret_links (id, user_id, ciphertext, max_views, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		link.ID, link.UserID, ciphertext, link.MaxViews, link.ExpiresAt, link.CreatedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to create secret link", "user_id", link.UserID, "error", err)
+		return fmt.Errorf("insert secret link: %w", err)
+	}
+	return nil
+}
+
+func (r *SecretLinkRepository) List(ctx context.Context, userID int) ([]secretlink.Link, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+secretLinkColumns+`
+		FROM secret_links
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id`, userID)
+	if err != nil {
+		r.log.Error("failed to list secret links", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list secret links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []secretlink.Link
+	for rows.Next() {
+		link, err := scanSecretLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan secret link: %w", err)
+		}
+		links = append(links, *link)
+	}
+	return links, rows.Err()
+}
+
+func (r *SecretLinkRepository) Delete(ctx context.Context, userID int, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM secret_links WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		r.log.Error("failed to delete secret link", "link_id", id, "error", err)
+		return fmt.Errorf("delete secret link: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return secretlink.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SecretLinkRepository) Consume(ctx context.Context, id string, now time.Time) ([]byte, error) {
+	// Условие на views в UPDATE перепроверяется после блокировки строки,
+	// поэтому параллельные запросы не получат больше просмотров, чем разрешено
+	var (
+		ciphertext      []byte
+		views, maxViews int
+	)
+	err := r.pool.QueryRow(ctx, `
+		UPDATE secret_links
+		SET views = views + 1
+		WHERE id = $1 AND expires_at > $2 AND views < max_views
+		RETURNING ciphertext, views, max_views`, id, now,
+	).Scan(&ciphertext, &views, &maxViews)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, secretlink.ErrNotFound
+		}
+		r.log.Error("failed to consume secret link", "link_id", id, "error", err)
+		return nil, fmt.Errorf("consume secret link: %w", err)
+	}
+
+	if views >= maxViews {
+		if _, err := r.pool.Exec(ctx, `DELETE FROM secret_links WHERE id = $1`, id); err != nil {
+			// Просмотры исчерпаны: ссылка больше не откроется и без удаления
+			r.log.Warn("failed to burn secret link", "link_id", id, "error", err)
+		}
+	}
+	return ciphertext, nil
+}
+
+func (r *SecretLinkRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.pool.Exec(ctx, `DELETE FROM secret_links WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired secret links: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanSecretLink(row pgx.Row) (*secretlink.Link, error) {
+	var link secretlink.Link
+	if err := row.Scan(&link.ID, &link.UserID, &link.ExpiresAt,
+		&link.MaxViews, &link.Views, &link.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
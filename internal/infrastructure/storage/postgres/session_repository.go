@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/exp/slog"
 )
@@ -29,15 +34,109 @@ func (r *SessionRepository) Create(ctx context.Context, userID int, tokenHash st
 	return err
 }
 
+func (r *SessionRepository) CreateMFA(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO sessions (user_id, token_hash, expires_at, mfa) 
+         VALUES ($1, decode($2, 'hex'), $3, TRUE)`,
+		userID, tokenHash, expiresAt)
+	return err
+}
+
+// Validate принимает сессию без второго фактора, только если он не включен у пользователя.
+// Сессии заблокированных пользователей недействительны.
 func (r *SessionRepository) Validate(ctx context.Context, tokenHash string) (int, error) {
 	var userID int
 	err := r.pool.QueryRow(ctx,
-		`SELECT user_id FROM sessions 
-         WHERE token_hash = decode($1, 'hex') AND expires_at > NOW()`,
+		`SELECT s.user_id FROM sessions s
+         JOIN users u ON u.id = s.user_id AND u.disabled_at IS NULL
+         LEFT JOIN user_totp t ON t.user_id = s.user_id AND t.enabled
+         WHERE s.token_hash = decode($1, 'hex') AND s.expires_at > NOW()
+           AND (t.user_id IS NULL OR s.mfa)`,
 		tokenHash).Scan(&userID)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, session.ErrInvalidSession
+	}
 	if err != nil {
-		return 0, fmt.Errorf("invalid session")
+		return 0, fmt.Errorf("validate session: %w", err)
 	}
 	return userID, nil
 }
+
+func (r *SessionRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Touch обновляет время использования не чаще раза в session.TouchInterval
+func (r *SessionRepository) Touch(ctx context.Context, tokenHash string, client session.Client, at time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE sessions
+         SET last_used_at = $2,
+             device_id = COALESCE(NULLIF($3, ''), device_id),
+             ip_address = COALESCE(NULLIF($4, ''), ip_address),
+             user_agent = COALESCE(NULLIF($5, ''), user_agent)
+         WHERE token_hash = decode($1, 'hex') AND (last_used_at IS NULL OR last_used_at < $6)`,
+		tokenHash, at, client.DeviceID, client.IPAddress, client.UserAgent, at.Add(-session.TouchInterval))
+	return err
+}
+
+func (r *SessionRepository) ListByUser(ctx context.Context, userID int, currentHash string) ([]session.Session, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT s.id, COALESCE(d.name, ''), COALESCE(d.public_key, ''), COALESCE(s.ip_address, ''),
+                COALESCE(s.user_agent, ''), s.mfa, s.created_at, s.last_used_at, s.expires_at,
+                s.token_hash = decode($2, 'hex')
+         FROM sessions s
+         LEFT JOIN devices d ON d.user_id = s.user_id AND d.device_uuid = s.device_id
+         WHERE s.user_id = $1 AND s.expires_at > NOW()
+         ORDER BY COALESCE(s.last_used_at, s.created_at) DESC, s.id DESC`,
+		userID, currentHash)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]session.Session, 0)
+	for rows.Next() {
+		var s session.Session
+		var publicKey string
+		if err := rows.Scan(&s.ID, &s.DeviceName, &publicKey, &s.IPAddress, &s.UserAgent, &s.MFA,
+			&s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.Current); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		s.DeviceFingerprint = sync.DeviceFingerprint(publicKey)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, userID, sessionID int) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE id = $1 AND user_id = $2`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) DeleteOthers(ctx context.Context, userID int, keepHash string) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM sessions WHERE user_id = $1 AND token_hash <> decode($2, 'hex')`, userID, keepHash)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// SettingsRepository реализует settings.Repository для PostgreSQL
+type SettingsRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+// NewSettingsRepository создает новый репозиторий настроек
+func NewSettingsRepository(pool *pgxpool.Pool, log *slog.Logger) *SettingsRepository {
+	return &SettingsRepository{
+		pool: pool,
+		log:  log,
+	}
+}
+
+// Get возвращает все заданные настройки пользователя
+func (r *SettingsRepository) Get(ctx context.Context, userID int) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT key, value FROM user_settings WHERE user_id = $1`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		values[key] = value
+	}
+
+	return values, rows.Err()
+}
+
+// Set сохраняет значения и удаляет ключи из списка remove в одной транзакции
+func (r *SettingsRepository) Set(ctx context.Context, userID int, values map[string]string, remove []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	for key, value := range values {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO user_settings (user_id, key, value, updated_at)
+			VALUES ($1, $2, $3, NOW())
+			ON CONFLICT (user_id, key) DO UPDATE SET
+				value = EXCLUDED.value,
+				updated_at = EXCLUDED.updated_at`,
+			userID, key, value)
+		if err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+
+	if len(remove) > 0 {
+		_, err := tx.Exec(ctx,
+			`DELETE FROM user_settings WHERE user_id = $1 AND key = ANY($2)`,
+			userID, remove)
+		if err != nil {
+			return fmt.Errorf("failed to reset settings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
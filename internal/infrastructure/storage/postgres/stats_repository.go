@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/stats"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// StatsRepository реализует stats.Repository для PostgreSQL
+type StatsRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+// NewStatsRepository создает новый репозиторий статистики
+func NewStatsRepository(pool *pgxpool.Pool, log *slog.Logger) *StatsRepository {
+	return &StatsRepository{
+		pool: pool,
+		log:  log,
+	}
+}
+
+// Daily возвращает изменения записей пользователя по дням начиная с since.
+// Таблицу record_daily_stats ведет триггер на records.
+func (r *StatsRepository) Daily(ctx context.Context, userID int, since time.Time) ([]stats.Day, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT TO_CHAR(day, 'YYYY-MM-DD'), created, updated, deleted, size_delta
+		FROM record_daily_stats
+		WHERE user_id = $1 AND day >= $2::DATE
+		ORDER BY day`,
+		userID, since.UTC().Format("2006-01-02"))
+	if err != nil {
+		r.log.Error("failed to get daily stats", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	var days []stats.Day
+	for rows.Next() {
+		var d stats.Day
+		if err := rows.Scan(&d.Day, &d.Created, &d.Updated, &d.Deleted, &d.SizeDelta); err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		days = append(days, d)
+	}
+
+	return days, rows.Err()
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -42,6 +44,7 @@ func (r *SyncRepository) GetSyncStatus(ctx context.Context, userID int) (*sync.S
 
 	var status sync.Status
 	var lastSyncTime sql.NullTime
+	var storageLimit sql.NullInt64
 
 	err := r.pool.QueryRow(ctx, query, userID).Scan(
 		&status.UserID,
@@ -49,7 +52,7 @@ func (r *SyncRepository) GetSyncStatus(ctx context.Context, userID int) (*sync.S
 		&status.TotalRecords,
 		&status.DeviceCount,
 		&status.StorageUsed,
-		&status.StorageLimit,
+		&storageLimit,
 		&status.SyncVersion,
 	)
 
@@ -62,7 +65,6 @@ func (r *SyncRepository) GetSyncStatus(ctx context.Context, userID int) (*sync.S
 				TotalRecords: 0,
 				DeviceCount:  0,
 				StorageUsed:  0,
-				StorageLimit: 104857600, // 100 MB
 				SyncVersion:  0,
 			}, nil
 		}
@@ -72,6 +74,10 @@ func (r *SyncRepository) GetSyncStatus(ctx context.Context, userID int) (*sync.S
 	if lastSyncTime.Valid {
 		status.LastSyncTime = lastSyncTime.Time
 	}
+	// Без заданного лимита действует лимит из конфигурации сервиса
+	if storageLimit.Valid {
+		status.StorageLimit = storageLimit.Int64
+	}
 
 	return &status, nil
 }
@@ -83,71 +89,113 @@ func (r *SyncRepository) UpdateSyncStatus(_ context.Context, status *sync.Status
 	return nil
 }
 
+const deviceColumns = `
+	id, user_id, COALESCE(device_uuid::text, ''), name, type, last_sync_time, created_at, updated_at,
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+	bytes_uploaded, bytes_downloaded, request_count, records_uploaded, records_downloaded,
+	public_key, status, approved_at, approved_by`
+
 // GetDeviceInfo возвращает информацию об устройстве
 func (r *SyncRepository) GetDeviceInfo(ctx context.Context, deviceID int) (*sync.DeviceInfo, error) {
-	query := `
-		SELECT id, user_id, name, type, last_sync_time, created_at, updated_at, ip_address, user_agent
-		FROM devices
-		WHERE id = $1
-	`
-
-	var device sync.DeviceInfo
-	var lastSyncTime sql.NullTime
-
-	err := r.pool.QueryRow(ctx, query, deviceID).Scan(
-		&device.ID,
-		&device.UserID,
-		&device.Name,
-		&device.Type,
-		&lastSyncTime,
-		&device.CreatedAt,
-		&device.UpdatedAt,
-		&device.IPAddress,
-		&device.UserAgent,
-	)
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE id = $1`
 
+	device, err := scanDevice(r.pool.QueryRow(ctx, query, deviceID))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, sync.ErrDeviceNotFound
 		}
 		return nil, fmt.Errorf("failed to get device info: %w", err)
 	}
 
-	if lastSyncTime.Valid {
-		device.LastSyncTime = lastSyncTime.Time
+	return device, nil
+}
+
+// GetDeviceByUUID возвращает устройство пользователя по UUID
+func (r *SyncRepository) GetDeviceByUUID(ctx context.Context, userID int, deviceUUID string) (*sync.DeviceInfo, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE user_id = $1 AND device_uuid = $2`
+
+	device, err := scanDevice(r.pool.QueryRow(ctx, query, userID, deviceUUID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sync.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device by uuid: %w", err)
 	}
 
-	return &device, nil
+	return device, nil
 }
 
-// RegisterDevice регистрирует новое устройство
+// RegisterDevice регистрирует новое устройство (ID == 0) или обновляет существующее
 func (r *SyncRepository) RegisterDevice(ctx context.Context, device *sync.DeviceInfo) error {
+	deviceUUID := sql.NullString{String: device.UUID, Valid: device.UUID != ""}
+	if device.Status == "" {
+		device.Status = sync.DeviceApproved
+	}
+
+	if device.ID == 0 {
+		query := `
+			INSERT INTO devices (user_id, device_uuid, name, type, last_sync_time, ip_address, user_agent,
+			                     public_key, status, approved_at, approved_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id, created_at, updated_at
+		`
+
+		var lastSyncTime *time.Time
+		if !device.LastSyncTime.IsZero() {
+			lastSyncTime = &device.LastSyncTime
+		}
+
+		err := r.pool.QueryRow(ctx, query,
+			device.UserID,
+			deviceUUID,
+			device.Name,
+			device.Type,
+			lastSyncTime,
+			device.IPAddress,
+			device.UserAgent,
+			device.PublicKey,
+			device.Status,
+			device.ApprovedAt,
+			device.ApprovedBy,
+		).Scan(&device.ID, &device.CreatedAt, &device.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to register device: %w", err)
+		}
+		return nil
+	}
+
 	query := `
-		INSERT INTO devices (id, user_id, name, type, last_sync_time, created_at, updated_at, ip_address, user_agent)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (id) DO UPDATE SET
-			name = EXCLUDED.name,
-			type = EXCLUDED.type,
-			last_sync_time = EXCLUDED.last_sync_time,
-			updated_at = EXCLUDED.updated_at,
-			ip_address = EXCLUDED.ip_address,
-			user_agent = EXCLUDED.user_agent
+		UPDATE devices
+		SET device_uuid = $1, name = $2, type = $3, public_key = $4, status = $5, approved_at = $6, approved_by = $7
+		WHERE id = $8 AND user_id = $9
+		RETURNING updated_at
 	`
 
-	_, err := r.pool.Exec(ctx, query,
-		device.ID,
-		device.UserID,
-		device.Name,
-		device.Type,
-		device.LastSyncTime,
-		device.CreatedAt,
-		device.UpdatedAt,
-		device.IPAddress,
-		device.UserAgent,
-	)
+	err := r.pool.QueryRow(ctx, query, deviceUUID, device.Name, device.Type,
+		device.PublicKey, device.Status, device.ApprovedAt, device.ApprovedBy, device.ID, device.UserID).
+		Scan(&device.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sync.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to update device: %w", err)
+	}
 
+	return nil
+}
+
+// ApproveDevice подтверждает устройство пользователя
+func (r *SyncRepository) ApproveDevice(ctx context.Context, userID, deviceID, approvedBy int, at time.Time) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE devices
+		SET status = $1, approved_at = $2, approved_by = $3
+		WHERE id = $4 AND user_id = $5
+	`, sync.DeviceApproved, at, approvedBy, deviceID, userID)
 	if err != nil {
-		return fmt.Errorf("failed to register device: %w", err)
+		return fmt.Errorf("failed to approve device: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return sync.ErrDeviceNotFound
 	}
 
 	return nil
@@ -169,15 +217,56 @@ func (r *SyncRepository) UpdateDeviceSyncTime(ctx context.Context, deviceID int,
 	return nil
 }
 
-// ListUserDevices возвращает список устройств пользователя
-func (r *SyncRepository) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+// AddDeviceUsage прибавляет трафик и операции к счетчикам устройства по его UUID
+func (r *SyncRepository) AddDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage sync.DeviceUsage, at time.Time) error {
 	query := `
-		SELECT id, user_id, name, type, last_sync_time, created_at, updated_at
-		FROM devices
-		WHERE user_id = $1
-		ORDER BY last_sync_time DESC
+		UPDATE devices
+		SET bytes_uploaded = bytes_uploaded + $1,
+		    bytes_downloaded = bytes_downloaded + $2,
+		    request_count = request_count + $3,
+		    records_uploaded = records_uploaded + $4,
+		    records_downloaded = records_downloaded + $5,
+		    last_sync_time = GREATEST(last_sync_time, $6)
+		WHERE user_id = $7 AND device_uuid = $8
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		usage.BytesUploaded,
+		usage.BytesDownloaded,
+		usage.Requests,
+		usage.RecordsUploaded,
+		usage.RecordsDownloaded,
+		at,
+		userID,
+		deviceUUID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add device usage: %w", err)
+	}
+
+	return nil
+}
+
+// AckDeviceSync сдвигает позицию, до которой устройство получило изменения
+func (r *SyncRepository) AckDeviceSync(ctx context.Context, userID int, deviceUUID string, through sync.Cursor) error {
+	query := `
+		UPDATE devices
+		SET synced_through = $3, synced_through_id = $4
+		WHERE user_id = $1 AND device_uuid = $2
+			AND (synced_through IS NULL OR (synced_through, synced_through_id) < ($3, $4))
 	`
 
+	if _, err := r.pool.Exec(ctx, query, userID, deviceUUID, through.Time, through.ID); err != nil {
+		return fmt.Errorf("failed to ack device sync: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserDevices возвращает список устройств пользователя
+func (r *SyncRepository) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE user_id = $1 ORDER BY last_sync_time DESC`
+
 	rows, err := r.pool.Query(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user devices: %w", err)
@@ -186,31 +275,14 @@ func (r *SyncRepository) ListUserDevices(ctx context.Context, userID int) ([]*sy
 
 	var devices []*sync.DeviceInfo
 	for rows.Next() {
-		var device sync.DeviceInfo
-		var lastSyncTime sql.NullTime
-
-		err := rows.Scan(
-			&device.ID,
-			&device.UserID,
-			&device.Name,
-			&device.Type,
-			&lastSyncTime,
-			&device.CreatedAt,
-			&device.UpdatedAt,
-		)
-
+		device, err := scanDevice(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan device: %w", err)
 		}
-
-		if lastSyncTime.Valid {
-			device.LastSyncTime = lastSyncTime.Time
-		}
-
-		devices = append(devices, &device)
+		devices = append(devices, device)
 	}
 
-	return devices, nil
+	return devices, rows.Err()
 }
 
 // DeleteDevice удаляет устройство
@@ -225,19 +297,76 @@ func (r *SyncRepository) DeleteDevice(ctx context.Context, deviceID int) error {
 	return nil
 }
 
-// GetRecordsForSync возвращает записи для синхронизации (используем реальную схему records)
-func (r *SyncRepository) GetRecordsForSync(ctx context.Context, userID int, lastSyncTime time.Time, limit, offset int) ([]*sync.RecordSync, error) {
+// MergeDevices переносит конфликты дубликатов на устройство keepID и удаляет
+// дубликаты. Удаление устройства каскадно удалило бы его конфликты.
+func (r *SyncRepository) MergeDevices(ctx context.Context, keepID int, duplicateIDs []int) error {
+	if len(duplicateIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		_ = tx.Rollback(ctx)
+	}(tx, ctx)
+
+	if _, err := tx.Exec(ctx, `UPDATE sync_conflicts SET device_id = $1 WHERE device_id = ANY($2)`,
+		keepID, duplicateIDs); err != nil {
+		return fmt.Errorf("failed to move conflicts: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE devices d
+		SET last_sync_time = GREATEST(d.last_sync_time, dup.last_sync_time),
+		    bytes_uploaded = d.bytes_uploaded + dup.bytes_uploaded,
+		    bytes_downloaded = d.bytes_downloaded + dup.bytes_downloaded,
+		    request_count = d.request_count + dup.request_count,
+		    records_uploaded = d.records_uploaded + dup.records_uploaded,
+		    records_downloaded = d.records_downloaded + dup.records_downloaded
+		FROM (
+			SELECT MAX(last_sync_time) AS last_sync_time,
+			       COALESCE(SUM(bytes_uploaded), 0) AS bytes_uploaded,
+			       COALESCE(SUM(bytes_downloaded), 0) AS bytes_downloaded,
+			       COALESCE(SUM(request_count), 0) AS request_count,
+			       COALESCE(SUM(records_uploaded), 0) AS records_uploaded,
+			       COALESCE(SUM(records_downloaded), 0) AS records_downloaded
+			FROM devices WHERE id = ANY($2)
+		) dup
+		WHERE d.id = $1
+	`, keepID, duplicateIDs); err != nil {
+		return fmt.Errorf("failed to merge sync time: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM devices WHERE id = ANY($1)`, duplicateIDs); err != nil {
+		return fmt.Errorf("failed to delete duplicate devices: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetRecordsForSync возвращает записи для синхронизации (используем реальную схему records).
+// Записи хранилищ организаций зашифрованы ключом организации и в личную синхронизацию не входят.
+func (r *SyncRepository) GetRecordsForSync(ctx context.Context, userID int, after sync.Cursor, limit int, filter sync.Filter) ([]*sync.RecordSync, error) {
+	conditions, args := filterConditions(filter, 5)
 	query := `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       deleted_at, checksum, device_id
+		       deleted_at, checksum, device_id, uuid::text
 		FROM records
 		WHERE user_id = $1 
-			AND last_modified > $2
-		ORDER BY last_modified ASC
-		LIMIT $3 OFFSET $4
+			AND org_id IS NULL
+			AND (last_modified, id) > ($2, $3)` + conditions + `
+		ORDER BY last_modified ASC, id ASC
+		LIMIT $4
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, lastSyncTime, limit, offset)
+	args = append([]interface{}{userID, after.Time, after.ID, limit}, args...)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query records for sync: %w", err)
 	}
@@ -259,7 +388,7 @@ func (r *SyncRepository) GetRecordsForSync(ctx context.Context, userID int, last
 func (r *SyncRepository) GetRecordByID(ctx context.Context, recordID int) (*sync.RecordSync, error) {
 	query := `
 		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
-		       deleted_at, checksum, device_id
+		       deleted_at, checksum, device_id, uuid::text
 		FROM records
 		WHERE id = $1
 	`
@@ -276,11 +405,87 @@ func (r *SyncRepository) GetRecordByID(ctx context.Context, recordID int) (*sync
 	return rec, nil
 }
 
+// GetRecordByUUID возвращает личную запись пользователя по UUID
+func (r *SyncRepository) GetRecordByUUID(ctx context.Context, userID int, recordUUID string) (*sync.RecordSync, error) {
+	query := `
+		SELECT id, user_id, type, encrypted_data, meta, version, last_modified, 
+		       deleted_at, checksum, device_id, uuid::text
+		FROM records
+		WHERE user_id = $1 AND uuid = $2 AND org_id IS NULL
+	`
+
+	rec, err := r.scanRecordSync(r.pool.QueryRow(ctx, query, userID, recordUUID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sync.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get record by uuid: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetRecordIndex возвращает версии и контрольные суммы личных записей пользователя,
+// включая удаленные
+func (r *SyncRepository) GetRecordIndex(ctx context.Context, userID int, filter sync.Filter) ([]*sync.RecordDigest, error) {
+	conditions, args := filterConditions(filter, 2)
+	query := `
+		SELECT id, uuid::text, version, COALESCE(checksum, ''), deleted_at IS NOT NULL
+		FROM records
+		WHERE user_id = $1 AND org_id IS NULL` + conditions + `
+		ORDER BY id
+	`
+
+	args = append([]interface{}{userID}, args...)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query record index: %w", err)
+	}
+	defer rows.Close()
+
+	var index []*sync.RecordDigest
+	for rows.Next() {
+		var d sync.RecordDigest
+		if err := rows.Scan(&d.ID, &d.UUID, &d.Version, &d.Checksum, &d.Deleted); err != nil {
+			return nil, fmt.Errorf("failed to scan record digest: %w", err)
+		}
+		index = append(index, &d)
+	}
+
+	return index, rows.Err()
+}
+
+// filterConditions возвращает условия выборочной синхронизации для WHERE;
+// параметры нумеруются с argIndex. Оператор ?| проверяет пересечение
+// тегов из meta со списком, записи без тегов под исключение не попадают.
+func filterConditions(filter sync.Filter, argIndex int) (string, []interface{}) {
+	var conditions string
+	var args []interface{}
+
+	if len(filter.Types) > 0 {
+		conditions += fmt.Sprintf(" AND type = ANY($%d)", argIndex)
+		args = append(args, filter.Types)
+		argIndex++
+	}
+	if len(filter.Tags) > 0 {
+		conditions += fmt.Sprintf(" AND COALESCE(meta->'tags' ?| $%d, false)", argIndex)
+		args = append(args, filter.Tags)
+		argIndex++
+	}
+	if len(filter.ExcludeTags) > 0 {
+		conditions += fmt.Sprintf(" AND NOT COALESCE(meta->'tags' ?| $%d, false)", argIndex)
+		args = append(args, filter.ExcludeTags)
+	}
+
+	return conditions, args
+}
+
 // GetRecordVersions возвращает версии записи из record_versions
 func (r *SyncRepository) GetRecordVersions(ctx context.Context, recordID int, limit int) ([]*sync.RecordSync, error) {
 	query := `
 		SELECT rv.id, r.user_id, r.type, rv.encrypted_data, rv.meta, rv.version, 
-		       rv.created_at as last_modified, NULL as deleted_at, rv.checksum, r.device_id
+		       rv.created_at as last_modified, NULL as deleted_at, rv.checksum, r.device_id,
+		       r.uuid::text
 		FROM record_versions rv
 		JOIN records r ON rv.record_id = r.id
 		WHERE rv.record_id = $1
@@ -313,9 +518,13 @@ func (r *SyncRepository) SaveRecord(ctx context.Context, record *sync.RecordSync
 		return fmt.Errorf("failed to decode encrypted data: %w", err)
 	}
 
+	if record.UUID == "" {
+		record.UUID = uuid.NewString()
+	}
+
 	query := `
-		INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id, uuid)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (user_id, type, encrypted_data) 
 		WHERE deleted_at IS NULL
 		DO UPDATE SET
@@ -325,7 +534,7 @@ func (r *SyncRepository) SaveRecord(ctx context.Context, record *sync.RecordSync
 			device_id = EXCLUDED.device_id,
 			last_modified = NOW()
 		WHERE records.version < EXCLUDED.version
-		RETURNING id, version, last_modified
+		RETURNING id, version, last_modified, uuid::text
 	`
 
 	err = r.pool.QueryRow(ctx, query,
@@ -336,7 +545,8 @@ func (r *SyncRepository) SaveRecord(ctx context.Context, record *sync.RecordSync
 		record.Version,
 		record.Checksum,
 		record.DeviceID,
-	).Scan(&record.ID, &record.Version, &record.LastModified)
+		record.UUID,
+	).Scan(&record.ID, &record.Version, &record.LastModified, &record.UUID)
 
 	if err != nil {
 		return fmt.Errorf("failed to save record: %w", err)
@@ -349,7 +559,8 @@ func (r *SyncRepository) SaveRecord(ctx context.Context, record *sync.RecordSync
 func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*sync.Conflict, error) {
 	query := `
 		SELECT id, record_id, user_id, device_id, local_data, server_data, 
-		       conflict_type, resolved, resolution, resolved_at, created_at, updated_at
+		       conflict_type, resolved, resolution, resolved_at, created_at, updated_at,
+		       record_type, title, local_version, server_version, local_modified, server_modified
 		FROM sync_conflicts
 		WHERE user_id = $1 AND resolved = false
 		ORDER BY created_at DESC
@@ -364,7 +575,7 @@ func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*s
 	var conflicts []*sync.Conflict
 	for rows.Next() {
 		var conflict sync.Conflict
-		var resolvedAt sql.NullTime
+		var resolvedAt, localModified, serverModified sql.NullTime
 
 		err := rows.Scan(
 			&conflict.ID,
@@ -379,6 +590,12 @@ func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*s
 			&resolvedAt,
 			&conflict.CreatedAt,
 			&conflict.UpdatedAt,
+			&conflict.RecordType,
+			&conflict.Title,
+			&conflict.LocalVersion,
+			&conflict.ServerVersion,
+			&localModified,
+			&serverModified,
 		)
 
 		if err != nil {
@@ -388,6 +605,12 @@ func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*s
 		if resolvedAt.Valid {
 			conflict.ResolvedAt = resolvedAt.Time
 		}
+		if localModified.Valid {
+			conflict.LocalModified = localModified.Time
+		}
+		if serverModified.Valid {
+			conflict.ServerModified = serverModified.Time
+		}
 
 		conflicts = append(conflicts, &conflict)
 	}
@@ -399,13 +622,14 @@ func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*s
 func (r *SyncRepository) GetConflictByID(ctx context.Context, conflictID int) (*sync.Conflict, error) {
 	query := `
 		SELECT id, record_id, user_id, device_id, local_data, server_data, 
-		       conflict_type, resolved, resolution, resolved_at, created_at, updated_at
+		       conflict_type, resolved, resolution, resolved_at, created_at, updated_at,
+		       record_type, title, local_version, server_version, local_modified, server_modified
 		FROM sync_conflicts
 		WHERE id = $1
 	`
 
 	var conflict sync.Conflict
-	var resolvedAt sql.NullTime
+	var resolvedAt, localModified, serverModified sql.NullTime
 
 	err := r.pool.QueryRow(ctx, query, conflictID).Scan(
 		&conflict.ID,
@@ -420,6 +644,12 @@ func (r *SyncRepository) GetConflictByID(ctx context.Context, conflictID int) (*
 		&resolvedAt,
 		&conflict.CreatedAt,
 		&conflict.UpdatedAt,
+		&conflict.RecordType,
+		&conflict.Title,
+		&conflict.LocalVersion,
+		&conflict.ServerVersion,
+		&localModified,
+		&serverModified,
 	)
 
 	if err != nil {
@@ -432,6 +662,12 @@ func (r *SyncRepository) GetConflictByID(ctx context.Context, conflictID int) (*
 	if resolvedAt.Valid {
 		conflict.ResolvedAt = resolvedAt.Time
 	}
+	if localModified.Valid {
+		conflict.LocalModified = localModified.Time
+	}
+	if serverModified.Valid {
+		conflict.ServerModified = serverModified.Time
+	}
 
 	return &conflict, nil
 }
@@ -441,8 +677,9 @@ func (r *SyncRepository) SaveConflict(ctx context.Context, conflict *sync.Confli
 	query := `
 		INSERT INTO sync_conflicts 
 			(record_id, user_id, device_id, local_data, server_data, 
-			 conflict_type, resolved, resolution, resolved_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			 conflict_type, resolved, resolution, resolved_at, created_at, updated_at,
+			 record_type, title, local_version, server_version, local_modified, server_modified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		ON CONFLICT (id) DO UPDATE SET
 			local_data = EXCLUDED.local_data,
 			server_data = EXCLUDED.server_data,
@@ -465,6 +702,12 @@ func (r *SyncRepository) SaveConflict(ctx context.Context, conflict *sync.Confli
 		conflict.ResolvedAt,
 		conflict.CreatedAt,
 		conflict.UpdatedAt,
+		conflict.RecordType,
+		conflict.Title,
+		conflict.LocalVersion,
+		conflict.ServerVersion,
+		conflict.LocalModified,
+		conflict.ServerModified,
 	).Scan(&conflict.ID)
 
 	if err != nil {
@@ -533,18 +776,102 @@ func (r *SyncRepository) BatchUpsertRecords(ctx context.Context, records []*sync
 	}(tx, ctx)
 
 	var processed int
-	var failedIDs []int
+	var failed []int
 
-	for _, rec := range records {
-		data, err := hex.DecodeString(rec.EncryptedData)
+	for i, rec := range records {
+		// Ошибка оператора прерывает транзакцию PostgreSQL целиком,
+		// поэтому каждая запись сохраняется в своей точке сохранения
+		sp, err := tx.Begin(ctx)
 		if err != nil {
-			failedIDs = append(failedIDs, rec.ID)
+			return 0, nil, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		if err := upsertRecordSync(ctx, sp, rec); err != nil {
+			r.log.Warn("failed to upsert record", "record_id", rec.ID, "index", i, "error", err)
+			if err := sp.Rollback(ctx); err != nil {
+				return 0, nil, fmt.Errorf("failed to rollback savepoint: %w", err)
+			}
+			failed = append(failed, i)
 			continue
 		}
 
-		_, err = tx.Exec(ctx, `
-		INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		if err := sp.Commit(ctx); err != nil {
+			return 0, nil, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+		processed++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, allIndexes(records), fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return processed, failed, nil
+}
+
+// Перед перезаписью синхронизацией прежнее состояние записи сохраняется в
+// record_versions, как при обновлении через API записей: по этой истории
+// клиент находит общего предка при слиянии конфликта
+const (
+	snapshotRecordByIDQuery = `
+		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum)
+		SELECT id, version, encrypted_data, meta, checksum
+		FROM records
+		WHERE id = $1 AND user_id = $2 AND org_id IS NULL AND version < $3
+		ON CONFLICT (record_id, version) DO NOTHING`
+	snapshotRecordByDataQuery = `
+		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum)
+		SELECT id, version, encrypted_data, meta, checksum
+		FROM records
+		WHERE user_id = $1 AND type = $2 AND encrypted_data = $3 AND deleted_at IS NULL AND version < $4
+		ON CONFLICT (record_id, version) DO NOTHING`
+)
+
+// upsertRecordSync обновляет запись по ID, если пришла более новая версия.
+// Новую запись и запись, которой на сервере уже нет, вставляет; совпадающая
+// по данным запись при этом обновляется, как в SaveRecord.
+func upsertRecordSync(ctx context.Context, tx pgx.Tx, rec *sync.RecordSync) error {
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return fmt.Errorf("failed to decode encrypted data: %w", err)
+	}
+
+	if rec.ID > 0 {
+		if _, err := tx.Exec(ctx, snapshotRecordByIDQuery, rec.ID, rec.UserID, rec.Version); err != nil {
+			return fmt.Errorf("failed to save record version: %w", err)
+		}
+
+		err := tx.QueryRow(ctx, `
+			UPDATE records
+			SET type = $3, encrypted_data = $4, meta = $5, version = $6, checksum = $7,
+			    device_id = $8, deleted_at = $9, last_modified = NOW()
+			WHERE id = $1 AND user_id = $2 AND org_id IS NULL AND version < $6
+			RETURNING id, version, last_modified, uuid::text`,
+			rec.ID, rec.UserID, rec.Type, data, rec.Meta, rec.Version, rec.Checksum, rec.DeviceID, rec.DeletedAt,
+		).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM records WHERE id = $1)`, rec.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("record %d: %w", rec.ID, sync.ErrVersionNotNewer)
+		}
+	}
+
+	if rec.UUID == "" {
+		rec.UUID = uuid.NewString()
+	}
+
+	if _, err := tx.Exec(ctx, snapshotRecordByDataQuery, rec.UserID, rec.Type, data, rec.Version); err != nil {
+		return fmt.Errorf("failed to save record version: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id, deleted_at, uuid)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		ON CONFLICT (user_id, type, encrypted_data) 
 		WHERE deleted_at IS NULL
 		DO UPDATE SET
@@ -554,29 +881,28 @@ func (r *SyncRepository) BatchUpsertRecords(ctx context.Context, records []*sync
 			device_id = EXCLUDED.device_id,
 			last_modified = NOW()
 		WHERE records.version < EXCLUDED.version
-		`, rec.UserID, rec.Type, data, rec.Meta, rec.Version, rec.Checksum, rec.DeviceID)
-
-		if err != nil {
-			failedIDs = append(failedIDs, rec.ID)
-			continue
-		}
-
-		processed++
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return 0, getAllIDs(records), fmt.Errorf("failed to commit transaction: %w", err)
+		RETURNING id, version, last_modified, uuid::text`,
+		rec.UserID, rec.Type, data, rec.Meta, rec.Version, rec.Checksum, rec.DeviceID, rec.DeletedAt, rec.UUID,
+	).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Те же данные с не меньшей версией уже есть на сервере
+		err = tx.QueryRow(ctx, `
+			SELECT id, version, last_modified, uuid::text
+			FROM records
+			WHERE user_id = $1 AND type = $2 AND encrypted_data = $3 AND deleted_at IS NULL`,
+			rec.UserID, rec.Type, data,
+		).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
 	}
 
-	return processed, failedIDs, nil
+	return err
 }
 
-func getAllIDs(records []*sync.RecordSync) []int {
-	ids := make([]int, len(records))
-	for i, rec := range records {
-		ids[i] = rec.ID
+func allIndexes(records []*sync.RecordSync) []int {
+	indexes := make([]int, len(records))
+	for i := range records {
+		indexes[i] = i
 	}
-	return ids
+	return indexes
 }
 
 // BatchDeleteRecords массовое удаление записей (soft delete)
@@ -599,7 +925,7 @@ func (r *SyncRepository) BatchDeleteRecords(ctx context.Context, recordIDs []int
 	query := fmt.Sprintf(`
 		UPDATE records 
 		SET deleted_at = $2, last_modified = $2
-		WHERE user_id = $1 AND id IN (%s)
+		WHERE user_id = $1 AND org_id IS NULL AND id IN (%s)
 	`, strings.Join(placeholders, ","))
 
 	_, err := r.pool.Exec(ctx, query, args...)
@@ -642,6 +968,53 @@ func (r *SyncRepository) RecordSyncDuration(_ context.Context, userID int, _ tim
 // Вспомогательные методы
 
 // scanRecordSync сканирует RecordSync из row
+// scanDevice сканирует DeviceInfo из row со столбцами deviceColumns
+func scanDevice(row interface {
+	Scan(dest ...interface{}) error
+}) (*sync.DeviceInfo, error) {
+	var device sync.DeviceInfo
+	var lastSyncTime, approvedAt sql.NullTime
+	var approvedBy sql.NullInt64
+
+	err := row.Scan(
+		&device.ID,
+		&device.UserID,
+		&device.UUID,
+		&device.Name,
+		&device.Type,
+		&lastSyncTime,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+		&device.IPAddress,
+		&device.UserAgent,
+		&device.Usage.BytesUploaded,
+		&device.Usage.BytesDownloaded,
+		&device.Usage.Requests,
+		&device.Usage.RecordsUploaded,
+		&device.Usage.RecordsDownloaded,
+		&device.PublicKey,
+		&device.Status,
+		&approvedAt,
+		&approvedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSyncTime.Valid {
+		device.LastSyncTime = lastSyncTime.Time
+	}
+	if approvedAt.Valid {
+		device.ApprovedAt = &approvedAt.Time
+	}
+	if approvedBy.Valid {
+		id := int(approvedBy.Int64)
+		device.ApprovedBy = &id
+	}
+
+	return &device, nil
+}
+
 func (r *SyncRepository) scanRecordSync(row interface {
 	Scan(dest ...interface{}) error
 }) (*sync.RecordSync, error) {
@@ -660,6 +1033,7 @@ func (r *SyncRepository) scanRecordSync(row interface {
 		&deletedAt,
 		&rec.Checksum,
 		&rec.DeviceID,
+		&rec.UUID,
 	)
 
 	if err != nil {
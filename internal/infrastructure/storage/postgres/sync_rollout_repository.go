@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// SyncRolloutRepository реализует sync.RolloutRepository для PostgreSQL
+type SyncRolloutRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+// NewSyncRolloutRepository создает новый репозиторий протоколов синхронизации
+func NewSyncRolloutRepository(pool *pgxpool.Pool, log *slog.Logger) *SyncRolloutRepository {
+	return &SyncRolloutRepository{
+		pool: pool,
+		log:  log,
+	}
+}
+
+// GetProtocol возвращает протокол, назначенный пользователю
+func (r *SyncRolloutRepository) GetProtocol(ctx context.Context, userID int) (sync.Protocol, bool, error) {
+	var p sync.Protocol
+	err := r.pool.QueryRow(ctx,
+		`SELECT protocol FROM user_sync_protocols WHERE user_id = $1`,
+		userID).Scan(&p)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+		r.log.Error("failed to get sync protocol", "user_id", userID, "error", err)
+		return "", false, fmt.Errorf("failed to get sync protocol: %w", err)
+	}
+
+	return p, true, nil
+}
+
+// SetProtocol назначает протокол пользователю
+func (r *SyncRolloutRepository) SetProtocol(ctx context.Context, userID int, p sync.Protocol) error {
+	result, err := r.pool.Exec(ctx, `
+		INSERT INTO user_sync_protocols (user_id, protocol, updated_at)
+		SELECT id, $2, NOW() FROM users WHERE id = $1
+		ON CONFLICT (user_id) DO UPDATE SET
+			protocol = EXCLUDED.protocol,
+			updated_at = EXCLUDED.updated_at`,
+		userID, string(p))
+	if err != nil {
+		r.log.Error("failed to set sync protocol", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to set sync protocol: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return sync.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// DeleteProtocol удаляет назначенный пользователю протокол
+func (r *SyncRolloutRepository) DeleteProtocol(ctx context.Context, userID int) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM user_sync_protocols WHERE user_id = $1`, userID)
+	if err != nil {
+		r.log.Error("failed to delete sync protocol", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete sync protocol: %w", err)
+	}
+
+	return nil
+}
@@ -2,13 +2,19 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gophkeeper/internal/domain/user"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/exp/slog"
 )
 
+const userColumns = `id, login, password_hash, role, disabled_at, delete_after, created_at`
+
 func NewUserRepository(pool *pgxpool.Pool, log *slog.Logger) *UserRepository {
 	return &UserRepository{
 		pool: pool,
@@ -26,17 +32,114 @@ func (r *UserRepository) Create(ctx context.Context, login, passwordHash string)
 	err := r.pool.QueryRow(ctx,
 		`INSERT INTO users (login, password_hash) VALUES ($1, $2) RETURNING id`,
 		login, passwordHash).Scan(&userID)
+	// 23505 - unique_violation: логин уже занят
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, user.ErrLoginTaken
+	}
 	return userID, err
 }
 
 func (r *UserRepository) FindByLogin(ctx context.Context, login string) (user.User, error) {
-	var u user.User
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, password_hash FROM users WHERE login = $1`, login).
-		Scan(&u.ID, &u.Password)
+	u, err := scanUser(r.pool.QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE login = $1`, login))
 	if err != nil {
 		return u, fmt.Errorf("user not found")
 	}
 
 	return u, nil
 }
+
+func (r *UserRepository) FindByID(ctx context.Context, id int) (user.User, error) {
+	u, err := scanUser(r.pool.QueryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err != nil {
+		return user.User{ID: id}, fmt.Errorf("user not found")
+	}
+
+	return u, nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]user.User, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []user.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) SetDisabled(ctx context.Context, id int, at *time.Time) error {
+	return r.update(ctx, `UPDATE users SET disabled_at = $2 WHERE id = $1`, id, at)
+}
+
+func (r *UserRepository) SetRole(ctx context.Context, id int, role string) error {
+	return r.update(ctx, `UPDATE users SET role = $2 WHERE id = $1`, id, role)
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	return r.update(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, id, passwordHash)
+}
+
+func (r *UserRepository) SetDeleteAfter(ctx context.Context, id int, at *time.Time) error {
+	return r.update(ctx, `UPDATE users SET delete_after = $2 WHERE id = $1`, id, at)
+}
+
+func (r *UserRepository) ListDeleteDue(ctx context.Context, before time.Time) ([]int, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id FROM users WHERE delete_after IS NOT NULL AND delete_after <= $1 ORDER BY id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("list users due for deletion: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) update(ctx context.Context, query string, id int, value any) error {
+	tag, err := r.pool.Exec(ctx, query, id, value)
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return user.ErrNotFound
+	}
+	return nil
+}
+
+func scanUser(row pgx.Row) (user.User, error) {
+	var u user.User
+	err := row.Scan(&u.ID, &u.Login, &u.Password, &u.Role, &u.DisabledAt, &u.DeleteAfter, &u.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return u, user.ErrNotFound
+	}
+	return u, err
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/viewer"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/exp/slog"
+)
+
+// ViewerRepository реализует viewer.Repository для PostgreSQL
+type ViewerRepository struct {
+	pool *pgxpool.Pool
+	log  *slog.Logger
+}
+
+func NewViewerRepository(pool *pgxpool.Pool, log *slog.Logger) *ViewerRepository {
+	return &ViewerRepository{
+		pool: pool,
+		log:  log.With("component", "viewer_repository"),
+	}
+}
+
+const viewerTokenColumns = `id, user_id, name, folder_ids, expires_at, created_at, last_used_at`
+
+func (r *ViewerRepository) Create(ctx context.Context, token *viewer.Token, secretHash string) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO viewer_tokens (user_id, name, token_hash, folder_ids, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		token.UserID, token.Name, secretHash, token.FolderIDs, token.ExpiresAt, token.CreatedAt,
+	).Scan(&token.ID)
+	if err != nil {
+		r.log.Error("failed to create viewer token", "user_id", token.UserID, "error", err)
+		return fmt.Errorf("insert viewer token: %w", err)
+	}
+	return nil
+}
+
+func (r *ViewerRepository) List(ctx context.Context, userID int) ([]viewer.Token, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+viewerTokenColumns+`
+		FROM viewer_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		r.log.Error("failed to list viewer tokens", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list viewer tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []viewer.Token
+	for rows.Next() {
+		token, err := scanViewerToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan viewer token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *ViewerRepository) GetByHash(ctx context.Context, secretHash string) (*viewer.Token, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+viewerTokenColumns+`
+		FROM viewer_tokens
+		WHERE token_hash = $1`, secretHash)
+
+	token, err := scanViewerToken(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, viewer.ErrNotFound
+		}
+		return nil, fmt.Errorf("get viewer token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *ViewerRepository) Delete(ctx context.Context, userID, id int) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM viewer_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		r.log.Error("failed to delete viewer token", "token_id", id, "error", err)
+		return fmt.Errorf("delete viewer token: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return viewer.ErrNotFound
+	}
+	return nil
+}
+
+func (r *ViewerRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE viewer_tokens SET last_used_at = $1 WHERE id = $2`, at, id); err != nil {
+		return fmt.Errorf("touch viewer token: %w", err)
+	}
+	return nil
+}
+
+func scanViewerToken(row pgx.Row) (*viewer.Token, error) {
+	var token viewer.Token
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.FolderIDs,
+		&token.ExpiresAt, &token.CreatedAt, &token.LastUsedAt); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
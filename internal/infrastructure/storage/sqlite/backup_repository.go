@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/domain/record"
+
+	"golang.org/x/exp/slog"
+)
+
+// BackupRepository реализует backup.Repository для SQLite
+type BackupRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewBackupRepository(db *sql.DB, log *slog.Logger) *BackupRepository {
+	return &BackupRepository{
+		db:  db,
+		log: log.With("component", "backup_repository"),
+	}
+}
+
+// ListUserIDs возвращает ID всех пользователей, у которых есть записи
+func (r *BackupRepository) ListUserIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT user_id FROM records ORDER BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ListAllRecords возвращает все записи пользователя, включая удаленные
+func (r *BackupRepository) ListAllRecords(ctx context.Context, userID int) ([]record.Record, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+recordColumns+`
+		FROM records
+		WHERE user_id = ?
+		ORDER BY id`, userID)
+	if err != nil {
+		r.log.Error("failed to list records for backup", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+	defer rows.Close()
+
+	records, err := scanRecords(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan record: %w", err)
+	}
+
+	return records, nil
+}
+
+// RestoreRecords возвращает записи к состоянию из снимка в одной транзакции.
+// Транзакция начинается с блокировкой на запись (_txlock=immediate), поэтому
+// отдельная блокировка строк, как FOR UPDATE в PostgreSQL, не нужна.
+func (r *BackupRepository) RestoreRecords(ctx context.Context, userID int, records []record.Record) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	restored := 0
+	for _, rec := range records {
+		changed, err := r.restoreRecord(ctx, tx, userID, &rec)
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			restored++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored, nil
+}
+
+func (r *BackupRepository) restoreRecord(ctx context.Context, tx *sql.Tx, userID int, rec *record.Record) (bool, error) {
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return false, fmt.Errorf("%w: record %d: %v", record.ErrInvalidData, rec.ID, err)
+	}
+
+	var ownerID int
+	err = tx.QueryRowContext(ctx, `SELECT user_id FROM records WHERE id = ?`, rec.ID).Scan(&ownerID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// Запись удаленной организации не восстанавливается
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO records (id, user_id, type, encrypted_data, meta, version,
+			                     last_modified, checksum, device_id, deleted_at, org_id, uuid)
+			SELECT ?1, ?2, ?3, ?4, ?5, ?6, NOW(), NULLIF(?7, ''), NULLIF(?8, ''), ?9, ?10, NULLIF(?11, '')
+			WHERE ?10 IS NULL OR EXISTS (SELECT 1 FROM organizations WHERE id = ?10)
+			ON CONFLICT DO NOTHING`,
+			rec.ID, userID, rec.Type, data, metaText(rec.Meta), rec.Version+1,
+			rec.Checksum, rec.DeviceID, utcPtr(rec.DeletedAt), rec.OrgID, rec.UUID)
+		if err != nil {
+			return false, fmt.Errorf("insert record %d: %w", rec.ID, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("insert record %d: %w", rec.ID, err)
+		}
+		if n == 0 {
+			r.log.Warn("record skipped on restore: duplicate data or organization removed",
+				"record_id", rec.ID, "user_id", userID)
+		}
+		return n > 0, nil
+	case err != nil:
+		return false, fmt.Errorf("read record %d: %w", rec.ID, err)
+	case ownerID != userID:
+		return false, fmt.Errorf("record %d belongs to another user", rec.ID)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE records
+		SET type = ?1, encrypted_data = ?2, meta = ?3, checksum = NULLIF(?4, ''),
+		    deleted_at = ?5, version = version + 1, last_modified = NOW()
+		WHERE id = ?6 AND user_id = ?7
+		  AND (encrypted_data <> ?2 OR json(meta) <> json(?3) OR deleted_at IS NOT ?5)`,
+		rec.Type, data, metaText(rec.Meta), rec.Checksum, utcPtr(rec.DeletedAt), rec.ID, userID)
+	if err != nil {
+		return false, fmt.Errorf("update record %d: %w", rec.ID, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update record %d: %w", rec.ID, err)
+	}
+
+	return n > 0, nil
+}
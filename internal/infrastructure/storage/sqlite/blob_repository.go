@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/blob"
+
+	"golang.org/x/exp/slog"
+)
+
+// BlobRepository реализует blob.Repository для SQLite
+type BlobRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewBlobRepository создает новый репозиторий блобов
+func NewBlobRepository(db *sql.DB, log *slog.Logger) *BlobRepository {
+	return &BlobRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetByChecksum возвращает блоб пользователя без данных
+func (r *BlobRepository) GetByChecksum(ctx context.Context, userID int, checksum string) (*blob.Blob, error) {
+	b := &blob.Blob{UserID: userID, Checksum: checksum}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT size, ref_count, created_at
+		FROM blobs
+		WHERE user_id = ? AND checksum = ?`,
+		userID, checksum).Scan(&b.Size, &b.RefCount, &b.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, blob.ErrNotFound
+		}
+		r.log.Error("failed to get blob", "user_id", userID, "checksum", checksum, "error", err)
+		return nil, fmt.Errorf("get blob: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetData возвращает зашифрованные данные блоба
+func (r *BlobRepository) GetData(ctx context.Context, userID int, checksum string) ([]byte, error) {
+	var data []byte
+	err := r.db.QueryRowContext(ctx,
+		`SELECT data FROM blobs WHERE user_id = ? AND checksum = ?`,
+		userID, checksum).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, blob.ErrNotFound
+		}
+		r.log.Error("failed to get blob data", "user_id", userID, "checksum", checksum, "error", err)
+		return nil, fmt.Errorf("get blob data: %w", err)
+	}
+
+	return data, nil
+}
+
+// Create сохраняет блоб. Счетчик ссылок учитывает записи, сославшиеся на блоб
+// до его загрузки (например, полученные синхронизацией с другого устройства).
+func (r *BlobRepository) Create(ctx context.Context, b *blob.Blob) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO blobs (user_id, checksum, data, size, ref_count)
+		VALUES (?1, ?2, ?3, ?4,
+		        (SELECT COUNT(*) FROM records WHERE user_id = ?1 AND json_extract(meta, '$.blob') = ?2))
+		ON CONFLICT (user_id, checksum) DO NOTHING`,
+		b.UserID, b.Checksum, b.Data, b.Size)
+	if err != nil {
+		r.log.Error("failed to create blob", "user_id", b.UserID, "checksum", b.Checksum, "error", err)
+		return false, fmt.Errorf("create blob: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// PruneUnreferenced удаляет блобы без ссылок, счетчик которых не менялся с before
+func (r *BlobRepository) PruneUnreferenced(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM blobs WHERE ref_count = 0 AND updated_at < ?`,
+		utc(before))
+	if err != nil {
+		r.log.Error("failed to prune blobs", "error", err)
+		return 0, fmt.Errorf("prune blobs: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,153 @@
+// Package sqlite реализует репозитории сервера на SQLite для небольших
+// установок без PostgreSQL. Схема создается миграциями из migrations/sqlite.
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName - драйвер sqlite3 с функциями, которых нет в SQLite:
+// NOW() и json_contains (аналог оператора jsonb @> PostgreSQL)
+const driverName = "sqlite3_gophkeeper"
+
+// timeFormat - формат, в котором go-sqlite3 записывает time.Time. Время
+// хранится текстом в UTC, поэтому строки сравниваются в хронологическом порядке.
+// Форматы повторяют sqlite3.SQLiteTimestampFormats, которых нет в сборке без cgo.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// timeLayouts - форматы, в которых время может прийти из базы строкой
+var timeLayouts = []string{
+	timeFormat,
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("now", now, false); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("json_contains", jsonContains, true)
+		},
+	})
+}
+
+// Open открывает базу SQLite. Транзакции начинаются с блокировкой на запись
+// (BEGIN IMMEDIATE), а занятая база ожидается до 5 секунд, поэтому
+// параллельные запросы не получают SQLITE_BUSY.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open(driverName,
+		path+"?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	return db, nil
+}
+
+func now() string {
+	return time.Now().UTC().Format(timeFormat)
+}
+
+// utc приводит время к UTC перед записью в базу
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+// utcPtr - utc для необязательного времени; nil записывается как NULL
+func utcPtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// parseTime разбирает время, вычисленное выражением: у результатов MAX() и
+// представлений нет объявленного типа, и драйвер возвращает их строкой
+func parseTime(s sql.NullString) (time.Time, error) {
+	if !s.Valid {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s.String, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("parse time %q", s.String)
+}
+
+// placeholders возвращает "?, ?, ..." и аргументы для условия IN
+func placeholders[T any](values []T) (string, []any) {
+	marks := make([]string, len(values))
+	args := make([]any, len(values))
+	for i, v := range values {
+		marks[i] = "?"
+		args[i] = v
+	}
+	return strings.Join(marks, ", "), args
+}
+
+// jsonContains повторяет оператор jsonb @> PostgreSQL: каждый ключ pattern
+// есть в doc с содержащимся в нем значением, а каждый элемент массива pattern
+// содержится в каком-либо элементе массива doc
+func jsonContains(doc, pattern string) (bool, error) {
+	var d, p any
+	if err := json.Unmarshal([]byte(doc), &d); err != nil {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(pattern), &p); err != nil {
+		return false, fmt.Errorf("json_contains: %w", err)
+	}
+	return contains(d, p), nil
+}
+
+func contains(doc, pattern any) bool {
+	switch p := pattern.(type) {
+	case map[string]any:
+		d, ok := doc.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range p {
+			v, ok := d[key]
+			if !ok || !contains(v, value) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		d, ok := doc.([]any)
+		if !ok {
+			return false
+		}
+		for _, value := range p {
+			found := false
+			for _, v := range d {
+				if contains(v, value) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(doc, pattern)
+	}
+}
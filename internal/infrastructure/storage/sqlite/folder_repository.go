@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/folder"
+)
+
+// FolderRepository реализует folder.Repository для SQLite
+type FolderRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewFolderRepository(db *sql.DB, log *slog.Logger) *FolderRepository {
+	return &FolderRepository{
+		db:  db,
+		log: log.With("component", "folder_repository"),
+	}
+}
+
+const folderColumns = `id, user_id, parent_id, name, created_at, updated_at`
+
+func (r *FolderRepository) List(ctx context.Context, userID int) ([]folder.Folder, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+folderColumns+`
+		FROM folders
+		WHERE user_id = ?
+		ORDER BY name, id`, userID)
+	if err != nil {
+		r.log.Error("failed to list folders", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list folders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []folder.Folder
+	for rows.Next() {
+		f, err := scanFolder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan folder: %w", err)
+		}
+		folders = append(folders, *f)
+	}
+
+	return folders, rows.Err()
+}
+
+func (r *FolderRepository) Get(ctx context.Context, userID, id int) (*folder.Folder, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+folderColumns+`
+		FROM folders
+		WHERE id = ? AND user_id = ?`, id, userID)
+
+	f, err := scanFolder(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, folder.ErrNotFound
+		}
+		return nil, fmt.Errorf("get folder: %w", err)
+	}
+	return f, nil
+}
+
+func (r *FolderRepository) Create(ctx context.Context, f *folder.Folder) (int, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO folders (user_id, parent_id, name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id`,
+		f.UserID, f.ParentID, f.Name, utc(f.CreatedAt), utc(f.UpdatedAt),
+	).Scan(&f.ID)
+	if err != nil {
+		r.log.Error("failed to create folder", "user_id", f.UserID, "error", err)
+		return 0, fmt.Errorf("insert folder: %w", err)
+	}
+	return f.ID, nil
+}
+
+func (r *FolderRepository) Update(ctx context.Context, f *folder.Folder) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE folders
+		SET parent_id = ?, name = ?, updated_at = ?
+		WHERE id = ? AND user_id = ?`,
+		f.ParentID, f.Name, utc(f.UpdatedAt), f.ID, f.UserID)
+	if err != nil {
+		r.log.Error("failed to update folder", "folder_id", f.ID, "error", err)
+		return fmt.Errorf("update folder: %w", err)
+	}
+
+	return requireAffected(result, folder.ErrNotFound)
+}
+
+func (r *FolderRepository) Delete(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM folders WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		r.log.Error("failed to delete folder", "folder_id", id, "error", err)
+		return fmt.Errorf("delete folder: %w", err)
+	}
+
+	return requireAffected(result, folder.ErrNotFound)
+}
+
+func (r *FolderRepository) CountRecords(ctx context.Context, userID, id int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NULL AND meta->>'folder_id' = ?`,
+		userID, id).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count folder records: %w", err)
+	}
+	return count, nil
+}
+
+func scanFolder(row interface{ Scan(dest ...any) error }) (*folder.Folder, error) {
+	var f folder.Folder
+	if err := row.Scan(&f.ID, &f.UserID, &f.ParentID, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
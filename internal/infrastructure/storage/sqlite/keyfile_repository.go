@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/keyfile"
+
+	"golang.org/x/exp/slog"
+)
+
+// KeyFileRepository реализует keyfile.Repository для SQLite
+type KeyFileRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewKeyFileRepository создает новый репозиторий файлов мастер-ключа
+func NewKeyFileRepository(db *sql.DB, log *slog.Logger) *KeyFileRepository {
+	return &KeyFileRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Get возвращает файл мастер-ключа пользователя
+func (r *KeyFileRepository) Get(ctx context.Context, userID int) (*keyfile.KeyFile, error) {
+	var file keyfile.KeyFile
+	err := r.db.QueryRowContext(ctx,
+		`SELECT data, key_hash, updated_at FROM user_key_files WHERE user_id = ?`,
+		userID).Scan(&file.Data, &file.KeyHash, &file.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, keyfile.ErrNotFound
+		}
+		r.log.Error("failed to get key file", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get key file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// Save сохраняет файл мастер-ключа; файл другого ключа не заменяет сохраненный
+func (r *KeyFileRepository) Save(ctx context.Context, userID int, file keyfile.KeyFile) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_key_files (user_id, data, key_hash, updated_at)
+		VALUES (?, ?, ?, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			data = excluded.data,
+			updated_at = excluded.updated_at
+		WHERE user_key_files.key_hash = excluded.key_hash`,
+		userID, file.Data, file.KeyHash)
+	if err != nil {
+		r.log.Error("failed to save key file", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to save key file: %w", err)
+	}
+
+	return requireAffected(result, keyfile.ErrKeyMismatch)
+}
+
+// Replace заменяет файл мастер-ключа после смены мастер-пароля
+func (r *KeyFileRepository) Replace(ctx context.Context, userID int, file keyfile.KeyFile, previousKeyHash string) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_key_files (user_id, data, key_hash, updated_at)
+		VALUES (?, ?, ?, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			data = excluded.data,
+			key_hash = excluded.key_hash,
+			updated_at = excluded.updated_at
+		WHERE user_key_files.key_hash IN (excluded.key_hash, ?)`,
+		userID, file.Data, file.KeyHash, previousKeyHash)
+	if err != nil {
+		r.log.Error("failed to replace key file", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to replace key file: %w", err)
+	}
+
+	return requireAffected(result, keyfile.ErrKeyMismatch)
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/org"
+
+	"golang.org/x/exp/slog"
+)
+
+// MembershipRepository реализует membership.Repository для SQLite
+type MembershipRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewMembershipRepository(db *sql.DB, log *slog.Logger) *MembershipRepository {
+	return &MembershipRepository{
+		db:  db,
+		log: log.With("component", "membership_repository"),
+	}
+}
+
+func (r *MembershipRepository) Get(ctx context.Context, orgID, userID int) (*membership.Membership, error) {
+	const query = `
+		SELECT org_id, user_id, role, status, encrypted_key, invited_by, created_at, updated_at
+		FROM org_memberships
+		WHERE org_id = ? AND user_id = ?`
+
+	var m membership.Membership
+	err := r.db.QueryRowContext(ctx, query, orgID, userID).Scan(
+		&m.OrgID, &m.UserID, &m.Role, &m.Status, &m.EncryptedKey,
+		&m.InvitedBy, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, membership.ErrNotFound
+		}
+		r.log.Error("failed to get membership", "org_id", orgID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get membership: %w", err)
+	}
+
+	return &m, nil
+}
+
+func (r *MembershipRepository) Create(ctx context.Context, m *membership.Membership) error {
+	const query = `
+		INSERT INTO org_memberships (org_id, user_id, role, status, encrypted_key, invited_by)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING created_at, updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.OrgID, m.UserID, m.Role, m.Status, m.EncryptedKey, m.InvitedBy,
+	).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		r.log.Error("failed to create membership", "org_id", m.OrgID, "user_id", m.UserID, "error", err)
+		return fmt.Errorf("create membership: %w", err)
+	}
+
+	return nil
+}
+
+func (r *MembershipRepository) Activate(ctx context.Context, orgID, userID int, encryptedKey string) error {
+	const query = `
+		UPDATE org_memberships
+		SET status = ?, encrypted_key = ?, updated_at = NOW()
+		WHERE org_id = ? AND user_id = ? AND status = ?`
+
+	result, err := r.db.ExecContext(ctx, query, org.StatusActive, encryptedKey, orgID, userID, org.StatusInvited)
+	if err != nil {
+		return fmt.Errorf("activate membership: %w", err)
+	}
+
+	return requireAffected(result, membership.ErrNotInvited)
+}
+
+func (r *MembershipRepository) UpdateRole(ctx context.Context, orgID, userID int, role org.Role) error {
+	const query = `
+		UPDATE org_memberships
+		SET role = ?, updated_at = NOW()
+		WHERE org_id = ? AND user_id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+
+	return requireAffected(result, membership.ErrNotFound)
+}
+
+func (r *MembershipRepository) Delete(ctx context.Context, orgID, userID int) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM org_memberships WHERE org_id = ? AND user_id = ?`, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("delete membership: %w", err)
+	}
+
+	return requireAffected(result, membership.ErrNotFound)
+}
+
+func (r *MembershipRepository) List(ctx context.Context, orgID int) ([]membership.Member, error) {
+	const query = `
+		SELECT m.user_id, u.login, m.role, m.status, m.created_at
+		FROM org_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = ?
+		ORDER BY u.login`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		r.log.Error("failed to list members", "org_id", orgID, "error", err)
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []membership.Member
+	for rows.Next() {
+		var m membership.Member
+		if err := rows.Scan(&m.UserID, &m.Login, &m.Role, &m.Status, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *MembershipRepository) CountOwners(ctx context.Context, orgID int) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM org_memberships
+		WHERE org_id = ? AND role = ? AND status = ?`,
+		orgID, org.RoleOwner, org.StatusActive,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count owners: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *MembershipRepository) FindUserID(ctx context.Context, login string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM users WHERE login = ?`, login).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, membership.ErrUserNotFound
+		}
+		return 0, fmt.Errorf("find user: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/org"
+)
+
+// OrgRepository реализует org.Repository для SQLite
+type OrgRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewOrgRepository(db *sql.DB, log *slog.Logger) *OrgRepository {
+	return &OrgRepository{
+		db:  db,
+		log: log.With("component", "org_repository"),
+	}
+}
+
+// Create создает организацию и членство владельца в одной транзакции
+func (r *OrgRepository) Create(ctx context.Context, o *org.Organization, ownerKey string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO organizations (name, created_by)
+		VALUES (?, ?)
+		RETURNING id, created_at`,
+		o.Name, o.CreatedBy,
+	).Scan(&o.ID, &o.CreatedAt)
+	if err != nil {
+		r.log.Error("failed to create organization", "user_id", o.CreatedBy, "error", err)
+		return 0, fmt.Errorf("insert organization: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO org_memberships (org_id, user_id, role, status, encrypted_key)
+		VALUES (?, ?, ?, ?, ?)`,
+		o.ID, o.CreatedBy, org.RoleOwner, org.StatusActive, ownerKey)
+	if err != nil {
+		return 0, fmt.Errorf("insert owner membership: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return o.ID, nil
+}
+
+const orgSummarySelect = `
+	SELECT o.id, o.name, m.role, m.status, m.encrypted_key, o.created_at,
+	       (SELECT COUNT(*) FROM org_memberships c WHERE c.org_id = o.id AND c.status = 'active')
+	FROM organizations o
+	JOIN org_memberships m ON m.org_id = o.id`
+
+func (r *OrgRepository) ListForUser(ctx context.Context, userID int) ([]org.Summary, error) {
+	rows, err := r.db.QueryContext(ctx, orgSummarySelect+`
+		WHERE m.user_id = ?
+		ORDER BY o.name`, userID)
+	if err != nil {
+		r.log.Error("failed to list organizations", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []org.Summary
+	for rows.Next() {
+		s, err := scanOrgSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, *s)
+	}
+
+	return orgs, rows.Err()
+}
+
+func (r *OrgRepository) GetForUser(ctx context.Context, orgID, userID int) (*org.Summary, error) {
+	row := r.db.QueryRowContext(ctx, orgSummarySelect+`
+		WHERE o.id = ? AND m.user_id = ?`, orgID, userID)
+
+	s, err := scanOrgSummary(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, org.ErrNotFound
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+
+	return s, nil
+}
+
+// Delete удаляет организацию; участники и записи хранилища удаляются каскадно
+func (r *OrgRepository) Delete(ctx context.Context, orgID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM organizations WHERE id = ?`, orgID)
+	if err != nil {
+		r.log.Error("failed to delete organization", "org_id", orgID, "error", err)
+		return fmt.Errorf("delete organization: %w", err)
+	}
+
+	return requireAffected(result, org.ErrNotFound)
+}
+
+func scanOrgSummary(row interface{ Scan(dest ...any) error }) (*org.Summary, error) {
+	var s org.Summary
+	if err := row.Scan(&s.ID, &s.Name, &s.Role, &s.Status, &s.EncryptedKey, &s.CreatedAt, &s.Members); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/quota"
+
+	"golang.org/x/exp/slog"
+)
+
+// QuotaRepository реализует quota.Repository для SQLite
+type QuotaRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewQuotaRepository создает новый репозиторий квот
+func NewQuotaRepository(db *sql.DB, log *slog.Logger) *QuotaRepository {
+	return &QuotaRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Used возвращает размер данных записей пользователя без учета корзины
+// с его блобами и вложениями: каждый блоб учитывается один раз, сколько бы записей
+// на него ни ссылалось
+func (r *QuotaRepository) Used(ctx context.Context, userID int) (int64, error) {
+	var used int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(LENGTH(encrypted_data)), 0)
+		       + (SELECT COALESCE(SUM(size), 0) FROM blobs WHERE user_id = ?1)
+		       + (SELECT COALESCE(SUM(size), 0) FROM record_attachments WHERE user_id = ?1)
+		FROM records
+		WHERE user_id = ?1 AND deleted_at IS NULL`,
+		userID).Scan(&used)
+	if err != nil {
+		r.log.Error("failed to get storage usage", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// GetLimit возвращает лимит, заданный пользователю
+func (r *QuotaRepository) GetLimit(ctx context.Context, userID int) (int64, bool, error) {
+	var limit int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT storage_limit FROM user_quotas WHERE user_id = ?`,
+		userID).Scan(&limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		r.log.Error("failed to get storage limit", "user_id", userID, "error", err)
+		return 0, false, fmt.Errorf("failed to get storage limit: %w", err)
+	}
+
+	return limit, true, nil
+}
+
+// SetLimit задает лимит пользователю
+func (r *QuotaRepository) SetLimit(ctx context.Context, userID int, limit int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_quotas (user_id, storage_limit, updated_at)
+		SELECT id, ?2, NOW() FROM users WHERE id = ?1
+		ON CONFLICT (user_id) DO UPDATE SET
+			storage_limit = excluded.storage_limit,
+			updated_at = excluded.updated_at`,
+		userID, limit)
+	if err != nil {
+		r.log.Error("failed to set storage limit", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to set storage limit: %w", err)
+	}
+
+	return requireAffected(result, quota.ErrUserNotFound)
+}
+
+// DeleteLimit удаляет заданный пользователю лимит
+func (r *QuotaRepository) DeleteLimit(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_quotas WHERE user_id = ?`, userID)
+	if err != nil {
+		r.log.Error("failed to delete storage limit", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete storage limit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,796 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gophkeeper/internal/domain/record"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+)
+
+// RecordRepository реализует record.Repository для SQLite
+type RecordRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewRecordRepository(db *sql.DB, log *slog.Logger) *RecordRepository {
+	return &RecordRepository{
+		db:  db,
+		log: log.With("component", "record_repository"),
+	}
+}
+
+const recordColumns = `
+	id, user_id, type, encrypted_data, meta, version, last_modified,
+	COALESCE(checksum, ''), COALESCE(device_id, ''), deleted_at, org_id,
+	COALESCE(uuid, '')`
+
+func (r *RecordRepository) List(ctx context.Context, userID int) ([]record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NULL
+		ORDER BY last_modified DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list records", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+func (r *RecordRepository) Get(ctx context.Context, userID, recordID int) (*record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE id = ? AND user_id = ? AND org_id IS NULL AND deleted_at IS NULL`
+
+	rec, err := scanRecord(r.db.QueryRowContext(ctx, query, recordID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record",
+			"record_id", recordID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get record: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *RecordRepository) GetHead(ctx context.Context, recordID int) (*record.Head, error) {
+	const query = `
+		SELECT id, user_id, org_id, version, COALESCE(checksum, ''), last_modified, deleted_at,
+		       COALESCE(LENGTH(encrypted_data), 0)
+		FROM records
+		WHERE id = ?`
+
+	var head record.Head
+	var deletedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, recordID).Scan(
+		&head.ID, &head.UserID, &head.OrgID, &head.Version,
+		&head.Checksum, &head.LastModified, &deletedAt, &head.Size,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record head", "record_id", recordID, "error", err)
+		return nil, fmt.Errorf("get record head: %w", err)
+	}
+
+	if deletedAt.Valid {
+		head.DeletedAt = &deletedAt.Time
+	}
+
+	return &head, nil
+}
+
+func (r *RecordRepository) GetByChecksum(ctx context.Context, userID int, checksum string) (*record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE checksum = ? AND user_id = ? AND org_id IS NULL AND deleted_at IS NULL`
+
+	rec, err := scanRecord(r.db.QueryRowContext(ctx, query, checksum, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record by checksum",
+			"checksum", checksum, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get record by checksum: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *RecordRepository) GetByUUID(ctx context.Context, userID int, recordUUID string) (*record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE uuid = ? AND user_id = ?`
+
+	rec, err := scanRecord(r.db.QueryRowContext(ctx, query, recordUUID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get record by uuid",
+			"uuid", recordUUID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get record by uuid: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *RecordRepository) Create(ctx context.Context, rec *record.Record) (int, error) {
+	const query = `
+		INSERT INTO records (uuid, user_id, type, encrypted_data, meta, checksum, device_id, org_id, last_modified)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+		RETURNING id, version, last_modified`
+
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", record.ErrInvalidData, err)
+	}
+	if rec.UUID == "" {
+		rec.UUID = uuid.NewString()
+	}
+
+	err = r.db.QueryRowContext(ctx, query,
+		rec.UUID, rec.UserID, rec.Type, data, metaText(rec.Meta), rec.Checksum, rec.DeviceID, rec.OrgID,
+	).Scan(&rec.ID, &rec.Version, &rec.LastModified)
+
+	// Запись с этим UUID создал параллельный запрос
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "records.uuid") {
+		return 0, record.ErrDuplicateUUID
+	}
+	if err != nil {
+		r.log.Error("failed to create record",
+			"user_id", rec.UserID, "type", rec.Type, "error", err)
+		return 0, fmt.Errorf("create record: %w", err)
+	}
+
+	return rec.ID, nil
+}
+
+func (r *RecordRepository) Update(ctx context.Context, rec *record.Record) error {
+	const query = `
+		UPDATE records
+		SET type = ?1, encrypted_data = ?2, meta = ?3,
+			version = version + 1, last_modified = NOW(),
+			checksum = ?4, device_id = ?5
+		WHERE id = ?6 AND version = ?8 AND deleted_at IS NULL
+		  AND ((org_id IS NULL AND user_id = ?7) OR org_id = ?9)
+		RETURNING version, last_modified`
+
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return fmt.Errorf("%w: %v", record.ErrInvalidData, err)
+	}
+
+	var newVersion int
+	var newLastModified time.Time
+
+	err = r.db.QueryRowContext(ctx, query,
+		rec.Type, data, metaText(rec.Meta), rec.Checksum, rec.DeviceID,
+		rec.ID, rec.UserID, rec.Version, rec.OrgID,
+	).Scan(&newVersion, &newLastModified)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return record.ErrVersionConflict
+		}
+		r.log.Error("failed to update record",
+			"record_id", rec.ID, "user_id", rec.UserID, "error", err)
+		return fmt.Errorf("update record: %w", err)
+	}
+
+	rec.Version = newVersion
+	rec.LastModified = newLastModified
+	return nil
+}
+
+func (r *RecordRepository) Delete(ctx context.Context, userID, recordID int) error {
+	const query = `DELETE FROM records WHERE id = ? AND user_id = ? AND org_id IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, recordID, userID)
+	if err != nil {
+		r.log.Error("failed to delete record",
+			"record_id", recordID, "user_id", userID, "error", err)
+		return fmt.Errorf("delete record: %w", err)
+	}
+
+	return requireAffected(result, record.ErrNotFound)
+}
+
+func (r *RecordRepository) SoftDelete(ctx context.Context, userID, recordID int) error {
+	const query = `
+		UPDATE records
+		SET deleted_at = NOW(), last_modified = NOW(), version = version + 1
+		WHERE id = ? AND user_id = ? AND org_id IS NULL AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, recordID, userID)
+	if err != nil {
+		r.log.Error("failed to soft delete record",
+			"record_id", recordID, "user_id", userID, "error", err)
+		return fmt.Errorf("soft delete record: %w", err)
+	}
+
+	return requireAffected(result, record.ErrNotFound)
+}
+
+func (r *RecordRepository) Search(ctx context.Context, userID int, criteria record.SearchCriteria) ([]record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NULL`
+
+	args := []any{userID}
+
+	if criteria.Type != "" {
+		query += " AND type = ?"
+		args = append(args, criteria.Type)
+	}
+
+	// LIKE в SQLite не различает регистр латиницы, как ILIKE в PostgreSQL
+	if criteria.Query != "" {
+		query += ` AND (meta->>'title' LIKE ? ESCAPE '\' OR meta->>'resource' LIKE ? ESCAPE '\')`
+		args = append(args, likePattern(criteria.Query), likePattern(criteria.Query))
+	}
+
+	if criteria.Title != "" {
+		query += ` AND meta->>'title' LIKE ? ESCAPE '\'`
+		args = append(args, likePattern(criteria.Title))
+	}
+
+	if criteria.Resource != "" {
+		query += ` AND meta->>'resource' LIKE ? ESCAPE '\'`
+		args = append(args, likePattern(criteria.Resource))
+	}
+
+	contains, err := criteria.MetaContains()
+	if err != nil {
+		return nil, fmt.Errorf("search records: %w", err)
+	}
+	for _, doc := range []json.RawMessage{contains, criteria.MetaQuery} {
+		if len(doc) == 0 {
+			continue
+		}
+		query += " AND json_contains(meta, ?)"
+		args = append(args, string(doc))
+	}
+
+	// Папка задана в meta.folder_id; подходят и записи вложенных папок
+	if criteria.Folder > 0 {
+		query += ` AND meta->>'folder_id' IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM folders WHERE id = ? AND user_id = ?
+				UNION ALL
+				SELECT f.id FROM folders f JOIN subtree s ON f.parent_id = s.id
+			)
+			SELECT id FROM subtree)`
+		args = append(args, criteria.Folder, userID)
+	}
+
+	if criteria.FromDate != nil {
+		query += " AND last_modified >= ?"
+		args = append(args, utc(*criteria.FromDate))
+	}
+
+	if criteria.ToDate != nil {
+		query += " AND last_modified <= ?"
+		args = append(args, utc(*criteria.ToDate))
+	}
+
+	query += " ORDER BY last_modified DESC"
+
+	if criteria.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, criteria.Limit)
+
+		if criteria.Offset > 0 {
+			query += " OFFSET ?"
+			args = append(args, criteria.Offset)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.log.Error("failed to search records", "criteria", criteria, "error", err)
+		return nil, fmt.Errorf("search records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// likePattern экранирует спецсимволы LIKE и оборачивает строку для поиска подстроки
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+func (r *RecordRepository) GetByType(ctx context.Context, userID int, recordType string) ([]record.Record, error) {
+	return r.Search(ctx, userID, record.SearchCriteria{Type: recordType})
+}
+
+func (r *RecordRepository) GetModifiedSince(ctx context.Context, userID int, since time.Time) ([]record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND last_modified > ? AND deleted_at IS NULL
+		ORDER BY last_modified DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, utc(since))
+	if err != nil {
+		r.log.Error("failed to get modified records",
+			"user_id", userID, "since", since, "error", err)
+		return nil, fmt.Errorf("get modified records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+func (r *RecordRepository) GetStats(ctx context.Context, userID int) (map[string]interface{}, error) {
+	const query = `
+		SELECT
+			type,
+			COUNT(*) as count,
+			COALESCE(SUM(LENGTH(encrypted_data)), 0) as total_size
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NULL
+		GROUP BY type`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to get stats", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("get stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]interface{})
+	typeStats := make(map[string]map[string]interface{})
+	var totalRecords, totalSize int64
+
+	for rows.Next() {
+		var recordType string
+		var count int64
+		var size int64
+
+		if err := rows.Scan(&recordType, &count, &size); err != nil {
+			return nil, fmt.Errorf("scan stat: %w", err)
+		}
+
+		typeStats[recordType] = map[string]interface{}{
+			"count": count,
+			"size":  size,
+		}
+		totalRecords += count
+		totalSize += size
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get stats: %w", err)
+	}
+
+	stats["by_type"] = typeStats
+	stats["total_records"] = totalRecords
+	stats["total_size"] = totalSize
+	stats["user_id"] = userID
+
+	return stats, nil
+}
+
+func (r *RecordRepository) SaveVersion(ctx context.Context, version *record.Version) error {
+	const query = `
+		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum, created_at)
+		VALUES (?, ?, ?, ?, ?, NOW())
+		ON CONFLICT (record_id, version) DO NOTHING`
+
+	data, err := hex.DecodeString(version.EncryptedData)
+	if err != nil {
+		return fmt.Errorf("decode encrypted data: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query,
+		version.RecordID, version.Version, data, metaText(version.Meta), version.Checksum)
+
+	return err
+}
+
+func (r *RecordRepository) GetVersions(ctx context.Context, recordID int) ([]record.Version, error) {
+	const query = `
+		SELECT id, record_id, version, encrypted_data, meta, COALESCE(checksum, ''), created_at
+		FROM record_versions
+		WHERE record_id = ?
+		ORDER BY version DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("get versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []record.Version
+	for rows.Next() {
+		var v record.Version
+		var data []byte
+		var meta string
+
+		err := rows.Scan(&v.ID, &v.RecordID, &v.Version, &data, &meta, &v.Checksum, &v.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan version: %w", err)
+		}
+
+		v.EncryptedData = hex.EncodeToString(data)
+		v.Meta = json.RawMessage(meta)
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// ListAttachments возвращает вложения записи без данных, в порядке загрузки
+func (r *RecordRepository) ListAttachments(ctx context.Context, recordID int) ([]record.Attachment, error) {
+	const query = `
+		SELECT id, record_id, user_id, encrypted_meta, size, created_at
+		FROM record_attachments
+		WHERE record_id = ?
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []record.Attachment
+	for rows.Next() {
+		var a record.Attachment
+		if err := rows.Scan(&a.ID, &a.RecordID, &a.UserID, &a.EncryptedMeta, &a.Size, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// GetAttachment возвращает вложение записи с данными
+func (r *RecordRepository) GetAttachment(ctx context.Context, recordID, attachmentID int) (*record.Attachment, error) {
+	const query = `
+		SELECT id, record_id, user_id, encrypted_meta, size, created_at, data
+		FROM record_attachments
+		WHERE id = ? AND record_id = ?`
+
+	var a record.Attachment
+	err := r.db.QueryRowContext(ctx, query, attachmentID, recordID).
+		Scan(&a.ID, &a.RecordID, &a.UserID, &a.EncryptedMeta, &a.Size, &a.CreatedAt, &a.Data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+
+	return &a, nil
+}
+
+// CreateAttachment сохраняет вложение и возвращает его ID
+func (r *RecordRepository) CreateAttachment(ctx context.Context, a *record.Attachment) (int, error) {
+	const query = `
+		INSERT INTO record_attachments (record_id, user_id, encrypted_meta, data, size)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id, created_at`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query, a.RecordID, a.UserID, a.EncryptedMeta, a.Data, a.Size).
+		Scan(&id, &a.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("create attachment: %w", err)
+	}
+
+	return id, nil
+}
+
+// DeleteAttachment удаляет вложение записи
+func (r *RecordRepository) DeleteAttachment(ctx context.Context, recordID, attachmentID int) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM record_attachments WHERE id = ? AND record_id = ?`,
+		attachmentID, recordID)
+	if err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	} else if n == 0 {
+		return record.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+// ListByOrg возвращает записи хранилища организации
+func (r *RecordRepository) ListByOrg(ctx context.Context, orgID int) ([]record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE org_id = ? AND deleted_at IS NULL
+		ORDER BY last_modified DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		r.log.Error("failed to list org records", "org_id", orgID, "error", err)
+		return nil, fmt.Errorf("list org records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// GetShared возвращает запись хранилища организации без проверки прав,
+// права проверяет вызывающий сервис
+func (r *RecordRepository) GetShared(ctx context.Context, recordID int) (*record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE id = ? AND org_id IS NOT NULL AND deleted_at IS NULL`
+
+	rec, err := scanRecord(r.db.QueryRowContext(ctx, query, recordID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, record.ErrNotFound
+		}
+		r.log.Error("failed to get shared record", "record_id", recordID, "error", err)
+		return nil, fmt.Errorf("get shared record: %w", err)
+	}
+
+	return rec, nil
+}
+
+func (r *RecordRepository) DeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM records WHERE id = ? AND org_id = ?`, recordID, orgID)
+	if err != nil {
+		r.log.Error("failed to delete org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return fmt.Errorf("delete org record: %w", err)
+	}
+
+	return requireAffected(result, record.ErrNotFound)
+}
+
+func (r *RecordRepository) SoftDeleteInOrg(ctx context.Context, orgID, recordID int) error {
+	const query = `
+		UPDATE records
+		SET deleted_at = NOW(), last_modified = NOW(), version = version + 1
+		WHERE id = ? AND org_id = ? AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, recordID, orgID)
+	if err != nil {
+		r.log.Error("failed to soft delete org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return fmt.Errorf("soft delete org record: %w", err)
+	}
+
+	return requireAffected(result, record.ErrNotFound)
+}
+
+// ListDeleted возвращает личные записи пользователя в корзине
+func (r *RecordRepository) ListDeleted(ctx context.Context, userID int) ([]record.Record, error) {
+	query := `SELECT ` + recordColumns + `
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list deleted records", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list deleted records: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+func (r *RecordRepository) Restore(ctx context.Context, userID, recordID int) (int, error) {
+	const query = `
+		UPDATE records
+		SET deleted_at = NULL, version = version + 1, last_modified = NOW()
+		WHERE id = ? AND user_id = ? AND org_id IS NULL AND deleted_at IS NOT NULL
+		RETURNING version`
+
+	var version int
+	if err := r.db.QueryRowContext(ctx, query, recordID, userID).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, record.ErrNotFound
+		}
+		r.log.Error("failed to restore record",
+			"record_id", recordID, "user_id", userID, "error", err)
+		return 0, fmt.Errorf("restore record: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *RecordRepository) RestoreInOrg(ctx context.Context, orgID, recordID int) (int, error) {
+	const query = `
+		UPDATE records
+		SET deleted_at = NULL, version = version + 1, last_modified = NOW()
+		WHERE id = ? AND org_id = ? AND deleted_at IS NOT NULL
+		RETURNING version`
+
+	var version int
+	if err := r.db.QueryRowContext(ctx, query, recordID, orgID).Scan(&version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, record.ErrNotFound
+		}
+		r.log.Error("failed to restore org record",
+			"record_id", recordID, "org_id", orgID, "error", err)
+		return 0, fmt.Errorf("restore org record: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *RecordRepository) PurgeDeleted(ctx context.Context, userID int, before time.Time) (int, error) {
+	const query = `
+		DELETE FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, userID, utc(before))
+	if err != nil {
+		r.log.Error("failed to purge deleted records", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("purge deleted records: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (r *RecordRepository) PurgeAllDeleted(ctx context.Context, before time.Time) (int, error) {
+	const query = `DELETE FROM records WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	result, err := r.db.ExecContext(ctx, query, utc(before))
+	if err != nil {
+		r.log.Error("failed to purge deleted records", "error", err)
+		return 0, fmt.Errorf("purge deleted records: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// PurgeAllAcknowledged удаляет записи из корзины, удаление которых получили
+// все активные устройства владельца
+func (r *RecordRepository) PurgeAllAcknowledged(ctx context.Context, before, activeSince time.Time) (int, error) {
+	const query = `
+		DELETE FROM records
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+			AND (org_id IS NOT NULL OR NOT EXISTS (
+				SELECT 1 FROM devices d
+				WHERE d.user_id = records.user_id
+					AND COALESCE(d.last_sync_time, d.created_at) >= ?
+					AND (d.synced_through IS NULL
+						OR (records.last_modified, records.id) > (d.synced_through, d.synced_through_id))
+			))`
+
+	result, err := r.db.ExecContext(ctx, query, utc(before), utc(activeSince))
+	if err != nil {
+		r.log.Error("failed to purge acknowledged records", "error", err)
+		return 0, fmt.Errorf("purge acknowledged records: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// expiryColumns - колонки записи без зашифрованных данных: для поиска
+// истекающих записей нужны только метаданные
+const expiryColumns = `
+	id, user_id, type, X'', meta, version, last_modified,
+	COALESCE(checksum, ''), COALESCE(device_id, ''), deleted_at, org_id,
+	COALESCE(uuid, '')`
+
+func (r *RecordRepository) ListWithExpiry(ctx context.Context, userID int) ([]record.Record, error) {
+	query := `SELECT ` + expiryColumns + `
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL AND deleted_at IS NULL
+			AND json_extract(CASE WHEN json_valid(meta) THEN meta END, '$.expires_at') IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.log.Error("failed to list records with expiry", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list records with expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+func (r *RecordRepository) ListAllWithExpiry(ctx context.Context) ([]record.Record, error) {
+	query := `SELECT ` + expiryColumns + `
+		FROM records
+		WHERE org_id IS NULL AND deleted_at IS NULL
+			AND json_extract(CASE WHEN json_valid(meta) THEN meta END, '$.expires_at') IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.log.Error("failed to list records with expiry", "error", err)
+		return nil, fmt.Errorf("list records with expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRecords(rows)
+}
+
+// Вспомогательные методы
+func scanRecords(rows *sql.Rows) ([]record.Record, error) {
+	var records []record.Record
+
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+
+	return records, rows.Err()
+}
+
+func scanRecord(row interface {
+	Scan(dest ...interface{}) error
+}) (*record.Record, error) {
+	var rec record.Record
+	var data []byte
+	var meta string
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&rec.ID, &rec.UserID, &rec.Type, &data,
+		&meta, &rec.Version, &rec.LastModified,
+		&rec.Checksum, &rec.DeviceID, &deletedAt, &rec.OrgID,
+		&rec.UUID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.EncryptedData = hex.EncodeToString(data)
+	rec.Meta = json.RawMessage(meta)
+	if deletedAt.Valid {
+		rec.DeletedAt = &deletedAt.Time
+	}
+
+	return &rec, nil
+}
+
+// metaText передает JSON текстом: []byte драйвер записал бы как BLOB,
+// который JSON-функции SQLite не разбирают
+func metaText(meta []byte) string {
+	if len(meta) == 0 {
+		return "{}"
+	}
+	return string(meta)
+}
+
+// requireAffected возвращает notFound, если запрос не изменил ни одной строки
+func requireAffected(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
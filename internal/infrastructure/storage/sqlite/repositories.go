@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"gophkeeper/internal/infrastructure/storage"
+
+	"golang.org/x/exp/slog"
+)
+
+// NewRepositories создает репозитории сервера поверх базы SQLite.
+// Close репозиториев закрывает базу.
+func NewRepositories(db *sql.DB, log *slog.Logger) *storage.Repositories {
+	return &storage.Repositories{
+		Users:       NewUserRepository(db, log),
+		Sessions:    NewSessionRepository(db, log),
+		Records:     NewRecordRepository(db, log),
+		Sync:        NewSyncRepository(db, log),
+		Settings:    NewSettingsRepository(db, log),
+		Orgs:        NewOrgRepository(db, log),
+		Memberships: NewMembershipRepository(db, log),
+		Quotas:      NewQuotaRepository(db, log),
+		MFA:         NewMFARepository(db, log),
+		Backups:     NewBackupRepository(db, log),
+		Blobs:       NewBlobRepository(db, log),
+		Folders:     NewFolderRepository(db, log),
+		SyncRollout: NewSyncRolloutRepository(db, log),
+		KeyFiles:    NewKeyFileRepository(db, log),
+		Viewers:     NewViewerRepository(db, log),
+		SecretLinks: NewSecretLinkRepository(db, log),
+		Stats:       NewStatsRepository(db, log),
+		Close: func() {
+			_ = db.Close()
+		},
+	}
+}
@@ -0,0 +1,923 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+	"gophkeeper/internal/domain/viewer"
+	"gophkeeper/internal/infrastructure/migration"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.DB.Driver = config.DriverSQLite
+	cfg.DB.SQLitePath = filepath.Join(t.TempDir(), "gophkeeper.db")
+	require.NoError(t, migration.NewMigration(cfg, migration.DefaultEngine).Up())
+
+	db, err := Open(cfg.DB.SQLitePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestJSONContains(t *testing.T) {
+	tests := []struct {
+		doc, pattern string
+		want         bool
+	}{
+		{`{"a":1,"b":"x"}`, `{"a":1}`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"tags":["x","y","z"]}`, `{"tags":["z","x"]}`, true},
+		{`{"tags":["x"]}`, `{"tags":["y"]}`, false},
+		{`{"a":{"b":{"c":true}}}`, `{"a":{"b":{}}}`, true},
+		{`{}`, `{"a":null}`, false},
+	}
+
+	for _, tt := range tests {
+		got, err := jsonContains(tt.doc, tt.pattern)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s @> %s", tt.doc, tt.pattern)
+	}
+}
+
+func TestRepositories_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	// Сессия: хеш токена хранится как BLOB, просроченная сессия недействительна
+	token := "0123456789abcdef"
+	require.NoError(t, repos.Sessions.Create(ctx, userID, token, time.Now().Add(time.Hour)))
+	got, err := repos.Sessions.Validate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, userID, got)
+
+	expired := "fedcba9876543210"
+	require.NoError(t, repos.Sessions.Create(ctx, userID, expired, time.Now().Add(-time.Hour)))
+	_, err = repos.Sessions.Validate(ctx, expired)
+	assert.ErrorIs(t, err, session.ErrInvalidSession)
+
+	// Запись: создание, поиск по метаданным, обновление и корзина
+	rec := &record.Record{
+		UserID:        userID,
+		Type:          record.RecTypeLogin,
+		EncryptedData: "deadbeef",
+		Meta:          json.RawMessage(`{"title":"GitHub","tags":["work","dev"]}`),
+		Version:       1,
+	}
+	id, err := repos.Records.Create(ctx, rec)
+	require.NoError(t, err)
+	require.NotEmpty(t, rec.UUID)
+
+	// Вторая запись с тем же UUID нарушает уникальный индекс
+	_, err = repos.Records.Create(ctx, &record.Record{UserID: userID, UUID: rec.UUID, Type: record.RecTypeText, EncryptedData: "0102"})
+	assert.ErrorIs(t, err, record.ErrDuplicateUUID)
+
+	found, err := repos.Records.Search(ctx, userID, record.SearchCriteria{
+		MetaQuery: json.RawMessage(`{"tags":["dev"]}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "deadbeef", found[0].EncryptedData)
+	assert.JSONEq(t, string(rec.Meta), string(found[0].Meta))
+
+	rec.ID = id
+	rec.EncryptedData = "cafebabe"
+	require.NoError(t, repos.Records.Update(ctx, rec))
+	assert.ErrorIs(t, repos.Records.Update(ctx, &record.Record{
+		ID: id, UserID: userID, Type: record.RecTypeLogin, EncryptedData: "00", Version: 1,
+	}), record.ErrVersionConflict)
+
+	stored, err := repos.Records.Get(ctx, userID, id)
+	require.NoError(t, err)
+	assert.Equal(t, "cafebabe", stored.EncryptedData)
+	assert.Equal(t, 2, stored.Version)
+
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, id))
+	deleted, err := repos.Records.ListDeleted(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+
+	purged, err := repos.Records.PurgeAllDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	// Синхронизация: повторная загрузка тех же данных обновляет запись, а не дублирует ее
+	synced := &sync.RecordSync{UserID: userID, Type: "text", EncryptedData: "0102", Meta: []byte(`{}`), Version: 1}
+	require.NoError(t, repos.Sync.SaveRecord(ctx, synced))
+	batch := []*sync.RecordSync{
+		{UserID: userID, Type: "text", EncryptedData: "0102", Meta: []byte(`{"title":"note"}`), Version: 2},
+		{UserID: userID, Type: "text", EncryptedData: "zz", Meta: []byte(`{}`), Version: 1},
+		{UserID: userID, Type: "text", EncryptedData: "0a0b", Meta: []byte(`{}`), Version: 1},
+	}
+	processed, failed, err := repos.Sync.BatchUpsertRecords(ctx, batch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, []int{1}, failed, "в ответе индексы, у новых записей ID еще нет")
+	assert.Equal(t, synced.ID, batch[0].ID)
+	assert.NotZero(t, batch[2].ID)
+
+	// Запись с ID обновляется на месте, даже если данные изменились
+	edited := []*sync.RecordSync{{ID: batch[2].ID, UserID: userID, Type: "text", EncryptedData: "0c0d", Meta: []byte(`{}`), Version: 2}}
+	_, failed, err = repos.Sync.BatchUpsertRecords(ctx, edited)
+	require.NoError(t, err)
+	assert.Empty(t, failed)
+	assert.Equal(t, batch[2].ID, edited[0].ID)
+
+	// Перезаписанные синхронизацией версии остаются в истории
+	versions, err := repos.Sync.GetRecordVersions(ctx, batch[2].ID, 10)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "0a0b", versions[0].EncryptedData)
+	versions, err = repos.Sync.GetRecordVersions(ctx, synced.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, 1, versions[0].Version)
+	assert.JSONEq(t, `{}`, string(versions[0].Meta))
+
+	// Устаревшая версия ничего не перезаписывает и в историю не попадает
+	_, failed, err = repos.Sync.BatchUpsertRecords(ctx, []*sync.RecordSync{
+		{ID: batch[2].ID, UserID: userID, Type: "text", EncryptedData: "0e0f", Meta: []byte(`{}`), Version: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0}, failed)
+	versions, err = repos.Sync.GetRecordVersions(ctx, batch[2].ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	changed, err := repos.Sync.GetRecordsForSync(ctx, userID, sync.Cursor{}, 10, sync.Filter{})
+	require.NoError(t, err)
+	require.Len(t, changed, 2)
+	assert.Equal(t, synced.ID, changed[0].ID)
+	assert.Equal(t, 2, changed[0].Version)
+	assert.Equal(t, "0c0d", changed[1].EncryptedData)
+
+	// Курсор указывает на последнюю отданную запись, следующая страница начинается после нее
+	next, err := repos.Sync.GetRecordsForSync(ctx, userID, sync.Cursor{Time: changed[0].LastModified, ID: changed[0].ID}, 10, sync.Filter{})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, changed[1].ID, next[0].ID)
+
+	status, err := repos.Sync.GetSyncStatus(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, status.UserID)
+	assert.Equal(t, 2, status.TotalRecords)
+
+	// Выборочная синхронизация по типу и тегам из meta
+	archived := &sync.RecordSync{UserID: userID, Type: "binary", EncryptedData: "ff", Meta: []byte(`{"tags":["archive","work"]}`), Version: 1}
+	require.NoError(t, repos.Sync.SaveRecord(ctx, archived))
+
+	for _, tt := range []struct {
+		filter sync.Filter
+		want   int
+	}{
+		{filter: sync.Filter{}, want: 3},
+		{filter: sync.Filter{Types: []string{"text"}}, want: 2},
+		{filter: sync.Filter{ExcludeTags: []string{"archive"}}, want: 2},
+		{filter: sync.Filter{Tags: []string{"home", "work"}}, want: 1},
+		{filter: sync.Filter{Types: []string{"text"}, Tags: []string{"work"}}, want: 0},
+	} {
+		filtered, err := repos.Sync.GetRecordsForSync(ctx, userID, sync.Cursor{}, 10, tt.filter)
+		require.NoError(t, err)
+		assert.Len(t, filtered, tt.want, "%+v", tt.filter)
+
+		index, err := repos.Sync.GetRecordIndex(ctx, userID, tt.filter)
+		require.NoError(t, err)
+		assert.Len(t, index, tt.want, "%+v", tt.filter)
+	}
+}
+
+func TestBlobRepository_RefCount(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	checksum := strings.Repeat("ab", 32)
+	created, err := repos.Blobs.Create(ctx, &blob.Blob{UserID: userID, Checksum: checksum, Data: []byte("ciphertext"), Size: 10})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repos.Blobs.Create(ctx, &blob.Blob{UserID: userID, Checksum: checksum, Data: []byte("other"), Size: 5})
+	require.NoError(t, err)
+	assert.False(t, created, "повторная загрузка не перезаписывает блоб")
+
+	refCount := func() int {
+		t.Helper()
+		b, err := repos.Blobs.GetByChecksum(ctx, userID, checksum)
+		require.NoError(t, err)
+		return b.RefCount
+	}
+	meta := json.RawMessage(`{"title":"scan.pdf","blob":"` + checksum + `"}`)
+
+	first, err := repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeBinary, EncryptedData: "01", Meta: meta})
+	require.NoError(t, err)
+	second, err := repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeBinary, EncryptedData: "02", Meta: meta})
+	require.NoError(t, err)
+	assert.Equal(t, 2, refCount())
+
+	// Ссылки из корзины сохраняются, окончательное удаление их снимает
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, first))
+	assert.Equal(t, 2, refCount())
+	require.NoError(t, repos.Records.Delete(ctx, userID, first))
+	assert.Equal(t, 1, refCount())
+
+	require.NoError(t, repos.Records.Update(ctx, &record.Record{
+		ID: second, UserID: userID, Type: record.RecTypeBinary, EncryptedData: "03", Meta: json.RawMessage(`{"title":"scan.pdf"}`), Version: 1,
+	}))
+	assert.Equal(t, 0, refCount())
+
+	used, err := repos.Quotas.Used(ctx, userID)
+	require.NoError(t, err)
+
+	pruned, err := repos.Blobs.PruneUnreferenced(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, pruned, "недавно освобожденный блоб не удаляется")
+
+	pruned, err = repos.Blobs.PruneUnreferenced(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+	_, err = repos.Blobs.GetByChecksum(ctx, userID, checksum)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+
+	usedAfterPrune, err := repos.Quotas.Used(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), used-usedAfterPrune, "блоб учитывается в квоте")
+
+	// Блоб, загруженный после записи со ссылкой, учитывает эту запись
+	_, err = repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeBinary, EncryptedData: "04", Meta: meta})
+	require.NoError(t, err)
+	_, err = repos.Blobs.Create(ctx, &blob.Blob{UserID: userID, Checksum: checksum, Data: []byte("ciphertext"), Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, refCount())
+
+	data, err := repos.Blobs.GetData(ctx, userID, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ciphertext"), data)
+}
+
+func TestRecordRepository_Attachments(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	recordID, err := repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeCard, EncryptedData: "01", Meta: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+
+	usedBefore, err := repos.Quotas.Used(ctx, userID)
+	require.NoError(t, err)
+
+	a := &record.Attachment{RecordID: recordID, UserID: userID, EncryptedMeta: "meta", Data: []byte("scan"), Size: 4}
+	a.ID, err = repos.Records.CreateAttachment(ctx, a)
+	require.NoError(t, err)
+	assert.False(t, a.CreatedAt.IsZero())
+
+	list, err := repos.Records.ListAttachments(ctx, recordID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "meta", list[0].EncryptedMeta)
+	assert.Nil(t, list[0].Data, "список не содержит данных")
+
+	got, err := repos.Records.GetAttachment(ctx, recordID, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("scan"), got.Data)
+
+	_, err = repos.Records.GetAttachment(ctx, recordID+1, a.ID)
+	assert.ErrorIs(t, err, record.ErrAttachmentNotFound)
+
+	usedAfter, err := repos.Quotas.Used(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), usedAfter-usedBefore)
+
+	require.NoError(t, repos.Records.DeleteAttachment(ctx, recordID, a.ID))
+	assert.ErrorIs(t, repos.Records.DeleteAttachment(ctx, recordID, a.ID), record.ErrAttachmentNotFound)
+
+	// Окончательное удаление записи удаляет ее вложения
+	_, err = repos.Records.CreateAttachment(ctx, a)
+	require.NoError(t, err)
+	require.NoError(t, repos.Records.Delete(ctx, userID, recordID))
+	list, err = repos.Records.ListAttachments(ctx, recordID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestFolderRepository(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	now := time.Now()
+
+	work := &folder.Folder{UserID: userID, Name: "Work", CreatedAt: now, UpdatedAt: now}
+	_, err = repos.Folders.Create(ctx, work)
+	require.NoError(t, err)
+	cloud := &folder.Folder{UserID: userID, ParentID: &work.ID, Name: "Cloud", CreatedAt: now, UpdatedAt: now}
+	_, err = repos.Folders.Create(ctx, cloud)
+	require.NoError(t, err)
+	personal := &folder.Folder{UserID: userID, Name: "Personal", CreatedAt: now, UpdatedAt: now}
+	_, err = repos.Folders.Create(ctx, personal)
+	require.NoError(t, err)
+
+	// Имена соседних папок уникальны
+	_, err = repos.Folders.Create(ctx, &folder.Folder{UserID: userID, Name: "Work", CreatedAt: now, UpdatedAt: now})
+	assert.Error(t, err)
+
+	got, err := repos.Folders.Get(ctx, userID, cloud.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.ParentID)
+	assert.Equal(t, work.ID, *got.ParentID)
+	_, err = repos.Folders.Get(ctx, userID+1, cloud.ID)
+	assert.ErrorIs(t, err, folder.ErrNotFound)
+
+	for i, folderID := range []int{work.ID, cloud.ID, cloud.ID, personal.ID} {
+		meta := json.RawMessage(fmt.Sprintf(`{"title":"key","folder_id":%d}`, folderID))
+		data := fmt.Sprintf("%02d", i)
+		_, err := repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeText, EncryptedData: data, Meta: meta})
+		require.NoError(t, err)
+	}
+
+	// Папка включает записи вложенных папок
+	records, err := repos.Records.Search(ctx, userID, record.SearchCriteria{Folder: work.ID})
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+	records, err = repos.Records.Search(ctx, userID, record.SearchCriteria{Folder: cloud.ID})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+	records, err = repos.Records.Search(ctx, userID+1, record.SearchCriteria{Folder: work.ID})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	count, err := repos.Folders.CountRecords(ctx, userID, cloud.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	cloud.ParentID = nil
+	cloud.Name = "Infra"
+	require.NoError(t, repos.Folders.Update(ctx, cloud))
+	list, err := repos.Folders.List(ctx, userID)
+	require.NoError(t, err)
+	paths := folder.Paths(list)
+	assert.Equal(t, "Infra", paths[cloud.ID])
+
+	require.NoError(t, repos.Folders.Delete(ctx, userID, personal.ID))
+	assert.ErrorIs(t, repos.Folders.Delete(ctx, userID, personal.ID), folder.ErrNotFound)
+}
+
+func TestSyncRolloutRepository(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	_, ok, err := repos.SyncRollout.GetProtocol(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, repos.SyncRollout.SetProtocol(ctx, userID, sync.ProtocolV2))
+	require.NoError(t, repos.SyncRollout.SetProtocol(ctx, userID, sync.ProtocolV1))
+	p, ok, err := repos.SyncRollout.GetProtocol(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, sync.ProtocolV1, p)
+
+	assert.ErrorIs(t, repos.SyncRollout.SetProtocol(ctx, userID+1, sync.ProtocolV2), sync.ErrUserNotFound)
+
+	require.NoError(t, repos.SyncRollout.DeleteProtocol(ctx, userID))
+	_, ok, err = repos.SyncRollout.GetProtocol(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSyncRepository_DeviceUsage(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	laptop := &sync.DeviceInfo{UserID: userID, UUID: "0f8fad5b-d9cb-469f-a165-70867728950e", Name: "laptop", Type: "desktop"}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, laptop))
+	legacy := &sync.DeviceInfo{UserID: userID, Name: "laptop", Type: "desktop"}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, legacy))
+
+	usage := sync.DeviceUsage{BytesUploaded: 100, BytesDownloaded: 2000, Requests: 1, RecordsUploaded: 2, RecordsDownloaded: 5}
+	at := time.Now()
+	require.NoError(t, repos.Sync.AddDeviceUsage(ctx, userID, laptop.UUID, usage, at))
+	require.NoError(t, repos.Sync.AddDeviceUsage(ctx, userID, laptop.UUID, usage, at))
+	// Чужое и незарегистрированное устройство не учитываются
+	require.NoError(t, repos.Sync.AddDeviceUsage(ctx, userID+1, laptop.UUID, usage, at))
+	require.NoError(t, repos.Sync.AddDeviceUsage(ctx, userID, "7c9e6679-7425-40de-944b-e07fc1f90ae7", usage, at))
+
+	got, err := repos.Sync.GetDeviceInfo(ctx, laptop.ID)
+	require.NoError(t, err)
+	assert.Equal(t, usage.Add(usage), got.Usage)
+	assert.WithinDuration(t, at, got.LastSyncTime, time.Second)
+
+	// Счетчики дубликата суммируются с оставленным устройством
+	require.NoError(t, repos.Sync.AddDeviceUsage(ctx, userID, laptop.UUID, usage, at))
+	require.NoError(t, repos.Sync.MergeDevices(ctx, legacy.ID, []int{laptop.ID}))
+	devices, err := repos.Sync.ListUserDevices(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, legacy.ID, devices[0].ID)
+	assert.Equal(t, int64(3), devices[0].Usage.Requests)
+	assert.Equal(t, int64(6000), devices[0].Usage.BytesDownloaded)
+}
+
+func TestSyncRepository_DeviceApproval(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	laptop := &sync.DeviceInfo{UserID: userID, UUID: "0f8fad5b-d9cb-469f-a165-70867728950e", Name: "laptop", Type: "desktop"}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, laptop))
+	phone := &sync.DeviceInfo{UserID: userID, UUID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Name: "phone", Type: "mobile",
+		PublicKey: "key", Status: sync.DevicePending}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, phone))
+
+	// Устройство без состояния считается подтвержденным
+	got, err := repos.Sync.GetDeviceByUUID(ctx, userID, laptop.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, laptop.ID, got.ID)
+	assert.Equal(t, sync.DeviceApproved, got.Status)
+
+	got, err = repos.Sync.GetDeviceByUUID(ctx, userID, phone.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, sync.DevicePending, got.Status)
+	assert.Equal(t, "key", got.PublicKey)
+
+	_, err = repos.Sync.GetDeviceByUUID(ctx, userID+1, phone.UUID)
+	assert.ErrorIs(t, err, sync.ErrDeviceNotFound)
+
+	assert.ErrorIs(t, repos.Sync.ApproveDevice(ctx, userID+1, phone.ID, laptop.ID, time.Now()), sync.ErrDeviceNotFound)
+	at := time.Now()
+	require.NoError(t, repos.Sync.ApproveDevice(ctx, userID, phone.ID, laptop.ID, at))
+
+	got, err = repos.Sync.GetDeviceInfo(ctx, phone.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sync.DeviceApproved, got.Status)
+	require.NotNil(t, got.ApprovedBy)
+	assert.Equal(t, laptop.ID, *got.ApprovedBy)
+	require.NotNil(t, got.ApprovedAt)
+	assert.WithinDuration(t, at, *got.ApprovedAt, time.Second)
+}
+
+func TestRecordRepository_PurgeAllAcknowledged(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	phone := &sync.DeviceInfo{UserID: userID, UUID: "0f8fad5b-d9cb-469f-a165-70867728950e", Name: "phone", Type: "mobile"}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, phone))
+	laptop := &sync.DeviceInfo{UserID: userID, UUID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Name: "laptop", Type: "desktop"}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, laptop))
+
+	rec := &sync.RecordSync{UserID: userID, Type: "text", EncryptedData: "0102", Meta: []byte(`{}`), Version: 1}
+	require.NoError(t, repos.Sync.SaveRecord(ctx, rec))
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, rec.ID))
+
+	// Удаление попадает в ленту изменений: курсор указывает на надгробие
+	changes, err := repos.Sync.GetRecordsForSync(ctx, userID, sync.Cursor{}, 10, sync.Filter{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.NotNil(t, changes[0].DeletedAt)
+	tombstone := sync.Cursor{Time: changes[0].LastModified, ID: rec.ID}
+
+	purge := func(activeSince time.Time) int {
+		purged, err := repos.Records.PurgeAllAcknowledged(ctx, time.Now().Add(time.Minute), activeSince)
+		require.NoError(t, err)
+		return purged
+	}
+
+	// Ни одно устройство еще не подтвердило удаление
+	assert.Zero(t, purge(time.Time{}))
+
+	require.NoError(t, repos.Sync.AckDeviceSync(ctx, userID, phone.UUID, tombstone))
+	require.NoError(t, repos.Sync.AckDeviceSync(ctx, userID, laptop.UUID, sync.Cursor{Time: tombstone.Time.Add(-time.Second)}))
+	assert.Zero(t, purge(time.Time{}), "ноутбук получил изменения только до удаления")
+
+	// Позиция устройства не откатывается назад
+	require.NoError(t, repos.Sync.AckDeviceSync(ctx, userID, phone.UUID, sync.Cursor{}))
+	// Устройства, не синхронизировавшиеся после activeSince, удаление не задерживают
+	assert.Equal(t, 1, purge(time.Now().Add(time.Hour)))
+
+	rec = &sync.RecordSync{UserID: userID, Type: "text", EncryptedData: "0304", Meta: []byte(`{}`), Version: 1}
+	require.NoError(t, repos.Sync.SaveRecord(ctx, rec))
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, rec.ID))
+	changes, err = repos.Sync.GetRecordsForSync(ctx, userID, tombstone, 10, sync.Filter{})
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	tombstone = sync.Cursor{Time: changes[0].LastModified, ID: rec.ID}
+
+	require.NoError(t, repos.Sync.AckDeviceSync(ctx, userID, phone.UUID, tombstone))
+	require.NoError(t, repos.Sync.AckDeviceSync(ctx, userID, laptop.UUID, tombstone))
+	assert.Equal(t, 1, purge(time.Time{}))
+}
+
+func TestKeyFileRepository(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	_, err = repos.KeyFiles.Get(ctx, userID)
+	assert.ErrorIs(t, err, keyfile.ErrNotFound)
+
+	hash := strings.Repeat("ab", 32)
+	require.NoError(t, repos.KeyFiles.Save(ctx, userID, keyfile.KeyFile{Data: []byte("v1"), KeyHash: hash}))
+	// Смена мастер-пароля: тот же ключ, новый файл
+	require.NoError(t, repos.KeyFiles.Save(ctx, userID, keyfile.KeyFile{Data: []byte("v2"), KeyHash: hash}))
+
+	err = repos.KeyFiles.Save(ctx, userID, keyfile.KeyFile{Data: []byte("other"), KeyHash: strings.Repeat("cd", 32)})
+	assert.ErrorIs(t, err, keyfile.ErrKeyMismatch)
+
+	file, err := repos.KeyFiles.Get(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), file.Data)
+	assert.Equal(t, hash, file.KeyHash)
+	assert.False(t, file.UpdatedAt.IsZero())
+
+	// Смена мастер-пароля меняет хэш в заголовке: замена только с прежним хэшем
+	newHash := strings.Repeat("ef", 32)
+	err = repos.KeyFiles.Replace(ctx, userID, keyfile.KeyFile{Data: []byte("v3"), KeyHash: newHash}, strings.Repeat("cd", 32))
+	assert.ErrorIs(t, err, keyfile.ErrKeyMismatch)
+	require.NoError(t, repos.KeyFiles.Replace(ctx, userID, keyfile.KeyFile{Data: []byte("v3"), KeyHash: newHash}, hash))
+	require.NoError(t, repos.KeyFiles.Replace(ctx, userID, keyfile.KeyFile{Data: []byte("v4"), KeyHash: newHash}, hash))
+
+	file, err = repos.KeyFiles.Get(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v4"), file.Data)
+	assert.Equal(t, newHash, file.KeyHash)
+	err = repos.KeyFiles.Save(ctx, userID, keyfile.KeyFile{Data: []byte("old"), KeyHash: hash})
+	assert.ErrorIs(t, err, keyfile.ErrKeyMismatch)
+}
+
+func TestRecordRepository_ListWithExpiry(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	alice, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	bob, err := repos.Users.Create(ctx, "bob", "hash")
+	require.NoError(t, err)
+
+	save := func(userID int, data, meta string) int {
+		rec := &sync.RecordSync{UserID: userID, Type: "card", EncryptedData: data, Meta: []byte(meta), Version: 1}
+		require.NoError(t, repos.Sync.SaveRecord(ctx, rec))
+		return rec.ID
+	}
+	card := save(alice, "01", `{"title":"Visa","expires_at":"2027-01-01T00:00:00Z"}`)
+	save(alice, "02", `{"title":"no expiry"}`)
+	trashed := save(alice, "03", `{"expires_at":"2026-01-01"}`)
+	require.NoError(t, repos.Records.SoftDelete(ctx, alice, trashed))
+	cert := save(bob, "04", `{"expires_at":"2026-06-01"}`)
+
+	records, err := repos.Records.ListWithExpiry(ctx, alice)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, card, records[0].ID)
+	assert.Empty(t, records[0].EncryptedData, "содержимое записей не читается")
+
+	records, err = repos.Records.ListAllWithExpiry(ctx)
+	require.NoError(t, err)
+	ids := make([]int, 0, len(records))
+	for _, rec := range records {
+		ids = append(ids, rec.ID)
+	}
+	assert.ElementsMatch(t, []int{card, cert}, ids)
+}
+
+func TestViewerRepository(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	now := time.Now()
+	token := &viewer.Token{UserID: userID, Name: "audit", FolderIDs: []int{3, 5}, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repos.Viewers.Create(ctx, token, viewer.HashSecret("gkv_secret")))
+	require.NotZero(t, token.ID)
+
+	got, err := repos.Viewers.GetByHash(ctx, viewer.HashSecret("gkv_secret"))
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, got.ID)
+	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, []int{3, 5}, got.FolderIDs)
+	assert.WithinDuration(t, token.ExpiresAt, got.ExpiresAt, time.Second)
+	assert.Nil(t, got.LastUsedAt)
+
+	_, err = repos.Viewers.GetByHash(ctx, viewer.HashSecret("gkv_other"))
+	assert.ErrorIs(t, err, viewer.ErrNotFound)
+
+	require.NoError(t, repos.Viewers.Touch(ctx, token.ID, now))
+	tokens, err := repos.Viewers.List(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.NotNil(t, tokens[0].LastUsedAt)
+	assert.WithinDuration(t, now, *tokens[0].LastUsedAt, time.Second)
+
+	// Чужой токен не отзывается
+	assert.ErrorIs(t, repos.Viewers.Delete(ctx, userID+1, token.ID), viewer.ErrNotFound)
+	require.NoError(t, repos.Viewers.Delete(ctx, userID, token.ID))
+	_, err = repos.Viewers.GetByHash(ctx, viewer.HashSecret("gkv_secret"))
+	assert.ErrorIs(t, err, viewer.ErrNotFound)
+}
+
+func TestSecretLinkRepository(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	now := time.Now()
+	twice := &secretlink.Link{ID: "twice", UserID: userID, MaxViews: 2, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	expired := &secretlink.Link{ID: "expired", UserID: userID, MaxViews: 1, ExpiresAt: now.Add(-time.Minute), CreatedAt: now}
+	require.NoError(t, repos.SecretLinks.Create(ctx, twice, []byte("ciphertext")))
+	require.NoError(t, repos.SecretLinks.Create(ctx, expired, []byte("old")))
+
+	links, err := repos.SecretLinks.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	// Истекшая ссылка не открывается
+	_, err = repos.SecretLinks.Consume(ctx, "expired", now)
+	assert.ErrorIs(t, err, secretlink.ErrNotFound)
+
+	for i := 0; i < 2; i++ {
+		data, err := repos.SecretLinks.Consume(ctx, "twice", now)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("ciphertext"), data)
+	}
+	// После последнего просмотра шифротекст удален
+	_, err = repos.SecretLinks.Consume(ctx, "twice", now)
+	assert.ErrorIs(t, err, secretlink.ErrNotFound)
+
+	n, err := repos.SecretLinks.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	links, err = repos.SecretLinks.List(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	link := &secretlink.Link{ID: "revoked", UserID: userID, MaxViews: 1, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	require.NoError(t, repos.SecretLinks.Create(ctx, link, []byte("x")))
+	assert.ErrorIs(t, repos.SecretLinks.Delete(ctx, userID+1, link.ID), secretlink.ErrNotFound)
+	require.NoError(t, repos.SecretLinks.Delete(ctx, userID, link.ID))
+	_, err = repos.SecretLinks.Consume(ctx, link.ID, now)
+	assert.ErrorIs(t, err, secretlink.ErrNotFound)
+}
+
+func TestUserRepository_Admin(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	aliceID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	_, err = repos.Users.Create(ctx, "bob", "hash")
+	require.NoError(t, err)
+
+	users, err := repos.Users.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Login)
+	assert.Equal(t, user.RoleUser, users[0].Role)
+	assert.False(t, users[0].Disabled())
+
+	require.NoError(t, repos.Users.SetRole(ctx, aliceID, user.RoleAdmin))
+	require.NoError(t, repos.Users.UpdatePassword(ctx, aliceID, "new-hash"))
+	u, err := repos.Users.FindByLogin(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, u.Role)
+	assert.Equal(t, "new-hash", u.Password)
+
+	// Сессии заблокированного пользователя недействительны
+	token := "0123456789abcdef"
+	require.NoError(t, repos.Sessions.Create(ctx, aliceID, token, time.Now().Add(time.Hour)))
+	disabledAt := time.Now()
+	require.NoError(t, repos.Users.SetDisabled(ctx, aliceID, &disabledAt))
+	u, err = repos.Users.FindByID(ctx, aliceID)
+	require.NoError(t, err)
+	require.NotNil(t, u.DisabledAt)
+	assert.WithinDuration(t, disabledAt, *u.DisabledAt, time.Second)
+	_, err = repos.Sessions.Validate(ctx, token)
+	assert.ErrorIs(t, err, session.ErrInvalidSession)
+
+	require.NoError(t, repos.Users.SetDisabled(ctx, aliceID, nil))
+	_, err = repos.Sessions.Validate(ctx, token)
+	require.NoError(t, err)
+
+	n, err := repos.Sessions.DeleteByUser(ctx, aliceID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	_, err = repos.Sessions.Validate(ctx, token)
+	assert.ErrorIs(t, err, session.ErrInvalidSession)
+
+	assert.ErrorIs(t, repos.Users.SetRole(ctx, 999, user.RoleAdmin), user.ErrNotFound)
+}
+
+func TestSessionRepository_List(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+
+	aliceID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	bobID, err := repos.Users.Create(ctx, "bob", "hash")
+	require.NoError(t, err)
+	laptop := &sync.DeviceInfo{UserID: aliceID, UUID: "0f8fad5b-d9cb-469f-a165-70867728950e", Name: "laptop", Type: "desktop",
+		PublicKey: "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="}
+	require.NoError(t, repos.Sync.RegisterDevice(ctx, laptop))
+
+	now := time.Now()
+	current, other, expired := "0123456789abcdef", "fedcba9876543210", "00112233445566778899"
+	require.NoError(t, repos.Sessions.Create(ctx, aliceID, current, now.Add(time.Hour)))
+	require.NoError(t, repos.Sessions.CreateMFA(ctx, aliceID, other, now.Add(time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, aliceID, expired, now.Add(-time.Hour)))
+	require.NoError(t, repos.Sessions.Create(ctx, bobID, "aabbccddeeff", now.Add(time.Hour)))
+
+	// Сессия использована позже, чем созданы остальные: created_at ставит
+	// база, и now мог остаться в предыдущей секунде
+	touched := now.Add(time.Minute)
+	client := session.Client{DeviceID: laptop.UUID, IPAddress: "192.0.2.1", UserAgent: "gophkeeper-cli/1.0"}
+	require.NoError(t, repos.Sessions.Touch(ctx, current, client, touched))
+	// Повторное использование в пределах TouchInterval не обновляет сессию
+	require.NoError(t, repos.Sessions.Touch(ctx, current, session.Client{IPAddress: "198.51.100.7"}, touched.Add(time.Second)))
+
+	sessions, err := repos.Sessions.ListByUser(ctx, aliceID, current)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2, "истекшие и чужие сессии не показываются")
+	got := sessions[0]
+	assert.True(t, got.Current)
+	assert.Equal(t, "laptop", got.DeviceName)
+	assert.Equal(t, sync.DeviceFingerprint(laptop.PublicKey), got.DeviceFingerprint)
+	assert.NotEmpty(t, got.DeviceFingerprint)
+	assert.Equal(t, "192.0.2.1", got.IPAddress)
+	assert.Equal(t, "gophkeeper-cli/1.0", got.UserAgent)
+	require.NotNil(t, got.LastUsedAt)
+	assert.WithinDuration(t, touched, *got.LastUsedAt, time.Second)
+	assert.False(t, sessions[1].Current)
+	assert.True(t, sessions[1].MFA)
+	assert.Nil(t, sessions[1].LastUsedAt)
+
+	assert.ErrorIs(t, repos.Sessions.Delete(ctx, bobID, sessions[1].ID), session.ErrNotFound)
+	require.NoError(t, repos.Sessions.Delete(ctx, aliceID, sessions[1].ID))
+	_, err = repos.Sessions.Validate(ctx, other)
+	assert.ErrorIs(t, err, session.ErrInvalidSession)
+
+	require.NoError(t, repos.Sessions.Create(ctx, aliceID, other, now.Add(time.Hour)))
+	n, err := repos.Sessions.DeleteOthers(ctx, aliceID, current)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n, "завершаются и истекшие сессии")
+	_, err = repos.Sessions.Validate(ctx, current)
+	require.NoError(t, err)
+
+	require.NoError(t, repos.Sessions.Create(ctx, bobID, expired, now.Add(-time.Hour)))
+	n, err = repos.Sessions.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestUserRepository_DeleteAfter(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+	now := time.Now()
+
+	aliceID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+	bobID, err := repos.Users.Create(ctx, "bob", "hash")
+	require.NoError(t, err)
+
+	_, err = repos.Records.Create(ctx, &record.Record{UserID: aliceID, Type: record.RecTypeText, EncryptedData: "01", Meta: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+	token := "0123456789abcdef"
+	require.NoError(t, repos.Sessions.Create(ctx, aliceID, token, now.Add(time.Hour)))
+
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	require.NoError(t, repos.Users.SetDeleteAfter(ctx, aliceID, &past))
+	require.NoError(t, repos.Users.SetDeleteAfter(ctx, bobID, &future))
+	u, err := repos.Users.FindByID(ctx, aliceID)
+	require.NoError(t, err)
+	require.NotNil(t, u.DeleteAfter)
+	assert.WithinDuration(t, past, *u.DeleteAfter, time.Second)
+
+	ids, err := repos.Users.ListDeleteDue(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []int{aliceID}, ids)
+
+	// Удаление пользователя каскадно удаляет записи и сессии
+	require.NoError(t, repos.Users.Delete(ctx, aliceID))
+	_, err = repos.Users.FindByID(ctx, aliceID)
+	assert.Error(t, err)
+	records, err := repos.Backups.ListAllRecords(ctx, aliceID)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	_, err = repos.Sessions.Validate(ctx, token)
+	assert.ErrorIs(t, err, session.ErrInvalidSession)
+	assert.ErrorIs(t, repos.Users.Delete(ctx, aliceID), user.ErrNotFound)
+
+	require.NoError(t, repos.Users.SetDeleteAfter(ctx, bobID, nil))
+	ids, err = repos.Users.ListDeleteDue(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestStatsRepository_Daily(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repos := NewRepositories(newTestDB(t), log)
+	today := time.Now().UTC()
+
+	userID, err := repos.Users.Create(ctx, "alice", "hash")
+	require.NoError(t, err)
+
+	create := func(data string) int {
+		t.Helper()
+		id, err := repos.Records.Create(ctx, &record.Record{UserID: userID, Type: record.RecTypeText, EncryptedData: data, Meta: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+		return id
+	}
+	first, second, third := create("0101"), create("0202"), create("0303")
+
+	require.NoError(t, repos.Records.Update(ctx, &record.Record{
+		ID: first, UserID: userID, Type: record.RecTypeText, EncryptedData: "01010101", Meta: json.RawMessage(`{}`), Version: 1,
+	}))
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, second))
+	require.NoError(t, repos.Records.SoftDelete(ctx, userID, third))
+	_, err = repos.Records.Restore(ctx, userID, third)
+	require.NoError(t, err)
+	require.NoError(t, repos.Records.Delete(ctx, userID, second), "запись из корзины учтена при перемещении в корзину")
+
+	days, err := repos.Stats.Daily(ctx, userID, today.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Equal(t, today.Format("2006-01-02"), days[0].Day)
+	assert.Equal(t, int64(4), days[0].Created, "три созданные и одна восстановленная")
+	assert.Equal(t, int64(1), days[0].Updated)
+	assert.Equal(t, int64(2), days[0].Deleted)
+
+	// Сумма изменений совпадает с текущим объемом записей
+	current, err := repos.Records.GetStats(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, current["total_records"], days[0].Created-days[0].Deleted)
+	assert.Equal(t, current["total_size"], days[0].SizeDelta)
+
+	days, err = repos.Stats.Daily(ctx, userID, today.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, days)
+
+	// Удаление пользователя удаляет и его статистику
+	require.NoError(t, repos.Users.Delete(ctx, userID))
+	days, err = repos.Stats.Daily(ctx, userID, today.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Empty(t, days)
+}
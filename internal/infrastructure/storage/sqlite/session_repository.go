@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/sync"
+
+	"golang.org/x/exp/slog"
+)
+
+// SessionRepository реализует session.Repository для SQLite.
+// Хэш токена хранится в BLOB, как BYTEA в PostgreSQL.
+type SessionRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewSessionRepository(db *sql.DB, log *slog.Logger) *SessionRepository {
+	return &SessionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	return r.create(ctx, userID, tokenHash, expiresAt, false)
+}
+
+func (r *SessionRepository) CreateMFA(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	return r.create(ctx, userID, tokenHash, expiresAt, true)
+}
+
+func (r *SessionRepository) create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, mfa bool) error {
+	hash, err := hex.DecodeString(tokenHash)
+	if err != nil {
+		return fmt.Errorf("decode token hash: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO sessions (user_id, token_hash, expires_at, mfa) VALUES (?, ?, ?, ?)`,
+		userID, hash, utc(expiresAt), mfa)
+	return err
+}
+
+// Validate принимает сессию без второго фактора, только если он не включен у пользователя.
+// Сессии заблокированных пользователей недействительны.
+func (r *SessionRepository) Validate(ctx context.Context, tokenHash string) (int, error) {
+	hash, err := hex.DecodeString(tokenHash)
+	if err != nil {
+		return 0, session.ErrInvalidSession
+	}
+
+	var userID int
+	err = r.db.QueryRowContext(ctx,
+		`SELECT s.user_id FROM sessions s
+         JOIN users u ON u.id = s.user_id AND u.disabled_at IS NULL
+         LEFT JOIN user_totp t ON t.user_id = s.user_id AND t.enabled
+         WHERE s.token_hash = ? AND s.expires_at > NOW()
+           AND (t.user_id IS NULL OR s.mfa)`,
+		hash).Scan(&userID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, session.ErrInvalidSession
+	}
+	if err != nil {
+		return 0, fmt.Errorf("validate session: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *SessionRepository) DeleteByUser(ctx context.Context, userID int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Touch обновляет время использования не чаще раза в session.TouchInterval
+func (r *SessionRepository) Touch(ctx context.Context, tokenHash string, client session.Client, at time.Time) error {
+	hash, err := hex.DecodeString(tokenHash)
+	if err != nil {
+		return fmt.Errorf("decode token hash: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE sessions
+         SET last_used_at = ?,
+             device_id = COALESCE(NULLIF(?, ''), device_id),
+             ip_address = COALESCE(NULLIF(?, ''), ip_address),
+             user_agent = COALESCE(NULLIF(?, ''), user_agent)
+         WHERE token_hash = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		utc(at), client.DeviceID, client.IPAddress, client.UserAgent, hash, utc(at.Add(-session.TouchInterval)))
+	return err
+}
+
+func (r *SessionRepository) ListByUser(ctx context.Context, userID int, currentHash string) ([]session.Session, error) {
+	current, err := hex.DecodeString(currentHash)
+	if err != nil {
+		return nil, fmt.Errorf("decode token hash: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT s.id, COALESCE(d.name, ''), COALESCE(d.public_key, ''), COALESCE(s.ip_address, ''),
+                COALESCE(s.user_agent, ''), s.mfa, s.created_at, s.last_used_at, s.expires_at,
+                s.token_hash = ?
+         FROM sessions s
+         LEFT JOIN devices d ON d.user_id = s.user_id AND d.device_uuid = s.device_id
+         WHERE s.user_id = ? AND s.expires_at > NOW()
+         ORDER BY COALESCE(s.last_used_at, s.created_at) DESC, s.id DESC`,
+		current, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]session.Session, 0)
+	for rows.Next() {
+		var s session.Session
+		var publicKey string
+		var lastUsed sql.NullTime
+		if err := rows.Scan(&s.ID, &s.DeviceName, &publicKey, &s.IPAddress, &s.UserAgent, &s.MFA,
+			&s.CreatedAt, &lastUsed, &s.ExpiresAt, &s.Current); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		s.DeviceFingerprint = sync.DeviceFingerprint(publicKey)
+		if lastUsed.Valid {
+			s.LastUsedAt = &lastUsed.Time
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepository) Delete(ctx context.Context, userID, sessionID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE id = ? AND user_id = ?`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) DeleteOthers(ctx context.Context, userID int, keepHash string) (int64, error) {
+	keep, err := hex.DecodeString(keepHash)
+	if err != nil {
+		return 0, fmt.Errorf("decode token hash: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ? AND token_hash <> ?`, userID, keep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at <= ?`, utc(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
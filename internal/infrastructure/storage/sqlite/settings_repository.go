@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golang.org/x/exp/slog"
+)
+
+// SettingsRepository реализует settings.Repository для SQLite
+type SettingsRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewSettingsRepository создает новый репозиторий настроек
+func NewSettingsRepository(db *sql.DB, log *slog.Logger) *SettingsRepository {
+	return &SettingsRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Get возвращает все заданные настройки пользователя
+func (r *SettingsRepository) Get(ctx context.Context, userID int) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT key, value FROM user_settings WHERE user_id = ?`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settings: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		values[key] = value
+	}
+
+	return values, rows.Err()
+}
+
+// Set сохраняет значения и удаляет ключи из списка remove в одной транзакции
+func (r *SettingsRepository) Set(ctx context.Context, userID int, values map[string]string, remove []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for key, value := range values {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO user_settings (user_id, key, value, updated_at)
+			VALUES (?, ?, ?, NOW())
+			ON CONFLICT (user_id, key) DO UPDATE SET
+				value = excluded.value,
+				updated_at = excluded.updated_at`,
+			userID, key, value)
+		if err != nil {
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+
+	if len(remove) > 0 {
+		in, keys := placeholders(remove)
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM user_settings WHERE user_id = ? AND key IN (`+in+`)`,
+			append([]any{userID}, keys...)...)
+		if err != nil {
+			return fmt.Errorf("failed to reset settings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/stats"
+
+	"golang.org/x/exp/slog"
+)
+
+// StatsRepository реализует stats.Repository для SQLite
+type StatsRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewStatsRepository создает новый репозиторий статистики
+func NewStatsRepository(db *sql.DB, log *slog.Logger) *StatsRepository {
+	return &StatsRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Daily возвращает изменения записей пользователя по дням начиная с since.
+// Таблицу record_daily_stats ведут триггеры на records.
+func (r *StatsRepository) Daily(ctx context.Context, userID int, since time.Time) ([]stats.Day, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT day, created, updated, deleted, size_delta
+		FROM record_daily_stats
+		WHERE user_id = ? AND day >= ?
+		ORDER BY day`,
+		userID, since.UTC().Format("2006-01-02"))
+	if err != nil {
+		r.log.Error("failed to get daily stats", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	var days []stats.Day
+	for rows.Next() {
+		var d stats.Day
+		if err := rows.Scan(&d.Day, &d.Created, &d.Updated, &d.Deleted, &d.SizeDelta); err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		days = append(days, d)
+	}
+
+	return days, rows.Err()
+}
@@ -0,0 +1,982 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/sync"
+)
+
+// SyncRepository реализация репозитория синхронизации для SQLite
+type SyncRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewSyncRepository создает новый репозиторий синхронизации
+func NewSyncRepository(db *sql.DB, log *slog.Logger) *SyncRepository {
+	return &SyncRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetSyncStatus возвращает статус синхронизации пользователя из VIEW
+func (r *SyncRepository) GetSyncStatus(ctx context.Context, userID int) (*sync.Status, error) {
+	query := `
+		SELECT user_id, last_sync_time, total_records, device_count,
+		       storage_used, storage_limit, sync_version
+		FROM sync_status_view
+		WHERE user_id = ?
+	`
+
+	var status sync.Status
+	var lastSyncTime sql.NullString
+	var storageLimit sql.NullInt64
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&status.UserID,
+		&lastSyncTime,
+		&status.TotalRecords,
+		&status.DeviceCount,
+		&status.StorageUsed,
+		&storageLimit,
+		&status.SyncVersion,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Возвращаем пустой статус для нового пользователя
+			return &sync.Status{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+
+	if status.LastSyncTime, err = parseTime(lastSyncTime); err != nil {
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+	// Без заданного лимита действует лимит из конфигурации сервиса
+	if storageLimit.Valid {
+		status.StorageLimit = storageLimit.Int64
+	}
+
+	return &status, nil
+}
+
+// UpdateSyncStatus обновляет статус синхронизации (no-op, т.к. используется VIEW)
+func (r *SyncRepository) UpdateSyncStatus(_ context.Context, status *sync.Status) error {
+	r.log.Debug("UpdateSyncStatus called but using VIEW, no action needed", "user_id", status.UserID)
+	return nil
+}
+
+const deviceColumns = `
+	id, user_id, COALESCE(device_uuid, ''), name, type, last_sync_time, created_at, updated_at,
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+	bytes_uploaded, bytes_downloaded, request_count, records_uploaded, records_downloaded,
+	public_key, status, approved_at, approved_by`
+
+// GetDeviceInfo возвращает информацию об устройстве
+func (r *SyncRepository) GetDeviceInfo(ctx context.Context, deviceID int) (*sync.DeviceInfo, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE id = ?`
+
+	device, err := scanDevice(r.db.QueryRowContext(ctx, query, deviceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sync.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device info: %w", err)
+	}
+
+	return device, nil
+}
+
+// GetDeviceByUUID возвращает устройство пользователя по UUID
+func (r *SyncRepository) GetDeviceByUUID(ctx context.Context, userID int, deviceUUID string) (*sync.DeviceInfo, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE user_id = ? AND device_uuid = ?`
+
+	device, err := scanDevice(r.db.QueryRowContext(ctx, query, userID, deviceUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sync.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device by uuid: %w", err)
+	}
+
+	return device, nil
+}
+
+// RegisterDevice регистрирует новое устройство (ID == 0) или обновляет существующее
+func (r *SyncRepository) RegisterDevice(ctx context.Context, device *sync.DeviceInfo) error {
+	deviceUUID := sql.NullString{String: device.UUID, Valid: device.UUID != ""}
+	if device.Status == "" {
+		device.Status = sync.DeviceApproved
+	}
+
+	if device.ID == 0 {
+		query := `
+			INSERT INTO devices (user_id, device_uuid, name, type, last_sync_time, ip_address, user_agent,
+			                     public_key, status, approved_at, approved_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+			RETURNING id, created_at, updated_at
+		`
+
+		var lastSyncTime *time.Time
+		if !device.LastSyncTime.IsZero() {
+			lastSyncTime = &device.LastSyncTime
+		}
+
+		err := r.db.QueryRowContext(ctx, query,
+			device.UserID,
+			deviceUUID,
+			device.Name,
+			device.Type,
+			utcPtr(lastSyncTime),
+			device.IPAddress,
+			device.UserAgent,
+			device.PublicKey,
+			device.Status,
+			utcPtr(device.ApprovedAt),
+			device.ApprovedBy,
+		).Scan(&device.ID, &device.CreatedAt, &device.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to register device: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE devices
+		SET device_uuid = ?, name = ?, type = ?, public_key = ?, status = ?, approved_at = ?, approved_by = ?,
+		    updated_at = NOW()
+		WHERE id = ? AND user_id = ?
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, deviceUUID, device.Name, device.Type,
+		device.PublicKey, device.Status, utcPtr(device.ApprovedAt), device.ApprovedBy, device.ID, device.UserID).
+		Scan(&device.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sync.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to update device: %w", err)
+	}
+
+	return nil
+}
+
+// ApproveDevice подтверждает устройство пользователя
+func (r *SyncRepository) ApproveDevice(ctx context.Context, userID, deviceID, approvedBy int, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE devices
+		SET status = ?, approved_at = ?, approved_by = ?, updated_at = NOW()
+		WHERE id = ? AND user_id = ?
+	`, sync.DeviceApproved, utc(at), approvedBy, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to approve device: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sync.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// UpdateDeviceSyncTime обновляет время синхронизации устройства
+func (r *SyncRepository) UpdateDeviceSyncTime(ctx context.Context, deviceID int, syncTime time.Time) error {
+	query := `
+		UPDATE devices
+		SET last_sync_time = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, utc(syncTime), deviceID); err != nil {
+		return fmt.Errorf("failed to update device sync time: %w", err)
+	}
+
+	return nil
+}
+
+// AddDeviceUsage прибавляет трафик и операции к счетчикам устройства по его UUID
+func (r *SyncRepository) AddDeviceUsage(ctx context.Context, userID int, deviceUUID string, usage sync.DeviceUsage, at time.Time) error {
+	query := `
+		UPDATE devices
+		SET bytes_uploaded = bytes_uploaded + ?,
+		    bytes_downloaded = bytes_downloaded + ?,
+		    request_count = request_count + ?,
+		    records_uploaded = records_uploaded + ?,
+		    records_downloaded = records_downloaded + ?,
+		    last_sync_time = ?
+		WHERE user_id = ? AND device_uuid = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		usage.BytesUploaded,
+		usage.BytesDownloaded,
+		usage.Requests,
+		usage.RecordsUploaded,
+		usage.RecordsDownloaded,
+		utc(at),
+		userID,
+		deviceUUID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add device usage: %w", err)
+	}
+
+	return nil
+}
+
+// AckDeviceSync сдвигает позицию, до которой устройство получило изменения
+func (r *SyncRepository) AckDeviceSync(ctx context.Context, userID int, deviceUUID string, through sync.Cursor) error {
+	query := `
+		UPDATE devices
+		SET synced_through = ?, synced_through_id = ?
+		WHERE user_id = ? AND device_uuid = ?
+			AND (synced_through IS NULL OR (synced_through, synced_through_id) < (?, ?))
+	`
+
+	at := utc(through.Time)
+	if _, err := r.db.ExecContext(ctx, query, at, through.ID, userID, deviceUUID, at, through.ID); err != nil {
+		return fmt.Errorf("failed to ack device sync: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserDevices возвращает список устройств пользователя
+func (r *SyncRepository) ListUserDevices(ctx context.Context, userID int) ([]*sync.DeviceInfo, error) {
+	query := `SELECT ` + deviceColumns + ` FROM devices WHERE user_id = ? ORDER BY last_sync_time DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []*sync.DeviceInfo
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
+
+// DeleteDevice удаляет устройство
+func (r *SyncRepository) DeleteDevice(ctx context.Context, deviceID int) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM devices WHERE id = ?`, deviceID); err != nil {
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+
+	return nil
+}
+
+// MergeDevices переносит конфликты дубликатов на устройство keepID и удаляет
+// дубликаты. Удаление устройства каскадно удалило бы его конфликты.
+func (r *SyncRepository) MergeDevices(ctx context.Context, keepID int, duplicateIDs []int) error {
+	if len(duplicateIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	in, ids := placeholders(duplicateIDs)
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sync_conflicts SET device_id = ? WHERE device_id IN (`+in+`)`,
+		append([]any{keepID}, ids...)...); err != nil {
+		return fmt.Errorf("failed to move conflicts: %w", err)
+	}
+
+	// Агрегатный MAX пропускает NULL так же, как GREATEST в PostgreSQL
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE devices
+		SET last_sync_time = (
+			SELECT MAX(last_sync_time) FROM devices
+			WHERE id = ? OR id IN (`+in+`)
+		)
+		WHERE id = ?
+	`, append(append([]any{keepID}, ids...), keepID)...); err != nil {
+		return fmt.Errorf("failed to merge sync time: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE devices
+		SET bytes_uploaded = devices.bytes_uploaded + dup.bytes_uploaded,
+		    bytes_downloaded = devices.bytes_downloaded + dup.bytes_downloaded,
+		    request_count = devices.request_count + dup.request_count,
+		    records_uploaded = devices.records_uploaded + dup.records_uploaded,
+		    records_downloaded = devices.records_downloaded + dup.records_downloaded
+		FROM (
+			SELECT COALESCE(SUM(bytes_uploaded), 0) AS bytes_uploaded,
+			       COALESCE(SUM(bytes_downloaded), 0) AS bytes_downloaded,
+			       COALESCE(SUM(request_count), 0) AS request_count,
+			       COALESCE(SUM(records_uploaded), 0) AS records_uploaded,
+			       COALESCE(SUM(records_downloaded), 0) AS records_downloaded
+			FROM devices WHERE id IN (`+in+`)
+		) AS dup
+		WHERE devices.id = ?
+	`, append(append([]any{}, ids...), keepID)...); err != nil {
+		return fmt.Errorf("failed to merge device usage: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM devices WHERE id IN (`+in+`)`, ids...); err != nil {
+		return fmt.Errorf("failed to delete duplicate devices: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+const recordSyncColumns = `
+	id, user_id, type, encrypted_data, meta, version, last_modified,
+	deleted_at, COALESCE(checksum, ''), COALESCE(device_id, ''), COALESCE(uuid, '')`
+
+// GetRecordsForSync возвращает записи для синхронизации.
+// Записи хранилищ организаций зашифрованы ключом организации и в личную синхронизацию не входят.
+func (r *SyncRepository) GetRecordsForSync(ctx context.Context, userID int, after sync.Cursor, limit int, filter sync.Filter) ([]*sync.RecordSync, error) {
+	conditions, filterArgs := filterConditions(filter)
+	query := `
+		SELECT ` + recordSyncColumns + `
+		FROM records
+		WHERE user_id = ?
+			AND org_id IS NULL
+			AND (last_modified, id) > (?, ?)` + conditions + `
+		ORDER BY last_modified ASC, id ASC
+		LIMIT ?
+	`
+
+	args := append([]any{userID, utc(after.Time), after.ID}, filterArgs...)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records for sync: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*sync.RecordSync
+	for rows.Next() {
+		rec, err := scanRecordSync(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan record: %w", err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
+
+// GetRecordByID возвращает запись по ID
+func (r *SyncRepository) GetRecordByID(ctx context.Context, recordID int) (*sync.RecordSync, error) {
+	query := `SELECT ` + recordSyncColumns + ` FROM records WHERE id = ?`
+
+	rec, err := scanRecordSync(r.db.QueryRowContext(ctx, query, recordID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sync.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get record: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetRecordByUUID возвращает личную запись пользователя по UUID
+func (r *SyncRepository) GetRecordByUUID(ctx context.Context, userID int, recordUUID string) (*sync.RecordSync, error) {
+	query := `SELECT ` + recordSyncColumns + ` FROM records WHERE user_id = ? AND uuid = ? AND org_id IS NULL`
+
+	rec, err := scanRecordSync(r.db.QueryRowContext(ctx, query, userID, recordUUID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sync.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get record by uuid: %w", err)
+	}
+
+	return rec, nil
+}
+
+// GetRecordIndex возвращает версии и контрольные суммы личных записей пользователя,
+// включая удаленные
+func (r *SyncRepository) GetRecordIndex(ctx context.Context, userID int, filter sync.Filter) ([]*sync.RecordDigest, error) {
+	conditions, args := filterConditions(filter)
+	query := `
+		SELECT id, COALESCE(uuid, ''), version, COALESCE(checksum, ''), deleted_at IS NOT NULL
+		FROM records
+		WHERE user_id = ? AND org_id IS NULL` + conditions + `
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append([]any{userID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query record index: %w", err)
+	}
+	defer rows.Close()
+
+	var index []*sync.RecordDigest
+	for rows.Next() {
+		var d sync.RecordDigest
+		if err := rows.Scan(&d.ID, &d.UUID, &d.Version, &d.Checksum, &d.Deleted); err != nil {
+			return nil, fmt.Errorf("failed to scan record digest: %w", err)
+		}
+		index = append(index, &d)
+	}
+
+	return index, rows.Err()
+}
+
+// filterConditions возвращает условия выборочной синхронизации для WHERE.
+// Теги из meta разворачиваются json_each; у записи без тегов строк нет.
+func filterConditions(filter sync.Filter) (string, []any) {
+	var conditions string
+	var args []any
+
+	if len(filter.Types) > 0 {
+		marks, values := placeholders(filter.Types)
+		conditions += " AND type IN (" + marks + ")"
+		args = append(args, values...)
+	}
+	if len(filter.Tags) > 0 {
+		marks, values := placeholders(filter.Tags)
+		conditions += " AND EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(meta) THEN meta END, '$.tags') WHERE value IN (" + marks + "))"
+		args = append(args, values...)
+	}
+	if len(filter.ExcludeTags) > 0 {
+		marks, values := placeholders(filter.ExcludeTags)
+		conditions += " AND NOT EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(meta) THEN meta END, '$.tags') WHERE value IN (" + marks + "))"
+		args = append(args, values...)
+	}
+
+	return conditions, args
+}
+
+// GetRecordVersions возвращает версии записи из record_versions
+func (r *SyncRepository) GetRecordVersions(ctx context.Context, recordID int, limit int) ([]*sync.RecordSync, error) {
+	query := `
+		SELECT rv.id, r.user_id, r.type, rv.encrypted_data, rv.meta, rv.version,
+		       rv.created_at, NULL, COALESCE(rv.checksum, ''), COALESCE(r.device_id, ''),
+		       COALESCE(r.uuid, '')
+		FROM record_versions rv
+		JOIN records r ON rv.record_id = r.id
+		WHERE rv.record_id = ?
+		ORDER BY rv.version DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, recordID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query record versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*sync.RecordSync
+	for rows.Next() {
+		rec, err := scanRecordSync(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan record version: %w", err)
+		}
+		versions = append(versions, rec)
+	}
+
+	return versions, rows.Err()
+}
+
+// upsertRecordQuery вставляет запись или обновляет совпадающую по данным,
+// если пришла более новая версия
+const upsertRecordQuery = `
+	INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id, uuid, last_modified)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	ON CONFLICT (user_id, type, encrypted_data)
+	WHERE deleted_at IS NULL
+	DO UPDATE SET
+		meta = excluded.meta,
+		version = excluded.version,
+		checksum = excluded.checksum,
+		device_id = excluded.device_id,
+		last_modified = NOW()
+	WHERE records.version < excluded.version`
+
+// SaveRecord сохраняет запись
+func (r *SyncRepository) SaveRecord(ctx context.Context, record *sync.RecordSync) error {
+	data, err := hex.DecodeString(record.EncryptedData)
+	if err != nil {
+		return fmt.Errorf("failed to decode encrypted data: %w", err)
+	}
+
+	if record.UUID == "" {
+		record.UUID = uuid.NewString()
+	}
+
+	err = r.db.QueryRowContext(ctx, upsertRecordQuery+` RETURNING id, version, last_modified, uuid`,
+		record.UserID,
+		record.Type,
+		data,
+		metaText(record.Meta),
+		record.Version,
+		record.Checksum,
+		record.DeviceID,
+		record.UUID,
+	).Scan(&record.ID, &record.Version, &record.LastModified, &record.UUID)
+
+	if err != nil {
+		return fmt.Errorf("failed to save record: %w", err)
+	}
+
+	return nil
+}
+
+const conflictColumns = `
+	id, record_id, user_id, device_id, local_data, server_data,
+	conflict_type, resolved, COALESCE(resolution, ''), resolved_at, created_at, updated_at,
+	record_type, title, local_version, server_version, local_modified, server_modified`
+
+// GetSyncConflicts возвращает конфликты синхронизации
+func (r *SyncRepository) GetSyncConflicts(ctx context.Context, userID int) ([]*sync.Conflict, error) {
+	query := `
+		SELECT ` + conflictColumns + `
+		FROM sync_conflicts
+		WHERE user_id = ? AND NOT resolved
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []*sync.Conflict
+	for rows.Next() {
+		conflict, err := scanConflict(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conflict: %w", err)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts, rows.Err()
+}
+
+// GetConflictByID возвращает конфликт по ID
+func (r *SyncRepository) GetConflictByID(ctx context.Context, conflictID int) (*sync.Conflict, error) {
+	query := `SELECT ` + conflictColumns + ` FROM sync_conflicts WHERE id = ?`
+
+	conflict, err := scanConflict(r.db.QueryRowContext(ctx, query, conflictID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sync.ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("failed to get conflict: %w", err)
+	}
+
+	return conflict, nil
+}
+
+// SaveConflict сохраняет конфликт
+func (r *SyncRepository) SaveConflict(ctx context.Context, conflict *sync.Conflict) error {
+	query := `
+		INSERT INTO sync_conflicts
+			(record_id, user_id, device_id, local_data, server_data,
+			 conflict_type, resolved, resolution, resolved_at, created_at, updated_at,
+			 record_type, title, local_version, server_version, local_modified, server_modified)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		conflict.RecordID,
+		conflict.UserID,
+		conflict.DeviceID,
+		conflict.LocalData,
+		conflict.ServerData,
+		conflict.ConflictType,
+		conflict.Resolved,
+		conflict.Resolution,
+		nullTime(conflict.ResolvedAt),
+		utc(conflict.CreatedAt),
+		utc(conflict.UpdatedAt),
+		conflict.RecordType,
+		conflict.Title,
+		conflict.LocalVersion,
+		conflict.ServerVersion,
+		nullTime(conflict.LocalModified),
+		nullTime(conflict.ServerModified),
+	).Scan(&conflict.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to save conflict: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveConflict разрешает конфликт
+func (r *SyncRepository) ResolveConflict(ctx context.Context, conflictID int, resolution string, resolvedData []byte) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Получаем информацию о конфликте
+	var recordID int
+	var userID int
+	var conflictType string
+	err = tx.QueryRowContext(ctx, `
+		SELECT record_id, user_id, conflict_type
+		FROM sync_conflicts
+		WHERE id = ?
+	`, conflictID).Scan(&recordID, &userID, &conflictType)
+	if err != nil {
+		return fmt.Errorf("failed to get conflict info: %w", err)
+	}
+
+	// Обновляем запись, если нужно
+	if conflictType == "version_mismatch" && resolvedData != nil {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE records
+			SET encrypted_data = ?, version = version + 1, last_modified = NOW()
+			WHERE id = ? AND user_id = ?
+		`, resolvedData, recordID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update record: %w", err)
+		}
+	}
+
+	// Помечаем конфликт как разрешенный
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sync_conflicts
+		SET resolved = TRUE, resolution = ?, resolved_at = NOW(), updated_at = NOW()
+		WHERE id = ?
+	`, resolution, conflictID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve conflict: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// BatchUpsertRecords массовое обновление/вставка записей
+func (r *SyncRepository) BatchUpsertRecords(ctx context.Context, records []*sync.RecordSync) (int, []int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var processed int
+	var failed []int
+
+	// В отличие от PostgreSQL, ошибка оператора в SQLite откатывает только
+	// сам оператор, и точки сохранения не нужны
+	for i, rec := range records {
+		if err := upsertRecordSync(ctx, tx, rec); err != nil {
+			r.log.Warn("failed to upsert record", "record_id", rec.ID, "index", i, "error", err)
+			failed = append(failed, i)
+			continue
+		}
+		processed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, allIndexes(records), fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return processed, failed, nil
+}
+
+// Перед перезаписью синхронизацией прежнее состояние записи сохраняется в
+// record_versions, как при обновлении через API записей: по этой истории
+// клиент находит общего предка при слиянии конфликта
+const (
+	snapshotRecordByIDQuery = `
+		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum, created_at)
+		SELECT id, version, encrypted_data, meta, checksum, NOW()
+		FROM records
+		WHERE id = ? AND user_id = ? AND org_id IS NULL AND version < ?
+		ON CONFLICT (record_id, version) DO NOTHING`
+	snapshotRecordByDataQuery = `
+		INSERT INTO record_versions (record_id, version, encrypted_data, meta, checksum, created_at)
+		SELECT id, version, encrypted_data, meta, checksum, NOW()
+		FROM records
+		WHERE user_id = ? AND type = ? AND encrypted_data = ? AND deleted_at IS NULL AND version < ?
+		ON CONFLICT (record_id, version) DO NOTHING`
+)
+
+// upsertRecordSync обновляет запись по ID, если пришла более новая версия.
+// Новую запись и запись, которой на сервере уже нет, вставляет; совпадающая
+// по данным запись при этом обновляется, как в SaveRecord.
+func upsertRecordSync(ctx context.Context, tx *sql.Tx, rec *sync.RecordSync) error {
+	data, err := hex.DecodeString(rec.EncryptedData)
+	if err != nil {
+		return fmt.Errorf("failed to decode encrypted data: %w", err)
+	}
+
+	if rec.ID > 0 {
+		if _, err := tx.ExecContext(ctx, snapshotRecordByIDQuery, rec.ID, rec.UserID, rec.Version); err != nil {
+			return fmt.Errorf("failed to save record version: %w", err)
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			UPDATE records
+			SET type = ?3, encrypted_data = ?4, meta = ?5, version = ?6, checksum = NULLIF(?7, ''),
+			    device_id = NULLIF(?8, ''), deleted_at = ?9, last_modified = NOW()
+			WHERE id = ?1 AND user_id = ?2 AND org_id IS NULL AND version < ?6
+			RETURNING id, version, last_modified, COALESCE(uuid, '')`,
+			rec.ID, rec.UserID, rec.Type, data, metaText(rec.Meta), rec.Version,
+			rec.Checksum, rec.DeviceID, utcPtr(rec.DeletedAt),
+		).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM records WHERE id = ?)`, rec.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("record %d: %w", rec.ID, sync.ErrVersionNotNewer)
+		}
+	}
+
+	if rec.UUID == "" {
+		rec.UUID = uuid.NewString()
+	}
+
+	if _, err := tx.ExecContext(ctx, snapshotRecordByDataQuery, rec.UserID, rec.Type, data, rec.Version); err != nil {
+		return fmt.Errorf("failed to save record version: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO records (user_id, type, encrypted_data, meta, version, checksum, device_id, deleted_at, uuid, last_modified)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+		ON CONFLICT (user_id, type, encrypted_data)
+		WHERE deleted_at IS NULL
+		DO UPDATE SET
+			meta = excluded.meta,
+			version = excluded.version,
+			checksum = excluded.checksum,
+			device_id = excluded.device_id,
+			last_modified = NOW()
+		WHERE records.version < excluded.version
+		RETURNING id, version, last_modified, uuid`,
+		rec.UserID, rec.Type, data, metaText(rec.Meta), rec.Version,
+		rec.Checksum, rec.DeviceID, utcPtr(rec.DeletedAt), rec.UUID,
+	).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Те же данные с не меньшей версией уже есть на сервере
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, version, last_modified, COALESCE(uuid, '')
+			FROM records
+			WHERE user_id = ? AND type = ? AND encrypted_data = ? AND deleted_at IS NULL`,
+			rec.UserID, rec.Type, data,
+		).Scan(&rec.ID, &rec.Version, &rec.LastModified, &rec.UUID)
+	}
+
+	return err
+}
+
+func allIndexes(records []*sync.RecordSync) []int {
+	indexes := make([]int, len(records))
+	for i := range records {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// BatchDeleteRecords массовое удаление записей (soft delete)
+func (r *SyncRepository) BatchDeleteRecords(ctx context.Context, recordIDs []int, userID int) error {
+	if len(recordIDs) == 0 {
+		return nil
+	}
+
+	in, ids := placeholders(recordIDs)
+	query := `
+		UPDATE records
+		SET deleted_at = NOW(), last_modified = NOW()
+		WHERE user_id = ? AND org_id IS NULL AND id IN (` + in + `)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, append([]any{userID}, ids...)...); err != nil {
+		return fmt.Errorf("failed to batch delete records: %w", err)
+	}
+
+	return nil
+}
+
+// GetSyncStats возвращает статистику синхронизации (заглушка)
+func (r *SyncRepository) GetSyncStats(_ context.Context, userID int) (*sync.Stats, error) {
+	return &sync.Stats{
+		UserID:    userID,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// IncrementSyncStats увеличивает статистику синхронизации (заглушка)
+func (r *SyncRepository) IncrementSyncStats(_ context.Context, userID int, _, _ int64) error {
+	r.log.Debug("IncrementSyncStats called but sync stats are not stored", "user_id", userID)
+	return nil
+}
+
+// RecordSyncDuration записывает время синхронизации (заглушка)
+func (r *SyncRepository) RecordSyncDuration(_ context.Context, userID int, _ time.Duration) error {
+	r.log.Debug("RecordSyncDuration called but sync stats are not stored", "user_id", userID)
+	return nil
+}
+
+// Вспомогательные методы
+
+// nullTime записывает нулевое время как NULL
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// scanRecordSync сканирует RecordSync из row
+func scanRecordSync(row interface {
+	Scan(dest ...interface{}) error
+}) (*sync.RecordSync, error) {
+	var rec sync.RecordSync
+	var data []byte
+	var meta string
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&rec.ID,
+		&rec.UserID,
+		&rec.Type,
+		&data,
+		&meta,
+		&rec.Version,
+		&rec.LastModified,
+		&deletedAt,
+		&rec.Checksum,
+		&rec.DeviceID,
+		&rec.UUID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.EncryptedData = hex.EncodeToString(data)
+	rec.Meta = []byte(meta)
+	if deletedAt.Valid {
+		rec.DeletedAt = &deletedAt.Time
+	}
+
+	return &rec, nil
+}
+
+// scanDevice сканирует DeviceInfo из row со столбцами deviceColumns
+func scanDevice(row interface {
+	Scan(dest ...interface{}) error
+}) (*sync.DeviceInfo, error) {
+	var device sync.DeviceInfo
+	var lastSyncTime, approvedAt sql.NullTime
+	var approvedBy sql.NullInt64
+
+	err := row.Scan(
+		&device.ID,
+		&device.UserID,
+		&device.UUID,
+		&device.Name,
+		&device.Type,
+		&lastSyncTime,
+		&device.CreatedAt,
+		&device.UpdatedAt,
+		&device.IPAddress,
+		&device.UserAgent,
+		&device.Usage.BytesUploaded,
+		&device.Usage.BytesDownloaded,
+		&device.Usage.Requests,
+		&device.Usage.RecordsUploaded,
+		&device.Usage.RecordsDownloaded,
+		&device.PublicKey,
+		&device.Status,
+		&approvedAt,
+		&approvedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSyncTime.Valid {
+		device.LastSyncTime = lastSyncTime.Time
+	}
+	if approvedAt.Valid {
+		device.ApprovedAt = &approvedAt.Time
+	}
+	if approvedBy.Valid {
+		id := int(approvedBy.Int64)
+		device.ApprovedBy = &id
+	}
+
+	return &device, nil
+}
+
+func scanConflict(row interface {
+	Scan(dest ...interface{}) error
+}) (*sync.Conflict, error) {
+	var conflict sync.Conflict
+	var resolvedAt, localModified, serverModified sql.NullTime
+
+	err := row.Scan(
+		&conflict.ID,
+		&conflict.RecordID,
+		&conflict.UserID,
+		&conflict.DeviceID,
+		&conflict.LocalData,
+		&conflict.ServerData,
+		&conflict.ConflictType,
+		&conflict.Resolved,
+		&conflict.Resolution,
+		&resolvedAt,
+		&conflict.CreatedAt,
+		&conflict.UpdatedAt,
+		&conflict.RecordType,
+		&conflict.Title,
+		&conflict.LocalVersion,
+		&conflict.ServerVersion,
+		&localModified,
+		&serverModified,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolvedAt.Valid {
+		conflict.ResolvedAt = resolvedAt.Time
+	}
+	if localModified.Valid {
+		conflict.LocalModified = localModified.Time
+	}
+	if serverModified.Valid {
+		conflict.ServerModified = serverModified.Time
+	}
+
+	return &conflict, nil
+}
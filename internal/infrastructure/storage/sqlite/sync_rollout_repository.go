@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gophkeeper/internal/domain/sync"
+
+	"golang.org/x/exp/slog"
+)
+
+// SyncRolloutRepository реализует sync.RolloutRepository для SQLite
+type SyncRolloutRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewSyncRolloutRepository создает новый репозиторий протоколов синхронизации
+func NewSyncRolloutRepository(db *sql.DB, log *slog.Logger) *SyncRolloutRepository {
+	return &SyncRolloutRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetProtocol возвращает протокол, назначенный пользователю
+func (r *SyncRolloutRepository) GetProtocol(ctx context.Context, userID int) (sync.Protocol, bool, error) {
+	var p sync.Protocol
+	err := r.db.QueryRowContext(ctx,
+		`SELECT protocol FROM user_sync_protocols WHERE user_id = ?`,
+		userID).Scan(&p)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		r.log.Error("failed to get sync protocol", "user_id", userID, "error", err)
+		return "", false, fmt.Errorf("failed to get sync protocol: %w", err)
+	}
+
+	return p, true, nil
+}
+
+// SetProtocol назначает протокол пользователю
+func (r *SyncRolloutRepository) SetProtocol(ctx context.Context, userID int, p sync.Protocol) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_sync_protocols (user_id, protocol, updated_at)
+		SELECT id, ?2, NOW() FROM users WHERE id = ?1
+		ON CONFLICT (user_id) DO UPDATE SET
+			protocol = excluded.protocol,
+			updated_at = excluded.updated_at`,
+		userID, string(p))
+	if err != nil {
+		r.log.Error("failed to set sync protocol", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to set sync protocol: %w", err)
+	}
+
+	return requireAffected(result, sync.ErrUserNotFound)
+}
+
+// DeleteProtocol удаляет назначенный пользователю протокол
+func (r *SyncRolloutRepository) DeleteProtocol(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_sync_protocols WHERE user_id = ?`, userID)
+	if err != nil {
+		r.log.Error("failed to delete sync protocol", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete sync protocol: %w", err)
+	}
+
+	return nil
+}
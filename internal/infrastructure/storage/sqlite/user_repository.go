@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gophkeeper/internal/domain/user"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/exp/slog"
+)
+
+const userColumns = `id, login, password_hash, role, disabled_at, delete_after, created_at`
+
+// UserRepository реализует user.Repository для SQLite
+type UserRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewUserRepository(db *sql.DB, log *slog.Logger) *UserRepository {
+	return &UserRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+func (r *UserRepository) Create(ctx context.Context, login, passwordHash string) (int, error) {
+	var userID int
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO users (login, password_hash) VALUES (?, ?) RETURNING id`,
+		login, passwordHash).Scan(&userID)
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return 0, user.ErrLoginTaken
+	}
+	return userID, err
+}
+
+func (r *UserRepository) FindByLogin(ctx context.Context, login string) (user.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE login = ?`, login))
+	if err != nil {
+		return u, fmt.Errorf("user not found")
+	}
+
+	return u, nil
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id int) (user.User, error) {
+	u, err := scanUser(r.db.QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	if err != nil {
+		return user.User{ID: id}, fmt.Errorf("user not found")
+	}
+
+	return u, nil
+}
+
+func (r *UserRepository) List(ctx context.Context) ([]user.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []user.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepository) SetDisabled(ctx context.Context, id int, at *time.Time) error {
+	return r.update(ctx, `UPDATE users SET disabled_at = ? WHERE id = ?`, utcPtr(at), id)
+}
+
+func (r *UserRepository) SetRole(ctx context.Context, id int, role string) error {
+	return r.update(ctx, `UPDATE users SET role = ? WHERE id = ?`, role, id)
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	return r.update(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, id)
+}
+
+func (r *UserRepository) SetDeleteAfter(ctx context.Context, id int, at *time.Time) error {
+	return r.update(ctx, `UPDATE users SET delete_after = ? WHERE id = ?`, utcPtr(at), id)
+}
+
+func (r *UserRepository) ListDeleteDue(ctx context.Context, before time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id FROM users WHERE delete_after IS NOT NULL AND delete_after <= ? ORDER BY id`, utc(before))
+	if err != nil {
+		return nil, fmt.Errorf("list users due for deletion: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+	return requireAffected(result, user.ErrNotFound)
+}
+
+func (r *UserRepository) update(ctx context.Context, query string, value any, id int) error {
+	result, err := r.db.ExecContext(ctx, query, value, id)
+	if err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+	return requireAffected(result, user.ErrNotFound)
+}
+
+func scanUser(row interface{ Scan(dest ...any) error }) (user.User, error) {
+	var u user.User
+	err := row.Scan(&u.ID, &u.Login, &u.Password, &u.Role, &u.DisabledAt, &u.DeleteAfter, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return u, user.ErrNotFound
+	}
+	return u, err
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"gophkeeper/internal/domain/viewer"
+)
+
+// ViewerRepository реализует viewer.Repository для SQLite
+type ViewerRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+func NewViewerRepository(db *sql.DB, log *slog.Logger) *ViewerRepository {
+	return &ViewerRepository{
+		db:  db,
+		log: log.With("component", "viewer_repository"),
+	}
+}
+
+const viewerTokenColumns = `id, user_id, name, folder_ids, expires_at, created_at, last_used_at`
+
+func (r *ViewerRepository) Create(ctx context.Context, token *viewer.Token, secretHash string) error {
+	folders, err := json.Marshal(token.FolderIDs)
+	if err != nil {
+		return fmt.Errorf("marshal folder ids: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO viewer_tokens (user_id, name, token_hash, folder_ids, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		token.UserID, token.Name, secretHash, string(folders), utc(token.ExpiresAt), utc(token.CreatedAt),
+	).Scan(&token.ID)
+	if err != nil {
+		r.log.Error("failed to create viewer token", "user_id", token.UserID, "error", err)
+		return fmt.Errorf("insert viewer token: %w", err)
+	}
+	return nil
+}
+
+func (r *ViewerRepository) List(ctx context.Context, userID int) ([]viewer.Token, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+viewerTokenColumns+`
+		FROM viewer_tokens
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		r.log.Error("failed to list viewer tokens", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("list viewer tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []viewer.Token
+	for rows.Next() {
+		token, err := scanViewerToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan viewer token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *ViewerRepository) GetByHash(ctx context.Context, secretHash string) (*viewer.Token, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+viewerTokenColumns+`
+		FROM viewer_tokens
+		WHERE token_hash = ?`, secretHash)
+
+	token, err := scanViewerToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, viewer.ErrNotFound
+		}
+		return nil, fmt.Errorf("get viewer token: %w", err)
+	}
+	return token, nil
+}
+
+func (r *ViewerRepository) Delete(ctx context.Context, userID, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM viewer_tokens WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		r.log.Error("failed to delete viewer token", "token_id", id, "error", err)
+		return fmt.Errorf("delete viewer token: %w", err)
+	}
+
+	return requireAffected(result, viewer.ErrNotFound)
+}
+
+func (r *ViewerRepository) Touch(ctx context.Context, id int, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE viewer_tokens SET last_used_at = ? WHERE id = ?`, utc(at), id); err != nil {
+		return fmt.Errorf("touch viewer token: %w", err)
+	}
+	return nil
+}
+
+func scanViewerToken(row interface{ Scan(dest ...any) error }) (*viewer.Token, error) {
+	var (
+		token   viewer.Token
+		folders string
+	)
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &folders,
+		&token.ExpiresAt, &token.CreatedAt, &token.LastUsedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(folders), &token.FolderIDs); err != nil {
+		return nil, fmt.Errorf("unmarshal folder ids: %w", err)
+	}
+	return &token, nil
+}
@@ -1,35 +1,46 @@
+// Package storage описывает набор репозиториев сервера, не зависящий от
+// драйвера базы. Реализации находятся в подпакетах postgres и sqlite.
 package storage
 
 import (
-	"context"
-	"encoding/json"
-	"time"
+	"gophkeeper/internal/app/server/backup"
+	"gophkeeper/internal/domain/blob"
+	"gophkeeper/internal/domain/folder"
+	"gophkeeper/internal/domain/keyfile"
+	"gophkeeper/internal/domain/membership"
+	"gophkeeper/internal/domain/mfa"
+	"gophkeeper/internal/domain/org"
+	"gophkeeper/internal/domain/quota"
+	"gophkeeper/internal/domain/record"
+	"gophkeeper/internal/domain/secretlink"
+	"gophkeeper/internal/domain/session"
+	"gophkeeper/internal/domain/settings"
+	"gophkeeper/internal/domain/stats"
+	"gophkeeper/internal/domain/sync"
+	"gophkeeper/internal/domain/user"
+	"gophkeeper/internal/domain/viewer"
 )
 
-type Record struct {
-	ID            int             `json:"id"`
-	Type          string          `json:"type"`
-	EncryptedData string          `json:"data"` // base64
-	Meta          json.RawMessage `json:"meta"`
-	Version       int             `json:"version"`
-	LastModified  time.Time       `json:"last_modified"`
-}
-
-type Storage interface {
-	// Пользователи
-	CreateUser(ctx context.Context, login, passwordHash string) (int, error)
-	AuthUser(ctx context.Context, login, password string) (int, string, error)
-
-	// Сессии
-	CreateSession(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
-	ValidateSession(ctx context.Context, tokenHash string) (int, error)
-
-	// Записи
-	ListRecords(ctx context.Context, userID int) ([]Record, error)
-	CreateRecord(ctx context.Context, userID int, typ, encryptedData string, meta json.RawMessage) (int, error)
-	GetRecord(ctx context.Context, userID, recordID int) (*Record, error)
-	UpdateRecord(ctx context.Context, userID, recordID int, typ, encryptedData string, meta json.RawMessage) error
-	DeleteRecord(ctx context.Context, userID, recordID int) error
+// Repositories - репозитории сервера поверх одной базы
+type Repositories struct {
+	Users       user.Repository
+	Sessions    session.Repository
+	Records     record.Repository
+	Sync        sync.Repository
+	Settings    settings.Repository
+	Orgs        org.Repository
+	Memberships membership.Repository
+	Quotas      quota.Repository
+	MFA         mfa.Repository
+	Backups     backup.Repository
+	Blobs       blob.Repository
+	Folders     folder.Repository
+	SyncRollout sync.RolloutRepository
+	KeyFiles    keyfile.Repository
+	Viewers     viewer.Repository
+	SecretLinks secretlink.Repository
+	Stats       stats.Repository
 
-	Close() error
+	// Close закрывает соединения с базой
+	Close func()
 }
@@ -2,43 +2,52 @@ package logger
 
 import (
 	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/utils/logger/sl"
 	"gophkeeper/internal/utils/slogpretty"
+	"io"
 	"os"
 
 	"golang.org/x/exp/slog"
 )
 
 func New(env string) *slog.Logger {
+	return NewTo(env, os.Stdout)
+}
+
+// NewTo создает логгер, пишущий в w. Записи с контекстом HTTP-запроса
+// получают его request_id, секреты (токены, пароли, данные записей)
+// заменяются на sl.Redacted.
+func NewTo(env string, w io.Writer) *slog.Logger {
 	var log *slog.Logger
 
 	switch env {
 	case config.EnvLocal:
-		log = setupPrettySlog()
+		log = setupPrettySlog(w)
 	case config.EnvDev:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}),
 		)
 	case config.EnvProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}),
 		)
 	default: // If env config is invalid, set prod settings by default due to security
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo}),
 		)
 	}
 
-	return log
+	return slog.New(sl.NewContextHandler(sl.NewRedactHandler(log.Handler())))
 }
 
-func setupPrettySlog() *slog.Logger {
+func setupPrettySlog(w io.Writer) *slog.Logger {
 	opts := slogpretty.PrettyHandlerOptions{
 		SlogOpts: &slog.HandlerOptions{
 			Level: slog.LevelDebug,
 		},
 	}
 
-	handler := opts.NewPrettyHandler(os.Stdout)
+	handler := opts.NewPrettyHandler(w)
 
 	return slog.New(handler)
 }
@@ -1,10 +1,14 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
 	"testing"
 
 	"gophkeeper/internal/app/server/config"
+	"gophkeeper/internal/utils/logger/sl"
 
 	"golang.org/x/exp/slog"
 
@@ -45,7 +49,7 @@ func TestNew(t *testing.T) {
 }
 
 func TestSetupPrettySlog(t *testing.T) {
-	logger := setupPrettySlog()
+	logger := setupPrettySlog(io.Discard)
 	require.NotNil(t, logger)
 
 	ctx := context.Background()
@@ -69,3 +73,44 @@ func TestNewLoggerLevels(t *testing.T) {
 	localLogger := New(config.EnvLocal)
 	assert.True(t, localLogger.Enabled(ctx, slog.LevelDebug))
 }
+
+func TestNewTo(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewTo(config.EnvProd, &buf)
+
+	logger.Info("to writer", "key", "value")
+	assert.Contains(t, buf.String(), `"msg":"to writer"`)
+	assert.Contains(t, buf.String(), `"key":"value"`)
+}
+
+func TestNewTo_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewTo(config.EnvProd, &buf)
+
+	logger.InfoContext(sl.WithRequestID(context.Background(), "req-1"), "with request")
+	logger.With("component", "test").InfoContext(context.Background(), "without request")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"request_id":"req-1"`)
+	assert.NotContains(t, lines[1], "request_id")
+}
+
+func TestNewTo_Redacts(t *testing.T) {
+	for _, env := range []string{config.EnvProd, config.EnvLocal} {
+		t.Run(env, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := NewTo(env, &buf).With("token", "token-secret")
+
+			logger.Info("response", "status", 200, "body", `{"access_token":"body-secret","user_id":7}`)
+			logger.Warn("login failed", "password", "password-secret", "login", "alice")
+
+			out := buf.String()
+			for _, secret := range []string{"token-secret", "body-secret", "password-secret"} {
+				assert.NotContains(t, out, secret)
+			}
+			assert.Contains(t, out, "alice")
+			assert.Contains(t, out, sl.Redacted)
+		})
+	}
+}
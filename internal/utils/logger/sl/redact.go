@@ -0,0 +1,262 @@
+package sl
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// Redacted заменяет в журнале значения секретов
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys - ключи атрибутов и полей JSON, значения которых не пишутся
+// в журнал. Ключи сравниваются без учета регистра, '-' равен '_'.
+var sensitiveKeys = map[string]bool{
+	"authorization":  true,
+	"cookie":         true,
+	"set_cookie":     true,
+	"x_api_key":      true,
+	"api_key":        true,
+	"cvv":            true,
+	"pin":            true,
+	"card_number":    true,
+	"number":         true,
+	"recovery_codes": true,
+	"private_key":    true,
+	"master_key":     true,
+	"encrypted_key":  true,
+	"data":           true,
+	"encrypted_data": true,
+	// значение без ключа: log.Debug("token", token)
+	"!badkey": true,
+}
+
+// sensitiveSuffixes - окончания ключей секретов: access_token, new_password
+var sensitiveSuffixes = []string{"token", "password", "passphrase", "secret"}
+
+var (
+	// bearerPattern - учетные данные в заголовке Authorization
+	bearerPattern = regexp.MustCompile(`(?i)\b(Bearer|Basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	// jsonFieldPattern - строковые и числовые поля JSON, например тела ответа
+	jsonFieldPattern = regexp.MustCompile(`"([A-Za-z0-9_\-]+)"\s*:\s*("(?:[^"\\]|\\.)*"|-?\d+(?:\.\d+)?)`)
+	// jsonNestedPattern - начало поля JSON со значением-массивом или объектом
+	jsonNestedPattern = regexp.MustCompile(`"([A-Za-z0-9_\-]+)"\s*:\s*[\[{]`)
+	// queryParamPattern - параметры URL вида ?token=...
+	queryParamPattern = regexp.MustCompile(`([?&])([A-Za-z0-9_\-]+)=([^&#\s"]*)`)
+)
+
+// IsSensitiveKey сообщает, что значение с ключом key - секрет
+func IsSensitiveKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString скрывает секреты внутри строки: токены Bearer и Basic,
+// значения секретных полей JSON и параметров URL
+func RedactString(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		s = bearerPattern.ReplaceAllString(s, "$1 "+Redacted)
+	}
+	if strings.Contains(s, `"`) {
+		s = redactJSONNested(s)
+		s = jsonFieldPattern.ReplaceAllStringFunc(s, func(field string) string {
+			m := jsonFieldPattern.FindStringSubmatch(field)
+			if !IsSensitiveKey(m[1]) || m[2] == `""` {
+				return field
+			}
+			return `"` + m[1] + `":"` + Redacted + `"`
+		})
+	}
+	if strings.Contains(s, "=") {
+		s = queryParamPattern.ReplaceAllStringFunc(s, func(param string) string {
+			m := queryParamPattern.FindStringSubmatch(param)
+			if !IsSensitiveKey(m[2]) || m[3] == "" {
+				return param
+			}
+			return m[1] + m[2] + "=" + Redacted
+		})
+	}
+	return s
+}
+
+// redactJSONNested заменяет целиком массивы и объекты JSON под секретными
+// ключами: "recovery_codes":["..."] становится "recovery_codes":"[REDACTED]".
+// Обрезанное значение без закрывающей скобки скрывается до конца строки.
+func redactJSONNested(s string) string {
+	var out strings.Builder
+	for {
+		loc := jsonNestedPattern.FindStringSubmatchIndex(s)
+		if loc == nil {
+			out.WriteString(s)
+			return out.String()
+		}
+		key := s[loc[2]:loc[3]]
+		if !IsSensitiveKey(key) {
+			// Вложенные поля обычного объекта проверяются дальше
+			out.WriteString(s[:loc[1]])
+			s = s[loc[1]:]
+			continue
+		}
+		out.WriteString(s[:loc[0]])
+		out.WriteString(`"` + key + `":"` + Redacted + `"`)
+		s = s[jsonValueEnd(s, loc[1]-1):]
+	}
+}
+
+// jsonValueEnd возвращает позицию сразу за массивом или объектом JSON,
+// который начинается в s[start], с учетом вложенности и скобок в строках
+func jsonValueEnd(s string, start int) int {
+	depth := 0
+	inString := false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// Redact возвращает атрибут без секретов. Значения секретных ключей
+// заменяются целиком (пустые остаются пустыми: видно, что значения нет),
+// в остальных строках, ошибках и группах секреты ищутся по содержимому.
+func Redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+
+	if IsSensitiveKey(a.Key) {
+		if a.Value.Kind() == slog.KindString && a.Value.String() == "" {
+			return a
+		}
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactString(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			attrs = append(attrs, Redact(ga))
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case http.Header:
+			a.Value = slog.AnyValue(redactHeader(v))
+		case error:
+			a.Value = slog.StringValue(RedactString(v.Error()))
+		case []byte:
+			a.Value = slog.StringValue(RedactString(string(v)))
+		case map[string]interface{}:
+			a.Value = slog.AnyValue(redactMap(v))
+		case []interface{}:
+			a.Value = slog.AnyValue(redactSlice(v))
+		}
+	}
+	return a
+}
+
+// redactMap возвращает копию разобранного JSON-объекта, в которой значения
+// секретных ключей, включая массивы и объекты, заменены на Redacted
+func redactMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if IsSensitiveKey(key) && value != nil && value != "" {
+			out[key] = Redacted
+			continue
+		}
+		out[key] = redactAny(value)
+	}
+	return out
+}
+
+func redactSlice(s []interface{}) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, value := range s {
+		out[i] = redactAny(value)
+	}
+	return out
+}
+
+func redactAny(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactMap(v)
+	case []interface{}:
+		return redactSlice(v)
+	case string:
+		return RedactString(v)
+	default:
+		return v
+	}
+}
+
+// redactHeader возвращает копию заголовков со скрытыми учетными данными
+func redactHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for key, values := range h {
+		if IsSensitiveKey(key) {
+			out[key] = []string{Redacted}
+			continue
+		}
+		out[key] = values
+	}
+	return out
+}
+
+// RedactHandler убирает секреты из записей журнала до того, как их
+// обработает h: атрибуты проходят через Redact, сообщение - через
+// RedactString. Ставится перед любым обработчиком вывода, поэтому защищает
+// и JSON, и форматированный вывод.
+type RedactHandler struct {
+	slog.Handler
+}
+
+// NewRedactHandler оборачивает h
+func NewRedactHandler(h slog.Handler) *RedactHandler {
+	return &RedactHandler{Handler: h}
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(Redact(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, Redact(a))
+	}
+	return &RedactHandler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{Handler: h.Handler.WithGroup(name)}
+}
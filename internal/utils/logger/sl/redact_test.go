@@ -0,0 +1,127 @@
+package sl
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+)
+
+// secretValue - LogValuer, который сам раскрывает секрет
+type secretValue struct{}
+
+func (secretValue) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("password", "valuer-secret"), slog.String("login", "alice"))
+}
+
+func TestRedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	// Время записи не выводится: в нем могут встретиться проверяемые цифры
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}}
+	log := slog.New(NewRedactHandler(slog.NewJSONHandler(&buf, opts)))
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer header-secret")
+	header.Set("Content-Type", "application/json")
+
+	log.With("access_token", "with-secret").WithGroup("req").Debug("Bearer message-secret",
+		"Authorization", "Bearer attr-secret",
+		"new-password", "password-secret",
+		"empty_token", "",
+		"body", `{"id":1,"token":"body-secret","data":"payload-secret","cvv":123,"title":"ok"}`,
+		"url", "https://example.com/api?id=5&api_key=query-secret",
+		"header", header,
+		"error", errors.New(`unauthorized: Bearer error-secret`),
+		"user", secretValue{},
+		slog.Group("card", slog.String("card_number", "4111111111111111"), slog.String("holder", "IVAN")),
+		"unkeyed-secret",
+	)
+
+	out := buf.String()
+	for _, secret := range []string{
+		"with-secret", "message-secret", "attr-secret", "password-secret", "body-secret",
+		"payload-secret", "123", "query-secret", "header-secret", "error-secret",
+		"valuer-secret", "4111111111111111", "unkeyed-secret",
+	} {
+		assert.NotContains(t, out, secret)
+	}
+	for _, kept := range []string{
+		`"id\":1`, `"title\":\"ok\"`, "id=5", "application/json", "unauthorized: Bearer [REDACTED]",
+		`"login":"alice"`, `"holder":"IVAN"`, `"empty_token":""`,
+	} {
+		assert.Contains(t, out, kept)
+	}
+}
+
+func TestRedactString_Nested(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "array under sensitive key",
+			in:   `{"recovery_codes":["aaaa-1111","bbbb-2222"],"count":2}`,
+			want: `{"recovery_codes":"[REDACTED]","count":2}`,
+		},
+		{
+			name: "object under sensitive key",
+			in:   `{"id":7,"data": {"number":"4111111111111111","holder":"IVAN","tags":["x]"]},"title":"ok"}`,
+			want: `{"id":7,"data":"[REDACTED]","title":"ok"}`,
+		},
+		{
+			name: "sensitive fields inside ordinary objects",
+			in:   `{"items":[{"id":1,"number":"4111111111111111"},{"id":2,"secret":{"k":"v"}}]}`,
+			want: `{"items":[{"id":1,"number":"[REDACTED]"},{"id":2,"secret":"[REDACTED]"}]}`,
+		},
+		{
+			name: "truncated value",
+			in:   `{"id":1,"recovery_codes":["aaaa-1111","bb`,
+			want: `{"id":1,"recovery_codes":"[REDACTED]"`,
+		},
+		{
+			name: "ordinary arrays stay",
+			in:   `{"ids":[1,2],"meta":{"page":3}}`,
+			want: `{"ids":[1,2],"meta":{"page":3}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactString(tt.in))
+		})
+	}
+}
+
+func TestRedact_DecodedJSON(t *testing.T) {
+	decoded := map[string]interface{}{
+		"id":             1.0,
+		"recovery_codes": []interface{}{"aaaa-1111"},
+		"items":          []interface{}{map[string]interface{}{"number": "4111111111111111", "title": "card"}},
+		"token":          "",
+	}
+	got := Redact(slog.Any("response", decoded)).Value.Any().(map[string]interface{})
+
+	assert.Equal(t, 1.0, got["id"])
+	assert.Equal(t, Redacted, got["recovery_codes"])
+	assert.Equal(t, Redacted, got["items"].([]interface{})[0].(map[string]interface{})["number"])
+	assert.Equal(t, "card", got["items"].([]interface{})[0].(map[string]interface{})["title"])
+	assert.Equal(t, "", got["token"])
+	assert.Equal(t, []interface{}{"aaaa-1111"}, decoded["recovery_codes"], "исходное значение не меняется")
+}
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, key := range []string{"token", "Refresh-Token", "password", "master_password", "X-Api-Key", "cvv", "data", "secret", "recovery_codes", "number"} {
+		assert.True(t, IsSensitiveKey(key), key)
+	}
+	for _, key := range []string{"token_id", "user_id", "key", "login", "record_id", "tokens"} {
+		assert.False(t, IsSensitiveKey(key), key)
+	}
+}
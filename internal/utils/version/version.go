@@ -0,0 +1,60 @@
+// Package version сравнивает версии клиента в формате MAJOR.MINOR.PATCH
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Header - заголовок запроса с версией клиента
+const Header = "X-Client-Version"
+
+// Version - версия MAJOR.MINOR.PATCH. Суффикс сборки и пре-релиза
+// (1.2.0-rc1, 1.2.0+abc) при сравнении не учитывается.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Parse разбирает версию вида 1.2.3, v1.2 или 1.2.3-rc1
+func Parse(s string) (Version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(raw, "-+"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	parts := strings.Split(raw, ".")
+	if raw == "" || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+// IsZero сообщает, что версия не задана
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// Less сообщает, что версия v старше other
+func (v Version) Less(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,39 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"1.2.3", Version{1, 2, 3}},
+		{"v1.2", Version{1, 2, 0}},
+		{"2", Version{2, 0, 0}},
+		{"1.4.0-rc1", Version{1, 4, 0}},
+		{" 1.0.1+abc ", Version{1, 0, 1}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"", "v", "1.x", "1.2.3.4", "-1.0", "GophKeeper-Client/1.0"} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestVersion_Less(t *testing.T) {
+	assert.True(t, Version{1, 0, 0}.Less(Version{1, 0, 1}))
+	assert.True(t, Version{1, 9, 9}.Less(Version{2, 0, 0}))
+	assert.True(t, Version{1, 2, 0}.Less(Version{1, 10, 0}))
+	assert.False(t, Version{1, 2, 3}.Less(Version{1, 2, 3}))
+	assert.False(t, Version{2, 0, 0}.Less(Version{1, 9, 9}))
+}
@@ -0,0 +1,7 @@
+ALTER TABLE sync_conflicts
+    DROP COLUMN IF EXISTS record_type,
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS local_version,
+    DROP COLUMN IF EXISTS server_version,
+    DROP COLUMN IF EXISTS local_modified,
+    DROP COLUMN IF EXISTS server_modified;
@@ -0,0 +1,7 @@
+ALTER TABLE sync_conflicts
+    ADD COLUMN IF NOT EXISTS record_type VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS local_version INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS server_version INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS local_modified TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS server_modified TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,5 @@
+ALTER TABLE records DROP CONSTRAINT IF EXISTS records_type_check;
+
+ALTER TABLE records
+    ADD CONSTRAINT records_type_check
+        CHECK (type IN ('login', 'text', 'binary', 'card', 'otp'));
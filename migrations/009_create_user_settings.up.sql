@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_settings
+(
+    user_id    INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    key        VARCHAR(64)              NOT NULL,
+    value      TEXT                     NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,7 @@
+DELETE FROM records WHERE org_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_records_org_id;
+ALTER TABLE records DROP COLUMN IF EXISTS org_id;
+
+DROP TABLE IF EXISTS org_memberships;
+DROP TABLE IF EXISTS organizations;
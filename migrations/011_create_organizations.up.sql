@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id         INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name       VARCHAR(255)             NOT NULL,
+    created_by INTEGER                  REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- encrypted_key - ключ хранилища организации, зашифрованный на клиенте:
+-- у приглашенного - кодом приглашения, у активного участника - его мастер-ключом
+CREATE TABLE IF NOT EXISTS org_memberships
+(
+    org_id        INTEGER                  NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id       INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role          VARCHAR(20)              NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read-only')),
+    status        VARCHAR(20)              NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'active')),
+    encrypted_key TEXT                     NOT NULL,
+    invited_by    INTEGER                  REFERENCES users (id) ON DELETE SET NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_memberships_user_id ON org_memberships (user_id);
+
+-- Запись с org_id принадлежит хранилищу организации, user_id - ее автор
+ALTER TABLE records
+    ADD COLUMN IF NOT EXISTS org_id INTEGER REFERENCES organizations (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_records_org_id ON records (org_id) WHERE org_id IS NOT NULL;
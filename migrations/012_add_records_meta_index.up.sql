@@ -0,0 +1,3 @@
+-- Поиск по тегам и категории (meta @> '{"tags": [...]}') использует GIN-индекс
+CREATE INDEX IF NOT EXISTS idx_records_meta ON records USING GIN (meta jsonb_path_ops)
+    WHERE deleted_at IS NULL;
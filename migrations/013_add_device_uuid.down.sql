@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_devices_user_uuid;
+ALTER TABLE devices DROP COLUMN IF EXISTS device_uuid;
@@ -0,0 +1,6 @@
+-- Постоянный идентификатор установки клиента. По нему клиент после
+-- переустановки находит свою прежнюю запись устройства.
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS device_uuid UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_user_uuid ON devices (user_id, device_uuid)
+    WHERE device_uuid IS NOT NULL;
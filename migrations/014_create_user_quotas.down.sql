@@ -0,0 +1,15 @@
+DROP VIEW IF EXISTS sync_status_view;
+CREATE VIEW sync_status_view AS
+SELECT
+    u.id as user_id,
+    MAX(r.last_modified) as last_sync_time,
+    COUNT(r.id) FILTER (WHERE r.deleted_at IS NULL) as total_records,
+    COUNT(DISTINCT r.device_id) FILTER (WHERE r.deleted_at IS NULL AND r.device_id IS NOT NULL) as device_count,
+    COALESCE(SUM(LENGTH(r.encrypted_data)) FILTER (WHERE r.deleted_at IS NULL), 0) as storage_used,
+    104857600 as storage_limit,
+    COALESCE(MAX(r.version), 0) as sync_version
+FROM users u
+         LEFT JOIN records r ON u.id = r.user_id
+GROUP BY u.id;
+
+DROP TABLE IF EXISTS user_quotas;
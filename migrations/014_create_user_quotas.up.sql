@@ -0,0 +1,24 @@
+-- Лимит хранилища, заданный пользователю администратором.
+-- Пользователям без строки в таблице действует лимит из конфигурации сервера.
+CREATE TABLE IF NOT EXISTS user_quotas
+(
+    user_id       INTEGER                  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    storage_limit BIGINT                   NOT NULL CHECK (storage_limit > 0),
+    updated_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- storage_limit в представлении - заданный лимит или NULL (действует лимит по умолчанию)
+DROP VIEW IF EXISTS sync_status_view;
+CREATE VIEW sync_status_view AS
+SELECT
+    u.id as user_id,
+    MAX(r.last_modified) as last_sync_time,
+    COUNT(r.id) FILTER (WHERE r.deleted_at IS NULL) as total_records,
+    COUNT(DISTINCT r.device_id) FILTER (WHERE r.deleted_at IS NULL AND r.device_id IS NOT NULL) as device_count,
+    COALESCE(SUM(LENGTH(r.encrypted_data)) FILTER (WHERE r.deleted_at IS NULL), 0) as storage_used,
+    q.storage_limit as storage_limit,
+    COALESCE(MAX(r.version), 0) as sync_version
+FROM users u
+         LEFT JOIN records r ON u.id = r.user_id
+         LEFT JOIN user_quotas q ON u.id = q.user_id
+GROUP BY u.id, q.storage_limit;
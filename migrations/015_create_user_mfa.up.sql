@@ -0,0 +1,36 @@
+-- Второй фактор входа (TOTP). Пока enabled = false, секрет ожидает
+-- подтверждения первым кодом и на вход не влияет.
+CREATE TABLE IF NOT EXISTS user_totp
+(
+    user_id        INTEGER                  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT                     NOT NULL,
+    enabled        BOOLEAN                  NOT NULL DEFAULT FALSE,
+    last_used_step BIGINT                   NOT NULL DEFAULT 0, -- защита от повторного использования кода
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    enabled_at     TIMESTAMP WITH TIME ZONE
+);
+
+-- Одноразовые коды восстановления хранятся только хэшами
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    id         INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT                     NOT NULL,
+    used_at    TIMESTAMP WITH TIME ZONE,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Незавершенные входы: пароль проверен, ждем код второго фактора
+CREATE TABLE IF NOT EXISTS login_challenges
+(
+    token_hash TEXT                     PRIMARY KEY,
+    user_id    INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    attempts   INTEGER                  NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires ON login_challenges (expires_at);
+
+-- Сессия прошла второй фактор. Сессии без него отклоняются,
+-- если у пользователя включена двухфакторная аутентификация.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa BOOLEAN NOT NULL DEFAULT FALSE;
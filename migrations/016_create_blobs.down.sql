@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS records_blob_refs ON records;
+DROP FUNCTION IF EXISTS records_blob_refs();
+DROP INDEX IF EXISTS idx_records_blob;
+DROP TABLE IF EXISTS blobs;
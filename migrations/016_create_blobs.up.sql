@@ -0,0 +1,48 @@
+-- Содержимое бинарных записей, общее для записей пользователя с одинаковым
+-- содержимым. Запись ссылается на блоб ключом "blob" в метаданных; ref_count
+-- поддерживает триггер на records, блобы без ссылок удаляются фоновой задачей.
+CREATE TABLE IF NOT EXISTS blobs
+(
+    user_id    INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    checksum   VARCHAR(64)              NOT NULL, -- SHA-256 зашифрованных данных в hex
+    data       BYTEA                    NOT NULL,
+    size       BIGINT                   NOT NULL,
+    ref_count  INTEGER                  NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(), -- последнее изменение ref_count
+    PRIMARY KEY (user_id, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blobs_unreferenced ON blobs (updated_at) WHERE ref_count = 0;
+CREATE INDEX IF NOT EXISTS idx_records_blob ON records (user_id, (meta ->> 'blob')) WHERE meta ? 'blob';
+
+CREATE OR REPLACE FUNCTION records_blob_refs() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.user_id = NEW.user_id
+        AND (OLD.meta ->> 'blob') IS NOT DISTINCT FROM (NEW.meta ->> 'blob') THEN
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.meta ? 'blob' THEN
+        UPDATE blobs
+        SET ref_count = GREATEST(ref_count - 1, 0), updated_at = NOW()
+        WHERE user_id = OLD.user_id AND checksum = OLD.meta ->> 'blob';
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.meta ? 'blob' THEN
+        UPDATE blobs
+        SET ref_count = ref_count + 1, updated_at = NOW()
+        WHERE user_id = NEW.user_id AND checksum = NEW.meta ->> 'blob';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS records_blob_refs ON records;
+CREATE TRIGGER records_blob_refs
+    AFTER INSERT OR UPDATE OR DELETE
+    ON records
+    FOR EACH ROW
+EXECUTE FUNCTION records_blob_refs();
@@ -0,0 +1,14 @@
+-- Папки для записей пользователя. Записи ссылаются на папку через folder_id
+-- в meta; удалить можно только пустую папку.
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  INTEGER REFERENCES folders (id) ON DELETE RESTRICT,
+    name       VARCHAR(100)             NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_user ON folders (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_sibling_name ON folders (user_id, COALESCE(parent_id, 0), name);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS user_sync_protocols;
+-- Протокол синхронизации, назначенный пользователю администратором.
+-- Пользователям без строки в таблице протокол выбирается по SYNC_V2_ROLLOUT_PERCENT.
+CREATE TABLE IF NOT EXISTS user_sync_protocols
+(
+    user_id    INTEGER                  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    protocol   VARCHAR(8)               NOT NULL CHECK (protocol IN ('v1', 'v2')),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+-- Файл мастер-ключа пользователя для восстановления хранилища на новом устройстве.
+-- Ключ в файле зашифрован мастер-паролем; key_hash отличает файлы разных ключей.
+CREATE TABLE IF NOT EXISTS user_key_files
+(
+    user_id    INTEGER                  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    data       BYTEA                    NOT NULL,
+    key_hash   VARCHAR(64)              NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS records_downloaded;
+ALTER TABLE devices DROP COLUMN IF EXISTS records_uploaded;
+ALTER TABLE devices DROP COLUMN IF EXISTS request_count;
+ALTER TABLE devices DROP COLUMN IF EXISTS bytes_downloaded;
+ALTER TABLE devices DROP COLUMN IF EXISTS bytes_uploaded;
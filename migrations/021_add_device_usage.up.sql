@@ -0,0 +1,7 @@
+-- Учет трафика и операций синхронизации по устройствам: помогает заметить
+-- клиента, который синхронизируется без остановки или скомпрометирован.
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS bytes_uploaded BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS bytes_downloaded BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS request_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS records_uploaded BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS records_downloaded BIGINT NOT NULL DEFAULT 0;
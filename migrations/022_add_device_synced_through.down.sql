@@ -0,0 +1,2 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS synced_through_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS synced_through;
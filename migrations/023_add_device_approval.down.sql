@@ -0,0 +1,4 @@
+ALTER TABLE devices DROP COLUMN IF EXISTS approved_by;
+ALTER TABLE devices DROP COLUMN IF EXISTS approved_at;
+ALTER TABLE devices DROP COLUMN IF EXISTS status;
+ALTER TABLE devices DROP COLUMN IF EXISTS public_key;
@@ -0,0 +1,7 @@
+-- Подтверждение устройств: новое устройство регистрируется с открытым ключом
+-- и не синхронизируется, пока его не подтвердят с уже доверенного устройства.
+-- Устройства, зарегистрированные до появления подтверждения, считаются доверенными.
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS public_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'approved';
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approved_at TIMESTAMPTZ;
+ALTER TABLE devices ADD COLUMN IF NOT EXISTS approved_by BIGINT REFERENCES devices(id) ON DELETE SET NULL;
@@ -0,0 +1,15 @@
+-- Токены только для чтения записей выбранных папок (аудит, экстренный доступ).
+-- Хранится только SHA-256 секрета; folder_ids - JSON-массив ID папок.
+CREATE TABLE IF NOT EXISTS viewer_tokens
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER                  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         VARCHAR(100)             NOT NULL,
+    token_hash   VARCHAR(64)              NOT NULL UNIQUE,
+    folder_ids   JSONB                    NOT NULL,
+    expires_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_viewer_tokens_user ON viewer_tokens (user_id);
@@ -0,0 +1,5 @@
+-- Запланированное удаление учетной записи: после delete_after фоновая задача
+-- удаляет пользователя вместе со всеми данными (внешние ключи с ON DELETE CASCADE).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS delete_after TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_delete_after ON users (delete_after) WHERE delete_after IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_records_user_uuid;
+ALTER TABLE records DROP COLUMN IF EXISTS uuid;
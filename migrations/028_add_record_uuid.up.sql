@@ -0,0 +1,12 @@
+-- Постоянный идентификатор записи. UUID создает клиент при создании записи,
+-- поэтому повтор запроса не создает дубликат, а клиент и сервер находят
+-- запись без сопоставления локальных и серверных числовых ID. Записям,
+-- созданным до появления UUID, он назначается здесь.
+ALTER TABLE records ADD COLUMN IF NOT EXISTS uuid UUID;
+
+UPDATE records SET uuid = gen_random_uuid() WHERE uuid IS NULL;
+
+ALTER TABLE records ALTER COLUMN uuid SET DEFAULT gen_random_uuid();
+ALTER TABLE records ALTER COLUMN uuid SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_records_user_uuid ON records (user_id, uuid);
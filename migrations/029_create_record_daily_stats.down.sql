@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS records_daily_stats ON records;
+DROP FUNCTION IF EXISTS records_daily_stats();
+DROP TABLE IF EXISTS record_daily_stats;
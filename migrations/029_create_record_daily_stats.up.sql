@@ -0,0 +1,89 @@
+-- Изменения личных записей пользователя по дням (UTC) для статистики роста
+-- хранилища. Таблицу ведет триггер на records: создание и восстановление из
+-- корзины, изменение содержимого, перемещение в корзину и удаление. Записи
+-- хранилищ организаций не учитываются, как и в статистике записей.
+CREATE TABLE IF NOT EXISTS record_daily_stats
+(
+    user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day        DATE    NOT NULL,
+    created    INTEGER NOT NULL DEFAULT 0,
+    updated    INTEGER NOT NULL DEFAULT 0,
+    deleted    INTEGER NOT NULL DEFAULT 0,
+    size_delta BIGINT  NOT NULL DEFAULT 0, -- изменение объема зашифрованных данных в байтах
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE OR REPLACE FUNCTION records_daily_stats() RETURNS TRIGGER AS
+$$
+DECLARE
+    uid       INTEGER;
+    n_created INTEGER := 0;
+    n_updated INTEGER := 0;
+    n_deleted INTEGER := 0;
+    delta     BIGINT  := 0;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.org_id IS NOT NULL OR NEW.deleted_at IS NOT NULL THEN
+            RETURN NULL;
+        END IF;
+        uid := NEW.user_id;
+        n_created := 1;
+        delta := LENGTH(NEW.encrypted_data);
+    ELSIF TG_OP = 'DELETE' THEN
+        -- Записи из корзины уже учтены удаленными. При удалении пользователя
+        -- его записи удаляются каскадом, и учитывать их некому.
+        IF OLD.org_id IS NOT NULL OR OLD.deleted_at IS NOT NULL
+            OR NOT EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id) THEN
+            RETURN NULL;
+        END IF;
+        uid := OLD.user_id;
+        n_deleted := 1;
+        delta := -LENGTH(OLD.encrypted_data);
+    ELSE
+        IF NEW.org_id IS NOT NULL THEN
+            RETURN NULL;
+        END IF;
+        uid := NEW.user_id;
+        IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+            n_deleted := 1;
+            delta := -LENGTH(OLD.encrypted_data);
+        ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+            n_created := 1;
+            delta := LENGTH(NEW.encrypted_data);
+        ELSIF NEW.deleted_at IS NULL AND (OLD.encrypted_data IS DISTINCT FROM NEW.encrypted_data
+            OR OLD.meta IS DISTINCT FROM NEW.meta) THEN
+            n_updated := 1;
+            delta := LENGTH(NEW.encrypted_data) - LENGTH(OLD.encrypted_data);
+        ELSE
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO record_daily_stats AS s (user_id, day, created, updated, deleted, size_delta)
+    VALUES (uid, (NOW() AT TIME ZONE 'UTC')::DATE, n_created, n_updated, n_deleted, delta)
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        created    = s.created + EXCLUDED.created,
+        updated    = s.updated + EXCLUDED.updated,
+        deleted    = s.deleted + EXCLUDED.deleted,
+        size_delta = s.size_delta + EXCLUDED.size_delta;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS records_daily_stats ON records;
+CREATE TRIGGER records_daily_stats
+    AFTER INSERT OR UPDATE OR DELETE
+    ON records
+    FOR EACH ROW
+EXECUTE FUNCTION records_daily_stats();
+
+-- Даты создания существующих записей не хранятся: они учитываются созданными
+-- в день последнего изменения, чтобы сумма изменений совпадала с текущим числом
+-- и объемом записей
+INSERT INTO record_daily_stats (user_id, day, created, size_delta)
+SELECT user_id, (last_modified AT TIME ZONE 'UTC')::DATE, COUNT(*), COALESCE(SUM(LENGTH(encrypted_data)), 0)
+FROM records
+WHERE org_id IS NULL AND deleted_at IS NULL
+GROUP BY 1, 2
+ON CONFLICT (user_id, day) DO NOTHING;
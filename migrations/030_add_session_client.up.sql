@@ -0,0 +1,6 @@
+-- Откуда создана и последний раз использована сессия: по этим сведениям
+-- пользователь находит в списке сессий чужой вход и завершает его.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS device_id TEXT;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip_address TEXT;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
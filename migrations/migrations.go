@@ -0,0 +1,15 @@
+// Package migrations встраивает SQL-миграции схемы в бинарник сервера,
+// чтобы они применялись без каталога migrations рядом с исполняемым файлом.
+package migrations
+
+import "embed"
+
+// FS содержит миграции PostgreSQL: файлы NNN_name.up.sql и NNN_name.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS содержит миграции SQLite в каталоге sqlite
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
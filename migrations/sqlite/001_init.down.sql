@@ -0,0 +1,14 @@
+DROP VIEW IF EXISTS sync_status_view;
+DROP TABLE IF EXISTS login_challenges;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
+DROP TABLE IF EXISTS user_quotas;
+DROP TABLE IF EXISTS user_settings;
+DROP TABLE IF EXISTS sync_conflicts;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS record_versions;
+DROP TABLE IF EXISTS records;
+DROP TABLE IF EXISTS org_memberships;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS users;
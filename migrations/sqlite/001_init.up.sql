@@ -0,0 +1,184 @@
+-- Схема SQLite соответствует схеме PostgreSQL после миграции 015.
+-- Время хранится текстом в UTC, JSON - текстом, зашифрованные данные - BLOB.
+-- Триггеров нет: updated_at обновляют сами запросы репозиториев.
+CREATE TABLE users
+(
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    login         TEXT     NOT NULL UNIQUE,
+    password_hash TEXT     NOT NULL,
+    created_at    DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE organizations
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT     NOT NULL,
+    created_by INTEGER  REFERENCES users (id) ON DELETE SET NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE org_memberships
+(
+    org_id        INTEGER  NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id       INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role          TEXT     NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read-only')),
+    status        TEXT     NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'active')),
+    encrypted_key TEXT     NOT NULL,
+    invited_by    INTEGER  REFERENCES users (id) ON DELETE SET NULL,
+    created_at    DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at    DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX idx_org_memberships_user_id ON org_memberships (user_id);
+
+CREATE TABLE records
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id        INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type           TEXT     NOT NULL CHECK (type IN ('login', 'text', 'binary', 'card', 'otp', 'ssh_key')),
+    encrypted_data BLOB     NOT NULL,
+    meta           TEXT     NOT NULL DEFAULT '{}',
+    version        INTEGER  NOT NULL DEFAULT 1,
+    last_modified  DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at     DATETIME,
+    checksum       TEXT,
+    device_id      TEXT,
+    org_id         INTEGER  REFERENCES organizations (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX records_user_type_data_unique
+    ON records (user_id, type, encrypted_data)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_records_user_type_modified ON records (user_id, type, last_modified DESC);
+CREATE INDEX idx_records_last_modified ON records (last_modified);
+CREATE INDEX idx_records_checksum ON records (checksum);
+CREATE INDEX idx_records_deleted_at ON records (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_records_org_id ON records (org_id) WHERE org_id IS NOT NULL;
+
+CREATE TABLE record_versions
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    record_id      INTEGER  NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    version        INTEGER  NOT NULL,
+    encrypted_data BLOB     NOT NULL,
+    meta           TEXT     NOT NULL DEFAULT '{}',
+    checksum       TEXT,
+    created_at     DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    UNIQUE (record_id, version)
+);
+
+CREATE TABLE sessions
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_hash BLOB     NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    mfa        BOOLEAN  NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX idx_sessions_user_expires ON sessions (user_id, expires_at);
+
+CREATE TABLE devices
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id        INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device_uuid    TEXT,
+    name           TEXT     NOT NULL,
+    type           TEXT     NOT NULL DEFAULT 'desktop',
+    last_sync_time DATETIME,
+    ip_address     TEXT,
+    user_agent     TEXT,
+    created_at     DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at     DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_devices_user_id ON devices (user_id);
+CREATE UNIQUE INDEX idx_devices_user_uuid ON devices (user_id, device_uuid)
+    WHERE device_uuid IS NOT NULL;
+
+CREATE TABLE sync_conflicts
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    record_id       INTEGER  NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    user_id         INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device_id       INTEGER  NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    local_data      BLOB,
+    server_data     BLOB,
+    conflict_type   TEXT     NOT NULL,
+    resolved        BOOLEAN  NOT NULL DEFAULT FALSE,
+    resolution      TEXT,
+    resolved_at     DATETIME,
+    created_at      DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at      DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    record_type     TEXT     NOT NULL DEFAULT '',
+    title           TEXT     NOT NULL DEFAULT '',
+    local_version   INTEGER  NOT NULL DEFAULT 0,
+    server_version  INTEGER  NOT NULL DEFAULT 0,
+    local_modified  DATETIME,
+    server_modified DATETIME
+);
+
+CREATE INDEX idx_sync_conflicts_resolved ON sync_conflicts (resolved);
+CREATE INDEX idx_sync_conflicts_record_id ON sync_conflicts (record_id);
+
+CREATE TABLE user_settings
+(
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    key        TEXT     NOT NULL,
+    value      TEXT     NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE TABLE user_quotas
+(
+    user_id       INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    storage_limit INTEGER  NOT NULL CHECK (storage_limit > 0),
+    updated_at    DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE user_totp
+(
+    user_id        INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT     NOT NULL,
+    enabled        BOOLEAN  NOT NULL DEFAULT FALSE,
+    last_used_step INTEGER  NOT NULL DEFAULT 0,
+    created_at     DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    enabled_at     DATETIME
+);
+
+CREATE TABLE user_recovery_codes
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT    NOT NULL,
+    used_at   DATETIME,
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE login_challenges
+(
+    token_hash TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    attempts   INTEGER  NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_login_challenges_expires ON login_challenges (expires_at);
+
+CREATE VIEW sync_status_view AS
+SELECT
+    u.id as user_id,
+    MAX(r.last_modified) as last_sync_time,
+    COUNT(r.id) FILTER (WHERE r.deleted_at IS NULL) as total_records,
+    COUNT(DISTINCT r.device_id) FILTER (WHERE r.deleted_at IS NULL AND r.device_id IS NOT NULL) as device_count,
+    COALESCE(SUM(LENGTH(r.encrypted_data)) FILTER (WHERE r.deleted_at IS NULL), 0) as storage_used,
+    q.storage_limit as storage_limit,
+    COALESCE(MAX(r.version), 0) as sync_version
+FROM users u
+         LEFT JOIN records r ON u.id = r.user_id
+         LEFT JOIN user_quotas q ON u.id = q.user_id
+GROUP BY u.id, q.storage_limit;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS records_blob_refs_delete;
+DROP TRIGGER IF EXISTS records_blob_refs_update;
+DROP TRIGGER IF EXISTS records_blob_refs_insert;
+DROP INDEX IF EXISTS idx_records_blob;
+DROP TABLE IF EXISTS blobs;
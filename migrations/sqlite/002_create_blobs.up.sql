@@ -0,0 +1,56 @@
+-- Содержимое бинарных записей, общее для записей пользователя с одинаковым
+-- содержимым. Запись ссылается на блоб ключом "blob" в метаданных; ref_count
+-- поддерживают триггеры на records, блобы без ссылок удаляются фоновой задачей.
+CREATE TABLE blobs
+(
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    checksum   TEXT     NOT NULL,
+    data       BLOB     NOT NULL,
+    size       INTEGER  NOT NULL,
+    ref_count  INTEGER  NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, checksum)
+);
+
+CREATE INDEX idx_blobs_unreferenced ON blobs (updated_at) WHERE ref_count = 0;
+CREATE INDEX idx_records_blob ON records (user_id, json_extract(meta, '$.blob'))
+    WHERE json_extract(meta, '$.blob') IS NOT NULL;
+
+CREATE TRIGGER records_blob_refs_insert
+    AFTER INSERT
+    ON records
+    WHEN json_extract(NEW.meta, '$.blob') IS NOT NULL
+BEGIN
+    UPDATE blobs
+    SET ref_count  = ref_count + 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+    WHERE user_id = NEW.user_id AND checksum = json_extract(NEW.meta, '$.blob');
+END;
+
+CREATE TRIGGER records_blob_refs_update
+    AFTER UPDATE OF meta, user_id
+    ON records
+    WHEN OLD.user_id != NEW.user_id
+        OR json_extract(OLD.meta, '$.blob') IS NOT json_extract(NEW.meta, '$.blob')
+BEGIN
+    UPDATE blobs
+    SET ref_count  = max(ref_count - 1, 0),
+        updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+    WHERE user_id = OLD.user_id AND checksum = json_extract(OLD.meta, '$.blob');
+    UPDATE blobs
+    SET ref_count  = ref_count + 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+    WHERE user_id = NEW.user_id AND checksum = json_extract(NEW.meta, '$.blob');
+END;
+
+CREATE TRIGGER records_blob_refs_delete
+    AFTER DELETE
+    ON records
+    WHEN json_extract(OLD.meta, '$.blob') IS NOT NULL
+BEGIN
+    UPDATE blobs
+    SET ref_count  = max(ref_count - 1, 0),
+        updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+    WHERE user_id = OLD.user_id AND checksum = json_extract(OLD.meta, '$.blob');
+END;
@@ -0,0 +1,15 @@
+-- Файлы, прикрепленные к записям. Имя файла и содержимое зашифрованы клиентом;
+-- user_id - загрузивший пользователь, вложение учитывается в его квоте.
+CREATE TABLE IF NOT EXISTS record_attachments
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    record_id      INTEGER  NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    user_id        INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    encrypted_meta TEXT     NOT NULL,
+    data           BLOB     NOT NULL,
+    size           INTEGER  NOT NULL,
+    created_at     DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_record_attachments_record ON record_attachments (record_id);
+CREATE INDEX IF NOT EXISTS idx_record_attachments_user ON record_attachments (user_id);
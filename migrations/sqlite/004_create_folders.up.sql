@@ -0,0 +1,14 @@
+-- Папки для записей пользователя. Записи ссылаются на папку через folder_id
+-- в meta; удалить можно только пустую папку.
+CREATE TABLE IF NOT EXISTS folders
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    parent_id  INTEGER REFERENCES folders (id) ON DELETE RESTRICT,
+    name       TEXT     NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_folders_user ON folders (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_sibling_name ON folders (user_id, COALESCE(parent_id, 0), name);
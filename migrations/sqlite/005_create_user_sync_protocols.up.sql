@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS user_sync_protocols;
+-- Протокол синхронизации, назначенный пользователю администратором.
+-- Пользователям без строки в таблице протокол выбирается по SYNC_V2_ROLLOUT_PERCENT.
+CREATE TABLE IF NOT EXISTS user_sync_protocols
+(
+    user_id    INTEGER  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    protocol   TEXT     NOT NULL CHECK (protocol IN ('v1', 'v2')),
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
@@ -0,0 +1,9 @@
+-- Файл мастер-ключа пользователя для восстановления хранилища на новом устройстве.
+-- Ключ в файле зашифрован мастер-паролем; key_hash отличает файлы разных ключей.
+CREATE TABLE IF NOT EXISTS user_key_files
+(
+    user_id    INTEGER  PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    data       BLOB     NOT NULL,
+    key_hash   TEXT     NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
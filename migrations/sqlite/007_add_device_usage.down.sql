@@ -0,0 +1,5 @@
+ALTER TABLE devices DROP COLUMN records_downloaded;
+ALTER TABLE devices DROP COLUMN records_uploaded;
+ALTER TABLE devices DROP COLUMN request_count;
+ALTER TABLE devices DROP COLUMN bytes_downloaded;
+ALTER TABLE devices DROP COLUMN bytes_uploaded;
@@ -0,0 +1,7 @@
+-- Учет трафика и операций синхронизации по устройствам: помогает заметить
+-- клиента, который синхронизируется без остановки или скомпрометирован.
+ALTER TABLE devices ADD COLUMN bytes_uploaded INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN bytes_downloaded INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN request_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN records_uploaded INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE devices ADD COLUMN records_downloaded INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE devices DROP COLUMN synced_through_id;
+ALTER TABLE devices DROP COLUMN synced_through;
@@ -0,0 +1,5 @@
+-- Позиция, до которой устройство получило изменения: курсор, с которого оно
+-- запросило следующую порцию. По ней сервер понимает, что устройство уже
+-- получило удаление записи и запись можно удалить окончательно.
+ALTER TABLE devices ADD COLUMN synced_through DATETIME;
+ALTER TABLE devices ADD COLUMN synced_through_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE devices DROP COLUMN approved_by;
+ALTER TABLE devices DROP COLUMN approved_at;
+ALTER TABLE devices DROP COLUMN status;
+ALTER TABLE devices DROP COLUMN public_key;
@@ -0,0 +1,7 @@
+-- Подтверждение устройств: новое устройство регистрируется с открытым ключом
+-- и не синхронизируется, пока его не подтвердят с уже доверенного устройства.
+-- Устройства, зарегистрированные до появления подтверждения, считаются доверенными.
+ALTER TABLE devices ADD COLUMN public_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE devices ADD COLUMN status TEXT NOT NULL DEFAULT 'approved';
+ALTER TABLE devices ADD COLUMN approved_at DATETIME;
+ALTER TABLE devices ADD COLUMN approved_by INTEGER REFERENCES devices (id) ON DELETE SET NULL;
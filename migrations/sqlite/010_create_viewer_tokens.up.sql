@@ -0,0 +1,15 @@
+-- Токены только для чтения записей выбранных папок (аудит, экстренный доступ).
+-- Хранится только SHA-256 секрета; folder_ids - JSON-массив ID папок.
+CREATE TABLE IF NOT EXISTS viewer_tokens
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         TEXT     NOT NULL,
+    token_hash   TEXT     NOT NULL UNIQUE,
+    folder_ids   TEXT     NOT NULL,
+    expires_at   DATETIME NOT NULL,
+    created_at   DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    last_used_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_viewer_tokens_user ON viewer_tokens (user_id);
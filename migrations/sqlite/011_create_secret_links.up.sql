@@ -0,0 +1,16 @@
+-- Одноразовые ссылки на секреты. Шифротекст зашифрован ключом из фрагмента
+-- ссылки, который на сервер не передается; строка удаляется после последнего
+-- просмотра или по истечении срока.
+CREATE TABLE IF NOT EXISTS secret_links
+(
+    id         TEXT PRIMARY KEY,
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ciphertext BLOB     NOT NULL,
+    max_views  INTEGER  NOT NULL,
+    views      INTEGER  NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_secret_links_user ON secret_links (user_id);
+CREATE INDEX IF NOT EXISTS idx_secret_links_expires ON secret_links (expires_at);
@@ -0,0 +1,4 @@
+-- Роль пользователя и блокировка учётной записи для административного API.
+-- Заблокированный пользователь не может войти, его сессии перестают действовать.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN disabled_at DATETIME;
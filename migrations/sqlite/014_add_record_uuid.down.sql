@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS records_default_uuid;
+DROP INDEX IF EXISTS idx_records_user_uuid;
+ALTER TABLE records DROP COLUMN uuid;
@@ -0,0 +1,22 @@
+-- Постоянный идентификатор записи. UUID создает клиент при создании записи,
+-- поэтому повтор запроса не создает дубликат, а клиент и сервер находят
+-- запись без сопоставления локальных и серверных числовых ID. Записям,
+-- созданным до появления UUID, он назначается здесь, а записям, вставленным
+-- без UUID, - триггером: SQLite не принимает выражение как DEFAULT новой колонки.
+ALTER TABLE records ADD COLUMN uuid TEXT;
+
+UPDATE records SET uuid = lower(
+    hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+    substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+) WHERE uuid IS NULL;
+
+CREATE UNIQUE INDEX idx_records_user_uuid ON records (user_id, uuid);
+
+CREATE TRIGGER records_default_uuid AFTER INSERT ON records
+    FOR EACH ROW WHEN NEW.uuid IS NULL
+BEGIN
+    UPDATE records SET uuid = lower(
+        hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-' ||
+        substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+    ) WHERE id = NEW.id;
+END;
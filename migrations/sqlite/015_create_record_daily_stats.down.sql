@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS records_daily_stats_delete;
+DROP TRIGGER IF EXISTS records_daily_stats_restore;
+DROP TRIGGER IF EXISTS records_daily_stats_trash;
+DROP TRIGGER IF EXISTS records_daily_stats_update;
+DROP TRIGGER IF EXISTS records_daily_stats_insert;
+DROP TABLE IF EXISTS record_daily_stats;
@@ -0,0 +1,87 @@
+-- Изменения личных записей пользователя по дням (UTC) для статистики роста
+-- хранилища. Таблицу ведут триггеры на records: создание и восстановление из
+-- корзины, изменение содержимого, перемещение в корзину и удаление. Записи
+-- хранилищ организаций не учитываются, как и в статистике записей.
+CREATE TABLE record_daily_stats
+(
+    user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day        TEXT    NOT NULL, -- YYYY-MM-DD
+    created    INTEGER NOT NULL DEFAULT 0,
+    updated    INTEGER NOT NULL DEFAULT 0,
+    deleted    INTEGER NOT NULL DEFAULT 0,
+    size_delta INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE TRIGGER records_daily_stats_insert
+    AFTER INSERT
+    ON records
+    WHEN NEW.org_id IS NULL AND NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO record_daily_stats (user_id, day, created, size_delta)
+    VALUES (NEW.user_id, date('now'), 1, LENGTH(NEW.encrypted_data))
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        created    = created + 1,
+        size_delta = size_delta + excluded.size_delta;
+END;
+
+CREATE TRIGGER records_daily_stats_update
+    AFTER UPDATE OF encrypted_data, meta
+    ON records
+    WHEN NEW.org_id IS NULL AND OLD.deleted_at IS NULL AND NEW.deleted_at IS NULL
+        AND (OLD.encrypted_data IS NOT NEW.encrypted_data OR OLD.meta IS NOT NEW.meta)
+BEGIN
+    INSERT INTO record_daily_stats (user_id, day, updated, size_delta)
+    VALUES (NEW.user_id, date('now'), 1, LENGTH(NEW.encrypted_data) - LENGTH(OLD.encrypted_data))
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        updated    = updated + 1,
+        size_delta = size_delta + excluded.size_delta;
+END;
+
+CREATE TRIGGER records_daily_stats_trash
+    AFTER UPDATE OF deleted_at
+    ON records
+    WHEN NEW.org_id IS NULL AND OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL
+BEGIN
+    INSERT INTO record_daily_stats (user_id, day, deleted, size_delta)
+    VALUES (NEW.user_id, date('now'), 1, -LENGTH(OLD.encrypted_data))
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        deleted    = deleted + 1,
+        size_delta = size_delta + excluded.size_delta;
+END;
+
+CREATE TRIGGER records_daily_stats_restore
+    AFTER UPDATE OF deleted_at
+    ON records
+    WHEN NEW.org_id IS NULL AND OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO record_daily_stats (user_id, day, created, size_delta)
+    VALUES (NEW.user_id, date('now'), 1, LENGTH(NEW.encrypted_data))
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        created    = created + 1,
+        size_delta = size_delta + excluded.size_delta;
+END;
+
+-- Записи из корзины уже учтены удаленными. При удалении пользователя его
+-- записи удаляются каскадом, и учитывать их некому.
+CREATE TRIGGER records_daily_stats_delete
+    AFTER DELETE
+    ON records
+    WHEN OLD.org_id IS NULL AND OLD.deleted_at IS NULL
+        AND EXISTS (SELECT 1 FROM users WHERE id = OLD.user_id)
+BEGIN
+    INSERT INTO record_daily_stats (user_id, day, deleted, size_delta)
+    VALUES (OLD.user_id, date('now'), 1, -LENGTH(OLD.encrypted_data))
+    ON CONFLICT (user_id, day) DO UPDATE SET
+        deleted    = deleted + 1,
+        size_delta = size_delta + excluded.size_delta;
+END;
+
+-- Даты создания существующих записей не хранятся: они учитываются созданными
+-- в день последнего изменения, чтобы сумма изменений совпадала с текущим числом
+-- и объемом записей
+INSERT INTO record_daily_stats (user_id, day, created, size_delta)
+SELECT user_id, date(last_modified), COUNT(*), COALESCE(SUM(LENGTH(encrypted_data)), 0)
+FROM records
+WHERE org_id IS NULL AND deleted_at IS NULL
+GROUP BY 1, 2;
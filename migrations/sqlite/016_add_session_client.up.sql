@@ -0,0 +1,6 @@
+-- Откуда создана и последний раз использована сессия: по этим сведениям
+-- пользователь находит в списке сессий чужой вход и завершает его.
+ALTER TABLE sessions ADD COLUMN device_id TEXT;
+ALTER TABLE sessions ADD COLUMN ip_address TEXT;
+ALTER TABLE sessions ADD COLUMN user_agent TEXT;
+ALTER TABLE sessions ADD COLUMN last_used_at DATETIME;
@@ -6,7 +6,6 @@ package service_mock
 
 import (
 	"context"
-	"encoding/json"
 	"gophkeeper/internal/domain/record"
 	"time"
 
@@ -185,8 +184,8 @@ func (_c *RecordServicerMock_BatchUpdate_Call) RunAndReturn(run func(ctx context
 }
 
 // Create provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) Create(ctx context.Context, userID int, typ record.RecType, encryptedData string, meta json.RawMessage) (int, error) {
-	ret := _mock.Called(ctx, userID, typ, encryptedData, meta)
+func (_mock *RecordServicerMock) Create(ctx context.Context, userID int, req record.CreateRequest) (int, error) {
+	ret := _mock.Called(ctx, userID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
@@ -194,16 +193,16 @@ func (_mock *RecordServicerMock) Create(ctx context.Context, userID int, typ rec
 
 	var r0 int
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType, string, json.RawMessage) (int, error)); ok {
-		return returnFunc(ctx, userID, typ, encryptedData, meta)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.CreateRequest) (int, error)); ok {
+		return returnFunc(ctx, userID, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType, string, json.RawMessage) int); ok {
-		r0 = returnFunc(ctx, userID, typ, encryptedData, meta)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.CreateRequest) int); ok {
+		r0 = returnFunc(ctx, userID, req)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.RecType, string, json.RawMessage) error); ok {
-		r1 = returnFunc(ctx, userID, typ, encryptedData, meta)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.CreateRequest) error); ok {
+		r1 = returnFunc(ctx, userID, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -218,14 +217,12 @@ type RecordServicerMock_Create_Call struct {
 // Create is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID int
-//   - typ record.RecType
-//   - encryptedData string
-//   - meta json.RawMessage
-func (_e *RecordServicerMock_Expecter) Create(ctx interface{}, userID interface{}, typ interface{}, encryptedData interface{}, meta interface{}) *RecordServicerMock_Create_Call {
-	return &RecordServicerMock_Create_Call{Call: _e.mock.On("Create", ctx, userID, typ, encryptedData, meta)}
+//   - req record.CreateRequest
+func (_e *RecordServicerMock_Expecter) Create(ctx interface{}, userID interface{}, req interface{}) *RecordServicerMock_Create_Call {
+	return &RecordServicerMock_Create_Call{Call: _e.mock.On("Create", ctx, userID, req)}
 }
 
-func (_c *RecordServicerMock_Create_Call) Run(run func(ctx context.Context, userID int, typ record.RecType, encryptedData string, meta json.RawMessage)) *RecordServicerMock_Create_Call {
+func (_c *RecordServicerMock_Create_Call) Run(run func(ctx context.Context, userID int, req record.CreateRequest)) *RecordServicerMock_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -235,42 +232,184 @@ func (_c *RecordServicerMock_Create_Call) Run(run func(ctx context.Context, user
 		if args[1] != nil {
 			arg1 = args[1].(int)
 		}
-		var arg2 record.RecType
+		var arg2 record.CreateRequest
 		if args[2] != nil {
-			arg2 = args[2].(record.RecType)
+			arg2 = args[2].(record.CreateRequest)
 		}
-		var arg3 string
-		if args[3] != nil {
-			arg3 = args[3].(string)
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_Create_Call) Return(n int, err error) *RecordServicerMock_Create_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_Create_Call) RunAndReturn(run func(ctx context.Context, userID int, req record.CreateRequest) (int, error)) *RecordServicerMock_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateInOrg provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) CreateInOrg(ctx context.Context, userID int, orgID int, req record.CreateRequest) (int, error) {
+	ret := _mock.Called(ctx, userID, orgID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateInOrg")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, record.CreateRequest) (int, error)); ok {
+		return returnFunc(ctx, userID, orgID, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, record.CreateRequest) int); ok {
+		r0 = returnFunc(ctx, userID, orgID, req)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, record.CreateRequest) error); ok {
+		r1 = returnFunc(ctx, userID, orgID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_CreateInOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateInOrg'
+type RecordServicerMock_CreateInOrg_Call struct {
+	*mock.Call
+}
+
+// CreateInOrg is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - orgID int
+//   - req record.CreateRequest
+func (_e *RecordServicerMock_Expecter) CreateInOrg(ctx interface{}, userID interface{}, orgID interface{}, req interface{}) *RecordServicerMock_CreateInOrg_Call {
+	return &RecordServicerMock_CreateInOrg_Call{Call: _e.mock.On("CreateInOrg", ctx, userID, orgID, req)}
+}
+
+func (_c *RecordServicerMock_CreateInOrg_Call) Run(run func(ctx context.Context, userID int, orgID int, req record.CreateRequest)) *RecordServicerMock_CreateInOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
 		}
-		var arg4 json.RawMessage
-		if args[4] != nil {
-			arg4 = args[4].(json.RawMessage)
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 record.CreateRequest
+		if args[3] != nil {
+			arg3 = args[3].(record.CreateRequest)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
 			arg3,
-			arg4,
 		)
 	})
 	return _c
 }
 
-func (_c *RecordServicerMock_Create_Call) Return(n int, err error) *RecordServicerMock_Create_Call {
+func (_c *RecordServicerMock_CreateInOrg_Call) Return(n int, err error) *RecordServicerMock_CreateInOrg_Call {
 	_c.Call.Return(n, err)
 	return _c
 }
 
-func (_c *RecordServicerMock_Create_Call) RunAndReturn(run func(ctx context.Context, userID int, typ record.RecType, encryptedData string, meta json.RawMessage) (int, error)) *RecordServicerMock_Create_Call {
+func (_c *RecordServicerMock_CreateInOrg_Call) RunAndReturn(run func(ctx context.Context, userID int, orgID int, req record.CreateRequest) (int, error)) *RecordServicerMock_CreateInOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRecord provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) CreateRecord(ctx context.Context, userID int, req record.CreateRequest) (*record.Record, error) {
+	ret := _mock.Called(ctx, userID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRecord")
+	}
+
+	var r0 *record.Record
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.CreateRequest) (*record.Record, error)); ok {
+		return returnFunc(ctx, userID, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.CreateRequest) *record.Record); ok {
+		r0 = returnFunc(ctx, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*record.Record)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.CreateRequest) error); ok {
+		r1 = returnFunc(ctx, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_CreateRecord_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRecord'
+type RecordServicerMock_CreateRecord_Call struct {
+	*mock.Call
+}
+
+// CreateRecord is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - req record.CreateRequest
+func (_e *RecordServicerMock_Expecter) CreateRecord(ctx interface{}, userID interface{}, req interface{}) *RecordServicerMock_CreateRecord_Call {
+	return &RecordServicerMock_CreateRecord_Call{Call: _e.mock.On("CreateRecord", ctx, userID, req)}
+}
+
+func (_c *RecordServicerMock_CreateRecord_Call) Run(run func(ctx context.Context, userID int, req record.CreateRequest)) *RecordServicerMock_CreateRecord_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 record.CreateRequest
+		if args[2] != nil {
+			arg2 = args[2].(record.CreateRequest)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_CreateRecord_Call) Return(record1 *record.Record, err error) *RecordServicerMock_CreateRecord_Call {
+	_c.Call.Return(record1, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_CreateRecord_Call) RunAndReturn(run func(ctx context.Context, userID int, req record.CreateRequest) (*record.Record, error)) *RecordServicerMock_CreateRecord_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // CreateWithModels provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) CreateWithModels(ctx context.Context, userID int, typ record.RecType, data record.Data, meta record.MetaData, deviceID string) (int, error) {
-	ret := _mock.Called(ctx, userID, typ, data, meta, deviceID)
+func (_mock *RecordServicerMock) CreateWithModels(ctx context.Context, userID int, req record.ModelRequest) (int, error) {
+	ret := _mock.Called(ctx, userID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateWithModels")
@@ -278,16 +417,16 @@ func (_mock *RecordServicerMock) CreateWithModels(ctx context.Context, userID in
 
 	var r0 int
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType, record.Data, record.MetaData, string) (int, error)); ok {
-		return returnFunc(ctx, userID, typ, data, meta, deviceID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.ModelRequest) (int, error)); ok {
+		return returnFunc(ctx, userID, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType, record.Data, record.MetaData, string) int); ok {
-		r0 = returnFunc(ctx, userID, typ, data, meta, deviceID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.ModelRequest) int); ok {
+		r0 = returnFunc(ctx, userID, req)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.RecType, record.Data, record.MetaData, string) error); ok {
-		r1 = returnFunc(ctx, userID, typ, data, meta, deviceID)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.ModelRequest) error); ok {
+		r1 = returnFunc(ctx, userID, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -302,15 +441,12 @@ type RecordServicerMock_CreateWithModels_Call struct {
 // CreateWithModels is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID int
-//   - typ record.RecType
-//   - data record.Data
-//   - meta record.MetaData
-//   - deviceID string
-func (_e *RecordServicerMock_Expecter) CreateWithModels(ctx interface{}, userID interface{}, typ interface{}, data interface{}, meta interface{}, deviceID interface{}) *RecordServicerMock_CreateWithModels_Call {
-	return &RecordServicerMock_CreateWithModels_Call{Call: _e.mock.On("CreateWithModels", ctx, userID, typ, data, meta, deviceID)}
+//   - req record.ModelRequest
+func (_e *RecordServicerMock_Expecter) CreateWithModels(ctx interface{}, userID interface{}, req interface{}) *RecordServicerMock_CreateWithModels_Call {
+	return &RecordServicerMock_CreateWithModels_Call{Call: _e.mock.On("CreateWithModels", ctx, userID, req)}
 }
 
-func (_c *RecordServicerMock_CreateWithModels_Call) Run(run func(ctx context.Context, userID int, typ record.RecType, data record.Data, meta record.MetaData, deviceID string)) *RecordServicerMock_CreateWithModels_Call {
+func (_c *RecordServicerMock_CreateWithModels_Call) Run(run func(ctx context.Context, userID int, req record.ModelRequest)) *RecordServicerMock_CreateWithModels_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -320,29 +456,14 @@ func (_c *RecordServicerMock_CreateWithModels_Call) Run(run func(ctx context.Con
 		if args[1] != nil {
 			arg1 = args[1].(int)
 		}
-		var arg2 record.RecType
+		var arg2 record.ModelRequest
 		if args[2] != nil {
-			arg2 = args[2].(record.RecType)
-		}
-		var arg3 record.Data
-		if args[3] != nil {
-			arg3 = args[3].(record.Data)
-		}
-		var arg4 record.MetaData
-		if args[4] != nil {
-			arg4 = args[4].(record.MetaData)
-		}
-		var arg5 string
-		if args[5] != nil {
-			arg5 = args[5].(string)
+			arg2 = args[2].(record.ModelRequest)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
-			arg3,
-			arg4,
-			arg5,
 		)
 	})
 	return _c
@@ -353,7 +474,7 @@ func (_c *RecordServicerMock_CreateWithModels_Call) Return(n int, err error) *Re
 	return _c
 }
 
-func (_c *RecordServicerMock_CreateWithModels_Call) RunAndReturn(run func(ctx context.Context, userID int, typ record.RecType, data record.Data, meta record.MetaData, deviceID string) (int, error)) *RecordServicerMock_CreateWithModels_Call {
+func (_c *RecordServicerMock_CreateWithModels_Call) RunAndReturn(run func(ctx context.Context, userID int, req record.ModelRequest) (int, error)) *RecordServicerMock_CreateWithModels_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -496,8 +617,8 @@ func (_c *RecordServicerMock_Find_Call) RunAndReturn(run func(ctx context.Contex
 }
 
 // GetByType provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) GetByType(ctx context.Context, userID int, recordType string) ([]record.Record, error) {
-	ret := _mock.Called(ctx, userID, recordType)
+func (_mock *RecordServicerMock) GetByType(ctx context.Context, userID int, typ record.RecType) ([]record.Record, error) {
+	ret := _mock.Called(ctx, userID, typ)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetByType")
@@ -505,18 +626,18 @@ func (_mock *RecordServicerMock) GetByType(ctx context.Context, userID int, reco
 
 	var r0 []record.Record
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string) ([]record.Record, error)); ok {
-		return returnFunc(ctx, userID, recordType)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType) ([]record.Record, error)); ok {
+		return returnFunc(ctx, userID, typ)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string) []record.Record); ok {
-		r0 = returnFunc(ctx, userID, recordType)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.RecType) []record.Record); ok {
+		r0 = returnFunc(ctx, userID, typ)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]record.Record)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
-		r1 = returnFunc(ctx, userID, recordType)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, record.RecType) error); ok {
+		r1 = returnFunc(ctx, userID, typ)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -531,12 +652,12 @@ type RecordServicerMock_GetByType_Call struct {
 // GetByType is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID int
-//   - recordType string
-func (_e *RecordServicerMock_Expecter) GetByType(ctx interface{}, userID interface{}, recordType interface{}) *RecordServicerMock_GetByType_Call {
-	return &RecordServicerMock_GetByType_Call{Call: _e.mock.On("GetByType", ctx, userID, recordType)}
+//   - typ record.RecType
+func (_e *RecordServicerMock_Expecter) GetByType(ctx interface{}, userID interface{}, typ interface{}) *RecordServicerMock_GetByType_Call {
+	return &RecordServicerMock_GetByType_Call{Call: _e.mock.On("GetByType", ctx, userID, typ)}
 }
 
-func (_c *RecordServicerMock_GetByType_Call) Run(run func(ctx context.Context, userID int, recordType string)) *RecordServicerMock_GetByType_Call {
+func (_c *RecordServicerMock_GetByType_Call) Run(run func(ctx context.Context, userID int, typ record.RecType)) *RecordServicerMock_GetByType_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -546,9 +667,9 @@ func (_c *RecordServicerMock_GetByType_Call) Run(run func(ctx context.Context, u
 		if args[1] != nil {
 			arg1 = args[1].(int)
 		}
-		var arg2 string
+		var arg2 record.RecType
 		if args[2] != nil {
-			arg2 = args[2].(string)
+			arg2 = args[2].(record.RecType)
 		}
 		run(
 			arg0,
@@ -564,7 +685,7 @@ func (_c *RecordServicerMock_GetByType_Call) Return(records []record.Record, err
 	return _c
 }
 
-func (_c *RecordServicerMock_GetByType_Call) RunAndReturn(run func(ctx context.Context, userID int, recordType string) ([]record.Record, error)) *RecordServicerMock_GetByType_Call {
+func (_c *RecordServicerMock_GetByType_Call) RunAndReturn(run func(ctx context.Context, userID int, typ record.RecType) ([]record.Record, error)) *RecordServicerMock_GetByType_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -644,8 +765,8 @@ func (_c *RecordServicerMock_GetModifiedSince_Call) RunAndReturn(run func(ctx co
 }
 
 // GetRecordWithModels provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) GetRecordWithModels(ctx context.Context, recordID int, userID int) (record.Data, record.MetaData, error) {
-	ret := _mock.Called(ctx, recordID, userID)
+func (_mock *RecordServicerMock) GetRecordWithModels(ctx context.Context, userID int, recordID int) (record.Data, record.MetaData, error) {
+	ret := _mock.Called(ctx, userID, recordID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetRecordWithModels")
@@ -655,24 +776,24 @@ func (_mock *RecordServicerMock) GetRecordWithModels(ctx context.Context, record
 	var r1 record.MetaData
 	var r2 error
 	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (record.Data, record.MetaData, error)); ok {
-		return returnFunc(ctx, recordID, userID)
+		return returnFunc(ctx, userID, recordID)
 	}
 	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) record.Data); ok {
-		r0 = returnFunc(ctx, recordID, userID)
+		r0 = returnFunc(ctx, userID, recordID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(record.Data)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) record.MetaData); ok {
-		r1 = returnFunc(ctx, recordID, userID)
+		r1 = returnFunc(ctx, userID, recordID)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(record.MetaData)
 		}
 	}
 	if returnFunc, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
-		r2 = returnFunc(ctx, recordID, userID)
+		r2 = returnFunc(ctx, userID, recordID)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -686,13 +807,13 @@ type RecordServicerMock_GetRecordWithModels_Call struct {
 
 // GetRecordWithModels is a helper method to define mock.On call
 //   - ctx context.Context
-//   - recordID int
 //   - userID int
-func (_e *RecordServicerMock_Expecter) GetRecordWithModels(ctx interface{}, recordID interface{}, userID interface{}) *RecordServicerMock_GetRecordWithModels_Call {
-	return &RecordServicerMock_GetRecordWithModels_Call{Call: _e.mock.On("GetRecordWithModels", ctx, recordID, userID)}
+//   - recordID int
+func (_e *RecordServicerMock_Expecter) GetRecordWithModels(ctx interface{}, userID interface{}, recordID interface{}) *RecordServicerMock_GetRecordWithModels_Call {
+	return &RecordServicerMock_GetRecordWithModels_Call{Call: _e.mock.On("GetRecordWithModels", ctx, userID, recordID)}
 }
 
-func (_c *RecordServicerMock_GetRecordWithModels_Call) Run(run func(ctx context.Context, recordID int, userID int)) *RecordServicerMock_GetRecordWithModels_Call {
+func (_c *RecordServicerMock_GetRecordWithModels_Call) Run(run func(ctx context.Context, userID int, recordID int)) *RecordServicerMock_GetRecordWithModels_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -720,7 +841,7 @@ func (_c *RecordServicerMock_GetRecordWithModels_Call) Return(data record.Data,
 	return _c
 }
 
-func (_c *RecordServicerMock_GetRecordWithModels_Call) RunAndReturn(run func(ctx context.Context, recordID int, userID int) (record.Data, record.MetaData, error)) *RecordServicerMock_GetRecordWithModels_Call {
+func (_c *RecordServicerMock_GetRecordWithModels_Call) RunAndReturn(run func(ctx context.Context, userID int, recordID int) (record.Data, record.MetaData, error)) *RecordServicerMock_GetRecordWithModels_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -865,6 +986,80 @@ func (_c *RecordServicerMock_GetVersions_Call) RunAndReturn(run func(ctx context
 	return _c
 }
 
+// Head provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) Head(ctx context.Context, userID int, recordID int) (*record.Head, error) {
+	ret := _mock.Called(ctx, userID, recordID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Head")
+	}
+
+	var r0 *record.Head
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (*record.Head, error)); ok {
+		return returnFunc(ctx, userID, recordID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) *record.Head); ok {
+		r0 = returnFunc(ctx, userID, recordID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*record.Head)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = returnFunc(ctx, userID, recordID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_Head_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Head'
+type RecordServicerMock_Head_Call struct {
+	*mock.Call
+}
+
+// Head is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - recordID int
+func (_e *RecordServicerMock_Expecter) Head(ctx interface{}, userID interface{}, recordID interface{}) *RecordServicerMock_Head_Call {
+	return &RecordServicerMock_Head_Call{Call: _e.mock.On("Head", ctx, userID, recordID)}
+}
+
+func (_c *RecordServicerMock_Head_Call) Run(run func(ctx context.Context, userID int, recordID int)) *RecordServicerMock_Head_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_Head_Call) Return(head *record.Head, err error) *RecordServicerMock_Head_Call {
+	_c.Call.Return(head, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_Head_Call) RunAndReturn(run func(ctx context.Context, userID int, recordID int) (*record.Head, error)) *RecordServicerMock_Head_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // List provides a mock function for the type RecordServicerMock
 func (_mock *RecordServicerMock) List(ctx context.Context, userID int) (record.ListResponse, error) {
 	ret := _mock.Called(ctx, userID)
@@ -931,6 +1126,364 @@ func (_c *RecordServicerMock_List_Call) RunAndReturn(run func(ctx context.Contex
 	return _c
 }
 
+// ListOrg provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) ListOrg(ctx context.Context, userID int, orgID int) (record.ListResponse, error) {
+	ret := _mock.Called(ctx, userID, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrg")
+	}
+
+	var r0 record.ListResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (record.ListResponse, error)); ok {
+		return returnFunc(ctx, userID, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) record.ListResponse); ok {
+		r0 = returnFunc(ctx, userID, orgID)
+	} else {
+		r0 = ret.Get(0).(record.ListResponse)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = returnFunc(ctx, userID, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_ListOrg_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrg'
+type RecordServicerMock_ListOrg_Call struct {
+	*mock.Call
+}
+
+// ListOrg is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - orgID int
+func (_e *RecordServicerMock_Expecter) ListOrg(ctx interface{}, userID interface{}, orgID interface{}) *RecordServicerMock_ListOrg_Call {
+	return &RecordServicerMock_ListOrg_Call{Call: _e.mock.On("ListOrg", ctx, userID, orgID)}
+}
+
+func (_c *RecordServicerMock_ListOrg_Call) Run(run func(ctx context.Context, userID int, orgID int)) *RecordServicerMock_ListOrg_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_ListOrg_Call) Return(listResponse record.ListResponse, err error) *RecordServicerMock_ListOrg_Call {
+	_c.Call.Return(listResponse, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_ListOrg_Call) RunAndReturn(run func(ctx context.Context, userID int, orgID int) (record.ListResponse, error)) *RecordServicerMock_ListOrg_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListTrash provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) ListTrash(ctx context.Context, userID int) ([]record.Record, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTrash")
+	}
+
+	var r0 []record.Record
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]record.Record, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []record.Record); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]record.Record)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_ListTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTrash'
+type RecordServicerMock_ListTrash_Call struct {
+	*mock.Call
+}
+
+// ListTrash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+func (_e *RecordServicerMock_Expecter) ListTrash(ctx interface{}, userID interface{}) *RecordServicerMock_ListTrash_Call {
+	return &RecordServicerMock_ListTrash_Call{Call: _e.mock.On("ListTrash", ctx, userID)}
+}
+
+func (_c *RecordServicerMock_ListTrash_Call) Run(run func(ctx context.Context, userID int)) *RecordServicerMock_ListTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_ListTrash_Call) Return(records []record.Record, err error) *RecordServicerMock_ListTrash_Call {
+	_c.Call.Return(records, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_ListTrash_Call) RunAndReturn(run func(ctx context.Context, userID int) ([]record.Record, error)) *RecordServicerMock_ListTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeTrash provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) PurgeTrash(ctx context.Context, userID int, olderThan time.Duration) (int, error) {
+	ret := _mock.Called(ctx, userID, olderThan)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTrash")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Duration) (int, error)); ok {
+		return returnFunc(ctx, userID, olderThan)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Duration) int); ok {
+		r0 = returnFunc(ctx, userID, olderThan)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = returnFunc(ctx, userID, olderThan)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_PurgeTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeTrash'
+type RecordServicerMock_PurgeTrash_Call struct {
+	*mock.Call
+}
+
+// PurgeTrash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - olderThan time.Duration
+func (_e *RecordServicerMock_Expecter) PurgeTrash(ctx interface{}, userID interface{}, olderThan interface{}) *RecordServicerMock_PurgeTrash_Call {
+	return &RecordServicerMock_PurgeTrash_Call{Call: _e.mock.On("PurgeTrash", ctx, userID, olderThan)}
+}
+
+func (_c *RecordServicerMock_PurgeTrash_Call) Run(run func(ctx context.Context, userID int, olderThan time.Duration)) *RecordServicerMock_PurgeTrash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 time.Duration
+		if args[2] != nil {
+			arg2 = args[2].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_PurgeTrash_Call) Return(n int, err error) *RecordServicerMock_PurgeTrash_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_PurgeTrash_Call) RunAndReturn(run func(ctx context.Context, userID int, olderThan time.Duration) (int, error)) *RecordServicerMock_PurgeTrash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListExpiring provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) ListExpiring(ctx context.Context, userID int, within time.Duration) ([]record.Expiration, error) {
+	ret := _mock.Called(ctx, userID, within)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListExpiring")
+	}
+
+	var r0 []record.Expiration
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]record.Expiration, error)); ok {
+		return returnFunc(ctx, userID, within)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Duration) []record.Expiration); ok {
+		r0 = returnFunc(ctx, userID, within)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]record.Expiration)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = returnFunc(ctx, userID, within)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_ListExpiring_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListExpiring'
+type RecordServicerMock_ListExpiring_Call struct {
+	*mock.Call
+}
+
+// ListExpiring is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - within time.Duration
+func (_e *RecordServicerMock_Expecter) ListExpiring(ctx interface{}, userID interface{}, within interface{}) *RecordServicerMock_ListExpiring_Call {
+	return &RecordServicerMock_ListExpiring_Call{Call: _e.mock.On("ListExpiring", ctx, userID, within)}
+}
+
+func (_c *RecordServicerMock_ListExpiring_Call) Run(run func(ctx context.Context, userID int, within time.Duration)) *RecordServicerMock_ListExpiring_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 time.Duration
+		if args[2] != nil {
+			arg2 = args[2].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_ListExpiring_Call) Return(expirations []record.Expiration, err error) *RecordServicerMock_ListExpiring_Call {
+	_c.Call.Return(expirations, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_ListExpiring_Call) RunAndReturn(run func(ctx context.Context, userID int, within time.Duration) ([]record.Expiration, error)) *RecordServicerMock_ListExpiring_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Restore provides a mock function for the type RecordServicerMock
+func (_mock *RecordServicerMock) Restore(ctx context.Context, userID int, recordID int) (int, error) {
+	ret := _mock.Called(ctx, userID, recordID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (int, error)); ok {
+		return returnFunc(ctx, userID, recordID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = returnFunc(ctx, userID, recordID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = returnFunc(ctx, userID, recordID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// RecordServicerMock_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type RecordServicerMock_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int
+//   - recordID int
+func (_e *RecordServicerMock_Expecter) Restore(ctx interface{}, userID interface{}, recordID interface{}) *RecordServicerMock_Restore_Call {
+	return &RecordServicerMock_Restore_Call{Call: _e.mock.On("Restore", ctx, userID, recordID)}
+}
+
+func (_c *RecordServicerMock_Restore_Call) Run(run func(ctx context.Context, userID int, recordID int)) *RecordServicerMock_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *RecordServicerMock_Restore_Call) Return(n int, err error) *RecordServicerMock_Restore_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *RecordServicerMock_Restore_Call) RunAndReturn(run func(ctx context.Context, userID int, recordID int) (int, error)) *RecordServicerMock_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Search provides a mock function for the type RecordServicerMock
 func (_mock *RecordServicerMock) Search(ctx context.Context, userID int, criteria record.SearchCriteria) ([]record.Record, error) {
 	ret := _mock.Called(ctx, userID, criteria)
@@ -1069,16 +1622,16 @@ func (_c *RecordServicerMock_SoftDelete_Call) RunAndReturn(run func(ctx context.
 }
 
 // Update provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) Update(ctx context.Context, userID int, recordID int, typ record.RecType, encryptedData string, meta json.RawMessage) error {
-	ret := _mock.Called(ctx, userID, recordID, typ, encryptedData, meta)
+func (_mock *RecordServicerMock) Update(ctx context.Context, userID int, req record.UpdateRequest) error {
+	ret := _mock.Called(ctx, userID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, record.RecType, string, json.RawMessage) error); ok {
-		r0 = returnFunc(ctx, userID, recordID, typ, encryptedData, meta)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, record.UpdateRequest) error); ok {
+		r0 = returnFunc(ctx, userID, req)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1093,15 +1646,12 @@ type RecordServicerMock_Update_Call struct {
 // Update is a helper method to define mock.On call
 //   - ctx context.Context
 //   - userID int
-//   - recordID int
-//   - typ record.RecType
-//   - encryptedData string
-//   - meta json.RawMessage
-func (_e *RecordServicerMock_Expecter) Update(ctx interface{}, userID interface{}, recordID interface{}, typ interface{}, encryptedData interface{}, meta interface{}) *RecordServicerMock_Update_Call {
-	return &RecordServicerMock_Update_Call{Call: _e.mock.On("Update", ctx, userID, recordID, typ, encryptedData, meta)}
+//   - req record.UpdateRequest
+func (_e *RecordServicerMock_Expecter) Update(ctx interface{}, userID interface{}, req interface{}) *RecordServicerMock_Update_Call {
+	return &RecordServicerMock_Update_Call{Call: _e.mock.On("Update", ctx, userID, req)}
 }
 
-func (_c *RecordServicerMock_Update_Call) Run(run func(ctx context.Context, userID int, recordID int, typ record.RecType, encryptedData string, meta json.RawMessage)) *RecordServicerMock_Update_Call {
+func (_c *RecordServicerMock_Update_Call) Run(run func(ctx context.Context, userID int, req record.UpdateRequest)) *RecordServicerMock_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -1111,29 +1661,14 @@ func (_c *RecordServicerMock_Update_Call) Run(run func(ctx context.Context, user
 		if args[1] != nil {
 			arg1 = args[1].(int)
 		}
-		var arg2 int
+		var arg2 record.UpdateRequest
 		if args[2] != nil {
-			arg2 = args[2].(int)
-		}
-		var arg3 record.RecType
-		if args[3] != nil {
-			arg3 = args[3].(record.RecType)
-		}
-		var arg4 string
-		if args[4] != nil {
-			arg4 = args[4].(string)
-		}
-		var arg5 json.RawMessage
-		if args[5] != nil {
-			arg5 = args[5].(json.RawMessage)
+			arg2 = args[2].(record.UpdateRequest)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
-			arg3,
-			arg4,
-			arg5,
 		)
 	})
 	return _c
@@ -1144,22 +1679,22 @@ func (_c *RecordServicerMock_Update_Call) Return(err error) *RecordServicerMock_
 	return _c
 }
 
-func (_c *RecordServicerMock_Update_Call) RunAndReturn(run func(ctx context.Context, userID int, recordID int, typ record.RecType, encryptedData string, meta json.RawMessage) error) *RecordServicerMock_Update_Call {
+func (_c *RecordServicerMock_Update_Call) RunAndReturn(run func(ctx context.Context, userID int, req record.UpdateRequest) error) *RecordServicerMock_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // UpdateWithModels provides a mock function for the type RecordServicerMock
-func (_mock *RecordServicerMock) UpdateWithModels(ctx context.Context, recordID int, userID int, data record.Data, meta record.MetaData, deviceID string) error {
-	ret := _mock.Called(ctx, recordID, userID, data, meta, deviceID)
+func (_mock *RecordServicerMock) UpdateWithModels(ctx context.Context, userID int, recordID int, req record.ModelRequest) error {
+	ret := _mock.Called(ctx, userID, recordID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateWithModels")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, record.Data, record.MetaData, string) error); ok {
-		r0 = returnFunc(ctx, recordID, userID, data, meta, deviceID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, record.ModelRequest) error); ok {
+		r0 = returnFunc(ctx, userID, recordID, req)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1173,16 +1708,14 @@ type RecordServicerMock_UpdateWithModels_Call struct {
 
 // UpdateWithModels is a helper method to define mock.On call
 //   - ctx context.Context
-//   - recordID int
 //   - userID int
-//   - data record.Data
-//   - meta record.MetaData
-//   - deviceID string
-func (_e *RecordServicerMock_Expecter) UpdateWithModels(ctx interface{}, recordID interface{}, userID interface{}, data interface{}, meta interface{}, deviceID interface{}) *RecordServicerMock_UpdateWithModels_Call {
-	return &RecordServicerMock_UpdateWithModels_Call{Call: _e.mock.On("UpdateWithModels", ctx, recordID, userID, data, meta, deviceID)}
+//   - recordID int
+//   - req record.ModelRequest
+func (_e *RecordServicerMock_Expecter) UpdateWithModels(ctx interface{}, userID interface{}, recordID interface{}, req interface{}) *RecordServicerMock_UpdateWithModels_Call {
+	return &RecordServicerMock_UpdateWithModels_Call{Call: _e.mock.On("UpdateWithModels", ctx, userID, recordID, req)}
 }
 
-func (_c *RecordServicerMock_UpdateWithModels_Call) Run(run func(ctx context.Context, recordID int, userID int, data record.Data, meta record.MetaData, deviceID string)) *RecordServicerMock_UpdateWithModels_Call {
+func (_c *RecordServicerMock_UpdateWithModels_Call) Run(run func(ctx context.Context, userID int, recordID int, req record.ModelRequest)) *RecordServicerMock_UpdateWithModels_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -1196,25 +1729,15 @@ func (_c *RecordServicerMock_UpdateWithModels_Call) Run(run func(ctx context.Con
 		if args[2] != nil {
 			arg2 = args[2].(int)
 		}
-		var arg3 record.Data
+		var arg3 record.ModelRequest
 		if args[3] != nil {
-			arg3 = args[3].(record.Data)
-		}
-		var arg4 record.MetaData
-		if args[4] != nil {
-			arg4 = args[4].(record.MetaData)
-		}
-		var arg5 string
-		if args[5] != nil {
-			arg5 = args[5].(string)
+			arg3 = args[3].(record.ModelRequest)
 		}
 		run(
 			arg0,
 			arg1,
 			arg2,
 			arg3,
-			arg4,
-			arg5,
 		)
 	})
 	return _c
@@ -1225,7 +1748,7 @@ func (_c *RecordServicerMock_UpdateWithModels_Call) Return(err error) *RecordSer
 	return _c
 }
 
-func (_c *RecordServicerMock_UpdateWithModels_Call) RunAndReturn(run func(ctx context.Context, recordID int, userID int, data record.Data, meta record.MetaData, deviceID string) error) *RecordServicerMock_UpdateWithModels_Call {
+func (_c *RecordServicerMock_UpdateWithModels_Call) RunAndReturn(run func(ctx context.Context, userID int, recordID int, req record.ModelRequest) error) *RecordServicerMock_UpdateWithModels_Call {
 	_c.Call.Return(run)
 	return _c
 }